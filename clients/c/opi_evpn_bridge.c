@@ -0,0 +1,92 @@
+/* SPDX-License-Identifier: Apache-2.0
+ * Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+ * Copyright (C) 2023 Nordix Foundation.
+ */
+
+#include "opi_evpn_bridge.h"
+
+#include <arpa/inet.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+int opi_evpn_full_name(char *buf, size_t len, const char *collection, const char *id)
+{
+	int n;
+
+	if (strncmp(id, "//", 2) == 0)
+		n = snprintf(buf, len, "%s", id);
+	else
+		n = snprintf(buf, len, "%s%s/%s", OPI_EVPN_SERVICE_NAME, collection, id);
+	if (n < 0 || (size_t)n >= len)
+		return -1;
+	return n;
+}
+
+const char *opi_evpn_short_id(const char *name)
+{
+	const char *slash = strrchr(name, '/');
+
+	return slash ? slash + 1 : name;
+}
+
+int opi_evpn_ipv4_prefix(OpiEvpnIPPrefix *prefix, OpiEvpnIPAddress *addr, const char *cidr)
+{
+	char ip[INET_ADDRSTRLEN];
+	const char *slash = strchr(cidr, '/');
+	struct in_addr in;
+	char *end;
+	long plen;
+
+	if (!slash || (size_t)(slash - cidr) >= sizeof(ip))
+		return -1;
+	memcpy(ip, cidr, slash - cidr);
+	ip[slash - cidr] = '\0';
+	if (inet_pton(AF_INET, ip, &in) != 1)
+		return -1;
+	plen = strtol(slash + 1, &end, 10);
+	if (end == slash + 1 || *end != '\0' || plen < 0 || plen > 32)
+		return -1;
+
+	opi_api__network__opinetcommon__v1alpha1__ipaddress__init(addr);
+	addr->af = OPI_API__NETWORK__OPINETCOMMON__V1ALPHA1__IP_AF__IP_AF_INET;
+	addr->v4_or_v6_case = OPI_API__NETWORK__OPINETCOMMON__V1ALPHA1__IPADDRESS__V4_OR_V6_V4_ADDR;
+	/* the fixed32 holds the address read as a big endian integer, like the server decodes it */
+	addr->v4_addr = ntohl(in.s_addr);
+
+	opi_api__network__opinetcommon__v1alpha1__ipprefix__init(prefix);
+	prefix->addr = addr;
+	prefix->len = (int32_t)plen;
+	return 0;
+}
+
+int opi_evpn_grpc_frame(const ProtobufCMessage *request, uint8_t **out, size_t *out_len)
+{
+	size_t len = protobuf_c_message_get_packed_size(request);
+	uint8_t *buf = malloc(OPI_EVPN_GRPC_PREFIX_LEN + len);
+
+	if (!buf)
+		return -1;
+	buf[0] = 0;
+	buf[1] = (uint8_t)(len >> 24);
+	buf[2] = (uint8_t)(len >> 16);
+	buf[3] = (uint8_t)(len >> 8);
+	buf[4] = (uint8_t)len;
+	protobuf_c_message_pack(request, buf + OPI_EVPN_GRPC_PREFIX_LEN);
+	*out = buf;
+	*out_len = OPI_EVPN_GRPC_PREFIX_LEN + len;
+	return 0;
+}
+
+ProtobufCMessage *opi_evpn_grpc_unframe(const ProtobufCMessageDescriptor *descriptor,
+					const uint8_t *body, size_t len)
+{
+	size_t msg_len;
+
+	if (len < OPI_EVPN_GRPC_PREFIX_LEN || body[0] != 0)
+		return NULL;
+	msg_len = (size_t)body[1] << 24 | (size_t)body[2] << 16 | (size_t)body[3] << 8 | body[4];
+	if (msg_len > len - OPI_EVPN_GRPC_PREFIX_LEN)
+		return NULL;
+	return protobuf_c_message_unpack(descriptor, NULL, msg_len, body + OPI_EVPN_GRPC_PREFIX_LEN);
+}
@@ -0,0 +1,70 @@
+/* SPDX-License-Identifier: Apache-2.0
+ * Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+ * Copyright (C) 2023 Nordix Foundation.
+ *
+ * Convenience layer on top of the protobuf-c bindings of the bridge api
+ * generated by "make c-client". It builds the names and the addresses of the
+ * objects and frames the messages of the unary gRPC calls, the transport is
+ * left to the HTTP/2 stack of the firmware.
+ */
+
+#ifndef OPI_EVPN_BRIDGE_H
+#define OPI_EVPN_BRIDGE_H
+
+#include <stddef.h>
+#include <stdint.h>
+
+#include "l2_xpu_infra_mgr.pb-c.h"
+#include "l3_xpu_infra_mgr.pb-c.h"
+#include "networktypes.pb-c.h"
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+#define OPI_EVPN_SERVICE_NAME "//network.opiproject.org/"
+
+#define OPI_EVPN_COLLECTION_VRFS "vrfs"
+#define OPI_EVPN_COLLECTION_BRIDGES "bridges"
+#define OPI_EVPN_COLLECTION_SVIS "svis"
+#define OPI_EVPN_COLLECTION_PORTS "ports"
+
+/* gRPC method paths of the calls, e.g. OPI_EVPN_METHOD("VrfService", "CreateVrf") */
+#define OPI_EVPN_METHOD(service, rpc) \
+	"/opi_api.network.evpn_gw.v1alpha1." service "/" rpc
+
+/* Length of the prefix of a gRPC message: compressed flag and big endian length */
+#define OPI_EVPN_GRPC_PREFIX_LEN 5
+
+typedef OpiApi__Network__Opinetcommon__V1alpha1__IPPrefix OpiEvpnIPPrefix;
+typedef OpiApi__Network__Opinetcommon__V1alpha1__IPAddress OpiEvpnIPAddress;
+
+/* opi_evpn_full_name writes the full name of the object into buf, ids which
+ * are full names already are copied unchanged. It returns the length of the
+ * name, or -1 when buf is too small. */
+int opi_evpn_full_name(char *buf, size_t len, const char *collection, const char *id);
+
+/* opi_evpn_short_id returns the id of the object, i.e. the last segment of its name */
+const char *opi_evpn_short_id(const char *name);
+
+/* opi_evpn_ipv4_prefix fills the prefix and its address from an IPv4 CIDR,
+ * e.g. "10.0.0.1/24". The address is owned by the caller and must outlive
+ * the prefix. It returns 0, or -1 when the CIDR is invalid. */
+int opi_evpn_ipv4_prefix(OpiEvpnIPPrefix *prefix, OpiEvpnIPAddress *addr, const char *cidr);
+
+/* opi_evpn_grpc_frame packs the request into a newly allocated gRPC message
+ * to be sent as the body of the call. It returns 0, or -1 when the allocation
+ * fails. The message is freed by the caller. */
+int opi_evpn_grpc_frame(const ProtobufCMessage *request, uint8_t **out, size_t *out_len);
+
+/* opi_evpn_grpc_unframe unpacks the response of the call from the body of the
+ * gRPC response, it returns NULL when the body is truncated, compressed or
+ * invalid. The response is freed with protobuf_c_message_free_unpacked. */
+ProtobufCMessage *opi_evpn_grpc_unframe(const ProtobufCMessageDescriptor *descriptor,
+					const uint8_t *body, size_t len);
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif /* OPI_EVPN_BRIDGE_H */
@@ -0,0 +1,93 @@
+/* SPDX-License-Identifier: Apache-2.0
+ * Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+ * Copyright (C) 2023 Nordix Foundation.
+ *
+ * Tests of the convenience layer, run by "make test"
+ */
+
+#include "opi_evpn_bridge.h"
+
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+static int failures;
+
+#define CHECK(cond) \
+	do { \
+		if (!(cond)) { \
+			fprintf(stderr, "%s:%d: %s\n", __FILE__, __LINE__, #cond); \
+			failures++; \
+		} \
+	} while (0)
+
+static void test_names(void)
+{
+	char buf[64];
+
+	CHECK(opi_evpn_full_name(buf, sizeof(buf), OPI_EVPN_COLLECTION_VRFS, "blue") == 34);
+	CHECK(strcmp(buf, "//network.opiproject.org/vrfs/blue") == 0);
+	/* full names are copied unchanged */
+	CHECK(opi_evpn_full_name(buf, sizeof(buf), OPI_EVPN_COLLECTION_VRFS, "//network.opiproject.org/vrfs/red") > 0);
+	CHECK(strcmp(buf, "//network.opiproject.org/vrfs/red") == 0);
+	CHECK(opi_evpn_full_name(buf, 10, OPI_EVPN_COLLECTION_VRFS, "blue") == -1);
+
+	CHECK(strcmp(opi_evpn_short_id("//network.opiproject.org/vrfs/blue"), "blue") == 0);
+	CHECK(strcmp(opi_evpn_short_id("blue"), "blue") == 0);
+}
+
+static void test_ipv4_prefix(void)
+{
+	OpiEvpnIPPrefix prefix;
+	OpiEvpnIPAddress addr;
+
+	CHECK(opi_evpn_ipv4_prefix(&prefix, &addr, "10.0.0.1/24") == 0);
+	CHECK(prefix.addr == &addr && prefix.len == 24);
+	CHECK(addr.v4_addr == 0x0a000001);
+
+	CHECK(opi_evpn_ipv4_prefix(&prefix, &addr, "10.0.0.1") == -1);
+	CHECK(opi_evpn_ipv4_prefix(&prefix, &addr, "10.0.0.300/24") == -1);
+	CHECK(opi_evpn_ipv4_prefix(&prefix, &addr, "10.0.0.1/33") == -1);
+	CHECK(opi_evpn_ipv4_prefix(&prefix, &addr, "10.0.0.1/") == -1);
+	CHECK(opi_evpn_ipv4_prefix(&prefix, &addr, "2001:db8::1/64") == -1);
+}
+
+static void test_grpc_frame(void)
+{
+	OpiApi__Network__EvpnGw__V1alpha1__GetVrfRequest req;
+	OpiApi__Network__EvpnGw__V1alpha1__GetVrfRequest *out;
+	char name[] = "//network.opiproject.org/vrfs/blue";
+	uint8_t *body;
+	size_t len;
+
+	opi_api__network__evpn_gw__v1alpha1__get_vrf_request__init(&req);
+	req.name = name;
+	CHECK(opi_evpn_grpc_frame(&req.base, &body, &len) == 0);
+	CHECK(len == OPI_EVPN_GRPC_PREFIX_LEN + protobuf_c_message_get_packed_size(&req.base));
+	CHECK(body[0] == 0 && (size_t)body[4] == len - OPI_EVPN_GRPC_PREFIX_LEN);
+
+	out = (OpiApi__Network__EvpnGw__V1alpha1__GetVrfRequest *)opi_evpn_grpc_unframe(
+		&opi_api__network__evpn_gw__v1alpha1__get_vrf_request__descriptor, body, len);
+	CHECK(out && strcmp(out->name, name) == 0);
+	if (out)
+		protobuf_c_message_free_unpacked(&out->base, NULL);
+
+	/* truncated and compressed bodies are refused */
+	CHECK(!opi_evpn_grpc_unframe(&opi_api__network__evpn_gw__v1alpha1__get_vrf_request__descriptor, body, len - 1));
+	body[0] = 1;
+	CHECK(!opi_evpn_grpc_unframe(&opi_api__network__evpn_gw__v1alpha1__get_vrf_request__descriptor, body, len));
+	free(body);
+}
+
+int main(void)
+{
+	test_names();
+	test_ipv4_prefix();
+	test_grpc_frame();
+	if (failures) {
+		fprintf(stderr, "%d checks failed\n", failures);
+		return EXIT_FAILURE;
+	}
+	printf("ok\n");
+	return EXIT_SUCCESS;
+}
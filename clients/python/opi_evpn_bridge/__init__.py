@@ -0,0 +1,56 @@
+# SPDX-License-Identifier: Apache-2.0
+# Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+# Copyright (C) 2023 Nordix Foundation.
+
+"""Python client of the opi-evpn-bridge gRPC API.
+
+The bindings under gen/ are generated by "make python-client" from the
+opi-api version pinned in the go.mod of the bridge.
+"""
+
+import os
+import sys
+
+# protoc generates absolute imports between the modules of the bindings,
+# e.g. "import networktypes_pb2", so their directory has to be on the path
+sys.path.insert(0, os.path.join(os.path.dirname(__file__), "gen"))
+
+from .errors import (  # noqa: E402
+    AlreadyExistsError,
+    BridgeError,
+    FailedPreconditionError,
+    InvalidArgumentError,
+    NotFoundError,
+    PermissionDeniedError,
+    ResourceExhaustedError,
+    UnavailableError,
+)
+from .names import (  # noqa: E402
+    COLLECTION_BRIDGES,
+    COLLECTION_PORTS,
+    COLLECTION_SVIS,
+    COLLECTION_VRFS,
+    full_name,
+    parse_name,
+    short_id,
+)
+from .client import Client  # noqa: E402
+
+__all__ = [
+    "AlreadyExistsError",
+    "BridgeError",
+    "Client",
+    "COLLECTION_BRIDGES",
+    "COLLECTION_PORTS",
+    "COLLECTION_SVIS",
+    "COLLECTION_VRFS",
+    "FailedPreconditionError",
+    "InvalidArgumentError",
+    "NotFoundError",
+    "PermissionDeniedError",
+    "ResourceExhaustedError",
+    "UnavailableError",
+    "full_name",
+    "parse_name",
+    "short_id",
+]
@@ -0,0 +1,221 @@
+# SPDX-License-Identifier: Apache-2.0
+# Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+# Copyright (C) 2023 Nordix Foundation.
+
+"""Client of the bridge services, like pkg/client/client.go."""
+
+import time
+
+import grpc
+
+import l2_xpu_infra_mgr_pb2 as l2
+import l2_xpu_infra_mgr_pb2_grpc as l2_grpc
+import l3_xpu_infra_mgr_pb2 as l3
+import l3_xpu_infra_mgr_pb2_grpc as l3_grpc
+
+from .errors import NotFoundError, new_error
+from .names import (
+    COLLECTION_BRIDGES,
+    COLLECTION_PORTS,
+    COLLECTION_SVIS,
+    COLLECTION_VRFS,
+    full_name,
+)
+
+DEFAULT_RETRIES = 3
+DEFAULT_BACKOFF = 0.2
+DEFAULT_POLL_INTERVAL = 0.5
+LIST_PAGE_SIZE = 50
+
+
+class _Kind:
+    """The messages and calls of a kind of object."""
+
+    def __init__(self, collection, pb, stub, message, plural, field, up):
+        self.collection = collection
+        self.pb = pb
+        self.stub = stub
+        self.message = message
+        self.plural = plural
+        # field is the name of the object in the requests, e.g. logical_bridge
+        self.field = field
+        self.up = up
+
+
+_KINDS = {
+    COLLECTION_VRFS: _Kind(
+        COLLECTION_VRFS, l3, l3_grpc.VrfServiceStub, "Vrf", "Vrfs", "vrf",
+        l3.VRF_OPER_STATUS_UP,
+    ),
+    COLLECTION_BRIDGES: _Kind(
+        COLLECTION_BRIDGES, l2, l2_grpc.LogicalBridgeServiceStub, "LogicalBridge",
+        "LogicalBridges", "logical_bridge", l2.LB_OPER_STATUS_UP,
+    ),
+    COLLECTION_SVIS: _Kind(
+        COLLECTION_SVIS, l3, l3_grpc.SviServiceStub, "Svi", "Svis", "svi",
+        l3.SVI_OPER_STATUS_UP,
+    ),
+    COLLECTION_PORTS: _Kind(
+        COLLECTION_PORTS, l2, l2_grpc.BridgePortServiceStub, "BridgePort",
+        "BridgePorts", "bridge_port", l2.BP_OPER_STATUS_UP,
+    ),
+}
+
+
+class Client:
+    """Calls the services of the bridge.
+
+    The calls which fail with UNAVAILABLE, i.e. which have not reached the
+    bridge, are retried with an exponential backoff. RESOURCE_EXHAUSTED is not
+    retried as the bridge answers it when a quota is reached.
+    """
+
+    def __init__(self, channel, retries=DEFAULT_RETRIES, backoff=DEFAULT_BACKOFF,
+                 poll_interval=DEFAULT_POLL_INTERVAL):
+        self._stubs = {c: k.stub(channel) for c, k in _KINDS.items()}
+        self.retries = retries
+        self.backoff = backoff
+        self.poll_interval = poll_interval
+
+    def _call(self, op, name, fn, request, timeout):
+        backoff = self.backoff
+        attempt = 0
+        while True:
+            try:
+                return fn(request, timeout=timeout)
+            except grpc.RpcError as err:
+                if err.code() != grpc.StatusCode.UNAVAILABLE or attempt >= self.retries:
+                    raise new_error(op, name, err) from err
+            attempt += 1
+            time.sleep(backoff)
+            backoff *= 2
+
+    def create(self, collection, object_id, spec, timeout=None):
+        """Create the object of the collection with the spec."""
+        kind = _KINDS[collection]
+        obj = getattr(kind.pb, kind.message)(spec=spec)
+        request = getattr(kind.pb, "Create%sRequest" % kind.message)(
+            **{kind.field: obj, kind.field + "_id": object_id}
+        )
+        fn = getattr(self._stubs[collection], "Create%s" % kind.message)
+        return self._call("Create%s" % kind.message, object_id, fn, request, timeout)
+
+    def get(self, collection, object_id, timeout=None):
+        """Get the object of the collection by its id or full name."""
+        kind = _KINDS[collection]
+        name = full_name(collection, object_id)
+        request = getattr(kind.pb, "Get%sRequest" % kind.message)(name=name)
+        fn = getattr(self._stubs[collection], "Get%s" % kind.message)
+        return self._call("Get%s" % kind.message, name, fn, request, timeout)
+
+    def list(self, collection, timeout=None):
+        """List all the objects of the collection, following the pages."""
+        kind = _KINDS[collection]
+        fn = getattr(self._stubs[collection], "List%s" % kind.plural)
+        objects = []
+        page_token = ""
+        while True:
+            request = getattr(kind.pb, "List%sRequest" % kind.plural)(
+                page_size=LIST_PAGE_SIZE, page_token=page_token
+            )
+            response = self._call("List%s" % kind.plural, "", fn, request, timeout)
+            objects.extend(getattr(response, kind.field + "s"))
+            page_token = response.next_page_token
+            if not page_token:
+                return objects
+
+    def update(self, collection, obj, update_mask=None, timeout=None):
+        """Update the object, the whole spec unless the mask is given."""
+        kind = _KINDS[collection]
+        request = getattr(kind.pb, "Update%sRequest" % kind.message)(
+            **{kind.field: obj}
+        )
+        if update_mask is not None:
+            request.update_mask.paths.extend(update_mask)
+        fn = getattr(self._stubs[collection], "Update%s" % kind.message)
+        return self._call("Update%s" % kind.message, obj.name, fn, request, timeout)
+
+    def delete(self, collection, object_id, allow_missing=False, timeout=None):
+        """Delete the object of the collection by its id or full name."""
+        kind = _KINDS[collection]
+        name = full_name(collection, object_id)
+        request = getattr(kind.pb, "Delete%sRequest" % kind.message)(
+            name=name, allow_missing=allow_missing
+        )
+        fn = getattr(self._stubs[collection], "Delete%s" % kind.message)
+        self._call("Delete%s" % kind.message, name, fn, request, timeout)
+
+    def wait_ready(self, collection, object_id, timeout=60):
+        """Wait until the object is operationally up and return it."""
+        kind = _KINDS[collection]
+        deadline = time.monotonic() + timeout
+        while True:
+            obj = self.get(collection, object_id)
+            if obj.status.oper_status == kind.up:
+                return obj
+            self._sleep_until(deadline, "%s is not up" % full_name(collection, object_id))
+
+    def wait_deleted(self, collection, object_id, timeout=60):
+        """Wait until the object is gone, e.g. after a deletion the bridge completes asynchronously."""
+        deadline = time.monotonic() + timeout
+        while True:
+            try:
+                self.get(collection, object_id)
+            except NotFoundError:
+                return
+            self._sleep_until(deadline, "%s is not deleted" % full_name(collection, object_id))
+
+    def _sleep_until(self, deadline, message):
+        if time.monotonic() + self.poll_interval > deadline:
+            raise TimeoutError(message)
+        time.sleep(self.poll_interval)
+
+    # Typed shorthands of the calls of the collections
+
+    def create_vrf(self, object_id, spec, timeout=None):
+        return self.create(COLLECTION_VRFS, object_id, spec, timeout)
+
+    def get_vrf(self, object_id, timeout=None):
+        return self.get(COLLECTION_VRFS, object_id, timeout)
+
+    def list_vrfs(self, timeout=None):
+        return self.list(COLLECTION_VRFS, timeout)
+
+    def delete_vrf(self, object_id, allow_missing=False, timeout=None):
+        self.delete(COLLECTION_VRFS, object_id, allow_missing, timeout)
+
+    def create_logical_bridge(self, object_id, spec, timeout=None):
+        return self.create(COLLECTION_BRIDGES, object_id, spec, timeout)
+
+    def get_logical_bridge(self, object_id, timeout=None):
+        return self.get(COLLECTION_BRIDGES, object_id, timeout)
+
+    def list_logical_bridges(self, timeout=None):
+        return self.list(COLLECTION_BRIDGES, timeout)
+
+    def delete_logical_bridge(self, object_id, allow_missing=False, timeout=None):
+        self.delete(COLLECTION_BRIDGES, object_id, allow_missing, timeout)
+
+    def create_svi(self, object_id, spec, timeout=None):
+        return self.create(COLLECTION_SVIS, object_id, spec, timeout)
+
+    def get_svi(self, object_id, timeout=None):
+        return self.get(COLLECTION_SVIS, object_id, timeout)
+
+    def list_svis(self, timeout=None):
+        return self.list(COLLECTION_SVIS, timeout)
+
+    def delete_svi(self, object_id, allow_missing=False, timeout=None):
+        self.delete(COLLECTION_SVIS, object_id, allow_missing, timeout)
+
+    def create_bridge_port(self, object_id, spec, timeout=None):
+        return self.create(COLLECTION_PORTS, object_id, spec, timeout)
+
+    def get_bridge_port(self, object_id, timeout=None):
+        return self.get(COLLECTION_PORTS, object_id, timeout)
+
+    def list_bridge_ports(self, timeout=None):
+        return self.list(COLLECTION_PORTS, timeout)
+
+    def delete_bridge_port(self, object_id, allow_missing=False, timeout=None):
+        self.delete(COLLECTION_PORTS, object_id, allow_missing, timeout)
@@ -0,0 +1,73 @@
+# SPDX-License-Identifier: Apache-2.0
+# Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+# Copyright (C) 2023 Nordix Foundation.
+
+"""Typed errors of the bridge calls, like pkg/client/errors.go."""
+
+import grpc
+
+
+class BridgeError(Exception):
+    """A call to the bridge failed with the gRPC status code."""
+
+    code = None
+
+    def __init__(self, op, name, code, message):
+        self.op = op
+        self.name = name
+        self.code = code
+        self.message = message
+        super().__init__(str(self))
+
+    def __str__(self):
+        if self.name:
+            return "%s %s: %s: %s" % (self.op, self.name, self.code.name, self.message)
+        return "%s: %s: %s" % (self.op, self.code.name, self.message)
+
+
+class NotFoundError(BridgeError):
+    code = grpc.StatusCode.NOT_FOUND
+
+
+class AlreadyExistsError(BridgeError):
+    code = grpc.StatusCode.ALREADY_EXISTS
+
+
+class InvalidArgumentError(BridgeError):
+    code = grpc.StatusCode.INVALID_ARGUMENT
+
+
+class FailedPreconditionError(BridgeError):
+    code = grpc.StatusCode.FAILED_PRECONDITION
+
+
+class ResourceExhaustedError(BridgeError):
+    code = grpc.StatusCode.RESOURCE_EXHAUSTED
+
+
+class UnavailableError(BridgeError):
+    code = grpc.StatusCode.UNAVAILABLE
+
+
+class PermissionDeniedError(BridgeError):
+    code = grpc.StatusCode.PERMISSION_DENIED
+
+
+_BY_CODE = {
+    cls.code: cls
+    for cls in (
+        NotFoundError,
+        AlreadyExistsError,
+        InvalidArgumentError,
+        FailedPreconditionError,
+        ResourceExhaustedError,
+        UnavailableError,
+        PermissionDeniedError,
+    )
+}
+
+
+def new_error(op, name, err):
+    """Wrap the grpc.RpcError of a call in the error class of its status code."""
+    code = err.code()
+    return _BY_CODE.get(code, BridgeError)(op, name, code, err.details())
@@ -0,0 +1,42 @@
+# SPDX-License-Identifier: Apache-2.0
+# Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+# Copyright (C) 2023 Nordix Foundation.
+
+"""Resource names of the bridge objects, like pkg/client/names.go."""
+
+import re
+
+SERVICE_NAME = "//network.opiproject.org/"
+
+COLLECTION_VRFS = "vrfs"
+COLLECTION_BRIDGES = "bridges"
+COLLECTION_SVIS = "svis"
+COLLECTION_PORTS = "ports"
+
+_ID = re.compile(r"^[a-zA-Z0-9][a-zA-Z0-9._~-]*$")
+
+
+def full_name(collection, object_id):
+    """Return the full name of the object, full names are returned unchanged."""
+    if object_id.startswith("//"):
+        return object_id
+    return SERVICE_NAME + collection + "/" + object_id
+
+
+def parse_name(name):
+    """Split a full name into its collection and id, raise ValueError when invalid."""
+    if not name.startswith(SERVICE_NAME):
+        raise ValueError("name %r is not in %s" % (name, SERVICE_NAME))
+    collection, _, object_id = name[len(SERVICE_NAME):].partition("/")
+    if not collection or not object_id or "/" in object_id:
+        raise ValueError(
+            "name %r is not of the form %s<collection>/<id>" % (name, SERVICE_NAME)
+        )
+    if not _ID.match(collection) or not _ID.match(object_id):
+        raise ValueError("invalid name %r" % name)
+    return collection, object_id
+
+
+def short_id(name):
+    """Return the id of the object, i.e. the last segment of its name."""
+    return name.rsplit("/", 1)[-1]
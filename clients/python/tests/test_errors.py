@@ -0,0 +1,70 @@
+# SPDX-License-Identifier: Apache-2.0
+# Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+# Copyright (C) 2023 Nordix Foundation.
+
+"""Tests of the typed errors of the bridge calls."""
+
+import grpc
+import pytest
+
+from opi_evpn_bridge.errors import (
+    AlreadyExistsError,
+    BridgeError,
+    FailedPreconditionError,
+    InvalidArgumentError,
+    NotFoundError,
+    PermissionDeniedError,
+    ResourceExhaustedError,
+    UnavailableError,
+    new_error,
+)
+
+BLUE = "//network.opiproject.org/vrfs/blue"
+
+
+class FakeRpcError(grpc.RpcError):
+    """An RpcError of a call with its status code and details."""
+
+    def __init__(self, code, details):
+        super().__init__()
+        self._code = code
+        self._details = details
+
+    def code(self):
+        return self._code
+
+    def details(self):
+        return self._details
+
+
+@pytest.mark.parametrize(
+    "code, cls",
+    [
+        (grpc.StatusCode.NOT_FOUND, NotFoundError),
+        (grpc.StatusCode.ALREADY_EXISTS, AlreadyExistsError),
+        (grpc.StatusCode.INVALID_ARGUMENT, InvalidArgumentError),
+        (grpc.StatusCode.FAILED_PRECONDITION, FailedPreconditionError),
+        (grpc.StatusCode.RESOURCE_EXHAUSTED, ResourceExhaustedError),
+        (grpc.StatusCode.UNAVAILABLE, UnavailableError),
+        (grpc.StatusCode.PERMISSION_DENIED, PermissionDeniedError),
+        # the other codes are raised as the base error
+        (grpc.StatusCode.INTERNAL, BridgeError),
+    ],
+)
+def test_new_error(code, cls):
+    err = new_error("GetVrf", BLUE, FakeRpcError(code, "details"))
+    assert type(err) is cls
+    assert isinstance(err, BridgeError)
+    assert err.code == code
+    assert err.op == "GetVrf"
+    assert err.name == BLUE
+    assert err.message == "details"
+
+
+def test_error_message():
+    not_found = FakeRpcError(grpc.StatusCode.NOT_FOUND, "unable to find key")
+    err = new_error("GetVrf", BLUE, not_found)
+    assert str(err) == "GetVrf " + BLUE + ": NOT_FOUND: unable to find key"
+    unavailable = FakeRpcError(grpc.StatusCode.UNAVAILABLE, "connection refused")
+    err = new_error("ListVrfs", "", unavailable)
+    assert str(err) == "ListVrfs: UNAVAILABLE: connection refused"
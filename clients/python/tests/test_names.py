@@ -0,0 +1,62 @@
+# SPDX-License-Identifier: Apache-2.0
+# Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+# Copyright (C) 2023 Nordix Foundation.
+
+"""Tests of the resource names of the bridge objects."""
+
+import pytest
+
+from opi_evpn_bridge.names import (
+    COLLECTION_SVIS,
+    COLLECTION_VRFS,
+    full_name,
+    parse_name,
+    short_id,
+)
+
+RED = "//network.opiproject.org/vrfs/red"
+
+
+@pytest.mark.parametrize(
+    "collection, object_id, expected",
+    [
+        (COLLECTION_VRFS, "blue", "//network.opiproject.org/vrfs/blue"),
+        (COLLECTION_SVIS, "blue-10", "//network.opiproject.org/svis/blue-10"),
+        # full names are returned unchanged
+        (COLLECTION_VRFS, RED, RED),
+    ],
+)
+def test_full_name(collection, object_id, expected):
+    assert full_name(collection, object_id) == expected
+
+
+def test_parse_name():
+    assert parse_name("//network.opiproject.org/vrfs/blue") == (COLLECTION_VRFS, "blue")
+
+
+@pytest.mark.parametrize(
+    "name",
+    [
+        "blue",
+        "//other.org/vrfs/blue",
+        "//network.opiproject.org/vrfs",
+        "//network.opiproject.org/vrfs/",
+        "//network.opiproject.org/vrfs/blue/extra",
+        "//network.opiproject.org/vrfs/-blue",
+        "//network.opiproject.org/vrfs/bl ue",
+    ],
+)
+def test_parse_invalid_name(name):
+    with pytest.raises(ValueError):
+        parse_name(name)
+
+
+@pytest.mark.parametrize(
+    "name, expected",
+    [
+        ("//network.opiproject.org/vrfs/blue", "blue"),
+        ("blue", "blue"),
+    ],
+)
+def test_short_id(name, expected):
+    assert short_id(name) == expected
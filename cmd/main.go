@@ -16,32 +16,55 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"syscall"
 	"time"
 
 	pc "github.com/opiproject/opi-api/inventory/v1/gen/go"
+	pcloud "github.com/opiproject/opi-api/network/cloud/v1alpha1/gen/go"
 	pe "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/admin"
 	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
+	"github.com/opiproject/opi-evpn-bridge/pkg/cloud"
 	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/dupaddr"
+	"github.com/opiproject/opi-evpn-bridge/pkg/fabric"
+	"github.com/opiproject/opi-evpn-bridge/pkg/health"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+	"github.com/opiproject/opi-evpn-bridge/pkg/interceptor"
+	"github.com/opiproject/opi-evpn-bridge/pkg/ipsec"
+	"github.com/opiproject/opi-evpn-bridge/pkg/lldp"
+	"github.com/opiproject/opi-evpn-bridge/pkg/logsink"
+	"github.com/opiproject/opi-evpn-bridge/pkg/macmobility"
+	"github.com/opiproject/opi-evpn-bridge/pkg/macsec"
+	"github.com/opiproject/opi-evpn-bridge/pkg/netlink"
 	"github.com/opiproject/opi-evpn-bridge/pkg/port"
+	"github.com/opiproject/opi-evpn-bridge/pkg/preflight"
+	"github.com/opiproject/opi-evpn-bridge/pkg/publisher"
+	"github.com/opiproject/opi-evpn-bridge/pkg/remote"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/schedule"
 	"github.com/opiproject/opi-evpn-bridge/pkg/svi"
+	"github.com/opiproject/opi-evpn-bridge/pkg/underlay"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	"github.com/opiproject/opi-evpn-bridge/pkg/vrf"
+	"github.com/opiproject/opi-evpn-bridge/pkg/webhook"
+	"github.com/opiproject/opi-evpn-bridge/pkg/ztp"
 	"github.com/opiproject/opi-smbios-bridge/pkg/inventory"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
-	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	ci_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxCIModule"
 	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
 	frr "github.com/opiproject/opi-evpn-bridge/pkg/frr"
+	"github.com/opiproject/opi-evpn-bridge/pkg/gobgp"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 )
 
@@ -52,42 +75,176 @@ var rootCmd = &cobra.Command{
 
 	Run: func(_ *cobra.Command, _ []string) {
 
+		// Ship the log to syslog or journald once the config is read
+		setupLogSink()
+
+		// Apply the reloadable settings on SIGHUP and config file changes
+		config.Watch()
+
 		taskmanager.TaskMan.StartTaskManager()
 
 		err := infradb.NewInfraDB(config.GlobalConfig.DBAddress, config.GlobalConfig.Database)
 		if err != nil {
 			log.Panicf("Error: %v", err)
 		}
-		go runGatewayServer(config.GlobalConfig.GRPCPort, config.GlobalConfig.HTTPPort)
+		if err := infradb.Migrate(config.GlobalConfig.DBBackupDir); err != nil {
+			log.Panicf("Error: %v", err)
+		}
+		if config.GlobalConfig.Remote.Mode != remote.ModeAgent {
+			// Send the status events to the registered webhooks
+			webhook.Start(context.Background(), &config.GlobalConfig)
+			// Mirror the events onto the message bus
+			if err := publisher.Start(context.Background(), &config.GlobalConfig); err != nil {
+				log.Panicf("Error: %v", err)
+			}
+			go runGatewayServer(config.GlobalConfig.ListenAddress, config.GlobalConfig.GRPCPort, config.GlobalConfig.HTTPPort)
+		}
 
-		switch config.GlobalConfig.Buildenv {
-		case "ci":
-			gen_linux.Initialize()
-			ci_linux.Initialize()
-			frr.Initialize()
+		routing.Register(frr.Backend{})
+		routing.Register(gobgp.Backend{})
+		backend, err := routing.Select(config.GlobalConfig.Routing.Backend)
+		if err != nil {
+			log.Panicf("Error: %v", err)
+		}
+
+		switch config.GlobalConfig.Remote.Mode {
+		case remote.ModeController:
+			// The node agents realize the objects, the controller programs no node
+			controller = remote.StartController(context.Background(), &config.GlobalConfig)
+		case remote.ModeAgent:
+			startNode(backend)
+			runAgent()
+			return
 		default:
-			log.Panic(" ERROR: Could not find Build env ")
+			startNode(backend)
 		}
 
 		// Create GRD VRF configuration during startup
 		if err := createGrdVrf(); err != nil {
 			log.Panicf("Error: %v", err)
 		}
-		runGrpcServer(config.GlobalConfig.GRPCPort, config.GlobalConfig.TLSFiles)
+		// Delete the ephemeral resources once their lease expires
+		go infradb.RunLeaseSweep(context.Background())
+		if config.GlobalConfig.Ztp.Enabled {
+			go runZtp(config.GlobalConfig.ListenAddress, config.GlobalConfig.GRPCPort)
+		}
+		runGrpcServer(config.GlobalConfig.ListenAddress, config.GlobalConfig.GRPCPort, config.GlobalConfig.TLSFiles)
 
 	},
 }
 
+// controller realizes the objects on the node agents when the bridge is a controller
+var controller *remote.Controller
+
+// startNode initializes the modules and brings up the node before its objects are realized
+func startNode(backend routing.Backend) {
+	switch config.GlobalConfig.Buildenv {
+	case "ci":
+		gen_linux.Initialize()
+		ci_linux.Initialize()
+		backend.Initialize()
+	default:
+		log.Panic(" ERROR: Could not find Build env ")
+	}
+
+	// Check the host before the first object is created
+	if err := preflight.Verify(context.Background(), &config.GlobalConfig, backend); err != nil {
+		log.Panicf("Error: %v", err)
+	}
+
+	// Bring the node into the fabric before the vrfs pick their vtep address
+	nlink := utils.WithWriteQueue(utils.WithNetlinkFaults(utils.NewNetlinkWrapperWithArgs(config.GlobalConfig.Tracer)), utils.DefaultNamespace)
+	if err := underlay.Bootstrap(context.Background(), &config.GlobalConfig, nlink, backend); err != nil {
+		log.Panicf("Error: %v", err)
+	}
+	// Protect the uplinks once they are up, their addresses go on the MACsec devices
+	if err := macsec.Start(context.Background(), &config.GlobalConfig); err != nil {
+		log.Panicf("Error: %v", err)
+	}
+	if err := underlay.BootstrapMpls(context.Background(), &config.GlobalConfig, backend); err != nil {
+		log.Panicf("Error: %v", err)
+	}
+	if err := underlay.BootstrapSrv6(context.Background(), &config.GlobalConfig, backend); err != nil {
+		log.Panicf("Error: %v", err)
+	}
+
+	// Discover the neighbors of the uplinks and check their cabling
+	if err := lldp.Start(context.Background(), &config.GlobalConfig); err != nil {
+		log.Panicf("Error: %v", err)
+	}
+
+	// Probe the remote VTEPs and answer their probes
+	interceptor.RegisterMetrics(fabric.Collectors()...)
+	if err := fabric.Start(context.Background(), &config.GlobalConfig); err != nil {
+		log.Panicf("Error: %v", err)
+	}
+
+	// Encrypt the VXLAN tunnels to the remote VTEPs
+	interceptor.RegisterMetrics(ipsec.Collectors()...)
+	if err := ipsec.Start(context.Background(), &config.GlobalConfig); err != nil {
+		log.Panicf("Error: %v", err)
+	}
+
+	// Alert on the addresses moving back and forth between the VTEPs
+	if err := dupaddr.Start(context.Background(), &config.GlobalConfig, backend); err != nil {
+		log.Panicf("Error: %v", err)
+	}
+
+	// Count the moves of the MAC addresses of the logical bridges
+	if err := macmobility.Start(context.Background(), &config.GlobalConfig, backend); err != nil {
+		log.Panicf("Error: %v", err)
+	}
+}
+
+// runAgent realizes on the node the objects of the controller until the bridge stops
+func runAgent() {
+	checker := newHealthChecker()
+	serving := func(ctx context.Context) bool {
+		resp, err := checker.Check(ctx, &healthpb.HealthCheckRequest{})
+		return err == nil && resp.GetStatus() == healthpb.HealthCheckResponse_SERVING
+	}
+	opts, err := agentDialOptions(&config.GlobalConfig.Remote)
+	if err != nil {
+		log.Panicf("Error: %v", err)
+	}
+	agent := remote.NewAgent(&config.GlobalConfig, opts, serving)
+	if err := agent.Run(context.Background()); err != nil {
+		log.Panicf("Error: %v", err)
+	}
+}
+
+// restoreDBCmd writes back a backup of the store taken before a migration, e.g. before downgrading the bridge
+var restoreDBCmd = &cobra.Command{
+	Use:   "restore-db <backup>",
+	Short: "restore a backup of the store",
+	Long:  "restore a backup of the store taken before a schema migration, the bridge must be stopped",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		if err := infradb.NewInfraDB(config.GlobalConfig.DBAddress, config.GlobalConfig.Database); err != nil {
+			return err
+		}
+		defer func() { _ = infradb.Close() }()
+		if err := infradb.RestoreBackup(args[0]); err != nil {
+			return err
+		}
+		log.Printf("Restored the store from %s", args[0])
+		return nil
+	},
+}
+
 // initialize the cobra configuration and bind the flags
 func initialize() error {
 	cobra.OnInitialize(config.Initcfg)
 
 	rootCmd.PersistentFlags().StringVarP(&config.GlobalConfig.CfgFile, "config", "c", "config.yaml", "config file path")
+	rootCmd.PersistentFlags().StringVar(&config.GlobalConfig.ListenAddress, "listenaddress", "", "The address of the gRPC and HTTP servers, all addresses when empty")
 	rootCmd.PersistentFlags().Uint16Var(&config.GlobalConfig.GRPCPort, "grpcport", 50151, "The gRPC server port")
 	rootCmd.PersistentFlags().Uint16Var(&config.GlobalConfig.HTTPPort, "httpport", 8082, "The HTTP server port")
 	rootCmd.PersistentFlags().StringVar(&config.GlobalConfig.TLSFiles, "tlsfiles", "", "TLS files in server_cert:server_key:ca_cert format.")
 	rootCmd.PersistentFlags().StringVar(&config.GlobalConfig.DBAddress, "dbaddress", "127.0.0.1:6379", "db address in ip_address:port format")
 	rootCmd.PersistentFlags().StringVar(&config.GlobalConfig.Database, "database", "redis", "Database connection string")
+	rootCmd.PersistentFlags().StringVar(&config.GlobalConfig.DBBackupDir, "dbbackupdir", "/var/lib/opi-evpn-bridge/backups", "The directory of the backups of the store taken before its migrations")
+	rootCmd.AddCommand(restoreDBCmd)
 
 	// Bind command-line flags to config fields
 	if err := viper.GetViper().BindPFlags(rootCmd.PersistentFlags()); err != nil {
@@ -114,8 +271,26 @@ func setupLogger(filename string) {
 	log.SetOutput(logger.Writer())
 }
 
+// setupLogSink adds the configured syslog or journald sink to the outputs of the logger
+func setupLogSink() {
+	sink, err := logsink.New(&config.GlobalConfig.LogSink)
+	if err != nil {
+		log.Panicf("Error: %v", err)
+	}
+	if sink != nil {
+		log.SetOutput(io.MultiWriter(logger.Writer(), sink))
+	}
+}
+
 func cleanUp() {
 	log.Println("Defer function called")
+	if config.GlobalConfig.Remote.Mode == remote.ModeController {
+		// the objects outlive the controller, the node agents keep them realized
+		if err := infradb.Close(); err != nil {
+			log.Println("Failed to close infradb")
+		}
+		return
+	}
 	if err := infradb.DeleteAllResources(); err != nil {
 		log.Println("Failed to delete all the resources: ", err)
 	}
@@ -123,7 +298,9 @@ func cleanUp() {
 	case "ci":
 		gen_linux.DeInitialize()
 		ci_linux.DeInitialize()
-		frr.DeInitialize()
+		if backend, err := routing.Get(); err == nil {
+			backend.DeInitialize()
+		}
 	default:
 		log.Panic(" ERROR: Could not find Build env ")
 	}
@@ -177,7 +354,7 @@ func main() {
 }
 
 // runGrpcServer start the grpc server for all the components
-func runGrpcServer(grpcPort uint16, tlsFiles string) {
+func runGrpcServer(listenAddress string, grpcPort uint16, tlsFiles string) {
 	if config.GlobalConfig.Tracer {
 		tp := utils.InitTracerProvider("opi-evpn-bridge")
 		defer func() {
@@ -187,7 +364,7 @@ func runGrpcServer(grpcPort uint16, tlsFiles string) {
 		}()
 	}
 
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", grpcPort))
+	lis, err := listen(listenAddress, grpcPort)
 	if err != nil {
 		log.Panicf("failed to listen: %v", err)
 	}
@@ -209,17 +386,13 @@ func runGrpcServer(grpcPort uint16, tlsFiles string) {
 		serverOptions = append(serverOptions, option)
 	}
 
+	chain, err := interceptor.Chain(config.GlobalConfig.Interceptors.Chain)
+	if err != nil {
+		log.Panicf("failed to build the interceptor chain: %v", err)
+	}
 	serverOptions = append(serverOptions,
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
-		grpc.UnaryInterceptor(
-			logging.UnaryServerInterceptor(utils.InterceptorLogger(log.Default()),
-				logging.WithLogOnEvents(
-					logging.StartCall,
-					logging.FinishCall,
-					logging.PayloadReceived,
-					logging.PayloadSent,
-				),
-			)),
+		grpc.ChainUnaryInterceptor(chain...),
 	)
 	s := grpc.NewServer(serverOptions...)
 
@@ -231,7 +404,13 @@ func runGrpcServer(grpcPort uint16, tlsFiles string) {
 	pe.RegisterBridgePortServiceServer(s, portServer)
 	pe.RegisterVrfServiceServer(s, vrfServer)
 	pe.RegisterSviServiceServer(s, sviServer)
+	// the v1alpha1 cloud API is served by translating into the EVPN services
+	pcloud.RegisterCloudInfraServiceServer(s, cloud.NewServer(vrfServer, bridgeServer, sviServer))
 	pc.RegisterInventoryServiceServer(s, &inventory.Server{})
+	healthpb.RegisterHealthServer(s, newHealthChecker())
+	if controller != nil {
+		remote.RegisterService(s, controller)
+	}
 
 	reflection.Register(s)
 
@@ -241,8 +420,47 @@ func runGrpcServer(grpcPort uint16, tlsFiles string) {
 	}
 }
 
+// healthInterval is the interval of the periodic health probes
+const healthInterval = 10 * time.Second
+
+// newHealthChecker starts the periodic probes of the subsystems served by the health service
+func newHealthChecker() *health.Checker {
+	checker := health.NewChecker(healthInterval)
+	checker.AddProbe("store", func(context.Context) error { return infradb.Ping() })
+	checker.AddIndicator("writes", readOnlyProbe)
+	if controller != nil {
+		// the controller programs no node, the agents report their own health
+		checker.AddProbe("agents", controller.Probe)
+		go checker.Run(context.Background())
+		return checker
+	}
+	checker.AddProbe("netlink", netlink.Probe)
+	if backend, err := routing.Get(); err == nil {
+		checker.AddProbe(backend.Name(), backend.Probe)
+		checker.AddDeepProbe(backend.Name(), backend.DeepProbe)
+	}
+	checker.AddDeepProbe("dataplane", gen_linux.DeepProbe)
+	if len(config.GlobalConfig.Lldp.Expected) != 0 {
+		checker.AddProbe("cabling", lldp.Probe)
+	}
+	go checker.Run(context.Background())
+	return checker
+}
+
+// readOnlyProbe fails while the bridge is read-only, the writes being refused
+func readOnlyProbe(context.Context) error {
+	mode, err := infradb.GetReadOnlyMode()
+	if err != nil {
+		return err
+	}
+	if mode.Enabled {
+		return fmt.Errorf("read-only since %v: %s", mode.Since, mode.Reason)
+	}
+	return nil
+}
+
 // runGatewayServer
-func runGatewayServer(grpcPort uint16, httpPort uint16) {
+func runGatewayServer(listenAddress string, grpcPort uint16, httpPort uint16) {
 	ctx := context.Background()
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -250,28 +468,109 @@ func runGatewayServer(grpcPort uint16, httpPort uint16) {
 	// Register gRPC server endpoint
 	// Note: Make sure the gRPC server is running properly and accessible
 	mux := runtime.NewServeMux()
-	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	opts := dialOptions()
+	grpcAddress := net.JoinHostPort(listenAddress, strconv.Itoa(int(grpcPort)))
 
 	// TODO: add/replace with more/less registrations, once opi-api compiler fixed
-	err := pc.RegisterInventoryServiceHandlerFromEndpoint(ctx, mux, fmt.Sprintf(":%d", grpcPort), opts)
+	err := pc.RegisterInventoryServiceHandlerFromEndpoint(ctx, mux, grpcAddress, opts)
 	if err != nil {
 		log.Panic("cannot register handler server")
 	}
 
+	// Register the operational endpoints not covered by opi-api
+	if err := admin.RegisterHandlers(mux); err != nil {
+		log.Panic("cannot register admin handlers")
+	}
+	conn, err := grpc.Dial(grpcAddress, opts...)
+	if err != nil {
+		log.Panic("cannot connect to the gRPC server")
+	}
+	defer conn.Close()
+	if err := admin.RegisterApplyHandler(mux, conn); err != nil {
+		log.Panic("cannot register apply handler")
+	}
+	if err := admin.RegisterRollbackHandler(mux, conn); err != nil {
+		log.Panic("cannot register rollback handler")
+	}
+	// Apply the scheduled changes through the gRPC API, as the apply endpoint does
+	go schedule.Run(ctx, conn)
+	if err := mux.HandlePath(http.MethodGet, "/metrics", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		interceptor.MetricsHandler().ServeHTTP(w, r)
+	}); err != nil {
+		log.Panic("cannot register metrics handler")
+	}
+
 	// Start HTTP server (and proxy calls to gRPC server endpoint)
+	lis, err := listen(listenAddress, httpPort)
+	if err != nil {
+		log.Panicf("failed to listen: %v", err)
+	}
 	log.Printf("HTTP Server listening at %v", httpPort)
 	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", httpPort),
 		Handler:      mux,
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
-	err = server.ListenAndServe()
+	err = server.Serve(lis)
 	if err != nil {
 		log.Panic("cannot start HTTP gateway server")
 	}
 }
 
+// dialOptions returns the options of the connections of the bridge to its own gRPC server
+func dialOptions() []grpc.DialOption {
+	return append(vrfDialOptions(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+// vrfDialOptions dials in the management vrf when there is one
+func vrfDialOptions() []grpc.DialOption {
+	var opts []grpc.DialOption
+	if vrf := config.GlobalConfig.Management.Vrf; vrf != "" {
+		// the gRPC server listens in the management vrf
+		dialer := net.Dialer{Control: utils.BindToDevice(vrf)}
+		opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", address)
+		}))
+	}
+	return opts
+}
+
+// agentDialOptions returns the options of the agent dialing the controller, over mutual TLS unless insecure
+func agentDialOptions(rc *config.RemoteConfig) ([]grpc.DialOption, error) {
+	if rc.Insecure {
+		log.Println("The agent dials the controller without TLS.")
+		return dialOptions(), nil
+	}
+	creds, err := utils.SetupTLSClientCredentials(utils.TLSConfig{ServerCertPath: rc.Cert, ServerKeyPath: rc.Key, CaCertPath: rc.Ca})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the TLS of the agent: %w", err)
+	}
+	return append(vrfDialOptions(), creds), nil
+}
+
+// runZtp provisions the bridge from the bundle of the provisioning server once its gRPC server is up
+func runZtp(listenAddress string, grpcPort uint16) {
+	opts := append(dialOptions(), grpc.WithDefaultCallOptions(grpc.WaitForReady(true)))
+	conn, err := grpc.Dial(net.JoinHostPort(listenAddress, strconv.Itoa(int(grpcPort))), opts...)
+	if err != nil {
+		log.Printf("ztp: cannot connect to the gRPC server: %v", err)
+		return
+	}
+	defer conn.Close()
+	if err := ztp.Run(context.Background(), &config.GlobalConfig, conn); err != nil {
+		log.Printf("Error: %v", err)
+	}
+}
+
+// listen opens the listening socket of a server, in the management vrf when there is one
+func listen(listenAddress string, port uint16) (net.Listener, error) {
+	lc := net.ListenConfig{}
+	if vrf := config.GlobalConfig.Management.Vrf; vrf != "" {
+		lc.Control = utils.BindToDevice(vrf)
+	}
+	return lc.Listen(context.Background(), "tcp", net.JoinHostPort(listenAddress, strconv.Itoa(int(port))))
+}
+
 // createGrdVrf creates the grd vrf with vni 0
 func createGrdVrf() error {
 	grdVrf, err := infradb.NewVrfWithArgs("//network.opiproject.org/vrfs/GRD", nil, nil, nil)
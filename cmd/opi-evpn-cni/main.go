@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package main is the CNI plugin attaching pods to the logical bridges of the evpn bridge
+package main
+
+import (
+	"github.com/containernetworking/cni/pkg/skel"
+	"github.com/containernetworking/cni/pkg/version"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/cni"
+)
+
+// main function
+func main() {
+	skel.PluginMain(cni.CmdAdd, cni.CmdCheck, cni.CmdDel, version.All, "opi-evpn-cni attaches pods to evpn logical bridges")
+}
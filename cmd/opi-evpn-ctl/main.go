@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package main is the command line client of the bridge
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/ctl"
+)
+
+func main() {
+	if err := ctl.NewRootCommand().Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package main is the kubernetes operator reconciling the custom resources against the bridge
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/operator"
+	"github.com/opiproject/opi-evpn-bridge/pkg/operator/api/v1alpha1"
+)
+
+// options of the operator
+var (
+	bridgeAddress  string
+	metricsAddress string
+	probeAddress   string
+	leaderElect    bool
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "opi-evpn-operator",
+	Short: "evpn bridge operator",
+	Long:  "kubernetes operator reconciling the evpn bridge custom resources against the bridge gRPC API",
+
+	RunE: func(_ *cobra.Command, _ []string) error {
+		ctrl.SetLogger(zap.New())
+
+		scheme := runtime.NewScheme()
+		if err := clientgoscheme.AddToScheme(scheme); err != nil {
+			return err
+		}
+		if err := v1alpha1.AddToScheme(scheme); err != nil {
+			return err
+		}
+
+		conn, err := grpc.Dial(bridgeAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := conn.Close(); err != nil {
+				log.Printf("Failed to close the connection to the bridge: %v", err)
+			}
+		}()
+
+		mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+			Scheme:                 scheme,
+			Metrics:                metricsserver.Options{BindAddress: metricsAddress},
+			HealthProbeBindAddress: probeAddress,
+			LeaderElection:         leaderElect,
+			LeaderElectionID:       "opi-evpn-operator.evpn.opiproject.org",
+		})
+		if err != nil {
+			return err
+		}
+		if err := operator.SetupWithManager(mgr, conn); err != nil {
+			return err
+		}
+		if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+			return err
+		}
+		if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+			return err
+		}
+		return mgr.Start(ctrl.SetupSignalHandler())
+	},
+}
+
+// main function
+func main() {
+	rootCmd.PersistentFlags().StringVar(&bridgeAddress, "bridge-address", "localhost:50151", "The gRPC address of the evpn bridge")
+	rootCmd.PersistentFlags().StringVar(&metricsAddress, "metrics-bind-address", ":8080", "The address the metrics endpoint binds to")
+	rootCmd.PersistentFlags().StringVar(&probeAddress, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to")
+	rootCmd.PersistentFlags().BoolVar(&leaderElect, "leader-elect", false, "Enable leader election for the operator")
+
+	if err := rootCmd.Execute(); err != nil {
+		log.Printf("Error in Execute(): %v", err)
+		os.Exit(1)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/lldp"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	// "gopkg.in/yaml.v2"
 )
@@ -34,6 +35,9 @@ func (h *ModulelciHandler) HandleEvent(eventType string, objectData *eventbus.Ob
 	case "bridge-port":
 		log.Printf("LCI recevied %s %s\n", eventType, objectData.Name)
 		handlebp(objectData)
+	case "virtual-port":
+		log.Printf("LCI recevied %s %s\n", eventType, objectData.Name)
+		handleVirtualPort(objectData)
 	default:
 		log.Printf("LCI: error: Unknown event type %s", eventType)
 	}
@@ -129,18 +133,28 @@ func handlebp(objectData *eventbus.ObjectData) {
 
 // setUpBp sets up the bridge port
 func setUpBp(bp *infradb.BridgePort) (string, bool) {
+	if err := lldp.Admitted(); err != nil {
+		log.Printf("LCI: tenant traffic is not admitted: %v\n", err)
+		return fmt.Sprintf("LCI: tenant traffic is not admitted: %v\n", err), false
+	}
 	resourceID := path.Base(bp.Name)
-	bridge, err := nlink.LinkByName(ctx, "br-tenant")
-	if err != nil {
-		log.Printf("LCI: Unable to find key br-tenant\n")
-		return fmt.Sprintf("LCI: Unable to find key br-tenant, %s", err), false
+	if vport, err := infradb.GetVirtualPortByLink(resourceID); err == nil {
+		if bp.Spec.Sflow != nil {
+			log.Printf("LCI: sFlow sampling is not supported on the virtual port %s\n", vport.Name)
+			return fmt.Sprintf("LCI: sFlow sampling is not supported on the virtual port %s\n", vport.Name), false
+		}
+		if bp.Spec.Qinq != nil {
+			log.Printf("LCI: QinQ is not supported on the virtual port %s\n", vport.Name)
+			return fmt.Sprintf("LCI: QinQ is not supported on the virtual port %s\n", vport.Name), false
+		}
+		return setUpVirtualBp(vport, bp)
 	}
 	iface, err := nlink.LinkByName(ctx, resourceID)
 	if err != nil {
 		log.Printf("LCI: Unable to find key %s\n", resourceID)
 		return fmt.Sprintf("LCI: Unable to find key %s\n", resourceID), false
 	}
-	if err := nlink.LinkSetMaster(ctx, iface, bridge); err != nil {
+	if err := topology.AddPort(ctx, iface); err != nil {
 		log.Printf("LCI: Failed to add iface to bridge: %v", err)
 		return fmt.Sprintf("LCI: Failed to add iface to bridge: %v", err), false
 	}
@@ -157,16 +171,16 @@ func setUpBp(bp *infradb.BridgePort) (string, bool) {
 		//TODO: Update opi-api to change vlanid to int16 in LogiclaBridge "https://linter.aip.dev/141/forbidden-types"
 		vid := uint16(BrObj.Spec.VlanID)
 		switch bp.Spec.Ptype {
-		case infradb.Access:
-			if err := nlink.BridgeVlanAdd(ctx, iface, vid, true, true, false, false); err != nil {
+		case infradb.Access, infradb.Trunk:
+			// Example: bridge vlan add dev eth2 vid 20 [pvid untagged]
+			if err := topology.AttachPort(ctx, iface, vid, bp.Spec.Ptype == infradb.Access); err != nil {
 				log.Printf("Failed to add vlan to bridge: %v", err)
 				return fmt.Sprintf("Failed to add vlan to bridge: %v", err), false
 			}
-		case infradb.Trunk:
-			// Example: bridge vlan add dev eth2 vid 20
-			if err := nlink.BridgeVlanAdd(ctx, iface, vid, false, false, false, false); err != nil {
-				log.Printf("Failed to add vlan to bridge: %v", err)
-				return fmt.Sprintf("Failed to add vlan to bridge: %v", err), false
+			// Example: bridge link set dev eth2 isolated on
+			if err := topology.IsolatePort(ctx, iface, vid, bp.Spec.Ptype == infradb.Access, bp.Spec.IsolatedIn(BrObj.Spec)); err != nil {
+				log.Printf("LCI: Failed to set the isolation of the port: %v", err)
+				return fmt.Sprintf("LCI: Failed to set the isolation of the port: %v", err), false
 			}
 		default:
 			log.Printf("Only ACCESS or TRUNK supported and not (%d)", bp.Spec.Ptype)
@@ -177,17 +191,33 @@ func setUpBp(bp *infradb.BridgePort) (string, bool) {
 		log.Printf("Failed to up iface link: %v", err)
 		return fmt.Sprintf("Failed to up iface link: %v", err), false
 	}
+	if err := setUpSflow(resourceID, iface.Attrs().Index, bp.Spec.Sflow); err != nil {
+		log.Printf("LCI: Failed to set up the sFlow sampling: %v", err)
+		return fmt.Sprintf("LCI: Failed to set up the sFlow sampling: %v", err), false
+	}
+	if err := setUpQinq(bp, iface); err != nil {
+		log.Printf("LCI: Failed to set up the QinQ mapping: %v", err)
+		return fmt.Sprintf("LCI: Failed to set up the QinQ mapping: %v", err), false
+	}
 	return "", true
 }
 
 // tearDownBp tears down a bridge port
 func tearDownBp(bp *infradb.BridgePort) (string, bool) {
 	resourceID := path.Base(bp.Name)
+	if vport, err := infradb.GetVirtualPortByLink(resourceID); err == nil {
+		return tearDownVirtualBp(vport, bp)
+	}
 	iface, err := nlink.LinkByName(ctx, resourceID)
 	if err != nil {
 		log.Printf("LCI: Unable to find key %s\n", resourceID)
 		return fmt.Sprintf("LCI: Unable to find key %s\n", resourceID), false
 	}
+	tearDownSflow(resourceID, iface.Attrs().Index)
+	if err := tearDownQinq(bp); err != nil {
+		log.Printf("LCI: Failed to tear down the QinQ mapping: %v", err)
+		return fmt.Sprintf("LCI: Failed to tear down the QinQ mapping: %v", err), false
+	}
 	if err := nlink.LinkSetDown(ctx, iface); err != nil {
 		log.Printf("LCI: Failed to down link: %v", err)
 		return fmt.Sprintf("LCI: Failed to down link: %v", err), false
@@ -204,11 +234,15 @@ func tearDownBp(bp *infradb.BridgePort) (string, bool) {
 		}
 		//TODO: Update opi-api to change vlanid to uint16 in LogiclaBridge
 		vid := uint16(BrObj.Spec.VlanID)
-		if err := nlink.BridgeVlanDel(ctx, iface, vid, true, true, false, false); err != nil {
+		if err := topology.DetachPort(ctx, iface, vid, bp.Spec.Ptype == infradb.Access); err != nil {
 			log.Printf("LCI: Failed to delete vlan to bridge: %v", err)
 			return fmt.Sprintf("LCI: Failed to delete vlan to bridge: %v", err), false
 		}
 	}
+	// The representor of a VF cannot be deleted, it goes away with the VF
+	if _, err := infradb.GetVfRepresentorByLink(resourceID); err == nil {
+		return "", true
+	}
 	if err := nlink.LinkDel(ctx, iface); err != nil {
 		log.Printf("Failed to delete link: %v", err)
 		return fmt.Sprintf("Failed to delete link: %v", err), false
@@ -219,6 +253,9 @@ func tearDownBp(bp *infradb.BridgePort) (string, bool) {
 var ctx context.Context
 var nlink utils.Netlink
 
+// topology maps the logical bridges onto linux bridges
+var topology utils.BridgeTopology
+
 // Initialize initializes the config and  subscribers
 func Initialize() {
 	eb := eventbus.EBus
@@ -230,7 +267,12 @@ func Initialize() {
 		}
 	}
 	ctx = context.Background()
-	nlink = utils.NewNetlinkWrapperWithArgs(config.GlobalConfig.Tracer)
+	nlink = utils.WithWriteQueue(utils.WithNetlinkFaults(utils.NewNetlinkWrapperWithArgs(config.GlobalConfig.Tracer)), utils.DefaultNamespace)
+	var err error
+	topology, err = utils.NewBridgeTopology(config.GlobalConfig.LinuxFrr.BridgeTopology, nlink, config.GlobalConfig.LinuxFrr.IPMtu+20)
+	if err != nil {
+		log.Fatalf("LCI: %v\n", err)
+	}
 }
 
 // DeInitialize function handles stops functionality
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxcimodule is the main package of the application
+package linuxcimodule
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// qinqLinkName returns the 802.1ad sub-interface of the S-VLAN of the port, or with a C-VLAN the 802.1Q
+// sub-interface stacked on it, the names are handed out by the interface name table of the infradb which
+// hashes the ones that do not fit in the kernel limit
+func qinqLinkName(bp *infradb.BridgePort, port string, svid, cvid uint16) (string, error) {
+	name := fmt.Sprintf("%sq%d", port, svid)
+	if cvid != 0 {
+		name = fmt.Sprintf("%s.%d", name, cvid)
+	}
+	return infradb.AllocateLinkName(bp.Name, fmt.Sprintf("%s/%d/%d", infradb.LinkRoleQinq, svid, cvid), name)
+}
+
+// qinqVlan returns the sub-interface of the tag on the parent, created and tagged as owned by the bridge
+// port unless it exists already
+func qinqVlan(name string, parent netlink.Link, vid uint16, proto netlink.VlanProtocol, owner string) (netlink.Link, error) {
+	if link, err := nlink.LinkByName(ctx, name); err == nil {
+		return link, nil
+	}
+	// Example: ip link add link eth2 name eth2q100 type vlan proto 802.1ad id 100
+	sub := &netlink.Vlan{
+		LinkAttrs:    netlink.LinkAttrs{Name: name, ParentIndex: parent.Attrs().Index},
+		VlanId:       int(vid),
+		VlanProtocol: proto,
+	}
+	if err := nlink.LinkAdd(ctx, sub); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	link, err := nlink.LinkByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := nlink.LinkSetAlias(ctx, link, utils.LinkAlias(owner)); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// setUpQinq stacks a C-VLAN sub-interface on the S-VLAN sub-interface of the port for every rule of the
+// mapping and puts it in the vlan of the logical bridge as an access port, the sub-interfaces of the rules
+// which are gone are removed
+func setUpQinq(bp *infradb.BridgePort, iface netlink.Link) error {
+	wanted := make(map[string]bool)
+	if bp.Spec.Qinq != nil {
+		for _, rule := range bp.Spec.Qinq.Rules {
+			lb, err := infradb.GetLB(rule.LogicalBridge)
+			if err != nil {
+				return fmt.Errorf("logical bridge %s of the QinQ rule %d/%d: %w", rule.LogicalBridge, rule.SVlan, rule.CVlan, err)
+			}
+			if lb.Spec.VlanID > math.MaxUint16 {
+				return fmt.Errorf("vlan id %d of the logical bridge %s is greater than 16 bit value", lb.Spec.VlanID, rule.LogicalBridge)
+			}
+			outer, err := qinqLinkName(bp, iface.Attrs().Name, rule.SVlan, 0)
+			if err != nil {
+				return err
+			}
+			inner, err := qinqLinkName(bp, iface.Attrs().Name, rule.SVlan, rule.CVlan)
+			if err != nil {
+				return err
+			}
+			wanted[outer], wanted[inner] = true, true
+
+			svlan, err := qinqVlan(outer, iface, rule.SVlan, netlink.VLAN_PROTOCOL_8021AD, bp.Name)
+			if err != nil {
+				return err
+			}
+			// Example: ip link add link eth2q100 name eth2q100.200 type vlan proto 802.1Q id 200
+			cvlan, err := qinqVlan(inner, svlan, rule.CVlan, netlink.VLAN_PROTOCOL_8021Q, bp.Name)
+			if err != nil {
+				return err
+			}
+			if err := topology.AddPort(ctx, cvlan); err != nil {
+				return err
+			}
+			if err := topology.AttachPort(ctx, cvlan, uint16(lb.Spec.VlanID), true); err != nil {
+				return err
+			}
+			if err := nlink.LinkSetUp(ctx, svlan); err != nil {
+				return err
+			}
+			if err := nlink.LinkSetUp(ctx, cvlan); err != nil {
+				return err
+			}
+		}
+	}
+	return removeQinq(bp, wanted)
+}
+
+// tearDownQinq removes the QinQ sub-interfaces of the port
+func tearDownQinq(bp *infradb.BridgePort) error {
+	return removeQinq(bp, nil)
+}
+
+// removeQinq deletes the sub-interfaces owned by the bridge port which are not wanted and releases their
+// names, the C-VLAN sub-interfaces go away with their S-VLAN sub-interface
+func removeQinq(bp *infradb.BridgePort, wanted map[string]bool) error {
+	links, err := nlink.LinkList(ctx)
+	if err != nil {
+		return err
+	}
+	var gone []string
+	deleted := make(map[int]bool)
+	for _, link := range links {
+		vlan, ok := link.(*netlink.Vlan)
+		if !ok || vlan.VlanProtocol != netlink.VLAN_PROTOCOL_8021AD || wanted[link.Attrs().Name] {
+			continue
+		}
+		if owner, ok := utils.LinkAliasOwner(link.Attrs().Alias); !ok || owner != bp.Name {
+			continue
+		}
+		if err := nlink.LinkDel(ctx, link); err != nil {
+			return err
+		}
+		deleted[link.Attrs().Index] = true
+		gone = append(gone, link.Attrs().Name)
+	}
+	for _, link := range links {
+		if wanted[link.Attrs().Name] || deleted[link.Attrs().Index] {
+			continue
+		}
+		if owner, ok := utils.LinkAliasOwner(link.Attrs().Alias); !ok || owner != bp.Name {
+			continue
+		}
+		gone = append(gone, link.Attrs().Name)
+		if deleted[link.Attrs().ParentIndex] {
+			continue
+		}
+		if err := nlink.LinkDel(ctx, link); err != nil {
+			return err
+		}
+	}
+	for _, name := range gone {
+		if owner, ok := infradb.GetLinkOwner(name); ok && owner.Object == bp.Name {
+			if err := infradb.ReleaseLinkName(bp.Name, owner.Role); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxcimodule is the main package of the application
+package linuxcimodule
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"sync"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/sflow"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+const (
+	// sflowGroup is the psample group of the tc sample actions of the bridge ports
+	sflowGroup = 0x5f10
+	// sflowFilterPref is the preference of the matchall filter of the sampling
+	sflowFilterPref = "49152"
+)
+
+var (
+	sflowOnce  sync.Once
+	sflowAgent *sflow.Agent
+	sflowErr   error
+)
+
+// tc runs the tc command
+var tc = func(args ...string) error {
+	out, err := exec.Command("tc", args...).CombinedOutput() //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("tc %v: %v: %s", args, err, out)
+	}
+	return nil
+}
+
+// startSflowAgent starts the sFlow agent receiving the samples of the bridge ports, once
+func startSflowAgent() (*sflow.Agent, error) {
+	sflowOnce.Do(func() {
+		source, err := sflow.ListenPsample(sflowGroup)
+		if err != nil {
+			sflowErr = fmt.Errorf("sFlow agent: %w", err)
+			return
+		}
+		// the collectors are on the management network
+		dialer := &net.Dialer{}
+		if vrf := config.GlobalConfig.Management.Vrf; vrf != "" {
+			dialer.Control = utils.BindToDevice(vrf)
+		}
+		vtep := utils.GetIPAddress(config.GlobalConfig.LinuxFrr.DefaultVtep)
+		sflowAgent = sflow.NewAgent(vtep.IP, dialer)
+		go sflowAgent.Run(context.Background(), source)
+	})
+	return sflowAgent, sflowErr
+}
+
+// setUpSflow samples the ingress of the port into the sFlow agent, or stops the sampling
+func setUpSflow(dev string, ifindex int, spec *infradb.SflowSpec) error {
+	if spec == nil {
+		tearDownSflow(dev, ifindex)
+		return nil
+	}
+	agent, err := startSflowAgent()
+	if err != nil {
+		return err
+	}
+	if err := tc("qdisc", "replace", "dev", dev, "clsact"); err != nil {
+		return err
+	}
+	// Example: tc filter replace dev eth2 ingress pref 49152 handle 1 matchall action sample rate 1000 group 24336 trunc 128
+	if err := tc("filter", "replace", "dev", dev, "ingress", "pref", sflowFilterPref, "handle", "1", "matchall",
+		"action", "sample", "rate", strconv.FormatUint(uint64(spec.SamplingRate), 10),
+		"group", strconv.Itoa(sflowGroup), "trunc", strconv.FormatUint(uint64(spec.HeaderLength), 10)); err != nil {
+		return err
+	}
+	return agent.SetPort(sflow.Port{
+		Ifindex:      uint32(ifindex),
+		Rate:         spec.SamplingRate,
+		HeaderLength: spec.HeaderLength,
+		Collector:    spec.Collector,
+	})
+}
+
+// tearDownSflow stops the sampling of the port, a port which was not sampled is left as is
+func tearDownSflow(dev string, ifindex int) {
+	if sflowAgent != nil {
+		sflowAgent.RemovePort(uint32(ifindex))
+	}
+	// the filter is missing when the port was not sampled
+	_ = tc("filter", "del", "dev", dev, "ingress", "pref", sflowFilterPref)
+}
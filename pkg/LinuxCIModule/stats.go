@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxcimodule is the main package of the application
+package linuxcimodule
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// PortStats holds the kernel counters of a bridge port
+type PortStats struct {
+	RxPackets uint64
+	TxPackets uint64
+	RxBytes   uint64
+	TxBytes   uint64
+	RxErrors  uint64
+	TxErrors  uint64
+	RxDropped uint64
+	TxDropped uint64
+}
+
+// GetBridgePortStats returns the kernel counters of the linux device behind the bridge port
+func GetBridgePortStats(name string) (*PortStats, error) {
+	bp, err := infradb.GetBP(name)
+	if err != nil {
+		return nil, err
+	}
+	if bp.Status.BPOperStatus != infradb.BridgePortOperStatusUp {
+		return nil, fmt.Errorf("bridge port %s is not operationally up", name)
+	}
+	link, err := netlink.LinkByName(path.Base(bp.Name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the device of bridge port %s: %v", name, err)
+	}
+	s := link.Attrs().Statistics
+	if s == nil {
+		return &PortStats{}, nil
+	}
+	return &PortStats{
+		RxPackets: s.RxPackets,
+		TxPackets: s.TxPackets,
+		RxBytes:   s.RxBytes,
+		TxBytes:   s.TxBytes,
+		RxErrors:  s.RxErrors,
+		TxErrors:  s.TxErrors,
+		RxDropped: s.RxDropped,
+		TxDropped: s.TxDropped,
+	}, nil
+}
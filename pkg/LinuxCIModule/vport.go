@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxcimodule is the main package of the application
+package linuxcimodule
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// defaultDriverTimeout bounds the execution of the driver when the config leaves it out
+const defaultDriverTimeout = 10 * time.Second
+
+// Commands of the virtual port driver
+const (
+	driverAdd    = "add"
+	driverDel    = "del"
+	driverAttach = "attach"
+	driverDetach = "detach"
+)
+
+// driverRequest is the json request written to the stdin of the driver. The add and del
+// commands create and remove the port in the userspace dataplane, attach and detach
+// plug it into the VLANs of its bridge port.
+type driverRequest struct {
+	Command    string   `json:"command"`
+	Port       string   `json:"port"`
+	Type       string   `json:"type"`
+	SocketPath string   `json:"socket_path"`
+	Server     bool     `json:"server"`
+	Queues     int      `json:"queues"`
+	MacAddress string   `json:"mac_address,omitempty"`
+	Access     bool     `json:"access,omitempty"`
+	Vlans      []uint32 `json:"vlans,omitempty"`
+	Isolated   bool     `json:"isolated,omitempty"`
+}
+
+// runDriver executes the virtual port driver with the request, the driver reports a
+// failure with a non zero exit code and a message on stderr
+func runDriver(req *driverRequest) error {
+	driver := config.GlobalConfig.VirtualPorts.Driver
+	if driver == "" {
+		return fmt.Errorf("no virtual port driver is configured")
+	}
+	timeout := defaultDriverTimeout
+	if config.GlobalConfig.VirtualPorts.Timeout > 0 {
+		timeout = time.Duration(config.GlobalConfig.VirtualPorts.Timeout) * time.Second
+	}
+	in, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	cctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(cctx, driver) //nolint:gosec
+	cmd.Stdin = bytes.NewReader(in)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("driver %s %s %s failed: %v: %s", driver, req.Command, req.Port, err, strings.TrimSpace(stderr.String()))
+	}
+	log.Printf("LCI Executed : %s %s %s\n", driver, req.Command, req.Port)
+	return nil
+}
+
+// newDriverRequest fills the request with the virtual port
+func newDriverRequest(command string, vport *infradb.VirtualPort) *driverRequest {
+	return &driverRequest{
+		Command:    command,
+		Port:       vport.LinkName(),
+		Type:       vport.Spec.Type,
+		SocketPath: vport.Spec.SocketPath,
+		Server:     vport.Spec.Server,
+		Queues:     vport.Spec.Queues,
+	}
+}
+
+// handleVirtualPort handles the virtual port functionality
+func handleVirtualPort(objectData *eventbus.ObjectData) {
+	var comp common.Component
+	comp.Name = lciComp
+	vport, err := infradb.GetVirtualPort(objectData.Name)
+	if err != nil || objectData.ResourceVersion != vport.ResourceVersion {
+		comp.CompStatus = common.ComponentStatusError
+		if err != nil {
+			comp.Details = fmt.Sprintf("LCI : GetVirtualPort error: %s\n", err)
+		} else {
+			comp.Details = fmt.Sprintf("LCI: Mismatch in resoruce version %+v\n and virtual port resource version %+v\n", objectData.ResourceVersion, vport.ResourceVersion)
+		}
+		log.Print(comp.Details)
+		comp.Timer = 2 * time.Second
+		if err := infradb.UpdateVirtualPortStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, comp); err != nil {
+			log.Printf("error in updating virtual port status: %s\n", err)
+		}
+		return
+	}
+	for i := 0; i < len(vport.Status.Components); i++ {
+		if vport.Status.Components[i].Name == lciComp {
+			comp = vport.Status.Components[i]
+		}
+	}
+	command := driverAdd
+	if vport.Status.OperStatus == infradb.OperStatusToBeDeleted {
+		command = driverDel
+	}
+	comp.Name = lciComp
+	comp.Details = ""
+	if err := runDriver(newDriverRequest(command, vport)); err != nil {
+		log.Printf("LCI: %v\n", err)
+		comp.Details = fmt.Sprintf("LCI: %v\n", err)
+		if comp.Timer == 0 {
+			comp.Timer = 2 * time.Second
+		} else {
+			comp.Timer *= 2
+		}
+		comp.CompStatus = common.ComponentStatusError
+	} else {
+		comp.CompStatus = common.ComponentStatusSuccess
+		comp.Timer = 0
+	}
+	log.Printf("LCI: %+v \n", comp)
+	if err := infradb.UpdateVirtualPortStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, comp); err != nil {
+		log.Printf("error in updating virtual port status: %s\n", err)
+	}
+}
+
+// bpDriverRequest fills the attach or detach request of the bridge port backed by the virtual port
+func bpDriverRequest(command string, vport *infradb.VirtualPort, bp *infradb.BridgePort) (*driverRequest, error) {
+	req := newDriverRequest(command, vport)
+	req.Access = bp.Spec.Ptype == infradb.Access
+	if bp.Spec.MacAddress != nil {
+		req.MacAddress = bp.Spec.MacAddress.String()
+	}
+	for _, bridgeRefName := range bp.Spec.LogicalBridges {
+		BrObj, err := infradb.GetLB(bridgeRefName)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find key %s and error is %v", bridgeRefName, err)
+		}
+		req.Vlans = append(req.Vlans, BrObj.Spec.VlanID)
+		req.Isolated = req.Isolated || bp.Spec.IsolatedIn(BrObj.Spec)
+	}
+	return req, nil
+}
+
+// setUpVirtualBp attaches the virtual port of the bridge port to its VLANs through the driver
+func setUpVirtualBp(vport *infradb.VirtualPort, bp *infradb.BridgePort) (string, bool) {
+	if vport.Status.OperStatus != infradb.OperStatusUp {
+		log.Printf("LCI: Virtual port %s is not up yet\n", vport.Name)
+		return fmt.Sprintf("LCI: Virtual port %s is not up yet\n", vport.Name), false
+	}
+	req, err := bpDriverRequest(driverAttach, vport, bp)
+	if err == nil {
+		err = runDriver(req)
+	}
+	if err != nil {
+		log.Printf("LCI: %v\n", err)
+		return fmt.Sprintf("LCI: %v\n", err), false
+	}
+	return "", true
+}
+
+// tearDownVirtualBp detaches the virtual port of the bridge port through the driver, the
+// port itself stays in the dataplane until the virtual port is deleted
+func tearDownVirtualBp(vport *infradb.VirtualPort, bp *infradb.BridgePort) (string, bool) {
+	req, err := bpDriverRequest(driverDetach, vport, bp)
+	if err == nil {
+		err = runDriver(req)
+	}
+	if err != nil {
+		log.Printf("LCI: %v\n", err)
+		return fmt.Sprintf("LCI: %v\n", err), false
+	}
+	return "", true
+}
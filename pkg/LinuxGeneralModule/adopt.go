@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"log"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+	"github.com/vishvananda/netlink"
+)
+
+// takeOver returns the existing device of an adopted resource: a device with the expected parameters which
+// has been created by the operator or for the resource itself. The device is tagged with the resource so
+// that it is managed from now on. Nothing is returned when the device has to be created.
+func takeOver(nl utils.Netlink, name, owner string, matches func(netlink.Link) bool) (netlink.Link, bool) {
+	link, err := nl.LinkByName(ctx, name)
+	if err != nil || !matches(link) {
+		return nil, false
+	}
+	if current, tagged := utils.LinkAliasOwner(link.Attrs().Alias); tagged {
+		return link, current == owner
+	}
+	if err := tagLinkIn(nl, link, owner); err != nil {
+		return nil, false
+	}
+	log.Printf("LGM: Took over %s for %s\n", name, owner)
+	return link, true
+}
+
+// takeOverVrf tags the existing devices of an adopted vrf and keeps its routing table out of the pool
+func takeOverVrf(nl utils.Netlink, vrf *infradb.Vrf) {
+	for _, role := range []string{infradb.LinkRoleVrf, infradb.LinkRoleBridge, infradb.LinkRoleVxlan} {
+		takeOver(nl, infradb.LinkName(vrf.Name, role), vrf.Name, func(netlink.Link) bool { return true })
+	}
+	RouteTableGen.Reserve(vrf.Name, *vrf.Metadata.RoutingTable[0])
+}
+
+// isVxlan tells whether the device is the vxlan device of the VNI
+func isVxlan(vni uint32) func(netlink.Link) bool {
+	return func(link netlink.Link) bool {
+		vxlan, ok := link.(*netlink.Vxlan)
+		return ok && uint32(vxlan.VxlanId) == vni
+	}
+}
+
+// isVlan tells whether the device is the vlan device of the vlan id
+func isVlan(vid uint16) func(netlink.Link) bool {
+	return func(link netlink.Link) bool {
+		vlan, ok := link.(*netlink.Vlan)
+		return ok && vlan.VlanId == int(vid)
+	}
+}
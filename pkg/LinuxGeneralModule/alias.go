@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"log"
+
+	"github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// tagLink sets the alias of the device to the resource which owns it, so that
+// `ip link` tells the devices of the bridge apart from the ones of the operator
+func tagLink(link netlink.Link, owner string) error {
+	return tagLinkIn(nlink, link, owner)
+}
+
+// tagLinkIn sets the alias of the device of a network namespace. The owner is not set as an
+// IFLA_ALTNAME as well: the alternative names are interface names, unique in the namespace and
+// bounded to 127 characters, while the devices of a vrf share their owner and the alias holds
+// the full resource name.
+func tagLinkIn(nl utils.Netlink, link netlink.Link, owner string) error {
+	// Example: ip link set <link> alias opi-evpn-bridge://network.opiproject.org/vrfs/<id>
+	if err := nl.LinkSetAlias(ctx, link, utils.LinkAlias(owner)); err != nil {
+		log.Printf("LGM: Failed to set the alias of %s: %v\n", link.Attrs().Name, err)
+		return err
+	}
+	return nil
+}
+
+// resourceVersion returns the resource version of the owner of a device, it is replaced by the tests
+var resourceVersion = infradb.GetResourceVersion
+
+// collectGarbage deletes the devices created by the bridge for the resources which have been
+// deleted while it was down. The devices without alias have been created by the operator and
+// the static devices have an empty owner, both are left alone. The named network namespaces are
+// swept as well, as the vrfs may have been moved there.
+func collectGarbage() {
+	collectGarbageIn("", nlink)
+	namespaces, err := utils.ListNetns()
+	if err != nil {
+		log.Printf("LGM: Failed to list the network namespaces: %v\n", err)
+		return
+	}
+	for _, namespace := range namespaces {
+		nl, err := netnsLink(namespace)
+		if err != nil {
+			log.Printf("LGM: %v\n", err)
+			continue
+		}
+		collectGarbageIn(namespace, nl)
+	}
+}
+
+// collectGarbageIn deletes the stale devices of a network namespace
+func collectGarbageIn(namespace string, nl utils.Netlink) {
+	links, err := nl.LinkList(ctx)
+	if err != nil {
+		log.Printf("LGM: Failed to list the links of network namespace %q: %v\n", namespace, err)
+		return
+	}
+	for _, link := range links {
+		owner, ok := utils.LinkAliasOwner(link.Attrs().Alias)
+		if !ok || owner == "" {
+			continue
+		}
+		version, err := resourceVersion(owner)
+		if err != nil || version != "" {
+			continue
+		}
+		if err := nl.LinkDel(ctx, link); err != nil {
+			log.Printf("LGM: Failed to delete the stale link %s of %s: %v\n", link.Attrs().Name, owner, err)
+			continue
+		}
+		log.Printf("LGM Executed : ip link delete %s (owner %s is gone)\n", link.Attrs().Name, owner)
+	}
+}
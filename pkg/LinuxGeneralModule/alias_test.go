@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
+)
+
+func Test_CollectGarbageIn(t *testing.T) {
+	const owner = "//network.opiproject.org/vrfs/blue"
+	link := func(alias string) netlink.Link {
+		return &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "br-blue", Alias: alias}}
+	}
+
+	tests := map[string]struct {
+		link    netlink.Link
+		version string
+		err     error
+		deleted bool
+	}{
+		"device of the operator": {
+			link: link(""),
+		},
+		"static device": {
+			link: link(utils.LinkAlias("")),
+		},
+		"owner exists": {
+			link:    link(utils.LinkAlias(owner)),
+			version: "1",
+		},
+		"owner is gone": {
+			link:    link(utils.LinkAlias(owner)),
+			deleted: true,
+		},
+		"store error": {
+			link: link(utils.LinkAlias(owner)),
+			err:  errors.New("store unavailable"),
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			saved := resourceVersion
+			t.Cleanup(func() { resourceVersion = saved })
+			resourceVersion = func(name string) (string, error) {
+				if name != owner {
+					t.Errorf("unexpected owner %s", name)
+				}
+				return tt.version, tt.err
+			}
+
+			nl := mocks.NewNetlink(t)
+			nl.On("LinkList", mock.Anything).Return([]netlink.Link{tt.link}, nil)
+			if tt.deleted {
+				nl.On("LinkDel", mock.Anything, tt.link).Return(nil)
+			}
+			// an unexpected LinkDel fails the mock
+			collectGarbageIn("", nl)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/vishvananda/netlink"
+)
+
+// bondMiimon is the link monitoring interval of the bond members in milliseconds
+const bondMiimon = 100
+
+// BondMemberState holds the runtime state of a bond member
+type BondMemberState struct {
+	Name         string
+	OperState    string
+	MiiStatus    string
+	Active       bool
+	AggregatorID uint16
+	// PartnerPortState is the LACP state reported by the partner for this member
+	PartnerPortState uint16
+}
+
+// BondState holds the runtime state of a bond and its LACP partner
+type BondState struct {
+	OperState    string
+	AggregatorID int
+	NumPorts     int
+	PartnerKey   int
+	PartnerMac   string
+	Members      []BondMemberState
+}
+
+// handleBond handles the bond functionality
+func handleBond(objectData *eventbus.ObjectData) {
+	bond, err := infradb.GetBond(objectData.Name)
+	handleResource(objectData, &bond.Resource, err,
+		func() (string, bool) { return setUpBond(bond) },
+		func() (string, bool) { return tearDownBond(bond) },
+		infradb.UpdateBondStatus)
+}
+
+// setUpBond creates the kernel bond and enslaves its members
+func setUpBond(bond *infradb.Bond) (string, bool) {
+	linkName := bond.LinkName()
+	link, err := nlink.LinkByName(ctx, linkName)
+	if err != nil {
+		attrs := netlink.NewLinkAttrs()
+		attrs.Name = linkName
+		nlBond := netlink.NewLinkBond(attrs)
+		nlBond.Mode = netlink.StringToBondMode(bond.Spec.Mode)
+		nlBond.Miimon = bondMiimon
+		nlBond.MinLinks = bond.Spec.MinLinks
+		if nlBond.Mode == netlink.BOND_MODE_802_3AD {
+			nlBond.LacpRate = netlink.StringToBondLacpRate(bond.Spec.LacpRate)
+		}
+		// Example: ip link add <bond> type bond mode <mode> miimon 100 min_links <n> lacp_rate <rate>
+		if err := nlink.LinkAdd(ctx, nlBond); err != nil {
+			log.Printf("LGM: Failed to create bond %s: %v\n", linkName, err)
+			return fmt.Sprintf("LGM: Failed to create bond %s: %v\n", linkName, err), false
+		}
+		if err := tagLink(nlBond, bond.Name); err != nil {
+			return fmt.Sprintf("LGM: Failed to set the alias of bond %s: %v\n", linkName, err), false
+		}
+		log.Printf("LGM Executed : ip link add %s type bond mode %s min_links %d lacp_rate %s\n", linkName, bond.Spec.Mode, bond.Spec.MinLinks, bond.Spec.LacpRate)
+		link = nlBond
+	}
+	for _, member := range bond.Spec.Members {
+		memberLink, err := nlink.LinkByName(ctx, member)
+		if err != nil {
+			log.Printf("LGM: Failed to get link information for %s: %v\n", member, err)
+			return fmt.Sprintf("LGM: Failed to get link information for %s: %v\n", member, err), false
+		}
+		if memberLink.Attrs().MasterIndex == link.Attrs().Index {
+			continue
+		}
+		// The kernel accepts only links which are down as bond members
+		if err := nlink.LinkSetDown(ctx, memberLink); err != nil {
+			log.Printf("LGM: Failed to set down link %s: %v\n", member, err)
+			return fmt.Sprintf("LGM: Failed to set down link %s: %v\n", member, err), false
+		}
+		// Example: ip link set <member> master <bond>
+		if err := nlink.LinkSetMaster(ctx, memberLink, link); err != nil {
+			log.Printf("LGM: Failed to add %s to bond %s: %v\n", member, linkName, err)
+			return fmt.Sprintf("LGM: Failed to add %s to bond %s: %v\n", member, linkName, err), false
+		}
+		if err := nlink.LinkSetUp(ctx, memberLink); err != nil {
+			log.Printf("LGM: Failed to set up link %s: %v\n", member, err)
+			return fmt.Sprintf("LGM: Failed to set up link %s: %v\n", member, err), false
+		}
+		log.Printf("LGM Executed : ip link set %s master %s up\n", member, linkName)
+	}
+	// Example: ip link set <bond> up
+	if err := nlink.LinkSetUp(ctx, link); err != nil {
+		log.Printf("LGM: Failed to set up bond %s: %v\n", linkName, err)
+		return fmt.Sprintf("LGM: Failed to set up bond %s: %v\n", linkName, err), false
+	}
+	return "", true
+}
+
+// tearDownBond releases the members and deletes the kernel bond
+func tearDownBond(bond *infradb.Bond) (string, bool) {
+	linkName := bond.LinkName()
+	link, err := nlink.LinkByName(ctx, linkName)
+	if err != nil {
+		log.Printf("LGM: Nothing to tear down for bond %s: %v\n", bond.Name, err)
+		return "", true
+	}
+	for _, member := range bond.Spec.Members {
+		memberLink, err := nlink.LinkByName(ctx, member)
+		if err != nil || memberLink.Attrs().MasterIndex != link.Attrs().Index {
+			continue
+		}
+		// Example: ip link set <member> nomaster
+		if err := nlink.LinkSetNoMaster(ctx, memberLink); err != nil {
+			log.Printf("LGM: Failed to release %s from bond %s: %v\n", member, linkName, err)
+			return fmt.Sprintf("LGM: Failed to release %s from bond %s: %v\n", member, linkName, err), false
+		}
+	}
+	// Example: ip link delete <bond>
+	if err := nlink.LinkDel(ctx, link); err != nil {
+		log.Printf("LGM: Failed to delete bond %s: %v\n", linkName, err)
+		return fmt.Sprintf("LGM: Failed to delete bond %s: %v\n", linkName, err), false
+	}
+	log.Printf("LGM Executed : ip link delete %s\n", linkName)
+	return "", true
+}
+
+// GetBondState returns the link and LACP partner state of the bond with the given name
+func GetBondState(name string) (*BondState, error) {
+	bond, err := infradb.GetBond(name)
+	if err != nil {
+		return nil, err
+	}
+	link, err := nlink.LinkByName(ctx, bond.LinkName())
+	if err != nil {
+		return nil, err
+	}
+	state := &BondState{OperState: link.Attrs().OperState.String(), Members: []BondMemberState{}}
+	if nlBond, ok := link.(*netlink.Bond); ok && nlBond.AdInfo != nil {
+		state.AggregatorID = nlBond.AdInfo.AggregatorId
+		state.NumPorts = nlBond.AdInfo.NumPorts
+		state.PartnerKey = nlBond.AdInfo.PartnerKey
+		state.PartnerMac = nlBond.AdInfo.PartnerMac.String()
+	}
+	for _, member := range bond.Spec.Members {
+		memberState := BondMemberState{Name: member, OperState: "missing"}
+		memberLink, err := nlink.LinkByName(ctx, member)
+		if err == nil {
+			memberState.OperState = memberLink.Attrs().OperState.String()
+			if slave, ok := memberLink.Attrs().Slave.(*netlink.BondSlave); ok {
+				memberState.MiiStatus = slave.MiiStatus.String()
+				memberState.Active = slave.State == netlink.BondStateActive
+				memberState.AggregatorID = slave.AggregatorId
+				memberState.PartnerPortState = slave.AdPartnerOperPortState
+			}
+		}
+		state.Members = append(state.Members, memberState)
+	}
+	return state, nil
+}
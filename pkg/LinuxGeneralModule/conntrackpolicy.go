@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// ctpLimitRule is the comment of the nftables rule dropping the connections over the limit
+const ctpLimitRule = "max-connections"
+
+// ConntrackPolicyStats holds the counters of a conntrack policy
+type ConntrackPolicyStats struct {
+	// DroppedConnections counts the new connections dropped as the subnet was at its limit
+	DroppedConnections uint64
+}
+
+// handleConntrackPolicy handles the conntrack policy functionality
+func handleConntrackPolicy(objectData *eventbus.ObjectData) {
+	ctp, err := infradb.GetConntrackPolicy(objectData.Name)
+	handleResource(objectData, &ctp.Resource, err,
+		func() (string, bool) { return setUpConntrackPolicy(ctp) },
+		func() (string, bool) { return tearDownConntrackPolicy(ctp) },
+		infradb.UpdateConntrackPolicyStatus)
+}
+
+// ctpTableName returns the nftables table used for the conntrack policy
+func ctpTableName(ctp *infradb.ConntrackPolicy) string {
+	return "opi-ct-" + path.Base(ctp.Name)
+}
+
+// ctpDevice returns the routed interface of the svi of the conntrack policy
+func ctpDevice(ctp *infradb.ConntrackPolicy) (string, error) {
+	svi, err := infradb.GetSvi(ctp.Spec.Svi)
+	if err != nil {
+		return "", err
+	}
+	lb, err := infradb.GetLB(svi.Spec.LogicalBridge)
+	if err != nil {
+		return "", err
+	}
+	return infradb.SviLinkName(svi, lb.Spec.VlanID), nil
+}
+
+// ctTimeoutPolicy renders the timeouts by state of a ct timeout object, in seconds
+func ctTimeoutPolicy(timeouts map[string]time.Duration) string {
+	states := make([]string, 0, len(timeouts))
+	for state := range timeouts {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+	policy := make([]string, 0, len(states))
+	for _, state := range states {
+		policy = append(policy, fmt.Sprintf("%s: %d", state, timeouts[state]/time.Second))
+	}
+	return strings.Join(policy, ", ")
+}
+
+// ctpRuleset renders the nftables ruleset of the conntrack policy. The new connections routed from or to the
+// subnet get the timeouts of the policy, one ct timeout object per protocol and address family, and those
+// opened by the hosts of the subnet over the limit are dropped before the connection tracking confirms them.
+func ctpRuleset(ctp *infradb.ConntrackPolicy, dev string) string {
+	table := ctpTableName(ctp)
+	type ctTimeout struct {
+		protocol string
+		timeouts map[string]time.Duration
+	}
+	var objects []ctTimeout
+	if len(ctp.Spec.TCPTimeouts) != 0 {
+		objects = append(objects, ctTimeout{"tcp", ctp.Spec.TCPTimeouts})
+	}
+	if len(ctp.Spec.UDPTimeouts) != 0 {
+		objects = append(objects, ctTimeout{"udp", ctp.Spec.UDPTimeouts})
+	}
+	families := []struct{ nfproto, l3proto, suffix string }{{"ipv4", "ip", "4"}, {"ipv6", "ip6", "6"}}
+
+	var b strings.Builder
+	// Declaring the table first makes the delete succeed when the table is not there yet
+	fmt.Fprintf(&b, "table inet %s {}\n", table)
+	fmt.Fprintf(&b, "delete table inet %s\n", table)
+	fmt.Fprintf(&b, "table inet %s {\n", table)
+	for _, o := range objects {
+		for _, f := range families {
+			fmt.Fprintf(&b, "\tct timeout %s%s {\n\t\tprotocol %s;\n\t\tl3proto %s;\n\t\tpolicy = { %s };\n\t}\n",
+				o.protocol, f.suffix, o.protocol, f.l3proto, ctTimeoutPolicy(o.timeouts))
+		}
+	}
+	fmt.Fprintf(&b, "\tchain forward {\n\t\ttype filter hook forward priority filter; policy accept;\n")
+	fmt.Fprintf(&b, "\t\tct state != new accept\n")
+	if ctp.Spec.MaxConnections != 0 {
+		fmt.Fprintf(&b, "\t\tiifname \"%s\" ct count over %d counter drop comment \"%s\"\n", dev, ctp.Spec.MaxConnections, ctpLimitRule)
+	}
+	if len(objects) != 0 {
+		fmt.Fprintf(&b, "\t\tiifname \"%s\" goto subnet\n", dev)
+		fmt.Fprintf(&b, "\t\toifname \"%s\" goto subnet\n", dev)
+	}
+	fmt.Fprintf(&b, "\t}\n")
+	if len(objects) != 0 {
+		fmt.Fprintf(&b, "\tchain subnet {\n")
+		for _, o := range objects {
+			for _, f := range families {
+				fmt.Fprintf(&b, "\t\tmeta nfproto %s meta l4proto %s ct timeout set \"%s%s\"\n", f.nfproto, o.protocol, o.protocol, f.suffix)
+			}
+		}
+		fmt.Fprintf(&b, "\t}\n")
+	}
+	fmt.Fprintf(&b, "}\n")
+	return b.String()
+}
+
+// setUpConntrackPolicy sets up the conntrack policy
+func setUpConntrackPolicy(ctp *infradb.ConntrackPolicy) (string, bool) {
+	dev, err := ctpDevice(ctp)
+	if err != nil {
+		log.Printf("LGM: Failed to resolve the device of conntrack policy %s: %v\n", ctp.Name, err)
+		return fmt.Sprintf("LGM: Failed to resolve the device of conntrack policy %s: %v\n", ctp.Name, err), false
+	}
+	// Example: nft -f <ruleset of table inet opi-ct-<id>>
+	if details, ok := applyNftables(ctpRuleset(ctp, dev)); !ok {
+		log.Print(details)
+		return details, false
+	}
+	log.Printf("LGM Executed : nft -f <table inet %s>\n", ctpTableName(ctp))
+	return "", true
+}
+
+// tearDownConntrackPolicy tears down the conntrack policy, the tracked connections keep their timeouts
+func tearDownConntrackPolicy(ctp *infradb.ConntrackPolicy) (string, bool) {
+	table := ctpTableName(ctp)
+	// Example: nft delete table inet opi-ct-<id>
+	if details, ok := applyNftables(fmt.Sprintf("table inet %s {}\ndelete table inet %s\n", table, table)); !ok {
+		log.Print(details)
+		return details, false
+	}
+	log.Printf("LGM Executed : nft delete table inet %s\n", table)
+	return "", true
+}
+
+// GetConntrackPolicyStats returns the counters of the conntrack policy
+func GetConntrackPolicyStats(name string) (*ConntrackPolicyStats, error) {
+	ctp, err := infradb.GetConntrackPolicy(name)
+	if err != nil {
+		return nil, err
+	}
+	if ctp.Status.OperStatus != infradb.OperStatusUp {
+		return nil, fmt.Errorf("conntrack policy %s is not operationally up", name)
+	}
+	out, err := exec.Command("nft", "-j", "list", "table", "inet", ctpTableName(ctp)).Output() //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nftables table %s: %v", ctpTableName(ctp), err)
+	}
+	counters, err := parseViolationCounters(out)
+	if err != nil {
+		return nil, err
+	}
+	return &ConntrackPolicyStats{DroppedConnections: counters[ctpLimitRule]}, nil
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_CtpRuleset(t *testing.T) {
+	ctp := &infradb.ConntrackPolicy{
+		Resource: infradb.Resource{Name: "//network.opiproject.org/conntrackpolicies/blue"},
+		Spec: &infradb.ConntrackPolicySpec{
+			Svi:            "//network.opiproject.org/svis/blue",
+			MaxConnections: 10000,
+			TCPTimeouts:    map[string]time.Duration{"established": time.Hour, "close_wait": time.Minute},
+		},
+	}
+	ruleset := ctpRuleset(ctp, "br-tenant.20")
+	for _, expected := range []string{
+		"delete table inet opi-ct-blue\n",
+		"\tct timeout tcp4 {\n\t\tprotocol tcp;\n\t\tl3proto ip;\n\t\tpolicy = { close_wait: 60, established: 3600 };\n\t}\n",
+		"\tct timeout tcp6 {\n\t\tprotocol tcp;\n\t\tl3proto ip6;\n",
+		"iifname \"br-tenant.20\" ct count over 10000 counter drop comment \"max-connections\"\n",
+		"oifname \"br-tenant.20\" goto subnet\n",
+		"meta nfproto ipv6 meta l4proto tcp ct timeout set \"tcp6\"\n",
+	} {
+		if !strings.Contains(ruleset, expected) {
+			t.Errorf("expected %q in the ruleset:\n%s", expected, ruleset)
+		}
+	}
+	if strings.Contains(ruleset, "udp") {
+		t.Errorf("expected no udp timeout:\n%s", ruleset)
+	}
+
+	ctp.Spec.TCPTimeouts = nil
+	if ruleset := ctpRuleset(ctp, "br-tenant.20"); strings.Contains(ruleset, "subnet") || strings.Contains(ruleset, "ct timeout") {
+		t.Errorf("expected the limit only:\n%s", ruleset)
+	}
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sync"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+	"github.com/vishvananda/netlink"
+)
+
+// deferredObject is an object whose programming waits for the underlay
+type deferredObject struct {
+	objectType string
+	vtepIP     *net.IPNet
+}
+
+// deferred holds the objects left in pending state, keyed by their name
+var deferred = struct {
+	sync.Mutex
+	objects map[string]deferredObject
+}{objects: make(map[string]deferredObject)}
+
+// stopDependencies ends the watching of the underlay
+var stopDependencies chan struct{}
+
+// missingDependency tells which underlay dependency of a vxlan device is missing, empty when
+// the configured uplink exists and the vtep ip is assigned to a local device. An unspecified
+// vtep ip stands for the default vtep device which had no address when the object was created,
+// it is filled in by the infradb when the programming resumes.
+func missingDependency(vtepIP *net.IPNet) string {
+	if uplink := config.GlobalConfig.LinuxFrr.Uplink; uplink != "" {
+		if _, err := nlink.LinkByName(ctx, uplink); err != nil {
+			return fmt.Sprintf("uplink %s", uplink)
+		}
+	}
+	if vtepIP == nil {
+		return ""
+	}
+	if vtepIP.IP.IsUnspecified() {
+		return fmt.Sprintf("vtep source interface %s", config.GlobalConfig.LinuxFrr.DefaultVtep)
+	}
+	addrs, err := nlink.AddrList(ctx, nil, netlink.FAMILY_ALL)
+	if err != nil {
+		return fmt.Sprintf("vtep ip %s: %v", vtepIP.IP, err)
+	}
+	for _, addr := range addrs {
+		if addr.IP.Equal(vtepIP.IP) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("vtep ip %s", vtepIP.IP)
+}
+
+// deferObject records the object to be programmed once its dependencies appear
+func deferObject(objectType, name string, vtepIP *net.IPNet) {
+	deferred.Lock()
+	defer deferred.Unlock()
+	deferred.objects[name] = deferredObject{objectType: objectType, vtepIP: vtepIP}
+}
+
+// forgetObject drops the object from the deferred ones
+func forgetObject(name string) {
+	deferred.Lock()
+	defer deferred.Unlock()
+	delete(deferred.objects, name)
+}
+
+// resumeDeferred resumes the programming of the deferred objects whose dependencies exist now
+func resumeDeferred() {
+	deferred.Lock()
+	defer deferred.Unlock()
+	for name, obj := range deferred.objects {
+		vtepIP := obj.vtepIP
+		if vtepIP != nil && vtepIP.IP.IsUnspecified() {
+			defaultVtepIP := utils.GetIPAddress(config.GlobalConfig.LinuxFrr.DefaultVtep)
+			vtepIP = &defaultVtepIP
+		}
+		if missing := missingDependency(vtepIP); missing != "" {
+			continue
+		}
+		log.Printf("LGM: The dependencies of %s %s exist, resuming its programming\n", obj.objectType, name)
+		if err := infradb.ResumeTask(obj.objectType, name, lgmComp); err != nil {
+			log.Printf("LGM: Failed to resume %s %s: %v\n", obj.objectType, name, err)
+		}
+		delete(deferred.objects, name)
+	}
+}
+
+// watchDependencies resumes the deferred objects on the link and address notifications of the kernel, and
+// announces the gateway IPs of the svis again when their devices go up
+func watchDependencies() {
+	stopDependencies = make(chan struct{})
+	links := make(chan netlink.LinkUpdate, 64)
+	addrs := make(chan netlink.AddrUpdate, 64)
+	onError := func(err error) {
+		log.Printf("LGM: underlay notification error: %v\n", err)
+	}
+	if err := netlink.LinkSubscribeWithOptions(links, stopDependencies, netlink.LinkSubscribeOptions{ErrorCallback: onError}); err != nil {
+		log.Printf("LGM: Failed to subscribe to the link notifications: %v\n", err)
+		return
+	}
+	if err := netlink.AddrSubscribeWithOptions(addrs, stopDependencies, netlink.AddrSubscribeOptions{ErrorCallback: onError}); err != nil {
+		log.Printf("LGM: Failed to subscribe to the address notifications: %v\n", err)
+		return
+	}
+	go func() {
+		for {
+			select {
+			case update, ok := <-links:
+				if !ok {
+					return
+				}
+				announceOnLink(update)
+			case update, ok := <-addrs:
+				if !ok {
+					return
+				}
+				if !update.NewAddr {
+					continue
+				}
+			}
+			resumeDeferred()
+		}
+	}()
+}
+
+// waitForDependency leaves the lgm component in pending state when an underlay dependency of the
+// vxlan device of the object is missing, the object is programmed once the dependency appears
+func waitForDependency(objectType, name string, vtepIP *net.IPNet, comp *common.Component) bool {
+	missing := missingDependency(vtepIP)
+	if missing == "" {
+		forgetObject(name)
+		return false
+	}
+	deferObject(objectType, name, vtepIP)
+	comp.Name = lgmComp
+	comp.CompStatus = common.ComponentStatusPending
+	comp.Details = fmt.Sprintf("LGM: Waiting for %s\n", missing)
+	comp.Timer = 0
+	return true
+}
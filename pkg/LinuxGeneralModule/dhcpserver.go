@@ -0,0 +1,260 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// handleDHCPServer handles the dhcp server functionality
+func handleDHCPServer(objectData *eventbus.ObjectData) {
+	dhcp, err := infradb.GetDHCPServer(objectData.Name)
+	handleResource(objectData, &dhcp.Resource, err,
+		func() (string, bool) { return setUpDHCPServer(dhcp) },
+		func() (string, bool) { return tearDownDHCPServer(dhcp) },
+		infradb.UpdateDHCPServerStatus)
+}
+
+// dhcpConfPath returns the dnsmasq configuration file of the dhcp server
+func dhcpConfPath(dhcp *infradb.DHCPServer) string {
+	return path.Join(dnsRunDir, "dhcp-"+path.Base(dhcp.Name)+".conf")
+}
+
+// dhcpPidPath returns the dnsmasq pid file of the dhcp server
+func dhcpPidPath(dhcp *infradb.DHCPServer) string {
+	return path.Join(dnsRunDir, "dhcp-"+path.Base(dhcp.Name)+".pid")
+}
+
+// dhcpLeasePath returns the dnsmasq lease file of the dhcp server
+func dhcpLeasePath(dhcp *infradb.DHCPServer) string {
+	return path.Join(dnsRunDir, "dhcp-"+path.Base(dhcp.Name)+".leases")
+}
+
+// dhcpHost is a static address handed out to a MAC address
+type dhcpHost struct {
+	mac net.HardwareAddr
+	ip  net.IP
+}
+
+// dhcpHosts returns the reservations of the dhcp server, followed by the addresses allocated with a MAC
+// address from the subnet by the IPAM of the SVI
+func dhcpHosts(dhcp *infradb.DHCPServer) ([]dhcpHost, error) {
+	hosts := []dhcpHost{}
+	reserved := map[string]bool{}
+	for _, r := range dhcp.Spec.Reservations {
+		bp, err := infradb.GetBP(r.BridgePort)
+		if err != nil {
+			return nil, err
+		}
+		if bp.Spec.MacAddress == nil {
+			return nil, fmt.Errorf("bridge port %s has no MAC address", r.BridgePort)
+		}
+		hosts = append(hosts, dhcpHost{mac: *bp.Spec.MacAddress, ip: r.IP})
+		reserved[bp.Spec.MacAddress.String()] = true
+	}
+	allocations, err := infradb.GetAllIPAllocations(dhcp.Spec.Svi)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range allocations {
+		mac, err := net.ParseMAC(a.MacAddress)
+		if err != nil || reserved[mac.String()] || !dhcp.Spec.Subnet.Contains(a.Address) {
+			continue
+		}
+		hosts = append(hosts, dhcpHost{mac: mac, ip: a.Address})
+	}
+	return hosts, nil
+}
+
+// dhcpGateway returns the gateway address of the svi in the subnet of the dhcp server
+func dhcpGateway(dhcp *infradb.DHCPServer, svi *infradb.Svi) net.IP {
+	for _, gwIP := range svi.Spec.GatewayIPs {
+		if dhcp.Spec.Subnet.Contains(gwIP.IP) {
+			return gwIP.IP
+		}
+	}
+	return nil
+}
+
+// dhcpConfig renders the dnsmasq configuration of the dhcp server, which answers on the svi only
+func dhcpConfig(dhcp *infradb.DHCPServer, linkSvi string, gateway net.IP, hosts []dhcpHost) string {
+	spec := dhcp.Spec
+	v6 := spec.Subnet.IP.To4() == nil
+	var b strings.Builder
+	// The DNS service of dnsmasq is off, a DNS forwarder of the VRF is a resource of its own
+	fmt.Fprintf(&b, "port=0\nno-resolv\nno-hosts\nbind-interfaces\nexcept-interface=lo\ndhcp-authoritative\n")
+	fmt.Fprintf(&b, "interface=%s\n", linkSvi)
+	fmt.Fprintf(&b, "pid-file=%s\n", dhcpPidPath(dhcp))
+	fmt.Fprintf(&b, "dhcp-leasefile=%s\n", dhcpLeasePath(dhcp))
+	ones, _ := spec.Subnet.Mask.Size()
+	if v6 {
+		fmt.Fprintf(&b, "dhcp-range=%s,%s,%d,%d\n", spec.RangeStart, spec.RangeEnd, ones, spec.LeaseTime)
+	} else {
+		fmt.Fprintf(&b, "dhcp-range=%s,%s,%s,%d\n", spec.RangeStart, spec.RangeEnd, net.IP(spec.Subnet.Mask), spec.LeaseTime)
+		if gateway != nil {
+			fmt.Fprintf(&b, "dhcp-option=option:router,%s\n", gateway)
+		}
+		if spec.Mtu != 0 {
+			fmt.Fprintf(&b, "dhcp-option=option:mtu,%d\n", spec.Mtu)
+		}
+	}
+	if len(spec.DNSServers) != 0 {
+		servers := make([]string, 0, len(spec.DNSServers))
+		for _, ip := range spec.DNSServers {
+			if v6 {
+				servers = append(servers, "["+ip.String()+"]")
+			} else {
+				servers = append(servers, ip.String())
+			}
+		}
+		if v6 {
+			fmt.Fprintf(&b, "dhcp-option=option6:dns-server,%s\n", strings.Join(servers, ","))
+		} else {
+			fmt.Fprintf(&b, "dhcp-option=option:dns-server,%s\n", strings.Join(servers, ","))
+		}
+	}
+	for _, host := range hosts {
+		if v6 {
+			// The DHCPv6 clients are matched by the MAC address of their DUID
+			fmt.Fprintf(&b, "dhcp-host=%s,[%s]\n", host.mac, host.ip)
+		} else {
+			fmt.Fprintf(&b, "dhcp-host=%s,%s\n", host.mac, host.ip)
+		}
+	}
+	return b.String()
+}
+
+// setUpDHCPServer starts the dnsmasq instance of the dhcp server inside the VRF of the svi
+func setUpDHCPServer(dhcp *infradb.DHCPServer) (string, bool) {
+	svi, err := infradb.GetSvi(dhcp.Spec.Svi)
+	if err != nil {
+		log.Printf("LGM: Failed to get the SVI of dhcp server %s: %v\n", dhcp.Name, err)
+		return fmt.Sprintf("LGM: Failed to get the SVI of dhcp server %s: %v\n", dhcp.Name, err), false
+	}
+	linkSvi, err := sviLinkName(svi)
+	if err != nil {
+		log.Printf("LGM: Failed to resolve the SVI device of dhcp server %s: %v\n", dhcp.Name, err)
+		return fmt.Sprintf("LGM: Failed to resolve the SVI device of dhcp server %s: %v\n", dhcp.Name, err), false
+	}
+	hosts, err := dhcpHosts(dhcp)
+	if err != nil {
+		log.Printf("LGM: Failed to resolve the reservations of dhcp server %s: %v\n", dhcp.Name, err)
+		return fmt.Sprintf("LGM: Failed to resolve the reservations of dhcp server %s: %v\n", dhcp.Name, err), false
+	}
+	if err := os.MkdirAll(dnsRunDir, 0o755); err != nil {
+		log.Printf("LGM: Failed to create %s: %v\n", dnsRunDir, err)
+		return fmt.Sprintf("LGM: Failed to create %s: %v\n", dnsRunDir, err), false
+	}
+	if err := stopDaemon(dhcpPidPath(dhcp)); err != nil {
+		log.Printf("LGM: Failed to stop dhcp server %s: %v\n", dhcp.Name, err)
+		return fmt.Sprintf("LGM: Failed to stop dhcp server %s: %v\n", dhcp.Name, err), false
+	}
+	conf := dhcpConfig(dhcp, linkSvi, dhcpGateway(dhcp, svi), hosts)
+	if err := os.WriteFile(dhcpConfPath(dhcp), []byte(conf), 0o600); err != nil {
+		log.Printf("LGM: Failed to write dnsmasq configuration of %s: %v\n", dhcp.Name, err)
+		return fmt.Sprintf("LGM: Failed to write dnsmasq configuration of %s: %v\n", dhcp.Name, err), false
+	}
+	cmd := []string{"dnsmasq", "--conf-file=" + dhcpConfPath(dhcp)}
+	if path.Base(svi.Spec.Vrf) != "GRD" {
+		vrfName := infradb.LinkName(svi.Spec.Vrf, infradb.LinkRoleVrf)
+		cmd = append([]string{"ip", "vrf", "exec", vrfName}, cmd...)
+	}
+	// Example: ip vrf exec <vrf> dnsmasq --conf-file=/run/opi-evpn/dhcp-<id>.conf
+	CP, err1 := run(cmd, false)
+	if err1 != 0 {
+		log.Printf("LGM: Failed to start dnsmasq for dhcp server %s: %s\n", dhcp.Name, CP)
+		return fmt.Sprintf("LGM: Failed to start dnsmasq for dhcp server %s: %s\n", dhcp.Name, CP), false
+	}
+	log.Printf("LGM Executed : %s\n", strings.Join(cmd, " "))
+	return "", true
+}
+
+// tearDownDHCPServer stops the dnsmasq instance of the dhcp server, the leases go with it
+func tearDownDHCPServer(dhcp *infradb.DHCPServer) (string, bool) {
+	if err := stopDaemon(dhcpPidPath(dhcp)); err != nil {
+		log.Printf("LGM: Failed to stop dhcp server %s: %v\n", dhcp.Name, err)
+		return fmt.Sprintf("LGM: Failed to stop dhcp server %s: %v\n", dhcp.Name, err), false
+	}
+	for _, file := range []string{dhcpConfPath(dhcp), dhcpLeasePath(dhcp)} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			log.Printf("LGM: Failed to remove %s: %v\n", file, err)
+			return fmt.Sprintf("LGM: Failed to remove %s: %v\n", file, err), false
+		}
+	}
+	log.Printf("LGM Executed : kill dnsmasq of dhcp server %s\n", dhcp.Name)
+	return "", true
+}
+
+// DHCPLease is an address leased by a dhcp server
+type DHCPLease struct {
+	IP net.IP
+	// MacAddress is the MAC of the IPv4 clients, the IPv6 clients are known by their ClientID
+	MacAddress string
+	Hostname   string
+	ClientID   string
+	Expires    time.Time
+}
+
+// parseDHCPLeases parses the dnsmasq lease file, the IPv4 leases read "<expiry> <mac> <ip> <hostname> <client-id>"
+// and the IPv6 ones "<expiry> <iaid> <ip> <hostname> <duid>" after the "duid" line of the server
+func parseDHCPLeases(data []byte) []DHCPLease {
+	leases := []DHCPLease{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] == "duid" {
+			continue
+		}
+		expiry, err := strconv.ParseInt(fields[0], 10, 64)
+		ip := net.ParseIP(fields[2])
+		if err != nil || ip == nil {
+			continue
+		}
+		lease := DHCPLease{IP: ip}
+		if expiry != 0 {
+			lease.Expires = time.Unix(expiry, 0)
+		}
+		if fields[3] != "*" {
+			lease.Hostname = fields[3]
+		}
+		if fields[4] != "*" {
+			lease.ClientID = fields[4]
+		}
+		if ip.To4() != nil {
+			lease.MacAddress = fields[1]
+		}
+		leases = append(leases, lease)
+	}
+	return leases
+}
+
+// GetDHCPLeases returns the addresses leased by the dhcp server
+func GetDHCPLeases(name string) ([]DHCPLease, error) {
+	dhcp, err := infradb.GetDHCPServer(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(dhcpLeasePath(dhcp))
+	if os.IsNotExist(err) {
+		return []DHCPLease{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseDHCPLeases(data), nil
+}
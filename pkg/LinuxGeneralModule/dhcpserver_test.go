@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// testDHCPServer returns a dhcp server of the subnet
+func testDHCPServer(subnet, start, end string, dns ...string) *infradb.DHCPServer {
+	_, ipnet, _ := net.ParseCIDR(subnet)
+	spec := &infradb.DHCPServerSpec{
+		Svi: "//network.opiproject.org/svis/web", Subnet: ipnet,
+		RangeStart: net.ParseIP(start), RangeEnd: net.ParseIP(end), LeaseTime: 3600,
+	}
+	for _, ip := range dns {
+		spec.DNSServers = append(spec.DNSServers, net.ParseIP(ip))
+	}
+	return &infradb.DHCPServer{Resource: infradb.Resource{Name: "//network.opiproject.org/dhcpservers/web"}, Spec: spec}
+}
+
+func Test_DHCPConfig(t *testing.T) {
+	mac, _ := net.ParseMAC("aa:bb:cc:00:00:01")
+	dhcp := testDHCPServer("10.0.0.0/24", "10.0.0.100", "10.0.0.200", "10.0.0.53", "10.0.1.53")
+	dhcp.Spec.Mtu = 1450
+	conf := dhcpConfig(dhcp, "blue-10", net.ParseIP("10.0.0.1"), []dhcpHost{{mac: mac, ip: net.ParseIP("10.0.0.5")}})
+	for _, expected := range []string{
+		"port=0\n",
+		"interface=blue-10\n",
+		"dhcp-range=10.0.0.100,10.0.0.200,255.255.255.0,3600\n",
+		"dhcp-option=option:router,10.0.0.1\n",
+		"dhcp-option=option:mtu,1450\n",
+		"dhcp-option=option:dns-server,10.0.0.53,10.0.1.53\n",
+		"dhcp-host=aa:bb:cc:00:00:01,10.0.0.5\n",
+	} {
+		if !strings.Contains(conf, expected) {
+			t.Errorf("expected %q in the configuration:\n%s", expected, conf)
+		}
+	}
+
+	dhcp = testDHCPServer("2001:db8::/64", "2001:db8::100", "2001:db8::1ff", "2001:db8::53")
+	conf = dhcpConfig(dhcp, "blue-10", net.ParseIP("2001:db8::1"), []dhcpHost{{mac: mac, ip: net.ParseIP("2001:db8::5")}})
+	for _, expected := range []string{
+		"dhcp-range=2001:db8::100,2001:db8::1ff,64,3600\n",
+		"dhcp-option=option6:dns-server,[2001:db8::53]\n",
+		"dhcp-host=aa:bb:cc:00:00:01,[2001:db8::5]\n",
+	} {
+		if !strings.Contains(conf, expected) {
+			t.Errorf("expected %q in the configuration:\n%s", expected, conf)
+		}
+	}
+	if strings.Contains(conf, "option:router") {
+		t.Errorf("expected no router option for DHCPv6, the hosts learn it from the RAs:\n%s", conf)
+	}
+}
+
+func Test_ParseDHCPLeases(t *testing.T) {
+	leases := parseDHCPLeases([]byte(`1700000000 aa:bb:cc:00:00:01 10.0.0.100 web-1 01:aa:bb:cc:00:00:01
+0 aa:bb:cc:00:00:02 10.0.0.5 * *
+duid 00:01:00:01:2c:5f:2a:10:aa:bb:cc:00:00:ff
+1700000000 12345 2001:db8::100 web-2 00:03:00:01:aa:bb:cc:00:00:03
+`))
+	if len(leases) != 3 {
+		t.Fatalf("expected 3 leases, received %+v", leases)
+	}
+	if l := leases[0]; l.MacAddress != "aa:bb:cc:00:00:01" || l.Hostname != "web-1" || !l.Expires.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("unexpected IPv4 lease %+v", l)
+	}
+	if l := leases[1]; !l.Expires.IsZero() || l.Hostname != "" || l.ClientID != "" {
+		t.Errorf("expected the infinite lease without hostname, received %+v", l)
+	}
+	if l := leases[2]; l.MacAddress != "" || l.ClientID != "00:03:00:01:aa:bb:cc:00:00:03" || !l.IP.Equal(net.ParseIP("2001:db8::100")) {
+		t.Errorf("unexpected IPv6 lease %+v", l)
+	}
+}
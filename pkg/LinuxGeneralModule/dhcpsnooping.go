@@ -0,0 +1,526 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// Comments of the nftables rules of a dhcp snooping, they tell the drop counters apart
+const (
+	snoopServerRule = "rogue-server"
+	snoopGuardRule  = "source-guard"
+)
+
+// The DHCP messages which change the bindings, RFC 2132
+const (
+	dhcpDecline = 4
+	dhcpAck     = 5
+	dhcpNak     = 6
+	dhcpRelease = 7
+)
+
+// dhcpInfiniteLease is the lease time of the addresses which do not expire
+const dhcpInfiniteLease = 0xffffffff
+
+// DHCPBinding is an address handed out by a DHCP server to a MAC address behind a bridge port
+type DHCPBinding struct {
+	BridgePort string
+	Mac        net.HardwareAddr
+	IP         net.IP
+	// Expires is zero for an infinite lease
+	Expires time.Time
+}
+
+// handleDHCPSnooping handles the dhcp snooping functionality
+func handleDHCPSnooping(objectData *eventbus.ObjectData) {
+	snooping, err := infradb.GetDHCPSnooping(objectData.Name)
+	handleResource(objectData, &snooping.Resource, err,
+		func() (string, bool) { return setUpDHCPSnooping(snooping) },
+		func() (string, bool) { return tearDownDHCPSnooping(snooping) },
+		infradb.UpdateDHCPSnoopingStatus)
+}
+
+// snoopTableName returns the nftables table used for the dhcp snooping
+func snoopTableName(name string) string {
+	return "opi-dhcpsnoop-" + path.Base(name)
+}
+
+// snoopedPorts returns the linux devices of the untrusted ports of the dhcp snooping by bridge port: the access
+// ports of the logical bridge which are not trusted, the trunks carry the servers and relays of the fabric. The ports
+// are read as the dhcp snooping is set up.
+func snoopedPorts(snooping *infradb.DHCPSnooping) (map[string]string, error) {
+	bps, err := infradb.GetAllBPs()
+	if err != nil && !errors.Is(err, infradb.ErrKeyNotFound) {
+		return nil, err
+	}
+	ports := map[string]string{}
+	for _, bp := range bps {
+		if bp.Spec.Ptype != infradb.Access || snooping.Spec.IsTrusted(bp.Name) {
+			continue
+		}
+		for _, lb := range bp.Spec.LogicalBridges {
+			if lb == snooping.Spec.LogicalBridge {
+				ports[bp.Name] = path.Base(bp.Name)
+			}
+		}
+	}
+	return ports, nil
+}
+
+// bindingElement renders the element of the nftables set of a binding, which expires with the lease
+func bindingElement(link string, b *DHCPBinding, now time.Time) string {
+	element := fmt.Sprintf("\"%s\" . %s . %s", link, b.Mac, b.IP)
+	if !b.Expires.IsZero() {
+		element += fmt.Sprintf(" timeout %ds", max(int(b.Expires.Sub(now).Seconds()), 1))
+	}
+	return element
+}
+
+// snoopRuleset renders the nftables ruleset of the dhcp snooping. The DHCP server messages received on the untrusted
+// ports are dropped and, with the source guard, the IPv4 packets and ARP messages of these ports whose source is not
+// bound to the port and the MAC address. The bindings are restored into the set, the DHCP clients get their addresses
+// from 0.0.0.0.
+func snoopRuleset(table string, spec *infradb.DHCPSnoopingSpec, links []string, elements []string) string {
+	var b strings.Builder
+	// Declaring the table first makes the delete succeed when the table is not there yet
+	fmt.Fprintf(&b, "table bridge %s {}\n", table)
+	fmt.Fprintf(&b, "delete table bridge %s\n", table)
+	fmt.Fprintf(&b, "table bridge %s {\n", table)
+	fmt.Fprintf(&b, "\tset bindings {\n\t\ttype ifname . ether_addr . ipv4_addr\n\t\tflags timeout\n")
+	if len(elements) != 0 {
+		fmt.Fprintf(&b, "\t\telements = { %s }\n", strings.Join(elements, ", "))
+	}
+	fmt.Fprintf(&b, "\t}\n")
+	fmt.Fprintf(&b, "\tchain prerouting {\n\t\ttype filter hook prerouting priority filter; policy accept;\n")
+	if len(links) != 0 {
+		quoted := make([]string, 0, len(links))
+		for _, link := range links {
+			quoted = append(quoted, "\""+link+"\"")
+		}
+		ports := strings.Join(quoted, ", ")
+		fmt.Fprintf(&b, "\t\tiifname { %s } ether type ip udp sport 67 udp dport 68 counter drop comment \"%s\"\n",
+			ports, snoopServerRule)
+		if spec.SourceGuard {
+			fmt.Fprintf(&b, "\t\tiifname { %s } ether type ip ip saddr != 0.0.0.0 iifname . ether saddr . ip saddr != @bindings counter drop comment \"%s\"\n",
+				ports, snoopGuardRule)
+			fmt.Fprintf(&b, "\t\tiifname { %s } ether type arp arp saddr ip != 0.0.0.0 iifname . arp saddr ether . arp saddr ip != @bindings counter drop comment \"%s\"\n",
+				ports, snoopGuardRule)
+		}
+	}
+	fmt.Fprintf(&b, "\t}\n}\n")
+	return b.String()
+}
+
+// dhcpMessage holds the fields of a DHCP message which the bindings are made of
+type dhcpMessage struct {
+	msgType   byte
+	ciaddr    net.IP
+	yiaddr    net.IP
+	chaddr    net.HardwareAddr
+	requested net.IP
+	// lease is the lease time in seconds
+	lease uint32
+}
+
+// parseDHCPFrame returns the DHCP message of an ethernet frame, false when the frame does not carry one
+func parseDHCPFrame(frame []byte) (*dhcpMessage, bool) {
+	const ethLen, udpLen, bootpLen = 14, 8, 240
+	if len(frame) < ethLen || binary.BigEndian.Uint16(frame[12:]) != unix.ETH_P_IP {
+		return nil, false
+	}
+	ip := frame[ethLen:]
+	if len(ip) < 20 || ip[0]>>4 != 4 || ip[9] != unix.IPPROTO_UDP || binary.BigEndian.Uint16(ip[6:])&0x1fff != 0 {
+		return nil, false
+	}
+	ihl := int(ip[0]&0xf) * 4
+	if len(ip) < ihl+udpLen+bootpLen {
+		return nil, false
+	}
+	udp := ip[ihl:]
+	sport, dport := binary.BigEndian.Uint16(udp), binary.BigEndian.Uint16(udp[2:])
+	if (sport != 67 || dport != 68) && (sport != 68 || dport != 67) {
+		return nil, false
+	}
+	bootp := udp[udpLen:]
+	// ethernet hardware addresses only
+	if bootp[1] != 1 || bootp[2] != 6 || binary.BigEndian.Uint32(bootp[236:]) != 0x63825363 {
+		return nil, false
+	}
+	msg := &dhcpMessage{
+		ciaddr: net.IP(bytes.Clone(bootp[12:16])),
+		yiaddr: net.IP(bytes.Clone(bootp[16:20])),
+		chaddr: net.HardwareAddr(bytes.Clone(bootp[28:34])),
+	}
+	for opts := bootp[bootpLen:]; len(opts) > 0; {
+		code := opts[0]
+		if code == 255 {
+			break
+		}
+		if code == 0 {
+			opts = opts[1:]
+			continue
+		}
+		if len(opts) < 2 || len(opts) < 2+int(opts[1]) {
+			return nil, false
+		}
+		data := opts[2 : 2+int(opts[1])]
+		switch {
+		case code == 53 && len(data) == 1:
+			msg.msgType = data[0]
+		case code == 51 && len(data) == 4:
+			msg.lease = binary.BigEndian.Uint32(data)
+		case code == 50 && len(data) == 4:
+			msg.requested = net.IP(bytes.Clone(data))
+		}
+		opts = opts[2+len(data):]
+	}
+	return msg, msg.msgType != 0
+}
+
+// dhcpSnooper builds the bindings of the untrusted ports of a dhcp snooping from their DHCP messages
+type dhcpSnooper struct {
+	mu    sync.Mutex
+	table string
+	spec  infradb.DHCPSnoopingSpec
+	// ports are the linux devices of the untrusted bridge ports
+	ports    map[string]string
+	bindings []*DHCPBinding
+	now      func() time.Time
+	// apply loads the changes of the bindings into the nftables set
+	apply func(ruleset string) (string, bool)
+	// done is closed once the captures of the ports returned
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newDHCPSnooper returns the snooper of the untrusted ports of the dhcp snooping
+func newDHCPSnooper(snooping *infradb.DHCPSnooping, ports map[string]string) *dhcpSnooper {
+	return &dhcpSnooper{
+		table: snoopTableName(snooping.Name),
+		spec:  *snooping.Spec,
+		ports: ports,
+		now:   time.Now,
+		apply: applyNftables,
+	}
+}
+
+// expire drops the bindings whose lease has expired, the caller must hold the lock
+func (s *dhcpSnooper) expire(now time.Time) {
+	kept := s.bindings[:0]
+	for _, b := range s.bindings {
+		if b.Expires.IsZero() || b.Expires.After(now) {
+			kept = append(kept, b)
+		}
+	}
+	s.bindings = kept
+}
+
+// unbind removes the bindings matching the filter from the table and the set, the caller must hold the lock
+func (s *dhcpSnooper) unbind(match func(b *DHCPBinding) bool) []string {
+	cmds := []string{}
+	kept := s.bindings[:0]
+	for _, b := range s.bindings {
+		if !match(b) {
+			kept = append(kept, b)
+			continue
+		}
+		// adding the element first makes the delete succeed when it timed out already
+		element := fmt.Sprintf("\"%s\" . %s . %s", s.ports[b.BridgePort], b.Mac, b.IP)
+		cmds = append(cmds, fmt.Sprintf("add element bridge %s bindings { %s }", s.table, element),
+			fmt.Sprintf("delete element bridge %s bindings { %s }", s.table, element))
+	}
+	s.bindings = kept
+	return cmds
+}
+
+// handle updates the bindings of the bridge port with its DHCP message, the acks and naks of the servers leave the
+// port while the releases and declines of the clients enter it
+func (s *dhcpSnooper) handle(port string, outgoing bool, msg *dhcpMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.expire(now)
+	var cmds []string
+	switch {
+	case outgoing && msg.msgType == dhcpAck && !msg.yiaddr.IsUnspecified():
+		// the address moves to the client, whatever it was bound to
+		cmds = s.unbind(func(b *DHCPBinding) bool { return b.IP.Equal(msg.yiaddr) })
+		binding := &DHCPBinding{BridgePort: port, Mac: msg.chaddr, IP: msg.yiaddr}
+		if msg.lease != dhcpInfiniteLease {
+			binding.Expires = now.Add(time.Duration(msg.lease) * time.Second)
+		}
+		s.bindings = append(s.bindings, binding)
+		cmds = append(cmds, fmt.Sprintf("add element bridge %s bindings { %s }", s.table, bindingElement(s.ports[port], binding, now)))
+		log.Printf("LGM: dhcp snooping bound %s to %s on %s\n", msg.yiaddr, msg.chaddr, port)
+	case outgoing && msg.msgType == dhcpNak:
+		cmds = s.unbind(func(b *DHCPBinding) bool {
+			return b.BridgePort == port && bytes.Equal(b.Mac, msg.chaddr)
+		})
+	case !outgoing && (msg.msgType == dhcpRelease || msg.msgType == dhcpDecline):
+		ip := msg.ciaddr
+		if msg.msgType == dhcpDecline {
+			ip = msg.requested
+		}
+		cmds = s.unbind(func(b *DHCPBinding) bool {
+			return b.BridgePort == port && bytes.Equal(b.Mac, msg.chaddr) && b.IP.Equal(ip)
+		})
+	}
+	if !s.spec.SourceGuard || len(cmds) == 0 {
+		return
+	}
+	if details, ok := s.apply(strings.Join(cmds, "\n") + "\n"); !ok {
+		log.Print(details)
+	}
+}
+
+// Bindings returns the bindings whose lease has not expired, by bridge port and address
+func (s *dhcpSnooper) Bindings() []*DHCPBinding {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(s.now())
+	out := make([]*DHCPBinding, 0, len(s.bindings))
+	for _, b := range s.bindings {
+		copied := *b
+		out = append(out, &copied)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].BridgePort != out[j].BridgePort {
+			return out[i].BridgePort < out[j].BridgePort
+		}
+		return bytes.Compare(out[i].IP.To16(), out[j].IP.To16()) < 0
+	})
+	return out
+}
+
+// elements renders the bindings of the ports into the elements of the nftables set
+func (s *dhcpSnooper) elements() []string {
+	now := s.now()
+	elements := []string{}
+	for _, b := range s.Bindings() {
+		if link, ok := s.ports[b.BridgePort]; ok {
+			elements = append(elements, bindingElement(link, b, now))
+		}
+	}
+	return elements
+}
+
+// dhcpFilter keeps the udp datagrams from or to the DHCP ports of the unfragmented IPv4 packets
+var dhcpFilter = []bpf.Instruction{
+	bpf.LoadAbsolute{Off: 12, Size: 2},
+	bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: unix.ETH_P_IP, SkipTrue: 11},
+	bpf.LoadAbsolute{Off: 23, Size: 1},
+	bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: unix.IPPROTO_UDP, SkipTrue: 9},
+	bpf.LoadAbsolute{Off: 20, Size: 2},
+	bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x1fff, SkipTrue: 7},
+	bpf.LoadMemShift{Off: 14},
+	bpf.LoadIndirect{Off: 14, Size: 2},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 67, SkipTrue: 5},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 68, SkipTrue: 4},
+	bpf.LoadIndirect{Off: 16, Size: 2},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 67, SkipTrue: 2},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 68, SkipTrue: 1},
+	bpf.RetConstant{Val: 0},
+	bpf.RetConstant{Val: 0x40000},
+}
+
+// snoopSocket opens the packet socket of the DHCP messages entering and leaving the device
+func snoopSocket(link string) (int, error) {
+	iface, err := net.InterfaceByName(link)
+	if err != nil {
+		return -1, err
+	}
+	raw, err := bpf.Assemble(dhcpFilter)
+	if err != nil {
+		return -1, err
+	}
+	filter := make([]unix.SockFilter, 0, len(raw))
+	for _, ins := range raw {
+		filter = append(filter, unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K})
+	}
+	proto := binary.NativeEndian.Uint16(binary.BigEndian.AppendUint16(nil, unix.ETH_P_IP))
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, int(proto))
+	if err != nil {
+		return -1, err
+	}
+	prog := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	if err := unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, &prog); err != nil {
+		unix.Close(fd)
+		return -1, err
+	}
+	if err := unix.Bind(fd, &unix.SockaddrLinklayer{Protocol: proto, Ifindex: iface.Index}); err != nil {
+		unix.Close(fd)
+		return -1, err
+	}
+	// the reads return regularly to notice the end of the context
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &unix.Timeval{Sec: 1}); err != nil {
+		unix.Close(fd)
+		return -1, err
+	}
+	return fd, nil
+}
+
+// snoop reads the DHCP messages of the bridge port until the context is done, the device may come up later
+func (s *dhcpSnooper) snoop(ctx context.Context, port, link string) {
+	for ctx.Err() == nil {
+		fd, err := snoopSocket(link)
+		if err != nil {
+			log.Printf("LGM: dhcp snooping of %s: %v\n", link, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+		buf := make([]byte, 1600)
+		for ctx.Err() == nil {
+			n, from, err := unix.Recvfrom(fd, buf, 0)
+			if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+				continue
+			}
+			if err != nil {
+				log.Printf("LGM: dhcp snooping of %s: %v\n", link, err)
+				break
+			}
+			ll, ok := from.(*unix.SockaddrLinklayer)
+			if !ok {
+				continue
+			}
+			if msg, ok := parseDHCPFrame(buf[:n]); ok {
+				s.handle(port, ll.Pkttype == unix.PACKET_OUTGOING, msg)
+			}
+		}
+		unix.Close(fd)
+	}
+}
+
+var (
+	dhcpSnoopersMu sync.Mutex
+	dhcpSnoopers   = make(map[string]*dhcpSnooper)
+)
+
+// startDHCPSnooper starts the snooper of the dhcp snooping, or keeps the running one when neither the dhcp snooping
+// nor its ports have changed. The bindings of the ports which are still snooped are kept.
+func startDHCPSnooper(snooping *infradb.DHCPSnooping, ports map[string]string) *dhcpSnooper {
+	dhcpSnoopersMu.Lock()
+	defer dhcpSnoopersMu.Unlock()
+
+	s := newDHCPSnooper(snooping, ports)
+	if running, ok := dhcpSnoopers[snooping.Name]; ok {
+		if reflect.DeepEqual(running.spec, *snooping.Spec) && reflect.DeepEqual(running.ports, ports) {
+			return running
+		}
+		running.cancel()
+		<-running.done
+		delete(dhcpSnoopers, snooping.Name)
+		for _, b := range running.Bindings() {
+			if _, ok := ports[b.BridgePort]; ok {
+				s.bindings = append(s.bindings, b)
+			}
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel, s.done = cancel, make(chan struct{})
+	dhcpSnoopers[snooping.Name] = s
+	var wg sync.WaitGroup
+	for port, link := range ports {
+		wg.Add(1)
+		go func(port, link string) {
+			defer wg.Done()
+			s.snoop(ctx, port, link)
+		}(port, link)
+	}
+	go func() {
+		wg.Wait()
+		close(s.done)
+	}()
+	return s
+}
+
+// stopDHCPSnooper stops the snooper of the dhcp snooping, its bindings are lost
+func stopDHCPSnooper(name string) {
+	dhcpSnoopersMu.Lock()
+	defer dhcpSnoopersMu.Unlock()
+
+	if running, ok := dhcpSnoopers[name]; ok {
+		running.cancel()
+		<-running.done
+		delete(dhcpSnoopers, name)
+	}
+}
+
+// setUpDHCPSnooping sets up the dhcp snooping
+func setUpDHCPSnooping(snooping *infradb.DHCPSnooping) (string, bool) {
+	ports, err := snoopedPorts(snooping)
+	if err != nil {
+		return fmt.Sprintf("LGM: Failed to get the ports of dhcp snooping %s: %v\n", snooping.Name, err), false
+	}
+	s := startDHCPSnooper(snooping, ports)
+	links := make([]string, 0, len(ports))
+	for _, link := range ports {
+		links = append(links, link)
+	}
+	sort.Strings(links)
+	table := snoopTableName(snooping.Name)
+	// Example: nft -f <ruleset of table bridge opi-dhcpsnoop-<id>>
+	if details, ok := applyNftables(snoopRuleset(table, snooping.Spec, links, s.elements())); !ok {
+		log.Print(details)
+		return details, false
+	}
+	log.Printf("LGM Executed : nft -f <table bridge %s>\n", table)
+	return "", true
+}
+
+// tearDownDHCPSnooping tears down the dhcp snooping
+func tearDownDHCPSnooping(snooping *infradb.DHCPSnooping) (string, bool) {
+	stopDHCPSnooper(snooping.Name)
+	table := snoopTableName(snooping.Name)
+	// Example: nft delete table bridge opi-dhcpsnoop-<id>
+	if details, ok := applyNftables(fmt.Sprintf("table bridge %s {}\ndelete table bridge %s\n", table, table)); !ok {
+		log.Print(details)
+		return details, false
+	}
+	log.Printf("LGM Executed : nft delete table bridge %s\n", table)
+	return "", true
+}
+
+// GetDHCPSnoopingBindings returns the bindings learnt by the dhcp snooping
+func GetDHCPSnoopingBindings(name string) ([]*DHCPBinding, error) {
+	snooping, err := infradb.GetDHCPSnooping(name)
+	if err != nil {
+		return nil, err
+	}
+	if snooping.Status.OperStatus != infradb.OperStatusUp {
+		return nil, fmt.Errorf("dhcp snooping %s is not operationally up", name)
+	}
+	dhcpSnoopersMu.Lock()
+	s, ok := dhcpSnoopers[name]
+	dhcpSnoopersMu.Unlock()
+	if !ok {
+		return []*DHCPBinding{}, nil
+	}
+	return s.Bindings(), nil
+}
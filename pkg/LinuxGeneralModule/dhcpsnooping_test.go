@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"encoding/binary"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// dhcpFrame returns the ethernet frame of a DHCP message of the type to the client MAC address
+func dhcpFrame(msgType byte, fromServer bool, chaddr string, ciaddr, yiaddr string, lease uint32) []byte {
+	bootp := make([]byte, 240)
+	bootp[0], bootp[1], bootp[2] = 1, 1, 6
+	if fromServer {
+		bootp[0] = 2
+	}
+	copy(bootp[12:], net.ParseIP(ciaddr).To4())
+	copy(bootp[16:], net.ParseIP(yiaddr).To4())
+	mac, _ := net.ParseMAC(chaddr)
+	copy(bootp[28:], mac)
+	binary.BigEndian.PutUint32(bootp[236:], 0x63825363)
+	bootp = append(bootp, 53, 1, msgType, 51, 4)
+	bootp = binary.BigEndian.AppendUint32(bootp, lease)
+	bootp = append(bootp, 255)
+
+	udp := make([]byte, 8, 8+len(bootp))
+	sport, dport := uint16(68), uint16(67)
+	if fromServer {
+		sport, dport = 67, 68
+	}
+	binary.BigEndian.PutUint16(udp, sport)
+	binary.BigEndian.PutUint16(udp[2:], dport)
+	binary.BigEndian.PutUint16(udp[4:], uint16(8+len(bootp)))
+	udp = append(udp, bootp...)
+
+	ip := make([]byte, 20, 20+len(udp))
+	ip[0], ip[8], ip[9] = 0x45, 64, 17
+	binary.BigEndian.PutUint16(ip[2:], uint16(20+len(udp)))
+	ip = append(ip, udp...)
+
+	frame := make([]byte, 12, 14+len(ip))
+	frame = binary.BigEndian.AppendUint16(frame, 0x0800)
+	return append(frame, ip...)
+}
+
+func Test_ParseDHCPFrame(t *testing.T) {
+	ack := dhcpFrame(dhcpAck, true, "aa:bb:cc:00:00:01", "0.0.0.0", "10.0.0.5", 3600)
+	msg, ok := parseDHCPFrame(ack)
+	if !ok {
+		t.Fatal("expected the ack to be parsed")
+	}
+	if msg.msgType != dhcpAck || msg.chaddr.String() != "aa:bb:cc:00:00:01" || !msg.yiaddr.Equal(net.ParseIP("10.0.0.5")) || msg.lease != 3600 {
+		t.Errorf("unexpected message %+v", msg)
+	}
+
+	vm, err := bpf.NewVM(dhcpFilter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := vm.Run(ack); err != nil || n == 0 {
+		t.Errorf("expected the filter to keep the ack, received %d %v", n, err)
+	}
+	other := append([]byte{}, ack...)
+	// tcp instead of udp
+	other[14+9] = 6
+	if n, err := vm.Run(other); err != nil || n != 0 {
+		t.Errorf("expected the filter to drop the tcp packet, received %d %v", n, err)
+	}
+	if _, ok := parseDHCPFrame(other); ok {
+		t.Error("expected the tcp packet not to be parsed")
+	}
+	// truncated options
+	if _, ok := parseDHCPFrame(ack[:len(ack)-3]); ok {
+		t.Error("expected the truncated ack not to be parsed")
+	}
+}
+
+func Test_DHCPSnooperHandle(t *testing.T) {
+	port := "//network.opiproject.org/ports/eth2"
+	snooping := &infradb.DHCPSnooping{
+		Resource: infradb.Resource{Name: "//network.opiproject.org/dhcpsnoopings/blue"},
+		Spec:     &infradb.DHCPSnoopingSpec{LogicalBridge: "//network.opiproject.org/bridges/blue", SourceGuard: true},
+	}
+	s := newDHCPSnooper(snooping, map[string]string{port: "eth2"})
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	applied := []string{}
+	s.apply = func(ruleset string) (string, bool) {
+		applied = append(applied, ruleset)
+		return "", true
+	}
+	handle := func(outgoing bool, frame []byte) {
+		msg, ok := parseDHCPFrame(frame)
+		if !ok {
+			t.Fatal("expected the frame to be parsed")
+		}
+		s.handle(port, outgoing, msg)
+	}
+
+	// an ack received on the port comes from a rogue server
+	handle(false, dhcpFrame(dhcpAck, true, "aa:bb:cc:00:00:01", "0.0.0.0", "10.0.0.9", 3600))
+	handle(true, dhcpFrame(dhcpAck, true, "aa:bb:cc:00:00:01", "0.0.0.0", "10.0.0.5", 3600))
+	handle(true, dhcpFrame(dhcpAck, true, "aa:bb:cc:00:00:02", "0.0.0.0", "10.0.0.6", dhcpInfiniteLease))
+	expected := []*DHCPBinding{
+		{BridgePort: port, Mac: net.HardwareAddr{0xaa, 0xbb, 0xcc, 0, 0, 1}, IP: net.ParseIP("10.0.0.5").To4(), Expires: now.Add(time.Hour)},
+		{BridgePort: port, Mac: net.HardwareAddr{0xaa, 0xbb, 0xcc, 0, 0, 2}, IP: net.ParseIP("10.0.0.6").To4()},
+	}
+	if bindings := s.Bindings(); !reflect.DeepEqual(bindings, expected) {
+		t.Errorf("expected the bindings %+v, received %+v", expected, bindings)
+	}
+
+	// the client releases its address
+	handle(false, dhcpFrame(dhcpRelease, false, "aa:bb:cc:00:00:02", "10.0.0.6", "0.0.0.0", 0))
+	// the lease of the other one expires
+	now = now.Add(2 * time.Hour)
+	if bindings := s.Bindings(); len(bindings) != 0 {
+		t.Errorf("expected no binding, received %+v", bindings)
+	}
+
+	expectedApplied := []string{
+		"add element bridge opi-dhcpsnoop-blue bindings { \"eth2\" . aa:bb:cc:00:00:01 . 10.0.0.5 timeout 3600s }\n",
+		"add element bridge opi-dhcpsnoop-blue bindings { \"eth2\" . aa:bb:cc:00:00:02 . 10.0.0.6 }\n",
+		"add element bridge opi-dhcpsnoop-blue bindings { \"eth2\" . aa:bb:cc:00:00:02 . 10.0.0.6 }\n" +
+			"delete element bridge opi-dhcpsnoop-blue bindings { \"eth2\" . aa:bb:cc:00:00:02 . 10.0.0.6 }\n",
+	}
+	if !reflect.DeepEqual(applied, expectedApplied) {
+		t.Errorf("expected the nftables changes %q, received %q", expectedApplied, applied)
+	}
+}
+
+func Test_SnoopRuleset(t *testing.T) {
+	spec := &infradb.DHCPSnoopingSpec{LogicalBridge: "//network.opiproject.org/bridges/blue", SourceGuard: true}
+	ruleset := snoopRuleset("opi-dhcpsnoop-blue", spec, []string{"eth2", "eth3"}, []string{"\"eth2\" . aa:bb:cc:00:00:01 . 10.0.0.5 timeout 60s"})
+	for _, expected := range []string{
+		"delete table bridge opi-dhcpsnoop-blue\n",
+		"elements = { \"eth2\" . aa:bb:cc:00:00:01 . 10.0.0.5 timeout 60s }\n",
+		"iifname { \"eth2\", \"eth3\" } ether type ip udp sport 67 udp dport 68 counter drop comment \"rogue-server\"\n",
+		"iifname . ether saddr . ip saddr != @bindings counter drop comment \"source-guard\"\n",
+		"iifname . arp saddr ether . arp saddr ip != @bindings counter drop comment \"source-guard\"\n",
+	} {
+		if !strings.Contains(ruleset, expected) {
+			t.Errorf("expected %q in the ruleset:\n%s", expected, ruleset)
+		}
+	}
+	spec.SourceGuard = false
+	if ruleset := snoopRuleset("opi-dhcpsnoop-blue", spec, []string{"eth2"}, nil); strings.Contains(ruleset, "source-guard") {
+		t.Errorf("expected no source guard:\n%s", ruleset)
+	}
+}
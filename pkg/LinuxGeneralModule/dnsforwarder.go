@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// dnsRunDir is the location of the dnsmasq configuration and pid files
+var dnsRunDir = "/run/opi-evpn"
+
+// handleDNSForwarder handles the dns forwarder functionality
+func handleDNSForwarder(objectData *eventbus.ObjectData) {
+	dns, err := infradb.GetDNSForwarder(objectData.Name)
+	handleResource(objectData, &dns.Resource, err,
+		func() (string, bool) { return setUpDNSForwarder(dns) },
+		func() (string, bool) { return tearDownDNSForwarder(dns) },
+		infradb.UpdateDNSForwarderStatus)
+}
+
+// dnsConfPath returns the dnsmasq configuration file of the dns forwarder
+func dnsConfPath(dns *infradb.DNSForwarder) string {
+	return path.Join(dnsRunDir, "dns-"+path.Base(dns.Name)+".conf")
+}
+
+// dnsPidPath returns the dnsmasq pid file of the dns forwarder
+func dnsPidPath(dns *infradb.DNSForwarder) string {
+	return path.Join(dnsRunDir, "dns-"+path.Base(dns.Name)+".pid")
+}
+
+// dnsListenAddresses returns the addresses the dns forwarder answers on
+func dnsListenAddresses(dns *infradb.DNSForwarder) ([]string, error) {
+	addresses := []string{}
+	for _, ip := range dns.Spec.ListenAddresses {
+		addresses = append(addresses, ip.String())
+	}
+	if len(addresses) != 0 {
+		return addresses, nil
+	}
+	vrf, err := infradb.GetVrf(dns.Spec.Vrf)
+	if err != nil {
+		return nil, err
+	}
+	for sviName := range vrf.Svis {
+		svi, err := infradb.GetSvi(sviName)
+		if err != nil {
+			return nil, err
+		}
+		for _, gwIP := range svi.Spec.GatewayIPs {
+			addresses = append(addresses, gwIP.IP.String())
+		}
+	}
+	sort.Strings(addresses)
+	return addresses, nil
+}
+
+// dnsConfig renders the dnsmasq configuration of the dns forwarder
+func dnsConfig(dns *infradb.DNSForwarder, addresses []string) string {
+	var b strings.Builder
+	// Only the configured servers are used, the resolv.conf of the host would leak queries out of the VRF
+	fmt.Fprintf(&b, "no-resolv\nno-hosts\nbind-interfaces\nexcept-interface=lo\n")
+	fmt.Fprintf(&b, "pid-file=%s\n", dnsPidPath(dns))
+	for _, addr := range addresses {
+		fmt.Fprintf(&b, "listen-address=%s\n", addr)
+	}
+	for _, fwd := range dns.Spec.ConditionalForwarders {
+		for _, server := range fwd.Servers {
+			fmt.Fprintf(&b, "server=/%s/%s\n", strings.Trim(fwd.Domain, "."), server)
+		}
+	}
+	for _, server := range dns.Spec.Upstreams {
+		fmt.Fprintf(&b, "server=%s\n", server)
+	}
+	return b.String()
+}
+
+// stopDNSForwarder stops the dnsmasq instance of the dns forwarder if it is running
+func stopDNSForwarder(dns *infradb.DNSForwarder) error {
+	return stopDaemon(dnsPidPath(dns))
+}
+
+// stopDaemon stops the daemon of the pid file if it is running
+func stopDaemon(pidPath string) error {
+	data, err := os.ReadFile(pidPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return err
+	}
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil && err != syscall.ESRCH {
+		return err
+	}
+	return os.Remove(pidPath)
+}
+
+// setUpDNSForwarder starts the dnsmasq instance of the dns forwarder inside the VRF
+func setUpDNSForwarder(dns *infradb.DNSForwarder) (string, bool) {
+	addresses, err := dnsListenAddresses(dns)
+	if err != nil {
+		log.Printf("LGM: Failed to resolve listen addresses of dns forwarder %s: %v\n", dns.Name, err)
+		return fmt.Sprintf("LGM: Failed to resolve listen addresses of dns forwarder %s: %v\n", dns.Name, err), false
+	}
+	if len(addresses) == 0 {
+		log.Printf("LGM: Dns forwarder %s has no address to listen on\n", dns.Name)
+		return fmt.Sprintf("LGM: Dns forwarder %s has no address to listen on\n", dns.Name), false
+	}
+	if err := os.MkdirAll(dnsRunDir, 0o755); err != nil {
+		log.Printf("LGM: Failed to create %s: %v\n", dnsRunDir, err)
+		return fmt.Sprintf("LGM: Failed to create %s: %v\n", dnsRunDir, err), false
+	}
+	if err := stopDNSForwarder(dns); err != nil {
+		log.Printf("LGM: Failed to stop dns forwarder %s: %v\n", dns.Name, err)
+		return fmt.Sprintf("LGM: Failed to stop dns forwarder %s: %v\n", dns.Name, err), false
+	}
+	if err := os.WriteFile(dnsConfPath(dns), []byte(dnsConfig(dns, addresses)), 0o600); err != nil {
+		log.Printf("LGM: Failed to write dnsmasq configuration of %s: %v\n", dns.Name, err)
+		return fmt.Sprintf("LGM: Failed to write dnsmasq configuration of %s: %v\n", dns.Name, err), false
+	}
+	cmd := []string{"dnsmasq", "--conf-file=" + dnsConfPath(dns)}
+	if path.Base(dns.Spec.Vrf) != "GRD" {
+		vrfName := infradb.LinkName(dns.Spec.Vrf, infradb.LinkRoleVrf)
+		// The sockets inherit the VRF so that the upstream queries never leave the tenant
+		cmd = append([]string{"ip", "vrf", "exec", vrfName}, cmd...)
+	}
+	// Example: ip vrf exec <vrf> dnsmasq --conf-file=/run/opi-evpn/dns-<id>.conf
+	CP, err1 := run(cmd, false)
+	if err1 != 0 {
+		log.Printf("LGM: Failed to start dnsmasq for dns forwarder %s: %s\n", dns.Name, CP)
+		return fmt.Sprintf("LGM: Failed to start dnsmasq for dns forwarder %s: %s\n", dns.Name, CP), false
+	}
+	log.Printf("LGM Executed : %s\n", strings.Join(cmd, " "))
+	return "", true
+}
+
+// tearDownDNSForwarder stops the dnsmasq instance of the dns forwarder
+func tearDownDNSForwarder(dns *infradb.DNSForwarder) (string, bool) {
+	if err := stopDNSForwarder(dns); err != nil {
+		log.Printf("LGM: Failed to stop dns forwarder %s: %v\n", dns.Name, err)
+		return fmt.Sprintf("LGM: Failed to stop dns forwarder %s: %v\n", dns.Name, err), false
+	}
+	if err := os.Remove(dnsConfPath(dns)); err != nil && !os.IsNotExist(err) {
+		log.Printf("LGM: Failed to remove dnsmasq configuration of %s: %v\n", dns.Name, err)
+		return fmt.Sprintf("LGM: Failed to remove dnsmasq configuration of %s: %v\n", dns.Name, err), false
+	}
+	log.Printf("LGM Executed : kill dnsmasq of dns forwarder %s\n", dns.Name)
+	return "", true
+}
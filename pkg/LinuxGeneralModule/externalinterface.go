@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"syscall"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/vishvananda/netlink"
+)
+
+// handleExternalInterface handles the external interface functionality
+func handleExternalInterface(objectData *eventbus.ObjectData) {
+	eif, err := infradb.GetExternalInterface(objectData.Name)
+	handleResource(objectData, &eif.Resource, err,
+		func() (string, bool) { return setUpExternalInterface(eif) },
+		func() (string, bool) { return tearDownExternalInterface(eif) },
+		infradb.UpdateExternalInterfaceStatus)
+}
+
+// setUpExternalInterface attaches the upstream interface to the vrf
+func setUpExternalInterface(eif *infradb.ExternalInterface) (string, bool) {
+	linkName := eif.Spec.LinkName()
+	parent, err := nlink.LinkByName(ctx, eif.Spec.Interface)
+	if err != nil {
+		log.Printf("LGM: Failed to get link information for %s: %v\n", eif.Spec.Interface, err)
+		return fmt.Sprintf("LGM: Failed to get link information for %s: %v\n", eif.Spec.Interface, err), false
+	}
+	link := parent
+	if eif.Spec.VlanID != 0 {
+		link, err = nlink.LinkByName(ctx, linkName)
+		if err != nil {
+			// Example: ip link add link <interface> name <interface>.<vlan> type vlan id <vlan>
+			vlan := &netlink.Vlan{LinkAttrs: netlink.LinkAttrs{Name: linkName, ParentIndex: parent.Attrs().Index}, VlanId: int(eif.Spec.VlanID)}
+			if err := nlink.LinkAdd(ctx, vlan); err != nil {
+				log.Printf("LGM: Failed to create vlan link %s: %v\n", linkName, err)
+				return fmt.Sprintf("LGM: Failed to create vlan link %s: %v\n", linkName, err), false
+			}
+			if err := tagLink(vlan, eif.Name); err != nil {
+				return fmt.Sprintf("LGM: Failed to set the alias of vlan link %s: %v\n", linkName, err), false
+			}
+			log.Printf("LGM Executed : ip link add link %s name %s type vlan id %d\n", eif.Spec.Interface, linkName, eif.Spec.VlanID)
+			link = vlan
+		}
+	}
+	if path.Base(eif.Spec.Vrf) != "GRD" {
+		vrfName := infradb.LinkName(eif.Spec.Vrf, infradb.LinkRoleVrf)
+		vrfLink, err := nlink.LinkByName(ctx, vrfName)
+		if err != nil {
+			log.Printf("LGM: Failed to get link information for %s: %v\n", vrfName, err)
+			return fmt.Sprintf("LGM: Failed to get link information for %s: %v\n", vrfName, err), false
+		}
+		// Example: ip link set <link> master <vrf>
+		if err := nlink.LinkSetMaster(ctx, link, vrfLink); err != nil {
+			log.Printf("LGM: Failed to add %s to vrf %s: %v\n", linkName, vrfName, err)
+			return fmt.Sprintf("LGM: Failed to add %s to vrf %s: %v\n", linkName, vrfName, err), false
+		}
+		log.Printf("LGM Executed : ip link set %s master %s\n", linkName, vrfName)
+	}
+	// Example: ip link set <link> up
+	if err := nlink.LinkSetUp(ctx, link); err != nil {
+		log.Printf("LGM: Failed to set up link %s: %v\n", linkName, err)
+		return fmt.Sprintf("LGM: Failed to set up link %s: %v\n", linkName, err), false
+	}
+	// Example: ip address add <address> dev <link>
+	addr := &netlink.Addr{IPNet: eif.Spec.Address}
+	if err := nlink.AddrAdd(ctx, link, addr); err != nil && !errors.Is(err, syscall.EEXIST) {
+		log.Printf("LGM: Failed to add address %s to %s: %v\n", eif.Spec.Address, linkName, err)
+		return fmt.Sprintf("LGM: Failed to add address %s to %s: %v\n", eif.Spec.Address, linkName, err), false
+	}
+	log.Printf("LGM Executed : ip address add %s dev %s\n", eif.Spec.Address, linkName)
+	return "", true
+}
+
+// tearDownExternalInterface detaches the upstream interface from the vrf
+func tearDownExternalInterface(eif *infradb.ExternalInterface) (string, bool) {
+	linkName := eif.Spec.LinkName()
+	link, err := nlink.LinkByName(ctx, linkName)
+	if err != nil {
+		log.Printf("LGM: Nothing to tear down for external interface %s: %v\n", eif.Name, err)
+		return "", true
+	}
+	if eif.Spec.VlanID != 0 {
+		// Example: ip link delete <interface>.<vlan>
+		if err := nlink.LinkDel(ctx, link); err != nil {
+			log.Printf("LGM: Failed to delete link %s: %v\n", linkName, err)
+			return fmt.Sprintf("LGM: Failed to delete link %s: %v\n", linkName, err), false
+		}
+		log.Printf("LGM Executed : ip link delete %s\n", linkName)
+		return "", true
+	}
+	// Example: ip address del <address> dev <interface>
+	if err := nlink.AddrDel(ctx, link, &netlink.Addr{IPNet: eif.Spec.Address}); err != nil {
+		log.Printf("LGM: Failed to delete address %s from %s: %v\n", eif.Spec.Address, linkName, err)
+	}
+	if path.Base(eif.Spec.Vrf) != "GRD" {
+		vrfName := infradb.LinkName(eif.Spec.Vrf, infradb.LinkRoleVrf)
+		// Example: ip link set <interface> nomaster
+		if err := nlink.LinkSetNoMaster(ctx, link); err != nil {
+			log.Printf("LGM: Failed to remove %s from vrf %s: %v\n", linkName, vrfName, err)
+			return fmt.Sprintf("LGM: Failed to remove %s from vrf %s: %v\n", linkName, vrfName, err), false
+		}
+	}
+	log.Printf("LGM Executed : ip address del %s dev %s; ip link set %s nomaster\n", eif.Spec.Address, linkName, linkName)
+	return "", true
+}
@@ -0,0 +1,358 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	vnetlink "github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/ipfix"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// flowLogPoll is how often the exporters read the connection tracking table
+var flowLogPoll = time.Second
+
+// listConntrack returns the connection tracking entries of the family
+var listConntrack = func(family vnetlink.InetFamily) ([]*vnetlink.ConntrackFlow, error) {
+	return vnetlink.ConntrackTableList(vnetlink.ConntrackTable, family)
+}
+
+// handleFlowLog handles the flow log functionality
+func handleFlowLog(objectData *eventbus.ObjectData) {
+	fl, err := infradb.GetFlowLog(objectData.Name)
+	handleResource(objectData, &fl.Resource, err,
+		func() (string, bool) { return setUpFlowLog(fl) },
+		func() (string, bool) { return tearDownFlowLog(fl) },
+		infradb.UpdateFlowLogStatus)
+}
+
+// flowLogTableName returns the nftables table used for the flow log
+func flowLogTableName(fl *infradb.FlowLog) string {
+	return "opi-flowlog-" + path.Base(fl.Name)
+}
+
+// flowLogDevice returns the device that the logged flows enter: the vrf device, which the
+// packets of all the devices of the vrf go through, or the routed interface of the svi
+func flowLogDevice(fl *infradb.FlowLog) (string, error) {
+	if fl.Spec.Svi == "" {
+		return infradb.LinkName(fl.Spec.Vrf, infradb.LinkRoleVrf), nil
+	}
+	svi, err := infradb.GetSvi(fl.Spec.Svi)
+	if err != nil {
+		return "", err
+	}
+	lb, err := infradb.GetLB(svi.Spec.LogicalBridge)
+	if err != nil {
+		return "", err
+	}
+	return infradb.SviLinkName(svi, lb.Spec.VlanID), nil
+}
+
+// flowLogRuleset renders the nftables ruleset marking the sampled new flows entering the device
+func flowLogRuleset(fl *infradb.FlowLog, dev string) string {
+	table := flowLogTableName(fl)
+	var sample string
+	if fl.Spec.SamplingRate > 1 {
+		sample = fmt.Sprintf(" numgen random mod %d == 0", fl.Spec.SamplingRate)
+	}
+	var b strings.Builder
+	// Declaring the table first makes the delete succeed when the table is not there yet
+	fmt.Fprintf(&b, "table inet %s {}\n", table)
+	fmt.Fprintf(&b, "delete table inet %s\n", table)
+	fmt.Fprintf(&b, "table inet %s {\n", table)
+	fmt.Fprintf(&b, "\tchain prerouting {\n\t\ttype filter hook prerouting priority mangle; policy accept;\n")
+	fmt.Fprintf(&b, "\t\tiifname \"%s\" ct state new%s ct mark set 0x%x\n", dev, sample, fl.Mark)
+	fmt.Fprintf(&b, "\t}\n}\n")
+	return b.String()
+}
+
+// enableConntrackAccounting makes the connection tracking count the packets and bytes of the flows,
+// and record their start when the kernel supports it
+func enableConntrackAccounting() error {
+	if err := os.WriteFile(path.Join(conntrackPath, "nf_conntrack_acct"), []byte("1"), 0); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path.Join(conntrackPath, "nf_conntrack_timestamp"), []byte("1"), 0); err != nil {
+		log.Printf("LGM: flow start times are not recorded: %v\n", err)
+	}
+	return nil
+}
+
+// flowCounters are the counters of one direction of a flow
+type flowCounters struct {
+	packets, bytes uint64
+}
+
+// trackedFlow is a sampled flow, the counters are the ones of the kernel and of the last export
+type trackedFlow struct {
+	entry                    vnetlink.ConntrackFlow
+	start, changed, exported time.Time
+	fwdSeen, revSeen         flowCounters
+	fwdSent, revSent         flowCounters
+}
+
+// flowKey identifies a flow by its original direction
+type flowKey struct {
+	protocol         uint8
+	src, dst         string
+	srcPort, dstPort uint16
+}
+
+// flowTracker turns the connection tracking entries carrying the mark into flow records
+type flowTracker struct {
+	mark         uint32
+	vrfID        uint32
+	active, idle time.Duration
+	flows        map[flowKey]*trackedFlow
+}
+
+// newFlowTracker returns the tracker of the flows of the flow log
+func newFlowTracker(fl *infradb.FlowLog, vrfID uint32) *flowTracker {
+	return &flowTracker{
+		mark:   fl.Mark,
+		vrfID:  vrfID,
+		active: fl.Spec.ActiveTimeout,
+		idle:   fl.Spec.IdleTimeout,
+		flows:  make(map[flowKey]*trackedFlow),
+	}
+}
+
+// records returns the records of both directions of the flow with the traffic since the last export
+func (t *flowTracker) records(f *trackedFlow, now time.Time, reason uint8) []ipfix.Flow {
+	var out []ipfix.Flow
+	for _, dir := range []struct {
+		seen, sent *flowCounters
+		reverse    bool
+	}{
+		{&f.fwdSeen, &f.fwdSent, false},
+		{&f.revSeen, &f.revSent, true},
+	} {
+		if dir.seen.packets <= dir.sent.packets {
+			continue
+		}
+		tuple := f.entry.Forward
+		if dir.reverse {
+			tuple = f.entry.Reverse
+		}
+		out = append(out, ipfix.Flow{
+			SrcIP:     tuple.SrcIP,
+			DstIP:     tuple.DstIP,
+			SrcPort:   tuple.SrcPort,
+			DstPort:   tuple.DstPort,
+			Protocol:  tuple.Protocol,
+			Packets:   dir.seen.packets - dir.sent.packets,
+			Bytes:     dir.seen.bytes - dir.sent.bytes,
+			Start:     f.start,
+			End:       f.changed,
+			EndReason: reason,
+			VrfID:     t.vrfID,
+		})
+		*dir.sent = *dir.seen
+	}
+	f.exported = now
+	return out
+}
+
+// update reads the connection tracking entries and returns the records which are due: the ones of the
+// flows which are gone, which have been idle for the idle timeout or active for the active timeout
+func (t *flowTracker) update(now time.Time, entries []*vnetlink.ConntrackFlow) []ipfix.Flow {
+	var out []ipfix.Flow
+	seen := make(map[flowKey]bool)
+	for _, entry := range entries {
+		if entry.Mark != t.mark {
+			continue
+		}
+		key := flowKey{entry.Forward.Protocol, entry.Forward.SrcIP.String(), entry.Forward.DstIP.String(),
+			entry.Forward.SrcPort, entry.Forward.DstPort}
+		seen[key] = true
+		f, ok := t.flows[key]
+		if !ok {
+			start := now
+			if entry.TimeStart != 0 {
+				start = time.Unix(0, int64(entry.TimeStart))
+			}
+			f = &trackedFlow{start: start, changed: now, exported: now}
+			t.flows[key] = f
+		}
+		fwd := flowCounters{entry.Forward.Packets, entry.Forward.Bytes}
+		rev := flowCounters{entry.Reverse.Packets, entry.Reverse.Bytes}
+		if !ok || fwd != f.fwdSeen || rev != f.revSeen {
+			f.changed = now
+		}
+		f.entry, f.fwdSeen, f.revSeen = *entry, fwd, rev
+
+		switch {
+		case now.Sub(f.changed) >= t.idle:
+			out = append(out, t.records(f, now, ipfix.EndReasonIdleTimeout)...)
+		case now.Sub(f.exported) >= t.active:
+			out = append(out, t.records(f, now, ipfix.EndReasonActiveTimeout)...)
+		}
+	}
+	for key, f := range t.flows {
+		if !seen[key] {
+			out = append(out, t.records(f, now, ipfix.EndReasonEndOfFlow)...)
+			delete(t.flows, key)
+		}
+	}
+	return out
+}
+
+// flush returns the records of the traffic of all the flows which has not been exported yet
+func (t *flowTracker) flush(now time.Time) []ipfix.Flow {
+	var out []ipfix.Flow
+	for key, f := range t.flows {
+		out = append(out, t.records(f, now, ipfix.EndReasonForcedEnd)...)
+		delete(t.flows, key)
+	}
+	return out
+}
+
+// flowLogExporter is the goroutine exporting the records of a flow log
+type flowLogExporter struct {
+	spec   infradb.FlowLogSpec
+	mark   uint32
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var (
+	flowLogExportersMu sync.Mutex
+	flowLogExporters   = make(map[string]*flowLogExporter)
+)
+
+// runFlowLogExporter polls the connection tracking table and exports the due records until the context is done
+func runFlowLogExporter(ctx context.Context, name string, tracker *flowTracker, exporter *ipfix.Exporter) {
+	defer exporter.Close()
+	ticker := time.NewTicker(flowLogPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := exporter.Export(tracker.flush(time.Now())); err != nil {
+				log.Printf("LGM: flow log %s: %v\n", name, err)
+			}
+			return
+		case <-ticker.C:
+		}
+		var entries []*vnetlink.ConntrackFlow
+		for _, family := range []vnetlink.InetFamily{vnetlink.FAMILY_V4, vnetlink.FAMILY_V6} {
+			flows, err := listConntrack(family)
+			if err != nil {
+				log.Printf("LGM: flow log %s: failed to list the connection tracking table: %v\n", name, err)
+				continue
+			}
+			entries = append(entries, flows...)
+		}
+		if records := tracker.update(time.Now(), entries); len(records) != 0 {
+			if err := exporter.Export(records); err != nil {
+				log.Printf("LGM: flow log %s: %v\n", name, err)
+			}
+		}
+	}
+}
+
+// startFlowLogExporter starts the exporter of the flow log, or keeps the running one when the flow log has not changed
+func startFlowLogExporter(fl *infradb.FlowLog, vrfID uint32) error {
+	flowLogExportersMu.Lock()
+	defer flowLogExportersMu.Unlock()
+
+	if running, ok := flowLogExporters[fl.Name]; ok {
+		if running.mark == fl.Mark && reflect.DeepEqual(running.spec, *fl.Spec) {
+			return nil
+		}
+		running.cancel()
+		<-running.done
+		delete(flowLogExporters, fl.Name)
+	}
+	// the collectors are on the management network
+	dialer := &net.Dialer{}
+	if vrf := config.GlobalConfig.Management.Vrf; vrf != "" {
+		dialer.Control = utils.BindToDevice(vrf)
+	}
+	exporter, err := ipfix.Dial(fl.Spec.Collector, fl.Mark-infradb.FlowLogMarkBase, dialer)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	running := &flowLogExporter{spec: *fl.Spec, mark: fl.Mark, cancel: cancel, done: make(chan struct{})}
+	flowLogExporters[fl.Name] = running
+	go func() {
+		defer close(running.done)
+		runFlowLogExporter(ctx, fl.Name, newFlowTracker(fl, vrfID), exporter)
+	}()
+	return nil
+}
+
+// stopFlowLogExporter stops the exporter of the flow log once it has exported the pending records
+func stopFlowLogExporter(name string) {
+	flowLogExportersMu.Lock()
+	defer flowLogExportersMu.Unlock()
+
+	if running, ok := flowLogExporters[name]; ok {
+		running.cancel()
+		<-running.done
+		delete(flowLogExporters, name)
+	}
+}
+
+// setUpFlowLog sets up the flow log
+func setUpFlowLog(fl *infradb.FlowLog) (string, bool) {
+	vrf, err := infradb.GetVrf(fl.Spec.Vrf)
+	if err != nil {
+		return fmt.Sprintf("LGM: Failed to get the vrf of flow log %s: %v\n", fl.Name, err), false
+	}
+	var vrfID uint32
+	if len(vrf.Metadata.RoutingTable) > 0 && vrf.Metadata.RoutingTable[0] != nil {
+		vrfID = *vrf.Metadata.RoutingTable[0]
+	}
+	dev, err := flowLogDevice(fl)
+	if err != nil {
+		log.Printf("LGM: Failed to resolve the device of flow log %s: %v\n", fl.Name, err)
+		return fmt.Sprintf("LGM: Failed to resolve the device of flow log %s: %v\n", fl.Name, err), false
+	}
+	if err := enableConntrackAccounting(); err != nil {
+		log.Printf("LGM: Failed to enable the connection tracking accounting: %v\n", err)
+		return fmt.Sprintf("LGM: Failed to enable the connection tracking accounting: %v\n", err), false
+	}
+	// Example: nft -f <ruleset of table inet opi-flowlog-<id>>
+	if details, ok := applyNftables(flowLogRuleset(fl, dev)); !ok {
+		log.Print(details)
+		return details, false
+	}
+	log.Printf("LGM Executed : nft -f <table inet %s>\n", flowLogTableName(fl))
+	if err := startFlowLogExporter(fl, vrfID); err != nil {
+		log.Printf("LGM: Failed to start the exporter of flow log %s: %v\n", fl.Name, err)
+		return fmt.Sprintf("LGM: Failed to start the exporter of flow log %s: %v\n", fl.Name, err), false
+	}
+	return "", true
+}
+
+// tearDownFlowLog tears down the flow log
+func tearDownFlowLog(fl *infradb.FlowLog) (string, bool) {
+	table := flowLogTableName(fl)
+	// Example: nft delete table inet opi-flowlog-<id>
+	if details, ok := applyNftables(fmt.Sprintf("table inet %s {}\ndelete table inet %s\n", table, table)); !ok {
+		log.Print(details)
+		return details, false
+	}
+	log.Printf("LGM Executed : nft delete table inet %s\n", table)
+	stopFlowLogExporter(fl.Name)
+	return "", true
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	vnetlink "github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/ipfix"
+)
+
+// testFlowLog returns a flow log with the timeouts of the spec defaults
+func testFlowLog(samplingRate uint32) *infradb.FlowLog {
+	return &infradb.FlowLog{
+		Resource: infradb.Resource{Name: "//network.opiproject.org/flowlogs/blue-flows"},
+		Spec: &infradb.FlowLogSpec{
+			Vrf: "//network.opiproject.org/vrfs/blue", Collector: "192.0.2.10:4739", SamplingRate: samplingRate,
+			ActiveTimeout: 60 * time.Second, IdleTimeout: 15 * time.Second,
+		},
+		Mark: infradb.FlowLogMarkBase + 1,
+	}
+}
+
+func Test_FlowLogRuleset(t *testing.T) {
+	ruleset := flowLogRuleset(testFlowLog(100), "blue")
+	for _, expected := range []string{
+		"delete table inet opi-flowlog-blue-flows\n",
+		"type filter hook prerouting priority mangle; policy accept;",
+		"iifname \"blue\" ct state new numgen random mod 100 == 0 ct mark set 0x464c0001\n",
+	} {
+		if !strings.Contains(ruleset, expected) {
+			t.Errorf("expected %q in the ruleset:\n%s", expected, ruleset)
+		}
+	}
+	if ruleset := flowLogRuleset(testFlowLog(1), "blue-100"); strings.Contains(ruleset, "numgen") {
+		t.Errorf("expected all the flows to be marked without sampling:\n%s", ruleset)
+	}
+}
+
+// conntrackEntry returns the entry of a tcp connection from 10.1.1.10 to 10.2.2.20
+func conntrackEntry(mark uint32, packets, replies uint64) *vnetlink.ConntrackFlow {
+	entry := &vnetlink.ConntrackFlow{Mark: mark}
+	entry.Forward.Protocol, entry.Reverse.Protocol = 6, 6
+	entry.Forward.SrcIP, entry.Forward.DstIP = net.ParseIP("10.1.1.10"), net.ParseIP("10.2.2.20")
+	entry.Forward.SrcPort, entry.Forward.DstPort = 40000, 443
+	entry.Reverse.SrcIP, entry.Reverse.DstIP = net.ParseIP("10.2.2.20"), net.ParseIP("10.1.1.10")
+	entry.Reverse.SrcPort, entry.Reverse.DstPort = 443, 40000
+	entry.Forward.Packets, entry.Forward.Bytes = packets, packets*100
+	entry.Reverse.Packets, entry.Reverse.Bytes = replies, replies*1000
+	return entry
+}
+
+func Test_FlowTracker(t *testing.T) {
+	fl := testFlowLog(1)
+	tracker := newFlowTracker(fl, 1000)
+	now := time.Date(2023, 10, 17, 9, 30, 0, 0, time.UTC)
+
+	// the flows of the other flow logs are left out
+	if records := tracker.update(now, []*vnetlink.ConntrackFlow{conntrackEntry(fl.Mark, 10, 8), conntrackEntry(fl.Mark+1, 5, 5)}); len(records) != 0 || len(tracker.flows) != 1 {
+		t.Fatalf("expected the new flow to be tracked without records, received %+v", records)
+	}
+
+	// active for the active timeout
+	now = now.Add(time.Minute)
+	records := tracker.update(now, []*vnetlink.ConntrackFlow{conntrackEntry(fl.Mark, 30, 20)})
+	if len(records) != 2 || records[0].EndReason != ipfix.EndReasonActiveTimeout ||
+		records[0].Packets != 30 || records[0].Bytes != 3000 || records[0].SrcPort != 40000 ||
+		records[1].Packets != 20 || records[1].SrcPort != 443 || records[1].VrfID != 1000 {
+		t.Fatalf("expected the records of both directions, received %+v", records)
+	}
+
+	// the replies stop, then the whole flow is idle for the idle timeout
+	now = now.Add(5 * time.Second)
+	if records := tracker.update(now, []*vnetlink.ConntrackFlow{conntrackEntry(fl.Mark, 35, 20)}); len(records) != 0 {
+		t.Fatalf("expected no record before the timeouts, received %+v", records)
+	}
+	now = now.Add(15 * time.Second)
+	records = tracker.update(now, []*vnetlink.ConntrackFlow{conntrackEntry(fl.Mark, 35, 20)})
+	if len(records) != 1 || records[0].EndReason != ipfix.EndReasonIdleTimeout || records[0].Packets != 5 || records[0].Bytes != 500 {
+		t.Fatalf("expected the delta of the forward direction on the idle timeout, received %+v", records)
+	}
+
+	// the connection ends with a last packet
+	now = now.Add(time.Second)
+	tracker.update(now, []*vnetlink.ConntrackFlow{conntrackEntry(fl.Mark, 36, 20)})
+	records = tracker.update(now.Add(time.Second), nil)
+	if len(records) != 1 || records[0].EndReason != ipfix.EndReasonEndOfFlow || records[0].Packets != 1 || len(tracker.flows) != 0 {
+		t.Fatalf("expected the end of the flow, received %+v", records)
+	}
+
+	tracker.update(now, []*vnetlink.ConntrackFlow{conntrackEntry(fl.Mark, 2, 1)})
+	if records := tracker.flush(now); len(records) != 2 || records[0].EndReason != ipfix.EndReasonForcedEnd {
+		t.Errorf("expected the pending traffic to be flushed, received %+v", records)
+	}
+}
@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+var (
+	// broadcast is the destination of the gratuitous ARPs
+	broadcast = net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	// allNodes is the multicast mac address of ff02::1, the destination of the unsolicited NAs
+	allNodes = net.HardwareAddr{0x33, 0x33, 0x00, 0x00, 0x00, 0x01}
+)
+
+// sviLinkName returns the linux device name used for the svi
+func sviLinkName(svi *infradb.Svi) (string, error) {
+	BrObj, err := infradb.GetLB(svi.Spec.LogicalBridge)
+	if err != nil {
+		return "", err
+	}
+	return infradb.SviLinkName(svi, BrObj.Spec.VlanID), nil
+}
+
+// garpFrame returns the gratuitous ARP request announcing the ip with the mac address, RFC 5227
+func garpFrame(mac net.HardwareAddr, ip net.IP) []byte {
+	frame := make([]byte, 0, 42)
+	frame = append(frame, broadcast...)
+	frame = append(frame, mac...)
+	frame = binary.BigEndian.AppendUint16(frame, unix.ETH_P_ARP)
+	// ethernet, ipv4, request
+	frame = binary.BigEndian.AppendUint16(frame, 1)
+	frame = binary.BigEndian.AppendUint16(frame, unix.ETH_P_IP)
+	frame = append(frame, 6, 4)
+	frame = binary.BigEndian.AppendUint16(frame, 1)
+	frame = append(frame, mac...)
+	frame = append(frame, ip.To4()...)
+	frame = append(frame, make([]byte, 6)...)
+	return append(frame, ip.To4()...)
+}
+
+// naFrame returns the unsolicited neighbor advertisement of the router announcing the ip with the mac
+// address to all the nodes, RFC 4861 7.2.6
+func naFrame(mac net.HardwareAddr, ip net.IP) []byte {
+	icmp := []byte{136, 0, 0, 0}
+	// router and override flags
+	icmp = append(icmp, 0xa0, 0, 0, 0)
+	icmp = append(icmp, ip.To16()...)
+	// target link-layer address option
+	icmp = append(icmp, 2, 1)
+	icmp = append(icmp, mac...)
+	binary.BigEndian.PutUint16(icmp[2:], icmpv6Checksum(ip.To16(), net.IPv6linklocalallnodes, icmp))
+
+	frame := make([]byte, 0, 14+40+len(icmp))
+	frame = append(frame, allNodes...)
+	frame = append(frame, mac...)
+	frame = binary.BigEndian.AppendUint16(frame, unix.ETH_P_IPV6)
+	frame = append(frame, 0x60, 0, 0, 0)
+	frame = binary.BigEndian.AppendUint16(frame, uint16(len(icmp)))
+	// icmpv6, hop limit 255 as the neighbor discovery requires
+	frame = append(frame, unix.IPPROTO_ICMPV6, 255)
+	frame = append(frame, ip.To16()...)
+	frame = append(frame, net.IPv6linklocalallnodes...)
+	return append(frame, icmp...)
+}
+
+// icmpv6Checksum returns the checksum of the icmpv6 message over the ipv6 pseudo header, RFC 4443 2.3
+func icmpv6Checksum(src, dst net.IP, msg []byte) uint16 {
+	pseudo := make([]byte, 0, 40+len(msg))
+	pseudo = append(pseudo, src...)
+	pseudo = append(pseudo, dst...)
+	pseudo = binary.BigEndian.AppendUint32(pseudo, uint32(len(msg)))
+	pseudo = append(pseudo, 0, 0, 0, unix.IPPROTO_ICMPV6)
+	pseudo = append(pseudo, msg...)
+	if len(pseudo)%2 == 1 {
+		pseudo = append(pseudo, 0)
+	}
+	var sum uint32
+	for i := 0; i < len(pseudo); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(pseudo[i:]))
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// sendFrames sends the gratuitous ARPs and unsolicited NAs of the ips on a packet socket of the device of
+// the named network namespace, with the mac address of the device
+func sendFrames(namespace, link string, ips []net.IP) error {
+	return utils.InNetns(namespace, func() error {
+		iface, err := net.InterfaceByName(link)
+		if err != nil {
+			return err
+		}
+		fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, 0)
+		if err != nil {
+			return err
+		}
+		defer unix.Close(fd)
+		var errs []error
+		for _, ip := range ips {
+			frame := naFrame(iface.HardwareAddr, ip)
+			if ip.To4() != nil {
+				frame = garpFrame(iface.HardwareAddr, ip)
+			}
+			// the frames carry their destination and ethertype, the ones of the address follow them
+			to := &unix.SockaddrLinklayer{Protocol: htons(binary.BigEndian.Uint16(frame[12:])), Ifindex: iface.Index, Halen: 6}
+			copy(to.Addr[:], frame[:6])
+			if err := unix.Sendto(fd, frame, 0, to); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", ip, err))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// htons converts the ethertype to the byte order of the packet sockets
+func htons(v uint16) uint16 {
+	return binary.NativeEndian.Uint16(binary.BigEndian.AppendUint16(nil, v))
+}
+
+// sendAnnouncement sends a round of announcements of the ips on the device, it is replaced by the tests
+var sendAnnouncement = sendFrames
+
+// sleep waits between the rounds of announcements, it is replaced by the tests
+var sleep = time.Sleep
+
+// announceSvi emits gratuitous ARPs and unsolicited NAs for all the gateway
+// and secondary IPs of the svi so that the hosts refresh their neighbor caches quickly
+func announceSvi(linkSvi string, svi *infradb.Svi) {
+	count := config.GlobalConfig.Garp.Count
+	if count == 0 || len(svi.Spec.Addresses()) == 0 {
+		return
+	}
+	interval := time.Duration(config.GlobalConfig.Garp.Interval) * time.Millisecond
+	namespace := infradb.SviNetns(svi)
+	var ips []net.IP
+	for _, gwIP := range svi.Spec.Addresses() {
+		ips = append(ips, gwIP.IP)
+	}
+	go func() {
+		for i := 0; i < count; i++ {
+			if i > 0 {
+				sleep(interval)
+			}
+			if err := sendAnnouncement(namespace, linkSvi, ips); err != nil {
+				log.Printf("LGM: Failed to announce gateway on %s: %v\n", linkSvi, err)
+			}
+		}
+		log.Printf("LGM: Announced %d gateway IPs on %s %d times\n", len(ips), linkSvi, count)
+	}()
+}
+
+// AnnounceSvi triggers on demand the emission of gratuitous ARPs and unsolicited NAs for the
+// gateway IPs of the svi with the given name, which must be operationally up
+func AnnounceSvi(name string) error {
+	svi, err := infradb.GetSvi(name)
+	if err != nil {
+		return err
+	}
+	if svi.Status.SviOperStatus != infradb.SviOperStatusUp {
+		return status.Errorf(codes.FailedPrecondition, "svi %s is not operationally up", name)
+	}
+	linkSvi, err := sviLinkName(svi)
+	if err != nil {
+		return err
+	}
+	announceSvi(linkSvi, svi)
+	return nil
+}
+
+// announcedLink is the state of a device as last notified, to tell when it goes up or changes its mac address
+type announcedLink struct {
+	up  bool
+	mac string
+}
+
+// announcedLinks holds the state of the devices indexed by their ifindex
+var announcedLinks = struct {
+	sync.Mutex
+	byIndex map[int]announcedLink
+}{byIndex: make(map[int]announcedLink)}
+
+// linkRaised records the state of the device of the notification and tells whether it has gone up, or
+// changed its mac address while up, e.g. after a failover or a mac move
+func linkRaised(update netlink.LinkUpdate) bool {
+	attrs := update.Attrs()
+	announcedLinks.Lock()
+	defer announcedLinks.Unlock()
+	if update.Header.Type == unix.RTM_DELLINK {
+		delete(announcedLinks.byIndex, attrs.Index)
+		return false
+	}
+	state := announcedLink{up: attrs.OperState == netlink.OperUp, mac: attrs.HardwareAddr.String()}
+	previous, ok := announcedLinks.byIndex[attrs.Index]
+	announcedLinks.byIndex[attrs.Index] = state
+	return ok && state.up && (!previous.up || previous.mac != state.mac)
+}
+
+// announceOnLink announces again the gateway IPs of the svis which are up on the device going up: the
+// device of the svi, the one of its vrf or its bridge. The svis being set up are announced once they are.
+func announceOnLink(update netlink.LinkUpdate) {
+	if !linkRaised(update) {
+		return
+	}
+	name := update.Attrs().Name
+	svis, err := infradb.GetAllSvis()
+	if err != nil {
+		log.Printf("LGM: Failed to list the svis to announce on %s: %v\n", name, err)
+		return
+	}
+	for _, svi := range svis {
+		if svi.Status.SviOperStatus != infradb.SviOperStatusUp {
+			continue
+		}
+		lb, err := infradb.GetLB(svi.Spec.LogicalBridge)
+		if err != nil {
+			continue
+		}
+		linkSvi := infradb.SviLinkName(svi, lb.Spec.VlanID)
+		bridge := ""
+		if topology != nil {
+			bridge = topology.BridgeName(uint16(lb.Spec.VlanID))
+		}
+		if name == linkSvi || name == infradb.LinkName(svi.Spec.Vrf, infradb.LinkRoleVrf) || name == bridge {
+			log.Printf("LGM: %s is up, announcing the gateway IPs of %s\n", name, svi.Name)
+			announceSvi(linkSvi, svi)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_AnnounceSvi(t *testing.T) {
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	savedGarp, savedSend, savedSleep := config.GlobalConfig.Garp, sendAnnouncement, sleep
+	t.Cleanup(func() { config.GlobalConfig.Garp, sendAnnouncement, sleep = savedGarp, savedSend, savedSleep })
+	config.GlobalConfig.Garp = config.GarpConfig{Count: 3, Interval: 250}
+
+	var lock sync.Mutex
+	var sent []string
+	var waits []time.Duration
+	done := make(chan struct{})
+	sendAnnouncement = func(namespace, link string, ips []net.IP) error {
+		lock.Lock()
+		defer lock.Unlock()
+		sent = append(sent, fmt.Sprintf("%s/%s %v", namespace, link, ips))
+		if len(sent) == 3 {
+			close(done)
+		}
+		return nil
+	}
+	sleep = func(d time.Duration) {
+		lock.Lock()
+		defer lock.Unlock()
+		waits = append(waits, d)
+	}
+
+	_, gw4, _ := net.ParseCIDR("10.0.0.1/24")
+	gw4.IP = net.ParseIP("10.0.0.1").To4()
+	_, gw6, _ := net.ParseCIDR("2001:db8::1/64")
+	gw6.IP = net.ParseIP("2001:db8::1")
+	svi := &infradb.Svi{Spec: &infradb.SviSpec{GatewayIPs: []*net.IPNet{gw4, gw6}}}
+	announceSvi("blue-20", svi)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the announcements have not been sent")
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	round := "/blue-20 [10.0.0.1 2001:db8::1]"
+	if !reflect.DeepEqual(sent, []string{round, round, round}) {
+		t.Errorf("expected three rounds of %s, sent %v", round, sent)
+	}
+	if !reflect.DeepEqual(waits, []time.Duration{250 * time.Millisecond, 250 * time.Millisecond}) {
+		t.Errorf("expected two waits of 250ms between the rounds, waited %v", waits)
+	}
+}
+
+func Test_AnnouncementFrames(t *testing.T) {
+	mac := net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}
+
+	garp := garpFrame(mac, net.ParseIP("10.0.0.1"))
+	expected := []byte{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x08, 0x06,
+		0x00, 0x01, 0x08, 0x00, 6, 4, 0x00, 0x01,
+		0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 10, 0, 0, 1,
+		0, 0, 0, 0, 0, 0, 10, 0, 0, 1,
+	}
+	if !bytes.Equal(garp, expected) {
+		t.Errorf("expected the gratuitous arp % x, built % x", expected, garp)
+	}
+
+	gw := net.ParseIP("2001:db8::1")
+	na := naFrame(mac, gw)
+	if len(na) != 14+40+32 {
+		t.Fatalf("expected a frame of %d bytes, built %d", 14+40+32, len(na))
+	}
+	if !bytes.Equal(na[:6], allNodes) || !bytes.Equal(na[6:12], mac) || binary.BigEndian.Uint16(na[12:]) != unix.ETH_P_IPV6 {
+		t.Errorf("unexpected ethernet header % x", na[:14])
+	}
+	ip, icmp := na[14:54], na[54:]
+	if ip[0]>>4 != 6 || binary.BigEndian.Uint16(ip[4:]) != 32 || ip[6] != unix.IPPROTO_ICMPV6 || ip[7] != 255 ||
+		!net.IP(ip[8:24]).Equal(gw) || !net.IP(ip[24:40]).Equal(net.IPv6linklocalallnodes) {
+		t.Errorf("unexpected ipv6 header % x", ip)
+	}
+	if icmp[0] != 136 || icmp[4] != 0xa0 || !net.IP(icmp[8:24]).Equal(gw) || icmp[24] != 2 || !bytes.Equal(icmp[26:], mac) {
+		t.Errorf("unexpected neighbor advertisement % x", icmp)
+	}
+	// the checksum of a message with its checksum is zero
+	if sum := icmpv6Checksum(gw, net.IPv6linklocalallnodes, icmp); sum != 0 {
+		t.Errorf("invalid checksum %#04x of % x", binary.BigEndian.Uint16(icmp[2:]), icmp)
+	}
+}
+
+func Test_LinkRaised(t *testing.T) {
+	update := func(msgType uint16, state netlink.LinkOperState, mac string) netlink.LinkUpdate {
+		hw, _ := net.ParseMAC(mac)
+		attrs := netlink.LinkAttrs{Index: 4242, Name: "blue-20", OperState: state, HardwareAddr: hw}
+		u := netlink.LinkUpdate{Link: &netlink.Vlan{LinkAttrs: attrs}}
+		u.Header.Type = msgType
+		return u
+	}
+	steps := []struct {
+		name   string
+		update netlink.LinkUpdate
+		raised bool
+	}{
+		{"first seen up", update(unix.RTM_NEWLINK, netlink.OperUp, "00:11:22:33:44:55"), false},
+		{"still up", update(unix.RTM_NEWLINK, netlink.OperUp, "00:11:22:33:44:55"), false},
+		{"mac move", update(unix.RTM_NEWLINK, netlink.OperUp, "00:11:22:33:44:66"), true},
+		{"down", update(unix.RTM_NEWLINK, netlink.OperDown, "00:11:22:33:44:66"), false},
+		{"failover", update(unix.RTM_NEWLINK, netlink.OperUp, "00:11:22:33:44:66"), true},
+		{"deleted", update(unix.RTM_DELLINK, netlink.OperDown, "00:11:22:33:44:66"), false},
+		{"created again up", update(unix.RTM_NEWLINK, netlink.OperUp, "00:11:22:33:44:66"), false},
+	}
+	for _, step := range steps {
+		if raised := linkRaised(step.update); raised != step.raised {
+			t.Errorf("%s: expected raised %v, received %v", step.name, step.raised, raised)
+		}
+	}
+}
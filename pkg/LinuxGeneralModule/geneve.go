@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+
+	"github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+const (
+	// geneveIngressPref is the preference of the filters tagging the decapsulated packets with their vlan
+	geneveIngressPref = "1"
+	// geneveFloodPref is the preference of the filters sending the flooded packets of a vlan to its chain,
+	// below the filters of the mac addresses of the remote vteps programmed by the routing backend
+	geneveFloodPref = "2"
+	// geneveDropPref is the last filter of the chain of a vlan, it drops the flooded packet once it has
+	// been mirrored to every remote vtep
+	geneveDropPref = "65535"
+)
+
+// setUpGeneveDevice creates the flow based geneve device in br-tenant unless it exists already
+func setUpGeneveDevice() error {
+	if _, err := nlink.LinkByName(ctx, infradb.GeneveDevice); err == nil {
+		return nil
+	}
+	// Example: ip link add gnv0 type geneve external dstport 6081
+	geneve := &netlink.Geneve{LinkAttrs: netlink.LinkAttrs{Name: infradb.GeneveDevice, MTU: ipMtu}, FlowBased: true, Dport: infradb.GenevePort}
+	if err := nlink.LinkAdd(ctx, geneve); err != nil {
+		return fmt.Errorf("failed to create %s: %v", infradb.GeneveDevice, err)
+	}
+	if err := nlink.LinkSetAlias(ctx, geneve, utils.LinkAlias("")); err != nil {
+		return fmt.Errorf("failed to tag %s: %v", infradb.GeneveDevice, err)
+	}
+	if err := topology.AddPort(ctx, geneve); err != nil {
+		return fmt.Errorf("failed to add %s to the tenant bridge: %v", infradb.GeneveDevice, err)
+	}
+	if err := nlink.LinkSetUp(ctx, geneve); err != nil {
+		return fmt.Errorf("failed to set up %s: %v", infradb.GeneveDevice, err)
+	}
+	if err := nlink.LinkSetBrNeighSuppress(ctx, geneve, true); err != nil {
+		return fmt.Errorf("failed to set neigh_suppress on %s: %v", infradb.GeneveDevice, err)
+	}
+	if CP, err := run([]string{"tc", "qdisc", "replace", "dev", infradb.GeneveDevice, "clsact"}, false); err != 0 {
+		return fmt.Errorf("failed to add the clsact qdisc of %s: %s", infradb.GeneveDevice, CP)
+	}
+	return nil
+}
+
+// setUpGeneve carries the vlan of the logical bridge over the geneve device: the decapsulated packets of its
+// VNI are tagged with the vlan and its flooded packets go to the chain of the vlan, where the routing backend
+// mirrors them to the remote vteps
+func setUpGeneve(lb *infradb.LogicalBridge) error {
+	if err := setUpGeneveDevice(); err != nil {
+		return err
+	}
+	geneve, err := nlink.LinkByName(ctx, infradb.GeneveDevice)
+	if err != nil {
+		return err
+	}
+	vid := uint16(lb.Spec.VlanID)
+	vlan := strconv.FormatUint(uint64(lb.Spec.VlanID), 10)
+	if err := topology.AttachPort(ctx, geneve, vid, false); err != nil {
+		return fmt.Errorf("failed to add vlan %d to %s: %v", vid, infradb.GeneveDevice, err)
+	}
+	cmds := [][]string{
+		// Example: tc filter replace dev gnv0 ingress pref 1 handle <vlan> protocol all flower enc_key_id <vni> action vlan push id <vlan>
+		{"tc", "filter", "replace", "dev", infradb.GeneveDevice, "ingress", "pref", geneveIngressPref, "handle", vlan, "protocol", "all",
+			"flower", "enc_key_id", strconv.FormatUint(uint64(*lb.Spec.Vni), 10), "action", "vlan", "push", "id", vlan},
+		// Example: tc filter replace dev gnv0 egress chain <vlan> pref 65535 handle 1 protocol all matchall action drop
+		{"tc", "filter", "replace", "dev", infradb.GeneveDevice, "egress", "chain", vlan, "pref", geneveDropPref, "handle", "1", "protocol", "all",
+			"matchall", "action", "drop"},
+		// Example: tc filter replace dev gnv0 egress pref 2 handle <vlan> protocol 802.1Q flower vlan_id <vlan> action vlan pop pipe action goto chain <vlan>
+		{"tc", "filter", "replace", "dev", infradb.GeneveDevice, "egress", "pref", geneveFloodPref, "handle", vlan, "protocol", "802.1Q",
+			"flower", "vlan_id", vlan, "action", "vlan", "pop", "pipe", "action", "goto", "chain", vlan},
+	}
+	for _, cmd := range cmds {
+		if CP, err := run(cmd, false); err != 0 {
+			return fmt.Errorf("failed to steer vlan %d over %s: %s", vid, infradb.GeneveDevice, CP)
+		}
+	}
+	return nil
+}
+
+// tearDownGeneve removes the vlan of the logical bridge from the geneve device, which is left for the other
+// logical bridges. It has nothing to do when the vlan is not carried over geneve.
+func tearDownGeneve(lb *infradb.LogicalBridge) {
+	geneve, err := nlink.LinkByName(ctx, infradb.GeneveDevice)
+	if err != nil {
+		return
+	}
+	vlan := strconv.FormatUint(uint64(lb.Spec.VlanID), 10)
+	ingress := []string{"dev", infradb.GeneveDevice, "ingress", "pref", geneveIngressPref, "handle", vlan, "protocol", "all", "flower"}
+	if _, err := exec.Command("tc", append([]string{"filter", "get"}, ingress...)...).CombinedOutput(); err != nil { //nolint:gosec
+		return
+	}
+	cmds := [][]string{
+		{"tc", "filter", "del", "dev", infradb.GeneveDevice, "egress", "pref", geneveFloodPref, "handle", vlan, "protocol", "802.1Q", "flower"},
+		// Example: tc filter del dev gnv0 egress chain <vlan>
+		{"tc", "filter", "del", "dev", infradb.GeneveDevice, "egress", "chain", vlan},
+		append([]string{"tc", "filter", "del"}, ingress...),
+	}
+	for _, cmd := range cmds {
+		if CP, err := run(cmd, false); err != 0 {
+			log.Printf("LGM: Failed to remove vlan %s from %s: %s\n", vlan, infradb.GeneveDevice, CP)
+		}
+	}
+	if err := topology.DetachPort(ctx, geneve, uint16(lb.Spec.VlanID), false); err != nil {
+		log.Printf("LGM: Failed to remove vlan %s from %s: %v\n", vlan, infradb.GeneveDevice, err)
+	}
+}
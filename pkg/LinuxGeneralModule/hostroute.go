@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// handleHostRoute handles the host route functionality
+func handleHostRoute(objectData *eventbus.ObjectData) {
+	hr, err := infradb.GetHostRoute(objectData.Name)
+	handleResource(objectData, &hr.Resource, err,
+		func() (string, bool) { return setUpHostRoute(hr) },
+		func() (string, bool) { return tearDownHostRoute(hr) },
+		infradb.UpdateHostRouteStatus)
+}
+
+// hostRouteLink returns the vrf device of the host route in the namespace of the vrf
+func hostRouteLink(hr *infradb.HostRoute) (utils.Netlink, netlink.Link, error) {
+	vrf, err := infradb.GetVrf(hr.Spec.Vrf)
+	if err != nil {
+		return nil, nil, err
+	}
+	nl, err := netnsLink(vrf.Spec.Netns)
+	if err != nil {
+		return nil, nil, err
+	}
+	link, err := nl.LinkByName(ctx, infradb.LinkName(vrf.Name, infradb.LinkRoleVrf))
+	if err != nil {
+		return nil, nil, err
+	}
+	return nl, link, nil
+}
+
+// pruneHostRoutes deletes the host addresses of the vrf device which are neither its loopback nor
+// a host route of the vrf anymore, e.g. a prefix which has been removed from a host route
+func pruneHostRoutes(nl utils.Netlink, link netlink.Link, vrfName string) {
+	vrf, err := infradb.GetVrf(vrfName)
+	if err != nil {
+		return
+	}
+	hrs, err := infradb.GetAllHostRoutes()
+	if err != nil {
+		log.Printf("LGM: Failed to get the host routes of %s: %v\n", vrfName, err)
+		return
+	}
+	wanted := make(map[string]bool)
+	if vrf.Spec.LoopbackIP != nil {
+		wanted[vrf.Spec.LoopbackIP.String()] = true
+	}
+	for _, hr := range hrs {
+		if hr.Spec.Vrf != vrfName || hr.Status.OperStatus == infradb.OperStatusToBeDeleted {
+			continue
+		}
+		for _, prefix := range hr.Spec.Prefixes {
+			wanted[prefix.String()] = true
+		}
+	}
+	addrs, err := nl.AddrList(ctx, link, netlink.FAMILY_V4)
+	if err != nil {
+		log.Printf("LGM: Failed to list the addresses of %s: %v\n", link.Attrs().Name, err)
+		return
+	}
+	for i := range addrs {
+		addr := &addrs[i]
+		if addr.IPNet == nil || wanted[addr.IPNet.String()] {
+			continue
+		}
+		if ones, bits := addr.IPNet.Mask.Size(); ones != bits {
+			continue
+		}
+		if err := nl.AddrDel(ctx, link, addr); err != nil {
+			log.Printf("LGM: Failed to delete ip address %v of %s: %v\n", addr.IPNet, link.Attrs().Name, err)
+			continue
+		}
+		log.Printf("LGM Executed :  ip address del %s dev %s\n", addr.IPNet, link.Attrs().Name)
+	}
+}
+
+// setUpHostRoute configures the host routes on the vrf device, so that the addresses are local to the vrf
+// like its loopback and the routes are in its routing table for FRR to advertise them
+func setUpHostRoute(hr *infradb.HostRoute) (string, bool) {
+	nl, link, err := hostRouteLink(hr)
+	if err != nil {
+		log.Printf("LGM: Failed to prepare host route %s: %v\n", hr.Name, err)
+		return fmt.Sprintf("LGM: Failed to prepare host route %s: %v\n", hr.Name, err), false
+	}
+	for _, prefix := range hr.Spec.Prefixes {
+		// Example: ip address add <prefix> dev <vrf>
+		if err := nl.AddrAdd(ctx, link, &netlink.Addr{IPNet: prefix}); err != nil && !errors.Is(err, unix.EEXIST) {
+			log.Printf("LGM: Failed to add host route %s to %s: %v\n", prefix, link.Attrs().Name, err)
+			return fmt.Sprintf("LGM: Failed to add host route %s to %s: %v\n", prefix, link.Attrs().Name, err), false
+		}
+		log.Printf("LGM Executed : ip address add %s dev %s\n", prefix, link.Attrs().Name)
+	}
+	pruneHostRoutes(nl, link, hr.Spec.Vrf)
+	return "", true
+}
+
+// tearDownHostRoute removes the host routes from the vrf device
+func tearDownHostRoute(hr *infradb.HostRoute) (string, bool) {
+	nl, link, err := hostRouteLink(hr)
+	if err != nil {
+		// The vrf is gone together with its addresses
+		log.Printf("LGM: Nothing to tear down for host route %s: %v\n", hr.Name, err)
+		return "", true
+	}
+	for _, prefix := range hr.Spec.Prefixes {
+		if err := nl.AddrDel(ctx, link, &netlink.Addr{IPNet: prefix}); err != nil {
+			log.Printf("LGM: Failed to delete host route %s of %s: %v\n", prefix, link.Attrs().Name, err)
+			continue
+		}
+		log.Printf("LGM Executed : ip address del %s dev %s\n", prefix, link.Attrs().Name)
+	}
+	return "", true
+}
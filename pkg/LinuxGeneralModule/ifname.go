@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"log"
+	"net"
+
+	"github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// linkMaster returns the name of the device which the device of the object is enslaved to
+func linkMaster(owner infradb.LinkOwner) string {
+	switch owner.Role {
+	case infradb.LinkRoleBridge:
+		return infradb.LinkName(owner.Object, infradb.LinkRoleVrf)
+	case infradb.LinkRoleVxlan:
+		return infradb.LinkName(owner.Object, infradb.LinkRoleBridge)
+	case infradb.LinkRoleSvi:
+		svi, err := infradb.GetSvi(owner.Object)
+		if err != nil {
+			return ""
+		}
+		return infradb.LinkName(svi.Spec.Vrf, infradb.LinkRoleVrf)
+	}
+	return ""
+}
+
+// ownsLink tells whether the existing device is the one of the object, the legacy names
+// are derived from the resource ids and may belong to a foreign device. The alias tells
+// the owner of the devices tagged by the bridge.
+func ownsLink(link netlink.Link, owner infradb.LinkOwner) bool {
+	if tag, ok := utils.LinkAliasOwner(link.Attrs().Alias); ok {
+		return tag == owner.Object
+	}
+	if owner.Role == infradb.LinkRoleVrf {
+		return link.Type() == "vrf"
+	}
+	master, err := nlink.LinkByName(ctx, linkMaster(owner))
+	if err != nil {
+		return false
+	}
+	return link.Attrs().MasterIndex == master.Attrs().Index
+}
+
+// migrateIfNames records the interface names of the objects created before the name table and
+// renames their devices which had a name colliding with another device or too long for the kernel
+func migrateIfNames() {
+	renames, err := infradb.MigrateIfNames()
+	if err != nil {
+		log.Printf("LGM: Failed to migrate the interface names: %v\n", err)
+		return
+	}
+	for _, rename := range renames {
+		link, err := nlink.LinkByName(ctx, rename.Old)
+		if err != nil || !ownsLink(link, rename.LinkOwner) {
+			continue
+		}
+		up := link.Attrs().Flags&net.FlagUp != 0
+		// The kernel renames only the devices which are down
+		if err := nlink.LinkSetDown(ctx, link); err != nil {
+			log.Printf("LGM: Failed to set down %s: %v\n", rename.Old, err)
+			continue
+		}
+		if err := nlink.LinkSetName(ctx, link, rename.New); err != nil {
+			log.Printf("LGM: Failed to rename %s to %s: %v\n", rename.Old, rename.New, err)
+			continue
+		}
+		if up {
+			if err := nlink.LinkSetUp(ctx, link); err != nil {
+				log.Printf("LGM: Failed to set up %s: %v\n", rename.New, err)
+			}
+		}
+		log.Printf("LGM Executed : ip link set %s name %s\n", rename.Old, rename.New)
+	}
+}
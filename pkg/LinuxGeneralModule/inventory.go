@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PortInventory describes a physical port of the DPU
+type PortInventory struct {
+	Name       string
+	MacAddress string
+	OperState  string
+	Mtu        int
+	// Speed is in Mb/s, zero when the link is down or the speed is unknown
+	Speed        int
+	PhysPortName string
+	PciAddress   string
+	Driver       string
+	Firmware     string
+	// TotalVfs is the number of VFs supported by the port, zero without SR-IOV
+	TotalVfs int
+	NumVfs   int
+	// EswitchMode is legacy or switchdev, empty when the port has no devlink eswitch
+	EswitchMode string
+}
+
+// readNetInt reads an integer sysfs attribute of a network device, zero when it is missing or invalid
+func readNetInt(dev, attr string) int {
+	value, err := strconv.Atoi(readNetAttr(dev, attr))
+	if err != nil || value < 0 {
+		return 0
+	}
+	return value
+}
+
+// parseDriverInfo parses the output of ethtool -i into the driver and the firmware version
+func parseDriverInfo(out string) (driver string, firmware string) {
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "driver":
+			driver = strings.TrimSpace(value)
+		case "firmware-version":
+			firmware = strings.TrimSpace(value)
+		}
+	}
+	return driver, firmware
+}
+
+// isPhysicalPort tells whether the network device is a port of a PCI function of the NIC,
+// the VFs and the VF representors are left out
+func isPhysicalPort(dev string) bool {
+	if _, err := os.Stat(filepath.Join(sysClassNet, dev, "device")); err != nil {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(sysClassNet, dev, "device", "physfn")); err == nil {
+		return false
+	}
+	return !vfPortName.MatchString(readNetAttr(dev, "phys_port_name"))
+}
+
+// GetPortInventory lists the physical ports of the DPU in the order of their names
+func GetPortInventory() ([]*PortInventory, error) {
+	entries, err := os.ReadDir(sysClassNet)
+	if err != nil {
+		return nil, err
+	}
+	ports := []*PortInventory{}
+	for _, entry := range entries {
+		dev := entry.Name()
+		if !isPhysicalPort(dev) {
+			continue
+		}
+		port := &PortInventory{
+			Name:         dev,
+			MacAddress:   readNetAttr(dev, "address"),
+			OperState:    readNetAttr(dev, "operstate"),
+			Mtu:          readNetInt(dev, "mtu"),
+			Speed:        readNetInt(dev, "speed"),
+			PhysPortName: readNetAttr(dev, "phys_port_name"),
+			TotalVfs:     readNetInt(dev, "device/sriov_totalvfs"),
+			NumVfs:       readNetInt(dev, "device/sriov_numvfs"),
+		}
+		if device, err := filepath.EvalSymlinks(filepath.Join(sysClassNet, dev, "device")); err == nil {
+			port.PciAddress = filepath.Base(device)
+		}
+		// Example: ethtool -i eth2
+		if out, err := exec.Command("ethtool", "-i", dev).Output(); err == nil { //nolint:gosec
+			port.Driver, port.Firmware = parseDriverInfo(string(out))
+		}
+		if mode, err := eswitchMode(dev); err == nil {
+			port.EswitchMode = mode
+		}
+		ports = append(ports, port)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Name < ports[j].Name })
+	return ports, nil
+}
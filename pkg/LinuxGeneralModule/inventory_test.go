@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeNetDevice creates a fake sysfs network device with the attributes, the device
+// symlink points to a fake PCI function when pci is set
+func writeNetDevice(t *testing.T, root, dev, pci string, attrs map[string]string) {
+	dir := filepath.Join(root, "class", "net", dev)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if pci != "" {
+		pciDir := filepath.Join(root, "devices", pci)
+		if err := os.MkdirAll(pciDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(pciDir, filepath.Join(dir, "device")); err != nil && !os.IsExist(err) {
+			t.Fatal(err)
+		}
+	}
+	for attr, value := range attrs {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte(value+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func Test_GetPortInventory(t *testing.T) {
+	root := t.TempDir()
+	writeNetDevice(t, root, "p0", "0000:03:00.0", map[string]string{
+		"address": "0c:42:a1:00:00:01", "operstate": "up", "mtu": "9000", "speed": "100000",
+		"phys_port_name": "p0", "phys_switch_id": "aabbcc",
+	})
+	if err := os.WriteFile(filepath.Join(root, "devices", "0000:03:00.0", "sriov_totalvfs"), []byte("127\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "devices", "0000:03:00.0", "sriov_numvfs"), []byte("4\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	writeNetDevice(t, root, "eth5", "0000:03:00.0", map[string]string{"phys_port_name": "pf0vf3", "phys_switch_id": "aabbcc", "speed": "-1"})
+	writeNetDevice(t, root, "eth6", "0000:03:00.4", nil)
+	if err := os.Symlink(filepath.Join(root, "devices", "0000:03:00.0"), filepath.Join(root, "devices", "0000:03:00.4", "physfn")); err != nil {
+		t.Fatal(err)
+	}
+	writeNetDevice(t, root, "br-tenant", "", map[string]string{"operstate": "up"})
+
+	saved := sysClassNet
+	sysClassNet = filepath.Join(root, "class", "net")
+	t.Cleanup(func() { sysClassNet = saved })
+
+	ports, err := GetPortInventory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ports) != 1 {
+		t.Fatalf("expected only the physical port p0, got %+v", ports)
+	}
+	p0 := ports[0]
+	if p0.Name != "p0" || p0.MacAddress != "0c:42:a1:00:00:01" || p0.Speed != 100000 || p0.Mtu != 9000 ||
+		p0.PciAddress != "0000:03:00.0" || p0.TotalVfs != 127 || p0.NumVfs != 4 {
+		t.Errorf("unexpected port %+v", p0)
+	}
+
+	rep, err := FindVfRepresentor("p0", 3)
+	if err != nil || rep != "eth5" {
+		t.Errorf("expected the representor eth5 of VF 3, got %q: %v", rep, err)
+	}
+	if _, err := FindVfRepresentor("p0", 4); err == nil {
+		t.Errorf("expected no representor of VF 4")
+	}
+}
+
+func Test_ParseDriverInfo(t *testing.T) {
+	out := "driver: mlx5_core\nversion: 6.1.0\nfirmware-version: 24.35.2000 (MT_0000000359)\nbus-info: 0000:03:00.0\n"
+	driver, firmware := parseDriverInfo(out)
+	if driver != "mlx5_core" || firmware != "24.35.2000 (MT_0000000359)" {
+		t.Errorf("unexpected driver %q and firmware %q", driver, firmware)
+	}
+}
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+	"github.com/vishvananda/netlink"
+)
+
+// ErrNoSuchDevice is returned when the kernel device does not exist
+var ErrNoSuchDevice = errors.New("no such kernel device")
+
+// KernelLink is a kernel device with the resource it has been created for, read from its alias
+type KernelLink struct {
+	Name string
+	// Owner is empty for the devices which have not been created for a resource
+	Owner string
+}
+
+// KernelDevice is an existing kernel device with the parameters a resource is imported from
+type KernelDevice struct {
+	Name string
+	// Type is the kind of the device, e.g. vrf, bridge, vxlan or vlan
+	Type  string
+	Owner string
+	// Master is the name of the master of the device, empty without one
+	Master string
+	// Slaves are the names of the devices enslaved to the device
+	Slaves []string
+	Mac    net.HardwareAddr
+	// Addresses are the addresses of the device, without the link-local ones
+	Addresses []*net.IPNet
+	// Table is the routing table of a vrf
+	Table uint32
+	// Vni and VtepIP are the VNI and the local address of a vxlan device
+	Vni    uint32
+	VtepIP net.IP
+	// VlanID is the vlan of a vlan device
+	VlanID uint32
+}
+
+// KernelFdbEntry is an entry of the forwarding database of a bridge
+type KernelFdbEntry struct {
+	Mac    string   `json:"mac"`
+	Ifname string   `json:"ifname"`
+	Vlan   int      `json:"vlan"`
+	Flags  []string `json:"flags"`
+	Dst    string   `json:"dst"`
+}
+
+// ExternLearned tells whether the entry has been installed by the routing stack, e.g. a MAC learnt over EVPN
+func (e *KernelFdbEntry) ExternLearned() bool {
+	for _, flag := range e.Flags {
+		if flag == "extern_learn" {
+			return true
+		}
+	}
+	return false
+}
+
+// KernelRoute is a route of a routing table
+type KernelRoute struct {
+	Dst      string `json:"dst"`
+	Type     string `json:"type"`
+	Protocol string `json:"protocol"`
+}
+
+// GetKernelLinks returns the kernel devices with their owner
+func GetKernelLinks(ctx context.Context) ([]KernelLink, error) {
+	if nlink == nil {
+		return nil, errNotInitialized
+	}
+	links, err := nlink.LinkList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]KernelLink, 0, len(links))
+	for _, link := range links {
+		owner, _ := utils.LinkAliasOwner(link.Attrs().Alias)
+		out = append(out, KernelLink{Name: link.Attrs().Name, Owner: owner})
+	}
+	return out, nil
+}
+
+// GetKernelDevice returns the device of the namespace of the bridge with its master, its slaves and its addresses
+func GetKernelDevice(ctx context.Context, name string) (*KernelDevice, error) {
+	if nlink == nil {
+		return nil, errNotInitialized
+	}
+	links, err := nlink.LinkList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var link netlink.Link
+	byIndex := map[int]string{}
+	for _, l := range links {
+		byIndex[l.Attrs().Index] = l.Attrs().Name
+		if l.Attrs().Name == name {
+			link = l
+		}
+	}
+	if link == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoSuchDevice, name)
+	}
+	dev := &KernelDevice{Name: name, Type: link.Type(), Mac: link.Attrs().HardwareAddr, Slaves: []string{}}
+	dev.Owner, _ = utils.LinkAliasOwner(link.Attrs().Alias)
+	if link.Attrs().MasterIndex != 0 {
+		dev.Master = byIndex[link.Attrs().MasterIndex]
+	}
+	for _, l := range links {
+		if l.Attrs().MasterIndex == link.Attrs().Index {
+			dev.Slaves = append(dev.Slaves, l.Attrs().Name)
+		}
+	}
+	switch l := link.(type) {
+	case *netlink.Vrf:
+		dev.Table = l.Table
+	case *netlink.Vxlan:
+		dev.Vni = uint32(l.VxlanId)
+		dev.VtepIP = l.SrcAddr
+	case *netlink.Vlan:
+		dev.VlanID = uint32(l.VlanId)
+	}
+	addrs, err := nlink.AddrList(ctx, link, netlink.FAMILY_ALL)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if addr.IPNet != nil && !addr.IP.IsLinkLocalUnicast() {
+			dev.Addresses = append(dev.Addresses, addr.IPNet)
+		}
+	}
+	return dev, nil
+}
+
+// SegmentBridge returns the name of the linux bridge of the vlan in the topology of the bridge
+func SegmentBridge(vid uint16) string {
+	if topology == nil {
+		return ""
+	}
+	return topology.BridgeName(vid)
+}
+
+// GetVxlanFdb returns the dynamic entries of the forwarding database of the vxlan device of the logical bridge
+func GetVxlanFdb(ctx context.Context, lb *infradb.LogicalBridge) ([]KernelFdbEntry, error) {
+	if nlink == nil {
+		return nil, errNotInitialized
+	}
+	out, err := nlink.ReadFDB(ctx, topology.BridgeName(uint16(lb.Spec.VlanID)))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseKernelJSON[KernelFdbEntry](out)
+	if err != nil {
+		return nil, err
+	}
+	vxlan := fmt.Sprintf("vxlan-%+v", lb.Spec.VlanID)
+	fdb := []KernelFdbEntry{}
+	for _, entry := range entries {
+		if entry.Ifname == vxlan {
+			fdb = append(fdb, entry)
+		}
+	}
+	return fdb, nil
+}
+
+// GetVrfRoutes returns the routes of the routing table of the vrf
+func GetVrfRoutes(ctx context.Context, vrf *infradb.Vrf) ([]KernelRoute, error) {
+	if nlink == nil {
+		return nil, errNotInitialized
+	}
+	if vrf.Metadata == nil || len(vrf.Metadata.RoutingTable) == 0 || vrf.Metadata.RoutingTable[0] == nil {
+		return nil, fmt.Errorf("vrf %s has no routing table", vrf.Name)
+	}
+	out, err := nlink.ReadRoute(ctx, strconv.FormatUint(uint64(*vrf.Metadata.RoutingTable[0]), 10))
+	if err != nil {
+		return nil, err
+	}
+	return parseKernelJSON[KernelRoute](out)
+}
+
+// parseKernelJSON decodes the json output of iproute2, which is empty rather than an empty list at times
+func parseKernelJSON[T any](out string) ([]T, error) {
+	entries := []T{}
+	if len(out) <= 3 {
+		return entries, nil
+	}
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
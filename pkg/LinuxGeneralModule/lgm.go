@@ -7,6 +7,7 @@ package linuxgeneralmodule
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math"
@@ -34,12 +35,6 @@ type ModulelgmHandler struct{}
 // lgmComp string constant
 const lgmComp string = "lgm"
 
-// brStr string constant
-const brStr string = "br-"
-
-// vxlanStr string constant
-const vxlanStr string = "vxlan-"
-
 // routingTableMax max value of routing table
 const routingTableMax = 4000
 
@@ -74,6 +69,48 @@ func (h *ModulelgmHandler) HandleEvent(eventType string, objectData *eventbus.Ob
 	case "logical-bridge":
 		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
 		handleLB(objectData)
+	case "route-leak":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handleRouteLeak(objectData)
+	case "host-route":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handleHostRoute(objectData)
+	case "vpc-peering":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handleVpcPeering(objectData)
+	case "nat-gateway":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handleNatGateway(objectData)
+	case "flow-log":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handleFlowLog(objectData)
+	case "dns-forwarder":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handleDNSForwarder(objectData)
+	case "dhcp-server":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handleDHCPServer(objectData)
+	case "router-advertisement":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handleRouterAdvertisement(objectData)
+	case "external-interface":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handleExternalInterface(objectData)
+	case "bond":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handleBond(objectData)
+	case "port-security":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handlePortSecurity(objectData)
+	case "dhcp-snooping":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handleDHCPSnooping(objectData)
+	case "conntrack-policy":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handleConntrackPolicy(objectData)
+	case "vf-representor":
+		log.Printf("LGM recevied %s %s\n", eventType, objectData.Name)
+		handleVfRepresentor(objectData)
 	default:
 		log.Printf("LGM: error: Unknown event type %s", eventType)
 	}
@@ -125,6 +162,16 @@ func handleLB(objectData *eventbus.ObjectData) {
 		}
 	}
 	if lb.Status.LBOperStatus != infradb.LogicalBridgeOperStatusToBeDeleted {
+		if lb.Spec.Vni != nil && waitForDependency("logical-bridge", lb.Name, lb.Spec.VtepIP, &comp) {
+			log.Printf("LGM: %+v \n", comp)
+			err := infradb.UpdateLBStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
+			if err != nil {
+				log.Printf("error in updating lb status: %s\n", err)
+			}
+			// The dependency may have appeared meanwhile
+			go resumeDeferred()
+			return
+		}
 		details, status := setUpBridge(lb)
 		comp.Name = lgmComp
 		comp.Details = details
@@ -145,6 +192,7 @@ func handleLB(objectData *eventbus.ObjectData) {
 			log.Printf("error in updating lb status: %s\n", err)
 		}
 	} else {
+		forgetObject(lb.Name)
 		details, status := tearDownBridge(lb)
 		comp.Name = lgmComp
 		comp.Details = details
@@ -300,6 +348,16 @@ func handlevrf(objectData *eventbus.ObjectData) {
 		}
 	}
 	if vrf.Status.VrfOperStatus != infradb.VrfOperStatusToBeDeleted {
+		if path.Base(vrf.Name) != "GRD" && vrf.Spec.Vni != nil && waitForDependency("vrf", vrf.Name, vrf.Spec.VtepIP, &comp) {
+			log.Printf("LGM: %+v \n", comp)
+			err := infradb.UpdateVrfStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
+			if err != nil {
+				log.Printf("error in updating vrf status: %s\n", err)
+			}
+			// The dependency may have appeared meanwhile
+			go resumeDeferred()
+			return
+		}
 		details, status := setUpVrf(vrf)
 		comp.Name = lgmComp
 		comp.Details = details
@@ -320,6 +378,7 @@ func handlevrf(objectData *eventbus.ObjectData) {
 			log.Printf("error in updating vrf status: %s\n", err)
 		}
 	} else {
+		forgetObject(vrf.Name)
 		details, status := tearDownVrf(vrf)
 		comp.Name = lgmComp
 		comp.Details = details
@@ -345,8 +404,8 @@ func handlevrf(objectData *eventbus.ObjectData) {
 // ipMtu variable int
 var ipMtu int
 
-// brTenant variable string
-var brTenant string
+// topology maps the logical bridges onto linux bridges
+var topology utils.BridgeTopology
 
 // ctx variable context
 var ctx context.Context
@@ -368,19 +427,25 @@ func Initialize() {
 			}
 		}
 	}
-	brTenant = "br-tenant"
 	ipMtu = config.GlobalConfig.LinuxFrr.IPMtu
 	ctx = context.Background()
 	if RouteTableGen, ok = utils.IDPoolInit("RTtable", routingTableMin, routingTableMax); !ok {
 		log.Printf("LGM: Failed in the assigning id \n")
 		return
 	}
-	nlink = utils.NewNetlinkWrapperWithArgs(false)
-	// Set up the static configuration parts
-	_, err := nlink.LinkByName(ctx, brTenant)
+	nlink = utils.WithWriteQueue(utils.WithNetlinkFaults(utils.NewNetlinkWrapperWithArgs(false)), utils.DefaultNamespace)
+	var err error
+	topology, err = utils.NewBridgeTopology(config.GlobalConfig.LinuxFrr.BridgeTopology, nlink, ipMtu+20)
 	if err != nil {
-		setUpTenantBridge()
+		log.Fatalf("LGM: %v\n", err)
 	}
+	// Set up the static configuration parts
+	if err := topology.SetUp(ctx); err != nil {
+		log.Fatalf("LGM: %v\n", err)
+	}
+	migrateIfNames()
+	collectGarbage()
+	watchDependencies()
 }
 
 // DeInitialize function handles stops functionality
@@ -388,34 +453,18 @@ func DeInitialize() {
 	eb := eventbus.EBus
 	err := TearDownTenantBridge()
 	if err != nil {
-		log.Printf("LGM: Failed to tear down br-tenant: %v\n", err)
+		log.Printf("LGM: Failed to tear down the tenant bridges: %v\n", err)
 	}
-	eb.UnsubscribeModule("lgm")
-}
-func setUpTenantBridge() {
-	brTenantMtu := ipMtu + 20
-	vlanfiltering := true
-	bridge := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: brTenant},
-		VlanDefaultPVID: new(uint16),
-		VlanFiltering:   &vlanfiltering,
-	}
-
-	if err := nlink.LinkAdd(ctx, bridge); err != nil {
-		log.Fatalf("LGM: Failed to create br-tenant: %v\n", err)
-	}
-
-	if err := nlink.LinkSetMTU(ctx, bridge, brTenantMtu); err != nil {
-		log.Fatalf("LGM : Unable to set MTU %v to br-tenant: %v\n", brTenantMtu, err)
-	}
-
-	if err := nlink.LinkSetUp(ctx, bridge); err != nil {
-		log.Fatalf("LGM: Failed to set up br-tenant: %v\n", err)
+	if stopDependencies != nil {
+		close(stopDependencies)
+		stopDependencies = nil
 	}
+	eb.UnsubscribeModule("lgm")
 }
 
 // routingtableBusy checks if the route is in filterred list
-func routingtableBusy(table uint32) (bool, error) {
-	routeList, err := nlink.RouteListFiltered(ctx, netlink.FAMILY_V4, &netlink.Route{Table: int(table)}, netlink.RT_FILTER_TABLE)
+func routingtableBusy(nl utils.Netlink, table uint32) (bool, error) {
+	routeList, err := nl.RouteListFiltered(ctx, netlink.FAMILY_V4, &netlink.Route{Table: int(table)}, netlink.RT_FILTER_TABLE)
 	if err != nil {
 		return false, err
 	}
@@ -423,35 +472,54 @@ func routingtableBusy(table uint32) (bool, error) {
 }
 
 // setUpBridge sets up the bridge
-func setUpBridge(lb *infradb.LogicalBridge) (string, bool) {
+func setUpBridge(lb *infradb.LogicalBridge) (_ string, ok bool) {
+	undo := &utils.UndoStack{}
+	defer func() {
+		if !ok {
+			rollBack(undo, lb.Name)
+		}
+	}()
 	link := fmt.Sprintf("vxlan-%+v", lb.Spec.VlanID)
-	if !reflect.ValueOf(lb.Spec.Vni).IsZero() {
-		brIntf, err := nlink.LinkByName(ctx, brTenant)
-		if err != nil {
-			log.Printf("LGM: Failed to get link information for %s: %v\n", brTenant, err)
-			return fmt.Sprintf("LGM: Failed to get link information for %s: %v\n", brTenant, err), false
+	if lb.Spec.IsGeneve() {
+		// The logical bridge may be moving from its vxlan device
+		if err := delLinkByName(link)(); err == nil {
+			log.Printf("LGM: Executed ip link delete %s", link)
 		}
-		vxlan := &netlink.Vxlan{LinkAttrs: netlink.LinkAttrs{Name: link, MTU: ipMtu}, VxlanId: int(*lb.Spec.Vni), Port: 4789, Learning: false, SrcAddr: lb.Spec.VtepIP.IP}
-		if err := nlink.LinkAdd(ctx, vxlan); err != nil {
-			log.Printf("LGM: Failed to create Vxlan linki %s: %v\n", link, err)
-			return fmt.Sprintf("LGM: Failed to create Vxlan linki %s: %v\n", link, err), false
+		if err := setUpGeneve(lb); err != nil {
+			log.Printf("LGM: Failed to carry %s over geneve: %v\n", lb.Name, err)
+			return fmt.Sprintf("LGM: Failed to carry %s over geneve: %v\n", lb.Name, err), false
 		}
-		// Example: ip link set vxlan-<lb-vlan-id> master br-tenant addrgenmode none
-		if err = nlink.LinkSetMaster(ctx, vxlan, brIntf); err != nil {
-			log.Printf("LGM: Failed to add Vxlan %s to bridge %s: %v\n", link, brTenant, err)
-			return fmt.Sprintf("LGM: Failed to add Vxlan %s to bridge %s: %v\n", link, brTenant, err), false
+		return "", true
+	}
+	if !reflect.ValueOf(lb.Spec.Vni).IsZero() {
+		// The logical bridge may be moving back from geneve
+		tearDownGeneve(lb)
+		bridge := topology.BridgeName(uint16(lb.Spec.VlanID))
+		var vxlan netlink.Link = &netlink.Vxlan{LinkAttrs: netlink.LinkAttrs{Name: link, MTU: ipMtu}, VxlanId: int(*lb.Spec.Vni), Port: 4789, Learning: false, SrcAddr: lb.Spec.VtepIP.IP}
+		// The vxlan device of an adopted logical bridge is kept as it is
+		if existing, ok := takeOver(nlink, link, lb.Name, isVxlan(*lb.Spec.Vni)); ok {
+			vxlan = existing
+		} else {
+			if err := nlink.LinkAdd(ctx, vxlan); err != nil {
+				log.Printf("LGM: Failed to create Vxlan linki %s: %v\n", link, err)
+				return fmt.Sprintf("LGM: Failed to create Vxlan linki %s: %v\n", link, err), false
+			}
+			undo.Push("ip link add "+link, delLinkByName(link))
+			if err := tagLink(vxlan, lb.Name); err != nil {
+				return fmt.Sprintf("LGM: Failed to set the alias of Vxlan link %s: %v\n", link, err), false
+			}
+		}
+		if err := topology.AttachVxlan(ctx, vxlan, uint16(lb.Spec.VlanID)); err != nil {
+			log.Printf("LGM: Failed to add Vxlan %s to bridge %s: %v\n", link, bridge, err)
+			return fmt.Sprintf("LGM: Failed to add Vxlan %s to bridge %s: %v\n", link, bridge, err), false
 		}
 		// Example: ip link set vxlan-<lb-vlan-id> up
-		if err = nlink.LinkSetUp(ctx, vxlan); err != nil {
+		if err := nlink.LinkSetUp(ctx, vxlan); err != nil {
 			log.Printf("LGM: Failed to up Vxlan link %s: %v\n", link, err)
 			return fmt.Sprintf("LGM: Failed to up Vxlan link %s: %v\n", link, err), false
 		}
-		// Example: bridge vlan add dev vxlan-<lb-vlan-id> vid <lb-vlan-id> pvid untagged
-		if err = nlink.BridgeVlanAdd(ctx, vxlan, uint16(lb.Spec.VlanID), true, true, false, false); err != nil {
-			log.Printf("LGM: Failed to add vlan to bridge %s: %v\n", brTenant, err)
-			return fmt.Sprintf("LGM: Failed to add vlan to bridge %s: %v\n", brTenant, err), false
-		}
-		if err = nlink.LinkSetBrNeighSuppress(ctx, vxlan, true); err != nil {
+		// The local proxy ARP of the svi answers the ARP requests of the remote hosts too
+		if err := nlink.LinkSetBrNeighSuppress(ctx, vxlan, !sviHasLocalProxyArp(lb)); err != nil {
 			log.Printf("LGM: Failed to add bridge %v neigh_suppress: %s\n", vxlan, err)
 			return fmt.Sprintf("LGM: Failed to add bridge %v neigh_suppress: %s\n", vxlan, err), false
 		}
@@ -464,7 +532,13 @@ func setUpBridge(lb *infradb.LogicalBridge) (string, bool) {
 // setUpVrf sets up the vrf
 //
 //nolint:funlen,gocognit
-func setUpVrf(vrf *infradb.Vrf) (string, bool) {
+func setUpVrf(vrf *infradb.Vrf) (_ string, ok bool) {
+	undo := &utils.UndoStack{}
+	defer func() {
+		if !ok {
+			rollBack(undo, vrf.Name)
+		}
+	}()
 	IPMtu := fmt.Sprintf("%+v", ipMtu)
 	if path.Base(vrf.Name) == "GRD" {
 		vrf.Metadata.RoutingTable = make([]*uint32, 2)
@@ -474,13 +548,37 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 		*vrf.Metadata.RoutingTable[1] = 255
 		return "", true
 	}
+	vrfLink := infradb.LinkName(vrf.Name, infradb.LinkRoleVrf)
+	brLink := infradb.LinkName(vrf.Name, infradb.LinkRoleBridge)
+	// A vrf moving to another network namespace is torn down in the one it leaves
+	if namespace, found := getVrfNetns(vrf.Name); found && namespace != vrf.Spec.Netns {
+		stale := *vrf
+		staleSpec := *vrf.Spec
+		staleSpec.Netns = namespace
+		stale.Spec = &staleSpec
+		if details, ok := tearDownVrf(&stale); !ok {
+			return details, false
+		}
+	}
+	nl, err := netnsLink(vrf.Spec.Netns)
+	if err != nil {
+		log.Printf("LGM: %v\n", err)
+		return fmt.Sprintf("LGM: %v\n", err), false
+	}
+	// A vrf in place, or adopted from the kernel with its routing table, is only switched over its dataplane
+	if vrf.Metadata != nil && len(vrf.Metadata.RoutingTable) != 0 && vrf.Metadata.RoutingTable[0] != nil {
+		if _, err := nl.LinkByName(ctx, vrfLink); err == nil {
+			takeOverVrf(nl, vrf)
+			return switchVrfDataplane(nl, vrf)
+		}
+	}
 	vrf.Metadata.RoutingTable = make([]*uint32, 1)
 	vrf.Metadata.RoutingTable[0] = new(uint32)
 	var routingtable uint32
 	name := vrf.Name
 	routingtable = RouteTableGen.GetID(name)
 	log.Printf("LGM assigned id %+v for vrf name %s\n", routingtable, vrf.Name)
-	isbusy, err := routingtableBusy(routingtable)
+	isbusy, err := routingtableBusy(nl, routingtable)
 	if err != nil {
 		log.Printf("LGM : Error occurred when checking if routing table %d is busy: %+v\n", routingtable, err)
 		return "", false
@@ -491,7 +589,7 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 	var vtip string
 	if !reflect.ValueOf(vrf.Spec.VtepIP).IsZero() {
 		vtip = fmt.Sprintf("%+v", vrf.Spec.VtepIP.IP)
-		// Verify that the specified VTEP IP exists as local IP
+		// Verify that the specified VTEP IP exists as local IP, the underlay stays in the namespace of the bridge
 		err := nlink.RouteListIPTable(ctx, vtip)
 		// Not found similar API in viswananda library so retain the linux commands as it is .. not able to get the route list exact vtip table local
 		if !err {
@@ -502,47 +600,58 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 	log.Printf("setUpVrf: %s %d\n", vtip, routingtable)
 	// Create the vrf interface for the specified routing table and add loopback address
 
-	linkAdderr := nlink.LinkAdd(ctx, &netlink.Vrf{
-		LinkAttrs: netlink.LinkAttrs{Name: path.Base(vrf.Name)},
+	linkAdderr := nl.LinkAdd(ctx, &netlink.Vrf{
+		LinkAttrs: netlink.LinkAttrs{Name: vrfLink},
 		Table:     routingtable,
 	})
 	if linkAdderr != nil {
 		log.Printf("LGM: Error in Adding vrf link table %d\n", routingtable)
 		return fmt.Sprintf("LGM: Error in Adding vrf link table %d\n", routingtable), false
 	}
+	undo.Push("ip link add "+vrfLink, delLinkIn(nl, vrfLink))
 
 	log.Printf("LGM: vrf link %s Added with table id %d\n", vrf.Name, routingtable)
 
-	link, linkErr := nlink.LinkByName(ctx, path.Base(vrf.Name))
+	link, linkErr := nl.LinkByName(ctx, vrfLink)
 	if linkErr != nil {
 		log.Printf("LGM : Link %s not found\n", vrf.Name)
 		return fmt.Sprintf("LGM : Link %s not found\n", vrf.Name), false
 	}
+	if err := tagLinkIn(nl, link, vrf.Name); err != nil {
+		return fmt.Sprintf("LGM : Unable to set the alias of link %s: %v\n", vrfLink, err), false
+	}
+
+	if details, ok := applyDeviceSysctls(vrf.Spec.Netns, vrfLink, defaultVrfSysctls, config.GlobalConfig.Sysctls.Vrf); !ok {
+		return details, false
+	}
 
-	linkmtuErr := nlink.LinkSetMTU(ctx, link, ipMtu)
+	linkmtuErr := nl.LinkSetMTU(ctx, link, ipMtu)
 	if linkmtuErr != nil {
 		log.Printf("LGM : Unable to set MTU to link %s \n", vrf.Name)
 		return fmt.Sprintf("LGM : Unable to set MTU to link %s \n", vrf.Name), false
 	}
 
-	linksetupErr := nlink.LinkSetUp(ctx, link)
+	linksetupErr := nl.LinkSetUp(ctx, link)
 	if linksetupErr != nil {
 		log.Printf("LGM : Unable to set link %s UP \n", vrf.Name)
 		return fmt.Sprintf("LGM : Unable to set link %s UP \n", vrf.Name), false
 	}
-	Lbip := fmt.Sprintf("%+v", vrf.Spec.LoopbackIP.IP)
+	// The loopback is optional, the BGP instance of the vrf then takes its router id from the VTEP
+	if vrf.Spec.LoopbackIP != nil {
+		Lbip := fmt.Sprintf("%+v", vrf.Spec.LoopbackIP.IP)
 
-	var address = vrf.Spec.LoopbackIP
-	var Addrs = &netlink.Addr{
-		IPNet: address,
-	}
-	addrErr := nlink.AddrAdd(ctx, link, Addrs)
-	if addrErr != nil {
-		log.Printf("LGM: Unable to set the loopback ip to vrf link %s \n", vrf.Name)
-		return fmt.Sprintf("LGM: Unable to set the loopback ip to vrf link %s \n", vrf.Name), false
-	}
+		var address = vrf.Spec.LoopbackIP
+		var Addrs = &netlink.Addr{
+			IPNet: address,
+		}
+		addrErr := nl.AddrAdd(ctx, link, Addrs)
+		if addrErr != nil {
+			log.Printf("LGM: Unable to set the loopback ip to vrf link %s \n", vrf.Name)
+			return fmt.Sprintf("LGM: Unable to set the loopback ip to vrf link %s \n", vrf.Name), false
+		}
 
-	log.Printf("LGM: Added Address %s dev %s\n", Lbip, vrf.Name)
+		log.Printf("LGM: Added Address %s dev %s\n", Lbip, vrf.Name)
+	}
 
 	Src1 := net.IPv4(0, 0, 0, 0)
 	route := netlink.Route{
@@ -552,11 +661,15 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 		Priority: 9999,
 		Src:      Src1,
 	}
-	routeaddErr := nlink.RouteAdd(ctx, &route)
+	routeaddErr := nl.RouteAdd(ctx, &route)
 	if routeaddErr != nil {
 		log.Printf("LGM : Failed in adding Route throw default %+v\n", routeaddErr)
 		return fmt.Sprintf("LGM : Failed in adding Route throw default %+v\n", routeaddErr), false
 	}
+	// The route stays in the table when the vrf device is deleted
+	undo.Push(fmt.Sprintf("ip route add throw default table %d", routingtable), func() error {
+		return nl.RouteDel(ctx, &route)
+	})
 
 	log.Printf("LGM : Added route throw default table %d proto opi_evpn_br metric 9999\n", routingtable)
 	// Disable reverse-path filtering to accept ingress traffic punted by the pipeline
@@ -568,172 +681,281 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 		// name. We need to assign a true random MAC address to avoid collisions when pairing two
 		// servers.
 
-		brErr := nlink.LinkAdd(ctx, &netlink.Bridge{
-			LinkAttrs: netlink.LinkAttrs{Name: brStr + path.Base(vrf.Name)},
+		brErr := nl.LinkAdd(ctx, &netlink.Bridge{
+			LinkAttrs: netlink.LinkAttrs{Name: brLink},
 		})
 		if brErr != nil {
 			log.Printf("LGM : Error in added bridge port\n")
 			return fmt.Sprintf("LGM : Error in added bridge port %v", brErr), false
 		}
-		log.Printf("LGM : Added link br-%s type bridge\n", vrf.Name)
+		undo.Push("ip link add "+brLink, delLinkIn(nl, brLink))
+		log.Printf("LGM : Added link %s type bridge\n", brLink)
 
 		rmac := fmt.Sprintf("%+v", GenerateMac()) // str(macaddress.MAC(b'\x00'+random.randbytes(5))).replace("-", ":")
 		hw, _ := net.ParseMAC(rmac)
 
-		linkBr, brErr := nlink.LinkByName(ctx, brStr+path.Base(vrf.Name))
+		linkBr, brErr := nl.LinkByName(ctx, brLink)
 		if brErr != nil {
-			log.Printf("LGM : Error in getting the br-%s\n", vrf.Name)
-			return fmt.Sprintf("LGM : Error in getting the br-%s\n", vrf.Name), false
+			log.Printf("LGM : Error in getting the %s\n", brLink)
+			return fmt.Sprintf("LGM : Error in getting the %s\n", brLink), false
 		}
-		hwErr := nlink.LinkSetHardwareAddr(ctx, linkBr, hw)
+		if err := tagLinkIn(nl, linkBr, vrf.Name); err != nil {
+			return fmt.Sprintf("LGM : Unable to set the alias of link %s: %v\n", brLink, err), false
+		}
+		hwErr := nl.LinkSetHardwareAddr(ctx, linkBr, hw)
 		if hwErr != nil {
 			log.Printf("LGM: Failed in the setting Hardware Address\n")
 			return fmt.Sprintf("LGM: Failed in the setting Hardware Address: %v\n", hwErr), false
 		}
 
-		linkmtuErr := nlink.LinkSetMTU(ctx, linkBr, ipMtu)
+		linkmtuErr := nl.LinkSetMTU(ctx, linkBr, ipMtu)
 		if linkmtuErr != nil {
-			log.Printf("LGM : Unable to set MTU to link br-%s \n", vrf.Name)
-			return fmt.Sprintf("LGM : Unable to set MTU to link br-%s \n", vrf.Name), false
+			log.Printf("LGM : Unable to set MTU to link %s \n", brLink)
+			return fmt.Sprintf("LGM : Unable to set MTU to link %s \n", brLink), false
 		}
 
-		linkMaster, errMaster := nlink.LinkByName(ctx, path.Base(vrf.Name))
+		linkMaster, errMaster := nl.LinkByName(ctx, vrfLink)
 		if errMaster != nil {
 			log.Printf("LGM : Error in getting the %s\n", vrf.Name)
 			return fmt.Sprintf("LGM : Error in getting the %s\n", vrf.Name), false
 		}
 
-		err := nlink.LinkSetMaster(ctx, linkBr, linkMaster)
+		err := nl.LinkSetMaster(ctx, linkBr, linkMaster)
 		if err != nil {
-			log.Printf("LGM : Unable to set the master to br-%s link", vrf.Name)
-			return fmt.Sprintf("LGM : Unable to set the master to br-%s link", vrf.Name), false
+			log.Printf("LGM : Unable to set the master to %s link", brLink)
+			return fmt.Sprintf("LGM : Unable to set the master to %s link", brLink), false
 		}
 
-		linksetupErr = nlink.LinkSetUp(ctx, linkBr)
+		linksetupErr = nl.LinkSetUp(ctx, linkBr)
 		if linksetupErr != nil {
 			log.Printf("LGM : Unable to set link %s UP \n", vrf.Name)
 			return fmt.Sprintf("LGM : Unable to set link %s UP \n", vrf.Name), false
 		}
-		log.Printf("LGM: link set  br-%s master  %s up mtu \n", vrf.Name, IPMtu)
-
-		// Create the VXLAN link in the external bridge
+		log.Printf("LGM: link set  %s master  %s up mtu %s\n", brLink, vrfLink, IPMtu)
 
-		SrcVtep := vrf.Spec.VtepIP.IP
-		vxlanErr := nlink.LinkAdd(ctx, &netlink.Vxlan{
-			LinkAttrs: netlink.LinkAttrs{Name: vxlanStr + path.Base(vrf.Name), MTU: ipMtu}, VxlanId: int(*vrf.Spec.Vni), SrcAddr: SrcVtep, Learning: false, Proxy: true, Port: 4789})
-		if vxlanErr != nil {
-			log.Printf("LGM : Error in added vxlan port\n")
-			return fmt.Sprintf("LGM : Error in added vxlan port %v\n", vxlanErr), false
+		// The VPCs carried over a VPN have no L3 VNI
+		if !vrf.Spec.IsVpn() {
+			if details, ok := setUpVrfVxlan(nl, vrf, linkBr, undo); !ok {
+				return details, false
+			}
 		}
+	}
+	*vrf.Metadata.RoutingTable[0] = routingtable
+	setVrfNetns(vrf.Name, vrf.Spec.Netns)
+	return setUpSrv6Sids(vrf, routingtable)
+}
 
-		log.Printf("LGM : link added vxlan-%s type vxlan id %d local %s dstport 4789 nolearning proxy\n", vrf.Name, *vrf.Spec.Vni, vtip)
+// setUpVrfVxlan creates the L3 VNI of the vrf in its external bridge. The vxlan device of a vrf in another
+// network namespace is created in the one of the bridge, where its socket stays on the underlay, then moved.
+func setUpVrfVxlan(nl utils.Netlink, vrf *infradb.Vrf, linkBr netlink.Link, undo *utils.UndoStack) (string, bool) {
+	vxlanLink := infradb.LinkName(vrf.Name, infradb.LinkRoleVxlan)
+	vtip := fmt.Sprintf("%+v", vrf.Spec.VtepIP.IP)
+	SrcVtep := vrf.Spec.VtepIP.IP
+	vxlan := &netlink.Vxlan{
+		LinkAttrs: netlink.LinkAttrs{Name: vxlanLink, MTU: ipMtu}, VxlanId: int(*vrf.Spec.Vni), SrcAddr: SrcVtep, Learning: false, Proxy: true, Port: 4789}
+	vxlanErr := nlink.LinkAdd(ctx, vxlan)
+	if vxlanErr != nil {
+		log.Printf("LGM : Error in added vxlan port\n")
+		return fmt.Sprintf("LGM : Error in added vxlan port %v\n", vxlanErr), false
+	}
 
-		linkVxlan, vxlanErr := nlink.LinkByName(ctx, vxlanStr+path.Base(vrf.Name))
-		if vxlanErr != nil {
-			log.Printf("LGM : Error in getting the %s\n", vxlanStr+vrf.Name)
-			return fmt.Sprintf("LGM : Error in getting the %s\n", vxlanStr+vrf.Name), false
-		}
+	log.Printf("LGM : link added %s type vxlan id %d local %s dstport 4789 nolearning proxy\n", vxlanLink, *vrf.Spec.Vni, vtip)
 
-		err = nlink.LinkSetMaster(ctx, linkVxlan, linkBr)
-		if err != nil {
-			log.Printf("LGM : Unable to set the master to vxlan-%s link", vrf.Name)
-			return fmt.Sprintf("LGM : Unable to set the master to vxlan-%s link", vrf.Name), false
-		}
+	linkVxlan, vxlanErr := moveToNetns(vxlan, vrf.Spec.Netns, nl)
+	if vxlanErr != nil {
+		log.Printf("LGM : %v\n", vxlanErr)
+		return fmt.Sprintf("LGM : %v\n", vxlanErr), false
+	}
+	undo.Push("ip link add "+vxlanLink, delLinkIn(nl, vxlanLink))
+	if err := tagLinkIn(nl, linkVxlan, vrf.Name); err != nil {
+		return fmt.Sprintf("LGM : Unable to set the alias of link %s: %v\n", vxlanLink, err), false
+	}
 
-		log.Printf("LGM: vrf Link vxlan setup master\n")
+	err := nl.LinkSetMaster(ctx, linkVxlan, linkBr)
+	if err != nil {
+		log.Printf("LGM : Unable to set the master to %s link", vxlanLink)
+		return fmt.Sprintf("LGM : Unable to set the master to %s link", vxlanLink), false
+	}
 
-		linksetupErr = nlink.LinkSetUp(ctx, linkVxlan)
-		if linksetupErr != nil {
-			log.Printf("LGM : Unable to set link %s UP \n", vrf.Name)
-			return fmt.Sprintf("LGM : Unable to set link %s UP \n", vrf.Name), false
+	log.Printf("LGM: vrf Link vxlan setup master\n")
+
+	linksetupErr := nl.LinkSetUp(ctx, linkVxlan)
+	if linksetupErr != nil {
+		log.Printf("LGM : Unable to set link %s UP \n", vrf.Name)
+		return fmt.Sprintf("LGM : Unable to set link %s UP \n", vrf.Name), false
+	}
+	return "", true
+}
+
+// switchVrfDataplane carries the vrf in place over its dataplane: the L3 VNI is removed when the VPC
+// moves to a VPN and it is created again when the VPC moves back to VXLAN, the SRv6 SIDs follow the VPC
+func switchVrfDataplane(nl utils.Netlink, vrf *infradb.Vrf) (string, bool) {
+	routingtable := *vrf.Metadata.RoutingTable[0]
+	vxlanLink := infradb.LinkName(vrf.Name, infradb.LinkRoleVxlan)
+	linkVxlan, err := nl.LinkByName(ctx, vxlanLink)
+	switch {
+	case vrf.Spec.IsVpn() && err == nil:
+		if err := nl.LinkDel(ctx, linkVxlan); err != nil {
+			log.Printf("LGM: Error in delete vxlan %+v\n", err)
+			return fmt.Sprintf("LGM: Error in delete vxlan %+v\n", err), false
+		}
+		log.Printf("LGM : Delete %s\n", vxlanLink)
+	case !vrf.Spec.IsVpn() && err != nil && vrf.Spec.Vni != nil:
+		brLink := infradb.LinkName(vrf.Name, infradb.LinkRoleBridge)
+		linkBr, err := nl.LinkByName(ctx, brLink)
+		if err != nil {
+			log.Printf("LGM : Error in getting the %s\n", brLink)
+			return fmt.Sprintf("LGM : Error in getting the %s\n", brLink), false
+		}
+		undo := &utils.UndoStack{}
+		if msg, ok := setUpVrfVxlan(nl, vrf, linkBr, undo); !ok {
+			rollBack(undo, vrf.Name)
+			return msg, false
 		}
 	}
-	details := fmt.Sprintf("{\"routingtable\":\"%d\"}", routingtable)
-	*vrf.Metadata.RoutingTable[0] = routingtable
-	return details, true
+	// The vrfs in another network namespace are never carried over SRv6
+	if !vrf.Spec.IsSrv6() && vrf.Spec.Netns == "" {
+		tearDownSrv6Sids(vrf)
+	}
+	setVrfNetns(vrf.Name, vrf.Spec.Netns)
+	return setUpSrv6Sids(vrf, routingtable)
 }
 
 // setUpSvi sets up the svi
-func setUpSvi(svi *infradb.Svi) (string, bool) {
+func setUpSvi(svi *infradb.Svi) (_ string, ok bool) {
+	undo := &utils.UndoStack{}
+	defer func() {
+		if !ok {
+			rollBack(undo, svi.Name)
+		}
+	}()
 	BrObj, err := infradb.GetLB(svi.Spec.LogicalBridge)
 	if err != nil {
 		log.Printf("LGM: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err)
 		return fmt.Sprintf("LGM: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err), false
 	}
-	linkSvi := fmt.Sprintf("%+v-%+v", path.Base(svi.Spec.Vrf), BrObj.Spec.VlanID)
-	brIntf, err := nlink.LinkByName(ctx, brTenant)
-	if err != nil {
-		log.Printf("LGM : Failed to get link information for %s: %v\n", brTenant, err)
-		return fmt.Sprintf("LGM : Failed to get link information for %s: %v\n", brTenant, err), false
-	}
+	linkSvi := infradb.SviLinkName(svi, BrObj.Spec.VlanID)
 	if BrObj.Spec.VlanID > math.MaxUint16 {
 		log.Printf("LGM : VlanID %v value passed in Logical Bridge create is greater than 16 bit value\n", BrObj.Spec.VlanID)
 		return fmt.Sprintf("LGM : VlanID %v value passed in Logical Bridge create is greater than 16 bit value\n", BrObj.Spec.VlanID), false
 	}
 	vid := uint16(BrObj.Spec.VlanID)
-	if err = nlink.BridgeVlanAdd(ctx, brIntf, vid, false, false, true, false); err != nil {
-		log.Printf("LGM : Failed to add VLAN %d to bridge interface %s: %v\n", vid, brTenant, err)
-		return fmt.Sprintf("LGM : Failed to add VLAN %d to bridge interface %s: %v\n", vid, brTenant, err), false
-	}
-	log.Printf("LGM Executed : bridge vlan add dev %s vid %d self\n", brTenant, vid)
+	// The svi follows its vrf to its network namespace, its vlan stays on the bridge of the namespace of the bridge
+	namespace := infradb.SviNetns(svi)
+	nl, err := netnsLink(namespace)
+	if err != nil {
+		log.Printf("LGM: %v\n", err)
+		return fmt.Sprintf("LGM: %v\n", err), false
+	}
+	bridge := topology.BridgeName(vid)
+	// The vlan device of an adopted svi is kept as it is, with its addresses
+	vlanLink, adopted := takeOver(nl, linkSvi, svi.Name, isVlan(vid))
+	if !adopted {
+		vlanLink, err = topology.AddSvi(ctx, linkSvi, vid)
+		if err != nil {
+			log.Printf("LGM : Failed to add SVI %s on bridge %s: %v\n", linkSvi, bridge, err)
+			return fmt.Sprintf("LGM : Failed to add SVI %s on bridge %s: %v\n", linkSvi, bridge, err), false
+		}
+		undo.Push(fmt.Sprintf("vlan %d of bridge %s", vid, bridge), func() error {
+			return topology.ReleaseSvi(ctx, vid)
+		})
+		if vlanLink, err = moveToNetns(vlanLink, namespace, nl); err != nil {
+			log.Printf("LGM : %v\n", err)
+			return fmt.Sprintf("LGM : %v\n", err), false
+		}
+		undo.Push("ip link add "+linkSvi, delLinkIn(nl, linkSvi))
+		if err := tagLinkIn(nl, vlanLink, svi.Name); err != nil {
+			return fmt.Sprintf("LGM : Failed to set the alias of SVI %s: %v\n", linkSvi, err), false
+		}
 
-	vlanLink := &netlink.Vlan{LinkAttrs: netlink.LinkAttrs{Name: linkSvi, ParentIndex: brIntf.Attrs().Index}, VlanId: int(BrObj.Spec.VlanID)}
-	if err = nlink.LinkAdd(ctx, vlanLink); err != nil {
-		log.Printf("LGM : Failed to add VLAN sub-interface %s: %v\n", linkSvi, err)
-		return fmt.Sprintf("LGM : Failed to add VLAN sub-interface %s: %v\n", linkSvi, err), false
+		log.Printf("LGM Executed : ip link add link %s name %s vlan %d\n", bridge, linkSvi, vid)
 	}
-
-	log.Printf("LGM Executed : ip link add link %s name %s type vlan id %d\n", brTenant, linkSvi, vid)
-	if err = nlink.LinkSetHardwareAddr(ctx, vlanLink, *svi.Spec.MacAddress); err != nil {
+	if err = nl.LinkSetHardwareAddr(ctx, vlanLink, *svi.Spec.MacAddress); err != nil {
 		log.Printf("LGM : Failed to set link %v: %s\n", vlanLink, err)
 		return fmt.Sprintf("LGM : Failed to set link %v: %s\n", vlanLink, err), false
 	}
 
 	log.Printf("LGM Executed : ip link set %s address %s\n", linkSvi, *svi.Spec.MacAddress)
-	vrfIntf, err := nlink.LinkByName(ctx, path.Base(svi.Spec.Vrf))
+	vrfLink := infradb.LinkName(svi.Spec.Vrf, infradb.LinkRoleVrf)
+	vrfIntf, err := nl.LinkByName(ctx, vrfLink)
 	if err != nil {
-		log.Printf("LGM : Failed to get link information for %s: %v\n", path.Base(svi.Spec.Vrf), err)
-		return fmt.Sprintf("LGM : Failed to get link information for %s: %v\n", path.Base(svi.Spec.Vrf), err), false
+		log.Printf("LGM : Failed to get link information for %s: %v\n", vrfLink, err)
+		return fmt.Sprintf("LGM : Failed to get link information for %s: %v\n", vrfLink, err), false
 	}
-	if err = nlink.LinkSetMaster(ctx, vlanLink, vrfIntf); err != nil {
+	if err = nl.LinkSetMaster(ctx, vlanLink, vrfIntf); err != nil {
 		log.Printf("LGM : Failed to set master for %v: %s\n", vlanLink, err)
 		return fmt.Sprintf("LGM : Failed to set master for %v: %s\n", vlanLink, err), false
 	}
-	if err = nlink.LinkSetUp(ctx, vlanLink); err != nil {
+	// The settings apply to the gateway addresses, they are written before the addresses are added
+	if details, ok := applyDeviceSysctls(namespace, linkSvi, defaultSviSysctls, sviSysctls(svi, config.GlobalConfig.Sysctls.Svi)); !ok {
+		return details, false
+	}
+	localProxyArp := svi.Spec.ProxyArp != nil && svi.Spec.ProxyArp.LocalProxyArp
+	if err = setNeighSuppress(BrObj, !localProxyArp); err != nil {
+		log.Printf("LGM : Failed to set neigh_suppress of %s: %v\n", BrObj.Name, err)
+		return fmt.Sprintf("LGM : Failed to set neigh_suppress of %s: %v\n", BrObj.Name, err), false
+	}
+	if err = nl.LinkSetUp(ctx, vlanLink); err != nil {
 		log.Printf("LGM : Failed to set up link for %v: %s\n", vlanLink, err)
 		return fmt.Sprintf("LGM : Failed to set up link for %v: %s\n", vlanLink, err), false
 	}
-	if err = nlink.LinkSetMTU(ctx, vlanLink, ipMtu); err != nil {
+	if err = nl.LinkSetMTU(ctx, vlanLink, ipMtu); err != nil {
 		log.Printf("LGM : Failed to set MTU for %v: %s\n", vlanLink, err)
 		return fmt.Sprintf("LGM : Failed to set MTU for %v: %s\n", vlanLink, err), false
 	}
 
-	log.Printf("LGM Executed :  ip link set %s master %s up mtu %d\n", linkSvi, path.Base(svi.Spec.Vrf), ipMtu)
-	// Ignoring the error as CI env doesn't allow to write to the filesystem
-	command := fmt.Sprintf("net.ipv4.conf.%s.arp_accept=1", linkSvi)
-	CP, err1 := run([]string{"sysctl", "-w", command}, false)
-	if err1 != 0 {
-		log.Printf("LGM: Error in executing command %s %s\n", "sysctl -w net.ipv4.conf.linkSvi.arp_accept=1", linkSvi)
-		log.Printf("%s\n", CP)
-		// return  false
-	}
-	for _, ipIntf := range svi.Spec.GatewayIPs {
+	log.Printf("LGM Executed :  ip link set %s master %s up mtu %d\n", linkSvi, vrfLink, ipMtu)
+	for _, ipIntf := range svi.Spec.Addresses() {
 		addr := &netlink.Addr{
 			IPNet: &net.IPNet{
 				IP:   ipIntf.IP,
 				Mask: ipIntf.Mask,
 			},
 		}
-		if err := nlink.AddrAdd(ctx, vlanLink, addr); err != nil {
+		if err := nl.AddrAdd(ctx, vlanLink, addr); err != nil && !(adopted && errors.Is(err, unix.EEXIST)) {
 			log.Printf("LGM: Failed to add ip address %v to %v: %v\n", addr, vlanLink, err)
 			return fmt.Sprintf("LGM: Failed to add ip address %v to %v: %v\n", addr, vlanLink, err), false
 		}
 
 		log.Printf("LGM Executed :  ip address add %s dev %+v\n", addr, vlanLink)
 	}
+	// The secondary addresses removed since the svi was set up are deleted
+	if adopted {
+		pruneSviAddresses(nl, vlanLink, svi)
+	}
+	// Let the hosts learn the (possibly changed) gateway IPs and MAC
+	announceSvi(linkSvi, svi)
+	// The vpc peerings only join the vrfs of the namespace of the bridge
+	if namespace == "" {
+		syncSviPeeringRoutes(svi, true)
+	}
 	return "", true
 }
 
+// delLinkByName returns the reverting of the creation of the device
+func delLinkByName(name string) func() error {
+	return delLinkIn(nlink, name)
+}
+
+// delLinkIn returns the reverting of the creation of the device of a network namespace
+func delLinkIn(nl utils.Netlink, name string) func() error {
+	return func() error {
+		link, err := nl.LinkByName(ctx, name)
+		if err != nil {
+			return err
+		}
+		return nl.LinkDel(ctx, link)
+	}
+}
+
+// rollBack reverts the steps done by a set up which has failed, so that it can be retried from scratch
+func rollBack(undo *utils.UndoStack, name string) {
+	log.Printf("LGM: Rolling back the set up of %s\n", name)
+	if err := undo.Rollback(); err != nil {
+		log.Printf("LGM: Failed to roll back the set up of %s: %v\n", name, err)
+	}
+}
+
 // GenerateMac Generates the random mac
 func GenerateMac() net.HardwareAddr {
 	buf := make([]byte, 5)
@@ -776,7 +998,20 @@ func NetMaskToInt(mask int) (netmaskint [4]int64) {
 
 // tearDownVrf tears down the vrf
 func tearDownVrf(vrf *infradb.Vrf) (string, bool) {
-	link, err1 := nlink.LinkByName(ctx, path.Base(vrf.Name))
+	vrfLink := infradb.LinkName(vrf.Name, infradb.LinkRoleVrf)
+	brLink := infradb.LinkName(vrf.Name, infradb.LinkRoleBridge)
+	vxlanLink := infradb.LinkName(vrf.Name, infradb.LinkRoleVxlan)
+	nl, err := netnsLink(vrf.Spec.Netns)
+	if netnsGone(err) {
+		log.Printf("LGM : Links of %s are gone with network namespace %s\n", vrf.Name, vrf.Spec.Netns)
+		forgetVrfNetns(vrf.Name)
+		return "", true
+	}
+	if err != nil {
+		log.Printf("LGM: %v\n", err)
+		return fmt.Sprintf("LGM: %v\n", err), false
+	}
+	link, err1 := nl.LinkByName(ctx, vrfLink)
 	if err1 != nil {
 		log.Printf("LGM : Link %s not found %+v\n", vrf.Name, err1)
 		return fmt.Sprintf("LGM : Link %s not found %+v\n", vrf.Name, err1), true
@@ -788,43 +1023,47 @@ func tearDownVrf(vrf *infradb.Vrf) (string, bool) {
 	routingtable := *vrf.Metadata.RoutingTable[0]
 	// Delete the Linux networking artefacts in reverse order
 	if !reflect.ValueOf(vrf.Spec.Vni).IsZero() {
-		linkVxlan, linkErr := nlink.LinkByName(ctx, vxlanStr+path.Base(vrf.Name))
-		if linkErr != nil {
-			log.Printf("LGM : Link vxlan-%s not found %+v\n", vrf.Name, linkErr)
-			return fmt.Sprintf("LGM : Link vxlan-%s not found %+v\n", vrf.Name, linkErr), false
-		}
-		delerr := nlink.LinkDel(ctx, linkVxlan)
-		if delerr != nil {
-			log.Printf("LGM: Error in delete vxlan %+v\n", delerr)
-			return fmt.Sprintf("LGM: Error in delete vxlan %+v\n", delerr), false
+		// The VPCs carried over a VPN have no L3 VNI
+		if !vrf.Spec.IsVpn() {
+			linkVxlan, linkErr := nl.LinkByName(ctx, vxlanLink)
+			if linkErr != nil {
+				log.Printf("LGM : Link %s not found %+v\n", vxlanLink, linkErr)
+				return fmt.Sprintf("LGM : Link %s not found %+v\n", vxlanLink, linkErr), false
+			}
+			delerr := nl.LinkDel(ctx, linkVxlan)
+			if delerr != nil {
+				log.Printf("LGM: Error in delete vxlan %+v\n", delerr)
+				return fmt.Sprintf("LGM: Error in delete vxlan %+v\n", delerr), false
+			}
+			log.Printf("LGM : Delete %s\n", vxlanLink)
 		}
-		log.Printf("LGM : Delete vxlan-%s\n", vrf.Name)
 
-		linkBr, linkbrErr := nlink.LinkByName(ctx, brStr+path.Base(vrf.Name))
+		linkBr, linkbrErr := nl.LinkByName(ctx, brLink)
 		if linkbrErr != nil {
-			log.Printf("LGM : Link br-%s not found %+v\n", vrf.Name, linkbrErr)
-			return fmt.Sprintf("LGM : Link br-%s not found %+v\n", vrf.Name, linkbrErr), false
+			log.Printf("LGM : Link %s not found %+v\n", brLink, linkbrErr)
+			return fmt.Sprintf("LGM : Link %s not found %+v\n", brLink, linkbrErr), false
 		}
-		delerr = nlink.LinkDel(ctx, linkBr)
+		delerr := nl.LinkDel(ctx, linkBr)
 		if delerr != nil {
 			log.Printf("LGM: Error in delete br %+v\n", delerr)
 			return fmt.Sprintf("LGM: Error in delete br %+v\n", delerr), false
 		}
-		log.Printf("LGM : Delete br-%s\n", vrf.Name)
+		log.Printf("LGM : Delete %s\n", brLink)
 	}
 	routeTable := fmt.Sprintf("%+v", routingtable)
-	flusherr := nlink.RouteFlushTable(ctx, routeTable)
+	flusherr := nl.RouteFlushTable(ctx, routeTable)
 	if flusherr != nil {
 		log.Printf("LGM: Error in flush table  %+v\n", routeTable)
 		return fmt.Sprintf("LGM: Error in flush table  %+v\n", routeTable), false
 	}
 	log.Printf("LGM Executed : ip route flush table %s\n", routeTable)
-	delerr := nlink.LinkDel(ctx, link)
+	delerr := nl.LinkDel(ctx, link)
 	if delerr != nil {
 		log.Printf("LGM: Error in delete br %+v\n", delerr)
 		return fmt.Sprintf("LGM: Error in delete br %+v\n", delerr), false
 	}
 	log.Printf("LGM :link delete  %s\n", vrf.Name)
+	forgetVrfNetns(vrf.Name)
 	return "", true
 }
 
@@ -835,29 +1074,42 @@ func tearDownSvi(svi *infradb.Svi) (string, bool) {
 		log.Printf("LGM: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err)
 		return fmt.Sprintf("LGM: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err), false
 	}
-	brIntf, err := nlink.LinkByName(ctx, brTenant)
-	if err != nil {
-		log.Printf("LGM : Failed to get link information for %s: %v\n", brTenant, err)
-		return fmt.Sprintf("LGM : Failed to get link information for %s: %v\n", brTenant, err), false
-	}
 	if BrObj.Spec.VlanID > math.MaxUint16 {
 		log.Printf("LGM : VlanID %v value passed in Logical Bridge create is greater than 16 bit value\n", BrObj.Spec.VlanID)
 		return fmt.Sprintf("LGM : VlanID %v value passed in Logical Bridge create is greater than 16 bit value\n", BrObj.Spec.VlanID), false
 	}
 	vid := uint16(BrObj.Spec.VlanID)
-	if err = nlink.BridgeVlanDel(ctx, brIntf, vid, false, false, true, false); err != nil {
-		log.Printf("LGM : Failed to Del VLAN %d to bridge interface %s: %v\n", vid, brTenant, err)
-		return fmt.Sprintf("LGM : Failed to Del VLAN %d to bridge interface %s: %v\n", vid, brTenant, err), false
+	namespace := infradb.SviNetns(svi)
+	nl, err := netnsLink(namespace)
+	if err != nil && !netnsGone(err) {
+		log.Printf("LGM: %v\n", err)
+		return fmt.Sprintf("LGM: %v\n", err), false
+	}
+	if err = topology.ReleaseSvi(ctx, vid); err != nil {
+		log.Printf("LGM : Failed to Del VLAN %d to bridge interface %s: %v\n", vid, topology.BridgeName(vid), err)
+		return fmt.Sprintf("LGM : Failed to Del VLAN %d to bridge interface %s: %v\n", vid, topology.BridgeName(vid), err), false
+	}
+	log.Printf("LGM Executed : release vlan %d of bridge %s\n", vid, topology.BridgeName(vid))
+	if namespace == "" {
+		syncSviPeeringRoutes(svi, false)
+	}
+	if svi.Spec.ProxyArp != nil && svi.Spec.ProxyArp.LocalProxyArp {
+		if err = setNeighSuppress(BrObj, true); err != nil {
+			log.Printf("LGM : Failed to restore neigh_suppress of %s: %v\n", BrObj.Name, err)
+		}
+	}
+	linkSvi := infradb.SviLinkName(svi, BrObj.Spec.VlanID)
+	if nl == nil {
+		log.Printf("LGM : Link %s is gone with network namespace %s\n", linkSvi, namespace)
+		return "", true
 	}
-	log.Printf("LGM Executed : bridge vlan del dev %s vid %d self\n", brTenant, vid)
-	linkSvi := fmt.Sprintf("%+v-%+v", path.Base(svi.Spec.Vrf), BrObj.Spec.VlanID)
-	Intf, err := nlink.LinkByName(ctx, linkSvi)
+	Intf, err := nl.LinkByName(ctx, linkSvi)
 	if err != nil {
 		log.Printf("LGM : Failed to get link %s: %v\n", linkSvi, err)
 		return fmt.Sprintf("LGM : Failed to get link %s: %v\n", linkSvi, err), true
 	}
 
-	if err = nlink.LinkDel(ctx, Intf); err != nil {
+	if err = nl.LinkDel(ctx, Intf); err != nil {
 		log.Printf("LGM : Failed to delete link %s: %v\n", linkSvi, err)
 		return fmt.Sprintf("LGM : Failed to delete link %s: %v\n", linkSvi, err), false
 	}
@@ -869,35 +1121,38 @@ func tearDownSvi(svi *infradb.Svi) (string, bool) {
 // tearDownBridge tears down the bridge
 func tearDownBridge(lb *infradb.LogicalBridge) (string, bool) {
 	link := fmt.Sprintf("vxlan-%+v", lb.Spec.VlanID)
-	if !reflect.ValueOf(lb.Spec.Vni).IsZero() {
+	if lb.Spec.IsGeneve() {
+		tearDownGeneve(lb)
+	} else if !reflect.ValueOf(lb.Spec.Vni).IsZero() {
 		Intf, err := nlink.LinkByName(ctx, link)
 		if err != nil {
 			log.Printf("LGM: Failed to get link %s: %v\n", link, err)
-			return fmt.Sprintf("LGM: Failed to get link %s: %v\n", link, err), true
-		}
-		if err = nlink.LinkDel(ctx, Intf); err != nil {
-			log.Printf("LGM : Failed to delete link %s: %v\n", link, err)
-			return fmt.Sprintf("LGM: Failed to delete link %s: %v\n", link, err), false
+		} else {
+			if err = nlink.LinkDel(ctx, Intf); err != nil {
+				log.Printf("LGM : Failed to delete link %s: %v\n", link, err)
+				return fmt.Sprintf("LGM: Failed to delete link %s: %v\n", link, err), false
+			}
+			log.Printf("LGM: Executed ip link delete %s", link)
 		}
-		log.Printf("LGM: Executed ip link delete %s", link)
-
-		return "", true
+	}
+	if err := topology.DelSegment(ctx, uint16(lb.Spec.VlanID)); err != nil {
+		log.Printf("LGM : Failed to delete bridge %s: %v\n", topology.BridgeName(uint16(lb.Spec.VlanID)), err)
+		return fmt.Sprintf("LGM : Failed to delete bridge %s: %v\n", topology.BridgeName(uint16(lb.Spec.VlanID)), err), false
 	}
 	return "", true
 }
 
-// TearDownTenantBridge tears down the bridge
+// TearDownTenantBridge tears down the static bridges of the topology
 func TearDownTenantBridge() error {
-	Intf, err := nlink.LinkByName(ctx, brTenant)
-	if err != nil {
-		log.Printf("LGM: Failed to get br-tenant %s: %v\n", Intf, err)
-		return err
+	// The geneve device is only there once a logical bridge has been carried over geneve
+	if err := delLinkByName(infradb.GeneveDevice)(); err == nil {
+		log.Printf("LGM: Executed ip link delete %s", infradb.GeneveDevice)
 	}
-	if err = nlink.LinkDel(ctx, Intf); err != nil {
-		log.Printf("LGM : Failed to delete br-tenant %s: %v\n", Intf, err)
+	if err := topology.TearDown(ctx); err != nil {
+		log.Printf("LGM : Failed to tear down the tenant bridges: %v\n", err)
 		return err
 	}
-	log.Printf("LGM: Executed ip link delete %s", brTenant)
+	log.Printf("LGM: Tore down the tenant bridges")
 
 	return nil
 }
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"errors"
+	"log"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// errNotInitialized is returned when the module is asked to change a device before it is initialized
+var errNotInitialized = errors.New("linux general module is not initialized")
+
+// SetSviAdminState brings the linux device of the svi with the given name administratively up or down,
+// the gateway IPs are announced again when it comes back up so that the hosts return to the node quickly
+func SetSviAdminState(name string, up bool) error {
+	if nlink == nil {
+		return errNotInitialized
+	}
+	svi, err := infradb.GetSvi(name)
+	if err != nil {
+		return err
+	}
+	linkSvi, err := sviLinkName(svi)
+	if err != nil {
+		return err
+	}
+	link, err := nlink.LinkByName(ctx, linkSvi)
+	if err != nil {
+		return err
+	}
+	if !up {
+		if err := nlink.LinkSetDown(ctx, link); err != nil {
+			return err
+		}
+		log.Printf("LGM: Brought %s of %s administratively down\n", linkSvi, name)
+		return nil
+	}
+	if err := nlink.LinkSetUp(ctx, link); err != nil {
+		return err
+	}
+	log.Printf("LGM: Brought %s of %s administratively up\n", linkSvi, name)
+	announceSvi(linkSvi, svi)
+	return nil
+}
@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// conntrackPath is the location of the connection tracking counters
+var conntrackPath = "/proc/sys/net/netfilter"
+
+// NatCounter holds the statistics of a nat rule
+type NatCounter struct {
+	Chain   string
+	Rule    string
+	Packets uint64
+	Bytes   uint64
+}
+
+// NatStats holds the statistics of a nat gateway
+type NatStats struct {
+	Counters       []NatCounter
+	ConntrackCount uint64
+	ConntrackMax   uint64
+}
+
+// handleNatGateway handles the nat gateway functionality
+func handleNatGateway(objectData *eventbus.ObjectData) {
+	nat, err := infradb.GetNatGateway(objectData.Name)
+	handleResource(objectData, &nat.Resource, err,
+		func() (string, bool) { return setUpNatGateway(nat) },
+		func() (string, bool) { return tearDownNatGateway(nat) },
+		infradb.UpdateNatGatewayStatus)
+}
+
+// natTableName returns the nftables table used for the nat gateway
+func natTableName(nat *infradb.NatGateway) string {
+	return "opi-nat-" + path.Base(nat.Name)
+}
+
+// natSubnets returns the prefixes that are translated by the nat gateway
+func natSubnets(nat *infradb.NatGateway) ([]string, error) {
+	subnets := []string{}
+	for _, subnet := range nat.Spec.Subnets {
+		subnets = append(subnets, subnet.String())
+	}
+	if len(subnets) != 0 {
+		return subnets, nil
+	}
+	vrf, err := infradb.GetVrf(nat.Spec.Vrf)
+	if err != nil {
+		return nil, err
+	}
+	for sviName := range vrf.Svis {
+		svi, err := infradb.GetSvi(sviName)
+		if err != nil {
+			return nil, err
+		}
+		for _, gwIP := range svi.Spec.GatewayIPs {
+			if gwIP.IP.To4() == nil {
+				continue
+			}
+			subnet := &net.IPNet{IP: gwIP.IP.Mask(gwIP.Mask), Mask: gwIP.Mask}
+			subnets = append(subnets, subnet.String())
+		}
+	}
+	sort.Strings(subnets)
+	return subnets, nil
+}
+
+// natRuleset renders the nftables ruleset of the nat gateway
+func natRuleset(nat *infradb.NatGateway, subnets []string) string {
+	table := natTableName(nat)
+	var b strings.Builder
+	// Declaring the table first makes the delete succeed when the table is not there yet
+	fmt.Fprintf(&b, "table ip %s {}\n", table)
+	fmt.Fprintf(&b, "delete table ip %s\n", table)
+	fmt.Fprintf(&b, "table ip %s {\n", table)
+	fmt.Fprintf(&b, "\tchain postrouting {\n\t\ttype nat hook postrouting priority srcnat; policy accept;\n")
+	if len(subnets) != 0 {
+		var ports string
+		if nat.Spec.PortRange != nil {
+			ports = fmt.Sprintf("%d-%d", nat.Spec.PortRange.Min, nat.Spec.PortRange.Max)
+		}
+		var action string
+		switch {
+		case nat.Spec.SnatIP != nil && ports != "":
+			action = fmt.Sprintf("snat to %s:%s", nat.Spec.SnatIP, ports)
+		case nat.Spec.SnatIP != nil:
+			action = fmt.Sprintf("snat to %s", nat.Spec.SnatIP)
+		case ports != "":
+			action = "masquerade to :" + ports
+		default:
+			action = "masquerade"
+		}
+		fmt.Fprintf(&b, "\t\toifname \"%s\" ip saddr { %s } counter %s\n", nat.Spec.ExternalInterface, strings.Join(subnets, ", "), action)
+	}
+	fmt.Fprintf(&b, "\t}\n")
+	fmt.Fprintf(&b, "\tchain prerouting {\n\t\ttype nat hook prerouting priority dstnat; policy accept;\n")
+	for _, rule := range nat.Spec.DnatRules {
+		fmt.Fprintf(&b, "\t\tiifname \"%s\" %s dport %d counter dnat to %s:%d\n",
+			nat.Spec.ExternalInterface, rule.Protocol, rule.ExternalPort, rule.InternalIP, rule.InternalPort)
+	}
+	fmt.Fprintf(&b, "\t}\n}\n")
+	return b.String()
+}
+
+// applyNftables loads the ruleset atomically into the kernel
+func applyNftables(ruleset string) (string, bool) {
+	f, err := os.CreateTemp("", "opi-nat-*.nft")
+	if err != nil {
+		return fmt.Sprintf("LGM: Failed to create nftables file: %v\n", err), false
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(ruleset); err != nil {
+		f.Close()
+		return fmt.Sprintf("LGM: Failed to write nftables file: %v\n", err), false
+	}
+	f.Close()
+	CP, err1 := run([]string{"nft", "-f", f.Name()}, false)
+	if err1 != 0 {
+		return fmt.Sprintf("LGM: Failed to load nftables ruleset: %s\n", CP), false
+	}
+	return "", true
+}
+
+// setUpNatGateway sets up the nat gateway
+func setUpNatGateway(nat *infradb.NatGateway) (string, bool) {
+	subnets, err := natSubnets(nat)
+	if err != nil {
+		log.Printf("LGM: Failed to resolve subnets of nat gateway %s: %v\n", nat.Name, err)
+		return fmt.Sprintf("LGM: Failed to resolve subnets of nat gateway %s: %v\n", nat.Name, err), false
+	}
+	// Example: nft -f <ruleset of table ip opi-nat-<id>>
+	if details, ok := applyNftables(natRuleset(nat, subnets)); !ok {
+		log.Print(details)
+		return details, false
+	}
+	log.Printf("LGM Executed : nft -f <table ip %s>\n", natTableName(nat))
+	return "", true
+}
+
+// tearDownNatGateway tears down the nat gateway
+func tearDownNatGateway(nat *infradb.NatGateway) (string, bool) {
+	table := natTableName(nat)
+	// Example: nft delete table ip opi-nat-<id>
+	if details, ok := applyNftables(fmt.Sprintf("table ip %s {}\ndelete table ip %s\n", table, table)); !ok {
+		log.Print(details)
+		return details, false
+	}
+	log.Printf("LGM Executed : nft delete table ip %s\n", table)
+	return "", true
+}
+
+// readConntrack reads one of the connection tracking counters
+func readConntrack(name string) uint64 {
+	data, err := os.ReadFile(path.Join(conntrackPath, name))
+	if err != nil {
+		return 0
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// nftListing is the part of the json output of nft that holds the rule counters
+type nftListing struct {
+	Nftables []struct {
+		Rule *struct {
+			Chain   string                       `json:"chain"`
+			Comment string                       `json:"comment"`
+			Expr    []map[string]json.RawMessage `json:"expr"`
+		} `json:"rule,omitempty"`
+	} `json:"nftables"`
+}
+
+// parseNatCounters extracts the rule counters from the json output of nft
+func parseNatCounters(data []byte) ([]NatCounter, error) {
+	listing := nftListing{}
+	if err := json.Unmarshal(data, &listing); err != nil {
+		return nil, err
+	}
+	counters := []NatCounter{}
+	for _, obj := range listing.Nftables {
+		if obj.Rule == nil {
+			continue
+		}
+		counter := NatCounter{Chain: obj.Rule.Chain}
+		var found bool
+		var action []string
+		for _, expr := range obj.Rule.Expr {
+			for key, value := range expr {
+				switch key {
+				case "counter":
+					c := struct {
+						Packets uint64 `json:"packets"`
+						Bytes   uint64 `json:"bytes"`
+					}{}
+					if err := json.Unmarshal(value, &c); err != nil {
+						return nil, err
+					}
+					counter.Packets, counter.Bytes, found = c.Packets, c.Bytes, true
+				case "snat", "dnat", "masquerade":
+					action = append(action, key)
+				}
+			}
+		}
+		if found {
+			counter.Rule = strings.Join(action, ",")
+			counters = append(counters, counter)
+		}
+	}
+	return counters, nil
+}
+
+// GetNatGatewayStats returns the rule counters and the connection tracking statistics of the nat gateway
+func GetNatGatewayStats(name string) (*NatStats, error) {
+	nat, err := infradb.GetNatGateway(name)
+	if err != nil {
+		return nil, err
+	}
+	if nat.Status.OperStatus != infradb.OperStatusUp {
+		return nil, fmt.Errorf("nat gateway %s is not operationally up", name)
+	}
+	out, err := exec.Command("nft", "-j", "list", "table", "ip", natTableName(nat)).Output() //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nftables table %s: %v", natTableName(nat), err)
+	}
+	counters, err := parseNatCounters(out)
+	if err != nil {
+		return nil, err
+	}
+	return &NatStats{
+		Counters:       counters,
+		ConntrackCount: readConntrack("nf_conntrack_count"),
+		ConntrackMax:   readConntrack("nf_conntrack_max"),
+	}, nil
+}
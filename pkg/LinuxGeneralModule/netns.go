@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// netnsHandle is the netlink wrapper of a named network namespace
+type netnsHandle struct {
+	id    string
+	nlink utils.Netlink
+}
+
+var (
+	netnsLock    sync.Mutex
+	netnsHandles = map[string]netnsHandle{}
+	// vrfNetns holds the namespace each vrf has been set up in, to tear it down there when it moves
+	vrfNetns = map[string]string{}
+)
+
+// netnsLink returns the netlink wrapper of the named network namespace, the one of the bridge
+// when the name is empty. The wrapper is opened again when the namespace has been created again.
+func netnsLink(namespace string) (utils.Netlink, error) {
+	if namespace == "" {
+		return nlink, nil
+	}
+	id, err := utils.NetnsID(namespace)
+	if err != nil {
+		return nil, err
+	}
+	netnsLock.Lock()
+	defer netnsLock.Unlock()
+	if h, ok := netnsHandles[namespace]; ok && h.id == id {
+		return h.nlink, nil
+	}
+	w, err := utils.NewNetlinkWrapperAt(namespace, false)
+	if err != nil {
+		return nil, err
+	}
+	h := netnsHandle{id: id, nlink: utils.WithWriteQueue(utils.WithNetlinkFaults(w), namespace)}
+	netnsHandles[namespace] = h
+	log.Printf("LGM: Opened the netlink handle of network namespace %s\n", namespace)
+	return h.nlink, nil
+}
+
+// netnsGone tells whether the named network namespace has been deleted, the devices in it with it
+func netnsGone(err error) bool {
+	return errors.Is(err, os.ErrNotExist)
+}
+
+// moveToNetns moves the device just created in the namespace of the bridge to the named namespace
+// and returns it there, the device is deleted when it cannot move
+func moveToNetns(link netlink.Link, namespace string, nl utils.Netlink) (netlink.Link, error) {
+	name := link.Attrs().Name
+	if namespace == "" {
+		return link, nil
+	}
+	// Example: ip link set <link> netns <namespace>
+	if err := utils.MoveLinkToNetns(ctx, nlink, link, namespace); err != nil {
+		if delErr := nlink.LinkDel(ctx, link); delErr != nil {
+			log.Printf("LGM: Failed to delete link %s: %v\n", name, delErr)
+		}
+		return nil, fmt.Errorf("failed to move link %s to network namespace %s: %w", name, namespace, err)
+	}
+	log.Printf("LGM Executed : ip link set %s netns %s\n", name, namespace)
+	return nl.LinkByName(ctx, name)
+}
+
+// setVrfNetns records the namespace the vrf has been set up in
+func setVrfNetns(name, namespace string) {
+	netnsLock.Lock()
+	defer netnsLock.Unlock()
+	vrfNetns[name] = namespace
+}
+
+// forgetVrfNetns forgets the namespace of the vrf which has been torn down
+func forgetVrfNetns(name string) {
+	netnsLock.Lock()
+	defer netnsLock.Unlock()
+	delete(vrfNetns, name)
+}
+
+// getVrfNetns returns the namespace the vrf has been set up in, false when it is not known
+func getVrfNetns(name string) (string, bool) {
+	netnsLock.Lock()
+	defer netnsLock.Unlock()
+	namespace, ok := vrfNetns[name]
+	return namespace, ok
+}
+
+// netnsCmd returns the command run in the named network namespace
+func netnsCmd(namespace string, cmd []string) []string {
+	if namespace == "" {
+		return cmd
+	}
+	// Example: ip netns exec <namespace> <cmd>
+	return append([]string{"ip", "netns", "exec", namespace}, cmd...)
+}
@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// Comments of the nftables rules of a port security, they tell the violation counters apart
+const (
+	psecMacRule = "mac-violation"
+	psecIPRule  = "ip-violation"
+)
+
+// PortSecurityStats holds the violation counters of a port security
+type PortSecurityStats struct {
+	// MacViolations counts the frames dropped for a source MAC address which is not allowed
+	MacViolations uint64
+	// IPViolations counts the packets dropped for a source IP address which is not allowed with their MAC address
+	IPViolations uint64
+	// LearnedMacs is the number of MAC addresses learned on the port, bounded by the MAC limit
+	LearnedMacs uint64
+}
+
+// handlePortSecurity handles the port security functionality
+func handlePortSecurity(objectData *eventbus.ObjectData) {
+	psec, err := infradb.GetPortSecurity(objectData.Name)
+	handleResource(objectData, &psec.Resource, err,
+		func() (string, bool) { return setUpPortSecurity(psec) },
+		func() (string, bool) { return tearDownPortSecurity(psec) },
+		infradb.UpdatePortSecurityStatus)
+}
+
+// psecTableName returns the nftables table used for the port security
+func psecTableName(psec *infradb.PortSecurity) string {
+	return "opi-psec-" + path.Base(psec.Name)
+}
+
+// psecLinkName returns the linux device of the bridge port of the port security
+func psecLinkName(psec *infradb.PortSecurity) string {
+	return path.Base(psec.Spec.BridgePort)
+}
+
+// psecRuleset renders the nftables ruleset of the port security. The frames of the port with a source
+// MAC address which is not allowed are dropped, then the IP packets and ARP messages whose source IP
+// address does not go with their MAC address. The addresses used before an IP address is configured,
+// e.g. by DHCP, duplicate address detection or neighbor discovery, are let through.
+func psecRuleset(psec *infradb.PortSecurity) string {
+	table := psecTableName(psec)
+	link := psecLinkName(psec)
+	var macs, ipv4, ipv6 []string
+	seen := map[string]bool{}
+	for _, addr := range psec.Spec.AllowedAddresses {
+		if !seen[addr.MAC.String()] {
+			seen[addr.MAC.String()] = true
+			macs = append(macs, addr.MAC.String())
+		}
+		switch {
+		case addr.IP == nil:
+		case addr.IP.To4() != nil:
+			ipv4 = append(ipv4, addr.MAC.String()+" . "+addr.IP.String())
+		default:
+			ipv6 = append(ipv6, addr.MAC.String()+" . "+addr.IP.String())
+		}
+	}
+
+	var b strings.Builder
+	// Declaring the table first makes the delete succeed when the table is not there yet
+	fmt.Fprintf(&b, "table bridge %s {}\n", table)
+	fmt.Fprintf(&b, "delete table bridge %s\n", table)
+	fmt.Fprintf(&b, "table bridge %s {\n", table)
+	fmt.Fprintf(&b, "\tchain prerouting {\n\t\ttype filter hook prerouting priority filter; policy accept;\n")
+	if len(macs) != 0 {
+		fmt.Fprintf(&b, "\t\tiifname \"%s\" ether saddr != { %s } counter drop comment \"%s\"\n",
+			link, strings.Join(macs, ", "), psecMacRule)
+	}
+	if len(ipv4) != 0 {
+		fmt.Fprintf(&b, "\t\tiifname \"%s\" ether type ip ip saddr != 0.0.0.0 ether saddr . ip saddr != { %s } counter drop comment \"%s\"\n",
+			link, strings.Join(ipv4, ", "), psecIPRule)
+		fmt.Fprintf(&b, "\t\tiifname \"%s\" ether type arp arp saddr ip != 0.0.0.0 arp saddr ether . arp saddr ip != { %s } counter drop comment \"%s\"\n",
+			link, strings.Join(ipv4, ", "), psecIPRule)
+	}
+	if len(ipv6) != 0 {
+		fmt.Fprintf(&b, "\t\tiifname \"%s\" ether type ip6 ip6 saddr != { ::, fe80::/10 } ether saddr . ip6 saddr != { %s } counter drop comment \"%s\"\n",
+			link, strings.Join(ipv6, ", "), psecIPRule)
+	}
+	fmt.Fprintf(&b, "\t}\n}\n")
+	return b.String()
+}
+
+// setMacLimit bounds the number of MAC addresses learned on the device, zero is unlimited
+func setMacLimit(link string, limit uint32) (string, bool) {
+	// Example: bridge link set dev eth2 fdb_max_learned 16
+	CP, err := run([]string{"bridge", "link", "set", "dev", link, "fdb_max_learned", strconv.FormatUint(uint64(limit), 10)}, false)
+	if err != 0 {
+		return fmt.Sprintf("LGM: Failed to set the MAC limit of %s: %s\n", link, CP), false
+	}
+	return "", true
+}
+
+// setUpPortSecurity sets up the port security
+func setUpPortSecurity(psec *infradb.PortSecurity) (string, bool) {
+	link := psecLinkName(psec)
+	if details, ok := setMacLimit(link, psec.Spec.MacLimit); !ok {
+		log.Print(details)
+		return details, false
+	}
+	log.Printf("LGM Executed : bridge link set dev %s fdb_max_learned %d\n", link, psec.Spec.MacLimit)
+	// Example: nft -f <ruleset of table bridge opi-psec-<id>>
+	if details, ok := applyNftables(psecRuleset(psec)); !ok {
+		log.Print(details)
+		return details, false
+	}
+	log.Printf("LGM Executed : nft -f <table bridge %s>\n", psecTableName(psec))
+	return "", true
+}
+
+// tearDownPortSecurity tears down the port security, the device may be gone with its bridge port
+func tearDownPortSecurity(psec *infradb.PortSecurity) (string, bool) {
+	link := psecLinkName(psec)
+	if _, err := nlink.LinkByName(ctx, link); err == nil {
+		if details, ok := setMacLimit(link, 0); !ok {
+			log.Print(details)
+			return details, false
+		}
+		log.Printf("LGM Executed : bridge link set dev %s fdb_max_learned 0\n", link)
+	}
+	table := psecTableName(psec)
+	// Example: nft delete table bridge opi-psec-<id>
+	if details, ok := applyNftables(fmt.Sprintf("table bridge %s {}\ndelete table bridge %s\n", table, table)); !ok {
+		log.Print(details)
+		return details, false
+	}
+	log.Printf("LGM Executed : nft delete table bridge %s\n", table)
+	return "", true
+}
+
+// parseViolationCounters sums the packet counters of the rules of the json output of nft by their comment
+func parseViolationCounters(data []byte) (map[string]uint64, error) {
+	listing := nftListing{}
+	if err := json.Unmarshal(data, &listing); err != nil {
+		return nil, err
+	}
+	counters := map[string]uint64{}
+	for _, obj := range listing.Nftables {
+		if obj.Rule == nil || obj.Rule.Comment == "" {
+			continue
+		}
+		for _, expr := range obj.Rule.Expr {
+			value, ok := expr["counter"]
+			if !ok {
+				continue
+			}
+			c := struct {
+				Packets uint64 `json:"packets"`
+			}{}
+			if err := json.Unmarshal(value, &c); err != nil {
+				return nil, err
+			}
+			counters[obj.Rule.Comment] += c.Packets
+		}
+	}
+	return counters, nil
+}
+
+// learnedMacs reads the number of MAC addresses learned on the bridge port device
+func learnedMacs(link string) (uint64, error) {
+	out, err := exec.Command("ip", "-d", "-j", "link", "show", "dev", link).Output() //nolint:gosec
+	if err != nil {
+		return 0, fmt.Errorf("failed to show the device %s: %v", link, err)
+	}
+	links := []struct {
+		LinkInfo struct {
+			SlaveData struct {
+				FdbLearned uint64 `json:"fdb_n_learned"`
+			} `json:"info_slave_data"`
+		} `json:"linkinfo"`
+	}{}
+	if err := json.Unmarshal(out, &links); err != nil {
+		return 0, err
+	}
+	if len(links) == 0 {
+		return 0, fmt.Errorf("device %s not found", link)
+	}
+	return links[0].LinkInfo.SlaveData.FdbLearned, nil
+}
+
+// GetPortSecurityStats returns the violation counters of the port security
+func GetPortSecurityStats(name string) (*PortSecurityStats, error) {
+	psec, err := infradb.GetPortSecurity(name)
+	if err != nil {
+		return nil, err
+	}
+	if psec.Status.OperStatus != infradb.OperStatusUp {
+		return nil, fmt.Errorf("port security %s is not operationally up", name)
+	}
+	out, err := exec.Command("nft", "-j", "list", "table", "bridge", psecTableName(psec)).Output() //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nftables table %s: %v", psecTableName(psec), err)
+	}
+	counters, err := parseViolationCounters(out)
+	if err != nil {
+		return nil, err
+	}
+	learned, err := learnedMacs(psecLinkName(psec))
+	if err != nil {
+		return nil, err
+	}
+	return &PortSecurityStats{
+		MacViolations: counters[psecMacRule],
+		IPViolations:  counters[psecIPRule],
+		LearnedMacs:   learned,
+	}, nil
+}
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vishvananda/netlink"
+)
+
+// probeDevice is the name of the dummy device created by the deep health check
+const probeDevice = "opi-probe0"
+
+// DeepProbe checks that devices can be created and deleted in the dataplane
+func DeepProbe(ctx context.Context) error {
+	if nlink == nil {
+		return errors.New("LGM is not initialized")
+	}
+	probe := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: probeDevice}}
+	// Remove the leftover of an interrupted probe
+	if link, err := nlink.LinkByName(ctx, probeDevice); err == nil {
+		if err := nlink.LinkDel(ctx, link); err != nil {
+			return fmt.Errorf("failed to delete %s: %v", probeDevice, err)
+		}
+	}
+	if err := nlink.LinkAdd(ctx, probe); err != nil {
+		return fmt.Errorf("failed to create %s: %v", probeDevice, err)
+	}
+	if err := nlink.LinkDel(ctx, probe); err != nil {
+		return fmt.Errorf("failed to delete %s: %v", probeDevice, err)
+	}
+	return nil
+}
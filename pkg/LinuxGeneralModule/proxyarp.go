@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// sviSysctls returns the configured settings of the svis followed by the proxy ARP of the svi, which
+// overrides them
+func sviSysctls(svi *infradb.Svi, configured []string) []string {
+	sysctls := append([]string{}, configured...)
+	proxyArp := svi.Spec.ProxyArp
+	if proxyArp == nil {
+		return sysctls
+	}
+	sysctls = append(sysctls,
+		fmt.Sprintf("ipv4.proxy_arp=%d", boolToInt(proxyArp.ProxyArp)),
+		fmt.Sprintf("ipv4.proxy_arp_pvlan=%d", boolToInt(proxyArp.LocalProxyArp)),
+	)
+	// The traffic within the subnet goes back out of the svi, the hosts must not be redirected to each other
+	if proxyArp.LocalProxyArp {
+		sysctls = append(sysctls, "ipv4.send_redirects=0")
+	}
+	return sysctls
+}
+
+// boolToInt renders a flag as a sysctl value
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// sviHasLocalProxyArp tells whether the svi of the logical bridge answers the ARP requests within its subnet
+func sviHasLocalProxyArp(lb *infradb.LogicalBridge) bool {
+	if lb.Svi == "" {
+		return false
+	}
+	svi, err := infradb.GetSvi(lb.Svi)
+	if err != nil {
+		return false
+	}
+	return svi.Spec.ProxyArp != nil && svi.Spec.ProxyArp.LocalProxyArp
+}
+
+// setNeighSuppress sets the ARP suppression of the vxlan device of the logical bridge, the EVPN answers the
+// ARP requests of the remote hosts in place of the svi otherwise
+func setNeighSuppress(lb *infradb.LogicalBridge, suppress bool) error {
+	if reflect.ValueOf(lb.Spec.Vni).IsZero() || lb.Spec.IsGeneve() {
+		return nil
+	}
+	link := fmt.Sprintf("vxlan-%+v", lb.Spec.VlanID)
+	vxlan, err := nlink.LinkByName(ctx, link)
+	if err != nil {
+		return err
+	}
+	if err := nlink.LinkSetBrNeighSuppress(ctx, vxlan, suppress); err != nil {
+		return err
+	}
+	log.Printf("LGM Executed : bridge link set dev %s neigh_suppress %t\n", link, suppress)
+	return nil
+}
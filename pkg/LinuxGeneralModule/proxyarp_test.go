@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_SviSysctls(t *testing.T) {
+	configured := []string{"ipv4.proxy_arp=1", "ipv4.arp_notify=1"}
+	svi := &infradb.Svi{Spec: &infradb.SviSpec{}}
+	if sysctls := sviSysctls(svi, configured); !reflect.DeepEqual(sysctls, configured) {
+		t.Errorf("expected the configured settings, received %v", sysctls)
+	}
+
+	svi.Spec.ProxyArp = &infradb.ProxyArpSpec{LocalProxyArp: true}
+	sysctls := deviceSysctls(defaultSviSysctls, sviSysctls(svi, configured))
+	expected := map[string]string{
+		"ipv4.arp_accept":      "1",
+		"ipv4.rp_filter":       "0",
+		"ipv6.accept_dad":      "0",
+		"ipv4.arp_notify":      "1",
+		"ipv4.proxy_arp":       "0",
+		"ipv4.proxy_arp_pvlan": "1",
+		"ipv4.send_redirects":  "0",
+	}
+	if !reflect.DeepEqual(sysctls, expected) {
+		t.Errorf("expected %v, received %v", expected, sysctls)
+	}
+	if len(configured) != 2 {
+		t.Error("expected the configured settings to be left alone")
+	}
+}
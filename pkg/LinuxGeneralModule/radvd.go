@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// handleRouterAdvertisement handles the router advertisement functionality
+func handleRouterAdvertisement(objectData *eventbus.ObjectData) {
+	ra, err := infradb.GetRouterAdvertisement(objectData.Name)
+	handleResource(objectData, &ra.Resource, err,
+		func() (string, bool) { return setUpRouterAdvertisement(ra) },
+		func() (string, bool) { return tearDownRouterAdvertisement(ra) },
+		infradb.UpdateRouterAdvertisementStatus)
+}
+
+// raConfPath returns the radvd configuration file of the router advertisement
+func raConfPath(ra *infradb.RouterAdvertisement) string {
+	return path.Join(dnsRunDir, "radvd-"+path.Base(ra.Name)+".conf")
+}
+
+// raPidPath returns the radvd pid file of the router advertisement
+func raPidPath(ra *infradb.RouterAdvertisement) string {
+	return path.Join(dnsRunDir, "radvd-"+path.Base(ra.Name)+".pid")
+}
+
+// raPrefixes returns the prefixes of the router advertisement, the IPv6 subnets of the svi by default
+func raPrefixes(ra *infradb.RouterAdvertisement, svi *infradb.Svi) []*net.IPNet {
+	if len(ra.Spec.Prefixes) != 0 {
+		return ra.Spec.Prefixes
+	}
+	prefixes := []*net.IPNet{}
+	for _, gwIP := range svi.Spec.GatewayIPs {
+		if gwIP.IP.To4() == nil {
+			prefixes = append(prefixes, &net.IPNet{IP: gwIP.IP.Mask(gwIP.Mask), Mask: gwIP.Mask})
+		}
+	}
+	return prefixes
+}
+
+// onOff renders a radvd flag
+func onOff(flag bool) string {
+	if flag {
+		return "on"
+	}
+	return "off"
+}
+
+// raConfig renders the radvd configuration of the router advertisement on the svi
+func raConfig(ra *infradb.RouterAdvertisement, linkSvi string, prefixes []*net.IPNet, mtu uint32) string {
+	spec := ra.Spec
+	var b strings.Builder
+	fmt.Fprintf(&b, "interface %s\n{\n", linkSvi)
+	fmt.Fprintf(&b, "\tAdvSendAdvert on;\n")
+	fmt.Fprintf(&b, "\tMaxRtrAdvInterval %d;\n", spec.Interval)
+	fmt.Fprintf(&b, "\tAdvManagedFlag %s;\n", onOff(spec.Managed))
+	fmt.Fprintf(&b, "\tAdvOtherConfigFlag %s;\n", onOff(spec.Other))
+	fmt.Fprintf(&b, "\tAdvLinkMTU %d;\n", mtu)
+	for _, prefix := range prefixes {
+		// The hosts of a managed subnet get their addresses from DHCPv6 rather than by SLAAC
+		fmt.Fprintf(&b, "\tprefix %s\n\t{\n\t\tAdvOnLink on;\n\t\tAdvAutonomous %s;\n\t};\n", prefix, onOff(!spec.Managed))
+	}
+	if len(spec.Rdnss) != 0 {
+		servers := make([]string, 0, len(spec.Rdnss))
+		for _, ip := range spec.Rdnss {
+			servers = append(servers, ip.String())
+		}
+		fmt.Fprintf(&b, "\tRDNSS %s\n\t{\n\t};\n", strings.Join(servers, " "))
+	}
+	fmt.Fprintf(&b, "};\n")
+	return b.String()
+}
+
+// setUpRouterAdvertisement starts the radvd instance advertising the svi inside its VRF
+func setUpRouterAdvertisement(ra *infradb.RouterAdvertisement) (string, bool) {
+	svi, err := infradb.GetSvi(ra.Spec.Svi)
+	if err != nil {
+		log.Printf("LGM: Failed to get the SVI of router advertisement %s: %v\n", ra.Name, err)
+		return fmt.Sprintf("LGM: Failed to get the SVI of router advertisement %s: %v\n", ra.Name, err), false
+	}
+	linkSvi, err := sviLinkName(svi)
+	if err != nil {
+		log.Printf("LGM: Failed to resolve the SVI device of router advertisement %s: %v\n", ra.Name, err)
+		return fmt.Sprintf("LGM: Failed to resolve the SVI device of router advertisement %s: %v\n", ra.Name, err), false
+	}
+	mtu := ra.Spec.Mtu
+	if mtu == 0 {
+		mtu = uint32(ipMtu)
+	}
+	if err := os.MkdirAll(dnsRunDir, 0o755); err != nil {
+		log.Printf("LGM: Failed to create %s: %v\n", dnsRunDir, err)
+		return fmt.Sprintf("LGM: Failed to create %s: %v\n", dnsRunDir, err), false
+	}
+	if err := stopDaemon(raPidPath(ra)); err != nil {
+		log.Printf("LGM: Failed to stop router advertisement %s: %v\n", ra.Name, err)
+		return fmt.Sprintf("LGM: Failed to stop router advertisement %s: %v\n", ra.Name, err), false
+	}
+	conf := raConfig(ra, linkSvi, raPrefixes(ra, svi), mtu)
+	if err := os.WriteFile(raConfPath(ra), []byte(conf), 0o600); err != nil {
+		log.Printf("LGM: Failed to write radvd configuration of %s: %v\n", ra.Name, err)
+		return fmt.Sprintf("LGM: Failed to write radvd configuration of %s: %v\n", ra.Name, err), false
+	}
+	cmd := []string{"radvd", "--config=" + raConfPath(ra), "--pidfile=" + raPidPath(ra)}
+	if path.Base(svi.Spec.Vrf) != "GRD" {
+		vrfName := infradb.LinkName(svi.Spec.Vrf, infradb.LinkRoleVrf)
+		cmd = append([]string{"ip", "vrf", "exec", vrfName}, cmd...)
+	}
+	// Example: ip vrf exec <vrf> radvd --config=/run/opi-evpn/radvd-<id>.conf --pidfile=/run/opi-evpn/radvd-<id>.pid
+	CP, err1 := run(cmd, false)
+	if err1 != 0 {
+		log.Printf("LGM: Failed to start radvd for router advertisement %s: %s\n", ra.Name, CP)
+		return fmt.Sprintf("LGM: Failed to start radvd for router advertisement %s: %s\n", ra.Name, CP), false
+	}
+	log.Printf("LGM Executed : %s\n", strings.Join(cmd, " "))
+	return "", true
+}
+
+// tearDownRouterAdvertisement stops the radvd instance of the router advertisement
+func tearDownRouterAdvertisement(ra *infradb.RouterAdvertisement) (string, bool) {
+	if err := stopDaemon(raPidPath(ra)); err != nil {
+		log.Printf("LGM: Failed to stop router advertisement %s: %v\n", ra.Name, err)
+		return fmt.Sprintf("LGM: Failed to stop router advertisement %s: %v\n", ra.Name, err), false
+	}
+	if err := os.Remove(raConfPath(ra)); err != nil && !os.IsNotExist(err) {
+		log.Printf("LGM: Failed to remove radvd configuration of %s: %v\n", ra.Name, err)
+		return fmt.Sprintf("LGM: Failed to remove radvd configuration of %s: %v\n", ra.Name, err), false
+	}
+	log.Printf("LGM Executed : kill radvd of router advertisement %s\n", ra.Name)
+	return "", true
+}
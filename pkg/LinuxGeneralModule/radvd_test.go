@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_RaConfig(t *testing.T) {
+	_, gw6, _ := net.ParseCIDR("2001:db8::1/64")
+	gw6.IP = net.ParseIP("2001:db8::1")
+	_, gw4, _ := net.ParseCIDR("10.0.0.1/24")
+	svi := &infradb.Svi{Spec: &infradb.SviSpec{GatewayIPs: []*net.IPNet{gw4, gw6}}}
+	ra := &infradb.RouterAdvertisement{
+		Resource: infradb.Resource{Name: "//network.opiproject.org/routeradvertisements/web"},
+		Spec: &infradb.RouterAdvertisementSpec{
+			Svi: "//network.opiproject.org/svis/web", Managed: true, Other: true, Interval: 600,
+			Rdnss: []net.IP{net.ParseIP("2001:db8::53"), net.ParseIP("2001:db8:1::53")},
+		},
+	}
+	prefixes := raPrefixes(ra, svi)
+	if len(prefixes) != 1 || prefixes[0].String() != "2001:db8::/64" {
+		t.Fatalf("expected the IPv6 subnet of the svi to be advertised, received %v", prefixes)
+	}
+	conf := raConfig(ra, "blue-10", prefixes, 1500)
+	for _, expected := range []string{
+		"interface blue-10\n{\n",
+		"\tMaxRtrAdvInterval 600;\n",
+		"\tAdvManagedFlag on;\n",
+		"\tAdvOtherConfigFlag on;\n",
+		"\tAdvLinkMTU 1500;\n",
+		"\tprefix 2001:db8::/64\n\t{\n\t\tAdvOnLink on;\n\t\tAdvAutonomous off;\n\t};\n",
+		"\tRDNSS 2001:db8::53 2001:db8:1::53\n",
+	} {
+		if !strings.Contains(conf, expected) {
+			t.Errorf("expected %q in the configuration:\n%s", expected, conf)
+		}
+	}
+
+	ra.Spec.Managed = false
+	if conf := raConfig(ra, "blue-10", prefixes, 1500); !strings.Contains(conf, "AdvAutonomous on;") {
+		t.Errorf("expected the prefixes to be autonomous without DHCPv6:\n%s", conf)
+	}
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// updateStatusFn reports the status of the component for a resource
+type updateStatusFn func(name string, resourceVersion string, notificationID string, component common.Component) error
+
+// handleResource handles the events of the resources which are not part of the opi-api.
+// The resource has been fetched by the caller, setUp and tearDown realize it and
+// updateStatus reports the outcome back to the infradb.
+func handleResource(objectData *eventbus.ObjectData, res *infradb.Resource, getErr error, setUp, tearDown func() (string, bool), updateStatus updateStatusFn) {
+	var comp common.Component
+	comp.Name = lgmComp
+	if getErr != nil || objectData.ResourceVersion != res.ResourceVersion {
+		comp.CompStatus = common.ComponentStatusError
+		if getErr != nil {
+			comp.Details = fmt.Sprintf("LGM: Get %s error: %s %s\n", objectData.Name, getErr, objectData.Name)
+		} else {
+			comp.Details = fmt.Sprintf("LGM: Mismatch in resoruce version %+v\n and resource version %+v\n", objectData.ResourceVersion, res.ResourceVersion)
+		}
+		log.Print(comp.Details)
+		comp.Timer = 2 * time.Second
+		if err := updateStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, comp); err != nil {
+			log.Printf("error in updating %s status: %s\n", objectData.Name, err)
+		}
+		return
+	}
+	for i := 0; i < len(res.Status.Components); i++ {
+		if res.Status.Components[i].Name == lgmComp {
+			comp = res.Status.Components[i]
+		}
+	}
+	var details string
+	var status bool
+	if res.Status.OperStatus != infradb.OperStatusToBeDeleted {
+		details, status = setUp()
+	} else {
+		details, status = tearDown()
+	}
+	comp.Name = lgmComp
+	comp.Details = details
+	if status {
+		comp.CompStatus = common.ComponentStatusSuccess
+		comp.Timer = 0
+	} else {
+		if comp.Timer == 0 {
+			comp.Timer = 2 * time.Second
+		} else {
+			comp.Timer *= 2
+		}
+		comp.CompStatus = common.ComponentStatusError
+	}
+	log.Printf("LGM: %+v \n", comp)
+	if err := updateStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, comp); err != nil {
+		log.Printf("error in updating %s status: %s\n", objectData.Name, err)
+	}
+}
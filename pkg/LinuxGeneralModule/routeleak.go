@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"fmt"
+	"log"
+	"path"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/vishvananda/netlink"
+)
+
+// handleRouteLeak handles the route leak functionality
+func handleRouteLeak(objectData *eventbus.ObjectData) {
+	rl, err := infradb.GetRouteLeak(objectData.Name)
+	handleResource(objectData, &rl.Resource, err,
+		func() (string, bool) { return setUpRouteLeak(rl) },
+		func() (string, bool) { return tearDownRouteLeak(rl) },
+		infradb.UpdateRouteLeakStatus)
+}
+
+// routeLeakRoutes builds the kernel routes which leak the prefixes of the source vrf
+// into the routing table of the destination vrf
+func routeLeakRoutes(rl *infradb.RouteLeak) ([]*netlink.Route, error) {
+	// Leaking out of the GRD is realized only through FRR as there is no vrf device to point to
+	if path.Base(rl.Spec.SrcVrf) == "GRD" {
+		return nil, nil
+	}
+	dstVrf, err := infradb.GetVrf(rl.Spec.DstVrf)
+	if err != nil {
+		return nil, err
+	}
+	if dstVrf.Metadata == nil || len(dstVrf.Metadata.RoutingTable) == 0 || dstVrf.Metadata.RoutingTable[0] == nil {
+		return nil, fmt.Errorf("routing table of vrf %s is not yet known", dstVrf.Name)
+	}
+	srcLink, err := nlink.LinkByName(ctx, infradb.LinkName(rl.Spec.SrcVrf, infradb.LinkRoleVrf))
+	if err != nil {
+		return nil, err
+	}
+	routes := []*netlink.Route{}
+	for _, prefix := range rl.Spec.Prefixes {
+		routes = append(routes, &netlink.Route{
+			Dst:       prefix,
+			LinkIndex: srcLink.Attrs().Index,
+			Table:     int(*dstVrf.Metadata.RoutingTable[0]),
+			Protocol:  255,
+		})
+	}
+	return routes, nil
+}
+
+// setUpRouteLeak sets up the route leak
+func setUpRouteLeak(rl *infradb.RouteLeak) (string, bool) {
+	routes, err := routeLeakRoutes(rl)
+	if err != nil {
+		log.Printf("LGM: Failed to prepare route leak %s: %v\n", rl.Name, err)
+		return fmt.Sprintf("LGM: Failed to prepare route leak %s: %v\n", rl.Name, err), false
+	}
+	for _, route := range routes {
+		// Example: ip route add <prefix> dev <src-vrf> table <dst-vrf-table> proto opi_evpn_br
+		if err := nlink.RouteAdd(ctx, route); err != nil {
+			log.Printf("LGM: Failed to add leaked route %s table %d: %v\n", route.Dst, route.Table, err)
+			return fmt.Sprintf("LGM: Failed to add leaked route %s table %d: %v\n", route.Dst, route.Table, err), false
+		}
+		log.Printf("LGM Executed : ip route add %s dev %s table %d\n", route.Dst, infradb.LinkName(rl.Spec.SrcVrf, infradb.LinkRoleVrf), route.Table)
+	}
+	return "", true
+}
+
+// tearDownRouteLeak tears down the route leak
+func tearDownRouteLeak(rl *infradb.RouteLeak) (string, bool) {
+	routes, err := routeLeakRoutes(rl)
+	if err != nil {
+		// The vrfs are gone together with their routes
+		log.Printf("LGM: Nothing to tear down for route leak %s: %v\n", rl.Name, err)
+		return "", true
+	}
+	for _, route := range routes {
+		if err := nlink.RouteDel(ctx, route); err != nil {
+			log.Printf("LGM: Failed to delete leaked route %s table %d: %v\n", route.Dst, route.Table, err)
+			continue
+		}
+		log.Printf("LGM Executed : ip route del %s dev %s table %d\n", route.Dst, infradb.LinkName(rl.Spec.SrcVrf, infradb.LinkRoleVrf), route.Table)
+	}
+	return "", true
+}
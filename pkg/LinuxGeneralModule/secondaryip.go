@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"log"
+
+	"github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// pruneSviAddresses deletes the global addresses of the svi device which are neither gateway nor secondary
+// addresses of the svi anymore, e.g. a secondary address which has been removed. The link-local addresses
+// of the kernel are left alone.
+func pruneSviAddresses(nl utils.Netlink, link netlink.Link, svi *infradb.Svi) {
+	addrs, err := nl.AddrList(ctx, link, netlink.FAMILY_ALL)
+	if err != nil {
+		log.Printf("LGM: Failed to list the addresses of %s: %v\n", link.Attrs().Name, err)
+		return
+	}
+	wanted := make(map[string]bool)
+	for _, addr := range svi.Spec.Addresses() {
+		wanted[addr.String()] = true
+	}
+	for i := range addrs {
+		addr := &addrs[i]
+		if addr.Scope != int(netlink.SCOPE_UNIVERSE) || addr.IPNet == nil || wanted[addr.IPNet.String()] {
+			continue
+		}
+		if err := nl.AddrDel(ctx, link, addr); err != nil {
+			log.Printf("LGM: Failed to delete ip address %v of %s: %v\n", addr.IPNet, link.Attrs().Name, err)
+			continue
+		}
+		log.Printf("LGM Executed :  ip address del %s dev %s\n", addr.IPNet, link.Attrs().Name)
+	}
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// srv6Proto marks the SIDs of the VPCs in the main table, as the other routes of the bridge
+const srv6Proto = "255"
+
+// setUpSrv6Sids binds the End.DT4 and End.DT6 SIDs of a VPC carried over SRv6 to the table of its vrf and
+// reports the table, and the SIDs
+func setUpSrv6Sids(vrf *infradb.Vrf, routingtable uint32) (string, bool) {
+	if !vrf.Spec.IsSrv6() {
+		return fmt.Sprintf("{\"routingtable\":\"%d\"}", routingtable), true
+	}
+	locator, err := infradb.Srv6Locator()
+	if err != nil {
+		log.Printf("LGM: %v\n", err)
+		return fmt.Sprintf("LGM: %v\n", err), false
+	}
+	vrfLink := infradb.LinkName(vrf.Name, infradb.LinkRoleVrf)
+	table := strconv.FormatUint(uint64(routingtable), 10)
+	dt4, dt6 := vrf.Spec.Srv6.Sids(locator)
+	for action, sid := range map[string]string{"End.DT4": dt4.String(), "End.DT6": dt6.String()} {
+		// The kernel decapsulates the packets to the sid and looks the inner packet up in the vrf table
+		// Example: ip -6 route replace <sid>/128 encap seg6local action End.DT4 vrftable <table> dev <vrf> proto 255
+		cmd := []string{"ip", "-6", "route", "replace", sid + "/128", "encap", "seg6local", "action", action, "vrftable", table,
+			"dev", vrfLink, "proto", srv6Proto}
+		if CP, err := run(cmd, false); err != 0 {
+			log.Printf("LGM: Failed to bind the %s sid %s to %s: %s\n", action, sid, vrfLink, CP)
+			return fmt.Sprintf("LGM: Failed to bind the %s sid %s to %s: %s\n", action, sid, vrfLink, CP), false
+		}
+	}
+	return fmt.Sprintf("{\"routingtable\":\"%d\",\"sids\":{\"End.DT4\":\"%s\",\"End.DT6\":\"%s\"}}", routingtable, dt4, dt6), true
+}
+
+// tearDownSrv6Sids removes the SIDs left bound to the vrf by SRv6, the other ones go with the vrf device
+func tearDownSrv6Sids(vrf *infradb.Vrf) {
+	vrfLink := infradb.LinkName(vrf.Name, infradb.LinkRoleVrf)
+	// Example: ip -6 route flush table main dev <vrf> proto 255
+	if CP, err := run([]string{"ip", "-6", "route", "flush", "table", "main", "dev", vrfLink, "proto", srv6Proto}, false); err != 0 {
+		log.Printf("LGM: Failed to remove the SRv6 sids of %s: %s\n", vrfLink, CP)
+	}
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+// sysctlPath is the location of the kernel settings
+var sysctlPath = "/proc/sys"
+
+// defaultSviSysctls are the settings of the svis needed by the EVPN IRB: the svis learn the neighbors
+// from the gratuitous arps, the anycast gateway addresses shared by all the vteps must not fail the
+// duplicate address detection, and the traffic routed asymmetrically must pass the reverse path filter
+var defaultSviSysctls = map[string]string{
+	"ipv4.arp_accept": "1",
+	"ipv4.rp_filter":  "0",
+	"ipv6.accept_dad": "0",
+}
+
+// defaultVrfSysctls are the settings of the vrf devices, whose reverse path filter applies to all their svis
+var defaultVrfSysctls = map[string]string{
+	"ipv4.rp_filter": "0",
+}
+
+// deviceSysctls returns the settings of a device, the defaults overridden by the configured ones
+func deviceSysctls(defaults map[string]string, configured []string) map[string]string {
+	sysctls := map[string]string{}
+	for setting, value := range defaults {
+		sysctls[setting] = value
+	}
+	for _, sysctl := range configured {
+		setting, value, _ := strings.Cut(sysctl, "=")
+		if value == "" {
+			delete(sysctls, setting)
+			continue
+		}
+		sysctls[setting] = value
+	}
+	return sysctls
+}
+
+// writeDeviceSysctls writes the settings of the device of the network namespace in the order of their names
+func writeDeviceSysctls(namespace string, dev string, sysctls map[string]string) error {
+	settings := make([]string, 0, len(sysctls))
+	for setting := range sysctls {
+		settings = append(settings, setting)
+	}
+	sort.Strings(settings)
+	for _, setting := range settings {
+		family, name, _ := strings.Cut(setting, ".")
+		if namespace != "" {
+			// The files of /proc/sys are the ones of the namespace of the bridge, the slashes keep the dots of the device name
+			// Example: ip netns exec <namespace> sysctl -w net/ipv4/conf/<dev>/arp_accept=1
+			key := strings.Join([]string{"net", family, "conf", dev, name}, "/")
+			if CP, err := run(netnsCmd(namespace, []string{"sysctl", "-w", key + "=" + sysctls[setting]}), false); err != 0 {
+				return fmt.Errorf("%s=%s in network namespace %s: %s", key, sysctls[setting], namespace, CP)
+			}
+			log.Printf("LGM Executed : ip netns exec %s sysctl -w %s=%s\n", namespace, key, sysctls[setting])
+			continue
+		}
+		// Example: sysctl -w net.ipv4.conf.<dev>.arp_accept=1, written to the file as the device name may hold dots
+		file := filepath.Join(sysctlPath, "net", family, "conf", dev, name)
+		if err := os.WriteFile(file, []byte(sysctls[setting]), 0600); err != nil {
+			return fmt.Errorf("net.%s.conf.%s.%s=%s: %w", family, dev, name, sysctls[setting], err)
+		}
+		log.Printf("LGM Executed : sysctl -w net.%s.conf.%s.%s=%s\n", family, dev, name, sysctls[setting])
+	}
+	return nil
+}
+
+// applyDeviceSysctls writes the settings of the device, a failure only stops the set up in strict mode
+func applyDeviceSysctls(namespace string, dev string, defaults map[string]string, configured []string) (string, bool) {
+	err := writeDeviceSysctls(namespace, dev, deviceSysctls(defaults, configured))
+	if err == nil {
+		return "", true
+	}
+	log.Printf("LGM: Failed to write the kernel settings of %s: %v\n", dev, err)
+	if !config.GlobalConfig.Sysctls.Strict {
+		return "", true
+	}
+	return fmt.Sprintf("LGM: Failed to write the kernel settings of %s: %v\n", dev, err), false
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+// fakeConfDir creates the kernel settings directories of the device in a fake /proc/sys
+func fakeConfDir(t *testing.T, dev string, families ...string) string {
+	root := t.TempDir()
+	sysctlPath = root
+	t.Cleanup(func() { sysctlPath = "/proc/sys" })
+	for _, family := range families {
+		if err := os.MkdirAll(filepath.Join(root, "net", family, "conf", dev), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func Test_DeviceSysctls(t *testing.T) {
+	sysctls := deviceSysctls(defaultSviSysctls, []string{"ipv4.arp_accept=0", "ipv6.accept_dad=", "ipv4.arp_notify=1"})
+	expected := map[string]string{"ipv4.arp_accept": "0", "ipv4.rp_filter": "0", "ipv4.arp_notify": "1"}
+	if !reflect.DeepEqual(sysctls, expected) {
+		t.Errorf("expected %v, received %v", expected, sysctls)
+	}
+	if defaultSviSysctls["ipv4.arp_accept"] != "1" {
+		t.Error("expected the defaults to be left alone")
+	}
+}
+
+func Test_WriteDeviceSysctls(t *testing.T) {
+	dev := "br.100"
+	root := fakeConfDir(t, dev, "ipv4", "ipv6")
+	if err := writeDeviceSysctls("", dev, defaultSviSysctls); err != nil {
+		t.Fatal(err)
+	}
+	for file, value := range map[string]string{
+		"net/ipv4/conf/br.100/arp_accept": "1",
+		"net/ipv4/conf/br.100/rp_filter":  "0",
+		"net/ipv6/conf/br.100/accept_dad": "0",
+	} {
+		data, err := os.ReadFile(filepath.Join(root, file))
+		if err != nil || string(data) != value {
+			t.Errorf("expected %s to be %s, received %q (%v)", file, value, data, err)
+		}
+	}
+}
+
+func Test_ApplyDeviceSysctls(t *testing.T) {
+	// IPv6 is disabled on the device, so its settings are missing
+	dev := "vrf-blue"
+	fakeConfDir(t, dev, "ipv4")
+	defer func(strict bool) { config.GlobalConfig.Sysctls.Strict = strict }(config.GlobalConfig.Sysctls.Strict)
+
+	config.GlobalConfig.Sysctls.Strict = false
+	if _, ok := applyDeviceSysctls("", dev, defaultSviSysctls, nil); !ok {
+		t.Error("expected the set up to go on when the settings are not strict")
+	}
+	config.GlobalConfig.Sysctls.Strict = true
+	if details, ok := applyDeviceSysctls("", dev, defaultSviSysctls, nil); ok || details == "" {
+		t.Error("expected the set up to fail in strict mode")
+	}
+	if _, ok := applyDeviceSysctls("", dev, defaultSviSysctls, []string{"ipv6.accept_dad="}); !ok {
+		t.Error("expected the set up to succeed once the missing setting is kept from the host")
+	}
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/vishvananda/netlink"
+)
+
+// sysClassNet is the sysfs directory of the network devices
+var sysClassNet = "/sys/class/net"
+
+var (
+	// pfPortName matches the physical port name of an uplink, e.g. p0
+	pfPortName = regexp.MustCompile(`^p(\d+)$`)
+	// vfPortName matches the physical port name of a VF representor, e.g. pf0vf3 or c1pf0vf3
+	vfPortName = regexp.MustCompile(`^(?:c\d+)?pf(\d+)vf(\d+)$`)
+)
+
+// handleVfRepresentor handles the VF representor functionality
+func handleVfRepresentor(objectData *eventbus.ObjectData) {
+	rep, err := infradb.GetVfRepresentor(objectData.Name)
+	handleResource(objectData, &rep.Resource, err,
+		func() (string, bool) { return setUpVfRepresentor(rep) },
+		func() (string, bool) { return tearDownVfRepresentor(rep) },
+		infradb.UpdateVfRepresentorStatus)
+}
+
+// readNetAttr reads a sysfs attribute of a network device
+func readNetAttr(dev, attr string) string {
+	data, err := os.ReadFile(filepath.Join(sysClassNet, dev, attr))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// eswitchMode returns the devlink eswitch mode of the PCI device of the PF
+func eswitchMode(pf string) (string, error) {
+	device, err := filepath.EvalSymlinks(filepath.Join(sysClassNet, pf, "device"))
+	if err != nil {
+		return "", fmt.Errorf("%s is not a PCI device: %v", pf, err)
+	}
+	dev, err := netlink.DevLinkGetDeviceByName("pci", filepath.Base(device))
+	if err != nil {
+		return "", fmt.Errorf("failed to get the devlink device of %s: %v", pf, err)
+	}
+	return dev.Attrs.Eswitch.Mode, nil
+}
+
+// FindVfRepresentor returns the representor netdev of the VF of the PF. The representor is on the
+// switch of the PF and its physical port name carries the PF number and the VF index.
+func FindVfRepresentor(pf string, vf int) (string, error) {
+	switchID := readNetAttr(pf, "phys_switch_id")
+	if switchID == "" {
+		return "", fmt.Errorf("%s has no switch id, it is not a switchdev PF", pf)
+	}
+	pfNum := -1
+	if m := pfPortName.FindStringSubmatch(readNetAttr(pf, "phys_port_name")); m != nil {
+		pfNum, _ = strconv.Atoi(m[1])
+	}
+	entries, err := os.ReadDir(sysClassNet)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		dev := entry.Name()
+		if dev == pf || readNetAttr(dev, "phys_switch_id") != switchID {
+			continue
+		}
+		m := vfPortName.FindStringSubmatch(readNetAttr(dev, "phys_port_name"))
+		if m == nil {
+			continue
+		}
+		repPf, _ := strconv.Atoi(m[1])
+		repVf, _ := strconv.Atoi(m[2])
+		if repVf == vf && (pfNum < 0 || repPf == pfNum) {
+			return dev, nil
+		}
+	}
+	return "", fmt.Errorf("no representor of VF %d of %s", vf, pf)
+}
+
+// setUpVfRepresentor names the representor of the VF after the resource id and
+// readies it for the tc offload of the bridge port
+func setUpVfRepresentor(rep *infradb.VfRepresentor) (string, bool) {
+	mode, err := eswitchMode(rep.Spec.PF)
+	if err != nil {
+		log.Printf("LGM: %v\n", err)
+		return fmt.Sprintf("LGM: %v\n", err), false
+	}
+	if mode != "switchdev" {
+		log.Printf("LGM: The eswitch of %s is in %s mode and not in switchdev mode\n", rep.Spec.PF, mode)
+		return fmt.Sprintf("LGM: The eswitch of %s is in %s mode and not in switchdev mode\n", rep.Spec.PF, mode), false
+	}
+	netdev, err := FindVfRepresentor(rep.Spec.PF, rep.Spec.VF)
+	if err != nil {
+		log.Printf("LGM: %v\n", err)
+		return fmt.Sprintf("LGM: %v\n", err), false
+	}
+	link, err := nlink.LinkByName(ctx, netdev)
+	if err != nil {
+		log.Printf("LGM: Failed to get link information for %s: %v\n", netdev, err)
+		return fmt.Sprintf("LGM: Failed to get link information for %s: %v\n", netdev, err), false
+	}
+	linkName := rep.LinkName()
+	if existing, err := nlink.LinkByName(ctx, linkName); err != nil {
+		// Example: ip link property add dev eth5 altname vm1-vf3
+		if CP, err := run([]string{"ip", "link", "property", "add", "dev", netdev, "altname", linkName}, false); err != 0 {
+			return fmt.Sprintf("LGM: Failed to name the representor %s %s: %s\n", netdev, linkName, CP), false
+		}
+		log.Printf("LGM Executed : ip link property add dev %s altname %s\n", netdev, linkName)
+	} else if existing.Attrs().Index != link.Attrs().Index {
+		log.Printf("LGM: The name %s is already used by %s\n", linkName, existing.Attrs().Name)
+		return fmt.Sprintf("LGM: The name %s is already used by %s\n", linkName, existing.Attrs().Name), false
+	}
+	for _, dev := range []string{rep.Spec.PF, netdev} {
+		// Example: ethtool -K eth5 hw-tc-offload on
+		if CP, err := run([]string{"ethtool", "-K", dev, "hw-tc-offload", "on"}, false); err != 0 {
+			return fmt.Sprintf("LGM: Failed to enable the tc offload of %s: %s\n", dev, CP), false
+		}
+		log.Printf("LGM Executed : ethtool -K %s hw-tc-offload on\n", dev)
+	}
+	// Example: tc qdisc replace dev eth5 clsact
+	if CP, err := run([]string{"tc", "qdisc", "replace", "dev", netdev, "clsact"}, false); err != 0 {
+		return fmt.Sprintf("LGM: Failed to add the clsact qdisc of %s: %s\n", netdev, CP), false
+	}
+	log.Printf("LGM Executed : tc qdisc replace dev %s clsact\n", netdev)
+	return "", true
+}
+
+// tearDownVfRepresentor gives the representor its kernel name back, it may be gone
+// with the VFs of the PF
+func tearDownVfRepresentor(rep *infradb.VfRepresentor) (string, bool) {
+	link, err := nlink.LinkByName(ctx, rep.LinkName())
+	if err != nil {
+		return "", true
+	}
+	netdev := link.Attrs().Name
+	// Example: tc qdisc del dev eth5 clsact
+	if _, err := run([]string{"tc", "qdisc", "del", "dev", netdev, "clsact"}, false); err == 0 {
+		log.Printf("LGM Executed : tc qdisc del dev %s clsact\n", netdev)
+	}
+	// Example: ip link property del dev eth5 altname vm1-vf3
+	if CP, err := run([]string{"ip", "link", "property", "del", "dev", netdev, "altname", rep.LinkName()}, false); err != 0 {
+		return fmt.Sprintf("LGM: Failed to remove the name %s of the representor %s: %s\n", rep.LinkName(), netdev, CP), false
+	}
+	log.Printf("LGM Executed : ip link property del dev %s altname %s\n", netdev, rep.LinkName())
+	return "", true
+}
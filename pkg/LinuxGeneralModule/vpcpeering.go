@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package linuxgeneralmodule is the main package of the application
+package linuxgeneralmodule
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"syscall"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/vishvananda/netlink"
+)
+
+// handleVpcPeering handles the vpc peering functionality
+func handleVpcPeering(objectData *eventbus.ObjectData) {
+	vp, err := infradb.GetVpcPeering(objectData.Name)
+	handleResource(objectData, &vp.Resource, err,
+		func() (string, bool) { return setUpVpcPeering(vp) },
+		func() (string, bool) { return tearDownVpcPeering(vp) },
+		infradb.UpdateVpcPeeringStatus)
+}
+
+// sviSubnets returns the subnets of the gateway addresses of the svis of the vrf
+func sviSubnets(vrf string) ([]*net.IPNet, error) {
+	svis, err := infradb.GetAllSvis()
+	if err != nil && !errors.Is(err, infradb.ErrKeyNotFound) {
+		return nil, err
+	}
+	subnets := []*net.IPNet{}
+	for _, svi := range svis {
+		if svi.Spec.Vrf == vrf {
+			subnets = append(subnets, gatewaySubnets(svi)...)
+		}
+	}
+	return subnets, nil
+}
+
+// gatewaySubnets returns the subnets of the gateway addresses of the svi
+func gatewaySubnets(svi *infradb.Svi) []*net.IPNet {
+	subnets := []*net.IPNet{}
+	for _, gw := range svi.Spec.GatewayIPs {
+		subnets = append(subnets, &net.IPNet{IP: gw.IP.Mask(gw.Mask), Mask: gw.Mask})
+	}
+	return subnets
+}
+
+// peeringRoutes builds the kernel routes which send the traffic of the destination vrf
+// towards the prefixes into the source vrf
+func peeringRoutes(src, dst string, prefixes []*net.IPNet) ([]*netlink.Route, error) {
+	dstVrf, err := infradb.GetVrf(dst)
+	if err != nil {
+		return nil, err
+	}
+	if dstVrf.Metadata == nil || len(dstVrf.Metadata.RoutingTable) == 0 || dstVrf.Metadata.RoutingTable[0] == nil {
+		return nil, fmt.Errorf("routing table of vrf %s is not yet known", dstVrf.Name)
+	}
+	srcLink, err := nlink.LinkByName(ctx, infradb.LinkName(src, infradb.LinkRoleVrf))
+	if err != nil {
+		return nil, err
+	}
+	routes := []*netlink.Route{}
+	for _, prefix := range prefixes {
+		routes = append(routes, &netlink.Route{
+			Dst:       prefix,
+			LinkIndex: srcLink.Attrs().Index,
+			Table:     int(*dstVrf.Metadata.RoutingTable[0]),
+			Protocol:  255,
+		})
+	}
+	return routes, nil
+}
+
+// vpcPeeringRoutes builds the kernel routes of both ways of the vpc peering. The locally hosted
+// subnets are routed in the kernel as FRR does not import the routes originated by the node through
+// the route targets, a way without prefix filters routes all the subnets of the svis of the peer.
+func vpcPeeringRoutes(vp *infradb.VpcPeering) ([]*netlink.Route, error) {
+	routes := []*netlink.Route{}
+	for _, dir := range vp.Spec.Directions() {
+		prefixes := dir.Prefixes
+		if len(prefixes) == 0 {
+			subnets, err := sviSubnets(dir.Src)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = subnets
+		}
+		dirRoutes, err := peeringRoutes(dir.Src, dir.Dst, prefixes)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, dirRoutes...)
+	}
+	return routes, nil
+}
+
+// setUpVpcPeering sets up the vpc peering
+func setUpVpcPeering(vp *infradb.VpcPeering) (string, bool) {
+	routes, err := vpcPeeringRoutes(vp)
+	if err != nil {
+		log.Printf("LGM: Failed to prepare vpc peering %s: %v\n", vp.Name, err)
+		return fmt.Sprintf("LGM: Failed to prepare vpc peering %s: %v\n", vp.Name, err), false
+	}
+	for _, route := range routes {
+		// Example: ip route add <prefix> dev <src-vrf> table <dst-vrf-table> proto opi_evpn_br
+		if err := nlink.RouteAdd(ctx, route); err != nil && !errors.Is(err, syscall.EEXIST) {
+			log.Printf("LGM: Failed to add peering route %s table %d: %v\n", route.Dst, route.Table, err)
+			return fmt.Sprintf("LGM: Failed to add peering route %s table %d: %v\n", route.Dst, route.Table, err), false
+		}
+		log.Printf("LGM Executed : ip route add %s dev %d table %d\n", route.Dst, route.LinkIndex, route.Table)
+	}
+	return "", true
+}
+
+// tearDownVpcPeering tears down the vpc peering
+func tearDownVpcPeering(vp *infradb.VpcPeering) (string, bool) {
+	routes, err := vpcPeeringRoutes(vp)
+	if err != nil {
+		// The vrfs are gone together with their routes
+		log.Printf("LGM: Nothing to tear down for vpc peering %s: %v\n", vp.Name, err)
+		return "", true
+	}
+	for _, route := range routes {
+		if err := nlink.RouteDel(ctx, route); err != nil {
+			log.Printf("LGM: Failed to delete peering route %s table %d: %v\n", route.Dst, route.Table, err)
+			continue
+		}
+		log.Printf("LGM Executed : ip route del %s dev %d table %d\n", route.Dst, route.LinkIndex, route.Table)
+	}
+	return "", true
+}
+
+// syncSviPeeringRoutes adds or removes the routes towards the subnets of the svi in the vrfs
+// peered without prefix filters to the vrf of the svi
+func syncSviPeeringRoutes(svi *infradb.Svi, add bool) {
+	vps, err := infradb.GetAllVpcPeerings()
+	if err != nil {
+		log.Printf("LGM: Failed to get vpc peerings: %v\n", err)
+		return
+	}
+	for _, vp := range vps {
+		if vp.Status.OperStatus == infradb.OperStatusToBeDeleted {
+			continue
+		}
+		for _, dir := range vp.Spec.Directions() {
+			if dir.Src != svi.Spec.Vrf || len(dir.Prefixes) != 0 {
+				continue
+			}
+			routes, err := peeringRoutes(dir.Src, dir.Dst, gatewaySubnets(svi))
+			if err != nil {
+				log.Printf("LGM: Failed to prepare the peering routes of svi %s: %v\n", svi.Name, err)
+				continue
+			}
+			for _, route := range routes {
+				if add {
+					err = nlink.RouteAdd(ctx, route)
+				} else {
+					err = nlink.RouteDel(ctx, route)
+				}
+				if err != nil && !errors.Is(err, syscall.EEXIST) {
+					log.Printf("LGM: Failed to update the peering route %s table %d of svi %s: %v\n", route.Dst, route.Table, svi.Name, err)
+				}
+			}
+		}
+	}
+}
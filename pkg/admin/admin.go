@@ -0,0 +1,284 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.einride.tech/aip/resourcename"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+// route describes an admin endpoint
+type route struct {
+	method  string
+	pattern string
+	handler runtime.HandlerFunc
+}
+
+// routes holds all the admin endpoints
+var routes = []route{
+	{http.MethodGet, "/v1/admin/bridgeports/{bridgeport}/stats", getBridgePortStats},
+	{http.MethodGet, "/v1/admin/bridgeports/{bridgeport}/sflow", getBridgePortSflow},
+	{http.MethodPut, "/v1/admin/bridgeports/{bridgeport}/sflow", setBridgePortSflow},
+	{http.MethodDelete, "/v1/admin/bridgeports/{bridgeport}/sflow", deleteBridgePortSflow},
+	{http.MethodGet, "/v1/admin/bridgeports/{bridgeport}/qinq", getBridgePortQinq},
+	{http.MethodPut, "/v1/admin/bridgeports/{bridgeport}/qinq", setBridgePortQinq},
+	{http.MethodDelete, "/v1/admin/bridgeports/{bridgeport}/qinq", deleteBridgePortQinq},
+	{http.MethodGet, "/v1/admin/bridgeports/{bridgeport}/isolation", getBridgePortIsolation},
+	{http.MethodPut, "/v1/admin/bridgeports/{bridgeport}/isolation", setBridgePortIsolation},
+	{http.MethodDelete, "/v1/admin/bridgeports/{bridgeport}/isolation", deleteBridgePortIsolation},
+	{http.MethodGet, "/v1/admin/bridgeports/{bridgeport}/profile", getBridgePortProfileOf},
+	{http.MethodPut, "/v1/admin/bridgeports/{bridgeport}/profile", setBridgePortProfileOf},
+	{http.MethodDelete, "/v1/admin/bridgeports/{bridgeport}/profile", deleteBridgePortProfileOf},
+	{http.MethodGet, "/v1/admin/bridgeportprofiles", listBridgePortProfiles},
+	{http.MethodGet, "/v1/admin/bridgeportprofiles/{profile}", getBridgePortProfile},
+	{http.MethodPut, "/v1/admin/bridgeportprofiles/{profile}", setBridgePortProfile},
+	{http.MethodDelete, "/v1/admin/bridgeportprofiles/{profile}", deleteBridgePortProfile},
+	{http.MethodGet, "/v1/admin/logicalbridges/{logicalbridge}/encap", getLogicalBridgeEncap},
+	{http.MethodPut, "/v1/admin/logicalbridges/{logicalbridge}/encap", setLogicalBridgeEncap},
+	{http.MethodDelete, "/v1/admin/logicalbridges/{logicalbridge}/encap", deleteLogicalBridgeEncap},
+	{http.MethodGet, "/v1/admin/logicalbridges/{logicalbridge}/isolation", getLogicalBridgeIsolation},
+	{http.MethodPut, "/v1/admin/logicalbridges/{logicalbridge}/isolation", setLogicalBridgeIsolation},
+	{http.MethodDelete, "/v1/admin/logicalbridges/{logicalbridge}/isolation", deleteLogicalBridgeIsolation},
+	{http.MethodGet, "/v1/admin/logicalbridges/{logicalbridge}/macmoves", getLogicalBridgeMacMoves},
+	{http.MethodGet, "/v1/admin/vrfs/{vrf}/dataplane", getVrfDataplane},
+	{http.MethodPut, "/v1/admin/vrfs/{vrf}/dataplane", setVrfDataplane},
+	{http.MethodDelete, "/v1/admin/vrfs/{vrf}/dataplane", deleteVrfDataplane},
+	{http.MethodGet, "/v1/admin/vrfs/{vrf}/netns", getVrfNetns},
+	{http.MethodPut, "/v1/admin/vrfs/{vrf}/netns", setVrfNetns},
+	{http.MethodDelete, "/v1/admin/vrfs/{vrf}/netns", deleteVrfNetns},
+	{http.MethodGet, "/v1/admin/lldp/neighbors", listLldpNeighbors},
+	{http.MethodGet, "/v1/admin/fabric/health", getFabricHealth},
+	{http.MethodGet, "/v1/admin/ipsec/tunnels", listIpsecTunnels},
+	{http.MethodGet, "/v1/admin/svis/{svi}/gateway", getSviGateway},
+	{http.MethodPost, "/v1/admin/svis/{svi}/announce", announceSvi},
+	{http.MethodGet, "/v1/admin/svis/{svi}/proxyarp", getSviProxyArp},
+	{http.MethodPut, "/v1/admin/svis/{svi}/proxyarp", setSviProxyArp},
+	{http.MethodDelete, "/v1/admin/svis/{svi}/proxyarp", deleteSviProxyArp},
+	{http.MethodGet, "/v1/admin/svis/{svi}/secondaryips", getSviSecondaryIPs},
+	{http.MethodPut, "/v1/admin/svis/{svi}/secondaryips", setSviSecondaryIPs},
+	{http.MethodDelete, "/v1/admin/svis/{svi}/secondaryips", deleteSviSecondaryIPs},
+	{http.MethodPost, "/v1/admin/svis/{svi}/allocations", allocateIP},
+	{http.MethodGet, "/v1/admin/svis/{svi}/allocations", listIPAllocations},
+	{http.MethodDelete, "/v1/admin/svis/{svi}/allocations/{address}", releaseIP},
+	{http.MethodPost, "/v1/admin/routeleaks", createRouteLeak},
+	{http.MethodGet, "/v1/admin/routeleaks", listRouteLeaks},
+	{http.MethodGet, "/v1/admin/routeleaks/{routeleak}", getRouteLeak},
+	{http.MethodDelete, "/v1/admin/routeleaks/{routeleak}", deleteRouteLeak},
+	{http.MethodPost, "/v1/admin/hostroutes", createHostRoute},
+	{http.MethodGet, "/v1/admin/hostroutes", listHostRoutes},
+	{http.MethodGet, "/v1/admin/hostroutes/{hostroute}", getHostRoute},
+	{http.MethodPut, "/v1/admin/hostroutes/{hostroute}", updateHostRoute},
+	{http.MethodDelete, "/v1/admin/hostroutes/{hostroute}", deleteHostRoute},
+	{http.MethodPost, "/v1/admin/vpcpeerings", createVpcPeering},
+	{http.MethodGet, "/v1/admin/vpcpeerings", listVpcPeerings},
+	{http.MethodGet, "/v1/admin/vpcpeerings/{vpcpeering}", getVpcPeering},
+	{http.MethodDelete, "/v1/admin/vpcpeerings/{vpcpeering}", deleteVpcPeering},
+	{http.MethodPost, "/v1/admin/flowlogs", createFlowLog},
+	{http.MethodGet, "/v1/admin/flowlogs", listFlowLogs},
+	{http.MethodGet, "/v1/admin/flowlogs/{flowlog}", getFlowLog},
+	{http.MethodDelete, "/v1/admin/flowlogs/{flowlog}", deleteFlowLog},
+	{http.MethodPost, "/v1/admin/routingpolicies", createRoutingPolicy},
+	{http.MethodGet, "/v1/admin/routingpolicies", listRoutingPolicies},
+	{http.MethodGet, "/v1/admin/routingpolicies/{routingpolicy}", getRoutingPolicy},
+	{http.MethodPut, "/v1/admin/routingpolicies/{routingpolicy}", updateRoutingPolicy},
+	{http.MethodDelete, "/v1/admin/routingpolicies/{routingpolicy}", deleteRoutingPolicy},
+	{http.MethodPost, "/v1/admin/natgateways", createNatGateway},
+	{http.MethodGet, "/v1/admin/natgateways", listNatGateways},
+	{http.MethodGet, "/v1/admin/natgateways/{natgateway}", getNatGateway},
+	{http.MethodGet, "/v1/admin/natgateways/{natgateway}/stats", getNatGatewayStats},
+	{http.MethodDelete, "/v1/admin/natgateways/{natgateway}", deleteNatGateway},
+	{http.MethodPost, "/v1/admin/dnsforwarders", createDNSForwarder},
+	{http.MethodGet, "/v1/admin/dnsforwarders", listDNSForwarders},
+	{http.MethodGet, "/v1/admin/dnsforwarders/{dnsforwarder}", getDNSForwarder},
+	{http.MethodDelete, "/v1/admin/dnsforwarders/{dnsforwarder}", deleteDNSForwarder},
+	{http.MethodPost, "/v1/admin/dhcpservers", createDHCPServer},
+	{http.MethodGet, "/v1/admin/dhcpservers", listDHCPServers},
+	{http.MethodGet, "/v1/admin/dhcpservers/{dhcpserver}", getDHCPServer},
+	{http.MethodDelete, "/v1/admin/dhcpservers/{dhcpserver}", deleteDHCPServer},
+	{http.MethodPost, "/v1/admin/routeradvertisements", createRouterAdvertisement},
+	{http.MethodGet, "/v1/admin/routeradvertisements", listRouterAdvertisements},
+	{http.MethodGet, "/v1/admin/routeradvertisements/{routeradvertisement}", getRouterAdvertisement},
+	{http.MethodDelete, "/v1/admin/routeradvertisements/{routeradvertisement}", deleteRouterAdvertisement},
+	{http.MethodPost, "/v1/admin/externalinterfaces", createExternalInterface},
+	{http.MethodGet, "/v1/admin/externalinterfaces", listExternalInterfaces},
+	{http.MethodGet, "/v1/admin/externalinterfaces/{externalinterface}", getExternalInterface},
+	{http.MethodDelete, "/v1/admin/externalinterfaces/{externalinterface}", deleteExternalInterface},
+	{http.MethodPost, "/v1/admin/bonds", createBond},
+	{http.MethodGet, "/v1/admin/bonds", listBonds},
+	{http.MethodGet, "/v1/admin/bonds/{bond}", getBond},
+	{http.MethodDelete, "/v1/admin/bonds/{bond}", deleteBond},
+	{http.MethodPost, "/v1/admin/portsecurities", createPortSecurity},
+	{http.MethodGet, "/v1/admin/portsecurities", listPortSecurities},
+	{http.MethodGet, "/v1/admin/portsecurities/{portsecurity}", getPortSecurity},
+	{http.MethodDelete, "/v1/admin/portsecurities/{portsecurity}", deletePortSecurity},
+	{http.MethodPost, "/v1/admin/dhcpsnoopings", createDHCPSnooping},
+	{http.MethodGet, "/v1/admin/dhcpsnoopings", listDHCPSnoopings},
+	{http.MethodGet, "/v1/admin/dhcpsnoopings/{dhcpsnooping}", getDHCPSnooping},
+	{http.MethodGet, "/v1/admin/dhcpsnoopings/{dhcpsnooping}/bindings", listDHCPBindings},
+	{http.MethodDelete, "/v1/admin/dhcpsnoopings/{dhcpsnooping}", deleteDHCPSnooping},
+	{http.MethodPost, "/v1/admin/conntrackpolicies", createConntrackPolicy},
+	{http.MethodGet, "/v1/admin/conntrackpolicies", listConntrackPolicies},
+	{http.MethodGet, "/v1/admin/conntrackpolicies/{conntrackpolicy}", getConntrackPolicy},
+	{http.MethodDelete, "/v1/admin/conntrackpolicies/{conntrackpolicy}", deleteConntrackPolicy},
+	{http.MethodPost, "/v1/admin/virtualports", createVirtualPort},
+	{http.MethodGet, "/v1/admin/virtualports", listVirtualPorts},
+	{http.MethodGet, "/v1/admin/virtualports/{virtualport}", getVirtualPort},
+	{http.MethodDelete, "/v1/admin/virtualports/{virtualport}", deleteVirtualPort},
+	{http.MethodPost, "/v1/admin/vfrepresentors", createVfRepresentor},
+	{http.MethodGet, "/v1/admin/vfrepresentors", listVfRepresentors},
+	{http.MethodGet, "/v1/admin/vfrepresentors/{vfrepresentor}", getVfRepresentor},
+	{http.MethodDelete, "/v1/admin/vfrepresentors/{vfrepresentor}", deleteVfRepresentor},
+	{http.MethodGet, "/v1/admin/inventory/ports", listPortInventory},
+	{http.MethodGet, "/v1/admin/devlink/devices", listDevlinkDevices},
+	{http.MethodPut, "/v1/admin/devlink/devices/{bus}/{device}/eswitch", setEswitchMode},
+	{http.MethodGet, "/v1/admin/maintenance", getMaintenance},
+	{http.MethodPost, "/v1/admin/maintenance", enterMaintenance},
+	{http.MethodDelete, "/v1/admin/maintenance", exitMaintenance},
+	{http.MethodGet, "/v1/admin/readonly", getReadOnly},
+	{http.MethodPut, "/v1/admin/readonly", setReadOnly},
+	{http.MethodDelete, "/v1/admin/readonly", deleteReadOnly},
+	{http.MethodGet, "/v1/admin/revisions", listRevisions},
+	{http.MethodGet, "/v1/admin/revisions/{revision}", getRevision},
+	{http.MethodGet, "/v1/admin/leases", listLeases},
+	{http.MethodPut, "/v1/admin/leases/{collection}/{resource}", setLease},
+	{http.MethodPost, "/v1/admin/leases/{collection}/{resource}/keepalive", keepAliveLease},
+	{http.MethodDelete, "/v1/admin/leases/{collection}/{resource}", deleteLease},
+	{http.MethodPost, "/v1/admin/schedules", createScheduledChange},
+	{http.MethodGet, "/v1/admin/schedules", listScheduledChanges},
+	{http.MethodGet, "/v1/admin/schedules/{schedule}", getScheduledChange},
+	{http.MethodPost, "/v1/admin/schedules/{schedule}/confirm", confirmScheduledChange},
+	{http.MethodDelete, "/v1/admin/schedules/{schedule}", deleteScheduledChange},
+	{http.MethodGet, "/v1/admin/webhooks", listWebhooks},
+	{http.MethodGet, "/v1/admin/webhooks/{webhook}", getWebhook},
+	{http.MethodPut, "/v1/admin/webhooks/{webhook}", setWebhook},
+	{http.MethodDelete, "/v1/admin/webhooks/{webhook}", deleteWebhook},
+	{http.MethodGet, "/v1/admin/netdevs", listNetdevs},
+	{http.MethodPut, "/v1/admin/netdevs/{netdev}/claim", claimNetdev},
+	{http.MethodDelete, "/v1/admin/netdevs/{netdev}/claim", releaseNetdev},
+	{http.MethodGet, "/v1/admin/quotas", getQuotaUsage},
+	{http.MethodGet, "/v1/admin/dependencygraph", getDependencyGraph},
+	{http.MethodGet, "/v1/admin/drift", checkDrift},
+	{http.MethodPost, "/v1/admin/adoptions", adoptDevice},
+	{http.MethodGet, "/v1/admin/linkstates", listLinkStates},
+	{http.MethodGet, "/v1/admin/writequeues", listWriteQueues},
+	{http.MethodGet, "/v1/admin/nodes", listNodes},
+	{http.MethodGet, "/v1/admin/evpn/vnis", listEvpnVnis},
+	{http.MethodGet, "/v1/admin/evpn/routes", listEvpnRoutes},
+	{http.MethodGet, "/v1/admin/evpn/duplicates", listDuplicates},
+	{http.MethodDelete, "/v1/admin/evpn/duplicates/{vni}", clearDuplicates},
+	{http.MethodGet, "/v1/admin/vrfs/{vrf}/bgppeers", listBgpPeers},
+	{http.MethodGet, "/v1/admin/vrfs/{vrf}/bgproutes", listBgpRoutes},
+	{http.MethodGet, "/v1/admin/nexthopgroups", listNexthopGroups},
+}
+
+// RegisterHandlers registers the admin endpoints on the gateway mux, the ones changing the bridge
+// are rejected while it is read-only and the ones of the objects carry their etag
+func RegisterHandlers(mux *runtime.ServeMux) error {
+	for _, r := range routes {
+		if err := mux.HandlePath(r.method, r.pattern, withETag(r, guardReadOnly(r))); err != nil {
+			log.Printf("admin: failed to register %s %s: %v\n", r.method, r.pattern, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// fullName translates a resource id to the full resource name
+func fullName(collection, resourceID string) string {
+	return resourcename.Join(
+		"//network.opiproject.org/",
+		collection, resourceID,
+	)
+}
+
+// component is the json representation of a component status
+type component struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Details string `json:"details,omitempty"`
+}
+
+// componentsToJSON translates the component statuses to their json representation
+func componentsToJSON(comps []common.Component) []component {
+	out := []component{}
+	for _, comp := range comps {
+		c := component{Name: comp.Name, Details: comp.Details}
+		switch comp.CompStatus {
+		case common.ComponentStatusPending:
+			c.Status = "PENDING"
+		case common.ComponentStatusSuccess:
+			c.Status = "SUCCESS"
+		case common.ComponentStatusError:
+			c.Status = "ERROR"
+		default:
+			c.Status = "UNSPECIFIED"
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// readRequest decodes the json body of the http request
+func readRequest(r *http.Request, obj interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(obj); err != nil {
+		return status.Errorf(codes.InvalidArgument, "malformed request body: %v", err)
+	}
+	return nil
+}
+
+// writeResponse writes the object as json to the http response
+func writeResponse(w http.ResponseWriter, code int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if obj == nil {
+		obj = struct{}{}
+	}
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		log.Printf("admin: failed to encode response: %v\n", err)
+	}
+}
+
+// sameSpec tells whether a create request replays the creation of the existing resource,
+// the specs are compared in the json form in which the store keeps them
+func sameSpec(requested interface{}, existing interface{}) bool {
+	a, err := json.Marshal(requested)
+	if err != nil {
+		return false
+	}
+	b, err := json.Marshal(existing)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(a, b)
+}
+
+// writeError translates the error to a http status and writes it to the response with its google.rpc details
+func writeError(w http.ResponseWriter, err error) {
+	st := apierrors.Convert(err)
+	details := []json.RawMessage{}
+	for _, detail := range st.Proto().GetDetails() {
+		// same shape as the details of the grpc-gateway errors: the detail with its @type
+		if data, err := protojson.Marshal(detail); err == nil {
+			details = append(details, data)
+		}
+	}
+	writeResponse(w, runtime.HTTPStatusFromCode(st.Code()), map[string]interface{}{
+		"code":    st.Code(),
+		"message": st.Message(),
+		"details": details,
+	})
+}
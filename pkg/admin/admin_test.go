@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+)
+
+func Test_CreateReplay(t *testing.T) {
+	tests := map[string]struct {
+		setup func(t *testing.T)
+		url   string
+		in    interface{}
+		other interface{}
+	}{
+		"route leak": {
+			url:   "/v1/admin/routeleaks?id=opi-leak",
+			in:    routeLeak{SrcVrf: testVrfA, DstVrf: testVrfB, Prefixes: []string{"10.0.0.0/24"}},
+			other: routeLeak{SrcVrf: testVrfA, DstVrf: testVrfB, Prefixes: []string{"10.0.1.0/24"}},
+		},
+		"nat gateway": {
+			url:   "/v1/admin/natgateways?id=opi-nat",
+			in:    natGateway{Vrf: testVrfA, ExternalInterface: "eth1", Subnets: []string{"10.0.0.0/24"}},
+			other: natGateway{Vrf: testVrfA, ExternalInterface: "eth2", Subnets: []string{"10.0.0.0/24"}},
+		},
+		"dns forwarder": {
+			url:   "/v1/admin/dnsforwarders?id=opi-dns",
+			in:    dnsForwarder{Vrf: testVrfA, Upstreams: []string{"192.0.2.53"}},
+			other: dnsForwarder{Vrf: testVrfA, Upstreams: []string{"198.51.100.53"}},
+		},
+		"dhcp server": {
+			setup: createTestSvi,
+			url:   "/v1/admin/dhcpservers?id=opi-dhcp",
+			in:    dhcpServer{Svi: fullName("svis", "web"), Subnet: "10.0.0.0/29", RangeStart: "10.0.0.2", RangeEnd: "10.0.0.6"},
+			other: dhcpServer{Svi: fullName("svis", "web"), Subnet: "10.0.0.0/29", RangeStart: "10.0.0.3", RangeEnd: "10.0.0.6"},
+		},
+		"router advertisement": {
+			setup: createTestDualStackSvi,
+			url:   "/v1/admin/routeradvertisements?id=opi-ra",
+			in:    routerAdvertisement{Svi: fullName("svis", "web6")},
+			other: routerAdvertisement{Svi: fullName("svis", "web6"), Managed: true},
+		},
+		"external interface": {
+			url:   "/v1/admin/externalinterfaces?id=opi-ext",
+			in:    externalInterface{Vrf: testVrfA, Interface: "eth1", VlanID: 100, Address: "198.51.100.2/30"},
+			other: externalInterface{Vrf: testVrfA, Interface: "eth1", VlanID: 100, Address: "198.51.100.6/30"},
+		},
+		"bond": {
+			url:   "/v1/admin/bonds?id=bond0",
+			in:    bond{Members: []string{"eth3", "eth4"}},
+			other: bond{Members: []string{"eth3", "eth5"}},
+		},
+		"port security": {
+			setup: createTestBridgePort,
+			url:   "/v1/admin/portsecurities?id=eth2-psec",
+			in:    portSecurity{BridgePort: testBridgePort, MacLimit: 16},
+			other: portSecurity{BridgePort: testBridgePort, MacLimit: 32},
+		},
+		"virtual port": {
+			url:   "/v1/admin/virtualports?id=vm1-eth0",
+			in:    virtualPort{Type: "vhost-user", SocketPath: "/var/run/vhost/vm1-eth0.sock", Server: true},
+			other: virtualPort{Type: "vhost-user", SocketPath: "/var/run/vhost/vm1-eth0.sock", Server: true, Queues: 4},
+		},
+		"vf representor": {
+			url:   "/v1/admin/vfrepresentors?id=vm1-vf3",
+			in:    vfRepresentor{PF: "p0", VF: 3},
+			other: vfRepresentor{PF: "p0", VF: 4},
+		},
+		"vpc peering": {
+			url:   "/v1/admin/vpcpeerings?id=blue-to-red",
+			in:    vpcPeering{VrfA: testVrfA, VrfB: testVrfB},
+			other: vpcPeering{VrfA: testVrfA, VrfB: testVrfB, PrefixesA: []string{"10.0.0.0/24"}},
+		},
+		"flow log": {
+			url:   "/v1/admin/flowlogs?id=blue-flows",
+			in:    flowLog{Vrf: testVrfA, Collector: "192.0.2.10:4739"},
+			other: flowLog{Vrf: testVrfA, Collector: "192.0.2.10:4739", SamplingRate: 100},
+		},
+		"routing policy": {
+			url:   "/v1/admin/routingpolicies?id=opi-rp",
+			in:    routingPolicy{Rules: []*routingPolicyRule{{Seq: 10, Action: "permit"}}},
+			other: routingPolicy{Rules: []*routingPolicyRule{{Seq: 10, Action: "deny"}}},
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			if tt.setup != nil {
+				tt.setup(t)
+			}
+			post := func(in interface{}) *httptest.ResponseRecorder {
+				body, _ := json.Marshal(in)
+				req := httptest.NewRequest(http.MethodPost, tt.url, bytes.NewReader(body))
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, req)
+				return rec
+			}
+
+			created := post(tt.in)
+			if created.Code != http.StatusOK {
+				t.Fatalf("failed to create: %s", created.Body.String())
+			}
+			// an exact replay returns the existing object
+			if rec := post(tt.in); rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), created.Body.Bytes()) {
+				t.Errorf("expected the existing object, received %d: %s", rec.Code, rec.Body.String())
+			}
+			// another spec with the same id is refused
+			rec := post(tt.other)
+			if rec.Code != http.StatusConflict {
+				t.Fatalf("expected code %d, received %d: %s", http.StatusConflict, rec.Code, rec.Body.String())
+			}
+			out := struct {
+				Details []struct {
+					Reason string `json:"reason"`
+				} `json:"details"`
+			}{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+				t.Fatal(err)
+			}
+			if len(out.Details) == 0 || out.Details[0].Reason != apierrors.ReasonAlreadyExists {
+				t.Errorf("expected the reason %s, received %s", apierrors.ReasonAlreadyExists, rec.Body.String())
+			}
+		})
+	}
+}
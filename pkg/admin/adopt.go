@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/adopt"
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/remote"
+)
+
+// adoptionCollections are the collections of the resources by the kind they are adopted as
+var adoptionCollections = map[string]string{
+	adopt.KindVrf:           "vrfs",
+	adopt.KindLogicalBridge: "bridges",
+	adopt.KindSvi:           "svis",
+}
+
+// adoption is the json representation of the adoption of a kernel device
+type adoption struct {
+	Kind   string `json:"kind"`
+	Device string `json:"device"`
+	// Name is the full name of the resource, the response only
+	Name string `json:"name,omitempty"`
+	// Devices are the kernel devices taken over by the resource, the response only
+	Devices []string `json:"devices,omitempty"`
+}
+
+// adoptDevice imports a kernel device configured by hand as a resource, the id of the resource is the
+// name of the device unless it is given
+func adoptDevice(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	if config.GlobalConfig.Remote.Mode == remote.ModeController {
+		writeError(w, status.Error(codes.FailedPrecondition, "the devices are adopted on the node agents, the controller has none"))
+		return
+	}
+	in := &adoption{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	collection, ok := adoptionCollections[in.Kind]
+	if !ok {
+		writeError(w, status.Errorf(codes.InvalidArgument, "unknown kind %q, expected %s, %s or %s", in.Kind, adopt.KindVrf, adopt.KindLogicalBridge, adopt.KindSvi))
+		return
+	}
+	if in.Device == "" {
+		writeError(w, status.Error(codes.InvalidArgument, "missing device"))
+		return
+	}
+	resourceID := in.Device
+	if id := r.URL.Query().Get("id"); id != "" {
+		resourceID = id
+	}
+	if err := resourceid.ValidateUserSettable(resourceID); err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", resourceID, err))
+		return
+	}
+	out, err := adopt.Adopt(r.Context(), in.Kind, in.Device, fullName(collection, resourceID))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, &adoption{Kind: out.Kind, Device: in.Device, Name: out.Name, Devices: out.Devices})
+}
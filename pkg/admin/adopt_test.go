@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/remote"
+)
+
+func Test_AdoptDevice(t *testing.T) {
+	tests := map[string]struct {
+		in         adoption
+		id         string
+		controller bool
+		code       int
+	}{
+		"unknown kind": {
+			in:   adoption{Kind: "bridge-port", Device: "eth2"},
+			code: http.StatusBadRequest,
+		},
+		"missing device": {
+			in:   adoption{Kind: "vrf"},
+			code: http.StatusBadRequest,
+		},
+		"invalid id": {
+			in:   adoption{Kind: "vrf", Device: "blue"},
+			id:   "Blue_VRF",
+			code: http.StatusBadRequest,
+		},
+		"controller": {
+			in:         adoption{Kind: "vrf", Device: "blue"},
+			controller: true,
+			code:       http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			if tt.controller {
+				config.GlobalConfig.Remote.Mode = remote.ModeController
+				t.Cleanup(func() { config.GlobalConfig.Remote.Mode = "" })
+			}
+			body, _ := json.Marshal(tt.in)
+			url := "/v1/admin/adoptions"
+			if tt.id != "" {
+				url += "?id=" + tt.id
+			}
+			req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apply"
+	"github.com/opiproject/opi-evpn-bridge/pkg/schedule"
+)
+
+// maxManifestSize bounds the bundle accepted by the apply endpoint
+const maxManifestSize = 4 << 20
+
+// defaultTransactionTimeout bounds an atomic apply when no timeout is given
+const defaultTransactionTimeout = 60 * time.Second
+
+// applyResult is the json representation of the outcome of an apply
+type applyResult struct {
+	*apply.Plan
+	Applied    bool   `json:"applied"`
+	RolledBack bool   `json:"rolledBack,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Change names the change to confirm before RevertAt, when the apply has a confirm timeout
+	Change   string     `json:"change,omitempty"`
+	RevertAt *time.Time `json:"revertAt,omitempty"`
+}
+
+// RegisterApplyHandler registers the declarative apply endpoint, which converges the bridge through its gRPC API
+func RegisterApplyHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	return mux.HandlePath(http.MethodPost, "/v1/admin/apply", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		applyBundle(w, r, conn)
+	})
+}
+
+// applyBundle computes the plan of a YAML or JSON bundle, and runs it unless dry_run is set.
+// With atomic set the plan runs as a transaction, which with wait also covers the programming
+// of the objects, within the timeout. With confirm_timeout the transaction is reverted unless
+// it is confirmed in time.
+func applyBundle(w http.ResponseWriter, r *http.Request, conn grpc.ClientConnInterface) {
+	query := r.URL.Query()
+	atomic := query.Get("atomic") == "true"
+	wait := query.Get("wait") == "true"
+	if wait && !atomic {
+		writeError(w, status.Error(codes.InvalidArgument, "wait requires atomic"))
+		return
+	}
+	var confirmTimeout time.Duration
+	if value := query.Get("confirm_timeout"); value != "" {
+		var err error
+		if confirmTimeout, err = time.ParseDuration(value); err != nil || confirmTimeout <= 0 {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid confirm_timeout %q", value))
+			return
+		}
+		if !atomic {
+			writeError(w, status.Error(codes.InvalidArgument, "confirm_timeout requires atomic"))
+			return
+		}
+	}
+	timeout := defaultTransactionTimeout
+	if value := query.Get("timeout"); value != "" {
+		var err error
+		if timeout, err = time.ParseDuration(value); err != nil || timeout <= 0 {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid timeout %q", value))
+			return
+		}
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxManifestSize))
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "failed to read the bundle: %v", err))
+		return
+	}
+	bundle, err := apply.Parse(data)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "invalid bundle: %v", err))
+		return
+	}
+	plan, err := apply.NewPlan(r.Context(), conn, bundle, query.Get("prune") == "true")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := &applyResult{Plan: plan}
+	if query.Get("dry_run") != "true" {
+		if atomic {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			err = plan.ApplyAtomic(ctx, wait)
+			out.RolledBack = err != nil
+		} else {
+			// the changes done before a failure are kept, the plan tells which ones were attempted
+			err = plan.Apply(r.Context())
+		}
+		if err != nil {
+			out.Error = err.Error()
+			writeResponse(w, runtime.HTTPStatusFromCode(status.Code(err)), out)
+			return
+		}
+		out.Applied = true
+		if confirmTimeout > 0 {
+			if err := trackApply(plan, confirmTimeout, out); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+	}
+	writeResponse(w, http.StatusOK, out)
+}
+
+// trackApply has the scheduler revert the applied plan unless it is confirmed within the timeout
+func trackApply(plan *apply.Plan, timeout time.Duration, out *applyResult) error {
+	snapshot, err := plan.Snapshot()
+	if err != nil {
+		return err
+	}
+	change, err := schedule.Track(resourceid.NewSystemGenerated(), snapshot, timeout)
+	if err != nil {
+		return err
+	}
+	out.Change = change.Name
+	out.RevertAt = &change.RevertAt
+	return nil
+}
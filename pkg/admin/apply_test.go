@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/port"
+	"github.com/opiproject/opi-evpn-bridge/pkg/svi"
+	"github.com/opiproject/opi-evpn-bridge/pkg/vrf"
+)
+
+const testBundle = `
+apiVersion: evpn.opiproject.org/v1alpha1
+kind: LogicalBridge
+metadata:
+  name: blue-web
+spec:
+  vlanId: 10
+---
+apiVersion: evpn.opiproject.org/v1alpha1
+kind: Vrf
+metadata:
+  name: opi-vrf-a
+`
+
+func Test_ApplyBundle(t *testing.T) {
+	tests := map[string]struct {
+		query   string
+		bundle  string
+		code    int
+		applied bool
+		changes int
+		confirm bool
+	}{
+		"dry run": {
+			query:   "?dry_run=true",
+			bundle:  testBundle,
+			code:    http.StatusOK,
+			changes: 2,
+		},
+		"apply": {
+			bundle:  testBundle,
+			code:    http.StatusOK,
+			applied: true,
+			changes: 2,
+		},
+		"atomic": {
+			query:   "?atomic=true&timeout=10s",
+			bundle:  testBundle,
+			code:    http.StatusOK,
+			applied: true,
+			changes: 2,
+		},
+		"confirm timeout": {
+			query:   "?atomic=true&confirm_timeout=5m",
+			bundle:  testBundle,
+			code:    http.StatusOK,
+			applied: true,
+			changes: 2,
+			confirm: true,
+		},
+		"confirm timeout without atomic": {
+			query:  "?confirm_timeout=5m",
+			bundle: testBundle,
+			code:   http.StatusBadRequest,
+		},
+		"wait without atomic": {
+			query:  "?wait=true",
+			bundle: testBundle,
+			code:   http.StatusBadRequest,
+		},
+		"invalid timeout": {
+			query:  "?atomic=true&timeout=soon",
+			bundle: testBundle,
+			code:   http.StatusBadRequest,
+		},
+		"invalid bundle": {
+			bundle: "apiVersion: v1\nkind: Pod\n",
+			code:   http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			lis := bufconn.Listen(1024 * 1024)
+			s := grpc.NewServer()
+			pb.RegisterVrfServiceServer(s, vrf.NewServer())
+			pb.RegisterLogicalBridgeServiceServer(s, bridge.NewServer())
+			pb.RegisterSviServiceServer(s, svi.NewServer())
+			pb.RegisterBridgePortServiceServer(s, port.NewServer())
+			go func() { _ = s.Serve(lis) }()
+			defer s.Stop()
+			conn, err := grpc.Dial("bufnet",
+				grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+				grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			if err := RegisterApplyHandler(mux, conn); err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/apply"+tt.query, strings.NewReader(tt.bundle))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Fatalf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &applyResult{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Applied != tt.applied || len(out.Changes) != tt.changes || (out.Change != "") != tt.confirm {
+				t.Errorf("unexpected result %s", rec.Body.String())
+			}
+			if tt.confirm {
+				if c, err := infradb.GetScheduledChange(out.Change); err != nil || c.State != infradb.ScheduleAwaitingConfirmation {
+					t.Errorf("expected the change to await its confirmation, received %+v %v", c, err)
+				}
+			}
+			_, err = infradb.GetLB("//network.opiproject.org/bridges/blue-web")
+			if (err == nil) != tt.applied {
+				t.Errorf("unexpected logical bridge lookup result %v", err)
+			}
+		})
+	}
+}
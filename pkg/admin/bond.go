@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net/http"
+	"sort"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// bondMember is the json representation of the runtime state of a bond member
+type bondMember struct {
+	Name             string `json:"name"`
+	OperState        string `json:"oper_state"`
+	MiiStatus        string `json:"mii_status,omitempty"`
+	Active           bool   `json:"active"`
+	AggregatorID     uint16 `json:"aggregator_id,omitempty"`
+	PartnerPortState uint16 `json:"partner_port_state,omitempty"`
+}
+
+// bondState is the json representation of the runtime state of a bond
+type bondState struct {
+	OperState    string       `json:"oper_state"`
+	AggregatorID int          `json:"aggregator_id,omitempty"`
+	NumPorts     int          `json:"num_ports,omitempty"`
+	PartnerKey   int          `json:"partner_key,omitempty"`
+	PartnerMac   string       `json:"partner_mac,omitempty"`
+	Members      []bondMember `json:"members"`
+}
+
+// bond is the json representation of a bond
+type bond struct {
+	Name       string      `json:"name,omitempty"`
+	Mode       string      `json:"mode,omitempty"`
+	LacpRate   string      `json:"lacp_rate,omitempty"`
+	Members    []string    `json:"members"`
+	MinLinks   int         `json:"min_links,omitempty"`
+	OperStatus string      `json:"oper_status,omitempty"`
+	Components []component `json:"components,omitempty"`
+	State      *bondState  `json:"state,omitempty"`
+}
+
+// bondToJSON translates the domain object to its json representation
+func bondToJSON(b *infradb.Bond) *bond {
+	return &bond{
+		Name:       b.Name,
+		Mode:       b.Spec.Mode,
+		LacpRate:   b.Spec.LacpRate,
+		Members:    b.Spec.Members,
+		MinLinks:   b.Spec.MinLinks,
+		OperStatus: b.Status.OperStatus.String(),
+		Components: componentsToJSON(b.Status.Components),
+	}
+}
+
+// bondStateToJSON translates the runtime state of the bond to its json representation
+func bondStateToJSON(s *gen_linux.BondState) *bondState {
+	out := &bondState{
+		OperState:    s.OperState,
+		AggregatorID: s.AggregatorID,
+		NumPorts:     s.NumPorts,
+		PartnerKey:   s.PartnerKey,
+		PartnerMac:   s.PartnerMac,
+		Members:      []bondMember{},
+	}
+	for _, m := range s.Members {
+		out.Members = append(out.Members, bondMember{
+			Name:             m.Name,
+			OperState:        m.OperState,
+			MiiStatus:        m.MiiStatus,
+			Active:           m.Active,
+			AggregatorID:     m.AggregatorID,
+			PartnerPortState: m.PartnerPortState,
+		})
+	}
+	return out
+}
+
+// createBond creates a bond out of the member interfaces
+func createBond(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &bond{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// the resource id is used as the linux device name, a system generated one would not fit
+	resourceID := r.URL.Query().Get("id")
+	if resourceID == "" {
+		writeError(w, status.Errorf(codes.InvalidArgument, "id is required, it is the name of the linux bond device"))
+		return
+	}
+	if err := resourceid.ValidateUserSettable(resourceID); err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", resourceID, err))
+		return
+	}
+	if len(resourceID) > 15 {
+		writeError(w, status.Errorf(codes.InvalidArgument, "id %s is longer than a linux interface name", resourceID))
+		return
+	}
+	name := fullName("bonds", resourceID)
+	spec := &infradb.BondSpec{Mode: in.Mode, LacpRate: in.LacpRate, Members: in.Members, MinLinks: in.MinLinks}
+	b, err := infradb.NewBond(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetBond(name); err == nil {
+		if !sameSpec(b.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("bonds", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createBond(): Already existing Bond with id %v", name)
+		writeResponse(w, http.StatusOK, bondToJSON(existing))
+		return
+	}
+	if err := infradb.CreateBond(b); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, bondToJSON(b))
+}
+
+// getBond returns a bond together with its link and LACP partner state
+func getBond(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	b, err := infradb.GetBond(fullName("bonds", params["bond"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := bondToJSON(b)
+	if b.Status.OperStatus == infradb.OperStatusUp {
+		state, err := gen_linux.GetBondState(b.Name)
+		if err != nil {
+			log.Printf("getBond(): Failed to read the state of bond %s: %v", b.Name, err)
+		} else {
+			out.State = bondStateToJSON(state)
+		}
+	}
+	writeResponse(w, http.StatusOK, out)
+}
+
+// listBonds returns all the bonds
+func listBonds(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	bonds, err := infradb.GetAllBonds()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(bonds, func(i, j int) bool { return bonds[i].Name < bonds[j].Name })
+	out := []*bond{}
+	for _, b := range bonds {
+		out = append(out, bondToJSON(b))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"bonds": out})
+}
+
+// deleteBond deletes a bond
+func deleteBond(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteBond(fullName("bonds", params["bond"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_CreateBond(t *testing.T) {
+	tests := map[string]struct {
+		existing *bond
+		id       string
+		noID     bool
+		in       bond
+		code     int
+		mode     string
+	}{
+		"defaults to lacp": {
+			in:   bond{Members: []string{"eth2", "eth3"}},
+			code: http.StatusOK,
+			mode: "802.3ad",
+		},
+		"active backup": {
+			in:   bond{Mode: "active-backup", Members: []string{"eth2", "eth3"}, MinLinks: 1},
+			code: http.StatusOK,
+			mode: "active-backup",
+		},
+		"unknown mode": {
+			in:   bond{Mode: "round-robin", Members: []string{"eth2"}},
+			code: http.StatusBadRequest,
+		},
+		"unknown lacp rate": {
+			in:   bond{LacpRate: "medium", Members: []string{"eth2"}},
+			code: http.StatusBadRequest,
+		},
+		"no members": {
+			in:   bond{},
+			code: http.StatusBadRequest,
+		},
+		"duplicated member": {
+			in:   bond{Members: []string{"eth2", "eth2"}},
+			code: http.StatusBadRequest,
+		},
+		"min links above members": {
+			in:   bond{Members: []string{"eth2"}, MinLinks: 2},
+			code: http.StatusBadRequest,
+		},
+		"id too long for a linux interface": {
+			id:   "bond-with-a-long-name",
+			in:   bond{Members: []string{"eth2"}},
+			code: http.StatusBadRequest,
+		},
+		"missing id": {
+			noID: true,
+			in:   bond{Members: []string{"eth2"}},
+			code: http.StatusBadRequest,
+		},
+		"member of another bond": {
+			existing: &bond{Members: []string{"eth3"}},
+			in:       bond{Members: []string{"eth2", "eth3"}},
+			code:     http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			if tt.existing != nil {
+				body, _ := json.Marshal(tt.existing)
+				req := httptest.NewRequest(http.MethodPost, "/v1/admin/bonds?id=bond1", bytes.NewReader(body))
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("failed to create existing bond: %s", rec.Body.String())
+				}
+			}
+			id := tt.id
+			if id == "" {
+				id = "bond0"
+			}
+
+			url := "/v1/admin/bonds?id=" + id
+			if tt.noID {
+				url = "/v1/admin/bonds"
+			}
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &bond{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("bonds", id) || out.Mode != tt.mode || out.OperStatus != "DOWN" {
+				t.Errorf("unexpected bond %+v", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ci_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxCIModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// portStats is the json representation of the bridge port counters
+type portStats struct {
+	RxPackets uint64 `json:"rx_packets"`
+	TxPackets uint64 `json:"tx_packets"`
+	RxBytes   uint64 `json:"rx_bytes"`
+	TxBytes   uint64 `json:"tx_bytes"`
+	RxErrors  uint64 `json:"rx_errors"`
+	TxErrors  uint64 `json:"tx_errors"`
+	RxDropped uint64 `json:"rx_dropped"`
+	TxDropped uint64 `json:"tx_dropped"`
+}
+
+// getBridgePortStats returns the kernel counters of a bridge port
+func getBridgePortStats(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	name := fullName("ports", params["bridgeport"])
+	bp, err := infradb.GetBP(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if bp.Status.BPOperStatus != infradb.BridgePortOperStatusUp {
+		writeError(w, status.Errorf(codes.FailedPrecondition, "bridge port %s is not operationally up", name))
+		return
+	}
+	stats, err := ci_linux.GetBridgePortStats(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, &portStats{
+		RxPackets: stats.RxPackets,
+		TxPackets: stats.TxPackets,
+		RxBytes:   stats.RxBytes,
+		TxBytes:   stats.TxBytes,
+		RxErrors:  stats.RxErrors,
+		TxErrors:  stats.TxErrors,
+		RxDropped: stats.RxDropped,
+		TxDropped: stats.TxDropped,
+	})
+}
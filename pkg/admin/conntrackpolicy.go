@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// conntrackPolicy is the json representation of a conntrack policy
+type conntrackPolicy struct {
+	Name           string `json:"name,omitempty"`
+	Svi            string `json:"svi"`
+	MaxConnections uint32 `json:"max_connections,omitempty"`
+	// TCPTimeouts and UDPTimeouts are in seconds by state
+	TCPTimeouts        map[string]uint32 `json:"tcp_timeouts,omitempty"`
+	UDPTimeouts        map[string]uint32 `json:"udp_timeouts,omitempty"`
+	OperStatus         string            `json:"oper_status,omitempty"`
+	Components         []component       `json:"components,omitempty"`
+	DroppedConnections *uint64           `json:"dropped_connections,omitempty"`
+}
+
+// timeoutsToJSON converts the timeouts by state to seconds
+func timeoutsToJSON(in map[string]time.Duration) map[string]uint32 {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string]uint32, len(in))
+	for state, timeout := range in {
+		out[state] = uint32(timeout / time.Second)
+	}
+	return out
+}
+
+// timeoutsFromJSON converts the timeouts by state from seconds
+func timeoutsFromJSON(in map[string]uint32) map[string]time.Duration {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string]time.Duration, len(in))
+	for state, timeout := range in {
+		out[state] = time.Duration(timeout) * time.Second
+	}
+	return out
+}
+
+// conntrackPolicyToJSON translates the domain object to its json representation
+func conntrackPolicyToJSON(ctp *infradb.ConntrackPolicy) *conntrackPolicy {
+	return &conntrackPolicy{
+		Name:           ctp.Name,
+		Svi:            ctp.Spec.Svi,
+		MaxConnections: ctp.Spec.MaxConnections,
+		TCPTimeouts:    timeoutsToJSON(ctp.Spec.TCPTimeouts),
+		UDPTimeouts:    timeoutsToJSON(ctp.Spec.UDPTimeouts),
+		OperStatus:     ctp.Status.OperStatus.String(),
+		Components:     componentsToJSON(ctp.Status.Components),
+	}
+}
+
+// createConntrackPolicy creates a conntrack policy for the subnet of an svi
+func createConntrackPolicy(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &conntrackPolicy{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("conntrackpolicies", resourceID)
+	ctp, err := infradb.NewConntrackPolicy(name, &infradb.ConntrackPolicySpec{
+		Svi:            in.Svi,
+		MaxConnections: in.MaxConnections,
+		TCPTimeouts:    timeoutsFromJSON(in.TCPTimeouts),
+		UDPTimeouts:    timeoutsFromJSON(in.UDPTimeouts),
+	})
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetConntrackPolicy(name); err == nil {
+		if !sameSpec(ctp.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("conntrackpolicies", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createConntrackPolicy(): Already existing Conntrack Policy with id %v", name)
+		writeResponse(w, http.StatusOK, conntrackPolicyToJSON(existing))
+		return
+	}
+	if err := infradb.CreateConntrackPolicy(ctp); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, conntrackPolicyToJSON(ctp))
+}
+
+// getConntrackPolicy returns a conntrack policy, with its dropped connections once it is operationally up
+func getConntrackPolicy(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	name := fullName("conntrackpolicies", params["conntrackpolicy"])
+	ctp, err := infradb.GetConntrackPolicy(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := conntrackPolicyToJSON(ctp)
+	if ctp.Status.OperStatus == infradb.OperStatusUp {
+		stats, err := gen_linux.GetConntrackPolicyStats(name)
+		if err != nil {
+			log.Printf("getConntrackPolicy(): Failed to read the counters of %s: %v", name, err)
+		} else {
+			out.DroppedConnections = &stats.DroppedConnections
+		}
+	}
+	writeResponse(w, http.StatusOK, out)
+}
+
+// listConntrackPolicies returns all the conntrack policies
+func listConntrackPolicies(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	ctps, err := infradb.GetAllConntrackPolicies()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(ctps, func(i, j int) bool { return ctps[i].Name < ctps[j].Name })
+	out := []*conntrackPolicy{}
+	for _, ctp := range ctps {
+		out = append(out, conntrackPolicyToJSON(ctp))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"conntrack_policies": out})
+}
+
+// deleteConntrackPolicy deletes a conntrack policy
+func deleteConntrackPolicy(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteConntrackPolicy(fullName("conntrackpolicies", params["conntrackpolicy"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_CreateConntrackPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in   conntrackPolicy
+		code int
+	}{
+		"limit and timeouts": {
+			in:   conntrackPolicy{Svi: fullName("svis", "web"), MaxConnections: 10000, TCPTimeouts: map[string]uint32{"established": 3600}, UDPTimeouts: map[string]uint32{"replied": 60}},
+			code: http.StatusOK,
+		},
+		"limit only": {
+			in:   conntrackPolicy{Svi: fullName("svis", "web"), MaxConnections: 10000},
+			code: http.StatusOK,
+		},
+		"nothing to enforce": {
+			in:   conntrackPolicy{Svi: fullName("svis", "web")},
+			code: http.StatusBadRequest,
+		},
+		"unknown tcp state": {
+			in:   conntrackPolicy{Svi: fullName("svis", "web"), TCPTimeouts: map[string]uint32{"open": 3600}},
+			code: http.StatusBadRequest,
+		},
+		"zero timeout": {
+			in:   conntrackPolicy{Svi: fullName("svis", "web"), UDPTimeouts: map[string]uint32{"unreplied": 0}},
+			code: http.StatusBadRequest,
+		},
+		"unknown svi": {
+			in:   conntrackPolicy{Svi: fullName("svis", "unknown"), MaxConnections: 10000},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			createTestSvi(t)
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/conntrackpolicies?id=web-ct", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &conntrackPolicy{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("conntrackpolicies", "web-ct") || out.OperStatus != "DOWN" ||
+				!reflect.DeepEqual(out.TCPTimeouts, tt.in.TCPTimeouts) || !reflect.DeepEqual(out.UDPTimeouts, tt.in.UDPTimeouts) {
+				t.Errorf("unexpected conntrack policy %+v", out)
+			}
+
+			// A second conntrack policy of the same subnet is refused, and the svi is kept
+			req = httptest.NewRequest(http.MethodPost, "/v1/admin/conntrackpolicies?id=other", bytes.NewReader(body))
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected a second conntrack policy to fail with %d, received %d", http.StatusBadRequest, rec.Code)
+			}
+			if err := infradb.DeleteSvi(fullName("svis", "web")); err != infradb.ErrSviInUse {
+				t.Errorf("expected the svi to be in use, received %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// graphNode is the json representation of a resource, or of a kernel device, of the dependency graph
+type graphNode struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	Up   bool   `json:"up,omitempty"`
+}
+
+// graphEdge is the json representation of a dependency between two nodes
+type graphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+}
+
+// dependencyGraph is the json representation of the dependency graph
+type dependencyGraph struct {
+	Nodes []*graphNode `json:"nodes"`
+	Edges []*graphEdge `json:"edges"`
+}
+
+// getDependencyGraph returns the references between the resources and the kernel devices they are
+// programmed as. With the root query parameter, the full name of a resource, only the resource and what
+// depends on it are returned, which is what breaks when the resource is deleted.
+func getDependencyGraph(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	graph, err := infradb.GetDependencyGraph()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if root := r.URL.Query().Get("root"); root != "" {
+		if graph, err = graph.Dependents(root); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	out := &dependencyGraph{Nodes: []*graphNode{}, Edges: []*graphEdge{}}
+	for _, n := range graph.Nodes {
+		out.Nodes = append(out.Nodes, &graphNode{ID: n.ID, Kind: n.Kind, Up: n.Up})
+	}
+	for _, e := range graph.Edges {
+		out.Edges = append(out.Edges, &graphEdge{From: e.From, To: e.To, Relation: e.Relation})
+	}
+	writeResponse(w, http.StatusOK, out)
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_GetDependencyGraph(t *testing.T) {
+	mux := newTestMux(t)
+	createTestSvi(t)
+	createTestBridgePort(t)
+	body, _ := json.Marshal(conntrackPolicy{Svi: fullName("svis", "web"), MaxConnections: 100})
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/conntrackpolicies?id=web-ct", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("failed to create the conntrack policy: %s", rec.Body.String())
+	}
+
+	getGraph := func(query string) (*dependencyGraph, int) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/dependencygraph"+query, nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		graph := &dependencyGraph{}
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), graph); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return graph, rec.Code
+	}
+	edges := func(graph *dependencyGraph) map[graphEdge]bool {
+		out := map[graphEdge]bool{}
+		for _, e := range graph.Edges {
+			out[*e] = true
+		}
+		return out
+	}
+
+	graph, code := getGraph("")
+	if code != http.StatusOK {
+		t.Fatalf("expected code %d, received %d", http.StatusOK, code)
+	}
+	all := edges(graph)
+	for _, expected := range []graphEdge{
+		{From: fullName("svis", "web"), To: testVrfA, Relation: "references"},
+		{From: fullName("svis", "web"), To: fullName("bridges", "web"), Relation: "references"},
+		{From: fullName("svis", "web"), To: "netdev:opi-vrf-a-10", Relation: "programmed-as"},
+		{From: fullName("conntrackpolicies", "web-ct"), To: fullName("svis", "web"), Relation: "references"},
+		{From: testBridgePort, To: fullName("bridges", "psec"), Relation: "references"},
+		{From: testBridgePort, To: "netdev:eth2", Relation: "programmed-as"},
+		{From: testVrfA, To: "netdev:opi-vrf-a", Relation: "programmed-as"},
+		{From: fullName("bridges", "web"), To: "netdev:br-tenant", Relation: "programmed-as"},
+	} {
+		if !all[expected] {
+			t.Errorf("expected the edge %+v in %+v", expected, graph.Edges)
+		}
+	}
+
+	// Deleting the vrf breaks the svi and its conntrack policy, not the bridges
+	graph, code = getGraph("?root=" + testVrfA)
+	if code != http.StatusOK {
+		t.Fatalf("expected code %d, received %d", http.StatusOK, code)
+	}
+	nodes := map[string]string{}
+	for _, n := range graph.Nodes {
+		nodes[n.ID] = n.Kind
+	}
+	expected := map[string]string{
+		testVrfA:                                "vrf",
+		"netdev:opi-vrf-a":                      "netdev",
+		fullName("svis", "web"):                 "svi",
+		"netdev:opi-vrf-a-10":                   "netdev",
+		fullName("conntrackpolicies", "web-ct"): "conntrack-policy",
+	}
+	if len(nodes) != len(expected) {
+		t.Errorf("expected the nodes %v, received %v", expected, nodes)
+	}
+	for id, kind := range expected {
+		if nodes[id] != kind {
+			t.Errorf("expected the node %s of kind %s, received %v", id, kind, nodes)
+		}
+	}
+	if edges(graph)[graphEdge{From: fullName("svis", "web"), To: fullName("bridges", "web"), Relation: "references"}] {
+		t.Error("expected no edge to the logical bridge outside of the dependents")
+	}
+
+	if _, code = getGraph("?root=" + fullName("vrfs", "unknown")); code != http.StatusNotFound {
+		t.Errorf("expected code %d for an unknown root, received %d", http.StatusNotFound, code)
+	}
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// dlink is the devlink client of the admin endpoints
+var dlink utils.Devlink = utils.NewDevlinkWrapperWithArgs(false)
+
+// devlinkResource is the json representation of a hardware table of a devlink device
+type devlinkResource struct {
+	Path      string  `json:"path"`
+	Size      uint64  `json:"size"`
+	Occupancy *uint64 `json:"occupancy,omitempty"`
+	Unit      string  `json:"unit,omitempty"`
+}
+
+// devlinkDevice is the json representation of a devlink device
+type devlinkDevice struct {
+	Bus         string            `json:"bus"`
+	Device      string            `json:"device"`
+	EswitchMode string            `json:"eswitch_mode,omitempty"`
+	Resources   []devlinkResource `json:"resources"`
+}
+
+// eswitch is the json representation of the eswitch of a devlink device
+type eswitch struct {
+	Mode string `json:"mode"`
+}
+
+// listDevlinkDevices returns the devlink devices with their eswitch mode and the occupancy of their hardware tables
+func listDevlinkDevices(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	devs, err := dlink.DeviceList(r.Context())
+	if err != nil {
+		writeError(w, status.Errorf(codes.Unavailable, "failed to list the devlink devices: %v", err))
+		return
+	}
+	out := []devlinkDevice{}
+	for _, dev := range devs {
+		d := devlinkDevice{Bus: dev.Bus, Device: dev.Device, EswitchMode: dev.EswitchMode, Resources: []devlinkResource{}}
+		resources, err := dlink.Resources(r.Context(), dev.Bus, dev.Device)
+		if err != nil {
+			// most devices have no resources
+			log.Printf("listDevlinkDevices(): No resources for %s/%s: %v", dev.Bus, dev.Device, err)
+		}
+		for _, res := range resources {
+			dr := devlinkResource{Path: res.Path, Size: res.Size, Unit: res.Unit}
+			if res.OccupancyValid {
+				occupancy := res.Occupancy
+				dr.Occupancy = &occupancy
+			}
+			d.Resources = append(d.Resources, dr)
+		}
+		out = append(out, d)
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"devices": out})
+}
+
+// setEswitchMode switches the eswitch of a devlink device between the legacy and switchdev modes,
+// the switchdev mode is kept while VF representors exist
+func setEswitchMode(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &eswitch{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	switch in.Mode {
+	case "switchdev":
+	case "legacy":
+		reps, err := infradb.GetAllVfRepresentors()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if len(reps) != 0 {
+			writeError(w, status.Errorf(codes.FailedPrecondition, "the VF representor %s needs the switchdev mode", reps[0].Name))
+			return
+		}
+	default:
+		writeError(w, status.Errorf(codes.InvalidArgument, "eswitch mode %q is not supported", in.Mode))
+		return
+	}
+	// Example: devlink dev eswitch set pci/0000:03:00.0 mode switchdev
+	if err := dlink.SetEswitchMode(r.Context(), params["bus"], params["device"], in.Mode); err != nil {
+		writeError(w, status.Errorf(codes.Unavailable, "failed to set the eswitch mode of %s/%s: %v", params["bus"], params["device"], err))
+		return
+	}
+	log.Printf("setEswitchMode(): Executed devlink dev eswitch set %s/%s mode %s", params["bus"], params["device"], in.Mode)
+	writeResponse(w, http.StatusOK, in)
+}
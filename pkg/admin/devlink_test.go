@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// fakeDevlink is a devlink client with one switchdev device and an FDB table
+type fakeDevlink struct {
+	mode      string
+	occupancy uint64
+}
+
+func (f *fakeDevlink) DeviceList(context.Context) ([]utils.DevlinkDevice, error) {
+	return []utils.DevlinkDevice{{Bus: "pci", Device: "0000:03:00.0", EswitchMode: f.mode}}, nil
+}
+
+func (f *fakeDevlink) SetEswitchMode(_ context.Context, _ string, _ string, mode string) error {
+	f.mode = mode
+	return nil
+}
+
+func (f *fakeDevlink) Resources(context.Context, string, string) ([]utils.DevlinkResourceUsage, error) {
+	return []utils.DevlinkResourceUsage{{Path: "/fdb", Size: 100, Occupancy: f.occupancy, OccupancyValid: true, Unit: "entry"}}, nil
+}
+
+// useFakeDevlink replaces the devlink client of the admin endpoints for the test
+func useFakeDevlink(t *testing.T, f *fakeDevlink) {
+	saved := dlink
+	dlink = f
+	t.Cleanup(func() { dlink = saved })
+}
+
+func Test_ListDevlinkDevices(t *testing.T) {
+	mux := newTestMux(t)
+	useFakeDevlink(t, &fakeDevlink{mode: "switchdev", occupancy: 42})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/devlink/devices", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	out := struct {
+		Devices []devlinkDevice `json:"devices"`
+	}{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Devices) != 1 || out.Devices[0].EswitchMode != "switchdev" || len(out.Devices[0].Resources) != 1 {
+		t.Fatalf("unexpected devices %+v", out.Devices)
+	}
+	if res := out.Devices[0].Resources[0]; res.Occupancy == nil || *res.Occupancy != 42 {
+		t.Errorf("expected an occupancy of 42, got %+v", res)
+	}
+}
+
+func Test_SetEswitchMode(t *testing.T) {
+	tests := map[string]struct {
+		mode           string
+		vfRepresentors bool
+		code           int
+	}{
+		"switchdev": {
+			mode: "switchdev",
+			code: http.StatusOK,
+		},
+		"legacy": {
+			mode: "legacy",
+			code: http.StatusOK,
+		},
+		"legacy with vf representors": {
+			mode:           "legacy",
+			vfRepresentors: true,
+			code:           http.StatusBadRequest,
+		},
+		"unknown mode": {
+			mode: "bridge",
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			f := &fakeDevlink{mode: "switchdev"}
+			useFakeDevlink(t, f)
+			if tt.vfRepresentors {
+				body, _ := json.Marshal(vfRepresentor{PF: "p0", VF: 3})
+				req := httptest.NewRequest(http.MethodPost, "/v1/admin/vfrepresentors?id=vm1-vf3", bytes.NewReader(body))
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("failed to create the VF representor: %s", rec.Body.String())
+				}
+			}
+
+			body, _ := json.Marshal(eswitch{Mode: tt.mode})
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/devlink/devices/pci/0000:03:00.0/eswitch", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Fatalf("expected code %d, got %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if tt.code == http.StatusOK && f.mode != tt.mode {
+				t.Errorf("expected eswitch mode %s, got %s", tt.mode, f.mode)
+			}
+		})
+	}
+}
+
+func Test_CreateVfRepresentorTableFull(t *testing.T) {
+	mux := newTestMux(t)
+	useFakeDevlink(t, &fakeDevlink{mode: "switchdev", occupancy: 95})
+	saved := config.GlobalConfig.Devlink
+	config.GlobalConfig.Devlink.OccupancyThreshold = 90
+	t.Cleanup(func() { config.GlobalConfig.Devlink = saved })
+
+	body, _ := json.Marshal(vfRepresentor{PF: "p0", VF: 3})
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/vfrepresentors?id=vm1-vf3", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code == http.StatusOK {
+		t.Fatalf("expected the VF representor to be refused, got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// dhcpReservation is the json representation of the address reserved to a bridge port
+type dhcpReservation struct {
+	BridgePort string `json:"bridge_port"`
+	IP         string `json:"ip"`
+}
+
+// dhcpLease is the json representation of an address leased by a dhcp server
+type dhcpLease struct {
+	IP         string     `json:"ip"`
+	MacAddress string     `json:"mac_address,omitempty"`
+	Hostname   string     `json:"hostname,omitempty"`
+	ClientID   string     `json:"client_id,omitempty"`
+	Expires    *time.Time `json:"expires,omitempty"`
+}
+
+// dhcpServer is the json representation of a dhcp server
+type dhcpServer struct {
+	Name         string            `json:"name,omitempty"`
+	Svi          string            `json:"svi"`
+	Subnet       string            `json:"subnet"`
+	RangeStart   string            `json:"range_start"`
+	RangeEnd     string            `json:"range_end"`
+	LeaseTime    uint32            `json:"lease_time,omitempty"`
+	Reservations []dhcpReservation `json:"reservations,omitempty"`
+	DNSServers   []string          `json:"dns_servers,omitempty"`
+	Mtu          uint32            `json:"mtu,omitempty"`
+	OperStatus   string            `json:"oper_status,omitempty"`
+	Components   []component       `json:"components,omitempty"`
+	Leases       []dhcpLease       `json:"leases,omitempty"`
+}
+
+// dhcpServerToJSON translates the domain object to its json representation
+func dhcpServerToJSON(dhcp *infradb.DHCPServer) *dhcpServer {
+	out := &dhcpServer{
+		Name:       dhcp.Name,
+		Svi:        dhcp.Spec.Svi,
+		Subnet:     dhcp.Spec.Subnet.String(),
+		RangeStart: dhcp.Spec.RangeStart.String(),
+		RangeEnd:   dhcp.Spec.RangeEnd.String(),
+		LeaseTime:  dhcp.Spec.LeaseTime,
+		DNSServers: ipsToJSON(dhcp.Spec.DNSServers),
+		Mtu:        dhcp.Spec.Mtu,
+		OperStatus: dhcp.Status.OperStatus.String(),
+		Components: componentsToJSON(dhcp.Status.Components),
+	}
+	for _, r := range dhcp.Spec.Reservations {
+		out.Reservations = append(out.Reservations, dhcpReservation{BridgePort: r.BridgePort, IP: r.IP.String()})
+	}
+	return out
+}
+
+// dhcpServerSpecFromJSON translates the json representation to the domain spec
+func dhcpServerSpecFromJSON(in *dhcpServer) (*infradb.DHCPServerSpec, error) {
+	spec := &infradb.DHCPServerSpec{Svi: in.Svi, LeaseTime: in.LeaseTime, Mtu: in.Mtu}
+	_, subnet, err := net.ParseCIDR(in.Subnet)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid subnet %s", in.Subnet)
+	}
+	spec.Subnet = subnet
+	if spec.RangeStart = net.ParseIP(in.RangeStart); spec.RangeStart == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid range start %s", in.RangeStart)
+	}
+	if spec.RangeEnd = net.ParseIP(in.RangeEnd); spec.RangeEnd == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid range end %s", in.RangeEnd)
+	}
+	if spec.DNSServers, err = ipsFromJSON(in.DNSServers); err != nil {
+		return nil, err
+	}
+	for _, r := range in.Reservations {
+		ip := net.ParseIP(r.IP)
+		if ip == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid ip %s", r.IP)
+		}
+		spec.Reservations = append(spec.Reservations, &infradb.DHCPReservation{BridgePort: r.BridgePort, IP: ip})
+	}
+	return spec, nil
+}
+
+// createDHCPServer creates a dhcp server for a subnet of an svi
+func createDHCPServer(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &dhcpServer{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("dhcpservers", resourceID)
+	spec, err := dhcpServerSpecFromJSON(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	dhcp, err := infradb.NewDHCPServer(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetDHCPServer(name); err == nil {
+		if !sameSpec(dhcp.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("dhcpservers", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createDHCPServer(): Already existing DHCP Server with id %v", name)
+		writeResponse(w, http.StatusOK, dhcpServerToJSON(existing))
+		return
+	}
+	if err := infradb.CreateDHCPServer(dhcp); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, dhcpServerToJSON(dhcp))
+}
+
+// getDHCPServer returns a dhcp server, with its leases once it is operationally up
+func getDHCPServer(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	name := fullName("dhcpservers", params["dhcpserver"])
+	dhcp, err := infradb.GetDHCPServer(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := dhcpServerToJSON(dhcp)
+	if dhcp.Status.OperStatus == infradb.OperStatusUp {
+		leases, err := gen_linux.GetDHCPLeases(name)
+		if err != nil {
+			log.Printf("getDHCPServer(): Failed to read the leases of %s: %v", name, err)
+		}
+		for _, l := range leases {
+			out.Leases = append(out.Leases, dhcpLease{
+				IP:         l.IP.String(),
+				MacAddress: l.MacAddress,
+				Hostname:   l.Hostname,
+				ClientID:   l.ClientID,
+				Expires:    timeToJSON(l.Expires),
+			})
+		}
+	}
+	writeResponse(w, http.StatusOK, out)
+}
+
+// listDHCPServers returns all the dhcp servers
+func listDHCPServers(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	dhcps, err := infradb.GetAllDHCPServers()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(dhcps, func(i, j int) bool { return dhcps[i].Name < dhcps[j].Name })
+	out := []*dhcpServer{}
+	for _, dhcp := range dhcps {
+		out = append(out, dhcpServerToJSON(dhcp))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"dhcp_servers": out})
+}
+
+// deleteDHCPServer deletes a dhcp server
+func deleteDHCPServer(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteDHCPServer(fullName("dhcpservers", params["dhcpserver"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_CreateDHCPServer(t *testing.T) {
+	svi := fullName("svis", "web")
+	tests := map[string]struct {
+		existing *dhcpServer
+		in       dhcpServer
+		code     int
+	}{
+		"range only": {
+			in:   dhcpServer{Svi: svi, Subnet: "10.0.0.0/29", RangeStart: "10.0.0.2", RangeEnd: "10.0.0.6"},
+			code: http.StatusOK,
+		},
+		"reservation and options": {
+			in: dhcpServer{Svi: svi, Subnet: "10.0.0.0/29", RangeStart: "10.0.0.4", RangeEnd: "10.0.0.6",
+				Reservations: []dhcpReservation{{BridgePort: testBridgePort, IP: "10.0.0.2"}},
+				DNSServers:   []string{"10.0.0.1"}, Mtu: 1450},
+			code: http.StatusOK,
+		},
+		"range out of the subnet": {
+			in:   dhcpServer{Svi: svi, Subnet: "10.0.0.0/29", RangeStart: "10.0.0.2", RangeEnd: "10.0.0.20"},
+			code: http.StatusBadRequest,
+		},
+		"reversed range": {
+			in:   dhcpServer{Svi: svi, Subnet: "10.0.0.0/29", RangeStart: "10.0.0.6", RangeEnd: "10.0.0.2"},
+			code: http.StatusBadRequest,
+		},
+		"short lease time": {
+			in:   dhcpServer{Svi: svi, Subnet: "10.0.0.0/29", RangeStart: "10.0.0.2", RangeEnd: "10.0.0.6", LeaseTime: 60},
+			code: http.StatusBadRequest,
+		},
+		"not a subnet of the svi": {
+			in:   dhcpServer{Svi: svi, Subnet: "10.0.1.0/29", RangeStart: "10.0.1.2", RangeEnd: "10.0.1.6"},
+			code: http.StatusBadRequest,
+		},
+		"unknown svi": {
+			in:   dhcpServer{Svi: fullName("svis", "unknown"), Subnet: "10.0.0.0/29", RangeStart: "10.0.0.2", RangeEnd: "10.0.0.6"},
+			code: http.StatusNotFound,
+		},
+		"unknown bridge port": {
+			in: dhcpServer{Svi: svi, Subnet: "10.0.0.0/29", RangeStart: "10.0.0.4", RangeEnd: "10.0.0.6",
+				Reservations: []dhcpReservation{{BridgePort: fullName("ports", "unknown"), IP: "10.0.0.2"}}},
+			code: http.StatusNotFound,
+		},
+		"subnet already has a server": {
+			existing: &dhcpServer{Svi: svi, Subnet: "10.0.0.0/29", RangeStart: "10.0.0.2", RangeEnd: "10.0.0.6"},
+			in:       dhcpServer{Svi: svi, Subnet: "10.0.0.0/29", RangeStart: "10.0.0.3", RangeEnd: "10.0.0.6"},
+			code:     http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			createTestSvi(t)
+			createTestBridgePort(t)
+			if tt.existing != nil {
+				body, _ := json.Marshal(tt.existing)
+				req := httptest.NewRequest(http.MethodPost, "/v1/admin/dhcpservers?id=existing-dhcp", bytes.NewReader(body))
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("failed to create existing dhcp server: %s", rec.Body.String())
+				}
+			}
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/dhcpservers?id=opi-dhcp", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &dhcpServer{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("dhcpservers", "opi-dhcp") || out.Svi != svi || out.LeaseTime != 3600 || out.OperStatus != "DOWN" {
+				t.Errorf("unexpected dhcp server %+v", out)
+			}
+		})
+	}
+}
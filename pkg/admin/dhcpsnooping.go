@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// dhcpSnooping is the json representation of a dhcp snooping
+type dhcpSnooping struct {
+	Name          string      `json:"name,omitempty"`
+	LogicalBridge string      `json:"logical_bridge"`
+	TrustedPorts  []string    `json:"trusted_ports,omitempty"`
+	SourceGuard   bool        `json:"source_guard,omitempty"`
+	OperStatus    string      `json:"oper_status,omitempty"`
+	Components    []component `json:"components,omitempty"`
+}
+
+// dhcpBinding is the json representation of an address handed out to a MAC address behind a bridge port
+type dhcpBinding struct {
+	BridgePort string `json:"bridge_port"`
+	MacAddress string `json:"mac_address"`
+	IP         string `json:"ip"`
+	// Expires is left out for an infinite lease
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// dhcpSnoopingToJSON translates the domain object to its json representation
+func dhcpSnoopingToJSON(snooping *infradb.DHCPSnooping) *dhcpSnooping {
+	return &dhcpSnooping{
+		Name:          snooping.Name,
+		LogicalBridge: snooping.Spec.LogicalBridge,
+		TrustedPorts:  snooping.Spec.TrustedPorts,
+		SourceGuard:   snooping.Spec.SourceGuard,
+		OperStatus:    snooping.Status.OperStatus.String(),
+		Components:    componentsToJSON(snooping.Status.Components),
+	}
+}
+
+// createDHCPSnooping snoops the DHCP messages of a logical bridge
+func createDHCPSnooping(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &dhcpSnooping{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("dhcpsnoopings", resourceID)
+	snooping, err := infradb.NewDHCPSnooping(name, &infradb.DHCPSnoopingSpec{
+		LogicalBridge: in.LogicalBridge,
+		TrustedPorts:  in.TrustedPorts,
+		SourceGuard:   in.SourceGuard,
+	})
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetDHCPSnooping(name); err == nil {
+		if !sameSpec(snooping.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("dhcpsnoopings", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createDHCPSnooping(): Already existing DHCP Snooping with id %v", name)
+		writeResponse(w, http.StatusOK, dhcpSnoopingToJSON(existing))
+		return
+	}
+	if err := infradb.CreateDHCPSnooping(snooping); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, dhcpSnoopingToJSON(snooping))
+}
+
+// getDHCPSnooping returns a dhcp snooping
+func getDHCPSnooping(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	snooping, err := infradb.GetDHCPSnooping(fullName("dhcpsnoopings", params["dhcpsnooping"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, dhcpSnoopingToJSON(snooping))
+}
+
+// listDHCPSnoopings returns all the dhcp snoopings
+func listDHCPSnoopings(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	snoopings, err := infradb.GetAllDHCPSnoopings()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(snoopings, func(i, j int) bool { return snoopings[i].Name < snoopings[j].Name })
+	out := []*dhcpSnooping{}
+	for _, snooping := range snoopings {
+		out = append(out, dhcpSnoopingToJSON(snooping))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"dhcp_snoopings": out})
+}
+
+// deleteDHCPSnooping deletes a dhcp snooping, its bindings are lost
+func deleteDHCPSnooping(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteDHCPSnooping(fullName("dhcpsnoopings", params["dhcpsnooping"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
+
+// listDHCPBindings returns the bindings learnt by a dhcp snooping
+func listDHCPBindings(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	name := fullName("dhcpsnoopings", params["dhcpsnooping"])
+	snooping, err := infradb.GetDHCPSnooping(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if snooping.Status.OperStatus != infradb.OperStatusUp {
+		writeError(w, status.Errorf(codes.FailedPrecondition, "dhcp snooping %s is not operationally up", name))
+		return
+	}
+	bindings, err := gen_linux.GetDHCPSnoopingBindings(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := []*dhcpBinding{}
+	for _, b := range bindings {
+		out = append(out, &dhcpBinding{
+			BridgePort: b.BridgePort,
+			MacAddress: b.Mac.String(),
+			IP:         b.IP.String(),
+			Expires:    timeToJSON(b.Expires),
+		})
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"bindings": out})
+}
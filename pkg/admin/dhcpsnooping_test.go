@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_CreateDHCPSnooping(t *testing.T) {
+	tests := map[string]struct {
+		in   dhcpSnooping
+		code int
+	}{
+		"source guard": {
+			in:   dhcpSnooping{LogicalBridge: fullName("bridges", "psec"), SourceGuard: true},
+			code: http.StatusOK,
+		},
+		"trusted server port": {
+			in:   dhcpSnooping{LogicalBridge: fullName("bridges", "psec"), TrustedPorts: []string{testBridgePort}},
+			code: http.StatusOK,
+		},
+		"duplicated trusted port": {
+			in:   dhcpSnooping{LogicalBridge: fullName("bridges", "psec"), TrustedPorts: []string{testBridgePort, testBridgePort}},
+			code: http.StatusBadRequest,
+		},
+		"unknown logical bridge": {
+			in:   dhcpSnooping{LogicalBridge: fullName("bridges", "unknown")},
+			code: http.StatusNotFound,
+		},
+		"unknown trusted port": {
+			in:   dhcpSnooping{LogicalBridge: fullName("bridges", "psec"), TrustedPorts: []string{fullName("ports", "unknown")}},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			createTestBridgePort(t)
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/dhcpsnoopings?id=psec-snoop", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &dhcpSnooping{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("dhcpsnoopings", "psec-snoop") || out.OperStatus != "DOWN" || out.SourceGuard != tt.in.SourceGuard {
+				t.Errorf("unexpected dhcp snooping %+v", out)
+			}
+
+			// A second dhcp snooping of the same logical bridge is refused
+			req = httptest.NewRequest(http.MethodPost, "/v1/admin/dhcpsnoopings?id=other", bytes.NewReader(body))
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected a second dhcp snooping to fail with %d, received %d", http.StatusBadRequest, rec.Code)
+			}
+			if err := infradb.DeleteLB(fullName("bridges", "psec")); err == nil {
+				t.Error("expected the logical bridge to be in use")
+			}
+
+			// The bindings are only there once the snooping is up
+			req = httptest.NewRequest(http.MethodGet, "/v1/admin/dhcpsnoopings/psec-snoop/bindings", nil)
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected the bindings to fail with %d, received %d", http.StatusBadRequest, rec.Code)
+			}
+
+			req = httptest.NewRequest(http.MethodDelete, "/v1/admin/dhcpsnoopings/psec-snoop", nil)
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected the dhcp snooping to be deleted, received %d: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"sort"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// conditionalForwarder is the json representation of a conditional forwarding domain
+type conditionalForwarder struct {
+	Domain  string   `json:"domain"`
+	Servers []string `json:"servers"`
+}
+
+// dnsForwarder is the json representation of a dns forwarder
+type dnsForwarder struct {
+	Name                  string                 `json:"name,omitempty"`
+	Vrf                   string                 `json:"vrf"`
+	Upstreams             []string               `json:"upstreams,omitempty"`
+	ConditionalForwarders []conditionalForwarder `json:"conditional_forwarders,omitempty"`
+	ListenAddresses       []string               `json:"listen_addresses,omitempty"`
+	OperStatus            string                 `json:"oper_status,omitempty"`
+	Components            []component            `json:"components,omitempty"`
+}
+
+// ipsToJSON translates a list of addresses to their string representation
+func ipsToJSON(ips []net.IP) []string {
+	var out []string
+	for _, ip := range ips {
+		out = append(out, ip.String())
+	}
+	return out
+}
+
+// ipsFromJSON parses a list of addresses
+func ipsFromJSON(in []string) ([]net.IP, error) {
+	var out []net.IP
+	for _, s := range in {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid ip %s", s)
+		}
+		out = append(out, ip)
+	}
+	return out, nil
+}
+
+// dnsForwarderToJSON translates the domain object to its json representation
+func dnsForwarderToJSON(dns *infradb.DNSForwarder) *dnsForwarder {
+	out := &dnsForwarder{
+		Name:            dns.Name,
+		Vrf:             dns.Spec.Vrf,
+		Upstreams:       ipsToJSON(dns.Spec.Upstreams),
+		ListenAddresses: ipsToJSON(dns.Spec.ListenAddresses),
+		OperStatus:      dns.Status.OperStatus.String(),
+		Components:      componentsToJSON(dns.Status.Components),
+	}
+	for _, fwd := range dns.Spec.ConditionalForwarders {
+		out.ConditionalForwarders = append(out.ConditionalForwarders, conditionalForwarder{
+			Domain:  fwd.Domain,
+			Servers: ipsToJSON(fwd.Servers),
+		})
+	}
+	return out
+}
+
+// dnsForwarderSpecFromJSON translates the json representation to the domain spec
+func dnsForwarderSpecFromJSON(in *dnsForwarder) (*infradb.DNSForwarderSpec, error) {
+	spec := &infradb.DNSForwarderSpec{Vrf: in.Vrf}
+	var err error
+	if spec.Upstreams, err = ipsFromJSON(in.Upstreams); err != nil {
+		return nil, err
+	}
+	if spec.ListenAddresses, err = ipsFromJSON(in.ListenAddresses); err != nil {
+		return nil, err
+	}
+	for _, fwd := range in.ConditionalForwarders {
+		servers, err := ipsFromJSON(fwd.Servers)
+		if err != nil {
+			return nil, err
+		}
+		spec.ConditionalForwarders = append(spec.ConditionalForwarders, &infradb.DNSConditionalForwarder{
+			Domain:  fwd.Domain,
+			Servers: servers,
+		})
+	}
+	return spec, nil
+}
+
+// createDNSForwarder creates a dns forwarder for a vrf
+func createDNSForwarder(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &dnsForwarder{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("dnsforwarders", resourceID)
+	spec, err := dnsForwarderSpecFromJSON(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	dns, err := infradb.NewDNSForwarder(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetDNSForwarder(name); err == nil {
+		if !sameSpec(dns.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("dnsforwarders", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createDNSForwarder(): Already existing DNS Forwarder with id %v", name)
+		writeResponse(w, http.StatusOK, dnsForwarderToJSON(existing))
+		return
+	}
+	if err := infradb.CreateDNSForwarder(dns); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, dnsForwarderToJSON(dns))
+}
+
+// getDNSForwarder returns a dns forwarder
+func getDNSForwarder(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	dns, err := infradb.GetDNSForwarder(fullName("dnsforwarders", params["dnsforwarder"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, dnsForwarderToJSON(dns))
+}
+
+// listDNSForwarders returns all the dns forwarders
+func listDNSForwarders(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	dnss, err := infradb.GetAllDNSForwarders()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(dnss, func(i, j int) bool { return dnss[i].Name < dnss[j].Name })
+	out := []*dnsForwarder{}
+	for _, dns := range dnss {
+		out = append(out, dnsForwarderToJSON(dns))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"dns_forwarders": out})
+}
+
+// deleteDNSForwarder deletes a dns forwarder
+func deleteDNSForwarder(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteDNSForwarder(fullName("dnsforwarders", params["dnsforwarder"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_CreateDNSForwarder(t *testing.T) {
+	tests := map[string]struct {
+		existing *dnsForwarder
+		in       dnsForwarder
+		code     int
+	}{
+		"upstreams only": {
+			in:   dnsForwarder{Vrf: testVrfA, Upstreams: []string{"192.0.2.53"}},
+			code: http.StatusOK,
+		},
+		"conditional forwarder only": {
+			in: dnsForwarder{Vrf: testVrfA, ConditionalForwarders: []conditionalForwarder{
+				{Domain: "corp.example.", Servers: []string{"10.1.0.53", "10.1.1.53"}},
+			}},
+			code: http.StatusOK,
+		},
+		"no resolver": {
+			in:   dnsForwarder{Vrf: testVrfA},
+			code: http.StatusBadRequest,
+		},
+		"invalid upstream": {
+			in:   dnsForwarder{Vrf: testVrfA, Upstreams: []string{"192.0.2.300"}},
+			code: http.StatusBadRequest,
+		},
+		"domain without servers": {
+			in:   dnsForwarder{Vrf: testVrfA, ConditionalForwarders: []conditionalForwarder{{Domain: "corp.example"}}},
+			code: http.StatusBadRequest,
+		},
+		"duplicated domain": {
+			in: dnsForwarder{Vrf: testVrfA, ConditionalForwarders: []conditionalForwarder{
+				{Domain: "corp.example", Servers: []string{"10.1.0.53"}},
+				{Domain: "corp.example.", Servers: []string{"10.1.1.53"}},
+			}},
+			code: http.StatusBadRequest,
+		},
+		"unknown vrf": {
+			in:   dnsForwarder{Vrf: fullName("vrfs", "unknown"), Upstreams: []string{"192.0.2.53"}},
+			code: http.StatusNotFound,
+		},
+		"vrf already has a forwarder": {
+			existing: &dnsForwarder{Vrf: testVrfA, Upstreams: []string{"192.0.2.53"}},
+			in:       dnsForwarder{Vrf: testVrfA, Upstreams: []string{"198.51.100.53"}},
+			code:     http.StatusBadRequest,
+		},
+		"other vrf": {
+			existing: &dnsForwarder{Vrf: testVrfA, Upstreams: []string{"192.0.2.53"}},
+			in:       dnsForwarder{Vrf: testVrfB, Upstreams: []string{"198.51.100.53"}},
+			code:     http.StatusOK,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			if tt.existing != nil {
+				body, _ := json.Marshal(tt.existing)
+				req := httptest.NewRequest(http.MethodPost, "/v1/admin/dnsforwarders?id=existing-dns", bytes.NewReader(body))
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("failed to create existing dns forwarder: %s", rec.Body.String())
+				}
+			}
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/dnsforwarders?id=opi-dns", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &dnsForwarder{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("dnsforwarders", "opi-dns") || out.Vrf != tt.in.Vrf || out.OperStatus != "DOWN" {
+				t.Errorf("unexpected dns forwarder %+v", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/drift"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// driftDifference is the json representation of an object whose state differs from the intended one
+type driftDifference struct {
+	Kind     string `json:"kind"`
+	Object   string `json:"object"`
+	Resource string `json:"resource,omitempty"`
+	Details  string `json:"details,omitempty"`
+}
+
+// driftReport is the json representation of a drift report
+type driftReport struct {
+	Time time.Time `json:"time"`
+	// InSync is set when every comparison ran and found no difference
+	InSync      bool               `json:"in_sync"`
+	Differences []*driftDifference `json:"differences"`
+	Skipped     []string           `json:"skipped"`
+}
+
+// checkDrift compares the intended state with the kernel and the running config of the routing stack,
+// the differences are reported and left alone
+func checkDrift(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	backend, err := routing.Get()
+	if err != nil {
+		writeError(w, routingError(err))
+		return
+	}
+	report, err := drift.Check(r.Context(), backend)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := &driftReport{
+		Time:        report.Time,
+		InSync:      len(report.Differences) == 0 && len(report.Skipped) == 0,
+		Differences: []*driftDifference{},
+		Skipped:     []string{},
+	}
+	for _, d := range report.Differences {
+		out.Differences = append(out.Differences, &driftDifference{Kind: d.Kind, Object: d.Object, Resource: d.Resource, Details: d.Details})
+	}
+	out.Skipped = append(out.Skipped, report.Skipped...)
+	writeResponse(w, http.StatusOK, out)
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// duplicate is the json representation of an address flagged as duplicate in a VNI
+type duplicate struct {
+	Vni uint32 `json:"vni"`
+	// LogicalBridge is the logical bridge of the VNI, empty for the VNI of a vrf
+	LogicalBridge string `json:"logical_bridge,omitempty"`
+	Mac           string `json:"mac"`
+	IP            string `json:"ip,omitempty"`
+	RemoteVtep    string `json:"remote_vtep,omitempty"`
+	Moves         int    `json:"moves"`
+	Frozen        bool   `json:"frozen"`
+}
+
+// duplicateDetector returns the routing backend when it detects the duplicate addresses
+func duplicateDetector() (routing.DuplicateDetector, error) {
+	backend, err := routing.Get()
+	if err != nil {
+		return nil, routingError(err)
+	}
+	detector, ok := backend.(routing.DuplicateDetector)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "the %s routing backend does not detect the duplicate addresses", backend.Name())
+	}
+	return detector, nil
+}
+
+// listDuplicates returns the addresses flagged as duplicate by the routing stack, with the logical bridge of their VNI
+func listDuplicates(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	detector, err := duplicateDetector()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	dups, err := detector.Duplicates(r.Context())
+	if err != nil {
+		writeError(w, routingError(err))
+		return
+	}
+	bridges := map[uint32]string{}
+	lbs, err := infradb.GetAllLBs()
+	if err != nil && err != infradb.ErrKeyNotFound {
+		writeError(w, err)
+		return
+	}
+	for _, lb := range lbs {
+		if lb.Spec.Vni != nil {
+			bridges[*lb.Spec.Vni] = lb.Name
+		}
+	}
+	frozen := config.GlobalConfig.DupAddr.Freeze != 0
+	out := []duplicate{}
+	for _, d := range dups {
+		out = append(out, duplicate{
+			Vni:           d.Vni,
+			LogicalBridge: bridges[d.Vni],
+			Mac:           d.Mac,
+			IP:            d.IP,
+			RemoteVtep:    d.RemoteVtep,
+			Moves:         d.Moves,
+			Frozen:        frozen,
+		})
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"duplicates": out})
+}
+
+// clearDuplicates clears the duplicate addresses of a VNI, or the mac or ip address of the query only
+func clearDuplicates(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	vni, err := strconv.ParseUint(params["vni"], 10, 24)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "invalid vni %s", params["vni"]))
+		return
+	}
+	mac, ip := r.URL.Query().Get("mac"), r.URL.Query().Get("ip")
+	if mac != "" {
+		hw, err := net.ParseMAC(mac)
+		if err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid mac %s", mac))
+			return
+		}
+		mac = hw.String()
+	}
+	if ip != "" && net.ParseIP(ip) == nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "invalid ip %s", ip))
+		return
+	}
+	if mac != "" && ip != "" {
+		writeError(w, status.Error(codes.InvalidArgument, "clear either a mac or an ip address"))
+		return
+	}
+	detector, err := duplicateDetector()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := detector.ClearDuplicate(r.Context(), uint32(vni), mac, ip); err != nil {
+		writeError(w, routingError(err))
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
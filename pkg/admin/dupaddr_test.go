@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// dupBackend reports fixed duplicates and records the clears
+type dupBackend struct {
+	encapBackend
+	dups    []routing.Duplicate
+	cleared []string
+}
+
+func (*dupBackend) ConfigureDupAddrDetection(context.Context, routing.DupAddrDetection) error {
+	return nil
+}
+
+func (b *dupBackend) Duplicates(context.Context) ([]routing.Duplicate, error) {
+	return b.dups, nil
+}
+
+func (b *dupBackend) ClearDuplicate(_ context.Context, vni uint32, mac, ip string) error {
+	b.cleared = append(b.cleared, clearKey(vni, mac, ip))
+	return nil
+}
+
+// clearKey records a clear of the backend
+func clearKey(vni uint32, mac, ip string) string {
+	return fmt.Sprintf("%d/%s/%s", vni, mac, ip)
+}
+
+func Test_ListDuplicates(t *testing.T) {
+	mux := newTestMux(t)
+	backend := &dupBackend{encapBackend: encapBackend{name: "test-dup"}, dups: []routing.Duplicate{
+		{Vni: 1000, Mac: "aa:bb:cc:00:00:01", RemoteVtep: "10.0.0.2", Moves: 5},
+		{Vni: 1000, Mac: "aa:bb:cc:00:00:01", IP: "10.0.0.5", Moves: 5},
+		{Vni: 2000, Mac: "aa:bb:cc:00:00:02", RemoteVtep: "10.0.0.3", Moves: 6},
+	}}
+	selectEncapBackend(t, backend)
+	vni := uint32(1000)
+	if err := createTestBridge("web", 30, &vni); err != nil {
+		t.Fatal(err)
+	}
+	orig := config.GlobalConfig.DupAddr
+	config.GlobalConfig.DupAddr.Freeze = -1
+	t.Cleanup(func() { config.GlobalConfig.DupAddr = orig })
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/evpn/duplicates", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code 200, received %d: %s", rec.Code, rec.Body.String())
+	}
+	out := struct {
+		Duplicates []duplicate `json:"duplicates"`
+	}{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	bridge := fullName("bridges", "web")
+	expected := []duplicate{
+		{Vni: 1000, LogicalBridge: bridge, Mac: "aa:bb:cc:00:00:01", RemoteVtep: "10.0.0.2", Moves: 5, Frozen: true},
+		{Vni: 1000, LogicalBridge: bridge, Mac: "aa:bb:cc:00:00:01", IP: "10.0.0.5", Moves: 5, Frozen: true},
+		{Vni: 2000, Mac: "aa:bb:cc:00:00:02", RemoteVtep: "10.0.0.3", Moves: 6, Frozen: true},
+	}
+	if !reflect.DeepEqual(out.Duplicates, expected) {
+		t.Errorf("expected %+v, received %+v", expected, out.Duplicates)
+	}
+}
+
+func Test_ClearDuplicates(t *testing.T) {
+	tests := map[string]struct {
+		url     string
+		code    int
+		cleared []string
+	}{
+		"whole vni": {
+			url:     "/v1/admin/evpn/duplicates/1000",
+			code:    http.StatusOK,
+			cleared: []string{clearKey(1000, "", "")},
+		},
+		"mac address": {
+			url:     "/v1/admin/evpn/duplicates/1000?mac=AA:BB:CC:00:00:01",
+			code:    http.StatusOK,
+			cleared: []string{clearKey(1000, "aa:bb:cc:00:00:01", "")},
+		},
+		"ip address": {
+			url:     "/v1/admin/evpn/duplicates/1000?ip=10.0.0.5",
+			code:    http.StatusOK,
+			cleared: []string{clearKey(1000, "", "10.0.0.5")},
+		},
+		"mac and ip address": {
+			url:  "/v1/admin/evpn/duplicates/1000?mac=aa:bb:cc:00:00:01&ip=10.0.0.5",
+			code: http.StatusBadRequest,
+		},
+		"vni out of range": {
+			url:  "/v1/admin/evpn/duplicates/16777216",
+			code: http.StatusBadRequest,
+		},
+		"invalid mac": {
+			url:  "/v1/admin/evpn/duplicates/1000?mac=aa:bb",
+			code: http.StatusBadRequest,
+		},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			backend := &dupBackend{encapBackend: encapBackend{name: "test-dup"}}
+			selectEncapBackend(t, backend)
+
+			req := httptest.NewRequest(http.MethodDelete, tt.url, nil)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if !reflect.DeepEqual(backend.cleared, tt.cleared) {
+				t.Errorf("expected the clears %v, received %v", tt.cleared, backend.cleared)
+			}
+		})
+	}
+}
+
+func Test_DuplicatesUnsupportedBackend(t *testing.T) {
+	mux := newTestMux(t)
+	selectEncapBackend(t, &encapBackend{name: "test-vxlan"})
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/evpn/duplicates", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("expected code 501, received %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"encoding/hex"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// geneveOption is the json representation of a geneve option TLV, the data is in hex
+type geneveOption struct {
+	Class uint16 `json:"class"`
+	Type  uint8  `json:"type"`
+	Data  string `json:"data,omitempty"`
+}
+
+// encapsulation is the json representation of the encapsulation of the tunnel of a logical bridge
+type encapsulation struct {
+	Type    string         `json:"type"`
+	Options []geneveOption `json:"options,omitempty"`
+}
+
+// toEncapsulation converts the encapsulation to json, nil being VXLAN
+func toEncapsulation(in *infradb.EncapSpec) *encapsulation {
+	if in == nil {
+		return &encapsulation{Type: routing.EncapVxlan}
+	}
+	out := &encapsulation{Type: in.Type}
+	for _, o := range in.Options {
+		out.Options = append(out.Options, geneveOption{Class: o.Class, Type: o.Type, Data: hex.EncodeToString(o.Data)})
+	}
+	return out
+}
+
+// getLogicalBridgeEncap returns the encapsulation of the tunnel of a logical bridge
+func getLogicalBridgeEncap(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	lb, err := infradb.GetLB(fullName("bridges", params["logicalbridge"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, toEncapsulation(lb.Spec.Encap))
+}
+
+// setLogicalBridgeEncap carries a logical bridge over VXLAN or geneve
+func setLogicalBridgeEncap(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &encapsulation{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	options := make([]infradb.GeneveOption, 0, len(in.Options))
+	for _, o := range in.Options {
+		data, err := hex.DecodeString(o.Data)
+		if err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "geneve option %04x:%02x data is not hex: %v", o.Class, o.Type, err))
+			return
+		}
+		options = append(options, infradb.GeneveOption{Class: o.Class, Type: o.Type, Data: data})
+	}
+	spec, err := infradb.NewEncapSpec(in.Type, options)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	lb, err := infradb.SetLogicalBridgeEncap(fullName("bridges", params["logicalbridge"]), spec, ifMatch(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, toEncapsulation(lb.Spec.Encap))
+}
+
+// deleteLogicalBridgeEncap carries a logical bridge over VXLAN again
+func deleteLogicalBridgeEncap(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if _, err := infradb.SetLogicalBridgeEncap(fullName("bridges", params["logicalbridge"]), nil, ifMatch(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// encapBackend is a routing backend carrying the logical bridges over the encapsulations it lists,
+// VXLAN only when it lists none
+type encapBackend struct {
+	routing.Backend
+	name   string
+	encaps []string
+}
+
+func (b *encapBackend) Name() string {
+	return b.name
+}
+
+// geneveBackend carries geneve
+type geneveBackend struct {
+	encapBackend
+}
+
+func (b *geneveBackend) Encaps() []string {
+	return b.encaps
+}
+
+func selectEncapBackend(t *testing.T, b routing.Backend) {
+	routing.Register(b)
+	if _, err := routing.Select(b.Name()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_SetLogicalBridgeEncap(t *testing.T) {
+	vni := uint32(1000)
+	tests := map[string]struct {
+		bridge  string
+		backend routing.Backend
+		in      encapsulation
+		code    int
+	}{
+		"geneve with options": {
+			bridge:  "geneve",
+			backend: &geneveBackend{encapBackend{name: "test-geneve", encaps: []string{routing.EncapVxlan, routing.EncapGeneve}}},
+			in:      encapsulation{Type: routing.EncapGeneve, Options: []geneveOption{{Class: 0x0102, Type: 0x80, Data: "00112233"}}},
+			code:    http.StatusOK,
+		},
+		"vxlan only backend": {
+			bridge:  "geneve",
+			backend: &encapBackend{name: "test-vxlan"},
+			in:      encapsulation{Type: routing.EncapGeneve},
+			code:    http.StatusBadRequest,
+		},
+		"option data not in words": {
+			bridge:  "geneve",
+			backend: &geneveBackend{encapBackend{name: "test-geneve", encaps: []string{routing.EncapVxlan, routing.EncapGeneve}}},
+			in:      encapsulation{Type: routing.EncapGeneve, Options: []geneveOption{{Class: 0x0102, Type: 0x80, Data: "001122"}}},
+			code:    http.StatusBadRequest,
+		},
+		"option data not hex": {
+			bridge:  "geneve",
+			backend: &geneveBackend{encapBackend{name: "test-geneve", encaps: []string{routing.EncapVxlan, routing.EncapGeneve}}},
+			in:      encapsulation{Type: routing.EncapGeneve, Options: []geneveOption{{Class: 0x0102, Type: 0x80, Data: "zz"}}},
+			code:    http.StatusBadRequest,
+		},
+		"unknown encapsulation": {
+			bridge:  "geneve",
+			backend: &encapBackend{name: "test-vxlan"},
+			in:      encapsulation{Type: "nvgre"},
+			code:    http.StatusBadRequest,
+		},
+		"logical bridge without vni": {
+			bridge:  "local",
+			backend: &geneveBackend{encapBackend{name: "test-geneve", encaps: []string{routing.EncapVxlan, routing.EncapGeneve}}},
+			in:      encapsulation{Type: routing.EncapGeneve},
+			code:    http.StatusBadRequest,
+		},
+		"unknown logical bridge": {
+			bridge:  "unknown",
+			backend: &geneveBackend{encapBackend{name: "test-geneve", encaps: []string{routing.EncapVxlan, routing.EncapGeneve}}},
+			in:      encapsulation{Type: routing.EncapGeneve},
+			code:    http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			selectEncapBackend(t, tt.backend)
+			if err := createTestBridge("geneve", 30, &vni); err != nil {
+				t.Fatal(err)
+			}
+			if err := createTestBridge("local", 40, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/logicalbridges/"+tt.bridge+"/encap", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			req = httptest.NewRequest(http.MethodGet, "/v1/admin/logicalbridges/"+tt.bridge+"/encap", nil)
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			out := &encapsulation{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*out, tt.in) {
+				t.Errorf("expected %+v, received %+v", tt.in, out)
+			}
+		})
+	}
+}
+
+func Test_DeleteLogicalBridgeEncap(t *testing.T) {
+	mux := newTestMux(t)
+	selectEncapBackend(t, &geneveBackend{encapBackend{name: "test-geneve", encaps: []string{routing.EncapVxlan, routing.EncapGeneve}}})
+	vni := uint32(1000)
+	if err := createTestBridge("geneve", 30, &vni); err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(encapsulation{Type: routing.EncapGeneve})
+	req := httptest.NewRequest(http.MethodPut, "/v1/admin/logicalbridges/geneve/encap", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the bridge to be carried over geneve, received %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/v1/admin/logicalbridges/geneve/encap", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/admin/logicalbridges/geneve/encap", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	out := &encapsulation{}
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Type != routing.EncapVxlan {
+		t.Errorf("expected the bridge back over vxlan, received %+v", out)
+	}
+}
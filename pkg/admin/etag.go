@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// objectRoute locates the object of the store read or changed by an endpoint: the collection of the object
+// and the parameter holding its id
+type objectRoute struct {
+	collection string
+	param      string
+}
+
+// objectRoutes holds the admin endpoints reading or changing an object which has a resource version
+var objectRoutes = map[string]objectRoute{
+	"/v1/admin/bridgeports/{bridgeport}/sflow":             {"ports", "bridgeport"},
+	"/v1/admin/bridgeports/{bridgeport}/qinq":              {"ports", "bridgeport"},
+	"/v1/admin/bridgeports/{bridgeport}/isolation":         {"ports", "bridgeport"},
+	"/v1/admin/bridgeports/{bridgeport}/profile":           {"ports", "bridgeport"},
+	"/v1/admin/logicalbridges/{logicalbridge}/encap":       {"bridges", "logicalbridge"},
+	"/v1/admin/logicalbridges/{logicalbridge}/isolation":   {"bridges", "logicalbridge"},
+	"/v1/admin/vrfs/{vrf}/dataplane":                       {"vrfs", "vrf"},
+	"/v1/admin/vrfs/{vrf}/netns":                           {"vrfs", "vrf"},
+	"/v1/admin/svis/{svi}/proxyarp":                        {"svis", "svi"},
+	"/v1/admin/svis/{svi}/secondaryips":                    {"svis", "svi"},
+	"/v1/admin/routeleaks/{routeleak}":                     {"routeleaks", "routeleak"},
+	"/v1/admin/hostroutes/{hostroute}":                     {"hostroutes", "hostroute"},
+	"/v1/admin/vpcpeerings/{vpcpeering}":                   {"vpcpeerings", "vpcpeering"},
+	"/v1/admin/flowlogs/{flowlog}":                         {"flowlogs", "flowlog"},
+	"/v1/admin/routingpolicies/{routingpolicy}":            {"routingpolicies", "routingpolicy"},
+	"/v1/admin/natgateways/{natgateway}":                   {"natgateways", "natgateway"},
+	"/v1/admin/dnsforwarders/{dnsforwarder}":               {"dnsforwarders", "dnsforwarder"},
+	"/v1/admin/dhcpservers/{dhcpserver}":                   {"dhcpservers", "dhcpserver"},
+	"/v1/admin/routeradvertisements/{routeradvertisement}": {"routeradvertisements", "routeradvertisement"},
+	"/v1/admin/externalinterfaces/{externalinterface}":     {"externalinterfaces", "externalinterface"},
+	"/v1/admin/bonds/{bond}":                               {"bonds", "bond"},
+	"/v1/admin/portsecurities/{portsecurity}":              {"portsecurities", "portsecurity"},
+	"/v1/admin/dhcpsnoopings/{dhcpsnooping}":               {"dhcpsnoopings", "dhcpsnooping"},
+	"/v1/admin/conntrackpolicies/{conntrackpolicy}":        {"conntrackpolicies", "conntrackpolicy"},
+	"/v1/admin/virtualports/{virtualport}":                 {"virtualports", "virtualport"},
+	"/v1/admin/vfrepresentors/{vfrepresentor}":             {"vfrepresentors", "vfrepresentor"},
+}
+
+// etagWriter sets the etag header with the resource version of the object once the handler has succeeded
+type etagWriter struct {
+	http.ResponseWriter
+	name string
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if code >= 200 && code < 300 {
+		if version, err := infradb.GetResourceVersion(w.name); err == nil && version != "" {
+			w.Header().Set(utils.ETagHeader, strconv.Quote(version))
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// ifMatch returns the resource version expected by the request, the store compares it with the version
+// of the object under the lock of the write
+func ifMatch(r *http.Request) infradb.WriteOption {
+	return infradb.IfMatch(ifMatchVersion(r))
+}
+
+// ifMatchVersion returns the resource version of the if-match header, with or without quotes
+func ifMatchVersion(r *http.Request) string {
+	value := strings.TrimSpace(r.Header.Get(utils.IfMatchHeader))
+	if version, err := strconv.Unquote(value); err == nil {
+		return version
+	}
+	return value
+}
+
+// withETag returns the resource version of the object of the endpoint in the etag header, and rejects the
+// changes of an existing object without if-match header when the etags are required
+func withETag(r route, handler runtime.HandlerFunc) runtime.HandlerFunc {
+	obj, ok := objectRoutes[r.pattern]
+	if !ok {
+		return handler
+	}
+	return func(w http.ResponseWriter, req *http.Request, params map[string]string) {
+		name := fullName(obj.collection, params[obj.param])
+		write := r.method == http.MethodPut || r.method == http.MethodDelete
+		if write && config.GlobalConfig.RequireETag && ifMatchVersion(req) == "" {
+			version, err := infradb.GetResourceVersion(name)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			if version != "" {
+				writeError(w, status.Errorf(codes.FailedPrecondition, "the %s header with the etag of %s is required", utils.IfMatchHeader, name))
+				return
+			}
+		}
+		if r.method == http.MethodDelete {
+			handler(w, req, params)
+			return
+		}
+		handler(&etagWriter{ResponseWriter: w, name: name}, req, params)
+	}
+}
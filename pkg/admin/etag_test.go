@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+func Test_ETag(t *testing.T) {
+	mux := newTestMux(t)
+	createTestVpc(t)
+	url := "/v1/admin/hostroutes/vips"
+	in := &hostRoute{Vrf: testVpc, Prefixes: []string{"10.9.0.1/32"}}
+	if rec := sendHostRoute(t, mux, http.MethodPost, "/v1/admin/hostroutes?id=vips", in); rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	send := func(method string, ifMatch string) *httptest.ResponseRecorder {
+		t.Helper()
+		body, err := json.Marshal(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(method, url, bytes.NewReader(body))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(http.MethodGet, "")
+	etag := rec.Header().Get("Etag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected an etag, received %d %q: %s", rec.Code, etag, rec.Body.String())
+	}
+
+	// the etag read by the client is stale once another client has changed the host route
+	in.Communities = []string{"65000:100"}
+	rec = send(http.MethodPut, etag)
+	if rec.Code != http.StatusOK || rec.Header().Get("Etag") == etag {
+		t.Fatalf("expected a new etag, received %d %q: %s", rec.Code, rec.Header().Get("Etag"), rec.Body.String())
+	}
+	updated := rec.Header().Get("Etag")
+	in.Communities = []string{"65000:200"}
+	if rec := send(http.MethodPut, etag); rec.Code != http.StatusConflict {
+		t.Errorf("expected code %d, received %d: %s", http.StatusConflict, rec.Code, rec.Body.String())
+	}
+	if rec := send(http.MethodDelete, etag); rec.Code != http.StatusConflict {
+		t.Errorf("expected code %d, received %d: %s", http.StatusConflict, rec.Code, rec.Body.String())
+	}
+
+	config.GlobalConfig.RequireETag = true
+	t.Cleanup(func() { config.GlobalConfig.RequireETag = false })
+	if rec := send(http.MethodDelete, ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected code %d, received %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+	if rec := send(http.MethodDelete, updated); rec.Code != http.StatusOK {
+		t.Errorf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+}
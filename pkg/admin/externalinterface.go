@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"sort"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// bgpPeer is the json representation of an external bgp peer
+type bgpPeer struct {
+	PeerIP   string `json:"peer_ip"`
+	RemoteAS uint32 `json:"remote_as"`
+}
+
+// externalInterface is the json representation of an external interface
+type externalInterface struct {
+	Name       string      `json:"name,omitempty"`
+	Vrf        string      `json:"vrf"`
+	Interface  string      `json:"interface"`
+	VlanID     uint16      `json:"vlan_id,omitempty"`
+	Address    string      `json:"address"`
+	Gateway    string      `json:"gateway,omitempty"`
+	BgpPeer    *bgpPeer    `json:"bgp_peer,omitempty"`
+	OperStatus string      `json:"oper_status,omitempty"`
+	Components []component `json:"components,omitempty"`
+}
+
+// externalInterfaceToJSON translates the domain object to its json representation
+func externalInterfaceToJSON(eif *infradb.ExternalInterface) *externalInterface {
+	out := &externalInterface{
+		Name:       eif.Name,
+		Vrf:        eif.Spec.Vrf,
+		Interface:  eif.Spec.Interface,
+		VlanID:     eif.Spec.VlanID,
+		Address:    eif.Spec.Address.String(),
+		OperStatus: eif.Status.OperStatus.String(),
+		Components: componentsToJSON(eif.Status.Components),
+	}
+	if eif.Spec.Gateway != nil {
+		out.Gateway = eif.Spec.Gateway.String()
+	}
+	if eif.Spec.BgpPeer != nil {
+		out.BgpPeer = &bgpPeer{PeerIP: eif.Spec.BgpPeer.PeerIP.String(), RemoteAS: eif.Spec.BgpPeer.RemoteAS}
+	}
+	return out
+}
+
+// externalInterfaceSpecFromJSON translates the json representation to the domain spec
+func externalInterfaceSpecFromJSON(in *externalInterface) (*infradb.ExternalInterfaceSpec, error) {
+	spec := &infradb.ExternalInterfaceSpec{Vrf: in.Vrf, Interface: in.Interface, VlanID: in.VlanID}
+	if in.Address != "" {
+		ip, ipnet, err := net.ParseCIDR(in.Address)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid address %s: %v", in.Address, err)
+		}
+		ipnet.IP = ip
+		spec.Address = ipnet
+	}
+	if in.Gateway != "" {
+		spec.Gateway = net.ParseIP(in.Gateway)
+		if spec.Gateway == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid gateway %s", in.Gateway)
+		}
+	}
+	if in.BgpPeer != nil {
+		peerIP := net.ParseIP(in.BgpPeer.PeerIP)
+		if peerIP == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid bgp peer ip %s", in.BgpPeer.PeerIP)
+		}
+		spec.BgpPeer = &infradb.ExternalBgpPeer{PeerIP: peerIP, RemoteAS: in.BgpPeer.RemoteAS}
+	}
+	return spec, nil
+}
+
+// createExternalInterface attaches a vrf to an upstream interface
+func createExternalInterface(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &externalInterface{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("externalinterfaces", resourceID)
+	spec, err := externalInterfaceSpecFromJSON(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	eif, err := infradb.NewExternalInterface(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetExternalInterface(name); err == nil {
+		if !sameSpec(eif.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("externalinterfaces", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createExternalInterface(): Already existing External Interface with id %v", name)
+		writeResponse(w, http.StatusOK, externalInterfaceToJSON(existing))
+		return
+	}
+	if err := infradb.CreateExternalInterface(eif); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, externalInterfaceToJSON(eif))
+}
+
+// getExternalInterface returns an external interface
+func getExternalInterface(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	eif, err := infradb.GetExternalInterface(fullName("externalinterfaces", params["externalinterface"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, externalInterfaceToJSON(eif))
+}
+
+// listExternalInterfaces returns all the external interfaces
+func listExternalInterfaces(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	eifs, err := infradb.GetAllExternalInterfaces()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(eifs, func(i, j int) bool { return eifs[i].Name < eifs[j].Name })
+	out := []*externalInterface{}
+	for _, eif := range eifs {
+		out = append(out, externalInterfaceToJSON(eif))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"external_interfaces": out})
+}
+
+// deleteExternalInterface deletes an external interface
+func deleteExternalInterface(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteExternalInterface(fullName("externalinterfaces", params["externalinterface"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
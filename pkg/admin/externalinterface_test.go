@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_CreateExternalInterface(t *testing.T) {
+	tests := map[string]struct {
+		existing *externalInterface
+		in       externalInterface
+		code     int
+	}{
+		"valid request": {
+			in: externalInterface{
+				Vrf: testVrfA, Interface: "eth1", VlanID: 100, Address: "198.51.100.2/30", Gateway: "198.51.100.1",
+				BgpPeer: &bgpPeer{PeerIP: "198.51.100.1", RemoteAS: 65500},
+			},
+			code: http.StatusOK,
+		},
+		"missing address": {
+			in:   externalInterface{Vrf: testVrfA, Interface: "eth1"},
+			code: http.StatusBadRequest,
+		},
+		"gateway outside of the subnet": {
+			in:   externalInterface{Vrf: testVrfA, Interface: "eth1", Address: "198.51.100.2/30", Gateway: "198.51.100.9"},
+			code: http.StatusBadRequest,
+		},
+		"bgp peer without remote as": {
+			in:   externalInterface{Vrf: testVrfA, Interface: "eth1", Address: "198.51.100.2/30", BgpPeer: &bgpPeer{PeerIP: "198.51.100.1"}},
+			code: http.StatusBadRequest,
+		},
+		"unknown vrf": {
+			in:   externalInterface{Vrf: fullName("vrfs", "unknown"), Interface: "eth1", Address: "198.51.100.2/30"},
+			code: http.StatusNotFound,
+		},
+		"interface already attached": {
+			existing: &externalInterface{Vrf: testVrfB, Interface: "eth1", VlanID: 100, Address: "198.51.100.6/30"},
+			in:       externalInterface{Vrf: testVrfA, Interface: "eth1", VlanID: 100, Address: "198.51.100.2/30"},
+			code:     http.StatusBadRequest,
+		},
+		"same interface other vlan": {
+			existing: &externalInterface{Vrf: testVrfB, Interface: "eth1", VlanID: 100, Address: "198.51.100.6/30"},
+			in:       externalInterface{Vrf: testVrfA, Interface: "eth1", VlanID: 200, Address: "198.51.100.2/30"},
+			code:     http.StatusOK,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			if tt.existing != nil {
+				body, _ := json.Marshal(tt.existing)
+				req := httptest.NewRequest(http.MethodPost, "/v1/admin/externalinterfaces?id=opi-existing", bytes.NewReader(body))
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("failed to create existing external interface: %s", rec.Body.String())
+				}
+			}
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/externalinterfaces?id=opi-eif", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &externalInterface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("externalinterfaces", "opi-eif") || out.OperStatus != "DOWN" || out.Address != tt.in.Address {
+				t.Errorf("unexpected external interface %+v", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/fabric"
+)
+
+// peerHealth is the json representation of the health of a remote VTEP, the round trip times are in milliseconds
+type peerHealth struct {
+	Peer      string     `json:"peer"`
+	Source    string     `json:"source"`
+	Sent      uint64     `json:"sent"`
+	Received  uint64     `json:"received"`
+	Loss      float64    `json:"loss"`
+	RttLast   float64    `json:"rtt_last_ms"`
+	RttMin    float64    `json:"rtt_min_ms"`
+	RttAvg    float64    `json:"rtt_avg_ms"`
+	RttMax    float64    `json:"rtt_max_ms"`
+	Jitter    float64    `json:"jitter_ms"`
+	LastProbe *time.Time `json:"last_probe,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// milliseconds converts a round trip time
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// getFabricHealth returns the loss and the latency of the last probes of the remote VTEPs
+func getFabricHealth(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	peers := []*peerHealth{}
+	for _, h := range fabric.Health() {
+		peers = append(peers, &peerHealth{
+			Peer:      h.Peer,
+			Source:    h.Source,
+			Sent:      h.Sent,
+			Received:  h.Received,
+			Loss:      h.Loss,
+			RttLast:   milliseconds(h.RttLast),
+			RttMin:    milliseconds(h.RttMin),
+			RttAvg:    milliseconds(h.RttAvg),
+			RttMax:    milliseconds(h.RttMax),
+			Jitter:    milliseconds(h.Jitter),
+			LastProbe: timeToJSON(h.LastProbe),
+			Error:     h.Error,
+		})
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"peers": peers})
+}
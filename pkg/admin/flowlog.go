@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// flowLog is the json representation of a flow log
+type flowLog struct {
+	Name string `json:"name,omitempty"`
+	Vrf  string `json:"vrf"`
+	// Svi restricts the log to the subnet of the svi
+	Svi string `json:"svi,omitempty"`
+	// Collector is the host:port of the IPFIX collector
+	Collector string `json:"collector"`
+	// SamplingRate logs one in sampling_rate new flows
+	SamplingRate uint32 `json:"sampling_rate,omitempty"`
+	// ActiveTimeout and IdleTimeout are in seconds
+	ActiveTimeout uint32      `json:"active_timeout,omitempty"`
+	IdleTimeout   uint32      `json:"idle_timeout,omitempty"`
+	OperStatus    string      `json:"oper_status,omitempty"`
+	Components    []component `json:"components,omitempty"`
+}
+
+// flowLogToJSON translates the domain object to its json representation
+func flowLogToJSON(fl *infradb.FlowLog) *flowLog {
+	return &flowLog{
+		Name:          fl.Name,
+		Vrf:           fl.Spec.Vrf,
+		Svi:           fl.Spec.Svi,
+		Collector:     fl.Spec.Collector,
+		SamplingRate:  fl.Spec.SamplingRate,
+		ActiveTimeout: uint32(fl.Spec.ActiveTimeout / time.Second),
+		IdleTimeout:   uint32(fl.Spec.IdleTimeout / time.Second),
+		OperStatus:    fl.Status.OperStatus.String(),
+		Components:    componentsToJSON(fl.Status.Components),
+	}
+}
+
+// createFlowLog exports the flows of a vrf or of the subnet of an svi
+func createFlowLog(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &flowLog{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("flowlogs", resourceID)
+	spec := &infradb.FlowLogSpec{
+		Vrf:           in.Vrf,
+		Svi:           in.Svi,
+		Collector:     in.Collector,
+		SamplingRate:  in.SamplingRate,
+		ActiveTimeout: time.Duration(in.ActiveTimeout) * time.Second,
+		IdleTimeout:   time.Duration(in.IdleTimeout) * time.Second,
+	}
+	fl, err := infradb.NewFlowLog(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetFlowLog(name); err == nil {
+		if !sameSpec(fl.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("flowlogs", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createFlowLog(): Already existing Flow Log with id %v", name)
+		writeResponse(w, http.StatusOK, flowLogToJSON(existing))
+		return
+	}
+	if err := infradb.CreateFlowLog(fl); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, flowLogToJSON(fl))
+}
+
+// getFlowLog returns a flow log
+func getFlowLog(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	fl, err := infradb.GetFlowLog(fullName("flowlogs", params["flowlog"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, flowLogToJSON(fl))
+}
+
+// listFlowLogs returns all the flow logs
+func listFlowLogs(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	fls, err := infradb.GetAllFlowLogs()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(fls, func(i, j int) bool { return fls[i].Name < fls[j].Name })
+	out := []*flowLog{}
+	for _, fl := range fls {
+		out = append(out, flowLogToJSON(fl))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"flow_logs": out})
+}
+
+// deleteFlowLog deletes a flow log
+func deleteFlowLog(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteFlowLog(fullName("flowlogs", params["flowlog"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_CreateFlowLog(t *testing.T) {
+	tests := map[string]struct {
+		in   flowLog
+		code int
+	}{
+		"valid request": {
+			in:   flowLog{Vrf: testVrfA, Collector: "192.0.2.10:4739", SamplingRate: 100},
+			code: http.StatusOK,
+		},
+		"subnet": {
+			in:   flowLog{Vrf: testVrfA, Svi: fullName("svis", "web"), Collector: "collector.example.com:4739", SamplingRate: 100},
+			code: http.StatusOK,
+		},
+		"missing collector": {
+			in:   flowLog{Vrf: testVrfA},
+			code: http.StatusBadRequest,
+		},
+		"collector without port": {
+			in:   flowLog{Vrf: testVrfA, Collector: "192.0.2.10"},
+			code: http.StatusBadRequest,
+		},
+		"idle longer than active": {
+			in:   flowLog{Vrf: testVrfA, Collector: "192.0.2.10:4739", ActiveTimeout: 30, IdleTimeout: 60},
+			code: http.StatusBadRequest,
+		},
+		"svi of another vrf": {
+			in:   flowLog{Vrf: testVrfB, Svi: fullName("svis", "web"), Collector: "192.0.2.10:4739"},
+			code: http.StatusBadRequest,
+		},
+		"unknown svi": {
+			in:   flowLog{Vrf: testVrfA, Svi: fullName("svis", "unknown"), Collector: "192.0.2.10:4739"},
+			code: http.StatusNotFound,
+		},
+		"unknown vrf": {
+			in:   flowLog{Vrf: fullName("vrfs", "unknown"), Collector: "192.0.2.10:4739"},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			createTestSvi(t)
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/flowlogs?id=blue-flows", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &flowLog{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("flowlogs", "blue-flows") || out.OperStatus != "DOWN" || out.ActiveTimeout != 60 || out.IdleTimeout != 15 {
+				t.Errorf("unexpected flow log %+v", out)
+			}
+		})
+	}
+}
+
+func Test_FlowLogMarks(t *testing.T) {
+	mux := newTestMux(t)
+	for _, id := range []string{"blue-flows", "red-flows"} {
+		body, _ := json.Marshal(flowLog{Vrf: testVrfA, Collector: "192.0.2.10:4739"})
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/flowlogs?id="+id, bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("failed to create the flow log %s: %d %s", id, rec.Code, rec.Body.String())
+		}
+	}
+	blue, err := infradb.GetFlowLog(fullName("flowlogs", "blue-flows"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	red, err := infradb.GetFlowLog(fullName("flowlogs", "red-flows"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blue.Mark == red.Mark || blue.Mark <= infradb.FlowLogMarkBase || red.Mark <= infradb.FlowLogMarkBase {
+		t.Errorf("expected distinct marks of the flow logs, received %#x and %#x", blue.Mark, red.Mark)
+	}
+	if err := infradb.DeleteVrf(testVrfA); err != infradb.ErrVrfNotEmpty {
+		t.Errorf("expected %v, received %v", infradb.ErrVrfNotEmpty, err)
+	}
+}
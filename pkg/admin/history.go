@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apply"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// revision is the json representation of a revision of a resource
+type revision struct {
+	Number uint64          `json:"number"`
+	Name   string          `json:"name"`
+	Kind   string          `json:"kind"`
+	Action string          `json:"action"`
+	Time   time.Time       `json:"time"`
+	Object json.RawMessage `json:"object,omitempty"`
+}
+
+// revisionToJSON translates the revision to its json representation, the object only when asked for
+func revisionToJSON(rev *infradb.Revision, withObject bool) *revision {
+	out := &revision{
+		Number: rev.Number,
+		Name:   rev.Name,
+		Kind:   rev.Kind,
+		Action: rev.Action,
+		Time:   rev.Time,
+	}
+	if withObject && len(rev.Object) > 0 {
+		out.Object = rev.Object
+	}
+	return out
+}
+
+// parseRevision reads the revision number of the path
+func parseRevision(params map[string]string) (uint64, error) {
+	number, err := strconv.ParseUint(params["revision"], 10, 64)
+	if err != nil || number == 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid revision %q", params["revision"])
+	}
+	return number, nil
+}
+
+// listRevisions returns the revisions of the resource named by the name query, or of all the resources, newest first
+func listRevisions(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	revs, err := infradb.GetRevisions(r.URL.Query().Get("name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := make([]*revision, 0, len(revs))
+	for _, rev := range revs {
+		out = append(out, revisionToJSON(rev, false))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"revisions": out})
+}
+
+// getRevision returns the revision with the resource as it was after the change
+func getRevision(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	number, err := parseRevision(params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	rev, err := infradb.GetRevision(number)
+	if err != nil {
+		writeError(w, status.Errorf(codes.NotFound, "unable to find revision %d", number))
+		return
+	}
+	writeResponse(w, http.StatusOK, revisionToJSON(rev, true))
+}
+
+// RegisterRollbackHandler registers the rollback endpoint, which converges the bridge back to a revision
+// through its gRPC API
+func RegisterRollbackHandler(mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	return mux.HandlePath(http.MethodPost, "/v1/admin/revisions/{revision}/rollback", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		rollback(w, r, params, conn)
+	})
+}
+
+// rollbackBundle returns the bundle of the state at the revision and the resources it covers. The resource
+// scope covers the resource of the revision only, the all scope every resource known to the history.
+func rollbackBundle(number uint64, scope string) (*apply.Bundle, map[string]bool, error) {
+	var state map[string]*infradb.Revision
+	switch scope {
+	case "", "resource":
+		rev, err := infradb.GetRevision(number)
+		if err != nil {
+			return nil, nil, status.Errorf(codes.NotFound, "unable to find revision %d", number)
+		}
+		state = map[string]*infradb.Revision{rev.Name: rev}
+	case "all":
+		if _, err := infradb.GetRevision(number); err != nil {
+			return nil, nil, status.Errorf(codes.NotFound, "unable to find revision %d", number)
+		}
+		var err error
+		if state, err = infradb.GetStateAt(number); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, status.Errorf(codes.InvalidArgument, "invalid scope %q", scope)
+	}
+	bundle := &apply.Bundle{}
+	names := map[string]bool{}
+	for name, rev := range state {
+		names[name] = true
+		// a resource without object did not exist at the revision
+		if len(rev.Object) == 0 {
+			continue
+		}
+		if err := bundle.AddObject(rev.Kind, rev.Object); err != nil {
+			return nil, nil, status.Errorf(codes.Internal, "failed to read revision %d: %v", rev.Number, err)
+		}
+	}
+	return bundle, names, nil
+}
+
+// rollback converges the resource of the revision, or with scope=all every resource of the history, back to
+// its state at the revision. The rollback runs as a transaction and is reverted as a whole when it fails.
+func rollback(w http.ResponseWriter, r *http.Request, params map[string]string, conn grpc.ClientConnInterface) {
+	number, err := parseRevision(params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	query := r.URL.Query()
+	bundle, scope, err := rollbackBundle(number, query.Get("scope"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	plan, err := apply.NewScopedPlan(r.Context(), conn, bundle, scope)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := &applyResult{Plan: plan}
+	if query.Get("dry_run") != "true" {
+		ctx, cancel := context.WithTimeout(r.Context(), defaultTransactionTimeout)
+		defer cancel()
+		if err := plan.ApplyAtomic(ctx, query.Get("wait") == "true"); err != nil {
+			out.RolledBack = true
+			out.Error = err.Error()
+			writeResponse(w, runtime.HTTPStatusFromCode(status.Code(err)), out)
+			return
+		}
+		out.Applied = true
+	}
+	writeResponse(w, http.StatusOK, out)
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_Revisions(t *testing.T) {
+	mux := newTestMux(t)
+	const blue = "//network.opiproject.org/vrfs/blue"
+	const red = "//network.opiproject.org/vrfs/red"
+	for _, rev := range []*infradb.Revision{
+		{Name: blue, Kind: "Vrf", Action: "create", Object: []byte(`{"name":"` + blue + `","spec":{"vni":1000}}`)},
+		{Name: blue, Kind: "Vrf", Action: "update", Object: []byte(`{"name":"` + blue + `","spec":{"vni":2000}}`)},
+		{Name: red, Kind: "Vrf", Action: "create", Object: []byte(`{"name":"` + red + `","spec":{"vni":3000}}`)},
+		{Name: blue, Kind: "Vrf", Action: "delete"},
+	} {
+		rev.Time = time.Now()
+		if _, err := infradb.AddRevision(rev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]struct {
+		url   string
+		code  int
+		count int
+	}{
+		"all": {
+			url:   "/v1/admin/revisions",
+			code:  http.StatusOK,
+			count: 4,
+		},
+		"one resource": {
+			url:   "/v1/admin/revisions?name=" + red,
+			code:  http.StatusOK,
+			count: 1,
+		},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if rec.Code != tt.code {
+				t.Fatalf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			out := map[string][]*revision{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+				t.Fatal(err)
+			}
+			if len(out["revisions"]) != tt.count {
+				t.Errorf("expected %d revisions, received %d", tt.count, len(out["revisions"]))
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/revisions/2", nil))
+	out := &revision{}
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Number != 2 || out.Action != "update" || len(out.Object) == 0 {
+		t.Errorf("unexpected revision %+v", out)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/revisions/42", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected code %d, received %d", http.StatusNotFound, rec.Code)
+	}
+
+	// at revision 2 blue has its second vni and red does not exist yet
+	bundle, scope, err := rollbackBundle(2, "all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.Vrfs) != 1 || bundle.Vrfs[0].GetSpec().GetVni() != 2000 || !scope[blue] || !scope[red] {
+		t.Errorf("unexpected bundle %v and scope %v", bundle.Vrfs, scope)
+	}
+	// the rollback of the delete brings nothing back, the resource stays deleted
+	bundle, scope, err = rollbackBundle(4, "resource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.Vrfs) != 0 || len(scope) != 1 || !scope[blue] {
+		t.Errorf("unexpected bundle %v and scope %v", bundle.Vrfs, scope)
+	}
+}
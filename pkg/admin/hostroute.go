@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"sort"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// hostRoute is the json representation of a host route
+type hostRoute struct {
+	Name        string      `json:"name,omitempty"`
+	Vrf         string      `json:"vrf"`
+	Prefixes    []string    `json:"prefixes"`
+	Communities []string    `json:"communities,omitempty"`
+	OperStatus  string      `json:"oper_status,omitempty"`
+	Components  []component `json:"components,omitempty"`
+}
+
+// hostRouteToJSON translates the domain object to its json representation
+func hostRouteToJSON(hr *infradb.HostRoute) *hostRoute {
+	out := &hostRoute{
+		Name:        hr.Name,
+		Vrf:         hr.Spec.Vrf,
+		Communities: hr.Spec.Communities,
+		OperStatus:  hr.Status.OperStatus.String(),
+		Components:  componentsToJSON(hr.Status.Components),
+	}
+	for _, prefix := range hr.Spec.Prefixes {
+		out.Prefixes = append(out.Prefixes, prefix.String())
+	}
+	return out
+}
+
+// hostRouteSpecFromJSON translates the json representation to the spec of the domain object,
+// an address without length is a host route
+func hostRouteSpecFromJSON(in *hostRoute) (*infradb.HostRouteSpec, error) {
+	spec := &infradb.HostRouteSpec{Vrf: in.Vrf, Communities: in.Communities}
+	for _, prefix := range in.Prefixes {
+		if ip := net.ParseIP(prefix); ip != nil && ip.To4() != nil {
+			spec.Prefixes = append(spec.Prefixes, &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)})
+			continue
+		}
+		_, ipnet, err := net.ParseCIDR(prefix)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid prefix %s: %v", prefix, err)
+		}
+		spec.Prefixes = append(spec.Prefixes, ipnet)
+	}
+	return spec, nil
+}
+
+// createHostRoute creates a host route advertised into the EVPN of a vrf
+func createHostRoute(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &hostRoute{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("hostroutes", resourceID)
+	spec, err := hostRouteSpecFromJSON(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	hr, err := infradb.NewHostRoute(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetHostRoute(name); err == nil {
+		if !sameSpec(hr.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("hostroutes", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createHostRoute(): Already existing Host Route with id %v", name)
+		writeResponse(w, http.StatusOK, hostRouteToJSON(existing))
+		return
+	}
+	if err := infradb.CreateHostRoute(hr); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, hostRouteToJSON(hr))
+}
+
+// updateHostRoute replaces the prefixes and the communities of a host route
+func updateHostRoute(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &hostRoute{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	name := fullName("hostroutes", params["hostroute"])
+	spec, err := hostRouteSpecFromJSON(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	hr, err := infradb.NewHostRoute(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	if err := infradb.UpdateHostRoute(hr, ifMatch(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, hostRouteToJSON(hr))
+}
+
+// getHostRoute returns a host route
+func getHostRoute(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	hr, err := infradb.GetHostRoute(fullName("hostroutes", params["hostroute"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, hostRouteToJSON(hr))
+}
+
+// listHostRoutes returns all the host routes
+func listHostRoutes(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	hrs, err := infradb.GetAllHostRoutes()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(hrs, func(i, j int) bool { return hrs[i].Name < hrs[j].Name })
+	out := []*hostRoute{}
+	for _, hr := range hrs {
+		out = append(out, hostRouteToJSON(hr))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"host_routes": out})
+}
+
+// deleteHostRoute deletes a host route and withdraws its routes
+func deleteHostRoute(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteHostRoute(fullName("hostroutes", params["hostroute"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
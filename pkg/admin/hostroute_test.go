@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+var testVpc = fullName("vrfs", "vpc")
+
+// createTestVpc creates the vrf "vpc" with a VNI and the loopback 10.255.0.1/32
+func createTestVpc(t *testing.T) {
+	vni := uint32(3000)
+	vrf, err := infradb.NewVrfWithArgs(testVpc, &vni, &net.IPNet{IP: net.ParseIP("10.255.0.1").To4(), Mask: net.CIDRMask(32, 32)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateVrf(vrf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sendHostRoute(t *testing.T, mux http.Handler, method, url string, in *hostRoute) *httptest.ResponseRecorder {
+	body, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(method, url, bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func Test_CreateHostRoute(t *testing.T) {
+	tests := map[string]struct {
+		in   hostRoute
+		code int
+	}{
+		"virtual ips with communities": {
+			in:   hostRoute{Vrf: testVpc, Prefixes: []string{"10.9.0.1/32", "10.9.0.2"}, Communities: []string{"65000:100", "no-export"}},
+			code: http.StatusOK,
+		},
+		"not a host route": {
+			in:   hostRoute{Vrf: testVpc, Prefixes: []string{"10.9.0.0/24"}},
+			code: http.StatusBadRequest,
+		},
+		"ipv6 host route": {
+			in:   hostRoute{Vrf: testVpc, Prefixes: []string{"2001:db8::1/128"}},
+			code: http.StatusBadRequest,
+		},
+		"duplicated host route": {
+			in:   hostRoute{Vrf: testVpc, Prefixes: []string{"10.9.0.1/32", "10.9.0.1/32"}},
+			code: http.StatusBadRequest,
+		},
+		"invalid community": {
+			in:   hostRoute{Vrf: testVpc, Prefixes: []string{"10.9.0.1/32"}, Communities: []string{"65000"}},
+			code: http.StatusBadRequest,
+		},
+		"loopback of the vrf": {
+			in:   hostRoute{Vrf: testVpc, Prefixes: []string{"10.255.0.1/32"}},
+			code: http.StatusBadRequest,
+		},
+		"vrf without vni": {
+			in:   hostRoute{Vrf: testVrfA, Prefixes: []string{"10.9.0.1/32"}},
+			code: http.StatusBadRequest,
+		},
+		"unknown vrf": {
+			in:   hostRoute{Vrf: fullName("vrfs", "unknown"), Prefixes: []string{"10.9.0.1/32"}},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			createTestVpc(t)
+
+			rec := sendHostRoute(t, mux, http.MethodPost, "/v1/admin/hostroutes?id=vips", &tt.in)
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &hostRoute{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("hostroutes", "vips") || len(out.Prefixes) != 2 || out.Prefixes[1] != "10.9.0.2/32" {
+				t.Errorf("unexpected host route %+v", out)
+			}
+			// the vrf cannot be deleted while it advertises the host routes
+			if err := infradb.DeleteVrf(testVpc); err == nil {
+				t.Errorf("expected the vrf to be in use")
+			}
+		})
+	}
+}
+
+func Test_UpdateHostRoute(t *testing.T) {
+	mux := newTestMux(t)
+	createTestVpc(t)
+	url := "/v1/admin/hostroutes/vips"
+	in := &hostRoute{Vrf: testVpc, Prefixes: []string{"10.9.0.1/32"}}
+	if rec := sendHostRoute(t, mux, http.MethodPost, "/v1/admin/hostroutes?id=vips", in); rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	// another host route cannot advertise the same address
+	if rec := sendHostRoute(t, mux, http.MethodPost, "/v1/admin/hostroutes?id=other", in); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected code %d, received %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+
+	in = &hostRoute{Vrf: testVpc, Prefixes: []string{"10.9.0.1/32", "10.9.0.3/32"}, Communities: []string{"65000:200"}}
+	if rec := sendHostRoute(t, mux, http.MethodPut, url, in); rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	hr, err := infradb.GetHostRoute(fullName("hostroutes", "vips"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hr.Spec.Prefixes) != 2 || !reflect.DeepEqual(hr.Spec.Communities, []string{"65000:200"}) {
+		t.Errorf("unexpected host route spec %+v", hr.Spec)
+	}
+
+	in.Vrf = testVrfB
+	if rec := sendHostRoute(t, mux, http.MethodPut, url, in); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected code %d, received %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+	in.Vrf = testVpc
+	if rec := sendHostRoute(t, mux, http.MethodPut, "/v1/admin/hostroutes/unknown", in); rec.Code != http.StatusNotFound {
+		t.Errorf("expected code %d, received %d: %s", http.StatusNotFound, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/macsec"
+)
+
+// sriovInventory is the json representation of the SR-IOV capabilities of a port
+type sriovInventory struct {
+	TotalVfs int `json:"total_vfs"`
+	NumVfs   int `json:"num_vfs"`
+}
+
+// macsecInventory is the json representation of the MACsec protection of an uplink
+type macsecInventory struct {
+	Mode         string     `json:"mode"`
+	Device       string     `json:"device"`
+	Protected    bool       `json:"protected"`
+	Cipher       string     `json:"cipher,omitempty"`
+	EncodingSa   *int       `json:"encoding_sa,omitempty"`
+	LastRotation *time.Time `json:"last_rotation,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// portInventory is the json representation of a physical port of the DPU
+type portInventory struct {
+	Name            string           `json:"name"`
+	MacAddress      string           `json:"mac_address"`
+	OperState       string           `json:"oper_state"`
+	Mtu             int              `json:"mtu"`
+	SpeedMbps       int              `json:"speed_mbps,omitempty"`
+	PhysPortName    string           `json:"phys_port_name,omitempty"`
+	PciAddress      string           `json:"pci_address"`
+	Driver          string           `json:"driver,omitempty"`
+	FirmwareVersion string           `json:"firmware_version,omitempty"`
+	Sriov           *sriovInventory  `json:"sriov,omitempty"`
+	EswitchMode     string           `json:"eswitch_mode,omitempty"`
+	Macsec          *macsecInventory `json:"macsec,omitempty"`
+}
+
+// listPortInventory returns the physical ports of the DPU with their capabilities, so that the
+// orchestrators know what they can attach before creating the resources
+func listPortInventory(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	ports, err := gen_linux.GetPortInventory()
+	if err != nil {
+		writeError(w, status.Errorf(codes.Unavailable, "failed to list the ports: %v", err))
+		return
+	}
+	protections := map[string]*macsecInventory{}
+	for _, s := range macsec.Statuses() {
+		protection := &macsecInventory{
+			Mode:         s.Mode,
+			Device:       s.Device,
+			Protected:    s.Protected,
+			Cipher:       s.Cipher,
+			LastRotation: timeToJSON(s.LastRotation),
+			Error:        s.Error,
+		}
+		if s.EncodingSa >= 0 {
+			encodingSa := s.EncodingSa
+			protection.EncodingSa = &encodingSa
+		}
+		protections[s.Interface] = protection
+	}
+	out := []*portInventory{}
+	for _, p := range ports {
+		port := &portInventory{
+			Name:            p.Name,
+			MacAddress:      p.MacAddress,
+			OperState:       p.OperState,
+			Mtu:             p.Mtu,
+			SpeedMbps:       p.Speed,
+			PhysPortName:    p.PhysPortName,
+			PciAddress:      p.PciAddress,
+			Driver:          p.Driver,
+			FirmwareVersion: p.Firmware,
+			EswitchMode:     p.EswitchMode,
+			Macsec:          protections[p.Name],
+		}
+		if p.TotalVfs != 0 {
+			port.Sriov = &sriovInventory{TotalVfs: p.TotalVfs, NumVfs: p.NumVfs}
+		}
+		out = append(out, port)
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"ports": out})
+}
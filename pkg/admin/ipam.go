@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// ipAllocation is the json representation of an address handed out from the subnet of an svi
+type ipAllocation struct {
+	Address    string `json:"address,omitempty"`
+	Owner      string `json:"owner,omitempty"`
+	MacAddress string `json:"mac_address,omitempty"`
+}
+
+// ipAllocationToJSON translates the domain object to its json representation
+func ipAllocationToJSON(a *infradb.IPAllocation) *ipAllocation {
+	return &ipAllocation{Address: a.Address.String(), Owner: a.Owner, MacAddress: a.MacAddress}
+}
+
+// allocateIP hands out an address from the subnets of the svi
+func allocateIP(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &ipAllocation{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	var requested net.IP
+	if in.Address != "" {
+		if requested = net.ParseIP(in.Address); requested == nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid address %s", in.Address))
+			return
+		}
+	}
+	mac := ""
+	if in.MacAddress != "" {
+		hw, err := net.ParseMAC(in.MacAddress)
+		if err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid mac address %s: %v", in.MacAddress, err))
+			return
+		}
+		mac = hw.String()
+	}
+	a, err := infradb.AllocateIP(fullName("svis", params["svi"]), in.Owner, mac, requested)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, ipAllocationToJSON(a))
+}
+
+// listIPAllocations returns the addresses handed out from the subnets of the svi
+func listIPAllocations(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	allocations, err := infradb.GetAllIPAllocations(fullName("svis", params["svi"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := []*ipAllocation{}
+	for _, a := range allocations {
+		out = append(out, ipAllocationToJSON(a))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"allocations": out})
+}
+
+// releaseIP returns an address to the subnets of the svi
+func releaseIP(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	address := net.ParseIP(params["address"])
+	if address == nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "invalid address %s", params["address"]))
+		return
+	}
+	err := infradb.ReleaseIP(fullName("svis", params["svi"]), address)
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/pbconv"
+)
+
+// createTestSvi creates the svi "web" of testVrfA with the gateway 10.0.0.1/29
+func createTestSvi(t *testing.T) {
+	lbName := fullName("bridges", "web")
+	lb, err := pbconv.LogicalBridgeFromPb(&pb.LogicalBridge{Name: lbName, Spec: &pb.LogicalBridgeSpec{
+		VlanId: 10,
+		VtepIpPrefix: &pc.IPPrefix{
+			Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 0x0a010101}},
+			Len:  32,
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateLB(lb); err != nil {
+		t.Fatal(err)
+	}
+	svi, err := pbconv.SviFromPb(&pb.Svi{Name: fullName("svis", "web"), Spec: &pb.SviSpec{
+		Vrf:           testVrfA,
+		LogicalBridge: lbName,
+		MacAddress:    []byte{0xaa, 0xbb, 0xcc, 0, 0, 1},
+		GwIpPrefix: []*pc.IPPrefix{{
+			Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 0x0a000001}},
+			Len:  29,
+		}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateSvi(svi); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_AllocateIP(t *testing.T) {
+	tests := map[string]struct {
+		existing []ipAllocation
+		in       ipAllocation
+		svi      string
+		code     int
+		address  string
+	}{
+		"first free address skips network and gateway": {
+			in:      ipAllocation{Owner: "pod-a"},
+			code:    http.StatusOK,
+			address: "10.0.0.2",
+		},
+		"next address": {
+			existing: []ipAllocation{{Owner: "pod-a"}},
+			in:       ipAllocation{Owner: "pod-b"},
+			code:     http.StatusOK,
+			address:  "10.0.0.3",
+		},
+		"same owner gets the same address": {
+			existing: []ipAllocation{{Owner: "pod-a"}},
+			in:       ipAllocation{Owner: "pod-a"},
+			code:     http.StatusOK,
+			address:  "10.0.0.2",
+		},
+		"requested address": {
+			in:      ipAllocation{Address: "10.0.0.5", MacAddress: "AA:BB:CC:00:00:05"},
+			code:    http.StatusOK,
+			address: "10.0.0.5",
+		},
+		"requested address in use": {
+			existing: []ipAllocation{{Address: "10.0.0.5"}},
+			in:       ipAllocation{Address: "10.0.0.5"},
+			code:     http.StatusBadRequest,
+		},
+		"gateway is reserved": {
+			in:   ipAllocation{Address: "10.0.0.1"},
+			code: http.StatusBadRequest,
+		},
+		"broadcast is reserved": {
+			in:   ipAllocation{Address: "10.0.0.7"},
+			code: http.StatusBadRequest,
+		},
+		"out of subnet": {
+			in:   ipAllocation{Address: "10.0.1.5"},
+			code: http.StatusBadRequest,
+		},
+		"pool exhausted": {
+			existing: []ipAllocation{{}, {}, {}, {}, {}},
+			in:       ipAllocation{},
+			code:     http.StatusTooManyRequests,
+		},
+		"unknown svi": {
+			svi:  "db",
+			in:   ipAllocation{},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			createTestSvi(t)
+			for _, a := range tt.existing {
+				body, _ := json.Marshal(a)
+				req := httptest.NewRequest(http.MethodPost, "/v1/admin/svis/web/allocations", bytes.NewReader(body))
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("failed to create existing allocation: %s", rec.Body.String())
+				}
+			}
+			svi := tt.svi
+			if svi == "" {
+				svi = "web"
+			}
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/svis/"+svi+"/allocations", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Fatalf("got code %d, want %d: %s", rec.Code, tt.code, rec.Body.String())
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+			out := &ipAllocation{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Address != tt.address {
+				t.Errorf("got address %s, want %s", out.Address, tt.address)
+			}
+		})
+	}
+}
+
+func Test_ReleaseIP(t *testing.T) {
+	mux := newTestMux(t)
+	createTestSvi(t)
+	if _, err := infradb.AllocateIP(fullName("svis", "web"), "pod-a", "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/admin/svis/web/allocations/10.0.0.2", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got code %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/v1/admin/svis/web/allocations/10.0.0.2", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("got code %d for a released address", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/admin/svis/web/allocations", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	out := map[string][]ipAllocation{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out["allocations"]) != 0 {
+		t.Errorf("got allocations %v after release", out["allocations"])
+	}
+}
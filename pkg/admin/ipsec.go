@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/ipsec"
+)
+
+// ipsecTunnel is the json representation of an encrypted tunnel, the spis are in hex
+type ipsecTunnel struct {
+	Peer            string     `json:"peer"`
+	Source          string     `json:"source"`
+	OutSpi          string     `json:"out_spi,omitempty"`
+	InSpis          []string   `json:"in_spis,omitempty"`
+	LastRekey       *time.Time `json:"last_rekey,omitempty"`
+	TxBytes         uint64     `json:"tx_bytes"`
+	TxPackets       uint64     `json:"tx_packets"`
+	RxBytes         uint64     `json:"rx_bytes"`
+	RxPackets       uint64     `json:"rx_packets"`
+	ReplayErrors    uint64     `json:"replay_errors"`
+	IntegrityErrors uint64     `json:"integrity_errors"`
+	Error           string     `json:"error,omitempty"`
+}
+
+// spiToJSON formats an spi as ip xfrm does
+func spiToJSON(spi uint32) string {
+	if spi == 0 {
+		return ""
+	}
+	return fmt.Sprintf("0x%08x", spi)
+}
+
+// listIpsecTunnels returns the tunnels to the remote VTEPs with the counters of their SAs
+func listIpsecTunnels(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	tunnels := []*ipsecTunnel{}
+	for _, t := range ipsec.Tunnels() {
+		out := &ipsecTunnel{
+			Peer:            t.Peer,
+			Source:          t.Source,
+			OutSpi:          spiToJSON(t.OutSpi),
+			LastRekey:       timeToJSON(t.LastRekey),
+			TxBytes:         t.TxBytes,
+			TxPackets:       t.TxPackets,
+			RxBytes:         t.RxBytes,
+			RxPackets:       t.RxPackets,
+			ReplayErrors:    t.ReplayErrors,
+			IntegrityErrors: t.IntegrityErrors,
+			Error:           t.Error,
+		}
+		for _, spi := range t.InSpis {
+			out.InSpis = append(out.InSpis, spiToJSON(spi))
+		}
+		tunnels = append(tunnels, out)
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"tunnels": tunnels})
+}
@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// portIsolation is the json representation of the isolation of a bridge port, inherited when it follows
+// the default of its logical bridges
+type portIsolation struct {
+	Isolated  *bool `json:"isolated"`
+	Inherited bool  `json:"inherited,omitempty"`
+}
+
+// bridgeIsolation is the json representation of the default isolation of the access ports of a logical bridge
+type bridgeIsolation struct {
+	IsolatedPorts bool `json:"isolated_ports"`
+}
+
+// toPortIsolation returns the isolation of the bridge port, isolated in any of its logical bridges
+func toPortIsolation(bp *infradb.BridgePort) (*portIsolation, error) {
+	if bp.Spec.Isolated != nil {
+		return &portIsolation{Isolated: bp.Spec.Isolated}, nil
+	}
+	isolated := false
+	for _, name := range bp.Spec.LogicalBridges {
+		lb, err := infradb.GetLB(name)
+		if err != nil {
+			return nil, err
+		}
+		isolated = isolated || bp.Spec.IsolatedIn(lb.Spec)
+	}
+	return &portIsolation{Isolated: &isolated, Inherited: true}, nil
+}
+
+// getBridgePortIsolation returns the isolation of a bridge port
+func getBridgePortIsolation(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	bp, err := infradb.GetBP(fullName("ports", params["bridgeport"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out, err := toPortIsolation(bp)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, out)
+}
+
+// setBridgePortIsolation isolates a bridge port from the other isolated ports, or lets it forward to them
+func setBridgePortIsolation(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &portIsolation{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	if in.Isolated == nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "isolated is required"))
+		return
+	}
+	bp, err := infradb.SetBridgePortIsolation(fullName("ports", params["bridgeport"]), in.Isolated, ifMatch(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, &portIsolation{Isolated: bp.Spec.Isolated})
+}
+
+// deleteBridgePortIsolation makes a bridge port follow the default isolation of its logical bridges
+func deleteBridgePortIsolation(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if _, err := infradb.SetBridgePortIsolation(fullName("ports", params["bridgeport"]), nil, ifMatch(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
+
+// getLogicalBridgeIsolation returns whether the access ports of a logical bridge are isolated by default
+func getLogicalBridgeIsolation(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	lb, err := infradb.GetLB(fullName("bridges", params["logicalbridge"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, &bridgeIsolation{IsolatedPorts: lb.Spec.IsolatedPorts})
+}
+
+// setLogicalBridgeIsolation sets whether the access ports of a logical bridge are isolated by default
+func setLogicalBridgeIsolation(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &bridgeIsolation{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	lb, err := infradb.SetLogicalBridgeIsolation(fullName("bridges", params["logicalbridge"]), in.IsolatedPorts, ifMatch(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, &bridgeIsolation{IsolatedPorts: lb.Spec.IsolatedPorts})
+}
+
+// deleteLogicalBridgeIsolation lets the access ports of a logical bridge forward to each other by default
+func deleteLogicalBridgeIsolation(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if _, err := infradb.SetLogicalBridgeIsolation(fullName("bridges", params["logicalbridge"]), false, ifMatch(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
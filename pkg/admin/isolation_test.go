@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_PortIsolation(t *testing.T) {
+	mux := newTestMux(t)
+	createTestBridgePort(t)
+
+	do := func(method, url, body string, code int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, bytes.NewReader([]byte(body)))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != code {
+			t.Fatalf("%s %s: expected code %d, received %d: %s", method, url, code, rec.Code, rec.Body.String())
+		}
+		return rec
+	}
+	isolation := func(expected bool, inherited bool) {
+		t.Helper()
+		out := portIsolation{}
+		if err := json.Unmarshal(do(http.MethodGet, "/v1/admin/bridgeports/eth2/isolation", "", http.StatusOK).Body.Bytes(), &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.Isolated == nil || *out.Isolated != expected || out.Inherited != inherited {
+			t.Errorf("expected the isolation %t (inherited %t), received %+v", expected, inherited, out)
+		}
+	}
+
+	isolation(false, true)
+
+	// the access ports of the logical bridge are isolated by default, the bridge port is programmed again
+	before, err := infradb.GetBP(testBridgePort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	do(http.MethodPut, "/v1/admin/logicalbridges/psec/isolation", `{"isolated_ports": true}`, http.StatusOK)
+	isolation(true, true)
+	after, err := infradb.GetBP(testBridgePort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.ResourceVersion == before.ResourceVersion {
+		t.Error("expected the bridge port to be programmed again")
+	}
+
+	// the flag of the bridge port overrides the default and outlives an update through the opi-api
+	do(http.MethodPut, "/v1/admin/bridgeports/eth2/isolation", `{"isolated": false}`, http.StatusOK)
+	after.Spec.Isolated = nil
+	if err := infradb.UpdateBP(after); err != nil {
+		t.Fatal(err)
+	}
+	isolation(false, false)
+
+	do(http.MethodDelete, "/v1/admin/bridgeports/eth2/isolation", "", http.StatusOK)
+	isolation(true, true)
+	do(http.MethodDelete, "/v1/admin/logicalbridges/psec/isolation", "", http.StatusOK)
+	isolation(false, true)
+
+	do(http.MethodPut, "/v1/admin/bridgeports/eth2/isolation", `{}`, http.StatusBadRequest)
+	do(http.MethodPut, "/v1/admin/bridgeports/unknown/isolation", `{"isolated": true}`, http.StatusNotFound)
+	do(http.MethodPut, "/v1/admin/logicalbridges/unknown/isolation", `{"isolated_ports": true}`, http.StatusNotFound)
+}
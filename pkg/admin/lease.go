@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// lease is the json representation of the lease of an ephemeral resource
+type lease struct {
+	Name string `json:"name,omitempty"`
+	// Duration is a Go duration, e.g. 30m
+	Duration string    `json:"duration"`
+	Expires  time.Time `json:"expires,omitempty"`
+}
+
+func leaseToJSON(l *infradb.Lease) *lease {
+	return &lease{Name: l.Name, Duration: l.Duration.String(), Expires: l.Expires}
+}
+
+// listLeases returns the leases of the ephemeral resources in the order of their expiry
+func listLeases(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	leases, err := infradb.GetAllLeases()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := make([]*lease, 0, len(leases))
+	for _, l := range leases {
+		out = append(out, leaseToJSON(l))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"leases": out})
+}
+
+// setLease gives a resource a lease, the resource is deleted when the lease is not renewed in time
+func setLease(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &lease{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	duration, err := time.ParseDuration(in.Duration)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "invalid lease duration %q: %v", in.Duration, err))
+		return
+	}
+	l, err := infradb.SetLease(fullName(params["collection"], params["resource"]), duration)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, leaseToJSON(l))
+}
+
+// keepAliveLease renews the lease of a resource by its duration
+func keepAliveLease(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	l, err := infradb.RenewLease(fullName(params["collection"], params["resource"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, leaseToJSON(l))
+}
+
+// deleteLease removes the lease of a resource, which is then kept until deleted
+func deleteLease(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	if err := infradb.DeleteLease(fullName(params["collection"], params["resource"])); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_SetLease(t *testing.T) {
+	tests := map[string]struct {
+		url  string
+		in   lease
+		code int
+	}{
+		"valid request": {
+			url:  "/v1/admin/leases/routeleaks/opi-leak",
+			in:   lease{Duration: "30m"},
+			code: http.StatusOK,
+		},
+		"invalid duration": {
+			url:  "/v1/admin/leases/routeleaks/opi-leak",
+			in:   lease{Duration: "soon"},
+			code: http.StatusBadRequest,
+		},
+		"negative duration": {
+			url:  "/v1/admin/leases/routeleaks/opi-leak",
+			in:   lease{Duration: "-5m"},
+			code: http.StatusBadRequest,
+		},
+		"unknown collection": {
+			url:  "/v1/admin/leases/widgets/opi-leak",
+			in:   lease{Duration: "30m"},
+			code: http.StatusBadRequest,
+		},
+		"missing resource": {
+			url:  "/v1/admin/leases/routeleaks/unknown",
+			in:   lease{Duration: "30m"},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			createTestRouteLeak(t, fullName("routeleaks", "opi-leak"), testVrfA, testVrfB, "10.0.0.0/24")
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPut, tt.url, bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Fatalf("expected code %d, got %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func Test_ExpireLeases(t *testing.T) {
+	mux := newTestMux(t)
+	name := fullName("routeleaks", "opi-leak")
+	createTestRouteLeak(t, name, testVrfA, testVrfB, "10.0.0.0/24")
+
+	body, _ := json.Marshal(lease{Duration: "30m"})
+	req := httptest.NewRequest(http.MethodPut, "/v1/admin/leases/routeleaks/opi-leak", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	// the lease has not expired yet
+	deleted, err := infradb.ExpireLeases(time.Now())
+	if err != nil || len(deleted) != 0 {
+		t.Fatalf("expected nothing deleted, got %v, %v", deleted, err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/admin/leases/routeleaks/opi-leak/keepalive", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	renewed := &lease{}
+	if err := json.Unmarshal(rec.Body.Bytes(), renewed); err != nil {
+		t.Fatal(err)
+	}
+	if renewed.Name != name || renewed.Duration != "30m0s" {
+		t.Errorf("unexpected lease %+v", renewed)
+	}
+
+	deleted, err = infradb.ExpireLeases(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0] != name {
+		t.Fatalf("expected %s deleted, got %v", name, deleted)
+	}
+	rl, err := infradb.GetRouteLeak(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rl.Status.OperStatus != infradb.OperStatusToBeDeleted {
+		t.Errorf("expected %s to be deleted, got %v", name, rl.Status.OperStatus)
+	}
+	leases, err := infradb.GetAllLeases()
+	if err != nil || len(leases) != 0 {
+		t.Errorf("expected the lease to be dropped, got %v, %v", leases, err)
+	}
+}
+
+func Test_DeleteDropsLease(t *testing.T) {
+	mux := newTestMux(t)
+	name := fullName("routeleaks", "opi-leak")
+	createTestRouteLeak(t, name, testVrfA, testVrfB, "10.0.0.0/24")
+	if _, err := infradb.SetLease(name, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/admin/routeleaks/opi-leak", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	leases, err := infradb.GetAllLeases()
+	if err != nil || len(leases) != 0 {
+		t.Errorf("expected the lease to be dropped with the resource, got %v, %v", leases, err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/admin/leases/routeleaks/opi-leak/keepalive", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected code %d, got %d: %s", http.StatusNotFound, rec.Code, rec.Body.String())
+	}
+}
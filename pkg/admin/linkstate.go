@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/netlink"
+)
+
+// linkState is the json representation of the state of a kernel device
+type linkState struct {
+	Name       string    `json:"name"`
+	Owner      string    `json:"owner,omitempty"`
+	OperState  string    `json:"oper_state"`
+	Carrier    bool      `json:"carrier"`
+	Flaps      uint64    `json:"flaps"`
+	FdbChanges uint64    `json:"fdb_changes"`
+	LastChange time.Time `json:"last_change"`
+}
+
+// listLinkStates returns the state of the kernel devices reported by the netlink notifications,
+// the owner query parameter restricts them to the devices of a resource
+func listLinkStates(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	owner := r.URL.Query().Get("owner")
+	out := []linkState{}
+	for _, state := range netlink.GetLinkStates() {
+		if owner != "" && state.Owner != owner {
+			continue
+		}
+		out = append(out, linkState{
+			Name:       state.Name,
+			Owner:      state.Owner,
+			OperState:  state.OperState,
+			Carrier:    state.Carrier,
+			Flaps:      state.Flaps,
+			FdbChanges: state.FdbChanges,
+			LastChange: state.LastChange,
+		})
+	}
+	writeResponse(w, http.StatusOK, out)
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/lldp"
+)
+
+// lldpVlan is the json representation of a VLAN announced by a neighbor
+type lldpVlan struct {
+	ID   uint16 `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// lldpNeighbor is the json representation of a neighbor discovered on an uplink
+type lldpNeighbor struct {
+	Interface         string     `json:"interface"`
+	ChassisID         string     `json:"chassis_id"`
+	PortID            string     `json:"port_id"`
+	PortDescription   string     `json:"port_description,omitempty"`
+	SystemName        string     `json:"system_name,omitempty"`
+	SystemDescription string     `json:"system_description,omitempty"`
+	ManagementAddress string     `json:"management_address,omitempty"`
+	PortVlanID        uint16     `json:"port_vlan_id,omitempty"`
+	Vlans             []lldpVlan `json:"vlans,omitempty"`
+	Expires           time.Time  `json:"expires"`
+}
+
+// lldpCabling is the json representation of the check of the cabling of an uplink
+type lldpCabling struct {
+	Interface  string `json:"interface"`
+	SystemName string `json:"system_name,omitempty"`
+	PortID     string `json:"port_id,omitempty"`
+	Cabled     bool   `json:"cabled"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// neighborToJSON translates a neighbor to its json representation
+func neighborToJSON(n *lldp.Neighbor) *lldpNeighbor {
+	out := &lldpNeighbor{
+		Interface:         n.Interface,
+		ChassisID:         n.ChassisID,
+		PortID:            n.PortID,
+		PortDescription:   n.PortDescription,
+		SystemName:        n.SystemName,
+		SystemDescription: n.SystemDescription,
+		PortVlanID:        n.PortVlanID,
+		Expires:           n.LastSeen.Add(n.TTL),
+	}
+	if n.ManagementAddress != nil {
+		out.ManagementAddress = n.ManagementAddress.String()
+	}
+	for _, v := range n.Vlans {
+		out.Vlans = append(out.Vlans, lldpVlan{ID: v.ID, Name: v.Name})
+	}
+	return out
+}
+
+// listLldpNeighbors returns the neighbors discovered on the uplinks and the check of their cabling
+func listLldpNeighbors(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	neighbors := lldp.Neighbors()
+	out := make([]*lldpNeighbor, 0, len(neighbors))
+	for i := range neighbors {
+		out = append(out, neighborToJSON(&neighbors[i]))
+	}
+	cabling := []*lldpCabling{}
+	for _, c := range lldp.CablingStatus() {
+		cabling = append(cabling, &lldpCabling{
+			Interface:  c.Interface,
+			SystemName: c.SystemName,
+			PortID:     c.PortID,
+			Cabled:     c.Cabled,
+			Reason:     c.Reason,
+		})
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"neighbors": out, "cabling": cabling})
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/macmobility"
+)
+
+// defaultTopMovers is the number of movers returned without the top query
+const defaultTopMovers = 10
+
+// macMover is the json representation of a MAC address of a logical bridge with its moves
+type macMover struct {
+	Mac string `json:"mac"`
+	// Moves counts the moves since the address is tracked, RecentMoves those within the window of the alarm
+	Moves       int        `json:"moves"`
+	RecentMoves int        `json:"recent_moves"`
+	LastMove    *time.Time `json:"last_move,omitempty"`
+	// Location is the remote VTEP the address is learnt from, empty when it is learnt locally
+	Location string `json:"location,omitempty"`
+}
+
+// macMoves is the json representation of the moves of the MAC addresses of a logical bridge
+type macMoves struct {
+	Vni uint32 `json:"vni"`
+	// TotalMoves counts the moves of the addresses no longer learnt too
+	TotalMoves int         `json:"total_moves"`
+	TopMovers  []*macMover `json:"top_movers"`
+}
+
+// getLogicalBridgeMacMoves returns the moves of the MAC addresses of a logical bridge, the addresses moving the most
+// first
+func getLogicalBridgeMacMoves(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	top := defaultTopMovers
+	if q := r.URL.Query().Get("top"); q != "" {
+		n, err := strconv.Atoi(q)
+		if err != nil || n < 1 {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid top %s", q))
+			return
+		}
+		top = n
+	}
+	lb, err := infradb.GetLB(fullName("bridges", params["logicalbridge"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if lb.Spec.Vni == nil {
+		writeError(w, status.Errorf(codes.FailedPrecondition, "logical bridge %s has no vni", lb.Name))
+		return
+	}
+	if !macmobility.Enabled() {
+		writeError(w, status.Error(codes.FailedPrecondition, "the mac mobility tracking is not enabled"))
+		return
+	}
+	stats := macmobility.GetStats(*lb.Spec.Vni, top)
+	out := &macMoves{Vni: stats.Vni, TotalMoves: stats.Total, TopMovers: []*macMover{}}
+	for _, m := range stats.Top {
+		out.TopMovers = append(out.TopMovers, &macMover{
+			Mac:         m.Mac,
+			Moves:       m.Moves,
+			RecentMoves: m.Recent,
+			LastMove:    timeToJSON(m.LastMove),
+			Location:    m.Location,
+		})
+	}
+	writeResponse(w, http.StatusOK, out)
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/macmobility"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// macBackend reports fixed MAC addresses
+type macBackend struct {
+	encapBackend
+	macs []routing.EvpnMac
+}
+
+func (b *macBackend) EvpnMacs(context.Context) ([]routing.EvpnMac, error) {
+	return b.macs, nil
+}
+
+func Test_GetLogicalBridgeMacMoves(t *testing.T) {
+	mux := newTestMux(t)
+	vni := uint32(1000)
+	if err := createTestBridge("web", 30, &vni); err != nil {
+		t.Fatal(err)
+	}
+	if err := createTestBridge("local", 40, nil); err != nil {
+		t.Fatal(err)
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	tests := map[string]struct {
+		path string
+		code int
+	}{
+		"not tracked":    {path: "/v1/admin/logicalbridges/web/macmoves", code: http.StatusBadRequest},
+		"invalid top":    {path: "/v1/admin/logicalbridges/web/macmoves?top=0", code: http.StatusBadRequest},
+		"unknown bridge": {path: "/v1/admin/logicalbridges/unknown/macmoves", code: http.StatusNotFound},
+		"bridge w/o vni": {path: "/v1/admin/logicalbridges/local/macmoves", code: http.StatusBadRequest},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if rec := get(tt.path); rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	cfg := &config.Config{MacMobility: config.MacMobilityConfig{Enabled: true}}
+	backend := &macBackend{encapBackend: encapBackend{name: "test-mac"}, macs: []routing.EvpnMac{
+		{Vni: 1000, Mac: "aa:bb:cc:00:00:01", RemoteVtep: "10.0.0.2", RemoteSeq: 3},
+	}}
+	if err := macmobility.Start(ctx, cfg, backend); err != nil {
+		t.Fatal(err)
+	}
+	rec := get("/v1/admin/logicalbridges/web/macmoves?top=5")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code 200, received %d: %s", rec.Code, rec.Body.String())
+	}
+	out := &macMoves{}
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatal(err)
+	}
+	// the first reading only learns the sequence numbers
+	if out.Vni != vni || out.TotalMoves != 0 || len(out.TopMovers) != 0 {
+		t.Errorf("expected no moves of vni %d, received %+v", vni, out)
+	}
+}
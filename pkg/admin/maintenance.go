@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/maintenance"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// maintenanceRequest is the json representation of the request entering the maintenance,
+// the fields left empty take the values of the maintenance section of the config
+type maintenanceRequest struct {
+	Mode string `json:"mode,omitempty"`
+	// DrainTimer and SviInterval are Go durations, e.g. 30s
+	DrainTimer  string `json:"drain_timer,omitempty"`
+	SviInterval string `json:"svi_interval,omitempty"`
+	// SviOrder holds the ids of the svis
+	SviOrder []string `json:"svi_order,omitempty"`
+}
+
+// maintenanceStep is the json representation of a step of the drain
+type maintenanceStep struct {
+	Name  string     `json:"name"`
+	Done  bool       `json:"done"`
+	At    *time.Time `json:"at,omitempty"`
+	Error string     `json:"error,omitempty"`
+}
+
+// maintenanceStatus is the json representation of the maintenance state of the node
+type maintenanceStatus struct {
+	State      string             `json:"state"`
+	Mode       string             `json:"mode,omitempty"`
+	Started    *time.Time         `json:"started,omitempty"`
+	SvisDownAt *time.Time         `json:"svis_down_at,omitempty"`
+	Progress   int                `json:"progress"`
+	Steps      []*maintenanceStep `json:"steps,omitempty"`
+}
+
+// timeToJSON omits the zero time
+func timeToJSON(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// maintenanceToJSON translates the maintenance state to its json representation
+func maintenanceToJSON(s *maintenance.Status) *maintenanceStatus {
+	out := &maintenanceStatus{
+		State:      string(s.State),
+		Mode:       string(s.Mode),
+		Started:    timeToJSON(s.Started),
+		SvisDownAt: timeToJSON(s.SvisDownAt),
+		Progress:   s.Progress(),
+	}
+	for _, step := range s.Steps {
+		out.Steps = append(out.Steps, &maintenanceStep{Name: step.Name, Done: step.Done, At: timeToJSON(step.At), Error: step.Error})
+	}
+	return out
+}
+
+// parseDrainDuration parses the duration of the field unless it is empty
+func parseDrainDuration(field string, value string, d *time.Duration) error {
+	if value == "" {
+		return nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid %s %q", field, value)
+	}
+	*d = parsed
+	return nil
+}
+
+// parseMaintenanceRequest completes the request with the config and checks it
+func parseMaintenanceRequest(in *maintenanceRequest) (maintenance.Request, error) {
+	defaults := config.GlobalConfig.Maintenance
+	req := maintenance.Request{
+		DrainTimer:  time.Duration(defaults.DrainTimer) * time.Second,
+		SviInterval: time.Duration(defaults.SviInterval) * time.Second,
+	}
+	name := defaults.Mode
+	if in.Mode != "" {
+		name = in.Mode
+	}
+	mode, err := routing.ParseDrainMode(name)
+	if err != nil {
+		return req, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	req.Mode = mode
+	if err := parseDrainDuration("drain_timer", in.DrainTimer, &req.DrainTimer); err != nil {
+		return req, err
+	}
+	if err := parseDrainDuration("svi_interval", in.SviInterval, &req.SviInterval); err != nil {
+		return req, err
+	}
+	order := defaults.SviOrder
+	if in.SviOrder != nil {
+		order = in.SviOrder
+	}
+	for _, svi := range order {
+		req.SviOrder = append(req.SviOrder, fullName("svis", svi))
+	}
+	return req, nil
+}
+
+// getMaintenance returns the maintenance state of the node and the progress of the drain
+func getMaintenance(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	s := maintenance.Get()
+	writeResponse(w, http.StatusOK, maintenanceToJSON(&s))
+}
+
+// enterMaintenance starts draining the node: the EVPN routes first, then the svis one by one
+func enterMaintenance(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &maintenanceRequest{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	req, err := parseMaintenanceRequest(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s, err := maintenance.Enter(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusAccepted, maintenanceToJSON(&s))
+}
+
+// exitMaintenance stops the drain and brings the traffic back to the node
+func exitMaintenance(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	s, err := maintenance.Exit()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, maintenanceToJSON(&s))
+}
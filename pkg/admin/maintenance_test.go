@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_EnterMaintenance(t *testing.T) {
+	tests := map[string]struct {
+		in   maintenanceRequest
+		code int
+	}{
+		"unknown mode": {
+			in:   maintenanceRequest{Mode: "shutdown"},
+			code: http.StatusBadRequest,
+		},
+		"invalid drain timer": {
+			in:   maintenanceRequest{DrainTimer: "-30s"},
+			code: http.StatusBadRequest,
+		},
+		"unknown svi": {
+			in:   maintenanceRequest{SviOrder: []string{"web"}},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/maintenance", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Fatalf("expected code %d, got %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+
+			req = httptest.NewRequest(http.MethodGet, "/v1/admin/maintenance", nil)
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			out := &maintenanceStatus{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.State != "ACTIVE" {
+				t.Errorf("expected the node to stay active, received %+v", out)
+			}
+		})
+	}
+}
+
+func Test_ExitMaintenance(t *testing.T) {
+	mux := newTestMux(t)
+	req := httptest.NewRequest(http.MethodDelete, "/v1/admin/maintenance", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected code %d, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// mplsDataplane carries the VPC over the MPLS core, srv6Dataplane over the SRv6 fabric
+const (
+	mplsDataplane = "mpls"
+	srv6Dataplane = "srv6"
+)
+
+// vrfDataplane is the json representation of the dataplane of a VPC, the label is its MPLS service label and
+// the functions pick its SRv6 SIDs in the locator, all of them allocated by the bridge when they are left out
+type vrfDataplane struct {
+	Type        string            `json:"type"`
+	Label       uint32            `json:"label,omitempty"`
+	Dt4Function uint32            `json:"dt4function,omitempty"`
+	Dt6Function uint32            `json:"dt6function,omitempty"`
+	Sids        map[string]string `json:"sids,omitempty"`
+}
+
+// toVrfDataplane converts the dataplane of a VPC to json, VXLAN being the default
+func toVrfDataplane(in *infradb.VrfSpec) *vrfDataplane {
+	switch {
+	case in.IsMpls():
+		return &vrfDataplane{Type: mplsDataplane, Label: in.Mpls.Label}
+	case in.IsSrv6():
+		out := &vrfDataplane{Type: srv6Dataplane, Dt4Function: in.Srv6.Dt4Function, Dt6Function: in.Srv6.Dt6Function}
+		if locator, err := infradb.Srv6Locator(); err == nil {
+			dt4, dt6 := in.Srv6.Sids(locator)
+			out.Sids = map[string]string{"End.DT4": dt4.String(), "End.DT6": dt6.String()}
+		}
+		return out
+	default:
+		return &vrfDataplane{Type: routing.EncapVxlan}
+	}
+}
+
+// getVrfDataplane returns the dataplane of a VPC
+func getVrfDataplane(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	vrf, err := infradb.GetVrf(fullName("vrfs", params["vrf"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, toVrfDataplane(vrf.Spec))
+}
+
+// setVrfDataplane carries a VPC over VXLAN, the MPLS core or the SRv6 fabric
+func setVrfDataplane(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &vrfDataplane{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	name := fullName("vrfs", params["vrf"])
+	if in.Type != srv6Dataplane && (in.Dt4Function != 0 || in.Dt6Function != 0) {
+		writeError(w, status.Errorf(codes.InvalidArgument, "the %s dataplane has no srv6 functions", in.Type))
+		return
+	}
+	var vrf *infradb.Vrf
+	var err error
+	switch in.Type {
+	case routing.EncapVxlan:
+		if in.Label != 0 {
+			writeError(w, status.Errorf(codes.InvalidArgument, "the vxlan dataplane has no label"))
+			return
+		}
+		vrf, err = infradb.SetVrfMpls(name, nil, ifMatch(r))
+	case mplsDataplane:
+		spec, specErr := infradb.NewMplsSpec(in.Label)
+		if specErr != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "%v", specErr))
+			return
+		}
+		vrf, err = infradb.SetVrfMpls(name, spec, ifMatch(r))
+	case srv6Dataplane:
+		if in.Label != 0 {
+			writeError(w, status.Errorf(codes.InvalidArgument, "the srv6 dataplane has no label"))
+			return
+		}
+		spec, specErr := infradb.NewSrv6Spec(in.Dt4Function, in.Dt6Function)
+		if specErr != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "%v", specErr))
+			return
+		}
+		vrf, err = infradb.SetVrfSrv6(name, spec, ifMatch(r))
+	default:
+		writeError(w, status.Errorf(codes.InvalidArgument, "unknown dataplane %q, expected %s, %s or %s",
+			in.Type, routing.EncapVxlan, mplsDataplane, srv6Dataplane))
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, toVrfDataplane(vrf.Spec))
+}
+
+// deleteVrfDataplane carries a VPC over VXLAN again, whether it was carried over MPLS or SRv6
+func deleteVrfDataplane(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if _, err := infradb.SetVrfMpls(fullName("vrfs", params["vrf"]), nil, ifMatch(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// mplsBackend carries the VPCs over MPLS
+type mplsBackend struct {
+	encapBackend
+}
+
+func (*mplsBackend) ConfigureMpls(context.Context, routing.Mpls) error {
+	return nil
+}
+
+// createTestMplsVrf creates the vrf "mpls" with a VNI
+func createTestMplsVrf(t *testing.T) {
+	vni := uint32(2000)
+	vrf, err := infradb.NewVrfWithArgs(fullName("vrfs", "mpls"), &vni, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateVrf(vrf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_SetVrfDataplane(t *testing.T) {
+	tests := map[string]struct {
+		vrf     string
+		backend routing.Backend
+		enabled bool
+		in      vrfDataplane
+		code    int
+	}{
+		"mpls with a label": {
+			vrf:     "mpls",
+			backend: &mplsBackend{encapBackend{name: "test-mpls"}},
+			enabled: true,
+			in:      vrfDataplane{Type: mplsDataplane, Label: 100},
+			code:    http.StatusOK,
+		},
+		"mpls with an allocated label": {
+			vrf:     "mpls",
+			backend: &mplsBackend{encapBackend{name: "test-mpls"}},
+			enabled: true,
+			in:      vrfDataplane{Type: mplsDataplane},
+			code:    http.StatusOK,
+		},
+		"reserved label": {
+			vrf:     "mpls",
+			backend: &mplsBackend{encapBackend{name: "test-mpls"}},
+			enabled: true,
+			in:      vrfDataplane{Type: mplsDataplane, Label: 3},
+			code:    http.StatusBadRequest,
+		},
+		"mpls disabled": {
+			vrf:     "mpls",
+			backend: &mplsBackend{encapBackend{name: "test-mpls"}},
+			in:      vrfDataplane{Type: mplsDataplane},
+			code:    http.StatusBadRequest,
+		},
+		"vxlan only backend": {
+			vrf:     "mpls",
+			backend: &encapBackend{name: "test-vxlan"},
+			enabled: true,
+			in:      vrfDataplane{Type: mplsDataplane},
+			code:    http.StatusBadRequest,
+		},
+		"vrf without vni": {
+			vrf:     "opi-vrf-a",
+			backend: &mplsBackend{encapBackend{name: "test-mpls"}},
+			enabled: true,
+			in:      vrfDataplane{Type: mplsDataplane},
+			code:    http.StatusBadRequest,
+		},
+		"mpls with srv6 functions": {
+			vrf:     "mpls",
+			backend: &mplsBackend{encapBackend{name: "test-mpls"}},
+			enabled: true,
+			in:      vrfDataplane{Type: mplsDataplane, Dt4Function: 1},
+			code:    http.StatusBadRequest,
+		},
+		"unknown dataplane": {
+			vrf:     "mpls",
+			backend: &mplsBackend{encapBackend{name: "test-mpls"}},
+			enabled: true,
+			in:      vrfDataplane{Type: "gre"},
+			code:    http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			selectEncapBackend(t, tt.backend)
+			config.GlobalConfig.Mpls.Enabled = tt.enabled
+			t.Cleanup(func() { config.GlobalConfig.Mpls = config.MplsConfig{} })
+			createTestMplsVrf(t)
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/vrfs/"+tt.vrf+"/dataplane", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			req = httptest.NewRequest(http.MethodGet, "/v1/admin/vrfs/"+tt.vrf+"/dataplane", nil)
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			out := &vrfDataplane{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Type != tt.in.Type || out.Label != tt.in.Label {
+				t.Errorf("expected %+v, received %+v", tt.in, out)
+			}
+		})
+	}
+}
+
+func Test_DeleteVrfDataplane(t *testing.T) {
+	mux := newTestMux(t)
+	selectEncapBackend(t, &mplsBackend{encapBackend{name: "test-mpls"}})
+	config.GlobalConfig.Mpls.Enabled = true
+	t.Cleanup(func() { config.GlobalConfig.Mpls = config.MplsConfig{} })
+	createTestMplsVrf(t)
+
+	body, _ := json.Marshal(vrfDataplane{Type: mplsDataplane})
+	req := httptest.NewRequest(http.MethodPut, "/v1/admin/vrfs/mpls/dataplane", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the vrf to be carried over mpls, received %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/v1/admin/vrfs/mpls/dataplane", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	vrf, err := infradb.GetVrf(fullName("vrfs", "mpls"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vrf.Spec.Mpls != nil {
+		t.Errorf("expected the vrf back over vxlan, received %+v", vrf.Spec.Mpls)
+	}
+}
@@ -0,0 +1,232 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"sort"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// portRange is the json representation of a port range
+type portRange struct {
+	Min uint16 `json:"min"`
+	Max uint16 `json:"max"`
+}
+
+// dnatRule is the json representation of a dnat rule
+type dnatRule struct {
+	Protocol     string `json:"protocol"`
+	ExternalPort uint16 `json:"external_port"`
+	InternalIP   string `json:"internal_ip"`
+	InternalPort uint16 `json:"internal_port"`
+}
+
+// natGateway is the json representation of a nat gateway
+type natGateway struct {
+	Name              string      `json:"name,omitempty"`
+	Vrf               string      `json:"vrf"`
+	ExternalInterface string      `json:"external_interface"`
+	SnatIP            string      `json:"snat_ip,omitempty"`
+	Subnets           []string    `json:"subnets,omitempty"`
+	PortRange         *portRange  `json:"port_range,omitempty"`
+	DnatRules         []dnatRule  `json:"dnat_rules,omitempty"`
+	OperStatus        string      `json:"oper_status,omitempty"`
+	Components        []component `json:"components,omitempty"`
+}
+
+// natCounter is the json representation of a nat rule counter
+type natCounter struct {
+	Chain   string `json:"chain"`
+	Rule    string `json:"rule"`
+	Packets uint64 `json:"packets"`
+	Bytes   uint64 `json:"bytes"`
+}
+
+// natStats is the json representation of the nat gateway statistics
+type natStats struct {
+	Counters       []natCounter `json:"counters"`
+	ConntrackCount uint64       `json:"conntrack_count"`
+	ConntrackMax   uint64       `json:"conntrack_max"`
+}
+
+// natGatewayToJSON translates the domain object to its json representation
+func natGatewayToJSON(nat *infradb.NatGateway) *natGateway {
+	out := &natGateway{
+		Name:              nat.Name,
+		Vrf:               nat.Spec.Vrf,
+		ExternalInterface: nat.Spec.ExternalInterface,
+		OperStatus:        nat.Status.OperStatus.String(),
+		Components:        componentsToJSON(nat.Status.Components),
+	}
+	if nat.Spec.SnatIP != nil {
+		out.SnatIP = nat.Spec.SnatIP.String()
+	}
+	for _, subnet := range nat.Spec.Subnets {
+		out.Subnets = append(out.Subnets, subnet.String())
+	}
+	if nat.Spec.PortRange != nil {
+		out.PortRange = &portRange{Min: nat.Spec.PortRange.Min, Max: nat.Spec.PortRange.Max}
+	}
+	for _, rule := range nat.Spec.DnatRules {
+		out.DnatRules = append(out.DnatRules, dnatRule{
+			Protocol:     rule.Protocol,
+			ExternalPort: rule.ExternalPort,
+			InternalIP:   rule.InternalIP.String(),
+			InternalPort: rule.InternalPort,
+		})
+	}
+	return out
+}
+
+// natGatewaySpecFromJSON translates the json representation to the domain spec
+func natGatewaySpecFromJSON(in *natGateway) (*infradb.NatGatewaySpec, error) {
+	spec := &infradb.NatGatewaySpec{Vrf: in.Vrf, ExternalInterface: in.ExternalInterface}
+	if in.SnatIP != "" {
+		spec.SnatIP = net.ParseIP(in.SnatIP)
+		if spec.SnatIP == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid snat ip %s", in.SnatIP)
+		}
+	}
+	for _, subnet := range in.Subnets {
+		_, ipnet, err := net.ParseCIDR(subnet)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid subnet %s: %v", subnet, err)
+		}
+		spec.Subnets = append(spec.Subnets, ipnet)
+	}
+	if in.PortRange != nil {
+		spec.PortRange = &infradb.PortRange{Min: in.PortRange.Min, Max: in.PortRange.Max}
+	}
+	for _, rule := range in.DnatRules {
+		ip := net.ParseIP(rule.InternalIP)
+		if ip == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid internal ip %s", rule.InternalIP)
+		}
+		spec.DnatRules = append(spec.DnatRules, &infradb.DnatRule{
+			Protocol:     rule.Protocol,
+			ExternalPort: rule.ExternalPort,
+			InternalIP:   ip,
+			InternalPort: rule.InternalPort,
+		})
+	}
+	return spec, nil
+}
+
+// createNatGateway creates a nat gateway for a vrf
+func createNatGateway(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &natGateway{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("natgateways", resourceID)
+	spec, err := natGatewaySpecFromJSON(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	nat, err := infradb.NewNatGateway(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetNatGateway(name); err == nil {
+		if !sameSpec(nat.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("natgateways", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createNatGateway(): Already existing NAT Gateway with id %v", name)
+		writeResponse(w, http.StatusOK, natGatewayToJSON(existing))
+		return
+	}
+	if err := infradb.CreateNatGateway(nat); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, natGatewayToJSON(nat))
+}
+
+// getNatGateway returns a nat gateway
+func getNatGateway(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	nat, err := infradb.GetNatGateway(fullName("natgateways", params["natgateway"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, natGatewayToJSON(nat))
+}
+
+// listNatGateways returns all the nat gateways
+func listNatGateways(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	nats, err := infradb.GetAllNatGateways()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(nats, func(i, j int) bool { return nats[i].Name < nats[j].Name })
+	out := []*natGateway{}
+	for _, nat := range nats {
+		out = append(out, natGatewayToJSON(nat))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"nat_gateways": out})
+}
+
+// deleteNatGateway deletes a nat gateway
+func deleteNatGateway(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteNatGateway(fullName("natgateways", params["natgateway"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
+
+// getNatGatewayStats returns the rule counters and the connection tracking statistics of a nat gateway
+func getNatGatewayStats(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	name := fullName("natgateways", params["natgateway"])
+	nat, err := infradb.GetNatGateway(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if nat.Status.OperStatus != infradb.OperStatusUp {
+		writeError(w, status.Errorf(codes.FailedPrecondition, "nat gateway %s is not operationally up", name))
+		return
+	}
+	stats, err := gen_linux.GetNatGatewayStats(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := &natStats{Counters: []natCounter{}, ConntrackCount: stats.ConntrackCount, ConntrackMax: stats.ConntrackMax}
+	for _, c := range stats.Counters {
+		out.Counters = append(out.Counters, natCounter{Chain: c.Chain, Rule: c.Rule, Packets: c.Packets, Bytes: c.Bytes})
+	}
+	writeResponse(w, http.StatusOK, out)
+}
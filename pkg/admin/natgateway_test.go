@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_CreateNatGateway(t *testing.T) {
+	tests := map[string]struct {
+		in   natGateway
+		code int
+	}{
+		"valid masquerade": {
+			in:   natGateway{Vrf: testVrfA, ExternalInterface: "eth1"},
+			code: http.StatusOK,
+		},
+		"valid snat and dnat": {
+			in: natGateway{
+				Vrf: testVrfA, ExternalInterface: "eth1", SnatIP: "203.0.113.10",
+				Subnets:   []string{"10.0.0.0/24"},
+				PortRange: &portRange{Min: 1024, Max: 65535},
+				DnatRules: []dnatRule{{Protocol: "TCP", ExternalPort: 8443, InternalIP: "10.0.0.5", InternalPort: 443}},
+			},
+			code: http.StatusOK,
+		},
+		"missing external interface": {
+			in:   natGateway{Vrf: testVrfA},
+			code: http.StatusBadRequest,
+		},
+		"ipv6 snat ip": {
+			in:   natGateway{Vrf: testVrfA, ExternalInterface: "eth1", SnatIP: "2001:db8::1"},
+			code: http.StatusBadRequest,
+		},
+		"invalid port range": {
+			in:   natGateway{Vrf: testVrfA, ExternalInterface: "eth1", PortRange: &portRange{Min: 2000, Max: 1000}},
+			code: http.StatusBadRequest,
+		},
+		"unsupported dnat protocol": {
+			in: natGateway{
+				Vrf: testVrfA, ExternalInterface: "eth1",
+				DnatRules: []dnatRule{{Protocol: "icmp", ExternalPort: 1, InternalIP: "10.0.0.5", InternalPort: 1}},
+			},
+			code: http.StatusBadRequest,
+		},
+		"unknown vrf": {
+			in:   natGateway{Vrf: fullName("vrfs", "unknown"), ExternalInterface: "eth1"},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/natgateways?id=opi-nat", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &natGateway{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("natgateways", "opi-nat") || out.OperStatus != "DOWN" {
+				t.Errorf("unexpected nat gateway %+v", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"path"
+	"sort"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/netlink"
+)
+
+// evpnBridgeOwner is the owner of the netdevs programmed by this bridge in the inventory
+const evpnBridgeOwner = "opi-evpn-bridge"
+
+// netdevClaim is the json representation of the claim of a netdev by another OPI bridge
+type netdevClaim struct {
+	Netdev string `json:"netdev,omitempty"`
+	Owner  string `json:"owner"`
+	Usage  string `json:"usage,omitempty"`
+}
+
+// netdev is the json representation of a netdev of the shared inventory
+type netdev struct {
+	Name      string `json:"name"`
+	OperState string `json:"oper_state,omitempty"`
+	// Owner is the OPI bridge which manages the netdev, empty when it is free
+	Owner string `json:"owner,omitempty"`
+	// Usage is the usage of a claimed netdev
+	Usage string `json:"usage,omitempty"`
+	// Resource is the Bridge Port attaching the netdev or the resource which created it
+	Resource string `json:"resource,omitempty"`
+}
+
+// netdevInventory merges the kernel devices, the Bridge Ports and the claims of the other OPI bridges,
+// the claimed netdevs which do not exist yet are listed too
+func netdevInventory(states []netlink.LinkState, bps []string, claims []*infradb.NetdevClaim) []*netdev {
+	byName := map[string]*netdev{}
+	for _, state := range states {
+		dev := &netdev{Name: state.Name, OperState: state.OperState}
+		if state.Owner != "" {
+			dev.Owner = evpnBridgeOwner
+			dev.Resource = state.Owner
+		}
+		byName[state.Name] = dev
+	}
+	for _, bp := range bps {
+		dev, ok := byName[path.Base(bp)]
+		if !ok {
+			dev = &netdev{Name: path.Base(bp)}
+			byName[dev.Name] = dev
+		}
+		dev.Owner = evpnBridgeOwner
+		dev.Resource = bp
+	}
+	for _, claim := range claims {
+		dev, ok := byName[claim.Netdev]
+		if !ok {
+			dev = &netdev{Name: claim.Netdev}
+			byName[dev.Name] = dev
+		}
+		dev.Owner = claim.Owner
+		dev.Usage = claim.Usage
+	}
+	out := make([]*netdev, 0, len(byName))
+	for _, dev := range byName {
+		out = append(out, dev)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// listNetdevs returns the inventory of the netdevs shared by the OPI bridges of the DPU
+func listNetdevs(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	bpNames := []string{}
+	bps, err := infradb.GetAllBPs()
+	if err != nil && err != infradb.ErrKeyNotFound {
+		writeError(w, err)
+		return
+	}
+	for _, bp := range bps {
+		bpNames = append(bpNames, bp.Name)
+	}
+	claims, err := infradb.GetAllNetdevClaims()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"netdevs": netdevInventory(netlink.GetLinkStates(), bpNames, claims)})
+}
+
+// claimNetdev claims a netdev for another OPI bridge, e.g. the storage bridge
+func claimNetdev(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &netdevClaim{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	claim := &infradb.NetdevClaim{Netdev: params["netdev"], Owner: in.Owner, Usage: in.Usage}
+	if err := infradb.ClaimNetdev(claim); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, &netdevClaim{Netdev: claim.Netdev, Owner: claim.Owner, Usage: claim.Usage})
+}
+
+// releaseNetdev releases the claim of a netdev, the owner query parameter guards against releasing the claim of another bridge
+func releaseNetdev(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.ReleaseNetdev(params["netdev"], r.URL.Query().Get("owner"))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
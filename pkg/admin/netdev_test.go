@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/netlink"
+	"github.com/opiproject/opi-evpn-bridge/pkg/pbconv"
+)
+
+func Test_ClaimNetdev(t *testing.T) {
+	tests := map[string]struct {
+		disabled bool
+		existing *netdevClaim
+		netdev   string
+		in       netdevClaim
+		code     int
+		usage    string
+	}{
+		"defaults to exclusive": {
+			netdev: "eth3",
+			in:     netdevClaim{Owner: "opi-spdk-bridge"},
+			code:   http.StatusOK,
+			usage:  "exclusive",
+		},
+		"claimed again by the same owner": {
+			existing: &netdevClaim{Owner: "opi-spdk-bridge"},
+			netdev:   "eth3",
+			in:       netdevClaim{Owner: "opi-spdk-bridge", Usage: "shared"},
+			code:     http.StatusOK,
+			usage:    "shared",
+		},
+		"claimed by another owner": {
+			existing: &netdevClaim{Owner: "opi-spdk-bridge"},
+			netdev:   "eth3",
+			in:       netdevClaim{Owner: "other-bridge"},
+			code:     http.StatusBadRequest,
+		},
+		"used by a bridge port": {
+			netdev: "eth2",
+			in:     netdevClaim{Owner: "opi-spdk-bridge"},
+			code:   http.StatusBadRequest,
+		},
+		"shared with a bridge port": {
+			netdev: "eth2",
+			in:     netdevClaim{Owner: "opi-spdk-bridge", Usage: "shared"},
+			code:   http.StatusOK,
+			usage:  "shared",
+		},
+		"unknown usage": {
+			netdev: "eth3",
+			in:     netdevClaim{Owner: "opi-spdk-bridge", Usage: "borrowed"},
+			code:   http.StatusBadRequest,
+		},
+		"no owner": {
+			netdev: "eth3",
+			code:   http.StatusBadRequest,
+		},
+		"storage integration disabled": {
+			disabled: true,
+			netdev:   "eth3",
+			in:       netdevClaim{Owner: "opi-spdk-bridge"},
+			code:     http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			config.GlobalConfig.Storage.Enabled = !tt.disabled
+			t.Cleanup(func() { config.GlobalConfig.Storage.Enabled = false })
+			mux := newTestMux(t)
+			createTestBridgePort(t)
+			if tt.existing != nil {
+				body, _ := json.Marshal(tt.existing)
+				req := httptest.NewRequest(http.MethodPut, "/v1/admin/netdevs/"+tt.netdev+"/claim", bytes.NewReader(body))
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("failed to create existing claim: %s", rec.Body.String())
+				}
+			}
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/netdevs/"+tt.netdev+"/claim", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &netdevClaim{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Netdev != tt.netdev || out.Usage != tt.usage {
+				t.Errorf("unexpected claim %+v", out)
+			}
+		})
+	}
+}
+
+func Test_ClaimedNetdevBridgePort(t *testing.T) {
+	config.GlobalConfig.Storage.Enabled = true
+	t.Cleanup(func() { config.GlobalConfig.Storage.Enabled = false })
+	_ = newTestMux(t)
+	if err := createTestBridge("storage", 30, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.ClaimNetdev(&infradb.NetdevClaim{Netdev: "eth3", Owner: "opi-spdk-bridge"}); err != nil {
+		t.Fatal(err)
+	}
+	bp, err := pbconv.BridgePortFromPb(&pb.BridgePort{Name: fullName("ports", "eth3"), Spec: &pb.BridgePortSpec{
+		Ptype:          pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS,
+		MacAddress:     []byte{0xaa, 0xbb, 0xcc, 0, 0, 3},
+		LogicalBridges: []string{fullName("bridges", "storage")},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateBP(bp); err != infradb.ErrNetdevClaimed {
+		t.Errorf("expected the claimed netdev to be refused, received %v", err)
+	}
+	if err := infradb.ReleaseNetdev("eth3", "other-bridge"); err != infradb.ErrNetdevClaimed {
+		t.Errorf("expected the claim of another owner to be kept, received %v", err)
+	}
+	if err := infradb.ReleaseNetdev("eth3", "opi-spdk-bridge"); err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateBP(bp); err != nil {
+		t.Errorf("expected the released netdev to be attached, received %v", err)
+	}
+}
+
+func Test_NetdevInventory(t *testing.T) {
+	states := []netlink.LinkState{
+		{Name: "br-tenant", OperState: "up", Owner: ""},
+		{Name: "eth2", OperState: "up"},
+		{Name: "eth3", OperState: "down"},
+		{Name: "vxlan-blue", OperState: "up", Owner: "//network.opiproject.org/vrfs/blue"},
+	}
+	bps := []string{"//network.opiproject.org/ports/eth2"}
+	claims := []*infradb.NetdevClaim{
+		{Netdev: "eth3", Owner: "opi-spdk-bridge", Usage: "exclusive"},
+		{Netdev: "eth4", Owner: "opi-spdk-bridge", Usage: "shared"},
+	}
+	expected := []*netdev{
+		{Name: "br-tenant", OperState: "up"},
+		{Name: "eth2", OperState: "up", Owner: "opi-evpn-bridge", Resource: "//network.opiproject.org/ports/eth2"},
+		{Name: "eth3", OperState: "down", Owner: "opi-spdk-bridge", Usage: "exclusive"},
+		{Name: "eth4", Owner: "opi-spdk-bridge", Usage: "shared"},
+		{Name: "vxlan-blue", OperState: "up", Owner: "opi-evpn-bridge", Resource: "//network.opiproject.org/vrfs/blue"},
+	}
+	if out := netdevInventory(states, bps, claims); !reflect.DeepEqual(out, expected) {
+		got, _ := json.Marshal(out)
+		t.Errorf("unexpected inventory %s", got)
+	}
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// vrfNetns is the json representation of the network namespace of a VPC, empty for the one of the bridge
+type vrfNetns struct {
+	Name string `json:"name"`
+}
+
+// getVrfNetns returns the network namespace of a VPC
+func getVrfNetns(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	vrf, err := infradb.GetVrf(fullName("vrfs", params["vrf"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, &vrfNetns{Name: vrf.Spec.Netns})
+}
+
+// setVrfNetns moves a VPC and its svis to a named network namespace
+func setVrfNetns(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &vrfNetns{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	if in.Name != "" {
+		if err := utils.ValidateNetnsName(in.Name); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+			return
+		}
+	}
+	vrf, err := infradb.SetVrfNetns(fullName("vrfs", params["vrf"]), in.Name, ifMatch(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, &vrfNetns{Name: vrf.Spec.Netns})
+}
+
+// deleteVrfNetns moves a VPC back to the network namespace of the bridge
+func deleteVrfNetns(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if _, err := infradb.SetVrfNetns(fullName("vrfs", params["vrf"]), "", ifMatch(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_SetVrfNetns(t *testing.T) {
+	tests := map[string]struct {
+		vrf  string
+		mpls bool
+		in   vrfNetns
+		code int
+	}{
+		"named namespace": {
+			vrf:  "opi-vrf-a",
+			in:   vrfNetns{Name: "tenant-a"},
+			code: http.StatusOK,
+		},
+		"namespace of the bridge": {
+			vrf:  "opi-vrf-a",
+			code: http.StatusOK,
+		},
+		"invalid name": {
+			vrf:  "opi-vrf-a",
+			in:   vrfNetns{Name: "../tenant-a"},
+			code: http.StatusBadRequest,
+		},
+		"vrf over mpls": {
+			vrf:  "mpls",
+			mpls: true,
+			in:   vrfNetns{Name: "tenant-a"},
+			code: http.StatusBadRequest,
+		},
+		"unknown vrf": {
+			vrf:  "unknown",
+			in:   vrfNetns{Name: "tenant-a"},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			if tt.mpls {
+				selectEncapBackend(t, &mplsBackend{encapBackend{name: "test-mpls"}})
+				config.GlobalConfig.Mpls.Enabled = true
+				t.Cleanup(func() { config.GlobalConfig.Mpls = config.MplsConfig{} })
+				createTestMplsVrf(t)
+				if _, err := infradb.SetVrfMpls(fullName("vrfs", tt.vrf), &infradb.MplsSpec{Label: 100}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/vrfs/"+tt.vrf+"/netns", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			req = httptest.NewRequest(http.MethodGet, "/v1/admin/vrfs/"+tt.vrf+"/netns", nil)
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			out := &vrfNetns{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != tt.in.Name {
+				t.Errorf("expected %+v, received %+v", tt.in, out)
+			}
+		})
+	}
+}
+
+func Test_DeleteVrfNetns(t *testing.T) {
+	mux := newTestMux(t)
+
+	body, _ := json.Marshal(vrfNetns{Name: "tenant-a"})
+	req := httptest.NewRequest(http.MethodPut, "/v1/admin/vrfs/opi-vrf-a/netns", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the vrf to move to tenant-a, received %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/v1/admin/vrfs/opi-vrf-a/netns", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	vrf, err := infradb.GetVrf(testVrfA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vrf.Spec.Netns != "" {
+		t.Errorf("expected the vrf back in the namespace of the bridge, received %s", vrf.Spec.Netns)
+	}
+}
@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"sort"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// allowedAddress is the json representation of a source address allowed on a port
+type allowedAddress struct {
+	MacAddress string `json:"mac_address"`
+	IP         string `json:"ip,omitempty"`
+}
+
+// portSecurityViolations is the json representation of the violation counters of a port security
+type portSecurityViolations struct {
+	MacViolations uint64 `json:"mac_violations"`
+	IPViolations  uint64 `json:"ip_violations"`
+	LearnedMacs   uint64 `json:"learned_macs"`
+}
+
+// portSecurity is the json representation of a port security
+type portSecurity struct {
+	Name             string                  `json:"name,omitempty"`
+	BridgePort       string                  `json:"bridge_port"`
+	MacLimit         uint32                  `json:"mac_limit,omitempty"`
+	AllowedAddresses []allowedAddress        `json:"allowed_addresses,omitempty"`
+	OperStatus       string                  `json:"oper_status,omitempty"`
+	Components       []component             `json:"components,omitempty"`
+	Violations       *portSecurityViolations `json:"violations,omitempty"`
+}
+
+// portSecurityToJSON translates the domain object to its json representation
+func portSecurityToJSON(psec *infradb.PortSecurity) *portSecurity {
+	out := &portSecurity{
+		Name:       psec.Name,
+		BridgePort: psec.Spec.BridgePort,
+		MacLimit:   psec.Spec.MacLimit,
+		OperStatus: psec.Status.OperStatus.String(),
+		Components: componentsToJSON(psec.Status.Components),
+	}
+	for _, addr := range psec.Spec.AllowedAddresses {
+		allowed := allowedAddress{MacAddress: addr.MAC.String()}
+		if addr.IP != nil {
+			allowed.IP = addr.IP.String()
+		}
+		out.AllowedAddresses = append(out.AllowedAddresses, allowed)
+	}
+	return out
+}
+
+// portSecuritySpecFromJSON translates the json representation to the domain spec
+func portSecuritySpecFromJSON(in *portSecurity) (*infradb.PortSecuritySpec, error) {
+	spec := &infradb.PortSecuritySpec{BridgePort: in.BridgePort, MacLimit: in.MacLimit}
+	for _, addr := range in.AllowedAddresses {
+		mac, err := net.ParseMAC(addr.MacAddress)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid mac address %s", addr.MacAddress)
+		}
+		allowed := &infradb.AllowedAddress{MAC: mac}
+		if addr.IP != "" {
+			if allowed.IP = net.ParseIP(addr.IP); allowed.IP == nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid ip %s", addr.IP)
+			}
+		}
+		spec.AllowedAddresses = append(spec.AllowedAddresses, allowed)
+	}
+	return spec, nil
+}
+
+// createPortSecurity creates a port security for a bridge port
+func createPortSecurity(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &portSecurity{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("portsecurities", resourceID)
+	spec, err := portSecuritySpecFromJSON(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	psec, err := infradb.NewPortSecurity(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetPortSecurity(name); err == nil {
+		if !sameSpec(psec.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("portsecurities", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createPortSecurity(): Already existing Port Security with id %v", name)
+		writeResponse(w, http.StatusOK, portSecurityToJSON(existing))
+		return
+	}
+	if err := infradb.CreatePortSecurity(psec); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, portSecurityToJSON(psec))
+}
+
+// getPortSecurity returns a port security, with its violation counters once it is operationally up
+func getPortSecurity(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	name := fullName("portsecurities", params["portsecurity"])
+	psec, err := infradb.GetPortSecurity(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := portSecurityToJSON(psec)
+	if psec.Status.OperStatus == infradb.OperStatusUp {
+		stats, err := gen_linux.GetPortSecurityStats(name)
+		if err != nil {
+			log.Printf("getPortSecurity(): Failed to read the violation counters of %s: %v", name, err)
+		} else {
+			out.Violations = &portSecurityViolations{
+				MacViolations: stats.MacViolations,
+				IPViolations:  stats.IPViolations,
+				LearnedMacs:   stats.LearnedMacs,
+			}
+		}
+	}
+	writeResponse(w, http.StatusOK, out)
+}
+
+// listPortSecurities returns all the port securities
+func listPortSecurities(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	psecs, err := infradb.GetAllPortSecurities()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(psecs, func(i, j int) bool { return psecs[i].Name < psecs[j].Name })
+	out := []*portSecurity{}
+	for _, psec := range psecs {
+		out = append(out, portSecurityToJSON(psec))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"port_securities": out})
+}
+
+// deletePortSecurity deletes a port security
+func deletePortSecurity(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeletePortSecurity(fullName("portsecurities", params["portsecurity"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/pbconv"
+)
+
+var testBridgePort = fullName("ports", "eth2")
+
+// createTestBridgePort creates an access bridge port on a new logical bridge
+func createTestBridgePort(t *testing.T) {
+	if err := createTestBridge("psec", 20, nil); err != nil {
+		t.Fatal(err)
+	}
+	bp, err := pbconv.BridgePortFromPb(&pb.BridgePort{Name: testBridgePort, Spec: &pb.BridgePortSpec{
+		Ptype:          pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS,
+		MacAddress:     []byte{0xaa, 0xbb, 0xcc, 0, 0, 1},
+		LogicalBridges: []string{fullName("bridges", "psec")},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateBP(bp); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_CreatePortSecurity(t *testing.T) {
+	tests := map[string]struct {
+		in   portSecurity
+		code int
+	}{
+		"mac limit": {
+			in:   portSecurity{BridgePort: testBridgePort, MacLimit: 16},
+			code: http.StatusOK,
+		},
+		"allowed addresses": {
+			in: portSecurity{BridgePort: testBridgePort, AllowedAddresses: []allowedAddress{
+				{MacAddress: "aa:bb:cc:00:00:01", IP: "10.0.0.5"},
+				{MacAddress: "aa:bb:cc:00:00:01", IP: "2001:db8::5"},
+				{MacAddress: "aa:bb:cc:00:00:02"},
+			}},
+			code: http.StatusOK,
+		},
+		"nothing to enforce": {
+			in:   portSecurity{BridgePort: testBridgePort},
+			code: http.StatusBadRequest,
+		},
+		"multicast mac": {
+			in:   portSecurity{BridgePort: testBridgePort, AllowedAddresses: []allowedAddress{{MacAddress: "01:00:5e:00:00:01"}}},
+			code: http.StatusBadRequest,
+		},
+		"duplicated address": {
+			in: portSecurity{BridgePort: testBridgePort, AllowedAddresses: []allowedAddress{
+				{MacAddress: "aa:bb:cc:00:00:01", IP: "10.0.0.5"},
+				{MacAddress: "AA:BB:CC:00:00:01", IP: "10.0.0.5"},
+			}},
+			code: http.StatusBadRequest,
+		},
+		"unknown bridge port": {
+			in:   portSecurity{BridgePort: fullName("ports", "unknown"), MacLimit: 16},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			createTestBridgePort(t)
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/portsecurities?id=eth2-psec", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &portSecurity{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("portsecurities", "eth2-psec") || out.OperStatus != "DOWN" || len(out.AllowedAddresses) != len(tt.in.AllowedAddresses) {
+				t.Errorf("unexpected port security %+v", out)
+			}
+
+			// A second port security of the same bridge port is refused, and the bridge port is kept
+			req = httptest.NewRequest(http.MethodPost, "/v1/admin/portsecurities?id=other", bytes.NewReader(body))
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected a second port security to fail with %d, received %d", http.StatusBadRequest, rec.Code)
+			}
+			if err := infradb.DeleteBP(testBridgePort); err != infradb.ErrBridgePortInUse {
+				t.Errorf("expected the bridge port to be in use, received %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// bridgePortProfile is the json representation of a bridge port profile, with the bridge ports which use it
+type bridgePortProfile struct {
+	Name        string         `json:"name,omitempty"`
+	Sflow       *sflowSampling `json:"sflow,omitempty"`
+	Qinq        *qinqMapping   `json:"qinq,omitempty"`
+	Isolated    *bool          `json:"isolated,omitempty"`
+	BridgePorts []string       `json:"bridge_ports,omitempty"`
+}
+
+// portProfile is the json representation of the profile used by a bridge port
+type portProfile struct {
+	Profile string `json:"profile"`
+}
+
+// bridgePortProfileToJSON translates the profile to its json representation
+func bridgePortProfileToJSON(p *infradb.BridgePortProfile, bps []string) *bridgePortProfile {
+	out := &bridgePortProfile{Name: p.Name, Isolated: p.Isolated, BridgePorts: bps}
+	if p.Sflow != nil {
+		out.Sflow = &sflowSampling{SamplingRate: p.Sflow.SamplingRate, HeaderLength: p.Sflow.HeaderLength, Collector: p.Sflow.Collector}
+	}
+	if p.Qinq != nil {
+		out.Qinq = toQinqMapping(p.Qinq)
+	}
+	return out
+}
+
+// bridgePortProfileFromJSON translates the json representation to the domain profile
+func bridgePortProfileFromJSON(name string, in *bridgePortProfile) (*infradb.BridgePortProfile, error) {
+	var sflow *infradb.SflowSpec
+	if in.Sflow != nil {
+		sflow = &infradb.SflowSpec{SamplingRate: in.Sflow.SamplingRate, HeaderLength: in.Sflow.HeaderLength, Collector: in.Sflow.Collector}
+	}
+	var qinq *infradb.QinqSpec
+	if in.Qinq != nil {
+		qinq = &infradb.QinqSpec{}
+		for _, rule := range in.Qinq.Rules {
+			qinq.Rules = append(qinq.Rules, infradb.QinqRule{SVlan: rule.SVlan, CVlan: rule.CVlan, LogicalBridge: rule.LogicalBridge})
+		}
+	}
+	p, err := infradb.NewBridgePortProfile(name, sflow, qinq, in.Isolated)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return p, nil
+}
+
+// listBridgePortProfiles returns the bridge port profiles in the order of their names
+func listBridgePortProfiles(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	profiles, err := infradb.GetAllBridgePortProfiles()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := make([]*bridgePortProfile, 0, len(profiles))
+	for _, p := range profiles {
+		out = append(out, bridgePortProfileToJSON(p, nil))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"bridge_port_profiles": out})
+}
+
+// getBridgePortProfile returns a bridge port profile with the bridge ports which use it
+func getBridgePortProfile(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	name := fullName("bridgeportprofiles", params["profile"])
+	p, err := infradb.GetBridgePortProfile(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	bps, err := infradb.GetBridgePortsOfProfile(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, bridgePortProfileToJSON(p, bps))
+}
+
+// setBridgePortProfile creates or replaces a bridge port profile, the bridge ports which use it are
+// programmed again with its settings
+func setBridgePortProfile(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &bridgePortProfile{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := resourceid.ValidateUserSettable(params["profile"]); err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", params["profile"], err))
+		return
+	}
+	p, err := bridgePortProfileFromJSON(fullName("bridgeportprofiles", params["profile"]), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	bps, err := infradb.SetBridgePortProfile(p)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, bridgePortProfileToJSON(p, bps))
+}
+
+// deleteBridgePortProfile deletes a bridge port profile which no bridge port uses
+func deleteBridgePortProfile(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	if err := infradb.DeleteBridgePortProfile(fullName("bridgeportprofiles", params["profile"])); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
+
+// getBridgePortProfileOf returns the profile used by a bridge port
+func getBridgePortProfileOf(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	name := fullName("ports", params["bridgeport"])
+	bp, err := infradb.GetBP(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if bp.Spec.Profile == "" {
+		writeError(w, status.Errorf(codes.NotFound, "bridge port %s has no profile", name))
+		return
+	}
+	writeResponse(w, http.StatusOK, &portProfile{Profile: bp.Spec.Profile})
+}
+
+// setBridgePortProfileOf makes a bridge port use a profile, given by its id or its full name
+func setBridgePortProfileOf(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &portProfile{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	if in.Profile == "" {
+		writeError(w, status.Errorf(codes.InvalidArgument, "profile is required"))
+		return
+	}
+	profile := in.Profile
+	if resourceid.ValidateUserSettable(profile) == nil {
+		profile = fullName("bridgeportprofiles", profile)
+	}
+	bp, err := infradb.AttachBridgePortProfile(fullName("ports", params["bridgeport"]), profile, ifMatch(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, &portProfile{Profile: bp.Spec.Profile})
+}
+
+// deleteBridgePortProfileOf detaches a bridge port from its profile, the port keeps the settings
+func deleteBridgePortProfileOf(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if _, err := infradb.AttachBridgePortProfile(fullName("ports", params["bridgeport"]), "", ifMatch(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_SetBridgePortProfile(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		code int
+	}{
+		"sflow and isolation": {
+			body: `{"sflow":{"sampling_rate":1000,"collector":"192.0.2.10:6343"},"isolated":true}`,
+			code: http.StatusOK,
+		},
+		"no setting": {
+			body: `{}`,
+			code: http.StatusBadRequest,
+		},
+		"invalid sflow": {
+			body: `{"sflow":{"collector":"192.0.2.10:6343"}}`,
+			code: http.StatusBadRequest,
+		},
+		"unknown logical bridge": {
+			body: `{"qinq":{"rules":[{"s_vlan":100,"c_vlan":10,"logical_bridge":"//network.opiproject.org/bridges/unknown"}]}}`,
+			code: http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/bridgeportprofiles/edge", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func Test_AttachBridgePortProfile(t *testing.T) {
+	mux := newTestMux(t)
+	createTestBridgePort(t)
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, url, strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := serve(http.MethodPut, "/v1/admin/bridgeportprofiles/edge", `{"sflow":{"sampling_rate":1000,"collector":"192.0.2.10:6343"}}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if rec := serve(http.MethodPut, "/v1/admin/bridgeports/eth2/profile", `{"profile":"unknown"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("expected code %d, received %d: %s", http.StatusNotFound, rec.Code, rec.Body.String())
+	}
+	if rec := serve(http.MethodPut, "/v1/admin/bridgeports/eth2/profile", `{"profile":"edge"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	bp, err := infradb.GetBP(testBridgePort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bp.Spec.Profile != fullName("bridgeportprofiles", "edge") || bp.Spec.Sflow == nil || bp.Spec.Sflow.SamplingRate != 1000 {
+		t.Errorf("expected the sampling of the profile, received %+v", bp.Spec)
+	}
+
+	// the profile manages the sampling, the isolation is still set on the port
+	if rec := serve(http.MethodPut, "/v1/admin/bridgeports/eth2/sflow", `{"sampling_rate":10,"collector":"192.0.2.10:6343"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected code %d, received %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+	if rec := serve(http.MethodPut, "/v1/admin/bridgeports/eth2/isolation", `{"isolated":true}`); rec.Code != http.StatusOK {
+		t.Errorf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	// a change of the profile updates its ports
+	rec := serve(http.MethodPut, "/v1/admin/bridgeportprofiles/edge", `{"sflow":{"sampling_rate":500,"collector":"192.0.2.10:6343"}}`)
+	out := &bridgePortProfile{}
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.BridgePorts) != 1 || out.BridgePorts[0] != testBridgePort {
+		t.Errorf("expected the profile to update %s, received %+v", testBridgePort, out)
+	}
+	if bp, _ := infradb.GetBP(testBridgePort); bp.Spec.Sflow.SamplingRate != 500 || bp.Spec.Isolated == nil || !*bp.Spec.Isolated {
+		t.Errorf("expected the new sampling and the isolation of the port, received %+v", bp.Spec)
+	}
+
+	if rec := serve(http.MethodDelete, "/v1/admin/bridgeportprofiles/edge", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected the profile in use, received %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(http.MethodDelete, "/v1/admin/bridgeports/eth2/profile", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if rec := serve(http.MethodDelete, "/v1/admin/bridgeportprofiles/edge", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if bp, _ := infradb.GetBP(testBridgePort); bp.Spec.Profile != "" || bp.Spec.Sflow == nil {
+		t.Errorf("expected the port to keep the sampling, received %+v", bp.Spec)
+	}
+}
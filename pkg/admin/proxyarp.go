@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// proxyArp is the json representation of the proxy ARP of an svi
+type proxyArp struct {
+	ProxyArp      bool `json:"proxy_arp"`
+	LocalProxyArp bool `json:"local_proxy_arp"`
+}
+
+// toProxyArp converts the proxy ARP to json, nil being disabled
+func toProxyArp(in *infradb.ProxyArpSpec) *proxyArp {
+	if in == nil {
+		return &proxyArp{}
+	}
+	return &proxyArp{ProxyArp: in.ProxyArp, LocalProxyArp: in.LocalProxyArp}
+}
+
+// getSviProxyArp returns the proxy ARP of an svi
+func getSviProxyArp(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	svi, err := infradb.GetSvi(fullName("svis", params["svi"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, toProxyArp(svi.Spec.ProxyArp))
+}
+
+// setSviProxyArp sets the proxy ARP of an svi
+func setSviProxyArp(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &proxyArp{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	svi, err := infradb.SetSviProxyArp(fullName("svis", params["svi"]), &infradb.ProxyArpSpec{ProxyArp: in.ProxyArp, LocalProxyArp: in.LocalProxyArp}, ifMatch(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, toProxyArp(svi.Spec.ProxyArp))
+}
+
+// deleteSviProxyArp leaves the proxy ARP of an svi to the kernel settings of the svis
+func deleteSviProxyArp(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if _, err := infradb.SetSviProxyArp(fullName("svis", params["svi"]), nil, ifMatch(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_SetSviProxyArp(t *testing.T) {
+	tests := map[string]struct {
+		svi  string
+		in   proxyArp
+		code int
+	}{
+		"local proxy arp": {
+			svi:  "web",
+			in:   proxyArp{ProxyArp: true, LocalProxyArp: true},
+			code: http.StatusOK,
+		},
+		"proxy arp only": {
+			svi:  "web",
+			in:   proxyArp{ProxyArp: true},
+			code: http.StatusOK,
+		},
+		"unknown svi": {
+			svi:  "unknown",
+			in:   proxyArp{LocalProxyArp: true},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			createTestSvi(t)
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/svis/"+tt.svi+"/proxyarp", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			// the proxy ARP outlives an update of the svi through the opi-api
+			svi, err := infradb.GetSvi(fullName("svis", tt.svi))
+			if err != nil {
+				t.Fatal(err)
+			}
+			svi.Spec.ProxyArp = nil
+			if err := infradb.UpdateSvi(svi); err != nil {
+				t.Fatal(err)
+			}
+			req = httptest.NewRequest(http.MethodGet, "/v1/admin/svis/"+tt.svi+"/proxyarp", nil)
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			out := proxyArp{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+				t.Fatal(err)
+			}
+			if out != tt.in {
+				t.Errorf("expected %+v, received %+v", tt.in, out)
+			}
+
+			req = httptest.NewRequest(http.MethodDelete, "/v1/admin/svis/"+tt.svi+"/proxyarp", nil)
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected the proxy ARP to be deleted, received %d: %s", rec.Code, rec.Body.String())
+			}
+			if svi, err := infradb.GetSvi(fullName("svis", tt.svi)); err != nil || svi.Spec.ProxyArp != nil {
+				t.Errorf("expected no proxy ARP, received %+v %v", svi, err)
+			}
+		})
+	}
+}
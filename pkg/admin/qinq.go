@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// qinqRule is the json representation of a rule of the QinQ mapping
+type qinqRule struct {
+	SVlan         uint16 `json:"s_vlan"`
+	CVlan         uint16 `json:"c_vlan"`
+	LogicalBridge string `json:"logical_bridge"`
+}
+
+// qinqMapping is the json representation of the QinQ mapping of a bridge port
+type qinqMapping struct {
+	Rules []qinqRule `json:"rules"`
+}
+
+// toQinqMapping converts the QinQ mapping of the bridge port
+func toQinqMapping(in *infradb.QinqSpec) *qinqMapping {
+	out := &qinqMapping{Rules: make([]qinqRule, 0, len(in.Rules))}
+	for _, rule := range in.Rules {
+		out.Rules = append(out.Rules, qinqRule{SVlan: rule.SVlan, CVlan: rule.CVlan, LogicalBridge: rule.LogicalBridge})
+	}
+	return out
+}
+
+// getBridgePortQinq returns the QinQ mapping of a bridge port
+func getBridgePortQinq(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	name := fullName("ports", params["bridgeport"])
+	bp, err := infradb.GetBP(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if bp.Spec.Qinq == nil {
+		writeError(w, status.Errorf(codes.NotFound, "bridge port %s has no QinQ mapping", name))
+		return
+	}
+	writeResponse(w, http.StatusOK, toQinqMapping(bp.Spec.Qinq))
+}
+
+// setBridgePortQinq maps the double tagged frames of a bridge port to logical bridges
+func setBridgePortQinq(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &qinqMapping{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	rules := make([]infradb.QinqRule, 0, len(in.Rules))
+	for _, rule := range in.Rules {
+		rules = append(rules, infradb.QinqRule{SVlan: rule.SVlan, CVlan: rule.CVlan, LogicalBridge: rule.LogicalBridge})
+	}
+	spec, err := infradb.NewQinqSpec(rules)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	bp, err := infradb.SetBridgePortQinq(fullName("ports", params["bridgeport"]), spec, ifMatch(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, toQinqMapping(bp.Spec.Qinq))
+}
+
+// deleteBridgePortQinq removes the QinQ mapping of a bridge port
+func deleteBridgePortQinq(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if _, err := infradb.SetBridgePortQinq(fullName("ports", params["bridgeport"]), nil, ifMatch(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
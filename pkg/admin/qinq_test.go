@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_SetBridgePortQinq(t *testing.T) {
+	lb := fullName("bridges", "psec")
+	tests := map[string]struct {
+		port string
+		in   qinqMapping
+		code int
+	}{
+		"valid request": {
+			port: "eth2",
+			in:   qinqMapping{Rules: []qinqRule{{SVlan: 100, CVlan: 200, LogicalBridge: lb}, {SVlan: 100, CVlan: 201, LogicalBridge: lb}}},
+			code: http.StatusOK,
+		},
+		"no rule": {
+			port: "eth2",
+			code: http.StatusBadRequest,
+		},
+		"vlan out of range": {
+			port: "eth2",
+			in:   qinqMapping{Rules: []qinqRule{{SVlan: 4095, CVlan: 200, LogicalBridge: lb}}},
+			code: http.StatusBadRequest,
+		},
+		"pair mapped twice": {
+			port: "eth2",
+			in:   qinqMapping{Rules: []qinqRule{{SVlan: 100, CVlan: 200, LogicalBridge: lb}, {SVlan: 100, CVlan: 200, LogicalBridge: lb}}},
+			code: http.StatusBadRequest,
+		},
+		"unknown logical bridge": {
+			port: "eth2",
+			in:   qinqMapping{Rules: []qinqRule{{SVlan: 100, CVlan: 200, LogicalBridge: fullName("bridges", "unknown")}}},
+			code: http.StatusNotFound,
+		},
+		"unknown bridge port": {
+			port: "unknown",
+			in:   qinqMapping{Rules: []qinqRule{{SVlan: 100, CVlan: 200, LogicalBridge: lb}}},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			createTestBridgePort(t)
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/bridgeports/"+tt.port+"/qinq", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &qinqMapping{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(out, &tt.in) {
+				t.Errorf("expected %+v, received %+v", tt.in, out)
+			}
+		})
+	}
+}
+
+func Test_DeleteBridgePortQinq(t *testing.T) {
+	mux := newTestMux(t)
+	createTestBridgePort(t)
+	spec, err := infradb.NewQinqSpec([]infradb.QinqRule{{SVlan: 100, CVlan: 200, LogicalBridge: fullName("bridges", "psec")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bp, err := infradb.SetBridgePortQinq(testBridgePort, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an update through the opi-api has no QinQ mapping, the stored one is kept
+	bp.Spec.Qinq = nil
+	if err := infradb.UpdateBP(bp); err != nil {
+		t.Fatal(err)
+	}
+	if bp, err = infradb.GetBP(testBridgePort); err != nil || bp.Spec.Qinq == nil {
+		t.Fatalf("expected the QinQ mapping to be kept by the update: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/admin/bridgeports/eth2/qinq", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/admin/bridgeports/eth2/qinq", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected code %d, received %d: %s", http.StatusNotFound, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// quotaValue is the json representation of the usage of a quota, a zero limit is unlimited
+type quotaValue struct {
+	Name  string `json:"name,omitempty"`
+	Limit int    `json:"limit"`
+	Used  int    `json:"used"`
+}
+
+// quotaUsage is the json representation of the usage of all the quotas
+type quotaUsage struct {
+	Vnis           quotaValue   `json:"vnis"`
+	SvisPerVrf     []quotaValue `json:"svis_per_vrf"`
+	PortsPerBridge []quotaValue `json:"ports_per_bridge"`
+}
+
+// quotaValuesToJSON translates the usage of a quota per object, sorted by name
+func quotaValuesToJSON(values map[string]infradb.QuotaValue) []quotaValue {
+	out := []quotaValue{}
+	for name, v := range values {
+		out = append(out, quotaValue{Name: name, Limit: v.Limit, Used: v.Used})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// getQuotaUsage returns the limits and the usage of the quotas
+func getQuotaUsage(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	usage, err := infradb.GetQuotaUsage()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, &quotaUsage{
+		Vnis:           quotaValue{Limit: usage.Vnis.Limit, Used: usage.Vnis.Used},
+		SvisPerVrf:     quotaValuesToJSON(usage.SvisPerVrf),
+		PortsPerBridge: quotaValuesToJSON(usage.PortsPerBridge),
+	})
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/pbconv"
+)
+
+// createTestBridge creates a logical bridge with an optional vni
+func createTestBridge(name string, vlan uint32, vni *uint32) error {
+	lb, err := pbconv.LogicalBridgeFromPb(&pb.LogicalBridge{Name: fullName("bridges", name), Spec: &pb.LogicalBridgeSpec{
+		VlanId: vlan,
+		Vni:    vni,
+	}})
+	if err != nil {
+		return err
+	}
+	return infradb.CreateLB(lb)
+}
+
+func Test_GetQuotaUsage(t *testing.T) {
+	mux := newTestMux(t)
+	config.GlobalConfig.Quotas = config.QuotasConfig{MaxVnis: 1, MaxSvisPerVrf: 1}
+	t.Cleanup(func() { config.GlobalConfig.Quotas = config.QuotasConfig{} })
+
+	createTestSvi(t)
+	vni := uint32(100)
+	if err := createTestBridge("db", 20, &vni); err != nil {
+		t.Fatal(err)
+	}
+	other := uint32(200)
+	err := createTestBridge("cache", 30, &other)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected the VNI quota to be exhausted, received %v", err)
+	}
+	svi, err := pbconv.SviFromPb(&pb.Svi{Name: fullName("svis", "db"), Spec: &pb.SviSpec{
+		Vrf:           testVrfA,
+		LogicalBridge: fullName("bridges", "db"),
+		MacAddress:    []byte{0xaa, 0xbb, 0xcc, 0, 0, 2},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateSvi(svi); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected the SVI quota of the VRF to be exhausted, received %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/quotas", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got code %d: %s", rec.Code, rec.Body.String())
+	}
+	out := &quotaUsage{}
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Vnis != (quotaValue{Limit: 1, Used: 1}) {
+		t.Errorf("unexpected VNI usage %+v", out.Vnis)
+	}
+	usage := map[string]quotaValue{}
+	for _, v := range out.SvisPerVrf {
+		usage[v.Name] = v
+	}
+	if usage[testVrfA].Used != 1 || usage[testVrfA].Limit != 1 || usage[testVrfB].Used != 0 {
+		t.Errorf("unexpected SVI usage %+v", out.SvisPerVrf)
+	}
+	if len(out.PortsPerBridge) != 2 {
+		t.Errorf("expected the usage of 2 logical bridges, received %+v", out.PortsPerBridge)
+	}
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/interceptor"
+)
+
+// writableWhenReadOnly holds the admin endpoints which still change the bridge while it is read-only:
+// leaving the mode, draining the node and keeping the leases alive
+var writableWhenReadOnly = map[string]bool{
+	"/v1/admin/readonly":                                 true,
+	"/v1/admin/maintenance":                              true,
+	"/v1/admin/leases/{collection}/{resource}/keepalive": true,
+}
+
+// readOnlyMode is the json representation of the read-only mode
+type readOnlyMode struct {
+	Enabled      bool       `json:"enabled"`
+	AllowDeletes bool       `json:"allow_deletes,omitempty"`
+	Reason       string     `json:"reason,omitempty"`
+	Since        *time.Time `json:"since,omitempty"`
+}
+
+// readOnlyToJSON translates the read-only mode to its json representation
+func readOnlyToJSON(mode infradb.ReadOnlyMode) *readOnlyMode {
+	return &readOnlyMode{
+		Enabled:      mode.Enabled,
+		AllowDeletes: mode.AllowDeletes,
+		Reason:       mode.Reason,
+		Since:        timeToJSON(mode.Since),
+	}
+}
+
+// guardReadOnly rejects the requests of an endpoint changing the bridge while it is read-only
+func guardReadOnly(r route) runtime.HandlerFunc {
+	if r.method == http.MethodGet || writableWhenReadOnly[r.pattern] {
+		return r.handler
+	}
+	return func(w http.ResponseWriter, req *http.Request, params map[string]string) {
+		mode, err := infradb.GetReadOnlyMode()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if err := interceptor.CheckWritable(mode, r.method+" "+r.pattern, r.method == http.MethodDelete); err != nil {
+			writeError(w, err)
+			return
+		}
+		r.handler(w, req, params)
+	}
+}
+
+// getReadOnly returns the read-only mode of the bridge
+func getReadOnly(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	mode, err := infradb.GetReadOnlyMode()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, readOnlyToJSON(mode))
+}
+
+// setReadOnly makes the bridge read-only, or changes the reason or the deletes of the mode
+func setReadOnly(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &readOnlyMode{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	mode, err := infradb.SetReadOnlyMode(infradb.ReadOnlyMode{Enabled: true, AllowDeletes: in.AllowDeletes, Reason: in.Reason})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, readOnlyToJSON(mode))
+}
+
+// deleteReadOnly makes the bridge writable again
+func deleteReadOnly(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	mode, err := infradb.SetReadOnlyMode(infradb.ReadOnlyMode{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, readOnlyToJSON(mode))
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_ReadOnly(t *testing.T) {
+	mux := newTestMux(t)
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodPut, "/v1/admin/readonly", `{"reason":"change freeze"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	out := &readOnlyMode{}
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Enabled || out.Reason != "change freeze" || out.Since == nil {
+		t.Errorf("unexpected mode %+v", out)
+	}
+
+	tests := map[string]struct {
+		method string
+		url    string
+		body   string
+		code   int
+	}{
+		"create rejected": {
+			method: http.MethodPost,
+			url:    "/v1/admin/routeleaks?id=blue-red",
+			body:   `{}`,
+			code:   http.StatusBadRequest,
+		},
+		"delete rejected": {
+			method: http.MethodDelete,
+			url:    "/v1/admin/routeleaks/blue-red",
+			code:   http.StatusBadRequest,
+		},
+		"read allowed": {
+			method: http.MethodGet,
+			url:    "/v1/admin/routeleaks",
+			code:   http.StatusOK,
+		},
+		"mode read": {
+			method: http.MethodGet,
+			url:    "/v1/admin/readonly",
+			code:   http.StatusOK,
+		},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			if rec := serve(tt.method, tt.url, tt.body); rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	// the deletes go through when the mode allows them
+	serve(http.MethodPut, "/v1/admin/readonly", `{"reason":"change freeze","allow_deletes":true}`)
+	if rec := serve(http.MethodDelete, "/v1/admin/routeleaks/blue-red", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected code %d, received %d: %s", http.StatusNotFound, rec.Code, rec.Body.String())
+	}
+
+	if rec := serve(http.MethodDelete, "/v1/admin/readonly", ""); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "since") {
+		t.Errorf("expected the bridge to be writable, received %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(http.MethodDelete, "/v1/admin/routeleaks/blue-red", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected code %d, received %d: %s", http.StatusNotFound, rec.Code, rec.Body.String())
+	}
+}
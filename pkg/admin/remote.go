@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/remote"
+)
+
+// remoteNode is the json representation of a node agent connected to the controller
+type remoteNode struct {
+	Name        string    `json:"name"`
+	Address     string    `json:"address"`
+	Version     string    `json:"version,omitempty"`
+	State       string    `json:"state"`
+	Serving     bool      `json:"serving"`
+	ConnectedAt time.Time `json:"connected_at"`
+	LastSeen    time.Time `json:"last_seen"`
+	Pending     int       `json:"pending"`
+	Realized    int       `json:"realized"`
+	Failed      []string  `json:"failed"`
+}
+
+// listNodes returns the node agents of the controller with the objects they failed to realize
+func listNodes(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	if config.GlobalConfig.Remote.Mode != remote.ModeController {
+		writeError(w, status.Error(codes.FailedPrecondition, "the bridge is not the controller of node agents"))
+		return
+	}
+	out := []remoteNode{}
+	for _, n := range remote.GetNodes() {
+		out = append(out, remoteNode{
+			Name:        n.Name,
+			Address:     n.Address,
+			Version:     n.Version,
+			State:       n.State,
+			Serving:     n.Serving,
+			ConnectedAt: n.ConnectedAt,
+			LastSeen:    n.LastSeen,
+			Pending:     n.Pending,
+			Realized:    n.Realized,
+			Failed:      n.Failed,
+		})
+	}
+	writeResponse(w, http.StatusOK, out)
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"sort"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// routeLeak is the json representation of a route leak
+type routeLeak struct {
+	Name       string      `json:"name,omitempty"`
+	SrcVrf     string      `json:"src_vrf"`
+	DstVrf     string      `json:"dst_vrf"`
+	Prefixes   []string    `json:"prefixes"`
+	OperStatus string      `json:"oper_status,omitempty"`
+	Components []component `json:"components,omitempty"`
+}
+
+// routeLeakToJSON translates the domain object to its json representation
+func routeLeakToJSON(rl *infradb.RouteLeak) *routeLeak {
+	out := &routeLeak{
+		Name:       rl.Name,
+		SrcVrf:     rl.Spec.SrcVrf,
+		DstVrf:     rl.Spec.DstVrf,
+		OperStatus: rl.Status.OperStatus.String(),
+		Components: componentsToJSON(rl.Status.Components),
+	}
+	for _, prefix := range rl.Spec.Prefixes {
+		out.Prefixes = append(out.Prefixes, prefix.String())
+	}
+	return out
+}
+
+// createRouteLeak creates a route leak between two vrfs
+func createRouteLeak(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &routeLeak{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("routeleaks", resourceID)
+	spec := &infradb.RouteLeakSpec{SrcVrf: in.SrcVrf, DstVrf: in.DstVrf}
+	for _, prefix := range in.Prefixes {
+		_, ipnet, err := net.ParseCIDR(prefix)
+		if err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid prefix %s: %v", prefix, err))
+			return
+		}
+		spec.Prefixes = append(spec.Prefixes, ipnet)
+	}
+	rl, err := infradb.NewRouteLeak(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetRouteLeak(name); err == nil {
+		if !sameSpec(rl.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("routeleaks", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createRouteLeak(): Already existing Route Leak with id %v", name)
+		writeResponse(w, http.StatusOK, routeLeakToJSON(existing))
+		return
+	}
+	if err := infradb.CreateRouteLeak(rl); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, routeLeakToJSON(rl))
+}
+
+// getRouteLeak returns a route leak
+func getRouteLeak(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	rl, err := infradb.GetRouteLeak(fullName("routeleaks", params["routeleak"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, routeLeakToJSON(rl))
+}
+
+// listRouteLeaks returns all the route leaks
+func listRouteLeaks(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	rls, err := infradb.GetAllRouteLeaks()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(rls, func(i, j int) bool { return rls[i].Name < rls[j].Name })
+	out := []*routeLeak{}
+	for _, rl := range rls {
+		out = append(out, routeLeakToJSON(rl))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"route_leaks": out})
+}
+
+// deleteRouteLeak deletes a route leak
+func deleteRouteLeak(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteRouteLeak(fullName("routeleaks", params["routeleak"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
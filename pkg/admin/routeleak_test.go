@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+var (
+	testVrfA = fullName("vrfs", "opi-vrf-a")
+	testVrfB = fullName("vrfs", "opi-vrf-b")
+	testVrfC = fullName("vrfs", "opi-vrf-c")
+)
+
+// newTestMux creates a gateway mux with the admin handlers on top of an empty gomap db
+func newTestMux(t *testing.T) *runtime.ServeMux {
+	eb := eventbus.EBus
+	eb.StartSubscriber("dummy", "vrf", 1, nil)
+	eb.StartSubscriber("dummy", "logical-bridge", 1, nil)
+	eb.StartSubscriber("dummy", "svi", 1, nil)
+	eb.StartSubscriber("dummy", "route-leak", 1, nil)
+	eb.StartSubscriber("dummy", "host-route", 1, nil)
+	eb.StartSubscriber("dummy", "nat-gateway", 1, nil)
+	eb.StartSubscriber("dummy", "dns-forwarder", 1, nil)
+	eb.StartSubscriber("dummy", "dhcp-server", 1, nil)
+	eb.StartSubscriber("dummy", "router-advertisement", 1, nil)
+	eb.StartSubscriber("dummy", "external-interface", 1, nil)
+	eb.StartSubscriber("dummy", "bond", 1, nil)
+	eb.StartSubscriber("dummy", "bridge-port", 1, nil)
+	eb.StartSubscriber("dummy", "port-security", 1, nil)
+	eb.StartSubscriber("dummy", "dhcp-snooping", 1, nil)
+	eb.StartSubscriber("dummy", "conntrack-policy", 1, nil)
+	eb.StartSubscriber("dummy", "virtual-port", 1, nil)
+	eb.StartSubscriber("dummy", "vf-representor", 1, nil)
+	eb.StartSubscriber("dummy", "routing-policy", 1, nil)
+	eb.StartSubscriber("dummy", "vpc-peering", 1, nil)
+	eb.StartSubscriber("dummy", "flow-log", 1, nil)
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{testVrfA, testVrfB, testVrfC} {
+		vrf, err := infradb.NewVrfWithArgs(name, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := infradb.CreateVrf(vrf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mux := runtime.NewServeMux()
+	if err := RegisterHandlers(mux); err != nil {
+		t.Fatal(err)
+	}
+	return mux
+}
+
+func createTestRouteLeak(t *testing.T, name string, src, dst string, prefixes ...string) {
+	spec := &infradb.RouteLeakSpec{SrcVrf: src, DstVrf: dst}
+	for _, prefix := range prefixes {
+		_, ipnet, _ := net.ParseCIDR(prefix)
+		spec.Prefixes = append(spec.Prefixes, ipnet)
+	}
+	rl, err := infradb.NewRouteLeak(name, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateRouteLeak(rl); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// createTestVpcPeering peers the vrfs, the way from a to b filtered by the prefixes when given
+func createTestVpcPeering(t *testing.T, name string, a, b string, prefixesA ...string) {
+	spec := &infradb.VpcPeeringSpec{VrfA: a, VrfB: b}
+	for _, prefix := range prefixesA {
+		_, ipnet, _ := net.ParseCIDR(prefix)
+		spec.PrefixesA = append(spec.PrefixesA, ipnet)
+	}
+	vp, err := infradb.NewVpcPeering(name, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateVpcPeering(vp); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_CreateRouteLeak(t *testing.T) {
+	tests := map[string]struct {
+		existing func(t *testing.T)
+		in       routeLeak
+		code     int
+	}{
+		"valid request": {
+			in:   routeLeak{SrcVrf: testVrfA, DstVrf: testVrfB, Prefixes: []string{"10.0.0.0/24"}},
+			code: http.StatusOK,
+		},
+		"same vrf": {
+			in:   routeLeak{SrcVrf: testVrfA, DstVrf: testVrfA, Prefixes: []string{"10.0.0.0/24"}},
+			code: http.StatusBadRequest,
+		},
+		"missing prefixes": {
+			in:   routeLeak{SrcVrf: testVrfA, DstVrf: testVrfB},
+			code: http.StatusBadRequest,
+		},
+		"invalid prefix": {
+			in:   routeLeak{SrcVrf: testVrfA, DstVrf: testVrfB, Prefixes: []string{"10.0.0.300/24"}},
+			code: http.StatusBadRequest,
+		},
+		"ipv6 prefix": {
+			in:   routeLeak{SrcVrf: testVrfA, DstVrf: testVrfB, Prefixes: []string{"10.0.0.0/24", "2001:db8::/64"}},
+			code: http.StatusOK,
+		},
+		"unknown vrf": {
+			in:   routeLeak{SrcVrf: testVrfA, DstVrf: fullName("vrfs", "unknown"), Prefixes: []string{"10.0.0.0/24"}},
+			code: http.StatusNotFound,
+		},
+		"direct loop": {
+			existing: func(t *testing.T) {
+				createTestRouteLeak(t, fullName("routeleaks", "a-to-b"), testVrfA, testVrfB, "10.0.0.0/16")
+			},
+			in:   routeLeak{SrcVrf: testVrfB, DstVrf: testVrfA, Prefixes: []string{"10.0.1.0/24"}},
+			code: http.StatusBadRequest,
+		},
+		"transitive loop": {
+			existing: func(t *testing.T) {
+				createTestRouteLeak(t, fullName("routeleaks", "a-to-b"), testVrfA, testVrfB, "10.0.0.0/24")
+				createTestRouteLeak(t, fullName("routeleaks", "b-to-c"), testVrfB, testVrfC, "10.0.0.0/24")
+			},
+			in:   routeLeak{SrcVrf: testVrfC, DstVrf: testVrfA, Prefixes: []string{"10.0.0.0/8"}},
+			code: http.StatusBadRequest,
+		},
+		"loop through a vpc peering": {
+			existing: func(t *testing.T) {
+				createTestRouteLeak(t, fullName("routeleaks", "a-to-b"), testVrfA, testVrfB, "2001:db8::/32")
+				createTestVpcPeering(t, fullName("vpcpeerings", "b-c"), testVrfB, testVrfC)
+			},
+			in:   routeLeak{SrcVrf: testVrfC, DstVrf: testVrfA, Prefixes: []string{"2001:db8:1::/64"}},
+			code: http.StatusBadRequest,
+		},
+		"no loop through a filtered vpc peering": {
+			existing: func(t *testing.T) {
+				createTestRouteLeak(t, fullName("routeleaks", "a-to-b"), testVrfA, testVrfB, "10.0.0.0/24")
+				createTestVpcPeering(t, fullName("vpcpeerings", "b-c"), testVrfB, testVrfC, "10.2.0.0/24")
+			},
+			in:   routeLeak{SrcVrf: testVrfC, DstVrf: testVrfA, Prefixes: []string{"10.0.0.0/24"}},
+			code: http.StatusOK,
+		},
+		"reverse leak of other prefixes": {
+			existing: func(t *testing.T) {
+				createTestRouteLeak(t, fullName("routeleaks", "a-to-b"), testVrfA, testVrfB, "10.0.0.0/24")
+			},
+			in:   routeLeak{SrcVrf: testVrfB, DstVrf: testVrfA, Prefixes: []string{"10.1.0.0/24"}},
+			code: http.StatusOK,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			if tt.existing != nil {
+				tt.existing(t)
+			}
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/routeleaks?id=opi-leak", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &routeLeak{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("routeleaks", "opi-leak") || out.OperStatus != "DOWN" {
+				t.Errorf("unexpected route leak %+v", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net/http"
+	"sort"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// routerAdvertisement is the json representation of the router advertisements of an svi
+type routerAdvertisement struct {
+	Name       string      `json:"name,omitempty"`
+	Svi        string      `json:"svi"`
+	Managed    bool        `json:"managed,omitempty"`
+	Other      bool        `json:"other,omitempty"`
+	Prefixes   []string    `json:"prefixes,omitempty"`
+	Rdnss      []string    `json:"rdnss,omitempty"`
+	Interval   uint32      `json:"interval,omitempty"`
+	Mtu        uint32      `json:"mtu,omitempty"`
+	OperStatus string      `json:"oper_status,omitempty"`
+	Components []component `json:"components,omitempty"`
+}
+
+// routerAdvertisementToJSON translates the domain object to its json representation
+func routerAdvertisementToJSON(ra *infradb.RouterAdvertisement) *routerAdvertisement {
+	return &routerAdvertisement{
+		Name:       ra.Name,
+		Svi:        ra.Spec.Svi,
+		Managed:    ra.Spec.Managed,
+		Other:      ra.Spec.Other,
+		Prefixes:   prefixesToJSON(ra.Spec.Prefixes),
+		Rdnss:      ipsToJSON(ra.Spec.Rdnss),
+		Interval:   ra.Spec.Interval,
+		Mtu:        ra.Spec.Mtu,
+		OperStatus: ra.Status.OperStatus.String(),
+		Components: componentsToJSON(ra.Status.Components),
+	}
+}
+
+// routerAdvertisementSpecFromJSON translates the json representation to the domain spec
+func routerAdvertisementSpecFromJSON(in *routerAdvertisement) (*infradb.RouterAdvertisementSpec, error) {
+	spec := &infradb.RouterAdvertisementSpec{
+		Svi:      in.Svi,
+		Managed:  in.Managed,
+		Other:    in.Other,
+		Interval: in.Interval,
+		Mtu:      in.Mtu,
+	}
+	var err error
+	if spec.Prefixes, err = parsePrefixes(in.Prefixes); err != nil {
+		return nil, err
+	}
+	if spec.Rdnss, err = ipsFromJSON(in.Rdnss); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// createRouterAdvertisement starts advertising the IPv6 subnets of an svi
+func createRouterAdvertisement(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &routerAdvertisement{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("routeradvertisements", resourceID)
+	spec, err := routerAdvertisementSpecFromJSON(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	ra, err := infradb.NewRouterAdvertisement(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetRouterAdvertisement(name); err == nil {
+		if !sameSpec(ra.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("routeradvertisements", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createRouterAdvertisement(): Already existing Router Advertisement with id %v", name)
+		writeResponse(w, http.StatusOK, routerAdvertisementToJSON(existing))
+		return
+	}
+	if err := infradb.CreateRouterAdvertisement(ra); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, routerAdvertisementToJSON(ra))
+}
+
+// getRouterAdvertisement returns the router advertisements of an svi
+func getRouterAdvertisement(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	ra, err := infradb.GetRouterAdvertisement(fullName("routeradvertisements", params["routeradvertisement"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, routerAdvertisementToJSON(ra))
+}
+
+// listRouterAdvertisements returns all the router advertisements
+func listRouterAdvertisements(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	ras, err := infradb.GetAllRouterAdvertisements()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(ras, func(i, j int) bool { return ras[i].Name < ras[j].Name })
+	out := []*routerAdvertisement{}
+	for _, ra := range ras {
+		out = append(out, routerAdvertisementToJSON(ra))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"router_advertisements": out})
+}
+
+// deleteRouterAdvertisement stops advertising the subnets of an svi
+func deleteRouterAdvertisement(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteRouterAdvertisement(fullName("routeradvertisements", params["routeradvertisement"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
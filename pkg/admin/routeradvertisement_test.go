@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/pbconv"
+)
+
+// createTestDualStackSvi creates the svi "web6" of testVrfA with the gateways 10.0.6.1/24 and 2001:db8:6::1/64
+func createTestDualStackSvi(t *testing.T) {
+	lbName := fullName("bridges", "web6")
+	lb, err := pbconv.LogicalBridgeFromPb(&pb.LogicalBridge{Name: lbName, Spec: &pb.LogicalBridgeSpec{
+		VlanId: 60,
+		VtepIpPrefix: &pc.IPPrefix{
+			Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 0x0a010101}},
+			Len:  32,
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateLB(lb); err != nil {
+		t.Fatal(err)
+	}
+	svi, err := pbconv.SviFromPb(&pb.Svi{Name: fullName("svis", "web6"), Spec: &pb.SviSpec{
+		Vrf:           testVrfA,
+		LogicalBridge: lbName,
+		MacAddress:    []byte{0xaa, 0xbb, 0xcc, 0, 0, 6},
+		GwIpPrefix: []*pc.IPPrefix{
+			{
+				Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 0x0a000601}},
+				Len:  24,
+			},
+			{
+				Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET6, V4OrV6: &pc.IPAddress_V6Addr{V6Addr: net.ParseIP("2001:db8:6::1")}},
+				Len:  64,
+			},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateSvi(svi); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_CreateRouterAdvertisement(t *testing.T) {
+	svi := fullName("svis", "web6")
+	tests := map[string]struct {
+		existing *routerAdvertisement
+		in       routerAdvertisement
+		code     int
+	}{
+		"defaults": {
+			in:   routerAdvertisement{Svi: svi},
+			code: http.StatusOK,
+		},
+		"managed with prefixes and dns": {
+			in: routerAdvertisement{Svi: svi, Managed: true, Other: true, Prefixes: []string{"2001:db8:6::/64"},
+				Rdnss: []string{"2001:db8::53"}, Interval: 30, Mtu: 1450},
+			code: http.StatusOK,
+		},
+		"IPv4 prefix": {
+			in:   routerAdvertisement{Svi: svi, Prefixes: []string{"10.0.6.0/24"}},
+			code: http.StatusBadRequest,
+		},
+		"IPv4 dns server": {
+			in:   routerAdvertisement{Svi: svi, Rdnss: []string{"10.0.0.53"}},
+			code: http.StatusBadRequest,
+		},
+		"interval too long": {
+			in:   routerAdvertisement{Svi: svi, Interval: 3600},
+			code: http.StatusBadRequest,
+		},
+		"mtu below the IPv6 minimum": {
+			in:   routerAdvertisement{Svi: svi, Mtu: 1000},
+			code: http.StatusBadRequest,
+		},
+		"svi without IPv6 gateway": {
+			in:   routerAdvertisement{Svi: fullName("svis", "web")},
+			code: http.StatusBadRequest,
+		},
+		"unknown svi": {
+			in:   routerAdvertisement{Svi: fullName("svis", "unknown")},
+			code: http.StatusNotFound,
+		},
+		"svi already advertised": {
+			existing: &routerAdvertisement{Svi: svi},
+			in:       routerAdvertisement{Svi: svi, Interval: 30},
+			code:     http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			createTestSvi(t)
+			createTestDualStackSvi(t)
+			if tt.existing != nil {
+				body, _ := json.Marshal(tt.existing)
+				req := httptest.NewRequest(http.MethodPost, "/v1/admin/routeradvertisements?id=existing-ra", bytes.NewReader(body))
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("failed to create existing router advertisement: %s", rec.Body.String())
+				}
+			}
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/routeradvertisements?id=opi-ra", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &routerAdvertisement{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("routeradvertisements", "opi-ra") || out.Svi != svi || out.Interval == 0 || out.OperStatus != "DOWN" {
+				t.Errorf("unexpected router advertisement %+v", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// evpnVni is the json representation of the state of a VNI in the routing stack
+type evpnVni struct {
+	Vni       uint32   `json:"vni"`
+	Type      string   `json:"type"`
+	VxlanIf   string   `json:"vxlan_if"`
+	TenantVrf string   `json:"tenant_vrf"`
+	NumMacs   int      `json:"num_macs"`
+	NumArpNd  int      `json:"num_arp_nd"`
+	InKernel  bool     `json:"in_kernel"`
+	Rd        string   `json:"rd,omitempty"`
+	ImportRts []string `json:"import_rts,omitempty"`
+	ExportRts []string `json:"export_rts,omitempty"`
+}
+
+// evpnRoute is the json representation of a path of the l2vpn evpn table
+type evpnRoute struct {
+	Rd        string   `json:"rd"`
+	Prefix    string   `json:"prefix"`
+	RouteType int      `json:"route_type"`
+	Mac       string   `json:"mac,omitempty"`
+	IP        string   `json:"ip,omitempty"`
+	Nexthops  []string `json:"nexthops"`
+	Best      bool     `json:"best"`
+	PathFrom  string   `json:"path_from"`
+}
+
+// bgpSession is the json representation of a bgp session of a vrf
+type bgpSession struct {
+	Afi      string `json:"afi"`
+	Address  string `json:"address"`
+	RemoteAs string `json:"remote_as"`
+	State    string `json:"state"`
+	Uptime   string `json:"uptime"`
+	PfxRcd   int    `json:"pfx_rcd"`
+	PfxSnt   int    `json:"pfx_snt"`
+	// GrLocalMode and GrRemoteMode are the graceful restart modes of the bridge and of the peer
+	GrLocalMode       string `json:"gr_local_mode,omitempty"`
+	GrRemoteMode      string `json:"gr_remote_mode,omitempty"`
+	GrRestartTime     int    `json:"gr_restart_time,omitempty"`
+	GrPeerRestartTime int    `json:"gr_peer_restart_time,omitempty"`
+}
+
+// bgpRoute is the json representation of a path of the unicast table of a vrf
+type bgpRoute struct {
+	Prefix   string   `json:"prefix"`
+	Nexthops []string `json:"nexthops"`
+	Best     bool     `json:"best"`
+	PathFrom string   `json:"path_from"`
+	AsPath   string   `json:"as_path,omitempty"`
+	Origin   string   `json:"origin"`
+	// Multipath tells that the path is one of the ECMP paths installed for the prefix
+	Multipath bool   `json:"multipath"`
+	Weight    uint32 `json:"weight,omitempty"`
+}
+
+// nexthopMember is the json representation of a remote vtep of a nexthop group
+type nexthopMember struct {
+	Nexthop string `json:"nexthop"`
+	Weight  uint32 `json:"weight,omitempty"`
+}
+
+// nexthopGroup is the json representation of a kernel nexthop object of the received routes
+type nexthopGroup struct {
+	ID      uint32          `json:"id"`
+	Vrf     string          `json:"vrf"`
+	Members []nexthopMember `json:"members"`
+	Routes  int             `json:"routes"`
+}
+
+// nexthopGroupStats is the json representation of the reuse of the nexthop groups
+type nexthopGroupStats struct {
+	Groups  int    `json:"groups"`
+	Routes  int    `json:"routes"`
+	Created uint64 `json:"created"`
+	Deleted uint64 `json:"deleted"`
+	Reused  uint64 `json:"reused"`
+}
+
+// nexthopGroups is the json representation of the nexthop groups and their statistics
+type nexthopGroups struct {
+	Groups []nexthopGroup    `json:"groups"`
+	Stats  nexthopGroupStats `json:"stats"`
+}
+
+// routingError translates the failure to query the routing stack, which is unavailable when disabled or not answering
+func routingError(err error) error {
+	return status.Errorf(codes.Unavailable, "failed to query the routing backend: %v", err)
+}
+
+// routingVrf checks that the vrf of the request exists and returns its name
+func routingVrf(params map[string]string) (string, error) {
+	name := fullName("vrfs", params["vrf"])
+	if _, err := infradb.GetVrf(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// listEvpnVnis returns the VNIs known by the routing stack
+func listEvpnVnis(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	backend, err := routing.Get()
+	if err != nil {
+		writeError(w, routingError(err))
+		return
+	}
+	vnis, err := backend.EvpnVnis(r.Context())
+	if err != nil {
+		writeError(w, routingError(err))
+		return
+	}
+	out := []evpnVni{}
+	for _, v := range vnis {
+		out = append(out, evpnVni(v))
+	}
+	writeResponse(w, http.StatusOK, out)
+}
+
+// listEvpnRoutes returns the routes of the l2vpn evpn table learned and advertised by the routing stack
+func listEvpnRoutes(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	backend, err := routing.Get()
+	if err != nil {
+		writeError(w, routingError(err))
+		return
+	}
+	routes, err := backend.EvpnRoutes(r.Context())
+	if err != nil {
+		writeError(w, routingError(err))
+		return
+	}
+	out := []evpnRoute{}
+	for _, rt := range routes {
+		out = append(out, evpnRoute(rt))
+	}
+	writeResponse(w, http.StatusOK, out)
+}
+
+// listBgpPeers returns the bgp sessions of the vrf
+func listBgpPeers(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	vrf, err := routingVrf(params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	backend, err := routing.Get()
+	if err != nil {
+		writeError(w, routingError(err))
+		return
+	}
+	peers, err := backend.BgpPeers(r.Context(), vrf)
+	if err != nil {
+		writeError(w, routingError(err))
+		return
+	}
+	out := []bgpSession{}
+	for _, p := range peers {
+		out = append(out, bgpSession(p))
+	}
+	writeResponse(w, http.StatusOK, out)
+}
+
+// listBgpRoutes returns the routes of the unicast tables of the vrf
+func listBgpRoutes(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	vrf, err := routingVrf(params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	backend, err := routing.Get()
+	if err != nil {
+		writeError(w, routingError(err))
+		return
+	}
+	routes, err := backend.BgpRoutes(r.Context(), vrf)
+	if err != nil {
+		writeError(w, routingError(err))
+		return
+	}
+	out := []bgpRoute{}
+	for _, rt := range routes {
+		out = append(out, bgpRoute(rt))
+	}
+	writeResponse(w, http.StatusOK, out)
+}
+
+// listNexthopGroups returns the kernel nexthop groups of the received routes and how much the routes share them
+func listNexthopGroups(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	backend, err := routing.Get()
+	if err != nil {
+		writeError(w, routingError(err))
+		return
+	}
+	groups, stats, err := backend.NexthopGroups(r.Context())
+	if err != nil {
+		writeError(w, routingError(err))
+		return
+	}
+	out := nexthopGroups{Groups: []nexthopGroup{}, Stats: nexthopGroupStats(stats)}
+	for _, g := range groups {
+		group := nexthopGroup{ID: g.ID, Vrf: g.Vrf, Members: []nexthopMember{}, Routes: g.Routes}
+		for _, m := range g.Members {
+			group.Members = append(group.Members, nexthopMember(m))
+		}
+		out.Groups = append(out.Groups, group)
+	}
+	writeResponse(w, http.StatusOK, out)
+}
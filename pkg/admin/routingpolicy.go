@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"sort"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// routingPolicyRule is the json representation of a rule of a routing policy
+type routingPolicyRule struct {
+	Seq uint32 `json:"seq"`
+	// Action is permit or deny
+	Action           string   `json:"action"`
+	MatchPrefixes    []string `json:"match_prefixes,omitempty"`
+	MaxPrefixLen     uint32   `json:"max_prefix_len,omitempty"`
+	MatchCommunities []string `json:"match_communities,omitempty"`
+	SetLocalPref     *uint32  `json:"set_local_pref,omitempty"`
+	SetMed           *uint32  `json:"set_med,omitempty"`
+	SetCommunities   []string `json:"set_communities,omitempty"`
+}
+
+// routingPolicyAttachment is the json representation of the attachment of a routing policy
+type routingPolicyAttachment struct {
+	// Target is the name of a vrf or of an svi running bgp
+	Target    string `json:"target"`
+	Direction string `json:"direction"`
+}
+
+// routingPolicy is the json representation of a routing policy
+type routingPolicy struct {
+	Name        string                     `json:"name,omitempty"`
+	Rules       []*routingPolicyRule       `json:"rules"`
+	Attachments []*routingPolicyAttachment `json:"attachments,omitempty"`
+	OperStatus  string                     `json:"oper_status,omitempty"`
+	Components  []component                `json:"components,omitempty"`
+}
+
+// routingPolicyToJSON translates the domain object to its json representation
+func routingPolicyToJSON(rp *infradb.RoutingPolicy) *routingPolicy {
+	out := &routingPolicy{
+		Name:       rp.Name,
+		OperStatus: rp.Status.OperStatus.String(),
+		Components: componentsToJSON(rp.Status.Components),
+	}
+	for _, rule := range rp.Spec.Rules {
+		r := &routingPolicyRule{
+			Seq:              rule.Seq,
+			Action:           "deny",
+			MaxPrefixLen:     rule.MaxPrefixLen,
+			MatchCommunities: rule.MatchCommunities,
+			SetLocalPref:     rule.SetLocalPref,
+			SetMed:           rule.SetMed,
+			SetCommunities:   rule.SetCommunities,
+		}
+		if rule.Permit {
+			r.Action = "permit"
+		}
+		for _, prefix := range rule.MatchPrefixes {
+			r.MatchPrefixes = append(r.MatchPrefixes, prefix.String())
+		}
+		out.Rules = append(out.Rules, r)
+	}
+	for _, att := range rp.Spec.Attachments {
+		out.Attachments = append(out.Attachments, &routingPolicyAttachment{Target: att.Target, Direction: att.Direction})
+	}
+	return out
+}
+
+// routingPolicySpecFromJSON translates the json representation to the spec of the domain object
+func routingPolicySpecFromJSON(in *routingPolicy) (*infradb.RoutingPolicySpec, error) {
+	spec := &infradb.RoutingPolicySpec{}
+	for _, r := range in.Rules {
+		if r.Action != "permit" && r.Action != "deny" {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid action %q of rule %d, it has to be permit or deny", r.Action, r.Seq)
+		}
+		rule := &infradb.RoutingPolicyRule{
+			Seq:              r.Seq,
+			Permit:           r.Action == "permit",
+			MaxPrefixLen:     r.MaxPrefixLen,
+			MatchCommunities: r.MatchCommunities,
+			SetLocalPref:     r.SetLocalPref,
+			SetMed:           r.SetMed,
+			SetCommunities:   r.SetCommunities,
+		}
+		for _, prefix := range r.MatchPrefixes {
+			_, ipnet, err := net.ParseCIDR(prefix)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid prefix %s: %v", prefix, err)
+			}
+			rule.MatchPrefixes = append(rule.MatchPrefixes, ipnet)
+		}
+		spec.Rules = append(spec.Rules, rule)
+	}
+	for _, att := range in.Attachments {
+		spec.Attachments = append(spec.Attachments, &infradb.RoutingPolicyAttachment{Target: att.Target, Direction: att.Direction})
+	}
+	return spec, nil
+}
+
+// createRoutingPolicy creates a routing policy
+func createRoutingPolicy(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &routingPolicy{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("routingpolicies", resourceID)
+	spec, err := routingPolicySpecFromJSON(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	rp, err := infradb.NewRoutingPolicy(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetRoutingPolicy(name); err == nil {
+		if !sameSpec(rp.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("routingpolicies", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createRoutingPolicy(): Already existing Routing Policy with id %v", name)
+		writeResponse(w, http.StatusOK, routingPolicyToJSON(existing))
+		return
+	}
+	if err := infradb.CreateRoutingPolicy(rp); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, routingPolicyToJSON(rp))
+}
+
+// updateRoutingPolicy replaces the rules and the attachments of a routing policy,
+// every object it is attached to gets the new rules
+func updateRoutingPolicy(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &routingPolicy{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	name := fullName("routingpolicies", params["routingpolicy"])
+	spec, err := routingPolicySpecFromJSON(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	rp, err := infradb.NewRoutingPolicy(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	if err := infradb.UpdateRoutingPolicy(rp, ifMatch(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, routingPolicyToJSON(rp))
+}
+
+// getRoutingPolicy returns a routing policy
+func getRoutingPolicy(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	rp, err := infradb.GetRoutingPolicy(fullName("routingpolicies", params["routingpolicy"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, routingPolicyToJSON(rp))
+}
+
+// listRoutingPolicies returns all the routing policies
+func listRoutingPolicies(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	rps, err := infradb.GetAllRoutingPolicies()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(rps, func(i, j int) bool { return rps[i].Name < rps[j].Name })
+	out := []*routingPolicy{}
+	for _, rp := range rps {
+		out = append(out, routingPolicyToJSON(rp))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"routing_policies": out})
+}
+
+// deleteRoutingPolicy deletes a routing policy and detaches it from the objects it is attached to
+func deleteRoutingPolicy(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteRoutingPolicy(fullName("routingpolicies", params["routingpolicy"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// testPolicyRules prefers the routes of 10.0.0.0/8
+var testPolicyRules = []*routingPolicyRule{
+	{Seq: 10, Action: "permit", MatchPrefixes: []string{"10.0.0.0/8"}, MaxPrefixLen: 24, SetLocalPref: new(uint32)},
+	{Seq: 20, Action: "permit"},
+}
+
+// putRoutingPolicy sends the routing policy with the method and returns the response
+func putRoutingPolicy(t *testing.T, mux http.Handler, method, url string, in *routingPolicy) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(in)
+	req := httptest.NewRequest(method, url, bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func Test_CreateRoutingPolicy(t *testing.T) {
+	tests := map[string]struct {
+		existing func(t *testing.T)
+		in       routingPolicy
+		code     int
+	}{
+		"valid request": {
+			in:   routingPolicy{Rules: testPolicyRules, Attachments: []*routingPolicyAttachment{{Target: testVrfA, Direction: "export"}}},
+			code: http.StatusOK,
+		},
+		"no rule": {
+			in:   routingPolicy{Attachments: []*routingPolicyAttachment{{Target: testVrfA, Direction: "export"}}},
+			code: http.StatusBadRequest,
+		},
+		"invalid action": {
+			in:   routingPolicy{Rules: []*routingPolicyRule{{Seq: 10, Action: "accept"}}},
+			code: http.StatusBadRequest,
+		},
+		"duplicated sequence": {
+			in:   routingPolicy{Rules: []*routingPolicyRule{{Seq: 10, Action: "permit"}, {Seq: 10, Action: "deny"}}},
+			code: http.StatusBadRequest,
+		},
+		"mixed families": {
+			in:   routingPolicy{Rules: []*routingPolicyRule{{Seq: 10, Action: "permit", MatchPrefixes: []string{"10.0.0.0/8", "2001:db8::/32"}}}},
+			code: http.StatusBadRequest,
+		},
+		"max prefix length too short": {
+			in:   routingPolicy{Rules: []*routingPolicyRule{{Seq: 10, Action: "permit", MatchPrefixes: []string{"10.0.0.0/16"}, MaxPrefixLen: 8}}},
+			code: http.StatusBadRequest,
+		},
+		"invalid community": {
+			in:   routingPolicy{Rules: []*routingPolicyRule{{Seq: 10, Action: "permit", SetCommunities: []string{"65000"}}}},
+			code: http.StatusBadRequest,
+		},
+		"invalid direction": {
+			in:   routingPolicy{Rules: testPolicyRules, Attachments: []*routingPolicyAttachment{{Target: testVrfA, Direction: "both"}}},
+			code: http.StatusBadRequest,
+		},
+		"unknown vrf": {
+			in:   routingPolicy{Rules: testPolicyRules, Attachments: []*routingPolicyAttachment{{Target: fullName("vrfs", "unknown"), Direction: "import"}}},
+			code: http.StatusNotFound,
+		},
+		"unknown svi": {
+			in:   routingPolicy{Rules: testPolicyRules, Attachments: []*routingPolicyAttachment{{Target: fullName("svis", "unknown"), Direction: "import"}}},
+			code: http.StatusNotFound,
+		},
+		"svi without bgp": {
+			existing: createTestSvi,
+			in:       routingPolicy{Rules: testPolicyRules, Attachments: []*routingPolicyAttachment{{Target: fullName("svis", "web"), Direction: "import"}}},
+			code:     http.StatusBadRequest,
+		},
+		"attached by another policy": {
+			existing: func(t *testing.T) {
+				spec, _ := routingPolicySpecFromJSON(&routingPolicy{Rules: testPolicyRules,
+					Attachments: []*routingPolicyAttachment{{Target: testVrfA, Direction: "export"}}})
+				rp, err := infradb.NewRoutingPolicy(fullName("routingpolicies", "other"), spec)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := infradb.CreateRoutingPolicy(rp); err != nil {
+					t.Fatal(err)
+				}
+			},
+			in:   routingPolicy{Rules: testPolicyRules, Attachments: []*routingPolicyAttachment{{Target: testVrfA, Direction: "export"}}},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			if tt.existing != nil {
+				tt.existing(t)
+			}
+
+			rec := putRoutingPolicy(t, mux, http.MethodPost, "/v1/admin/routingpolicies?id=opi-rp", &tt.in)
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &routingPolicy{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("routingpolicies", "opi-rp") || out.OperStatus != "DOWN" || len(out.Rules) != 2 ||
+				out.Rules[0].MatchPrefixes[0] != "10.0.0.0/8" {
+				t.Errorf("unexpected routing policy %+v", out)
+			}
+		})
+	}
+}
+
+func Test_UpdateRoutingPolicy(t *testing.T) {
+	mux := newTestMux(t)
+	url := "/v1/admin/routingpolicies/opi-rp"
+	in := &routingPolicy{Rules: testPolicyRules, Attachments: []*routingPolicyAttachment{{Target: testVrfA, Direction: "export"}}}
+	if rec := putRoutingPolicy(t, mux, http.MethodPut, url, in); rec.Code != http.StatusNotFound {
+		t.Errorf("expected the update of an unknown policy to fail, received %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := putRoutingPolicy(t, mux, http.MethodPost, "/v1/admin/routingpolicies?id=opi-rp", in); rec.Code != http.StatusOK {
+		t.Fatalf("failed to create the policy: %d %s", rec.Code, rec.Body.String())
+	}
+
+	in.Rules = []*routingPolicyRule{{Seq: 5, Action: "deny", MatchCommunities: []string{"no-export"}}}
+	in.Attachments = append(in.Attachments, &routingPolicyAttachment{Target: testVrfB, Direction: "import"})
+	rec := putRoutingPolicy(t, mux, http.MethodPut, url, in)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the update to succeed, received %d: %s", rec.Code, rec.Body.String())
+	}
+	rp, err := infradb.GetRoutingPolicy(fullName("routingpolicies", "opi-rp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rp.Spec.Rules) != 1 || rp.Spec.Rules[0].Seq != 5 || len(rp.Spec.Attachments) != 2 {
+		t.Errorf("unexpected spec after the update %+v", rp.Spec)
+	}
+
+	// the vrfs of the policy cannot go away while it is attached to them
+	if err := infradb.DeleteVrf(testVrfB); err != infradb.ErrVrfNotEmpty {
+		t.Errorf("expected %v, received %v", infradb.ErrVrfNotEmpty, err)
+	}
+	in.Attachments = in.Attachments[:1]
+	if rec := putRoutingPolicy(t, mux, http.MethodPut, url, in); rec.Code != http.StatusOK {
+		t.Fatalf("expected the detach to succeed, received %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := infradb.DeleteVrf(testVrfB); err != nil {
+		t.Errorf("expected the detached vrf to be deleted, received %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/schedule"
+)
+
+// scheduledChange is the json representation of a scheduled change, without its bundle
+type scheduledChange struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	ExecuteAt time.Time `json:"execute_at"`
+	// RevertAfter is a Go duration, e.g. 30m
+	RevertAfter string     `json:"revert_after,omitempty"`
+	Prune       bool       `json:"prune,omitempty"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+	RevertAt    *time.Time `json:"revert_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// scheduledChangeToJSON translates the scheduled change to its json representation
+func scheduledChangeToJSON(c *infradb.ScheduledChange) *scheduledChange {
+	out := &scheduledChange{
+		Name:      c.Name,
+		State:     string(c.State),
+		ExecuteAt: c.ExecuteAt,
+		Prune:     c.Prune,
+		AppliedAt: timeToJSON(c.AppliedAt),
+		RevertAt:  timeToJSON(c.RevertAt),
+		Error:     c.Error,
+	}
+	if c.RevertAfter != 0 {
+		out.RevertAfter = c.RevertAfter.String()
+	}
+	return out
+}
+
+// parseScheduleQuery reads the time, the revert window and the prune of a scheduled change
+func parseScheduleQuery(r *http.Request, c *infradb.ScheduledChange) error {
+	query := r.URL.Query()
+	if value := query.Get("execute_at"); value != "" {
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid execute_at %q: %v", value, err)
+		}
+		c.ExecuteAt = at
+	}
+	if value := query.Get("revert_after"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return status.Errorf(codes.InvalidArgument, "invalid revert_after %q", value)
+		}
+		c.RevertAfter = d
+	}
+	c.Prune = query.Get("prune") == "true"
+	return nil
+}
+
+// createScheduledChange schedules the YAML or JSON bundle of the body, as accepted by the apply endpoint
+func createScheduledChange(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	// see https://google.aip.dev/133#user-specified-ids
+	c := &infradb.ScheduledChange{Name: resourceid.NewSystemGenerated()}
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		c.Name = id
+	}
+	if err := parseScheduleQuery(r, c); err != nil {
+		writeError(w, err)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxManifestSize))
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "failed to read the bundle: %v", err))
+		return
+	}
+	c.Bundle = data
+	c, err = schedule.Submit(c)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusCreated, scheduledChangeToJSON(c))
+}
+
+// listScheduledChanges returns the scheduled changes in the order of their time
+func listScheduledChanges(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	changes, err := infradb.GetAllScheduledChanges()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := make([]*scheduledChange, 0, len(changes))
+	for _, c := range changes {
+		out = append(out, scheduledChangeToJSON(c))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"schedules": out})
+}
+
+// getScheduledChange returns the state of a scheduled change
+func getScheduledChange(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	c, err := infradb.GetScheduledChange(params["schedule"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, scheduledChangeToJSON(c))
+}
+
+// confirmScheduledChange keeps the applied change instead of reverting it at the end of its window
+func confirmScheduledChange(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	c, err := schedule.Confirm(params["schedule"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, scheduledChangeToJSON(c))
+}
+
+// deleteScheduledChange cancels a pending change, or forgets a change which is over
+func deleteScheduledChange(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	if err := schedule.Cancel(params["schedule"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_CreateScheduledChange(t *testing.T) {
+	tests := map[string]struct {
+		query string
+		body  string
+		code  int
+	}{
+		"scheduled": {
+			query: "?id=night-change&execute_at=2030-01-02T03:00:00Z&revert_after=30m",
+			body:  testBundle,
+			code:  http.StatusCreated,
+		},
+		"invalid time": {
+			query: "?execute_at=tonight",
+			body:  testBundle,
+			code:  http.StatusBadRequest,
+		},
+		"invalid revert window": {
+			query: "?revert_after=-5m",
+			body:  testBundle,
+			code:  http.StatusBadRequest,
+		},
+		"invalid bundle": {
+			body: "kind: Subnet",
+			code: http.StatusBadRequest,
+		},
+		"invalid id": {
+			query: "?id=Night_Change",
+			body:  testBundle,
+			code:  http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/schedules"+tt.query, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Fatalf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusCreated {
+				return
+			}
+			out := &scheduledChange{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != "night-change" || out.State != "PENDING" || out.RevertAfter != "30m0s" {
+				t.Errorf("unexpected scheduled change %+v", out)
+			}
+
+			// a pending change cannot be confirmed, it can be canceled
+			for _, step := range []struct {
+				method string
+				url    string
+				code   int
+			}{
+				{http.MethodGet, "/v1/admin/schedules/night-change", http.StatusOK},
+				{http.MethodPost, "/v1/admin/schedules/night-change/confirm", http.StatusBadRequest},
+				{http.MethodDelete, "/v1/admin/schedules/night-change", http.StatusOK},
+				{http.MethodGet, "/v1/admin/schedules/night-change", http.StatusNotFound},
+			} {
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, httptest.NewRequest(step.method, step.url, nil))
+				if rec.Code != step.code {
+					t.Errorf("%s %s: expected code %d, received %d: %s", step.method, step.url, step.code, rec.Code, rec.Body.String())
+				}
+			}
+		})
+	}
+}
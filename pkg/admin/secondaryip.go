@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// secondaryIPs is the json representation of the secondary addresses of an svi, a virtual IP being a /32 or a /128
+type secondaryIPs struct {
+	Addresses []string `json:"addresses"`
+}
+
+// toSecondaryIPs converts the secondary addresses to json
+func toSecondaryIPs(in []*net.IPNet) *secondaryIPs {
+	out := &secondaryIPs{Addresses: make([]string, 0, len(in))}
+	for _, ip := range in {
+		out.Addresses = append(out.Addresses, ip.String())
+	}
+	return out
+}
+
+// getSviSecondaryIPs returns the secondary addresses of an svi
+func getSviSecondaryIPs(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	svi, err := infradb.GetSvi(fullName("svis", params["svi"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, toSecondaryIPs(svi.Spec.SecondaryIPs))
+}
+
+// setSviSecondaryIPs replaces the secondary addresses of an svi, the addresses are given with their prefix length
+func setSviSecondaryIPs(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &secondaryIPs{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(in.Addresses) == 0 {
+		writeError(w, status.Errorf(codes.InvalidArgument, "addresses are required"))
+		return
+	}
+	ips := make([]*net.IPNet, 0, len(in.Addresses))
+	for _, value := range in.Addresses {
+		ip, prefix, err := net.ParseCIDR(value)
+		if err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid address %s", value))
+			return
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		ips = append(ips, &net.IPNet{IP: ip, Mask: prefix.Mask})
+	}
+	svi, err := infradb.SetSviSecondaryIPs(fullName("svis", params["svi"]), ips, ifMatch(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, toSecondaryIPs(svi.Spec.SecondaryIPs))
+}
+
+// deleteSviSecondaryIPs removes the secondary addresses of an svi
+func deleteSviSecondaryIPs(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if _, err := infradb.SetSviSecondaryIPs(fullName("svis", params["svi"]), nil, ifMatch(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/pbconv"
+)
+
+// createOtherTestSvi creates the svi "db" of testVrfA with the gateway 10.0.1.1/24
+func createOtherTestSvi(t *testing.T) {
+	if err := createTestBridge("db", 20, nil); err != nil {
+		t.Fatal(err)
+	}
+	svi, err := pbconv.SviFromPb(&pb.Svi{Name: fullName("svis", "db"), Spec: &pb.SviSpec{
+		Vrf:           testVrfA,
+		LogicalBridge: fullName("bridges", "db"),
+		MacAddress:    []byte{0xaa, 0xbb, 0xcc, 0, 0, 2},
+		GwIpPrefix: []*pc.IPPrefix{{
+			Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 0x0a000101}},
+			Len:  24,
+		}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateSvi(svi); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_SetSviSecondaryIPs(t *testing.T) {
+	tests := map[string]struct {
+		svi       string
+		addresses []string
+		code      int
+	}{
+		"legacy gateway and virtual ip": {
+			svi:       "web",
+			addresses: []string{"10.0.0.6/29", "192.0.2.10/32"},
+			code:      http.StatusOK,
+		},
+		"legacy subnet of its own": {
+			svi:       "web",
+			addresses: []string{"172.16.0.1/24"},
+			code:      http.StatusOK,
+		},
+		"gateway address": {
+			svi:       "web",
+			addresses: []string{"10.0.0.1/32"},
+			code:      http.StatusBadRequest,
+		},
+		"network address": {
+			svi:       "web",
+			addresses: []string{"172.16.0.0/24"},
+			code:      http.StatusBadRequest,
+		},
+		"overlapping subnet of the svi": {
+			svi:       "web",
+			addresses: []string{"10.0.0.9/28"},
+			code:      http.StatusBadRequest,
+		},
+		"virtual ip in the subnet of another svi": {
+			svi:       "web",
+			addresses: []string{"10.0.1.5/32"},
+			code:      http.StatusBadRequest,
+		},
+		"invalid address": {
+			svi:       "web",
+			addresses: []string{"10.0.0.6"},
+			code:      http.StatusBadRequest,
+		},
+		"unknown svi": {
+			svi:       "unknown",
+			addresses: []string{"192.0.2.10/32"},
+			code:      http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			createTestSvi(t)
+			createOtherTestSvi(t)
+
+			body, _ := json.Marshal(&secondaryIPs{Addresses: tt.addresses})
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/svis/"+tt.svi+"/secondaryips", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			// the secondary addresses outlive an update of the svi through the opi-api and are reserved
+			svi, err := infradb.GetSvi(fullName("svis", tt.svi))
+			if err != nil {
+				t.Fatal(err)
+			}
+			svi.Spec.SecondaryIPs = nil
+			if err := infradb.UpdateSvi(svi); err != nil {
+				t.Fatal(err)
+			}
+			svi, err = infradb.GetSvi(fullName("svis", tt.svi))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(svi.Spec.SecondaryIPs) != len(tt.addresses) || len(svi.Spec.Addresses()) != len(tt.addresses)+1 {
+				t.Errorf("expected the addresses %v, received %v", tt.addresses, svi.Spec.Addresses())
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// sflowSampling is the json representation of the sFlow sampling of a bridge port
+type sflowSampling struct {
+	SamplingRate uint32 `json:"sampling_rate"`
+	HeaderLength uint32 `json:"header_length,omitempty"`
+	Collector    string `json:"collector"`
+}
+
+// getBridgePortSflow returns the sFlow sampling of a bridge port
+func getBridgePortSflow(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	name := fullName("ports", params["bridgeport"])
+	bp, err := infradb.GetBP(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if bp.Spec.Sflow == nil {
+		writeError(w, status.Errorf(codes.NotFound, "bridge port %s is not sampled", name))
+		return
+	}
+	writeResponse(w, http.StatusOK, &sflowSampling{
+		SamplingRate: bp.Spec.Sflow.SamplingRate,
+		HeaderLength: bp.Spec.Sflow.HeaderLength,
+		Collector:    bp.Spec.Sflow.Collector,
+	})
+}
+
+// setBridgePortSflow samples the ingress of a bridge port into the sFlow agent
+func setBridgePortSflow(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &sflowSampling{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	spec, err := infradb.NewSflowSpec(in.SamplingRate, in.HeaderLength, in.Collector)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	bp, err := infradb.SetBridgePortSflow(fullName("ports", params["bridgeport"]), spec, ifMatch(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, &sflowSampling{
+		SamplingRate: bp.Spec.Sflow.SamplingRate,
+		HeaderLength: bp.Spec.Sflow.HeaderLength,
+		Collector:    bp.Spec.Sflow.Collector,
+	})
+}
+
+// deleteBridgePortSflow stops the sampling of a bridge port
+func deleteBridgePortSflow(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if _, err := infradb.SetBridgePortSflow(fullName("ports", params["bridgeport"]), nil, ifMatch(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_SetBridgePortSflow(t *testing.T) {
+	tests := map[string]struct {
+		port string
+		in   sflowSampling
+		code int
+	}{
+		"valid request": {
+			port: "eth2",
+			in:   sflowSampling{SamplingRate: 1000, Collector: "192.0.2.10:6343"},
+			code: http.StatusOK,
+		},
+		"zero sampling rate": {
+			port: "eth2",
+			in:   sflowSampling{Collector: "192.0.2.10:6343"},
+			code: http.StatusBadRequest,
+		},
+		"header too long": {
+			port: "eth2",
+			in:   sflowSampling{SamplingRate: 1000, HeaderLength: 1500, Collector: "192.0.2.10:6343"},
+			code: http.StatusBadRequest,
+		},
+		"collector without port": {
+			port: "eth2",
+			in:   sflowSampling{SamplingRate: 1000, Collector: "192.0.2.10"},
+			code: http.StatusBadRequest,
+		},
+		"unknown bridge port": {
+			port: "unknown",
+			in:   sflowSampling{SamplingRate: 1000, Collector: "192.0.2.10:6343"},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			createTestBridgePort(t)
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/bridgeports/"+tt.port+"/sflow", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &sflowSampling{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.HeaderLength != 128 || out.SamplingRate != tt.in.SamplingRate {
+				t.Errorf("unexpected sampling %+v", out)
+			}
+		})
+	}
+}
+
+func Test_DeleteBridgePortSflow(t *testing.T) {
+	mux := newTestMux(t)
+	createTestBridgePort(t)
+	spec, err := infradb.NewSflowSpec(1000, 0, "192.0.2.10:6343")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bp, err := infradb.SetBridgePortSflow(testBridgePort, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an update through the opi-api has no sampling, the stored one is kept
+	bp.Spec.Sflow = nil
+	if err := infradb.UpdateBP(bp); err != nil {
+		t.Fatal(err)
+	}
+	if bp, err = infradb.GetBP(testBridgePort); err != nil || bp.Spec.Sflow == nil {
+		t.Fatalf("expected the sampling to be kept by the update: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/admin/bridgeports/eth2/sflow", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, received %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/admin/bridgeports/eth2/sflow", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected code %d, received %d: %s", http.StatusNotFound, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// srv6Backend carries the VPCs over SRv6
+type srv6Backend struct {
+	encapBackend
+}
+
+func (*srv6Backend) ConfigureSrv6(context.Context, routing.Srv6) error {
+	return nil
+}
+
+func Test_SetVrfSrv6Dataplane(t *testing.T) {
+	tests := map[string]struct {
+		backend routing.Backend
+		locator string
+		in      vrfDataplane
+		out     *vrfDataplane
+		code    int
+	}{
+		"srv6 with functions": {
+			backend: &srv6Backend{encapBackend{name: "test-srv6"}},
+			locator: "fc00:0:1::/48",
+			in:      vrfDataplane{Type: srv6Dataplane, Dt4Function: 0x10, Dt6Function: 0x11},
+			out: &vrfDataplane{Type: srv6Dataplane, Dt4Function: 0x10, Dt6Function: 0x11,
+				Sids: map[string]string{"End.DT4": "fc00:0:1:10::", "End.DT6": "fc00:0:1:11::"}},
+			code: http.StatusOK,
+		},
+		"srv6 with allocated functions": {
+			backend: &srv6Backend{encapBackend{name: "test-srv6"}},
+			locator: "fc00:0:1::/48",
+			in:      vrfDataplane{Type: srv6Dataplane},
+			out: &vrfDataplane{Type: srv6Dataplane, Dt4Function: 1, Dt6Function: 2,
+				Sids: map[string]string{"End.DT4": "fc00:0:1:1::", "End.DT6": "fc00:0:1:2::"}},
+			code: http.StatusOK,
+		},
+		"srv6 with a label": {
+			backend: &srv6Backend{encapBackend{name: "test-srv6"}},
+			locator: "fc00:0:1::/48",
+			in:      vrfDataplane{Type: srv6Dataplane, Label: 100},
+			code:    http.StatusBadRequest,
+		},
+		"srv6 disabled": {
+			backend: &srv6Backend{encapBackend{name: "test-srv6"}},
+			in:      vrfDataplane{Type: srv6Dataplane},
+			code:    http.StatusBadRequest,
+		},
+		"mpls only backend": {
+			backend: &mplsBackend{encapBackend{name: "test-mpls"}},
+			locator: "fc00:0:1::/48",
+			in:      vrfDataplane{Type: srv6Dataplane},
+			code:    http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			selectEncapBackend(t, tt.backend)
+			config.GlobalConfig.Srv6 = config.Srv6Config{Enabled: tt.locator != "", Locator: tt.locator}
+			t.Cleanup(func() { config.GlobalConfig.Srv6 = config.Srv6Config{} })
+			createTestMplsVrf(t)
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/vrfs/mpls/dataplane", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &vrfDataplane{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(out, tt.out) {
+				t.Errorf("expected %+v, received %+v", tt.out, out)
+			}
+		})
+	}
+}
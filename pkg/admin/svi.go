@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// sviGateway is the json representation of the gateway addresses of an svi, with the policy which picked them
+// when they were given by their network address
+type sviGateway struct {
+	Policy     string   `json:"policy,omitempty"`
+	Prefixes   []string `json:"prefixes"`
+	GatewayIPs []string `json:"gateway_ips"`
+}
+
+// getSviGateway returns the gateway addresses programmed on an svi
+func getSviGateway(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	svi, err := infradb.GetSvi(fullName("svis", params["svi"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := &sviGateway{Policy: svi.Spec.GatewayPolicy, Prefixes: []string{}, GatewayIPs: []string{}}
+	prefixes := svi.Spec.GatewayPrefixes
+	if prefixes == nil {
+		prefixes = svi.Spec.GatewayIPs
+	}
+	for _, prefix := range prefixes {
+		out.Prefixes = append(out.Prefixes, prefix.String())
+	}
+	for _, gw := range svi.Spec.GatewayIPs {
+		out.GatewayIPs = append(out.GatewayIPs, gw.String())
+	}
+	writeResponse(w, http.StatusOK, out)
+}
+
+// announceSvi triggers the emission of gratuitous ARPs / unsolicited NAs for the svi gateway IPs
+func announceSvi(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	if err := gen_linux.AnnounceSvi(fullName("svis", params["svi"])); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusAccepted, nil)
+}
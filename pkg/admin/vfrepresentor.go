@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net/http"
+	"sort"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// vfRepresentor is the json representation of a VF representor
+type vfRepresentor struct {
+	Name       string      `json:"name,omitempty"`
+	PF         string      `json:"pf"`
+	VF         int         `json:"vf"`
+	OperStatus string      `json:"oper_status,omitempty"`
+	Components []component `json:"components,omitempty"`
+	// Netdev is the kernel name of the representor, known once the VF representor is up
+	Netdev string `json:"netdev,omitempty"`
+}
+
+// vfRepresentorToJSON translates the domain object to its json representation
+func vfRepresentorToJSON(rep *infradb.VfRepresentor) *vfRepresentor {
+	return &vfRepresentor{
+		Name:       rep.Name,
+		PF:         rep.Spec.PF,
+		VF:         rep.Spec.VF,
+		OperStatus: rep.Status.OperStatus.String(),
+		Components: componentsToJSON(rep.Status.Components),
+	}
+}
+
+// createVfRepresentor creates a VF representor for a VF of a PF
+func createVfRepresentor(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &vfRepresentor{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("vfrepresentors", resourceID)
+	rep, err := infradb.NewVfRepresentor(name, &infradb.VfRepresentorSpec{PF: in.PF, VF: in.VF})
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetVfRepresentor(name); err == nil {
+		if !sameSpec(rep.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("vfrepresentors", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createVfRepresentor(): Already existing VF Representor with id %v", name)
+		writeResponse(w, http.StatusOK, vfRepresentorToJSON(existing))
+		return
+	}
+	// the offload of the VF takes entries of the hardware tables
+	if err := utils.CheckOffloadCapacity(r.Context(), dlink, config.GlobalConfig.Devlink.OccupancyThreshold); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := infradb.CreateVfRepresentor(rep); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, vfRepresentorToJSON(rep))
+}
+
+// getVfRepresentor returns a VF representor with its representor netdev once it is up
+func getVfRepresentor(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	rep, err := infradb.GetVfRepresentor(fullName("vfrepresentors", params["vfrepresentor"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := vfRepresentorToJSON(rep)
+	if rep.Status.OperStatus == infradb.OperStatusUp {
+		netdev, err := gen_linux.FindVfRepresentor(rep.Spec.PF, rep.Spec.VF)
+		if err != nil {
+			log.Printf("getVfRepresentor(): Failed to find the representor of %s: %v", rep.Name, err)
+		} else {
+			out.Netdev = netdev
+		}
+	}
+	writeResponse(w, http.StatusOK, out)
+}
+
+// listVfRepresentors returns all the VF representors
+func listVfRepresentors(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	reps, err := infradb.GetAllVfRepresentors()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(reps, func(i, j int) bool { return reps[i].Name < reps[j].Name })
+	out := []*vfRepresentor{}
+	for _, rep := range reps {
+		out = append(out, vfRepresentorToJSON(rep))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"vf_representors": out})
+}
+
+// deleteVfRepresentor deletes a VF representor
+func deleteVfRepresentor(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteVfRepresentor(fullName("vfrepresentors", params["vfrepresentor"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_CreateVfRepresentor(t *testing.T) {
+	tests := map[string]struct {
+		existing *vfRepresentor
+		in       vfRepresentor
+		code     int
+	}{
+		"first vf": {
+			in:   vfRepresentor{PF: "p0", VF: 0},
+			code: http.StatusOK,
+		},
+		"other vf of the same pf": {
+			existing: &vfRepresentor{PF: "p0", VF: 0},
+			in:       vfRepresentor{PF: "p0", VF: 3},
+			code:     http.StatusOK,
+		},
+		"no pf": {
+			in:   vfRepresentor{VF: 3},
+			code: http.StatusBadRequest,
+		},
+		"negative vf": {
+			in:   vfRepresentor{PF: "p0", VF: -1},
+			code: http.StatusBadRequest,
+		},
+		"vf with a representor": {
+			existing: &vfRepresentor{PF: "p0", VF: 3},
+			in:       vfRepresentor{PF: "p0", VF: 3},
+			code:     http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			if tt.existing != nil {
+				body, _ := json.Marshal(tt.existing)
+				req := httptest.NewRequest(http.MethodPost, "/v1/admin/vfrepresentors?id=vm0-vf", bytes.NewReader(body))
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("failed to create existing VF representor: %s", rec.Body.String())
+				}
+			}
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/vfrepresentors?id=vm1-vf", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &vfRepresentor{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("vfrepresentors", "vm1-vf") || out.PF != tt.in.PF || out.VF != tt.in.VF || out.OperStatus != "DOWN" {
+				t.Errorf("unexpected VF representor %+v", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net/http"
+	"sort"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// virtualPort is the json representation of a virtual port
+type virtualPort struct {
+	Name       string      `json:"name,omitempty"`
+	Type       string      `json:"type"`
+	SocketPath string      `json:"socket_path"`
+	Server     bool        `json:"server,omitempty"`
+	Queues     int         `json:"queues,omitempty"`
+	OperStatus string      `json:"oper_status,omitempty"`
+	Components []component `json:"components,omitempty"`
+}
+
+// virtualPortToJSON translates the domain object to its json representation
+func virtualPortToJSON(vport *infradb.VirtualPort) *virtualPort {
+	return &virtualPort{
+		Name:       vport.Name,
+		Type:       vport.Spec.Type,
+		SocketPath: vport.Spec.SocketPath,
+		Server:     vport.Spec.Server,
+		Queues:     vport.Spec.Queues,
+		OperStatus: vport.Status.OperStatus.String(),
+		Components: componentsToJSON(vport.Status.Components),
+	}
+}
+
+// createVirtualPort creates a vhost-user or virtio-user port in the userspace dataplane
+func createVirtualPort(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &virtualPort{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("virtualports", resourceID)
+	spec := &infradb.VirtualPortSpec{Type: in.Type, SocketPath: in.SocketPath, Server: in.Server, Queues: in.Queues}
+	vport, err := infradb.NewVirtualPort(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetVirtualPort(name); err == nil {
+		if !sameSpec(vport.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("virtualports", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createVirtualPort(): Already existing Virtual Port with id %v", name)
+		writeResponse(w, http.StatusOK, virtualPortToJSON(existing))
+		return
+	}
+	if err := infradb.CreateVirtualPort(vport); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, virtualPortToJSON(vport))
+}
+
+// getVirtualPort returns a virtual port
+func getVirtualPort(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	vport, err := infradb.GetVirtualPort(fullName("virtualports", params["virtualport"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, virtualPortToJSON(vport))
+}
+
+// listVirtualPorts returns all the virtual ports
+func listVirtualPorts(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	vports, err := infradb.GetAllVirtualPorts()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(vports, func(i, j int) bool { return vports[i].Name < vports[j].Name })
+	out := []*virtualPort{}
+	for _, vport := range vports {
+		out = append(out, virtualPortToJSON(vport))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"virtual_ports": out})
+}
+
+// deleteVirtualPort deletes a virtual port
+func deleteVirtualPort(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteVirtualPort(fullName("virtualports", params["virtualport"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/pbconv"
+)
+
+func Test_CreateVirtualPort(t *testing.T) {
+	tests := map[string]struct {
+		existing *virtualPort
+		in       virtualPort
+		code     int
+		queues   int
+	}{
+		"vhost-user server": {
+			in:     virtualPort{Type: "vhost-user", SocketPath: "/var/run/vhost/vm1-eth0.sock", Server: true, Queues: 2},
+			code:   http.StatusOK,
+			queues: 2,
+		},
+		"virtio-user defaults to one queue": {
+			in:     virtualPort{Type: "virtio-user", SocketPath: "/var/run/vhost/exception.sock"},
+			code:   http.StatusOK,
+			queues: 1,
+		},
+		"unknown type": {
+			in:   virtualPort{Type: "af-xdp", SocketPath: "/var/run/vhost/vm1-eth0.sock"},
+			code: http.StatusBadRequest,
+		},
+		"relative socket path": {
+			in:   virtualPort{Type: "vhost-user", SocketPath: "vm1-eth0.sock"},
+			code: http.StatusBadRequest,
+		},
+		"too many queues": {
+			in:   virtualPort{Type: "vhost-user", SocketPath: "/var/run/vhost/vm1-eth0.sock", Queues: 1024},
+			code: http.StatusBadRequest,
+		},
+		"socket of another virtual port": {
+			existing: &virtualPort{Type: "vhost-user", SocketPath: "/var/run/vhost/vm1-eth0.sock"},
+			in:       virtualPort{Type: "vhost-user", SocketPath: "/var/run/vhost/vm1-eth0.sock"},
+			code:     http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			if tt.existing != nil {
+				body, _ := json.Marshal(tt.existing)
+				req := httptest.NewRequest(http.MethodPost, "/v1/admin/virtualports?id=vm0-eth0", bytes.NewReader(body))
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("failed to create existing virtual port: %s", rec.Body.String())
+				}
+			}
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/virtualports?id=vm1-eth0", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &virtualPort{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("virtualports", "vm1-eth0") || out.Queues != tt.queues || out.OperStatus != "DOWN" {
+				t.Errorf("unexpected virtual port %+v", out)
+			}
+		})
+	}
+}
+
+func Test_DeleteVirtualPortInUse(t *testing.T) {
+	mux := newTestMux(t)
+	body, _ := json.Marshal(virtualPort{Type: "vhost-user", SocketPath: "/var/run/vhost/vm1-eth0.sock"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/virtualports?id=vm1-eth0", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("failed to create the virtual port: %s", rec.Body.String())
+	}
+	if err := createTestBridge("vport", 30, nil); err != nil {
+		t.Fatal(err)
+	}
+	bp, err := pbconv.BridgePortFromPb(&pb.BridgePort{Name: fullName("ports", "vm1-eth0"), Spec: &pb.BridgePortSpec{
+		Ptype:          pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS,
+		MacAddress:     []byte{0xaa, 0xbb, 0xcc, 0, 0, 1},
+		LogicalBridges: []string{fullName("bridges", "vport")},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateBP(bp); err != nil {
+		t.Fatal(err)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/v1/admin/virtualports/vm1-eth0", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected the virtual port in use to fail with %d, received %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"sort"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// vpcPeering is the json representation of a vpc peering
+type vpcPeering struct {
+	Name string `json:"name,omitempty"`
+	VrfA string `json:"vrf_a"`
+	VrfB string `json:"vrf_b"`
+	// PrefixesA are the prefixes of vrf_a reachable from vrf_b, all of them when empty
+	PrefixesA []string `json:"prefixes_a,omitempty"`
+	// PrefixesB are the prefixes of vrf_b reachable from vrf_a, all of them when empty
+	PrefixesB  []string    `json:"prefixes_b,omitempty"`
+	OperStatus string      `json:"oper_status,omitempty"`
+	Components []component `json:"components,omitempty"`
+}
+
+// prefixesToJSON translates the prefixes to their json representation
+func prefixesToJSON(prefixes []*net.IPNet) []string {
+	var out []string
+	for _, prefix := range prefixes {
+		out = append(out, prefix.String())
+	}
+	return out
+}
+
+// parsePrefixes parses the prefixes of the json representation
+func parsePrefixes(prefixes []string) ([]*net.IPNet, error) {
+	var out []*net.IPNet
+	for _, prefix := range prefixes {
+		_, ipnet, err := net.ParseCIDR(prefix)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid prefix %s: %v", prefix, err)
+		}
+		out = append(out, ipnet)
+	}
+	return out, nil
+}
+
+// vpcPeeringToJSON translates the domain object to its json representation
+func vpcPeeringToJSON(vp *infradb.VpcPeering) *vpcPeering {
+	return &vpcPeering{
+		Name:       vp.Name,
+		VrfA:       vp.Spec.VrfA,
+		VrfB:       vp.Spec.VrfB,
+		PrefixesA:  prefixesToJSON(vp.Spec.PrefixesA),
+		PrefixesB:  prefixesToJSON(vp.Spec.PrefixesB),
+		OperStatus: vp.Status.OperStatus.String(),
+		Components: componentsToJSON(vp.Status.Components),
+	}
+}
+
+// createVpcPeering connects two vrfs hosted on the node
+func createVpcPeering(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	in := &vpcPeering{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	// see https://google.aip.dev/133#user-specified-ids
+	resourceID := resourceid.NewSystemGenerated()
+	if id := r.URL.Query().Get("id"); id != "" {
+		if err := resourceid.ValidateUserSettable(id); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid id %s: %v", id, err))
+			return
+		}
+		resourceID = id
+	}
+	name := fullName("vpcpeerings", resourceID)
+	spec := &infradb.VpcPeeringSpec{VrfA: in.VrfA, VrfB: in.VrfB}
+	var err error
+	if spec.PrefixesA, err = parsePrefixes(in.PrefixesA); err != nil {
+		writeError(w, err)
+		return
+	}
+	if spec.PrefixesB, err = parsePrefixes(in.PrefixesB); err != nil {
+		writeError(w, err)
+		return
+	}
+	vp, err := infradb.NewVpcPeering(name, spec)
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+	// idempotent API when called with same key and spec, should return same object
+	if existing, err := infradb.GetVpcPeering(name); err == nil {
+		if !sameSpec(vp.Spec, existing.Spec) {
+			writeError(w, apierrors.AlreadyExists("vpcpeerings", name, "%s already exists with another spec", name))
+			return
+		}
+		log.Printf("createVpcPeering(): Already existing VPC Peering with id %v", name)
+		writeResponse(w, http.StatusOK, vpcPeeringToJSON(existing))
+		return
+	}
+	if err := infradb.CreateVpcPeering(vp); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, vpcPeeringToJSON(vp))
+}
+
+// getVpcPeering returns a vpc peering
+func getVpcPeering(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	vp, err := infradb.GetVpcPeering(fullName("vpcpeerings", params["vpcpeering"]))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, vpcPeeringToJSON(vp))
+}
+
+// listVpcPeerings returns all the vpc peerings
+func listVpcPeerings(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	vps, err := infradb.GetAllVpcPeerings()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sort.Slice(vps, func(i, j int) bool { return vps[i].Name < vps[j].Name })
+	out := []*vpcPeering{}
+	for _, vp := range vps {
+		out = append(out, vpcPeeringToJSON(vp))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"vpc_peerings": out})
+}
+
+// deleteVpcPeering deletes a vpc peering
+func deleteVpcPeering(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	err := infradb.DeleteVpcPeering(fullName("vpcpeerings", params["vpcpeering"]), ifMatch(r))
+	if err == infradb.ErrKeyNotFound && r.URL.Query().Get("allow_missing") == "true" {
+		err = nil
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_CreateVpcPeering(t *testing.T) {
+	tests := map[string]struct {
+		existing func(t *testing.T)
+		in       vpcPeering
+		code     int
+	}{
+		"valid request": {
+			in:   vpcPeering{VrfA: testVrfA, VrfB: testVrfB, PrefixesB: []string{"10.2.0.0/16"}},
+			code: http.StatusOK,
+		},
+		"same vrf": {
+			in:   vpcPeering{VrfA: testVrfA, VrfB: testVrfA},
+			code: http.StatusBadRequest,
+		},
+		"missing vrf": {
+			in:   vpcPeering{VrfA: testVrfA},
+			code: http.StatusBadRequest,
+		},
+		"grd": {
+			in:   vpcPeering{VrfA: testVrfA, VrfB: fullName("vrfs", "GRD")},
+			code: http.StatusBadRequest,
+		},
+		"invalid prefix": {
+			in:   vpcPeering{VrfA: testVrfA, VrfB: testVrfB, PrefixesA: []string{"10.0.0.300/24"}},
+			code: http.StatusBadRequest,
+		},
+		"unknown vrf": {
+			in:   vpcPeering{VrfA: testVrfA, VrfB: fullName("vrfs", "unknown")},
+			code: http.StatusNotFound,
+		},
+		"already peered": {
+			existing: func(t *testing.T) {
+				vp, err := infradb.NewVpcPeering(fullName("vpcpeerings", "red-to-blue"), &infradb.VpcPeeringSpec{VrfA: testVrfB, VrfB: testVrfA})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := infradb.CreateVpcPeering(vp); err != nil {
+					t.Fatal(err)
+				}
+			},
+			in:   vpcPeering{VrfA: testVrfA, VrfB: testVrfB},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			if tt.existing != nil {
+				tt.existing(t)
+			}
+
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/vpcpeerings?id=blue-to-red", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("expected code %d, received %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			out := &vpcPeering{}
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Name != fullName("vpcpeerings", "blue-to-red") || out.OperStatus != "DOWN" || len(out.PrefixesB) != 1 {
+				t.Errorf("unexpected vpc peering %+v", out)
+			}
+		})
+	}
+}
+
+func Test_DeletePeeredVrf(t *testing.T) {
+	mux := newTestMux(t)
+	body, _ := json.Marshal(vpcPeering{VrfA: testVrfA, VrfB: testVrfB})
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/vpcpeerings?id=blue-to-red", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("failed to create the peering: %d %s", rec.Code, rec.Body.String())
+	}
+	if err := infradb.DeleteVrf(testVrfB); err != infradb.ErrVrfNotEmpty {
+		t.Errorf("expected %v, received %v", infradb.ErrVrfNotEmpty, err)
+	}
+}
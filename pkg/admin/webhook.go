@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// webhook is the json representation of a webhook, its secret is never returned
+type webhook struct {
+	Name      string                    `json:"name,omitempty"`
+	URL       string                    `json:"url"`
+	Secret    string                    `json:"secret,omitempty"`
+	HasSecret bool                      `json:"hasSecret,omitempty"`
+	Types     []infradb.StatusEventType `json:"types,omitempty"`
+	Kinds     []string                  `json:"kinds,omitempty"`
+}
+
+func webhookToJSON(w *infradb.Webhook) *webhook {
+	return &webhook{Name: w.Name, URL: w.URL, HasSecret: w.Secret != "", Types: w.Types, Kinds: w.Kinds}
+}
+
+// listWebhooks returns the registered webhooks in the order of their names
+func listWebhooks(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	webhooks, err := infradb.GetAllWebhooks()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := make([]*webhook, 0, len(webhooks))
+	for _, wh := range webhooks {
+		out = append(out, webhookToJSON(wh))
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{"webhooks": out})
+}
+
+// getWebhook returns a webhook
+func getWebhook(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	wh, err := infradb.GetWebhook(params["webhook"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, webhookToJSON(wh))
+}
+
+// setWebhook registers a webhook, or replaces the webhook of the same name
+func setWebhook(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	in := &webhook{}
+	if err := readRequest(r, in); err != nil {
+		writeError(w, err)
+		return
+	}
+	wh := &infradb.Webhook{Name: params["webhook"], URL: in.URL, Secret: in.Secret, Types: in.Types, Kinds: in.Kinds}
+	if err := infradb.SetWebhook(wh); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, webhookToJSON(wh))
+}
+
+// deleteWebhook unregisters a webhook
+func deleteWebhook(w http.ResponseWriter, _ *http.Request, params map[string]string) {
+	if err := infradb.DeleteWebhook(params["webhook"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_SetWebhook(t *testing.T) {
+	tests := map[string]struct {
+		in   webhook
+		code int
+	}{
+		"valid request": {
+			in:   webhook{URL: "https://orchestrator.example.com/events", Secret: "s3cr3t", Types: []infradb.StatusEventType{infradb.StatusEventFailed}},
+			code: http.StatusOK,
+		},
+		"not an http url": {
+			in:   webhook{URL: "ftp://orchestrator.example.com/events"},
+			code: http.StatusBadRequest,
+		},
+		"unknown event type": {
+			in:   webhook{URL: "https://orchestrator.example.com/events", Types: []infradb.StatusEventType{"programmed"}},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			mux := newTestMux(t)
+			body, _ := json.Marshal(tt.in)
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/webhooks/orchestrator", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Fatalf("expected code %d, got %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+			if strings.Contains(rec.Body.String(), "s3cr3t") {
+				t.Error("expected the secret not to be returned")
+			}
+		})
+	}
+}
+
+func Test_ListWebhooks(t *testing.T) {
+	mux := newTestMux(t)
+	for _, name := range []string{"b", "a"} {
+		if err := infradb.SetWebhook(&infradb.Webhook{Name: name, URL: "http://127.0.0.1/" + name, Secret: "s3cr3t"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/webhooks", http.NoBody))
+	out := struct{ Webhooks []webhook }{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Webhooks) != 2 || out.Webhooks[0].Name != "a" || !out.Webhooks[0].HasSecret || out.Webhooks[0].Secret != "" {
+		t.Errorf("unexpected webhooks %+v", out.Webhooks)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/v1/admin/webhooks/a", http.NoBody))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/webhooks/a", http.NoBody))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected code %d, got %d: %s", http.StatusNotFound, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package admin exposes the operational endpoints which are not covered by the opi-api services
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// writeQueue is the json representation of the dataplane write queue of a namespace
+type writeQueue struct {
+	Namespace   string     `json:"namespace"`
+	Running     string     `json:"running,omitempty"`
+	Pending     []string   `json:"pending"`
+	Enqueued    uint64     `json:"enqueued"`
+	Merged      uint64     `json:"merged"`
+	Executed    uint64     `json:"executed"`
+	Retried     uint64     `json:"retried"`
+	Failed      uint64     `json:"failed"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// listWriteQueues returns the operations waiting to change the dataplane of every namespace
+// and the counters of the operations issued so far
+func listWriteQueues(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	out := []writeQueue{}
+	for _, stats := range utils.GetWriteQueueStats() {
+		q := writeQueue{
+			Namespace: stats.Namespace,
+			Running:   stats.Running,
+			Pending:   stats.Pending,
+			Enqueued:  stats.Enqueued,
+			Merged:    stats.Merged,
+			Executed:  stats.Executed,
+			Retried:   stats.Retried,
+			Failed:    stats.Failed,
+			LastError: stats.LastError,
+		}
+		if !stats.LastErrorAt.IsZero() {
+			q.LastErrorAt = &stats.LastErrorAt
+		}
+		out = append(out, q)
+	}
+	writeResponse(w, http.StatusOK, out)
+}
@@ -0,0 +1,303 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package adopt imports the devices configured by hand in the kernel as resources of the bridge
+package adopt
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// Kinds of the resources a device is adopted as
+const (
+	// KindVrf adopts a vrf device, with the bridge and the vxlan device of its L3 VNI
+	KindVrf = "vrf"
+	// KindLogicalBridge adopts the vxlan device of a vlan, named vxlan-<vlan id>
+	KindLogicalBridge = "logical-bridge"
+	// KindSvi adopts a vlan device of the bridge enslaved to the device of an adopted or created vrf
+	KindSvi = "svi"
+)
+
+// Adoption is a resource imported from the kernel with the devices it has taken over
+type Adoption struct {
+	Kind    string
+	Name    string
+	Devices []string
+}
+
+// Adopt imports the kernel device as a resource with its current parameters. The resource is created as
+// usual, but the modules keep the devices in place instead of creating them, so the traffic is not
+// disrupted. The devices created by the bridge for a resource are refused.
+func Adopt(ctx context.Context, kind, device, name string) (*Adoption, error) {
+	dev, err := getDevice(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	if dev.Owner != "" {
+		return nil, apierrors.FailedPrecondition(apierrors.ReasonInUse, device, "%s has been created for %s", device, dev.Owner)
+	}
+	if owner, ok := infradb.GetLinkOwner(device); ok {
+		return nil, apierrors.FailedPrecondition(apierrors.ReasonInUse, device, "%s is the %s of %s", device, owner.Role, owner.Object)
+	}
+	switch kind {
+	case KindVrf:
+		return adoptVrf(ctx, dev, name)
+	case KindLogicalBridge:
+		return adoptLogicalBridge(dev, name)
+	case KindSvi:
+		return adoptSvi(dev, name)
+	}
+	return nil, apierrors.InvalidField("kind", apierrors.ReasonInvalidArgument, "unknown kind %q, expected %s, %s or %s", kind, KindVrf, KindLogicalBridge, KindSvi)
+}
+
+// getDevice reads the kernel device, a missing one is not found
+func getDevice(ctx context.Context, name string) (*gen_linux.KernelDevice, error) {
+	dev, err := gen_linux.GetKernelDevice(ctx, name)
+	if errors.Is(err, gen_linux.ErrNoSuchDevice) {
+		return nil, apierrors.NotFound("netdevs", name)
+	}
+	return dev, err
+}
+
+// expectType refuses a device of another type than the one the kind is adopted from
+func expectType(dev *gen_linux.KernelDevice, kind, devType string) error {
+	if dev.Type != devType {
+		return apierrors.FailedPrecondition(apierrors.ReasonFailedPrecondition, dev.Name,
+			"%s is a %s device, a %s is adopted from a %s device", dev.Name, dev.Type, kind, devType)
+	}
+	return nil
+}
+
+// hostPrefix returns the address as a host prefix, the VTEP IPs are kept so
+func hostPrefix(ip net.IP) *net.IPNet {
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// vrfSpec returns the spec of the vrf device with the vxlan device of its L3 VNI, nil for a vrf without L3 VNI
+func vrfSpec(vrf, vxlan *gen_linux.KernelDevice) (*infradb.VrfSpec, error) {
+	if len(vrf.Addresses) > 1 {
+		return nil, apierrors.FailedPrecondition(apierrors.ReasonFailedPrecondition, vrf.Name,
+			"%s has %d addresses, a vrf has a single loopback", vrf.Name, len(vrf.Addresses))
+	}
+	spec := &infradb.VrfSpec{}
+	if len(vrf.Addresses) == 1 {
+		spec.LoopbackIP = vrf.Addresses[0]
+	}
+	if vxlan != nil {
+		if vxlan.Vni == 0 || vxlan.VtepIP == nil {
+			return nil, apierrors.FailedPrecondition(apierrors.ReasonFailedPrecondition, vxlan.Name,
+				"%s has no VNI or no local address", vxlan.Name)
+		}
+		vni := vxlan.Vni
+		spec.Vni = &vni
+		spec.VtepIP = hostPrefix(vxlan.VtepIP)
+	}
+	return spec, nil
+}
+
+// l3Devices returns the bridge enslaved to the vrf device and the vxlan device enslaved to that bridge,
+// nil when the vrf has no L3 VNI
+func l3Devices(ctx context.Context, vrf *gen_linux.KernelDevice) (*gen_linux.KernelDevice, *gen_linux.KernelDevice, error) {
+	for _, slave := range vrf.Slaves {
+		bridge, err := getDevice(ctx, slave)
+		if err != nil {
+			return nil, nil, err
+		}
+		if bridge.Type != "bridge" {
+			continue
+		}
+		for _, port := range bridge.Slaves {
+			vxlan, err := getDevice(ctx, port)
+			if err != nil {
+				return nil, nil, err
+			}
+			if vxlan.Type == "vxlan" {
+				return bridge, vxlan, nil
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+// adoptVrf imports the vrf device with its routing table, the vrf keeps the names of its devices
+func adoptVrf(ctx context.Context, dev *gen_linux.KernelDevice, name string) (*Adoption, error) {
+	if err := expectType(dev, KindVrf, "vrf"); err != nil {
+		return nil, err
+	}
+	if path.Base(name) == "GRD" {
+		return nil, status.Error(codes.InvalidArgument, "the GRD is the default table of the kernel, it cannot be adopted")
+	}
+	bridge, vxlan, err := l3Devices(ctx, dev)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := vrfSpec(dev, vxlan)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]string{infradb.LinkRoleVrf: dev.Name}
+	if bridge != nil {
+		for _, d := range []*gen_linux.KernelDevice{bridge, vxlan} {
+			if d.Owner != "" {
+				return nil, apierrors.FailedPrecondition(apierrors.ReasonInUse, d.Name, "%s has been created for %s", d.Name, d.Owner)
+			}
+		}
+		names[infradb.LinkRoleBridge] = bridge.Name
+		names[infradb.LinkRoleVxlan] = vxlan.Name
+	}
+	if _, err := infradb.GetVrf(name); err == nil {
+		return nil, apierrors.AlreadyExists("vrfs", name, "%s already exists", name)
+	}
+	vrf, err := infradb.NewVrf(name, spec)
+	if err != nil {
+		return nil, err
+	}
+	// The routing table of the device lets the lgm module find the vrf in place
+	table := dev.Table
+	vrf.Metadata.RoutingTable = []*uint32{&table}
+	if err := infradb.ReserveIfNames(name, names); err != nil {
+		return nil, apierrors.FailedPrecondition(apierrors.ReasonInUse, dev.Name, "%v", err)
+	}
+	if err := infradb.CreateVrf(vrf); err != nil {
+		release(name)
+		return nil, err
+	}
+	log.Printf("adopt: %s adopted as %s with table %d\n", dev.Name, name, table)
+	adoption := &Adoption{Kind: KindVrf, Name: name, Devices: []string{dev.Name}}
+	if bridge != nil {
+		adoption.Devices = append(adoption.Devices, bridge.Name, vxlan.Name)
+	}
+	return adoption, nil
+}
+
+// vxlanVlanID returns the vlan of the vxlan device of a logical bridge from its name
+func vxlanVlanID(name string) (uint32, error) {
+	id, found := strings.CutPrefix(name, "vxlan-")
+	vid, err := strconv.ParseUint(id, 10, 16)
+	if !found || err != nil || vid == 0 || vid > 4094 {
+		return 0, apierrors.FailedPrecondition(apierrors.ReasonFailedPrecondition, name,
+			"%s is not named vxlan-<vlan id>, the name of the vxlan device of a logical bridge", name)
+	}
+	return uint32(vid), nil
+}
+
+// logicalBridgeSpec returns the spec of the vxlan device of the bridge of the vlan
+func logicalBridgeSpec(vxlan *gen_linux.KernelDevice, segmentBridge func(uint16) string) (*infradb.LogicalBridgeSpec, error) {
+	vid, err := vxlanVlanID(vxlan.Name)
+	if err != nil {
+		return nil, err
+	}
+	if bridge := segmentBridge(uint16(vid)); vxlan.Master != bridge {
+		return nil, apierrors.FailedPrecondition(apierrors.ReasonFailedPrecondition, vxlan.Name,
+			"%s is not a port of %s, the bridge of the vlan %d", vxlan.Name, bridge, vid)
+	}
+	if vxlan.Vni == 0 || vxlan.VtepIP == nil {
+		return nil, apierrors.FailedPrecondition(apierrors.ReasonFailedPrecondition, vxlan.Name,
+			"%s has no VNI or no local address", vxlan.Name)
+	}
+	vni := vxlan.Vni
+	return &infradb.LogicalBridgeSpec{VlanID: vid, Vni: &vni, VtepIP: hostPrefix(vxlan.VtepIP)}, nil
+}
+
+// adoptLogicalBridge imports the vxlan device of a vlan of the bridge
+func adoptLogicalBridge(dev *gen_linux.KernelDevice, name string) (*Adoption, error) {
+	if err := expectType(dev, KindLogicalBridge, "vxlan"); err != nil {
+		return nil, err
+	}
+	spec, err := logicalBridgeSpec(dev, gen_linux.SegmentBridge)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := infradb.GetLB(name); err == nil {
+		return nil, apierrors.AlreadyExists("logicalBridges", name, "%s already exists", name)
+	}
+	lb, err := infradb.NewLogicalBridge(name, spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := infradb.CreateLB(lb); err != nil {
+		return nil, err
+	}
+	log.Printf("adopt: %s adopted as %s\n", dev.Name, name)
+	return &Adoption{Kind: KindLogicalBridge, Name: name, Devices: []string{dev.Name}}, nil
+}
+
+// sviSpec returns the spec of the vlan device enslaved to the device of the vrf, on the logical bridge of its vlan
+func sviSpec(dev *gen_linux.KernelDevice, vrf string, lbs []*infradb.LogicalBridge) (*infradb.SviSpec, error) {
+	spec := &infradb.SviSpec{Vrf: vrf, GatewayIPs: dev.Addresses}
+	for _, lb := range lbs {
+		if lb.Spec.VlanID == dev.VlanID {
+			spec.LogicalBridge = lb.Name
+		}
+	}
+	if spec.LogicalBridge == "" {
+		return nil, apierrors.FailedPrecondition(apierrors.ReasonFailedPrecondition, dev.Name,
+			"no logical bridge has the vlan %d of %s, it is adopted or created first", dev.VlanID, dev.Name)
+	}
+	if len(dev.Mac) == 0 {
+		return nil, apierrors.FailedPrecondition(apierrors.ReasonFailedPrecondition, dev.Name, "%s has no MAC address", dev.Name)
+	}
+	mac := dev.Mac
+	spec.MacAddress = &mac
+	return spec, nil
+}
+
+// adoptSvi imports the vlan device with its MAC and gateway addresses, it keeps its name
+func adoptSvi(dev *gen_linux.KernelDevice, name string) (*Adoption, error) {
+	if err := expectType(dev, KindSvi, "vlan"); err != nil {
+		return nil, err
+	}
+	owner, ok := infradb.GetLinkOwner(dev.Master)
+	if dev.Master == "" || !ok || owner.Role != infradb.LinkRoleVrf {
+		return nil, apierrors.FailedPrecondition(apierrors.ReasonFailedPrecondition, dev.Name,
+			"%s is not enslaved to the device of a vrf, the vrf is adopted or created first", dev.Name)
+	}
+	lbs, err := infradb.GetAllLBs()
+	if err != nil && err != infradb.ErrKeyNotFound {
+		return nil, err
+	}
+	spec, err := sviSpec(dev, owner.Object, lbs)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := infradb.GetSvi(name); err == nil {
+		return nil, apierrors.AlreadyExists("svis", name, "%s already exists", name)
+	}
+	svi, err := infradb.NewSvi(name, spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := infradb.ReserveIfNames(name, map[string]string{infradb.LinkRoleSvi: dev.Name}); err != nil {
+		return nil, apierrors.FailedPrecondition(apierrors.ReasonInUse, dev.Name, "%v", err)
+	}
+	if err := infradb.CreateSvi(svi); err != nil {
+		release(name)
+		return nil, err
+	}
+	log.Printf("adopt: %s adopted as %s\n", dev.Name, name)
+	return &Adoption{Kind: KindSvi, Name: name, Devices: []string{dev.Name}}, nil
+}
+
+// release forgets the names reserved for a resource which has not been created
+func release(name string) {
+	if err := infradb.ReleaseIfNames(name); err != nil {
+		log.Printf("adopt: failed to release the names of %s: %v\n", name, err)
+	}
+}
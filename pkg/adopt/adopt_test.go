@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package adopt imports the devices configured by hand in the kernel as resources of the bridge
+package adopt
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func mustCIDR(t *testing.T, s string) *net.IPNet {
+	t.Helper()
+	ip, ipnet, err := net.ParseCIDR(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ipnet.IP = ip
+	return ipnet
+}
+
+func Test_VrfSpec(t *testing.T) {
+	tests := map[string]struct {
+		addresses []string
+		vxlan     *gen_linux.KernelDevice
+		loopback  string
+		vni       uint32
+		vtep      string
+		fail      bool
+	}{
+		"with l3 vni": {
+			addresses: []string{"10.0.0.1/32"},
+			vxlan:     &gen_linux.KernelDevice{Name: "vxlan-blue", Type: "vxlan", Vni: 1000, VtepIP: net.ParseIP("192.0.2.1")},
+			loopback:  "10.0.0.1/32",
+			vni:       1000,
+			vtep:      "192.0.2.1/32",
+		},
+		"without l3 vni": {},
+		"several addresses": {
+			addresses: []string{"10.0.0.1/32", "10.0.0.2/32"},
+			fail:      true,
+		},
+		"vxlan without local address": {
+			vxlan: &gen_linux.KernelDevice{Name: "vxlan-blue", Type: "vxlan", Vni: 1000},
+			fail:  true,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			vrf := &gen_linux.KernelDevice{Name: "blue", Type: "vrf", Table: 1001}
+			for _, addr := range tt.addresses {
+				vrf.Addresses = append(vrf.Addresses, mustCIDR(t, addr))
+			}
+			spec, err := vrfSpec(vrf, tt.vxlan)
+			if (err != nil) != tt.fail {
+				t.Fatalf("expected failure %v, received %v", tt.fail, err)
+			}
+			if tt.fail {
+				return
+			}
+			if got := fmt.Sprint(spec.LoopbackIP); tt.loopback != "" && got != tt.loopback {
+				t.Errorf("expected the loopback %s, received %s", tt.loopback, got)
+			}
+			if (spec.Vni == nil) != (tt.vni == 0) || (spec.Vni != nil && *spec.Vni != tt.vni) {
+				t.Errorf("expected the vni %d, received %v", tt.vni, spec.Vni)
+			}
+			if got := fmt.Sprint(spec.VtepIP); tt.vtep != "" && got != tt.vtep {
+				t.Errorf("expected the vtep %s, received %s", tt.vtep, got)
+			}
+		})
+	}
+}
+
+func Test_LogicalBridgeSpec(t *testing.T) {
+	segmentBridge := func(uint16) string { return "br-tenant" }
+	tests := map[string]struct {
+		vxlan gen_linux.KernelDevice
+		vid   uint32
+		fail  bool
+	}{
+		"vxlan of a vlan": {
+			vxlan: gen_linux.KernelDevice{Name: "vxlan-10", Master: "br-tenant", Vni: 10, VtepIP: net.ParseIP("192.0.2.1")},
+			vid:   10,
+		},
+		"not named after its vlan": {
+			vxlan: gen_linux.KernelDevice{Name: "vx10", Master: "br-tenant", Vni: 10, VtepIP: net.ParseIP("192.0.2.1")},
+			fail:  true,
+		},
+		"vlan out of range": {
+			vxlan: gen_linux.KernelDevice{Name: "vxlan-4095", Master: "br-tenant", Vni: 10, VtepIP: net.ParseIP("192.0.2.1")},
+			fail:  true,
+		},
+		"not on the bridge": {
+			vxlan: gen_linux.KernelDevice{Name: "vxlan-10", Vni: 10, VtepIP: net.ParseIP("192.0.2.1")},
+			fail:  true,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			spec, err := logicalBridgeSpec(&tt.vxlan, segmentBridge)
+			if (err != nil) != tt.fail {
+				t.Fatalf("expected failure %v, received %v", tt.fail, err)
+			}
+			if tt.fail {
+				return
+			}
+			if spec.VlanID != tt.vid || *spec.Vni != tt.vxlan.Vni || spec.VtepIP.String() != "192.0.2.1/32" {
+				t.Errorf("unexpected spec %+v", spec)
+			}
+		})
+	}
+}
+
+func Test_SviSpec(t *testing.T) {
+	const vrf = "//network.opiproject.org/vrfs/blue"
+	mac, _ := net.ParseMAC("aa:bb:cc:00:00:01")
+	lbs := []*infradb.LogicalBridge{
+		{Name: "//network.opiproject.org/bridges/web", Spec: &infradb.LogicalBridgeSpec{VlanID: 10}},
+	}
+	tests := map[string]struct {
+		dev  gen_linux.KernelDevice
+		fail bool
+	}{
+		"vlan of a logical bridge": {
+			dev: gen_linux.KernelDevice{Name: "blue-10", VlanID: 10, Mac: mac, Addresses: []*net.IPNet{mustCIDR(t, "10.0.10.1/24")}},
+		},
+		"no logical bridge": {
+			dev:  gen_linux.KernelDevice{Name: "blue-20", VlanID: 20, Mac: mac},
+			fail: true,
+		},
+		"no mac address": {
+			dev:  gen_linux.KernelDevice{Name: "blue-10", VlanID: 10},
+			fail: true,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			spec, err := sviSpec(&tt.dev, vrf, lbs)
+			if (err != nil) != tt.fail {
+				t.Fatalf("expected failure %v, received %v", tt.fail, err)
+			}
+			if tt.fail {
+				return
+			}
+			if spec.Vrf != vrf || spec.LogicalBridge != lbs[0].Name || spec.MacAddress.String() != mac.String() {
+				t.Errorf("unexpected spec %+v", spec)
+			}
+			if len(spec.GatewayIPs) != 1 || spec.GatewayIPs[0].String() != "10.0.10.1/24" {
+				t.Errorf("expected the gateway 10.0.10.1/24, received %v", spec.GatewayIPs)
+			}
+		})
+	}
+}
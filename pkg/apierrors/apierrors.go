@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package apierrors builds the errors returned by the gRPC and admin handlers. Besides the status code
+// and the message, they carry the google.rpc details (ErrorInfo, BadRequest, ResourceInfo,
+// PreconditionFailure, RetryInfo) which let the clients tell the errors apart without parsing the message.
+package apierrors
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain is the ErrorInfo domain of the errors of the bridge
+const Domain = "network.opiproject.org"
+
+// Reasons of the ErrorInfo details, they are stable and meant to be matched by the clients
+const (
+	ReasonInvalidField       = "INVALID_FIELD"
+	ReasonOutOfRange         = "OUT_OF_RANGE"
+	ReasonInvalidName        = "INVALID_NAME"
+	ReasonInvalidAddress     = "INVALID_ADDRESS"
+	ReasonInvalidPrefix      = "INVALID_PREFIX"
+	ReasonPrefixOverlap      = "PREFIX_OVERLAP"
+	ReasonNotFound           = "RESOURCE_NOT_FOUND"
+	ReasonReferenceNotFound  = "REFERENCE_NOT_FOUND"
+	ReasonAlreadyExists      = "RESOURCE_ALREADY_EXISTS"
+	ReasonInUse              = "RESOURCE_IN_USE"
+	ReasonNotEmpty           = "RESOURCE_NOT_EMPTY"
+	ReasonExhausted          = "RESOURCE_EXHAUSTED"
+	ReasonNotConfigured      = "NOT_CONFIGURED"
+	ReasonInvalidArgument    = "INVALID_ARGUMENT"
+	ReasonFailedPrecondition = "FAILED_PRECONDITION"
+	ReasonInMaintenance      = "IN_MAINTENANCE"
+	ReasonReadOnly           = "READ_ONLY"
+	ReasonVersionMismatch    = "RESOURCE_VERSION_MISMATCH"
+)
+
+// withDetails returns the status error with the details, the status without them should they not marshal
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// errorInfo builds the ErrorInfo detail of the reason, the metadata is given as key value pairs
+func errorInfo(reason string, metadata ...string) *errdetails.ErrorInfo {
+	info := &errdetails.ErrorInfo{Reason: reason, Domain: Domain}
+	if len(metadata) > 1 {
+		info.Metadata = make(map[string]string, len(metadata)/2)
+		for i := 0; i+1 < len(metadata); i += 2 {
+			info.Metadata[metadata[i]] = metadata[i+1]
+		}
+	}
+	return info
+}
+
+// InvalidField returns an InvalidArgument error with a BadRequest violation of the field,
+// e.g. vrf.spec.vni, the field is also in the metadata of the ErrorInfo
+func InvalidField(field string, reason string, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return withDetails(status.New(codes.InvalidArgument, msg),
+		errorInfo(reason, "field", field),
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: msg}}})
+}
+
+// NotFound returns a NotFound error with the ResourceInfo of the missing resource
+func NotFound(resourceType string, name string) error {
+	return withDetails(status.Newf(codes.NotFound, "unable to find key %s", name),
+		errorInfo(ReasonNotFound, "resource_type", resourceType, "resource_name", name),
+		&errdetails.ResourceInfo{ResourceType: resourceType, ResourceName: name})
+}
+
+// AlreadyExists returns an AlreadyExists error with the ResourceInfo of the existing resource
+func AlreadyExists(resourceType string, name string, format string, args ...interface{}) error {
+	return withDetails(status.Newf(codes.AlreadyExists, format, args...),
+		errorInfo(ReasonAlreadyExists, "resource_type", resourceType, "resource_name", name),
+		&errdetails.ResourceInfo{ResourceType: resourceType, ResourceName: name})
+}
+
+// FailedPrecondition returns a FailedPrecondition error with the PreconditionFailure violated by the subject,
+// e.g. the name of the resource still in use
+func FailedPrecondition(reason string, subject string, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return withDetails(status.New(codes.FailedPrecondition, msg),
+		errorInfo(reason, "subject", subject),
+		&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{Type: reason, Subject: subject, Description: msg}}})
+}
+
+// Exhausted returns a ResourceExhausted error, with a RetryInfo when the client may retry after the delay
+func Exhausted(reason string, retryDelay time.Duration, format string, args ...interface{}) error {
+	details := []protoadapt.MessageV1{errorInfo(reason)}
+	if retryDelay > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryDelay)})
+	}
+	return withDetails(status.Newf(codes.ResourceExhausted, format, args...), details...)
+}
+
+// sentinel is the code and the reason of a registered error
+type sentinel struct {
+	err    error
+	code   codes.Code
+	reason string
+}
+
+var (
+	sentinelsMu sync.RWMutex
+	sentinels   []sentinel
+)
+
+// Register gives the code and the reason of an error created with errors.New, e.g. the errors of the store,
+// so that Convert returns it as a status error with an ErrorInfo
+func Register(err error, code codes.Code, reason string) error {
+	sentinelsMu.Lock()
+	defer sentinelsMu.Unlock()
+	sentinels = append(sentinels, sentinel{err: err, code: code, reason: reason})
+	return err
+}
+
+// Lookup returns the status of a status error or of a registered error, false for any other error
+func Lookup(err error) (*status.Status, bool) {
+	if err == nil {
+		return nil, false
+	}
+	if st, ok := status.FromError(err); ok {
+		return st, true
+	}
+	sentinelsMu.RLock()
+	defer sentinelsMu.RUnlock()
+	for _, s := range sentinels {
+		if errors.Is(err, s.err) {
+			st, _ := status.FromError(withDetails(status.New(s.code, err.Error()), errorInfo(s.reason)))
+			return st, true
+		}
+	}
+	return nil, false
+}
+
+// Convert returns the status of the error, Internal for the errors which are neither status nor registered errors
+func Convert(err error) *status.Status {
+	if st, ok := Lookup(err); ok {
+		return st
+	}
+	return status.New(codes.Internal, err.Error())
+}
+
+// Reason returns the reason of the ErrorInfo of the error, empty without ErrorInfo
+func Reason(err error) string {
+	if info := ErrorInfo(err); info != nil {
+		return info.Reason
+	}
+	return ""
+}
+
+// ErrorInfo returns the ErrorInfo detail of the error, nil without one
+func ErrorInfo(err error) *errdetails.ErrorInfo {
+	st, ok := Lookup(err)
+	if !ok {
+		return nil
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	return nil
+}
+
+// FieldViolations returns the field violations of the BadRequest detail of the error
+func FieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	st, ok := Lookup(err)
+	if !ok {
+		return nil
+	}
+	violations := []*errdetails.BadRequest_FieldViolation{}
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			violations = append(violations, br.FieldViolations...)
+		}
+	}
+	return violations
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package apierrors builds the errors returned by the gRPC and admin handlers
+package apierrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_InvalidField(t *testing.T) {
+	err := InvalidField("vrf.spec.vni", ReasonOutOfRange, "Vni value (%d) have to be between 0 and 16777215", 16777216)
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument || st.Message() != "Vni value (16777216) have to be between 0 and 16777215" {
+		t.Fatalf("unexpected status %v", st)
+	}
+	if reason := Reason(err); reason != ReasonOutOfRange {
+		t.Errorf("expected reason %s, received %q", ReasonOutOfRange, reason)
+	}
+	if info := ErrorInfo(err); info.Domain != Domain || info.Metadata["field"] != "vrf.spec.vni" {
+		t.Errorf("unexpected ErrorInfo %v", info)
+	}
+	violations := FieldViolations(err)
+	if len(violations) != 1 || violations[0].Field != "vrf.spec.vni" {
+		t.Errorf("unexpected field violations %v", violations)
+	}
+}
+
+func Test_NotFound(t *testing.T) {
+	err := NotFound("vrfs", "//network.opiproject.org/vrfs/blue")
+	st := status.Convert(err)
+	if st.Code() != codes.NotFound || st.Message() != "unable to find key //network.opiproject.org/vrfs/blue" {
+		t.Fatalf("unexpected status %v", st)
+	}
+	found := false
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ResourceInfo); ok {
+			found = info.ResourceType == "vrfs" && info.ResourceName == "//network.opiproject.org/vrfs/blue"
+		}
+	}
+	if !found {
+		t.Errorf("expected a ResourceInfo, received %v", st.Details())
+	}
+}
+
+func Test_Exhausted(t *testing.T) {
+	st := status.Convert(Exhausted(ReasonExhausted, 5*time.Second, "no VNI is left"))
+	found := false
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			found = info.RetryDelay.AsDuration() == 5*time.Second
+		}
+	}
+	if st.Code() != codes.ResourceExhausted || !found {
+		t.Errorf("expected a ResourceExhausted error with a RetryInfo, received %v %v", st, st.Details())
+	}
+}
+
+func Test_Convert(t *testing.T) {
+	errRegistered := Register(errors.New("the VRF is not empty"), codes.FailedPrecondition, ReasonNotEmpty)
+	tests := map[string]struct {
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		"registered error": {
+			err:    errRegistered,
+			code:   codes.FailedPrecondition,
+			reason: ReasonNotEmpty,
+		},
+		"wrapped registered error": {
+			err:    fmt.Errorf("DeleteVrf(): %w", errRegistered),
+			code:   codes.FailedPrecondition,
+			reason: ReasonNotEmpty,
+		},
+		"status error": {
+			err:  status.Error(codes.Aborted, "etag mismatch"),
+			code: codes.Aborted,
+		},
+		"plain error": {
+			err:  errors.New("plain"),
+			code: codes.Internal,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			st := Convert(tt.err)
+			if st.Code() != tt.code {
+				t.Errorf("expected code %v, received %v", tt.code, st)
+			}
+			if reason := Reason(st.Err()); reason != tt.reason {
+				t.Errorf("expected reason %q, received %q", tt.reason, reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,525 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package apply converges the bridge towards a declarative bundle of resources
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"time"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// Action is what the plan does with an object
+type Action string
+
+const (
+	// ActionCreate creates the object missing from the bridge
+	ActionCreate Action = "create"
+	// ActionUpdate updates the object whose spec differs from the bundle
+	ActionUpdate Action = "update"
+	// ActionDelete deletes the object missing from the bundle, only when pruning
+	ActionDelete Action = "delete"
+	// ActionUnchanged leaves the object alone
+	ActionUnchanged Action = "unchanged"
+)
+
+// deletePollInterval is the interval at which a deleted object is checked until the bridge removes it
+var deletePollInterval = 500 * time.Millisecond
+
+// readyPollInterval is the interval at which an object is checked until the bridge has programmed it
+var readyPollInterval = 500 * time.Millisecond
+
+// rollbackTimeout bounds the undoing of a transaction, which runs even if the context of the transaction has expired
+const rollbackTimeout = 60 * time.Second
+
+// Change is one step of the plan
+type Change struct {
+	Action Action `json:"action"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	// RolledBack tells that the change has been undone because the transaction failed
+	RolledBack bool `json:"rolledBack,omitempty"`
+
+	run  func(ctx context.Context) error
+	undo func(ctx context.Context) error
+	// ready waits until the bridge has programmed the created or updated object
+	ready func(ctx context.Context) error
+	// previous is the object as it was before an update or a delete
+	previous proto.Message
+}
+
+// Plan is the ordered list of changes converging the bridge towards the bundle
+type Plan struct {
+	Changes []*Change `json:"changes"`
+}
+
+// kind binds an object type to its gRPC service
+type kind[T proto.Message] struct {
+	name    string
+	objName func(T) string
+	spec    func(T) proto.Message
+	list    func(ctx context.Context, conn grpc.ClientConnInterface, pageToken string) ([]T, string, error)
+	get     func(ctx context.Context, conn grpc.ClientConnInterface, name string, opts ...grpc.CallOption) (T, error)
+	create  func(ctx context.Context, conn grpc.ClientConnInterface, obj T, opts ...grpc.CallOption) error
+	update  func(ctx context.Context, conn grpc.ClientConnInterface, obj T, opts ...grpc.CallOption) error
+	delete  func(ctx context.Context, conn grpc.ClientConnInterface, name string, opts ...grpc.CallOption) error
+	// programmed tells whether the bridge has programmed the object
+	programmed func(T) bool
+	// serverGet reads the object from the server of the service, without going through a connection
+	serverGet func(ctx context.Context, server interface{}, name string) (T, error)
+	// objects returns the objects of the kind in the bundle
+	objects func(b *Bundle) *[]T
+}
+
+// errNoServer tells that the server is not the one of the service of the kind
+var errNoServer = errors.New("the server does not serve the kind")
+
+// grdVrf is created by the bridge itself and is never pruned
+const grdVrf = "GRD"
+
+var vrfKind = &kind[*pb.Vrf]{
+	name:    "Vrf",
+	objName: func(in *pb.Vrf) string { return in.GetName() },
+	spec:    func(in *pb.Vrf) proto.Message { return in.GetSpec() },
+	list: func(ctx context.Context, conn grpc.ClientConnInterface, pageToken string) ([]*pb.Vrf, string, error) {
+		resp, err := pb.NewVrfServiceClient(conn).ListVrfs(ctx, &pb.ListVrfsRequest{PageToken: pageToken})
+		return resp.GetVrfs(), resp.GetNextPageToken(), err
+	},
+	get: func(ctx context.Context, conn grpc.ClientConnInterface, name string, opts ...grpc.CallOption) (*pb.Vrf, error) {
+		return pb.NewVrfServiceClient(conn).GetVrf(ctx, &pb.GetVrfRequest{Name: name}, opts...)
+	},
+	create: func(ctx context.Context, conn grpc.ClientConnInterface, obj *pb.Vrf, opts ...grpc.CallOption) error {
+		_, err := pb.NewVrfServiceClient(conn).CreateVrf(ctx, &pb.CreateVrfRequest{VrfId: path.Base(obj.Name), Vrf: obj}, opts...)
+		return err
+	},
+	update: func(ctx context.Context, conn grpc.ClientConnInterface, obj *pb.Vrf, opts ...grpc.CallOption) error {
+		_, err := pb.NewVrfServiceClient(conn).UpdateVrf(ctx, &pb.UpdateVrfRequest{Vrf: obj}, opts...)
+		return err
+	},
+	delete: func(ctx context.Context, conn grpc.ClientConnInterface, name string, opts ...grpc.CallOption) error {
+		_, err := pb.NewVrfServiceClient(conn).DeleteVrf(ctx, &pb.DeleteVrfRequest{Name: name, AllowMissing: true}, opts...)
+		return err
+	},
+	programmed: func(in *pb.Vrf) bool { return in.GetStatus().GetOperStatus() == pb.VRFOperStatus_VRF_OPER_STATUS_UP },
+	serverGet: func(ctx context.Context, server interface{}, name string) (*pb.Vrf, error) {
+		srv, ok := server.(pb.VrfServiceServer)
+		if !ok {
+			return nil, errNoServer
+		}
+		return srv.GetVrf(ctx, &pb.GetVrfRequest{Name: name})
+	},
+	objects: func(b *Bundle) *[]*pb.Vrf { return &b.Vrfs },
+}
+
+var logicalBridgeKind = &kind[*pb.LogicalBridge]{
+	name:    "LogicalBridge",
+	objName: func(in *pb.LogicalBridge) string { return in.GetName() },
+	spec:    func(in *pb.LogicalBridge) proto.Message { return in.GetSpec() },
+	list: func(ctx context.Context, conn grpc.ClientConnInterface, pageToken string) ([]*pb.LogicalBridge, string, error) {
+		resp, err := pb.NewLogicalBridgeServiceClient(conn).ListLogicalBridges(ctx, &pb.ListLogicalBridgesRequest{PageToken: pageToken})
+		return resp.GetLogicalBridges(), resp.GetNextPageToken(), err
+	},
+	get: func(ctx context.Context, conn grpc.ClientConnInterface, name string, opts ...grpc.CallOption) (*pb.LogicalBridge, error) {
+		return pb.NewLogicalBridgeServiceClient(conn).GetLogicalBridge(ctx, &pb.GetLogicalBridgeRequest{Name: name}, opts...)
+	},
+	create: func(ctx context.Context, conn grpc.ClientConnInterface, obj *pb.LogicalBridge, opts ...grpc.CallOption) error {
+		_, err := pb.NewLogicalBridgeServiceClient(conn).CreateLogicalBridge(ctx, &pb.CreateLogicalBridgeRequest{LogicalBridgeId: path.Base(obj.Name), LogicalBridge: obj}, opts...)
+		return err
+	},
+	update: func(ctx context.Context, conn grpc.ClientConnInterface, obj *pb.LogicalBridge, opts ...grpc.CallOption) error {
+		_, err := pb.NewLogicalBridgeServiceClient(conn).UpdateLogicalBridge(ctx, &pb.UpdateLogicalBridgeRequest{LogicalBridge: obj}, opts...)
+		return err
+	},
+	delete: func(ctx context.Context, conn grpc.ClientConnInterface, name string, opts ...grpc.CallOption) error {
+		_, err := pb.NewLogicalBridgeServiceClient(conn).DeleteLogicalBridge(ctx, &pb.DeleteLogicalBridgeRequest{Name: name, AllowMissing: true}, opts...)
+		return err
+	},
+	programmed: func(in *pb.LogicalBridge) bool {
+		return in.GetStatus().GetOperStatus() == pb.LBOperStatus_LB_OPER_STATUS_UP
+	},
+	serverGet: func(ctx context.Context, server interface{}, name string) (*pb.LogicalBridge, error) {
+		srv, ok := server.(pb.LogicalBridgeServiceServer)
+		if !ok {
+			return nil, errNoServer
+		}
+		return srv.GetLogicalBridge(ctx, &pb.GetLogicalBridgeRequest{Name: name})
+	},
+	objects: func(b *Bundle) *[]*pb.LogicalBridge { return &b.LogicalBridges },
+}
+
+var sviKind = &kind[*pb.Svi]{
+	name:    "Svi",
+	objName: func(in *pb.Svi) string { return in.GetName() },
+	spec:    func(in *pb.Svi) proto.Message { return in.GetSpec() },
+	list: func(ctx context.Context, conn grpc.ClientConnInterface, pageToken string) ([]*pb.Svi, string, error) {
+		resp, err := pb.NewSviServiceClient(conn).ListSvis(ctx, &pb.ListSvisRequest{PageToken: pageToken})
+		return resp.GetSvis(), resp.GetNextPageToken(), err
+	},
+	get: func(ctx context.Context, conn grpc.ClientConnInterface, name string, opts ...grpc.CallOption) (*pb.Svi, error) {
+		return pb.NewSviServiceClient(conn).GetSvi(ctx, &pb.GetSviRequest{Name: name}, opts...)
+	},
+	create: func(ctx context.Context, conn grpc.ClientConnInterface, obj *pb.Svi, opts ...grpc.CallOption) error {
+		_, err := pb.NewSviServiceClient(conn).CreateSvi(ctx, &pb.CreateSviRequest{SviId: path.Base(obj.Name), Svi: obj}, opts...)
+		return err
+	},
+	update: func(ctx context.Context, conn grpc.ClientConnInterface, obj *pb.Svi, opts ...grpc.CallOption) error {
+		_, err := pb.NewSviServiceClient(conn).UpdateSvi(ctx, &pb.UpdateSviRequest{Svi: obj}, opts...)
+		return err
+	},
+	delete: func(ctx context.Context, conn grpc.ClientConnInterface, name string, opts ...grpc.CallOption) error {
+		_, err := pb.NewSviServiceClient(conn).DeleteSvi(ctx, &pb.DeleteSviRequest{Name: name, AllowMissing: true}, opts...)
+		return err
+	},
+	programmed: func(in *pb.Svi) bool { return in.GetStatus().GetOperStatus() == pb.SVIOperStatus_SVI_OPER_STATUS_UP },
+	serverGet: func(ctx context.Context, server interface{}, name string) (*pb.Svi, error) {
+		srv, ok := server.(pb.SviServiceServer)
+		if !ok {
+			return nil, errNoServer
+		}
+		return srv.GetSvi(ctx, &pb.GetSviRequest{Name: name})
+	},
+	objects: func(b *Bundle) *[]*pb.Svi { return &b.Svis },
+}
+
+var bridgePortKind = &kind[*pb.BridgePort]{
+	name:    "BridgePort",
+	objName: func(in *pb.BridgePort) string { return in.GetName() },
+	spec:    func(in *pb.BridgePort) proto.Message { return in.GetSpec() },
+	list: func(ctx context.Context, conn grpc.ClientConnInterface, pageToken string) ([]*pb.BridgePort, string, error) {
+		resp, err := pb.NewBridgePortServiceClient(conn).ListBridgePorts(ctx, &pb.ListBridgePortsRequest{PageToken: pageToken})
+		return resp.GetBridgePorts(), resp.GetNextPageToken(), err
+	},
+	get: func(ctx context.Context, conn grpc.ClientConnInterface, name string, opts ...grpc.CallOption) (*pb.BridgePort, error) {
+		return pb.NewBridgePortServiceClient(conn).GetBridgePort(ctx, &pb.GetBridgePortRequest{Name: name}, opts...)
+	},
+	create: func(ctx context.Context, conn grpc.ClientConnInterface, obj *pb.BridgePort, opts ...grpc.CallOption) error {
+		_, err := pb.NewBridgePortServiceClient(conn).CreateBridgePort(ctx, &pb.CreateBridgePortRequest{BridgePortId: path.Base(obj.Name), BridgePort: obj}, opts...)
+		return err
+	},
+	update: func(ctx context.Context, conn grpc.ClientConnInterface, obj *pb.BridgePort, opts ...grpc.CallOption) error {
+		_, err := pb.NewBridgePortServiceClient(conn).UpdateBridgePort(ctx, &pb.UpdateBridgePortRequest{BridgePort: obj}, opts...)
+		return err
+	},
+	delete: func(ctx context.Context, conn grpc.ClientConnInterface, name string, opts ...grpc.CallOption) error {
+		_, err := pb.NewBridgePortServiceClient(conn).DeleteBridgePort(ctx, &pb.DeleteBridgePortRequest{Name: name, AllowMissing: true}, opts...)
+		return err
+	},
+	programmed: func(in *pb.BridgePort) bool {
+		return in.GetStatus().GetOperStatus() == pb.BPOperStatus_BP_OPER_STATUS_UP
+	},
+	serverGet: func(ctx context.Context, server interface{}, name string) (*pb.BridgePort, error) {
+		srv, ok := server.(pb.BridgePortServiceServer)
+		if !ok {
+			return nil, errNoServer
+		}
+		return srv.GetBridgePort(ctx, &pb.GetBridgePortRequest{Name: name})
+	},
+	objects: func(b *Bundle) *[]*pb.BridgePort { return &b.BridgePorts },
+}
+
+// listAll walks through all the pages of the list, the bridge answers NotFound when there is no object at all
+func (k *kind[T]) listAll(ctx context.Context, conn grpc.ClientConnInterface) ([]T, error) {
+	all := []T{}
+	token := ""
+	for {
+		objs, next, err := k.list(ctx, conn, token)
+		if status.Code(err) == codes.NotFound {
+			return all, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the %ss: %v", k.name, err)
+		}
+		all = append(all, objs...)
+		if next == "" {
+			return all, nil
+		}
+		token = next
+	}
+}
+
+// waitDeleted polls the object until the bridge has removed it
+func (k *kind[T]) waitDeleted(ctx context.Context, conn grpc.ClientConnInterface, name string) error {
+	for {
+		_, err := k.get(ctx, conn, name)
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s %s is still being deleted: %v", k.name, name, ctx.Err())
+		case <-time.After(deletePollInterval):
+		}
+	}
+}
+
+// waitProgrammed polls the object until the bridge has programmed it
+func (k *kind[T]) waitProgrammed(ctx context.Context, conn grpc.ClientConnInterface, name string) error {
+	for {
+		obj, err := k.get(ctx, conn, name)
+		if err != nil {
+			return err
+		}
+		if k.programmed(obj) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s %s has not been programmed: %v", k.name, name, ctx.Err())
+		case <-time.After(readyPollInterval):
+		}
+	}
+}
+
+// withDefaults returns the desired spec where the message fields left unset, e.g. the vtep prefix,
+// take the value picked by the bridge so that they are not seen as a change
+func withDefaults(desired, current proto.Message) proto.Message {
+	out := proto.Clone(desired)
+	m := out.ProtoReflect()
+	cur := current.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Message() != nil && !fd.IsList() && !fd.IsMap() && !m.Has(fd) && cur.Has(fd) {
+			m.Set(fd, cur.Get(fd))
+		}
+	}
+	return out
+}
+
+// changed tells whether the spec of the object differs from the desired one
+func (k *kind[T]) changed(desired, current T) bool {
+	return !proto.Equal(withDefaults(k.spec(desired), k.spec(current)), k.spec(current))
+}
+
+// getVersion returns the object with its etag, empty when the bridge returns none
+func (k *kind[T]) getVersion(ctx context.Context, conn grpc.ClientConnInterface, name string) (T, string, error) {
+	var header metadata.MD
+	obj, err := k.get(ctx, conn, name, grpc.Header(&header))
+	return obj, etag(header), err
+}
+
+// etag returns the resource version of the etag response header
+func etag(header metadata.MD) string {
+	if values := header.Get(utils.ETagHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// withIfMatch makes the change fail with Aborted unless the object is still at the version, the change of
+// another client in between is not silently overwritten. Without version the change is unconditional.
+func withIfMatch(ctx context.Context, version string) context.Context {
+	if version == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, utils.IfMatchHeader, version)
+}
+
+// diff computes the creations, updates and deletions of the kind, the objects missing from the desired ones
+// are deleted when prune tells so
+func (k *kind[T]) diff(ctx context.Context, conn grpc.ClientConnInterface, desired []T, prune func(name string) bool) (applies, deletes []*Change, err error) {
+	current, err := k.listAll(ctx, conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	existing := map[string]T{}
+	for _, obj := range current {
+		existing[k.objName(obj)] = obj
+	}
+	wanted := map[string]bool{}
+	for _, obj := range desired {
+		obj := obj
+		name := k.objName(obj)
+		wanted[name] = true
+		c := &Change{Kind: k.name, Name: name}
+		cur, ok := existing[name]
+		version := ""
+		if ok && k.changed(obj, cur) {
+			// the update is computed from, and only applies to, the version of the object read now
+			if cur, version, err = k.getVersion(ctx, conn, name); err != nil {
+				return nil, nil, err
+			}
+		}
+		switch {
+		case !ok:
+			c.Action = ActionCreate
+			created := ""
+			c.run = func(ctx context.Context) error {
+				var header metadata.MD
+				if err := k.create(ctx, conn, obj, grpc.Header(&header)); err != nil {
+					return err
+				}
+				created = etag(header)
+				return nil
+			}
+			c.undo = func(ctx context.Context) error {
+				if err := k.delete(withIfMatch(ctx, created), conn, name); err != nil {
+					return err
+				}
+				return k.waitDeleted(ctx, conn, name)
+			}
+		case k.changed(obj, cur):
+			c.Action = ActionUpdate
+			c.previous = cur
+			updated := ""
+			c.run = func(ctx context.Context) error {
+				var header metadata.MD
+				if err := k.update(withIfMatch(ctx, version), conn, obj, grpc.Header(&header)); err != nil {
+					return err
+				}
+				updated = etag(header)
+				return nil
+			}
+			c.undo = func(ctx context.Context) error { return k.update(withIfMatch(ctx, updated), conn, cur) }
+		default:
+			c.Action = ActionUnchanged
+		}
+		if c.run != nil {
+			c.ready = func(ctx context.Context) error { return k.waitProgrammed(ctx, conn, name) }
+		}
+		applies = append(applies, c)
+	}
+	for _, listed := range current {
+		name := k.objName(listed)
+		if wanted[name] || !prune(name) || (k.name == vrfKind.name && path.Base(name) == grdVrf) {
+			continue
+		}
+		obj, version, err := k.getVersion(ctx, conn, name)
+		if status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		deletes = append(deletes, &Change{
+			Action: ActionDelete,
+			Kind:   k.name,
+			Name:   name,
+			run: func(ctx context.Context) error {
+				if err := k.delete(withIfMatch(ctx, version), conn, name); err != nil {
+					return err
+				}
+				// the parents can only be deleted once their children are gone
+				return k.waitDeleted(ctx, conn, name)
+			},
+			undo:     func(ctx context.Context) error { return k.create(ctx, conn, obj) },
+			previous: obj,
+		})
+	}
+	return applies, deletes, nil
+}
+
+// NewPlan compares the bundle with the objects of the bridge, when pruning the objects missing from the bundle are deleted
+func NewPlan(ctx context.Context, conn grpc.ClientConnInterface, b *Bundle, prune bool) (*Plan, error) {
+	return newPlan(ctx, conn, b, func(string) bool { return prune })
+}
+
+// NewScopedPlan compares the bundle with the objects of the bridge, the objects of the scope missing from
+// the bundle are deleted while the objects out of the scope are left alone
+func NewScopedPlan(ctx context.Context, conn grpc.ClientConnInterface, b *Bundle, scope map[string]bool) (*Plan, error) {
+	return newPlan(ctx, conn, b, func(name string) bool { return scope[name] })
+}
+
+// newPlan computes the changes of the kinds in dependency order
+func newPlan(ctx context.Context, conn grpc.ClientConnInterface, b *Bundle, prune func(name string) bool) (*Plan, error) {
+	vrfApplies, vrfDeletes, err := vrfKind.diff(ctx, conn, b.Vrfs, prune)
+	if err != nil {
+		return nil, err
+	}
+	lbApplies, lbDeletes, err := logicalBridgeKind.diff(ctx, conn, b.LogicalBridges, prune)
+	if err != nil {
+		return nil, err
+	}
+	sviApplies, sviDeletes, err := sviKind.diff(ctx, conn, b.Svis, prune)
+	if err != nil {
+		return nil, err
+	}
+	bpApplies, bpDeletes, err := bridgePortKind.diff(ctx, conn, b.BridgePorts, prune)
+	if err != nil {
+		return nil, err
+	}
+	p := &Plan{Changes: []*Change{}}
+	// deletions go first from the children to the parents, then the parents are created before their children
+	for _, changes := range [][]*Change{bpDeletes, sviDeletes, lbDeletes, vrfDeletes, vrfApplies, lbApplies, sviApplies, bpApplies} {
+		p.Changes = append(p.Changes, changes...)
+	}
+	return p, nil
+}
+
+// Apply runs the changes of the plan in order and stops at the first failure
+func (p *Plan) Apply(ctx context.Context) error {
+	for _, c := range p.Changes {
+		if c.run == nil {
+			continue
+		}
+		log.Printf("Apply(): %s %s %s", c.Action, c.Kind, c.Name)
+		if err := c.run(ctx); err != nil {
+			return fmt.Errorf("failed to %s %s %s: %w", c.Action, c.Kind, c.Name, err)
+		}
+	}
+	return nil
+}
+
+// ApplyAtomic runs the changes of the plan as a transaction: when a change fails, or when waiting
+// for the programming and an object is not programmed before the context expires, the changes
+// done so far are undone in the reverse order so that the bridge is left as it was
+func (p *Plan) ApplyAtomic(ctx context.Context, waitProgrammed bool) error {
+	done := []*Change{}
+	err := func() error {
+		for _, c := range p.Changes {
+			if c.run == nil {
+				continue
+			}
+			log.Printf("ApplyAtomic(): %s %s %s", c.Action, c.Kind, c.Name)
+			if err := c.run(ctx); err != nil {
+				return fmt.Errorf("failed to %s %s %s: %w", c.Action, c.Kind, c.Name, err)
+			}
+			done = append(done, c)
+		}
+		if !waitProgrammed {
+			return nil
+		}
+		for _, c := range done {
+			if c.ready == nil {
+				continue
+			}
+			if err := c.ready(ctx); err != nil {
+				return fmt.Errorf("failed to %s %s %s: %w", c.Action, c.Kind, c.Name, err)
+			}
+		}
+		return nil
+	}()
+	if err == nil {
+		return nil
+	}
+
+	// the rollback is not bound to the context of the transaction, which may have expired
+	rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+	for i := len(done) - 1; i >= 0; i-- {
+		c := done[i]
+		log.Printf("ApplyAtomic(): rolling back %s %s %s", c.Action, c.Kind, c.Name)
+		if rerr := c.undo(rollbackCtx); rerr != nil {
+			return fmt.Errorf("%w, and failed to roll back %s %s %s: %v", err, c.Action, c.Kind, c.Name, rerr)
+		}
+		c.RolledBack = true
+	}
+	return fmt.Errorf("%w, the transaction has been rolled back", err)
+}
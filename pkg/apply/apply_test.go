@@ -0,0 +1,369 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package apply converges the bridge towards a declarative bundle of resources
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/port"
+	"github.com/opiproject/opi-evpn-bridge/pkg/svi"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+	"github.com/opiproject/opi-evpn-bridge/pkg/vrf"
+)
+
+// newTestConn serves the bridge gRPC API on top of an empty gomap db
+func newTestConn(t *testing.T) *grpc.ClientConn {
+	eb := eventbus.EBus
+	for _, eventType := range []string{"vrf", "logical-bridge", "svi", "bridge-port"} {
+		eb.StartSubscriber("dummy", eventType, 1, nil)
+	}
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(grpc.UnaryInterceptor(utils.ETagInterceptor(infradb.GetResourceVersion, false)))
+	pb.RegisterVrfServiceServer(s, vrf.NewServer())
+	pb.RegisterLogicalBridgeServiceServer(s, bridge.NewServer())
+	pb.RegisterSviServiceServer(s, svi.NewServer())
+	pb.RegisterBridgePortServiceServer(s, port.NewServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// mustParse parses the manifest of the test
+func mustParse(t *testing.T, manifest string) *Bundle {
+	b, err := Parse([]byte(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+const blueVrf = `
+apiVersion: evpn.opiproject.org/v1alpha1
+kind: Vrf
+metadata:
+  name: blue
+spec:
+  vni: 1000
+  loopbackIpPrefix: 10.0.0.1/32
+  vtepIpPrefix: 10.1.0.1/32
+`
+
+const blueWebBridge = `
+apiVersion: evpn.opiproject.org/v1alpha1
+kind: LogicalBridge
+metadata:
+  name: blue-web
+spec:
+  vlanId: 10
+  vni: 10
+`
+
+func Test_Parse(t *testing.T) {
+	sample, err := os.ReadFile("../../config/operator/samples/tenant.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		manifest string
+		counts   []int
+		err      bool
+	}{
+		"operator sample": {
+			manifest: string(sample),
+			counts:   []int{1, 1, 1, 1},
+		},
+		"json": {
+			manifest: `{"apiVersion": "evpn.opiproject.org/v1alpha1", "kind": "LogicalBridge", "metadata": {"name": "red"}, "spec": {"vlanId": 20}}`,
+			counts:   []int{0, 1, 0, 0},
+		},
+		"empty documents": {
+			manifest: "---\n" + blueVrf + "---\n---\n",
+			counts:   []int{1, 0, 0, 0},
+		},
+		"wrong api version": {
+			manifest: "apiVersion: v1\nkind: Vrf\nmetadata:\n  name: blue\n",
+			err:      true,
+		},
+		"unknown kind": {
+			manifest: "apiVersion: evpn.opiproject.org/v1alpha1\nkind: Subnet\nmetadata:\n  name: blue\n",
+			err:      true,
+		},
+		"unknown field": {
+			manifest: "apiVersion: evpn.opiproject.org/v1alpha1\nkind: Vrf\nmetadata:\n  name: blue\nspec:\n  vnii: 1000\n",
+			err:      true,
+		},
+		"missing name": {
+			manifest: "apiVersion: evpn.opiproject.org/v1alpha1\nkind: Vrf\nspec:\n  vni: 1000\n",
+			err:      true,
+		},
+		"invalid prefix": {
+			manifest: "apiVersion: evpn.opiproject.org/v1alpha1\nkind: Vrf\nmetadata:\n  name: blue\nspec:\n  vtepIpPrefix: 10.1.0.300/32\n",
+			err:      true,
+		},
+		"duplicated object": {
+			manifest: blueVrf + "---" + blueVrf,
+			err:      true,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			b, err := Parse([]byte(tt.manifest))
+			if (err != nil) != tt.err {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if err != nil {
+				return
+			}
+			counts := []int{len(b.Vrfs), len(b.LogicalBridges), len(b.Svis), len(b.BridgePorts)}
+			if !reflect.DeepEqual(counts, tt.counts) {
+				t.Errorf("expected %v objects, received %v", tt.counts, counts)
+			}
+		})
+	}
+}
+
+func Test_NewPlan(t *testing.T) {
+	redBridge := `
+apiVersion: evpn.opiproject.org/v1alpha1
+kind: LogicalBridge
+metadata:
+  name: red-web
+spec:
+  vlanId: 20
+`
+	tests := map[string]struct {
+		existing string
+		manifest string
+		prune    bool
+		changes  []string
+	}{
+		"create in dependency order": {
+			manifest: blueWebBridge + "---" + blueVrf,
+			changes:  []string{"create Vrf blue", "create LogicalBridge blue-web"},
+		},
+		"unchanged and updated": {
+			existing: blueVrf + "---" + blueWebBridge,
+			manifest: blueVrf + "---" + blueWebBridge[:len(blueWebBridge)-len("vni: 10\n")] + "vni: 11\n",
+			changes:  []string{"unchanged Vrf blue", "update LogicalBridge blue-web"},
+		},
+		"extra objects are kept without prune": {
+			existing: blueWebBridge + "---" + redBridge,
+			manifest: blueWebBridge,
+			changes:  []string{"unchanged LogicalBridge blue-web"},
+		},
+		"extra objects are deleted first with prune": {
+			existing: blueWebBridge + "---" + redBridge,
+			manifest: blueVrf + "---" + blueWebBridge,
+			prune:    true,
+			changes:  []string{"delete LogicalBridge red-web", "create Vrf blue", "unchanged LogicalBridge blue-web"},
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			conn := newTestConn(t)
+			ctx := context.Background()
+			if tt.existing != "" {
+				p, err := NewPlan(ctx, conn, mustParse(t, tt.existing), false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := p.Apply(ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+			p, err := NewPlan(ctx, conn, mustParse(t, tt.manifest), tt.prune)
+			if err != nil {
+				t.Fatal(err)
+			}
+			changes := []string{}
+			for _, c := range p.Changes {
+				changes = append(changes, fmt.Sprintf("%s %s %s", c.Action, c.Kind, path.Base(c.Name)))
+			}
+			if !reflect.DeepEqual(changes, tt.changes) {
+				t.Errorf("expected changes %v, received %v", tt.changes, changes)
+			}
+		})
+	}
+}
+
+func Test_ApplyAtomic(t *testing.T) {
+	tests := map[string]struct {
+		failRun   string
+		failReady string
+		wait      bool
+		steps     []string
+		err       bool
+	}{
+		"committed": {
+			steps: []string{"run a", "run b", "run c"},
+		},
+		"committed once programmed": {
+			wait:  true,
+			steps: []string{"run a", "run b", "run c", "ready a", "ready b", "ready c"},
+		},
+		"failed change": {
+			failRun: "c",
+			steps:   []string{"run a", "run b", "run c", "undo b", "undo a"},
+			err:     true,
+		},
+		"not programmed": {
+			failReady: "b",
+			wait:      true,
+			steps:     []string{"run a", "run b", "run c", "ready a", "ready b", "undo c", "undo b", "undo a"},
+			err:       true,
+		},
+		"programming not awaited": {
+			failReady: "b",
+			steps:     []string{"run a", "run b", "run c"},
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			steps := []string{}
+			step := func(action, name, fail string) func(context.Context) error {
+				return func(context.Context) error {
+					steps = append(steps, action+" "+name)
+					if name == fail {
+						return fmt.Errorf("%s %s failed", action, name)
+					}
+					return nil
+				}
+			}
+			p := &Plan{Changes: []*Change{{Action: ActionUnchanged, Kind: "Vrf", Name: "unchanged"}}}
+			for _, name := range []string{"a", "b", "c"} {
+				p.Changes = append(p.Changes, &Change{
+					Action: ActionCreate,
+					Kind:   "Vrf",
+					Name:   name,
+					run:    step("run", name, tt.failRun),
+					undo:   step("undo", name, ""),
+					ready:  step("ready", name, tt.failReady),
+				})
+			}
+			err := p.ApplyAtomic(context.Background(), tt.wait)
+			if (err != nil) != tt.err {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(steps, tt.steps) {
+				t.Errorf("expected steps %v, received %v", tt.steps, steps)
+			}
+			for _, c := range p.Changes {
+				if c.RolledBack && c.Name == tt.failRun {
+					t.Errorf("the failed change %s has been rolled back", c.Name)
+				}
+			}
+		})
+	}
+}
+
+func Test_Revert(t *testing.T) {
+	conn := newTestConn(t)
+	ctx := context.Background()
+	p, err := NewPlan(ctx, conn, mustParse(t, blueWebBridge), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the bridge is updated and the vrf created, the snapshot deletes the vrf and restores the bridge
+	updated := blueWebBridge[:len(blueWebBridge)-len("vni: 10\n")] + "vni: 11\n"
+	p, err = NewPlan(ctx, conn, mustParse(t, blueVrf+"---"+updated), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(ctx); err != nil {
+		t.Fatal(err)
+	}
+	s, err := p.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Undos) != 2 || s.Undos[0].Action != ActionCreate || s.Undos[0].Previous != nil ||
+		s.Undos[1].Action != ActionUpdate || s.Undos[1].Previous == nil {
+		t.Fatalf("unexpected snapshot %+v", s.Undos)
+	}
+
+	// the dummy subscribers never remove the deleted vrf, only the update is reverted
+	s.Undos = s.Undos[1:]
+	if err := s.Revert(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Undos) != 0 {
+		t.Errorf("expected no undo left, received %+v", s.Undos)
+	}
+	p, err = NewPlan(ctx, conn, mustParse(t, blueWebBridge), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Changes) != 1 || p.Changes[0].Action != ActionUnchanged {
+		t.Errorf("expected the bridge as it was, received %+v", p.Changes[0])
+	}
+
+	s.Undos = []*Undo{{Action: ActionUpdate, Kind: "Subnet", Name: "blue"}}
+	if err := s.Revert(ctx, conn); err == nil || len(s.Undos) != 1 {
+		t.Errorf("expected the unknown kind to be kept, received %v", err)
+	}
+}
+
+func Test_ApplyConcurrentChange(t *testing.T) {
+	conn := newTestConn(t)
+	ctx := context.Background()
+	p, err := NewPlan(ctx, conn, mustParse(t, blueWebBridge), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// another client changes the bridge once the plan has been computed, the plan does not overwrite it
+	p, err = NewPlan(ctx, conn, mustParse(t, blueWebBridge[:len(blueWebBridge)-len("vni: 10\n")]+"vni: 11\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewPlan(ctx, conn, mustParse(t, blueWebBridge[:len(blueWebBridge)-len("vni: 10\n")]+"vni: 12\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Apply(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(ctx); status.Code(errors.Unwrap(err)) != codes.Aborted {
+		t.Errorf("expected the update to be aborted, received %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package apply converges the bridge towards a declarative bundle of resources
+package apply
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/operator/api/v1alpha1"
+)
+
+// Bundle holds the desired objects of the bridge
+type Bundle struct {
+	Vrfs           []*pb.Vrf
+	LogicalBridges []*pb.LogicalBridge
+	Svis           []*pb.Svi
+	BridgePorts    []*pb.BridgePort
+}
+
+// document is the part of a manifest that identifies its kind
+type document struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+}
+
+// decodeStrict unmarshals the document rejecting the unknown fields, so that typos are not silently ignored
+func decodeStrict(raw []byte, obj interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	return dec.Decode(obj)
+}
+
+// Parse reads a bundle from YAML or JSON manifests, using the same kinds as the kubernetes operator
+func Parse(data []byte) (*Bundle, error) {
+	b := &Bundle{}
+	seen := map[string]bool{}
+	dec := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for i := 1; ; i++ {
+		raw := json.RawMessage{}
+		err := dec.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return b, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %v", i, err)
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+		doc := document{}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("document %d: %v", i, err)
+		}
+		if doc.APIVersion != v1alpha1.GroupVersion.String() {
+			return nil, fmt.Errorf("document %d: apiVersion must be %s and not %q", i, v1alpha1.GroupVersion, doc.APIVersion)
+		}
+		if doc.Name == "" {
+			return nil, fmt.Errorf("document %d: %s without metadata.name", i, doc.Kind)
+		}
+		key := doc.Kind + "/" + doc.Name
+		if seen[key] {
+			return nil, fmt.Errorf("document %d: %s is duplicated", i, key)
+		}
+		seen[key] = true
+		if err := b.add(doc.Kind, raw); err != nil {
+			return nil, fmt.Errorf("document %d: %s: %v", i, key, err)
+		}
+	}
+}
+
+// add translates the document of the given kind and adds it to the bundle
+func (b *Bundle) add(kind string, raw []byte) error {
+	switch kind {
+	case "Vrf":
+		obj := &v1alpha1.Vrf{}
+		if err := decodeStrict(raw, obj); err != nil {
+			return err
+		}
+		vrf, err := obj.ToPb()
+		if err != nil {
+			return err
+		}
+		b.Vrfs = append(b.Vrfs, vrf)
+	case "LogicalBridge":
+		obj := &v1alpha1.LogicalBridge{}
+		if err := decodeStrict(raw, obj); err != nil {
+			return err
+		}
+		lb, err := obj.ToPb()
+		if err != nil {
+			return err
+		}
+		b.LogicalBridges = append(b.LogicalBridges, lb)
+	case "Svi":
+		obj := &v1alpha1.Svi{}
+		if err := decodeStrict(raw, obj); err != nil {
+			return err
+		}
+		svi, err := obj.ToPb()
+		if err != nil {
+			return err
+		}
+		b.Svis = append(b.Svis, svi)
+	case "BridgePort":
+		obj := &v1alpha1.BridgePort{}
+		if err := decodeStrict(raw, obj); err != nil {
+			return err
+		}
+		bp, err := obj.ToPb()
+		if err != nil {
+			return err
+		}
+		b.BridgePorts = append(b.BridgePorts, bp)
+	default:
+		return fmt.Errorf("unknown kind, expected one of Vrf, LogicalBridge, Svi or BridgePort")
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package apply converges the bridge towards a declarative bundle of resources
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Undo reverts one change of an applied plan
+type Undo struct {
+	Action Action `json:"action"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	// Previous is the object as it was before an update or a delete, in its protojson form
+	Previous json.RawMessage `json:"previous,omitempty"`
+}
+
+// Snapshot holds what it takes to revert an applied plan, it is kept in the store
+// so that the plan can be reverted long after it has been applied, even after a restart
+type Snapshot struct {
+	Undos []*Undo `json:"undos"`
+}
+
+// restorer restores the objects of a kind
+type restorer interface {
+	restore(ctx context.Context, conn grpc.ClientConnInterface, u *Undo) error
+	capture(ctx context.Context, server interface{}, name string) (*Undo, error)
+	addTo(b *Bundle, data []byte) error
+}
+
+// restorers restore the objects by kind
+var restorers = map[string]restorer{
+	vrfKind.name:           vrfKind,
+	logicalBridgeKind.name: logicalBridgeKind,
+	sviKind.name:           sviKind,
+	bridgePortKind.name:    bridgePortKind,
+}
+
+// unmarshal returns the object of its protojson form
+func (k *kind[T]) unmarshal(data []byte) (T, error) {
+	var zero T
+	obj := zero.ProtoReflect().New().Interface().(T)
+	if err := protojson.Unmarshal(data, obj); err != nil {
+		return zero, fmt.Errorf("invalid %s: %v", k.name, err)
+	}
+	return obj, nil
+}
+
+// addTo adds the object in its protojson form to the bundle
+func (k *kind[T]) addTo(b *Bundle, data []byte) error {
+	obj, err := k.unmarshal(data)
+	if err != nil {
+		return err
+	}
+	objs := k.objects(b)
+	*objs = append(*objs, obj)
+	return nil
+}
+
+// IsKind tells whether the objects of the kind, e.g. "Vrf", can be applied and reverted
+func IsKind(kind string) bool {
+	_, ok := restorers[kind]
+	return ok
+}
+
+// AddObject adds the object of the kind in its protojson form, e.g. as kept in the history, to the bundle
+func (b *Bundle) AddObject(kind string, data []byte) error {
+	r, ok := restorers[kind]
+	if !ok {
+		return fmt.Errorf("unknown kind %s", kind)
+	}
+	return r.addTo(b, data)
+}
+
+// restore deletes the created object, or brings back the updated or deleted object as it was
+func (k *kind[T]) restore(ctx context.Context, conn grpc.ClientConnInterface, u *Undo) error {
+	if u.Action == ActionCreate {
+		if err := k.delete(ctx, conn, u.Name); err != nil {
+			return err
+		}
+		return k.waitDeleted(ctx, conn, u.Name)
+	}
+	obj, err := k.unmarshal(u.Previous)
+	if err != nil {
+		return fmt.Errorf("previous %s: %v", u.Name, err)
+	}
+	if u.Action == ActionUpdate {
+		return k.update(ctx, conn, obj)
+	}
+	return k.create(ctx, conn, obj)
+}
+
+// capture returns the undo of an update of the object, which restores it as it is now
+func (k *kind[T]) capture(ctx context.Context, server interface{}, name string) (*Undo, error) {
+	obj, err := k.serverGet(ctx, server, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := protojson.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to keep %s %s: %v", k.name, name, err)
+	}
+	return &Undo{Action: ActionUpdate, Kind: k.name, Name: name, Previous: data}, nil
+}
+
+// CaptureUpdate returns the snapshot reverting the update of the object of the kind, e.g. "Vrf", which is about
+// to be done by a handler of the server. It fails when the server does not serve the kind.
+func CaptureUpdate(ctx context.Context, server interface{}, kind, name string) (*Snapshot, error) {
+	r, ok := restorers[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown kind %s", kind)
+	}
+	u, err := r.capture(ctx, server, name)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Undos: []*Undo{u}}, nil
+}
+
+// CaptureCreate returns the snapshot reverting the creation of the object of the kind, e.g. by an update
+// allowing the object to be missing, which is about to be done by a handler of the server
+func CaptureCreate(kind, name string) (*Snapshot, error) {
+	if !IsKind(kind) {
+		return nil, fmt.Errorf("unknown kind %s", kind)
+	}
+	return &Snapshot{Undos: []*Undo{{Action: ActionCreate, Kind: kind, Name: name}}}, nil
+}
+
+// Snapshot returns what it takes to revert the changes of the plan once it has been applied
+func (p *Plan) Snapshot() (*Snapshot, error) {
+	s := &Snapshot{Undos: []*Undo{}}
+	for _, c := range p.Changes {
+		if c.run == nil {
+			continue
+		}
+		u := &Undo{Action: c.Action, Kind: c.Kind, Name: c.Name}
+		if c.previous != nil {
+			data, err := protojson.Marshal(c.previous)
+			if err != nil {
+				return nil, fmt.Errorf("failed to keep %s %s: %v", c.Kind, c.Name, err)
+			}
+			u.Previous = data
+		}
+		s.Undos = append(s.Undos, u)
+	}
+	return s, nil
+}
+
+// Revert undoes the changes in the reverse order, the children being deleted before their parents
+// and the parents restored before their children. It stops at the first failure, keeping the undos
+// which are left so that the revert can be retried.
+func (s *Snapshot) Revert(ctx context.Context, conn grpc.ClientConnInterface) error {
+	for i := len(s.Undos) - 1; i >= 0; i-- {
+		u := s.Undos[i]
+		r, ok := restorers[u.Kind]
+		if !ok {
+			return fmt.Errorf("unknown kind %s", u.Kind)
+		}
+		log.Printf("Revert(): undoing %s %s %s", u.Action, u.Kind, u.Name)
+		if err := r.restore(ctx, conn, u); err != nil {
+			return fmt.Errorf("failed to undo %s %s %s: %w", u.Action, u.Kind, u.Name, err)
+		}
+		s.Undos = s.Undos[:i]
+	}
+	return nil
+}
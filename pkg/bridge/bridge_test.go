@@ -9,9 +9,12 @@ package bridge
 import (
 	"context"
 	"fmt"
+	"path"
 	"reflect"
 	"testing"
 
+	"go.einride.tech/aip/resourceid"
+	"go.einride.tech/aip/resourcename"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
@@ -85,14 +88,25 @@ func Test_CreateLogicalBridge(t *testing.T) {
 			exist:   false,
 			on:      nil,
 		},
-		"no required vlan_id field": {
+		"allocated vlan_id field": {
 			id: testLogicalBridgeID,
 			in: &pb.LogicalBridge{
 				Spec: &pb.LogicalBridgeSpec{},
 			},
-			out:     nil,
-			errCode: codes.Unknown,
-			errMsg:  "missing required field: logical_bridge.spec.vlan_id",
+			out: &pb.LogicalBridge{
+				Spec: &pb.LogicalBridgeSpec{
+					VlanId: 1,
+					VtepIpPrefix: &pc.IPPrefix{
+						Addr: &pc.IPAddress{
+							Af:     pc.IpAf_IP_AF_INET,
+							V4OrV6: &pc.IPAddress_V4Addr{},
+						},
+					},
+				},
+				Status: testLogicalBridgeWithStatus.Status,
+			},
+			errCode: codes.OK,
+			errMsg:  "",
 			exist:   false,
 			on:      nil,
 		},
@@ -106,7 +120,7 @@ func Test_CreateLogicalBridge(t *testing.T) {
 			},
 			out:     nil,
 			errCode: codes.InvalidArgument,
-			errMsg:  fmt.Sprintf("VlanId value (%v) have to be between 1 and 4095", 4096),
+			errMsg:  fmt.Sprintf("VlanId value (%v) have to be between 0 and 4094", 4096),
 			exist:   false,
 			on:      nil,
 		},
@@ -147,6 +161,20 @@ func Test_CreateLogicalBridge(t *testing.T) {
 			errMsg:  "",
 			exist:   true,
 		},
+		"already exists with another spec": {
+			id: testLogicalBridgeID,
+			in: &pb.LogicalBridge{
+				Spec: &pb.LogicalBridgeSpec{
+					Vni:          testLogicalBridge.Spec.Vni,
+					VlanId:       23,
+					VtepIpPrefix: testLogicalBridge.Spec.VtepIpPrefix,
+				},
+			},
+			out:     nil,
+			errCode: codes.AlreadyExists,
+			errMsg:  fmt.Sprintf("%s already exists with another spec", testLogicalBridgeName),
+			exist:   true,
+		},
 		"successful call": {
 			id:      testLogicalBridgeID,
 			in:      &testLogicalBridge,
@@ -527,3 +555,21 @@ func Test_ListLogicalBridges(t *testing.T) {
 		})
 	}
 }
+
+func FuzzResourceIDToFullName(f *testing.F) {
+	for _, id := range []string{testLogicalBridgeID, "a", "opi-bridge-0123456789", "", "a/b", "../x", "-a", "a-", "A", "a b", "a%2Fb"} {
+		f.Add(id)
+	}
+	f.Fuzz(func(t *testing.T, id string) {
+		if resourceid.ValidateUserSettable(id) != nil {
+			return
+		}
+		name := resourceIDToFullName(id)
+		if err := resourcename.Validate(name); err != nil {
+			t.Errorf("the name %q of the id %q is not valid: %v", name, id, err)
+		}
+		if base := path.Base(name); base != id {
+			t.Errorf("the name %q of the id %q ends with %q", name, id, base)
+		}
+	})
+}
@@ -18,11 +18,13 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/pbconv"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
 )
 
@@ -39,7 +41,7 @@ func (s *Server) createLogicalBridge(lb *pb.LogicalBridge) (*pb.LogicalBridge, e
 	}
 
 	// translation of pb to domain object
-	domainLB, err := infradb.NewLogicalBridge(lb)
+	domainLB, err := pbconv.LogicalBridgeFromPb(lb)
 	if err != nil {
 		return nil, err
 	}
@@ -47,12 +49,12 @@ func (s *Server) createLogicalBridge(lb *pb.LogicalBridge) (*pb.LogicalBridge, e
 	if err := infradb.CreateLB(domainLB); err != nil {
 		return nil, err
 	}
-	return domainLB.ToPb(), nil
+	return pbconv.LogicalBridgeToPb(domainLB), nil
 }
 
-func (s *Server) deleteLogicalBridge(name string) error {
+func (s *Server) deleteLogicalBridge(name string, opts ...infradb.WriteOption) error {
 	// Note: The status of the object will be generated in infraDB operation not here
-	if err := infradb.DeleteLB(name); err != nil {
+	if err := infradb.DeleteLB(name, opts...); err != nil {
 		return err
 	}
 	return nil
@@ -63,7 +65,7 @@ func (s *Server) getLogicalBridge(name string) (*pb.LogicalBridge, error) {
 	if err != nil {
 		return nil, err
 	}
-	return domainLB.ToPb(), nil
+	return pbconv.LogicalBridgeToPb(domainLB), nil
 }
 
 func (s *Server) getAllLogicalBridges() ([]*pb.LogicalBridge, error) {
@@ -74,27 +76,45 @@ func (s *Server) getAllLogicalBridges() ([]*pb.LogicalBridge, error) {
 	}
 
 	for _, domainLB := range domainLBs {
-		lbs = append(lbs, domainLB.ToPb())
+		lbs = append(lbs, pbconv.LogicalBridgeToPb(domainLB))
 	}
 	return lbs, nil
 }
 
-func (s *Server) updateLogicalBridge(lb *pb.LogicalBridge) (*pb.LogicalBridge, error) {
+func (s *Server) updateLogicalBridge(lb *pb.LogicalBridge, opts ...infradb.WriteOption) (*pb.LogicalBridge, error) {
 	// check parameters
 	if err := s.validateLogicalBridgeSpec(lb); err != nil {
 		return nil, err
 	}
 
 	// translation of pb to domain object
-	domainLB, err := infradb.NewLogicalBridge(lb)
+	domainLB, err := pbconv.LogicalBridgeFromPb(lb)
 	if err != nil {
 		return nil, err
 	}
 	// Note: The status of the object will be generated in infraDB operation not here
-	if err := infradb.UpdateLB(domainLB); err != nil {
+	if err := infradb.UpdateLB(domainLB, opts...); err != nil {
 		return nil, err
 	}
-	return domainLB.ToPb(), nil
+	return pbconv.LogicalBridgeToPb(domainLB), nil
+}
+
+// sameLogicalBridgeSpec tells whether the create request replays the creation of the existing Logical Bridge.
+// The spec of the request is compared in its stored form, a VLAN ID or a VNI left to the pools matches the
+// allocated one.
+func (s *Server) sameLogicalBridgeSpec(lb *pb.LogicalBridge, existing *pb.LogicalBridge) bool {
+	domainLB, err := pbconv.LogicalBridgeFromPb(lb)
+	if err != nil {
+		return false
+	}
+	spec := pbconv.LogicalBridgeToPb(domainLB).Spec
+	if spec.VlanId == 0 {
+		spec.VlanId = existing.Spec.VlanId
+	}
+	if spec.Vni == nil || *spec.Vni == 0 {
+		spec.Vni = existing.Spec.Vni
+	}
+	return proto.Equal(spec, existing.Spec)
 }
 
 func resourceIDToFullName(resourceID string) string {
@@ -138,7 +158,7 @@ func (s *Server) TestCreateLogicalBridge(lb *pb.LogicalBridge) (*pb.LogicalBridg
 	}
 
 	// translation of pb to domain object
-	domainLB, err := infradb.NewLogicalBridge(lb)
+	domainLB, err := pbconv.LogicalBridgeFromPb(lb)
 	if err != nil {
 		return nil, err
 	}
@@ -146,7 +166,7 @@ func (s *Server) TestCreateLogicalBridge(lb *pb.LogicalBridge) (*pb.LogicalBridg
 	if err := infradb.CreateLB(domainLB); err != nil {
 		return nil, err
 	}
-	return domainLB.ToPb(), nil
+	return pbconv.LogicalBridgeToPb(domainLB), nil
 }
 
 func newTestEnv(ctx context.Context, t *testing.T) *testEnv {
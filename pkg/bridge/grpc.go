@@ -15,6 +15,7 @@ import (
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"go.einride.tech/aip/resourceid"
@@ -24,12 +25,16 @@ import (
 )
 
 // CreateLogicalBridge executes the creation of the LogicalBridge
-func (s *Server) CreateLogicalBridge(_ context.Context, in *pb.CreateLogicalBridgeRequest) (*pb.LogicalBridge, error) {
+func (s *Server) CreateLogicalBridge(ctx context.Context, in *pb.CreateLogicalBridgeRequest) (*pb.LogicalBridge, error) {
 	// check input correctness
 	if err := s.validateCreateLogicalBridgeRequest(in); err != nil {
 		log.Printf("CreateLogicalBridge(): validation failure: %v", err)
 		return nil, err
 	}
+	if err := utils.ContextError(ctx); err != nil {
+		log.Printf("CreateLogicalBridge(): %v", err)
+		return nil, err
+	}
 
 	// see https://google.aip.dev/133#user-specified-ids
 	resourceID := resourceid.NewSystemGenerated()
@@ -38,7 +43,7 @@ func (s *Server) CreateLogicalBridge(_ context.Context, in *pb.CreateLogicalBrid
 		resourceID = in.LogicalBridgeId
 	}
 	in.LogicalBridge.Name = resourceIDToFullName(resourceID)
-	// idempotent API when called with same key, should return same object
+	// idempotent API when called with same key and spec, should return same object
 	lbObj, err := s.getLogicalBridge(in.LogicalBridge.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
@@ -46,6 +51,15 @@ func (s *Server) CreateLogicalBridge(_ context.Context, in *pb.CreateLogicalBrid
 			return nil, err
 		}
 	} else {
+		if err := s.validateLogicalBridgeSpec(in.LogicalBridge); err != nil {
+			log.Printf("CreateLogicalBridge(): validation failure: %v", err)
+			return nil, err
+		}
+		if !s.sameLogicalBridgeSpec(in.LogicalBridge, lbObj) {
+			err := apierrors.AlreadyExists("logicalBridges", in.LogicalBridge.Name, "%s already exists with another spec", in.LogicalBridge.Name)
+			log.Printf("CreateLogicalBridge(): %v", err)
+			return nil, err
+		}
 		log.Printf("CreateLogicalBridge(): Already existing LogicalBridge with id %v", in.LogicalBridge.Name)
 		return lbObj, nil
 	}
@@ -60,12 +74,16 @@ func (s *Server) CreateLogicalBridge(_ context.Context, in *pb.CreateLogicalBrid
 }
 
 // DeleteLogicalBridge deletes a LogicalBridge
-func (s *Server) DeleteLogicalBridge(_ context.Context, in *pb.DeleteLogicalBridgeRequest) (*emptypb.Empty, error) {
+func (s *Server) DeleteLogicalBridge(ctx context.Context, in *pb.DeleteLogicalBridgeRequest) (*emptypb.Empty, error) {
 	// check input correctness
 	if err := s.validateDeleteLogicalBridgeRequest(in); err != nil {
 		log.Printf("DeleteLogicalBridge(): validation failure: %v", err)
 		return nil, err
 	}
+	if err := utils.ContextError(ctx); err != nil {
+		log.Printf("DeleteLogicalBridge(): %v", err)
+		return nil, err
+	}
 	// fetch object from the database
 	_, err := s.getLogicalBridge(in.Name)
 	if err != nil {
@@ -74,14 +92,14 @@ func (s *Server) DeleteLogicalBridge(_ context.Context, in *pb.DeleteLogicalBrid
 			return nil, err
 		}
 		if !in.AllowMissing {
-			err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+			err = apierrors.NotFound("logicalBridges", in.Name)
 			log.Printf("DeleteLogicalBridge(): LogicalBridge with id %v: Not Found %v", in.Name, err)
 			return nil, err
 		}
 		return &emptypb.Empty{}, nil
 	}
 
-	if err := s.deleteLogicalBridge(in.Name); err != nil {
+	if err := s.deleteLogicalBridge(in.Name, infradb.IfMatch(utils.IfMatch(ctx))); err != nil {
 		log.Printf("DeleteLogicalBridge(): LogicalBridge with id %v, Delete Logical Bridge from DB failure: %v", in.Name, err)
 		return nil, err
 	}
@@ -90,12 +108,16 @@ func (s *Server) DeleteLogicalBridge(_ context.Context, in *pb.DeleteLogicalBrid
 }
 
 // UpdateLogicalBridge updates a LogicalBridge
-func (s *Server) UpdateLogicalBridge(_ context.Context, in *pb.UpdateLogicalBridgeRequest) (*pb.LogicalBridge, error) {
+func (s *Server) UpdateLogicalBridge(ctx context.Context, in *pb.UpdateLogicalBridgeRequest) (*pb.LogicalBridge, error) {
 	// check input correctness
 	if err := s.validateUpdateLogicalBridgeRequest(in); err != nil {
 		log.Printf("UpdateLogicalBridge(): validation failure: %v", err)
 		return nil, err
 	}
+	if err := utils.ContextError(ctx); err != nil {
+		log.Printf("UpdateLogicalBridge(): %v", err)
+		return nil, err
+	}
 
 	// fetch object from the database
 	lbObj, err := s.getLogicalBridge(in.LogicalBridge.Name)
@@ -105,7 +127,7 @@ func (s *Server) UpdateLogicalBridge(_ context.Context, in *pb.UpdateLogicalBrid
 			return nil, err
 		}
 		if !in.AllowMissing {
-			err = status.Errorf(codes.NotFound, "unable to find key %s", in.LogicalBridge.Name)
+			err = apierrors.NotFound("logicalBridges", in.LogicalBridge.Name)
 			log.Printf("UpdateLogicalBridge(): LogicalBridge with id %v: Not Found %v", in.LogicalBridge.Name, err)
 			return nil, err
 		}
@@ -134,13 +156,21 @@ func (s *Server) UpdateLogicalBridge(_ context.Context, in *pb.UpdateLogicalBrid
 	// Apply updateMask to the current Pb object
 	utils.ApplyMaskToStoredPbObject(in.UpdateMask, updatedlbObj, in.LogicalBridge)
 
+	// The zero VNI and VLAN ID allocate them on create only, an update keeps the ones in use
+	if updatedlbObj.Spec.Vni != nil && *updatedlbObj.Spec.Vni == 0 {
+		updatedlbObj.Spec.Vni = lbObj.Spec.Vni
+	}
+	if updatedlbObj.Spec.VlanId == 0 {
+		updatedlbObj.Spec.VlanId = lbObj.Spec.VlanId
+	}
+
 	// Check if the object before the application of the field mask
 	// is different with the one after the application of the field mask
 	if reflect.DeepEqual(lbObj, updatedlbObj) {
 		return lbObj, nil
 	}
 
-	response, err := s.updateLogicalBridge(updatedlbObj)
+	response, err := s.updateLogicalBridge(updatedlbObj, infradb.IfMatch(utils.IfMatch(ctx)))
 	if err != nil {
 		log.Printf("UpdateLogicalBridge(): LogicalBridge with id %v, Update Logical Bridge to DB failure: %v", in.LogicalBridge.Name, err)
 		return nil, err
@@ -163,7 +193,7 @@ func (s *Server) GetLogicalBridge(_ context.Context, in *pb.GetLogicalBridgeRequ
 			log.Printf("Failed to interact with store: %v", err)
 			return nil, err
 		}
-		err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+		err = apierrors.NotFound("logicalBridges", in.Name)
 		log.Printf("GetLogicalBridge(): LogicalBridge with id %v: Not Found %v", in.Name, err)
 		return nil, err
 	}
@@ -179,7 +209,9 @@ func (s *Server) ListLogicalBridges(_ context.Context, in *pb.ListLogicalBridges
 		return nil, err
 	}
 	// fetch pagination from the database, calculate size and offset
+	s.paginationLock.Lock()
 	size, offset, err := utils.ExtractPagination(in.PageSize, in.PageToken, s.Pagination)
+	s.paginationLock.Unlock()
 	if err != nil {
 		return nil, err
 	}
@@ -201,7 +233,9 @@ func (s *Server) ListLogicalBridges(_ context.Context, in *pb.ListLogicalBridges
 	token := ""
 	if hasMoreElements {
 		token = uuid.New().String()
+		s.paginationLock.Lock()
 		s.Pagination[token] = offset + size
+		s.paginationLock.Unlock()
 	}
 	return &pb.ListLogicalBridgesResponse{LogicalBridges: Blobarray, NextPageToken: token}, nil
 }
@@ -7,6 +7,8 @@
 package bridge
 
 import (
+	"sync"
+
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 
@@ -17,7 +19,9 @@ import (
 type Server struct {
 	pb.UnimplementedLogicalBridgeServiceServer
 	Pagination map[string]int
-	tracer     trace.Tracer
+	// paginationLock guards Pagination, the List calls run concurrently
+	paginationLock sync.Mutex
+	tracer         trace.Tracer
 }
 
 // NewServer creates initialized instance of EVPN server
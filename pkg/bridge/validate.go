@@ -7,22 +7,21 @@
 package bridge
 
 import (
-	"fmt"
-
 	"go.einride.tech/aip/fieldbehavior"
 	"go.einride.tech/aip/fieldmask"
 	"go.einride.tech/aip/resourceid"
 	"go.einride.tech/aip/resourcename"
 
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 )
 
 func (s *Server) validateCreateLogicalBridgeRequest(in *pb.CreateLogicalBridgeRequest) error {
 	// check required fields
-	if err := fieldbehavior.ValidateRequiredFields(in); err != nil {
+	if err := utils.ValidateRequiredFields(in); err != nil {
 		return err
 	}
 
@@ -36,19 +35,24 @@ func (s *Server) validateCreateLogicalBridgeRequest(in *pb.CreateLogicalBridgeRe
 }
 
 func (s *Server) validateLogicalBridgeSpec(lb *pb.LogicalBridge) error {
-	// check vlan id is in range
-	if lb.Spec.VlanId < 1 || lb.Spec.VlanId > 4095 {
-		msg := fmt.Sprintf("VlanId value (%d) have to be between 1 and 4095", lb.Spec.VlanId)
-		return status.Errorf(codes.InvalidArgument, msg)
+	// check vlan id is in range, a zero vlan id is allocated from the vlan pool
+	if lb.Spec.VlanId > 4094 {
+		return apierrors.InvalidField("logical_bridge.spec.vlan_id", apierrors.ReasonOutOfRange,
+			"VlanId value (%d) have to be between 0 and 4094", lb.Spec.VlanId)
 	}
 
-	// check vni is in range
-	if (lb.Spec.Vni != nil) && (*lb.Spec.Vni < 1 || *lb.Spec.Vni > 16777215) {
-		msg := fmt.Sprintf("Vni value (%d) have to be between 1 and 16777215", *lb.Spec.Vni)
-		return status.Errorf(codes.InvalidArgument, msg)
+	// check vni is in range, a zero vni is allocated from the vni pool
+	if (lb.Spec.Vni != nil) && (*lb.Spec.Vni > 16777215) {
+		return apierrors.InvalidField("logical_bridge.spec.vni", apierrors.ReasonOutOfRange,
+			"Vni value (%d) have to be between 0 and 16777215", *lb.Spec.Vni)
 	}
 
-	// Dimitris: Should I validate the vtep_ip_prefix ?
+	if lb.Spec.VtepIpPrefix != nil {
+		if _, err := common.ConvertToIPNet(lb.Spec.VtepIpPrefix); err != nil {
+			return apierrors.InvalidField("logical_bridge.spec.vtep_ip_prefix", apierrors.ReasonInvalidAddress,
+				"Invalid vtep_ip_prefix: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -63,7 +67,7 @@ func (s *Server) validateDeleteLogicalBridgeRequest(in *pb.DeleteLogicalBridgeRe
 
 func (s *Server) validateUpdateLogicalBridgeRequest(in *pb.UpdateLogicalBridgeRequest) error {
 	// check required fields
-	if err := fieldbehavior.ValidateRequiredFields(in); err != nil {
+	if err := utils.ValidateRequiredFields(in); err != nil {
 		return err
 	}
 
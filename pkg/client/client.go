@@ -0,0 +1,389 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package client is the Go client of the bridge gRPC API
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults of the retries and of the polling of the wait helpers
+const (
+	defaultRetries      = 3
+	defaultBackoff      = 200 * time.Millisecond
+	defaultPollInterval = 500 * time.Millisecond
+)
+
+// Client calls the services of the bridge. The calls which fail with Unavailable, i.e. which
+// have not reached the bridge, are retried with an exponential backoff.
+type Client struct {
+	vrfs    pb.VrfServiceClient
+	bridges pb.LogicalBridgeServiceClient
+	svis    pb.SviServiceClient
+	ports   pb.BridgePortServiceClient
+
+	retries      int
+	backoff      time.Duration
+	pollInterval time.Duration
+}
+
+// Option configures the client
+type Option func(*Client)
+
+// WithRetries sets how many times a call which has not reached the bridge is retried, 0 disables the retries
+func WithRetries(retries int) Option {
+	return func(c *Client) { c.retries = retries }
+}
+
+// WithBackoff sets the wait before the first retry, which doubles with every retry
+func WithBackoff(backoff time.Duration) Option {
+	return func(c *Client) { c.backoff = backoff }
+}
+
+// WithPollInterval sets the interval at which the wait helpers get the object
+func WithPollInterval(interval time.Duration) Option {
+	return func(c *Client) { c.pollInterval = interval }
+}
+
+// New returns a client of the bridge on top of the connection
+func New(conn grpc.ClientConnInterface, opts ...Option) *Client {
+	c := &Client{
+		vrfs:         pb.NewVrfServiceClient(conn),
+		bridges:      pb.NewLogicalBridgeServiceClient(conn),
+		svis:         pb.NewSviServiceClient(conn),
+		ports:        pb.NewBridgePortServiceClient(conn),
+		retries:      defaultRetries,
+		backoff:      defaultBackoff,
+		pollInterval: defaultPollInterval,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// retryable tells whether the call has not reached the bridge. ResourceExhausted is not retried
+// as the bridge answers it when a quota is reached.
+func retryable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// do runs the call, retrying it while it does not reach the bridge, and wraps its error
+func (c *Client) do(ctx context.Context, op, name string, call func(ctx context.Context) error) error {
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		err := call(ctx)
+		if err == nil || attempt >= c.retries || !retryable(err) {
+			return newError(op, name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return newError(op, name, err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// call runs a call returning an object
+func call[T any](ctx context.Context, c *Client, op, name string, fn func(ctx context.Context) (T, error)) (T, error) {
+	var out T
+	err := c.do(ctx, op, name, func(ctx context.Context) error {
+		var err error
+		out, err = fn(ctx)
+		return err
+	})
+	return out, err
+}
+
+// listAll walks through all the pages of a list, the bridge answers NotFound when there is no object at all
+func listAll[T any](ctx context.Context, c *Client, op string, list func(ctx context.Context, pageToken string) ([]T, string, error)) ([]T, error) {
+	all := []T{}
+	token := ""
+	for {
+		var objs []T
+		var next string
+		err := c.do(ctx, op, "", func(ctx context.Context) error {
+			var err error
+			objs, next, err = list(ctx, token)
+			return err
+		})
+		if errors.Is(err, ErrNotFound) {
+			return all, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, objs...)
+		if next == "" {
+			return all, nil
+		}
+		token = next
+	}
+}
+
+// waitFor gets the object until done tells that it has reached the awaited state
+func waitFor[T any](ctx context.Context, c *Client, get func(ctx context.Context) (T, error), done func(T, error) (bool, error)) error {
+	for {
+		obj, err := get(ctx)
+		if ok, err := done(obj, err); ok || err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
+
+// untilUp waits for the operational status to be up
+func untilUp[T any](up func(T) bool) func(T, error) (bool, error) {
+	return func(obj T, err error) (bool, error) {
+		if err != nil {
+			return false, err
+		}
+		return up(obj), nil
+	}
+}
+
+// untilGone waits for the object to be removed
+func untilGone[T any](_ T, err error) (bool, error) {
+	if errors.Is(err, ErrNotFound) {
+		return true, nil
+	}
+	return false, err
+}
+
+// CreateVrf creates the vrf with the given id
+func (c *Client) CreateVrf(ctx context.Context, id string, spec *pb.VrfSpec) (*pb.Vrf, error) {
+	return call(ctx, c, "CreateVrf", FullName(CollectionVrfs, id), func(ctx context.Context) (*pb.Vrf, error) {
+		return c.vrfs.CreateVrf(ctx, &pb.CreateVrfRequest{VrfId: id, Vrf: &pb.Vrf{Spec: spec}})
+	})
+}
+
+// GetVrf gets the vrf, the id can be given with or without its collection
+func (c *Client) GetVrf(ctx context.Context, id string) (*pb.Vrf, error) {
+	name := FullName(CollectionVrfs, id)
+	return call(ctx, c, "GetVrf", name, func(ctx context.Context) (*pb.Vrf, error) {
+		return c.vrfs.GetVrf(ctx, &pb.GetVrfRequest{Name: name})
+	})
+}
+
+// ListVrfs lists all the vrfs
+func (c *Client) ListVrfs(ctx context.Context) ([]*pb.Vrf, error) {
+	return listAll(ctx, c, "ListVrfs", func(ctx context.Context, pageToken string) ([]*pb.Vrf, string, error) {
+		resp, err := c.vrfs.ListVrfs(ctx, &pb.ListVrfsRequest{PageToken: pageToken})
+		return resp.GetVrfs(), resp.GetNextPageToken(), err
+	})
+}
+
+// UpdateVrf replaces the spec of the vrf
+func (c *Client) UpdateVrf(ctx context.Context, vrf *pb.Vrf) (*pb.Vrf, error) {
+	return call(ctx, c, "UpdateVrf", vrf.GetName(), func(ctx context.Context) (*pb.Vrf, error) {
+		return c.vrfs.UpdateVrf(ctx, &pb.UpdateVrfRequest{Vrf: vrf})
+	})
+}
+
+// DeleteVrf deletes the vrf, which the bridge removes once it has been torn down
+func (c *Client) DeleteVrf(ctx context.Context, id string, allowMissing bool) error {
+	name := FullName(CollectionVrfs, id)
+	return c.do(ctx, "DeleteVrf", name, func(ctx context.Context) error {
+		_, err := c.vrfs.DeleteVrf(ctx, &pb.DeleteVrfRequest{Name: name, AllowMissing: allowMissing})
+		return err
+	})
+}
+
+// WaitVrfReady waits until the bridge has programmed the vrf
+func (c *Client) WaitVrfReady(ctx context.Context, id string) (*pb.Vrf, error) {
+	var vrf *pb.Vrf
+	err := waitFor(ctx, c, func(ctx context.Context) (*pb.Vrf, error) {
+		var err error
+		vrf, err = c.GetVrf(ctx, id)
+		return vrf, err
+	}, untilUp(func(in *pb.Vrf) bool { return in.GetStatus().GetOperStatus() == pb.VRFOperStatus_VRF_OPER_STATUS_UP }))
+	return vrf, err
+}
+
+// WaitVrfDeleted waits until the bridge has removed the vrf
+func (c *Client) WaitVrfDeleted(ctx context.Context, id string) error {
+	return waitFor(ctx, c, func(ctx context.Context) (*pb.Vrf, error) { return c.GetVrf(ctx, id) }, untilGone[*pb.Vrf])
+}
+
+// CreateLogicalBridge creates the logical bridge with the given id
+func (c *Client) CreateLogicalBridge(ctx context.Context, id string, spec *pb.LogicalBridgeSpec) (*pb.LogicalBridge, error) {
+	return call(ctx, c, "CreateLogicalBridge", FullName(CollectionBridges, id), func(ctx context.Context) (*pb.LogicalBridge, error) {
+		return c.bridges.CreateLogicalBridge(ctx, &pb.CreateLogicalBridgeRequest{LogicalBridgeId: id, LogicalBridge: &pb.LogicalBridge{Spec: spec}})
+	})
+}
+
+// GetLogicalBridge gets the logical bridge, the id can be given with or without its collection
+func (c *Client) GetLogicalBridge(ctx context.Context, id string) (*pb.LogicalBridge, error) {
+	name := FullName(CollectionBridges, id)
+	return call(ctx, c, "GetLogicalBridge", name, func(ctx context.Context) (*pb.LogicalBridge, error) {
+		return c.bridges.GetLogicalBridge(ctx, &pb.GetLogicalBridgeRequest{Name: name})
+	})
+}
+
+// ListLogicalBridges lists all the logical bridges
+func (c *Client) ListLogicalBridges(ctx context.Context) ([]*pb.LogicalBridge, error) {
+	return listAll(ctx, c, "ListLogicalBridges", func(ctx context.Context, pageToken string) ([]*pb.LogicalBridge, string, error) {
+		resp, err := c.bridges.ListLogicalBridges(ctx, &pb.ListLogicalBridgesRequest{PageToken: pageToken})
+		return resp.GetLogicalBridges(), resp.GetNextPageToken(), err
+	})
+}
+
+// UpdateLogicalBridge replaces the spec of the logical bridge
+func (c *Client) UpdateLogicalBridge(ctx context.Context, lb *pb.LogicalBridge) (*pb.LogicalBridge, error) {
+	return call(ctx, c, "UpdateLogicalBridge", lb.GetName(), func(ctx context.Context) (*pb.LogicalBridge, error) {
+		return c.bridges.UpdateLogicalBridge(ctx, &pb.UpdateLogicalBridgeRequest{LogicalBridge: lb})
+	})
+}
+
+// DeleteLogicalBridge deletes the logical bridge, which the bridge removes once it has been torn down
+func (c *Client) DeleteLogicalBridge(ctx context.Context, id string, allowMissing bool) error {
+	name := FullName(CollectionBridges, id)
+	return c.do(ctx, "DeleteLogicalBridge", name, func(ctx context.Context) error {
+		_, err := c.bridges.DeleteLogicalBridge(ctx, &pb.DeleteLogicalBridgeRequest{Name: name, AllowMissing: allowMissing})
+		return err
+	})
+}
+
+// WaitLogicalBridgeReady waits until the bridge has programmed the logical bridge
+func (c *Client) WaitLogicalBridgeReady(ctx context.Context, id string) (*pb.LogicalBridge, error) {
+	var lb *pb.LogicalBridge
+	err := waitFor(ctx, c, func(ctx context.Context) (*pb.LogicalBridge, error) {
+		var err error
+		lb, err = c.GetLogicalBridge(ctx, id)
+		return lb, err
+	}, untilUp(func(in *pb.LogicalBridge) bool {
+		return in.GetStatus().GetOperStatus() == pb.LBOperStatus_LB_OPER_STATUS_UP
+	}))
+	return lb, err
+}
+
+// WaitLogicalBridgeDeleted waits until the bridge has removed the logical bridge
+func (c *Client) WaitLogicalBridgeDeleted(ctx context.Context, id string) error {
+	return waitFor(ctx, c, func(ctx context.Context) (*pb.LogicalBridge, error) { return c.GetLogicalBridge(ctx, id) }, untilGone[*pb.LogicalBridge])
+}
+
+// CreateSvi creates the svi with the given id
+func (c *Client) CreateSvi(ctx context.Context, id string, spec *pb.SviSpec) (*pb.Svi, error) {
+	return call(ctx, c, "CreateSvi", FullName(CollectionSvis, id), func(ctx context.Context) (*pb.Svi, error) {
+		return c.svis.CreateSvi(ctx, &pb.CreateSviRequest{SviId: id, Svi: &pb.Svi{Spec: spec}})
+	})
+}
+
+// GetSvi gets the svi, the id can be given with or without its collection
+func (c *Client) GetSvi(ctx context.Context, id string) (*pb.Svi, error) {
+	name := FullName(CollectionSvis, id)
+	return call(ctx, c, "GetSvi", name, func(ctx context.Context) (*pb.Svi, error) {
+		return c.svis.GetSvi(ctx, &pb.GetSviRequest{Name: name})
+	})
+}
+
+// ListSvis lists all the svis
+func (c *Client) ListSvis(ctx context.Context) ([]*pb.Svi, error) {
+	return listAll(ctx, c, "ListSvis", func(ctx context.Context, pageToken string) ([]*pb.Svi, string, error) {
+		resp, err := c.svis.ListSvis(ctx, &pb.ListSvisRequest{PageToken: pageToken})
+		return resp.GetSvis(), resp.GetNextPageToken(), err
+	})
+}
+
+// UpdateSvi replaces the spec of the svi
+func (c *Client) UpdateSvi(ctx context.Context, svi *pb.Svi) (*pb.Svi, error) {
+	return call(ctx, c, "UpdateSvi", svi.GetName(), func(ctx context.Context) (*pb.Svi, error) {
+		return c.svis.UpdateSvi(ctx, &pb.UpdateSviRequest{Svi: svi})
+	})
+}
+
+// DeleteSvi deletes the svi, which the bridge removes once it has been torn down
+func (c *Client) DeleteSvi(ctx context.Context, id string, allowMissing bool) error {
+	name := FullName(CollectionSvis, id)
+	return c.do(ctx, "DeleteSvi", name, func(ctx context.Context) error {
+		_, err := c.svis.DeleteSvi(ctx, &pb.DeleteSviRequest{Name: name, AllowMissing: allowMissing})
+		return err
+	})
+}
+
+// WaitSviReady waits until the bridge has programmed the svi
+func (c *Client) WaitSviReady(ctx context.Context, id string) (*pb.Svi, error) {
+	var svi *pb.Svi
+	err := waitFor(ctx, c, func(ctx context.Context) (*pb.Svi, error) {
+		var err error
+		svi, err = c.GetSvi(ctx, id)
+		return svi, err
+	}, untilUp(func(in *pb.Svi) bool { return in.GetStatus().GetOperStatus() == pb.SVIOperStatus_SVI_OPER_STATUS_UP }))
+	return svi, err
+}
+
+// WaitSviDeleted waits until the bridge has removed the svi
+func (c *Client) WaitSviDeleted(ctx context.Context, id string) error {
+	return waitFor(ctx, c, func(ctx context.Context) (*pb.Svi, error) { return c.GetSvi(ctx, id) }, untilGone[*pb.Svi])
+}
+
+// CreateBridgePort creates the bridge port with the given id
+func (c *Client) CreateBridgePort(ctx context.Context, id string, spec *pb.BridgePortSpec) (*pb.BridgePort, error) {
+	return call(ctx, c, "CreateBridgePort", FullName(CollectionPorts, id), func(ctx context.Context) (*pb.BridgePort, error) {
+		return c.ports.CreateBridgePort(ctx, &pb.CreateBridgePortRequest{BridgePortId: id, BridgePort: &pb.BridgePort{Spec: spec}})
+	})
+}
+
+// GetBridgePort gets the bridge port, the id can be given with or without its collection
+func (c *Client) GetBridgePort(ctx context.Context, id string) (*pb.BridgePort, error) {
+	name := FullName(CollectionPorts, id)
+	return call(ctx, c, "GetBridgePort", name, func(ctx context.Context) (*pb.BridgePort, error) {
+		return c.ports.GetBridgePort(ctx, &pb.GetBridgePortRequest{Name: name})
+	})
+}
+
+// ListBridgePorts lists all the bridge ports
+func (c *Client) ListBridgePorts(ctx context.Context) ([]*pb.BridgePort, error) {
+	return listAll(ctx, c, "ListBridgePorts", func(ctx context.Context, pageToken string) ([]*pb.BridgePort, string, error) {
+		resp, err := c.ports.ListBridgePorts(ctx, &pb.ListBridgePortsRequest{PageToken: pageToken})
+		return resp.GetBridgePorts(), resp.GetNextPageToken(), err
+	})
+}
+
+// UpdateBridgePort replaces the spec of the bridge port
+func (c *Client) UpdateBridgePort(ctx context.Context, bp *pb.BridgePort) (*pb.BridgePort, error) {
+	return call(ctx, c, "UpdateBridgePort", bp.GetName(), func(ctx context.Context) (*pb.BridgePort, error) {
+		return c.ports.UpdateBridgePort(ctx, &pb.UpdateBridgePortRequest{BridgePort: bp})
+	})
+}
+
+// DeleteBridgePort deletes the bridge port, which the bridge removes once it has been torn down
+func (c *Client) DeleteBridgePort(ctx context.Context, id string, allowMissing bool) error {
+	name := FullName(CollectionPorts, id)
+	return c.do(ctx, "DeleteBridgePort", name, func(ctx context.Context) error {
+		_, err := c.ports.DeleteBridgePort(ctx, &pb.DeleteBridgePortRequest{Name: name, AllowMissing: allowMissing})
+		return err
+	})
+}
+
+// WaitBridgePortReady waits until the bridge has programmed the bridge port
+func (c *Client) WaitBridgePortReady(ctx context.Context, id string) (*pb.BridgePort, error) {
+	var bp *pb.BridgePort
+	err := waitFor(ctx, c, func(ctx context.Context) (*pb.BridgePort, error) {
+		var err error
+		bp, err = c.GetBridgePort(ctx, id)
+		return bp, err
+	}, untilUp(func(in *pb.BridgePort) bool {
+		return in.GetStatus().GetOperStatus() == pb.BPOperStatus_BP_OPER_STATUS_UP
+	}))
+	return bp, err
+}
+
+// WaitBridgePortDeleted waits until the bridge has removed the bridge port
+func (c *Client) WaitBridgePortDeleted(ctx context.Context, id string) error {
+	return waitFor(ctx, c, func(ctx context.Context) (*pb.BridgePort, error) { return c.GetBridgePort(ctx, id) }, untilGone[*pb.BridgePort])
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package client is the Go client of the bridge gRPC API
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/vrf"
+)
+
+// flakyVrfServer fails the first calls with Unavailable
+type flakyVrfServer struct {
+	pb.UnimplementedVrfServiceServer
+	failures int
+	calls    int
+}
+
+func (s *flakyVrfServer) GetVrf(_ context.Context, in *pb.GetVrfRequest) (*pb.Vrf, error) {
+	s.calls++
+	if s.calls <= s.failures {
+		return nil, status.Error(codes.Unavailable, "try again")
+	}
+	return &pb.Vrf{Name: in.Name}, nil
+}
+
+// newTestConn serves the vrf service on a buffered connection
+func newTestConn(t *testing.T, server pb.VrfServiceServer) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	pb.RegisterVrfServiceServer(s, server)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func Test_ParseName(t *testing.T) {
+	tests := map[string]struct {
+		name       string
+		collection string
+		id         string
+		err        bool
+	}{
+		"vrf":               {name: "//network.opiproject.org/vrfs/blue", collection: "vrfs", id: "blue"},
+		"other service":     {name: "//storage.opiproject.org/volumes/blue", err: true},
+		"missing id":        {name: "//network.opiproject.org/vrfs/", err: true},
+		"nested collection": {name: "//network.opiproject.org/tenants/a/vrfs/blue", err: true},
+		"id only":           {name: "blue", err: true},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			collection, id, err := ParseName(tt.name)
+			if (err != nil) != tt.err {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if collection != tt.collection || id != tt.id {
+				t.Errorf("expected %s %s, received %s %s", tt.collection, tt.id, collection, id)
+			}
+			if err == nil && FullName(collection, id) != tt.name {
+				t.Errorf("expected %s, received %s", tt.name, FullName(collection, id))
+			}
+		})
+	}
+	if name := FullName(CollectionVrfs, "//network.opiproject.org/vrfs/blue"); name != "//network.opiproject.org/vrfs/blue" {
+		t.Errorf("the full name has been changed to %s", name)
+	}
+}
+
+func Test_Retries(t *testing.T) {
+	tests := map[string]struct {
+		failures int
+		retries  int
+		calls    int
+		err      error
+	}{
+		"retried":       {failures: 2, retries: 3, calls: 3},
+		"too many":      {failures: 5, retries: 2, calls: 3, err: ErrUnavailable},
+		"retry disable": {failures: 1, retries: 0, calls: 1, err: ErrUnavailable},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			server := &flakyVrfServer{failures: tt.failures}
+			c := New(newTestConn(t, server), WithRetries(tt.retries), WithBackoff(time.Millisecond))
+			obj, err := c.GetVrf(context.Background(), "blue")
+			if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Fatalf("expected error %v, received %v", tt.err, err)
+			}
+			if server.calls != tt.calls {
+				t.Errorf("expected %d calls, received %d", tt.calls, server.calls)
+			}
+			if err == nil && obj.Name != "//network.opiproject.org/vrfs/blue" {
+				t.Errorf("unexpected vrf %v", obj)
+			}
+			if err != nil && status.Code(err) != codes.Unavailable {
+				t.Errorf("the status of %v has been lost", err)
+			}
+		})
+	}
+}
+
+func Test_VrfLifecycle(t *testing.T) {
+	eventbus.EBus.StartSubscriber("dummy", "vrf", 1, nil)
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	c := New(newTestConn(t, vrf.NewServer()), WithPollInterval(time.Millisecond))
+	ctx := context.Background()
+
+	vrfs, err := c.ListVrfs(ctx)
+	if err != nil || len(vrfs) != 0 {
+		t.Fatalf("expected no vrf, received %v %v", vrfs, err)
+	}
+	spec := &pb.VrfSpec{LoopbackIpPrefix: &pc.IPPrefix{Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 167772162}}, Len: 32}}
+	created, err := c.CreateVrf(ctx, "blue", spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Name != "//network.opiproject.org/vrfs/blue" {
+		t.Errorf("unexpected name %s", created.Name)
+	}
+	// the bridge returns the existing object to a repeated create
+	if again, err := c.CreateVrf(ctx, "blue", spec); err != nil || again.Name != created.Name {
+		t.Errorf("unexpected result of a repeated create %v %v", again, err)
+	}
+	if _, err := c.GetVrf(ctx, "green"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, received %v", ErrNotFound, err)
+	}
+	if vrfs, err := c.ListVrfs(ctx); err != nil || len(vrfs) != 1 {
+		t.Errorf("expected one vrf, received %v %v", vrfs, err)
+	}
+
+	// no component programs the vrf in the test
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.WaitVrfReady(waitCtx, created.Name); !errors.Is(err, context.DeadlineExceeded) && status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expected the wait to time out, received %v", err)
+	}
+	if err := c.DeleteVrf(ctx, "green", true); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := c.WaitVrfDeleted(ctx, "green"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package client is the Go client of the bridge gRPC API
+package client
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error is the failure of a call to the bridge. It matches the sentinel errors of its code
+// with errors.Is, and keeps the gRPC status for status.FromError.
+type Error struct {
+	// Op is the call, e.g. CreateVrf
+	Op string
+	// Name is the object of the call, empty for the lists
+	Name    string
+	Code    codes.Code
+	Message string
+}
+
+// The sentinel errors matched by the errors of their code
+var (
+	ErrNotFound           = &Error{Code: codes.NotFound}
+	ErrAlreadyExists      = &Error{Code: codes.AlreadyExists}
+	ErrInvalidArgument    = &Error{Code: codes.InvalidArgument}
+	ErrFailedPrecondition = &Error{Code: codes.FailedPrecondition}
+	ErrResourceExhausted  = &Error{Code: codes.ResourceExhausted}
+	ErrUnavailable        = &Error{Code: codes.Unavailable}
+	ErrPermissionDenied   = &Error{Code: codes.PermissionDenied}
+)
+
+func (e *Error) Error() string {
+	if e.Op == "" {
+		return e.Code.String()
+	}
+	if e.Name == "" {
+		return fmt.Sprintf("%s: %s: %s", e.Op, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s %s: %s: %s", e.Op, e.Name, e.Code, e.Message)
+}
+
+// Is matches the errors of the same code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// GRPCStatus returns the status returned by the bridge
+func (e *Error) GRPCStatus() *status.Status {
+	return status.New(e.Code, e.Message)
+}
+
+// newError wraps the error of a call, the errors which are not a gRPC status are returned unchanged
+func newError(op, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return &Error{Op: op, Name: name, Code: s.Code(), Message: s.Message()}
+}
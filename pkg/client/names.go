@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package client is the Go client of the bridge gRPC API
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"go.einride.tech/aip/resourcename"
+)
+
+// serviceName is the prefix of the names of the objects of the bridge
+const serviceName = "//network.opiproject.org/"
+
+// The collections of the objects of the bridge
+const (
+	CollectionVrfs    = "vrfs"
+	CollectionBridges = "bridges"
+	CollectionSvis    = "svis"
+	CollectionPorts   = "ports"
+)
+
+// FullName returns the name of the object with the given id in the collection,
+// e.g. //network.opiproject.org/vrfs/blue. A full name is returned unchanged.
+func FullName(collection, id string) string {
+	if strings.HasPrefix(id, "//") {
+		return id
+	}
+	return resourcename.Join(serviceName, collection, id)
+}
+
+// ParseName splits the name of an object into its collection and its id
+func ParseName(name string) (collection, id string, err error) {
+	rest, ok := strings.CutPrefix(name, serviceName)
+	if !ok {
+		return "", "", fmt.Errorf("name %q is not in %s", name, serviceName)
+	}
+	collection, id, ok = strings.Cut(rest, "/")
+	if !ok || collection == "" || id == "" || strings.Contains(id, "/") {
+		return "", "", fmt.Errorf("name %q is not of the form %s<collection>/<id>", name, serviceName)
+	}
+	if err := resourcename.Validate(rest); err != nil {
+		return "", "", fmt.Errorf("invalid name %q: %v", name, err)
+	}
+	return collection, id, nil
+}
+
+// ID returns the id of the object, the last segment of its name
+func ID(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package cloud serves the v1alpha1 CloudInfraService VPCs and subnets on top
+// of the EVPN gateway services
+package cloud
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	pb "github.com/opiproject/opi-api/network/cloud/v1alpha1/gen/go"
+	pe "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+)
+
+var (
+	testVni    = uint32(1000)
+	testMac    = []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}
+	testV6Gw   = net.ParseIP("2001:db8::1")
+	testSubnet = &pb.Subnet{
+		Name: "//network.opiproject.org/subnets/web",
+		Spec: &pb.SubnetSpec{
+			VpcNameRef:          "//network.opiproject.org/vpcs/blue",
+			V4Prefix:            &pc.IPv4Prefix{Addr: 0x0a000100, Len: 24},
+			Ipv4VirtualRouterIp: 0x0a000101,
+			V6Prefix:            &pc.IPv6Prefix{Addr: net.ParseIP("2001:db8::"), Len: 64},
+			Ipv6VirtualRouterIp: testV6Gw,
+			VirtualRouterMac:    testMac,
+			AccessEncap: &pc.Encap{
+				Type:  pc.EncapType_ENCAP_TYPE_DOT1Q,
+				Value: &pc.EncapVal{Val: &pc.EncapVal_VlanId{VlanId: 10}},
+			},
+			FabricEncap: &pc.Encap{
+				Type:  pc.EncapType_ENCAP_TYPE_VXLAN,
+				Value: &pc.EncapVal{Val: &pc.EncapVal_Vnid{Vnid: 2010}},
+			},
+		},
+		Status: &pb.SubnetStatus{},
+	}
+)
+
+func Test_VpcTranslation(t *testing.T) {
+	vpc := &pb.Vpc{
+		Name: "//network.opiproject.org/vpcs/blue",
+		Spec: &pb.VpcSpec{
+			Type: pb.VPCType_VPC_TYPE_TENANT,
+			FabricEncap: &pc.Encap{
+				Type:  pc.EncapType_ENCAP_TYPE_VXLAN,
+				Value: &pc.EncapVal{Val: &pc.EncapVal_Vnid{Vnid: int32(testVni)}},
+			},
+		},
+		Status: &pb.VpcStatus{},
+	}
+	vrf, err := vpcToVrf(vpc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &pe.Vrf{Name: "//network.opiproject.org/vrfs/blue", Spec: &pe.VrfSpec{Vni: &testVni}}
+	if !proto.Equal(vrf, want) {
+		t.Errorf("expected %v, received %v", want, vrf)
+	}
+	if back := vrfToVpc(vrf); !proto.Equal(back, vpc) {
+		t.Errorf("expected %v, received %v", vpc, back)
+	}
+
+	vpc.Spec.Type = pb.VPCType_VPC_TYPE_UNDERLAY
+	if _, err := vpcToVrf(vpc); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected an invalid underlay VPC, received %v", err)
+	}
+}
+
+func Test_SubnetTranslation(t *testing.T) {
+	lb, svi, err := subnetToPb(testSubnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lb.Name != "//network.opiproject.org/bridges/web" || lb.Spec.VlanId != 10 || *lb.Spec.Vni != 2010 {
+		t.Errorf("unexpected logical bridge %v", lb)
+	}
+	if svi.Name != "//network.opiproject.org/svis/web" || svi.Spec.Vrf != "//network.opiproject.org/vrfs/blue" ||
+		svi.Spec.LogicalBridge != lb.Name || len(svi.Spec.GwIpPrefix) != 2 {
+		t.Errorf("unexpected SVI %v", svi)
+	}
+	if back := pbToSubnet(lb, svi); !proto.Equal(back, testSubnet) {
+		t.Errorf("expected %v, received %v", testSubnet, back)
+	}
+
+	missingGw := proto.Clone(testSubnet).(*pb.Subnet)
+	missingGw.Spec.Ipv4VirtualRouterIp = 0
+	if _, _, err := subnetToPb(missingGw); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected a missing virtual router address, received %v", err)
+	}
+}
+
+type fakeBridges struct {
+	pe.UnimplementedLogicalBridgeServiceServer
+	deleted []string
+}
+
+func (f *fakeBridges) CreateLogicalBridge(_ context.Context, in *pe.CreateLogicalBridgeRequest) (*pe.LogicalBridge, error) {
+	return in.LogicalBridge, nil
+}
+
+func (f *fakeBridges) DeleteLogicalBridge(_ context.Context, in *pe.DeleteLogicalBridgeRequest) (*emptypb.Empty, error) {
+	f.deleted = append(f.deleted, in.Name)
+	return &emptypb.Empty{}, nil
+}
+
+type failingSvis struct {
+	pe.UnimplementedSviServiceServer
+}
+
+func (failingSvis) CreateSvi(context.Context, *pe.CreateSviRequest) (*pe.Svi, error) {
+	return nil, errors.New("svi failure")
+}
+
+func Test_CreateSubnetRollback(t *testing.T) {
+	bridges := &fakeBridges{}
+	server := NewServer(nil, bridges, failingSvis{})
+	in := &pb.CreateSubnetRequest{Subnet: proto.Clone(testSubnet).(*pb.Subnet), SubnetId: "web"}
+	if _, err := server.CreateSubnet(context.Background(), in); err == nil {
+		t.Fatal("expected the SVI failure")
+	}
+	if len(bridges.deleted) != 1 || bridges.deleted[0] != "//network.opiproject.org/bridges/web" {
+		t.Errorf("expected the logical bridge to be deleted, deleted %v", bridges.deleted)
+	}
+}
@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package cloud serves the v1alpha1 CloudInfraService VPCs and subnets on top
+// of the EVPN gateway services
+package cloud
+
+import (
+	"context"
+	"log"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	pb "github.com/opiproject/opi-api/network/cloud/v1alpha1/gen/go"
+	pe "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+)
+
+// CreateVpc creates the VRF of a VPC
+func (s *Server) CreateVpc(ctx context.Context, in *pb.CreateVpcRequest) (*pb.Vpc, error) {
+	if in.Vpc == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing required field: vpc")
+	}
+	vrf, err := vpcToVrf(in.Vpc)
+	if err != nil {
+		log.Printf("CreateVpc(): translation failure: %v", err)
+		return nil, err
+	}
+	vrf, err = s.vrfs.CreateVrf(ctx, &pe.CreateVrfRequest{Vrf: vrf, VrfId: in.VpcId})
+	if err != nil {
+		return nil, err
+	}
+	return vrfToVpc(vrf), nil
+}
+
+// DeleteVpc deletes the VRF of a VPC
+func (s *Server) DeleteVpc(ctx context.Context, in *pb.DeleteVpcRequest) (*emptypb.Empty, error) {
+	return s.vrfs.DeleteVrf(ctx, &pe.DeleteVrfRequest{Name: fullName(vrfs, in.Name), AllowMissing: in.AllowMissing})
+}
+
+// UpdateVpc replaces the VRF of a VPC with the translation of the whole VPC,
+// the update mask of the VPC does not apply to the fields of the VRF
+func (s *Server) UpdateVpc(ctx context.Context, in *pb.UpdateVpcRequest) (*pb.Vpc, error) {
+	if in.Vpc == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing required field: vpc")
+	}
+	if in.Vpc.Name == "" {
+		in.Vpc.Name = in.Name
+	}
+	vrf, err := vpcToVrf(in.Vpc)
+	if err != nil {
+		log.Printf("UpdateVpc(): translation failure: %v", err)
+		return nil, err
+	}
+	vrf, err = s.vrfs.UpdateVrf(ctx, &pe.UpdateVrfRequest{Vrf: vrf})
+	if err != nil {
+		return nil, err
+	}
+	return vrfToVpc(vrf), nil
+}
+
+// GetVpc gets the VPC of a VRF
+func (s *Server) GetVpc(ctx context.Context, in *pb.GetVpcRequest) (*pb.Vpc, error) {
+	vrf, err := s.vrfs.GetVrf(ctx, &pe.GetVrfRequest{Name: fullName(vrfs, in.Name)})
+	if err != nil {
+		return nil, err
+	}
+	return vrfToVpc(vrf), nil
+}
+
+// ListVpcs lists the VPCs of the VRFs, a page of VPCs is a page of VRFs
+func (s *Server) ListVpcs(ctx context.Context, in *pb.ListVpcsRequest) (*pb.ListVpcsResponse, error) {
+	resp, err := s.vrfs.ListVrfs(ctx, &pe.ListVrfsRequest{PageSize: in.PageSize, PageToken: in.PageToken})
+	if err != nil {
+		return nil, err
+	}
+	vpcList := make([]*pb.Vpc, 0, len(resp.Vrfs))
+	for _, vrf := range resp.Vrfs {
+		vpcList = append(vpcList, vrfToVpc(vrf))
+	}
+	return &pb.ListVpcsResponse{Vpc: vpcList, NextPageToken: resp.NextPageToken}, nil
+}
+
+// CreateSubnet creates the logical bridge and then the SVI of a subnet, the
+// logical bridge is deleted again when the SVI cannot be created
+func (s *Server) CreateSubnet(ctx context.Context, in *pb.CreateSubnetRequest) (*pb.Subnet, error) {
+	if in.Subnet == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing required field: subnet")
+	}
+	// the logical bridge and the SVI have to share the id, it cannot be left to each server
+	id := resourceid.NewSystemGenerated()
+	if in.SubnetId != "" {
+		id = in.SubnetId
+	}
+	in.Subnet.Name = id
+	lb, svi, err := subnetToPb(in.Subnet)
+	if err != nil {
+		log.Printf("CreateSubnet(): translation failure: %v", err)
+		return nil, err
+	}
+	lb, err = s.bridges.CreateLogicalBridge(ctx, &pe.CreateLogicalBridgeRequest{LogicalBridge: lb, LogicalBridgeId: id})
+	if err != nil {
+		return nil, err
+	}
+	svi, err = s.svis.CreateSvi(ctx, &pe.CreateSviRequest{Svi: svi, SviId: id})
+	if err != nil {
+		if _, delErr := s.bridges.DeleteLogicalBridge(ctx, &pe.DeleteLogicalBridgeRequest{Name: lb.Name}); delErr != nil {
+			log.Printf("CreateSubnet(): failed to delete the logical bridge %v: %v", lb.Name, delErr)
+		}
+		return nil, err
+	}
+	return pbToSubnet(lb, svi), nil
+}
+
+// DeleteSubnet deletes the SVI and then the logical bridge of a subnet
+func (s *Server) DeleteSubnet(ctx context.Context, in *pb.DeleteSubnetRequest) (*emptypb.Empty, error) {
+	svi, err := s.svis.GetSvi(ctx, &pe.GetSviRequest{Name: fullName(svis, in.Name)})
+	if err != nil {
+		if status.Code(err) == codes.NotFound && in.AllowMissing {
+			return &emptypb.Empty{}, nil
+		}
+		return nil, err
+	}
+	if _, err := s.svis.DeleteSvi(ctx, &pe.DeleteSviRequest{Name: svi.Name}); err != nil {
+		return nil, err
+	}
+	return s.bridges.DeleteLogicalBridge(ctx, &pe.DeleteLogicalBridgeRequest{Name: svi.Spec.LogicalBridge, AllowMissing: true})
+}
+
+// UpdateSubnet replaces the logical bridge and the SVI of a subnet with the
+// translation of the whole subnet
+func (s *Server) UpdateSubnet(ctx context.Context, in *pb.UpdateSubnetRequest) (*pb.Subnet, error) {
+	if in.Subnet == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing required field: subnet")
+	}
+	if in.Subnet.Name == "" {
+		in.Subnet.Name = in.Name
+	}
+	lb, svi, err := subnetToPb(in.Subnet)
+	if err != nil {
+		log.Printf("UpdateSubnet(): translation failure: %v", err)
+		return nil, err
+	}
+	lb, err = s.bridges.UpdateLogicalBridge(ctx, &pe.UpdateLogicalBridgeRequest{LogicalBridge: lb})
+	if err != nil {
+		return nil, err
+	}
+	svi, err = s.svis.UpdateSvi(ctx, &pe.UpdateSviRequest{Svi: svi})
+	if err != nil {
+		return nil, err
+	}
+	return pbToSubnet(lb, svi), nil
+}
+
+// GetSubnet gets the subnet of an SVI and its logical bridge
+func (s *Server) GetSubnet(ctx context.Context, in *pb.GetSubnetRequest) (*pb.Subnet, error) {
+	svi, err := s.svis.GetSvi(ctx, &pe.GetSviRequest{Name: fullName(svis, in.Name)})
+	if err != nil {
+		return nil, err
+	}
+	return s.subnet(ctx, svi)
+}
+
+// ListSubnets lists the subnets of the SVIs, a page of subnets is a page of SVIs
+func (s *Server) ListSubnets(ctx context.Context, in *pb.ListSubnetsRequest) (*pb.ListSubnetsResponse, error) {
+	resp, err := s.svis.ListSvis(ctx, &pe.ListSvisRequest{PageSize: in.PageSize, PageToken: in.PageToken})
+	if err != nil {
+		return nil, err
+	}
+	subnetList := make([]*pb.Subnet, 0, len(resp.Svis))
+	for _, svi := range resp.Svis {
+		subnet, err := s.subnet(ctx, svi)
+		if err != nil {
+			return nil, err
+		}
+		subnetList = append(subnetList, subnet)
+	}
+	return &pb.ListSubnetsResponse{Subnet: subnetList, NextPageToken: resp.NextPageToken}, nil
+}
+
+func (s *Server) subnet(ctx context.Context, svi *pe.Svi) (*pb.Subnet, error) {
+	lb, err := s.bridges.GetLogicalBridge(ctx, &pe.GetLogicalBridgeRequest{Name: svi.Spec.LogicalBridge})
+	if err != nil {
+		return nil, err
+	}
+	return pbToSubnet(lb, svi), nil
+}
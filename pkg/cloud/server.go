@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package cloud serves the v1alpha1 CloudInfraService VPCs and subnets on top
+// of the EVPN gateway services, so that clients of the older API keep working
+// while they migrate to the VRF, logical bridge and SVI resources
+package cloud
+
+import (
+	pb "github.com/opiproject/opi-api/network/cloud/v1alpha1/gen/go"
+	pe "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+)
+
+// Server represents the Server object
+type Server struct {
+	pb.UnimplementedCloudInfraServiceServer
+	vrfs    pe.VrfServiceServer
+	bridges pe.LogicalBridgeServiceServer
+	svis    pe.SviServiceServer
+}
+
+// NewServer creates a CloudInfraService server translating into the
+// given EVPN servers, which share the internal model of the resources
+func NewServer(vrfs pe.VrfServiceServer, bridges pe.LogicalBridgeServiceServer, svis pe.SviServiceServer) *Server {
+	return &Server{
+		vrfs:    vrfs,
+		bridges: bridges,
+		svis:    svis,
+	}
+}
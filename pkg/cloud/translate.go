@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package cloud serves the v1alpha1 CloudInfraService VPCs and subnets on top
+// of the EVPN gateway services
+package cloud
+
+import (
+	"net"
+	"path"
+
+	"go.einride.tech/aip/resourcename"
+
+	pb "github.com/opiproject/opi-api/network/cloud/v1alpha1/gen/go"
+	pe "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+// A VPC is a VRF and a subnet is a logical bridge with an SVI, each one keeps
+// the id of the cloud resource in its own collection
+const (
+	vpcs           = "vpcs"
+	subnets        = "subnets"
+	vrfs           = "vrfs"
+	logicalBridges = "bridges"
+	svis           = "svis"
+)
+
+func fullName(collection string, name string) string {
+	return resourcename.Join(
+		"//network.opiproject.org/",
+		collection, path.Base(name),
+	)
+}
+
+// encapValue returns the VLAN or the VNI of an encap, nil when the encap is not set
+func encapValue(encap *pc.Encap, field string, encapType pc.EncapType) (*uint32, error) {
+	if encap == nil || encap.Type == pc.EncapType_ENCAP_TYPE_UNSPECIFIED {
+		return nil, nil
+	}
+	if encap.Type != encapType {
+		return nil, apierrors.InvalidField(field, apierrors.ReasonInvalidField,
+			"Encap %v is not supported, only %v is", encap.Type, encapType)
+	}
+	value := encap.GetValue().GetVlanId()
+	if encapType == pc.EncapType_ENCAP_TYPE_VXLAN {
+		value = encap.GetValue().GetVnid()
+	}
+	if value < 0 {
+		return nil, apierrors.InvalidField(field, apierrors.ReasonOutOfRange, "Encap value %d is negative", value)
+	}
+	v := uint32(value)
+	return &v, nil
+}
+
+func vxlanEncap(vni *uint32) *pc.Encap {
+	if vni == nil {
+		return nil
+	}
+	return &pc.Encap{
+		Type:  pc.EncapType_ENCAP_TYPE_VXLAN,
+		Value: &pc.EncapVal{Val: &pc.EncapVal_Vnid{Vnid: int32(*vni)}},
+	}
+}
+
+// vpcToVrf translates a VPC into the VRF carrying its fabric VNI
+func vpcToVrf(vpc *pb.Vpc) (*pe.Vrf, error) {
+	spec := vpc.GetSpec()
+	if t := spec.GetType(); t != pb.VPCType_VPC_TYPE_UNSPECIFIED && t != pb.VPCType_VPC_TYPE_TENANT {
+		return nil, apierrors.InvalidField("vpc.spec.type", apierrors.ReasonInvalidField,
+			"VPC type %v is not supported, only tenant VPCs are", t)
+	}
+	vni, err := encapValue(spec.GetFabricEncap(), "vpc.spec.fabric_encap", pc.EncapType_ENCAP_TYPE_VXLAN)
+	if err != nil {
+		return nil, err
+	}
+	return &pe.Vrf{
+		Name: fullName(vrfs, vpc.Name),
+		Spec: &pe.VrfSpec{Vni: vni},
+	}, nil
+}
+
+func vrfToVpc(vrf *pe.Vrf) *pb.Vpc {
+	spec := &pb.VpcSpec{Type: pb.VPCType_VPC_TYPE_TENANT}
+	if vrf.Spec != nil {
+		spec.FabricEncap = vxlanEncap(vrf.Spec.Vni)
+	}
+	return &pb.Vpc{
+		Name:   fullName(vpcs, vrf.Name),
+		Spec:   spec,
+		Status: &pb.VpcStatus{},
+	}
+}
+
+// subnetToPb translates a subnet into its logical bridge and the SVI, whose gateway
+// addresses are the virtual router addresses with the length of the subnet prefixes
+func subnetToPb(subnet *pb.Subnet) (*pe.LogicalBridge, *pe.Svi, error) {
+	spec := subnet.GetSpec()
+	if spec.GetVpcNameRef() == "" {
+		return nil, nil, apierrors.InvalidField("subnet.spec.vpc_name_ref", apierrors.ReasonInvalidField,
+			"The VPC of the subnet is missing")
+	}
+	vlan, err := encapValue(spec.GetAccessEncap(), "subnet.spec.access_encap", pc.EncapType_ENCAP_TYPE_DOT1Q)
+	if err != nil {
+		return nil, nil, err
+	}
+	vni, err := encapValue(spec.GetFabricEncap(), "subnet.spec.fabric_encap", pc.EncapType_ENCAP_TYPE_VXLAN)
+	if err != nil {
+		return nil, nil, err
+	}
+	var gateways []*pc.IPPrefix
+	if spec.GetV4Prefix() != nil {
+		if spec.Ipv4VirtualRouterIp == 0 {
+			return nil, nil, apierrors.InvalidField("subnet.spec.ipv4_virtual_router_ip", apierrors.ReasonInvalidAddress,
+				"The IPv4 subnet needs a virtual router address")
+		}
+		gateways = append(gateways, &pc.IPPrefix{
+			Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: spec.Ipv4VirtualRouterIp}},
+			Len:  spec.V4Prefix.Len,
+		})
+	}
+	if spec.GetV6Prefix() != nil {
+		if len(spec.Ipv6VirtualRouterIp) != net.IPv6len {
+			return nil, nil, apierrors.InvalidField("subnet.spec.ipv6_virtual_router_ip", apierrors.ReasonInvalidAddress,
+				"The IPv6 subnet needs a 16 bytes long virtual router address")
+		}
+		gateways = append(gateways, &pc.IPPrefix{
+			Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET6, V4OrV6: &pc.IPAddress_V6Addr{V6Addr: spec.Ipv6VirtualRouterIp}},
+			Len:  spec.V6Prefix.Len,
+		})
+	}
+
+	lb := &pe.LogicalBridge{
+		Name: fullName(logicalBridges, subnet.Name),
+		Spec: &pe.LogicalBridgeSpec{Vni: vni},
+	}
+	if vlan != nil {
+		lb.Spec.VlanId = *vlan
+	}
+	svi := &pe.Svi{
+		Name: fullName(svis, subnet.Name),
+		Spec: &pe.SviSpec{
+			Vrf:           fullName(vrfs, spec.VpcNameRef),
+			LogicalBridge: lb.Name,
+			MacAddress:    spec.VirtualRouterMac,
+			GwIpPrefix:    gateways,
+		},
+	}
+	return lb, svi, nil
+}
+
+func pbToSubnet(lb *pe.LogicalBridge, svi *pe.Svi) *pb.Subnet {
+	spec := &pb.SubnetSpec{
+		VpcNameRef:       fullName(vpcs, svi.GetSpec().GetVrf()),
+		VirtualRouterMac: svi.GetSpec().GetMacAddress(),
+		AccessEncap: &pc.Encap{
+			Type:  pc.EncapType_ENCAP_TYPE_DOT1Q,
+			Value: &pc.EncapVal{Val: &pc.EncapVal_VlanId{VlanId: int32(lb.GetSpec().GetVlanId())}},
+		},
+	}
+	if lb.Spec != nil {
+		spec.FabricEncap = vxlanEncap(lb.Spec.Vni)
+	}
+	for _, gw := range svi.GetSpec().GetGwIpPrefix() {
+		gwNet, err := common.ConvertToDualStackIPNet(gw)
+		if err != nil {
+			continue
+		}
+		if ip4 := gwNet.IP.To4(); ip4 != nil {
+			prefix := common.ConvertToIPPrefix(&net.IPNet{IP: ip4.Mask(gwNet.Mask), Mask: gwNet.Mask})
+			spec.V4Prefix = &pc.IPv4Prefix{Addr: prefix.Addr.GetV4Addr(), Len: gw.Len}
+			spec.Ipv4VirtualRouterIp = gw.Addr.GetV4Addr()
+			continue
+		}
+		spec.V6Prefix = &pc.IPv6Prefix{Addr: gwNet.IP.Mask(gwNet.Mask), Len: gw.Len}
+		spec.Ipv6VirtualRouterIp = gw.Addr.GetV6Addr()
+	}
+	return &pb.Subnet{
+		Name:   fullName(subnets, svi.Name),
+		Spec:   spec,
+		Status: &pb.SubnetStatus{},
+	}
+}
@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package cni
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/skel"
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/plugins/pkg/ip"
+	"github.com/containernetworking/plugins/pkg/ipam"
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/containernetworking/plugins/plugins/ipam/host-local/backend/allocator"
+	"github.com/containernetworking/plugins/plugins/ipam/host-local/backend/disk"
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// bridgeTimeout bounds the calls to the evpn bridge
+const bridgeTimeout = 10 * time.Second
+
+// dial connects to the evpn bridge
+func dial(conf *NetConf) (*grpc.ClientConn, error) {
+	return grpc.Dial(conf.BridgeAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+// allocate reserves an address of the range for the container interface
+func allocate(conf *NetConf, rng *allocator.Range, args *skel.CmdArgs) (*current.IPConfig, error) {
+	store, err := disk.New(conf.Name, conf.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	if err := store.Lock(); err != nil {
+		return nil, err
+	}
+	defer func() { _ = store.Unlock() }()
+	return allocator.NewIPAllocator(&allocator.RangeSet{*rng}, store, 0).Get(args.ContainerID, args.IfName, nil)
+}
+
+// release frees the address of the container interface
+func release(conf *NetConf, args *skel.CmdArgs) error {
+	store, err := disk.New(conf.Name, conf.DataDir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if err := store.Lock(); err != nil {
+		return err
+	}
+	defer func() { _ = store.Unlock() }()
+	return store.ReleaseByID(args.ContainerID, args.IfName)
+}
+
+// CmdAdd creates the veth of the pod, attaches its host side to the logical bridge and assigns an address of the subnet
+func CmdAdd(args *skel.CmdArgs) error {
+	conf, err := loadConf(args.StdinData)
+	if err != nil {
+		return err
+	}
+	conn, err := dial(conf)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), bridgeTimeout)
+	defer cancel()
+
+	lbName := fullName("bridges", conf.LogicalBridge)
+	if _, err := pb.NewLogicalBridgeServiceClient(conn).GetLogicalBridge(ctx, &pb.GetLogicalBridgeRequest{Name: lbName}); err != nil {
+		return fmt.Errorf("failed to get logical bridge %s: %v", conf.LogicalBridge, err)
+	}
+	rng, err := resolveRange(ctx, conf, pb.NewSviServiceClient(conn))
+	if err != nil {
+		return err
+	}
+	ipConf, err := allocate(conf, rng, args)
+	if err != nil {
+		return err
+	}
+	success := false
+	defer func() {
+		if !success {
+			_ = release(conf, args)
+		}
+	}()
+
+	netns, err := ns.GetNS(args.Netns)
+	if err != nil {
+		return fmt.Errorf("failed to open netns %q: %v", args.Netns, err)
+	}
+	defer netns.Close()
+
+	hostName := hostVethName(args.ContainerID, args.IfName)
+	result := &current.Result{CNIVersion: current.ImplementedSpecVersion}
+	ipConf.Interface = current.Int(1)
+	result.IPs = []*current.IPConfig{ipConf}
+	if rng.Gateway != nil {
+		dst := &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}
+		if rng.Gateway.To4() == nil {
+			dst = &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)}
+		}
+		result.Routes = []*types.Route{{Dst: *dst, GW: rng.Gateway}}
+	}
+
+	var contIface net.Interface
+	err = netns.Do(func(hostNS ns.NetNS) error {
+		hostIface, cont, err := ip.SetupVethWithName(args.IfName, hostName, conf.MTU, "", hostNS)
+		if err != nil {
+			return err
+		}
+		contIface = cont
+		result.Interfaces = []*current.Interface{
+			{Name: hostIface.Name, Mac: hostIface.HardwareAddr.String()},
+			{Name: contIface.Name, Mac: contIface.HardwareAddr.String(), Sandbox: args.Netns},
+		}
+		return ipam.ConfigureIface(args.IfName, result)
+	})
+	if err != nil {
+		_ = ip.DelLinkByName(hostName)
+		return err
+	}
+
+	bp := &pb.BridgePort{Spec: &pb.BridgePortSpec{
+		MacAddress:     contIface.HardwareAddr,
+		Ptype:          pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS,
+		LogicalBridges: []string{lbName},
+	}}
+	if _, err := pb.NewBridgePortServiceClient(conn).CreateBridgePort(ctx, &pb.CreateBridgePortRequest{BridgePortId: hostName, BridgePort: bp}); err != nil {
+		_ = ip.DelLinkByName(hostName)
+		return fmt.Errorf("failed to create bridge port %s: %v", hostName, err)
+	}
+
+	success = true
+	return types.PrintResult(result, conf.CNIVersion)
+}
+
+// CmdDel deletes the bridge port of the pod, its veth and releases its address
+func CmdDel(args *skel.CmdArgs) error {
+	conf, err := loadConf(args.StdinData)
+	if err != nil {
+		return err
+	}
+	conn, err := dial(conf)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), bridgeTimeout)
+	defer cancel()
+
+	hostName := hostVethName(args.ContainerID, args.IfName)
+	if _, err := pb.NewBridgePortServiceClient(conn).DeleteBridgePort(ctx, &pb.DeleteBridgePortRequest{Name: fullName("ports", hostName), AllowMissing: true}); err != nil {
+		return fmt.Errorf("failed to delete bridge port %s: %v", hostName, err)
+	}
+	if err := release(conf, args); err != nil {
+		return err
+	}
+	// the bridge deletes the host side veth together with the bridge port, the container side is only left behind on failures
+	if args.Netns != "" {
+		err := ns.WithNetNSPath(args.Netns, func(_ ns.NetNS) error {
+			if err := ip.DelLinkByName(args.IfName); err != nil && !errors.Is(err, ip.ErrLinkNotFound) {
+				return err
+			}
+			return nil
+		})
+		var nsErr ns.NSPathNotExistErr
+		if err != nil && !errors.As(err, &nsErr) {
+			return err
+		}
+	}
+	return nil
+}
+
+// CmdCheck verifies that the bridge port of the pod is attached to the logical bridge
+func CmdCheck(args *skel.CmdArgs) error {
+	conf, err := loadConf(args.StdinData)
+	if err != nil {
+		return err
+	}
+	conn, err := dial(conf)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), bridgeTimeout)
+	defer cancel()
+
+	hostName := hostVethName(args.ContainerID, args.IfName)
+	bp, err := pb.NewBridgePortServiceClient(conn).GetBridgePort(ctx, &pb.GetBridgePortRequest{Name: fullName("ports", hostName)})
+	if err != nil {
+		return fmt.Errorf("failed to get bridge port %s: %v", hostName, err)
+	}
+	lbName := fullName("bridges", conf.LogicalBridge)
+	for _, lb := range bp.GetSpec().GetLogicalBridges() {
+		if lb == lbName {
+			return ns.WithNetNSPath(args.Netns, func(_ ns.NetNS) error {
+				_, err := net.InterfaceByName(args.IfName)
+				return err
+			})
+		}
+	}
+	return fmt.Errorf("bridge port %s is not attached to logical bridge %s", hostName, conf.LogicalBridge)
+}
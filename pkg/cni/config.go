@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package cni attaches pods to the logical bridges of the evpn bridge
+package cni
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/plugins/plugins/ipam/host-local/backend/allocator"
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+	"go.einride.tech/aip/resourcename"
+)
+
+// defaultDataDir holds the address allocations of the networks
+const defaultDataDir = "/var/lib/cni/opi-evpn"
+
+// NetConf is the network configuration of the plugin
+type NetConf struct {
+	types.NetConf
+	// BridgeAddress is the gRPC address of the evpn bridge
+	BridgeAddress string `json:"bridgeAddress"`
+	// LogicalBridge is the id of the logical bridge the pods join
+	LogicalBridge string `json:"logicalBridge"`
+	// Svi is the id of the svi of the logical bridge, its gateway address gives the subnet
+	Svi string `json:"svi,omitempty"`
+	// Subnet and Gateway are used when the logical bridge has no svi
+	Subnet  string `json:"subnet,omitempty"`
+	Gateway string `json:"gateway,omitempty"`
+	MTU     int    `json:"mtu,omitempty"`
+	DataDir string `json:"dataDir,omitempty"`
+}
+
+// loadConf parses and validates the network configuration
+func loadConf(data []byte) (*NetConf, error) {
+	conf := &NetConf{BridgeAddress: "localhost:50151", DataDir: defaultDataDir}
+	if err := json.Unmarshal(data, conf); err != nil {
+		return nil, fmt.Errorf("failed to load netconf: %v", err)
+	}
+	if conf.LogicalBridge == "" {
+		return nil, fmt.Errorf("logicalBridge must be set")
+	}
+	if conf.Svi == "" && conf.Subnet == "" {
+		return nil, fmt.Errorf("either svi or subnet must be set")
+	}
+	return conf, nil
+}
+
+// fullName returns the bridge name of the object with the given id
+func fullName(collection, id string) string {
+	return resourcename.Join("//network.opiproject.org/", collection, id)
+}
+
+// prefixFromPb translates the protobuf representation of a prefix
+func prefixFromPb(p *pc.IPPrefix) *net.IPNet {
+	var ip net.IP
+	if p.GetAddr().GetAf() == pc.IpAf_IP_AF_INET6 {
+		ip = net.IP(p.GetAddr().GetV6Addr())
+	} else {
+		ip = make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, p.GetAddr().GetV4Addr())
+	}
+	bits := 8 * len(ip)
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(int(p.GetLen()), bits)}
+}
+
+// resolveRange returns the address range of the pods, either from the svi of the logical bridge or from the configuration
+func resolveRange(ctx context.Context, conf *NetConf, svis pb.SviServiceClient) (*allocator.Range, error) {
+	rng := &allocator.Range{}
+	if conf.Svi == "" {
+		_, subnet, err := net.ParseCIDR(conf.Subnet)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subnet %s: %v", conf.Subnet, err)
+		}
+		rng.Subnet = types.IPNet(*subnet)
+		if conf.Gateway != "" {
+			rng.Gateway = net.ParseIP(conf.Gateway)
+			if rng.Gateway == nil {
+				return nil, fmt.Errorf("invalid gateway %s", conf.Gateway)
+			}
+		}
+	} else {
+		svi, err := svis.GetSvi(ctx, &pb.GetSviRequest{Name: fullName("svis", conf.Svi)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get svi %s: %v", conf.Svi, err)
+		}
+		if svi.GetSpec().GetLogicalBridge() != fullName("bridges", conf.LogicalBridge) {
+			return nil, fmt.Errorf("svi %s does not belong to logical bridge %s", conf.Svi, conf.LogicalBridge)
+		}
+		if len(svi.GetSpec().GetGwIpPrefix()) == 0 {
+			return nil, fmt.Errorf("svi %s has no gateway address", conf.Svi)
+		}
+		gw := prefixFromPb(svi.GetSpec().GetGwIpPrefix()[0])
+		rng.Gateway = gw.IP
+		rng.Subnet = types.IPNet{IP: gw.IP.Mask(gw.Mask), Mask: gw.Mask}
+	}
+	if err := rng.Canonicalize(); err != nil {
+		return nil, err
+	}
+	return rng, nil
+}
+
+// hostVethName returns the name of the host side veth, which is also the id of the bridge port
+func hostVethName(containerID, ifName string) string {
+	sum := sha256.Sum256([]byte(containerID + ifName))
+	return "cni" + hex.EncodeToString(sum[:])[:12]
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package cni
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/containernetworking/plugins/plugins/ipam/host-local/backend/allocator"
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeSviClient returns the svis of a map
+type fakeSviClient struct {
+	pb.SviServiceClient
+	svis map[string]*pb.Svi
+}
+
+func (f *fakeSviClient) GetSvi(_ context.Context, in *pb.GetSviRequest, _ ...grpc.CallOption) (*pb.Svi, error) {
+	svi, ok := f.svis[in.Name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+	}
+	return svi, nil
+}
+
+func Test_ResolveRange(t *testing.T) {
+	svis := &fakeSviClient{svis: map[string]*pb.Svi{
+		"//network.opiproject.org/svis/blue-web": {Spec: &pb.SviSpec{
+			LogicalBridge: "//network.opiproject.org/bridges/blue-web",
+			GwIpPrefix: []*pc.IPPrefix{{
+				Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 0x0a0a0a01}},
+				Len:  24,
+			}},
+		}},
+	}}
+	tests := map[string]struct {
+		conf    string
+		subnet  string
+		gateway string
+		errMsg  string
+	}{
+		"from svi": {
+			conf:    `{"name": "blue", "logicalBridge": "blue-web", "svi": "blue-web"}`,
+			subnet:  "10.10.10.0/24",
+			gateway: "10.10.10.1",
+		},
+		"from config": {
+			conf:    `{"name": "blue", "logicalBridge": "blue-l2", "subnet": "192.168.1.0/24"}`,
+			subnet:  "192.168.1.0/24",
+			gateway: "192.168.1.1",
+		},
+		"svi of another logical bridge": {
+			conf:   `{"name": "blue", "logicalBridge": "blue-db", "svi": "blue-web"}`,
+			errMsg: "svi blue-web does not belong to logical bridge blue-db",
+		},
+		"unknown svi": {
+			conf:   `{"name": "blue", "logicalBridge": "blue-web", "svi": "red-web"}`,
+			errMsg: "failed to get svi red-web: rpc error: code = NotFound desc = unable to find key //network.opiproject.org/svis/red-web",
+		},
+		"no subnet": {
+			conf:   `{"name": "blue", "logicalBridge": "blue-web"}`,
+			errMsg: "either svi or subnet must be set",
+		},
+		"no logical bridge": {
+			conf:   `{"name": "blue", "subnet": "192.168.1.0/24"}`,
+			errMsg: "logicalBridge must be set",
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			conf, err := loadConf([]byte(tt.conf))
+			if err == nil {
+				var rng *allocator.Range
+				rng, err = resolveRange(context.Background(), conf, svis)
+				if err == nil {
+					subnet := (*net.IPNet)(&rng.Subnet).String()
+					if subnet != tt.subnet || rng.Gateway.String() != tt.gateway {
+						t.Errorf("range = %s gateway %v, want %s gateway %s", subnet, rng.Gateway, tt.subnet, tt.gateway)
+					}
+				}
+			}
+			if tt.errMsg == "" && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tt.errMsg != "" && (err == nil || err.Error() != tt.errMsg) {
+				t.Errorf("error = %v, want %s", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func Test_HostVethName(t *testing.T) {
+	name := hostVethName("0123456789abcdef", "eth0")
+	if len(name) > 15 {
+		t.Errorf("%s is longer than a linux interface name", name)
+	}
+	if err := resourceid.ValidateUserSettable(name); err != nil {
+		t.Errorf("%s is not a valid bridge port id: %v", name, err)
+	}
+	if name == hostVethName("0123456789abcdef", "net1") {
+		t.Errorf("interfaces of the same container share the veth name %s", name)
+	}
+}
@@ -7,10 +7,15 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
 	"log"
 	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -55,6 +60,12 @@ type LinuxFrrConfig struct {
 	DefaultVtep string `yaml:"defaultvtep"`
 	IPMtu       int    `yaml:"ipmtu"`
 	LocalAs     int    `yaml:"localas"`
+	// BridgeTopology is either vlan-aware (one br-tenant) or per-vlan (one bridge per logical bridge)
+	BridgeTopology string `yaml:"bridgetopology"`
+	// FrrAddress is the host of the FRR daemons vty sockets
+	FrrAddress string `yaml:"frraddress"`
+	// Uplink is the underlay device, the vxlan devices are programmed once it exists
+	Uplink string `yaml:"uplink"`
 }
 
 // InterfaceConfig linux frr config structure
@@ -78,22 +89,474 @@ type NetlinkConfig struct {
 	EnableEcmp      bool `yaml:"enableecmp"`
 }
 
+// GarpConfig gratuitous arp / unsolicited na config structure
+type GarpConfig struct {
+	Count    int `yaml:"count"`
+	Interval int `yaml:"interval"`
+}
+
+// QuotasConfig quotas config structure, a zero limit is unlimited
+type QuotasConfig struct {
+	MaxSvisPerVrf     int `yaml:"maxsvispervrf"`
+	MaxPortsPerBridge int `yaml:"maxportsperbridge"`
+	MaxVnis           int `yaml:"maxvnis"`
+}
+
+// VniPoolConfig range of the VNIs given to the LogicalBridges and VRFs created with a zero VNI,
+// no VNI is allocated when Max is zero
+type VniPoolConfig struct {
+	Min uint32 `yaml:"min"`
+	Max uint32 `yaml:"max"`
+}
+
+// VlanPoolConfig range of the local VLAN IDs given to the LogicalBridges created with a zero VLAN ID,
+// the whole 1-4094 range when Max is zero
+type VlanPoolConfig struct {
+	Min uint32 `yaml:"min"`
+	Max uint32 `yaml:"max"`
+}
+
+// VirtualPortsConfig userspace dataplane driver config structure
+type VirtualPortsConfig struct {
+	// Driver is the executable which programs the vhost-user and virtio-user ports, it reads a json request on stdin
+	Driver string `yaml:"driver"`
+	// Timeout bounds the execution of the driver in seconds, 10 seconds when zero
+	Timeout int `yaml:"timeout"`
+}
+
+// StorageConfig storage bridge integration config structure
+type StorageConfig struct {
+	// Enabled lets the storage bridge (opi-spdk-bridge) on the same DPU claim its netdevs
+	Enabled bool `yaml:"enabled"`
+}
+
+// DevlinkConfig devlink config structure
+type DevlinkConfig struct {
+	// OccupancyThreshold is the percentage of a hardware table above which the offloaded objects
+	// are refused, zero disables the check
+	OccupancyThreshold int `yaml:"occupancythreshold"`
+}
+
+// LeasesConfig ephemeral resources config structure
+type LeasesConfig struct {
+	// Interval is the period in seconds of the sweep deleting the resources whose lease has expired, 10 seconds when zero
+	Interval int `yaml:"interval"`
+	// MaxDuration bounds the duration of a lease in seconds, unlimited when zero
+	MaxDuration int `yaml:"maxduration"`
+}
+
+// GatewayConfig svi gateway address config structure
+type GatewayConfig struct {
+	// Policy picks the gateway address of an SVI whose gateway prefix is a network address, e.g. 10.0.10.0/24:
+	// first (10.0.10.1), last (10.0.10.254) or explicit which rejects such a prefix, first when empty
+	Policy string `yaml:"policy"`
+}
+
+// HistoryConfig configuration history config structure
+type HistoryConfig struct {
+	// MaxRevisions bounds the number of revisions kept, the oldest ones being dropped, 1000 when zero
+	MaxRevisions int `yaml:"maxrevisions"`
+}
+
+// MaintenanceConfig maintenance mode config structure, the defaults of the requests entering the maintenance
+type MaintenanceConfig struct {
+	// Mode is how the EVPN routes are drained: withdraw, med or prepend, withdraw when empty
+	Mode string `yaml:"mode"`
+	// DrainTimer is the time in seconds given to the traffic to move away before the svis are brought down
+	DrainTimer int `yaml:"draintimer"`
+	// SviInterval is the time in seconds between bringing down two svis
+	SviInterval int `yaml:"sviinterval"`
+	// SviOrder holds the ids of the svis brought down first, in that order, the others follow in the order of their names
+	SviOrder []string `yaml:"sviorder"`
+}
+
+// InterceptorsConfig gRPC interceptor chain config structure
+type InterceptorsConfig struct {
+	// Chain names the interceptors in the order in which they wrap the calls, the default chain when empty
+	Chain []string `yaml:"chain"`
+	// AuthTokens are the bearer tokens accepted by the auth interceptor
+	AuthTokens []string `yaml:"authtokens"`
+}
+
+// DeadlinesConfig maximum execution times of the gRPC methods in seconds, a zero time is unlimited
+type DeadlinesConfig struct {
+	// Default applies to the methods missing from Methods
+	Default int `yaml:"default"`
+	// Methods is keyed by the method name, e.g. CreateVrf
+	Methods map[string]int `yaml:"methods"`
+}
+
+// GoBgpConfig gobgp routing backend config structure
+type GoBgpConfig struct {
+	// Address is the host:port of the gRPC API of gobgpd
+	Address string `yaml:"address"`
+	// LocalAs is the 2 octet AS number used in the route distinguishers and targets
+	LocalAs int `yaml:"localas"`
+	// PollInterval is the period in seconds of the programming of the received routes
+	PollInterval int `yaml:"pollinterval"`
+	// FlushInterval is the time in milliseconds given to a burst of route updates to settle before they
+	// are programmed together
+	FlushInterval int `yaml:"flushinterval"`
+}
+
+// EcmpConfig multipath config structure of the type-5 routes received from several remote VTEPs
+type EcmpConfig struct {
+	// MaxPaths is the number of paths installed per prefix, zero keeps the default of the routing stack
+	MaxPaths int `yaml:"maxpaths"`
+	// Weighted spreads the traffic in proportion to the BGP link bandwidth extended community of the paths
+	Weighted bool `yaml:"weighted"`
+}
+
+// GracefulRestartConfig bgp graceful restart config structure, the peers keep the routes of the bridge while its
+// routing stack restarts rather than withdrawing its macs and prefixes from the fabric
+type GracefulRestartConfig struct {
+	// Enabled makes the bridge a restarting speaker, it is only a helper of its peers otherwise
+	Enabled bool `yaml:"enabled"`
+	// RestartTime is the time in seconds the peers wait for the sessions to come back, the default of the routing stack when zero
+	RestartTime int `yaml:"restarttime"`
+	// StalePathTime is the time in seconds the paths of a restarting peer are kept, the default of the routing stack when zero
+	StalePathTime int `yaml:"stalepathtime"`
+}
+
+// RoutingConfig routing config structure
+type RoutingConfig struct {
+	// Backend is the name of the routing stack which runs the EVPN control plane, frr when empty
+	Backend         string                `yaml:"backend"`
+	GoBgp           GoBgpConfig           `yaml:"gobgp"`
+	Ecmp            EcmpConfig            `yaml:"ecmp"`
+	GracefulRestart GracefulRestartConfig `yaml:"gracefulrestart"`
+}
+
+// UplinkConfig underlay interface config structure
+type UplinkConfig struct {
+	Name string `yaml:"name"`
+	// Address is the prefix of the interface, e.g. 10.168.1.5/24, the interface is unnumbered when empty
+	Address string `yaml:"address"`
+	// MTU is set on the interface when not zero
+	MTU int `yaml:"mtu"`
+}
+
+// UnderlayPeerConfig underlay bgp session config structure
+type UnderlayPeerConfig struct {
+	// Address is the address of the neighbor, Interface the uplink of an unnumbered session, one of them is set
+	Address   string `yaml:"address"`
+	Interface string `yaml:"interface"`
+	// RemoteAs is the AS number of the neighbor, internal or external
+	RemoteAs string `yaml:"remoteas"`
+}
+
+// UnderlayConfig underlay bootstrap config structure, it brings a factory-fresh node into the fabric
+type UnderlayConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// VtepIP is the prefix assigned to the linuxfrr.defaultvtep device, which is created as a dummy device when missing
+	VtepIP string `yaml:"vtepip"`
+	// RouterID is the bgp router id, the address of VtepIP when empty
+	RouterID string               `yaml:"routerid"`
+	Uplinks  []UplinkConfig       `yaml:"uplinks"`
+	Peers    []UnderlayPeerConfig `yaml:"peers"`
+}
+
+// LldpExpectationConfig expected cabling of an uplink, the fields left empty are not checked
+type LldpExpectationConfig struct {
+	Interface string `yaml:"interface"`
+	// SystemName is the name of the top of rack switch, PortID the id of its port
+	SystemName string `yaml:"systemname"`
+	PortID     string `yaml:"portid"`
+}
+
+// LldpConfig LLDP neighbor discovery config structure
+type LldpConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Interfaces run the LLDP agent, the underlay uplinks when empty
+	Interfaces []string `yaml:"interfaces"`
+	// TxInterval is the interval in seconds of the announcements, which are held 4 times as long by the neighbors
+	TxInterval int                     `yaml:"txinterval"`
+	Expected   []LldpExpectationConfig `yaml:"expected"`
+	// Enforce holds the bridge ports down until the uplinks are cabled as expected
+	Enforce bool `yaml:"enforce"`
+}
+
+// FabricHealthConfig probes of the remote VTEPs config structure
+type FabricHealthConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Mode is icmp, udp or vxlan, the udp and vxlan probes are echoed by the remote bridges on Port
+	Mode string `yaml:"mode"`
+	Port int    `yaml:"port"`
+	// Interval in seconds between the rounds of Count probes of each peer, which are lost after Timeout milliseconds
+	Interval int `yaml:"interval"`
+	Count    int `yaml:"count"`
+	Timeout  int `yaml:"timeout"`
+	// Size is the size in bytes of the probes
+	Size int `yaml:"size"`
+	// Peers are probed on top of the remote VTEPs of the EVPN routes, which are only probed with Discover
+	Peers    []string `yaml:"peers"`
+	Discover bool     `yaml:"discover"`
+	// StateFile keeps the rekey intervals of the outbound SAs across the restarts so that no SA is installed twice
+	StateFile string `yaml:"statefile"`
+}
+
+// DupAddrDetectionConfig duplicate address detection config structure, an address learnt from MaxMoves
+// different VTEPs within Time seconds is a duplicate, typically a loop in a tenant network
+type DupAddrDetectionConfig struct {
+	// Enabled watches the duplicates and sends an event when an address is detected
+	Enabled bool `yaml:"enabled"`
+	// MaxMoves and Time keep the defaults of the routing stack when zero
+	MaxMoves int `yaml:"maxmoves"`
+	Time     int `yaml:"time"`
+	// Freeze is the time in seconds a duplicate address is held where it was learnt last, -1 until it is
+	// cleared, zero lets it move
+	Freeze int `yaml:"freeze"`
+	// Interval is the period in seconds of the check of the duplicates, 10 seconds when zero
+	Interval int `yaml:"interval"`
+}
+
+// MacMobilityConfig MAC mobility tracking config structure, the moves of the MAC addresses of the logical bridges
+// are counted from the sequence numbers of their EVPN routes
+type MacMobilityConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is the period in seconds of the reading of the MAC addresses, 10 seconds when zero
+	Interval int `yaml:"interval"`
+	// Threshold is the number of moves of an address within Window seconds which raises an alarm, zero for none
+	Threshold int `yaml:"threshold"`
+	// Window is the time in seconds the recent moves are counted over, an hour when zero
+	Window int `yaml:"window"`
+}
+
+// MplsConfig MPLS dataplane config structure, the VPCs selecting it are carried over an MPLS core as
+// BGP/MPLS IP VPNs instead of VXLAN
+type MplsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Transport distributes the label of the vtep address in the core, ldp or sr
+	Transport string `yaml:"transport"`
+	// Interfaces face the MPLS core and accept labelled packets, the underlay uplinks when empty
+	Interfaces []string `yaml:"interfaces"`
+	// PlatformLabels is the size of the label table of the kernel
+	PlatformLabels int `yaml:"platformlabels"`
+	// SidIndex is the index of the prefix segment of the vtep address in the global block of the sr transport
+	SidIndex int `yaml:"sidindex"`
+}
+
+// Srv6Config SRv6 dataplane config structure, the VPCs selecting it are carried over an SRv6 fabric as
+// L3VPNs whose End.DT4 and End.DT6 SIDs are allocated from the locator
+type Srv6Config struct {
+	Enabled bool `yaml:"enabled"`
+	// Locator is the IPv6 prefix of the SIDs of the node, up to a /64, the 16 bits after it are the functions
+	Locator string `yaml:"locator"`
+	// LocatorName names the locator in the routing backend
+	LocatorName string `yaml:"locatorname"`
+	// Source is the source address of the encapsulated packets, the first address of the locator when empty
+	Source string `yaml:"source"`
+}
+
+// IpsecConfig encryption of the VXLAN tunnels config structure, the VXLAN packets exchanged with the peers are
+// wrapped in ESP transport mode with keys derived from the pre-shared key or the certificates
+type IpsecConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// KeySource is psk, the keys derive from the key in PskFile, or pki, the keys derive from the ECDH of the
+	// ECDSA key of the node with the certificate of the peer, named <peer address>.pem in CertDir and signed by Ca
+	KeySource string `yaml:"keysource"`
+	PskFile   string `yaml:"pskfile"`
+	Cert      string `yaml:"cert"`
+	Key       string `yaml:"key"`
+	Ca        string `yaml:"ca"`
+	CertDir   string `yaml:"certdir"`
+	// Cipher is the AEAD of the SAs, aes-gcm-128 or aes-gcm-256
+	Cipher string `yaml:"cipher"`
+	// Rekey is the interval in seconds after which the SAs are replaced by the ones of the next interval
+	Rekey int `yaml:"rekey"`
+	// Peers are encrypted on top of the remote VTEPs of the EVPN routes, which are only encrypted with Discover
+	Peers    []string `yaml:"peers"`
+	Discover bool     `yaml:"discover"`
+	// StateFile keeps the rekey intervals of the outbound SAs across the restarts so that no SA is installed twice
+	StateFile string `yaml:"statefile"`
+}
+
+// MacsecPeerConfig switch port at the end of an uplink protected with static keys
+type MacsecPeerConfig struct {
+	Interface string `yaml:"interface"`
+	// Mac is the address the switch port sends its MACsec frames from
+	Mac string `yaml:"mac"`
+}
+
+// MacsecConfig MACsec protection of the uplinks config structure, the underlay runs over a MACsec device stacked on
+// each uplink
+type MacsecConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Mode is mka, wpa_supplicant agrees the keys with the switch from the connectivity association key of KeyFile
+	// named Ckn, or static, the bridge installs the secure association key of KeyFile, configured on the switch too
+	Mode    string `yaml:"mode"`
+	KeyFile string `yaml:"keyfile"`
+	Ckn     string `yaml:"ckn"`
+	// Interfaces are protected, the underlay uplinks when empty
+	Interfaces []string `yaml:"interfaces"`
+	// Cipher is gcm-aes-128 or gcm-aes-256
+	Cipher string `yaml:"cipher"`
+	// Reload is the interval in seconds the key file is read again at, a new key is rotated in without loss
+	Reload int `yaml:"reload"`
+	// Peers are the switch ports of the uplinks in static mode
+	Peers []MacsecPeerConfig `yaml:"peers"`
+	// StateFile keeps the keys installed in static mode across the restarts, a key is never installed twice
+	StateFile string `yaml:"statefile"`
+}
+
+// ZtpConfig zero-touch provisioning config structure, the bridge fetches and applies its initial bundle at startup
+type ZtpConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// URL is the https location of the bundle, read from the dhcp leases when empty
+	URL string `yaml:"url"`
+	// LeaseFiles are the dhclient lease files holding the url in LeaseOption, the last lease wins
+	LeaseFiles  []string `yaml:"leasefiles"`
+	LeaseOption string   `yaml:"leaseoption"`
+	// StatusURL receives the progress of the provisioning as json posts, nothing is reported when empty
+	StatusURL string `yaml:"statusurl"`
+	// CAFile verifies the certificate of the provisioning server, the system roots are used when empty
+	CAFile string `yaml:"cafile"`
+	// Attempts bounds the fetching and applying of the bundle, retried every RetryInterval seconds, unlimited when zero
+	Attempts      int `yaml:"attempts"`
+	RetryInterval int `yaml:"retryinterval"`
+	// Timeout bounds in seconds the transaction applying the bundle until its objects are programmed
+	Timeout int `yaml:"timeout"`
+	// DoneFile is written once the bundle is applied, the provisioning does not run again while it exists
+	DoneFile string `yaml:"donefile"`
+}
+
+// WebhooksConfig delivery config structure of the status events to the webhooks
+type WebhooksConfig struct {
+	// Retries is the number of retries of a failed delivery, 5 when zero and none when negative
+	Retries int `yaml:"retries"`
+	// Timeout bounds a post in seconds, 10 seconds when zero
+	Timeout int `yaml:"timeout"`
+}
+
+// PublisherConfig message bus config structure, the lifecycle and status events are mirrored onto the bus
+type PublisherConfig struct {
+	// Backend is nats or kafka, no event is published when empty
+	Backend string `yaml:"backend"`
+	// Address is the host:port of the NATS server or the url of the Kafka REST proxy
+	Address string `yaml:"address"`
+	// TopicPrefix starts the topic of each kind of object, e.g. opi-evpn.vrf, opi-evpn when empty
+	TopicPrefix string `yaml:"topicprefix"`
+}
+
+// LogSinkConfig structured log shipping config structure, the log file is kept
+type LogSinkConfig struct {
+	// Type is syslog or journald, the log only goes to the file when empty
+	Type string `yaml:"type"`
+	// Network is unixgram, udp or tcp and Address the syslog daemon, the local daemon when empty.
+	// For journald Address is the socket of the native protocol.
+	Network string `yaml:"network"`
+	Address string `yaml:"address"`
+	// Facility is the syslog facility, daemon when empty
+	Facility string `yaml:"facility"`
+	// Tag is the application name of the messages, the name of the executable when empty
+	Tag string `yaml:"tag"`
+	// Priority is the least severe priority shipped: debug, info, notice, warning or err, all when empty
+	Priority string `yaml:"priority"`
+	// RateLimit bounds the lines shipped per second with bursts of Burst lines, unlimited when zero
+	RateLimit int `yaml:"ratelimit"`
+	Burst     int `yaml:"burst"`
+}
+
+// RemoteConfig remote agent mode config structure, a controller serves the API and holds the store
+// while the node agents program the DPUs
+type RemoteConfig struct {
+	// Mode is controller or agent, the bridge programs its own node when empty
+	Mode string `yaml:"mode"`
+	// Controller is the host:port of the gRPC server of the controller, dialed by the agents
+	Controller string `yaml:"controller"`
+	// Node is the name of the node of the agent, its hostname when empty
+	Node string `yaml:"node"`
+	// Heartbeat is the interval in seconds of the heartbeats of the agents, 5 when zero.
+	// A node missing three heartbeats is down and synchronized again once it is back.
+	Heartbeat int `yaml:"heartbeat"`
+	// Timeout bounds in seconds the programming of an object on the nodes, 20 when zero
+	Timeout int `yaml:"timeout"`
+	// Ca verifies the controller, which verifies the agent with its Cert and Key, the agents dial the controller
+	// without TLS only with Insecure
+	Ca       string `yaml:"ca"`
+	Cert     string `yaml:"cert"`
+	Key      string `yaml:"key"`
+	Insecure bool   `yaml:"insecure"`
+}
+
+// ManagementConfig management plane separation config structure
+type ManagementConfig struct {
+	// Vrf is the vrf device the gRPC and HTTP servers listen in, the default vrf when empty
+	Vrf string `yaml:"vrf"`
+	// Interface is the management interface, which has to be enslaved to Vrf
+	Interface string `yaml:"interface"`
+}
+
+// DeviceSysctlsConfig kernel settings of the devices created for the svis and vrfs, in family.setting=value
+// format, e.g. ipv4.arp_accept=1. They add to or override the defaults, an empty value keeps the setting of the host.
+type DeviceSysctlsConfig struct {
+	Svi []string `yaml:"svi"`
+	Vrf []string `yaml:"vrf"`
+	// Strict fails the creation of a device whose settings cannot be written, the failure is only logged otherwise
+	Strict bool `yaml:"strict"`
+}
+
+// PreflightConfig startup self-check config structure
+type PreflightConfig struct {
+	// Skip names the checks not run, either a check like module:vxlan or a whole kind like module
+	Skip []string `yaml:"skip"`
+	// Sysctls adds to or overrides the required kernel settings, e.g. net.ipv4.ip_forward=1,
+	// alternatives are separated by |
+	Sysctls []string `yaml:"sysctls"`
+	// WarnOnly logs the failed checks instead of stopping the bridge
+	WarnOnly bool `yaml:"warnonly"`
+}
+
 // Config global config structure
 type Config struct {
-	CfgFile     string
-	GRPCPort    uint16             `yaml:"grpcport"`
-	HTTPPort    uint16             `yaml:"httpport"`
-	TLSFiles    string             `yaml:"tlsfiles"`
-	Database    string             `yaml:"database"`
-	DBAddress   string             `yaml:"dbaddress"`
-	Buildenv    string             `yaml:"buildenv"`
-	Tracer      bool               `yaml:"tracer"`
-	Subscribers []SubscriberConfig `yaml:"subscribers"`
-	Interfaces  InterfaceConfig    `yaml:"interfaces"`
-	LinuxFrr    LinuxFrrConfig     `yaml:"linuxfrr"`
-	Netlink     NetlinkConfig      `yaml:"netlink"`
-	P4          P4Config           `yaml:"p4"`
-	LogLevel    loglevelConfig     `yaml:"loglevel"`
+	CfgFile       string
+	ListenAddress string                 `yaml:"listenaddress"`
+	GRPCPort      uint16                 `yaml:"grpcport"`
+	HTTPPort      uint16                 `yaml:"httpport"`
+	TLSFiles      string                 `yaml:"tlsfiles"`
+	Database      string                 `yaml:"database"`
+	DBAddress     string                 `yaml:"dbaddress"`
+	DBBackupDir   string                 `yaml:"dbbackupdir"`
+	Buildenv      string                 `yaml:"buildenv"`
+	Tracer        bool                   `yaml:"tracer"`
+	RequireETag   bool                   `yaml:"requireetag"`
+	Subscribers   []SubscriberConfig     `yaml:"subscribers"`
+	Interfaces    InterfaceConfig        `yaml:"interfaces"`
+	LinuxFrr      LinuxFrrConfig         `yaml:"linuxfrr"`
+	Routing       RoutingConfig          `yaml:"routing"`
+	Netlink       NetlinkConfig          `yaml:"netlink"`
+	Garp          GarpConfig             `yaml:"garp"`
+	P4            P4Config               `yaml:"p4"`
+	LogLevel      loglevelConfig         `yaml:"loglevel"`
+	Quotas        QuotasConfig           `yaml:"quotas"`
+	VniPool       VniPoolConfig          `yaml:"vnipool"`
+	VlanPool      VlanPoolConfig         `yaml:"vlanpool"`
+	Gateway       GatewayConfig          `yaml:"gateway"`
+	VirtualPorts  VirtualPortsConfig     `yaml:"virtualports"`
+	Storage       StorageConfig          `yaml:"storage"`
+	Devlink       DevlinkConfig          `yaml:"devlink"`
+	Leases        LeasesConfig           `yaml:"leases"`
+	History       HistoryConfig          `yaml:"history"`
+	Maintenance   MaintenanceConfig      `yaml:"maintenance"`
+	Deadlines     DeadlinesConfig        `yaml:"deadlines"`
+	Interceptors  InterceptorsConfig     `yaml:"interceptors"`
+	Preflight     PreflightConfig        `yaml:"preflight"`
+	Sysctls       DeviceSysctlsConfig    `yaml:"sysctls"`
+	Management    ManagementConfig       `yaml:"management"`
+	Underlay      UnderlayConfig         `yaml:"underlay"`
+	Lldp          LldpConfig             `yaml:"lldp"`
+	FabricHealth  FabricHealthConfig     `yaml:"fabrichealth"`
+	DupAddr       DupAddrDetectionConfig `yaml:"dupaddrdetection"`
+	MacMobility   MacMobilityConfig      `yaml:"macmobility"`
+	Mpls          MplsConfig             `yaml:"mpls"`
+	Srv6          Srv6Config             `yaml:"srv6"`
+	Ipsec         IpsecConfig            `yaml:"ipsec"`
+	Macsec        MacsecConfig           `yaml:"macsec"`
+	Ztp           ZtpConfig              `yaml:"ztp"`
+	Webhooks      WebhooksConfig         `yaml:"webhooks"`
+	Publisher     PublisherConfig        `yaml:"publisher"`
+	LogSink       LogSinkConfig          `yaml:"logsink"`
+	Remote        RemoteConfig           `yaml:"remote"`
 }
 
 // GlobalConfig global config
@@ -107,8 +570,41 @@ func SetConfig(cfg Config) error {
 
 const (
 	configFilePath = "./"
+	// envPrefix is the prefix of the environment variables overriding the config, e.g. OPI_EVPN_LINUXFRR_LOCALAS
+	envPrefix = "OPI_EVPN"
 )
 
+// bindEnv binds an environment variable to every scalar setting of the config structure,
+// so that viper.Unmarshal sees the overrides even when the key is missing from the file
+func bindEnv(t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := prefix + strings.ToLower(field.Name)
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			if err := bindEnv(field.Type, key+"."); err != nil {
+				return err
+			}
+		case reflect.Map:
+			continue
+		case reflect.Slice:
+			if field.Type.Elem().Kind() != reflect.String {
+				continue
+			}
+			fallthrough
+		default:
+			if err := viper.BindEnv(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // Initcfg read the config from file
 func Initcfg() {
 	if GlobalConfig.CfgFile != "" {
@@ -119,6 +615,11 @@ func Initcfg() {
 		viper.SetConfigType("yaml")
 		viper.SetConfigName("config.yaml")
 	}
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	if err := bindEnv(reflect.TypeOf(GlobalConfig), ""); err != nil {
+		log.Panic(err)
+	}
 
 	if err := LoadConfig(); err != nil {
 		log.Panic(err)
@@ -177,6 +678,325 @@ func ValidateConfig() error {
 		return err
 	}
 
+	if threshold := viper.GetInt("devlink.occupancythreshold"); threshold < 0 || threshold > 100 {
+		err = fmt.Errorf("devlink occupancy threshold must be a percentage between 0 and 100")
+		return err
+	}
+
+	if viper.GetInt("leases.interval") < 0 || viper.GetInt("leases.maxduration") < 0 {
+		err = fmt.Errorf("leases interval and maxduration must not be negative")
+		return err
+	}
+
+	if viper.GetInt("history.maxrevisions") < 0 {
+		err = fmt.Errorf("history maxrevisions must not be negative")
+		return err
+	}
+
+	if viper.GetInt("maintenance.draintimer") < 0 || viper.GetInt("maintenance.sviinterval") < 0 {
+		err = fmt.Errorf("maintenance draintimer and sviinterval must not be negative")
+		return err
+	}
+
+	if maxPaths := viper.GetInt("routing.ecmp.maxpaths"); maxPaths < 0 || maxPaths > 64 {
+		err = fmt.Errorf("routing ecmp maxpaths must be between 0 and 64")
+		return err
+	}
+
+	for _, key := range []string{"routing.gracefulrestart.restarttime", "routing.gracefulrestart.stalepathtime"} {
+		if t := viper.GetInt(key); t < 0 || t > 4095 {
+			err = fmt.Errorf("%s must be between 0 and 4095 seconds", key)
+			return err
+		}
+	}
+
+	if viper.GetInt("garp.count") < 0 || viper.GetInt("garp.interval") < 0 {
+		err = fmt.Errorf("garp count and interval must not be negative")
+		return err
+	}
+
+	switch viper.GetString("gateway.policy") {
+	case "", "first", "last", "explicit":
+	default:
+		err = fmt.Errorf("gateway policy must be one of first, last or explicit")
+		return err
+	}
+
+	switch viper.GetString("linuxfrr.bridgetopology") {
+	case "", "vlan-aware", "per-vlan":
+	default:
+		err = fmt.Errorf("linuxfrr bridgetopology must be either vlan-aware or per-vlan")
+		return err
+	}
+
+	for _, key := range []string{"loglevel.db", "loglevel.grpc", "loglevel.linux", "loglevel.netlink", "loglevel.p4"} {
+		switch viper.GetString(key) {
+		case "", "debug", "info", "warn", "error":
+		default:
+			err = fmt.Errorf("%s must be one of debug, info, warn or error", key)
+			return err
+		}
+	}
+
+	for _, key := range []string{"quotas.maxsvispervrf", "quotas.maxportsperbridge", "quotas.maxvnis"} {
+		if viper.GetInt(key) < 0 {
+			err = fmt.Errorf("%s must not be negative", key)
+			return err
+		}
+	}
+
+	if viper.GetInt("netlink.pollinterval") < 0 {
+		err = fmt.Errorf("netlink pollinterval must not be negative")
+		return err
+	}
+
+	if viper.GetInt("routing.gobgp.pollinterval") < 0 || viper.GetInt("routing.gobgp.flushinterval") < 0 {
+		err = fmt.Errorf("routing gobgp pollinterval and flushinterval must not be negative")
+		return err
+	}
+
+	if viper.GetInt("routing.gobgp.localas") < 0 || viper.GetInt("routing.gobgp.localas") > 65535 {
+		err = fmt.Errorf("routing gobgp localas must be a 2 octet AS number")
+		return err
+	}
+
+	for _, sysctl := range viper.GetStringSlice("preflight.sysctls") {
+		if key, value, ok := strings.Cut(sysctl, "="); !ok || key == "" || value == "" {
+			err = fmt.Errorf("preflight sysctls must be in key=value format, not %s", sysctl)
+			return err
+		}
+	}
+
+	for _, key := range []string{"sysctls.svi", "sysctls.vrf"} {
+		for _, sysctl := range viper.GetStringSlice(key) {
+			setting, _, ok := strings.Cut(sysctl, "=")
+			family, name, _ := strings.Cut(setting, ".")
+			if !ok || (family != "ipv4" && family != "ipv6") || name == "" || strings.ContainsAny(name, "./") {
+				err = fmt.Errorf("%s must be in ipv4.setting=value or ipv6.setting=value format, not %s", key, sysctl)
+				return err
+			}
+		}
+	}
+
+	if viper.GetString("management.interface") != "" && viper.GetString("management.vrf") == "" {
+		err = fmt.Errorf("management interface requires the management vrf it is enslaved to")
+		return err
+	}
+
+	if viper.GetInt("webhooks.timeout") < 0 {
+		err = fmt.Errorf("webhooks timeout must not be negative")
+		return err
+	}
+
+	switch viper.GetString("logsink.type") {
+	case "", "syslog", "journald":
+	default:
+		err = fmt.Errorf("logsink type must be syslog or journald, not %s", viper.GetString("logsink.type"))
+		return err
+	}
+	if viper.GetInt("logsink.ratelimit") < 0 || viper.GetInt("logsink.burst") < 0 {
+		err = fmt.Errorf("logsink ratelimit and burst must not be negative")
+		return err
+	}
+
+	if backend := viper.GetString("publisher.backend"); backend != "" {
+		if backend != "nats" && backend != "kafka" {
+			err = fmt.Errorf("publisher backend must be nats or kafka, not %s", backend)
+			return err
+		}
+		if viper.GetString("publisher.address") == "" {
+			err = fmt.Errorf("publisher backend %s requires an address", backend)
+			return err
+		}
+	}
+
+	switch viper.GetString("remote.mode") {
+	case "", "controller":
+	case "agent":
+		if viper.GetString("remote.controller") == "" {
+			err = fmt.Errorf("remote agent mode requires the address of the controller")
+			return err
+		}
+		if !viper.GetBool("remote.insecure") && (viper.GetString("remote.ca") == "" ||
+			viper.GetString("remote.cert") == "" || viper.GetString("remote.key") == "") {
+			err = fmt.Errorf("remote agent mode requires a ca, a cert and a key unless insecure")
+			return err
+		}
+	default:
+		err = fmt.Errorf("remote mode must be controller or agent, not %s", viper.GetString("remote.mode"))
+		return err
+	}
+	if viper.GetInt("remote.heartbeat") < 0 {
+		err = fmt.Errorf("remote heartbeat must not be negative")
+		return err
+	}
+	// the task manager gives up on a component after 30 seconds
+	if timeout := viper.GetInt("remote.timeout"); timeout < 0 || timeout > 25 {
+		err = fmt.Errorf("remote timeout must be between 0 and 25 seconds")
+		return err
+	}
+
+	if viper.GetInt("lldp.txinterval") < 0 {
+		err = fmt.Errorf("lldp txinterval must not be negative")
+		return err
+	}
+
+	switch viper.GetString("fabrichealth.mode") {
+	case "", "icmp", "udp", "vxlan":
+	default:
+		err = fmt.Errorf("fabrichealth mode must be icmp, udp or vxlan, not %s", viper.GetString("fabrichealth.mode"))
+		return err
+	}
+	for _, key := range []string{"fabrichealth.interval", "fabrichealth.count", "fabrichealth.timeout", "fabrichealth.size"} {
+		if viper.GetInt(key) < 0 {
+			err = fmt.Errorf("%s must not be negative", key)
+			return err
+		}
+	}
+	if port := viper.GetInt("fabrichealth.port"); port < 0 || port > 65535 {
+		err = fmt.Errorf("fabrichealth port must be between 1 and 65535")
+		return err
+	}
+	for _, peer := range viper.GetStringSlice("fabrichealth.peers") {
+		if net.ParseIP(peer) == nil {
+			err = fmt.Errorf("fabrichealth peer %s is not an ip address", peer)
+			return err
+		}
+	}
+
+	if moves := viper.GetInt("dupaddrdetection.maxmoves"); moves != 0 && (moves < 2 || moves > 1000) {
+		err = fmt.Errorf("dupaddrdetection maxmoves must be between 2 and 1000")
+		return err
+	}
+	if t := viper.GetInt("dupaddrdetection.time"); t != 0 && (t < 2 || t > 1800) {
+		err = fmt.Errorf("dupaddrdetection time must be between 2 and 1800 seconds")
+		return err
+	}
+	if freeze := viper.GetInt("dupaddrdetection.freeze"); freeze != 0 && freeze != -1 && (freeze < 30 || freeze > 3600) {
+		err = fmt.Errorf("dupaddrdetection freeze must be between 30 and 3600 seconds, or -1")
+		return err
+	}
+	if viper.GetInt("dupaddrdetection.interval") < 0 {
+		err = fmt.Errorf("dupaddrdetection interval must not be negative")
+		return err
+	}
+	for _, key := range []string{"interval", "threshold", "window"} {
+		if viper.GetInt("macmobility."+key) < 0 {
+			err = fmt.Errorf("macmobility %s must not be negative", key)
+			return err
+		}
+	}
+	if window := viper.GetInt("macmobility.window"); window != 0 && window < viper.GetInt("macmobility.interval") {
+		err = fmt.Errorf("macmobility window must not be shorter than the interval")
+		return err
+	}
+
+	switch viper.GetString("mpls.transport") {
+	case "", "ldp", "sr":
+	default:
+		err = fmt.Errorf("mpls transport must be ldp or sr, not %s", viper.GetString("mpls.transport"))
+		return err
+	}
+	if viper.GetInt("mpls.platformlabels") < 0 || viper.GetInt("mpls.platformlabels") > 1048575 {
+		err = fmt.Errorf("mpls platformlabels must be between 0 and 1048575")
+		return err
+	}
+	if viper.GetInt("mpls.sidindex") < 0 {
+		err = fmt.Errorf("mpls sidindex must not be negative")
+		return err
+	}
+
+	if locator := viper.GetString("srv6.locator"); locator != "" {
+		if prefix, perr := netip.ParsePrefix(locator); perr != nil || !prefix.Addr().Is6() || prefix.Bits() > 64 {
+			err = fmt.Errorf("srv6 locator must be an IPv6 prefix up to a /64, not %s", locator)
+			return err
+		}
+	} else if viper.GetBool("srv6.enabled") {
+		err = fmt.Errorf("srv6 requires a locator")
+		return err
+	}
+	if source := viper.GetString("srv6.source"); source != "" {
+		if addr, perr := netip.ParseAddr(source); perr != nil || !addr.Is6() {
+			err = fmt.Errorf("srv6 source must be an IPv6 address, not %s", source)
+			return err
+		}
+	}
+
+	switch viper.GetString("ipsec.keysource") {
+	case "", "psk":
+		if viper.GetBool("ipsec.enabled") && viper.GetString("ipsec.pskfile") == "" {
+			err = fmt.Errorf("ipsec psk keysource requires a pskfile")
+			return err
+		}
+	case "pki":
+		if viper.GetBool("ipsec.enabled") && (viper.GetString("ipsec.cert") == "" || viper.GetString("ipsec.key") == "" ||
+			viper.GetString("ipsec.ca") == "" || viper.GetString("ipsec.certdir") == "") {
+			err = fmt.Errorf("ipsec pki keysource requires a cert, a key, a ca and a certdir")
+			return err
+		}
+	default:
+		err = fmt.Errorf("ipsec keysource must be psk or pki, not %s", viper.GetString("ipsec.keysource"))
+		return err
+	}
+	switch viper.GetString("ipsec.cipher") {
+	case "", "aes-gcm-128", "aes-gcm-256":
+	default:
+		err = fmt.Errorf("ipsec cipher must be aes-gcm-128 or aes-gcm-256, not %s", viper.GetString("ipsec.cipher"))
+		return err
+	}
+	if viper.GetInt("ipsec.rekey") < 0 {
+		err = fmt.Errorf("ipsec rekey must not be negative")
+		return err
+	}
+	for _, peer := range viper.GetStringSlice("ipsec.peers") {
+		if net.ParseIP(peer) == nil {
+			err = fmt.Errorf("ipsec peer %s is not an ip address", peer)
+			return err
+		}
+	}
+
+	switch viper.GetString("macsec.mode") {
+	case "", "mka":
+		if ckn := viper.GetString("macsec.ckn"); ckn != "" {
+			if b, herr := hex.DecodeString(ckn); herr != nil || len(b) == 0 || len(b) > 32 {
+				err = fmt.Errorf("macsec ckn must be up to 32 bytes in hex, not %s", ckn)
+				return err
+			}
+		} else if viper.GetBool("macsec.enabled") {
+			err = fmt.Errorf("macsec mka mode requires a ckn")
+			return err
+		}
+	case "static":
+	default:
+		err = fmt.Errorf("macsec mode must be mka or static, not %s", viper.GetString("macsec.mode"))
+		return err
+	}
+	if viper.GetBool("macsec.enabled") && viper.GetString("macsec.keyfile") == "" {
+		err = fmt.Errorf("macsec requires a keyfile")
+		return err
+	}
+	switch viper.GetString("macsec.cipher") {
+	case "", "gcm-aes-128", "gcm-aes-256":
+	default:
+		err = fmt.Errorf("macsec cipher must be gcm-aes-128 or gcm-aes-256, not %s", viper.GetString("macsec.cipher"))
+		return err
+	}
+	if viper.GetInt("macsec.reload") < 0 {
+		err = fmt.Errorf("macsec reload must not be negative")
+		return err
+	}
+
+	if ztpURL := viper.GetString("ztp.url"); ztpURL != "" {
+		if u, perr := url.Parse(ztpURL); perr != nil || u.Scheme != "https" || u.Host == "" {
+			err = fmt.Errorf("ztp url must be an https url, not %s", ztpURL)
+			return err
+		}
+	}
+	if viper.GetInt("ztp.attempts") < 0 || viper.GetInt("ztp.retryinterval") < 0 || viper.GetInt("ztp.timeout") < 0 {
+		err = fmt.Errorf("ztp attempts, retryinterval and timeout must not be negative")
+		return err
+	}
+
 	dbAddr := viper.GetString("dbaddress")
 	_, port, err := net.SplitHostPort(dbAddr)
 	if err != nil {
@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package config introduces the configuration from file or runtime param
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const testConfig = `
+grpcport: 50151
+httpport: 8082
+dbaddress: 127.0.0.1:6379
+linuxfrr:
+    localas: 65000
+garp:
+    count: 3
+    interval: 1000
+`
+
+// loadTestConfig loads the config file through viper as the bridge does at startup
+func loadTestConfig(t *testing.T, content string) string {
+	viper.Reset()
+	GlobalConfig = Config{}
+	reloadHooks = nil
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	GlobalConfig.CfgFile = file
+	Initcfg()
+	return file
+}
+
+func Test_EnvOverride(t *testing.T) {
+	t.Setenv("OPI_EVPN_LINUXFRR_LOCALAS", "65100")
+	t.Setenv("OPI_EVPN_LISTENADDRESS", "127.0.0.1")
+	t.Setenv("OPI_EVPN_NETLINK_POLLINTERVAL", "5")
+	loadTestConfig(t, testConfig)
+
+	if GlobalConfig.LinuxFrr.LocalAs != 65100 {
+		t.Errorf("expected local as 65100 from the environment, received %v", GlobalConfig.LinuxFrr.LocalAs)
+	}
+	if GlobalConfig.ListenAddress != "127.0.0.1" {
+		t.Errorf("expected listen address 127.0.0.1, received %q", GlobalConfig.ListenAddress)
+	}
+	if GlobalConfig.Netlink.PollInterval != 5 {
+		t.Errorf("expected poll interval 5, received %v", GlobalConfig.Netlink.PollInterval)
+	}
+	if GlobalConfig.Garp.Count != 3 {
+		t.Errorf("expected garp count 3 from the file, received %v", GlobalConfig.Garp.Count)
+	}
+}
+
+func Test_PreflightSysctls(t *testing.T) {
+	loadTestConfig(t, testConfig+`
+preflight:
+    sysctls: ["net.ipv4.tcp_l3mdev_accept=1", "net.ipv4.conf.all.rp_filter=0|2"]
+`)
+	expected := []string{"net.ipv4.tcp_l3mdev_accept=1", "net.ipv4.conf.all.rp_filter=0|2"}
+	if !reflect.DeepEqual(GlobalConfig.Preflight.Sysctls, expected) {
+		t.Errorf("expected the settings %v, received %v", expected, GlobalConfig.Preflight.Sysctls)
+	}
+}
+
+func Test_Reload(t *testing.T) {
+	tests := map[string]struct {
+		content  string
+		err      bool
+		garp     GarpConfig
+		localAs  int
+		logLevel string
+		vniPool  VniPoolConfig
+		hook     bool
+	}{
+		"reloadable settings are applied": {
+			content:  testConfig + "loglevel:\n    grpc: warn\n",
+			garp:     GarpConfig{Count: 3, Interval: 1000},
+			localAs:  65000,
+			logLevel: "warn",
+			hook:     true,
+		},
+		"pools are applied": {
+			content: testConfig + "vnipool:\n    min: 100\n    max: 199\n",
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+			vniPool: VniPoolConfig{Min: 100, Max: 199},
+			hook:    true,
+		},
+		"other settings wait for a restart": {
+			content: "grpcport: 50151\nhttpport: 8082\ndbaddress: 127.0.0.1:6379\nlinuxfrr:\n    localas: 65200\ngarp:\n    count: 5\n",
+			garp:    GarpConfig{Count: 5},
+			localAs: 65000,
+			hook:    true,
+		},
+		"invalid config is rejected": {
+			content: testConfig + "loglevel:\n    grpc: verbose\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+		"ztp url which is not https is rejected": {
+			content: testConfig + "ztp:\n    url: http://ztp.example.com/dpu.yaml\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+		"unknown log sink is rejected": {
+			content: testConfig + "logsink:\n    type: fluentd\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+		"agent without controller is rejected": {
+			content: testConfig + "remote:\n    mode: agent\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+		"agent without tls is rejected": {
+			content: testConfig + "remote:\n    mode: agent\n    controller: 10.10.10.10:50151\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+		"insecure agent is accepted": {
+			content: testConfig + "remote:\n    mode: agent\n    controller: 10.10.10.10:50151\n    insecure: true\n",
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+			hook:    true,
+		},
+		"unknown fabric probe mode is rejected": {
+			content: testConfig + "fabrichealth:\n    mode: twamp\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+		"duplicate address freeze below the bgp range is rejected": {
+			content: testConfig + "dupaddrdetection:\n    freeze: 10\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+		"mac mobility window shorter than the interval is rejected": {
+			content: testConfig + "macmobility:\n    interval: 60\n    window: 30\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+		"unknown mpls transport is rejected": {
+			content: testConfig + "mpls:\n    transport: rsvp\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+		"srv6 locator longer than a /64 is rejected": {
+			content: testConfig + "srv6:\n    locator: fc00:0:1::/96\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+		"ipsec psk without a pskfile is rejected": {
+			content: testConfig + "ipsec:\n    enabled: true\n    keysource: psk\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+		"macsec mka without a ckn is rejected": {
+			content: testConfig + "macsec:\n    enabled: true\n    mode: mka\n    keyfile: /etc/opi/cak\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+		"graceful restart time beyond the bgp range is rejected": {
+			content: testConfig + "routing:\n    gracefulrestart:\n        restarttime: 5000\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+		"negative lldp interval is rejected": {
+			content: testConfig + "lldp:\n    txinterval: -1\n",
+			err:     true,
+			garp:    GarpConfig{Count: 3, Interval: 1000},
+			localAs: 65000,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			file := loadTestConfig(t, testConfig)
+			called := false
+			OnReload(func(*Config) { called = true })
+			if err := os.WriteFile(file, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := Reload(); (err != nil) != tt.err {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(GlobalConfig.Garp, tt.garp) {
+				t.Errorf("expected garp %+v, received %+v", tt.garp, GlobalConfig.Garp)
+			}
+			if GlobalConfig.LinuxFrr.LocalAs != tt.localAs {
+				t.Errorf("expected local as %v, received %v", tt.localAs, GlobalConfig.LinuxFrr.LocalAs)
+			}
+			if GlobalConfig.LogLevel.Grpc != tt.logLevel {
+				t.Errorf("expected grpc log level %q, received %q", tt.logLevel, GlobalConfig.LogLevel.Grpc)
+			}
+			if GlobalConfig.VniPool != tt.vniPool {
+				t.Errorf("expected vni pool %+v, received %+v", tt.vniPool, GlobalConfig.VniPool)
+			}
+			if called != tt.hook {
+				t.Errorf("expected hook called %v, received %v", tt.hook, called)
+			}
+		})
+	}
+}
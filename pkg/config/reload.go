@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package config introduces the configuration from file or runtime param
+package config
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// ReloadHook is called with the global config once a reload has been applied
+type ReloadHook func(cfg *Config)
+
+var (
+	reloadMu    sync.Mutex
+	reloadHooks []ReloadHook
+)
+
+// reloadableKeys are the settings applied at runtime, any other change requires a restart
+var reloadableKeys = map[string]bool{
+	"deadlines":               true,
+	"devlink":                 true,
+	"garp":                    true,
+	"gateway":                 true,
+	"history":                 true,
+	"leases":                  true,
+	"maintenance":             true,
+	"interceptors.authtokens": true,
+	"loglevel":                true,
+	"netlink.pollinterval":    true,
+	"quotas":                  true,
+	"sysctls":                 true,
+	"vlanpool":                true,
+	"vnipool":                 true,
+}
+
+// OnReload registers a hook called after every reload of the config
+func OnReload(hook ReloadHook) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadHooks = append(reloadHooks, hook)
+}
+
+// Watch reloads the config on SIGHUP and whenever the config file changes
+func Watch() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+	go func() {
+		for range sigChan {
+			log.Println("config: received SIGHUP, reloading", viper.ConfigFileUsed())
+			if err := Reload(); err != nil {
+				log.Printf("config: reload failed, keeping the running config: %v", err)
+			}
+		}
+	}()
+
+	if viper.ConfigFileUsed() == "" {
+		return
+	}
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("config: %s changed, reloading", e.Name)
+		reloadMu.Lock()
+		defer reloadMu.Unlock()
+		if err := applyReload(); err != nil {
+			log.Printf("config: reload failed, keeping the running config: %v", err)
+		}
+	})
+	viper.WatchConfig()
+}
+
+// Reload re-reads the config file and the environment and applies the settings which are safe to change at runtime
+func Reload() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+	return applyReload()
+}
+
+// applyReload copies the reloadable settings read by viper into the global config and calls the hooks
+func applyReload() error {
+	cfg := Config{}
+	if err := viper.Unmarshal(&cfg); err != nil {
+		return err
+	}
+	if err := ValidateConfig(); err != nil {
+		return err
+	}
+	for _, key := range changedKeys(reflect.ValueOf(GlobalConfig), reflect.ValueOf(cfg), "") {
+		log.Printf("config: %s changed, restart required to apply it", key)
+	}
+
+	GlobalConfig.Garp = cfg.Garp
+	GlobalConfig.Gateway = cfg.Gateway
+	GlobalConfig.LogLevel = cfg.LogLevel
+	GlobalConfig.Netlink.PollInterval = cfg.Netlink.PollInterval
+	GlobalConfig.Quotas = cfg.Quotas
+	GlobalConfig.Sysctls = cfg.Sysctls
+	GlobalConfig.VniPool = cfg.VniPool
+	GlobalConfig.VlanPool = cfg.VlanPool
+	GlobalConfig.Deadlines = cfg.Deadlines
+	GlobalConfig.Devlink = cfg.Devlink
+	GlobalConfig.Leases = cfg.Leases
+	GlobalConfig.History = cfg.History
+	GlobalConfig.Maintenance = cfg.Maintenance
+	GlobalConfig.Interceptors.AuthTokens = cfg.Interceptors.AuthTokens
+	log.Printf("config: reloaded garp %+v, loglevel %+v, netlink pollinterval %v, quotas %+v, vnipool %+v, vlanpool %+v, deadlines %+v",
+		GlobalConfig.Garp, GlobalConfig.LogLevel, GlobalConfig.Netlink.PollInterval, GlobalConfig.Quotas,
+		GlobalConfig.VniPool, GlobalConfig.VlanPool, GlobalConfig.Deadlines)
+
+	for _, hook := range reloadHooks {
+		hook(&GlobalConfig)
+	}
+	return nil
+}
+
+// changedKeys returns the keys of the settings which differ and cannot be reloaded
+func changedKeys(old, cur reflect.Value, prefix string) []string {
+	keys := []string{}
+	for i := 0; i < old.NumField(); i++ {
+		field := old.Type().Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := prefix + strings.ToLower(field.Name)
+		if reloadableKeys[key] {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, changedKeys(old.Field(i), cur.Field(i), key+".")...)
+			continue
+		}
+		if !reflect.DeepEqual(old.Field(i).Interface(), cur.Field(i).Interface()) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
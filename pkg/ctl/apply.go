@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ctl implements the command line client of the bridge gRPC API
+package ctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// applyChange is one step of the plan returned by the bridge
+type applyChange struct {
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	// RolledBack tells that the change has been undone because the transaction failed
+	RolledBack bool `json:"rolledBack,omitempty"`
+}
+
+// applyResult is the outcome of an apply returned by the bridge
+type applyResult struct {
+	Changes    []applyChange `json:"changes"`
+	Applied    bool          `json:"applied"`
+	RolledBack bool          `json:"rolledBack,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	// Change names the change to confirm before RevertAt
+	Change   string     `json:"change,omitempty"`
+	RevertAt *time.Time `json:"revertAt,omitempty"`
+}
+
+// readManifests concatenates the manifests into a single bundle, "-" reads the standard input
+func readManifests(in io.Reader, files []string) ([]byte, error) {
+	var bundle bytes.Buffer
+	for _, file := range files {
+		var data []byte
+		var err error
+		if file == "-" {
+			data, err = io.ReadAll(in)
+		} else {
+			data, err = os.ReadFile(file)
+		}
+		if err != nil {
+			return nil, err
+		}
+		bundle.WriteString("\n---\n")
+		bundle.Write(data)
+	}
+	return bundle.Bytes(), nil
+}
+
+func newApplyCommand(o *options) *cobra.Command {
+	var files []string
+	var prune, dryRun, atomic, wait bool
+	var confirmTimeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "apply -f <manifest>...",
+		Short: "converge the bridge towards YAML or JSON manifests",
+		Long: "apply sends the Vrf, LogicalBridge, Svi and BridgePort manifests (the kinds of the kubernetes operator) to the bridge,\n" +
+			"which creates and updates the objects to match them and prints the plan it ran.\n" +
+			"With --atomic the plan is a transaction: when a change fails the bridge undoes the ones already done,\n" +
+			"and with --wait also when the objects are not programmed within --timeout.\n" +
+			"With --confirm-timeout the bridge reverts the transaction unless it is confirmed in time with opi-evpn-ctl confirm",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			bundle, err := readManifests(cmd.InOrStdin(), files)
+			if err != nil {
+				return err
+			}
+			query := url.Values{}
+			if prune {
+				query.Set("prune", "true")
+			}
+			if dryRun {
+				query.Set("dry_run", "true")
+			}
+			if atomic {
+				query.Set("atomic", "true")
+				// the bridge rolls the transaction back when the request times out
+				query.Set("timeout", o.timeout.String())
+			}
+			if wait {
+				query.Set("wait", "true")
+			}
+			if confirmTimeout > 0 {
+				query.Set("confirm_timeout", confirmTimeout.String())
+			}
+			ctx, cancel := o.context()
+			defer cancel()
+			u := url.URL{Scheme: "http", Host: o.httpAddress, Path: "/v1/admin/apply", RawQuery: query.Encode()}
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(bundle))
+			if err != nil {
+				return err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+			result := &applyResult{}
+			if err := json.Unmarshal(body, result); err != nil || result.Changes == nil {
+				return fmt.Errorf("apply failed: %s: %s", resp.Status, body)
+			}
+			if err := printApplyResult(o, cmd.OutOrStdout(), result, dryRun); err != nil {
+				return err
+			}
+			if result.Error != "" {
+				return errors.New(result.Error)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVarP(&files, "filename", "f", nil, "manifest files, - reads the standard input")
+	cmd.Flags().BoolVar(&prune, "prune", false, "delete the objects which are not in the manifests")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only print the plan")
+	cmd.Flags().BoolVar(&atomic, "atomic", false, "roll back all the changes when one of them fails")
+	cmd.Flags().BoolVar(&wait, "wait", false, "with --atomic, also roll back when the objects are not programmed within the timeout")
+	cmd.Flags().DurationVar(&confirmTimeout, "confirm-timeout", 0, "with --atomic, revert the changes unless they are confirmed within this duration")
+	if err := cmd.MarkFlagRequired("filename"); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+// printApplyResult writes the plan in the requested format
+func printApplyResult(o *options, w io.Writer, result *applyResult, dryRun bool) error {
+	if o.output != "table" {
+		return o.printValue(w, result)
+	}
+	rows := [][]string{}
+	for _, c := range result.Changes {
+		action := c.Action
+		if c.RolledBack {
+			action += " (rolled back)"
+		}
+		rows = append(rows, []string{action, c.Kind, shortName(c.Name)})
+	}
+	if err := printTable(w, []string{"ACTION", "KIND", "ID"}, rows); err != nil {
+		return err
+	}
+	if dryRun {
+		_, err := fmt.Fprintln(w, "dry run, nothing was applied")
+		return err
+	}
+	if result.Change != "" && result.RevertAt != nil {
+		_, err := fmt.Fprintf(w, "reverted at %s unless confirmed with: opi-evpn-ctl confirm %s\n", result.RevertAt.Format(time.RFC3339), result.Change)
+		return err
+	}
+	return nil
+}
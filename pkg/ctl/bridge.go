@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ctl implements the command line client of the bridge gRPC API
+package ctl
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+// bridgeKind describes the logical bridge commands
+var bridgeKind = &kind[*pb.LogicalBridge]{
+	use:        "bridge",
+	aliases:    []string{"bridges", "logical-bridge", "lb"},
+	short:      "manage the logical bridges",
+	collection: "bridges",
+	table: table[*pb.LogicalBridge]{
+		header: []string{"ID", "VLAN", "VNI", "VTEP", "STATUS"},
+		row: func(in *pb.LogicalBridge) []string {
+			vni := ""
+			if in.GetSpec().Vni != nil {
+				vni = fmt.Sprint(in.GetSpec().GetVni())
+			}
+			return []string{
+				shortName(in.GetName()),
+				fmt.Sprint(in.GetSpec().GetVlanId()),
+				vni,
+				prefixString(in.GetSpec().GetVtepIpPrefix()),
+				operStatus(in.GetStatus().GetOperStatus()),
+			}
+		},
+	},
+	name: func(in *pb.LogicalBridge) string { return in.GetName() },
+	get: func(ctx context.Context, conn grpc.ClientConnInterface, name string) (*pb.LogicalBridge, error) {
+		return pb.NewLogicalBridgeServiceClient(conn).GetLogicalBridge(ctx, &pb.GetLogicalBridgeRequest{Name: name})
+	},
+	list: func(ctx context.Context, conn grpc.ClientConnInterface, pageToken string) ([]*pb.LogicalBridge, string, error) {
+		resp, err := pb.NewLogicalBridgeServiceClient(conn).ListLogicalBridges(ctx, &pb.ListLogicalBridgesRequest{PageToken: pageToken})
+		return resp.GetLogicalBridges(), resp.GetNextPageToken(), err
+	},
+	delete: func(ctx context.Context, conn grpc.ClientConnInterface, name string, allowMissing bool, opts ...grpc.CallOption) error {
+		_, err := pb.NewLogicalBridgeServiceClient(conn).DeleteLogicalBridge(ctx, &pb.DeleteLogicalBridgeRequest{Name: name, AllowMissing: allowMissing}, opts...)
+		return err
+	},
+}
+
+func newBridgeCommand(o *options) *cobra.Command {
+	var vlan, vni uint32
+	var vtep string
+	create := &cobra.Command{
+		Use:   "create <id>",
+		Short: "create a logical bridge",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spec := &pb.LogicalBridgeSpec{VlanId: vlan}
+			if cmd.Flags().Changed("vni") {
+				spec.Vni = &vni
+			}
+			var err error
+			if spec.VtepIpPrefix, err = prefixToPb(vtep); err != nil {
+				return fmt.Errorf("invalid --vtep: %v", err)
+			}
+			conn, err := o.dial()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context()
+			defer cancel()
+			lb, err := pb.NewLogicalBridgeServiceClient(conn).CreateLogicalBridge(ctx, &pb.CreateLogicalBridgeRequest{
+				LogicalBridgeId: args[0],
+				LogicalBridge:   &pb.LogicalBridge{Spec: spec},
+			})
+			if err != nil {
+				return err
+			}
+			return printObject(o, cmd.OutOrStdout(), bridgeKind.table, lb)
+		},
+	}
+	create.Flags().Uint32Var(&vlan, "vlan", 0, "vlan of the logical bridge")
+	create.Flags().Uint32Var(&vni, "vni", 0, "L2 vni of the logical bridge")
+	create.Flags().StringVar(&vtep, "vtep", "", "vtep prefix of the logical bridge, e.g. 10.0.0.1/32")
+	if err := create.MarkFlagRequired("vlan"); err != nil {
+		panic(err)
+	}
+	return bridgeKind.newCommand(o, create)
+}
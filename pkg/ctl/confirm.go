@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ctl implements the command line client of the bridge gRPC API
+package ctl
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+func newConfirmCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "confirm <change>",
+		Short: "keep a change applied with a confirm timeout, which the bridge reverts otherwise",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := o.context()
+			defer cancel()
+			u := url.URL{Scheme: "http", Host: o.httpAddress, Path: "/v1/admin/schedules/" + args[0] + "/confirm"}
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), http.NoBody)
+			if err != nil {
+				return err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				body, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("failed to confirm %s: %s: %s", args[0], resp.Status, body)
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s confirmed\n", args[0])
+			return err
+		},
+	}
+}
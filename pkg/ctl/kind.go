@@ -0,0 +1,228 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ctl implements the command line client of the bridge gRPC API
+package ctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// analyzeOnlyHeader asks the bridge to only report what a delete would affect
+	analyzeOnlyHeader = "opi-analyze-only"
+	// impactHeader lists the affected resources and kernel devices of an analyzed delete
+	impactHeader = "opi-impact"
+)
+
+// kind describes how a bridge object is listed, shown and deleted
+type kind[T proto.Message] struct {
+	use        string
+	aliases    []string
+	short      string
+	collection string
+	table      table[T]
+	name       func(T) string
+	get        func(ctx context.Context, conn grpc.ClientConnInterface, name string) (T, error)
+	list       func(ctx context.Context, conn grpc.ClientConnInterface, pageToken string) ([]T, string, error)
+	delete     func(ctx context.Context, conn grpc.ClientConnInterface, name string, allowMissing bool, opts ...grpc.CallOption) error
+	// stats is the optional admin endpoint returning the counters of the object
+	stats string
+}
+
+// newCommand returns the command of the kind with its list, show and delete subcommands
+func (k *kind[T]) newCommand(o *options, create *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     k.use,
+		Aliases: k.aliases,
+		Short:   k.short,
+	}
+	cmd.AddCommand(create, k.newListCommand(o), k.newShowCommand(o), k.newDeleteCommand(o))
+	return cmd
+}
+
+// listAll walks through all the pages of the list
+func (k *kind[T]) listAll(o *options) ([]T, error) {
+	conn, err := o.dial()
+	if err != nil {
+		return nil, err
+	}
+	all := []T{}
+	token := ""
+	for {
+		ctx, cancel := o.context()
+		objs, next, err := k.list(ctx, conn, token)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, objs...)
+		if next == "" {
+			return all, nil
+		}
+		token = next
+	}
+}
+
+// names returns the names of all the objects of the kind
+func (k *kind[T]) names(o *options) ([]string, error) {
+	objs, err := k.listAll(o)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, obj := range objs {
+		names = append(names, k.name(obj))
+	}
+	return names, nil
+}
+
+func (k *kind[T]) newListCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "list the " + k.use + "s",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			objs, err := k.listAll(o)
+			if err != nil {
+				return err
+			}
+			return printList(o, cmd.OutOrStdout(), k.table, objs)
+		},
+	}
+}
+
+func (k *kind[T]) newShowCommand(o *options) *cobra.Command {
+	var stats bool
+	cmd := &cobra.Command{
+		Use:               "show <id>",
+		Aliases:           []string{"get"},
+		Short:             "show a " + k.use,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNames(o, k.names),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conn, err := o.dial()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context()
+			defer cancel()
+			obj, err := k.get(ctx, conn, fullName(k.collection, args[0]))
+			if err != nil {
+				return err
+			}
+			if !stats {
+				return printObject(o, cmd.OutOrStdout(), k.table, obj)
+			}
+			counters, err := o.getStats(ctx, fmt.Sprintf(k.stats, shortName(args[0])))
+			if err != nil {
+				return err
+			}
+			return k.printWithStats(o, cmd.OutOrStdout(), obj, counters)
+		},
+	}
+	if k.stats != "" {
+		cmd.Flags().BoolVar(&stats, "stats", false, "show the counters of the "+k.use)
+	}
+	return cmd
+}
+
+func (k *kind[T]) newDeleteCommand(o *options) *cobra.Command {
+	var allowMissing, analyzeOnly bool
+	cmd := &cobra.Command{
+		Use:               "delete <id>...",
+		Aliases:           []string{"rm"},
+		Short:             "delete " + k.use + "s",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeNames(o, k.names),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conn, err := o.dial()
+			if err != nil {
+				return err
+			}
+			for _, id := range args {
+				ctx, cancel := o.context()
+				if analyzeOnly {
+					ctx = metadata.AppendToOutgoingContext(ctx, analyzeOnlyHeader, "true")
+				}
+				var header metadata.MD
+				err := k.delete(ctx, conn, fullName(k.collection, id), allowMissing, grpc.Header(&header))
+				cancel()
+				if err != nil {
+					return err
+				}
+				if analyzeOnly {
+					fmt.Fprintf(cmd.OutOrStdout(), "deleting %s %s would affect:\n", k.use, shortName(id))
+					for _, affected := range header.Get(impactHeader) {
+						fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", affected)
+					}
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s deleted\n", k.use, shortName(id))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&allowMissing, "allow-missing", false, "do not fail when the "+k.use+" does not exist")
+	cmd.Flags().BoolVar(&analyzeOnly, "analyze-only", false, "only list the resources and kernel devices the delete would affect")
+	return cmd
+}
+
+// getStats reads the counters from the admin endpoint
+func (o *options) getStats(ctx context.Context, path string) (map[string]uint64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+o.httpAddress+path, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to read the counters: %s: %s", resp.Status, body)
+	}
+	counters := map[string]uint64{}
+	if err := json.NewDecoder(resp.Body).Decode(&counters); err != nil {
+		return nil, err
+	}
+	return counters, nil
+}
+
+// printWithStats writes the object followed by its counters
+func (k *kind[T]) printWithStats(o *options, w io.Writer, obj T, counters map[string]uint64) error {
+	if o.output != "table" {
+		v, err := toJSON(obj)
+		if err != nil {
+			return err
+		}
+		return o.printValue(w, map[string]interface{}{k.use: v, "stats": counters})
+	}
+	if err := printObject(o, w, k.table, obj); err != nil {
+		return err
+	}
+	keys := []string{}
+	for key := range counters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	rows := [][]string{}
+	for _, key := range keys {
+		rows = append(rows, []string{key, fmt.Sprint(counters[key])})
+	}
+	fmt.Fprintln(w)
+	return printTable(w, []string{"COUNTER", "VALUE"}, rows)
+}
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ctl implements the command line client of the bridge gRPC API
+package ctl
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"text/tabwriter"
+
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/yaml"
+)
+
+// outputFormats are the values accepted by --output
+var outputFormats = []string{"table", "json", "yaml"}
+
+// validateOutput checks the --output flag
+func (o *options) validateOutput() error {
+	for _, f := range outputFormats {
+		if o.output == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q, expected one of %s", o.output, strings.Join(outputFormats, ", "))
+}
+
+// table describes how the objects of a kind are printed as a table
+type table[T proto.Message] struct {
+	header []string
+	row    func(T) []string
+}
+
+// printTable writes the rows aligned in columns
+func printTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// toJSON translates a protobuf object to a generic json value
+func toJSON(msg proto.Message) (json.RawMessage, error) {
+	return protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+}
+
+// printValue writes the json value in the requested structured format
+func (o *options) printValue(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if o.output == "yaml" {
+		data, err = yaml.JSONToYAML(data)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// printList writes the objects in the requested format
+func printList[T proto.Message](o *options, w io.Writer, t table[T], objs []T) error {
+	if o.output == "table" {
+		rows := [][]string{}
+		for _, obj := range objs {
+			rows = append(rows, t.row(obj))
+		}
+		return printTable(w, t.header, rows)
+	}
+	values := []json.RawMessage{}
+	for _, obj := range objs {
+		v, err := toJSON(obj)
+		if err != nil {
+			return err
+		}
+		values = append(values, v)
+	}
+	return o.printValue(w, values)
+}
+
+// printObject writes a single object in the requested format
+func printObject[T proto.Message](o *options, w io.Writer, t table[T], obj T) error {
+	if o.output == "table" {
+		return printTable(w, t.header, [][]string{t.row(obj)})
+	}
+	v, err := toJSON(obj)
+	if err != nil {
+		return err
+	}
+	return o.printValue(w, v)
+}
+
+// prefixToPb translates a CIDR into its protobuf representation
+func prefixToPb(cidr string) (*pc.IPPrefix, error) {
+	if cidr == "" {
+		return nil, nil
+	}
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	ones, _ := ipnet.Mask.Size()
+	if v4 := ip.To4(); v4 != nil {
+		return &pc.IPPrefix{
+			Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: binary.BigEndian.Uint32(v4)}},
+			Len:  int32(ones),
+		}, nil
+	}
+	return &pc.IPPrefix{
+		Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET6, V4OrV6: &pc.IPAddress_V6Addr{V6Addr: ip.To16()}},
+		Len:  int32(ones),
+	}, nil
+}
+
+// prefixString returns the CIDR notation of a protobuf prefix, or an empty string when it is not set
+func prefixString(p *pc.IPPrefix) string {
+	if p == nil || p.GetAddr() == nil {
+		return ""
+	}
+	var ip net.IP
+	if p.GetAddr().GetAf() == pc.IpAf_IP_AF_INET6 {
+		ip = net.IP(p.GetAddr().GetV6Addr())
+	} else {
+		ip = make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, p.GetAddr().GetV4Addr())
+	}
+	return fmt.Sprintf("%s/%d", ip, p.GetLen())
+}
+
+// macString returns the printable mac address, or an empty string when it is not set
+func macString(mac []byte) string {
+	if len(mac) == 0 {
+		return ""
+	}
+	return net.HardwareAddr(mac).String()
+}
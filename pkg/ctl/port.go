@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ctl implements the command line client of the bridge gRPC API
+package ctl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+// portTypes maps the --type values to the bridge port types
+var portTypes = map[string]pb.BridgePortType{
+	"access": pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS,
+	"trunk":  pb.BridgePortType_BRIDGE_PORT_TYPE_TRUNK,
+}
+
+// portKind describes the bridge port commands
+var portKind = &kind[*pb.BridgePort]{
+	use:        "port",
+	aliases:    []string{"ports", "bridge-port", "bp"},
+	short:      "manage the bridge ports",
+	collection: "ports",
+	table: table[*pb.BridgePort]{
+		header: []string{"ID", "MAC", "TYPE", "BRIDGES", "STATUS"},
+		row: func(in *pb.BridgePort) []string {
+			bridges := []string{}
+			for _, lb := range in.GetSpec().GetLogicalBridges() {
+				bridges = append(bridges, shortName(lb))
+			}
+			return []string{
+				shortName(in.GetName()),
+				macString(in.GetSpec().GetMacAddress()),
+				strings.ToLower(strings.TrimPrefix(in.GetSpec().GetPtype().String(), "BRIDGE_PORT_TYPE_")),
+				strings.Join(bridges, ","),
+				operStatus(in.GetStatus().GetOperStatus()),
+			}
+		},
+	},
+	name: func(in *pb.BridgePort) string { return in.GetName() },
+	get: func(ctx context.Context, conn grpc.ClientConnInterface, name string) (*pb.BridgePort, error) {
+		return pb.NewBridgePortServiceClient(conn).GetBridgePort(ctx, &pb.GetBridgePortRequest{Name: name})
+	},
+	list: func(ctx context.Context, conn grpc.ClientConnInterface, pageToken string) ([]*pb.BridgePort, string, error) {
+		resp, err := pb.NewBridgePortServiceClient(conn).ListBridgePorts(ctx, &pb.ListBridgePortsRequest{PageToken: pageToken})
+		return resp.GetBridgePorts(), resp.GetNextPageToken(), err
+	},
+	delete: func(ctx context.Context, conn grpc.ClientConnInterface, name string, allowMissing bool, opts ...grpc.CallOption) error {
+		_, err := pb.NewBridgePortServiceClient(conn).DeleteBridgePort(ctx, &pb.DeleteBridgePortRequest{Name: name, AllowMissing: allowMissing}, opts...)
+		return err
+	},
+	stats: "/v1/admin/bridgeports/%s/stats",
+}
+
+func newPortCommand(o *options) *cobra.Command {
+	var mac, ptype string
+	var bridges []string
+	create := &cobra.Command{
+		Use:   "create <id>",
+		Short: "create a bridge port, the id is the name of the linux device",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hw, err := net.ParseMAC(mac)
+			if err != nil {
+				return fmt.Errorf("invalid --mac: %v", err)
+			}
+			t, ok := portTypes[ptype]
+			if !ok {
+				return fmt.Errorf("invalid --type %q, expected access or trunk", ptype)
+			}
+			spec := &pb.BridgePortSpec{MacAddress: hw, Ptype: t}
+			for _, lb := range bridges {
+				spec.LogicalBridges = append(spec.LogicalBridges, fullName("bridges", lb))
+			}
+			conn, err := o.dial()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context()
+			defer cancel()
+			bp, err := pb.NewBridgePortServiceClient(conn).CreateBridgePort(ctx, &pb.CreateBridgePortRequest{
+				BridgePortId: args[0],
+				BridgePort:   &pb.BridgePort{Spec: spec},
+			})
+			if err != nil {
+				return err
+			}
+			return printObject(o, cmd.OutOrStdout(), portKind.table, bp)
+		},
+	}
+	create.Flags().StringVar(&mac, "mac", "", "mac address of the bridge port")
+	create.Flags().StringVar(&ptype, "type", "access", "type of the bridge port, access or trunk")
+	create.Flags().StringSliceVar(&bridges, "bridge", nil, "logical bridges of the bridge port, repeat for a trunk")
+	if err := create.MarkFlagRequired("mac"); err != nil {
+		panic(err)
+	}
+	if err := create.RegisterFlagCompletionFunc("type", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return []string{"access", "trunk"}, cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		panic(err)
+	}
+	if err := create.RegisterFlagCompletionFunc("bridge", completeNames(o, bridgeKind.names)); err != nil {
+		panic(err)
+	}
+	return portKind.newCommand(o, create)
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ctl implements the command line client of the bridge gRPC API
+package ctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// quotaValue is the usage of a quota returned by the bridge, a zero limit is unlimited
+type quotaValue struct {
+	Name  string `json:"name,omitempty"`
+	Limit int    `json:"limit"`
+	Used  int    `json:"used"`
+}
+
+// quotaUsage is the usage of all the quotas returned by the bridge
+type quotaUsage struct {
+	Vnis           quotaValue   `json:"vnis"`
+	SvisPerVrf     []quotaValue `json:"svis_per_vrf"`
+	PortsPerBridge []quotaValue `json:"ports_per_bridge"`
+}
+
+// quotaRow formats the usage of a quota
+func quotaRow(quota string, v quotaValue) []string {
+	limit := "unlimited"
+	if v.Limit > 0 {
+		limit = strconv.Itoa(v.Limit)
+	}
+	scope := "-"
+	if v.Name != "" {
+		scope = shortName(v.Name)
+	}
+	return []string{quota, scope, strconv.Itoa(v.Used), limit}
+}
+
+func newQuotaCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:     "quotas",
+		Aliases: []string{"quota"},
+		Short:   "show the limits and the usage of the quotas",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, cancel := o.context()
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+o.httpAddress+"/v1/admin/quotas", http.NoBody)
+			if err != nil {
+				return err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				body, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("failed to read the quotas: %s: %s", resp.Status, body)
+			}
+			usage := &quotaUsage{}
+			if err := json.NewDecoder(resp.Body).Decode(usage); err != nil {
+				return err
+			}
+			if o.output != "table" {
+				return o.printValue(cmd.OutOrStdout(), usage)
+			}
+			rows := [][]string{quotaRow("vnis", usage.Vnis)}
+			for _, v := range usage.SvisPerVrf {
+				rows = append(rows, quotaRow("svis-per-vrf", v))
+			}
+			for _, v := range usage.PortsPerBridge {
+				rows = append(rows, quotaRow("ports-per-bridge", v))
+			}
+			return printTable(cmd.OutOrStdout(), []string{"QUOTA", "SCOPE", "USED", "LIMIT"}, rows)
+		},
+	}
+}
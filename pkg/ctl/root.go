@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ctl implements the command line client of the bridge gRPC API
+package ctl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/client"
+)
+
+// options shared by all the commands
+type options struct {
+	address     string
+	httpAddress string
+	output      string
+	timeout     time.Duration
+	parent      string
+
+	conn *grpc.ClientConn
+}
+
+// NewRootCommand returns the opi-evpn-ctl command with all its subcommands
+func NewRootCommand() *cobra.Command {
+	o := &options{}
+	cmd := &cobra.Command{
+		Use:           "opi-evpn-ctl",
+		Short:         "evpn bridge command line client",
+		Long:          "command line client managing the vrfs, logical bridges, bridge ports and svis of the evpn bridge",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return o.validateOutput()
+		},
+		PersistentPostRunE: func(_ *cobra.Command, _ []string) error {
+			if o.conn == nil {
+				return nil
+			}
+			return o.conn.Close()
+		},
+	}
+	cmd.PersistentFlags().StringVar(&o.address, "address", "localhost:50151", "gRPC address of the bridge")
+	cmd.PersistentFlags().StringVar(&o.httpAddress, "http-address", "localhost:8082", "HTTP address of the bridge, used for the operational endpoints")
+	cmd.PersistentFlags().StringVarP(&o.output, "output", "o", "table", "output format, one of table, json or yaml")
+	cmd.PersistentFlags().DurationVar(&o.timeout, "timeout", 10*time.Second, "timeout of each request")
+	cmd.PersistentFlags().StringVar(&o.parent, "parent", "", "tenant id, or full name of a VPC, scoping the gRPC requests")
+	if err := cmd.RegisterFlagCompletionFunc("output", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return outputFormats, cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		panic(err)
+	}
+
+	cmd.AddCommand(newVrfCommand(o), newBridgeCommand(o), newPortCommand(o), newSviCommand(o), newApplyCommand(o), newConfirmCommand(o), newQuotaCommand(o))
+	return cmd
+}
+
+// dial connects to the bridge gRPC API, the connection is closed once the command completes
+func (o *options) dial() (*grpc.ClientConn, error) {
+	if o.conn != nil {
+		return o.conn, nil
+	}
+	conn, err := grpc.Dial(o.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %v", o.address, err)
+	}
+	o.conn = conn
+	return conn, nil
+}
+
+// context returns the context of a single request, carrying the parent of the request
+func (o *options) context() (context.Context, context.CancelFunc) {
+	ctx := context.Background()
+	if o.parent != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "parent", fullName("tenants", o.parent))
+	}
+	return context.WithTimeout(ctx, o.timeout)
+}
+
+// fullName returns the bridge name of the object, the id can be given with or without its collection
+func fullName(collection, id string) string {
+	return client.FullName(collection, id)
+}
+
+// shortName returns the id of the object, which is what the user types on the command line
+func shortName(name string) string {
+	return client.ID(name)
+}
+
+// operStatus trims the enum prefix of the operational status
+func operStatus(s fmt.Stringer) string {
+	str := s.String()
+	if i := strings.Index(str, "OPER_STATUS_"); i >= 0 {
+		return str[i+len("OPER_STATUS_"):]
+	}
+	return str
+}
+
+// completeNames offers the ids returned by list as completion of an argument or a flag
+func completeNames(o *options, list func(o *options) ([]string, error)) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		names, err := list(o)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		ids := []string{}
+		for _, name := range names {
+			ids = append(ids, shortName(name))
+		}
+		return ids, cobra.ShellCompDirectiveNoFileComp
+	}
+}
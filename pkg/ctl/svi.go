@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ctl implements the command line client of the bridge gRPC API
+package ctl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+// sviKind describes the svi commands, an svi is the routed subnet of a logical bridge in a vrf
+var sviKind = &kind[*pb.Svi]{
+	use:        "svi",
+	aliases:    []string{"svis", "subnet", "subnets"},
+	short:      "manage the svis (subnets)",
+	collection: "svis",
+	table: table[*pb.Svi]{
+		header: []string{"ID", "VRF", "BRIDGE", "GATEWAYS", "BGP", "STATUS"},
+		row: func(in *pb.Svi) []string {
+			gateways := []string{}
+			for _, gw := range in.GetSpec().GetGwIpPrefix() {
+				gateways = append(gateways, prefixString(gw))
+			}
+			bgp := ""
+			if in.GetSpec().GetEnableBgp() {
+				bgp = fmt.Sprintf("AS%d", in.GetSpec().GetRemoteAs())
+			}
+			return []string{
+				shortName(in.GetName()),
+				shortName(in.GetSpec().GetVrf()),
+				shortName(in.GetSpec().GetLogicalBridge()),
+				strings.Join(gateways, ","),
+				bgp,
+				operStatus(in.GetStatus().GetOperStatus()),
+			}
+		},
+	},
+	name: func(in *pb.Svi) string { return in.GetName() },
+	get: func(ctx context.Context, conn grpc.ClientConnInterface, name string) (*pb.Svi, error) {
+		return pb.NewSviServiceClient(conn).GetSvi(ctx, &pb.GetSviRequest{Name: name})
+	},
+	list: func(ctx context.Context, conn grpc.ClientConnInterface, pageToken string) ([]*pb.Svi, string, error) {
+		resp, err := pb.NewSviServiceClient(conn).ListSvis(ctx, &pb.ListSvisRequest{PageToken: pageToken})
+		return resp.GetSvis(), resp.GetNextPageToken(), err
+	},
+	delete: func(ctx context.Context, conn grpc.ClientConnInterface, name string, allowMissing bool, opts ...grpc.CallOption) error {
+		_, err := pb.NewSviServiceClient(conn).DeleteSvi(ctx, &pb.DeleteSviRequest{Name: name, AllowMissing: allowMissing}, opts...)
+		return err
+	},
+}
+
+func newSviCommand(o *options) *cobra.Command {
+	var vrf, bridge, mac string
+	var gateways []string
+	var remoteAs uint32
+	create := &cobra.Command{
+		Use:   "create <id>",
+		Short: "create an svi",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hw, err := net.ParseMAC(mac)
+			if err != nil {
+				return fmt.Errorf("invalid --mac: %v", err)
+			}
+			spec := &pb.SviSpec{
+				Vrf:           fullName("vrfs", vrf),
+				LogicalBridge: fullName("bridges", bridge),
+				MacAddress:    hw,
+				EnableBgp:     remoteAs != 0,
+				RemoteAs:      remoteAs,
+			}
+			for _, gw := range gateways {
+				p, err := prefixToPb(gw)
+				if err != nil {
+					return fmt.Errorf("invalid --gateway: %v", err)
+				}
+				spec.GwIpPrefix = append(spec.GwIpPrefix, p)
+			}
+			conn, err := o.dial()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context()
+			defer cancel()
+			svi, err := pb.NewSviServiceClient(conn).CreateSvi(ctx, &pb.CreateSviRequest{SviId: args[0], Svi: &pb.Svi{Spec: spec}})
+			if err != nil {
+				return err
+			}
+			return printObject(o, cmd.OutOrStdout(), sviKind.table, svi)
+		},
+	}
+	create.Flags().StringVar(&vrf, "vrf", "", "vrf of the svi")
+	create.Flags().StringVar(&bridge, "bridge", "", "logical bridge of the svi")
+	create.Flags().StringVar(&mac, "mac", "", "mac address of the svi")
+	create.Flags().StringSliceVar(&gateways, "gateway", nil, "gateway prefixes of the svi, e.g. 10.0.0.1/24, none for a link-local only svi")
+	create.Flags().Uint32Var(&remoteAs, "remote-as", 0, "enables BGP towards the workloads of the svi with this remote AS")
+	for _, flag := range []string{"vrf", "bridge", "mac"} {
+		if err := create.MarkFlagRequired(flag); err != nil {
+			panic(err)
+		}
+	}
+	if err := create.RegisterFlagCompletionFunc("vrf", completeNames(o, vrfKind.names)); err != nil {
+		panic(err)
+	}
+	if err := create.RegisterFlagCompletionFunc("bridge", completeNames(o, bridgeKind.names)); err != nil {
+		panic(err)
+	}
+	return sviKind.newCommand(o, create)
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ctl implements the command line client of the bridge gRPC API
+package ctl
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+// vrfKind describes the vrf commands, a vrf is the vpc of a tenant
+var vrfKind = &kind[*pb.Vrf]{
+	use:        "vrf",
+	aliases:    []string{"vrfs", "vpc", "vpcs"},
+	short:      "manage the vrfs (vpcs)",
+	collection: "vrfs",
+	table: table[*pb.Vrf]{
+		header: []string{"ID", "VNI", "LOOPBACK", "VTEP", "STATUS"},
+		row: func(in *pb.Vrf) []string {
+			vni := ""
+			if in.GetSpec().Vni != nil {
+				vni = fmt.Sprint(in.GetSpec().GetVni())
+			}
+			return []string{
+				shortName(in.GetName()),
+				vni,
+				prefixString(in.GetSpec().GetLoopbackIpPrefix()),
+				prefixString(in.GetSpec().GetVtepIpPrefix()),
+				operStatus(in.GetStatus().GetOperStatus()),
+			}
+		},
+	},
+	name: func(in *pb.Vrf) string { return in.GetName() },
+	get: func(ctx context.Context, conn grpc.ClientConnInterface, name string) (*pb.Vrf, error) {
+		return pb.NewVrfServiceClient(conn).GetVrf(ctx, &pb.GetVrfRequest{Name: name})
+	},
+	list: func(ctx context.Context, conn grpc.ClientConnInterface, pageToken string) ([]*pb.Vrf, string, error) {
+		resp, err := pb.NewVrfServiceClient(conn).ListVrfs(ctx, &pb.ListVrfsRequest{PageToken: pageToken})
+		return resp.GetVrfs(), resp.GetNextPageToken(), err
+	},
+	delete: func(ctx context.Context, conn grpc.ClientConnInterface, name string, allowMissing bool, opts ...grpc.CallOption) error {
+		_, err := pb.NewVrfServiceClient(conn).DeleteVrf(ctx, &pb.DeleteVrfRequest{Name: name, AllowMissing: allowMissing}, opts...)
+		return err
+	},
+}
+
+func newVrfCommand(o *options) *cobra.Command {
+	var vni uint32
+	var loopback, vtep string
+	create := &cobra.Command{
+		Use:   "create <id>",
+		Short: "create a vrf",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spec := &pb.VrfSpec{}
+			if cmd.Flags().Changed("vni") {
+				spec.Vni = &vni
+			}
+			var err error
+			if spec.LoopbackIpPrefix, err = prefixToPb(loopback); err != nil {
+				return fmt.Errorf("invalid --loopback: %v", err)
+			}
+			if spec.VtepIpPrefix, err = prefixToPb(vtep); err != nil {
+				return fmt.Errorf("invalid --vtep: %v", err)
+			}
+			conn, err := o.dial()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context()
+			defer cancel()
+			vrf, err := pb.NewVrfServiceClient(conn).CreateVrf(ctx, &pb.CreateVrfRequest{VrfId: args[0], Vrf: &pb.Vrf{Spec: spec}})
+			if err != nil {
+				return err
+			}
+			return printObject(o, cmd.OutOrStdout(), vrfKind.table, vrf)
+		},
+	}
+	create.Flags().Uint32Var(&vni, "vni", 0, "L3 vni of the vrf")
+	create.Flags().StringVar(&loopback, "loopback", "", "loopback prefix of the vrf, e.g. 4.4.4.4/32, the router id is the vtep without it")
+	create.Flags().StringVar(&vtep, "vtep", "", "vtep prefix of the vrf, e.g. 10.0.0.1/32")
+	return vrfKind.newCommand(o, create)
+}
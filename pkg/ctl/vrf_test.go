@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ctl implements the command line client of the bridge gRPC API
+package ctl
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+)
+
+// fakeVrfServer returns one vrf per page
+type fakeVrfServer struct {
+	pb.UnimplementedVrfServiceServer
+	vrfs []*pb.Vrf
+}
+
+func (s *fakeVrfServer) ListVrfs(_ context.Context, in *pb.ListVrfsRequest) (*pb.ListVrfsResponse, error) {
+	i := 0
+	if in.PageToken != "" {
+		i = int(in.PageToken[0] - '0')
+	}
+	resp := &pb.ListVrfsResponse{Vrfs: s.vrfs[i : i+1]}
+	if i+1 < len(s.vrfs) {
+		resp.NextPageToken = string(rune('0' + i + 1))
+	}
+	return resp, nil
+}
+
+// startFakeVrfServer serves the vrfs on a random local port
+func startFakeVrfServer(t *testing.T, vrfs ...*pb.Vrf) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	pb.RegisterVrfServiceServer(s, &fakeVrfServer{vrfs: vrfs})
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func Test_ListVrfs(t *testing.T) {
+	vni := uint32(1000)
+	loopback, _ := prefixToPb("4.4.4.4/32")
+	vrfs := []*pb.Vrf{
+		{
+			Name:   fullName("vrfs", "blue"),
+			Spec:   &pb.VrfSpec{Vni: &vni, LoopbackIpPrefix: loopback},
+			Status: &pb.VrfStatus{OperStatus: pb.VRFOperStatus_VRF_OPER_STATUS_UP},
+		},
+		{
+			Name:   fullName("vrfs", "red"),
+			Spec:   &pb.VrfSpec{},
+			Status: &pb.VrfStatus{OperStatus: pb.VRFOperStatus_VRF_OPER_STATUS_DOWN},
+		},
+	}
+	tests := map[string]struct {
+		args []string
+		out  string
+		err  bool
+	}{
+		"table": {
+			args: []string{"vrf", "list"},
+			out: "ID    VNI   LOOPBACK    VTEP  STATUS\n" +
+				"blue  1000  4.4.4.4/32        UP\n" +
+				"red                           DOWN\n",
+		},
+		"json through the vpc alias": {
+			args: []string{"vpc", "list", "-o", "json"},
+			out: `[
+  {
+    "name": "//network.opiproject.org/vrfs/blue",
+    "spec": {
+      "vni": 1000,
+      "loopback_ip_prefix": {
+        "addr": {
+          "af": "IP_AF_INET",
+          "v4_addr": 67372036
+        },
+        "len": 32
+      }
+    },
+    "status": {
+      "oper_status": "VRF_OPER_STATUS_UP"
+    }
+  },
+  {
+    "name": "//network.opiproject.org/vrfs/red",
+    "spec": {},
+    "status": {
+      "oper_status": "VRF_OPER_STATUS_DOWN"
+    }
+  }
+]
+`,
+		},
+		"yaml": {
+			args: []string{"vrf", "ls", "-o", "yaml"},
+			out: `- name: //network.opiproject.org/vrfs/blue
+  spec:
+    loopback_ip_prefix:
+      addr:
+        af: IP_AF_INET
+        v4_addr: 67372036
+      len: 32
+    vni: 1000
+  status:
+    oper_status: VRF_OPER_STATUS_UP
+- name: //network.opiproject.org/vrfs/red
+  spec: {}
+  status:
+    oper_status: VRF_OPER_STATUS_DOWN
+`,
+		},
+		"unknown output": {
+			args: []string{"vrf", "list", "-o", "xml"},
+			err:  true,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			addr := startFakeVrfServer(t, vrfs...)
+			cmd := NewRootCommand()
+			out := &bytes.Buffer{}
+			cmd.SetOut(out)
+			cmd.SetArgs(append([]string{"--address", addr}, tt.args...))
+			err := cmd.Execute()
+			if (err != nil) != tt.err {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tt.err && out.String() != tt.out {
+				t.Errorf("expected output\n%s\nreceived\n%s", tt.out, out.String())
+			}
+		})
+	}
+}
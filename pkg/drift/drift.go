@@ -0,0 +1,341 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package drift compares the intended state of the bridge with the kernel and the routing stack
+package drift
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// Kinds of differences
+const (
+	// MissingDevice is a kernel device of an operationally up resource which does not exist
+	MissingDevice = "missing-device"
+	// ExtraDevice is a kernel device created for a resource which does not exist anymore
+	ExtraDevice = "extra-device"
+	// ExtraFdbEntry is a MAC address installed by the routing stack on a vxlan device which it does not know anymore
+	ExtraFdbEntry = "extra-fdb-entry"
+	// StaleRoute is a bgp route of the routing table of a vrf which is not a path of its bgp table anymore
+	StaleRoute = "stale-route"
+	// MissingRoutingVrf is a vrf which is not in the running config of the routing stack
+	MissingRoutingVrf = "missing-routing-vrf"
+	// ExtraRoutingVrf is a vrf of the running config of the routing stack which does not exist
+	ExtraRoutingVrf = "extra-routing-vrf"
+	// RoutingVniMismatch is a vrf whose L3 VNI differs in the running config of the routing stack
+	RoutingVniMismatch = "routing-vni-mismatch"
+	// MissingBgpInstance is a vrf without bgp instance in the running config of the routing stack
+	MissingBgpInstance = "missing-bgp-instance"
+)
+
+// zeroMac is the address of the flooding entries of the vxlan devices
+const zeroMac = "00:00:00:00:00:00"
+
+// Difference is an object whose state differs from the intended one
+type Difference struct {
+	Kind string
+	// Object is the device, MAC address, prefix or vrf which differs
+	Object string
+	// Resource is the resource the object belongs to, empty when it belongs to none
+	Resource string
+	Details  string
+}
+
+// Report lists the differences found by a check
+type Report struct {
+	Time        time.Time
+	Differences []Difference
+	// Skipped are the comparisons which could not be made, with the reason
+	Skipped []string
+}
+
+// Check compares the operationally up resources with the kernel state and the running config of the
+// routing backend, nothing is repaired. A comparison which cannot be made is reported as skipped.
+func Check(ctx context.Context, backend routing.Backend) (*Report, error) {
+	graph, err := infradb.GetDependencyGraph()
+	if err != nil {
+		return nil, err
+	}
+	vrfs, err := infradb.GetAllVrfs()
+	if err != nil && err != infradb.ErrKeyNotFound {
+		return nil, err
+	}
+	lbs, err := infradb.GetAllLBs()
+	if err != nil && err != infradb.ErrKeyNotFound {
+		return nil, err
+	}
+	report := &Report{Time: time.Now()}
+	skip := func(check string, err error) {
+		report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %v", check, err))
+	}
+
+	// the kernel state of a vrf moved to a network namespace is not in the one of the bridge
+	for _, vrf := range vrfs {
+		if vrf.Spec.Netns != "" {
+			skip(vrf.Name, fmt.Errorf("in network namespace %s", vrf.Spec.Netns))
+		}
+	}
+
+	if links, err := gen_linux.GetKernelLinks(ctx); err != nil {
+		skip("devices", err)
+	} else {
+		report.Differences = append(report.Differences, compareDevices(outsideNetns(graph, vrfs), links)...)
+	}
+
+	if reporter, ok := backend.(routing.MacReporter); !ok {
+		skip("fdb", fmt.Errorf("the %s backend does not report the MAC addresses", backend.Name()))
+	} else if macs, err := reporter.EvpnMacs(ctx); err != nil {
+		skip("fdb", err)
+	} else {
+		for _, lb := range lbs {
+			if lb.Status.LBOperStatus != infradb.LogicalBridgeOperStatusUp || lb.Spec.Vni == nil || lb.Spec.IsGeneve() {
+				continue
+			}
+			fdb, err := gen_linux.GetVxlanFdb(ctx, lb)
+			if err != nil {
+				skip("fdb of "+lb.Name, err)
+				continue
+			}
+			report.Differences = append(report.Differences, compareFdb(lb.Name, *lb.Spec.Vni, fdb, macs)...)
+		}
+	}
+
+	for _, vrf := range vrfs {
+		if vrf.Status.VrfOperStatus != infradb.VrfOperStatusUp || path.Base(vrf.Name) == "GRD" || vrf.Spec.Netns != "" {
+			continue
+		}
+		kernelRoutes, err := gen_linux.GetVrfRoutes(ctx, vrf)
+		if err != nil {
+			skip("routes of "+vrf.Name, err)
+			continue
+		}
+		bgpRoutes, err := backend.BgpRoutes(ctx, vrf.Name)
+		if err != nil {
+			skip("routes of "+vrf.Name, err)
+			continue
+		}
+		report.Differences = append(report.Differences, compareRoutes(vrf.Name, kernelRoutes, bgpRoutes)...)
+	}
+
+	if reporter, ok := backend.(routing.ConfigReporter); !ok {
+		skip("routing config", fmt.Errorf("the %s backend does not report its running config", backend.Name()))
+	} else if running, err := reporter.RunningVrfs(ctx); err != nil {
+		skip("routing config", err)
+	} else {
+		report.Differences = append(report.Differences, compareRoutingVrfs(intendedRoutingVrfs(vrfs), running)...)
+	}
+
+	sort.SliceStable(report.Differences, func(i, j int) bool {
+		if report.Differences[i].Kind != report.Differences[j].Kind {
+			return report.Differences[i].Kind < report.Differences[j].Kind
+		}
+		return report.Differences[i].Object < report.Differences[j].Object
+	})
+	return report, nil
+}
+
+// outsideNetns returns the graph without the devices of the vrfs moved to a network namespace and of their svis
+func outsideNetns(graph *infradb.DependencyGraph, vrfs []*infradb.Vrf) *infradb.DependencyGraph {
+	moved := map[string]bool{}
+	for _, vrf := range vrfs {
+		if vrf.Spec.Netns != "" {
+			moved[vrf.Name] = true
+		}
+	}
+	if len(moved) == 0 {
+		return graph
+	}
+	svis := map[string]bool{}
+	for _, n := range graph.Nodes {
+		if n.Kind == "svi" {
+			svis[n.ID] = true
+		}
+	}
+	for _, e := range graph.Edges {
+		if e.Relation == infradb.GraphRelationReferences && moved[e.To] && svis[e.From] {
+			moved[e.From] = true
+		}
+	}
+	out := &infradb.DependencyGraph{Nodes: graph.Nodes}
+	for _, e := range graph.Edges {
+		if e.Relation == infradb.GraphRelationProgrammedAs && moved[e.From] {
+			continue
+		}
+		out.Edges = append(out.Edges, e)
+	}
+	return out
+}
+
+// compareDevices finds the devices of the up resources which are missing, and the devices left behind by
+// the resources which are gone
+func compareDevices(graph *infradb.DependencyGraph, links []gen_linux.KernelLink) []Difference {
+	resources := map[string]*infradb.GraphNode{}
+	for _, n := range graph.Nodes {
+		if n.Kind != infradb.GraphKindNetdev {
+			resources[n.ID] = n
+		}
+	}
+	present := map[string]bool{}
+	for _, link := range links {
+		present[link.Name] = true
+	}
+
+	diffs := []Difference{}
+	reported := map[string]bool{}
+	for _, e := range graph.Edges {
+		if e.Relation != infradb.GraphRelationProgrammedAs || resources[e.From] == nil || !resources[e.From].Up {
+			continue
+		}
+		dev := strings.TrimPrefix(e.To, "netdev:")
+		if present[dev] || reported[dev] {
+			continue
+		}
+		reported[dev] = true
+		diffs = append(diffs, Difference{Kind: MissingDevice, Object: dev, Resource: e.From})
+	}
+	for _, link := range links {
+		if link.Owner != "" && resources[link.Owner] == nil {
+			diffs = append(diffs, Difference{Kind: ExtraDevice, Object: link.Name, Resource: link.Owner,
+				Details: "created for a resource which does not exist"})
+		}
+	}
+	return diffs
+}
+
+// compareFdb finds the MAC addresses installed by the routing stack on the vxlan device of the logical bridge
+// which are not remote addresses of its VNI
+func compareFdb(lb string, vni uint32, fdb []gen_linux.KernelFdbEntry, macs []routing.EvpnMac) []Difference {
+	remote := map[string]bool{}
+	for _, mac := range macs {
+		if mac.Vni == vni && mac.RemoteVtep != "" {
+			remote[strings.ToLower(mac.Mac)] = true
+		}
+	}
+	diffs := []Difference{}
+	for _, entry := range fdb {
+		if !entry.ExternLearned() || entry.Mac == zeroMac || remote[strings.ToLower(entry.Mac)] {
+			continue
+		}
+		details := fmt.Sprintf("on %s, not a remote MAC address of the VNI %d", entry.Ifname, vni)
+		if entry.Dst != "" {
+			details = fmt.Sprintf("on %s to %s, not a remote MAC address of the VNI %d", entry.Ifname, entry.Dst, vni)
+		}
+		diffs = append(diffs, Difference{Kind: ExtraFdbEntry, Object: entry.Mac, Resource: lb, Details: details})
+	}
+	return diffs
+}
+
+// compareRoutes finds the bgp routes of the routing table of the vrf which are not installed paths of its bgp table
+func compareRoutes(vrf string, kernelRoutes []gen_linux.KernelRoute, bgpRoutes []routing.BgpRoute) []Difference {
+	installed := map[netip.Prefix]bool{}
+	for _, r := range bgpRoutes {
+		if !r.Best && !r.Multipath {
+			continue
+		}
+		if prefix, err := parsePrefix(r.Prefix); err == nil {
+			installed[prefix] = true
+		}
+	}
+	diffs := []Difference{}
+	for _, r := range kernelRoutes {
+		if r.Protocol != "bgp" {
+			continue
+		}
+		prefix, err := parsePrefix(r.Dst)
+		if err != nil || installed[prefix] {
+			continue
+		}
+		diffs = append(diffs, Difference{Kind: StaleRoute, Object: prefix.String(), Resource: vrf,
+			Details: "not an installed path of the bgp table"})
+	}
+	return diffs
+}
+
+// parsePrefix reads the destinations of iproute2 and the prefixes of bgp, the host routes have no length
+// and the default route is named default
+func parsePrefix(dst string) (netip.Prefix, error) {
+	if dst == "default" {
+		return netip.MustParsePrefix("0.0.0.0/0"), nil
+	}
+	if !strings.Contains(dst, "/") {
+		addr, err := netip.ParseAddr(dst)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(dst)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return prefix.Masked(), nil
+}
+
+// intendedVrf is a vrf as it should be in the running config of the routing stack
+type intendedVrf struct {
+	resource string
+	// configured tells whether the routing stack is given the vrf, only the vrfs with a VNI are
+	configured bool
+	vni        uint32
+}
+
+// intendedRoutingVrfs returns the vrfs by the name of their kernel device
+func intendedRoutingVrfs(vrfs []*infradb.Vrf) map[string]*intendedVrf {
+	intended := map[string]*intendedVrf{}
+	for _, vrf := range vrfs {
+		if path.Base(vrf.Name) == "GRD" {
+			continue
+		}
+		v := &intendedVrf{resource: vrf.Name}
+		if vrf.Status.VrfOperStatus == infradb.VrfOperStatusUp && vrf.Spec.Vni != nil {
+			v.configured = true
+			if !vrf.Spec.IsVpn() {
+				v.vni = *vrf.Spec.Vni
+			}
+		}
+		intended[infradb.LinkName(vrf.Name, infradb.LinkRoleVrf)] = v
+	}
+	return intended
+}
+
+// compareRoutingVrfs finds the vrfs missing from the running config of the routing stack, the ones which
+// should not be there and the ones which differ
+func compareRoutingVrfs(intended map[string]*intendedVrf, running []routing.RunningVrf) []Difference {
+	diffs := []Difference{}
+	seen := map[string]bool{}
+	for _, r := range running {
+		seen[r.Name] = true
+		v, ok := intended[r.Name]
+		if !ok {
+			diffs = append(diffs, Difference{Kind: ExtraRoutingVrf, Object: r.Name, Details: "no vrf has this device"})
+			continue
+		}
+		if !v.configured {
+			continue
+		}
+		if r.Vni != v.vni {
+			diffs = append(diffs, Difference{Kind: RoutingVniMismatch, Object: r.Name, Resource: v.resource,
+				Details: fmt.Sprintf("vni %d instead of %d", r.Vni, v.vni)})
+		}
+		if !r.Bgp {
+			diffs = append(diffs, Difference{Kind: MissingBgpInstance, Object: r.Name, Resource: v.resource})
+		}
+	}
+	for name, v := range intended {
+		if v.configured && !seen[name] {
+			diffs = append(diffs, Difference{Kind: MissingRoutingVrf, Object: name, Resource: v.resource})
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package drift compares the intended state of the bridge with the kernel and the routing stack
+package drift
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+func sortDifferences(diffs []Difference) []Difference {
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Kind != diffs[j].Kind {
+			return diffs[i].Kind < diffs[j].Kind
+		}
+		return diffs[i].Object < diffs[j].Object
+	})
+	return diffs
+}
+
+func Test_CompareDevices(t *testing.T) {
+	const (
+		vrf = "//network.opiproject.org/vrfs/blue"
+		svi = "//network.opiproject.org/svis/blue"
+		bp  = "//network.opiproject.org/ports/eth2"
+	)
+	graph := &infradb.DependencyGraph{
+		Nodes: []*infradb.GraphNode{
+			{ID: vrf, Kind: "vrf", Up: true},
+			{ID: svi, Kind: "svi", Up: true},
+			{ID: bp, Kind: "bridge-port"},
+			{ID: "netdev:blue", Kind: infradb.GraphKindNetdev},
+			{ID: "netdev:blue-10", Kind: infradb.GraphKindNetdev},
+			{ID: "netdev:eth2", Kind: infradb.GraphKindNetdev},
+		},
+		Edges: []*infradb.GraphEdge{
+			{From: svi, To: vrf, Relation: infradb.GraphRelationReferences},
+			{From: vrf, To: "netdev:blue", Relation: infradb.GraphRelationProgrammedAs},
+			{From: svi, To: "netdev:blue-10", Relation: infradb.GraphRelationProgrammedAs},
+			{From: bp, To: "netdev:eth2", Relation: infradb.GraphRelationProgrammedAs},
+		},
+	}
+	links := []gen_linux.KernelLink{
+		{Name: "blue", Owner: vrf},
+		{Name: "red", Owner: "//network.opiproject.org/vrfs/red"},
+		{Name: "br-tenant"},
+		{Name: "lo"},
+	}
+	expected := []Difference{
+		{Kind: ExtraDevice, Object: "red", Resource: "//network.opiproject.org/vrfs/red", Details: "created for a resource which does not exist"},
+		// the port is not up yet, its device is not expected
+		{Kind: MissingDevice, Object: "blue-10", Resource: svi},
+	}
+	if diffs := sortDifferences(compareDevices(graph, links)); !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("expected %+v, received %+v", expected, diffs)
+	}
+}
+
+func Test_CompareDevicesInNetns(t *testing.T) {
+	const (
+		vrf = "//network.opiproject.org/vrfs/blue"
+		svi = "//network.opiproject.org/svis/blue"
+		bp  = "//network.opiproject.org/ports/eth2"
+	)
+	graph := &infradb.DependencyGraph{
+		Nodes: []*infradb.GraphNode{
+			{ID: vrf, Kind: "vrf", Up: true},
+			{ID: svi, Kind: "svi", Up: true},
+			{ID: bp, Kind: "bridge-port", Up: true},
+			{ID: "netdev:blue", Kind: infradb.GraphKindNetdev},
+			{ID: "netdev:blue-10", Kind: infradb.GraphKindNetdev},
+			{ID: "netdev:eth2", Kind: infradb.GraphKindNetdev},
+		},
+		Edges: []*infradb.GraphEdge{
+			{From: svi, To: vrf, Relation: infradb.GraphRelationReferences},
+			{From: vrf, To: "netdev:blue", Relation: infradb.GraphRelationProgrammedAs},
+			{From: svi, To: "netdev:blue-10", Relation: infradb.GraphRelationProgrammedAs},
+			{From: bp, To: "netdev:eth2", Relation: infradb.GraphRelationProgrammedAs},
+		},
+	}
+	vrfs := []*infradb.Vrf{{Name: vrf, Spec: &infradb.VrfSpec{Netns: "tenant-a"}}}
+	// the devices of the vrf and of its svi are in the namespace, the port is missing from the bridge
+	expected := []Difference{{Kind: MissingDevice, Object: "eth2", Resource: bp}}
+	if diffs := sortDifferences(compareDevices(outsideNetns(graph, vrfs), nil)); !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("expected %+v, received %+v", expected, diffs)
+	}
+}
+
+func Test_CompareFdb(t *testing.T) {
+	fdb := []gen_linux.KernelFdbEntry{
+		{Mac: "aa:bb:cc:00:00:01", Ifname: "vxlan-10", Flags: []string{"extern_learn"}},
+		{Mac: "aa:bb:cc:00:00:02", Ifname: "vxlan-10", Flags: []string{"extern_learn"}},
+		{Mac: "aa:bb:cc:00:00:03", Ifname: "vxlan-10"},
+		{Mac: zeroMac, Ifname: "vxlan-10", Flags: []string{"extern_learn"}, Dst: "10.0.0.2"},
+	}
+	macs := []routing.EvpnMac{
+		{Vni: 100, Mac: "AA:BB:CC:00:00:01", RemoteVtep: "10.0.0.2"},
+		// learnt locally, the routing stack does not install it
+		{Vni: 100, Mac: "aa:bb:cc:00:00:02"},
+		{Vni: 200, Mac: "aa:bb:cc:00:00:02", RemoteVtep: "10.0.0.3"},
+	}
+	expected := []Difference{
+		{Kind: ExtraFdbEntry, Object: "aa:bb:cc:00:00:02", Resource: "//network.opiproject.org/bridges/blue", Details: "on vxlan-10, not a remote MAC address of the VNI 100"},
+	}
+	if diffs := compareFdb("//network.opiproject.org/bridges/blue", 100, fdb, macs); !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("expected %+v, received %+v", expected, diffs)
+	}
+}
+
+func Test_CompareRoutes(t *testing.T) {
+	kernelRoutes := []gen_linux.KernelRoute{
+		{Dst: "default", Protocol: "bgp"},
+		{Dst: "10.1.0.0/24", Protocol: "bgp"},
+		{Dst: "10.2.0.5", Protocol: "bgp"},
+		{Dst: "10.3.0.0/24", Protocol: "bgp"},
+		{Dst: "10.0.0.0/29", Protocol: "kernel"},
+	}
+	bgpRoutes := []routing.BgpRoute{
+		{Prefix: "0.0.0.0/0", Best: true},
+		{Prefix: "10.1.0.0/24", Multipath: true},
+		{Prefix: "10.2.0.5/32", Best: true},
+		// a valid path which is not installed
+		{Prefix: "10.3.0.0/24"},
+	}
+	expected := []Difference{
+		{Kind: StaleRoute, Object: "10.3.0.0/24", Resource: "//network.opiproject.org/vrfs/blue", Details: "not an installed path of the bgp table"},
+	}
+	if diffs := compareRoutes("//network.opiproject.org/vrfs/blue", kernelRoutes, bgpRoutes); !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("expected %+v, received %+v", expected, diffs)
+	}
+}
+
+func Test_CompareRoutingVrfs(t *testing.T) {
+	intended := map[string]*intendedVrf{
+		"blue":   {resource: "//network.opiproject.org/vrfs/blue", configured: true, vni: 1000},
+		"red":    {resource: "//network.opiproject.org/vrfs/red", configured: true, vni: 2000},
+		"green":  {resource: "//network.opiproject.org/vrfs/green", configured: true},
+		"yellow": {resource: "//network.opiproject.org/vrfs/yellow"},
+	}
+	running := []routing.RunningVrf{
+		{Name: "blue", Vni: 1000, Bgp: true},
+		{Name: "red", Vni: 2001},
+		{Name: "yellow"},
+		{Name: "purple", Vni: 3000, Bgp: true},
+	}
+	expected := []Difference{
+		{Kind: ExtraRoutingVrf, Object: "purple", Details: "no vrf has this device"},
+		{Kind: MissingBgpInstance, Object: "red", Resource: "//network.opiproject.org/vrfs/red"},
+		{Kind: MissingRoutingVrf, Object: "green", Resource: "//network.opiproject.org/vrfs/green"},
+		{Kind: RoutingVniMismatch, Object: "red", Resource: "//network.opiproject.org/vrfs/red", Details: "vni 2001 instead of 2000"},
+	}
+	if diffs := sortDifferences(compareRoutingVrfs(intended, running)); !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("expected %+v, received %+v", expected, diffs)
+	}
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package dupaddr alerts on the addresses flagged as duplicate by the EVPN control plane, a MAC address
+// moving back and forth between the VTEPs being the telltale of a loop in a tenant network
+package dupaddr
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+const defaultInterval = 10 * time.Second
+
+// key identifies a duplicate address across the checks, its number of moves changes
+type key struct {
+	vni     uint32
+	mac, ip string
+}
+
+// Watcher checks the duplicates at every interval and alerts on the addresses newly flagged
+type Watcher struct {
+	interval time.Duration
+	frozen   bool
+	detector routing.DuplicateDetector
+	known    map[key]bool
+	// notify sends the alert of a duplicate address
+	notify func(dup routing.Duplicate, details string)
+}
+
+// newWatcher returns the watcher of the duplicates of the detector
+func newWatcher(cfg *config.DupAddrDetectionConfig, detector routing.DuplicateDetector) *Watcher {
+	w := &Watcher{
+		interval: time.Duration(cfg.Interval) * time.Second,
+		frozen:   cfg.Freeze != 0,
+		detector: detector,
+		known:    map[key]bool{},
+		notify:   notifyBridge,
+	}
+	if w.interval == 0 {
+		w.interval = defaultInterval
+	}
+	return w
+}
+
+// Details describes the duplicate address in an alert
+func Details(dup routing.Duplicate, frozen bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "mac %s", dup.Mac)
+	if dup.IP != "" {
+		fmt.Fprintf(&b, " ip %s", dup.IP)
+	}
+	fmt.Fprintf(&b, " of vni %d is duplicate after %d moves", dup.Vni, dup.Moves)
+	if dup.RemoteVtep != "" {
+		fmt.Fprintf(&b, ", learnt last from %s", dup.RemoteVtep)
+	} else {
+		b.WriteString(", learnt last locally")
+	}
+	if frozen {
+		b.WriteString(", frozen")
+	}
+	return b.String()
+}
+
+// notifyBridge sends the alert on the logical bridge of the VNI, the duplicates of the other VNIs are only logged
+func notifyBridge(dup routing.Duplicate, details string) {
+	log.Printf("dupaddr: %s\n", details)
+	lb, err := infradb.GetLBByVni(dup.Vni)
+	if err != nil {
+		return
+	}
+	infradb.NotifyAlert(infradb.StatusEventDuplicate, "logical-bridge", lb.Name, lb.ResourceVersion, details)
+}
+
+// check alerts on the duplicates which were not flagged at the previous check, an address cleared and
+// flagged again is alerted on again
+func (w *Watcher) check(ctx context.Context) {
+	dups, err := w.detector.Duplicates(ctx)
+	if err != nil {
+		log.Printf("dupaddr: failed to read the duplicate addresses: %v\n", err)
+		return
+	}
+	current := make(map[key]bool, len(dups))
+	for _, dup := range dups {
+		k := key{vni: dup.Vni, mac: dup.Mac, ip: dup.IP}
+		current[k] = true
+		if !w.known[k] {
+			w.notify(dup, Details(dup, w.frozen))
+		}
+	}
+	w.known = current
+}
+
+// Run checks the duplicates at every interval until the context is done
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Start sets the thresholds of the detection in the routing backend and watches the duplicates, it does
+// nothing unless enabled
+func Start(ctx context.Context, cfg *config.Config, backend routing.Backend) error {
+	dc := &cfg.DupAddr
+	if !dc.Enabled {
+		return nil
+	}
+	detector, ok := backend.(routing.DuplicateDetector)
+	if !ok {
+		return fmt.Errorf("the %s routing backend does not detect the duplicate addresses", backend.Name())
+	}
+	detection := routing.DupAddrDetection{MaxMoves: dc.MaxMoves, Time: dc.Time, Freeze: dc.Freeze}
+	if err := detector.ConfigureDupAddrDetection(ctx, detection); err != nil {
+		return fmt.Errorf("dupaddr: %w", err)
+	}
+	w := newWatcher(dc, detector)
+	go w.Run(ctx)
+	log.Printf("dupaddr: checking the duplicate addresses every %v\n", w.interval)
+	return nil
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package dupaddr alerts on the addresses flagged as duplicate by the EVPN control plane, a MAC address
+// moving back and forth between the VTEPs being the telltale of a loop in a tenant network
+package dupaddr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// fakeDetector reports the duplicates it is given
+type fakeDetector struct {
+	dups []routing.Duplicate
+}
+
+func (*fakeDetector) ConfigureDupAddrDetection(context.Context, routing.DupAddrDetection) error {
+	return nil
+}
+
+func (d *fakeDetector) Duplicates(context.Context) ([]routing.Duplicate, error) {
+	return d.dups, nil
+}
+
+func (*fakeDetector) ClearDuplicate(context.Context, uint32, string, string) error {
+	return nil
+}
+
+func Test_Check(t *testing.T) {
+	mac := routing.Duplicate{Vni: 1000, Mac: "aa:bb:cc:00:00:01", RemoteVtep: "10.0.0.2", Moves: 5}
+	neigh := routing.Duplicate{Vni: 1000, Mac: "aa:bb:cc:00:00:01", IP: "10.0.0.5", Moves: 5}
+	detector := &fakeDetector{}
+	w := newWatcher(&config.DupAddrDetectionConfig{Freeze: -1}, detector)
+	alerts := []string{}
+	w.notify = func(_ routing.Duplicate, details string) { alerts = append(alerts, details) }
+
+	detector.dups = []routing.Duplicate{mac}
+	w.check(context.Background())
+	// the address is alerted on once while it stays duplicate
+	mac.Moves = 6
+	detector.dups = []routing.Duplicate{mac, neigh}
+	w.check(context.Background())
+	// cleared, then flagged again
+	detector.dups = nil
+	w.check(context.Background())
+	detector.dups = []routing.Duplicate{mac}
+	w.check(context.Background())
+
+	expected := []string{
+		"mac aa:bb:cc:00:00:01 of vni 1000 is duplicate after 5 moves, learnt last from 10.0.0.2, frozen",
+		"mac aa:bb:cc:00:00:01 ip 10.0.0.5 of vni 1000 is duplicate after 5 moves, learnt last locally, frozen",
+		"mac aa:bb:cc:00:00:01 of vni 1000 is duplicate after 6 moves, learnt last from 10.0.0.2, frozen",
+	}
+	if !reflect.DeepEqual(alerts, expected) {
+		t.Errorf("expected the alerts %q, received %q", expected, alerts)
+	}
+	if w.interval != defaultInterval {
+		t.Errorf("expected the default interval, received %v", w.interval)
+	}
+}
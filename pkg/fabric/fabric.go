@@ -0,0 +1,393 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package fabric probes the remote VTEPs to measure the loss and the latency of the underlay
+package fabric
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+const (
+	defaultInterval = 10 * time.Second
+	defaultCount    = 5
+	defaultTimeout  = time.Second
+	defaultSize     = 64
+	// DefaultPort is the port of the responder of the udp probes
+	DefaultPort = 4800
+	// window is the number of the last probes of a peer the loss and the latency are computed over
+	window = 100
+	// imetRoute is the EVPN route type whose nexthops are the remote VTEPs of the logical bridges
+	imetRoute = 3
+)
+
+// Source of the peers
+const (
+	SourceConfig = "config"
+	SourceEvpn   = "evpn"
+)
+
+// PeerHealth is the loss and the latency of the last probes of a remote VTEP
+type PeerHealth struct {
+	Peer   string
+	Source string
+	// Sent and Received count the probes since the peer is probed
+	Sent     uint64
+	Received uint64
+	// Loss is the ratio of the last probes lost, between 0 and 1
+	Loss                            float64
+	RttLast, RttMin, RttAvg, RttMax time.Duration
+	// Jitter is the mean deviation of the round trip times of consecutive probes
+	Jitter    time.Duration
+	LastProbe time.Time
+	// Error is the failure of the last round, the probes could not be sent
+	Error string
+}
+
+// peerState holds the results of the last probes of a peer, a zero round trip time is a lost probe
+type peerState struct {
+	source   string
+	sent     uint64
+	received uint64
+	results  []time.Duration
+	last     time.Time
+	err      string
+}
+
+// record adds the result of a probe to the window
+func (s *peerState) record(rtt time.Duration) {
+	s.sent++
+	if rtt > 0 {
+		s.received++
+	}
+	s.results = append(s.results, rtt)
+	if len(s.results) > window {
+		s.results = s.results[len(s.results)-window:]
+	}
+}
+
+// health computes the loss and the latency over the window
+func (s *peerState) health(peer string) PeerHealth {
+	h := PeerHealth{Peer: peer, Source: s.source, Sent: s.sent, Received: s.received, LastProbe: s.last, Error: s.err}
+	if len(s.results) == 0 {
+		return h
+	}
+	var lost, received int
+	var sum, deviation time.Duration
+	var previous time.Duration
+	for _, rtt := range s.results {
+		if rtt == 0 {
+			lost++
+			continue
+		}
+		if received == 0 || rtt < h.RttMin {
+			h.RttMin = rtt
+		}
+		h.RttMax = max(h.RttMax, rtt)
+		if previous != 0 {
+			deviation += (rtt - previous).Abs()
+		}
+		previous = rtt
+		sum += rtt
+		h.RttLast = rtt
+		received++
+	}
+	h.Loss = float64(lost) / float64(len(s.results))
+	if received != 0 {
+		h.RttAvg = sum / time.Duration(received)
+	}
+	if received > 1 {
+		h.Jitter = deviation / time.Duration(received-1)
+	}
+	return h
+}
+
+var (
+	lossGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "opi_evpn_fabric_peer_loss_ratio",
+		Help: "Ratio of the last probes of a remote VTEP which were lost.",
+	}, []string{"peer"})
+	rttGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "opi_evpn_fabric_peer_rtt_seconds",
+		Help: "Average round trip time of the last probes of a remote VTEP.",
+	}, []string{"peer"})
+	jitterGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "opi_evpn_fabric_peer_jitter_seconds",
+		Help: "Mean deviation of the round trip times of the last probes of a remote VTEP.",
+	}, []string{"peer"})
+)
+
+// Collectors returns the metrics of the probes
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{lossGauge, rttGauge, jitterGauge}
+}
+
+// Monitor probes the peers at every interval
+type Monitor struct {
+	mu       sync.Mutex
+	peers    map[string]*peerState
+	mode     string
+	port     int
+	count    int
+	size     int
+	timeout  time.Duration
+	interval time.Duration
+	static   []netip.Addr
+	discover bool
+	local    netip.Addr
+	dialer   *net.Dialer
+	// evpnRoutes returns the routes of the remote VTEPs
+	evpnRoutes func(ctx context.Context) ([]routing.EvpnRoute, error)
+	nextID     atomic.Uint32
+}
+
+// newMonitor returns the monitor of the peers of the config
+func newMonitor(cfg *config.FabricHealthConfig, local netip.Addr) (*Monitor, error) {
+	m := &Monitor{
+		peers:    make(map[string]*peerState),
+		mode:     cfg.Mode,
+		port:     cfg.Port,
+		count:    cfg.Count,
+		size:     cfg.Size,
+		timeout:  time.Duration(cfg.Timeout) * time.Millisecond,
+		interval: time.Duration(cfg.Interval) * time.Second,
+		discover: cfg.Discover,
+		local:    local,
+		dialer:   &net.Dialer{},
+		evpnRoutes: func(ctx context.Context) ([]routing.EvpnRoute, error) {
+			backend, err := routing.Get()
+			if err != nil {
+				return nil, err
+			}
+			return backend.EvpnRoutes(ctx)
+		},
+	}
+	if m.mode == "" {
+		m.mode = ModeIcmp
+	}
+	if m.port == 0 {
+		m.port = DefaultPort
+	}
+	if m.count == 0 {
+		m.count = defaultCount
+	}
+	if m.size == 0 {
+		m.size = defaultSize
+	}
+	if m.timeout == 0 {
+		m.timeout = defaultTimeout
+	}
+	if m.interval == 0 {
+		m.interval = defaultInterval
+	}
+	for _, peer := range cfg.Peers {
+		addr, err := netip.ParseAddr(peer)
+		if err != nil {
+			return nil, err
+		}
+		m.static = append(m.static, addr)
+	}
+	return m, nil
+}
+
+// targets returns the peers to probe with their source, the configured peers first
+func (m *Monitor) targets(ctx context.Context) map[netip.Addr]string {
+	out := make(map[netip.Addr]string)
+	if m.discover {
+		routes, err := m.evpnRoutes(ctx)
+		if err != nil {
+			log.Printf("fabric: failed to read the remote VTEPs of the EVPN routes: %v\n", err)
+		}
+		for _, route := range routes {
+			if route.RouteType != imetRoute {
+				continue
+			}
+			for _, nh := range route.Nexthops {
+				if addr, err := netip.ParseAddr(nh); err == nil && addr.Unmap() != m.local && !addr.IsUnspecified() {
+					out[addr.Unmap()] = SourceEvpn
+				}
+			}
+		}
+	}
+	for _, addr := range m.static {
+		out[addr] = SourceConfig
+	}
+	return out
+}
+
+// probe runs a round of probes of the peer
+func (m *Monitor) probe(peer netip.Addr) ([]time.Duration, error) {
+	p, err := newPinger(m.mode, m.dialer, peer, uint16(m.nextID.Add(1)), m.port, m.size)
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+	results := make([]time.Duration, 0, m.count)
+	for seq := 0; seq < m.count; seq++ {
+		rtt, err := p.ping(uint32(seq), m.timeout)
+		if err != nil && !errors.Is(err, errLost) {
+			return results, err
+		}
+		results = append(results, rtt)
+	}
+	return results, nil
+}
+
+// round probes the peers in parallel and forgets the peers which are gone
+func (m *Monitor) round(ctx context.Context) {
+	targets := m.targets(ctx)
+	var wg sync.WaitGroup
+	for peer, source := range targets {
+		wg.Add(1)
+		go func(peer netip.Addr, source string) {
+			defer wg.Done()
+			results, err := m.probe(peer)
+			m.record(peer.String(), source, results, err)
+		}(peer, source)
+	}
+	wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for peer := range m.peers {
+		if _, ok := targets[netip.MustParseAddr(peer)]; !ok {
+			delete(m.peers, peer)
+			lossGauge.DeleteLabelValues(peer)
+			rttGauge.DeleteLabelValues(peer)
+			jitterGauge.DeleteLabelValues(peer)
+		}
+	}
+}
+
+// record stores the results of a round and updates the metrics of the peer
+func (m *Monitor) record(peer, source string, results []time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.peers[peer]
+	if !ok {
+		s = &peerState{}
+		m.peers[peer] = s
+	}
+	s.source = source
+	s.last = time.Now()
+	s.err = ""
+	if err != nil {
+		s.err = err.Error()
+	}
+	for _, rtt := range results {
+		s.record(rtt)
+	}
+	h := s.health(peer)
+	lossGauge.WithLabelValues(peer).Set(h.Loss)
+	rttGauge.WithLabelValues(peer).Set(h.RttAvg.Seconds())
+	jitterGauge.WithLabelValues(peer).Set(h.Jitter.Seconds())
+}
+
+// Health returns the health of the peers, by address
+func (m *Monitor) Health() []PeerHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]PeerHealth, 0, len(m.peers))
+	for peer, s := range m.peers {
+		out = append(out, s.health(peer))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return netip.MustParseAddr(out[i].Peer).Less(netip.MustParseAddr(out[j].Peer))
+	})
+	return out
+}
+
+// Run probes the peers at every interval until the context is done
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.round(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Respond echoes the udp probes of the remote bridges until the context is done
+func Respond(ctx context.Context, conn net.PacketConn) {
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	buf := make([]byte, 65536)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("fabric: the responder stopped: %v\n", err)
+			}
+			return
+		}
+		if _, _, ok := decodeProbe(buf[:n]); !ok {
+			continue
+		}
+		if _, err := conn.WriteTo(buf[:n], from); err != nil {
+			log.Printf("fabric: failed to answer the probe of %v: %v\n", from, err)
+		}
+	}
+}
+
+// running is the monitor started with the bridge
+var running atomic.Pointer[Monitor]
+
+// Start runs the responder and the probes of the remote VTEPs
+func Start(ctx context.Context, cfg *config.Config) error {
+	fc := &cfg.FabricHealth
+	if !fc.Enabled {
+		return nil
+	}
+	// the node does not probe itself
+	vtep := strings.Split(cfg.Underlay.VtepIP, "/")[0]
+	if vtep == "" {
+		vtep = utils.GetIPAddress(cfg.LinuxFrr.DefaultVtep).IP.String()
+	}
+	local, _ := netip.ParseAddr(vtep)
+	m, err := newMonitor(fc, local)
+	if err != nil {
+		return err
+	}
+	conn, err := net.ListenPacket("udp", net.JoinHostPort("", strconv.Itoa(m.port)))
+	if err != nil {
+		return err
+	}
+	go Respond(ctx, conn)
+	running.Store(m)
+	go m.Run(ctx)
+	log.Printf("fabric: probing the remote VTEPs with %s every %v\n", m.mode, m.interval)
+	return nil
+}
+
+// Health returns the health of the remote VTEPs, none when the probes do not run
+func Health() []PeerHealth {
+	if m := running.Load(); m != nil {
+		return m.Health()
+	}
+	return []PeerHealth{}
+}
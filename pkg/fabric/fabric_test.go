@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package fabric probes the remote VTEPs to measure the loss and the latency of the underlay
+package fabric
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+func Test_Health(t *testing.T) {
+	s := &peerState{}
+	for _, rtt := range []time.Duration{2 * time.Millisecond, 0, 4 * time.Millisecond, 3 * time.Millisecond} {
+		s.record(rtt)
+	}
+	h := s.health("10.0.0.2")
+	if h.Sent != 4 || h.Received != 3 || h.Loss != 0.25 {
+		t.Errorf("unexpected counters %+v", h)
+	}
+	if h.RttMin != 2*time.Millisecond || h.RttMax != 4*time.Millisecond || h.RttAvg != 3*time.Millisecond || h.RttLast != 3*time.Millisecond {
+		t.Errorf("unexpected round trip times %+v", h)
+	}
+	// |4-2| and |3-4| over two deviations
+	if h.Jitter != 1500*time.Microsecond {
+		t.Errorf("expected a jitter of 1.5ms, received %v", h.Jitter)
+	}
+
+	// the loss is computed over the window
+	for i := 0; i < window; i++ {
+		s.record(time.Millisecond)
+	}
+	if h := s.health("10.0.0.2"); h.Loss != 0 || h.Sent != uint64(window+4) {
+		t.Errorf("expected the lost probe to leave the window, received %+v", h)
+	}
+}
+
+func Test_ProbeUDP(t *testing.T) {
+	for _, mode := range []string{ModeUDP, ModeVxlan} {
+		t.Run(mode, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			go Respond(ctx, conn)
+
+			m, err := newMonitor(&config.FabricHealthConfig{
+				Mode:  mode,
+				Port:  conn.LocalAddr().(*net.UDPAddr).Port,
+				Count: 3,
+				Size:  1400,
+				Peers: []string{"127.0.0.1"},
+			}, netip.Addr{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.round(ctx)
+			health := m.Health()
+			if len(health) != 1 || health[0].Received != 3 || health[0].Loss != 0 || health[0].RttAvg == 0 {
+				t.Errorf("unexpected health %+v", health)
+			}
+		})
+	}
+}
+
+func Test_ProbeLost(t *testing.T) {
+	// nothing answers on the port of a closed socket
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	conn.Close()
+
+	m, err := newMonitor(&config.FabricHealthConfig{Mode: ModeUDP, Port: port, Count: 2, Timeout: 50, Peers: []string{"127.0.0.1"}}, netip.Addr{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.round(context.Background())
+	if health := m.Health(); len(health) != 1 || health[0].Loss != 1 || health[0].Error != "" {
+		t.Errorf("unexpected health %+v", health)
+	}
+}
+
+func Test_Targets(t *testing.T) {
+	m, err := newMonitor(&config.FabricHealthConfig{Discover: true, Peers: []string{"10.0.0.9"}}, netip.MustParseAddr("10.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.evpnRoutes = func(context.Context) ([]routing.EvpnRoute, error) {
+		return []routing.EvpnRoute{
+			{RouteType: 3, Nexthops: []string{"10.0.0.2"}},
+			// the routes originated by the node
+			{RouteType: 3, Nexthops: []string{"10.0.0.1"}},
+			{RouteType: 2, Nexthops: []string{"10.0.0.3"}},
+			{RouteType: 3, Nexthops: []string{"10.0.0.9"}},
+		}, nil
+	}
+	targets := m.targets(context.Background())
+	expected := map[netip.Addr]string{
+		netip.MustParseAddr("10.0.0.2"): SourceEvpn,
+		netip.MustParseAddr("10.0.0.9"): SourceConfig,
+	}
+	if len(targets) != len(expected) {
+		t.Fatalf("expected %v, received %v", expected, targets)
+	}
+	for peer, source := range expected {
+		if targets[peer] != source {
+			t.Errorf("expected %s from %s, received %q", peer, source, targets[peer])
+		}
+	}
+}
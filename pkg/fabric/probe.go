@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package fabric probes the remote VTEPs to measure the loss and the latency of the underlay
+package fabric
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Modes of the probes
+const (
+	// ModeIcmp sends ICMP echo requests, answered by the kernel of the remote VTEPs
+	ModeIcmp = "icmp"
+	// ModeUDP sends udp probes, echoed by the responder of the remote bridges
+	ModeUDP = "udp"
+	// ModeVxlan sends the udp probes behind a VXLAN header, so that they have the size of the tenant traffic
+	ModeVxlan = "vxlan"
+)
+
+const (
+	// magic starts the udp probes
+	magic = 0x4f504946
+	// probeLen is the magic, the sequence and the send time of a probe
+	probeLen = 16
+	// vxlanLen is the VXLAN header of the vxlan probes
+	vxlanLen = 8
+	// vxlanFlagVni tells that the VNI of the VXLAN header is valid
+	vxlanFlagVni = 0x08
+)
+
+// errLost is the probe without answer before the timeout
+var errLost = errors.New("probe lost")
+
+// pinger sends the probes of a round to a peer
+type pinger interface {
+	ping(seq uint32, timeout time.Duration) (time.Duration, error)
+	Close() error
+}
+
+// encodeProbe encodes the udp probe, padded to size
+func encodeProbe(seq uint32, sent time.Time, size int, vxlan bool) []byte {
+	b := make([]byte, 0, max(size, vxlanLen+probeLen))
+	if vxlan {
+		// the VNI 0 is not used by the tenants
+		b = append(b, vxlanFlagVni, 0, 0, 0, 0, 0, 0, 0)
+	}
+	b = binary.BigEndian.AppendUint32(b, magic)
+	b = binary.BigEndian.AppendUint32(b, seq)
+	b = binary.BigEndian.AppendUint64(b, uint64(sent.UnixNano()))
+	if len(b) < size {
+		b = append(b, make([]byte, size-len(b))...)
+	}
+	return b
+}
+
+// decodeProbe returns the sequence and the send time of a udp probe
+func decodeProbe(b []byte) (uint32, time.Time, bool) {
+	if len(b) >= vxlanLen+probeLen && b[0] == vxlanFlagVni {
+		b = b[vxlanLen:]
+	}
+	if len(b) < probeLen || binary.BigEndian.Uint32(b) != magic {
+		return 0, time.Time{}, false
+	}
+	return binary.BigEndian.Uint32(b[4:]), time.Unix(0, int64(binary.BigEndian.Uint64(b[8:]))), true
+}
+
+// udpPinger sends the udp probes from a source port of its own, the rounds take different paths of the ECMP underlay
+type udpPinger struct {
+	conn  net.Conn
+	size  int
+	vxlan bool
+	buf   []byte
+}
+
+// newUDPPinger connects to the responder of the peer
+func newUDPPinger(dialer *net.Dialer, peer netip.Addr, port, size int, vxlan bool) (pinger, error) {
+	conn, err := dialer.Dial("udp", netip.AddrPortFrom(peer, uint16(port)).String())
+	if err != nil {
+		return nil, err
+	}
+	return &udpPinger{conn: conn, size: size, vxlan: vxlan, buf: make([]byte, 65536)}, nil
+}
+
+func (p *udpPinger) ping(seq uint32, timeout time.Duration) (time.Duration, error) {
+	start := time.Now()
+	if _, err := p.conn.Write(encodeProbe(seq, start, p.size, p.vxlan)); err != nil {
+		return 0, err
+	}
+	deadline := start.Add(timeout)
+	_ = p.conn.SetReadDeadline(deadline)
+	for {
+		n, err := p.conn.Read(p.buf)
+		if err != nil {
+			var ne net.Error
+			// the port is unreachable while the remote bridge does not run the responder
+			if errors.As(err, &ne) && ne.Timeout() || errors.Is(err, syscall.ECONNREFUSED) {
+				return 0, errLost
+			}
+			return 0, err
+		}
+		// the late answers of the previous probes are skipped
+		if got, _, ok := decodeProbe(p.buf[:n]); ok && got == seq {
+			return time.Since(start), nil
+		}
+	}
+}
+
+func (p *udpPinger) Close() error {
+	return p.conn.Close()
+}
+
+// icmpPinger sends ICMP echo requests with an identifier of its own
+type icmpPinger struct {
+	conn  net.PacketConn
+	peer  netip.Addr
+	id    uint16
+	size  int
+	reply byte
+	buf   []byte
+}
+
+// ICMP echo types
+const (
+	icmpEcho       = 8
+	icmpEchoReply  = 0
+	icmp6Echo      = 128
+	icmp6EchoReply = 129
+)
+
+// newIcmpPinger opens the raw socket of the family of the peer
+func newIcmpPinger(peer netip.Addr, id uint16, size int) (pinger, error) {
+	network, reply := "ip4:icmp", byte(icmpEchoReply)
+	if peer.Is6() && !peer.Is4In6() {
+		network, reply = "ip6:ipv6-icmp", icmp6EchoReply
+	}
+	conn, err := net.ListenPacket(network, "")
+	if err != nil {
+		return nil, err
+	}
+	return &icmpPinger{conn: conn, peer: peer.Unmap(), id: id, size: size, reply: reply, buf: make([]byte, 65536)}, nil
+}
+
+// checksum is the internet checksum of the ICMP message
+func checksum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(b[i:]))
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
+
+// encodeEcho encodes the echo request, the kernel fills the checksum of the ICMPv6 messages
+func encodeEcho(typ byte, id uint16, seq uint32, size int) []byte {
+	b := []byte{typ, 0, 0, 0}
+	b = binary.BigEndian.AppendUint16(b, id)
+	b = binary.BigEndian.AppendUint16(b, uint16(seq))
+	if size > len(b) {
+		b = append(b, make([]byte, size-len(b))...)
+	}
+	if typ == icmpEcho {
+		binary.BigEndian.PutUint16(b[2:], checksum(b))
+	}
+	return b
+}
+
+func (p *icmpPinger) ping(seq uint32, timeout time.Duration) (time.Duration, error) {
+	typ := byte(icmpEcho)
+	if p.reply == icmp6EchoReply {
+		typ = icmp6Echo
+	}
+	start := time.Now()
+	if _, err := p.conn.WriteTo(encodeEcho(typ, p.id, seq, p.size), &net.IPAddr{IP: p.peer.AsSlice()}); err != nil {
+		return 0, err
+	}
+	_ = p.conn.SetReadDeadline(start.Add(timeout))
+	for {
+		n, from, err := p.conn.ReadFrom(p.buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return 0, errLost
+			}
+			return 0, err
+		}
+		// the raw socket receives the answers of all the pingers
+		addr, ok := from.(*net.IPAddr)
+		if !ok || n < 8 || p.buf[0] != p.reply || !addr.IP.Equal(p.peer.AsSlice()) {
+			continue
+		}
+		if binary.BigEndian.Uint16(p.buf[4:]) == p.id && binary.BigEndian.Uint16(p.buf[6:]) == uint16(seq) {
+			return time.Since(start), nil
+		}
+	}
+}
+
+func (p *icmpPinger) Close() error {
+	return p.conn.Close()
+}
+
+// newPinger returns the pinger of the mode
+func newPinger(mode string, dialer *net.Dialer, peer netip.Addr, id uint16, port, size int) (pinger, error) {
+	switch mode {
+	case ModeIcmp:
+		return newIcmpPinger(peer, id, size)
+	case ModeUDP, ModeVxlan:
+		return newUDPPinger(dialer, peer, port, size, mode == ModeVxlan)
+	default:
+		return nil, fmt.Errorf("unknown fabric probe mode %s", mode)
+	}
+}
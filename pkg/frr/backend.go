@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// Backend is the routing backend which programs FRR through its vty
+type Backend struct{}
+
+// build time check that struct implements interface
+var _ routing.Backend = Backend{}
+var _ routing.EncapSupporter = Backend{}
+
+// Name returns the name of the backend and of the infradb component
+func (Backend) Name() string {
+	return frrComp
+}
+
+// Initialize subscribes FRR to the infradb events
+func (Backend) Initialize() {
+	Initialize()
+}
+
+// DeInitialize unsubscribes FRR from the infradb events
+func (Backend) DeInitialize() {
+	DeInitialize()
+}
+
+// Probe checks that the FRR daemons accept vty connections
+func (Backend) Probe(ctx context.Context) error {
+	return Probe(ctx)
+}
+
+// DeepProbe checks that the FRR daemons answer commands
+func (Backend) DeepProbe(ctx context.Context) error {
+	return DeepProbe(ctx)
+}
+
+// Encaps returns VXLAN only: zebra binds the EVPN VNIs to the vxlan devices, it does not know the geneve ones
+func (Backend) Encaps() []string {
+	return []string{routing.EncapVxlan}
+}
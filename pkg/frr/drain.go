@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// drainRouteMap is the route map applied to the EVPN peers of the default instance while the node is drained
+const drainRouteMap = "OPI-DRAIN"
+
+// drainPrependCount is the number of times the local AS is prepended while the node is drained
+const drainPrependCount = 3
+
+// drainCmds renders the configuration which drains the node, or undoes it. The withdraw mode stops
+// advertising the VNIs and the prefixes of the vrfs, the other modes apply a route map to the EVPN peers.
+func drainCmds(mode routing.DrainMode, drained bool, peers []string, vrfs []string) string {
+	no := ""
+	if drained {
+		no = "no "
+	}
+	var cmds strings.Builder
+	cmds.WriteString("configure terminal\n")
+	if mode == routing.DrainWithdraw {
+		fmt.Fprintf(&cmds, " router bgp %+v\n address-family l2vpn evpn\n %sadvertise-all-vni\n exit-address-family\n exit\n", localas, no)
+		for _, vrf := range vrfs {
+			fmt.Fprintf(&cmds, " router bgp %+v vrf %s\n address-family l2vpn evpn\n %sadvertise ipv4 unicast\n exit-address-family\n exit\n", localas, vrf, no)
+		}
+		cmds.WriteString(" exit\n")
+		return cmds.String()
+	}
+	if drained {
+		fmt.Fprintf(&cmds, " route-map %s permit 10\n", drainRouteMap)
+		if mode == routing.DrainMed {
+			cmds.WriteString("  set metric 4294967295\n")
+		} else {
+			fmt.Fprintf(&cmds, "  set as-path prepend %s\n", strings.TrimSpace(strings.Repeat(fmt.Sprintf("%d ", localas), drainPrependCount)))
+		}
+		cmds.WriteString(" exit\n")
+	}
+	fmt.Fprintf(&cmds, " router bgp %+v\n address-family l2vpn evpn\n", localas)
+	for _, peer := range peers {
+		if drained {
+			fmt.Fprintf(&cmds, " neighbor %s route-map %s out\n", peer, drainRouteMap)
+		} else {
+			fmt.Fprintf(&cmds, " no neighbor %s route-map %s out\n", peer, drainRouteMap)
+		}
+	}
+	cmds.WriteString(" exit-address-family\n exit\n")
+	if !drained {
+		fmt.Fprintf(&cmds, " no route-map %s\n", drainRouteMap)
+	}
+	cmds.WriteString(" exit\n")
+	return cmds.String()
+}
+
+// SetDrain drains the node, or undoes it, through the configuration of bgpd
+func (Backend) SetDrain(ctx context.Context, mode routing.DrainMode, drained bool) error {
+	if !config.GlobalConfig.LinuxFrr.Enabled {
+		return nil
+	}
+	if frr == nil {
+		return ErrNotInitialized
+	}
+	summary := map[string]json.RawMessage{}
+	if err := bgpShow(ctx, "show bgp summary json", &summary); err != nil {
+		return err
+	}
+	peers := []string{}
+	for _, peer := range parseBgpPeers(summary, nil) {
+		if peer.Afi == "l2VpnEvpn" {
+			peers = append(peers, peer.Address)
+		}
+	}
+	vrfs := []string{}
+	objs, err := infradb.GetAllVrfs()
+	if err != nil && err != infradb.ErrKeyNotFound {
+		return err
+	}
+	for _, vrf := range objs {
+		if path.Base(vrf.Name) != "GRD" && vrf.Spec.Vni != nil {
+			vrfs = append(vrfs, frrVrfName(vrf.Name))
+		}
+	}
+
+	cmds := drainCmds(mode, drained, peers, vrfs)
+	if _, err := frr.FrrBgpCmd(ctx, cmds, false); err != nil {
+		log.Printf("FRR: Error in draining the node (%s, %v): %v\n", mode, drained, err)
+		return err
+	}
+	if err := frr.Save(ctx); err != nil {
+		log.Printf("FRR(SetDrain): Failed to run save command: %v\n", err)
+	}
+	log.Printf("FRR: Executed %s\n", cmds)
+	return nil
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+func Test_DrainCmds(t *testing.T) {
+	localas = 65000
+	peers := []string{"10.0.0.2"}
+	vrfs := []string{"blue"}
+	tests := map[string]struct {
+		mode     routing.DrainMode
+		drained  bool
+		expected string
+	}{
+		"withdraw": {
+			mode:    routing.DrainWithdraw,
+			drained: true,
+			expected: "configure terminal\n" +
+				" router bgp 65000\n address-family l2vpn evpn\n no advertise-all-vni\n exit-address-family\n exit\n" +
+				" router bgp 65000 vrf blue\n address-family l2vpn evpn\n no advertise ipv4 unicast\n exit-address-family\n exit\n" +
+				" exit\n",
+		},
+		"undo withdraw": {
+			mode:    routing.DrainWithdraw,
+			drained: false,
+			expected: "configure terminal\n" +
+				" router bgp 65000\n address-family l2vpn evpn\n advertise-all-vni\n exit-address-family\n exit\n" +
+				" router bgp 65000 vrf blue\n address-family l2vpn evpn\n advertise ipv4 unicast\n exit-address-family\n exit\n" +
+				" exit\n",
+		},
+		"med": {
+			mode:    routing.DrainMed,
+			drained: true,
+			expected: "configure terminal\n" +
+				" route-map OPI-DRAIN permit 10\n  set metric 4294967295\n exit\n" +
+				" router bgp 65000\n address-family l2vpn evpn\n neighbor 10.0.0.2 route-map OPI-DRAIN out\n exit-address-family\n exit\n" +
+				" exit\n",
+		},
+		"prepend": {
+			mode:    routing.DrainPrepend,
+			drained: true,
+			expected: "configure terminal\n" +
+				" route-map OPI-DRAIN permit 10\n  set as-path prepend 65000 65000 65000\n exit\n" +
+				" router bgp 65000\n address-family l2vpn evpn\n neighbor 10.0.0.2 route-map OPI-DRAIN out\n exit-address-family\n exit\n" +
+				" exit\n",
+		},
+		"undo prepend": {
+			mode:    routing.DrainPrepend,
+			drained: false,
+			expected: "configure terminal\n" +
+				" router bgp 65000\n address-family l2vpn evpn\n no neighbor 10.0.0.2 route-map OPI-DRAIN out\n exit-address-family\n exit\n" +
+				" no route-map OPI-DRAIN\n exit\n",
+		},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			if cmds := drainCmds(tt.mode, tt.drained, peers, vrfs); cmds != tt.expected {
+				t.Errorf("expected %q, received %q", tt.expected, cmds)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// build time check that struct implements interface
+var _ routing.DuplicateDetector = Backend{}
+
+// the defaults of bgpd, which takes the moves and the time together
+const (
+	defaultDadMaxMoves = 5
+	defaultDadTime     = 180
+)
+
+// dupAddrCmds renders the duplicate address detection of the EVPN, bgpd hands it over to zebra which flags
+// the addresses of all the VNIs
+func dupAddrCmds(detection routing.DupAddrDetection) string {
+	maxMoves, window := detection.MaxMoves, detection.Time
+	if maxMoves == 0 {
+		maxMoves = defaultDadMaxMoves
+	}
+	if window == 0 {
+		window = defaultDadTime
+	}
+	var cmds strings.Builder
+	fmt.Fprintf(&cmds, "configure terminal\n router bgp %+v\n address-family l2vpn evpn\n", localas)
+	fmt.Fprintf(&cmds, " dup-addr-detection max-moves %d time %d\n", maxMoves, window)
+	switch {
+	case detection.Freeze < 0:
+		cmds.WriteString(" dup-addr-detection freeze permanent\n")
+	case detection.Freeze > 0:
+		fmt.Fprintf(&cmds, " dup-addr-detection freeze %d\n", detection.Freeze)
+	}
+	cmds.WriteString(" exit-address-family\n exit\n exit\n")
+	return cmds.String()
+}
+
+// ConfigureDupAddrDetection sets the thresholds of the duplicate address detection in the default bgp instance
+func (Backend) ConfigureDupAddrDetection(ctx context.Context, detection routing.DupAddrDetection) error {
+	if !config.GlobalConfig.LinuxFrr.Enabled {
+		return nil
+	}
+	if frr == nil {
+		return ErrNotInitialized
+	}
+	cmds := dupAddrCmds(detection)
+	if _, err := frr.FrrBgpCmd(ctx, cmds, false); err != nil {
+		log.Printf("FRR: Error in configuring the duplicate address detection: %v\n", err)
+		return err
+	}
+	if err := frr.Save(ctx); err != nil {
+		log.Printf("FRR(ConfigureDupAddrDetection): Failed to run save command: %v\n", err)
+	}
+	log.Printf("FRR: Executed %s\n", cmds)
+	return nil
+}
+
+// zebraMacEntry is the json representation of a MAC or ARP/ND entry of zebra
+type zebraMacEntry struct {
+	Mac            string `json:"mac"`
+	RemoteVtep     string `json:"remoteVtep"`
+	LocalSequence  uint32 `json:"localSequence"`
+	RemoteSequence uint32 `json:"remoteSequence"`
+	DetectionCount int    `json:"detectionCount"`
+	IsDuplicate    bool   `json:"isDuplicate"`
+}
+
+// parseDupMacs flattens "show evpn mac vni all duplicate json", the MACs of a VNI are under "macs"
+func parseDupMacs(vnis map[string]json.RawMessage) []routing.Duplicate {
+	dups := []routing.Duplicate{}
+	for key, raw := range vnis {
+		vni, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			continue
+		}
+		var table struct {
+			Macs map[string]zebraMacEntry `json:"macs"`
+		}
+		if json.Unmarshal(raw, &table) != nil {
+			continue
+		}
+		for mac, e := range table.Macs {
+			if e.IsDuplicate {
+				dups = append(dups, routing.Duplicate{Vni: uint32(vni), Mac: mac, RemoteVtep: e.RemoteVtep, Moves: e.DetectionCount})
+			}
+		}
+	}
+	return dups
+}
+
+// parseDupNeighs flattens "show evpn arp-cache vni all duplicate json", the entries of a VNI are keyed by IP
+// address next to its counters
+func parseDupNeighs(vnis map[string]json.RawMessage) []routing.Duplicate {
+	dups := []routing.Duplicate{}
+	for key, raw := range vnis {
+		vni, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			continue
+		}
+		entries := map[string]json.RawMessage{}
+		if json.Unmarshal(raw, &entries) != nil {
+			continue
+		}
+		for ip, data := range entries {
+			var e zebraMacEntry
+			if net.ParseIP(ip) == nil || json.Unmarshal(data, &e) != nil || !e.IsDuplicate {
+				continue
+			}
+			dups = append(dups, routing.Duplicate{Vni: uint32(vni), Mac: e.Mac, IP: ip, RemoteVtep: e.RemoteVtep, Moves: e.DetectionCount})
+		}
+	}
+	return dups
+}
+
+// Duplicates returns the duplicate MAC addresses and ARP/ND entries flagged by zebra, in the order of their VNI
+func (Backend) Duplicates(ctx context.Context) ([]routing.Duplicate, error) {
+	macs := map[string]json.RawMessage{}
+	if err := zebraShow(ctx, "show evpn mac vni all duplicate json", &macs); err != nil {
+		return nil, err
+	}
+	neighs := map[string]json.RawMessage{}
+	if err := zebraShow(ctx, "show evpn arp-cache vni all duplicate json", &neighs); err != nil {
+		return nil, err
+	}
+	dups := append(parseDupMacs(macs), parseDupNeighs(neighs)...)
+	sort.Slice(dups, func(i, j int) bool {
+		if dups[i].Vni != dups[j].Vni {
+			return dups[i].Vni < dups[j].Vni
+		}
+		if dups[i].Mac != dups[j].Mac {
+			return dups[i].Mac < dups[j].Mac
+		}
+		return dups[i].IP < dups[j].IP
+	})
+	return dups, nil
+}
+
+// clearDupCmd renders the zebra command which clears the duplicate addresses
+func clearDupCmd(vni uint32, mac, ip string) string {
+	switch {
+	case mac != "":
+		return fmt.Sprintf("clear evpn dup-addr vni %d mac %s", vni, mac)
+	case ip != "":
+		return fmt.Sprintf("clear evpn dup-addr vni %d ip %s", vni, ip)
+	default:
+		return fmt.Sprintf("clear evpn dup-addr vni %d", vni)
+	}
+}
+
+// ClearDuplicate clears the duplicate addresses in zebra, which learns them again from scratch
+func (Backend) ClearDuplicate(ctx context.Context, vni uint32, mac, ip string) error {
+	if frr == nil {
+		return ErrNotInitialized
+	}
+	cmd := clearDupCmd(vni, mac, ip)
+	if _, err := frr.FrrZebraCmd(ctx, cmd, false); err != nil {
+		log.Printf("FRR: Error in %s: %v\n", cmd, err)
+		return err
+	}
+	log.Printf("FRR: Executed %s\n", cmd)
+	return nil
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
+)
+
+func Test_DupAddrCmds(t *testing.T) {
+	localas = 65000
+	tests := map[string]struct {
+		detection routing.DupAddrDetection
+		cmds      string
+	}{
+		"stack defaults": {
+			cmds: "configure terminal\n router bgp 65000\n address-family l2vpn evpn\n" +
+				" dup-addr-detection max-moves 5 time 180\n exit-address-family\n exit\n exit\n",
+		},
+		"freeze": {
+			detection: routing.DupAddrDetection{MaxMoves: 3, Time: 60, Freeze: 300},
+			cmds: "configure terminal\n router bgp 65000\n address-family l2vpn evpn\n" +
+				" dup-addr-detection max-moves 3 time 60\n dup-addr-detection freeze 300\n exit-address-family\n exit\n exit\n",
+		},
+		"permanent freeze": {
+			detection: routing.DupAddrDetection{MaxMoves: 3, Freeze: -1},
+			cmds: "configure terminal\n router bgp 65000\n address-family l2vpn evpn\n" +
+				" dup-addr-detection max-moves 3 time 180\n dup-addr-detection freeze permanent\n exit-address-family\n exit\n exit\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if cmds := dupAddrCmds(tt.detection); cmds != tt.cmds {
+				t.Errorf("expected %q, received %q", tt.cmds, cmds)
+			}
+		})
+	}
+}
+
+func Test_Duplicates(t *testing.T) {
+	mockFrr := mocks.NewFrr(t)
+	frr = mockFrr
+	t.Cleanup(func() { frr = nil })
+
+	cmd := "show evpn mac vni all duplicate json"
+	mockFrr.On("FrrZebraCmd", context.Background(), cmd, true).Return(vtyOutput(cmd, `{
+  "1000":{"numMacs":2,"macs":{
+    "aa:bb:cc:00:00:02":{"type":"remote","remoteVtep":"10.0.0.2","localSequence":4,"remoteSequence":5,"detectionCount":5,"isDuplicate":true},
+    "aa:bb:cc:00:00:01":{"type":"local","intf":"blue-10","vlan":10,"localSequence":6,"remoteSequence":5,"detectionCount":6,"isDuplicate":true}}},
+  "2000":{"numMacs":0,"macs":{}}
+}`), nil)
+	cmd = "show evpn arp-cache vni all duplicate json"
+	mockFrr.On("FrrZebraCmd", context.Background(), cmd, true).Return(vtyOutput(cmd, `{
+  "1000":{"numArpNd":1,
+    "10.0.0.5":{"type":"remote","state":"active","mac":"aa:bb:cc:00:00:02","remoteVtep":"10.0.0.2","localSequence":2,"remoteSequence":3,"detectionCount":5,"isDuplicate":true}}
+}`), nil)
+
+	dups, err := Backend{}.Duplicates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []routing.Duplicate{
+		{Vni: 1000, Mac: "aa:bb:cc:00:00:01", Moves: 6},
+		{Vni: 1000, Mac: "aa:bb:cc:00:00:02", RemoteVtep: "10.0.0.2", Moves: 5},
+		{Vni: 1000, Mac: "aa:bb:cc:00:00:02", IP: "10.0.0.5", RemoteVtep: "10.0.0.2", Moves: 5},
+	}
+	if !reflect.DeepEqual(dups, expected) {
+		t.Errorf("expected %+v, received %+v", expected, dups)
+	}
+
+	cmd = "clear evpn dup-addr vni 1000 mac aa:bb:cc:00:00:02"
+	mockFrr.On("FrrZebraCmd", context.Background(), cmd, false).Return("", nil)
+	if err := (Backend{}).ClearDuplicate(context.Background(), 1000, "aa:bb:cc:00:00:02", ""); err != nil {
+		t.Fatal(err)
+	}
+	if cmd := clearDupCmd(1000, "", "10.0.0.5"); cmd != "clear evpn dup-addr vni 1000 ip 10.0.0.5" {
+		t.Errorf("unexpected command %q", cmd)
+	}
+	if cmd := clearDupCmd(1000, "", ""); cmd != "clear evpn dup-addr vni 1000" {
+		t.Errorf("unexpected command %q", cmd)
+	}
+}
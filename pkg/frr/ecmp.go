@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// ecmpCmds returns the commands of the bgp instance of a vrf and of its unicast address family which install
+// the type-5 routes of a prefix received from several remote VTEPs as one multipath route. The paths come from
+// other leaves with their own AS, so the AS paths only need to be as long. zebra installs the paths weighted by
+// their link bandwidth extended community, unless ignored.
+func ecmpCmds(cfg config.EcmpConfig) (router string, family string) {
+	if cfg.MaxPaths > 1 {
+		router += " bgp bestpath as-path multipath-relax\n"
+	}
+	if !cfg.Weighted {
+		router += " bgp bestpath bandwidth ignore\n"
+	}
+	if cfg.MaxPaths != 0 {
+		family = fmt.Sprintf(" maximum-paths %d\n maximum-paths ibgp %d\n", cfg.MaxPaths, cfg.MaxPaths)
+	}
+	return router, family
+}
+
+// zebraRoute is the json representation of a route in "show ip route vrf <vrf> json"
+type zebraRoute struct {
+	Protocol string `json:"protocol"`
+	Selected bool   `json:"selected"`
+	Nexthops []struct {
+		IP     string `json:"ip"`
+		Active bool   `json:"active"`
+		Weight uint32 `json:"weight"`
+	} `json:"nexthops"`
+}
+
+// nexthopWeights returns the weights of the nexthops of the bgp routes installed in the vrf by prefix and nexthop,
+// zebra reports them for the weighted multipath routes only
+func nexthopWeights(ctx context.Context, vrf string) (map[string]map[string]uint32, error) {
+	weights := map[string]map[string]uint32{}
+	for _, afi := range []string{"ip", "ipv6"} {
+		table := map[string][]zebraRoute{}
+		if err := zebraShow(ctx, fmt.Sprintf("show %s route vrf %s json", afi, frrVrfName(vrf)), &table); err != nil {
+			return nil, err
+		}
+		for prefix, routes := range table {
+			for _, route := range routes {
+				if route.Protocol != "bgp" || !route.Selected {
+					continue
+				}
+				for _, nh := range route.Nexthops {
+					if !nh.Active || nh.Weight == 0 {
+						continue
+					}
+					if weights[prefix] == nil {
+						weights[prefix] = map[string]uint32{}
+					}
+					weights[prefix][nh.IP] = nh.Weight
+				}
+			}
+		}
+	}
+	return weights, nil
+}
+
+// zebraNexthopGroup is the json representation of a nexthop object in "show nexthop-group rib json"
+type zebraNexthopGroup struct {
+	Type      string `json:"type"`
+	RefCount  int    `json:"refCount"`
+	Vrf       string `json:"vrf"`
+	Installed bool   `json:"installed"`
+	Nexthops  []struct {
+		IP     string `json:"ip"`
+		Weight uint32 `json:"weight"`
+	} `json:"nexthops"`
+}
+
+// NexthopGroups returns the nexthop objects which zebra installed in the kernel for the bgp routes, in the order
+// of their id. zebra creates and shares them itself, so only the current groups and routes are counted.
+func (Backend) NexthopGroups(ctx context.Context) ([]routing.NexthopGroup, routing.NexthopGroupStats, error) {
+	table := map[string]zebraNexthopGroup{}
+	if err := zebraShow(ctx, "show nexthop-group rib json", &table); err != nil {
+		return nil, routing.NexthopGroupStats{}, err
+	}
+	return parseNexthopGroups(table)
+}
+
+// parseNexthopGroups translates the installed nexthop objects of bgp
+func parseNexthopGroups(table map[string]zebraNexthopGroup) ([]routing.NexthopGroup, routing.NexthopGroupStats, error) {
+	groups := []routing.NexthopGroup{}
+	stats := routing.NexthopGroupStats{}
+	for key, nhg := range table {
+		if nhg.Type != "bgp" || !nhg.Installed || len(nhg.Nexthops) == 0 {
+			continue
+		}
+		id, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			return nil, stats, fmt.Errorf("invalid nexthop group id %q", key)
+		}
+		group := routing.NexthopGroup{ID: uint32(id), Vrf: nhg.Vrf, Routes: nhg.RefCount}
+		for _, nh := range nhg.Nexthops {
+			group.Members = append(group.Members, routing.NexthopMember{Nexthop: nh.IP, Weight: nh.Weight})
+		}
+		groups = append(groups, group)
+		stats.Groups++
+		stats.Routes += nhg.RefCount
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	return groups, stats, nil
+}
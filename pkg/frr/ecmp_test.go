@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
+)
+
+func Test_EcmpCmds(t *testing.T) {
+	tests := map[string]struct {
+		cfg            config.EcmpConfig
+		router, family string
+	}{
+		"stack default": {
+			cfg: config.EcmpConfig{Weighted: true},
+		},
+		"weighted": {
+			cfg:    config.EcmpConfig{MaxPaths: 8, Weighted: true},
+			router: " bgp bestpath as-path multipath-relax\n",
+			family: " maximum-paths 8\n maximum-paths ibgp 8\n",
+		},
+		"equal cost": {
+			cfg:    config.EcmpConfig{MaxPaths: 4},
+			router: " bgp bestpath as-path multipath-relax\n bgp bestpath bandwidth ignore\n",
+			family: " maximum-paths 4\n maximum-paths ibgp 4\n",
+		},
+		"best path only": {
+			cfg:    config.EcmpConfig{MaxPaths: 1, Weighted: true},
+			family: " maximum-paths 1\n maximum-paths ibgp 1\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			router, family := ecmpCmds(tt.cfg)
+			if router != tt.router || family != tt.family {
+				t.Errorf("expected %q %q, received %q %q", tt.router, tt.family, router, family)
+			}
+		})
+	}
+}
+
+func Test_NexthopGroups(t *testing.T) {
+	mockFrr := mocks.NewFrr(t)
+	frr = mockFrr
+	t.Cleanup(func() { frr = nil })
+	cmd := "show nexthop-group rib json"
+	mockFrr.On("FrrZebraCmd", context.Background(), cmd, true).Return(vtyOutput(cmd, `{
+  "12":{"type":"zebra","refCount":1,"vrf":"default","installed":true,"nexthops":[{"ip":"192.168.0.1","weight":1}]},
+  "75":{"type":"bgp","refCount":3,"vrf":"blue","installed":true,"nexthops":[{"ip":"10.0.0.2","weight":102},{"ip":"10.0.0.3","weight":255}]},
+  "31":{"type":"bgp","refCount":1,"vrf":"blue","installed":true,"nexthops":[{"ip":"10.0.0.2","weight":1}]},
+  "40":{"type":"bgp","refCount":1,"vrf":"red","installed":false,"nexthops":[{"ip":"10.0.0.4","weight":1}]}
+}`), nil)
+
+	groups, stats, err := Backend{}.NexthopGroups(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []routing.NexthopGroup{
+		{ID: 31, Vrf: "blue", Members: []routing.NexthopMember{{Nexthop: "10.0.0.2", Weight: 1}}, Routes: 1},
+		{ID: 75, Vrf: "blue", Members: []routing.NexthopMember{{Nexthop: "10.0.0.2", Weight: 102}, {Nexthop: "10.0.0.3", Weight: 255}}, Routes: 3},
+	}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("expected %+v, received %+v", expected, groups)
+	}
+	if expected := (routing.NexthopGroupStats{Groups: 2, Routes: 4}); stats != expected {
+		t.Errorf("expected %+v, received %+v", expected, stats)
+	}
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// handleExternalInterface handles the external interface functionality
+func handleExternalInterface(objectData *eventbus.ObjectData) {
+	eif, err := infradb.GetExternalInterface(objectData.Name)
+	handleResource(objectData, &eif.Resource, err,
+		func() (string, bool) { return renderExternalInterface(eif, false) },
+		func() (string, bool) { return renderExternalInterface(eif, true) },
+		infradb.UpdateExternalInterfaceStatus)
+}
+
+// externalInterfaceCmds builds the default route and the bgp peering of the external interface.
+// The default route is configured as a static route so that it is redistributed into the EVPN fabric.
+func externalInterfaceCmds(eif *infradb.ExternalInterface, remove bool) string {
+	no := ""
+	if remove {
+		no = "no "
+	}
+	route, defRoute, family := "ip route", "0.0.0.0/0", "ipv4 unicast"
+	if eif.Spec.Address.IP.To4() == nil {
+		route, defRoute, family = "ipv6 route", "::/0", "ipv6 unicast"
+	}
+	var cmds strings.Builder
+	cmds.WriteString("configure terminal\n")
+	if eif.Spec.Gateway != nil {
+		if path.Base(eif.Spec.Vrf) == "GRD" {
+			fmt.Fprintf(&cmds, " %s%s %s %s %s\n", no, route, defRoute, eif.Spec.Gateway, eif.Spec.LinkName())
+		} else {
+			fmt.Fprintf(&cmds, " vrf %s\n  %s%s %s %s %s\n exit-vrf\n", frrVrfName(eif.Spec.Vrf), no, route, defRoute, eif.Spec.Gateway, eif.Spec.LinkName())
+		}
+	}
+	if peer := eif.Spec.BgpPeer; peer != nil {
+		fmt.Fprintf(&cmds, " %s\n", bgpRouterCmd(eif.Spec.Vrf))
+		if remove {
+			fmt.Fprintf(&cmds, " no neighbor %s\n", peer.PeerIP)
+		} else {
+			fmt.Fprintf(&cmds, " neighbor %s remote-as %d\n address-family %s\n neighbor %s activate\n exit-address-family\n", peer.PeerIP, peer.RemoteAS, family, peer.PeerIP)
+		}
+		cmds.WriteString(" exit\n")
+	}
+	return cmds.String()
+}
+
+// renderExternalInterface configures or removes the external interface in FRR
+func renderExternalInterface(eif *infradb.ExternalInterface, remove bool) (string, bool) {
+	if eif.Spec.Gateway == nil && eif.Spec.BgpPeer == nil {
+		return "", true
+	}
+	cmds := externalInterfaceCmds(eif, remove)
+	_, err := frr.FrrBgpCmd(ctx, cmds, false)
+	if err != nil {
+		log.Printf("FRR: Error in rendering the external interface %s: %v\n", eif.Name, err)
+		return fmt.Sprintf("FRR: Error in rendering the external interface %s: %v\n", eif.Name, err), false
+	}
+	err = frr.Save(ctx)
+	if err != nil {
+		log.Printf("FRR(renderExternalInterface): Failed to run save command: %v\n", err)
+	}
+	log.Printf("FRR: Executed %s\n", cmds)
+	return "", true
+}
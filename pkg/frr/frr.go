@@ -7,7 +7,6 @@ package frr
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"log"
@@ -16,7 +15,6 @@ import (
 	"os/user"
 	"path"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/config"
@@ -53,9 +51,6 @@ type bgpl2VpnCmd struct {
 	ExportRts             []string
 }
 
-// route empty structure
-type route struct{}
-
 // bgpVrfCmd structure
 type bgpVrfCmd struct {
 	VrfID         int
@@ -64,7 +59,7 @@ type bgpVrfCmd struct {
 	RouterID      string
 	DefaultLocPrf uint
 	LocalAS       int
-	Routes        route
+	Routes        map[string]bgpPaths
 }
 
 // ModuleFrrActionHandler empty structure
@@ -104,6 +99,21 @@ func (h *moduleFrrHandler) HandleEvent(eventType string, objectData *eventbus.Ob
 	case "svi":
 		log.Printf("FRR recevied %s %s\n", eventType, objectData.Name)
 		handlesvi(objectData)
+	case "route-leak":
+		log.Printf("FRR recevied %s %s\n", eventType, objectData.Name)
+		handleRouteLeak(objectData)
+	case "external-interface":
+		log.Printf("FRR recevied %s %s\n", eventType, objectData.Name)
+		handleExternalInterface(objectData)
+	case "routing-policy":
+		log.Printf("FRR recevied %s %s\n", eventType, objectData.Name)
+		handleRoutingPolicy(objectData)
+	case "vpc-peering":
+		log.Printf("FRR recevied %s %s\n", eventType, objectData.Name)
+		handleVpcPeering(objectData)
+	case "host-route":
+		log.Printf("FRR recevied %s %s\n", eventType, objectData.Name)
+		handleHostRoute(objectData)
 	default:
 		log.Printf("error: Unknown event type %s", eventType)
 	}
@@ -423,6 +433,9 @@ var ctx context.Context
 // frr variable of type utils wrapper
 var frr utils.Frr
 
+// frrAddress is the host of the FRR vty sockets
+var frrAddress = "localhost"
+
 // Initialize function handles init functionality
 func Initialize() {
 	frrEnabled := config.GlobalConfig.LinuxFrr.Enabled
@@ -439,7 +452,10 @@ func Initialize() {
 	subscribeInfradb(&config.GlobalConfig)
 
 	ctx = context.Background()
-	frr = utils.NewFrrWrapperWithArgs("localhost", config.GlobalConfig.Tracer)
+	if config.GlobalConfig.LinuxFrr.FrrAddress != "" {
+		frrAddress = config.GlobalConfig.LinuxFrr.FrrAddress
+	}
+	frr = utils.WithFrrWriteQueue(utils.WithFrrFaults(utils.NewFrrWrapperWithArgs(frrAddress, config.GlobalConfig.Tracer)), utils.DefaultNamespace)
 
 	// Make sure IPv4 forwarding is enabled.
 	detail, flag := run([]string{"sysctl", "-w", " net.ipv4.ip_forward=1"}, false)
@@ -460,6 +476,31 @@ func DeInitialize() {
 	eb.UnsubscribeModule(frrComp)
 }
 
+// Probe checks that the FRR daemons accept vty connections
+func Probe(ctx context.Context) error {
+	if !config.GlobalConfig.LinuxFrr.Enabled {
+		return nil
+	}
+	return utils.FrrPing(ctx, frrAddress)
+}
+
+// DeepProbe checks that the FRR daemons answer commands
+func DeepProbe(ctx context.Context) error {
+	if !config.GlobalConfig.LinuxFrr.Enabled {
+		return nil
+	}
+	if frr == nil {
+		return ErrNotInitialized
+	}
+	if _, err := frr.FrrZebraCmd(ctx, "show version", true); err != nil {
+		return fmt.Errorf("zebra: %v", err)
+	}
+	if _, err := frr.FrrBgpCmd(ctx, "show bgp summary", true); err != nil {
+		return fmt.Errorf("bgpd: %v", err)
+	}
+	return nil
+}
+
 // setUpVrf sets up the vrf
 func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 	// This function must not be executed for the vrf representing the GRD
@@ -468,8 +509,15 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 	}
 	if vrf.Spec.Vni != nil {
 		// Configure the vrf in FRR and set up BGP EVPN for it
-		vrfName := fmt.Sprintf("vrf %s", path.Base(vrf.Name))
+		vrfName := fmt.Sprintf("vrf %s", frrVrfName(vrf.Name))
 		vniID := fmt.Sprintf("vni %s", strconv.Itoa(int(*vrf.Spec.Vni)))
+		if vrf.Spec.IsVpn() {
+			// The VPC carried over a VPN has no L3 VNI, the one left by VXLAN is removed
+			if _, err := frr.FrrZebraCmd(ctx, fmt.Sprintf("configure terminal\n %s\n no %s\n exit-vrf\n exit", vrfName, vniID), false); err != nil {
+				log.Printf("FRR: No %s to remove from %s: %v\n", vniID, vrfName, err)
+			}
+			vniID = ""
+		}
 
 		_, err := frr.FrrZebraCmd(ctx, fmt.Sprintf("configure terminal\n %s\n %s\n exit-vrf\n exit", vrfName, vniID), false)
 		if err != nil {
@@ -481,14 +529,9 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 			log.Printf("FRR(setUpVrf): Failed to run save command: %v\n", err)
 		}
 		log.Printf("FRR: Executed frr config t %s %s exit-vrf exit\n", vrfName, vniID)
-		var lbIP string
-
-		if vrf.Spec.LoopbackIP != nil {
-			lbIP = "0.0.0.0"
-		} else {
-			lbIP = fmt.Sprintf("%+v", vrf.Spec.LoopbackIP.IP)
-		}
-		_, err = frr.FrrBgpCmd(ctx, fmt.Sprintf("configure terminal\n router bgp %+v vrf %s\n bgp router-id %s\n no bgp ebgp-requires-policy\n no bgp hard-administrative-reset\n no bgp graceful-restart notification\n address-family ipv4 unicast\n redistribute connected\n redistribute static\n exit-address-family\n address-family l2vpn evpn\n advertise ipv4 unicast\n exit-address-family\n exit", localas, path.Base(vrf.Name), lbIP), false)
+		lbIP := vrfRouterID(vrf)
+		ecmpRouter, ecmpFamily := ecmpCmds(config.GlobalConfig.Routing.Ecmp)
+		_, err = frr.FrrBgpCmd(ctx, fmt.Sprintf("configure terminal\n router bgp %+v vrf %s\n bgp router-id %s\n no bgp ebgp-requires-policy\n no bgp hard-administrative-reset\n no bgp graceful-restart notification\n%s%s address-family ipv4 unicast\n redistribute connected\n redistribute static\n%s exit-address-family\n%s exit", localas, frrVrfName(vrf.Name), lbIP, gracefulRestartCmds(config.GlobalConfig.Routing.GracefulRestart), ecmpRouter, ecmpFamily, vrfDataplaneCmds(vrf)), false)
 		if err != nil {
 			log.Printf("FRR: Error Executing config t bgpVrfName router bgp %+v vrf %s bgp_route_id %s no bgp ebgp-requires-policy exit-vrf exit Error %v \n", localas, vrf.Name, lbIP, err)
 			return fmt.Sprintf("FRR: Error Executing config t bgpVrfName router bgp %+v vrf %s bgp_route_id %s no bgp ebgp-requires-policy exit-vrf exit Error %v \n", localas, vrf.Name, lbIP, err), false
@@ -498,6 +541,9 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 			log.Printf("FRR(setUpVrf): Failed to run save command: %v\n", err)
 		}
 		log.Printf("FRR: Executed config t bgpVrfName router bgp %+v vrf %s bgp_route_id %s no bgp ebgp-requires-policy exit-vrf exit\n", localas, vrf.Name, lbIP)
+		if vrf.Spec.IsVpn() {
+			return vpnDetails(vrf)
+		}
 		// Update the vrf with attributes from FRR
 		cmd := fmt.Sprintf("show bgp l2vpn evpn vni %d json", *vrf.Spec.Vni)
 		cp, err := frr.FrrBgpCmd(ctx, cmd, true)
@@ -508,17 +554,13 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 		if err != nil {
 			log.Printf("FRR(setUpVrf): Failed to run save command: %v\n", err)
 		}
-		hname, _ := os.Hostname()
-		l2VpnCmd := strings.Split(cp, "json")
-		l2VpnCmd = strings.Split(l2VpnCmd[1], hname)
-		cp = l2VpnCmd[0]
 		var bgpL2vpn bgpl2VpnCmd
-		err1 := json.Unmarshal([]byte(cp), &bgpL2vpn)
+		err1 := decodeVtyJSON(cp, &bgpL2vpn)
 		if err1 != nil {
 			log.Printf("FRR: unable to get the command %s\n", cmd)
 			return fmt.Sprintf("FRR: Failed in unmarshal the command %s\n", cmd), false
 		}
-		cmd = fmt.Sprintf("show bgp vrf %s json", path.Base(vrf.Name))
+		cmd = fmt.Sprintf("show bgp vrf %s json", frrVrfName(vrf.Name))
 		cp, err = frr.FrrBgpCmd(ctx, cmd, true)
 		if err != nil {
 			log.Printf("FRR:  unable to get the command %s-%v", cmd, err)
@@ -528,12 +570,8 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 			log.Printf("FRR(setUpVrf): Failed to run save command: %v\n", err)
 		}
 
-		bgpCmd := strings.Split(cp, "json")
-		bgpCmd = strings.Split(bgpCmd[1], hname)
-		cp = bgpCmd[0]
-
 		var bgpVrf bgpVrfCmd
-		err1 = json.Unmarshal([]byte(cp), &bgpVrf)
+		err1 = decodeVtyJSON(cp, &bgpVrf)
 		if err1 != nil {
 			log.Printf("FRR: unable to get the command %s \"%s\"\n", cp, cmd)
 			return fmt.Sprintf("FRR: unable to unmarshal \"%s\"\n", cmd), false
@@ -546,6 +584,40 @@ func setUpVrf(vrf *infradb.Vrf) (string, bool) {
 	return "", true
 }
 
+// vrfRouterID returns the router id of the BGP instance of the vrf, the loopback address when the vrf has one
+// and else the VTEP address, which is as unique to the node
+func vrfRouterID(vrf *infradb.Vrf) string {
+	switch {
+	case vrf.Spec.LoopbackIP != nil:
+		return vrf.Spec.LoopbackIP.IP.String()
+	case vrf.Spec.VtepIP != nil:
+		return vrf.Spec.VtepIP.IP.String()
+	}
+	return "0.0.0.0"
+}
+
+// sviBgpCmds returns the configuration of the BGP sessions with the hosts behind the svi: the hosts of the
+// subnet of the svi connect from the listen range, the hosts of a link-local only svi are reached over an
+// unnumbered session on the svi, which carries their IPv4 routes with IPv6 next hops
+func sviBgpCmds(svi *infradb.Svi, vrfName string, linkSvi string) string {
+	bgpVrfName := fmt.Sprintf("router bgp %+v vrf %s\n", localas, vrfName)
+	remoteAs := fmt.Sprintf("%d", *svi.Spec.RemoteAs)
+	if svi.Spec.IsUnnumbered() {
+		return fmt.Sprintf("configure terminal\n %s bgp disable-ebgp-connected-route-check\n"+
+			" neighbor %s interface remote-as %s\n neighbor %s as-override\n neighbor %s soft-reconfiguration inbound\n"+
+			" address-family ipv6 unicast\n neighbor %s activate\n neighbor %s as-override\n exit-address-family\n exit",
+			bgpVrfName, linkSvi, remoteAs, linkSvi, linkSvi, linkSvi, linkSvi)
+	}
+	gwIP := svi.Spec.GatewayIPs[0].IP.String()
+	neighlink := fmt.Sprintf("neighbor %s peer-group\n", linkSvi)
+	neighlinkRe := fmt.Sprintf("neighbor %s remote-as %s\n", linkSvi, remoteAs)
+	neighlinkGw := fmt.Sprintf("neighbor %s update-source %s\n", linkSvi, gwIP)
+	neighlinkOv := fmt.Sprintf("neighbor %s as-override\n", linkSvi)
+	neighlinkSr := fmt.Sprintf("neighbor %s soft-reconfiguration inbound\n", linkSvi)
+	bgpListen := fmt.Sprintf(" bgp listen range %s peer-group %s\n", svi.Spec.GatewayIPs[0], linkSvi)
+	return fmt.Sprintf("configure terminal\n %s bgp disable-ebgp-connected-route-check\n %s %s %s %s %s %s exit", bgpVrfName, neighlink, neighlinkRe, neighlinkGw, neighlinkOv, neighlinkSr, bgpListen)
+}
+
 // setUpSvi sets up the svi
 func setUpSvi(svi *infradb.Svi) (string, bool) {
 	brObj, err := infradb.GetLB(svi.Spec.LogicalBridge)
@@ -553,24 +625,13 @@ func setUpSvi(svi *infradb.Svi) (string, bool) {
 		log.Printf("FRR: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err)
 		return fmt.Sprintf("FRR: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err), false
 	}
-	linkSvi := fmt.Sprintf("%+v-%+v", path.Base(svi.Spec.Vrf), brObj.Spec.VlanID)
-	if svi.Spec.EnableBgp && len(svi.Spec.GatewayIPs) != 0 {
-		// gwIP := fmt.Sprintf("%s", svi.Spec.GatewayIPs[0].IP.To4())
-		gwIP := string(svi.Spec.GatewayIPs[0].IP.To4())
-		remoteAs := fmt.Sprintf("%d", *svi.Spec.RemoteAs)
-		bgpVrfName := fmt.Sprintf("router bgp %+v vrf %s\n", localas, path.Base(svi.Spec.Vrf))
-		neighlink := fmt.Sprintf("neighbor %s peer-group\n", linkSvi)
-		neighlinkRe := fmt.Sprintf("neighbor %s remote-as %s\n", linkSvi, remoteAs)
-		neighlinkGw := fmt.Sprintf("neighbor %s update-source %s\n", linkSvi, gwIP)
-		neighlinkOv := fmt.Sprintf("neighbor %s as-override\n", linkSvi)
-		neighlinkSr := fmt.Sprintf("neighbor %s soft-reconfiguration inbound\n", linkSvi)
-		bgpListen := fmt.Sprintf(" bgp listen range %s peer-group %s\n", svi.Spec.GatewayIPs[0], linkSvi)
-
-		_, err := frr.FrrBgpCmd(ctx, fmt.Sprintf("configure terminal\n %s bgp disable-ebgp-connected-route-check\n %s %s %s %s %s %s exit", bgpVrfName, neighlink, neighlinkRe, neighlinkGw, neighlinkOv, neighlinkSr, bgpListen), false)
+	linkSvi := infradb.SviLinkName(svi, brObj.Spec.VlanID)
+	if svi.Spec.EnableBgp {
+		_, err := frr.FrrBgpCmd(ctx, sviBgpCmds(svi, frrVrfName(svi.Spec.Vrf), linkSvi), false)
 
 		if err != nil {
-			log.Printf("FRR: Error in conf svi %s %s command %s\n", svi.Name, path.Base(svi.Spec.Vrf), err)
-			return fmt.Sprintf("FRR: Error in conf svi %s %s command %s\n", svi.Name, path.Base(svi.Spec.Vrf), err), false
+			log.Printf("FRR: Error in conf svi %s %s command %s\n", svi.Name, frrVrfName(svi.Spec.Vrf), err)
+			return fmt.Sprintf("FRR: Error in conf svi %s %s command %s\n", svi.Name, frrVrfName(svi.Spec.Vrf), err), false
 		}
 		err = frr.Save(ctx)
 		if err != nil {
@@ -589,10 +650,13 @@ func tearDownSvi(svi *infradb.Svi) (string, bool) {
 		log.Printf("LCI: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err)
 		return fmt.Sprintf("LCI: unable to find key %s and error is %v", svi.Spec.LogicalBridge, err), false
 	}
-	linkSvi := fmt.Sprintf("%+v-%+v", path.Base(svi.Spec.Vrf), brObj.Spec.VlanID)
-	if svi.Spec.EnableBgp && len(svi.Spec.GatewayIPs) != 0 {
-		bgpVrfName := fmt.Sprintf("router bgp %+v vrf %s", localas, path.Base(svi.Spec.Vrf))
+	linkSvi := infradb.SviLinkName(svi, brObj.Spec.VlanID)
+	if svi.Spec.EnableBgp {
+		bgpVrfName := fmt.Sprintf("router bgp %+v vrf %s", localas, frrVrfName(svi.Spec.Vrf))
 		noNeigh := fmt.Sprintf("no neighbor %s peer-group", linkSvi)
+		if svi.Spec.IsUnnumbered() {
+			noNeigh = fmt.Sprintf("no neighbor %s interface", linkSvi)
+		}
 
 		_, err := frr.FrrBgpCmd(ctx, fmt.Sprintf("configure terminal\n %s\n %s\n exit", bgpVrfName, noNeigh), false)
 
@@ -604,7 +668,7 @@ func tearDownSvi(svi *infradb.Svi) (string, bool) {
 		if err != nil {
 			log.Printf("FRR(tearDownSvi): Failed to run save command: %v\n", err)
 		}
-		log.Printf("FRR: Executed vtysh -c conf t -c router bgp %+v vrf %s -c no  neighbor %s peer-group -c exit\n", localas, path.Base(svi.Spec.Vrf), linkSvi)
+		log.Printf("FRR: Executed vtysh -c conf t -c router bgp %+v vrf %s -c %s -c exit\n", localas, frrVrfName(svi.Spec.Vrf), noNeigh)
 		return "", true
 	}
 	return "", true
@@ -617,7 +681,7 @@ func tearDownVrf(vrf *infradb.Vrf) (string, bool) {
 		return "", true
 	}
 
-	_, err := frr.FrrZebraCmd(ctx, fmt.Sprintf("show vrf %s vni\n", path.Base(vrf.Name)), true)
+	_, err := frr.FrrZebraCmd(ctx, fmt.Sprintf("show vrf %s vni\n", frrVrfName(vrf.Name)), true)
 	if err != nil {
 		log.Printf("FRR: Error  %s\n", err)
 		return "", true
@@ -629,8 +693,8 @@ func tearDownVrf(vrf *infradb.Vrf) (string, bool) {
 	// Clean up FRR last
 	if vrf != nil && *vrf.Spec.Vni != 0 {
 		log.Printf("FRR Deleted event")
-		delCmd1 := fmt.Sprintf("no router bgp %+v vrf %s", localas, path.Base(vrf.Name))
-		delCmd2 := fmt.Sprintf("no vrf %s", path.Base(vrf.Name))
+		delCmd1 := fmt.Sprintf("no router bgp %+v vrf %s", localas, frrVrfName(vrf.Name))
+		delCmd2 := fmt.Sprintf("no vrf %s", frrVrfName(vrf.Name))
 		_, err = frr.FrrBgpCmd(ctx, fmt.Sprintf("configure terminal\n %s\n exit\n", delCmd1), false)
 		if err != nil {
 			log.Printf("FRR: Error  %s\n", err)
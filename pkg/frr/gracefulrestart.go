@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+// gracefulRestartCmds returns the commands of a bgp instance which make the bridge a restarting speaker. The peers
+// then keep the EVPN routes of the bridge as stale while bgpd restarts, instead of flushing its macs fabric-wide,
+// and the forwarding state is announced preserved since zebra keeps the kernel routes. bgpd stays a helper of its
+// peers only when disabled, its default.
+func gracefulRestartCmds(cfg config.GracefulRestartConfig) string {
+	if !cfg.Enabled {
+		return ""
+	}
+	cmds := " bgp graceful-restart\n bgp graceful-restart preserve-fw-state\n"
+	if cfg.RestartTime != 0 {
+		cmds += fmt.Sprintf(" bgp graceful-restart restart-time %d\n", cfg.RestartTime)
+	}
+	if cfg.StalePathTime != 0 {
+		cmds += fmt.Sprintf(" bgp graceful-restart stalepath-time %d\n", cfg.StalePathTime)
+	}
+	return cmds
+}
+
+// bgpNeighborJSON is the json representation of a neighbor in "show bgp vrf <vrf> neighbors json"
+type bgpNeighborJSON struct {
+	GracefulRestartInfo struct {
+		LocalGrMode  string `json:"localGrMode"`
+		RemoteGrMode string `json:"remoteGrMode"`
+		Timers       struct {
+			ConfiguredRestartTimer int `json:"configuredRestartTimer"`
+			ReceivedRestartTimer   int `json:"receivedRestartTimer"`
+		} `json:"timers"`
+	} `json:"gracefulRestartInfo"`
+}
+
+// grMode trims the star which FRR appends to the modes inherited from the bgp instance
+func grMode(mode string) string {
+	return strings.TrimSuffix(mode, "*")
+}
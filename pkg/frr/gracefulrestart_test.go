@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+func Test_GracefulRestartCmds(t *testing.T) {
+	tests := map[string]struct {
+		cfg  config.GracefulRestartConfig
+		cmds string
+	}{
+		"helper only": {
+			cfg: config.GracefulRestartConfig{RestartTime: 120},
+		},
+		"restarting speaker": {
+			cfg:  config.GracefulRestartConfig{Enabled: true},
+			cmds: " bgp graceful-restart\n bgp graceful-restart preserve-fw-state\n",
+		},
+		"timers": {
+			cfg: config.GracefulRestartConfig{Enabled: true, RestartTime: 180, StalePathTime: 600},
+			cmds: " bgp graceful-restart\n bgp graceful-restart preserve-fw-state\n" +
+				" bgp graceful-restart restart-time 180\n bgp graceful-restart stalepath-time 600\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if cmds := gracefulRestartCmds(tt.cfg); cmds != tt.cmds {
+				t.Errorf("expected %q, received %q", tt.cmds, cmds)
+			}
+		})
+	}
+}
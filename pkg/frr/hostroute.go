@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// renderedHostRoutes holds the host routes as last rendered, so that an update first withdraws
+// the prefixes which the new spec no longer has
+var renderedHostRoutes = struct {
+	sync.Mutex
+	specs map[string]*infradb.HostRouteSpec
+}{specs: make(map[string]*infradb.HostRouteSpec)}
+
+// handleHostRoute handles the host route functionality
+func handleHostRoute(objectData *eventbus.ObjectData) {
+	hr, err := infradb.GetHostRoute(objectData.Name)
+	setUp := func() (string, bool) {
+		renderedHostRoutes.Lock()
+		defer renderedHostRoutes.Unlock()
+		details, ok := renderHostRoute(hr.Name, renderedHostRoutes.specs[hr.Name], hr.Spec)
+		if ok {
+			renderedHostRoutes.specs[hr.Name] = hr.Spec
+		}
+		return details, ok
+	}
+	tearDown := func() (string, bool) {
+		renderedHostRoutes.Lock()
+		defer renderedHostRoutes.Unlock()
+		details, ok := renderHostRoute(hr.Name, hr.Spec, nil)
+		if ok {
+			delete(renderedHostRoutes.specs, hr.Name)
+		}
+		return details, ok
+	}
+	handleResource(objectData, &hr.Resource, err, setUp, tearDown, infradb.UpdateHostRouteStatus)
+}
+
+// hostRouteMap returns the name of the route map setting the communities of the host route
+func hostRouteMap(name string) string {
+	return "hr-" + path.Base(name)
+}
+
+// hostRouteCmds withdraws the prefixes of the old spec which the new one no longer has and originates the
+// prefixes of the new spec through the route map setting their communities. The originated routes are in
+// the ipv4 unicast table of the vrf, which is advertised as EVPN type-5 routes, and win over the same
+// prefixes redistributed as connected routes by their origin.
+func hostRouteCmds(routeMap, router string, old, spec *infradb.HostRouteSpec) string {
+	kept := map[string]bool{}
+	if spec != nil {
+		for _, prefix := range spec.Prefixes {
+			kept[prefix.String()] = true
+		}
+	}
+	var cmds strings.Builder
+	cmds.WriteString("configure terminal\n")
+	if old != nil {
+		fmt.Fprintf(&cmds, " %s\n address-family ipv4 unicast\n", router)
+		for _, prefix := range old.Prefixes {
+			if !kept[prefix.String()] {
+				fmt.Fprintf(&cmds, " no network %s\n", prefix)
+			}
+		}
+		cmds.WriteString(" exit-address-family\n exit\n")
+	}
+	fmt.Fprintf(&cmds, " no route-map %s\n", routeMap)
+	if spec != nil {
+		fmt.Fprintf(&cmds, " route-map %s permit 10\n", routeMap)
+		if len(spec.Communities) != 0 {
+			fmt.Fprintf(&cmds, "  set community %s additive\n", strings.Join(spec.Communities, " "))
+		}
+		cmds.WriteString(" exit\n")
+		fmt.Fprintf(&cmds, " %s\n address-family ipv4 unicast\n", router)
+		for _, prefix := range spec.Prefixes {
+			fmt.Fprintf(&cmds, " network %s route-map %s\n", prefix, routeMap)
+		}
+		cmds.WriteString(" exit-address-family\n exit\n")
+	}
+	cmds.WriteString(" exit\n")
+	return cmds.String()
+}
+
+// renderHostRoute replaces the rendering of the old spec of the host route by the one of the new spec,
+// a nil spec withdraws the host routes
+func renderHostRoute(name string, old, spec *infradb.HostRouteSpec) (string, bool) {
+	vrf := ""
+	if spec != nil {
+		vrf = spec.Vrf
+	} else if old != nil {
+		vrf = old.Vrf
+	}
+	cmds := hostRouteCmds(hostRouteMap(name), bgpRouterCmd(vrf), old, spec)
+	_, err := frr.FrrBgpCmd(ctx, cmds, false)
+	if err != nil {
+		log.Printf("FRR: Error in rendering the host route %s: %v\n", name, err)
+		return fmt.Sprintf("FRR: Error in rendering the host route %s: %v\n", name, err), false
+	}
+	err = frr.Save(ctx)
+	if err != nil {
+		log.Printf("FRR(renderHostRoute): Failed to run save command: %v\n", err)
+	}
+	log.Printf("FRR: Executed %s\n", cmds)
+	return "", true
+}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"net"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_HostRouteCmds(t *testing.T) {
+	_, vip1, _ := net.ParseCIDR("10.9.0.1/32")
+	_, vip2, _ := net.ParseCIDR("10.9.0.2/32")
+	spec := &infradb.HostRouteSpec{Prefixes: []*net.IPNet{vip1, vip2}, Communities: []string{"65000:100", "no-export"}}
+	updated := &infradb.HostRouteSpec{Prefixes: []*net.IPNet{vip2}}
+	router := "router bgp 65000 vrf blue"
+
+	tests := map[string]struct {
+		old, spec *infradb.HostRouteSpec
+		expected  string
+	}{
+		"create": {
+			spec: spec,
+			expected: "configure terminal\n" +
+				" no route-map hr-vips\n" +
+				" route-map hr-vips permit 10\n  set community 65000:100 no-export additive\n exit\n" +
+				" router bgp 65000 vrf blue\n address-family ipv4 unicast\n" +
+				" network 10.9.0.1/32 route-map hr-vips\n network 10.9.0.2/32 route-map hr-vips\n" +
+				" exit-address-family\n exit\n" +
+				" exit\n",
+		},
+		"update": {
+			old:  spec,
+			spec: updated,
+			expected: "configure terminal\n" +
+				" router bgp 65000 vrf blue\n address-family ipv4 unicast\n no network 10.9.0.1/32\n exit-address-family\n exit\n" +
+				" no route-map hr-vips\n" +
+				" route-map hr-vips permit 10\n exit\n" +
+				" router bgp 65000 vrf blue\n address-family ipv4 unicast\n network 10.9.0.2/32 route-map hr-vips\n exit-address-family\n exit\n" +
+				" exit\n",
+		},
+		"delete": {
+			old: spec,
+			expected: "configure terminal\n" +
+				" router bgp 65000 vrf blue\n address-family ipv4 unicast\n" +
+				" no network 10.9.0.1/32\n no network 10.9.0.2/32\n" +
+				" exit-address-family\n exit\n" +
+				" no route-map hr-vips\n" +
+				" exit\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if cmds := hostRouteCmds("hr-vips", router, tt.old, tt.spec); cmds != tt.expected {
+				t.Errorf("expected\n%s\nreceived\n%s", tt.expected, cmds)
+			}
+		})
+	}
+}
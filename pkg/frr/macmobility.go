@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// build time check that struct implements interface
+var _ routing.MacReporter = Backend{}
+
+// EvpnMacs returns the MAC addresses of the VNIs known by zebra, in the order of their VNI and address
+func (Backend) EvpnMacs(ctx context.Context) ([]routing.EvpnMac, error) {
+	vnis := map[string]json.RawMessage{}
+	if err := zebraShow(ctx, "show evpn mac vni all json", &vnis); err != nil {
+		return nil, err
+	}
+	return parseEvpnMacs(vnis), nil
+}
+
+// parseEvpnMacs flattens "show evpn mac vni all json", the MACs of a VNI are under "macs"
+func parseEvpnMacs(vnis map[string]json.RawMessage) []routing.EvpnMac {
+	macs := []routing.EvpnMac{}
+	for key, raw := range vnis {
+		vni, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			continue
+		}
+		var table struct {
+			Macs map[string]zebraMacEntry `json:"macs"`
+		}
+		if json.Unmarshal(raw, &table) != nil {
+			continue
+		}
+		for mac, e := range table.Macs {
+			macs = append(macs, routing.EvpnMac{
+				Vni:        uint32(vni),
+				Mac:        mac,
+				RemoteVtep: e.RemoteVtep,
+				LocalSeq:   e.LocalSequence,
+				RemoteSeq:  e.RemoteSequence,
+			})
+		}
+	}
+	sort.Slice(macs, func(i, j int) bool {
+		if macs[i].Vni != macs[j].Vni {
+			return macs[i].Vni < macs[j].Vni
+		}
+		return macs[i].Mac < macs[j].Mac
+	})
+	return macs
+}
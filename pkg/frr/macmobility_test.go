@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
+)
+
+func Test_EvpnMacs(t *testing.T) {
+	mockFrr := mocks.NewFrr(t)
+	frr = mockFrr
+	t.Cleanup(func() { frr = nil })
+
+	cmd := "show evpn mac vni all json"
+	mockFrr.On("FrrZebraCmd", context.Background(), cmd, true).Return(vtyOutput(cmd, `{
+  "2000":{"numMacs":1,"macs":{
+    "aa:bb:cc:00:00:03":{"type":"remote","remoteVtep":"10.0.0.3","localSequence":0,"remoteSequence":0}}},
+  "1000":{"numMacs":2,"macs":{
+    "aa:bb:cc:00:00:02":{"type":"remote","remoteVtep":"10.0.0.2","localSequence":4,"remoteSequence":5},
+    "aa:bb:cc:00:00:01":{"type":"local","intf":"blue-10","vlan":10,"localSequence":6,"remoteSequence":5}}}
+}`), nil)
+
+	macs, err := Backend{}.EvpnMacs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []routing.EvpnMac{
+		{Vni: 1000, Mac: "aa:bb:cc:00:00:01", LocalSeq: 6, RemoteSeq: 5},
+		{Vni: 1000, Mac: "aa:bb:cc:00:00:02", RemoteVtep: "10.0.0.2", LocalSeq: 4, RemoteSeq: 5},
+		{Vni: 2000, Mac: "aa:bb:cc:00:00:03", RemoteVtep: "10.0.0.3"},
+	}
+	if !reflect.DeepEqual(macs, expected) {
+		t.Errorf("expected %+v, received %+v", expected, macs)
+	}
+}
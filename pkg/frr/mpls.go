@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// build time check that struct implements interface
+var _ routing.MplsConfigurer = Backend{}
+
+// ldpCmds renders the ldpd configuration which distributes the labels of the addresses of the node, the
+// vtep address among them, to the core behind the interfaces
+func ldpCmds(mpls routing.Mpls) string {
+	var cmds strings.Builder
+	vtep := mpls.VtepIP.Addr()
+	fmt.Fprintf(&cmds, "configure terminal\n mpls ldp\n router-id %s\n address-family ipv4\n discovery transport-address %s\n", vtep, vtep)
+	for _, dev := range mpls.Interfaces {
+		fmt.Fprintf(&cmds, " interface %s\n exit\n", dev)
+	}
+	cmds.WriteString(" exit-address-family\n exit\n exit\n")
+	return cmds.String()
+}
+
+// vpnCmds renders the default bgp instance exchanging the VPN routes of the VPCs with the neighbors, and
+// with the sr transport the labelled route of the vtep address with its prefix segment
+func vpnCmds(mpls routing.Mpls) string {
+	var cmds strings.Builder
+	fmt.Fprintf(&cmds, "configure terminal\n router bgp %+v\n", localas)
+	if mpls.Transport == routing.MplsTransportSr {
+		fmt.Fprintf(&cmds, " address-family ipv4 unicast\n network %s label-index %d\n exit-address-family\n", mpls.VtepIP.Masked(), mpls.SidIndex)
+		cmds.WriteString(" address-family ipv4 labeled-unicast\n")
+		for _, neighbor := range mpls.Neighbors {
+			fmt.Fprintf(&cmds, " neighbor %s activate\n", neighbor)
+		}
+		cmds.WriteString(" exit-address-family\n")
+	}
+	cmds.WriteString(" address-family ipv4 vpn\n")
+	for _, neighbor := range mpls.Neighbors {
+		fmt.Fprintf(&cmds, " neighbor %s activate\n", neighbor)
+	}
+	cmds.WriteString(" exit-address-family\n exit\n exit\n")
+	return cmds.String()
+}
+
+// ConfigureMpls brings up the transport of the labels in ldpd or bgpd and the VPN sessions in bgpd
+func (Backend) ConfigureMpls(ctx context.Context, mpls routing.Mpls) error {
+	if !config.GlobalConfig.LinuxFrr.Enabled {
+		return nil
+	}
+	if frr == nil {
+		return ErrNotInitialized
+	}
+	if mpls.Transport == routing.MplsTransportLdp {
+		cmds := ldpCmds(mpls)
+		if _, err := frr.FrrLdpCmd(ctx, cmds, false); err != nil {
+			log.Printf("FRR: Error in configuring ldp: %v\n", err)
+			return err
+		}
+		log.Printf("FRR: Executed %s\n", cmds)
+	}
+	cmds := vpnCmds(mpls)
+	if _, err := frr.FrrBgpCmd(ctx, cmds, false); err != nil {
+		log.Printf("FRR: Error in configuring the VPN sessions: %v\n", err)
+		return err
+	}
+	if err := frr.Save(ctx); err != nil {
+		log.Printf("FRR(ConfigureMpls): Failed to run save command: %v\n", err)
+	}
+	log.Printf("FRR: Executed %s\n", cmds)
+	return nil
+}
+
+// vpnLabel returns the service label of the VPC, auto when bgpd allocates it
+func vpnLabel(vrf *infradb.Vrf) string {
+	if vrf.Spec.Mpls.Label == 0 {
+		return "auto"
+	}
+	return fmt.Sprint(vrf.Spec.Mpls.Label)
+}
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+func Test_MplsCmds(t *testing.T) {
+	localas = 65000
+	mpls := routing.Mpls{
+		Transport:  routing.MplsTransportLdp,
+		VtepIP:     netip.MustParsePrefix("10.0.0.2/32"),
+		Interfaces: []string{"eth1", "eth2"},
+		SidIndex:   2,
+		Neighbors:  []string{"10.168.1.6"},
+	}
+	expected := "configure terminal\n mpls ldp\n router-id 10.0.0.2\n address-family ipv4\n discovery transport-address 10.0.0.2\n" +
+		" interface eth1\n exit\n interface eth2\n exit\n exit-address-family\n exit\n exit\n"
+	if cmds := ldpCmds(mpls); cmds != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, cmds)
+	}
+	expected = "configure terminal\n router bgp 65000\n address-family ipv4 vpn\n neighbor 10.168.1.6 activate\n exit-address-family\n exit\n exit\n"
+	if cmds := vpnCmds(mpls); cmds != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, cmds)
+	}
+	mpls.Transport = routing.MplsTransportSr
+	expected = "configure terminal\n router bgp 65000\n" +
+		" address-family ipv4 unicast\n network 10.0.0.2/32 label-index 2\n exit-address-family\n" +
+		" address-family ipv4 labeled-unicast\n neighbor 10.168.1.6 activate\n exit-address-family\n" +
+		" address-family ipv4 vpn\n neighbor 10.168.1.6 activate\n exit-address-family\n exit\n exit\n"
+	if cmds := vpnCmds(mpls); cmds != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, cmds)
+	}
+}
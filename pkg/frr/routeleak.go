@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// handleRouteLeak handles the route leak functionality
+func handleRouteLeak(objectData *eventbus.ObjectData) {
+	rl, err := infradb.GetRouteLeak(objectData.Name)
+	render := func() (string, bool) { return renderVrfImports(rl.Spec.DstVrf) }
+	handleResource(objectData, &rl.Resource, err, render, render, infradb.UpdateRouteLeakStatus)
+}
+
+// frrVrfName translates the vrf name to the one used by FRR
+func frrVrfName(vrf string) string {
+	if path.Base(vrf) == "GRD" {
+		return "default"
+	}
+	return infradb.LinkName(vrf, infradb.LinkRoleVrf)
+}
+
+// bgpRouterCmd returns the bgp router command of the vrf
+func bgpRouterCmd(vrf string) string {
+	if path.Base(vrf) == "GRD" {
+		return fmt.Sprintf("router bgp %+v", localas)
+	}
+	return fmt.Sprintf("router bgp %+v vrf %s", localas, frrVrfName(vrf))
+}
+
+// vrfImport is a set of prefixes of a source vrf imported by a vrf, out of a route leak
+// or out of a filtered way of a vpc peering
+type vrfImport struct {
+	prefixList string
+	srcVrf     string
+	prefixes   []*net.IPNet
+	deleted    bool
+}
+
+// vrfImports returns the imports of the destination vrf in a stable order
+func vrfImports(dstVrf string) ([]vrfImport, error) {
+	rls, err := infradb.GetAllRouteLeaks()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(rls, func(i, j int) bool { return rls[i].Name < rls[j].Name })
+	imports := []vrfImport{}
+	for _, rl := range rls {
+		if rl.Spec.DstVrf != dstVrf {
+			continue
+		}
+		imports = append(imports, vrfImport{
+			prefixList: fmt.Sprintf("leak-%s", path.Base(rl.Name)),
+			srcVrf:     rl.Spec.SrcVrf,
+			prefixes:   rl.Spec.Prefixes,
+			deleted:    rl.Status.OperStatus == infradb.OperStatusToBeDeleted,
+		})
+	}
+	vps, err := infradb.GetAllVpcPeerings()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(vps, func(i, j int) bool { return vps[i].Name < vps[j].Name })
+	for _, vp := range vps {
+		for _, dir := range vp.Spec.Directions() {
+			// the unfiltered ways import the routes of the peer through its route target
+			if dir.Dst != dstVrf || len(dir.Prefixes) == 0 {
+				continue
+			}
+			imports = append(imports, vrfImport{
+				prefixList: fmt.Sprintf("peer-%s", path.Base(vp.Name)),
+				srcVrf:     dir.Src,
+				prefixes:   dir.Prefixes,
+				deleted:    vp.Status.OperStatus == infradb.OperStatusToBeDeleted,
+			})
+		}
+	}
+	return imports, nil
+}
+
+// importFamily is an address family of the imports with the keyword of its prefix-lists
+type importFamily struct {
+	name       string
+	prefixList string
+	routeMap   string
+	maxLen     int
+	ipv4       bool
+}
+
+// importFamilies are the address families the imports are rendered in
+var importFamilies = []importFamily{
+	{name: "ipv4 unicast", prefixList: "ip", routeMap: "import", maxLen: 32, ipv4: true},
+	{name: "ipv6 unicast", prefixList: "ipv6", routeMap: "import6", maxLen: 128},
+}
+
+// prefixes returns the prefixes of the family
+func (af importFamily) prefixes(prefixes []*net.IPNet) []*net.IPNet {
+	out := []*net.IPNet{}
+	for _, prefix := range prefixes {
+		if (prefix.IP.To4() != nil) == af.ipv4 {
+			out = append(out, prefix)
+		}
+	}
+	return out
+}
+
+// renderVrfImports renders the complete import configuration of the destination vrf
+// out of all the route leaks and filtered vpc peerings that point to it. The configuration
+// is regenerated as a whole as FRR supports only one import route-map per address family.
+func renderVrfImports(dstVrf string) (string, bool) {
+	vrfImps, err := vrfImports(dstVrf)
+	if err != nil {
+		log.Printf("FRR: Failed to get the imports of vrf %s: %v\n", dstVrf, err)
+		return fmt.Sprintf("FRR: Failed to get the imports of vrf %s: %v\n", dstVrf, err), false
+	}
+
+	var cmds strings.Builder
+	cmds.WriteString("configure terminal\n")
+	for _, af := range importFamilies {
+		renderFamilyImports(&cmds, af, dstVrf, vrfImps)
+	}
+
+	_, err = frr.FrrBgpCmd(ctx, cmds.String(), false)
+	if err != nil {
+		log.Printf("FRR: Error in rendering the imports of vrf %s: %v\n", dstVrf, err)
+		return fmt.Sprintf("FRR: Error in rendering the imports of vrf %s: %v\n", dstVrf, err), false
+	}
+	err = frr.Save(ctx)
+	if err != nil {
+		log.Printf("FRR(renderVrfImports): Failed to run save command: %v\n", err)
+	}
+	log.Printf("FRR: Executed %s\n", cmds.String())
+	return "", true
+}
+
+// renderFamilyImports renders the prefix-lists, the import route-map and the imported vrfs of the address
+// family, the sources without prefixes of the family are not imported in it
+func renderFamilyImports(cmds *strings.Builder, af importFamily, dstVrf string, vrfImps []vrfImport) {
+	routeMap := fmt.Sprintf("%s-%s", af.routeMap, frrVrfName(dstVrf))
+	fmt.Fprintf(cmds, " no route-map %s\n", routeMap)
+	imports := map[string]bool{}
+	seq := 0
+	for _, imp := range vrfImps {
+		fmt.Fprintf(cmds, " no %s prefix-list %s\n", af.prefixList, imp.prefixList)
+		src := frrVrfName(imp.srcVrf)
+		prefixes := af.prefixes(imp.prefixes)
+		if imp.deleted || len(prefixes) == 0 {
+			if _, ok := imports[src]; !ok {
+				imports[src] = false
+			}
+			continue
+		}
+		imports[src] = true
+		for i, prefix := range prefixes {
+			fmt.Fprintf(cmds, " %s prefix-list %s seq %d permit %s le %d\n", af.prefixList, imp.prefixList, (i+1)*5, prefix, af.maxLen)
+		}
+		seq += 10
+		fmt.Fprintf(cmds, " route-map %s permit %d\n  match %s address prefix-list %s\n  match source-vrf %s\n exit\n",
+			routeMap, seq, af.prefixList, imp.prefixList, src)
+	}
+	fmt.Fprintf(cmds, " %s\n address-family %s\n", bgpRouterCmd(dstVrf), af.name)
+	if seq > 0 {
+		fmt.Fprintf(cmds, " import vrf route-map %s\n", routeMap)
+	} else {
+		cmds.WriteString(" no import vrf route-map\n")
+	}
+	srcs := make([]string, 0, len(imports))
+	for src := range imports {
+		srcs = append(srcs, src)
+	}
+	sort.Strings(srcs)
+	for _, src := range srcs {
+		if imports[src] {
+			fmt.Fprintf(cmds, " import vrf %s\n", src)
+		} else {
+			fmt.Fprintf(cmds, " no import vrf %s\n", src)
+		}
+	}
+	cmds.WriteString(" exit-address-family\n exit\n")
+}
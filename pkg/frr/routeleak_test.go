@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_RenderFamilyImports(t *testing.T) {
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	localas = 65000
+	prefixes := func(cidrs ...string) []*net.IPNet {
+		out := []*net.IPNet{}
+		for _, cidr := range cidrs {
+			_, ipnet, _ := net.ParseCIDR(cidr)
+			out = append(out, ipnet)
+		}
+		return out
+	}
+	imports := []vrfImport{
+		{prefixList: "leak-shared", srcVrf: "//network.opiproject.org/vrfs/shared", prefixes: prefixes("10.200.0.0/24", "2001:db8::/64")},
+		{prefixList: "peer-red", srcVrf: "//network.opiproject.org/vrfs/red", prefixes: prefixes("10.1.0.0/16")},
+	}
+	tests := map[string]struct {
+		af       importFamily
+		expected string
+	}{
+		"ipv4": {
+			af: importFamilies[0],
+			expected: " no route-map import-default\n" +
+				" no ip prefix-list leak-shared\n ip prefix-list leak-shared seq 5 permit 10.200.0.0/24 le 32\n" +
+				" route-map import-default permit 10\n  match ip address prefix-list leak-shared\n  match source-vrf shared\n exit\n" +
+				" no ip prefix-list peer-red\n ip prefix-list peer-red seq 5 permit 10.1.0.0/16 le 32\n" +
+				" route-map import-default permit 20\n  match ip address prefix-list peer-red\n  match source-vrf red\n exit\n" +
+				" router bgp 65000\n address-family ipv4 unicast\n import vrf route-map import-default\n" +
+				" import vrf red\n import vrf shared\n exit-address-family\n exit\n",
+		},
+		"ipv6": {
+			af: importFamilies[1],
+			expected: " no route-map import6-default\n" +
+				" no ipv6 prefix-list leak-shared\n ipv6 prefix-list leak-shared seq 5 permit 2001:db8::/64 le 128\n" +
+				" route-map import6-default permit 10\n  match ipv6 address prefix-list leak-shared\n  match source-vrf shared\n exit\n" +
+				" no ipv6 prefix-list peer-red\n" +
+				" router bgp 65000\n address-family ipv6 unicast\n import vrf route-map import6-default\n" +
+				" no import vrf red\n import vrf shared\n exit-address-family\n exit\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var cmds strings.Builder
+			renderFamilyImports(&cmds, tt.af, "//network.opiproject.org/vrfs/GRD", imports)
+			if cmds.String() != tt.expected {
+				t.Errorf("expected\n%s\nreceived\n%s", tt.expected, cmds.String())
+			}
+		})
+	}
+}
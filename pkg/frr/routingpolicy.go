@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// renderedPolicies holds the routing policies as last rendered, so that an update first undoes
+// the lists and the attachments which the new spec no longer has
+var renderedPolicies = struct {
+	sync.Mutex
+	specs map[string]*infradb.RoutingPolicySpec
+}{specs: make(map[string]*infradb.RoutingPolicySpec)}
+
+// handleRoutingPolicy handles the routing policy functionality
+func handleRoutingPolicy(objectData *eventbus.ObjectData) {
+	rp, err := infradb.GetRoutingPolicy(objectData.Name)
+	setUp := func() (string, bool) {
+		renderedPolicies.Lock()
+		defer renderedPolicies.Unlock()
+		details, ok := renderRoutingPolicy(rp.Name, renderedPolicies.specs[rp.Name], rp.Spec)
+		if ok {
+			renderedPolicies.specs[rp.Name] = rp.Spec
+		}
+		return details, ok
+	}
+	tearDown := func() (string, bool) {
+		renderedPolicies.Lock()
+		defer renderedPolicies.Unlock()
+		details, ok := renderRoutingPolicy(rp.Name, rp.Spec, nil)
+		if ok {
+			delete(renderedPolicies.specs, rp.Name)
+		}
+		return details, ok
+	}
+	handleResource(objectData, &rp.Resource, err, setUp, tearDown, infradb.UpdateRoutingPolicyStatus)
+}
+
+// policyRouteMap returns the name of the route map rendering the routing policy
+func policyRouteMap(name string) string {
+	return "rp-" + path.Base(name)
+}
+
+// attachPoint is where a route map is applied in the bgp configuration
+type attachPoint struct {
+	router string
+	family string
+	bind   string
+	unbind string
+}
+
+// resolveAttachment translates the attachment of the routing policy to its place in the bgp configuration:
+// the export of a vrf filters the prefixes it advertises as EVPN type-5 routes, the import the routes it
+// installs, the attachments to an svi apply to its bgp peer group
+func resolveAttachment(att *infradb.RoutingPolicyAttachment, routeMap string) (attachPoint, error) {
+	if path.Base(path.Dir(att.Target)) == "vrfs" {
+		if att.Direction == infradb.PolicyExport {
+			return attachPoint{
+				router: bgpRouterCmd(att.Target),
+				family: "l2vpn evpn",
+				bind:   fmt.Sprintf("advertise ipv4 unicast route-map %s", routeMap),
+				unbind: "advertise ipv4 unicast",
+			}, nil
+		}
+		return attachPoint{
+			router: bgpRouterCmd(att.Target),
+			family: "ipv4 unicast",
+			bind:   fmt.Sprintf("table-map %s", routeMap),
+			unbind: fmt.Sprintf("no table-map %s", routeMap),
+		}, nil
+	}
+	svi, err := infradb.GetSvi(att.Target)
+	if err != nil {
+		return attachPoint{}, err
+	}
+	lb, err := infradb.GetLB(svi.Spec.LogicalBridge)
+	if err != nil {
+		return attachPoint{}, err
+	}
+	direction := "in"
+	if att.Direction == infradb.PolicyExport {
+		direction = "out"
+	}
+	peerGroup := infradb.SviLinkName(svi, lb.Spec.VlanID)
+	return attachPoint{
+		router: bgpRouterCmd(svi.Spec.Vrf),
+		family: "ipv4 unicast",
+		bind:   fmt.Sprintf("neighbor %s route-map %s %s", peerGroup, routeMap, direction),
+		unbind: fmt.Sprintf("no neighbor %s route-map %s %s", peerGroup, routeMap, direction),
+	}, nil
+}
+
+// routingPolicyCmds renders the route map of the spec with its prefix and community lists, after removing
+// the ones of the old spec, unbinds the attach points which are gone and binds the new ones
+func routingPolicyCmds(routeMap string, old, spec *infradb.RoutingPolicySpec, unbind, bind []attachPoint) string {
+	var cmds strings.Builder
+	cmds.WriteString("configure terminal\n")
+	for _, p := range unbind {
+		fmt.Fprintf(&cmds, " %s\n address-family %s\n %s\n exit-address-family\n exit\n", p.router, p.family, p.unbind)
+	}
+	fmt.Fprintf(&cmds, " no route-map %s\n", routeMap)
+	if old != nil {
+		for _, rule := range old.Rules {
+			list := fmt.Sprintf("%s-%d", routeMap, rule.Seq)
+			if len(rule.MatchPrefixes) != 0 {
+				fmt.Fprintf(&cmds, " no %s prefix-list %s\n", prefixListFamily(rule), list)
+			}
+			if len(rule.MatchCommunities) != 0 {
+				fmt.Fprintf(&cmds, " no bgp community-list standard %s\n", list)
+			}
+		}
+	}
+	if spec != nil {
+		for _, rule := range spec.Rules {
+			list := fmt.Sprintf("%s-%d", routeMap, rule.Seq)
+			for i, prefix := range rule.MatchPrefixes {
+				fmt.Fprintf(&cmds, " %s prefix-list %s seq %d permit %s", prefixListFamily(rule), list, (i+1)*5, prefix)
+				if ones, _ := prefix.Mask.Size(); rule.MaxPrefixLen > uint32(ones) {
+					fmt.Fprintf(&cmds, " le %d", rule.MaxPrefixLen)
+				}
+				cmds.WriteString("\n")
+			}
+			for _, community := range rule.MatchCommunities {
+				fmt.Fprintf(&cmds, " bgp community-list standard %s permit %s\n", list, community)
+			}
+			action := "deny"
+			if rule.Permit {
+				action = "permit"
+			}
+			fmt.Fprintf(&cmds, " route-map %s %s %d\n", routeMap, action, rule.Seq)
+			if len(rule.MatchPrefixes) != 0 {
+				fmt.Fprintf(&cmds, "  match %s address prefix-list %s\n", prefixListFamily(rule), list)
+			}
+			if len(rule.MatchCommunities) != 0 {
+				fmt.Fprintf(&cmds, "  match community %s\n", list)
+			}
+			if rule.SetLocalPref != nil {
+				fmt.Fprintf(&cmds, "  set local-preference %d\n", *rule.SetLocalPref)
+			}
+			if rule.SetMed != nil {
+				fmt.Fprintf(&cmds, "  set metric %d\n", *rule.SetMed)
+			}
+			if len(rule.SetCommunities) != 0 {
+				fmt.Fprintf(&cmds, "  set community %s additive\n", strings.Join(rule.SetCommunities, " "))
+			}
+			cmds.WriteString(" exit\n")
+		}
+		for _, p := range bind {
+			fmt.Fprintf(&cmds, " %s\n address-family %s\n %s\n exit-address-family\n exit\n", p.router, p.family, p.bind)
+		}
+	}
+	cmds.WriteString(" exit\n")
+	return cmds.String()
+}
+
+// prefixListFamily returns the address family of the prefix list of the rule
+func prefixListFamily(rule *infradb.RoutingPolicyRule) string {
+	if len(rule.MatchPrefixes) != 0 && rule.MatchPrefixes[0].IP.To4() == nil {
+		return "ipv6"
+	}
+	return "ip"
+}
+
+// renderRoutingPolicy replaces the rendering of the old spec of the routing policy by the one of the new spec,
+// a nil spec removes the routing policy
+func renderRoutingPolicy(name string, old, spec *infradb.RoutingPolicySpec) (string, bool) {
+	routeMap := policyRouteMap(name)
+	kept := map[infradb.RoutingPolicyAttachment]bool{}
+	bind := []attachPoint{}
+	if spec != nil {
+		for _, att := range spec.Attachments {
+			p, err := resolveAttachment(att, routeMap)
+			if err != nil {
+				log.Printf("FRR: Failed to resolve the attachment %s of %s: %v\n", att.Target, name, err)
+				return fmt.Sprintf("FRR: Failed to resolve the attachment %s of %s: %v\n", att.Target, name, err), false
+			}
+			bind = append(bind, p)
+			kept[*att] = true
+		}
+	}
+	unbind := []attachPoint{}
+	if old != nil {
+		for _, att := range old.Attachments {
+			if kept[*att] {
+				continue
+			}
+			p, err := resolveAttachment(att, routeMap)
+			if err != nil {
+				// the attach point is gone together with its configuration
+				log.Printf("FRR: Skipping the detachment of %s from %s: %v\n", name, att.Target, err)
+				continue
+			}
+			unbind = append(unbind, p)
+		}
+	}
+
+	cmds := routingPolicyCmds(routeMap, old, spec, unbind, bind)
+	_, err := frr.FrrBgpCmd(ctx, cmds, false)
+	if err != nil {
+		log.Printf("FRR: Error in rendering the routing policy %s: %v\n", name, err)
+		return fmt.Sprintf("FRR: Error in rendering the routing policy %s: %v\n", name, err), false
+	}
+	err = frr.Save(ctx)
+	if err != nil {
+		log.Printf("FRR(renderRoutingPolicy): Failed to run save command: %v\n", err)
+	}
+	log.Printf("FRR: Executed %s\n", cmds)
+	return "", true
+}
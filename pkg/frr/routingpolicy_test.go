@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"net"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_RoutingPolicyCmds(t *testing.T) {
+	_, prefix, _ := net.ParseCIDR("10.1.0.0/16")
+	_, prefix6, _ := net.ParseCIDR("2001:db8::/32")
+	localPref := uint32(200)
+	spec := &infradb.RoutingPolicySpec{
+		Rules: []*infradb.RoutingPolicyRule{
+			{Seq: 10, Permit: true, MatchPrefixes: []*net.IPNet{prefix}, MaxPrefixLen: 24, SetLocalPref: &localPref, SetCommunities: []string{"65000:1"}},
+			{Seq: 20, Permit: false, MatchPrefixes: []*net.IPNet{prefix6}, MatchCommunities: []string{"no-export"}},
+		},
+	}
+	vrf := attachPoint{router: "router bgp 65000 vrf blue", family: "l2vpn evpn",
+		bind: "advertise ipv4 unicast route-map rp-p", unbind: "advertise ipv4 unicast"}
+
+	tests := map[string]struct {
+		old, spec    *infradb.RoutingPolicySpec
+		unbind, bind []attachPoint
+		expected     string
+	}{
+		"create": {
+			spec: spec,
+			bind: []attachPoint{vrf},
+			expected: "configure terminal\n" +
+				" no route-map rp-p\n" +
+				" ip prefix-list rp-p-10 seq 5 permit 10.1.0.0/16 le 24\n" +
+				" route-map rp-p permit 10\n  match ip address prefix-list rp-p-10\n" +
+				"  set local-preference 200\n  set community 65000:1 additive\n exit\n" +
+				" ipv6 prefix-list rp-p-20 seq 5 permit 2001:db8::/32\n" +
+				" bgp community-list standard rp-p-20 permit no-export\n" +
+				" route-map rp-p deny 20\n  match ipv6 address prefix-list rp-p-20\n  match community rp-p-20\n exit\n" +
+				" router bgp 65000 vrf blue\n address-family l2vpn evpn\n advertise ipv4 unicast route-map rp-p\n exit-address-family\n exit\n" +
+				" exit\n",
+		},
+		"delete": {
+			old:    spec,
+			unbind: []attachPoint{vrf},
+			expected: "configure terminal\n" +
+				" router bgp 65000 vrf blue\n address-family l2vpn evpn\n advertise ipv4 unicast\n exit-address-family\n exit\n" +
+				" no route-map rp-p\n" +
+				" no ip prefix-list rp-p-10\n" +
+				" no ipv6 prefix-list rp-p-20\n no bgp community-list standard rp-p-20\n" +
+				" exit\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if cmds := routingPolicyCmds("rp-p", tt.old, tt.spec, tt.unbind, tt.bind); cmds != tt.expected {
+				t.Errorf("expected\n%s\nreceived\n%s", tt.expected, cmds)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// build time check that struct implements interface
+var _ routing.ConfigReporter = Backend{}
+
+// RunningVrfs returns the vrfs of the running config of zebra with their L3 VNI and whether bgpd has an
+// instance for them, in the order of their names. The default vrf is left out.
+func (Backend) RunningVrfs(ctx context.Context) ([]routing.RunningVrf, error) {
+	if frr == nil {
+		return nil, ErrNotInitialized
+	}
+	zebraConf, err := frr.FrrZebraCmd(ctx, "show running-config", true)
+	if err != nil {
+		return nil, fmt.Errorf("zebra show running-config: %v", err)
+	}
+	bgpConf, err := frr.FrrBgpCmd(ctx, "show running-config", true)
+	if err != nil {
+		return nil, fmt.Errorf("bgpd show running-config: %v", err)
+	}
+	return parseRunningVrfs(zebraConf, bgpConf), nil
+}
+
+// parseRunningVrfs reads the "vrf <name>" blocks of zebra with their "vni <vni>" and the
+// "router bgp <as> vrf <name>" instances of bgpd
+func parseRunningVrfs(zebraConf, bgpConf string) []routing.RunningVrf {
+	vrfs := map[string]*routing.RunningVrf{}
+	get := func(name string) *routing.RunningVrf {
+		vrf, ok := vrfs[name]
+		if !ok {
+			vrf = &routing.RunningVrf{Name: name}
+			vrfs[name] = vrf
+		}
+		return vrf
+	}
+
+	var current *routing.RunningVrf
+	for _, line := range strings.Split(zebraConf, "\n") {
+		line = strings.TrimRight(line, "\r")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 2 && fields[0] == "vrf" && !strings.HasPrefix(line, " "):
+			current = get(fields[1])
+		case len(fields) >= 2 && fields[0] == "vni" && current != nil:
+			if vni, err := strconv.ParseUint(fields[1], 10, 32); err == nil {
+				current.Vni = uint32(vni)
+			}
+		case line == "exit-vrf" || line == "!":
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(bgpConf, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 5 && fields[0] == "router" && fields[1] == "bgp" && fields[3] == "vrf" && fields[4] != "default" {
+			get(fields[4]).Bgp = true
+		}
+	}
+
+	out := make([]routing.RunningVrf, 0, len(vrfs))
+	for _, vrf := range vrfs {
+		out = append(out, *vrf)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
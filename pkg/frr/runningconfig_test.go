@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+func Test_ParseRunningVrfs(t *testing.T) {
+	zebraConf := `show running-config
+Building configuration...
+
+Current configuration:
+!
+frr version 8.5
+hostname dpu
+!
+vrf blue
+ vni 1000
+exit-vrf
+!
+vrf red
+ vni 2000 prefix-routes-only
+exit-vrf
+!
+vrf green
+exit-vrf
+!
+interface eth0
+ ip address 10.0.0.1/24
+exit
+!
+end
+dpu# `
+	bgpConf := `show running-config
+!
+router bgp 65000
+ bgp router-id 10.0.0.1
+exit
+!
+router bgp 65000 vrf blue
+ bgp router-id 10.0.0.1
+exit
+!
+router bgp 65000 vrf yellow
+exit
+!
+end`
+	expected := []routing.RunningVrf{
+		{Name: "blue", Vni: 1000, Bgp: true},
+		{Name: "green"},
+		{Name: "red", Vni: 2000},
+		{Name: "yellow", Bgp: true},
+	}
+	if vrfs := parseRunningVrfs(zebraConf, bgpConf); !reflect.DeepEqual(vrfs, expected) {
+		t.Errorf("expected %+v, received %+v", expected, vrfs)
+	}
+}
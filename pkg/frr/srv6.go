@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// build time check that struct implements interface
+var _ routing.Srv6Configurer = Backend{}
+
+// locatorCmds renders the zebra configuration of the locator and of the source address of the encapsulation
+func locatorCmds(srv6 routing.Srv6) string {
+	return fmt.Sprintf("configure terminal\n segment-routing\n srv6\n encapsulation\n source-address %s\n exit\n"+
+		" locators\n locator %s\n prefix %s\n exit\n exit\n exit\n exit\n exit\n", srv6.Source, srv6.LocatorName, srv6.Locator)
+}
+
+// srv6VpnCmds renders the default bgp instance allocating the SIDs of the VPCs from the locator, advertising
+// the locator and exchanging the VPN routes of the VPCs with the neighbors
+func srv6VpnCmds(srv6 routing.Srv6) string {
+	var cmds strings.Builder
+	fmt.Fprintf(&cmds, "configure terminal\n router bgp %+v\n segment-routing srv6\n locator %s\n exit\n", localas, srv6.LocatorName)
+	for _, family := range []string{"ipv6 unicast", "ipv4 vpn", "ipv6 vpn"} {
+		fmt.Fprintf(&cmds, " address-family %s\n", family)
+		if family == "ipv6 unicast" {
+			fmt.Fprintf(&cmds, " network %s\n", srv6.Locator)
+		}
+		for _, neighbor := range srv6.Neighbors {
+			fmt.Fprintf(&cmds, " neighbor %s activate\n", neighbor)
+		}
+		cmds.WriteString(" exit-address-family\n")
+	}
+	cmds.WriteString(" exit\n exit\n")
+	return cmds.String()
+}
+
+// ConfigureSrv6 sets up the locator in zebra and the SRv6 VPN sessions in bgpd
+func (Backend) ConfigureSrv6(ctx context.Context, srv6 routing.Srv6) error {
+	if !config.GlobalConfig.LinuxFrr.Enabled {
+		return nil
+	}
+	if frr == nil {
+		return ErrNotInitialized
+	}
+	cmds := locatorCmds(srv6)
+	if _, err := frr.FrrZebraCmd(ctx, cmds, false); err != nil {
+		log.Printf("FRR: Error in configuring the srv6 locator: %v\n", err)
+		return err
+	}
+	log.Printf("FRR: Executed %s\n", cmds)
+	cmds = srv6VpnCmds(srv6)
+	if _, err := frr.FrrBgpCmd(ctx, cmds, false); err != nil {
+		log.Printf("FRR: Error in configuring the srv6 VPN sessions: %v\n", err)
+		return err
+	}
+	if err := frr.Save(ctx); err != nil {
+		log.Printf("FRR(ConfigureSrv6): Failed to run save command: %v\n", err)
+	}
+	log.Printf("FRR: Executed %s\n", cmds)
+	return nil
+}
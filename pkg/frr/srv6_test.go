@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+func Test_Srv6Cmds(t *testing.T) {
+	localas = 65000
+	srv6 := routing.Srv6{
+		LocatorName: "opi",
+		Locator:     netip.MustParsePrefix("fc00:0:1::/48"),
+		Source:      netip.MustParseAddr("fc00:0:1::1"),
+		Neighbors:   []string{"eth2"},
+	}
+	expected := "configure terminal\n segment-routing\n srv6\n encapsulation\n source-address fc00:0:1::1\n exit\n" +
+		" locators\n locator opi\n prefix fc00:0:1::/48\n exit\n exit\n exit\n exit\n exit\n"
+	if cmds := locatorCmds(srv6); cmds != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, cmds)
+	}
+	expected = "configure terminal\n router bgp 65000\n segment-routing srv6\n locator opi\n exit\n" +
+		" address-family ipv6 unicast\n network fc00:0:1::/48\n neighbor eth2 activate\n exit-address-family\n" +
+		" address-family ipv4 vpn\n neighbor eth2 activate\n exit-address-family\n" +
+		" address-family ipv6 vpn\n neighbor eth2 activate\n exit-address-family\n exit\n exit\n"
+	if cmds := srv6VpnCmds(srv6); cmds != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, cmds)
+	}
+}
@@ -0,0 +1,336 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// ErrNotInitialized is returned when the state of FRR is queried while the module is disabled
+var ErrNotInitialized = errors.New("FRR module is not initialized")
+
+// zebraVni is the json representation of a VNI in "show evpn vni json"
+type zebraVni struct {
+	Vni       uint32 `json:"vni"`
+	Type      string `json:"type"`
+	VxlanIf   string `json:"vxlanIf"`
+	TenantVrf string `json:"tenantVrf"`
+	NumMacs   int    `json:"numMacs"`
+	NumArpNd  int    `json:"numArpNd"`
+}
+
+// bgpNexthop is the json representation of a nexthop of a bgp path
+type bgpNexthop struct {
+	IP   string `json:"ip"`
+	Afi  string `json:"afi"`
+	Used bool   `json:"used"`
+}
+
+// bgpPath is the json representation of a path of a bgp route
+type bgpPath struct {
+	Valid     bool         `json:"valid"`
+	Bestpath  bool         `json:"bestpath"`
+	Multipath bool         `json:"multipath"`
+	PathFrom  string       `json:"pathFrom"`
+	RouteType int          `json:"routeType"`
+	Mac       string       `json:"mac"`
+	IP        string       `json:"ip"`
+	Path      string       `json:"path"`
+	Origin    string       `json:"origin"`
+	Nexthops  []bgpNexthop `json:"nexthops"`
+}
+
+// bgpPaths decodes the paths of a route, some FRR releases nest them in one more array
+type bgpPaths []bgpPath
+
+// UnmarshalJSON flattens the nested arrays of paths
+func (p *bgpPaths) UnmarshalJSON(data []byte) error {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+	for _, elem := range elems {
+		if strings.HasPrefix(strings.TrimSpace(string(elem)), "[") {
+			var nested []bgpPath
+			if err := json.Unmarshal(elem, &nested); err != nil {
+				return err
+			}
+			*p = append(*p, nested...)
+			continue
+		}
+		var path bgpPath
+		if err := json.Unmarshal(elem, &path); err != nil {
+			return err
+		}
+		*p = append(*p, path)
+	}
+	return nil
+}
+
+// nexthopIPs returns the addresses of the nexthops of the path
+func (p *bgpPath) nexthopIPs() []string {
+	ips := []string{}
+	for _, nh := range p.Nexthops {
+		ips = append(ips, nh.IP)
+	}
+	return ips
+}
+
+// bgpPeerJSON is the json representation of a peer in "show bgp vrf <vrf> summary json"
+type bgpPeerJSON struct {
+	// RemoteAs is a number, or external and internal for the peers which have not been established yet
+	RemoteAs json.RawMessage `json:"remoteAs"`
+	State    string          `json:"state"`
+	Uptime   string          `json:"peerUptime"`
+	PfxRcd   int             `json:"pfxRcd"`
+	PfxSnt   int             `json:"pfxSnt"`
+}
+
+// decodeVtyJSON decodes the json document out of the vty output, which echoes
+// the command before it and ends with the prompt
+func decodeVtyJSON(output string, v interface{}) error {
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return fmt.Errorf("no json document in %q", output)
+	}
+	return json.Unmarshal([]byte(output[start:end+1]), v)
+}
+
+// bgpShow runs the show command on bgpd and decodes its json output
+func bgpShow(ctx context.Context, cmd string, v interface{}) error {
+	if frr == nil {
+		return ErrNotInitialized
+	}
+	out, err := frr.FrrBgpCmd(ctx, cmd, true)
+	if err != nil {
+		return fmt.Errorf("%s: %v", cmd, err)
+	}
+	if err := decodeVtyJSON(out, v); err != nil {
+		return fmt.Errorf("%s: %v", cmd, err)
+	}
+	return nil
+}
+
+// zebraShow runs the show command on zebra and decodes its json output
+func zebraShow(ctx context.Context, cmd string, v interface{}) error {
+	if frr == nil {
+		return ErrNotInitialized
+	}
+	out, err := frr.FrrZebraCmd(ctx, cmd, true)
+	if err != nil {
+		return fmt.Errorf("%s: %v", cmd, err)
+	}
+	if err := decodeVtyJSON(out, v); err != nil {
+		return fmt.Errorf("%s: %v", cmd, err)
+	}
+	return nil
+}
+
+// EvpnVnis returns the VNIs known by zebra completed with the bgp parameters, in the order of the VNIs
+func (Backend) EvpnVnis(ctx context.Context) ([]routing.EvpnVni, error) {
+	zebraTable := map[string]json.RawMessage{}
+	if err := zebraShow(ctx, "show evpn vni json", &zebraTable); err != nil {
+		return nil, err
+	}
+	bgpTable := map[string]json.RawMessage{}
+	if err := bgpShow(ctx, "show bgp l2vpn evpn vni json", &bgpTable); err != nil {
+		return nil, err
+	}
+	return mergeEvpnVnis(zebraTable, bgpTable), nil
+}
+
+// mergeEvpnVnis completes the VNIs of zebra with the bgp parameters. Both tables mix
+// global attributes like numVnis with the VNIs, only the objects are VNIs.
+func mergeEvpnVnis(zebraTable, bgpTable map[string]json.RawMessage) []routing.EvpnVni {
+	vnis := []routing.EvpnVni{}
+	for key, raw := range zebraTable {
+		var zv zebraVni
+		if json.Unmarshal(raw, &zv) != nil {
+			continue
+		}
+		vni := routing.EvpnVni{
+			Vni:       zv.Vni,
+			Type:      zv.Type,
+			VxlanIf:   zv.VxlanIf,
+			TenantVrf: zv.TenantVrf,
+			NumMacs:   zv.NumMacs,
+			NumArpNd:  zv.NumArpNd,
+		}
+		var bv bgpl2VpnCmd
+		if raw, ok := bgpTable[key]; ok && json.Unmarshal(raw, &bv) == nil {
+			vni.InKernel = bv.InKernel == "True"
+			vni.Rd = bv.Rd
+			vni.ImportRts = bv.ImportRts
+			vni.ExportRts = bv.ExportRts
+		}
+		vnis = append(vnis, vni)
+	}
+	sort.Slice(vnis, func(i, j int) bool { return vnis[i].Vni < vnis[j].Vni })
+	return vnis
+}
+
+// EvpnRoutes returns the paths of the l2vpn evpn table, in the order of their rd and prefix
+func (Backend) EvpnRoutes(ctx context.Context) ([]routing.EvpnRoute, error) {
+	table := map[string]json.RawMessage{}
+	if err := bgpShow(ctx, "show bgp l2vpn evpn json", &table); err != nil {
+		return nil, err
+	}
+	return parseEvpnRoutes(table), nil
+}
+
+// parseEvpnRoutes flattens the l2vpn evpn table. The table mixes the global attributes
+// like localAS with the route distinguishers, only the objects carry routes.
+func parseEvpnRoutes(table map[string]json.RawMessage) []routing.EvpnRoute {
+	routes := []routing.EvpnRoute{}
+	for rd, raw := range table {
+		prefixes := map[string]json.RawMessage{}
+		if json.Unmarshal(raw, &prefixes) != nil {
+			continue
+		}
+		for prefix, raw := range prefixes {
+			var route struct {
+				Paths bgpPaths `json:"paths"`
+			}
+			if json.Unmarshal(raw, &route) != nil {
+				continue
+			}
+			for i := range route.Paths {
+				p := &route.Paths[i]
+				if !p.Valid {
+					continue
+				}
+				routes = append(routes, routing.EvpnRoute{
+					Rd:        rd,
+					Prefix:    prefix,
+					RouteType: p.RouteType,
+					Mac:       p.Mac,
+					IP:        p.IP,
+					Nexthops:  p.nexthopIPs(),
+					Best:      p.Bestpath,
+					PathFrom:  p.PathFrom,
+				})
+			}
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Rd != routes[j].Rd {
+			return routes[i].Rd < routes[j].Rd
+		}
+		return routes[i].Prefix < routes[j].Prefix
+	})
+	return routes
+}
+
+// BgpPeers returns the bgp sessions of the vrf, in the order of their address family and address, with
+// the graceful restart state of their neighbor
+func (Backend) BgpPeers(ctx context.Context, vrf string) ([]routing.BgpPeer, error) {
+	summary := map[string]json.RawMessage{}
+	if err := bgpShow(ctx, fmt.Sprintf("show bgp vrf %s summary json", frrVrfName(vrf)), &summary); err != nil {
+		return nil, err
+	}
+	raw := map[string]json.RawMessage{}
+	if err := bgpShow(ctx, fmt.Sprintf("show bgp vrf %s neighbors json", frrVrfName(vrf)), &raw); err != nil {
+		return nil, err
+	}
+	neighbors := map[string]bgpNeighborJSON{}
+	for address, data := range raw {
+		var n bgpNeighborJSON
+		if json.Unmarshal(data, &n) == nil {
+			neighbors[address] = n
+		}
+	}
+	return parseBgpPeers(summary, neighbors), nil
+}
+
+// parseBgpPeers flattens the peers of the address families of the summary
+func parseBgpPeers(summary map[string]json.RawMessage, neighbors map[string]bgpNeighborJSON) []routing.BgpPeer {
+	peers := []routing.BgpPeer{}
+	for afi, raw := range summary {
+		var family struct {
+			Peers map[string]bgpPeerJSON `json:"peers"`
+		}
+		if json.Unmarshal(raw, &family) != nil {
+			continue
+		}
+		for address, p := range family.Peers {
+			gr := neighbors[address].GracefulRestartInfo
+			peers = append(peers, routing.BgpPeer{
+				Afi:               afi,
+				Address:           address,
+				RemoteAs:          strings.Trim(string(p.RemoteAs), `"`),
+				State:             p.State,
+				Uptime:            p.Uptime,
+				PfxRcd:            p.PfxRcd,
+				PfxSnt:            p.PfxSnt,
+				GrLocalMode:       grMode(gr.LocalGrMode),
+				GrRemoteMode:      grMode(gr.RemoteGrMode),
+				GrRestartTime:     gr.Timers.ConfiguredRestartTimer,
+				GrPeerRestartTime: gr.Timers.ReceivedRestartTimer,
+			})
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		if peers[i].Afi != peers[j].Afi {
+			return peers[i].Afi < peers[j].Afi
+		}
+		return peers[i].Address < peers[j].Address
+	})
+	return peers
+}
+
+// BgpRoutes returns the paths of the ipv4 and ipv6 unicast tables of the vrf, in the order of their prefix
+func (Backend) BgpRoutes(ctx context.Context, vrf string) ([]routing.BgpRoute, error) {
+	routes := []routing.BgpRoute{}
+	for _, afi := range []string{"ipv4", "ipv6"} {
+		var table bgpVrfCmd
+		if err := bgpShow(ctx, fmt.Sprintf("show bgp vrf %s %s unicast json", frrVrfName(vrf), afi), &table); err != nil {
+			return nil, err
+		}
+		routes = append(routes, parseBgpRoutes(&table)...)
+	}
+	weights, err := nexthopWeights(ctx, vrf)
+	if err != nil {
+		return nil, err
+	}
+	for i := range routes {
+		if routes[i].Multipath && len(routes[i].Nexthops) != 0 {
+			routes[i].Weight = weights[routes[i].Prefix][routes[i].Nexthops[0]]
+		}
+	}
+	return routes, nil
+}
+
+// parseBgpRoutes flattens the valid paths of the unicast table
+func parseBgpRoutes(table *bgpVrfCmd) []routing.BgpRoute {
+	routes := []routing.BgpRoute{}
+	for prefix, paths := range table.Routes {
+		for i := range paths {
+			p := &paths[i]
+			if !p.Valid {
+				continue
+			}
+			routes = append(routes, routing.BgpRoute{
+				Prefix:    prefix,
+				Nexthops:  p.nexthopIPs(),
+				Best:      p.Bestpath,
+				PathFrom:  p.PathFrom,
+				AsPath:    p.Path,
+				Origin:    p.Origin,
+				Multipath: p.Multipath,
+			})
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool { return routes[i].Prefix < routes[j].Prefix })
+	return routes
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
+)
+
+// vtyOutput wraps the json document like the vty does, the command is echoed and the prompt follows
+func vtyOutput(cmd, doc string) string {
+	return cmd + "\r\n" + doc + "\r\nhost#"
+}
+
+func Test_GetEvpnState(t *testing.T) {
+	mockFrr := mocks.NewFrr(t)
+	frr = mockFrr
+	t.Cleanup(func() { frr = nil })
+
+	mockFrr.On("FrrZebraCmd", context.Background(), "show evpn vni json", true).Return(vtyOutput("show evpn vni json", `{
+  "1000":{"vni":1000,"type":"L2","tenantVrf":"blue","numMacs":2,"numArpNd":1,"numRemoteVteps":1,"vxlanIf":"vxlan-1000"},
+  "2000":{"vni":2000,"type":"L3","tenantVrf":"blue","numMacs":0,"numArpNd":0,"numRemoteVteps":"n\/a","vxlanIf":"vxlan-blue"}
+}`), nil)
+	mockFrr.On("FrrBgpCmd", context.Background(), "show bgp l2vpn evpn vni json", true).Return(vtyOutput("show bgp l2vpn evpn vni json", `{
+  "advertiseAllVnis":"Enabled",
+  "numVnis":1,
+  "1000":{"vni":1000,"type":"L2","inKernel":"True","rd":"10.0.0.1:2","originatorIp":"10.0.0.1","importRts":["65000:1000"],"exportRts":["65000:1000"]}
+}`), nil)
+	mockFrr.On("FrrBgpCmd", context.Background(), "show bgp l2vpn evpn json", true).Return(vtyOutput("show bgp l2vpn evpn json", `{
+  "bgpLocalRouterId":"10.0.0.1",
+  "localAS":65000,
+  "10.0.0.2:2":{
+    "rd":"10.0.0.2:2",
+    "[2]:[0]:[48]:[aa:bb:cc:dd:ee:ff]":{"prefix":"[2]:[0]:[48]:[aa:bb:cc:dd:ee:ff]","prefixLen":352,"paths":[[
+      {"valid":true,"bestpath":true,"pathFrom":"external","routeType":2,"mac":"aa:bb:cc:dd:ee:ff","nexthops":[{"ip":"10.0.0.2","afi":"ipv4","used":true}]},
+      {"valid":false,"pathFrom":"external","routeType":2,"mac":"aa:bb:cc:dd:ee:ff","nexthops":[{"ip":"10.0.0.3","afi":"ipv4","used":true}]}
+    ]]}
+  },
+  "numPrefix":1,
+  "totalPrefix":1
+}`), nil)
+
+	vnis, err := Backend{}.EvpnVnis(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantVnis := []routing.EvpnVni{
+		{Vni: 1000, Type: "L2", VxlanIf: "vxlan-1000", TenantVrf: "blue", NumMacs: 2, NumArpNd: 1,
+			InKernel: true, Rd: "10.0.0.1:2", ImportRts: []string{"65000:1000"}, ExportRts: []string{"65000:1000"}},
+		{Vni: 2000, Type: "L3", VxlanIf: "vxlan-blue", TenantVrf: "blue"},
+	}
+	if !reflect.DeepEqual(vnis, wantVnis) {
+		t.Errorf("expected %+v, received %+v", wantVnis, vnis)
+	}
+
+	routes, err := Backend{}.EvpnRoutes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRoutes := []routing.EvpnRoute{
+		{Rd: "10.0.0.2:2", Prefix: "[2]:[0]:[48]:[aa:bb:cc:dd:ee:ff]", RouteType: 2, Mac: "aa:bb:cc:dd:ee:ff",
+			Nexthops: []string{"10.0.0.2"}, Best: true, PathFrom: "external"},
+	}
+	if !reflect.DeepEqual(routes, wantRoutes) {
+		t.Errorf("expected %+v, received %+v", wantRoutes, routes)
+	}
+}
+
+func Test_GetBgpState(t *testing.T) {
+	mockFrr := mocks.NewFrr(t)
+	frr = mockFrr
+	t.Cleanup(func() { frr = nil })
+
+	mockFrr.On("FrrBgpCmd", context.Background(), "show bgp vrf default summary json", true).Return(vtyOutput("show bgp vrf default summary json", `{
+  "ipv4Unicast":{"routerId":"10.0.0.1","as":65000,"peers":{
+    "10.1.1.2":{"remoteAs":65001,"state":"Established","peerUptime":"00:01:02","pfxRcd":3,"pfxSnt":2},
+    "eth1":{"remoteAs":"external","state":"Active","peerUptime":"never","pfxRcd":0,"pfxSnt":0}
+  }}
+}`), nil)
+	mockFrr.On("FrrBgpCmd", context.Background(), "show bgp vrf default neighbors json", true).Return(vtyOutput("show bgp vrf default neighbors json", `{
+  "10.1.1.2":{"remoteAs":65001,"bgpState":"Established","gracefulRestartInfo":{"localGrMode":"Restart*","remoteGrMode":"Helper",
+    "rBit":false,"timers":{"configuredRestartTimer":120,"receivedRestartTimer":90}}},
+  "eth1":{"remoteAs":"external","bgpState":"Active"}
+}`), nil)
+	mockFrr.On("FrrBgpCmd", context.Background(), "show bgp vrf default ipv4 unicast json", true).Return(vtyOutput("show bgp vrf default ipv4 unicast json", `{
+  "vrfId":0,"vrfName":"default","localAS":65000,
+  "routes":{"192.168.1.0/24":[{"valid":true,"bestpath":true,"pathFrom":"external","path":"65001","origin":"IGP","nexthops":[{"ip":"10.1.1.2","afi":"ipv4","used":true}]}],
+    "192.168.2.0/24":[{"valid":true,"bestpath":true,"multipath":true,"pathFrom":"external","path":"65002","origin":"IGP","nexthops":[{"ip":"10.0.0.2","afi":"ipv4","used":true}]},
+      {"valid":true,"multipath":true,"pathFrom":"external","path":"65003","origin":"IGP","nexthops":[{"ip":"10.0.0.3","afi":"ipv4","used":true}]}]}
+}`), nil)
+	mockFrr.On("FrrBgpCmd", context.Background(), "show bgp vrf default ipv6 unicast json", true).Return(vtyOutput("show bgp vrf default ipv6 unicast json", `{
+  "vrfId":0,"vrfName":"default","localAS":65000,"routes":{}
+}`), nil)
+
+	mockFrr.On("FrrZebraCmd", context.Background(), "show ip route vrf default json", true).Return(vtyOutput("show ip route vrf default json", `{
+  "192.168.2.0/24":[{"prefix":"192.168.2.0/24","protocol":"bgp","selected":true,"installed":true,"nexthops":[
+    {"ip":"10.0.0.2","afi":"ipv4","active":true,"weight":255},{"ip":"10.0.0.3","afi":"ipv4","active":true,"weight":102}]}]
+}`), nil)
+	mockFrr.On("FrrZebraCmd", context.Background(), "show ipv6 route vrf default json", true).Return(vtyOutput("show ipv6 route vrf default json", `{}`), nil)
+
+	peers, err := Backend{}.BgpPeers(context.Background(), "//network.opiproject.org/vrfs/GRD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPeers := []routing.BgpPeer{
+		{Afi: "ipv4Unicast", Address: "10.1.1.2", RemoteAs: "65001", State: "Established", Uptime: "00:01:02", PfxRcd: 3, PfxSnt: 2,
+			GrLocalMode: "Restart", GrRemoteMode: "Helper", GrRestartTime: 120, GrPeerRestartTime: 90},
+		{Afi: "ipv4Unicast", Address: "eth1", RemoteAs: "external", State: "Active", Uptime: "never"},
+	}
+	if !reflect.DeepEqual(peers, wantPeers) {
+		t.Errorf("expected %+v, received %+v", wantPeers, peers)
+	}
+
+	routes, err := Backend{}.BgpRoutes(context.Background(), "//network.opiproject.org/vrfs/GRD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRoutes := []routing.BgpRoute{
+		{Prefix: "192.168.1.0/24", Nexthops: []string{"10.1.1.2"}, Best: true, PathFrom: "external", AsPath: "65001", Origin: "IGP"},
+		{Prefix: "192.168.2.0/24", Nexthops: []string{"10.0.0.2"}, Best: true, PathFrom: "external", AsPath: "65002", Origin: "IGP", Multipath: true, Weight: 255},
+		{Prefix: "192.168.2.0/24", Nexthops: []string{"10.0.0.3"}, PathFrom: "external", AsPath: "65003", Origin: "IGP", Multipath: true, Weight: 102},
+	}
+	if !reflect.DeepEqual(routes, wantRoutes) {
+		t.Errorf("expected %+v, received %+v", wantRoutes, routes)
+	}
+}
+
+func Test_StateNotInitialized(t *testing.T) {
+	if _, err := (Backend{}).EvpnRoutes(context.Background()); err != ErrNotInitialized {
+		t.Errorf("expected %v, received %v", ErrNotInitialized, err)
+	}
+}
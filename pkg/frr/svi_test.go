@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"net"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_SviBgpCmds(t *testing.T) {
+	localas = 65000
+	remoteAs := uint32(65100)
+	_, gw, _ := net.ParseCIDR("10.0.0.1/24")
+	gw.IP = net.ParseIP("10.0.0.1")
+	svi := &infradb.Svi{Spec: &infradb.SviSpec{
+		Vrf:        "//network.opiproject.org/vrfs/blue",
+		GatewayIPs: []*net.IPNet{gw},
+		EnableBgp:  true,
+		RemoteAs:   &remoteAs,
+	}}
+	expected := "configure terminal\n router bgp 65000 vrf blue\n bgp disable-ebgp-connected-route-check\n" +
+		" neighbor blue-10 peer-group\n neighbor blue-10 remote-as 65100\n neighbor blue-10 update-source 10.0.0.1\n" +
+		" neighbor blue-10 as-override\n neighbor blue-10 soft-reconfiguration inbound\n" +
+		"  bgp listen range 10.0.0.1/24 peer-group blue-10\n exit"
+	if cmds := sviBgpCmds(svi, "blue", "blue-10"); cmds != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, cmds)
+	}
+
+	svi.Spec.GatewayIPs = nil
+	expected = "configure terminal\n router bgp 65000 vrf blue\n bgp disable-ebgp-connected-route-check\n" +
+		" neighbor blue-10 interface remote-as 65100\n neighbor blue-10 as-override\n neighbor blue-10 soft-reconfiguration inbound\n" +
+		" address-family ipv6 unicast\n neighbor blue-10 activate\n neighbor blue-10 as-override\n exit-address-family\n exit"
+	if cmds := sviBgpCmds(svi, "blue", "blue-10"); cmds != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, cmds)
+	}
+}
+
+func Test_VrfRouterID(t *testing.T) {
+	_, loopback, _ := net.ParseCIDR("10.1.1.1/32")
+	_, vtep, _ := net.ParseCIDR("10.2.2.2/32")
+	vrf := &infradb.Vrf{Spec: &infradb.VrfSpec{LoopbackIP: loopback, VtepIP: vtep}}
+	if id := vrfRouterID(vrf); id != "10.1.1.1" {
+		t.Errorf("expected the loopback as router id, received %s", id)
+	}
+	vrf.Spec.LoopbackIP = nil
+	if id := vrfRouterID(vrf); id != "10.2.2.2" {
+		t.Errorf("expected the VTEP as router id of a VRF without loopback, received %s", id)
+	}
+}
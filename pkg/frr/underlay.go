@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// build time check that struct implements interface
+var _ routing.UnderlayConfigurer = Backend{}
+
+// underlayRaInterval is the interval of the router advertisements of the unnumbered uplinks in seconds, short
+// for the sessions to come up quickly
+const underlayRaInterval = 10
+
+// underlayCmds renders the default bgp instance of the underlay: the sessions to the fabric, which carry
+// the IPv4 routes and the EVPN routes, and the advertisement of the vtep address. The unnumbered sessions
+// peer with the IPv6 link local address of the neighbor on the interface and carry the IPv4 routes with
+// IPv6 next hops (RFC 5549).
+func underlayCmds(underlay routing.Underlay) string {
+	var cmds strings.Builder
+	cmds.WriteString("configure terminal\n")
+	fmt.Fprintf(&cmds, " router bgp %+v\n", localas)
+	if underlay.RouterID != "" {
+		fmt.Fprintf(&cmds, " bgp router-id %s\n", underlay.RouterID)
+	}
+	cmds.WriteString(gracefulRestartCmds(config.GlobalConfig.Routing.GracefulRestart))
+	neighbors := make([]string, 0, len(underlay.Peers))
+	for _, peer := range underlay.Peers {
+		if peer.Interface != "" {
+			fmt.Fprintf(&cmds, " neighbor %s interface remote-as %s\n", peer.Interface, peer.RemoteAs)
+			fmt.Fprintf(&cmds, " neighbor %s capability extended-nexthop\n", peer.Interface)
+			neighbors = append(neighbors, peer.Interface)
+		} else {
+			fmt.Fprintf(&cmds, " neighbor %s remote-as %s\n", peer.Address, peer.RemoteAs)
+			neighbors = append(neighbors, peer.Address)
+		}
+	}
+	cmds.WriteString(" address-family ipv4 unicast\n")
+	if underlay.VtepIP.IsValid() {
+		fmt.Fprintf(&cmds, " network %s\n", underlay.VtepIP.Masked())
+	}
+	for _, neighbor := range neighbors {
+		fmt.Fprintf(&cmds, " neighbor %s activate\n", neighbor)
+	}
+	cmds.WriteString(" exit-address-family\n address-family l2vpn evpn\n")
+	for _, neighbor := range neighbors {
+		fmt.Fprintf(&cmds, " neighbor %s activate\n", neighbor)
+	}
+	cmds.WriteString(" advertise-all-vni\n exit-address-family\n exit\n exit\n")
+	return cmds.String()
+}
+
+// underlayRaCmds renders the router advertisements of zebra on the interfaces of the unnumbered sessions,
+// from which the neighbors learn the link local address to peer with
+func underlayRaCmds(underlay routing.Underlay) string {
+	var cmds strings.Builder
+	for _, peer := range underlay.Peers {
+		if peer.Interface != "" {
+			fmt.Fprintf(&cmds, " interface %s\n ipv6 nd ra-interval %d\n no ipv6 nd suppress-ra\n exit\n", peer.Interface, underlayRaInterval)
+		}
+	}
+	if cmds.Len() == 0 {
+		return ""
+	}
+	return "configure terminal\n" + cmds.String() + " exit\n"
+}
+
+// ConfigureUnderlay brings up the bgp sessions of the underlay in the default instance of bgpd
+func (Backend) ConfigureUnderlay(ctx context.Context, underlay routing.Underlay) error {
+	if !config.GlobalConfig.LinuxFrr.Enabled {
+		return nil
+	}
+	if frr == nil {
+		return ErrNotInitialized
+	}
+	if raCmds := underlayRaCmds(underlay); raCmds != "" {
+		if _, err := frr.FrrZebraCmd(ctx, raCmds, false); err != nil {
+			log.Printf("FRR: Error in configuring the router advertisements of the underlay: %v\n", err)
+			return err
+		}
+		log.Printf("FRR: Executed %s\n", raCmds)
+	}
+	cmds := underlayCmds(underlay)
+	if _, err := frr.FrrBgpCmd(ctx, cmds, false); err != nil {
+		log.Printf("FRR: Error in configuring the underlay: %v\n", err)
+		return err
+	}
+	if err := frr.Save(ctx); err != nil {
+		log.Printf("FRR(ConfigureUnderlay): Failed to run save command: %v\n", err)
+	}
+	log.Printf("FRR: Executed %s\n", cmds)
+	return nil
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+func Test_UnderlayCmds(t *testing.T) {
+	localas = 65000
+	underlay := routing.Underlay{
+		RouterID: "10.0.0.2",
+		VtepIP:   netip.MustParsePrefix("10.0.0.2/32"),
+		Peers: []routing.UnderlayPeer{
+			{Address: "10.168.1.6", RemoteAs: "65001"},
+			{Interface: "eth2", RemoteAs: "external"},
+		},
+	}
+	expected := "configure terminal\n" +
+		" router bgp 65000\n bgp router-id 10.0.0.2\n" +
+		" neighbor 10.168.1.6 remote-as 65001\n neighbor eth2 interface remote-as external\n neighbor eth2 capability extended-nexthop\n" +
+		" address-family ipv4 unicast\n network 10.0.0.2/32\n neighbor 10.168.1.6 activate\n neighbor eth2 activate\n exit-address-family\n" +
+		" address-family l2vpn evpn\n neighbor 10.168.1.6 activate\n neighbor eth2 activate\n advertise-all-vni\n exit-address-family\n" +
+		" exit\n exit\n"
+	if cmds := underlayCmds(underlay); cmds != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, cmds)
+	}
+	expected = "configure terminal\n interface eth2\n ipv6 nd ra-interval 10\n no ipv6 nd suppress-ra\n exit\n exit\n"
+	if cmds := underlayRaCmds(underlay); cmds != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, cmds)
+	}
+	if cmds := underlayRaCmds(routing.Underlay{}); cmds != "" {
+		t.Errorf("expected no router advertisements without unnumbered session, received\n%s", cmds)
+	}
+	expected = "configure terminal\n router bgp 65000\n address-family ipv4 unicast\n exit-address-family\n" +
+		" address-family l2vpn evpn\n advertise-all-vni\n exit-address-family\n exit\n exit\n"
+	if cmds := underlayCmds(routing.Underlay{}); cmds != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, cmds)
+	}
+}
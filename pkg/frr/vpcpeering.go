@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// handleVpcPeering handles the vpc peering functionality
+func handleVpcPeering(objectData *eventbus.ObjectData) {
+	vp, err := infradb.GetVpcPeering(objectData.Name)
+	render := func() (string, bool) {
+		for _, vrf := range []string{vp.Spec.VrfA, vp.Spec.VrfB} {
+			if details, ok := renderVrfImports(vrf); !ok {
+				return details, false
+			}
+			if details, ok := renderVrfRouteTargets(vrf); !ok {
+				return details, false
+			}
+		}
+		return "", true
+	}
+	handleResource(objectData, &vp.Resource, err, render, render, infradb.UpdateVpcPeeringStatus)
+}
+
+// routeTarget returns the route target which FRR derives from the vni
+func routeTarget(vni uint32) string {
+	return fmt.Sprintf("%d:%d", localas, vni)
+}
+
+// routeTargetCmds renders the route targets imported by the EVPN instance of the vrf. FRR drops
+// the route target derived from the vni once one is configured, so the own one is configured too.
+func routeTargetCmds(router string, own string, imports map[string]bool) string {
+	var cmds strings.Builder
+	fmt.Fprintf(&cmds, "configure terminal\n %s\n address-family l2vpn evpn\n", router)
+	rts := make([]string, 0, len(imports))
+	peered := false
+	for rt, imported := range imports {
+		rts = append(rts, rt)
+		peered = peered || imported
+	}
+	sort.Strings(rts)
+	for _, rt := range rts {
+		if imports[rt] {
+			fmt.Fprintf(&cmds, " route-target import %s\n", rt)
+		} else {
+			fmt.Fprintf(&cmds, " no route-target import %s\n", rt)
+		}
+	}
+	if peered {
+		fmt.Fprintf(&cmds, " route-target import %s\n", own)
+	} else {
+		fmt.Fprintf(&cmds, " no route-target import %s\n", own)
+	}
+	cmds.WriteString(" exit-address-family\n exit\n exit\n")
+	return cmds.String()
+}
+
+// renderVrfRouteTargets renders the route targets of the vrfs which the vrf is peered to without
+// prefix filters, so that it imports their EVPN type-5 routes
+func renderVrfRouteTargets(vrfName string) (string, bool) {
+	vrf, err := infradb.GetVrf(vrfName)
+	if err != nil {
+		log.Printf("FRR: Failed to get vrf %s: %v\n", vrfName, err)
+		return fmt.Sprintf("FRR: Failed to get vrf %s: %v\n", vrfName, err), false
+	}
+	// a vrf without vni has no EVPN instance, its peers are reached through the kernel routes only
+	if vrf.Spec.Vni == nil {
+		return "", true
+	}
+	vps, err := infradb.GetAllVpcPeerings()
+	if err != nil {
+		log.Printf("FRR: Failed to get vpc peerings: %v\n", err)
+		return fmt.Sprintf("FRR: Failed to get vpc peerings: %v\n", err), false
+	}
+	imports := map[string]bool{}
+	for _, vp := range vps {
+		for _, dir := range vp.Spec.Directions() {
+			if dir.Dst != vrfName || len(dir.Prefixes) != 0 {
+				continue
+			}
+			peer, err := infradb.GetVrf(dir.Src)
+			if err != nil || peer.Spec.Vni == nil {
+				continue
+			}
+			rt := routeTarget(*peer.Spec.Vni)
+			imports[rt] = imports[rt] || vp.Status.OperStatus != infradb.OperStatusToBeDeleted
+		}
+	}
+	if len(imports) == 0 {
+		return "", true
+	}
+
+	cmds := routeTargetCmds(bgpRouterCmd(vrfName), routeTarget(*vrf.Spec.Vni), imports)
+	_, err = frr.FrrBgpCmd(ctx, cmds, false)
+	if err != nil {
+		log.Printf("FRR: Error in rendering the route targets of vrf %s: %v\n", vrfName, err)
+		return fmt.Sprintf("FRR: Error in rendering the route targets of vrf %s: %v\n", vrfName, err), false
+	}
+	err = frr.Save(ctx)
+	if err != nil {
+		log.Printf("FRR(renderVrfRouteTargets): Failed to run save command: %v\n", err)
+	}
+	log.Printf("FRR: Executed %s\n", cmds)
+	return "", true
+}
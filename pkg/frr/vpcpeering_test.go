@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"testing"
+)
+
+func Test_RouteTargetCmds(t *testing.T) {
+	tests := map[string]struct {
+		imports  map[string]bool
+		expected string
+	}{
+		"peered": {
+			imports: map[string]bool{"65000:2000": true, "65000:3000": true},
+			expected: "configure terminal\n router bgp 65000 vrf blue\n address-family l2vpn evpn\n" +
+				" route-target import 65000:2000\n route-target import 65000:3000\n route-target import 65000:1000\n" +
+				" exit-address-family\n exit\n exit\n",
+		},
+		"one peering left": {
+			imports: map[string]bool{"65000:2000": false, "65000:3000": true},
+			expected: "configure terminal\n router bgp 65000 vrf blue\n address-family l2vpn evpn\n" +
+				" no route-target import 65000:2000\n route-target import 65000:3000\n route-target import 65000:1000\n" +
+				" exit-address-family\n exit\n exit\n",
+		},
+		"last peering gone": {
+			imports: map[string]bool{"65000:2000": false},
+			expected: "configure terminal\n router bgp 65000 vrf blue\n address-family l2vpn evpn\n" +
+				" no route-target import 65000:2000\n no route-target import 65000:1000\n" +
+				" exit-address-family\n exit\n exit\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if cmds := routeTargetCmds("router bgp 65000 vrf blue", "65000:1000", tt.imports); cmds != tt.expected {
+				t.Errorf("expected\n%s\nreceived\n%s", tt.expected, cmds)
+			}
+		})
+	}
+}
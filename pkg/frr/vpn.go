@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// vrfDataplaneCmds renders the address families of the bgp instance of the vrf which carry the VPC over
+// its dataplane: the EVPN type 5 routes of its L3 VNI, or the VPN routes of its MPLS service label or of
+// its SRv6 SIDs, whose route distinguisher and targets are derived from the VNI. The other dataplanes are
+// removed, for the VPCs which switch over.
+func vrfDataplaneCmds(vrf *infradb.Vrf) string {
+	noVpn := func(family string) string {
+		return fmt.Sprintf(" address-family %s unicast\n no import vpn\n no export vpn\n exit-address-family\n", family)
+	}
+	vpn := func(family string, export string) string {
+		id := vpnID(vrf)
+		return fmt.Sprintf(" address-family %s unicast\n %s\n rd vpn export %s\n rt vpn both %s\n export vpn\n import vpn\n exit-address-family\n", family, export, id, id)
+	}
+	noEvpn := " address-family l2vpn evpn\n no advertise ipv4 unicast\n exit-address-family\n"
+	switch {
+	case vrf.Spec.IsMpls():
+		return vpn("ipv4", "no sid vpn export\n label vpn export "+vpnLabel(vrf)) + noVpn("ipv6") + noEvpn
+	case vrf.Spec.IsSrv6():
+		return vpn("ipv4", fmt.Sprintf("no label vpn export\n sid vpn export %d", vrf.Spec.Srv6.Dt4Function)) +
+			vpn("ipv6", fmt.Sprintf("sid vpn export %d", vrf.Spec.Srv6.Dt6Function)) + noEvpn
+	}
+	return noVpn("ipv4") + noVpn("ipv6") + " address-family l2vpn evpn\n advertise ipv4 unicast\n exit-address-family\n"
+}
+
+// vpnID returns the route distinguisher of the VPC, which is also its import and export route target
+func vpnID(vrf *infradb.Vrf) string {
+	return fmt.Sprintf("%+v:%d", localas, *vrf.Spec.Vni)
+}
+
+// vpnDetails reports the VPN attributes of the VPC carried over the MPLS core or the SRv6 fabric
+func vpnDetails(vrf *infradb.Vrf) (string, bool) {
+	vpn := vpnID(vrf)
+	var dataplane string
+	if vrf.Spec.IsSrv6() {
+		locator, err := infradb.Srv6Locator()
+		if err != nil {
+			return fmt.Sprintf("FRR: %v\n", err), false
+		}
+		dt4, dt6 := vrf.Spec.Srv6.Sids(locator)
+		dataplane = fmt.Sprintf("\"sids\":{\"End.DT4\":\"%s\",\"End.DT6\":\"%s\"}", dt4, dt6)
+	} else {
+		dataplane = fmt.Sprintf("\"label\":\"%s\"", vpnLabel(vrf))
+	}
+	details := fmt.Sprintf("{ \"rd\":\"%s\",%s,\"importRts\":[\"%s\"],\"exportRts\":[\"%s\"],\"localAS\":%+v }", vpn, dataplane, vpn, vpn, localas)
+	log.Printf("FRR Details %s\n", details)
+	return details, true
+}
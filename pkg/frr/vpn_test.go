@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package frr handles the frr related functionality
+package frr
+
+import (
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+func Test_VrfDataplaneCmds(t *testing.T) {
+	localas = 65000
+	vni := uint32(1000)
+	vrf := &infradb.Vrf{Spec: &infradb.VrfSpec{Vni: &vni}}
+	expected := " address-family ipv4 unicast\n no import vpn\n no export vpn\n exit-address-family\n" +
+		" address-family ipv6 unicast\n no import vpn\n no export vpn\n exit-address-family\n" +
+		" address-family l2vpn evpn\n advertise ipv4 unicast\n exit-address-family\n"
+	if cmds := vrfDataplaneCmds(vrf); cmds != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, cmds)
+	}
+
+	vrf.Spec.Mpls = &infradb.MplsSpec{Label: 100}
+	expected = " address-family ipv4 unicast\n no sid vpn export\n label vpn export 100\n rd vpn export 65000:1000\n rt vpn both 65000:1000\n export vpn\n import vpn\n exit-address-family\n" +
+		" address-family ipv6 unicast\n no import vpn\n no export vpn\n exit-address-family\n" +
+		" address-family l2vpn evpn\n no advertise ipv4 unicast\n exit-address-family\n"
+	if cmds := vrfDataplaneCmds(vrf); cmds != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, cmds)
+	}
+	vrf.Spec.Mpls.Label = 0
+	if label := vpnLabel(vrf); label != "auto" {
+		t.Errorf("expected bgpd to allocate the label, received %s", label)
+	}
+
+	vrf.Spec.Mpls, vrf.Spec.Srv6 = nil, &infradb.Srv6Spec{Dt4Function: 1, Dt6Function: 2}
+	expected = " address-family ipv4 unicast\n no label vpn export\n sid vpn export 1\n rd vpn export 65000:1000\n rt vpn both 65000:1000\n export vpn\n import vpn\n exit-address-family\n" +
+		" address-family ipv6 unicast\n sid vpn export 2\n rd vpn export 65000:1000\n rt vpn both 65000:1000\n export vpn\n import vpn\n exit-address-family\n" +
+		" address-family l2vpn evpn\n no advertise ipv4 unicast\n exit-address-family\n"
+	if cmds := vrfDataplaneCmds(vrf); cmds != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, cmds)
+	}
+}
+
+func Test_VpnDetails(t *testing.T) {
+	localas = 65000
+	config.GlobalConfig.Srv6.Locator = "fc00:0:1::/48"
+	t.Cleanup(func() { config.GlobalConfig.Srv6 = config.Srv6Config{} })
+	vni := uint32(1000)
+	vrf := &infradb.Vrf{Spec: &infradb.VrfSpec{Vni: &vni, Srv6: &infradb.Srv6Spec{Dt4Function: 1, Dt6Function: 2}}}
+	expected := `{ "rd":"65000:1000","sids":{"End.DT4":"fc00:0:1:1::","End.DT6":"fc00:0:1:2::"},"importRts":["65000:1000"],"exportRts":["65000:1000"],"localAS":65000 }`
+	if details, ok := vpnDetails(vrf); !ok || details != expected {
+		t.Errorf("expected\n%s\nreceived\n%s", expected, details)
+	}
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package gobgp runs the EVPN control plane with an external gobgpd instead of FRR
+package gobgp
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxBatchLines is the highest number of commands sent to one run of ip or bridge
+const maxBatchLines = 1000
+
+// batchFailure matches the report of a failed command of "ip -force -batch -", e.g. Command failed -:3
+var batchFailure = regexp.MustCompile(`Command failed -:(\d+)`)
+
+// execBatch runs the lines with "<tool> -force -batch -", which goes on after a failed line, and returns
+// its output, it is replaced by the tests
+var execBatch = func(tool string, lines []string) (string, error) {
+	cmd := exec.Command(tool, "-force", "-batch", "-") //nolint:gosec
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n") + "\n")
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// runBatch runs the commands of the keys, rendered by cmdOf, with one run of their tool per maxBatchLines
+// commands and returns the error of the commands which failed by key
+func runBatch[K comparable](keys []K, cmdOf func(K) []string) map[K]error {
+	failed := map[K]error{}
+	byTool := map[string][]K{}
+	linesByTool := map[string][]string{}
+	tools := []string{}
+	for _, key := range keys {
+		cmd := cmdOf(key)
+		tool := cmd[0]
+		if _, ok := byTool[tool]; !ok {
+			tools = append(tools, tool)
+		}
+		byTool[tool] = append(byTool[tool], key)
+		linesByTool[tool] = append(linesByTool[tool], strings.Join(cmd[1:], " "))
+	}
+	for _, tool := range tools {
+		all, allLines := byTool[tool], linesByTool[tool]
+		for start := 0; start < len(all); start += maxBatchLines {
+			end := min(start+maxBatchLines, len(all))
+			chunk, lines := all[start:end], allLines[start:end]
+			out, err := execBatch(tool, lines)
+			if err == nil {
+				continue
+			}
+			matches := batchFailure.FindAllStringSubmatch(out, -1)
+			if len(matches) == 0 {
+				// the tool did not run, none of the commands is applied
+				for _, key := range chunk {
+					failed[key] = fmt.Errorf("%s -batch: %v: %s", tool, err, out)
+				}
+				continue
+			}
+			for _, m := range matches {
+				line, _ := strconv.Atoi(m[1])
+				if line >= 1 && line <= len(chunk) {
+					failed[chunk[line-1]] = fmt.Errorf("%s %s: %v", tool, lines[line-1], err)
+				}
+			}
+		}
+	}
+	return failed
+}
+
+// runStages runs the commands of the entries, ordered by stage, one stage after the other so that the
+// entries of a stage find the ones they refer to, and returns the entries whose command failed
+func runStages(entries []kernelEntry, cmdOf func(kernelEntry) []string) map[kernelEntry]error {
+	failed := map[kernelEntry]error{}
+	for start := 0; start < len(entries); {
+		end := start
+		for end < len(entries) && entries[end].stage() == entries[start].stage() {
+			end++
+		}
+		for entry, err := range runBatch(entries[start:end], cmdOf) {
+			failed[entry] = err
+		}
+		start = end
+	}
+	return failed
+}
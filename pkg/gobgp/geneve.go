@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package gobgp runs the EVPN control plane with an external gobgpd instead of FRR
+package gobgp
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// The filters of the geneve device are shared with the linux general module, which steers the flooded
+// packets of a vlan to its chain, at egress pref 2, and drops them at the end of the chain, at pref 65535
+const (
+	// geneveUnicastPref is the preference of the filters of the remote mac addresses, above the flooding
+	geneveUnicastPref = 1
+	// maxGeneveUnicast is the highest number of remote mac addresses of the logical bridges over geneve
+	maxGeneveUnicast = 1 << 20
+	// maxGeneveFlood is the highest preference of the filters mirroring a vlan to its remote vteps
+	maxGeneveFlood = 65534
+)
+
+// geneveUnicastPool allocates the handles of the filters of the remote mac addresses by vlan and mac address
+var geneveUnicastPool = newEntryIDs("geneve unicast", 1, maxGeneveUnicast, func(entry kernelEntry) (string, bool) {
+	if entry.kind != entryGeneveFdb {
+		return "", false
+	}
+	return geneveUnicastKey(entry.vlan, entry.mac), true
+})
+
+// geneveFloodPool allocates the preferences of the filters of the chains of the vlans by vlan and remote vtep
+var geneveFloodPool = newEntryIDs("geneve flood", 1, maxGeneveFlood, func(entry kernelEntry) (string, bool) {
+	if entry.kind != entryGeneveFlood {
+		return "", false
+	}
+	return geneveFloodKey(entry.vlan, entry.vtep), true
+})
+
+// l2Device is the device which carries a logical bridge to the remote vteps
+type l2Device struct {
+	dev string
+	// vlan and tunnel are set for the logical bridges carried over geneve, tunnel being the arguments
+	// of tc tunnel_key set of the logical bridge but for the remote vtep
+	vlan   uint16
+	tunnel string
+}
+
+// geneveUnicastKey identifies the filter of the mac address of the vlan
+func geneveUnicastKey(vlan uint16, mac [6]byte) string {
+	return fmt.Sprintf("vlan %d mac %s", vlan, net.HardwareAddr(mac[:]))
+}
+
+// geneveFloodKey identifies the filter mirroring the flooded packets of the vlan to the remote vtep
+func geneveFloodKey(vlan uint16, vtep string) string {
+	return fmt.Sprintf("vlan %d vtep %s", vlan, vtep)
+}
+
+// geneveEntry returns the filter of the mac address, or the flooding when it is nil, of the logical bridge
+// toward the remote vtep
+func geneveEntry(dev l2Device, vtep string, mac *[6]byte) (kernelEntry, bool) {
+	entry := kernelEntry{kind: entryGeneveFlood, dev: dev.dev, vlan: dev.vlan, tunnel: dev.tunnel, vtep: vtep}
+	key, pool := geneveFloodKey(dev.vlan, vtep), geneveFloodPool
+	if mac != nil {
+		entry.kind, entry.mac = entryGeneveFdb, *mac
+		key, pool = geneveUnicastKey(dev.vlan, *mac), geneveUnicastPool
+	}
+	if entry.id = pool.id(key); entry.id == 0 {
+		log.Printf("GoBGP: No geneve filter left for %s\n", key)
+		return kernelEntry{}, false
+	}
+	return entry, true
+}
+
+// geneveAddCmd renders the filter of the geneve device
+func (e kernelEntry) geneveAddCmd() []string {
+	tunnel := append(append([]string{"tunnel_key", "set"}, strings.Fields(e.tunnel)...), "dst_ip", e.vtep)
+	if e.kind == entryGeneveFdb {
+		// Example: tc filter replace dev gnv0 egress pref 1 handle <id> protocol 802.1Q flower vlan_id <vlan> dst_mac <mac>
+		// action vlan pop pipe action tunnel_key set id <vni> src_ip <vtep> dst_port 6081 dst_ip <remote vtep>
+		cmd := []string{"tc", "filter", "replace", "dev", e.dev, "egress", "pref", fmt.Sprint(geneveUnicastPref), "handle", fmt.Sprint(e.id),
+			"protocol", "802.1Q", "flower", "vlan_id", fmt.Sprint(e.vlan), "dst_mac", net.HardwareAddr(e.mac[:]).String(),
+			"action", "vlan", "pop", "pipe", "action"}
+		return append(cmd, tunnel...)
+	}
+	// The copy sent to the remote vtep leaves untagged, so that it does not enter the chain again
+	// Example: tc filter replace dev gnv0 egress chain <vlan> pref <id> handle 1 protocol all matchall
+	// action tunnel_key set id <vni> src_ip <vtep> dst_port 6081 dst_ip <remote vtep> pipe action mirred egress mirror dev gnv0 pipe action continue
+	cmd := []string{"tc", "filter", "replace", "dev", e.dev, "egress", "chain", fmt.Sprint(e.vlan), "pref", fmt.Sprint(e.id), "handle", "1",
+		"protocol", "all", "matchall", "action"}
+	cmd = append(append(cmd, tunnel...), "pipe")
+	return append(cmd, "action", "mirred", "egress", "mirror", "dev", e.dev, "pipe", "action", "continue")
+}
+
+// geneveDelCmd renders the command deleting the filter of the geneve device
+func (e kernelEntry) geneveDelCmd() []string {
+	if e.kind == entryGeneveFdb {
+		return []string{"tc", "filter", "del", "dev", e.dev, "egress", "pref", fmt.Sprint(geneveUnicastPref), "handle", fmt.Sprint(e.id),
+			"protocol", "802.1Q", "flower"}
+	}
+	return []string{"tc", "filter", "del", "dev", e.dev, "egress", "chain", fmt.Sprint(e.vlan), "pref", fmt.Sprint(e.id)}
+}
+
+// lbDevice returns the device of the logical bridge
+func lbDevice(lb *infradb.LogicalBridge) l2Device {
+	if lb.Spec.IsGeneve() {
+		return l2Device{dev: infradb.GeneveDevice, vlan: uint16(lb.Spec.VlanID), tunnel: symbols.intern(lb.Spec.GeneveTunnel())}
+	}
+	return l2Device{dev: symbols.intern(fmt.Sprintf("vxlan-%+v", lb.Spec.VlanID))}
+}
@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package gobgp runs the EVPN control plane with an external gobgpd instead of FRR
+package gobgp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// gobgpComp string constant
+const gobgpComp string = "gobgp"
+
+// replayThreshold time threshold for replay
+const replayThreshold = 64 * time.Second
+
+// defaultAddress is the address of the gRPC API of gobgpd when not configured
+const defaultAddress = "127.0.0.1:50051"
+
+// defaultPollInterval is the period of the programming of the received routes when not configured
+const defaultPollInterval = 5 * time.Second
+
+// defaultFlushInterval is the time given to a burst of route updates to settle when not configured
+const defaultFlushInterval = 200 * time.Millisecond
+
+// Backend is the routing backend which originates and receives the EVPN routes with gobgpd
+type Backend struct{}
+
+// build time check that struct implements interface
+var _ routing.Backend = Backend{}
+var _ routing.EncapSupporter = Backend{}
+
+// Encaps returns VXLAN and geneve, the remote vteps of the logical bridges over geneve are programmed
+// as tc filters of the geneve device
+func (Backend) Encaps() []string {
+	return []string{routing.EncapVxlan, routing.EncapGeneve}
+}
+
+// moduleGoBgpHandler empty structure
+type moduleGoBgpHandler struct{}
+
+// address is the host:port of the gRPC API of gobgpd
+var address = defaultAddress
+
+// localas is the AS number of the route distinguishers and targets
+var localas int
+
+// ecmp is how the prefixes received from several vteps are spread over them
+var ecmp config.EcmpConfig
+
+// nlink variable wrapper
+var nlink utils.Netlink
+
+// ctx variable of type context
+var ctx context.Context
+
+// stop ends the programming of the received routes
+var stop chan struct{}
+
+// execCmd runs the command and returns its output, it is replaced by the tests
+var execCmd = func(cmd []string) (string, error) {
+	out, err := exec.Command(cmd[0], cmd[1:]...).CombinedOutput() //nolint:gosec
+	if err != nil {
+		return "", fmt.Errorf("%s: %v: %s", strings.Join(cmd, " "), err, out)
+	}
+	return string(out), nil
+}
+
+// gobgpCmd runs the gobgp client against gobgpd
+func gobgpCmd(args ...string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", err
+	}
+	return execCmd(append([]string{"gobgp", "-u", host, "-p", port}, args...))
+}
+
+// Name returns the name of the backend and of the infradb component
+func (Backend) Name() string {
+	return gobgpComp
+}
+
+// Initialize subscribes to the infradb events, withdraws the routes left over by a previous
+// run, which are originated again by the replay, and starts programming the received routes
+func (Backend) Initialize() {
+	gobgpConfig := config.GlobalConfig.Routing.GoBgp
+	if gobgpConfig.Address != "" {
+		address = gobgpConfig.Address
+	}
+	localas = gobgpConfig.LocalAs
+	if localas == 0 {
+		localas = config.GlobalConfig.LinuxFrr.LocalAs
+	}
+	ecmp = config.GlobalConfig.Routing.Ecmp
+	interval := defaultPollInterval
+	if gobgpConfig.PollInterval != 0 {
+		interval = time.Duration(gobgpConfig.PollInterval) * time.Second
+	}
+	flush := defaultFlushInterval
+	if gobgpConfig.FlushInterval != 0 {
+		flush = time.Duration(gobgpConfig.FlushInterval) * time.Millisecond
+	}
+	ctx = context.Background()
+	nlink = utils.WithWriteQueue(utils.WithNetlinkFaults(utils.NewNetlinkWrapperWithArgs(config.GlobalConfig.Tracer)), utils.DefaultNamespace)
+
+	if _, err := gobgpCmd("global", "rib", "-a", "evpn", "del", "all"); err != nil {
+		log.Printf("GoBGP: Failed to withdraw the stale routes: %v\n", err)
+	}
+	subscribeInfradb(&config.GlobalConfig)
+
+	stop = make(chan struct{})
+	updates := make(chan struct{}, 1)
+	go monitorRib(updates, stop)
+	go watchRib(interval, flush, updates, stop)
+}
+
+// DeInitialize unsubscribes from the infradb events and stops programming the received routes
+func (Backend) DeInitialize() {
+	eb := eventbus.EBus
+	eb.UnsubscribeModule(gobgpComp)
+	if stop != nil {
+		close(stop)
+		stop = nil
+	}
+}
+
+// Probe checks that gobgpd accepts connections on its gRPC API
+func (Backend) Probe(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: 10 * time.Second, Control: utils.BindToDevice("")}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// DeepProbe checks that gobgpd answers requests
+func (Backend) DeepProbe(context.Context) error {
+	_, err := gobgpCmd("global")
+	return err
+}
+
+// subscribeInfradb function handles the infradb subscriptions
+func subscribeInfradb(config *config.Config) {
+	eb := eventbus.EBus
+	for _, subscriberConfig := range config.Subscribers {
+		if subscriberConfig.Name == gobgpComp {
+			for _, eventType := range subscriberConfig.Events {
+				eb.StartSubscriber(subscriberConfig.Name, eventType, subscriberConfig.Priority, &moduleGoBgpHandler{})
+			}
+		}
+	}
+}
+
+// HandleEvent handles the events. The originated routes are derived from all the objects,
+// so every event reconciles them all and reports the status of the object of the event.
+func (h *moduleGoBgpHandler) HandleEvent(eventType string, objectData *eventbus.ObjectData) {
+	log.Printf("GoBGP recevied %s %s\n", eventType, objectData.Name)
+	var update func(common.Component) error
+	var comps []common.Component
+	switch eventType {
+	case "vrf":
+		update = func(comp common.Component) error {
+			return infradb.UpdateVrfStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
+		}
+		if vrf, err := infradb.GetVrf(objectData.Name); err == nil {
+			comps = vrf.Status.Components
+		}
+	case "svi":
+		update = func(comp common.Component) error {
+			return infradb.UpdateSviStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
+		}
+		if svi, err := infradb.GetSvi(objectData.Name); err == nil {
+			comps = svi.Status.Components
+		}
+	case "logical-bridge":
+		update = func(comp common.Component) error {
+			return infradb.UpdateLBStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
+		}
+		if lb, err := infradb.GetLB(objectData.Name); err == nil {
+			comps = lb.Status.Components
+		}
+	case "bridge-port":
+		update = func(comp common.Component) error {
+			return infradb.UpdateBPStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
+		}
+		if bp, err := infradb.GetBP(objectData.Name); err == nil {
+			comps = bp.Status.Components
+		}
+	case "host-route":
+		update = func(comp common.Component) error {
+			return infradb.UpdateHostRouteStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, comp)
+		}
+		if hr, err := infradb.GetHostRoute(objectData.Name); err == nil {
+			comps = hr.Status.Components
+		}
+	default:
+		log.Printf("error: Unknown event type %s", eventType)
+		return
+	}
+
+	comp := common.Component{Name: gobgpComp}
+	for _, c := range comps {
+		if c.Name == gobgpComp {
+			comp = c
+		}
+	}
+	details, ok := reconcilePaths()
+	comp.Details = details
+	if ok {
+		comp.CompStatus = common.ComponentStatusSuccess
+		comp.Timer = 0
+	} else {
+		if comp.Timer == 0 { // wait timer is 2 powerof natural numbers ex : 1,2,3...
+			comp.Timer = 2 * time.Second
+		} else {
+			comp.Timer *= 2
+		}
+		comp.CompStatus = common.ComponentStatusError
+	}
+	log.Printf("%+v\n", comp)
+
+	// Checking the timer to decide if we need to replay or not
+	comp.CheckReplayThreshold(replayThreshold)
+
+	if err := update(comp); err != nil {
+		log.Printf("error in updating %s status: %s\n", eventType, err)
+	}
+}
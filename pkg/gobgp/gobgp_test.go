@@ -0,0 +1,510 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package gobgp runs the EVPN control plane with an external gobgpd instead of FRR
+package gobgp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+func Test_OriginatedPaths(t *testing.T) {
+	localas = 65000
+	l2vni, l3vni := uint32(1000), uint32(2000)
+	vtep := &net.IPNet{IP: net.ParseIP("10.0.0.1").To4(), Mask: net.CIDRMask(32, 32)}
+	bpMac, _ := net.ParseMAC("aa:bb:cc:00:00:01")
+	sviMac, _ := net.ParseMAC("aa:bb:cc:00:00:02")
+	_, gw, _ := net.ParseCIDR("192.168.1.1/24")
+	gw.IP = net.ParseIP("192.168.1.1").To4()
+	_, vip, _ := net.ParseCIDR("10.9.0.1/32")
+
+	state := &localState{
+		vrfs: []*infradb.Vrf{{Name: "//network.opiproject.org/vrfs/blue", Spec: &infradb.VrfSpec{Vni: &l3vni, VtepIP: vtep}}},
+		lbs:  []*infradb.LogicalBridge{{Name: "//network.opiproject.org/bridges/br10", Spec: &infradb.LogicalBridgeSpec{VlanID: 10, Vni: &l2vni, VtepIP: vtep}}},
+		bps: []*infradb.BridgePort{{Name: "//network.opiproject.org/ports/p1", Spec: &infradb.BridgePortSpec{
+			MacAddress: &bpMac, LogicalBridges: []string{"//network.opiproject.org/bridges/br10"}}}},
+		svis: []*infradb.Svi{{Name: "//network.opiproject.org/svis/s1", Spec: &infradb.SviSpec{
+			Vrf: "//network.opiproject.org/vrfs/blue", LogicalBridge: "//network.opiproject.org/bridges/br10",
+			MacAddress: &sviMac, GatewayIPs: []*net.IPNet{gw}}}},
+		hrs: []*infradb.HostRoute{{Spec: &infradb.HostRouteSpec{Vrf: "//network.opiproject.org/vrfs/blue",
+			Prefixes: []*net.IPNet{vip}, Communities: []string{"65000:100", "no-export"}}}},
+	}
+	paths, err := state.originatedPaths(func(string) (string, error) { return "aa:bb:cc:00:00:03", nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(paths))
+	for key := range paths {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	expected := []string{
+		"macadv aa:bb:cc:00:00:01 0.0.0.0 etag 0 label 1000 rd 65000:1000 rt 65000:1000 encap vxlan nexthop 10.0.0.1",
+		"macadv aa:bb:cc:00:00:02 192.168.1.1 etag 0 label 1000 rd 65000:1000 rt 65000:1000 encap vxlan nexthop 10.0.0.1 default-gateway",
+		"multicast 10.0.0.1 etag 0 rd 65000:1000 rt 65000:1000 encap vxlan pmsi ingress-repl 1000 10.0.0.1",
+		"prefix 10.9.0.1/32 gw 0.0.0.0 etag 0 label 2000 rd 65000:2000 rt 65000:2000 encap vxlan nexthop 10.0.0.1 router-mac aa:bb:cc:00:00:03 community 65000:100,no-export",
+		"prefix 192.168.1.0/24 gw 0.0.0.0 etag 0 label 2000 rd 65000:2000 rt 65000:2000 encap vxlan nexthop 10.0.0.1 router-mac aa:bb:cc:00:00:03",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %q, received %q", expected, keys)
+	}
+}
+
+// testRib is the evpn rib with a path originated locally and the three route types received from a peer
+const testRib = `{
+  "[type:macadv][rd:65000:1000][etag:0][mac:aa:bb:cc:00:00:01][ip:<nil>]": [
+    {"nlri":{"type":2,"value":{"rd":{"type":0,"admin":65000,"assigned":1000},"mac":"aa:bb:cc:00:00:01","ip":"<nil>","labels":[1000]}},
+     "best":true,"attrs":[{"type":14,"nexthop":"10.0.0.1"}]}
+  ],
+  "[type:macadv][rd:65001:1000][etag:0][mac:aa:bb:cc:00:00:09][ip:<nil>]": [
+    {"nlri":{"type":2,"value":{"rd":{"type":0,"admin":65001,"assigned":1000},"mac":"aa:bb:cc:00:00:09","ip":"<nil>","labels":[1000]}},
+     "best":true,"attrs":[{"type":14,"nexthop":"10.0.0.2"}],"neighbor-ip":"10.0.0.2"}
+  ],
+  "[type:multicast][rd:65001:1000][etag:0][ip:10.0.0.2]": [
+    {"nlri":{"type":3,"value":{"rd":{"type":0,"admin":65001,"assigned":1000},"ip":"10.0.0.2"}},
+     "best":true,"attrs":[{"type":14,"nexthop":"10.0.0.2"},{"type":22,"label":1000,"tunnel-id":"10.0.0.2"}],"neighbor-ip":"10.0.0.2"}
+  ],
+  "[type:Prefix][rd:65001:2000][etag:0][prefix:192.168.2.0/24]": [
+    {"nlri":{"type":5,"value":{"rd":{"type":0,"admin":65001,"assigned":2000},"prefix":"192.168.2.0/24","gateway":"0.0.0.0","label":2000}},
+     "best":true,"attrs":[{"type":14,"nexthop":"10.0.0.2"},{"type":16,"value":[{"type":6,"subtype":3,"mac":"aa:bb:cc:00:00:0a"}]}],"neighbor-ip":"10.0.0.2"}
+  ]
+}`
+
+func Test_KernelEntries(t *testing.T) {
+	paths, err := parseRib([]byte(testRib))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 4 || paths[0].Rd != "65000:1000" || paths[0].Neighbor != "" {
+		t.Fatalf("unexpected paths %+v", paths)
+	}
+	if counts := vniCounts(paths); counts[1000] != 1 {
+		t.Errorf("expected one received mac address, received %v", counts)
+	}
+
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	nexthopPool = newNexthopIDs()
+	entries := kernelEntries(paths, map[uint32]l2Device{1000: {dev: "vxlan-10"}}, map[uint32]string{2000: "//network.opiproject.org/vrfs/blue"}, config.EcmpConfig{})
+	keys := make([]string, 0, len(entries))
+	for entry := range entries {
+		keys = append(keys, strings.Join(entry.addCmd(), " "))
+	}
+	sort.Strings(keys)
+	expected := []string{
+		"bridge fdb append 00:00:00:00:00:00 dev vxlan-10 dst 10.0.0.2 self permanent",
+		"bridge fdb replace aa:bb:cc:00:00:09 dev vxlan-10 dst 10.0.0.2 self static",
+		"bridge fdb replace aa:bb:cc:00:00:0a dev vxlan-blue dst 10.0.0.2 self static",
+		"ip neigh replace 10.0.0.2 lladdr aa:bb:cc:00:00:0a dev br-blue nud noarp",
+		"ip nexthop replace id 268435456 via 10.0.0.2 dev br-blue onlink",
+		"ip route replace 192.168.2.0/24 vrf blue nhid 268435456",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %q, received %q", expected, keys)
+	}
+}
+
+func Test_KernelEntriesGeneve(t *testing.T) {
+	paths, err := parseRib([]byte(testRib))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vni := uint32(1000)
+	vtep := &net.IPNet{IP: net.ParseIP("10.0.0.1").To4(), Mask: net.CIDRMask(32, 32)}
+	lb := &infradb.LogicalBridge{Spec: &infradb.LogicalBridgeSpec{VlanID: 10, Vni: &vni, VtepIP: vtep,
+		Encap: &infradb.EncapSpec{Type: routing.EncapGeneve, Options: []infradb.GeneveOption{{Class: 0x0102, Type: 0x80, Data: []byte{0, 0x11, 0x22, 0x33}}}}}}
+	if lbEncap(lb) != routing.EncapGeneve {
+		t.Errorf("expected the routes of the bridge to signal geneve")
+	}
+
+	geneveUnicastPool = newEntryIDs("geneve unicast", 1, maxGeneveUnicast, geneveUnicastPool.keyOf)
+	geneveFloodPool = newEntryIDs("geneve flood", 1, maxGeneveFlood, geneveFloodPool.keyOf)
+	entries := kernelEntries(paths, map[uint32]l2Device{1000: lbDevice(lb)}, nil, config.EcmpConfig{})
+	keys := make([]string, 0, len(entries))
+	for entry := range entries {
+		keys = append(keys, strings.Join(entry.addCmd(), " "))
+	}
+	sort.Strings(keys)
+	tunnel := "tunnel_key set id 1000 src_ip 10.0.0.1 dst_port 6081 geneve_opts 0102:80:00112233 dst_ip 10.0.0.2"
+	expected := []string{
+		"tc filter replace dev gnv0 egress chain 10 pref 1 handle 1 protocol all matchall action " + tunnel +
+			" pipe action mirred egress mirror dev gnv0 pipe action continue",
+		"tc filter replace dev gnv0 egress pref 1 handle 1 protocol 802.1Q flower vlan_id 10 dst_mac aa:bb:cc:00:00:09 action vlan pop pipe action " + tunnel,
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %q, received %q", expected, keys)
+	}
+	for entry := range entries {
+		if entry.kind == entryGeneveFlood && strings.Join(entry.delCmd(), " ") != "tc filter del dev gnv0 egress chain 10 pref 1" {
+			t.Errorf("unexpected delete command %q", entry.delCmd())
+		}
+	}
+
+	geneveUnicastPool.retain(nil)
+	geneveFloodPool.retain(nil)
+	if len(geneveUnicastPool.keys) != 0 || len(geneveFloodPool.keys) != 0 {
+		t.Errorf("expected the filters to be released, received %v and %v", geneveUnicastPool.keys, geneveFloodPool.keys)
+	}
+}
+
+// testEcmpRib is a prefix received from two vteps signaling the bandwidth of their links
+const testEcmpRib = `{
+  "[type:Prefix][rd:65001:2000][etag:0][prefix:192.168.2.0/24]": [
+    {"nlri":{"type":5,"value":{"rd":{"type":0,"admin":65001,"assigned":2000},"prefix":"192.168.2.0/24","gateway":"0.0.0.0","label":2000}},
+     "best":true,"attrs":[{"type":14,"nexthop":"10.0.0.3"},{"type":16,"value":[{"type":6,"subtype":3,"mac":"aa:bb:cc:00:00:0b"},{"type":64,"subtype":4,"asn":65001,"bandwidth":1.25e+09}]}],"neighbor-ip":"10.0.0.3"}
+  ],
+  "[type:Prefix][rd:65002:2000][etag:0][prefix:192.168.2.0/24]": [
+    {"nlri":{"type":5,"value":{"rd":{"type":0,"admin":65002,"assigned":2000},"prefix":"192.168.2.0/24","gateway":"0.0.0.0","label":2000}},
+     "best":true,"attrs":[{"type":14,"nexthop":"10.0.0.2"},{"type":16,"value":[{"type":6,"subtype":3,"mac":"aa:bb:cc:00:00:0a"},{"type":64,"subtype":4,"asn":65002,"bandwidth":5e+08}]}],"neighbor-ip":"10.0.0.2"}
+  ]
+}`
+
+func Test_EcmpEntries(t *testing.T) {
+	paths, err := parseRib([]byte(testEcmpRib))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	l3Vnis := map[uint32]string{2000: "//network.opiproject.org/vrfs/blue"}
+	nexthopPool = newNexthopIDs()
+	tests := map[string]struct {
+		ecmp     config.EcmpConfig
+		expected []string
+	}{
+		"best path": {
+			ecmp: config.EcmpConfig{},
+			expected: []string{
+				"bridge fdb replace aa:bb:cc:00:00:0a dev vxlan-blue dst 10.0.0.2 self static",
+				"ip neigh replace 10.0.0.2 lladdr aa:bb:cc:00:00:0a dev br-blue nud noarp",
+				"ip nexthop replace id 268435456 via 10.0.0.2 dev br-blue onlink",
+				"ip route replace 192.168.2.0/24 vrf blue nhid 268435456",
+			},
+		},
+		"weighted": {
+			ecmp: config.EcmpConfig{MaxPaths: 4, Weighted: true},
+			expected: []string{
+				"bridge fdb replace aa:bb:cc:00:00:0a dev vxlan-blue dst 10.0.0.2 self static",
+				"bridge fdb replace aa:bb:cc:00:00:0b dev vxlan-blue dst 10.0.0.3 self static",
+				"ip neigh replace 10.0.0.2 lladdr aa:bb:cc:00:00:0a dev br-blue nud noarp",
+				"ip neigh replace 10.0.0.3 lladdr aa:bb:cc:00:00:0b dev br-blue nud noarp",
+				"ip nexthop replace id 268435456 via 10.0.0.2 dev br-blue onlink",
+				"ip nexthop replace id 268435457 via 10.0.0.3 dev br-blue onlink",
+				"ip nexthop replace id 268435458 group 268435456,102/268435457,255",
+				"ip route replace 192.168.2.0/24 vrf blue nhid 268435458",
+			},
+		},
+		"unweighted": {
+			ecmp: config.EcmpConfig{MaxPaths: 4},
+			expected: []string{
+				"bridge fdb replace aa:bb:cc:00:00:0a dev vxlan-blue dst 10.0.0.2 self static",
+				"bridge fdb replace aa:bb:cc:00:00:0b dev vxlan-blue dst 10.0.0.3 self static",
+				"ip neigh replace 10.0.0.2 lladdr aa:bb:cc:00:00:0a dev br-blue nud noarp",
+				"ip neigh replace 10.0.0.3 lladdr aa:bb:cc:00:00:0b dev br-blue nud noarp",
+				"ip nexthop replace id 268435456 via 10.0.0.2 dev br-blue onlink",
+				"ip nexthop replace id 268435457 via 10.0.0.3 dev br-blue onlink",
+				"ip nexthop replace id 268435459 group 268435456/268435457",
+				"ip route replace 192.168.2.0/24 vrf blue nhid 268435459",
+			},
+		},
+	}
+	for _, name := range []string{"best path", "weighted", "unweighted"} {
+		tt := tests[name]
+		entries := kernelEntries(paths, nil, l3Vnis, tt.ecmp)
+		keys := make([]string, 0, len(entries))
+		for entry := range entries {
+			keys = append(keys, strings.Join(entry.addCmd(), " "))
+		}
+		sort.Strings(keys)
+		if !reflect.DeepEqual(keys, tt.expected) {
+			t.Errorf("%s: expected %q, received %q", name, tt.expected, keys)
+		}
+	}
+
+	route := kernelEntry{kind: entryRoute, dev: "blue", prefix: netip.MustParsePrefix("192.168.2.0/24"), id: 3}
+	group := kernelEntry{kind: entryNexthopGroup, dev: "blue", id: 3, group: "1/2"}
+	nexthop := kernelEntry{kind: entryNexthop, dev: "blue", bridge: "br-blue", vtep: "10.0.0.2", id: 1}
+	neigh := kernelEntry{kind: entryNeighbor, dev: "br-blue", vtep: "10.0.0.2"}
+	fdb := kernelEntry{kind: entryFdb, dev: "vxlan-blue", vtep: "10.0.0.2"}
+	entries := []kernelEntry{route, fdb, group, neigh, nexthop}
+	sortByStage(entries, true)
+	if expected := []kernelEntry{route, group, nexthop, fdb, neigh}; !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected the deletions in the order %+v, received %+v", expected, entries)
+	}
+}
+
+func Test_PrefixTree(t *testing.T) {
+	tree := &prefixTree[int]{}
+	prefixes := []string{"192.168.2.0/24", "10.0.0.0/8", "192.168.3.0/24", "192.168.0.0/16", "2001:db8::/32", "10.1.0.0/16", "192.168.2.128/25"}
+	for i, p := range prefixes {
+		*tree.insert(netip.MustParsePrefix(p)) = i
+	}
+	*tree.insert(netip.MustParsePrefix("10.1.2.3/16")) += 10
+	walked := []string{}
+	tree.walk(func(p netip.Prefix, v *int) { walked = append(walked, fmt.Sprintf("%s=%d", p, *v)) })
+	expected := []string{"10.0.0.0/8=1", "10.1.0.0/16=15", "192.168.0.0/16=3", "192.168.2.0/24=0", "192.168.2.128/25=6", "192.168.3.0/24=2", "2001:db8::/32=4"}
+	if !reflect.DeepEqual(walked, expected) {
+		t.Errorf("expected %q, received %q", expected, walked)
+	}
+	if v := tree.get(netip.MustParsePrefix("192.168.3.0/24")); v == nil || *v != 2 {
+		t.Errorf("expected to find 192.168.3.0/24, received %v", v)
+	}
+	for _, p := range []string{"192.168.0.0/22", "192.168.2.0/23", "10.0.0.0/16", "2001:db8::/48"} {
+		if v := tree.get(netip.MustParsePrefix(p)); v != nil {
+			t.Errorf("expected %s to be missing, received %d", p, *v)
+		}
+	}
+
+	a, b := symbols.intern(strings.Repeat("10.0.0.2", 1)), symbols.intern(string([]byte("10.0.0.2")))
+	if unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Error("expected the vtep addresses to share their copy")
+	}
+}
+
+func Test_NexthopGroupReuse(t *testing.T) {
+	nexthopPool = newNexthopIDs()
+	installed.entries = make(map[kernelEntry]bool)
+	installed.stats = routing.NexthopGroupStats{}
+	executed := []string{}
+	orig := execBatch
+	t.Cleanup(func() { execBatch = orig })
+	execBatch = func(tool string, lines []string) (string, error) {
+		for _, line := range lines {
+			executed = append(executed, tool+" "+line)
+		}
+		return "", nil
+	}
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	l3Vnis := map[uint32]string{2000: "//network.opiproject.org/vrfs/blue"}
+	received := func(prefixes ...string) []ribPath {
+		paths := []ribPath{}
+		for _, prefix := range prefixes {
+			for _, vtep := range []string{"10.0.0.2", "10.0.0.3"} {
+				paths = append(paths, ribPath{RouteType: routeTypePrefix, Prefix: prefix, Vni: 2000, Nexthop: vtep,
+					RouterMac: "aa:bb:cc:00:00:0a", Best: true, Neighbor: vtep})
+			}
+		}
+		return paths
+	}
+	ecmp := config.EcmpConfig{MaxPaths: 2}
+
+	installEntries(kernelEntries(received("192.168.2.0/24", "192.168.3.0/24"), nil, l3Vnis, ecmp))
+	installEntries(kernelEntries(received("192.168.2.0/24", "192.168.3.0/24", "192.168.4.0/24"), nil, l3Vnis, ecmp))
+	groups, stats, _ := Backend{}.NexthopGroups(context.Background())
+	if expected := (routing.NexthopGroupStats{Groups: 1, Routes: 3, Created: 3, Reused: 1}); stats != expected {
+		t.Errorf("expected %+v, received %+v", expected, stats)
+	}
+	if len(groups) != 3 || groups[2].ID != firstNexthopID+2 || groups[2].Routes != 3 || len(groups[2].Members) != 2 {
+		t.Errorf("expected the three prefixes to share a group of two vteps, received %+v", groups)
+	}
+
+	executed = executed[:0]
+	installEntries(kernelEntries(nil, nil, l3Vnis, ecmp))
+	expected := []string{
+		"ip route del 192.168.2.0/24 vrf blue",
+		"ip route del 192.168.3.0/24 vrf blue",
+		"ip route del 192.168.4.0/24 vrf blue",
+		"ip nexthop del id 268435458",
+	}
+	if !reflect.DeepEqual(executed[:4], expected) {
+		t.Errorf("expected the routes to be deleted before their group, received %q", executed)
+	}
+	if _, stats, _ = (Backend{}).NexthopGroups(context.Background()); stats.Deleted != 3 || stats.Routes != 0 {
+		t.Errorf("expected the nexthop objects to be deleted, received %+v", stats)
+	}
+	if len(nexthopPool.keys) != 0 {
+		t.Errorf("expected the nexthop ids to be released, received %v", nexthopPool.keys)
+	}
+}
+
+func Test_RunBatch(t *testing.T) {
+	orig := execBatch
+	t.Cleanup(func() { execBatch = orig })
+	runs := []string{}
+	execBatch = func(tool string, lines []string) (string, error) {
+		runs = append(runs, fmt.Sprintf("%s %d", tool, len(lines)))
+		if tool == "bridge" {
+			return "", errors.New("executable file not found")
+		}
+		if strings.HasPrefix(lines[len(lines)-1], "neigh") {
+			return "RTNETLINK answers: Invalid argument\nCommand failed -:2\n", errors.New("exit status 1")
+		}
+		return "", nil
+	}
+	keys := []string{}
+	cmds := map[string][]string{}
+	for i := 0; i < maxBatchLines+1; i++ {
+		key := fmt.Sprintf("route %d", i)
+		keys = append(keys, key)
+		cmds[key] = []string{"ip", "route", "replace", fmt.Sprintf("10.%d.%d.0/24", i/256, i%256), "vrf", "blue", "nhid", "1"}
+	}
+	keys = append(keys, "neigh a", "neigh b", "fdb")
+	cmds["neigh a"] = []string{"ip", "neigh", "replace", "10.0.0.2"}
+	cmds["neigh b"] = []string{"ip", "neigh", "replace", "10.0.0.3"}
+	cmds["fdb"] = []string{"bridge", "fdb", "replace", "aa:bb:cc:00:00:0a"}
+
+	failed := runBatch(keys, func(key string) []string { return cmds[key] })
+	if expected := []string{"ip 1000", "ip 3", "bridge 1"}; !reflect.DeepEqual(runs, expected) {
+		t.Errorf("expected the runs %q, received %q", expected, runs)
+	}
+	if len(failed) != 2 || failed["neigh a"] == nil || failed["fdb"] == nil {
+		t.Errorf("expected the second command of the last ip run and the bridge command to fail, received %v", failed)
+	}
+}
+
+// benchRib returns the type-5 paths of the prefixes received from the vteps
+func benchRib(prefixes int, vteps int) []ribPath {
+	paths := make([]ribPath, 0, prefixes*vteps)
+	for i := 0; i < prefixes; i++ {
+		for v := 0; v < vteps; v++ {
+			vtep := fmt.Sprintf("10.1.%d.%d", v/256, v%256)
+			paths = append(paths, ribPath{RouteType: routeTypePrefix, Prefix: fmt.Sprintf("%d.%d.%d.0/24", 16+i/65536, i/256%256, i%256), Vni: 2000,
+				Nexthop: vtep, RouterMac: "aa:bb:cc:00:00:0a", Bandwidth: float64(v + 1), Best: true, Neighbor: vtep})
+		}
+	}
+	return paths
+}
+
+func BenchmarkKernelEntries(b *testing.B) {
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		b.Fatal(err)
+	}
+	paths := benchRib(10000, 4)
+	l3Vnis := map[uint32]string{2000: "//network.opiproject.org/vrfs/blue"}
+	ecmp := config.EcmpConfig{MaxPaths: 4, Weighted: true}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		kernelEntries(paths, nil, l3Vnis, ecmp)
+	}
+}
+
+func BenchmarkInstallEntries(b *testing.B) {
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		b.Fatal(err)
+	}
+	orig := execBatch
+	b.Cleanup(func() { execBatch = orig })
+	execBatch = func(string, []string) (string, error) { return "", nil }
+	l3Vnis := map[uint32]string{2000: "//network.opiproject.org/vrfs/blue"}
+	ecmp := config.EcmpConfig{MaxPaths: 4}
+	flapped := kernelEntries(benchRib(10000, 4), nil, l3Vnis, ecmp)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// a peer flap withdraws all the routes and advertises them again
+		installEntries(map[kernelEntry]bool{})
+		installEntries(flapped)
+	}
+}
+
+func Test_ParsePeers(t *testing.T) {
+	now := time.Unix(1700000100, 0)
+	peers, err := parsePeers([]byte(`[
+  {"conf":{"neighbor_address":"10.0.0.2","peer_asn":65001},"state":{"session_state":6},
+   "timers":{"state":{"uptime":{"seconds":1700000000}}},
+   "graceful_restart":{"state":{"enabled":true,"restart_time":120,"peer_restart_time":90}},
+   "afi_safis":[{"config":{"family":{"afi":25,"safi":70}},"state":{"received":3,"advertised":4}}]},
+  {"conf":{"neighbor_address":"10.0.0.3","peer_asn":65002},"state":{"session_state":3},
+   "afi_safis":[{"config":{"family":{"afi":25,"safi":70}}}]}
+]`), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []routing.BgpPeer{
+		{Afi: "l2vpnEvpn", Address: "10.0.0.2", RemoteAs: "65001", State: "Established", Uptime: "1m40s", PfxRcd: 3, PfxSnt: 4,
+			GrLocalMode: "Restart", GrRestartTime: 120, GrPeerRestartTime: 90},
+		{Afi: "l2vpnEvpn", Address: "10.0.0.3", RemoteAs: "65002", State: "Active", Uptime: "never", GrLocalMode: "Disable"},
+	}
+	if !reflect.DeepEqual(peers, expected) {
+		t.Errorf("expected %+v, received %+v", expected, peers)
+	}
+}
+
+func Test_DrainedPaths(t *testing.T) {
+	localas = 65000
+	args := []string{"multicast", "10.0.0.1", "etag", "0", "rd", "65000:1000"}
+	paths := map[string][]string{strings.Join(args, " "): args}
+	tests := map[routing.DrainMode][]string{
+		"":                    {"multicast 10.0.0.1 etag 0 rd 65000:1000"},
+		routing.DrainWithdraw: {},
+		routing.DrainMed:      {"multicast 10.0.0.1 etag 0 rd 65000:1000 med 4294967295"},
+		routing.DrainPrepend:  {"multicast 10.0.0.1 etag 0 rd 65000:1000 aspath 65000 65000 65000"},
+	}
+	for mode, expected := range tests {
+		keys := []string{}
+		for key := range drainedPaths(paths, mode) {
+			keys = append(keys, key)
+		}
+		if !reflect.DeepEqual(keys, expected) {
+			t.Errorf("mode %q: expected %q, received %q", mode, expected, keys)
+		}
+	}
+	if len(args) != 6 {
+		t.Errorf("expected the paths to be left as they are, received %q", args)
+	}
+}
+
+func Test_ConfigureUnderlay(t *testing.T) {
+	orig := execCmd
+	t.Cleanup(func() { execCmd = orig })
+	localas, address = 65000, "127.0.0.1:50051"
+	runs := []string{}
+	execCmd = func(cmd []string) (string, error) {
+		runs = append(runs, strings.Join(cmd[5:], " "))
+		switch cmd[5] {
+		case "global":
+			if cmd[6] == "as" {
+				return "", errors.New("exit status 1: gobgp is already started")
+			}
+		case "neighbor":
+			if cmd[7] == "10.168.1.6" {
+				return "", errors.New("exit status 1: can't overwrite the existing peer: 10.168.1.6")
+			}
+		}
+		return "", nil
+	}
+	underlay := routing.Underlay{
+		RouterID: "10.0.0.2",
+		VtepIP:   netip.MustParsePrefix("10.0.0.2/32"),
+		Peers: []routing.UnderlayPeer{
+			{Address: "10.168.1.6", RemoteAs: "65001"},
+			{Interface: "eth2", RemoteAs: "internal"},
+		},
+	}
+	if err := (Backend{}).ConfigureUnderlay(context.Background(), underlay); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"global as 65000 router-id 10.0.0.2",
+		"neighbor add 10.168.1.6 as 65001 family ipv4-unicast,l2vpn-evpn",
+		"neighbor add interface eth2 as 65000 family ipv4-unicast,l2vpn-evpn",
+		"global rib add 10.0.0.2/32 -a ipv4",
+	}
+	if !reflect.DeepEqual(runs, expected) {
+		t.Errorf("expected the commands %q, received %q", expected, runs)
+	}
+	underlay.Peers = []routing.UnderlayPeer{{Interface: "eth2", RemoteAs: "external"}}
+	if err := (Backend{}).ConfigureUnderlay(context.Background(), underlay); err == nil {
+		t.Error("expected an external peer without AS number to be refused")
+	}
+}
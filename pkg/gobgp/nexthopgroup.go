@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package gobgp runs the EVPN control plane with an external gobgpd instead of FRR
+package gobgp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// The ids of the nexthop objects of the received routes, well above the ones allocated by zebra
+const (
+	firstNexthopID    = 1 << 28
+	maxNexthopObjects = 1 << 16
+)
+
+// nexthopPool allocates the ids of the kernel nexthop objects by key: a remote vtep of a vrf or a set of them
+var nexthopPool = newNexthopIDs()
+
+// entryIDs are the ids of kernel entries by key, an entry keeps its id while it is installed
+type entryIDs struct {
+	mu   sync.Mutex
+	pool utils.IDPool
+	keys map[string]bool
+	// keyOf returns the key of the id held by the entry, false for the entries of the other pools
+	keyOf func(kernelEntry) (string, bool)
+}
+
+// newEntryIDs returns the pool of the ids between first and last
+func newEntryIDs(name string, first, last uint32, keyOf func(kernelEntry) (string, bool)) *entryIDs {
+	pool, _ := utils.IDPoolInit(name, first, last)
+	return &entryIDs{pool: pool, keys: make(map[string]bool), keyOf: keyOf}
+}
+
+// newNexthopIDs returns the pool of the nexthop ids
+func newNexthopIDs() *entryIDs {
+	return newEntryIDs("nexthop", firstNexthopID, firstNexthopID+maxNexthopObjects-1, func(entry kernelEntry) (string, bool) {
+		switch entry.kind {
+		case entryNexthop:
+			return vtepNexthopKey(entry.dev, entry.vtep), true
+		case entryNexthopGroup:
+			return nexthopGroupKey(entry.dev, entry.group), true
+		}
+		return "", false
+	})
+}
+
+// id returns the id of the entry with the key, zero when the pool is exhausted
+func (n *entryIDs) id(key string) uint32 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	id := n.pool.GetID(key)
+	if id != 0 {
+		n.keys[key] = true
+	}
+	return id
+}
+
+// retain releases the ids which none of the entries holds
+func (n *entryIDs) retain(entries map[kernelEntry]bool) {
+	held := map[string]bool{}
+	for entry := range entries {
+		if key, ok := n.keyOf(entry); ok {
+			held[key] = true
+		}
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for key := range n.keys {
+		if !held[key] {
+			n.pool.ReleaseID(key)
+			delete(n.keys, key)
+		}
+	}
+}
+
+// vtepNexthopKey identifies the nexthop object of a remote vtep of the vrf
+func vtepNexthopKey(vrfDev string, vtep string) string {
+	return fmt.Sprintf("%s via %s", vrfDev, vtep)
+}
+
+// vtepNexthop returns the nexthop object reaching the remote vtep through the bridge of the vrf
+func vtepNexthop(vrfDev string, brDev string, vtep string) (kernelEntry, bool) {
+	key := vtepNexthopKey(vrfDev, vtep)
+	id := nexthopPool.id(key)
+	if id == 0 {
+		log.Printf("GoBGP: No nexthop id left for %s\n", key)
+		return kernelEntry{}, false
+	}
+	return kernelEntry{kind: entryNexthop, dev: vrfDev, bridge: brDev, vtep: vtep, id: id}, true
+}
+
+// nexthopGroupKey identifies the nexthop group of the vrf over the members of the spec
+func nexthopGroupKey(vrfDev string, spec string) string {
+	return fmt.Sprintf("%s group %s", vrfDev, spec)
+}
+
+// nexthopGroup returns the nexthop group over the nexthop objects of the vteps of the vrf,
+// which is shared by all the prefixes going to the same vteps with the same weights
+func nexthopGroup(vrfDev string, members []routing.NexthopMember) (kernelEntry, bool) {
+	ids := []string{}
+	for _, m := range members {
+		id := fmt.Sprint(nexthopPool.id(vtepNexthopKey(vrfDev, m.Nexthop)))
+		if m.Weight != 0 {
+			id = fmt.Sprintf("%s,%d", id, m.Weight)
+		}
+		ids = append(ids, id)
+	}
+	spec := strings.Join(ids, "/")
+	key := nexthopGroupKey(vrfDev, spec)
+	id := nexthopPool.id(key)
+	if id == 0 {
+		log.Printf("GoBGP: No nexthop id left for %s\n", key)
+		return kernelEntry{}, false
+	}
+	return kernelEntry{kind: entryNexthopGroup, dev: vrfDev, id: id, group: spec}, true
+}
+
+// groupMembers returns the vteps of the nexthop group with their weights, vteps gives the vtep
+// of the nexthop objects by id
+func groupMembers(spec string, vteps map[uint32]string) []routing.NexthopMember {
+	members := []routing.NexthopMember{}
+	for _, member := range strings.Split(spec, "/") {
+		id, weight, _ := strings.Cut(member, ",")
+		m := routing.NexthopMember{}
+		if n, err := strconv.ParseUint(id, 10, 32); err == nil {
+			m.Nexthop = vteps[uint32(n)]
+		}
+		if n, err := strconv.ParseUint(weight, 10, 32); err == nil {
+			m.Weight = uint32(n)
+		}
+		members = append(members, m)
+	}
+	return members
+}
+
+// installedNexthopGroups returns the installed nexthop objects with the number of routes going through
+// them, in the order of their id, and counts the ones used by the routes
+func installedNexthopGroups() ([]routing.NexthopGroup, routing.NexthopGroupStats) {
+	installed.Lock()
+	defer installed.Unlock()
+	stats := installed.stats
+	routes := map[uint32]int{}
+	vteps := map[uint32]string{}
+	for entry := range installed.entries {
+		switch entry.kind {
+		case entryRoute:
+			routes[entry.id]++
+			stats.Routes++
+		case entryNexthop:
+			vteps[entry.id] = entry.vtep
+		}
+	}
+	groups := []routing.NexthopGroup{}
+	for entry := range installed.entries {
+		if !entry.isNexthop() {
+			continue
+		}
+		group := routing.NexthopGroup{ID: entry.id, Vrf: entry.dev, Routes: routes[entry.id]}
+		if entry.kind == entryNexthop {
+			group.Members = []routing.NexthopMember{{Nexthop: entry.vtep}}
+		} else {
+			group.Members = groupMembers(entry.group, vteps)
+		}
+		if group.Routes != 0 {
+			stats.Groups++
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	return groups, stats
+}
+
+// NexthopGroups returns the nexthop objects programmed for the received routes: one per remote vtep of a vrf
+// and one per set of vteps which a prefix is spread over, and how often the routes found them installed already
+func (Backend) NexthopGroups(_ context.Context) ([]routing.NexthopGroup, routing.NexthopGroupStats, error) {
+	groups, stats := installedNexthopGroups()
+	return groups, stats, nil
+}
@@ -0,0 +1,291 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package gobgp runs the EVPN control plane with an external gobgpd instead of FRR
+package gobgp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// originated holds the paths announced to gobgpd, keyed by their arguments, and the drain mode of the node
+var originated = struct {
+	sync.Mutex
+	paths map[string][]string
+	// drain is empty when the node is not drained
+	drain routing.DrainMode
+}{paths: make(map[string][]string)}
+
+// drainPrependCount is the number of times the local AS is prepended while the node is drained
+const drainPrependCount = 3
+
+// routeDistinguisher returns the type 0 route distinguisher of the VNI
+func routeDistinguisher(vni uint32) string {
+	return fmt.Sprintf("%d:%d", localas, vni)
+}
+
+// routeTarget returns the route target of the VNI, imported and exported
+func routeTarget(vni uint32) string {
+	return fmt.Sprintf("%d:%d", localas, vni)
+}
+
+// evpnPath returns the arguments of a path of the evpn address family carrying the VNI over the encapsulation
+func evpnPath(vni uint32, encap string, nexthop net.IP, args ...string) []string {
+	args = append(args, "rd", routeDistinguisher(vni), "rt", routeTarget(vni), "encap", encap)
+	if nexthop != nil {
+		args = append(args, "nexthop", nexthop.String())
+	}
+	return args
+}
+
+// localState is the part of the infradb which is announced
+type localState struct {
+	vrfs []*infradb.Vrf
+	lbs  []*infradb.LogicalBridge
+	bps  []*infradb.BridgePort
+	svis []*infradb.Svi
+	hrs  []*infradb.HostRoute
+}
+
+// readLocalState reads the objects which are not being deleted
+func readLocalState() (*localState, error) {
+	state := &localState{}
+	vrfs, err := infradb.GetAllVrfs()
+	if err != nil {
+		return nil, err
+	}
+	for _, vrf := range vrfs {
+		if vrf.Status.VrfOperStatus != infradb.VrfOperStatusToBeDeleted {
+			state.vrfs = append(state.vrfs, vrf)
+		}
+	}
+	lbs, err := infradb.GetAllLBs()
+	if err != nil {
+		return nil, err
+	}
+	for _, lb := range lbs {
+		if lb.Status.LBOperStatus != infradb.LogicalBridgeOperStatusToBeDeleted {
+			state.lbs = append(state.lbs, lb)
+		}
+	}
+	bps, err := infradb.GetAllBPs()
+	if err != nil {
+		return nil, err
+	}
+	for _, bp := range bps {
+		if bp.Status.BPOperStatus != infradb.BridgePortOperStatusToBeDeleted {
+			state.bps = append(state.bps, bp)
+		}
+	}
+	svis, err := infradb.GetAllSvis()
+	if err != nil {
+		return nil, err
+	}
+	for _, svi := range svis {
+		if svi.Status.SviOperStatus != infradb.SviOperStatusToBeDeleted {
+			state.svis = append(state.svis, svi)
+		}
+	}
+	hrs, err := infradb.GetAllHostRoutes()
+	if err != nil {
+		return nil, err
+	}
+	for _, hr := range hrs {
+		if hr.Status.OperStatus != infradb.OperStatusToBeDeleted {
+			state.hrs = append(state.hrs, hr)
+		}
+	}
+	return state, nil
+}
+
+// lbEncap returns the encapsulation signaled with the routes of the logical bridge
+func lbEncap(lb *infradb.LogicalBridge) string {
+	if lb.Spec.IsGeneve() {
+		return routing.EncapGeneve
+	}
+	return routing.EncapVxlan
+}
+
+// routerMac returns the mac address of the bridge of the vrf, which is the router mac of its type-5 routes
+func routerMac(vrf string) (string, error) {
+	link, err := nlink.LinkByName(ctx, infradb.LinkName(vrf, infradb.LinkRoleBridge))
+	if err != nil {
+		return "", err
+	}
+	return link.Attrs().HardwareAddr.String(), nil
+}
+
+// originatedPaths derives the paths which describe the local state:
+// a type-3 route per logical bridge with a VNI to join its flooding list,
+// a type-2 route per mac address of the bridge ports and svis of the logical bridge and
+// a type-5 route per subnet of the svis of a vrf with a VNI and
+// a type-5 route per host route of a vrf with a VNI, carrying the communities of the host route
+func (s *localState) originatedPaths(routerMac func(string) (string, error)) (map[string][]string, error) {
+	paths := make(map[string][]string)
+	add := func(args []string) {
+		paths[strings.Join(args, " ")] = args
+	}
+	lbs := make(map[string]*infradb.LogicalBridge)
+	for _, lb := range s.lbs {
+		if lb.Spec.Vni == nil || lb.Spec.VtepIP == nil {
+			continue
+		}
+		lbs[lb.Name] = lb
+		vtep := lb.Spec.VtepIP.IP.String()
+		vni := strconv.Itoa(int(*lb.Spec.Vni))
+		// Example: gobgp global rib -a evpn add multicast <vtep> etag 0 rd <rd> rt <rt> encap vxlan pmsi ingress-repl <vni> <vtep>
+		add(append(evpnPath(*lb.Spec.Vni, lbEncap(lb), nil, "multicast", vtep, "etag", "0"), "pmsi", "ingress-repl", vni, vtep))
+	}
+	for _, bp := range s.bps {
+		if bp.Spec.MacAddress == nil {
+			continue
+		}
+		for _, name := range bp.Spec.LogicalBridges {
+			lb, ok := lbs[name]
+			if !ok {
+				continue
+			}
+			// Example: gobgp global rib -a evpn add macadv <mac> 0.0.0.0 etag 0 label <vni> rd <rd> rt <rt> encap vxlan nexthop <vtep>
+			add(evpnPath(*lb.Spec.Vni, lbEncap(lb), lb.Spec.VtepIP.IP, "macadv", bp.Spec.MacAddress.String(), "0.0.0.0",
+				"etag", "0", "label", strconv.Itoa(int(*lb.Spec.Vni))))
+		}
+	}
+	vrfs := make(map[string]*infradb.Vrf)
+	for _, vrf := range s.vrfs {
+		if vrf.Spec.Vni != nil && vrf.Spec.VtepIP != nil && path.Base(vrf.Name) != "GRD" {
+			vrfs[vrf.Name] = vrf
+		}
+	}
+	for _, svi := range s.svis {
+		if lb, ok := lbs[svi.Spec.LogicalBridge]; ok && svi.Spec.MacAddress != nil {
+			for _, gw := range svi.Spec.Addresses() {
+				add(append(evpnPath(*lb.Spec.Vni, lbEncap(lb), lb.Spec.VtepIP.IP, "macadv", svi.Spec.MacAddress.String(), gw.IP.String(),
+					"etag", "0", "label", strconv.Itoa(int(*lb.Spec.Vni))), "default-gateway"))
+			}
+		}
+		vrf, ok := vrfs[svi.Spec.Vrf]
+		if !ok || len(svi.Spec.Addresses()) == 0 {
+			continue
+		}
+		rmac, err := routerMac(vrf.Name)
+		if err != nil {
+			return nil, fmt.Errorf("no router mac for %s: %v", vrf.Name, err)
+		}
+		// the secondary addresses are advertised as their subnets, a virtual IP as a host route
+		for _, gw := range svi.Spec.Addresses() {
+			subnet := &net.IPNet{IP: gw.IP.Mask(gw.Mask), Mask: gw.Mask}
+			// Example: gobgp global rib -a evpn add prefix <subnet> gw 0.0.0.0 etag 0 label <l3vni> rd <rd> rt <rt> encap vxlan router-mac <rmac> nexthop <vtep>
+			args := []string{"prefix", subnet.String(), "gw", "0.0.0.0", "etag", "0", "label", strconv.Itoa(int(*vrf.Spec.Vni))}
+			args = evpnPath(*vrf.Spec.Vni, routing.EncapVxlan, vrf.Spec.VtepIP.IP, args...)
+			add(append(args, "router-mac", rmac))
+		}
+	}
+	for _, hr := range s.hrs {
+		vrf, ok := vrfs[hr.Spec.Vrf]
+		if !ok {
+			continue
+		}
+		rmac, err := routerMac(vrf.Name)
+		if err != nil {
+			return nil, fmt.Errorf("no router mac for %s: %v", vrf.Name, err)
+		}
+		for _, prefix := range hr.Spec.Prefixes {
+			// Example: gobgp global rib -a evpn add prefix <host> gw 0.0.0.0 etag 0 label <l3vni> rd <rd> rt <rt> encap vxlan router-mac <rmac> nexthop <vtep> community <communities>
+			args := []string{"prefix", prefix.String(), "gw", "0.0.0.0", "etag", "0", "label", strconv.Itoa(int(*vrf.Spec.Vni))}
+			args = append(evpnPath(*vrf.Spec.Vni, routing.EncapVxlan, vrf.Spec.VtepIP.IP, args...), "router-mac", rmac)
+			if len(hr.Spec.Communities) != 0 {
+				args = append(args, "community", strings.Join(hr.Spec.Communities, ","))
+			}
+			add(args)
+		}
+	}
+	return paths, nil
+}
+
+// drainedPaths returns the paths announced while the node is drained in the mode: none when the routes
+// are withdrawn, else the paths with the highest MED or with the local AS prepended
+func drainedPaths(paths map[string][]string, mode routing.DrainMode) map[string][]string {
+	var attrs []string
+	switch mode {
+	case "":
+		return paths
+	case routing.DrainMed:
+		attrs = []string{"med", "4294967295"}
+	case routing.DrainPrepend:
+		attrs = []string{"aspath", strings.TrimSpace(strings.Repeat(fmt.Sprintf("%d ", localas), drainPrependCount))}
+	}
+	drained := make(map[string][]string)
+	if attrs == nil {
+		return drained
+	}
+	for _, args := range paths {
+		args = append(append([]string{}, args...), attrs...)
+		drained[strings.Join(args, " ")] = args
+	}
+	return drained
+}
+
+// SetDrain drains the node, or undoes it, by announcing the paths again with the attributes of the mode
+func (Backend) SetDrain(_ context.Context, mode routing.DrainMode, drained bool) error {
+	originated.Lock()
+	originated.drain = ""
+	if drained {
+		originated.drain = mode
+	}
+	originated.Unlock()
+	if details, ok := reconcilePaths(); !ok {
+		return errors.New(strings.TrimSpace(details))
+	}
+	return nil
+}
+
+// reconcilePaths announces the paths of the local state which are missing and withdraws the stale ones
+func reconcilePaths() (string, bool) {
+	originated.Lock()
+	defer originated.Unlock()
+	state, err := readLocalState()
+	if err != nil {
+		return fmt.Sprintf("GoBGP: Failed to read the local state: %v\n", err), false
+	}
+	paths, err := state.originatedPaths(routerMac)
+	if err != nil {
+		log.Printf("GoBGP: %v\n", err)
+		return fmt.Sprintf("GoBGP: %v\n", err), false
+	}
+	paths = drainedPaths(paths, originated.drain)
+	for key, args := range originated.paths {
+		if _, ok := paths[key]; ok {
+			continue
+		}
+		if _, err := gobgpCmd(append([]string{"global", "rib", "-a", "evpn", "del"}, args...)...); err != nil {
+			log.Printf("GoBGP: Failed to withdraw %s: %v\n", key, err)
+			return fmt.Sprintf("GoBGP: Failed to withdraw %s: %v\n", key, err), false
+		}
+		delete(originated.paths, key)
+		log.Printf("GoBGP: Executed gobgp global rib -a evpn del %s\n", key)
+	}
+	for key, args := range paths {
+		if _, ok := originated.paths[key]; ok {
+			continue
+		}
+		if _, err := gobgpCmd(append([]string{"global", "rib", "-a", "evpn", "add"}, args...)...); err != nil {
+			log.Printf("GoBGP: Failed to announce %s: %v\n", key, err)
+			return fmt.Sprintf("GoBGP: Failed to announce %s: %v\n", key, err), false
+		}
+		originated.paths[key] = args
+		log.Printf("GoBGP: Executed gobgp global rib -a evpn add %s\n", key)
+	}
+	return "", true
+}
@@ -0,0 +1,713 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package gobgp runs the EVPN control plane with an external gobgpd instead of FRR
+package gobgp
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/netip"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// EVPN route types, RFC 7432 and RFC 9136
+const (
+	routeTypeMacIP     = 2
+	routeTypeMulticast = 3
+	routeTypePrefix    = 5
+)
+
+// BGP path attribute types carrying the nexthop, the extended communities and the PMSI tunnel
+const (
+	attrNexthop      = 3
+	attrExtCommunity = 16
+	attrMpReach      = 14
+	attrPmsiTunnel   = 22
+)
+
+// The link bandwidth extended community, draft-ietf-idr-link-bandwidth
+const (
+	extCommunityLinkBandwidth        = 0x40
+	extCommunityLinkBandwidthSubtype = 0x04
+)
+
+// ribPath is a path of the evpn rib of gobgpd
+type ribPath struct {
+	RouteType int
+	Rd        string
+	Mac       string
+	IP        string
+	Prefix    string
+	Vni       uint32
+	Nexthop   string
+	RouterMac string
+	// Bandwidth is the link bandwidth signaled with the path in bytes per second, zero when it has none
+	Bandwidth float64
+	Best      bool
+	// Neighbor is the peer which sent the path, empty for the originated paths
+	Neighbor string
+}
+
+// ribPathJSON is the json representation of a path in "gobgp -j global rib -a evpn"
+type ribPathJSON struct {
+	Nlri struct {
+		Type  int `json:"type"`
+		Value struct {
+			Rd     json.RawMessage `json:"rd"`
+			Mac    string          `json:"mac"`
+			IP     string          `json:"ip"`
+			Labels []uint32        `json:"labels"`
+			Prefix string          `json:"prefix"`
+			Label  uint32          `json:"label"`
+		} `json:"value"`
+	} `json:"nlri"`
+	Best       bool              `json:"best"`
+	Attrs      []json.RawMessage `json:"attrs"`
+	NeighborIP string            `json:"neighbor-ip"`
+}
+
+// attrJSON is the json representation of the path attributes used by the bridge
+type attrJSON struct {
+	Type    int             `json:"type"`
+	Nexthop string          `json:"nexthop"`
+	Label   uint32          `json:"label"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// rdString renders the route distinguisher, e.g. {"type":0,"admin":65000,"assigned":10} as 65000:10
+func rdString(raw json.RawMessage) string {
+	var rd struct {
+		Admin    json.RawMessage `json:"admin"`
+		Assigned json.RawMessage `json:"assigned"`
+	}
+	if json.Unmarshal(raw, &rd) != nil || rd.Admin == nil {
+		return strings.Trim(string(raw), `"`)
+	}
+	return strings.Trim(string(rd.Admin), `"`) + ":" + string(rd.Assigned)
+}
+
+// parseRib flattens the evpn rib in the order of the route distinguishers and prefixes
+func parseRib(data []byte) ([]ribPath, error) {
+	rib := map[string][]ribPathJSON{}
+	if err := json.Unmarshal(data, &rib); err != nil {
+		return nil, err
+	}
+	paths := []ribPath{}
+	for _, dest := range rib {
+		for i := range dest {
+			p := &dest[i]
+			rp := ribPath{
+				RouteType: p.Nlri.Type,
+				Rd:        symbols.intern(rdString(p.Nlri.Value.Rd)),
+				Mac:       p.Nlri.Value.Mac,
+				IP:        p.Nlri.Value.IP,
+				Prefix:    p.Nlri.Value.Prefix,
+				Best:      p.Best,
+			}
+			// gobgpd renders the missing addresses as <nil>
+			if rp.IP == "<nil>" {
+				rp.IP = ""
+			}
+			if p.NeighborIP != "" && p.NeighborIP != "<nil>" && !net.ParseIP(p.NeighborIP).IsUnspecified() {
+				rp.Neighbor = symbols.intern(p.NeighborIP)
+			}
+			switch rp.RouteType {
+			case routeTypeMacIP:
+				if len(p.Nlri.Value.Labels) != 0 {
+					rp.Vni = p.Nlri.Value.Labels[0]
+				}
+			case routeTypePrefix:
+				rp.Vni = p.Nlri.Value.Label
+			}
+			for _, raw := range p.Attrs {
+				var attr attrJSON
+				if json.Unmarshal(raw, &attr) != nil {
+					continue
+				}
+				switch attr.Type {
+				case attrNexthop, attrMpReach:
+					rp.Nexthop = symbols.intern(attr.Nexthop)
+				case attrPmsiTunnel:
+					rp.Vni = attr.Label
+				case attrExtCommunity:
+					var comms []struct {
+						Type      int     `json:"type"`
+						Subtype   int     `json:"subtype"`
+						Mac       string  `json:"mac"`
+						Bandwidth float64 `json:"bandwidth"`
+					}
+					if json.Unmarshal(attr.Value, &comms) != nil {
+						continue
+					}
+					for _, c := range comms {
+						if c.Mac != "" {
+							rp.RouterMac = symbols.intern(c.Mac)
+						}
+						if c.Type == extCommunityLinkBandwidth && c.Subtype == extCommunityLinkBandwidthSubtype {
+							rp.Bandwidth = c.Bandwidth
+						}
+					}
+				}
+			}
+			paths = append(paths, rp)
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		if paths[i].Rd != paths[j].Rd {
+			return paths[i].Rd < paths[j].Rd
+		}
+		return paths[i].key() < paths[j].key()
+	})
+	return paths, nil
+}
+
+// key identifies the route of the path within its route distinguisher
+func (p *ribPath) key() string {
+	switch p.RouteType {
+	case routeTypeMacIP:
+		return fmt.Sprintf("[2]:[%s]:[%s]", p.Mac, p.IP)
+	case routeTypeMulticast:
+		return fmt.Sprintf("[3]:[%s]", p.IP)
+	case routeTypePrefix:
+		return fmt.Sprintf("[5]:[%s]", p.Prefix)
+	}
+	return fmt.Sprintf("[%d]", p.RouteType)
+}
+
+// readRib reads the evpn rib of gobgpd
+func readRib() ([]ribPath, error) {
+	out, err := gobgpCmd("-j", "global", "rib", "-a", "evpn")
+	if err != nil {
+		return nil, err
+	}
+	return parseRib([]byte(out))
+}
+
+// Stages of the kernel entries: the entries of a stage refer to the ones of the previous stages,
+// so they are added in the order of their stage and deleted in the reverse order
+const (
+	stageNeighbor = iota
+	stageNexthop
+	stageNexthopGroup
+	stageRoute
+)
+
+// entryKind is the kind of a kernel entry
+type entryKind uint8
+
+const (
+	// entryFdb forwards a mac address of a logical bridge, or the router mac of a vrf, to a remote vtep
+	entryFdb entryKind = iota
+	// entryFlood floods the broadcast and unknown traffic of a logical bridge to a remote vtep
+	entryFlood
+	// entryNeighbor resolves a remote vtep to its router mac on the bridge of a vrf
+	entryNeighbor
+	// entryNexthop is the nexthop object of a remote vtep of a vrf
+	entryNexthop
+	// entryNexthopGroup is the nexthop object spreading the traffic over several remote vteps of a vrf
+	entryNexthopGroup
+	// entryRoute is a prefix of a vrf going through a nexthop object
+	entryRoute
+	// entryGeneveFdb sends a mac address of a logical bridge carried over geneve to a remote vtep
+	entryGeneveFdb
+	// entryGeneveFlood mirrors the broadcast and unknown traffic of a logical bridge carried over geneve to a remote vtep
+	entryGeneveFlood
+)
+
+// kernelEntry is a fdb entry, neighbor, nexthop object or route programmed for a received path. It holds
+// the fields telling it apart only, the addresses of the vteps and the names of the devices being shared
+// by all the entries, and renders its commands when they run.
+type kernelEntry struct {
+	kind entryKind
+	// dev is the device of the entry, the vrf device for a nexthop object or a route
+	dev string
+	// bridge is the device which a nexthop object reaches its vtep through
+	bridge string
+	vtep   string
+	mac    [6]byte
+	prefix netip.Prefix
+	// id is the id of the nexthop object, or of the one which the route goes through
+	id uint32
+	// group lists the ids of the members of a nexthop group with their weights, e.g. 1,10/2,20
+	group string
+	// vlan and tunnel are the vlan and the tunnel of a logical bridge carried over geneve
+	vlan   uint16
+	tunnel string
+}
+
+// stage returns the stage of the entry
+func (e kernelEntry) stage() int {
+	switch e.kind {
+	case entryNexthop:
+		return stageNexthop
+	case entryNexthopGroup:
+		return stageNexthopGroup
+	case entryRoute:
+		return stageRoute
+	}
+	return stageNeighbor
+}
+
+// isNexthop tells whether the entry is a nexthop object
+func (e kernelEntry) isNexthop() bool {
+	return e.kind == entryNexthop || e.kind == entryNexthopGroup
+}
+
+// addCmd renders the command programming the entry
+func (e kernelEntry) addCmd() []string {
+	mac := net.HardwareAddr(e.mac[:]).String()
+	switch e.kind {
+	case entryFdb:
+		// Example: bridge fdb replace <mac> dev vxlan-<vlan> dst <vtep> self static
+		return []string{"bridge", "fdb", "replace", mac, "dev", e.dev, "dst", e.vtep, "self", "static"}
+	case entryFlood:
+		// Example: bridge fdb append 00:00:00:00:00:00 dev vxlan-<vlan> dst <vtep> self permanent
+		return []string{"bridge", "fdb", "append", mac, "dev", e.dev, "dst", e.vtep, "self", "permanent"}
+	case entryNeighbor:
+		// Example: ip neigh replace <vtep> lladdr <router mac> dev br-<vrf> nud noarp
+		return []string{"ip", "neigh", "replace", e.vtep, "lladdr", mac, "dev", e.dev, "nud", "noarp"}
+	case entryNexthop:
+		// Example: ip nexthop replace id <id> via <vtep> dev br-<vrf> onlink
+		return []string{"ip", "nexthop", "replace", "id", fmt.Sprint(e.id), "via", e.vtep, "dev", e.bridge, "onlink"}
+	case entryNexthopGroup:
+		// Example: ip nexthop replace id <id> group <id>,<weight>/<id>,<weight>
+		return []string{"ip", "nexthop", "replace", "id", fmt.Sprint(e.id), "group", e.group}
+	case entryGeneveFdb, entryGeneveFlood:
+		return e.geneveAddCmd()
+	}
+	// Example: ip route replace <prefix> vrf <vrf> nhid <id>
+	return []string{"ip", "route", "replace", e.prefix.String(), "vrf", e.dev, "nhid", fmt.Sprint(e.id)}
+}
+
+// delCmd renders the command deleting the entry
+func (e kernelEntry) delCmd() []string {
+	switch e.kind {
+	case entryFdb, entryFlood:
+		return []string{"bridge", "fdb", "del", net.HardwareAddr(e.mac[:]).String(), "dev", e.dev, "dst", e.vtep, "self"}
+	case entryNeighbor:
+		return []string{"ip", "neigh", "del", e.vtep, "dev", e.dev}
+	case entryNexthop, entryNexthopGroup:
+		return []string{"ip", "nexthop", "del", "id", fmt.Sprint(e.id)}
+	case entryGeneveFdb, entryGeneveFlood:
+		return e.geneveDelCmd()
+	}
+	return []string{"ip", "route", "del", e.prefix.String(), "vrf", e.dev}
+}
+
+// compare orders the entries by stage, then by their fields
+func (e kernelEntry) compare(o kernelEntry) int {
+	if c := cmp.Compare(e.stage(), o.stage()); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(e.kind, o.kind); c != 0 {
+		return c
+	}
+	if c := strings.Compare(e.dev, o.dev); c != 0 {
+		return c
+	}
+	if c := e.prefix.Addr().Compare(o.prefix.Addr()); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(e.prefix.Bits(), o.prefix.Bits()); c != 0 {
+		return c
+	}
+	if c := strings.Compare(e.vtep, o.vtep); c != 0 {
+		return c
+	}
+	if c := bytes.Compare(e.mac[:], o.mac[:]); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(e.id, o.id); c != 0 {
+		return c
+	}
+	if c := strings.Compare(e.group, o.group); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(e.vlan, o.vlan); c != 0 {
+		return c
+	}
+	return strings.Compare(e.tunnel, o.tunnel)
+}
+
+// parseMac returns the mac address in the form of the kernel entries
+func parseMac(s string) ([6]byte, bool) {
+	mac, err := net.ParseMAC(s)
+	if err != nil || len(mac) != 6 {
+		return [6]byte{}, false
+	}
+	return [6]byte(mac), true
+}
+
+// installed holds the kernel entries programmed for the received paths and counts the changes
+// of the nexthop objects
+var installed = struct {
+	sync.Mutex
+	entries map[kernelEntry]bool
+	stats   routing.NexthopGroupStats
+}{entries: make(map[kernelEntry]bool)}
+
+// maxNexthopWeight is the highest weight of a member of a kernel nexthop group
+const maxNexthopWeight = 255
+
+// ecmpMember is a remote vtep which the traffic to a prefix is spread over
+type ecmpMember struct {
+	nexthop   string
+	routerMac string
+	// bandwidth is the link bandwidth signaled by the vtep
+	bandwidth float64
+	// weight is the share of the traffic of the vtep, zero when the members are not weighted
+	weight uint32
+}
+
+// ecmpSets groups the vteps of the best received type-5 paths by VNI and prefix, in the order of their address
+// and up to the max paths of the config, zero keeping the best path only. The members are weighted by the link
+// bandwidth of their paths when the config asks for it and all of them signal one.
+func ecmpSets(paths []ribPath, cfg config.EcmpConfig) map[uint32]*prefixTree[[]ecmpMember] {
+	sets := make(map[uint32]*prefixTree[[]ecmpMember])
+	for i := range paths {
+		p := &paths[i]
+		if p.RouteType != routeTypePrefix || p.Neighbor == "" || !p.Best || p.Nexthop == "" || p.RouterMac == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(p.Prefix)
+		if err != nil {
+			continue
+		}
+		tree, ok := sets[p.Vni]
+		if !ok {
+			tree = &prefixTree[[]ecmpMember]{}
+			sets[p.Vni] = tree
+		}
+		set := tree.insert(prefix)
+		duplicate := false
+		for _, other := range *set {
+			duplicate = duplicate || other.nexthop == p.Nexthop
+		}
+		if !duplicate {
+			*set = append(*set, ecmpMember{nexthop: p.Nexthop, routerMac: p.RouterMac, bandwidth: p.Bandwidth})
+		}
+	}
+	maxPaths := cfg.MaxPaths
+	if maxPaths == 0 {
+		maxPaths = 1
+	}
+	for _, tree := range sets {
+		tree.walk(func(_ netip.Prefix, set *[]ecmpMember) {
+			members := *set
+			sort.Slice(members, func(i, j int) bool {
+				return bytes.Compare(net.ParseIP(members[i].nexthop).To16(), net.ParseIP(members[j].nexthop).To16()) < 0
+			})
+			if len(members) > maxPaths {
+				members = members[:maxPaths]
+			}
+			weighted := cfg.Weighted && len(members) > 1
+			maxBandwidth := 0.0
+			for _, m := range members {
+				weighted = weighted && m.bandwidth > 0
+				maxBandwidth = math.Max(maxBandwidth, m.bandwidth)
+			}
+			if weighted {
+				for i := range members {
+					members[i].weight = uint32(math.Max(1, math.Round(members[i].bandwidth*maxNexthopWeight/maxBandwidth)))
+				}
+			}
+			*set = members
+		})
+	}
+	return sets
+}
+
+// kernelEntries translates the best received paths to the kernel entries of the devices of their VNI,
+// l2Vnis gives the device of the logical bridges and l3Vnis the vrfs by VNI. A prefix received
+// from several vteps is routed through a nexthop group of the vteps.
+func kernelEntries(paths []ribPath, l2Vnis map[uint32]l2Device, l3Vnis map[uint32]string, ecmp config.EcmpConfig) map[kernelEntry]bool {
+	entries := make(map[kernelEntry]bool)
+	for i := range paths {
+		p := &paths[i]
+		if p.Neighbor == "" || !p.Best || p.Nexthop == "" {
+			continue
+		}
+		switch p.RouteType {
+		case routeTypeMacIP:
+			dev, ok := l2Vnis[p.Vni]
+			if !ok {
+				continue
+			}
+			mac, ok := parseMac(p.Mac)
+			if !ok {
+				continue
+			}
+			if dev.tunnel != "" {
+				if entry, ok := geneveEntry(dev, p.Nexthop, &mac); ok {
+					entries[entry] = true
+				}
+				continue
+			}
+			entries[kernelEntry{kind: entryFdb, dev: dev.dev, vtep: p.Nexthop, mac: mac}] = true
+		case routeTypeMulticast:
+			dev, ok := l2Vnis[p.Vni]
+			if !ok {
+				continue
+			}
+			if dev.tunnel != "" {
+				if entry, ok := geneveEntry(dev, p.Nexthop, nil); ok {
+					entries[entry] = true
+				}
+				continue
+			}
+			entries[kernelEntry{kind: entryFlood, dev: dev.dev, vtep: p.Nexthop}] = true
+		}
+	}
+	for vni, tree := range ecmpSets(paths, ecmp) {
+		vrf, ok := l3Vnis[vni]
+		if !ok {
+			continue
+		}
+		vrfDev := symbols.intern(infradb.LinkName(vrf, infradb.LinkRoleVrf))
+		brDev := symbols.intern(infradb.LinkName(vrf, infradb.LinkRoleBridge))
+		vxlanDev := symbols.intern(infradb.LinkName(vrf, infradb.LinkRoleVxlan))
+		tree.walk(func(prefix netip.Prefix, set *[]ecmpMember) {
+			members := *set
+			group := []routing.NexthopMember{}
+			var nh kernelEntry
+			for _, m := range members {
+				routerMac, ok := parseMac(m.routerMac)
+				if !ok {
+					return
+				}
+				// The remote vtep is reached through the bridge of the vrf with the router mac of the remote vrf
+				entries[kernelEntry{kind: entryNeighbor, dev: brDev, vtep: m.nexthop, mac: routerMac}] = true
+				entries[kernelEntry{kind: entryFdb, dev: vxlanDev, vtep: m.nexthop, mac: routerMac}] = true
+				if nh, ok = vtepNexthop(vrfDev, brDev, m.nexthop); !ok {
+					return
+				}
+				entries[nh] = true
+				group = append(group, routing.NexthopMember{Nexthop: m.nexthop, Weight: m.weight})
+			}
+			// A single vtep is used directly, the prefixes going to the same set of vteps share one group
+			if len(members) > 1 {
+				var ok bool
+				if nh, ok = nexthopGroup(vrfDev, group); !ok {
+					return
+				}
+				entries[nh] = true
+			}
+			entries[kernelEntry{kind: entryRoute, dev: vrfDev, prefix: prefix, id: nh.id}] = true
+		})
+	}
+	return entries
+}
+
+// vniDevices returns the devices of the logical bridges and the vrfs by VNI
+func vniDevices() (map[uint32]l2Device, map[uint32]string, error) {
+	l2Vnis := make(map[uint32]l2Device)
+	l3Vnis := make(map[uint32]string)
+	lbs, err := infradb.GetAllLBs()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, lb := range lbs {
+		if lb.Spec.Vni != nil {
+			l2Vnis[*lb.Spec.Vni] = lbDevice(lb)
+		}
+	}
+	vrfs, err := infradb.GetAllVrfs()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, vrf := range vrfs {
+		if vrf.Spec.Vni != nil && path.Base(vrf.Name) != "GRD" {
+			l3Vnis[*vrf.Spec.Vni] = vrf.Name
+		}
+	}
+	return l2Vnis, l3Vnis, nil
+}
+
+// programRib programs the kernel entries of the received paths which are missing and deletes
+// the ones of the withdrawn paths. The entries which fail are retried by the next run.
+func programRib() error {
+	paths, err := readRib()
+	if err != nil {
+		return err
+	}
+	l2Vnis, l3Vnis, err := vniDevices()
+	if err != nil {
+		return err
+	}
+	installEntries(kernelEntries(paths, l2Vnis, l3Vnis, ecmp))
+	return nil
+}
+
+// installEntries programs the entries which are not installed yet and deletes the installed ones which are gone,
+// the commands of a stage are sent in batches
+func installEntries(entries map[kernelEntry]bool) {
+	installed.Lock()
+	defer installed.Unlock()
+	stale := []kernelEntry{}
+	for entry := range installed.entries {
+		if !entries[entry] {
+			stale = append(stale, entry)
+		}
+	}
+	sortByStage(stale, true)
+	failed := runStages(stale, kernelEntry.delCmd)
+	for _, entry := range stale {
+		if err := failed[entry]; err != nil {
+			log.Printf("GoBGP: Failed to delete the entry of a withdrawn route: %v\n", err)
+		}
+		if entry.isNexthop() {
+			installed.stats.Deleted++
+		}
+		delete(installed.entries, entry)
+	}
+	existing := map[uint32]bool{}
+	for entry := range installed.entries {
+		if entry.isNexthop() {
+			existing[entry.id] = true
+		}
+	}
+	missing := []kernelEntry{}
+	for entry := range entries {
+		if !installed.entries[entry] {
+			missing = append(missing, entry)
+		}
+	}
+	sortByStage(missing, false)
+	failed = runStages(missing, kernelEntry.addCmd)
+	for _, entry := range missing {
+		if err := failed[entry]; err != nil {
+			log.Printf("GoBGP: Failed to program a received route: %v\n", err)
+			continue
+		}
+		installed.entries[entry] = true
+		log.Printf("GoBGP: Executed %s\n", strings.Join(entry.addCmd(), " "))
+		if entry.isNexthop() {
+			installed.stats.Created++
+		}
+		if entry.kind == entryRoute && existing[entry.id] {
+			installed.stats.Reused++
+		}
+	}
+	nexthopPool.retain(installed.entries)
+	geneveUnicastPool.retain(installed.entries)
+	geneveFloodPool.retain(installed.entries)
+}
+
+// sortByStage orders the entries by their stage, the reverse order deleting the entries
+func sortByStage(entries []kernelEntry, reverse bool) {
+	sort.Slice(entries, func(i, j int) bool {
+		if si, sj := entries[i].stage(), entries[j].stage(); si != sj {
+			return si < sj != reverse
+		}
+		return entries[i].compare(entries[j]) < 0
+	})
+}
+
+// watchRib programs the received routes on every update of the rib, once the burst of updates has settled
+// for the flush interval, and at least every poll interval, until stop is closed
+func watchRib(interval time.Duration, flush time.Duration, updates <-chan struct{}, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := programRib(); err != nil {
+			log.Printf("GoBGP: Failed to read the evpn rib: %v\n", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-updates:
+			select {
+			case <-stop:
+				return
+			case <-time.After(flush):
+			}
+		}
+	}
+}
+
+// monitorRetry is the delay before the monitor of the rib is restarted
+const monitorRetry = 5 * time.Second
+
+// monitorRib signals the updates of the evpn rib reported by gobgpd, the routes are still polled while
+// gobgpd does not answer
+func monitorRib(updates chan<- struct{}, stop chan struct{}) {
+	for {
+		if err := runMonitor(updates, stop); err != nil {
+			log.Printf("GoBGP: The monitor of the evpn rib ended: %v\n", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(monitorRetry):
+		}
+	}
+}
+
+// runMonitor runs "gobgp monitor global rib" until it fails or stop is closed, every line of its
+// output is an update of the rib. A pending signal already covers the new updates.
+func runMonitor(updates chan<- struct{}, stop chan struct{}) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("gobgp", "-u", host, "-p", port, "-j", "monitor", "global", "rib", "-a", "evpn") //nolint:gosec
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			_ = cmd.Process.Kill()
+		case <-done:
+		}
+	}()
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		select {
+		case updates <- struct{}{}:
+		default:
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+	return cmd.Wait()
+}
+
+// vniCounts counts the received mac addresses per VNI
+func vniCounts(paths []ribPath) map[uint32]int {
+	counts := make(map[uint32]int)
+	for i := range paths {
+		if paths[i].RouteType == routeTypeMacIP && paths[i].Neighbor != "" && paths[i].Best {
+			counts[paths[i].Vni]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,268 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package gobgp runs the EVPN control plane with an external gobgpd instead of FRR
+package gobgp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// sessionStates names the session states of gobgpd, which reports them as numbers
+var sessionStates = map[string]string{
+	"1": "Idle", "2": "Connect", "3": "Active", "4": "OpenSent", "5": "OpenConfirm", "6": "Established",
+}
+
+// afiNames and safiNames name the address families of gobgpd, which reports them as numbers
+var (
+	afiNames  = map[string]string{"1": "ipv4", "2": "ipv6", "25": "l2vpn"}
+	safiNames = map[string]string{"1": "Unicast", "70": "Evpn"}
+)
+
+// peerJSON is the json representation of a peer in "gobgp -j neighbor"
+type peerJSON struct {
+	Conf struct {
+		NeighborAddress string `json:"neighbor_address"`
+		PeerAsn         uint32 `json:"peer_asn"`
+	} `json:"conf"`
+	State struct {
+		SessionState json.RawMessage `json:"session_state"`
+	} `json:"state"`
+	Timers struct {
+		State struct {
+			Uptime struct {
+				Seconds int64 `json:"seconds"`
+			} `json:"uptime"`
+		} `json:"state"`
+	} `json:"timers"`
+	GracefulRestart struct {
+		State struct {
+			Enabled         bool `json:"enabled"`
+			HelperOnly      bool `json:"helper_only"`
+			RestartTime     int  `json:"restart_time"`
+			PeerRestartTime int  `json:"peer_restart_time"`
+		} `json:"state"`
+	} `json:"graceful_restart"`
+	AfiSafis []struct {
+		Config struct {
+			Family struct {
+				Afi  json.RawMessage `json:"afi"`
+				Safi json.RawMessage `json:"safi"`
+			} `json:"family"`
+		} `json:"config"`
+		State struct {
+			Received   int `json:"received"`
+			Advertised int `json:"advertised"`
+		} `json:"state"`
+	} `json:"afi_safis"`
+}
+
+// enumName names the enum of gobgpd, reported either as a number or as a string
+func enumName(raw json.RawMessage, names map[string]string) string {
+	value := strings.Trim(string(raw), `"`)
+	if name, ok := names[value]; ok {
+		return name
+	}
+	return value
+}
+
+// grMode names the graceful restart mode of the peer after the modes of FRR
+func (p *peerJSON) grMode() string {
+	switch gr := p.GracefulRestart.State; {
+	case !gr.Enabled:
+		return "Disable"
+	case gr.HelperOnly:
+		return "Helper"
+	default:
+		return "Restart"
+	}
+}
+
+// parsePeers flattens the address families of the peers in the order of their address family and address
+func parsePeers(data []byte, now time.Time) ([]routing.BgpPeer, error) {
+	var peers []peerJSON
+	if err := json.Unmarshal(data, &peers); err != nil {
+		return nil, err
+	}
+	out := []routing.BgpPeer{}
+	for i := range peers {
+		p := &peers[i]
+		uptime := "never"
+		if p.Timers.State.Uptime.Seconds != 0 {
+			uptime = now.Sub(time.Unix(p.Timers.State.Uptime.Seconds, 0)).Truncate(time.Second).String()
+		}
+		for _, as := range p.AfiSafis {
+			out = append(out, routing.BgpPeer{
+				Afi:               enumName(as.Config.Family.Afi, afiNames) + enumName(as.Config.Family.Safi, safiNames),
+				Address:           p.Conf.NeighborAddress,
+				RemoteAs:          fmt.Sprint(p.Conf.PeerAsn),
+				State:             enumName(p.State.SessionState, sessionStates),
+				Uptime:            uptime,
+				PfxRcd:            as.State.Received,
+				PfxSnt:            as.State.Advertised,
+				GrLocalMode:       p.grMode(),
+				GrRestartTime:     p.GracefulRestart.State.RestartTime,
+				GrPeerRestartTime: p.GracefulRestart.State.PeerRestartTime,
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Afi != out[j].Afi {
+			return out[i].Afi < out[j].Afi
+		}
+		return out[i].Address < out[j].Address
+	})
+	return out, nil
+}
+
+// EvpnVnis returns the VNIs of the logical bridges and vrfs, in the order of the VNIs
+func (Backend) EvpnVnis(context.Context) ([]routing.EvpnVni, error) {
+	paths, err := readRib()
+	if err != nil {
+		return nil, err
+	}
+	macs := vniCounts(paths)
+	vnis := []routing.EvpnVni{}
+	lbs, err := infradb.GetAllLBs()
+	if err != nil {
+		return nil, err
+	}
+	for _, lb := range lbs {
+		if lb.Spec.Vni == nil {
+			continue
+		}
+		vni := routing.EvpnVni{
+			Vni:     *lb.Spec.Vni,
+			Type:    "L2",
+			VxlanIf: lbDevice(lb).dev,
+			NumMacs: macs[*lb.Spec.Vni],
+		}
+		vnis = append(vnis, withBgpParams(vni))
+	}
+	vrfs, err := infradb.GetAllVrfs()
+	if err != nil {
+		return nil, err
+	}
+	for _, vrf := range vrfs {
+		if vrf.Spec.Vni == nil || path.Base(vrf.Name) == "GRD" {
+			continue
+		}
+		vni := routing.EvpnVni{
+			Vni:       *vrf.Spec.Vni,
+			Type:      "L3",
+			VxlanIf:   infradb.LinkName(vrf.Name, infradb.LinkRoleVxlan),
+			TenantVrf: infradb.LinkName(vrf.Name, infradb.LinkRoleVrf),
+		}
+		vnis = append(vnis, withBgpParams(vni))
+	}
+	sort.Slice(vnis, func(i, j int) bool { return vnis[i].Vni < vnis[j].Vni })
+	return vnis, nil
+}
+
+// withBgpParams completes the VNI with its route distinguisher and targets and tells whether its device exists
+func withBgpParams(vni routing.EvpnVni) routing.EvpnVni {
+	vni.Rd = routeDistinguisher(vni.Vni)
+	vni.ImportRts = []string{routeTarget(vni.Vni)}
+	vni.ExportRts = []string{routeTarget(vni.Vni)}
+	_, err := nlink.LinkByName(ctx, vni.VxlanIf)
+	vni.InKernel = err == nil
+	return vni
+}
+
+// EvpnRoutes returns the paths of the evpn rib of gobgpd, in the order of their rd and prefix
+func (Backend) EvpnRoutes(context.Context) ([]routing.EvpnRoute, error) {
+	paths, err := readRib()
+	if err != nil {
+		return nil, err
+	}
+	routes := []routing.EvpnRoute{}
+	for i := range paths {
+		p := &paths[i]
+		pathFrom := "local"
+		if p.Neighbor != "" {
+			pathFrom = p.Neighbor
+		}
+		route := routing.EvpnRoute{
+			Rd:        p.Rd,
+			Prefix:    p.key(),
+			RouteType: p.RouteType,
+			Mac:       p.Mac,
+			IP:        p.IP,
+			Nexthops:  []string{},
+			Best:      p.Best,
+			PathFrom:  pathFrom,
+		}
+		if p.Nexthop != "" {
+			route.Nexthops = append(route.Nexthops, p.Nexthop)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// BgpPeers returns the peers of gobgpd, which peers in the global instance only
+func (Backend) BgpPeers(_ context.Context, vrf string) ([]routing.BgpPeer, error) {
+	if path.Base(vrf) != "GRD" {
+		return []routing.BgpPeer{}, nil
+	}
+	out, err := gobgpCmd("-j", "neighbor")
+	if err != nil {
+		return nil, err
+	}
+	return parsePeers([]byte(out), time.Now())
+}
+
+// BgpRoutes returns the type-5 routes carrying the VNI of the vrf, in the order of their prefix
+func (Backend) BgpRoutes(_ context.Context, vrf string) ([]routing.BgpRoute, error) {
+	obj, err := infradb.GetVrf(vrf)
+	if err != nil {
+		return nil, err
+	}
+	routes := []routing.BgpRoute{}
+	if obj.Spec.Vni == nil {
+		return routes, nil
+	}
+	paths, err := readRib()
+	if err != nil {
+		return nil, err
+	}
+	sets := ecmpSets(paths, ecmp)
+	for i := range paths {
+		p := &paths[i]
+		if p.RouteType != routeTypePrefix || p.Vni != *obj.Spec.Vni {
+			continue
+		}
+		route := routing.BgpRoute{Prefix: p.Prefix, Nexthops: []string{}, Best: p.Best, PathFrom: "local"}
+		if p.Neighbor != "" {
+			route.PathFrom = p.Neighbor
+			prefix, err := netip.ParsePrefix(p.Prefix)
+			if tree := sets[p.Vni]; err == nil && tree != nil {
+				if members := tree.get(prefix); members != nil && len(*members) > 1 {
+					for _, m := range *members {
+						if m.nexthop == p.Nexthop {
+							route.Multipath = true
+							route.Weight = m.weight
+						}
+					}
+				}
+			}
+		}
+		if p.Nexthop != "" {
+			route.Nexthops = append(route.Nexthops, p.Nexthop)
+		}
+		routes = append(routes, route)
+	}
+	sort.SliceStable(routes, func(i, j int) bool { return routes[i].Prefix < routes[j].Prefix })
+	return routes, nil
+}
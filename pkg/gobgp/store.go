@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package gobgp runs the EVPN control plane with an external gobgpd instead of FRR
+package gobgp
+
+import (
+	"math/bits"
+	"net/netip"
+	"sync"
+)
+
+// maxSymbols bounds the symbol table, which starts over when it is full: the strings handed out stay valid
+const maxSymbols = 1 << 16
+
+// symbols interns the strings which many paths and kernel entries repeat: the addresses of the vteps,
+// their router macs, the route distinguishers and the names of the devices
+var symbols = newSymbolTable()
+
+// symbolTable maps a string to its single copy
+type symbolTable struct {
+	mu   sync.Mutex
+	strs map[string]string
+}
+
+// newSymbolTable returns an empty symbol table
+func newSymbolTable() *symbolTable {
+	return &symbolTable{strs: make(map[string]string)}
+}
+
+// intern returns the copy of the string shared by all its users
+func (t *symbolTable) intern(s string) string {
+	if s == "" {
+		return s
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if shared, ok := t.strs[s]; ok {
+		return shared
+	}
+	if len(t.strs) >= maxSymbols {
+		t.strs = make(map[string]string)
+	}
+	t.strs[s] = s
+	return s
+}
+
+// prefixNode is a node of the prefix tree, it holds a value when set, otherwise it only joins its children
+type prefixNode[V any] struct {
+	prefix netip.Prefix
+	set    bool
+	value  V
+	child  [2]*prefixNode[V]
+}
+
+// prefixTree is a path compressed radix tree of the prefixes of both address families, the more
+// specific prefixes are below the ones covering them
+type prefixTree[V any] struct {
+	roots [2]*prefixNode[V]
+}
+
+// addrBit returns the i-th bit of the address, counting from the most significant one
+func addrBit(addr netip.Addr, i int) int {
+	b := addr.AsSlice()
+	return int(b[i/8]>>(7-i%8)) & 1
+}
+
+// commonBits returns the length of the prefix shared by the two prefixes of the same family
+func commonBits(a netip.Prefix, b netip.Prefix) int {
+	x, y := a.Addr().AsSlice(), b.Addr().AsSlice()
+	n := 0
+	for i := range x {
+		if d := x[i] ^ y[i]; d != 0 {
+			n += bits.LeadingZeros8(d)
+			break
+		}
+		n += 8
+	}
+	return min(n, a.Bits(), b.Bits())
+}
+
+// family returns the root of the tree holding the prefix
+func family(p netip.Prefix) int {
+	if p.Addr().Is4() {
+		return 0
+	}
+	return 1
+}
+
+// insert returns the value of the prefix, which is added to the tree when missing
+func (t *prefixTree[V]) insert(p netip.Prefix) *V {
+	p = p.Masked()
+	link := &t.roots[family(p)]
+	for {
+		n := *link
+		if n == nil {
+			n = &prefixNode[V]{prefix: p, set: true}
+			*link = n
+			return &n.value
+		}
+		common := commonBits(n.prefix, p)
+		switch {
+		case common == n.prefix.Bits() && common == p.Bits():
+			n.set = true
+			return &n.value
+		case common == n.prefix.Bits():
+			link = &n.child[addrBit(p.Addr(), common)]
+		case common == p.Bits():
+			// the prefix covers the node, it takes its place
+			leaf := &prefixNode[V]{prefix: p, set: true}
+			leaf.child[addrBit(n.prefix.Addr(), common)] = n
+			*link = leaf
+			return &leaf.value
+		default:
+			// the prefix and the node part ways below their common bits
+			fork := &prefixNode[V]{prefix: netip.PrefixFrom(p.Addr(), common).Masked()}
+			leaf := &prefixNode[V]{prefix: p, set: true}
+			fork.child[addrBit(n.prefix.Addr(), common)] = n
+			fork.child[addrBit(p.Addr(), common)] = leaf
+			*link = fork
+			return &leaf.value
+		}
+	}
+}
+
+// get returns the value of the prefix, nil when the tree does not hold it
+func (t *prefixTree[V]) get(p netip.Prefix) *V {
+	p = p.Masked()
+	n := t.roots[family(p)]
+	for n != nil && n.prefix.Bits() <= p.Bits() && n.prefix.Contains(p.Addr()) {
+		if n.prefix.Bits() == p.Bits() {
+			if n.set {
+				return &n.value
+			}
+			return nil
+		}
+		n = n.child[addrBit(p.Addr(), n.prefix.Bits())]
+	}
+	return nil
+}
+
+// walk calls fn with the prefixes of the tree and their values, the IPv4 prefixes first, in the order
+// of their address and the less specific prefixes before the more specific ones
+func (t *prefixTree[V]) walk(fn func(netip.Prefix, *V)) {
+	var visit func(*prefixNode[V])
+	visit = func(n *prefixNode[V]) {
+		if n == nil {
+			return
+		}
+		if n.set {
+			fn(n.prefix, &n.value)
+		}
+		visit(n.child[0])
+		visit(n.child[1])
+	}
+	visit(t.roots[0])
+	visit(t.roots[1])
+}
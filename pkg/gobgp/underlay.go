@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package gobgp runs the EVPN control plane with an external gobgpd instead of FRR
+package gobgp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// build time check that struct implements interface
+var _ routing.UnderlayConfigurer = Backend{}
+
+// underlayFamilies are the address families of the sessions of the underlay
+const underlayFamilies = "ipv4-unicast,l2vpn-evpn"
+
+// underlayArgs returns the gobgp commands of the underlay: the global instance, the sessions to the fabric
+// and the advertisement of the vtep address
+func underlayArgs(underlay routing.Underlay) ([][]string, error) {
+	args := [][]string{}
+	if underlay.RouterID != "" {
+		args = append(args, []string{"global", "as", strconv.Itoa(localas), "router-id", underlay.RouterID})
+	}
+	for _, peer := range underlay.Peers {
+		remoteAs := peer.RemoteAs
+		switch remoteAs {
+		case "internal":
+			remoteAs = strconv.Itoa(localas)
+		case "external":
+			return nil, fmt.Errorf("gobgp needs the AS number of the external peer %s%s", peer.Address, peer.Interface)
+		}
+		neighbor := []string{peer.Address}
+		if peer.Interface != "" {
+			neighbor = []string{"interface", peer.Interface}
+		}
+		cmd := append([]string{"neighbor", "add"}, neighbor...)
+		args = append(args, append(cmd, "as", remoteAs, "family", underlayFamilies))
+	}
+	if underlay.VtepIP.IsValid() {
+		args = append(args, []string{"global", "rib", "add", underlay.VtepIP.Masked().String(), "-a", "ipv4"})
+	}
+	return args, nil
+}
+
+// ConfigureUnderlay brings up the bgp sessions of the underlay in gobgpd, the global instance and the
+// sessions which exist already are kept
+func (Backend) ConfigureUnderlay(_ context.Context, underlay routing.Underlay) error {
+	args, err := underlayArgs(underlay)
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if _, err := gobgpCmd(arg...); err != nil {
+			if strings.Contains(err.Error(), "already started") || strings.Contains(err.Error(), "existing peer") {
+				continue
+			}
+			log.Printf("GoBGP: Failed to configure the underlay: %v\n", err)
+			return err
+		}
+		log.Printf("GoBGP: Executed gobgp %s\n", strings.Join(arg, " "))
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package health implements the grpc.health.v1 service with the status of the bridge subsystems
+package health
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	pb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// DeepService is the service name which runs every probe, including the deep ones, on each Check
+const DeepService = "deep"
+
+// probeTimeout bounds the duration of a single probe
+var probeTimeout = 10 * time.Second
+
+// Probe checks a subsystem, it returns an error when the subsystem does not work
+type Probe func(ctx context.Context) error
+
+// Checker serves the health of the subsystems, the overall status ("") is SERVING only when all of them are
+type Checker struct {
+	*health.Server
+	interval time.Duration
+	// mu serializes the runs of the probes
+	mu     sync.Mutex
+	probes map[string]Probe
+	deep   map[string]Probe
+	// indicators report a state of the bridge which does not make it unhealthy
+	indicators map[string]Probe
+}
+
+// NewChecker creates a checker running the probes at the given interval
+func NewChecker(interval time.Duration) *Checker {
+	c := &Checker{
+		Server:     health.NewServer(),
+		interval:   interval,
+		probes:     map[string]Probe{},
+		deep:       map[string]Probe{},
+		indicators: map[string]Probe{},
+	}
+	c.SetServingStatus("", pb.HealthCheckResponse_NOT_SERVING)
+	c.SetServingStatus(DeepService, pb.HealthCheckResponse_NOT_SERVING)
+	return c
+}
+
+// AddProbe registers the periodic probe of a subsystem, which is also the health service name of the subsystem
+func (c *Checker) AddProbe(subsystem string, probe Probe) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.probes[subsystem] = probe
+	c.SetServingStatus(subsystem, pb.HealthCheckResponse_NOT_SERVING)
+}
+
+// AddDeepProbe registers a probe which only runs when the DeepService is checked, as it changes the dataplane or is expensive
+func (c *Checker) AddDeepProbe(name string, probe Probe) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deep[name] = probe
+}
+
+// AddIndicator registers a periodic probe served under its name which is left out of the overall status,
+// e.g. the writes refused while the bridge is read-only
+func (c *Checker) AddIndicator(name string, probe Probe) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.indicators[name] = probe
+	c.SetServingStatus(name, pb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Run runs the probes until the context is canceled
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		c.CheckAll(ctx)
+		select {
+		case <-ctx.Done():
+			c.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll runs the periodic probes and updates the status of the subsystems and of the indicators
+func (c *Checker) CheckAll(ctx context.Context) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	serving := c.runProbes(ctx, c.probes, true)
+	for name, probe := range c.indicators {
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		c.SetServingStatus(name, servingStatus(probe(probeCtx) == nil))
+		cancel()
+	}
+	return serving
+}
+
+// runProbes runs the probes in name order, the status of the subsystems is only updated for the periodic ones
+func (c *Checker) runProbes(ctx context.Context, probes map[string]Probe, update bool) bool {
+	names := make([]string, 0, len(probes))
+	for name := range probes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	serving := true
+	for _, name := range names {
+		status := pb.HealthCheckResponse_SERVING
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		if err := probes[name](probeCtx); err != nil {
+			log.Printf("health: %s probe failed: %v", name, err)
+			status = pb.HealthCheckResponse_NOT_SERVING
+			serving = false
+		}
+		cancel()
+		if update {
+			c.SetServingStatus(name, status)
+		}
+	}
+	if update {
+		c.SetServingStatus("", servingStatus(serving))
+	}
+	return serving
+}
+
+// Check returns the stored status of the service, checking the DeepService runs all the probes first
+func (c *Checker) Check(ctx context.Context, in *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
+	if in.GetService() == DeepService {
+		c.mu.Lock()
+		serving := c.runProbes(ctx, c.probes, true)
+		serving = c.runProbes(ctx, c.deep, false) && serving
+		c.mu.Unlock()
+		c.SetServingStatus(DeepService, servingStatus(serving))
+	}
+	return c.Server.Check(ctx, in)
+}
+
+// servingStatus converts the result of the probes
+func servingStatus(serving bool) pb.HealthCheckResponse_ServingStatus {
+	if serving {
+		return pb.HealthCheckResponse_SERVING
+	}
+	return pb.HealthCheckResponse_NOT_SERVING
+}
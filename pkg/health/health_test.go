@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package health implements the grpc.health.v1 service with the status of the bridge subsystems
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// probeResult returns a probe failing with err
+func probeResult(err error) Probe {
+	return func(context.Context) error { return err }
+}
+
+func Test_Check(t *testing.T) {
+	failure := errors.New("failure")
+	tests := map[string]struct {
+		store   error
+		frr     error
+		deep    error
+		writes  error
+		service string
+		status  pb.HealthCheckResponse_ServingStatus
+	}{
+		"all subsystems serving": {
+			service: "",
+			status:  pb.HealthCheckResponse_SERVING,
+		},
+		"failed subsystem": {
+			frr:     failure,
+			service: "frr",
+			status:  pb.HealthCheckResponse_NOT_SERVING,
+		},
+		"other subsystem serving": {
+			frr:     failure,
+			service: "store",
+			status:  pb.HealthCheckResponse_SERVING,
+		},
+		"overall status follows the subsystems": {
+			store:   failure,
+			service: "",
+			status:  pb.HealthCheckResponse_NOT_SERVING,
+		},
+		"deep check serving": {
+			service: DeepService,
+			status:  pb.HealthCheckResponse_SERVING,
+		},
+		"deep probe failed": {
+			deep:    failure,
+			service: DeepService,
+			status:  pb.HealthCheckResponse_NOT_SERVING,
+		},
+		"deep probe does not change the periodic status": {
+			deep:    failure,
+			service: "",
+			status:  pb.HealthCheckResponse_SERVING,
+		},
+		"failed indicator": {
+			writes:  failure,
+			service: "writes",
+			status:  pb.HealthCheckResponse_NOT_SERVING,
+		},
+		"indicator does not change the overall status": {
+			writes:  failure,
+			service: "",
+			status:  pb.HealthCheckResponse_SERVING,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			c := NewChecker(time.Minute)
+			c.AddProbe("store", probeResult(tt.store))
+			c.AddProbe("frr", probeResult(tt.frr))
+			c.AddDeepProbe("dataplane", probeResult(tt.deep))
+			c.AddIndicator("writes", probeResult(tt.writes))
+			c.CheckAll(context.Background())
+
+			resp, err := c.Check(context.Background(), &pb.HealthCheckRequest{Service: tt.service})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Status != tt.status {
+				t.Errorf("expected status %v, received %v", tt.status, resp.Status)
+			}
+		})
+	}
+}
+
+func Test_CheckBeforeProbes(t *testing.T) {
+	c := NewChecker(time.Minute)
+	c.AddProbe("store", probeResult(nil))
+	resp, err := c.Check(context.Background(), &pb.HealthCheckRequest{Service: "store"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != pb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected NOT_SERVING before the first probe, received %v", resp.Status)
+	}
+}
@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+var (
+	// ErrBondMemberInUse bond member is already part of another bond
+	ErrBondMemberInUse = errors.New("the bond member is already part of another bond")
+	// ErrBondInUse bond is still used by a bridge port
+	ErrBondInUse = errors.New("the bond is still used by a bridge port")
+)
+
+// bondModes are the supported kernel bonding modes
+var bondModes = map[string]bool{
+	"balance-rr":    true,
+	"active-backup": true,
+	"balance-xor":   true,
+	"broadcast":     true,
+	"802.3ad":       true,
+	"balance-tlb":   true,
+	"balance-alb":   true,
+}
+
+// BondSpec holds Bond Spec
+type BondSpec struct {
+	// Mode is the kernel bonding mode, e.g. 802.3ad or active-backup
+	Mode string
+	// LacpRate is either slow or fast and applies only to 802.3ad
+	LacpRate string
+	Members  []string
+	MinLinks int
+}
+
+// Bond holds Bond info. The linux device carries the resource id of the
+// bond so that a Bridge Port with the same resource id attaches the bond.
+type Bond struct {
+	Resource
+	Spec *BondSpec
+}
+
+// LinkName returns the linux device of the bond
+func (in *Bond) LinkName() string {
+	return path.Base(in.Name)
+}
+
+// bondKind describes the storage of the Bond objects
+var bondKind = registerKind(resourceKind{
+	eventType: "bond",
+	indexKey:  "bonds",
+	newObject: func() resourceObject { return &Bond{} },
+})
+
+// validate checks the Bond Spec
+func (in *BondSpec) validate() error {
+	if in.Mode == "" {
+		in.Mode = "802.3ad"
+	}
+	if !bondModes[in.Mode] {
+		return fmt.Errorf("Bond mode %s is not supported", in.Mode)
+	}
+	switch in.LacpRate {
+	case "":
+		in.LacpRate = "slow"
+	case "slow", "fast":
+	default:
+		return fmt.Errorf("Bond LACP rate %s is not supported", in.LacpRate)
+	}
+	if len(in.Members) == 0 {
+		return fmt.Errorf("Bond needs at least one member")
+	}
+	seen := map[string]bool{}
+	for _, member := range in.Members {
+		if member == "" || seen[member] {
+			return fmt.Errorf("Bond member %q is empty or duplicated", member)
+		}
+		seen[member] = true
+	}
+	if in.MinLinks < 0 || in.MinLinks > len(in.Members) {
+		return fmt.Errorf("Bond min links %d is out of range", in.MinLinks)
+	}
+	return nil
+}
+
+// NewBond creates new Bond object
+func NewBond(name string, spec *BondSpec) (*Bond, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewBond(): Bond spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("NewBond(): %v", err)
+	}
+
+	res, err := newResource(name, bondKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bond{Resource: res, Spec: spec}, nil
+}
+
+// getAllBonds returns all the bonds, the caller must hold the global lock
+func getAllBonds() ([]*Bond, error) {
+	bonds := []*Bond{}
+	names, err := bondKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		bond := &Bond{}
+		if err := bondKind.get(name, bond); err != nil {
+			log.Printf("getAllBonds(): Failed to get the Bond %s from store: %v", name, err)
+			return nil, err
+		}
+		bonds = append(bonds, bond)
+	}
+	return bonds, nil
+}
+
+// CreateBond creates an infradb bond object
+func CreateBond(bond *Bond) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	bonds, err := getAllBonds()
+	if err != nil {
+		return err
+	}
+	for _, existing := range bonds {
+		for _, member := range existing.Spec.Members {
+			for _, newMember := range bond.Spec.Members {
+				if member == newMember {
+					log.Printf("CreateBond(): Member %s is already part of %s\n", member, existing.Name)
+					return ErrBondMemberInUse
+				}
+			}
+		}
+	}
+
+	return bondKind.create(bond)
+}
+
+// DeleteBond deletes a bond infradb object
+func DeleteBond(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	bond := &Bond{}
+	if err := bondKind.get(name, bond); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, bond.ResourceVersion, opts); err != nil {
+		return err
+	}
+
+	bpsMap := make(map[string]bool)
+	if _, err := infradb.client.Get("bps", &bpsMap); err != nil {
+		log.Println(err)
+		return err
+	}
+	for bpName := range bpsMap {
+		if path.Base(bpName) == bond.LinkName() {
+			log.Printf("DeleteBond(): Bond %s is still used by Bridge Port %s\n", name, bpName)
+			return ErrBondInUse
+		}
+	}
+
+	return bondKind.delete(bond)
+}
+
+// GetBond returns an infradb bond object
+func GetBond(name string) (*Bond, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	bond := &Bond{}
+	err := bondKind.get(name, bond)
+	return bond, err
+}
+
+// GetAllBonds returns a list of bonds from the DB
+func GetAllBonds() ([]*Bond, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllBonds()
+}
+
+// UpdateBondStatus updates the status of bond object based on the component report
+func UpdateBondStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return bondKind.updateStatus(&Bond{}, name, resourceVersion, notificationID, component)
+}
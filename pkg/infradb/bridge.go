@@ -6,14 +6,11 @@
 package infradb
 
 import (
-	// "encoding/binary"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"log"
 	"net"
 
-	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
 	"github.com/opiproject/opi-evpn-bridge/pkg/config"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
@@ -45,6 +42,11 @@ type LogicalBridgeSpec struct {
 	VlanID uint32
 	Vni    *uint32
 	VtepIP *net.IPNet
+	// Encap is the encapsulation of the tunnel, VXLAN when it is nil
+	Encap *EncapSpec
+	// IsolatedPorts isolates the access ports of the Logical Bridge by default, it is set with
+	// SetLogicalBridgeIsolation
+	IsolatedPorts bool
 }
 
 // LogicalBridgeMetadata holds Logical Bridge Metadata
@@ -63,21 +65,15 @@ type LogicalBridge struct {
 }
 
 // build time check that struct implements interface
-var _ EvpnObject[*pb.LogicalBridge] = (*LogicalBridge)(nil)
+var _ EvpnObject = (*LogicalBridge)(nil)
 
-// NewLogicalBridge creates new Logical Bridge object from protobuf message
-func NewLogicalBridge(in *pb.LogicalBridge) (*LogicalBridge, error) {
-	var vip *net.IPNet
+// NewLogicalBridge creates new Logical Bridge object from its spec, the VTEP IP defaults to the one of the config
+func NewLogicalBridge(name string, spec *LogicalBridgeSpec) (*LogicalBridge, error) {
 	components := make([]common.Component, 0)
 
-	// Parse vtep IP
-	if in.Spec.VtepIpPrefix != nil {
-		vtepip := make(net.IP, 4)
-		binary.BigEndian.PutUint32(vtepip, in.Spec.VtepIpPrefix.Addr.GetV4Addr())
-		vip = &net.IPNet{IP: vtepip, Mask: net.CIDRMask(int(in.Spec.VtepIpPrefix.Len), 32)}
-	} else {
+	if spec.VtepIP == nil {
 		tmpVtepIP := utils.GetIPAddress(config.GlobalConfig.LinuxFrr.DefaultVtep)
-		vip = &tmpVtepIP
+		spec.VtepIP = &tmpVtepIP
 	}
 
 	subscribers := eventbus.EBus.GetSubscribers("logical-bridge")
@@ -92,12 +88,8 @@ func NewLogicalBridge(in *pb.LogicalBridge) (*LogicalBridge, error) {
 	}
 
 	return &LogicalBridge{
-		Name: in.Name,
-		Spec: &LogicalBridgeSpec{
-			VlanID: in.Spec.VlanId,
-			Vni:    in.Spec.Vni,
-			VtepIP: vip,
-		},
+		Name: name,
+		Spec: spec,
 		Status: &LogicalBridgeStatus{
 			LBOperStatus: LogicalBridgeOperStatus(LogicalBridgeOperStatusDown),
 			Components:   components,
@@ -109,49 +101,6 @@ func NewLogicalBridge(in *pb.LogicalBridge) (*LogicalBridge, error) {
 	}, nil
 }
 
-// ToPb transforms Logical Bridge object to protobuf message
-func (in *LogicalBridge) ToPb() *pb.LogicalBridge {
-	vtepip := common.ConvertToIPPrefix(in.Spec.VtepIP)
-
-	lb := &pb.LogicalBridge{
-		Name: in.Name,
-		Spec: &pb.LogicalBridgeSpec{
-			VlanId:       in.Spec.VlanID,
-			Vni:          in.Spec.Vni,
-			VtepIpPrefix: vtepip,
-		},
-		Status: &pb.LogicalBridgeStatus{},
-	}
-
-	switch in.Status.LBOperStatus {
-	case LogicalBridgeOperStatusDown:
-		lb.Status.OperStatus = pb.LBOperStatus_LB_OPER_STATUS_DOWN
-	case LogicalBridgeOperStatusUp:
-		lb.Status.OperStatus = pb.LBOperStatus_LB_OPER_STATUS_UP
-	case LogicalBridgeOperStatusToBeDeleted:
-		lb.Status.OperStatus = pb.LBOperStatus_LB_OPER_STATUS_TO_BE_DELETED
-	default:
-		lb.Status.OperStatus = pb.LBOperStatus_LB_OPER_STATUS_UNSPECIFIED
-	}
-
-	for _, comp := range in.Status.Components {
-		component := &pb.Component{Name: comp.Name, Details: comp.Details}
-		switch comp.CompStatus {
-		case common.ComponentStatusPending:
-			component.Status = pb.CompStatus_COMP_STATUS_PENDING
-		case common.ComponentStatusSuccess:
-			component.Status = pb.CompStatus_COMP_STATUS_SUCCESS
-		case common.ComponentStatusError:
-			component.Status = pb.CompStatus_COMP_STATUS_ERROR
-		default:
-			component.Status = pb.CompStatus_COMP_STATUS_UNSPECIFIED
-		}
-		lb.Status.Components = append(lb.Status.Components, component)
-	}
-
-	return lb
-}
-
 // AddSvi adds a reference of SVI to the Logical Bridge object
 func (in *LogicalBridge) AddSvi(sviName string) error {
 	if in.Svi != "" {
@@ -6,6 +6,9 @@
 package common
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"net"
 	"reflect"
 	"time"
@@ -57,6 +60,17 @@ func ConvertToIPPrefix(ipNet *net.IPNet) *pc.IPPrefix {
 	}
 
 	maskLen, _ := ipNet.Mask.Size()
+	if ipNet.IP.To4() == nil {
+		return &pc.IPPrefix{
+			Addr: &pc.IPAddress{
+				Af: pc.IpAf_IP_AF_INET6,
+				V4OrV6: &pc.IPAddress_V6Addr{
+					V6Addr: []byte(ipNet.IP.To16()),
+				},
+			},
+			Len: int32(maskLen),
+		}
+	}
 	return &pc.IPPrefix{
 		Addr: &pc.IPAddress{
 			Af: pc.IpAf_IP_AF_INET,
@@ -67,3 +81,38 @@ func ConvertToIPPrefix(ipNet *net.IPNet) *pc.IPPrefix {
 		Len: int32(maskLen),
 	}
 }
+
+// ConvertToIPNet converts IPPrefix type to IPNet, only the IPv4 prefixes are supported and a prefix
+// without address stands for 0.0.0.0 like any unset protobuf field
+func ConvertToIPNet(prefix *pc.IPPrefix) (*net.IPNet, error) {
+	if prefix == nil {
+		return nil, errors.New("the prefix is missing")
+	}
+	if _, ok := prefix.Addr.GetV4OrV6().(*pc.IPAddress_V6Addr); ok || prefix.Addr.GetAf() == pc.IpAf_IP_AF_INET6 {
+		return nil, errors.New("only IPv4 prefixes are supported")
+	}
+	if prefix.Len < 0 || prefix.Len > 32 {
+		return nil, fmt.Errorf("the prefix length %d has to be between 0 and 32", prefix.Len)
+	}
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, prefix.Addr.GetV4Addr())
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(int(prefix.Len), 32)}, nil
+}
+
+// ConvertToDualStackIPNet converts IPPrefix type to IPNet like ConvertToIPNet, the IPv6 prefixes
+// are accepted as well as they are on the subnets of the SVIs
+func ConvertToDualStackIPNet(prefix *pc.IPPrefix) (*net.IPNet, error) {
+	v6, ok := prefix.GetAddr().GetV4OrV6().(*pc.IPAddress_V6Addr)
+	if !ok && prefix.GetAddr().GetAf() != pc.IpAf_IP_AF_INET6 {
+		return ConvertToIPNet(prefix)
+	}
+	if !ok || len(v6.V6Addr) != net.IPv6len {
+		return nil, errors.New("the IPv6 address has to be 16 bytes long")
+	}
+	if prefix.Len < 0 || prefix.Len > 128 {
+		return nil, fmt.Errorf("the prefix length %d has to be between 0 and 128", prefix.Len)
+	}
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, v6.V6Addr)
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(int(prefix.Len), 128)}, nil
+}
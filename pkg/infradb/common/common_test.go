@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package common holds common functionality
+package common
+
+import (
+	"net"
+	"testing"
+
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+)
+
+func FuzzConvertToIPNet(f *testing.F) {
+	f.Add(int32(pc.IpAf_IP_AF_INET), uint32(167772162), int32(24), false, false)
+	f.Add(int32(pc.IpAf_IP_AF_INET), uint32(0), int32(0), true, false)
+	f.Add(int32(pc.IpAf_IP_AF_INET6), uint32(1), int32(64), false, true)
+	f.Add(int32(pc.IpAf_IP_AF_INET), uint32(0xffffffff), int32(33), false, false)
+	f.Add(int32(pc.IpAf_IP_AF_UNSPECIFIED), uint32(1), int32(-1), false, false)
+	f.Fuzz(func(t *testing.T, af int32, v4 uint32, length int32, noAddr bool, v6 bool) {
+		prefix := &pc.IPPrefix{Len: length}
+		if !noAddr {
+			prefix.Addr = &pc.IPAddress{Af: pc.IpAf(af), V4OrV6: &pc.IPAddress_V4Addr{V4Addr: v4}}
+			if v6 {
+				prefix.Addr.V4OrV6 = &pc.IPAddress_V6Addr{V6Addr: make([]byte, 16)}
+			}
+		}
+		ipNet, err := ConvertToIPNet(prefix)
+		if err != nil {
+			return
+		}
+		if (v6 && !noAddr) || length < 0 || length > 32 {
+			t.Fatalf("expected %v to be refused, received %v", prefix, ipNet)
+		}
+		if ones, bits := ipNet.Mask.Size(); len(ipNet.IP) != 4 || bits != 32 || ones != int(length) {
+			t.Fatalf("expected an IPv4 prefix of length %d, received %v", length, ipNet)
+		}
+		back := ConvertToIPPrefix(ipNet)
+		if back.Len != length || back.Addr.GetV4Addr() != prefix.Addr.GetV4Addr() {
+			t.Errorf("expected %v to convert back, received %v", prefix, back)
+		}
+	})
+}
+
+func TestConvertToDualStackIPNet(t *testing.T) {
+	_, want, _ := net.ParseCIDR("2001:db8::1/64")
+	want.IP = net.ParseIP("2001:db8::1")
+	prefix := ConvertToIPPrefix(want)
+	if prefix.Addr.GetAf() != pc.IpAf_IP_AF_INET6 || prefix.Len != 64 {
+		t.Fatalf("expected an IPv6 prefix of length 64, received %v", prefix)
+	}
+	if _, err := ConvertToIPNet(prefix); err == nil {
+		t.Errorf("expected %v to be refused by ConvertToIPNet", prefix)
+	}
+	got, err := ConvertToDualStackIPNet(prefix)
+	if err != nil || got.String() != want.String() {
+		t.Errorf("expected %v, received %v: %v", want, got, err)
+	}
+	prefix.Len = 129
+	if _, err := ConvertToDualStackIPNet(prefix); err == nil {
+		t.Errorf("expected the prefix length 129 to be refused")
+	}
+	prefix = &pc.IPPrefix{Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET6, V4OrV6: &pc.IPAddress_V6Addr{V6Addr: []byte{1}}}, Len: 64}
+	if _, err := ConvertToDualStackIPNet(prefix); err == nil {
+		t.Errorf("expected the short IPv6 address to be refused")
+	}
+	got, err = ConvertToDualStackIPNet(&pc.IPPrefix{Addr: &pc.IPAddress{V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 167772161}}, Len: 24})
+	if err != nil || got.String() != "10.0.0.1/24" {
+		t.Errorf("expected 10.0.0.1/24, received %v: %v", got, err)
+	}
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// Test_ConcurrentChanges creates and deletes the SVIs of a VRF from parallel goroutines,
+// while others read the objects, so that go test -race checks the locking of the DB
+func Test_ConcurrentChanges(t *testing.T) {
+	for _, eventType := range []string{"vrf", "logical-bridge", "svi"} {
+		eventbus.EBus.StartSubscriber("dummy", eventType, 1, nil)
+	}
+	if err := NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	const count = 16
+	vrfName := "//network.opiproject.org/vrfs/concurrent"
+	vrf, err := NewVrf(vrfName, &VrfSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateVrf(vrf); err != nil {
+		t.Fatal(err)
+	}
+
+	mac := net.HardwareAddr{0xaa, 0xbb, 0xcc, 0x00, 0x00, 0x01}
+	remoteAs := uint32(0)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			lb, err := NewLogicalBridge(fmt.Sprintf("//network.opiproject.org/bridges/concurrent-%d", i),
+				&LogicalBridgeSpec{VlanID: uint32(100 + i)})
+			if err == nil {
+				err = CreateLB(lb)
+			}
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			svi, err := NewSvi(fmt.Sprintf("//network.opiproject.org/svis/concurrent-%d", i),
+				&SviSpec{Vrf: vrfName, LogicalBridge: lb.Name, MacAddress: &mac, RemoteAs: &remoteAs})
+			if err == nil {
+				err = CreateSvi(svi)
+			}
+			if err == nil && i%2 == 0 {
+				err = DeleteSvi(svi.Name)
+			}
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := GetAllSvis(); err != nil && err != ErrKeyNotFound {
+				t.Error(err)
+			}
+			if _, err := GetDependencyGraph(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the deleted SVIs wait for their subscribers, all of them are still referenced by the VRF
+	stored, err := GetVrf(vrfName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Svis) != count {
+		t.Errorf("expected %d SVIs in the VRF, found %d", count, len(stored.Svis))
+	}
+}
@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+// ErrConntrackPolicyInUse the SVI already has a conntrack policy
+var ErrConntrackPolicyInUse = errors.New("the SVI already has a conntrack policy")
+
+// ConntrackTCPStates are the states of the tcp connections whose timeout can be overridden, as named by nftables
+var ConntrackTCPStates = []string{"syn_sent", "syn_recv", "established", "fin_wait", "close_wait", "last_ack", "time_wait", "close", "retrans", "unacknowledged"}
+
+// ConntrackUDPStates are the states of the udp flows whose timeout can be overridden, as named by nftables
+var ConntrackUDPStates = []string{"unreplied", "replied"}
+
+// ConntrackPolicySpec holds Conntrack Policy Spec
+type ConntrackPolicySpec struct {
+	// Svi is the subnet whose connections are tracked under the policy
+	Svi string
+	// MaxConnections bounds the connections opened by the hosts of the subnet, zero is unlimited
+	MaxConnections uint32
+	// TCPTimeouts override the kernel timeouts of the tcp connections of the subnet by state
+	TCPTimeouts map[string]time.Duration
+	// UDPTimeouts override the kernel timeouts of the udp flows of the subnet by state
+	UDPTimeouts map[string]time.Duration
+}
+
+// ConntrackPolicy holds Conntrack Policy info
+type ConntrackPolicy struct {
+	Resource
+	Spec *ConntrackPolicySpec
+}
+
+// conntrackPolicyKind describes the storage of the Conntrack Policy objects
+var conntrackPolicyKind = registerKind(resourceKind{
+	eventType: "conntrack-policy",
+	indexKey:  "conntrackpolicies",
+	newObject: func() resourceObject { return &ConntrackPolicy{} },
+	references: func(obj resourceObject) []string {
+		return []string{obj.(*ConntrackPolicy).Spec.Svi}
+	},
+})
+
+// validateConntrackTimeouts checks that the timeouts are of known states and in whole seconds
+func validateConntrackTimeouts(protocol string, timeouts map[string]time.Duration, states []string) error {
+	for state, timeout := range timeouts {
+		known := false
+		for _, s := range states {
+			known = known || s == state
+		}
+		if !known {
+			return fmt.Errorf("conntrack policy %s state %s is not one of %v", protocol, state, states)
+		}
+		if timeout < time.Second || timeout%time.Second != 0 {
+			return fmt.Errorf("conntrack policy %s %s timeout %v is not a whole number of seconds", protocol, state, timeout)
+		}
+	}
+	return nil
+}
+
+// validate checks the Conntrack Policy Spec
+func (in *ConntrackPolicySpec) validate() error {
+	if in.Svi == "" {
+		return fmt.Errorf("conntrack policy needs an SVI")
+	}
+	if in.MaxConnections == 0 && len(in.TCPTimeouts) == 0 && len(in.UDPTimeouts) == 0 {
+		return fmt.Errorf("conntrack policy needs a connection limit or timeouts")
+	}
+	if err := validateConntrackTimeouts("tcp", in.TCPTimeouts, ConntrackTCPStates); err != nil {
+		return err
+	}
+	return validateConntrackTimeouts("udp", in.UDPTimeouts, ConntrackUDPStates)
+}
+
+// NewConntrackPolicy creates new Conntrack Policy object
+func NewConntrackPolicy(name string, spec *ConntrackPolicySpec) (*ConntrackPolicy, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewConntrackPolicy(): Conntrack Policy spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("NewConntrackPolicy(): %v", err)
+	}
+
+	res, err := newResource(name, conntrackPolicyKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConntrackPolicy{Resource: res, Spec: spec}, nil
+}
+
+// getAllConntrackPolicies returns all the conntrack policies, the caller must hold the global lock
+func getAllConntrackPolicies() ([]*ConntrackPolicy, error) {
+	ctps := []*ConntrackPolicy{}
+	names, err := conntrackPolicyKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		ctp := &ConntrackPolicy{}
+		if err := conntrackPolicyKind.get(name, ctp); err != nil {
+			log.Printf("getAllConntrackPolicies(): Failed to get the Conntrack Policy %s from store: %v", name, err)
+			return nil, err
+		}
+		ctps = append(ctps, ctp)
+	}
+	return ctps, nil
+}
+
+// CreateConntrackPolicy creates an infradb conntrack policy object
+func CreateConntrackPolicy(ctp *ConntrackPolicy) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	found, err := infradb.client.Get(ctp.Spec.Svi, &Svi{})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if !found {
+		log.Printf("CreateConntrackPolicy(): The SVI with name %+v has not been found\n", ctp.Spec.Svi)
+		return ErrSviNotFound
+	}
+
+	ctps, err := getAllConntrackPolicies()
+	if err != nil {
+		return err
+	}
+	for _, existing := range ctps {
+		if existing.Spec.Svi == ctp.Spec.Svi {
+			log.Printf("CreateConntrackPolicy(): %s already has the conntrack policy %s\n", ctp.Spec.Svi, existing.Name)
+			return ErrConntrackPolicyInUse
+		}
+	}
+
+	return conntrackPolicyKind.create(ctp)
+}
+
+// DeleteConntrackPolicy deletes a conntrack policy infradb object
+func DeleteConntrackPolicy(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	ctp := &ConntrackPolicy{}
+	if err := conntrackPolicyKind.get(name, ctp); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, ctp.ResourceVersion, opts); err != nil {
+		return err
+	}
+	return conntrackPolicyKind.delete(ctp)
+}
+
+// GetConntrackPolicy returns an infradb conntrack policy object
+func GetConntrackPolicy(name string) (*ConntrackPolicy, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	ctp := &ConntrackPolicy{}
+	err := conntrackPolicyKind.get(name, ctp)
+	return ctp, err
+}
+
+// GetAllConntrackPolicies returns a list of conntrack policies from the DB
+func GetAllConntrackPolicies() ([]*ConntrackPolicy, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllConntrackPolicies()
+}
+
+// UpdateConntrackPolicyStatus updates the status of conntrack policy object based on the component report
+func UpdateConntrackPolicyStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return conntrackPolicyKind.updateStatus(&ConntrackPolicy{}, name, resourceVersion, notificationID, component)
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"log"
+	"path"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+)
+
+var (
+	// ErrVrfToBeDeleted the vrf is being deleted
+	ErrVrfToBeDeleted = errors.New("the vrf is being deleted")
+	// ErrVpnNoVni the vrf has no VNI, which identifies the VPC in its VPN route distinguisher and targets
+	ErrVpnNoVni = errors.New("the vrf has no VNI to derive its VPN route distinguisher and targets from")
+)
+
+// IsVpn tells whether the VPC is carried over a VPN, through the MPLS core or the SRv6 fabric, instead of
+// its L3 VNI
+func (in *VrfSpec) IsVpn() bool {
+	return in.IsMpls() || in.IsSrv6()
+}
+
+// setVrfDataplane switches the stored vrf over the dataplane that set writes in its spec, then the vrf
+// is programmed again. The GRD and the vrfs without VNI only run over VXLAN.
+func setVrfDataplane(caller string, name string, set func(vrf *Vrf) error, opts ...WriteOption) (*Vrf, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	subscribers := eventbus.EBus.GetSubscribers("vrf")
+	if len(subscribers) == 0 {
+		log.Printf("%s(): No subscribers for Vrf objects\n", caller)
+		return nil, errors.New("no subscribers found for vrf")
+	}
+
+	vrf := &Vrf{}
+	found, err := infradb.client.Get(name, vrf)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	if err := checkIfMatch(name, vrf.ResourceVersion, opts); err != nil {
+		return nil, err
+	}
+	if vrf.Status.VrfOperStatus == VrfOperStatusToBeDeleted {
+		return nil, ErrVrfToBeDeleted
+	}
+	if vrf.Spec.Vni == nil || path.Base(vrf.Name) == "GRD" {
+		return nil, ErrVpnNoVni
+	}
+
+	if err := set(vrf); err != nil {
+		return nil, err
+	}
+	for i := range vrf.Status.Components {
+		vrf.Status.Components[i].CompStatus = common.ComponentStatusPending
+	}
+	vrf.ResourceVersion = generateVersion()
+
+	err = infradb.client.Set(vrf.Name, vrf)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	notifyLifecycle(StatusEventUpdated, "vrf", vrf.Name, vrf.ResourceVersion)
+	taskmanager.TaskMan.CreateTask(vrf.Name, "vrf", vrf.ResourceVersion, subscribers)
+
+	return vrf, nil
+}
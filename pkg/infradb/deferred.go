@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package infradb exposes the interface for the manipulation of the api objects
+package infradb
+
+import (
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// pendingSubs returns the subscribers from the component which has left the object in pending state
+// onwards, nil when the component is not pending. The components are ordered like the subscribers.
+func pendingSubs(components []common.Component, subs []*eventbus.Subscriber, componentName string) []*eventbus.Subscriber {
+	for i, comp := range components {
+		if comp.Name != componentName {
+			continue
+		}
+		if comp.CompStatus != common.ComponentStatusPending || i >= len(subs) {
+			return nil
+		}
+		return subs[i:]
+	}
+	return nil
+}
+
+// resolveVtepIP fills in the vtep ip which has been left unspecified because the default vtep
+// device had no address when the object was created, and tells whether it has changed
+func resolveVtepIP(vtepIP *net.IPNet) bool {
+	if vtepIP == nil || !vtepIP.IP.IsUnspecified() {
+		return false
+	}
+	defaultVtepIP := utils.GetIPAddress(config.GlobalConfig.LinuxFrr.DefaultVtep)
+	if defaultVtepIP.IP.IsUnspecified() {
+		return false
+	}
+	*vtepIP = defaultVtepIP
+	return true
+}
+
+// ResumeTask creates again the task of an object which a component has left in pending state
+// because a dependency was missing, starting from that component, with the vtep ip of the default
+// vtep device if it was not known at creation time. The objects which are not
+// pending anymore, e.g. because they have been updated meanwhile, are left alone.
+func ResumeTask(objectType, name, componentName string) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	var components []common.Component
+	var resourceVersion string
+	var vtepIP *net.IPNet
+	var obj interface{}
+	switch objectType {
+	case "vrf":
+		vrf := Vrf{}
+		found, err := infradb.client.Get(name, &vrf)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrKeyNotFound
+		}
+		if vrf.Status.VrfOperStatus == VrfOperStatusToBeDeleted {
+			return nil
+		}
+		components, resourceVersion, vtepIP, obj = vrf.Status.Components, vrf.ResourceVersion, vrf.Spec.VtepIP, vrf
+	case "logical-bridge":
+		lb := LogicalBridge{}
+		found, err := infradb.client.Get(name, &lb)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrKeyNotFound
+		}
+		if lb.Status.LBOperStatus == LogicalBridgeOperStatusToBeDeleted {
+			return nil
+		}
+		components, resourceVersion, vtepIP, obj = lb.Status.Components, lb.ResourceVersion, lb.Spec.VtepIP, lb
+	default:
+		return fmt.Errorf("resuming %s objects is not supported", objectType)
+	}
+
+	subs := pendingSubs(components, eventbus.EBus.GetSubscribers(objectType), componentName)
+	if subs == nil {
+		log.Printf("ResumeTask(): %s %s is not pending on %s\n", objectType, name, componentName)
+		return nil
+	}
+	if resolveVtepIP(vtepIP) {
+		if err := infradb.client.Set(name, obj); err != nil {
+			return err
+		}
+	}
+	taskmanager.TaskMan.CreateTask(name, objectType, resourceVersion, subs)
+	return nil
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+func Test_PendingSubs(t *testing.T) {
+	subs := []*eventbus.Subscriber{{Name: "lgm"}, {Name: "frr"}, {Name: "lci"}}
+	tests := map[string]struct {
+		components []common.Component
+		expected   []string
+	}{
+		"pending": {
+			components: []common.Component{
+				{Name: "lgm", CompStatus: common.ComponentStatusSuccess},
+				{Name: "frr", CompStatus: common.ComponentStatusPending},
+				{Name: "lci", CompStatus: common.ComponentStatusPending},
+			},
+			expected: []string{"frr", "lci"},
+		},
+		"not pending anymore": {
+			components: []common.Component{
+				{Name: "lgm", CompStatus: common.ComponentStatusSuccess},
+				{Name: "frr", CompStatus: common.ComponentStatusError},
+				{Name: "lci", CompStatus: common.ComponentStatusPending},
+			},
+		},
+		"unknown component": {
+			components: []common.Component{{Name: "lgm", CompStatus: common.ComponentStatusPending}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var names []string
+			for _, sub := range pendingSubs(tt.components, subs, "frr") {
+				names = append(names, sub.Name)
+			}
+			if len(names) != len(tt.expected) {
+				t.Fatalf("expected %v, received %v", tt.expected, names)
+			}
+			for i := range names {
+				if names[i] != tt.expected[i] {
+					t.Errorf("expected %v, received %v", tt.expected, names)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+var (
+	// ErrDHCPServerInUse the subnet already has a DHCP server
+	ErrDHCPServerInUse = errors.New("the subnet already has a DHCP server")
+	// ErrDHCPServerSubnet the subnet is not the one of a gateway address of the SVI
+	ErrDHCPServerSubnet = errors.New("the subnet is not the one of a gateway address of the SVI")
+	// ErrDHCPServerNoMac the bridge port of a reservation has no MAC address
+	ErrDHCPServerNoMac = errors.New("the Bridge Port of the reservation has no MAC address")
+)
+
+const (
+	// defaultDHCPLeaseTime is the lease time of the addresses when the spec leaves it out, in seconds
+	defaultDHCPLeaseTime = 3600
+	// minDHCPLeaseTime is the shortest lease time dnsmasq hands out, in seconds
+	minDHCPLeaseTime = 120
+	// minDHCPMtu is the smallest MTU of the DHCP interface MTU option
+	minDHCPMtu = 68
+)
+
+// DHCPReservation hands out the same address to the MAC address of a Bridge Port
+type DHCPReservation struct {
+	BridgePort string
+	IP         net.IP
+}
+
+// DHCPServerSpec holds DHCP Server Spec
+type DHCPServerSpec struct {
+	Svi string
+	// Subnet is the subnet of a gateway address of the SVI, its family selects DHCP or DHCPv6
+	Subnet *net.IPNet
+	// RangeStart and RangeEnd bound the addresses handed out dynamically
+	RangeStart net.IP
+	RangeEnd   net.IP
+	// LeaseTime is the lease time of the addresses in seconds
+	LeaseTime    uint32
+	Reservations []*DHCPReservation
+	DNSServers   []net.IP
+	// Mtu is sent in the interface MTU option, IPv4 only as the IPv6 hosts learn it from the RAs
+	Mtu uint32
+}
+
+// DHCPServer holds DHCP Server info
+type DHCPServer struct {
+	Resource
+	Spec *DHCPServerSpec
+}
+
+// dhcpServerKind describes the storage of the DHCP Server objects
+var dhcpServerKind = registerKind(resourceKind{
+	eventType: "dhcp-server",
+	indexKey:  "dhcpservers",
+	newObject: func() resourceObject { return &DHCPServer{} },
+	references: func(obj resourceObject) []string {
+		spec := obj.(*DHCPServer).Spec
+		refs := []string{spec.Svi}
+		for _, r := range spec.Reservations {
+			refs = append(refs, r.BridgePort)
+		}
+		return refs
+	},
+})
+
+// sameFamily tells whether both addresses are IPv4 or both are IPv6
+func sameFamily(a, b net.IP) bool {
+	return (a.To4() == nil) == (b.To4() == nil)
+}
+
+// validate checks the DHCP Server Spec and sets the default lease time
+func (in *DHCPServerSpec) validate() error {
+	if in.Svi == "" {
+		return fmt.Errorf("DHCP Server needs an SVI")
+	}
+	if in.Subnet == nil {
+		return fmt.Errorf("DHCP Server needs a subnet")
+	}
+	subnet := &net.IPNet{IP: in.Subnet.IP.Mask(in.Subnet.Mask), Mask: in.Subnet.Mask}
+	for _, ip := range []net.IP{in.RangeStart, in.RangeEnd} {
+		if ip == nil || !subnet.Contains(ip) {
+			return fmt.Errorf("DHCP Server range %s-%s is not in %s", in.RangeStart, in.RangeEnd, subnet)
+		}
+	}
+	if bytes.Compare(in.RangeStart.To16(), in.RangeEnd.To16()) > 0 {
+		return fmt.Errorf("DHCP Server range %s-%s ends before it starts", in.RangeStart, in.RangeEnd)
+	}
+	if in.LeaseTime == 0 {
+		in.LeaseTime = defaultDHCPLeaseTime
+	}
+	if in.LeaseTime < minDHCPLeaseTime {
+		return fmt.Errorf("DHCP Server lease time %d is shorter than %d seconds", in.LeaseTime, minDHCPLeaseTime)
+	}
+	for _, dns := range in.DNSServers {
+		if !sameFamily(dns, subnet.IP) {
+			return fmt.Errorf("DHCP Server DNS server %s is not of the family of %s", dns, subnet)
+		}
+	}
+	if in.Mtu != 0 {
+		if subnet.IP.To4() == nil {
+			return fmt.Errorf("DHCP Server MTU is only sent to IPv4 hosts")
+		}
+		if in.Mtu < minDHCPMtu || in.Mtu > 0xffff {
+			return fmt.Errorf("DHCP Server MTU %d is not between %d and %d", in.Mtu, minDHCPMtu, 0xffff)
+		}
+	}
+	for i, r := range in.Reservations {
+		if r.BridgePort == "" {
+			return fmt.Errorf("DHCP Server reservation of %s needs a bridge port", r.IP)
+		}
+		if r.IP == nil || !subnet.Contains(r.IP) {
+			return fmt.Errorf("DHCP Server reservation %s of %s is not in %s", r.IP, r.BridgePort, subnet)
+		}
+		for _, other := range in.Reservations[:i] {
+			if other.BridgePort == r.BridgePort || other.IP.Equal(r.IP) {
+				return fmt.Errorf("DHCP Server reservation %s of %s is duplicated", r.IP, r.BridgePort)
+			}
+		}
+	}
+	return nil
+}
+
+// NewDHCPServer creates new DHCP Server object
+func NewDHCPServer(name string, spec *DHCPServerSpec) (*DHCPServer, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewDHCPServer(): DHCP Server spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("NewDHCPServer(): %v", err)
+	}
+
+	res, err := newResource(name, dhcpServerKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DHCPServer{Resource: res, Spec: spec}, nil
+}
+
+// getAllDHCPServers returns all the dhcp servers, the caller must hold the global lock
+func getAllDHCPServers() ([]*DHCPServer, error) {
+	dhcps := []*DHCPServer{}
+	names, err := dhcpServerKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		dhcp := &DHCPServer{}
+		if err := dhcpServerKind.get(name, dhcp); err != nil {
+			log.Printf("getAllDHCPServers(): Failed to get the DHCP Server %s from store: %v", name, err)
+			return nil, err
+		}
+		dhcps = append(dhcps, dhcp)
+	}
+	return dhcps, nil
+}
+
+// CreateDHCPServer creates an infradb dhcp server object
+func CreateDHCPServer(dhcp *DHCPServer) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	svi := Svi{}
+	found, err := infradb.client.Get(dhcp.Spec.Svi, &svi)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if !found {
+		log.Printf("CreateDHCPServer(): The SVI with name %+v has not been found\n", dhcp.Spec.Svi)
+		return ErrSviNotFound
+	}
+	if subnet := sviSubnetFor(&svi, dhcp.Spec.Subnet.IP); subnet == nil || subnet.String() != dhcp.Spec.Subnet.String() {
+		log.Printf("CreateDHCPServer(): %s is not a subnet of %s\n", dhcp.Spec.Subnet, dhcp.Spec.Svi)
+		return ErrDHCPServerSubnet
+	}
+	for _, r := range dhcp.Spec.Reservations {
+		bp := BridgePort{}
+		found, err := infradb.client.Get(r.BridgePort, &bp)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		if !found {
+			log.Printf("CreateDHCPServer(): The Bridge Port with name %+v has not been found\n", r.BridgePort)
+			return ErrBridgePortNotFound
+		}
+		if bp.Spec.MacAddress == nil || len(*bp.Spec.MacAddress) == 0 {
+			return ErrDHCPServerNoMac
+		}
+	}
+
+	dhcps, err := getAllDHCPServers()
+	if err != nil {
+		return err
+	}
+	for _, existing := range dhcps {
+		if existing.Spec.Svi == dhcp.Spec.Svi && existing.Spec.Subnet.String() == dhcp.Spec.Subnet.String() {
+			log.Printf("CreateDHCPServer(): %s already has the DHCP server %s\n", dhcp.Spec.Subnet, existing.Name)
+			return ErrDHCPServerInUse
+		}
+	}
+
+	return dhcpServerKind.create(dhcp)
+}
+
+// DeleteDHCPServer deletes a dhcp server infradb object
+func DeleteDHCPServer(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	dhcp := &DHCPServer{}
+	if err := dhcpServerKind.get(name, dhcp); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, dhcp.ResourceVersion, opts); err != nil {
+		return err
+	}
+	return dhcpServerKind.delete(dhcp)
+}
+
+// GetDHCPServer returns an infradb dhcp server object
+func GetDHCPServer(name string) (*DHCPServer, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	dhcp := &DHCPServer{}
+	err := dhcpServerKind.get(name, dhcp)
+	return dhcp, err
+}
+
+// GetAllDHCPServers returns a list of dhcp servers from the DB
+func GetAllDHCPServers() ([]*DHCPServer, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllDHCPServers()
+}
+
+// UpdateDHCPServerStatus updates the status of dhcp server object based on the component report
+func UpdateDHCPServerStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return dhcpServerKind.updateStatus(&DHCPServer{}, name, resourceVersion, notificationID, component)
+}
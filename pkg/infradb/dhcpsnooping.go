@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+// ErrDHCPSnoopingInUse the logical bridge already has a DHCP snooping
+var ErrDHCPSnoopingInUse = errors.New("the Logical Bridge already has a DHCP snooping")
+
+// DHCPSnoopingSpec holds DHCP Snooping Spec
+type DHCPSnoopingSpec struct {
+	LogicalBridge string
+	// TrustedPorts are the bridge ports of the DHCP servers and relays, the offers and acks received on the
+	// other access ports of the logical bridge are dropped
+	TrustedPorts []string
+	// SourceGuard drops the IP packets and ARP messages of the untrusted ports whose source address was not
+	// handed out to their MAC address on the port
+	SourceGuard bool
+}
+
+// DHCPSnooping holds DHCP Snooping info
+type DHCPSnooping struct {
+	Resource
+	Spec *DHCPSnoopingSpec
+}
+
+// dhcpSnoopingKind describes the storage of the DHCP Snooping objects
+var dhcpSnoopingKind = registerKind(resourceKind{
+	eventType: "dhcp-snooping",
+	indexKey:  "dhcpsnoopings",
+	newObject: func() resourceObject { return &DHCPSnooping{} },
+	references: func(obj resourceObject) []string {
+		spec := obj.(*DHCPSnooping).Spec
+		return append([]string{spec.LogicalBridge}, spec.TrustedPorts...)
+	},
+})
+
+// validate checks the DHCP Snooping Spec
+func (in *DHCPSnoopingSpec) validate() error {
+	if in.LogicalBridge == "" {
+		return fmt.Errorf("dhcp snooping needs a logical bridge")
+	}
+	for i, port := range in.TrustedPorts {
+		for _, other := range in.TrustedPorts[:i] {
+			if other == port {
+				return fmt.Errorf("dhcp snooping trusted port %s is duplicated", port)
+			}
+		}
+	}
+	return nil
+}
+
+// IsTrusted tells whether the DHCP servers of the bridge port are trusted
+func (in *DHCPSnoopingSpec) IsTrusted(port string) bool {
+	for _, trusted := range in.TrustedPorts {
+		if trusted == port {
+			return true
+		}
+	}
+	return false
+}
+
+// NewDHCPSnooping creates new DHCP Snooping object
+func NewDHCPSnooping(name string, spec *DHCPSnoopingSpec) (*DHCPSnooping, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewDHCPSnooping(): DHCP Snooping spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("NewDHCPSnooping(): %v", err)
+	}
+
+	res, err := newResource(name, dhcpSnoopingKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DHCPSnooping{Resource: res, Spec: spec}, nil
+}
+
+// getAllDHCPSnoopings returns all the dhcp snoopings, the caller must hold the global lock
+func getAllDHCPSnoopings() ([]*DHCPSnooping, error) {
+	snoopings := []*DHCPSnooping{}
+	names, err := dhcpSnoopingKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		snooping := &DHCPSnooping{}
+		if err := dhcpSnoopingKind.get(name, snooping); err != nil {
+			log.Printf("getAllDHCPSnoopings(): Failed to get the DHCP Snooping %s from store: %v", name, err)
+			return nil, err
+		}
+		snoopings = append(snoopings, snooping)
+	}
+	return snoopings, nil
+}
+
+// CreateDHCPSnooping creates an infradb dhcp snooping object
+func CreateDHCPSnooping(snooping *DHCPSnooping) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	found, err := infradb.client.Get(snooping.Spec.LogicalBridge, &LogicalBridge{})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if !found {
+		log.Printf("CreateDHCPSnooping(): The Logical Bridge with name %+v has not been found\n", snooping.Spec.LogicalBridge)
+		return ErrLogicalBridgeNotFound
+	}
+	for _, port := range snooping.Spec.TrustedPorts {
+		found, err := infradb.client.Get(port, &BridgePort{})
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		if !found {
+			log.Printf("CreateDHCPSnooping(): The Bridge Port with name %+v has not been found\n", port)
+			return ErrBridgePortNotFound
+		}
+	}
+
+	snoopings, err := getAllDHCPSnoopings()
+	if err != nil {
+		return err
+	}
+	for _, existing := range snoopings {
+		if existing.Spec.LogicalBridge == snooping.Spec.LogicalBridge {
+			log.Printf("CreateDHCPSnooping(): %s already has the dhcp snooping %s\n", snooping.Spec.LogicalBridge, existing.Name)
+			return ErrDHCPSnoopingInUse
+		}
+	}
+
+	return dhcpSnoopingKind.create(snooping)
+}
+
+// DeleteDHCPSnooping deletes a dhcp snooping infradb object
+func DeleteDHCPSnooping(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	snooping := &DHCPSnooping{}
+	if err := dhcpSnoopingKind.get(name, snooping); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, snooping.ResourceVersion, opts); err != nil {
+		return err
+	}
+	return dhcpSnoopingKind.delete(snooping)
+}
+
+// GetDHCPSnooping returns an infradb dhcp snooping object
+func GetDHCPSnooping(name string) (*DHCPSnooping, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	snooping := &DHCPSnooping{}
+	err := dhcpSnoopingKind.get(name, snooping)
+	return snooping, err
+}
+
+// GetAllDHCPSnoopings returns a list of dhcp snoopings from the DB
+func GetAllDHCPSnoopings() ([]*DHCPSnooping, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllDHCPSnoopings()
+}
+
+// UpdateDHCPSnoopingStatus updates the status of dhcp snooping object based on the component report
+func UpdateDHCPSnoopingStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return dhcpSnoopingKind.updateStatus(&DHCPSnooping{}, name, resourceVersion, notificationID, component)
+}
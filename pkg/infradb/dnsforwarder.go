@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+// ErrDNSForwarderInUse the VRF already has a DNS forwarder
+var ErrDNSForwarderInUse = errors.New("the VRF already has a DNS forwarder")
+
+// DNSConditionalForwarder sends the queries of a domain to dedicated servers
+type DNSConditionalForwarder struct {
+	Domain  string
+	Servers []net.IP
+}
+
+// DNSForwarderSpec holds DNS Forwarder Spec
+type DNSForwarderSpec struct {
+	Vrf string
+	// Upstreams are the resolvers used for the queries which match no conditional forwarder
+	Upstreams             []net.IP
+	ConditionalForwarders []*DNSConditionalForwarder
+	// ListenAddresses defaults to the gateway addresses of the SVIs of the VRF
+	ListenAddresses []net.IP
+}
+
+// DNSForwarder holds DNS Forwarder info
+type DNSForwarder struct {
+	Resource
+	Spec *DNSForwarderSpec
+}
+
+// dnsForwarderKind describes the storage of the DNS Forwarder objects
+var dnsForwarderKind = registerKind(resourceKind{
+	eventType: "dns-forwarder",
+	indexKey:  "dnsforwarders",
+	newObject: func() resourceObject { return &DNSForwarder{} },
+	references: func(obj resourceObject) []string {
+		return []string{obj.(*DNSForwarder).Spec.Vrf}
+	},
+})
+
+// validate checks the DNS Forwarder Spec
+func (in *DNSForwarderSpec) validate() error {
+	if in.Vrf == "" {
+		return fmt.Errorf("DNS Forwarder needs a VRF")
+	}
+	if len(in.Upstreams) == 0 && len(in.ConditionalForwarders) == 0 {
+		return fmt.Errorf("DNS Forwarder needs an upstream resolver or a conditional forwarder")
+	}
+	domains := map[string]bool{}
+	for _, fwd := range in.ConditionalForwarders {
+		domain := strings.Trim(fwd.Domain, ".")
+		if domain == "" || strings.ContainsAny(domain, "/# ") {
+			return fmt.Errorf("DNS Forwarder domain %q is not valid", fwd.Domain)
+		}
+		if domains[domain] {
+			return fmt.Errorf("DNS Forwarder domain %s is duplicated", domain)
+		}
+		domains[domain] = true
+		if len(fwd.Servers) == 0 {
+			return fmt.Errorf("DNS Forwarder domain %s needs a server", domain)
+		}
+	}
+	return nil
+}
+
+// NewDNSForwarder creates new DNS Forwarder object
+func NewDNSForwarder(name string, spec *DNSForwarderSpec) (*DNSForwarder, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewDNSForwarder(): DNS Forwarder spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("NewDNSForwarder(): %v", err)
+	}
+
+	res, err := newResource(name, dnsForwarderKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DNSForwarder{Resource: res, Spec: spec}, nil
+}
+
+// getAllDNSForwarders returns all the dns forwarders, the caller must hold the global lock
+func getAllDNSForwarders() ([]*DNSForwarder, error) {
+	dnss := []*DNSForwarder{}
+	names, err := dnsForwarderKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		dns := &DNSForwarder{}
+		if err := dnsForwarderKind.get(name, dns); err != nil {
+			log.Printf("getAllDNSForwarders(): Failed to get the DNS Forwarder %s from store: %v", name, err)
+			return nil, err
+		}
+		dnss = append(dnss, dns)
+	}
+	return dnss, nil
+}
+
+// CreateDNSForwarder creates an infradb dns forwarder object
+func CreateDNSForwarder(dns *DNSForwarder) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	if err := checkVrfExists(dns.Spec.Vrf); err != nil {
+		return err
+	}
+
+	dnss, err := getAllDNSForwarders()
+	if err != nil {
+		return err
+	}
+	for _, existing := range dnss {
+		if existing.Spec.Vrf == dns.Spec.Vrf {
+			log.Printf("CreateDNSForwarder(): %s already has the DNS forwarder %s\n", dns.Spec.Vrf, existing.Name)
+			return ErrDNSForwarderInUse
+		}
+	}
+
+	return dnsForwarderKind.create(dns)
+}
+
+// DeleteDNSForwarder deletes a dns forwarder infradb object
+func DeleteDNSForwarder(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	dns := &DNSForwarder{}
+	if err := dnsForwarderKind.get(name, dns); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, dns.ResourceVersion, opts); err != nil {
+		return err
+	}
+	return dnsForwarderKind.delete(dns)
+}
+
+// GetDNSForwarder returns an infradb dns forwarder object
+func GetDNSForwarder(name string) (*DNSForwarder, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	dns := &DNSForwarder{}
+	err := dnsForwarderKind.get(name, dns)
+	return dns, err
+}
+
+// GetAllDNSForwarders returns a list of dns forwarders from the DB
+func GetAllDNSForwarders() ([]*DNSForwarder, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllDNSForwarders()
+}
+
+// UpdateDNSForwarderStatus updates the status of dns forwarder object based on the component report
+func UpdateDNSForwarderStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return dnsForwarderKind.updateStatus(&DNSForwarder{}, name, resourceVersion, notificationID, component)
+}
@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+var (
+	// ErrLogicalBridgeToBeDeleted the logical bridge is being deleted
+	ErrLogicalBridgeToBeDeleted = errors.New("the logical bridge is being deleted")
+	// ErrEncapNoVni the logical bridge is not stretched over a tunnel
+	ErrEncapNoVni = errors.New("the logical bridge has no VNI to encapsulate")
+	// ErrEncapUnsupported the routing backend or the bridge topology cannot carry the encapsulation
+	ErrEncapUnsupported = errors.New("the encapsulation is not supported")
+)
+
+const (
+	// GeneveDevice is the flow based geneve device which carries all the logical bridges over geneve, the
+	// kernel takes a single one per udp port. Its vlans are the ones of the logical bridges.
+	GeneveDevice = "gnv0"
+	// GenevePort is the udp port of the geneve tunnels
+	GenevePort = 6081
+	// maxGeneveOptionData is the largest data of a geneve option, its length is a 5 bits count of 4 bytes words
+	maxGeneveOptionData = 124
+	// maxGeneveOptions is the largest length of the options of a geneve header, a 6 bits count of 4 bytes words
+	maxGeneveOptions = 252
+	// geneveOptionHeader is the class, type and length preceding the data of an option
+	geneveOptionHeader = 4
+)
+
+// GeneveOption is a TLV carried in the geneve header of the packets of a logical bridge
+type GeneveOption struct {
+	Class uint16
+	// Type has its high bit set for the options the receivers must understand
+	Type uint8
+	Data []byte
+}
+
+// String renders the option as tc tunnel_key does, e.g. 0102:80:00112233
+func (o *GeneveOption) String() string {
+	return fmt.Sprintf("%04x:%02x:%x", o.Class, o.Type, o.Data)
+}
+
+// EncapSpec holds the encapsulation of the tunnel of a Logical Bridge, VXLAN when it is left out
+type EncapSpec struct {
+	// Type is either vxlan or geneve
+	Type string
+	// Options are sent in the geneve header of every packet
+	Options []GeneveOption
+}
+
+// GeneveOptions renders the options as the geneve_opts of tc tunnel_key, empty without options
+func (in *EncapSpec) GeneveOptions() string {
+	opts := make([]string, 0, len(in.Options))
+	for i := range in.Options {
+		opts = append(opts, in.Options[i].String())
+	}
+	return strings.Join(opts, ",")
+}
+
+// validate checks the encapsulation and its options
+func (in *EncapSpec) validate() error {
+	switch in.Type {
+	case routing.EncapVxlan:
+		if len(in.Options) != 0 {
+			return fmt.Errorf("vxlan encapsulation does not carry options")
+		}
+	case routing.EncapGeneve:
+		total := 0
+		for _, o := range in.Options {
+			if len(o.Data)%4 != 0 || len(o.Data) > maxGeneveOptionData {
+				return fmt.Errorf("geneve option %04x:%02x data must be a multiple of 4 bytes up to %d bytes", o.Class, o.Type, maxGeneveOptionData)
+			}
+			total += geneveOptionHeader + len(o.Data)
+		}
+		if total > maxGeneveOptions {
+			return fmt.Errorf("geneve options take %d bytes, more than %d", total, maxGeneveOptions)
+		}
+	default:
+		return fmt.Errorf("unknown encapsulation %q, expected %s or %s", in.Type, routing.EncapVxlan, routing.EncapGeneve)
+	}
+	return nil
+}
+
+// NewEncapSpec returns the validated encapsulation
+func NewEncapSpec(typ string, options []GeneveOption) (*EncapSpec, error) {
+	in := &EncapSpec{Type: typ, Options: options}
+	if err := in.validate(); err != nil {
+		return nil, fmt.Errorf("NewEncapSpec(): %w", err)
+	}
+	return in, nil
+}
+
+// GeneveTunnel renders the tunnel of the logical bridge as the arguments of tc tunnel_key set, but for
+// the remote vtep, e.g. id 1000 src_ip 10.0.0.1 dst_port 6081 geneve_opts 0102:80:00112233
+func (in *LogicalBridgeSpec) GeneveTunnel() string {
+	args := fmt.Sprintf("id %d src_ip %s dst_port %d", *in.Vni, in.VtepIP.IP, GenevePort)
+	if opts := in.Encap.GeneveOptions(); opts != "" {
+		args += " geneve_opts " + opts
+	}
+	return args
+}
+
+// IsGeneve tells whether the logical bridge is carried over geneve
+func (in *LogicalBridgeSpec) IsGeneve() bool {
+	return in.Vni != nil && in.Encap != nil && in.Encap.Type == routing.EncapGeneve
+}
+
+// checkEncapSupport checks that the routing backend and the bridge topology carry the encapsulation
+func checkEncapSupport(encap *EncapSpec) error {
+	if encap.Type == routing.EncapVxlan {
+		return nil
+	}
+	backend, err := routing.Get()
+	if err != nil {
+		return err
+	}
+	if err := routing.SupportsEncap(backend, encap.Type); err != nil {
+		return fmt.Errorf("%w: %v", ErrEncapUnsupported, err)
+	}
+	// the tunnel of all the logical bridges is one port of the vlan aware bridge
+	if topology := config.GlobalConfig.LinuxFrr.BridgeTopology; topology != "" && topology != utils.VlanAwareTopology {
+		return fmt.Errorf("%w: %s requires the %s bridge topology", ErrEncapUnsupported, encap.Type, utils.VlanAwareTopology)
+	}
+	return nil
+}
+
+// SetLogicalBridgeEncap sets the encapsulation of the tunnel of the logical bridge, nil is back to VXLAN,
+// the logical bridge is programmed again over its new tunnel
+func SetLogicalBridgeEncap(name string, encap *EncapSpec, opts ...WriteOption) (*LogicalBridge, error) {
+	if encap != nil {
+		if err := encap.validate(); err != nil {
+			return nil, fmt.Errorf("SetLogicalBridgeEncap(): %w", err)
+		}
+		if err := checkEncapSupport(encap); err != nil {
+			return nil, err
+		}
+	}
+
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	subscribers := eventbus.EBus.GetSubscribers("logical-bridge")
+	if len(subscribers) == 0 {
+		log.Println("SetLogicalBridgeEncap(): No subscribers for Logical Bridge objects")
+		return nil, errors.New("no subscribers found for logical bridge")
+	}
+
+	lb := &LogicalBridge{}
+	found, err := infradb.client.Get(name, lb)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	if err := checkIfMatch(name, lb.ResourceVersion, opts); err != nil {
+		return nil, err
+	}
+	if lb.Status.LBOperStatus == LogicalBridgeOperStatusToBeDeleted {
+		return nil, ErrLogicalBridgeToBeDeleted
+	}
+	if lb.Spec.Vni == nil {
+		return nil, ErrEncapNoVni
+	}
+	if encap != nil && encap.Type == routing.EncapGeneve && lb.Svi != "" {
+		svi := &Svi{}
+		found, err := infradb.client.Get(lb.Svi, svi)
+		if err != nil {
+			return nil, err
+		}
+		if found && svi.Spec.hasLocalProxyArp() {
+			return nil, ErrProxyArpGeneve
+		}
+	}
+
+	lb.Spec.Encap = encap
+	for i := range lb.Status.Components {
+		lb.Status.Components[i].CompStatus = common.ComponentStatusPending
+	}
+	lb.ResourceVersion = generateVersion()
+
+	err = infradb.client.Set(lb.Name, lb)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	notifyLifecycle(StatusEventUpdated, "logical-bridge", lb.Name, lb.ResourceVersion)
+	taskmanager.TaskMan.CreateTask(lb.Name, "logical-bridge", lb.ResourceVersion, subscribers)
+
+	return lb, nil
+}
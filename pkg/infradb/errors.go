@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"google.golang.org/grpc/codes"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+)
+
+// init gives the errors of the store their status code and ErrorInfo reason, the handlers
+// return them as they are and apierrors.Convert turns them into status errors
+func init() {
+	for _, e := range []struct {
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{ErrKeyNotFound, codes.NotFound, apierrors.ReasonNotFound},
+		{ErrVrfNotFound, codes.NotFound, apierrors.ReasonReferenceNotFound},
+		{ErrLogicalBridgeNotFound, codes.NotFound, apierrors.ReasonReferenceNotFound},
+		{ErrBridgePortNotFound, codes.NotFound, apierrors.ReasonReferenceNotFound},
+		{ErrVrfNotEmpty, codes.FailedPrecondition, apierrors.ReasonNotEmpty},
+		{ErrLogicalBridgeNotEmpty, codes.FailedPrecondition, apierrors.ReasonNotEmpty},
+		{ErrRoutingTableInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrVniInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrRouteLeakLoop, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrRouteLeakSameVrf, codes.InvalidArgument, apierrors.ReasonInvalidArgument},
+		{ErrExternalInterfaceInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrBondMemberInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrBondInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrIPAddressInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrIPAddressOutOfSubnet, codes.InvalidArgument, apierrors.ReasonInvalidAddress},
+		{ErrIPPoolExhausted, codes.ResourceExhausted, apierrors.ReasonExhausted},
+		{ErrSviUnnumbered, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrGatewayNotExplicit, codes.InvalidArgument, apierrors.ReasonInvalidAddress},
+		{ErrSecondaryIPOverlap, codes.FailedPrecondition, apierrors.ReasonPrefixOverlap},
+		{ErrHostRouteOverlap, codes.FailedPrecondition, apierrors.ReasonPrefixOverlap},
+		{ErrHostRouteNoVni, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrIfNameExhausted, codes.ResourceExhausted, apierrors.ReasonExhausted},
+		{ErrDNSForwarderInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrDHCPServerInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrDHCPServerSubnet, codes.InvalidArgument, apierrors.ReasonInvalidArgument},
+		{ErrDHCPServerNoMac, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrRouterAdvertisementInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrRouterAdvertisementNoIPv6, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrPortSecurityInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrDHCPSnoopingInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrConntrackPolicyInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrBridgePortInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrVirtualPortInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrVirtualPortSocketInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrVfRepresentorInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrVfInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrRoutingPolicyAttached, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrRoutingPolicyNoBgp, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrSviNotFound, codes.NotFound, apierrors.ReasonReferenceNotFound},
+		{ErrSviInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrVpcPeeringSameVrf, codes.InvalidArgument, apierrors.ReasonInvalidArgument},
+		{ErrVpcPeeringExists, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrFlowLogSviVrf, codes.InvalidArgument, apierrors.ReasonInvalidArgument},
+		{ErrFlowLogMarksExhausted, codes.ResourceExhausted, apierrors.ReasonExhausted},
+		{ErrBridgePortToBeDeleted, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrBridgePortProfileInUse, codes.FailedPrecondition, apierrors.ReasonInUse},
+		{ErrBridgePortProfiled, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrLogicalBridgeToBeDeleted, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrEncapNoVni, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrEncapUnsupported, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrVrfToBeDeleted, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrVpnNoVni, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrMplsUnsupported, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrSrv6Unsupported, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrSviToBeDeleted, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrProxyArpNoIPv4, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrProxyArpGeneve, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrNetnsVpn, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrNetnsGrd, codes.FailedPrecondition, apierrors.ReasonFailedPrecondition},
+		{ErrVersionMismatch, codes.Aborted, apierrors.ReasonVersionMismatch},
+	} {
+		apierrors.Register(e.err, e.code, e.reason)
+	}
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"sync"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+// StatusEventType is the kind of transition of a status event
+type StatusEventType string
+
+const (
+	// StatusEventUp is sent when every component has programmed the object
+	StatusEventUp StatusEventType = "up"
+	// StatusEventDeleted is sent once the object has been torn down and removed
+	StatusEventDeleted StatusEventType = "deleted"
+	// StatusEventFailed is sent when a component fails to reconcile the object, the reconciliation is retried
+	StatusEventFailed StatusEventType = "failed"
+	// StatusEventCreated, StatusEventUpdated and StatusEventDeleting are the lifecycle events sent when
+	// the object is stored, before the components reconcile it
+	StatusEventCreated  StatusEventType = "created"
+	StatusEventUpdated  StatusEventType = "updated"
+	StatusEventDeleting StatusEventType = "deleting"
+	// StatusEventDuplicate is the alert sent when the routing stack flags an address of a logical bridge as
+	// duplicate, the details name the address
+	StatusEventDuplicate StatusEventType = "duplicate"
+	// StatusEventMacMoves is the alert sent when a MAC address of a logical bridge moved between the VTEPs more
+	// often than the alarm threshold, the details name the address
+	StatusEventMacMoves StatusEventType = "mac-moves"
+)
+
+// IsStatus tells whether the event is a status transition rather than a lifecycle event
+func (t StatusEventType) IsStatus() bool {
+	return t == StatusEventUp || t == StatusEventDeleted || t == StatusEventFailed
+}
+
+// IsValid tells whether the type is known
+func (t StatusEventType) IsValid() bool {
+	return t.IsStatus() || t == StatusEventCreated || t == StatusEventUpdated || t == StatusEventDeleting || t.IsAlert()
+}
+
+// IsAlert tells whether the event is an alert raised by the monitoring of the objects
+func (t StatusEventType) IsAlert() bool {
+	return t == StatusEventDuplicate || t == StatusEventMacMoves
+}
+
+// StatusEvent is a status transition or a lifecycle event of an object
+type StatusEvent struct {
+	Type StatusEventType `json:"type"`
+	// Kind is the event type of the object, e.g. vrf or logical-bridge
+	Kind            string    `json:"kind"`
+	Name            string    `json:"name"`
+	ResourceVersion string    `json:"resourceVersion"`
+	Component       string    `json:"component,omitempty"`
+	Details         string    `json:"details,omitempty"`
+	Time            time.Time `json:"time"`
+}
+
+// StatusListener is called with the status events, under the global lock of the DB so it must not block nor call the DB
+type StatusListener func(event StatusEvent)
+
+var (
+	statusListenersLock sync.RWMutex
+	statusListeners     []StatusListener
+)
+
+// OnStatusChange registers a listener of the status events
+func OnStatusChange(listener StatusListener) {
+	statusListenersLock.Lock()
+	defer statusListenersLock.Unlock()
+	statusListeners = append(statusListeners, listener)
+}
+
+// notifyStatus sends the event of a status report to the listeners. The oper statuses of all the kinds
+// share the same values, before and after are the oper status of the object around the report.
+func notifyStatus(kind, name, resourceVersion string, before, after int32, deleted bool, component common.Component) {
+	event := StatusEvent{Kind: kind, Name: name, ResourceVersion: resourceVersion, Component: component.Name, Time: time.Now().UTC()}
+	switch {
+	case component.CompStatus == common.ComponentStatusError:
+		event.Type, event.Details = StatusEventFailed, component.Details
+	case deleted:
+		event.Type, event.Component = StatusEventDeleted, ""
+	case after == int32(OperStatusUp) && before != int32(OperStatusUp):
+		event.Type, event.Component = StatusEventUp, ""
+	default:
+		return
+	}
+	dispatchStatus(event)
+}
+
+// notifyLifecycle sends the lifecycle event of an object to the listeners
+func notifyLifecycle(eventType StatusEventType, kind, name, resourceVersion string) {
+	dispatchStatus(StatusEvent{Type: eventType, Kind: kind, Name: name, ResourceVersion: resourceVersion, Time: time.Now().UTC()})
+}
+
+// NotifyAlert sends an alert on the object to the listeners
+func NotifyAlert(eventType StatusEventType, kind, name, resourceVersion, details string) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	dispatchStatus(StatusEvent{Type: eventType, Kind: kind, Name: name, ResourceVersion: resourceVersion, Details: details, Time: time.Now().UTC()})
+}
+
+// dispatchStatus calls the listeners with the event
+func dispatchStatus(event StatusEvent) {
+	statusListenersLock.RLock()
+	defer statusListenersLock.RUnlock()
+	for _, listener := range statusListeners {
+		listener(event)
+	}
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+func Test_NotifyStatus(t *testing.T) {
+	received := []StatusEventType{}
+	OnStatusChange(func(event StatusEvent) {
+		if event.Name == "//network.opiproject.org/vrfs/events" {
+			received = append(received, event.Type)
+		}
+	})
+	name := "//network.opiproject.org/vrfs/events"
+	success := common.Component{Name: "frr", CompStatus: common.ComponentStatusSuccess}
+	failure := common.Component{Name: "frr", CompStatus: common.ComponentStatusError, Details: "vtysh failed"}
+
+	notifyLifecycle(StatusEventCreated, "vrf", name, "1")
+	// a component of several succeeds, another fails, the last one brings the object up, it is then deleted
+	notifyStatus("vrf", name, "1", int32(OperStatusDown), int32(OperStatusDown), false, success)
+	notifyStatus("vrf", name, "1", int32(OperStatusDown), int32(OperStatusDown), false, failure)
+	notifyStatus("vrf", name, "1", int32(OperStatusDown), int32(OperStatusUp), false, success)
+	notifyStatus("vrf", name, "1", int32(OperStatusUp), int32(OperStatusUp), false, success)
+	notifyLifecycle(StatusEventDeleting, "vrf", name, "2")
+	notifyStatus("vrf", name, "2", int32(OperStatusToBeDeleted), int32(OperStatusToBeDeleted), true, success)
+
+	expected := []StatusEventType{StatusEventCreated, StatusEventFailed, StatusEventUp, StatusEventDeleting, StatusEventDeleted}
+	if len(received) != len(expected) {
+		t.Fatalf("expected the events %v, received %v", expected, received)
+	}
+	for i := range expected {
+		if received[i] != expected[i] {
+			t.Errorf("expected the events %v, received %v", expected, received)
+		}
+	}
+}
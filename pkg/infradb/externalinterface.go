@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+// ErrExternalInterfaceInUse the upstream interface is already attached
+var ErrExternalInterfaceInUse = errors.New("the upstream interface and vlan are already attached to a VRF")
+
+// ExternalBgpPeer holds the BGP peering towards the external network
+type ExternalBgpPeer struct {
+	PeerIP   net.IP
+	RemoteAS uint32
+}
+
+// ExternalInterfaceSpec holds External Interface Spec
+type ExternalInterfaceSpec struct {
+	Vrf string
+	// Interface is the linux device towards the upstream router
+	Interface string
+	// VlanID is the vlan on top of the interface, zero means untagged
+	VlanID  uint16
+	Address *net.IPNet
+	// Gateway is the upstream router, when set a default route is injected into the VRF
+	Gateway net.IP
+	// BgpPeer is the optional BGP peering with the upstream router
+	BgpPeer *ExternalBgpPeer
+}
+
+// ExternalInterface holds External Interface info
+type ExternalInterface struct {
+	Resource
+	Spec *ExternalInterfaceSpec
+}
+
+// LinkName returns the linux device that is attached to the VRF
+func (in *ExternalInterfaceSpec) LinkName() string {
+	if in.VlanID == 0 {
+		return in.Interface
+	}
+	return fmt.Sprintf("%s.%d", in.Interface, in.VlanID)
+}
+
+// externalInterfaceKind describes the storage of the External Interface objects
+var externalInterfaceKind = registerKind(resourceKind{
+	eventType: "external-interface",
+	indexKey:  "externalinterfaces",
+	newObject: func() resourceObject { return &ExternalInterface{} },
+	references: func(obj resourceObject) []string {
+		return []string{obj.(*ExternalInterface).Spec.Vrf}
+	},
+})
+
+// validate checks the External Interface Spec
+func (in *ExternalInterfaceSpec) validate() error {
+	if in.Vrf == "" || in.Interface == "" || in.Address == nil {
+		return fmt.Errorf("External Interface needs a VRF, an interface and an address")
+	}
+	if in.VlanID > 4094 {
+		return fmt.Errorf("External Interface vlan %d is out of range", in.VlanID)
+	}
+	isV4 := in.Address.IP.To4() != nil
+	if in.Gateway != nil {
+		if (in.Gateway.To4() != nil) != isV4 || !in.Address.Contains(in.Gateway) {
+			return fmt.Errorf("External Interface gateway %s is not part of %s", in.Gateway, in.Address)
+		}
+	}
+	if in.BgpPeer != nil {
+		if in.BgpPeer.PeerIP == nil || in.BgpPeer.RemoteAS == 0 {
+			return fmt.Errorf("External Interface BGP peer needs a peer IP and a remote AS")
+		}
+		if !in.Address.Contains(in.BgpPeer.PeerIP) {
+			return fmt.Errorf("External Interface BGP peer %s is not part of %s", in.BgpPeer.PeerIP, in.Address)
+		}
+	}
+	return nil
+}
+
+// NewExternalInterface creates new External Interface object
+func NewExternalInterface(name string, spec *ExternalInterfaceSpec) (*ExternalInterface, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewExternalInterface(): External Interface spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("NewExternalInterface(): %v", err)
+	}
+
+	res, err := newResource(name, externalInterfaceKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExternalInterface{Resource: res, Spec: spec}, nil
+}
+
+// getAllExternalInterfaces returns all the external interfaces, the caller must hold the global lock
+func getAllExternalInterfaces() ([]*ExternalInterface, error) {
+	eifs := []*ExternalInterface{}
+	names, err := externalInterfaceKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		eif := &ExternalInterface{}
+		if err := externalInterfaceKind.get(name, eif); err != nil {
+			log.Printf("getAllExternalInterfaces(): Failed to get the External Interface %s from store: %v", name, err)
+			return nil, err
+		}
+		eifs = append(eifs, eif)
+	}
+	return eifs, nil
+}
+
+// CreateExternalInterface creates an infradb external interface object
+func CreateExternalInterface(eif *ExternalInterface) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	if err := checkVrfExists(eif.Spec.Vrf); err != nil {
+		return err
+	}
+
+	eifs, err := getAllExternalInterfaces()
+	if err != nil {
+		return err
+	}
+	for _, existing := range eifs {
+		if existing.Spec.Interface == eif.Spec.Interface && existing.Spec.VlanID == eif.Spec.VlanID {
+			log.Printf("CreateExternalInterface(): %s vlan %d is already attached by %s\n", eif.Spec.Interface, eif.Spec.VlanID, existing.Name)
+			return ErrExternalInterfaceInUse
+		}
+	}
+
+	return externalInterfaceKind.create(eif)
+}
+
+// DeleteExternalInterface deletes an external interface infradb object
+func DeleteExternalInterface(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	eif := &ExternalInterface{}
+	if err := externalInterfaceKind.get(name, eif); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, eif.ResourceVersion, opts); err != nil {
+		return err
+	}
+	return externalInterfaceKind.delete(eif)
+}
+
+// GetExternalInterface returns an infradb external interface object
+func GetExternalInterface(name string) (*ExternalInterface, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	eif := &ExternalInterface{}
+	err := externalInterfaceKind.get(name, eif)
+	return eif, err
+}
+
+// GetAllExternalInterfaces returns a list of external interfaces from the DB
+func GetAllExternalInterfaces() ([]*ExternalInterface, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllExternalInterfaces()
+}
+
+// UpdateExternalInterfaceStatus updates the status of external interface object based on the component report
+func UpdateExternalInterfaceStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return externalInterfaceKind.updateStatus(&ExternalInterface{}, name, resourceVersion, notificationID, component)
+}
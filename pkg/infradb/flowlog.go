@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+var (
+	// ErrFlowLogSviVrf the SVI of the flow log is not in its VRF
+	ErrFlowLogSviVrf = errors.New("the SVI of the flow log is not in its VRF")
+	// ErrFlowLogMarksExhausted all the connection marks of the flow logs are taken
+	ErrFlowLogMarksExhausted = errors.New("no connection mark left for the flow log")
+)
+
+const (
+	// FlowLogMarkBase is the first connection mark of the flow logs, each one marks its sampled flows with its own
+	FlowLogMarkBase = 0x464c0000
+	// maxFlowLogs is the number of connection marks of the flow logs
+	maxFlowLogs = 0x10000
+
+	defaultFlowLogActiveTimeout = 60 * time.Second
+	defaultFlowLogIdleTimeout   = 15 * time.Second
+)
+
+// FlowLogSpec holds Flow Log Spec
+type FlowLogSpec struct {
+	// Vrf is the VPC whose flows are logged
+	Vrf string
+	// Svi restricts the log to the flows entering the subnet of the SVI, all the flows of the VRF when empty
+	Svi string
+	// Collector is the host:port of the IPFIX collector, reached over udp
+	Collector string
+	// SamplingRate logs one in SamplingRate new flows, all of them when 0 or 1
+	SamplingRate uint32
+	// ActiveTimeout is how often the records of a long lived flow are exported
+	ActiveTimeout time.Duration
+	// IdleTimeout is how long a flow stays without traffic before its record is exported
+	IdleTimeout time.Duration
+}
+
+// FlowLog holds Flow Log info
+type FlowLog struct {
+	Resource
+	Spec *FlowLogSpec
+	// Mark is the connection mark of the sampled flows, given at creation
+	Mark uint32
+}
+
+// flowLogKind describes the storage of the Flow Log objects
+var flowLogKind = registerKind(resourceKind{
+	eventType: "flow-log",
+	indexKey:  "flowlogs",
+	newObject: func() resourceObject { return &FlowLog{} },
+	references: func(obj resourceObject) []string {
+		fl := obj.(*FlowLog)
+		if fl.Spec.Svi == "" {
+			return []string{fl.Spec.Vrf}
+		}
+		return []string{fl.Spec.Vrf, fl.Spec.Svi}
+	},
+})
+
+// validate checks the Flow Log Spec and sets the default timeouts
+func (in *FlowLogSpec) validate() error {
+	if in.Vrf == "" || in.Collector == "" {
+		return fmt.Errorf("Flow Log needs a VRF and a collector")
+	}
+	host, port, err := net.SplitHostPort(in.Collector)
+	if err != nil || host == "" || port == "" {
+		return fmt.Errorf("Flow Log collector %s is not a host:port", in.Collector)
+	}
+	if in.ActiveTimeout < 0 || in.IdleTimeout < 0 {
+		return fmt.Errorf("Flow Log timeouts cannot be negative")
+	}
+	if in.ActiveTimeout == 0 {
+		in.ActiveTimeout = defaultFlowLogActiveTimeout
+	}
+	if in.IdleTimeout == 0 {
+		in.IdleTimeout = defaultFlowLogIdleTimeout
+	}
+	if in.IdleTimeout > in.ActiveTimeout {
+		return fmt.Errorf("Flow Log idle timeout %v is longer than the active timeout %v", in.IdleTimeout, in.ActiveTimeout)
+	}
+	return nil
+}
+
+// NewFlowLog creates new Flow Log object
+func NewFlowLog(name string, spec *FlowLogSpec) (*FlowLog, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewFlowLog(): Flow Log spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("NewFlowLog(): %v", err)
+	}
+
+	res, err := newResource(name, flowLogKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FlowLog{Resource: res, Spec: spec}, nil
+}
+
+// getAllFlowLogs returns all the flow logs, the caller must hold the global lock
+func getAllFlowLogs() ([]*FlowLog, error) {
+	fls := []*FlowLog{}
+	names, err := flowLogKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		fl := &FlowLog{}
+		if err := flowLogKind.get(name, fl); err != nil {
+			log.Printf("getAllFlowLogs(): Failed to get the Flow Log %s from store: %v", name, err)
+			return nil, err
+		}
+		fls = append(fls, fl)
+	}
+	return fls, nil
+}
+
+// allocateFlowLogMark returns the first connection mark which no flow log uses, the caller must hold the global lock
+func allocateFlowLogMark() (uint32, error) {
+	fls, err := getAllFlowLogs()
+	if err != nil {
+		return 0, err
+	}
+	used := make(map[uint32]bool, len(fls))
+	for _, fl := range fls {
+		used[fl.Mark] = true
+	}
+	for mark := uint32(FlowLogMarkBase + 1); mark < FlowLogMarkBase+maxFlowLogs; mark++ {
+		if !used[mark] {
+			return mark, nil
+		}
+	}
+	return 0, ErrFlowLogMarksExhausted
+}
+
+// CreateFlowLog creates an infradb flow log object
+func CreateFlowLog(fl *FlowLog) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	if err := checkVrfExists(fl.Spec.Vrf); err != nil {
+		return err
+	}
+	if fl.Spec.Svi != "" {
+		svi := Svi{}
+		found, err := infradb.client.Get(fl.Spec.Svi, &svi)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrSviNotFound
+		}
+		if svi.Spec.Vrf != fl.Spec.Vrf {
+			return ErrFlowLogSviVrf
+		}
+	}
+
+	mark, err := allocateFlowLogMark()
+	if err != nil {
+		return err
+	}
+	fl.Mark = mark
+	return flowLogKind.create(fl)
+}
+
+// DeleteFlowLog deletes a flow log infradb object
+func DeleteFlowLog(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	fl := &FlowLog{}
+	if err := flowLogKind.get(name, fl); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, fl.ResourceVersion, opts); err != nil {
+		return err
+	}
+	return flowLogKind.delete(fl)
+}
+
+// GetFlowLog returns an infradb flow log object
+func GetFlowLog(name string) (*FlowLog, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	fl := &FlowLog{}
+	err := flowLogKind.get(name, fl)
+	return fl, err
+}
+
+// GetAllFlowLogs returns a list of flow logs from the DB
+func GetAllFlowLogs() ([]*FlowLog, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllFlowLogs()
+}
+
+// UpdateFlowLogStatus updates the status of flow log object based on the component report
+func UpdateFlowLogStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return flowLogKind.updateStatus(&FlowLog{}, name, resourceVersion, notificationID, component)
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+const (
+	// GatewayPolicyFirst picks the first address of the subnet, e.g. 10.0.10.1 of 10.0.10.0/24
+	GatewayPolicyFirst = "first"
+	// GatewayPolicyLast picks the last address of the subnet, e.g. 10.0.10.254 of 10.0.10.0/24
+	GatewayPolicyLast = "last"
+	// GatewayPolicyExplicit requires the gateway address to be given
+	GatewayPolicyExplicit = "explicit"
+)
+
+// ErrGatewayNotExplicit the gateway prefix of the SVI is a network address while the policy requires the address
+var ErrGatewayNotExplicit = errors.New("the gateway address of the SVI must be explicit")
+
+// GatewayPolicy returns the configured gateway address policy, first when it is not configured
+func GatewayPolicy() string {
+	if policy := config.GlobalConfig.Gateway.Policy; policy != "" {
+		return policy
+	}
+	return GatewayPolicyFirst
+}
+
+// isNetworkAddress tells whether the prefix is given by its network address, the /31, /32, /127 and /128 prefixes
+// excepted as their addresses are all usable
+func isNetworkAddress(prefix *net.IPNet) bool {
+	ones, bits := prefix.Mask.Size()
+	return bits-ones > 1 && prefix.IP.Equal(prefix.IP.Mask(prefix.Mask))
+}
+
+// resolveGateway returns the gateway address of the prefix by the policy, a prefix which is not given by its
+// network address is returned as it is
+func resolveGateway(prefix *net.IPNet, policy string) (*net.IPNet, error) {
+	if !isNetworkAddress(prefix) {
+		return prefix, nil
+	}
+	ip := prefix.IP.Mask(prefix.Mask)
+	switch policy {
+	case GatewayPolicyFirst:
+		ip[len(ip)-1]++
+	case GatewayPolicyLast:
+		for i := range ip {
+			ip[i] |= ^prefix.Mask[i]
+		}
+		// the last address of an IPv4 subnet is its broadcast address
+		if len(prefix.Mask) == net.IPv4len {
+			ip[len(ip)-1]--
+		}
+	default:
+		return nil, fmt.Errorf("gateway prefix %s: %w", prefix, ErrGatewayNotExplicit)
+	}
+	return &net.IPNet{IP: ip, Mask: prefix.Mask}, nil
+}
+
+// resolveGateways returns the gateway addresses of the prefixes by the policy
+func resolveGateways(prefixes []*net.IPNet, policy string) ([]*net.IPNet, error) {
+	out := make([]*net.IPNet, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		gw, err := resolveGateway(prefix, policy)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, gw)
+	}
+	return out, nil
+}
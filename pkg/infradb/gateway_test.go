@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func Test_ResolveGateway(t *testing.T) {
+	tests := map[string]struct {
+		prefix  string
+		policy  string
+		gateway string
+		err     error
+	}{
+		"first address": {
+			prefix:  "10.0.10.0/24",
+			policy:  GatewayPolicyFirst,
+			gateway: "10.0.10.1/24",
+		},
+		"last address": {
+			prefix:  "10.0.10.0/24",
+			policy:  GatewayPolicyLast,
+			gateway: "10.0.10.254/24",
+		},
+		"last address of a small subnet": {
+			prefix:  "10.0.10.128/26",
+			policy:  GatewayPolicyLast,
+			gateway: "10.0.10.190/26",
+		},
+		"last IPv6 address": {
+			prefix:  "2001:db8:10::/64",
+			policy:  GatewayPolicyLast,
+			gateway: "2001:db8:10:0:ffff:ffff:ffff:ffff/64",
+		},
+		"explicit address kept": {
+			prefix:  "10.0.10.100/24",
+			policy:  GatewayPolicyExplicit,
+			gateway: "10.0.10.100/24",
+		},
+		"network address with explicit policy": {
+			prefix: "10.0.10.0/24",
+			policy: GatewayPolicyExplicit,
+			err:    ErrGatewayNotExplicit,
+		},
+		"point to point prefix": {
+			prefix:  "10.0.10.0/31",
+			policy:  GatewayPolicyFirst,
+			gateway: "10.0.10.0/31",
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ip, prefix, err := net.ParseCIDR(tt.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ip.To4() != nil {
+				ip = ip.To4()
+			}
+			prefix.IP = ip
+			gw, err := resolveGateway(prefix, tt.policy)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, received %v", tt.err, err)
+			}
+			if tt.err == nil && gw.String() != tt.gateway {
+				t.Errorf("expected the gateway %s, received %s", tt.gateway, gw)
+			}
+		})
+	}
+}
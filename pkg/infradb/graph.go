@@ -0,0 +1,281 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"sort"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+const (
+	// GraphKindNetdev is the kind of the kernel devices in the dependency graph
+	GraphKindNetdev = "netdev"
+	// GraphRelationReferences links a resource to a resource it depends on
+	GraphRelationReferences = "references"
+	// GraphRelationProgrammedAs links a resource to a kernel device programmed for it
+	GraphRelationProgrammedAs = "programmed-as"
+
+	// netdevNodePrefix keeps the kernel devices apart from the resource names in the node ids
+	netdevNodePrefix = "netdev:"
+)
+
+// GraphNode is a resource, or a kernel device, of the dependency graph
+type GraphNode struct {
+	// ID is the name of the resource, or netdev:<name> for a kernel device
+	ID string
+	// Kind is the event type of the resource, or netdev
+	Kind string
+	// Up tells whether the resource is operationally up, it is false for the kernel devices
+	Up bool
+}
+
+// GraphEdge goes from a resource to what it depends on or is programmed as
+type GraphEdge struct {
+	From     string
+	To       string
+	Relation string
+}
+
+// DependencyGraph holds the references between the resources and the kernel devices they map to
+type DependencyGraph struct {
+	Nodes []*GraphNode
+	Edges []*GraphEdge
+}
+
+// graphBuilder collects the nodes and the edges of the graph, once each
+type graphBuilder struct {
+	nodes map[string]*GraphNode
+	edges map[GraphEdge]bool
+}
+
+func (b *graphBuilder) node(id, kind string, up bool) {
+	if _, ok := b.nodes[id]; !ok {
+		b.nodes[id] = &GraphNode{ID: id, Kind: kind, Up: up}
+	}
+}
+
+func (b *graphBuilder) reference(from, to string) {
+	if to != "" {
+		b.edges[GraphEdge{From: from, To: to, Relation: GraphRelationReferences}] = true
+	}
+}
+
+func (b *graphBuilder) netdev(from, dev string) {
+	id := netdevNodePrefix + dev
+	b.node(id, GraphKindNetdev, false)
+	b.edges[GraphEdge{From: from, To: id, Relation: GraphRelationProgrammedAs}] = true
+}
+
+// graph returns the nodes and edges sorted, the referenced resources which are not stored are left out
+func (b *graphBuilder) graph() *DependencyGraph {
+	g := &DependencyGraph{}
+	for _, n := range b.nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	for e := range b.edges {
+		if _, ok := b.nodes[e.To]; !ok {
+			continue
+		}
+		edge := e
+		g.Edges = append(g.Edges, &edge)
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	return g
+}
+
+// addOpiObjects adds the VRFs, Logical Bridges, SVIs and Bridge Ports with their kernel devices, the caller
+// must hold the global lock
+//
+// nolint: funlen
+func (b *graphBuilder) addOpiObjects(ifNames *ifNameTable) error {
+	names, err := storedNames("vrfs")
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		vrf := &Vrf{}
+		if _, err := infradb.client.Get(name, vrf); err != nil {
+			return err
+		}
+		b.node(vrf.Name, "vrf", vrf.Status.VrfOperStatus == VrfOperStatusUp)
+		// The GRD is the default vrf of the host
+		if path.Base(vrf.Name) == "GRD" {
+			continue
+		}
+		b.netdev(vrf.Name, ifNames.name(vrf.Name, LinkRoleVrf, 0))
+		if vrf.Spec.Vni != nil {
+			b.netdev(vrf.Name, ifNames.name(vrf.Name, LinkRoleBridge, 0))
+			if !vrf.Spec.IsVpn() {
+				b.netdev(vrf.Name, ifNames.name(vrf.Name, LinkRoleVxlan, 0))
+			}
+		}
+	}
+
+	topology, err := utils.NewBridgeTopology(config.GlobalConfig.LinuxFrr.BridgeTopology, nil, 0)
+	if err != nil {
+		return err
+	}
+	lbVlans := map[string]uint32{}
+	if names, err = storedNames("lbs"); err != nil {
+		return err
+	}
+	for _, name := range names {
+		lb := &LogicalBridge{}
+		if _, err := infradb.client.Get(name, lb); err != nil {
+			return err
+		}
+		lbVlans[lb.Name] = lb.Spec.VlanID
+		b.node(lb.Name, "logical-bridge", lb.Status.LBOperStatus == LogicalBridgeOperStatusUp)
+		b.netdev(lb.Name, topology.BridgeName(uint16(lb.Spec.VlanID)))
+		switch {
+		case lb.Spec.IsGeneve():
+			b.netdev(lb.Name, GeneveDevice)
+		case lb.Spec.Vni != nil:
+			b.netdev(lb.Name, fmt.Sprintf("vxlan-%d", lb.Spec.VlanID))
+		}
+	}
+
+	if names, err = storedNames("svis"); err != nil {
+		return err
+	}
+	for _, name := range names {
+		svi := &Svi{}
+		if _, err := infradb.client.Get(name, svi); err != nil {
+			return err
+		}
+		b.node(svi.Name, "svi", svi.Status.SviOperStatus == SviOperStatusUp)
+		b.reference(svi.Name, svi.Spec.Vrf)
+		b.reference(svi.Name, svi.Spec.LogicalBridge)
+		if vlan, ok := lbVlans[svi.Spec.LogicalBridge]; ok {
+			if dev, ok := ifNames.Names[ownerKey(svi.Name, LinkRoleSvi)]; ok {
+				b.netdev(svi.Name, dev)
+			} else {
+				b.netdev(svi.Name, fmt.Sprintf("%s-%d", ifNames.name(svi.Spec.Vrf, LinkRoleVrf, 0), vlan))
+			}
+		}
+	}
+
+	if names, err = storedNames("bps"); err != nil {
+		return err
+	}
+	for _, name := range names {
+		bp := &BridgePort{}
+		if _, err := infradb.client.Get(name, bp); err != nil {
+			return err
+		}
+		b.node(bp.Name, "bridge-port", bp.Status.BPOperStatus == BridgePortOperStatusUp)
+		for _, lb := range bp.Spec.LogicalBridges {
+			b.reference(bp.Name, lb)
+		}
+		b.netdev(bp.Name, path.Base(bp.Name))
+	}
+	return nil
+}
+
+// addResources adds the resources of the registered kinds with their references, the caller must hold the
+// global lock
+func (b *graphBuilder) addResources() error {
+	for _, kind := range resourceKinds {
+		names, err := kind.names()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			obj := kind.newObject()
+			if err := kind.get(name, obj); err != nil {
+				return err
+			}
+			b.node(name, kind.eventType, obj.base().Status.OperStatus == OperStatusUp)
+			if kind.references == nil {
+				continue
+			}
+			for _, ref := range kind.references(obj) {
+				b.reference(name, ref)
+			}
+		}
+	}
+	return nil
+}
+
+// GetDependencyGraph returns the graph of all the resources, their references and their kernel devices
+func GetDependencyGraph() (*DependencyGraph, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	ifNames, err := getIfNames()
+	if err != nil {
+		return nil, err
+	}
+	b := &graphBuilder{nodes: map[string]*GraphNode{}, edges: map[GraphEdge]bool{}}
+	if err := b.addOpiObjects(ifNames); err != nil {
+		log.Printf("GetDependencyGraph(): %v", err)
+		return nil, err
+	}
+	if err := b.addResources(); err != nil {
+		log.Printf("GetDependencyGraph(): %v", err)
+		return nil, err
+	}
+	return b.graph(), nil
+}
+
+// Dependents returns the part of the graph which goes with the resource: the resource and everything which
+// references it, directly or not, with their kernel devices. It is what breaks when the resource is deleted.
+func (g *DependencyGraph) Dependents(name string) (*DependencyGraph, error) {
+	kept := map[string]bool{}
+	for _, n := range g.Nodes {
+		if n.ID == name && n.Kind != GraphKindNetdev {
+			kept[name] = true
+		}
+	}
+	if !kept[name] {
+		return nil, ErrKeyNotFound
+	}
+	for grown := true; grown; {
+		grown = false
+		for _, e := range g.Edges {
+			if e.Relation == GraphRelationReferences && kept[e.To] && !kept[e.From] {
+				kept[e.From] = true
+				grown = true
+			}
+		}
+	}
+	sub := &DependencyGraph{}
+	for _, e := range g.Edges {
+		if kept[e.From] && e.Relation == GraphRelationProgrammedAs {
+			kept[e.To] = true
+		}
+	}
+	for _, n := range g.Nodes {
+		if kept[n.ID] {
+			sub.Nodes = append(sub.Nodes, n)
+		}
+	}
+	for _, e := range g.Edges {
+		if kept[e.From] && kept[e.To] {
+			sub.Edges = append(sub.Edges, e)
+		}
+	}
+	return sub, nil
+}
+
+// GetDependents returns the resource with everything which depends on it and their kernel devices
+func GetDependents(name string) (*DependencyGraph, error) {
+	graph, err := GetDependencyGraph()
+	if err != nil {
+		return nil, err
+	}
+	return graph.Dependents(name)
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"log"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+// historyKey is the key of the revisions of the resources in the store
+var historyKey = registerStoreKey("history")
+
+// defaultMaxRevisions bounds the history when no bound is configured
+const defaultMaxRevisions = 1000
+
+// Revision is the state of a resource after a change, the resource is gone when it has no object
+type Revision struct {
+	// Number orders the revisions of all the resources, it is never reused
+	Number uint64
+	// Name is the full name of the resource, Kind its kind, e.g. "Vrf"
+	Name string
+	Kind string
+	// Action is the change, "create", "update" or "delete"
+	Action string
+	Time   time.Time
+	// Object is the resource in its protojson form, empty when the change deleted it
+	Object []byte
+}
+
+// history is the bounded list of the revisions, oldest first
+type history struct {
+	Next      uint64
+	Revisions []*Revision
+}
+
+// loadHistory returns the history, the caller must hold the global lock
+func loadHistory() (*history, error) {
+	h := &history{Next: 1}
+	if _, err := infradb.client.Get(historyKey, h); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return h, nil
+}
+
+// AddRevision numbers the revision and adds it to the history, dropping the oldest revisions
+// beyond history.maxrevisions
+func AddRevision(rev *Revision) (*Revision, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	h, err := loadHistory()
+	if err != nil {
+		return nil, err
+	}
+	rev.Number = h.Next
+	h.Next++
+	h.Revisions = append(h.Revisions, rev)
+	maxRevisions := config.GlobalConfig.History.MaxRevisions
+	if maxRevisions <= 0 {
+		maxRevisions = defaultMaxRevisions
+	}
+	if extra := len(h.Revisions) - maxRevisions; extra > 0 {
+		h.Revisions = append([]*Revision{}, h.Revisions[extra:]...)
+	}
+	if err := infradb.client.Set(historyKey, h); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// GetRevisions returns the revisions of the resource, or of all the resources when the name is empty, newest first
+func GetRevisions(name string) ([]*Revision, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	h, err := loadHistory()
+	if err != nil {
+		return nil, err
+	}
+	out := []*Revision{}
+	for i := len(h.Revisions) - 1; i >= 0; i-- {
+		if name == "" || h.Revisions[i].Name == name {
+			out = append(out, h.Revisions[i])
+		}
+	}
+	return out, nil
+}
+
+// GetRevision returns the revision of the number
+func GetRevision(number uint64) (*Revision, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	h, err := loadHistory()
+	if err != nil {
+		return nil, err
+	}
+	for _, rev := range h.Revisions {
+		if rev.Number == number {
+			return rev, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// GetStateAt returns, for every resource of the history, its latest revision up to the revision number.
+// A resource created after the revision has a revision without object as it did not exist yet, a resource
+// whose revisions up to the number have been dropped from the history is left out as its state is unknown.
+func GetStateAt(number uint64) (map[string]*Revision, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	h, err := loadHistory()
+	if err != nil {
+		return nil, err
+	}
+	state := map[string]*Revision{}
+	seen := map[string]bool{}
+	for _, rev := range h.Revisions {
+		first := !seen[rev.Name]
+		seen[rev.Name] = true
+		switch {
+		case rev.Number <= number:
+			state[rev.Name] = rev
+		case first && rev.Action == "create":
+			state[rev.Name] = &Revision{Name: rev.Name, Kind: rev.Kind}
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"path"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+var (
+	// ErrHostRouteOverlap the host route is already an address of the VRF
+	ErrHostRouteOverlap = errors.New("the host route overlaps with an address of the VRF")
+	// ErrHostRouteNoVni the vrf has no VNI to advertise the host routes with
+	ErrHostRouteNoVni = errors.New("the vrf has no VNI to advertise the host routes with")
+)
+
+// HostRouteSpec holds Host Route Spec
+type HostRouteSpec struct {
+	// Vrf is the VRF of the VPC that the host routes are advertised into
+	Vrf string
+	// Prefixes are the IPv4 host routes, they are configured on the VRF device like its loopback
+	// and advertised as EVPN type-5 routes
+	Prefixes []*net.IPNet
+	// Communities are set on the advertised routes
+	Communities []string
+}
+
+// HostRoute holds Host Route info
+type HostRoute struct {
+	Resource
+	Spec *HostRouteSpec
+}
+
+// hostRouteKind describes the storage of the Host Route objects
+var hostRouteKind = registerKind(resourceKind{
+	eventType: "host-route",
+	indexKey:  "hostroutes",
+	newObject: func() resourceObject { return &HostRoute{} },
+	references: func(obj resourceObject) []string {
+		return []string{obj.(*HostRoute).Spec.Vrf}
+	},
+})
+
+// validate checks the Host Route Spec
+func (in *HostRouteSpec) validate() error {
+	if in.Vrf == "" || len(in.Prefixes) == 0 {
+		return fmt.Errorf("host route needs a VRF and prefixes")
+	}
+	for i, prefix := range in.Prefixes {
+		if prefix.IP.To4() == nil || !isHostPrefix(prefix) {
+			return fmt.Errorf("host route %s has to be an IPv4 /32 prefix", prefix)
+		}
+		if prefix.IP.IsUnspecified() || prefix.IP.IsMulticast() || prefix.IP.IsLoopback() || prefix.IP.IsLinkLocalUnicast() {
+			return fmt.Errorf("host route %s is not a unicast address", prefix)
+		}
+		for _, other := range in.Prefixes[:i] {
+			if other.IP.Equal(prefix.IP) {
+				return fmt.Errorf("host route %s is duplicated", prefix)
+			}
+		}
+	}
+	for _, community := range in.Communities {
+		if !communityRegexp.MatchString(community) {
+			return fmt.Errorf("host route community %q is not valid", community)
+		}
+	}
+	return nil
+}
+
+// NewHostRoute creates new Host Route object
+func NewHostRoute(name string, spec *HostRouteSpec) (*HostRoute, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewHostRoute(): Host Route spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+
+	res, err := newResource(name, hostRouteKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HostRoute{Resource: res, Spec: spec}, nil
+}
+
+// getAllHostRoutes returns all the host routes, the caller must hold the global lock
+func getAllHostRoutes() ([]*HostRoute, error) {
+	hrs := []*HostRoute{}
+	names, err := hostRouteKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		hr := &HostRoute{}
+		if err := hostRouteKind.get(name, hr); err != nil {
+			log.Printf("getAllHostRoutes(): Failed to get the Host Route %s from store: %v", name, err)
+			return nil, err
+		}
+		hrs = append(hrs, hr)
+	}
+	return hrs, nil
+}
+
+// vrfHostRoutes returns the prefixes of the host routes of the vrf, except the ones of the named host route,
+// the caller must hold the global lock
+func vrfHostRoutes(vrf string, except string) ([]*net.IPNet, error) {
+	hrs, err := getAllHostRoutes()
+	if err != nil {
+		return nil, err
+	}
+	prefixes := []*net.IPNet{}
+	for _, hr := range hrs {
+		if hr.Name == except || hr.Spec.Vrf != vrf || hr.Status.OperStatus == OperStatusToBeDeleted {
+			continue
+		}
+		prefixes = append(prefixes, hr.Spec.Prefixes...)
+	}
+	return prefixes, nil
+}
+
+// checkHostRoute checks that the vrf of the host route has a VNI and that the host routes are none of
+// the addresses of the vrf: its loopback, the addresses of its SVIs and the other host routes,
+// the caller must hold the global lock
+func checkHostRoute(hr *HostRoute) error {
+	vrf := &Vrf{}
+	found, err := infradb.client.Get(hr.Spec.Vrf, vrf)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrVrfNotFound
+	}
+	if vrf.Spec.Vni == nil || path.Base(vrf.Name) == "GRD" {
+		return ErrHostRouteNoVni
+	}
+	addresses, err := vrfHostRoutes(vrf.Name, hr.Name)
+	if err != nil {
+		return err
+	}
+	if vrf.Spec.LoopbackIP != nil {
+		addresses = append(addresses, vrf.Spec.LoopbackIP)
+	}
+	for name := range vrf.Svis {
+		svi := &Svi{}
+		found, err := infradb.client.Get(name, svi)
+		if err != nil {
+			return err
+		}
+		if found {
+			addresses = append(addresses, svi.Spec.Addresses()...)
+		}
+	}
+	if prefixesOverlap(hr.Spec.Prefixes, addresses) {
+		log.Printf("checkHostRoute(): The host routes of %s overlap with the addresses of %s\n", hr.Name, vrf.Name)
+		return ErrHostRouteOverlap
+	}
+	return nil
+}
+
+// CreateHostRoute creates an infradb host route object
+func CreateHostRoute(hr *HostRoute) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	if err := checkHostRoute(hr); err != nil {
+		return err
+	}
+	return hostRouteKind.create(hr)
+}
+
+// UpdateHostRoute replaces the spec of a host route, the subscribers withdraw the routes it no longer has
+// and advertise the new ones with the new communities
+func UpdateHostRoute(hr *HostRoute, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	existing := &HostRoute{}
+	if err := hostRouteKind.get(hr.Name, existing); err != nil {
+		return err
+	}
+	if err := checkIfMatch(hr.Name, existing.ResourceVersion, opts); err != nil {
+		return err
+	}
+	if existing.Status.OperStatus == OperStatusToBeDeleted {
+		return ErrKeyNotFound
+	}
+	if existing.Spec.Vrf != hr.Spec.Vrf {
+		return status.Errorf(codes.InvalidArgument, "UpdateHostRoute(): the VRF of a host route cannot be changed")
+	}
+	if err := checkHostRoute(hr); err != nil {
+		return err
+	}
+	existing.Spec = hr.Spec
+	if err := hostRouteKind.update(existing); err != nil {
+		return err
+	}
+	*hr = *existing
+	return nil
+}
+
+// DeleteHostRoute deletes a host route infradb object
+func DeleteHostRoute(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	hr := &HostRoute{}
+	if err := hostRouteKind.get(name, hr); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, hr.ResourceVersion, opts); err != nil {
+		return err
+	}
+	return hostRouteKind.delete(hr)
+}
+
+// GetHostRoute returns an infradb host route object
+func GetHostRoute(name string) (*HostRoute, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	hr := &HostRoute{}
+	err := hostRouteKind.get(name, hr)
+	return hr, err
+}
+
+// GetAllHostRoutes returns a list of host routes from the DB
+func GetAllHostRoutes() ([]*HostRoute, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllHostRoutes()
+}
+
+// UpdateHostRouteStatus updates the status of host route object based on the component report
+func UpdateHostRouteStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return hostRouteKind.updateStatus(&HostRoute{}, name, resourceVersion, notificationID, component)
+}
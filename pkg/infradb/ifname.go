@@ -0,0 +1,412 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// ifNamesKey is the key of the table mapping the kernel interface names to the objects which own them
+var ifNamesKey = registerStoreKey("ifnames")
+
+// ifNameAttempts bounds the search of a free hashed name
+const ifNameAttempts = 16
+
+// Roles of the kernel devices created for the objects
+const (
+	// LinkRoleVrf is the l3 master device of the VRF
+	LinkRoleVrf = "vrf"
+	// LinkRoleBridge is the bridge which terminates the l3 vxlan of the VRF
+	LinkRoleBridge = "br"
+	// LinkRoleVxlan is the l3 vxlan device of the VRF
+	LinkRoleVxlan = "vxlan"
+	// LinkRoleSvi is the routed interface of the SVI
+	LinkRoleSvi = "svi"
+	// LinkRoleQinq is a QinQ sub-interface of the bridge port, followed by its S-VLAN and C-VLAN
+	LinkRoleQinq = "qinq"
+	// LinkRoleMacsec is the MACsec device of an uplink
+	LinkRoleMacsec = "macsec"
+)
+
+// ErrIfNameExhausted no free kernel interface name has been found for the device
+var ErrIfNameExhausted = errors.New("no free kernel interface name")
+
+// reservedIfNames are the devices which are named after a vlan id or are static, they are never
+// handed out to the devices of the VRFs and SVIs
+var reservedIfNames = regexp.MustCompile(`^(lo|br-tenant|vxlan-\d+|brt-\d+)$`)
+
+// managementIfName tells whether the name is the one of a device of the management plane, which
+// must never be handed out either
+func managementIfName(name string) bool {
+	mgmt := config.GlobalConfig.Management
+	return name == mgmt.Vrf || name == mgmt.Interface
+}
+
+// LinkOwner is the device of an object which owns a kernel interface name
+type LinkOwner struct {
+	Object string
+	Role   string
+}
+
+// ifNameTable maps the kernel interface names to their owner and back
+type ifNameTable struct {
+	Owners map[string]LinkOwner
+	Names  map[string]string
+}
+
+// ownerKey is the key of the device of the object in the Names of the table
+func ownerKey(object, role string) string {
+	return role + ":" + object
+}
+
+// legacyIfName is the name the device had before the table was introduced, derived from the resource id
+func legacyIfName(object, role string, vlanID uint32) string {
+	id := path.Base(object)
+	switch role {
+	case LinkRoleBridge:
+		return "br-" + id
+	case LinkRoleVxlan:
+		return "vxlan-" + id
+	case LinkRoleSvi:
+		return fmt.Sprintf("%s-%d", id, vlanID)
+	}
+	return id
+}
+
+// validIfName tells whether the kernel accepts the interface name
+func validIfName(name string) bool {
+	if name == "" || len(name) > utils.IfNameSize || name == "." || name == ".." {
+		return false
+	}
+	return !strings.ContainsAny(name, "/: \t\n")
+}
+
+// getIfNames returns the table of the interface names, the caller holds the global lock
+func getIfNames() (*ifNameTable, error) {
+	table := &ifNameTable{}
+	if _, err := infradb.client.Get(ifNamesKey, table); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if table.Owners == nil {
+		table.Owners = make(map[string]LinkOwner)
+	}
+	if table.Names == nil {
+		table.Names = make(map[string]string)
+	}
+	return table, nil
+}
+
+// allocate hands out a name to the device: the preferred name when it is valid and free,
+// otherwise a deterministic hashed name. The name of a device which has one already is kept.
+func (t *ifNameTable) allocate(object, role, preferred string) (string, error) {
+	key := ownerKey(object, role)
+	if name, ok := t.Names[key]; ok {
+		return name, nil
+	}
+	free := func(name string) bool {
+		_, taken := t.Owners[name]
+		return validIfName(name) && !taken && !reservedIfNames.MatchString(name) && !managementIfName(name)
+	}
+	name := preferred
+	for attempt := 0; !free(name); attempt++ {
+		if attempt == ifNameAttempts {
+			return "", fmt.Errorf("%w for the %s of %s", ErrIfNameExhausted, role, object)
+		}
+		name = utils.HashedIfName(preferred, key, attempt)
+	}
+	t.Owners[name] = LinkOwner{Object: object, Role: role}
+	t.Names[key] = name
+	return name, nil
+}
+
+// release forgets the names of all the devices of the object
+func (t *ifNameTable) release(object string) {
+	for name, owner := range t.Owners {
+		if owner.Object == object {
+			delete(t.Owners, name)
+			delete(t.Names, ownerKey(owner.Object, owner.Role))
+		}
+	}
+}
+
+// allocateVrfIfNames hands out the names of the devices of the VRF, the caller holds the global lock
+func allocateVrfIfNames(vrf *Vrf) error {
+	if path.Base(vrf.Name) == "GRD" {
+		// The GRD is the default table of the kernel, it has no devices
+		return nil
+	}
+	table, err := getIfNames()
+	if err != nil {
+		return err
+	}
+	for _, role := range []string{LinkRoleVrf, LinkRoleBridge, LinkRoleVxlan} {
+		if _, err := table.allocate(vrf.Name, role, legacyIfName(vrf.Name, role, 0)); err != nil {
+			return err
+		}
+	}
+	return infradb.client.Set(ifNamesKey, table)
+}
+
+// allocateSviIfName hands out the name of the routed interface of the SVI, the caller holds the global lock
+func allocateSviIfName(svi *Svi, vrf *Vrf, lb *LogicalBridge) error {
+	table, err := getIfNames()
+	if err != nil {
+		return err
+	}
+	preferred := fmt.Sprintf("%s-%d", table.name(vrf.Name, LinkRoleVrf, 0), lb.Spec.VlanID)
+	if _, err := table.allocate(svi.Name, LinkRoleSvi, preferred); err != nil {
+		return err
+	}
+	return infradb.client.Set(ifNamesKey, table)
+}
+
+// releaseIfNames forgets the names of the devices of the deleted object, the caller holds the global lock
+func releaseIfNames(object string) error {
+	table, err := getIfNames()
+	if err != nil {
+		return err
+	}
+	table.release(object)
+	return infradb.client.Set(ifNamesKey, table)
+}
+
+// ReserveIfNames records the names of the existing devices of an object being adopted, by role, so that
+// the object keeps them. The names owned by another object, reserved for the vlans or of the management
+// plane are refused.
+func ReserveIfNames(object string, names map[string]string) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	table, err := getIfNames()
+	if err != nil {
+		return err
+	}
+	if err := table.reserve(object, names); err != nil {
+		return err
+	}
+	return infradb.client.Set(ifNamesKey, table)
+}
+
+// reserve records the names of the devices of the object by role, nothing is recorded when one is refused
+func (t *ifNameTable) reserve(object string, names map[string]string) error {
+	roles := sortedKeys(rolesOf(names))
+	for _, role := range roles {
+		name := names[role]
+		if owner, taken := t.Owners[name]; taken && owner.Object != object {
+			return fmt.Errorf("%s is the %s of %s", name, owner.Role, owner.Object)
+		}
+		if !validIfName(name) || reservedIfNames.MatchString(name) || managementIfName(name) {
+			return fmt.Errorf("%s is reserved and cannot be the %s of %s", name, role, object)
+		}
+	}
+	for _, role := range roles {
+		t.Owners[names[role]] = LinkOwner{Object: object, Role: role}
+		t.Names[ownerKey(object, role)] = names[role]
+	}
+	return nil
+}
+
+// ReleaseIfNames forgets the names of the devices of an object which has not been created
+func ReleaseIfNames(object string) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return releaseIfNames(object)
+}
+
+// AllocateLinkName hands out the name of a device of the object which is not a VRF or an SVI: the preferred
+// name when it is valid and free, otherwise a hashed name. The device keeps its name until it is released.
+func AllocateLinkName(object, role, preferred string) (string, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	table, err := getIfNames()
+	if err != nil {
+		return "", err
+	}
+	if name, ok := table.Names[ownerKey(object, role)]; ok {
+		return name, nil
+	}
+	name, err := table.allocate(object, role, preferred)
+	if err != nil {
+		return "", err
+	}
+	return name, infradb.client.Set(ifNamesKey, table)
+}
+
+// ReleaseLinkName forgets the name of the device of the object with the role
+func ReleaseLinkName(object, role string) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	table, err := getIfNames()
+	if err != nil {
+		return err
+	}
+	key := ownerKey(object, role)
+	name, ok := table.Names[key]
+	if !ok {
+		return nil
+	}
+	delete(table.Names, key)
+	delete(table.Owners, name)
+	return infradb.client.Set(ifNamesKey, table)
+}
+
+// rolesOf returns the set of the roles of the names
+func rolesOf(names map[string]string) map[string]bool {
+	roles := make(map[string]bool, len(names))
+	for role := range names {
+		roles[role] = true
+	}
+	return roles
+}
+
+// name returns the name of the device, or its legacy name when it has not been recorded
+func (t *ifNameTable) name(object, role string, vlanID uint32) string {
+	if name, ok := t.Names[ownerKey(object, role)]; ok {
+		return name
+	}
+	return legacyIfName(object, role, vlanID)
+}
+
+// LinkName returns the kernel interface name of the device of the VRF
+func LinkName(vrfName, role string) string {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	table, err := getIfNames()
+	if err != nil {
+		return legacyIfName(vrfName, role, 0)
+	}
+	return table.name(vrfName, role, 0)
+}
+
+// SviLinkName returns the kernel interface name of the routed interface of the SVI on the vlan
+func SviLinkName(svi *Svi, vlanID uint32) string {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	table, err := getIfNames()
+	if err != nil {
+		return fmt.Sprintf("%s-%d", path.Base(svi.Spec.Vrf), vlanID)
+	}
+	if name, ok := table.Names[ownerKey(svi.Name, LinkRoleSvi)]; ok {
+		return name
+	}
+	return fmt.Sprintf("%s-%d", table.name(svi.Spec.Vrf, LinkRoleVrf, 0), vlanID)
+}
+
+// GetLinkOwner returns the device of the object which owns the kernel interface name
+func GetLinkOwner(ifname string) (LinkOwner, bool) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	table, err := getIfNames()
+	if err != nil {
+		return LinkOwner{}, false
+	}
+	owner, ok := table.Owners[ifname]
+	return owner, ok
+}
+
+// IfNameRename is a device whose legacy name differs from the name it has been given
+type IfNameRename struct {
+	LinkOwner
+	Old string
+	New string
+}
+
+// MigrateIfNames records the names of the devices of the objects created before the name table,
+// in the order of their resource names so that the outcome does not depend on the store. The devices
+// keep their legacy name unless it collides or is too long for the kernel. The renames whose legacy
+// name is not owned by another device are returned so that the devices existing in the kernel can follow.
+func MigrateIfNames() ([]IfNameRename, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	table, err := getIfNames()
+	if err != nil {
+		return nil, err
+	}
+	renames := []IfNameRename{}
+	record := func(object, role, legacy string) error {
+		if _, ok := table.Names[ownerKey(object, role)]; ok {
+			return nil
+		}
+		name, err := table.allocate(object, role, legacy)
+		if err != nil {
+			return err
+		}
+		if name != legacy {
+			renames = append(renames, IfNameRename{LinkOwner: LinkOwner{Object: object, Role: role}, Old: legacy, New: name})
+		}
+		return nil
+	}
+
+	vrfs := make(map[string]bool)
+	if _, err := infradb.client.Get("vrfs", &vrfs); err != nil {
+		return nil, err
+	}
+	for _, name := range sortedKeys(vrfs) {
+		if path.Base(name) == "GRD" {
+			continue
+		}
+		for _, role := range []string{LinkRoleVrf, LinkRoleBridge, LinkRoleVxlan} {
+			if err := record(name, role, legacyIfName(name, role, 0)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	svis := make(map[string]bool)
+	if _, err := infradb.client.Get("svis", &svis); err != nil {
+		return nil, err
+	}
+	for _, name := range sortedKeys(svis) {
+		svi := &Svi{}
+		lb := &LogicalBridge{}
+		if found, err := infradb.client.Get(name, svi); err != nil || !found {
+			continue
+		}
+		if found, err := infradb.client.Get(svi.Spec.LogicalBridge, lb); err != nil || !found {
+			continue
+		}
+		if err := record(name, LinkRoleSvi, legacyIfName(svi.Spec.Vrf, LinkRoleSvi, lb.Spec.VlanID)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := infradb.client.Set(ifNamesKey, table); err != nil {
+		return nil, err
+	}
+	unowned := []IfNameRename{}
+	for _, rename := range renames {
+		if _, taken := table.Owners[rename.Old]; !taken {
+			unowned = append(unowned, rename)
+		}
+	}
+	return unowned, nil
+}
+
+// sortedKeys returns the keys of the index map in order
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+func Test_AllocateIfName(t *testing.T) {
+	tests := map[string]struct {
+		taken      map[string]LinkOwner
+		management string
+		object     string
+		role       string
+		preferred  string
+		legacy     bool
+	}{
+		"free legacy name": {
+			object:    "//network.opiproject.org/vrfs/blue",
+			role:      LinkRoleBridge,
+			preferred: "br-blue",
+			legacy:    true,
+		},
+		"too long": {
+			object:    "//network.opiproject.org/vrfs/tenant-blue-prod",
+			role:      LinkRoleVxlan,
+			preferred: "vxlan-tenant-blue-prod",
+		},
+		"taken by another type": {
+			taken:     map[string]LinkOwner{"blue-10": {Object: "//network.opiproject.org/vrfs/blue-10", Role: LinkRoleVrf}},
+			object:    "//network.opiproject.org/svis/blue",
+			role:      LinkRoleSvi,
+			preferred: "blue-10",
+		},
+		"reserved for a logical bridge": {
+			object:    "//network.opiproject.org/vrfs/10",
+			role:      LinkRoleVxlan,
+			preferred: "vxlan-10",
+		},
+		"taken by the management vrf": {
+			management: "mgmt",
+			object:     "//network.opiproject.org/vrfs/mgmt",
+			role:       LinkRoleVrf,
+			preferred:  "mgmt",
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			config.GlobalConfig.Management.Vrf = tt.management
+			defer func() { config.GlobalConfig.Management.Vrf = "" }()
+			table := &ifNameTable{Owners: map[string]LinkOwner{}, Names: map[string]string{}}
+			for name, owner := range tt.taken {
+				table.Owners[name] = owner
+				table.Names[ownerKey(owner.Object, owner.Role)] = name
+			}
+			name, err := table.allocate(tt.object, tt.role, tt.preferred)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !validIfName(name) {
+				t.Errorf("invalid interface name %q", name)
+			}
+			if (name == tt.preferred) != tt.legacy {
+				t.Errorf("expected legacy name %v, received %s", tt.legacy, name)
+			}
+			if owner := table.Owners[name]; owner.Object != tt.object || owner.Role != tt.role {
+				t.Errorf("expected owner %s, received %v", tt.object, owner)
+			}
+
+			// The name is kept and is deterministic
+			again, _ := table.allocate(tt.object, tt.role, tt.preferred)
+			if again != name {
+				t.Errorf("expected %s again, received %s", name, again)
+			}
+			table.release(tt.object)
+			if len(table.Names) != len(tt.taken) {
+				t.Errorf("expected the name of %s to be released", tt.object)
+			}
+			if again, _ := table.allocate(tt.object, tt.role, tt.preferred); again != name {
+				t.Errorf("expected %s after release, received %s", name, again)
+			}
+		})
+	}
+}
+
+func Test_ReserveIfNames(t *testing.T) {
+	const vrf = "//network.opiproject.org/vrfs/blue"
+	tests := map[string]struct {
+		names map[string]string
+		fail  bool
+	}{
+		"hand configured names": {
+			names: map[string]string{LinkRoleVrf: "tenant-blue", LinkRoleBridge: "br-l3-blue", LinkRoleVxlan: "vx-blue"},
+		},
+		"taken by another object": {
+			names: map[string]string{LinkRoleVrf: "tenant-blue", LinkRoleBridge: "red"},
+			fail:  true,
+		},
+		"reserved for a logical bridge": {
+			names: map[string]string{LinkRoleVrf: "tenant-blue", LinkRoleVxlan: "vxlan-10"},
+			fail:  true,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			table := &ifNameTable{
+				Owners: map[string]LinkOwner{"red": {Object: "//network.opiproject.org/vrfs/red", Role: LinkRoleVrf}},
+				Names:  map[string]string{ownerKey("//network.opiproject.org/vrfs/red", LinkRoleVrf): "red"},
+			}
+			err := table.reserve(vrf, tt.names)
+			if (err != nil) != tt.fail {
+				t.Fatalf("expected failure %v, received %v", tt.fail, err)
+			}
+			if tt.fail {
+				if len(table.Names) != 1 {
+					t.Errorf("expected nothing to be reserved, received %v", table.Names)
+				}
+				return
+			}
+			for role, name := range tt.names {
+				if got := table.name(vrf, role, 0); got != name {
+					t.Errorf("expected the %s to be %s, received %s", role, name, got)
+				}
+			}
+		})
+	}
+}
+
+func Test_AllocateLinkName(t *testing.T) {
+	if err := NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	const bp = "//network.opiproject.org/ports/eth2"
+	short, err := AllocateLinkName(bp, LinkRoleQinq+"/100/0", "eth2q100")
+	if err != nil || short != "eth2q100" {
+		t.Fatalf("expected the preferred name eth2q100, received %s %v", short, err)
+	}
+	long, err := AllocateLinkName(bp, LinkRoleQinq+"/100/200", "enp175s0f0np0q100.200")
+	if err != nil || len(long) > 15 {
+		t.Fatalf("expected a hashed name, received %s %v", long, err)
+	}
+	if again, _ := AllocateLinkName(bp, LinkRoleQinq+"/100/200", "enp175s0f0np0q100.200"); again != long {
+		t.Errorf("expected the device to keep %s, received %s", long, again)
+	}
+	// a name owned by the device of another object is never handed out again
+	other, err := AllocateLinkName("//network.opiproject.org/ports/eth3", LinkRoleQinq+"/100/0", "eth2q100")
+	if err != nil || other == short {
+		t.Errorf("expected a name other than %s, received %s %v", short, other, err)
+	}
+	if err := ReleaseLinkName(bp, LinkRoleQinq+"/100/0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := GetLinkOwner(short); ok {
+		t.Errorf("expected %s to be released", short)
+	}
+}
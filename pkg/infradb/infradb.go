@@ -19,7 +19,15 @@ import (
 )
 
 var infradb *InfraDB
-var globalLock sync.Mutex
+
+// globalLock guards the objects of the store and the references between them. The Get
+// functions share it, the changes and the status updates take it alone since they read
+// and write several objects, e.g. an SVI with its VRF and Logical Bridge. It is the last
+// lock taken: the modules may hold their own lock when they call the DB (like the deferred
+// objects of the LGM), but the DB never calls a module or netlink while holding it, the
+// subscribers are reached through the task manager queue and the status listeners must
+// not block nor call the DB.
+var globalLock sync.RWMutex
 
 // InfraDB structure
 type InfraDB struct {
@@ -60,6 +68,28 @@ func NewInfraDB(address string, dbtype string) error {
 	return nil
 }
 
+// Ping checks that the store answers requests
+func Ping() error {
+	if infradb == nil {
+		return errors.New("infradb is not initialized")
+	}
+	_, err := infradb.client.Get("vrfs", &map[string]bool{})
+	return err
+}
+
+// GetResourceVersion returns the resource version of any object of the store, or an empty version when it does not exist
+func GetResourceVersion(name string) (string, error) {
+	obj := struct{ ResourceVersion string }{}
+	found, err := infradb.client.Get(name, &obj)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", nil
+	}
+	return obj.ResourceVersion, nil
+}
+
 // Close closes a infradb connection to the DB
 func Close() error {
 	return infradb.client.Close()
@@ -80,6 +110,12 @@ func CreateLB(lb *LogicalBridge) error {
 
 	log.Printf("CreateLB(): Create Logical Bridge: %+v\n", lb)
 
+	// A zero VNI is allocated from the VNI pool
+	if err := resolveVni(&lb.Spec.Vni); err != nil {
+		log.Printf("CreateLB(): %v\n", err)
+		return err
+	}
+
 	// Check if VNI is already used
 	if lb.Spec.Vni != nil {
 		found, err := infradb.client.Get("vpns", &vpns)
@@ -96,11 +132,31 @@ func CreateLB(lb *LogicalBridge) error {
 				log.Printf("CreateLB(): VNI already in use: %+v\n", lb.Spec.Vni)
 				return ErrVniInUse
 			}
+			if err := checkVniQuota(vpns); err != nil {
+				return err
+			}
 			vpns[*lb.Spec.Vni] = false
 		}
 	}
 
-	err := infradb.client.Set(lb.Name, lb)
+	// Check if the VLAN ID is already used, a zero VLAN ID is allocated from the VLAN pool
+	vlans, err := loadVlans()
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if err := claimVlan(vlans, lb.Name, &lb.Spec.VlanID); err != nil {
+		log.Printf("CreateLB(): %v\n", err)
+		return err
+	}
+
+	err = infradb.client.Set(lb.Name, lb)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	err = infradb.client.Set(vlansKey, vlans)
 	if err != nil {
 		log.Println(err)
 		return err
@@ -133,13 +189,14 @@ func CreateLB(lb *LogicalBridge) error {
 		return err
 	}
 
+	notifyLifecycle(StatusEventCreated, "logical-bridge", lb.Name, lb.ResourceVersion)
 	taskmanager.TaskMan.CreateTask(lb.Name, "logical-bridge", lb.ResourceVersion, subscribers)
 
 	return nil
 }
 
 // DeleteLB deletes a logical bridge infradb object
-func DeleteLB(name string) error {
+func DeleteLB(name string, opts ...WriteOption) error {
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
@@ -158,6 +215,9 @@ func DeleteLB(name string) error {
 	if !found {
 		return ErrKeyNotFound
 	}
+	if err := checkIfMatch(name, lb.ResourceVersion, opts); err != nil {
+		return err
+	}
 
 	if lb.Svi != "" {
 		log.Printf("DeleteLB(): Can not delete Logical Bridge %+v. Associated with SVI interfaces", lb.Name)
@@ -180,6 +240,8 @@ func DeleteLB(name string) error {
 		return err
 	}
 
+	forgetLease(lb.Name)
+	notifyLifecycle(StatusEventDeleting, "logical-bridge", lb.Name, lb.ResourceVersion)
 	taskmanager.TaskMan.CreateTask(lb.Name, "logical-bridge", lb.ResourceVersion, subscribers)
 
 	return nil
@@ -187,8 +249,8 @@ func DeleteLB(name string) error {
 
 // GetLB returns an infradb logical bridge object
 func GetLB(name string) (*LogicalBridge, error) {
-	globalLock.Lock()
-	defer globalLock.Unlock()
+	globalLock.RLock()
+	defer globalLock.RUnlock()
 
 	lb := LogicalBridge{}
 	found, err := infradb.client.Get(name, &lb)
@@ -199,10 +261,24 @@ func GetLB(name string) (*LogicalBridge, error) {
 	return &lb, err
 }
 
+// GetLBByVni returns the logical bridge of the VNI
+func GetLBByVni(vni uint32) (*LogicalBridge, error) {
+	lbs, err := GetAllLBs()
+	if err != nil {
+		return nil, err
+	}
+	for _, lb := range lbs {
+		if lb.Spec.Vni != nil && *lb.Spec.Vni == vni {
+			return lb, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
 // GetAllLBs returns a list of logical bridges from the DB
 func GetAllLBs() ([]*LogicalBridge, error) {
-	globalLock.Lock()
-	defer globalLock.Unlock()
+	globalLock.RLock()
+	defer globalLock.RUnlock()
 
 	lbs := []*LogicalBridge{}
 	lbsMap := make(map[string]bool)
@@ -238,7 +314,7 @@ func GetAllLBs() ([]*LogicalBridge, error) {
 }
 
 // UpdateLB updates a logical bridge infradb object
-func UpdateLB(lb *LogicalBridge) error {
+func UpdateLB(lb *LogicalBridge, opts ...WriteOption) error {
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
@@ -248,12 +324,50 @@ func UpdateLB(lb *LogicalBridge) error {
 		return errors.New("no subscribers found for logical bridge")
 	}
 
-	err := infradb.client.Set(lb.Name, lb)
+	// Move the Logical Bridge to its new VLAN ID
+	stored := LogicalBridge{}
+	found, err := infradb.client.Get(lb.Name, &stored)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if err := checkIfMatch(lb.Name, stored.ResourceVersion, opts); err != nil {
+		return err
+	}
+	var vlans map[uint32]string
+	if found && stored.Spec.VlanID != lb.Spec.VlanID {
+		vlans, err = loadVlans()
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		if err := claimVlan(vlans, lb.Name, &lb.Spec.VlanID); err != nil {
+			log.Printf("UpdateLB(): %v\n", err)
+			return err
+		}
+		releaseVlan(vlans, lb.Name, stored.Spec.VlanID)
+	}
+	// The encapsulation and the isolation are not part of the opi-api spec of the update
+	if found && stored.Spec != nil {
+		lb.Spec.Encap = stored.Spec.Encap
+		lb.Spec.IsolatedPorts = stored.Spec.IsolatedPorts
+	}
+
+	err = infradb.client.Set(lb.Name, lb)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
 
+	if vlans != nil {
+		err = infradb.client.Set(vlansKey, vlans)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+
+	notifyLifecycle(StatusEventUpdated, "logical-bridge", lb.Name, lb.ResourceVersion)
 	taskmanager.TaskMan.CreateTask(lb.Name, "logical-bridge", lb.ResourceVersion, subscribers)
 
 	return nil
@@ -321,6 +435,7 @@ func UpdateLBStatus(name string, resourceVersion string, notificationID string,
 	}
 
 	// Set the state of the component
+	before := lb.Status.LBOperStatus
 	lb.setComponentState(component)
 
 	// Check if all the components are in Success state
@@ -346,6 +461,19 @@ func UpdateLBStatus(name string, resourceVersion string, notificationID string,
 				}
 			}
 
+			// Free the VLAN ID
+			vlans, err := loadVlans()
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+			releaseVlan(vlans, lb.Name, lb.Spec.VlanID)
+			err = infradb.client.Set(vlansKey, vlans)
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+
 			lbs := make(map[string]bool)
 			found, err = infradb.client.Get("lbs", &lbs)
 			if err != nil {
@@ -383,6 +511,8 @@ func UpdateLBStatus(name string, resourceVersion string, notificationID string,
 		log.Printf("UpdateLBStatus(): Logical Bridge %s has been updated: %+v\n", name, lb)
 	}
 
+	notifyStatus("logical-bridge", lb.Name, lb.ResourceVersion, int32(before), int32(lb.Status.LBOperStatus),
+		allCompSuccess && lb.Status.LBOperStatus == LogicalBridgeOperStatusToBeDeleted, component)
 	taskmanager.TaskMan.StatusUpdated(lb.Name, "logical-bridge", lb.ResourceVersion, notificationID, false, &component)
 
 	return nil
@@ -423,6 +553,16 @@ func CreateBP(bp *BridgePort) error {
 		}
 	}
 
+	// The netdevs kept by the storage bridge never join a tenant bridge
+	if err := checkNetdevClaim(bp.Name); err != nil {
+		return err
+	}
+
+	// Check the quota before touching any Logical Bridge
+	if err := checkPortQuota(bp.Spec.LogicalBridges); err != nil {
+		return err
+	}
+
 	// Get Logical Bridge infraDB objects
 	// Fill up the Vlans list of the infraDB Bridge Port object
 	// Add Bridge Port reference and save the Logical Bridge object back to DB
@@ -479,13 +619,14 @@ func CreateBP(bp *BridgePort) error {
 		return err
 	}
 
+	notifyLifecycle(StatusEventCreated, "bridge-port", bp.Name, bp.ResourceVersion)
 	taskmanager.TaskMan.CreateTask(bp.Name, "bridge-port", bp.ResourceVersion, subscribers)
 
 	return nil
 }
 
 // DeleteBP deletes a bridge port infradb object
-func DeleteBP(name string) error {
+func DeleteBP(name string, opts ...WriteOption) error {
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
@@ -503,6 +644,18 @@ func DeleteBP(name string) error {
 	if !found {
 		return ErrKeyNotFound
 	}
+	if err := checkIfMatch(name, bp.ResourceVersion, opts); err != nil {
+		return err
+	}
+
+	referrer, err := findReferrer(bp.Name)
+	if err != nil {
+		return err
+	}
+	if referrer != "" {
+		log.Printf("DeleteBP(): Can not delete Bridge Port %+v. Associated with %+v", bp.Name, referrer)
+		return ErrBridgePortInUse
+	}
 
 	for i := range subscribers {
 		bp.Status.Components[i].CompStatus = common.ComponentStatusPending
@@ -515,6 +668,8 @@ func DeleteBP(name string) error {
 		return err
 	}
 
+	forgetLease(bp.Name)
+	notifyLifecycle(StatusEventDeleting, "bridge-port", bp.Name, bp.ResourceVersion)
 	taskmanager.TaskMan.CreateTask(bp.Name, "bridge-port", bp.ResourceVersion, subscribers)
 
 	return nil
@@ -522,8 +677,8 @@ func DeleteBP(name string) error {
 
 // GetBP returns an infradb bridge port object
 func GetBP(name string) (*BridgePort, error) {
-	globalLock.Lock()
-	defer globalLock.Unlock()
+	globalLock.RLock()
+	defer globalLock.RUnlock()
 
 	bp := BridgePort{}
 	found, err := infradb.client.Get(name, &bp)
@@ -536,8 +691,8 @@ func GetBP(name string) (*BridgePort, error) {
 
 // GetAllBPs returns a list of bridge ports from the DB
 func GetAllBPs() ([]*BridgePort, error) {
-	globalLock.Lock()
-	defer globalLock.Unlock()
+	globalLock.RLock()
+	defer globalLock.RUnlock()
 
 	bps := []*BridgePort{}
 	bpsMap := make(map[string]bool)
@@ -573,7 +728,7 @@ func GetAllBPs() ([]*BridgePort, error) {
 }
 
 // UpdateBP updates a bridge port infradb object
-func UpdateBP(bp *BridgePort) error {
+func UpdateBP(bp *BridgePort, opts ...WriteOption) error {
 	// Note: The update functions for all the objects need to be revisited
 	// The implementaation currently is not correct but due to low priority
 	// will be refactored in the future.
@@ -586,12 +741,30 @@ func UpdateBP(bp *BridgePort) error {
 		return errors.New("no subscribers found for bridge port")
 	}
 
-	err := infradb.client.Set(bp.Name, bp)
+	// The sFlow sampling, the QinQ mapping, the isolation and the profile are not part of the opi-api spec of the update
+	stored := BridgePort{}
+	found, err := infradb.client.Get(bp.Name, &stored)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if err := checkIfMatch(bp.Name, stored.ResourceVersion, opts); err != nil {
+		return err
+	}
+	if found && stored.Spec != nil {
+		bp.Spec.Sflow = stored.Spec.Sflow
+		bp.Spec.Qinq = stored.Spec.Qinq
+		bp.Spec.Isolated = stored.Spec.Isolated
+		bp.Spec.Profile = stored.Spec.Profile
+	}
+
+	err = infradb.client.Set(bp.Name, bp)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
 
+	notifyLifecycle(StatusEventUpdated, "bridge-port", bp.Name, bp.ResourceVersion)
 	taskmanager.TaskMan.CreateTask(bp.Name, "bridge-port", bp.ResourceVersion, subscribers)
 
 	return nil
@@ -640,6 +813,7 @@ func UpdateBPStatus(name string, resourceVersion string, notificationID string,
 	}
 
 	// Set the state of the component
+	before := bp.Status.BPOperStatus
 	bp.setComponentState(component)
 
 	// Check if all the components are in Success state
@@ -676,6 +850,13 @@ func UpdateBPStatus(name string, resourceVersion string, notificationID string,
 				}
 			}
 
+			// Free the interface names of the QinQ sub-interfaces of the Bridge Port
+			err = releaseIfNames(bp.Name)
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+
 			// Delete the Bridge Port object from the DB
 			err = infradb.client.Delete(bp.Name)
 			if err != nil {
@@ -721,6 +902,8 @@ func UpdateBPStatus(name string, resourceVersion string, notificationID string,
 		log.Printf("UpdateBPStatus(): Bridge Port %s has been updated: %+v\n", name, bp)
 	}
 
+	notifyStatus("bridge-port", bp.Name, bp.ResourceVersion, int32(before), int32(bp.Status.BPOperStatus),
+		allCompSuccess && bp.Status.BPOperStatus == BridgePortOperStatusToBeDeleted, component)
 	taskmanager.TaskMan.StatusUpdated(bp.Name, "bridge-port", bp.ResourceVersion, notificationID, false, &component)
 
 	return nil
@@ -741,6 +924,12 @@ func CreateVrf(vrf *Vrf) error {
 
 	log.Printf("CreateVrf(): Create Vrf: %+v\n", vrf)
 
+	// A zero VNI is allocated from the VNI pool
+	if err := resolveVni(&vrf.Spec.Vni); err != nil {
+		log.Printf("CreateVrf(): %v\n", err)
+		return err
+	}
+
 	// TODO: Move the check for VNI in a common place
 	// and use that comomn code also for checking the LB vni
 	// Check if VNI is already used
@@ -759,10 +948,18 @@ func CreateVrf(vrf *Vrf) error {
 				log.Printf("CreateVrf(): VNI already in use: %+v\n", *vrf.Spec.Vni)
 				return ErrVniInUse
 			}
+			if err := checkVniQuota(vpns); err != nil {
+				return err
+			}
 			vpns[*vrf.Spec.Vni] = false
 		}
 	}
 
+	if err := allocateVrfIfNames(vrf); err != nil {
+		log.Printf("CreateVrf(): %v\n", err)
+		return err
+	}
+
 	err := infradb.client.Set(vrf.Name, vrf)
 	if err != nil {
 		log.Println(err)
@@ -796,13 +993,14 @@ func CreateVrf(vrf *Vrf) error {
 		return err
 	}
 
+	notifyLifecycle(StatusEventCreated, "vrf", vrf.Name, vrf.ResourceVersion)
 	taskmanager.TaskMan.CreateTask(vrf.Name, "vrf", vrf.ResourceVersion, subscribers)
 
 	return nil
 }
 
 // DeleteVrf deletes a vrf infradb object
-func DeleteVrf(name string) error {
+func DeleteVrf(name string, opts ...WriteOption) error {
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
@@ -820,12 +1018,24 @@ func DeleteVrf(name string) error {
 	if !found {
 		return ErrKeyNotFound
 	}
+	if err := checkIfMatch(name, vrf.ResourceVersion, opts); err != nil {
+		return err
+	}
 
 	if len(vrf.Svis) != 0 {
 		log.Printf("DeleteVrf(): Can not delete VRF %+v. Associated with SVI interfaces", vrf.Name)
 		return ErrVrfNotEmpty
 	}
 
+	referrer, err := findReferrer(vrf.Name)
+	if err != nil {
+		return err
+	}
+	if referrer != "" {
+		log.Printf("DeleteVrf(): Can not delete VRF %+v. Associated with %+v", vrf.Name, referrer)
+		return ErrVrfNotEmpty
+	}
+
 	for i := range subscribers {
 		vrf.Status.Components[i].CompStatus = common.ComponentStatusPending
 	}
@@ -837,6 +1047,8 @@ func DeleteVrf(name string) error {
 		return err
 	}
 
+	forgetLease(vrf.Name)
+	notifyLifecycle(StatusEventDeleting, "vrf", vrf.Name, vrf.ResourceVersion)
 	taskmanager.TaskMan.CreateTask(vrf.Name, "vrf", vrf.ResourceVersion, subscribers)
 
 	return nil
@@ -844,8 +1056,8 @@ func DeleteVrf(name string) error {
 
 // GetVrf returns an infradb vrf object
 func GetVrf(name string) (*Vrf, error) {
-	globalLock.Lock()
-	defer globalLock.Unlock()
+	globalLock.RLock()
+	defer globalLock.RUnlock()
 
 	vrf := Vrf{}
 	found, err := infradb.client.Get(name, &vrf)
@@ -858,8 +1070,8 @@ func GetVrf(name string) (*Vrf, error) {
 
 // GetAllVrfs returns a list of svis from the DB
 func GetAllVrfs() ([]*Vrf, error) {
-	globalLock.Lock()
-	defer globalLock.Unlock()
+	globalLock.RLock()
+	defer globalLock.RUnlock()
 
 	vrfs := []*Vrf{}
 	vrfsMap := make(map[string]bool)
@@ -895,7 +1107,7 @@ func GetAllVrfs() ([]*Vrf, error) {
 }
 
 // UpdateVrf updates a vrf infradb object
-func UpdateVrf(vrf *Vrf) error {
+func UpdateVrf(vrf *Vrf, opts ...WriteOption) error {
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
@@ -905,12 +1117,28 @@ func UpdateVrf(vrf *Vrf) error {
 		return errors.New("no subscribers found for vrf")
 	}
 
-	err := infradb.client.Set(vrf.Name, vrf)
+	// The dataplane and the namespace are not part of the opi-api spec of the update
+	stored := Vrf{}
+	found, err := infradb.client.Get(vrf.Name, &stored)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
+	if err := checkIfMatch(vrf.Name, stored.ResourceVersion, opts); err != nil {
+		return err
+	}
+	if found && stored.Spec != nil {
+		vrf.Spec.Mpls, vrf.Spec.Srv6 = stored.Spec.Mpls, stored.Spec.Srv6
+		vrf.Spec.Netns = stored.Spec.Netns
+	}
 
+	err = infradb.client.Set(vrf.Name, vrf)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	notifyLifecycle(StatusEventUpdated, "vrf", vrf.Name, vrf.ResourceVersion)
 	taskmanager.TaskMan.CreateTask(vrf.Name, "vrf", vrf.ResourceVersion, subscribers)
 
 	return nil
@@ -958,6 +1186,7 @@ func UpdateVrfStatus(name string, resourceVersion string, notificationID string,
 	}
 
 	// Set the state of the component
+	before := vrf.Status.VrfOperStatus
 	vrf.setComponentState(component)
 
 	// Check if all the components are in Success state
@@ -975,6 +1204,13 @@ func UpdateVrfStatus(name string, resourceVersion string, notificationID string,
 				return err
 			}
 
+			// Free the interface names of the devices of the VRF
+			err = releaseIfNames(vrf.Name)
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+
 			// Delete VNI from the VPN map
 			if vrf.Spec.Vni != nil {
 				err = removeVniFromVpns(*vrf.Spec.Vni)
@@ -1021,6 +1257,8 @@ func UpdateVrfStatus(name string, resourceVersion string, notificationID string,
 		log.Printf("UpdateVrfStatus(): VRF %s has been updated: %+v\n", name, vrf)
 	}
 
+	notifyStatus("vrf", vrf.Name, vrf.ResourceVersion, int32(before), int32(vrf.Status.VrfOperStatus),
+		allCompSuccess && vrf.Status.VrfOperStatus == VrfOperStatusToBeDeleted, component)
 	taskmanager.TaskMan.StatusUpdated(vrf.Name, "vrf", vrf.ResourceVersion, notificationID, false, &component)
 
 	return nil
@@ -1063,6 +1301,15 @@ func CreateSvi(svi *Svi) error {
 		return ErrLogicalBridgeNotFound
 	}
 
+	if err := checkSviQuota(&vrf); err != nil {
+		return err
+	}
+
+	if err := allocateSviIfName(svi, &vrf, &lb); err != nil {
+		log.Printf("CreateSvi(): %v\n", err)
+		return err
+	}
+
 	// Store svi reference to the VRF object
 	if err := vrf.AddSvi(svi.Name); err != nil {
 		log.Println(err)
@@ -1112,13 +1359,14 @@ func CreateSvi(svi *Svi) error {
 		return err
 	}
 
+	notifyLifecycle(StatusEventCreated, "svi", svi.Name, svi.ResourceVersion)
 	taskmanager.TaskMan.CreateTask(svi.Name, "svi", svi.ResourceVersion, subscribers)
 
 	return nil
 }
 
 // DeleteSvi deletes a svi infradb object
-func DeleteSvi(name string) error {
+func DeleteSvi(name string, opts ...WriteOption) error {
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
@@ -1136,6 +1384,18 @@ func DeleteSvi(name string) error {
 	if !found {
 		return ErrKeyNotFound
 	}
+	if err := checkIfMatch(name, svi.ResourceVersion, opts); err != nil {
+		return err
+	}
+
+	referrer, err := findReferrer(svi.Name)
+	if err != nil {
+		return err
+	}
+	if referrer != "" {
+		log.Printf("DeleteSvi(): Can not delete SVI %+v. Associated with %+v", svi.Name, referrer)
+		return ErrSviInUse
+	}
 
 	for i := range subscribers {
 		svi.Status.Components[i].CompStatus = common.ComponentStatusPending
@@ -1148,6 +1408,8 @@ func DeleteSvi(name string) error {
 		return err
 	}
 
+	forgetLease(svi.Name)
+	notifyLifecycle(StatusEventDeleting, "svi", svi.Name, svi.ResourceVersion)
 	taskmanager.TaskMan.CreateTask(svi.Name, "svi", svi.ResourceVersion, subscribers)
 
 	return nil
@@ -1155,8 +1417,8 @@ func DeleteSvi(name string) error {
 
 // GetSvi returns an infradb svi object
 func GetSvi(name string) (*Svi, error) {
-	globalLock.Lock()
-	defer globalLock.Unlock()
+	globalLock.RLock()
+	defer globalLock.RUnlock()
 
 	svi := Svi{}
 	found, err := infradb.client.Get(name, &svi)
@@ -1169,8 +1431,8 @@ func GetSvi(name string) (*Svi, error) {
 
 // GetAllSvis returns a list of svis from the DB
 func GetAllSvis() ([]*Svi, error) {
-	globalLock.Lock()
-	defer globalLock.Unlock()
+	globalLock.RLock()
+	defer globalLock.RUnlock()
 
 	svis := []*Svi{}
 	svisMap := make(map[string]bool)
@@ -1208,6 +1470,176 @@ func GetAllSvis() ([]*Svi, error) {
 // DeleteAllResources deletes all components from infradb
 func DeleteAllResources() error {
 	duration := 10 * time.Second
+	fls, _ := GetAllFlowLogs()
+	for _, fl := range fls {
+		err := DeleteFlowLog(fl.Name)
+		if err != nil {
+			return err
+		}
+	}
+	startTime := time.Now()
+	for {
+		f, _ := GetAllFlowLogs()
+		if len(f) == 0 {
+			break
+		}
+		if time.Since(startTime) > duration {
+			return errors.New("failed to delete FlowLogs")
+		}
+	}
+	rps, _ := GetAllRoutingPolicies()
+	for _, rp := range rps {
+		err := DeleteRoutingPolicy(rp.Name)
+		if err != nil {
+			return err
+		}
+	}
+	startTime = time.Now()
+	for {
+		r, _ := GetAllRoutingPolicies()
+		if len(r) == 0 {
+			break
+		}
+		if time.Since(startTime) > duration {
+			return errors.New("failed to delete RoutingPolicies")
+		}
+	}
+	vps, _ := GetAllVpcPeerings()
+	for _, vp := range vps {
+		err := DeleteVpcPeering(vp.Name)
+		if err != nil {
+			return err
+		}
+	}
+	startTime = time.Now()
+	for {
+		v, _ := GetAllVpcPeerings()
+		if len(v) == 0 {
+			break
+		}
+		if time.Since(startTime) > duration {
+			return errors.New("failed to delete VpcPeerings")
+		}
+	}
+	hrs, _ := GetAllHostRoutes()
+	for _, hr := range hrs {
+		err := DeleteHostRoute(hr.Name)
+		if err != nil {
+			return err
+		}
+	}
+	startTime = time.Now()
+	for {
+		h, _ := GetAllHostRoutes()
+		if len(h) == 0 {
+			break
+		}
+		if time.Since(startTime) > duration {
+			return errors.New("failed to delete HostRoutes")
+		}
+	}
+	rls, _ := GetAllRouteLeaks()
+	for _, rl := range rls {
+		err := DeleteRouteLeak(rl.Name)
+		if err != nil {
+			return err
+		}
+	}
+	startTime = time.Now()
+	for {
+		r, _ := GetAllRouteLeaks()
+		if len(r) == 0 {
+			break
+		}
+		if time.Since(startTime) > duration {
+			return errors.New("failed to delete RouteLeaks")
+		}
+	}
+	nats, _ := GetAllNatGateways()
+	for _, nat := range nats {
+		err := DeleteNatGateway(nat.Name)
+		if err != nil {
+			return err
+		}
+	}
+	startTime = time.Now()
+	for {
+		n, _ := GetAllNatGateways()
+		if len(n) == 0 {
+			break
+		}
+		if time.Since(startTime) > duration {
+			return errors.New("failed to delete NatGateways")
+		}
+	}
+	dnss, _ := GetAllDNSForwarders()
+	for _, dns := range dnss {
+		err := DeleteDNSForwarder(dns.Name)
+		if err != nil {
+			return err
+		}
+	}
+	startTime = time.Now()
+	for {
+		d, _ := GetAllDNSForwarders()
+		if len(d) == 0 {
+			break
+		}
+		if time.Since(startTime) > duration {
+			return errors.New("failed to delete DNSForwarders")
+		}
+	}
+	dhcps, _ := GetAllDHCPServers()
+	for _, dhcp := range dhcps {
+		err := DeleteDHCPServer(dhcp.Name)
+		if err != nil {
+			return err
+		}
+	}
+	startTime = time.Now()
+	for {
+		d, _ := GetAllDHCPServers()
+		if len(d) == 0 {
+			break
+		}
+		if time.Since(startTime) > duration {
+			return errors.New("failed to delete DHCPServers")
+		}
+	}
+	ras, _ := GetAllRouterAdvertisements()
+	for _, ra := range ras {
+		err := DeleteRouterAdvertisement(ra.Name)
+		if err != nil {
+			return err
+		}
+	}
+	startTime = time.Now()
+	for {
+		r, _ := GetAllRouterAdvertisements()
+		if len(r) == 0 {
+			break
+		}
+		if time.Since(startTime) > duration {
+			return errors.New("failed to delete RouterAdvertisements")
+		}
+	}
+	eifs, _ := GetAllExternalInterfaces()
+	for _, eif := range eifs {
+		err := DeleteExternalInterface(eif.Name)
+		if err != nil {
+			return err
+		}
+	}
+	startTime = time.Now()
+	for {
+		e, _ := GetAllExternalInterfaces()
+		if len(e) == 0 {
+			break
+		}
+		if time.Since(startTime) > duration {
+			return errors.New("failed to delete ExternalInterfaces")
+		}
+	}
 	bps, _ := GetAllBPs()
 	for _, bp := range bps {
 		err := DeleteBP(bp.Name)
@@ -1215,7 +1647,7 @@ func DeleteAllResources() error {
 			return err
 		}
 	}
-	startTime := time.Now()
+	startTime = time.Now()
 	for {
 		b, _ := GetAllBPs()
 		if len(b) == 0 {
@@ -1225,6 +1657,23 @@ func DeleteAllResources() error {
 			return errors.New("failed to delete BridgePorts")
 		}
 	}
+	bonds, _ := GetAllBonds()
+	for _, bond := range bonds {
+		err := DeleteBond(bond.Name)
+		if err != nil {
+			return err
+		}
+	}
+	startTime = time.Now()
+	for {
+		b, _ := GetAllBonds()
+		if len(b) == 0 {
+			break
+		}
+		if time.Since(startTime) > duration {
+			return errors.New("failed to delete Bonds")
+		}
+	}
 	svis, _ := GetAllSvis()
 	for _, svi := range svis {
 		err := DeleteSvi(svi.Name)
@@ -1280,7 +1729,7 @@ func DeleteAllResources() error {
 }
 
 // UpdateSvi updates a svi infradb object
-func UpdateSvi(svi *Svi) error {
+func UpdateSvi(svi *Svi, opts ...WriteOption) error {
 	globalLock.Lock()
 	defer globalLock.Unlock()
 
@@ -1290,12 +1739,28 @@ func UpdateSvi(svi *Svi) error {
 		return errors.New("no subscribers found for svi")
 	}
 
-	err := infradb.client.Set(svi.Name, svi)
+	// The proxy ARP and the secondary addresses are not part of the opi-api spec of the update
+	stored := Svi{}
+	found, err := infradb.client.Get(svi.Name, &stored)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
+	if err := checkIfMatch(svi.Name, stored.ResourceVersion, opts); err != nil {
+		return err
+	}
+	if found && stored.Spec != nil {
+		svi.Spec.ProxyArp = stored.Spec.ProxyArp
+		svi.Spec.SecondaryIPs = stored.Spec.SecondaryIPs
+	}
 
+	err = infradb.client.Set(svi.Name, svi)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	notifyLifecycle(StatusEventUpdated, "svi", svi.Name, svi.ResourceVersion)
 	taskmanager.TaskMan.CreateTask(svi.Name, "svi", svi.ResourceVersion, subscribers)
 
 	return nil
@@ -1344,6 +1809,7 @@ func UpdateSviStatus(name string, resourceVersion string, notificationID string,
 	}
 
 	// Set the state of the component
+	before := svi.Status.SviOperStatus
 	svi.setComponentState(component)
 
 	// Check if all the components are in Success state
@@ -1405,6 +1871,20 @@ func UpdateSviStatus(name string, resourceVersion string, notificationID string,
 				return err
 			}
 
+			// Drop the addresses handed out from the subnets of the SVI
+			err = infradb.client.Delete(allocationsKey(svi.Name))
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+
+			// Free the interface name of the SVI
+			err = releaseIfNames(svi.Name)
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+
 			// Delete the SVI from the svis map
 			svis := make(map[string]bool)
 			found, err = infradb.client.Get("svis", &svis)
@@ -1443,6 +1923,8 @@ func UpdateSviStatus(name string, resourceVersion string, notificationID string,
 		log.Printf("UpdateSviStatus(): SVI %s has been updated: %+v\n", name, svi)
 	}
 
+	notifyStatus("svi", svi.Name, svi.ResourceVersion, int32(before), int32(svi.Status.SviOperStatus),
+		allCompSuccess && svi.Status.SviOperStatus == SviOperStatusToBeDeleted, component)
 	taskmanager.TaskMan.StatusUpdated(svi.Name, "svi", svi.ResourceVersion, notificationID, false, &component)
 
 	return nil
@@ -10,9 +10,9 @@ import (
 	"time"
 )
 
-// EvpnObject is an interface for all domain objects in evpn-gw
-type EvpnObject[T any] interface {
-	ToPb() T
+// EvpnObject is an interface for all domain objects in evpn-gw, they know nothing
+// of the protobuf messages which are converted to and from them by pkg/pbconv
+type EvpnObject interface {
 	GetName() string
 }
 
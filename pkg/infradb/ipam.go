@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"log"
+	"math/big"
+	"net"
+	"sort"
+)
+
+var (
+	// ErrIPAddressInUse the address is already allocated or reserved
+	ErrIPAddressInUse = errors.New("the IP address is already allocated or reserved")
+	// ErrIPAddressOutOfSubnet the address is not part of the subnets of the svi
+	ErrIPAddressOutOfSubnet = errors.New("the IP address is not part of the subnets of the SVI")
+	// ErrIPPoolExhausted no address is left in the subnets of the svi
+	ErrIPPoolExhausted = errors.New("no IP address is left in the subnets of the SVI")
+	// ErrSviUnnumbered the svi is link-local only and has no subnet to allocate from
+	ErrSviUnnumbered = errors.New("the SVI is link-local only and has no subnet")
+)
+
+// maxAllocationScan bounds the search of a free address in large (IPv6) subnets
+const maxAllocationScan = 1 << 16
+
+// IPAllocation is an address handed out from the subnet of an svi
+type IPAllocation struct {
+	Address net.IP
+	Svi     string
+	// Owner identifies the consumer of the address, allocating again for the same owner returns the same address
+	Owner string
+	// MacAddress is the optional MAC of the consumer, used for static DHCP host entries
+	MacAddress string
+}
+
+// allocationsKey returns the key of the DB map holding the allocations of the svi
+func allocationsKey(sviName string) string {
+	return sviName + "/allocations"
+}
+
+// getAllocations returns the allocations of the svi by address, the caller must hold the global lock
+func getAllocations(sviName string) (map[string]*IPAllocation, error) {
+	allocations := make(map[string]*IPAllocation)
+	if _, err := infradb.client.Get(allocationsKey(sviName), &allocations); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return allocations, nil
+}
+
+// isReserved tells whether the address is the network, broadcast, a gateway or a secondary address of the svi
+func isReserved(svi *Svi, subnet *net.IPNet, ip net.IP) bool {
+	if ip.Equal(subnet.IP.Mask(subnet.Mask)) {
+		return true
+	}
+	if ip.To4() != nil {
+		broadcast := make(net.IP, net.IPv4len)
+		for i, b := range subnet.IP.To4().Mask(subnet.Mask) {
+			broadcast[i] = b | ^subnet.Mask[len(subnet.Mask)-net.IPv4len+i]
+		}
+		if ip.Equal(broadcast) {
+			return true
+		}
+	}
+	for _, addr := range svi.Spec.Addresses() {
+		if ip.Equal(addr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextIP returns the address following ip
+func nextIP(ip net.IP) net.IP {
+	n := new(big.Int).Add(new(big.Int).SetBytes(ip), big.NewInt(1)).Bytes()
+	next := make(net.IP, len(ip))
+	copy(next[len(next)-len(n):], n)
+	return next
+}
+
+// sviSubnetFor returns the subnet of the svi holding the address
+func sviSubnetFor(svi *Svi, ip net.IP) *net.IPNet {
+	for _, gw := range svi.Spec.GatewayIPs {
+		subnet := &net.IPNet{IP: gw.IP.Mask(gw.Mask), Mask: gw.Mask}
+		if subnet.Contains(ip) {
+			return subnet
+		}
+	}
+	return nil
+}
+
+// AllocateIP hands out an address from the subnets of the svi, either the requested one or
+// the first free one, skipping the network, broadcast and gateway addresses
+func AllocateIP(sviName string, owner string, mac string, requested net.IP) (*IPAllocation, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	svi := Svi{}
+	found, err := infradb.client.Get(sviName, &svi)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	if svi.Spec.IsUnnumbered() {
+		return nil, ErrSviUnnumbered
+	}
+	allocations, err := getAllocations(sviName)
+	if err != nil {
+		return nil, err
+	}
+	if owner != "" {
+		for _, a := range allocations {
+			if a.Owner == owner && (requested == nil || requested.Equal(a.Address)) {
+				return a, nil
+			}
+		}
+	}
+
+	var address net.IP
+	if requested != nil {
+		subnet := sviSubnetFor(&svi, requested)
+		if subnet == nil {
+			return nil, ErrIPAddressOutOfSubnet
+		}
+		if _, ok := allocations[requested.String()]; ok || isReserved(&svi, subnet, requested) {
+			return nil, ErrIPAddressInUse
+		}
+		address = requested
+	} else {
+		for _, gw := range svi.Spec.GatewayIPs {
+			subnet := &net.IPNet{IP: gw.IP.Mask(gw.Mask), Mask: gw.Mask}
+			ip := subnet.IP
+			for i := 0; i < maxAllocationScan && subnet.Contains(ip); i++ {
+				if _, ok := allocations[ip.String()]; !ok && !isReserved(&svi, subnet, ip) {
+					address = ip
+					break
+				}
+				ip = nextIP(ip)
+			}
+			if address != nil {
+				break
+			}
+		}
+		if address == nil {
+			return nil, ErrIPPoolExhausted
+		}
+	}
+
+	allocation := &IPAllocation{Address: address, Svi: sviName, Owner: owner, MacAddress: mac}
+	allocations[address.String()] = allocation
+	if err := infradb.client.Set(allocationsKey(sviName), allocations); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	log.Printf("AllocateIP(): Allocated %s from SVI %s to %s\n", address, sviName, owner)
+	return allocation, nil
+}
+
+// ReleaseIP returns the address to the subnets of the svi
+func ReleaseIP(sviName string, address net.IP) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	allocations, err := getAllocations(sviName)
+	if err != nil {
+		return err
+	}
+	if _, ok := allocations[address.String()]; !ok {
+		return ErrKeyNotFound
+	}
+	delete(allocations, address.String())
+	if err := infradb.client.Set(allocationsKey(sviName), allocations); err != nil {
+		log.Println(err)
+		return err
+	}
+	log.Printf("ReleaseIP(): Released %s of SVI %s\n", address, sviName)
+	return nil
+}
+
+// GetAllIPAllocations returns the allocations of the svi sorted by address
+func GetAllIPAllocations(sviName string) ([]*IPAllocation, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	svi := Svi{}
+	found, err := infradb.client.Get(sviName, &svi)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	allocations, err := getAllocations(sviName)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*IPAllocation, 0, len(allocations))
+	for _, a := range allocations {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return new(big.Int).SetBytes(out[i].Address.To16()).Cmp(new(big.Int).SetBytes(out[j].Address.To16())) < 0
+	})
+	return out, nil
+}
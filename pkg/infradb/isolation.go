@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"log"
+	"sort"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+)
+
+// IsolatedIn tells whether the bridge port is isolated in the logical bridge: its own flag when it is set,
+// otherwise the access ports follow the default of their logical bridge and the trunks, usually the
+// uplinks, are not isolated
+func (in *BridgePortSpec) IsolatedIn(lb *LogicalBridgeSpec) bool {
+	if in.Isolated != nil {
+		return *in.Isolated
+	}
+	return in.Ptype == Access && lb.IsolatedPorts
+}
+
+// SetBridgePortIsolation sets the isolation of the bridge port, nil follows the default of its logical
+// bridges, the bridge port is programmed again
+func SetBridgePortIsolation(name string, isolated *bool, opts ...WriteOption) (*BridgePort, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	subscribers := eventbus.EBus.GetSubscribers("bridge-port")
+	if len(subscribers) == 0 {
+		log.Println("SetBridgePortIsolation(): No subscribers for Bridge Port objects")
+		return nil, errors.New("no subscribers found for bridge port")
+	}
+
+	bp := &BridgePort{}
+	found, err := infradb.client.Get(name, bp)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	if err := checkIfMatch(name, bp.ResourceVersion, opts); err != nil {
+		return nil, err
+	}
+	if bp.Status.BPOperStatus == BridgePortOperStatusToBeDeleted {
+		return nil, ErrBridgePortToBeDeleted
+	}
+	if err := checkNotProfiled(bp, func(p *BridgePortProfile) bool { return p.Isolated != nil }); err != nil {
+		return nil, err
+	}
+
+	bp.Spec.Isolated = isolated
+	if err := reprogramBP(bp, subscribers); err != nil {
+		return nil, err
+	}
+	return bp, nil
+}
+
+// SetLogicalBridgeIsolation sets whether the access ports of the logical bridge are isolated by default,
+// its bridge ports which follow the default are programmed again
+func SetLogicalBridgeIsolation(name string, isolatedPorts bool, opts ...WriteOption) (*LogicalBridge, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	subscribers := eventbus.EBus.GetSubscribers("bridge-port")
+	if len(subscribers) == 0 {
+		log.Println("SetLogicalBridgeIsolation(): No subscribers for Bridge Port objects")
+		return nil, errors.New("no subscribers found for bridge port")
+	}
+
+	lb := &LogicalBridge{}
+	found, err := infradb.client.Get(name, lb)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	if err := checkIfMatch(name, lb.ResourceVersion, opts); err != nil {
+		return nil, err
+	}
+	if lb.Status.LBOperStatus == LogicalBridgeOperStatusToBeDeleted {
+		return nil, ErrLogicalBridgeToBeDeleted
+	}
+	if lb.Spec.IsolatedPorts == isolatedPorts {
+		return lb, nil
+	}
+
+	// The isolation is programmed on the bridge ports, the logical bridge itself is left as it is
+	lb.Spec.IsolatedPorts = isolatedPorts
+	lb.ResourceVersion = generateVersion()
+	err = infradb.client.Set(lb.Name, lb)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	notifyLifecycle(StatusEventUpdated, "logical-bridge", lb.Name, lb.ResourceVersion)
+
+	bpNames := make([]string, 0, len(lb.BridgePorts))
+	for bpName := range lb.BridgePorts {
+		bpNames = append(bpNames, bpName)
+	}
+	sort.Strings(bpNames)
+	for _, bpName := range bpNames {
+		bp := &BridgePort{}
+		found, err := infradb.client.Get(bpName, bp)
+		if err != nil {
+			return nil, err
+		}
+		if !found || bp.Status.BPOperStatus == BridgePortOperStatusToBeDeleted || bp.Spec.Ptype != Access || bp.Spec.Isolated != nil {
+			continue
+		}
+		if err := reprogramBP(bp, subscribers); err != nil {
+			return nil, err
+		}
+	}
+	return lb, nil
+}
+
+// reprogramBP stores the bridge port with a new version and programs it again, the caller must hold the
+// global lock
+func reprogramBP(bp *BridgePort, subscribers []*eventbus.Subscriber) error {
+	for i := range bp.Status.Components {
+		bp.Status.Components[i].CompStatus = common.ComponentStatusPending
+	}
+	bp.ResourceVersion = generateVersion()
+
+	err := infradb.client.Set(bp.Name, bp)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	notifyLifecycle(StatusEventUpdated, "bridge-port", bp.Name, bp.ResourceVersion)
+	taskmanager.TaskMan.CreateTask(bp.Name, "bridge-port", bp.ResourceVersion, subscribers)
+	return nil
+}
@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"path"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+// leasesKey is the key of the DB map holding the leases of the ephemeral resources by resource name
+var leasesKey = registerStoreKey("leases")
+
+// defaultLeaseInterval is the period of the lease sweep when none is configured
+const defaultLeaseInterval = 10 * time.Second
+
+// Lease makes a resource ephemeral: the resource is deleted, and its kernel state torn down,
+// once the lease expires without having been renewed
+type Lease struct {
+	Name     string
+	Duration time.Duration
+	Expires  time.Time
+}
+
+// leaseDeleters delete the resources by collection
+var leaseDeleters = map[string]func(string, ...WriteOption) error{
+	"vrfs":                 DeleteVrf,
+	"bridges":              DeleteLB,
+	"svis":                 DeleteSvi,
+	"ports":                DeleteBP,
+	"routeleaks":           DeleteRouteLeak,
+	"hostroutes":           DeleteHostRoute,
+	"natgateways":          DeleteNatGateway,
+	"dnsforwarders":        DeleteDNSForwarder,
+	"dhcpservers":          DeleteDHCPServer,
+	"routeradvertisements": DeleteRouterAdvertisement,
+	"externalinterfaces":   DeleteExternalInterface,
+	"bonds":                DeleteBond,
+	"portsecurities":       DeletePortSecurity,
+	"dhcpsnoopings":        DeleteDHCPSnooping,
+	"conntrackpolicies":    DeleteConntrackPolicy,
+	"virtualports":         DeleteVirtualPort,
+	"vfrepresentors":       DeleteVfRepresentor,
+	"routingpolicies":      DeleteRoutingPolicy,
+	"vpcpeerings":          DeleteVpcPeering,
+	"flowlogs":             DeleteFlowLog,
+}
+
+// loadLeases returns the leases by resource name, the caller must hold the global lock
+func loadLeases() (map[string]*Lease, error) {
+	leases := make(map[string]*Lease)
+	if _, err := infradb.client.Get(leasesKey, &leases); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return leases, nil
+}
+
+// SetLease gives the resource a lease of the duration from now, it replaces the lease the resource may already have
+func SetLease(name string, duration time.Duration) (*Lease, error) {
+	if _, ok := leaseDeleters[path.Base(path.Dir(name))]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "%s cannot have a lease", name)
+	}
+	if duration <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "the lease duration %v is not positive", duration)
+	}
+	if maxDuration := time.Duration(config.GlobalConfig.Leases.MaxDuration) * time.Second; maxDuration > 0 && duration > maxDuration {
+		return nil, status.Errorf(codes.InvalidArgument, "the lease duration %v is longer than %v", duration, maxDuration)
+	}
+
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	obj := struct{ ResourceVersion string }{}
+	found, err := infradb.client.Get(name, &obj)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	leases, err := loadLeases()
+	if err != nil {
+		return nil, err
+	}
+	lease := &Lease{Name: name, Duration: duration, Expires: time.Now().Add(duration)}
+	leases[name] = lease
+	if err := infradb.client.Set(leasesKey, leases); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+// RenewLease extends the lease of the resource by its duration from now
+func RenewLease(name string) (*Lease, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	leases, err := loadLeases()
+	if err != nil {
+		return nil, err
+	}
+	lease, ok := leases[name]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	lease.Expires = time.Now().Add(lease.Duration)
+	if err := infradb.client.Set(leasesKey, leases); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+// DeleteLease removes the lease of the resource, which then stays until it is deleted
+func DeleteLease(name string) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	leases, err := loadLeases()
+	if err != nil {
+		return err
+	}
+	if _, ok := leases[name]; !ok {
+		return ErrKeyNotFound
+	}
+	delete(leases, name)
+	return infradb.client.Set(leasesKey, leases)
+}
+
+// GetAllLeases returns the leases in the order of their expiry
+func GetAllLeases() ([]*Lease, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	leases, err := loadLeases()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Lease, 0, len(leases))
+	for _, lease := range leases {
+		out = append(out, lease)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Expires.Before(out[j].Expires) })
+	return out, nil
+}
+
+// ExpireLeases deletes the resources whose lease has expired at the given time and returns their names.
+// A resource which cannot be deleted yet, e.g. a VRF which is not empty, keeps its lease and is deleted
+// by a later sweep, the lease of a resource which is already gone is dropped.
+func ExpireLeases(now time.Time) ([]string, error) {
+	expired := []*Lease{}
+	leases, err := GetAllLeases()
+	if err != nil {
+		return nil, err
+	}
+	for _, lease := range leases {
+		if lease.Expires.After(now) {
+			break
+		}
+		expired = append(expired, lease)
+	}
+
+	deleted := []string{}
+	for _, lease := range expired {
+		// the delete functions take the global lock
+		err := leaseDeleters[path.Base(path.Dir(lease.Name))](lease.Name)
+		if err != nil && !errors.Is(err, ErrKeyNotFound) {
+			log.Printf("ExpireLeases(): Failed to delete %s whose lease has expired: %v\n", lease.Name, err)
+			continue
+		}
+		if err == nil {
+			log.Printf("ExpireLeases(): Deleted %s whose lease expired at %v\n", lease.Name, lease.Expires)
+			deleted = append(deleted, lease.Name)
+		}
+		if err := dropLease(lease); err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// forgetLease removes the lease of a resource being deleted, so that a resource created again
+// with the same name is not deleted by the old lease, the caller must hold the global lock
+func forgetLease(name string) {
+	leases, err := loadLeases()
+	if err != nil {
+		return
+	}
+	if _, ok := leases[name]; !ok {
+		return
+	}
+	delete(leases, name)
+	if err := infradb.client.Set(leasesKey, leases); err != nil {
+		log.Printf("forgetLease(): Failed to remove the lease of %s: %v\n", name, err)
+	}
+}
+
+// dropLease removes the expired lease unless it has been renewed meanwhile
+func dropLease(expired *Lease) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	leases, err := loadLeases()
+	if err != nil {
+		return err
+	}
+	if lease, ok := leases[expired.Name]; ok && lease.Expires.Equal(expired.Expires) {
+		delete(leases, expired.Name)
+		return infradb.client.Set(leasesKey, leases)
+	}
+	return nil
+}
+
+// RunLeaseSweep deletes the resources whose lease has expired every leases.interval seconds until the context is done
+func RunLeaseSweep(ctx context.Context) {
+	for {
+		interval := time.Duration(config.GlobalConfig.Leases.Interval) * time.Second
+		if interval <= 0 {
+			interval = defaultLeaseInterval
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		if _, err := ExpireLeases(time.Now()); err != nil {
+			log.Printf("RunLeaseSweep(): %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package infradb exposes the interface for the manipulation of the api objects
+package infradb
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// schemaVersionKey is the key of the schema version of the store
+const schemaVersionKey = "schemaversion"
+
+// schemaVersion is the version of the representation of the objects in the store
+type schemaVersion struct {
+	Version int
+}
+
+// migration brings the store from a schema version to the next one, the caller holds the global lock
+type migration struct {
+	description string
+	migrate     func() error
+}
+
+// migrations[i] migrates the store from the schema version i to i+1. The version 0 stands for the stores
+// written before the schema was versioned. Append the migrations, never change or remove a released one.
+var migrations = []migration{
+	{
+		description: "give a resource version to the objects stored without one",
+		migrate:     migrateResourceVersions,
+	},
+	{
+		description: "index the VLAN IDs used by the logical bridges",
+		migrate:     migrateVlans,
+	},
+}
+
+// storeKeys are the keys of the store holding a table rather than an object, the objects are
+// gathered from the indexes of their kind
+var storeKeys = []string{schemaVersionKey, "vpns", "rts"}
+
+// registerStoreKey adds the key of a table to the dumps of the store and returns it
+func registerStoreKey(key string) string {
+	storeKeys = append(storeKeys, key)
+	return key
+}
+
+// SchemaVersion returns the schema version of the store written by this release
+func SchemaVersion() int {
+	return len(migrations)
+}
+
+// nameIndexes returns the keys of the maps in the store holding the names of the objects
+func nameIndexes() []string {
+	indexes := []string{"vrfs", "lbs", "bps", "svis"}
+	for _, kind := range resourceKinds {
+		indexes = append(indexes, kind.indexKey)
+	}
+	return indexes
+}
+
+// storedNames returns the names of the objects listed in the index, the caller must hold the global lock
+func storedNames(index string) ([]string, error) {
+	names := make(map[string]bool)
+	if _, err := infradb.client.Get(index, &names); err != nil {
+		return nil, err
+	}
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+// dumpStore reads the raw value of every key of the store, the caller must hold the global lock.
+// The store has no listing of its keys, they are the registered tables and the objects gathered
+// from their indexes.
+func dumpStore() (map[string]json.RawMessage, error) {
+	keys := append([]string{}, storeKeys...)
+	keys = append(keys, nameIndexes()...)
+	for _, index := range nameIndexes() {
+		names, err := storedNames(index)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, names...)
+		if index == "svis" {
+			for _, name := range names {
+				keys = append(keys, allocationsKey(name))
+			}
+		}
+	}
+	dump := make(map[string]json.RawMessage)
+	for _, key := range keys {
+		var raw json.RawMessage
+		found, err := infradb.client.Get(key, &raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		if found {
+			dump[key] = raw
+		}
+	}
+	return dump, nil
+}
+
+// writeBackup saves the dump of the store of the schema version into a new file of the directory
+func writeBackup(dir string, version int, dump map[string]json.RawMessage) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	// the random suffix keeps the backups of the migrations run within the same second
+	file, err := os.CreateTemp(dir, fmt.Sprintf("infradb-v%d-%s-*.json", version, time.Now().UTC().Format("20060102T150405Z")))
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return "", err
+	}
+	return file.Name(), file.Close()
+}
+
+// restoreStore writes back the dump of the store and deletes the keys which are not in the dump,
+// e.g. the objects created after it, the caller must hold the global lock
+func restoreStore(dump map[string]json.RawMessage) error {
+	current, err := dumpStore()
+	if err != nil {
+		return err
+	}
+	for key, raw := range dump {
+		if err := infradb.client.Set(key, raw); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", key, err)
+		}
+	}
+	for key := range current {
+		if _, ok := dump[key]; ok {
+			continue
+		}
+		if err := infradb.client.Delete(key); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// RestoreBackup writes back into the store a backup taken before a migration, e.g. to downgrade
+// to the release which wrote it. The store is restored as it was, the objects created after the
+// backup are deleted.
+func RestoreBackup(file string) error {
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return err
+	}
+	dump := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return fmt.Errorf("invalid backup %s: %w", file, err)
+	}
+	globalLock.Lock()
+	defer globalLock.Unlock()
+	return restoreStore(dump)
+}
+
+// Migrate brings the store to the schema version of this release at start up. The store is saved
+// into backupDir before the first migration and restored when a migration fails, so that the
+// previous release can still run on it. An empty store is stamped with the current version, a store
+// written by a newer release is refused.
+func Migrate(backupDir string) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	stored := schemaVersion{}
+	if _, err := infradb.client.Get(schemaVersionKey, &stored); err != nil {
+		return err
+	}
+	switch {
+	case stored.Version == SchemaVersion():
+		return nil
+	case stored.Version > SchemaVersion():
+		return fmt.Errorf("the store has the schema version %d of a newer release, this release supports up to %d",
+			stored.Version, SchemaVersion())
+	}
+
+	dump, err := dumpStore()
+	if err != nil {
+		return err
+	}
+	if len(dump) == 0 {
+		log.Printf("Migrate(): new store, schema version %d\n", SchemaVersion())
+		return infradb.client.Set(schemaVersionKey, &schemaVersion{Version: SchemaVersion()})
+	}
+	backup, err := writeBackup(backupDir, stored.Version, dump)
+	if err != nil {
+		return fmt.Errorf("failed to back up the store before its migration: %w", err)
+	}
+	log.Printf("Migrate(): saved the store of schema version %d into %s\n", stored.Version, backup)
+
+	for version := stored.Version; version < SchemaVersion(); version++ {
+		log.Printf("Migrate(): schema version %d to %d: %s\n", version, version+1, migrations[version].description)
+		if err := migrations[version].migrate(); err != nil {
+			if rerr := restoreStore(dump); rerr != nil {
+				return fmt.Errorf("migration to schema version %d failed: %w, restoring %s failed: %v", version+1, err, backup, rerr)
+			}
+			return fmt.Errorf("migration to schema version %d failed, the store has been restored: %w", version+1, err)
+		}
+	}
+	return infradb.client.Set(schemaVersionKey, &schemaVersion{Version: SchemaVersion()})
+}
+
+// migrateResourceVersions gives a resource version to the objects written before the etags,
+// which the concurrency control would otherwise take for missing objects
+func migrateResourceVersions() error {
+	for _, index := range nameIndexes() {
+		names, err := storedNames(index)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			obj := make(map[string]json.RawMessage)
+			found, err := infradb.client.Get(name, &obj)
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+			var version string
+			if raw, ok := obj["ResourceVersion"]; ok {
+				if err := json.Unmarshal(raw, &version); err != nil {
+					return fmt.Errorf("invalid resource version of %s: %w", name, err)
+				}
+			}
+			if version != "" {
+				continue
+			}
+			if obj["ResourceVersion"], err = json.Marshal(generateVersion()); err != nil {
+				return err
+			}
+			if err := infradb.client.Set(name, obj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+const testMigrationVrf = "//network.opiproject.org/vrfs/blue"
+
+// legacyVrf is a vrf written before the resource versions
+type legacyVrf struct {
+	Name string
+}
+
+func newLegacyStore(t *testing.T) {
+	t.Helper()
+	if err := NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.client.Set("vrfs", map[string]bool{testMigrationVrf: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.client.Set(testMigrationVrf, legacyVrf{Name: testMigrationVrf}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func storedVersion(t *testing.T) int {
+	t.Helper()
+	stored := schemaVersion{}
+	if _, err := infradb.client.Get(schemaVersionKey, &stored); err != nil {
+		t.Fatal(err)
+	}
+	return stored.Version
+}
+
+func backups(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "infradb-v*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func Test_MigrateNewStore(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(dir); err != nil {
+		t.Fatal(err)
+	}
+	if storedVersion(t) != SchemaVersion() {
+		t.Errorf("expected the schema version %d, got %d", SchemaVersion(), storedVersion(t))
+	}
+	if len(backups(t, dir)) != 0 {
+		t.Error("expected no backup of an empty store")
+	}
+}
+
+func Test_MigrateLegacyStore(t *testing.T) {
+	dir := t.TempDir()
+	newLegacyStore(t)
+	if err := Migrate(dir); err != nil {
+		t.Fatal(err)
+	}
+	if storedVersion(t) != SchemaVersion() {
+		t.Errorf("expected the schema version %d, got %d", SchemaVersion(), storedVersion(t))
+	}
+	vrf := Vrf{}
+	if _, err := infradb.client.Get(testMigrationVrf, &vrf); err != nil {
+		t.Fatal(err)
+	}
+	if vrf.ResourceVersion == "" || vrf.Name != testMigrationVrf {
+		t.Errorf("expected the vrf to be kept with a resource version, got %+v", vrf)
+	}
+
+	// the objects and the tables written after the backup are not restored
+	const newVrf = "//network.opiproject.org/vrfs/red"
+	if err := infradb.client.Set("vrfs", map[string]bool{testMigrationVrf: true, newVrf: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.client.Set(newVrf, Vrf{Name: newVrf}); err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.client.Set(leasesKey, map[string]string{newVrf: "lease"}); err != nil {
+		t.Fatal(err)
+	}
+
+	files := backups(t, dir)
+	if len(files) != 1 {
+		t.Fatalf("expected one backup, got %v", files)
+	}
+	if err := RestoreBackup(files[0]); err != nil {
+		t.Fatal(err)
+	}
+	vrf = Vrf{}
+	if _, err := infradb.client.Get(testMigrationVrf, &vrf); err != nil {
+		t.Fatal(err)
+	}
+	if vrf.ResourceVersion != "" || storedVersion(t) != 0 {
+		t.Errorf("expected the backup to restore the legacy store, got %+v version %d", vrf, storedVersion(t))
+	}
+	for _, key := range []string{newVrf, leasesKey} {
+		if found, err := infradb.client.Get(key, &map[string]interface{}{}); err != nil || found {
+			t.Errorf("expected %s to be deleted by the restore, found %v: %v", key, found, err)
+		}
+	}
+	if names, err := storedNames("vrfs"); err != nil || len(names) != 1 {
+		t.Errorf("expected the index of the backup, got %v: %v", names, err)
+	}
+
+	// a migrated store is left alone
+	if err := Migrate(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(dir); err != nil {
+		t.Fatal(err)
+	}
+	if len(backups(t, dir)) != 2 {
+		t.Error("expected a single backup per migration")
+	}
+}
+
+func Test_MigrateFailure(t *testing.T) {
+	saved := migrations
+	defer func() { migrations = saved }()
+	migrations = append(append([]migration{}, saved...), migration{
+		description: "fail",
+		migrate:     func() error { return errors.New("broken") },
+	})
+
+	newLegacyStore(t)
+	if err := Migrate(t.TempDir()); err == nil {
+		t.Fatal("expected the migration to fail")
+	}
+	vrf := Vrf{}
+	if _, err := infradb.client.Get(testMigrationVrf, &vrf); err != nil {
+		t.Fatal(err)
+	}
+	if vrf.ResourceVersion != "" || storedVersion(t) != 0 {
+		t.Errorf("expected the store to be restored, got %+v version %d", vrf, storedVersion(t))
+	}
+}
+
+func Test_MigrateNewerStore(t *testing.T) {
+	if err := NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.client.Set(schemaVersionKey, schemaVersion{Version: SchemaVersion() + 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(t.TempDir()); err == nil {
+		t.Error("expected a store of a newer release to be refused")
+	}
+}
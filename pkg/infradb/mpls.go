@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+var (
+	// ErrMplsUnsupported the MPLS dataplane is disabled or the routing backend cannot carry it
+	ErrMplsUnsupported = errors.New("the MPLS dataplane is not supported")
+)
+
+const (
+	// minMplsLabel is the first label which is not reserved
+	minMplsLabel = 16
+	// maxMplsLabel is the highest 20 bits label
+	maxMplsLabel = 1<<20 - 1
+)
+
+// MplsSpec selects the MPLS dataplane for a VPC, which is then carried over the MPLS core as a BGP/MPLS
+// IP VPN instead of its L3 VNI
+type MplsSpec struct {
+	// Label is the service label of the routes of the VPC, allocated by the routing backend when 0
+	Label uint32
+}
+
+// validate checks the service label
+func (in *MplsSpec) validate() error {
+	if in.Label != 0 && (in.Label < minMplsLabel || in.Label > maxMplsLabel) {
+		return fmt.Errorf("mpls label %d must be between %d and %d", in.Label, minMplsLabel, maxMplsLabel)
+	}
+	return nil
+}
+
+// NewMplsSpec returns the validated MPLS dataplane
+func NewMplsSpec(label uint32) (*MplsSpec, error) {
+	in := &MplsSpec{Label: label}
+	if err := in.validate(); err != nil {
+		return nil, fmt.Errorf("NewMplsSpec(): %w", err)
+	}
+	return in, nil
+}
+
+// IsMpls tells whether the VPC is carried over the MPLS core
+func (in *VrfSpec) IsMpls() bool {
+	return in.Vni != nil && in.Mpls != nil
+}
+
+// checkMplsSupport checks that the MPLS core is configured and that the routing backend carries the VPCs over it
+func checkMplsSupport() error {
+	if !config.GlobalConfig.Mpls.Enabled {
+		return fmt.Errorf("%w: mpls is not enabled in the config", ErrMplsUnsupported)
+	}
+	backend, err := routing.Get()
+	if err != nil {
+		return err
+	}
+	if _, ok := backend.(routing.MplsConfigurer); !ok {
+		return fmt.Errorf("%w: the %s routing backend does not carry the VPCs over MPLS", ErrMplsUnsupported, backend.Name())
+	}
+	return nil
+}
+
+// SetVrfMpls carries the VPC of the vrf over the MPLS core, nil is back to VXLAN, the vrf is programmed
+// again over its new dataplane
+func SetVrfMpls(name string, mpls *MplsSpec, opts ...WriteOption) (*Vrf, error) {
+	if mpls != nil {
+		if err := mpls.validate(); err != nil {
+			return nil, fmt.Errorf("SetVrfMpls(): %w", err)
+		}
+		if err := checkMplsSupport(); err != nil {
+			return nil, err
+		}
+	}
+	return setVrfDataplane("SetVrfMpls", name, func(vrf *Vrf) error {
+		if mpls != nil && vrf.Spec.Netns != "" {
+			return ErrNetnsVpn
+		}
+		vrf.Spec.Mpls, vrf.Spec.Srv6 = mpls, nil
+		return nil
+	}, opts...)
+}
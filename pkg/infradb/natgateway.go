@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+// PortRange holds a range of L4 ports
+type PortRange struct {
+	Min uint16
+	Max uint16
+}
+
+// DnatRule holds a destination NAT rule
+type DnatRule struct {
+	// Protocol is either tcp or udp
+	Protocol     string
+	ExternalPort uint16
+	InternalIP   net.IP
+	InternalPort uint16
+}
+
+// NatGatewaySpec holds NAT Gateway Spec
+type NatGatewaySpec struct {
+	Vrf string
+	// ExternalInterface is the linux interface towards the external network
+	ExternalInterface string
+	// SnatIP is the address that the traffic is translated to,
+	// when empty the traffic is masqueraded behind the external interface address
+	SnatIP net.IP
+	// Subnets are the source prefixes to be translated,
+	// when empty the gateway prefixes of the SVIs of the VRF are used
+	Subnets   []*net.IPNet
+	PortRange *PortRange
+	DnatRules []*DnatRule
+}
+
+// NatGateway holds NAT Gateway info
+type NatGateway struct {
+	Resource
+	Spec *NatGatewaySpec
+}
+
+// natGatewayKind describes the storage of the NAT Gateway objects
+var natGatewayKind = registerKind(resourceKind{
+	eventType: "nat-gateway",
+	indexKey:  "natgateways",
+	newObject: func() resourceObject { return &NatGateway{} },
+	references: func(obj resourceObject) []string {
+		return []string{obj.(*NatGateway).Spec.Vrf}
+	},
+})
+
+// validate checks the NAT Gateway Spec
+func (in *NatGatewaySpec) validate() error {
+	if in.Vrf == "" || in.ExternalInterface == "" {
+		return fmt.Errorf("NAT Gateway needs a VRF and an external interface")
+	}
+	if in.SnatIP != nil && in.SnatIP.To4() == nil {
+		return fmt.Errorf("NAT Gateway SNAT IP %s is not an IPv4 address", in.SnatIP)
+	}
+	for _, subnet := range in.Subnets {
+		if subnet.IP.To4() == nil {
+			return fmt.Errorf("NAT Gateway subnet %s is not an IPv4 prefix", subnet)
+		}
+	}
+	if in.PortRange != nil && (in.PortRange.Min == 0 || in.PortRange.Min > in.PortRange.Max) {
+		return fmt.Errorf("NAT Gateway port range %d-%d is invalid", in.PortRange.Min, in.PortRange.Max)
+	}
+	for _, rule := range in.DnatRules {
+		proto := strings.ToLower(rule.Protocol)
+		if proto != "tcp" && proto != "udp" {
+			return fmt.Errorf("NAT Gateway DNAT protocol %s is not supported", rule.Protocol)
+		}
+		if rule.ExternalPort == 0 || rule.InternalPort == 0 || rule.InternalIP.To4() == nil {
+			return fmt.Errorf("NAT Gateway DNAT rule %+v is invalid", rule)
+		}
+		rule.Protocol = proto
+	}
+	return nil
+}
+
+// NewNatGateway creates new NAT Gateway object
+func NewNatGateway(name string, spec *NatGatewaySpec) (*NatGateway, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewNatGateway(): NAT Gateway spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("NewNatGateway(): %v", err)
+	}
+
+	res, err := newResource(name, natGatewayKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NatGateway{Resource: res, Spec: spec}, nil
+}
+
+// CreateNatGateway creates an infradb nat gateway object
+func CreateNatGateway(nat *NatGateway) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	if err := checkVrfExists(nat.Spec.Vrf); err != nil {
+		return err
+	}
+	return natGatewayKind.create(nat)
+}
+
+// DeleteNatGateway deletes a nat gateway infradb object
+func DeleteNatGateway(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	nat := &NatGateway{}
+	if err := natGatewayKind.get(name, nat); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, nat.ResourceVersion, opts); err != nil {
+		return err
+	}
+	return natGatewayKind.delete(nat)
+}
+
+// GetNatGateway returns an infradb nat gateway object
+func GetNatGateway(name string) (*NatGateway, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	nat := &NatGateway{}
+	err := natGatewayKind.get(name, nat)
+	return nat, err
+}
+
+// GetAllNatGateways returns a list of nat gateways from the DB
+func GetAllNatGateways() ([]*NatGateway, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	nats := []*NatGateway{}
+	names, err := natGatewayKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		nat := &NatGateway{}
+		if err := natGatewayKind.get(name, nat); err != nil {
+			return nil, err
+		}
+		nats = append(nats, nat)
+	}
+	return nats, nil
+}
+
+// UpdateNatGatewayStatus updates the status of nat gateway object based on the component report
+func UpdateNatGatewayStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return natGatewayKind.updateStatus(&NatGateway{}, name, resourceVersion, notificationID, component)
+}
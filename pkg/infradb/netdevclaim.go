@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"log"
+	"path"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+// netdevClaimsKey is the key of the DB map holding the netdevs claimed by the other OPI bridges by name
+var netdevClaimsKey = registerStoreKey("netdevclaims")
+
+// Usages of a claimed netdev
+const (
+	// NetdevExclusive keeps the netdev out of the tenant bridges
+	NetdevExclusive = "exclusive"
+	// NetdevShared lets a Bridge Port attach the netdev, e.g. a storage VF also carrying tenant traffic
+	NetdevShared = "shared"
+)
+
+var (
+	// ErrStorageDisabled the netdev claims are refused when the storage integration is disabled
+	ErrStorageDisabled = apierrors.FailedPrecondition(apierrors.ReasonNotConfigured, "storage", "the storage integration is disabled")
+	// ErrNetdevClaimed the netdev is claimed by another owner
+	ErrNetdevClaimed = apierrors.FailedPrecondition(apierrors.ReasonInUse, "netdev", "the netdev is claimed by another OPI bridge")
+	// ErrNetdevInUse the netdev is already attached by a Bridge Port
+	ErrNetdevInUse = apierrors.FailedPrecondition(apierrors.ReasonInUse, "netdev", "the netdev is already used by a Bridge Port")
+)
+
+// NetdevClaim records that a netdev of the DPU is managed by another OPI bridge, e.g. the NVMe/TCP
+// interfaces of opi-spdk-bridge, so that the two bridges do not program the same netdev
+type NetdevClaim struct {
+	Netdev string
+	// Owner identifies the claiming bridge, e.g. opi-spdk-bridge
+	Owner string
+	// Usage is either exclusive or shared
+	Usage string
+}
+
+// loadNetdevClaims returns the claimed netdevs by name, the caller must hold the global lock
+func loadNetdevClaims() (map[string]*NetdevClaim, error) {
+	claims := make(map[string]*NetdevClaim)
+	if _, err := infradb.client.Get(netdevClaimsKey, &claims); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return claims, nil
+}
+
+// checkNetdevClaim checks that the netdev of the Bridge Port is not kept out of the tenant
+// bridges by another OPI bridge, the caller must hold the global lock
+func checkNetdevClaim(bpName string) error {
+	if !config.GlobalConfig.Storage.Enabled {
+		return nil
+	}
+	claims, err := loadNetdevClaims()
+	if err != nil {
+		return err
+	}
+	if claim, ok := claims[path.Base(bpName)]; ok && claim.Usage == NetdevExclusive {
+		log.Printf("checkNetdevClaim(): %s is claimed by %s\n", claim.Netdev, claim.Owner)
+		return ErrNetdevClaimed
+	}
+	return nil
+}
+
+// ClaimNetdev claims a netdev for another OPI bridge, claiming it again for the same owner updates its usage
+func ClaimNetdev(claim *NetdevClaim) error {
+	if !config.GlobalConfig.Storage.Enabled {
+		return ErrStorageDisabled
+	}
+	if claim.Netdev == "" || claim.Owner == "" {
+		return status.Error(codes.InvalidArgument, "a netdev claim needs a netdev and an owner")
+	}
+	switch claim.Usage {
+	case "":
+		claim.Usage = NetdevExclusive
+	case NetdevExclusive, NetdevShared:
+	default:
+		return status.Errorf(codes.InvalidArgument, "netdev usage %q is not supported", claim.Usage)
+	}
+
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	claims, err := loadNetdevClaims()
+	if err != nil {
+		return err
+	}
+	if existing, ok := claims[claim.Netdev]; ok && existing.Owner != claim.Owner {
+		log.Printf("ClaimNetdev(): %s is already claimed by %s\n", claim.Netdev, existing.Owner)
+		return ErrNetdevClaimed
+	}
+	if claim.Usage == NetdevExclusive {
+		bpsMap := make(map[string]bool)
+		if _, err := infradb.client.Get("bps", &bpsMap); err != nil {
+			log.Println(err)
+			return err
+		}
+		for bpName := range bpsMap {
+			if path.Base(bpName) == claim.Netdev {
+				log.Printf("ClaimNetdev(): %s is used by Bridge Port %s\n", claim.Netdev, bpName)
+				return ErrNetdevInUse
+			}
+		}
+	}
+	claims[claim.Netdev] = claim
+	return infradb.client.Set(netdevClaimsKey, claims)
+}
+
+// ReleaseNetdev releases the claim of the owner on the netdev
+func ReleaseNetdev(netdev string, owner string) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	claims, err := loadNetdevClaims()
+	if err != nil {
+		return err
+	}
+	claim, ok := claims[netdev]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	if owner != "" && claim.Owner != owner {
+		log.Printf("ReleaseNetdev(): %s is claimed by %s and not by %s\n", netdev, claim.Owner, owner)
+		return ErrNetdevClaimed
+	}
+	delete(claims, netdev)
+	return infradb.client.Set(netdevClaimsKey, claims)
+}
+
+// GetAllNetdevClaims returns the claimed netdevs in the order of their names
+func GetAllNetdevClaims() ([]*NetdevClaim, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	claims, err := loadNetdevClaims()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*NetdevClaim, 0, len(claims))
+	for _, claim := range claims {
+		out = append(out, claim)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Netdev < out[j].Netdev })
+	return out, nil
+}
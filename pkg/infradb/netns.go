@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+var (
+	// ErrNetnsVpn the vrfs in a network namespace are only carried over VXLAN
+	ErrNetnsVpn = errors.New("a vrf in a network namespace is only carried over VXLAN")
+	// ErrNetnsGrd the GRD is the routing table of the namespace of the bridge
+	ErrNetnsGrd = errors.New("the GRD cannot move to another network namespace")
+)
+
+// SetVrfNetns moves the devices of the vrf to the named network namespace, the one of the bridge when
+// the name is empty, then the vrf is programmed again. The svis of the vrf follow it, so the namespace
+// only changes while the vrf has none.
+func SetVrfNetns(name string, netns string, opts ...WriteOption) (*Vrf, error) {
+	if netns != "" {
+		if err := utils.ValidateNetnsName(netns); err != nil {
+			return nil, fmt.Errorf("SetVrfNetns(): %w", err)
+		}
+	}
+
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	subscribers := eventbus.EBus.GetSubscribers("vrf")
+	if len(subscribers) == 0 {
+		log.Println("SetVrfNetns(): No subscribers for Vrf objects")
+		return nil, errors.New("no subscribers found for vrf")
+	}
+
+	vrf := &Vrf{}
+	found, err := infradb.client.Get(name, vrf)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	if err := checkIfMatch(name, vrf.ResourceVersion, opts); err != nil {
+		return nil, err
+	}
+	if vrf.Spec.Netns == netns {
+		return vrf, nil
+	}
+	switch {
+	case vrf.Status.VrfOperStatus == VrfOperStatusToBeDeleted:
+		return nil, ErrVrfToBeDeleted
+	case path.Base(vrf.Name) == "GRD":
+		return nil, ErrNetnsGrd
+	case netns != "" && vrf.Spec.IsVpn():
+		return nil, ErrNetnsVpn
+	case len(vrf.Svis) != 0:
+		return nil, ErrVrfNotEmpty
+	}
+
+	vrf.Spec.Netns = netns
+	for i := range vrf.Status.Components {
+		vrf.Status.Components[i].CompStatus = common.ComponentStatusPending
+	}
+	vrf.ResourceVersion = generateVersion()
+
+	err = infradb.client.Set(vrf.Name, vrf)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	notifyLifecycle(StatusEventUpdated, "vrf", vrf.Name, vrf.ResourceVersion)
+	taskmanager.TaskMan.CreateTask(vrf.Name, "vrf", vrf.ResourceVersion, subscribers)
+
+	return vrf, nil
+}
+
+// SviNetns returns the network namespace of the svi, the one of its vrf
+func SviNetns(svi *Svi) string {
+	vrf, err := GetVrf(svi.Spec.Vrf)
+	if err != nil {
+		return ""
+	}
+	return vrf.Spec.Netns
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"log"
+)
+
+// ErrParentConflict the object is owned by another parent, or is shared
+var ErrParentConflict = errors.New("the object is owned by another parent")
+
+// parentsKey is the key of the map holding the parent of the objects created on behalf of a tenant
+var parentsKey = registerStoreKey("parents")
+
+// getParents returns the map of the parents, the caller holds the global lock
+func getParents() (map[string]string, error) {
+	parents := make(map[string]string)
+	if _, err := infradb.client.Get(parentsKey, &parents); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return parents, nil
+}
+
+// SetParent records the parent (tenant) which owns the object
+func SetParent(name, parent string) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	parents, err := getParents()
+	if err != nil {
+		return err
+	}
+	parents[name] = parent
+	return infradb.client.Set(parentsKey, &parents)
+}
+
+// ClaimParent records the parent which owns the object about to be created, unless another parent owns
+// it or it already exists without parent, i.e. it is shared. The check and the record are done under the
+// global lock, so that two parents creating the same object concurrently cannot both own it. claimed tells
+// whether the parent has been recorded by this call, rather than being the owner already.
+func ClaimParent(name, parent string) (claimed bool, err error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	parents, err := getParents()
+	if err != nil {
+		return false, err
+	}
+	switch parents[name] {
+	case parent:
+		return false, nil
+	case "":
+	default:
+		return false, ErrParentConflict
+	}
+	version, err := GetResourceVersion(name)
+	if err != nil {
+		return false, err
+	}
+	if version != "" {
+		return false, ErrParentConflict
+	}
+	parents[name] = parent
+	return true, infradb.client.Set(parentsKey, &parents)
+}
+
+// GetParent returns the parent of the object, or an empty parent when the object is not owned by a tenant
+func GetParent(name string) (string, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	parents, err := getParents()
+	if err != nil {
+		return "", err
+	}
+	return parents[name], nil
+}
+
+// DeleteParent forgets the parent of the object
+func DeleteParent(name string) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	parents, err := getParents()
+	if err != nil {
+		return err
+	}
+	if _, ok := parents[name]; !ok {
+		return nil
+	}
+	delete(parents, name)
+	return infradb.client.Set(parentsKey, &parents)
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"log"
+)
+
+// placementsKey is the key of the map holding the node of the objects placed on a single node
+var placementsKey = registerStoreKey("placements")
+
+// getPlacements returns the map of the placements, the caller holds the global lock
+func getPlacements() (map[string]string, error) {
+	placements := make(map[string]string)
+	if _, err := infradb.client.Get(placementsKey, &placements); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return placements, nil
+}
+
+// SetPlacement records the node the object lives on
+func SetPlacement(name, node string) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	placements, err := getPlacements()
+	if err != nil {
+		return err
+	}
+	placements[name] = node
+	return infradb.client.Set(placementsKey, &placements)
+}
+
+// GetPlacement returns the node the object lives on, or an empty node when it lives on all the nodes
+func GetPlacement(name string) (string, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	placements, err := getPlacements()
+	if err != nil {
+		return "", err
+	}
+	return placements[name], nil
+}
+
+// GetPlacements returns the node of all the objects placed on a single node
+func GetPlacements() (map[string]string, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getPlacements()
+}
+
+// DeletePlacement forgets the node of the object
+func DeletePlacement(name string) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	placements, err := getPlacements()
+	if err != nil {
+		return err
+	}
+	if _, ok := placements[name]; !ok {
+		return nil
+	}
+	delete(placements, name)
+	return infradb.client.Set(placementsKey, &placements)
+}
@@ -12,7 +12,6 @@ import (
 	"log"
 	"net"
 
-	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
 )
@@ -55,6 +54,18 @@ type BridgePortSpec struct {
 	Ptype          BridgePortType
 	MacAddress     *net.HardwareAddr
 	LogicalBridges []string
+	// Sflow samples the packets received by the port, it is set with SetBridgePortSflow
+	// as the opi-api Bridge Port has no field for it
+	Sflow *SflowSpec
+	// Qinq maps the double tagged frames of the port to Logical Bridges, it is set with
+	// SetBridgePortQinq
+	Qinq *QinqSpec
+	// Isolated stops the port from forwarding to the other isolated ports of its Logical Bridges,
+	// it is set with SetBridgePortIsolation and follows the Logical Bridges when it is nil
+	Isolated *bool
+	// Profile names the Bridge Port Profile whose settings are copied on the port, it is set with
+	// AttachBridgePortProfile
+	Profile string
 }
 
 // BridgePortMetadata holds Bridge Port Metadata
@@ -76,17 +87,13 @@ type BridgePort struct {
 }
 
 // build time check that struct implements interface
-var _ EvpnObject[*pb.BridgePort] = (*BridgePort)(nil)
+var _ EvpnObject = (*BridgePort)(nil)
 
-// NewBridgePort creates new Bridge Port object from protobuf message
-func NewBridgePort(in *pb.BridgePort) (*BridgePort, error) {
-	var bpType BridgePortType
-	var transTrunk bool
+// NewBridgePort creates new Bridge Port object from its spec, a port without Logical Bridges
+// is a transparent trunk
+func NewBridgePort(name string, spec *BridgePortSpec) (*BridgePort, error) {
 	components := make([]common.Component, 0)
 
-	// Tansform Mac From Byte to net.HardwareAddr type
-	macAddr := net.HardwareAddr(in.Spec.MacAddress)
-
 	subscribers := eventbus.EBus.GetSubscribers("bridge-port")
 	if len(subscribers) == 0 {
 		log.Println("NewBridgePort(): No subscribers for Bridge Port objects")
@@ -98,88 +105,19 @@ func NewBridgePort(in *pb.BridgePort) (*BridgePort, error) {
 		components = append(components, component)
 	}
 
-	if len(in.Spec.LogicalBridges) == 0 {
-		transTrunk = true
-	}
-
-	switch in.Spec.Ptype {
-	case pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS:
-		bpType = Access
-	case pb.BridgePortType_BRIDGE_PORT_TYPE_TRUNK:
-		bpType = Trunk
-	default:
-		bpType = Unspecified
-	}
-
 	return &BridgePort{
-		Name: in.Name,
-		Spec: &BridgePortSpec{
-			Ptype:          bpType,
-			MacAddress:     &macAddr,
-			LogicalBridges: in.Spec.LogicalBridges,
-		},
+		Name: name,
+		Spec: spec,
 		Status: &BridgePortStatus{
 			BPOperStatus: BridgePortOperStatus(BridgePortOperStatusDown),
 			Components:   components,
 		},
 		Metadata:         &BridgePortMetadata{},
-		TransparentTrunk: transTrunk,
+		TransparentTrunk: len(spec.LogicalBridges) == 0,
 		ResourceVersion:  generateVersion(),
 	}, nil
 }
 
-// ToPb transforms Bridge Port object to protobuf message
-func (in *BridgePort) ToPb() *pb.BridgePort {
-	bp := &pb.BridgePort{
-		Name: in.Name,
-		Spec: &pb.BridgePortSpec{
-			MacAddress: *in.Spec.MacAddress,
-		},
-		Status: &pb.BridgePortStatus{},
-	}
-
-	switch in.Spec.Ptype {
-	case Access:
-		bp.Spec.Ptype = pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS
-	case Trunk:
-		bp.Spec.Ptype = pb.BridgePortType_BRIDGE_PORT_TYPE_TRUNK
-	default:
-		bp.Spec.Ptype = pb.BridgePortType_BRIDGE_PORT_TYPE_UNSPECIFIED
-	}
-
-	if !in.TransparentTrunk {
-		bp.Spec.LogicalBridges = in.Spec.LogicalBridges
-	}
-
-	switch in.Status.BPOperStatus {
-	case BridgePortOperStatusDown:
-		bp.Status.OperStatus = pb.BPOperStatus_BP_OPER_STATUS_DOWN
-	case BridgePortOperStatusUp:
-		bp.Status.OperStatus = pb.BPOperStatus_BP_OPER_STATUS_UP
-	case BridgePortOperStatusToBeDeleted:
-		bp.Status.OperStatus = pb.BPOperStatus_BP_OPER_STATUS_TO_BE_DELETED
-	default:
-		bp.Status.OperStatus = pb.BPOperStatus_BP_OPER_STATUS_UNSPECIFIED
-	}
-
-	for _, comp := range in.Status.Components {
-		component := &pb.Component{Name: comp.Name, Details: comp.Details}
-		switch comp.CompStatus {
-		case common.ComponentStatusPending:
-			component.Status = pb.CompStatus_COMP_STATUS_PENDING
-		case common.ComponentStatusSuccess:
-			component.Status = pb.CompStatus_COMP_STATUS_SUCCESS
-		case common.ComponentStatusError:
-			component.Status = pb.CompStatus_COMP_STATUS_ERROR
-		default:
-			component.Status = pb.CompStatus_COMP_STATUS_UNSPECIFIED
-		}
-		bp.Status.Components = append(bp.Status.Components, component)
-	}
-
-	return bp
-}
-
 // GetName returns object unique name
 func (in *BridgePort) GetName() string {
 	return in.Name
@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+var (
+	// ErrBridgePortNotFound the referenced bridge port has not been found
+	ErrBridgePortNotFound = errors.New("the referenced Bridge Port has not been found")
+	// ErrBridgePortInUse the bridge port is still used by a port security
+	ErrBridgePortInUse = errors.New("the Bridge Port is still used by a port security")
+	// ErrPortSecurityInUse the bridge port already has a port security
+	ErrPortSecurityInUse = errors.New("the Bridge Port already has a port security")
+)
+
+// AllowedAddress is a source MAC address allowed on a port, the source IP address sent
+// with the MAC address is checked too when IP is set
+type AllowedAddress struct {
+	MAC net.HardwareAddr
+	IP  net.IP
+}
+
+// PortSecuritySpec holds Port Security Spec
+type PortSecuritySpec struct {
+	BridgePort string
+	// MacLimit bounds the number of MAC addresses learned on the port, zero is unlimited
+	MacLimit uint32
+	// AllowedAddresses restricts the source addresses of the traffic of the port, any source
+	// is allowed when empty
+	AllowedAddresses []*AllowedAddress
+}
+
+// PortSecurity holds Port Security info
+type PortSecurity struct {
+	Resource
+	Spec *PortSecuritySpec
+}
+
+// portSecurityKind describes the storage of the Port Security objects
+var portSecurityKind = registerKind(resourceKind{
+	eventType: "port-security",
+	indexKey:  "portsecurities",
+	newObject: func() resourceObject { return &PortSecurity{} },
+	references: func(obj resourceObject) []string {
+		return []string{obj.(*PortSecurity).Spec.BridgePort}
+	},
+})
+
+// validate checks the Port Security Spec
+func (in *PortSecuritySpec) validate() error {
+	if in.BridgePort == "" {
+		return fmt.Errorf("port security needs a bridge port")
+	}
+	if in.MacLimit == 0 && len(in.AllowedAddresses) == 0 {
+		return fmt.Errorf("port security needs a MAC limit or allowed addresses")
+	}
+	for i, addr := range in.AllowedAddresses {
+		if len(addr.MAC) != 6 || addr.MAC[0]&1 == 1 {
+			return fmt.Errorf("port security allowed MAC %s is not a unicast MAC address", addr.MAC)
+		}
+		if addr.IP != nil && (addr.IP.IsUnspecified() || addr.IP.IsMulticast()) {
+			return fmt.Errorf("port security allowed IP %s is not a unicast IP address", addr.IP)
+		}
+		for _, other := range in.AllowedAddresses[:i] {
+			if bytes.Equal(other.MAC, addr.MAC) && other.IP.Equal(addr.IP) {
+				return fmt.Errorf("port security allowed address %s %s is duplicated", addr.MAC, addr.IP)
+			}
+		}
+	}
+	return nil
+}
+
+// NewPortSecurity creates new Port Security object
+func NewPortSecurity(name string, spec *PortSecuritySpec) (*PortSecurity, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewPortSecurity(): Port Security spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("NewPortSecurity(): %v", err)
+	}
+
+	res, err := newResource(name, portSecurityKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PortSecurity{Resource: res, Spec: spec}, nil
+}
+
+// getAllPortSecurities returns all the port securities, the caller must hold the global lock
+func getAllPortSecurities() ([]*PortSecurity, error) {
+	psecs := []*PortSecurity{}
+	names, err := portSecurityKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		psec := &PortSecurity{}
+		if err := portSecurityKind.get(name, psec); err != nil {
+			log.Printf("getAllPortSecurities(): Failed to get the Port Security %s from store: %v", name, err)
+			return nil, err
+		}
+		psecs = append(psecs, psec)
+	}
+	return psecs, nil
+}
+
+// CreatePortSecurity creates an infradb port security object
+func CreatePortSecurity(psec *PortSecurity) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	found, err := infradb.client.Get(psec.Spec.BridgePort, &BridgePort{})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if !found {
+		log.Printf("CreatePortSecurity(): The Bridge Port with name %+v has not been found\n", psec.Spec.BridgePort)
+		return ErrBridgePortNotFound
+	}
+
+	psecs, err := getAllPortSecurities()
+	if err != nil {
+		return err
+	}
+	for _, existing := range psecs {
+		if existing.Spec.BridgePort == psec.Spec.BridgePort {
+			log.Printf("CreatePortSecurity(): %s already has the port security %s\n", psec.Spec.BridgePort, existing.Name)
+			return ErrPortSecurityInUse
+		}
+	}
+
+	return portSecurityKind.create(psec)
+}
+
+// DeletePortSecurity deletes a port security infradb object
+func DeletePortSecurity(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	psec := &PortSecurity{}
+	if err := portSecurityKind.get(name, psec); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, psec.ResourceVersion, opts); err != nil {
+		return err
+	}
+	return portSecurityKind.delete(psec)
+}
+
+// GetPortSecurity returns an infradb port security object
+func GetPortSecurity(name string) (*PortSecurity, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	psec := &PortSecurity{}
+	err := portSecurityKind.get(name, psec)
+	return psec, err
+}
+
+// GetAllPortSecurities returns a list of port securities from the DB
+func GetAllPortSecurities() ([]*PortSecurity, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllPortSecurities()
+}
+
+// UpdatePortSecurityStatus updates the status of port security object based on the component report
+func UpdatePortSecurityStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return portSecurityKind.updateStatus(&PortSecurity{}, name, resourceVersion, notificationID, component)
+}
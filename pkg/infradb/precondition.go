@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrVersionMismatch the object has been changed since the client read the version it expects
+var ErrVersionMismatch = errors.New("the object was modified concurrently")
+
+// WriteOption tunes an update or a delete of an object
+type WriteOption func(*writeOptions)
+
+// writeOptions holds the options of a write
+type writeOptions struct {
+	ifMatch string
+}
+
+// IfMatch makes the write fail with ErrVersionMismatch unless the stored object has the resource version,
+// the empty version matches any. The version is compared under the lock of the write, so that two clients
+// racing on the same version cannot both succeed.
+func IfMatch(version string) WriteOption {
+	return func(o *writeOptions) {
+		o.ifMatch = version
+	}
+}
+
+// checkIfMatch compares the resource version of the stored object with the one expected by the write,
+// the caller must hold the global lock
+func checkIfMatch(name string, current string, opts []WriteOption) error {
+	o := writeOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.ifMatch != "" && o.ifMatch != current {
+		return fmt.Errorf("%w: %s is at version %s, not %s", ErrVersionMismatch, name, current, o.ifMatch)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+func Test_IfMatch(t *testing.T) {
+	eventbus.EBus.StartSubscriber("dummy", "vrf", 1, nil)
+	if err := NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	name := "//network.opiproject.org/vrfs/ifmatch"
+	vrf, err := NewVrf(name, &VrfSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateVrf(vrf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := GetResourceVersion(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first of the two clients which read the same version wins, the other one is rejected
+	update, err := NewVrf(name, &VrfSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateVrf(update, IfMatch(read)); err != nil {
+		t.Fatal(err)
+	}
+	update, err = NewVrf(name, &VrfSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateVrf(update, IfMatch(read)); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("expected %v, received %v", ErrVersionMismatch, err)
+	}
+	if err := DeleteVrf(name, IfMatch(read)); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("expected %v, received %v", ErrVersionMismatch, err)
+	}
+
+	current, err := GetResourceVersion(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteVrf(name, IfMatch(current)); err != nil {
+		t.Errorf("expected the delete at the current version, received %v", err)
+	}
+}
@@ -0,0 +1,312 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// bridgePortProfilesKey is the key of the DB map holding the bridge port profiles by name
+var bridgePortProfilesKey = registerStoreKey("bridgeportprofiles")
+
+var (
+	// ErrBridgePortProfileInUse the bridge port profile is still used by a bridge port
+	ErrBridgePortProfileInUse = errors.New("the Bridge Port Profile is still used by a bridge port")
+	// ErrBridgePortProfiled the setting of the bridge port is managed by its profile
+	ErrBridgePortProfiled = errors.New("the setting of the Bridge Port is managed by its profile")
+)
+
+// BridgePortProfile holds the settings shared by the bridge ports which use it, a setting left nil
+// is set on every port on its own
+type BridgePortProfile struct {
+	Name     string
+	Sflow    *SflowSpec
+	Qinq     *QinqSpec
+	Isolated *bool
+}
+
+// validate checks the settings of the profile
+func (in *BridgePortProfile) validate() error {
+	if in.Name == "" {
+		return fmt.Errorf("bridge port profile name cannot be empty")
+	}
+	if in.Sflow == nil && in.Qinq == nil && in.Isolated == nil {
+		return fmt.Errorf("bridge port profile %s has no setting", in.Name)
+	}
+	if in.Sflow != nil {
+		if err := in.Sflow.validate(); err != nil {
+			return err
+		}
+	}
+	if in.Qinq != nil {
+		if err := in.Qinq.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewBridgePortProfile returns the validated bridge port profile
+func NewBridgePortProfile(name string, sflow *SflowSpec, qinq *QinqSpec, isolated *bool) (*BridgePortProfile, error) {
+	in := &BridgePortProfile{Name: name, Sflow: sflow, Qinq: qinq, Isolated: isolated}
+	if err := in.validate(); err != nil {
+		return nil, fmt.Errorf("NewBridgePortProfile(): %w", err)
+	}
+	return in, nil
+}
+
+// applyTo sets the settings of the profile on the bridge port spec, the settings of the previous profile
+// which the profile leaves to the ports are removed
+func (in *BridgePortProfile) applyTo(spec *BridgePortSpec, previous *BridgePortProfile) {
+	if in.Sflow != nil || previous != nil && previous.Sflow != nil {
+		spec.Sflow = in.Sflow
+	}
+	if in.Qinq != nil || previous != nil && previous.Qinq != nil {
+		spec.Qinq = in.Qinq
+	}
+	if in.Isolated != nil || previous != nil && previous.Isolated != nil {
+		spec.Isolated = in.Isolated
+	}
+}
+
+// loadBridgePortProfiles returns the bridge port profiles by name, the caller must hold the global lock
+func loadBridgePortProfiles() (map[string]*BridgePortProfile, error) {
+	profiles := make(map[string]*BridgePortProfile)
+	if _, err := infradb.client.Get(bridgePortProfilesKey, &profiles); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// checkQinqBridges checks that the logical bridges of the QinQ rules exist, the caller must hold the global lock
+func checkQinqBridges(qinq *QinqSpec) error {
+	if qinq == nil {
+		return nil
+	}
+	for _, rule := range qinq.Rules {
+		found, err := infradb.client.Get(rule.LogicalBridge, &LogicalBridge{})
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("logical bridge %s of the rule %d/%d: %w", rule.LogicalBridge, rule.SVlan, rule.CVlan, ErrKeyNotFound)
+		}
+	}
+	return nil
+}
+
+// checkNotProfiled fails when the profile of the bridge port manages the setting, the caller must hold the
+// global lock
+func checkNotProfiled(bp *BridgePort, managed func(p *BridgePortProfile) bool) error {
+	if bp.Spec.Profile == "" {
+		return nil
+	}
+	profiles, err := loadBridgePortProfiles()
+	if err != nil {
+		return err
+	}
+	if p, ok := profiles[bp.Spec.Profile]; ok && managed(p) {
+		return fmt.Errorf("%s uses the profile %s: %w", bp.Name, p.Name, ErrBridgePortProfiled)
+	}
+	return nil
+}
+
+// bridgePortsOfProfile returns the bridge ports which use the profile, the caller must hold the global lock
+func bridgePortsOfProfile(name string) ([]*BridgePort, error) {
+	bpsMap := make(map[string]bool)
+	if _, err := infradb.client.Get("bps", &bpsMap); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	bpNames := make([]string, 0, len(bpsMap))
+	for bpName := range bpsMap {
+		bpNames = append(bpNames, bpName)
+	}
+	sort.Strings(bpNames)
+	bps := []*BridgePort{}
+	for _, bpName := range bpNames {
+		bp := &BridgePort{}
+		found, err := infradb.client.Get(bpName, bp)
+		if err != nil {
+			return nil, err
+		}
+		if found && bp.Spec.Profile == name && bp.Status.BPOperStatus != BridgePortOperStatusToBeDeleted {
+			bps = append(bps, bp)
+		}
+	}
+	return bps, nil
+}
+
+// SetBridgePortProfile creates or replaces the bridge port profile, the bridge ports which use it are
+// programmed again with its settings
+func SetBridgePortProfile(p *BridgePortProfile) ([]string, error) {
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("SetBridgePortProfile(): %w", err)
+	}
+
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	subscribers := eventbus.EBus.GetSubscribers("bridge-port")
+	if len(subscribers) == 0 {
+		log.Println("SetBridgePortProfile(): No subscribers for Bridge Port objects")
+		return nil, errors.New("no subscribers found for bridge port")
+	}
+	if err := checkQinqBridges(p.Qinq); err != nil {
+		return nil, fmt.Errorf("SetBridgePortProfile(): %w", err)
+	}
+
+	profiles, err := loadBridgePortProfiles()
+	if err != nil {
+		return nil, err
+	}
+	previous := profiles[p.Name]
+	profiles[p.Name] = p
+	if err := infradb.client.Set(bridgePortProfilesKey, profiles); err != nil {
+		return nil, err
+	}
+
+	bps, err := bridgePortsOfProfile(p.Name)
+	if err != nil {
+		return nil, err
+	}
+	updated := make([]string, 0, len(bps))
+	for _, bp := range bps {
+		p.applyTo(bp.Spec, previous)
+		if err := reprogramBP(bp, subscribers); err != nil {
+			return nil, err
+		}
+		updated = append(updated, bp.Name)
+	}
+	return updated, nil
+}
+
+// GetBridgePortProfile returns the bridge port profile of the name
+func GetBridgePortProfile(name string) (*BridgePortProfile, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	profiles, err := loadBridgePortProfiles()
+	if err != nil {
+		return nil, err
+	}
+	p, ok := profiles[name]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return p, nil
+}
+
+// GetAllBridgePortProfiles returns the bridge port profiles in the order of their names
+func GetAllBridgePortProfiles() ([]*BridgePortProfile, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	profiles, err := loadBridgePortProfiles()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*BridgePortProfile, 0, len(profiles))
+	for _, p := range profiles {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// GetBridgePortsOfProfile returns the names of the bridge ports which use the profile
+func GetBridgePortsOfProfile(name string) ([]string, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	bps, err := bridgePortsOfProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(bps))
+	for _, bp := range bps {
+		names = append(names, bp.Name)
+	}
+	return names, nil
+}
+
+// DeleteBridgePortProfile deletes the bridge port profile, which no bridge port may use
+func DeleteBridgePortProfile(name string) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	profiles, err := loadBridgePortProfiles()
+	if err != nil {
+		return err
+	}
+	if _, ok := profiles[name]; !ok {
+		return ErrKeyNotFound
+	}
+	bps, err := bridgePortsOfProfile(name)
+	if err != nil {
+		return err
+	}
+	if len(bps) != 0 {
+		log.Printf("DeleteBridgePortProfile(): Can not delete %s. Used by %s", name, bps[0].Name)
+		return ErrBridgePortProfileInUse
+	}
+	delete(profiles, name)
+	return infradb.client.Set(bridgePortProfilesKey, profiles)
+}
+
+// AttachBridgePortProfile makes the bridge port use the profile, the port is programmed again with the settings
+// of the profile. An empty profile detaches the port from its profile, the port keeps the settings which are
+// from now on set on the port itself.
+func AttachBridgePortProfile(name string, profile string, opts ...WriteOption) (*BridgePort, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	subscribers := eventbus.EBus.GetSubscribers("bridge-port")
+	if len(subscribers) == 0 {
+		log.Println("AttachBridgePortProfile(): No subscribers for Bridge Port objects")
+		return nil, errors.New("no subscribers found for bridge port")
+	}
+
+	bp := &BridgePort{}
+	found, err := infradb.client.Get(name, bp)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	if err := checkIfMatch(name, bp.ResourceVersion, opts); err != nil {
+		return nil, err
+	}
+	if bp.Status.BPOperStatus == BridgePortOperStatusToBeDeleted {
+		return nil, ErrBridgePortToBeDeleted
+	}
+	if bp.Spec.Profile == profile {
+		return bp, nil
+	}
+
+	if profile != "" {
+		profiles, err := loadBridgePortProfiles()
+		if err != nil {
+			return nil, err
+		}
+		p, ok := profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("AttachBridgePortProfile(): bridge port profile %s: %w", profile, ErrKeyNotFound)
+		}
+		p.applyTo(bp.Spec, nil)
+	}
+	bp.Spec.Profile = profile
+	if err := reprogramBP(bp, subscribers); err != nil {
+		return nil, err
+	}
+	return bp, nil
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"log"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+)
+
+var (
+	// ErrSviToBeDeleted the SVI is being deleted
+	ErrSviToBeDeleted = errors.New("the svi is being deleted")
+	// ErrProxyArpNoIPv4 the SVI has no IPv4 gateway address to answer the ARP requests from
+	ErrProxyArpNoIPv4 = errors.New("the SVI has no IPv4 gateway address")
+	// ErrProxyArpGeneve the ARP suppression of the geneve device cannot be lifted for a single logical bridge
+	ErrProxyArpGeneve = errors.New("local proxy ARP is not supported on a logical bridge carried over geneve")
+)
+
+// ProxyArpSpec holds the proxy ARP of an SVI, it is set with SetSviProxyArp as the opi-api SVI has no field for it
+type ProxyArpSpec struct {
+	// ProxyArp answers the ARP requests of the hosts for the addresses routed through another interface
+	ProxyArp bool
+	// LocalProxyArp answers the ARP requests of the hosts for the other hosts of the subnet too, their traffic is
+	// then routed through the gateway, e.g. between isolated ports
+	LocalProxyArp bool
+}
+
+// hasLocalProxyArp tells whether the SVI routes the traffic within its subnet
+func (in *SviSpec) hasLocalProxyArp() bool {
+	return in.ProxyArp != nil && in.ProxyArp.LocalProxyArp
+}
+
+// checkProxyArp checks the proxy ARP of the SVI against its addresses and its logical bridge, the caller must
+// hold the global lock
+func checkProxyArp(svi *Svi, proxyArp *ProxyArpSpec) error {
+	if proxyArp == nil {
+		return nil
+	}
+	hasIPv4 := false
+	for _, gwIP := range svi.Spec.GatewayIPs {
+		hasIPv4 = hasIPv4 || gwIP.IP.To4() != nil
+	}
+	if !hasIPv4 {
+		return ErrProxyArpNoIPv4
+	}
+	if !proxyArp.LocalProxyArp {
+		return nil
+	}
+	lb := &LogicalBridge{}
+	found, err := infradb.client.Get(svi.Spec.LogicalBridge, lb)
+	if err != nil {
+		return err
+	}
+	if found && lb.Spec.IsGeneve() {
+		return ErrProxyArpGeneve
+	}
+	return nil
+}
+
+// SetSviProxyArp sets the proxy ARP of an SVI, nil disables it
+func SetSviProxyArp(name string, proxyArp *ProxyArpSpec, opts ...WriteOption) (*Svi, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	subscribers := eventbus.EBus.GetSubscribers("svi")
+	if len(subscribers) == 0 {
+		log.Println("SetSviProxyArp(): No subscribers for SVI objects")
+		return nil, errors.New("no subscribers found for svi")
+	}
+
+	svi := &Svi{}
+	found, err := infradb.client.Get(name, svi)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	if err := checkIfMatch(name, svi.ResourceVersion, opts); err != nil {
+		return nil, err
+	}
+	if svi.Status.SviOperStatus == SviOperStatusToBeDeleted {
+		return nil, ErrSviToBeDeleted
+	}
+	if err := checkProxyArp(svi, proxyArp); err != nil {
+		return nil, err
+	}
+
+	svi.Spec.ProxyArp = proxyArp
+	for i := range svi.Status.Components {
+		svi.Status.Components[i].CompStatus = common.ComponentStatusPending
+	}
+	svi.ResourceVersion = generateVersion()
+
+	err = infradb.client.Set(svi.Name, svi)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	notifyLifecycle(StatusEventUpdated, "svi", svi.Name, svi.ResourceVersion)
+	taskmanager.TaskMan.CreateTask(svi.Name, "svi", svi.ResourceVersion, subscribers)
+
+	return svi, nil
+}
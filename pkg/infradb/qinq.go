@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+)
+
+// maxQinqVlanID is the largest usable vlan id of the S-VLAN and C-VLAN tags
+const maxQinqVlanID = 4094
+
+// QinqRule maps the frames double tagged with the S-VLAN and the C-VLAN to a Logical Bridge
+type QinqRule struct {
+	// SVlan is the outer 802.1ad tag of the service provider
+	SVlan uint16
+	// CVlan is the inner 802.1Q tag of the customer
+	CVlan uint16
+	// LogicalBridge is the name of the Logical Bridge carrying the frames, untagged
+	LogicalBridge string
+}
+
+// QinqSpec holds the QinQ mapping of a Bridge Port
+type QinqSpec struct {
+	Rules []QinqRule
+}
+
+// validate checks the vlan ids of the rules and that every S-VLAN and C-VLAN pair is mapped once
+func (in *QinqSpec) validate() error {
+	if len(in.Rules) == 0 {
+		return fmt.Errorf("QinQ mapping has no rule")
+	}
+	seen := make(map[[2]uint16]bool, len(in.Rules))
+	for _, rule := range in.Rules {
+		if rule.SVlan == 0 || rule.SVlan > maxQinqVlanID || rule.CVlan == 0 || rule.CVlan > maxQinqVlanID {
+			return fmt.Errorf("QinQ vlan ids %d/%d are not between 1 and %d", rule.SVlan, rule.CVlan, maxQinqVlanID)
+		}
+		if rule.LogicalBridge == "" {
+			return fmt.Errorf("QinQ rule %d/%d has no logical bridge", rule.SVlan, rule.CVlan)
+		}
+		pair := [2]uint16{rule.SVlan, rule.CVlan}
+		if seen[pair] {
+			return fmt.Errorf("QinQ vlan ids %d/%d are mapped twice", rule.SVlan, rule.CVlan)
+		}
+		seen[pair] = true
+	}
+	return nil
+}
+
+// NewQinqSpec returns the validated QinQ mapping
+func NewQinqSpec(rules []QinqRule) (*QinqSpec, error) {
+	in := &QinqSpec{Rules: rules}
+	if err := in.validate(); err != nil {
+		return nil, fmt.Errorf("NewQinqSpec(): %w", err)
+	}
+	return in, nil
+}
+
+// SetBridgePortQinq sets the QinQ mapping of the bridge port, nil removes it, the bridge port is
+// programmed again with the mapping
+func SetBridgePortQinq(name string, qinq *QinqSpec, opts ...WriteOption) (*BridgePort, error) {
+	if qinq != nil {
+		if err := qinq.validate(); err != nil {
+			return nil, fmt.Errorf("SetBridgePortQinq(): %w", err)
+		}
+	}
+
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	subscribers := eventbus.EBus.GetSubscribers("bridge-port")
+	if len(subscribers) == 0 {
+		log.Println("SetBridgePortQinq(): No subscribers for Bridge Port objects")
+		return nil, errors.New("no subscribers found for bridge port")
+	}
+
+	bp := &BridgePort{}
+	found, err := infradb.client.Get(name, bp)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	if err := checkIfMatch(name, bp.ResourceVersion, opts); err != nil {
+		return nil, err
+	}
+	if bp.Status.BPOperStatus == BridgePortOperStatusToBeDeleted {
+		return nil, ErrBridgePortToBeDeleted
+	}
+	if err := checkNotProfiled(bp, func(p *BridgePortProfile) bool { return p.Qinq != nil }); err != nil {
+		return nil, err
+	}
+	if qinq != nil {
+		for _, rule := range qinq.Rules {
+			lb := &LogicalBridge{}
+			found, err := infradb.client.Get(rule.LogicalBridge, lb)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				return nil, fmt.Errorf("SetBridgePortQinq(): logical bridge %s of the rule %d/%d: %w", rule.LogicalBridge, rule.SVlan, rule.CVlan, ErrKeyNotFound)
+			}
+		}
+	}
+
+	bp.Spec.Qinq = qinq
+	for i := range bp.Status.Components {
+		bp.Status.Components[i].CompStatus = common.ComponentStatusPending
+	}
+	bp.ResourceVersion = generateVersion()
+
+	err = infradb.client.Set(bp.Name, bp)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	notifyLifecycle(StatusEventUpdated, "bridge-port", bp.Name, bp.ResourceVersion)
+	taskmanager.TaskMan.CreateTask(bp.Name, "bridge-port", bp.ResourceVersion, subscribers)
+
+	return bp, nil
+}
@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// QuotaError is returned when a create would exceed a quota, it is reported as ResourceExhausted
+type QuotaError struct {
+	Quota string
+	Scope string
+	Limit int
+}
+
+// Error returns the description of the exceeded quota
+func (e *QuotaError) Error() string {
+	if e.Scope == "" {
+		return fmt.Sprintf("the %s quota of %d is exhausted", e.Quota, e.Limit)
+	}
+	return fmt.Sprintf("the %s quota of %d is exhausted for %s", e.Quota, e.Limit, e.Scope)
+}
+
+// GRPCStatus converts the error into a ResourceExhausted status
+func (e *QuotaError) GRPCStatus() *status.Status {
+	return status.New(codes.ResourceExhausted, e.Error())
+}
+
+// QuotaValue holds the limit of a quota and its usage, a zero limit is unlimited
+type QuotaValue struct {
+	Limit int
+	Used  int
+}
+
+// QuotaUsage holds the usage of the quotas
+type QuotaUsage struct {
+	Vnis           QuotaValue
+	SvisPerVrf     map[string]QuotaValue
+	PortsPerBridge map[string]QuotaValue
+}
+
+// checkQuota returns a QuotaError when one more object exceeds the limit
+func checkQuota(quota, scope string, limit, used int) error {
+	if limit > 0 && used >= limit {
+		err := &QuotaError{Quota: quota, Scope: scope, Limit: limit}
+		log.Printf("checkQuota(): %v", err)
+		return err
+	}
+	return nil
+}
+
+// checkVniQuota checks that one more VNI can be used
+func checkVniQuota(vpns map[uint32]bool) error {
+	return checkQuota("VNI", "", config.GlobalConfig.Quotas.MaxVnis, len(vpns))
+}
+
+// checkSviQuota checks that one more SVI can be attached to the VRF
+func checkSviQuota(vrf *Vrf) error {
+	return checkQuota("SVIs per VRF", vrf.Name, config.GlobalConfig.Quotas.MaxSvisPerVrf, len(vrf.Svis))
+}
+
+// checkPortQuota checks that one more Bridge Port can be attached to each of the Logical Bridges
+func checkPortQuota(lbNames []string) error {
+	limit := config.GlobalConfig.Quotas.MaxPortsPerBridge
+	if limit <= 0 {
+		return nil
+	}
+	for _, lbName := range lbNames {
+		lb := LogicalBridge{}
+		found, err := infradb.client.Get(lbName, &lb)
+		if err != nil {
+			return err
+		}
+		if !found {
+			// reported by the caller
+			continue
+		}
+		if err := checkQuota("Bridge Ports per Logical Bridge", lb.Name, limit, len(lb.BridgePorts)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetQuotaUsage returns the limits and the usage of the quotas
+func GetQuotaUsage() (*QuotaUsage, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	quotas := config.GlobalConfig.Quotas
+	usage := &QuotaUsage{
+		Vnis:           QuotaValue{Limit: quotas.MaxVnis},
+		SvisPerVrf:     map[string]QuotaValue{},
+		PortsPerBridge: map[string]QuotaValue{},
+	}
+
+	vpns := make(map[uint32]bool)
+	if _, err := infradb.client.Get("vpns", &vpns); err != nil {
+		return nil, err
+	}
+	usage.Vnis.Used = len(vpns)
+
+	vrfs := make(map[string]bool)
+	if _, err := infradb.client.Get("vrfs", &vrfs); err != nil {
+		return nil, err
+	}
+	for name := range vrfs {
+		vrf := Vrf{}
+		found, err := infradb.client.Get(name, &vrf)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			usage.SvisPerVrf[name] = QuotaValue{Limit: quotas.MaxSvisPerVrf, Used: len(vrf.Svis)}
+		}
+	}
+
+	lbs := make(map[string]bool)
+	if _, err := infradb.client.Get("lbs", &lbs); err != nil {
+		return nil, err
+	}
+	for name := range lbs {
+		lb := LogicalBridge{}
+		found, err := infradb.client.Get(name, &lb)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			usage.PortsPerBridge[name] = QuotaValue{Limit: quotas.MaxPortsPerBridge, Used: len(lb.BridgePorts)}
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// readOnlyKey is the key of the read-only mode in the store
+var readOnlyKey = registerStoreKey("readonly")
+
+// ReadOnlyMode rejects the calls changing the resources, during a change freeze or a troubleshooting window
+type ReadOnlyMode struct {
+	Enabled bool
+	// AllowDeletes lets the deletes through, e.g. to remove a faulty resource during the window
+	AllowDeletes bool
+	Reason       string
+	// Since is when the mode has been entered, zero when it is not enabled
+	Since time.Time
+}
+
+// GetReadOnlyMode returns the read-only mode of the bridge
+func GetReadOnlyMode() (ReadOnlyMode, error) {
+	if infradb == nil {
+		return ReadOnlyMode{}, errors.New("infradb is not initialized")
+	}
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+	return getReadOnlyMode()
+}
+
+// getReadOnlyMode reads the mode from the store, the mode is not enabled when it has never been set
+func getReadOnlyMode() (ReadOnlyMode, error) {
+	mode := ReadOnlyMode{}
+	if _, err := infradb.client.Get(readOnlyKey, &mode); err != nil {
+		log.Println(err)
+		return ReadOnlyMode{}, err
+	}
+	return mode, nil
+}
+
+// SetReadOnlyMode enters, changes or leaves the read-only mode, which survives the restarts of the bridge.
+// The mode keeps the time it has been entered at when it is changed.
+func SetReadOnlyMode(mode ReadOnlyMode) (ReadOnlyMode, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	current, err := getReadOnlyMode()
+	if err != nil {
+		return ReadOnlyMode{}, err
+	}
+	switch {
+	case !mode.Enabled:
+		mode = ReadOnlyMode{}
+	case current.Enabled:
+		mode.Since = current.Since
+	default:
+		mode.Since = time.Now()
+	}
+	if err := infradb.client.Set(readOnlyKey, &mode); err != nil {
+		log.Println(err)
+		return ReadOnlyMode{}, err
+	}
+	if mode.Enabled {
+		log.Printf("SetReadOnlyMode(): The bridge is read-only, deletes allowed %v: %s\n", mode.AllowDeletes, mode.Reason)
+	} else {
+		log.Println("SetReadOnlyMode(): The bridge is writable")
+	}
+	return mode, nil
+}
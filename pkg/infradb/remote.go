@@ -0,0 +1,384 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+)
+
+// The controller of a cluster of DPUs holds the objects, the node agents mirror them into the DB of their
+// node where the components realize them. The mirrored object keeps the resource version of the controller,
+// so that the status events of the node name the version that the controller waits for.
+
+// mirrorObject is implemented by the objects mirrored on the nodes
+type mirrorObject interface {
+	GetName() string
+	checkForAllSuccess() bool
+	// mirrorStatus returns the resource version and whether the object is to be deleted
+	mirrorStatus() (string, bool)
+	// resetMirrorStatus sets the components of the node in pending state, the object is down or to be deleted
+	resetMirrorStatus(subs []*eventbus.Subscriber, deleting bool)
+	// keepMetadata keeps the metadata written by the components of the node in the new version of the object
+	keepMetadata(local mirrorObject)
+}
+
+// mirrorKind describes how the objects of a kind are mirrored on the nodes
+type mirrorKind struct {
+	eventType string
+	indexKey  string
+	newObject func() mirrorObject
+	// register books the VNIs, VLAN IDs and interface names of the object in the DB of the node
+	register func(obj mirrorObject) error
+}
+
+// mirrorKinds returns the kinds of objects realized by the node agents, the parents before their children
+func mirrorKinds() []mirrorKind {
+	kinds := []mirrorKind{
+		{eventType: "vrf", indexKey: "vrfs", newObject: func() mirrorObject { return &Vrf{} }, register: registerMirroredVrf},
+		{eventType: "logical-bridge", indexKey: "lbs", newObject: func() mirrorObject { return &LogicalBridge{} }, register: registerMirroredLB},
+		{eventType: "svi", indexKey: "svis", newObject: func() mirrorObject { return &Svi{} }, register: registerMirroredSvi},
+		{eventType: "bridge-port", indexKey: "bps", newObject: func() mirrorObject { return &BridgePort{} }},
+	}
+	for _, kind := range resourceKinds {
+		k := kind
+		kinds = append(kinds, mirrorKind{eventType: k.eventType, indexKey: k.indexKey, newObject: func() mirrorObject {
+			return k.newObject().(mirrorObject)
+		}})
+	}
+	return kinds
+}
+
+// findMirrorKind returns the kind of the event type
+func findMirrorKind(kind string) (mirrorKind, error) {
+	for _, k := range mirrorKinds() {
+		if k.eventType == kind {
+			return k, nil
+		}
+	}
+	return mirrorKind{}, fmt.Errorf("unknown kind of object %s", kind)
+}
+
+// RemoteKinds returns the event types of the objects realized by the node agents, the parents before their children
+func RemoteKinds() []string {
+	kinds := []string{}
+	for _, k := range mirrorKinds() {
+		kinds = append(kinds, k.eventType)
+	}
+	return kinds
+}
+
+// GetStoredObject returns the object as stored, whatever its kind
+func GetStoredObject(name string) (json.RawMessage, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	var raw json.RawMessage
+	found, err := infradb.client.Get(name, &raw)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	return raw, nil
+}
+
+// GetStoredNames returns the names of the stored objects of the kind
+func GetStoredNames(kind string) ([]string, error) {
+	k, err := findMirrorKind(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return storedNames(k.indexKey)
+}
+
+// UpdateObjectStatus updates the status of an object of any kind based on the component report,
+// the component sends no metadata
+func UpdateObjectStatus(kind, name, resourceVersion, notificationID string, component common.Component) error {
+	switch kind {
+	case "vrf":
+		return UpdateVrfStatus(name, resourceVersion, notificationID, nil, component)
+	case "logical-bridge":
+		return UpdateLBStatus(name, resourceVersion, notificationID, nil, component)
+	case "svi":
+		return UpdateSviStatus(name, resourceVersion, notificationID, nil, component)
+	case "bridge-port":
+		return UpdateBPStatus(name, resourceVersion, notificationID, nil, component)
+	}
+	for _, k := range resourceKinds {
+		if k.eventType == kind {
+			globalLock.Lock()
+			defer globalLock.Unlock()
+			return k.updateStatus(k.newObject(), name, resourceVersion, notificationID, component)
+		}
+	}
+	return fmt.Errorf("unknown kind of object %s", kind)
+}
+
+// MirrorObject stores on the node the object sent by the controller, and the components of the node
+// realize it. It returns true when the version of the object needs nothing more: it is realized
+// already, or it is to be deleted and the node does not hold it.
+func MirrorObject(kind string, data json.RawMessage) (bool, error) {
+	k, err := findMirrorKind(kind)
+	if err != nil {
+		return false, err
+	}
+	obj := k.newObject()
+	if err := json.Unmarshal(data, obj); err != nil {
+		return false, fmt.Errorf("invalid %s: %w", kind, err)
+	}
+	version, deleting := obj.mirrorStatus()
+
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	subscribers := eventbus.EBus.GetSubscribers(kind)
+	if len(subscribers) == 0 {
+		log.Printf("MirrorObject(): No subscribers for %s objects\n", kind)
+		return false, fmt.Errorf("no subscribers found for %s", kind)
+	}
+
+	local := k.newObject()
+	found, err := infradb.client.Get(obj.GetName(), local)
+	if err != nil {
+		return false, err
+	}
+	event := StatusEventCreated
+	switch {
+	case found:
+		// a forgotten object being torn down keeps its version, the controller may hold it again
+		if localVersion, localDeleting := local.mirrorStatus(); localVersion == version && localDeleting == deleting {
+			return local.checkForAllSuccess(), nil
+		}
+		obj.keepMetadata(local)
+		event = StatusEventUpdated
+	case deleting:
+		return true, nil
+	}
+	if deleting {
+		event = StatusEventDeleting
+	} else if k.register != nil {
+		if err := k.register(obj); err != nil {
+			return false, err
+		}
+	}
+	obj.resetMirrorStatus(subscribers, deleting)
+	if err := storeMirroredObject(k, obj); err != nil {
+		return false, err
+	}
+
+	notifyLifecycle(event, kind, obj.GetName(), version)
+	taskmanager.TaskMan.CreateTask(obj.GetName(), kind, version, subscribers)
+	return false, nil
+}
+
+// ForgetMirroredObjects tears down the objects of the kind held by the node which the controller does not hold
+func ForgetMirroredObjects(kind string, keep map[string]bool) error {
+	k, err := findMirrorKind(kind)
+	if err != nil {
+		return err
+	}
+
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	subscribers := eventbus.EBus.GetSubscribers(kind)
+	names, err := storedNames(k.indexKey)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if keep[name] {
+			continue
+		}
+		obj := k.newObject()
+		found, err := infradb.client.Get(name, obj)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		if _, deleting := obj.mirrorStatus(); deleting || len(subscribers) == 0 {
+			continue
+		}
+		log.Printf("ForgetMirroredObjects(): the controller does not hold the %s %s\n", kind, name)
+		obj.resetMirrorStatus(subscribers, true)
+		if err := storeMirroredObject(k, obj); err != nil {
+			return err
+		}
+		version, _ := obj.mirrorStatus()
+		notifyLifecycle(StatusEventDeleting, kind, name, version)
+		taskmanager.TaskMan.CreateTask(name, kind, version, subscribers)
+	}
+	return nil
+}
+
+// storeMirroredObject stores the object and adds it to the index of its kind, the caller must hold the global lock
+func storeMirroredObject(k mirrorKind, obj mirrorObject) error {
+	if err := infradb.client.Set(obj.GetName(), obj); err != nil {
+		log.Println(err)
+		return err
+	}
+	names := make(map[string]bool)
+	if _, err := infradb.client.Get(k.indexKey, &names); err != nil {
+		log.Println(err)
+		return err
+	}
+	names[obj.GetName()] = false
+	return infradb.client.Set(k.indexKey, &names)
+}
+
+// bookVni adds the VNI to the map of the VNIs in use, the caller must hold the global lock
+func bookVni(vni *uint32) error {
+	if vni == nil {
+		return nil
+	}
+	vpns := make(map[uint32]bool)
+	if _, err := infradb.client.Get("vpns", &vpns); err != nil {
+		return err
+	}
+	vpns[*vni] = false
+	return infradb.client.Set("vpns", &vpns)
+}
+
+// registerMirroredVrf books the VNI and the interface names of the mirrored VRF
+func registerMirroredVrf(obj mirrorObject) error {
+	vrf := obj.(*Vrf)
+	if err := bookVni(vrf.Spec.Vni); err != nil {
+		return err
+	}
+	return allocateVrfIfNames(vrf)
+}
+
+// registerMirroredLB books the VNI and the VLAN ID of the mirrored Logical Bridge
+func registerMirroredLB(obj mirrorObject) error {
+	lb := obj.(*LogicalBridge)
+	if err := bookVni(lb.Spec.Vni); err != nil {
+		return err
+	}
+	vlans, err := loadVlans()
+	if err != nil {
+		return err
+	}
+	vlans[lb.Spec.VlanID] = lb.Name
+	return infradb.client.Set(vlansKey, vlans)
+}
+
+// registerMirroredSvi books the interface name of the mirrored SVI, its VRF and Logical Bridge are mirrored before it
+func registerMirroredSvi(obj mirrorObject) error {
+	svi := obj.(*Svi)
+	vrf, lb := &Vrf{}, &LogicalBridge{}
+	foundVrf, err := infradb.client.Get(svi.Spec.Vrf, vrf)
+	if err != nil {
+		return err
+	}
+	foundLB, err := infradb.client.Get(svi.Spec.LogicalBridge, lb)
+	if err != nil {
+		return err
+	}
+	if !foundVrf || !foundLB {
+		return fmt.Errorf("the VRF and Logical Bridge of %s are not on the node yet", svi.Name)
+	}
+	return allocateSviIfName(svi, vrf, lb)
+}
+
+// pendingComponents returns the components of the subscribers in pending state
+func pendingComponents(subs []*eventbus.Subscriber) []common.Component {
+	components := make([]common.Component, 0, len(subs))
+	for _, sub := range subs {
+		components = append(components, common.Component{Name: sub.Name, CompStatus: common.ComponentStatusPending})
+	}
+	return components
+}
+
+func (in *Vrf) mirrorStatus() (string, bool) {
+	return in.ResourceVersion, in.Status.VrfOperStatus == VrfOperStatusToBeDeleted
+}
+
+func (in *Vrf) resetMirrorStatus(subs []*eventbus.Subscriber, deleting bool) {
+	in.Status.Components = pendingComponents(subs)
+	in.Status.VrfOperStatus = VrfOperStatusDown
+	if deleting {
+		in.Status.VrfOperStatus = VrfOperStatusToBeDeleted
+	}
+}
+
+func (in *Vrf) keepMetadata(local mirrorObject) {
+	in.Metadata = local.(*Vrf).Metadata
+}
+
+func (in *LogicalBridge) mirrorStatus() (string, bool) {
+	return in.ResourceVersion, in.Status.LBOperStatus == LogicalBridgeOperStatusToBeDeleted
+}
+
+func (in *LogicalBridge) resetMirrorStatus(subs []*eventbus.Subscriber, deleting bool) {
+	in.Status.Components = pendingComponents(subs)
+	in.Status.LBOperStatus = LogicalBridgeOperStatusDown
+	if deleting {
+		in.Status.LBOperStatus = LogicalBridgeOperStatusToBeDeleted
+	}
+}
+
+func (in *LogicalBridge) keepMetadata(local mirrorObject) {
+	in.Metadata = local.(*LogicalBridge).Metadata
+}
+
+func (in *Svi) mirrorStatus() (string, bool) {
+	return in.ResourceVersion, in.Status.SviOperStatus == SviOperStatusToBeDeleted
+}
+
+func (in *Svi) resetMirrorStatus(subs []*eventbus.Subscriber, deleting bool) {
+	in.Status.Components = pendingComponents(subs)
+	in.Status.SviOperStatus = SviOperStatusDown
+	if deleting {
+		in.Status.SviOperStatus = SviOperStatusToBeDeleted
+	}
+}
+
+func (in *Svi) keepMetadata(local mirrorObject) {
+	in.Metadata = local.(*Svi).Metadata
+}
+
+func (in *BridgePort) mirrorStatus() (string, bool) {
+	return in.ResourceVersion, in.Status.BPOperStatus == BridgePortOperStatusToBeDeleted
+}
+
+func (in *BridgePort) resetMirrorStatus(subs []*eventbus.Subscriber, deleting bool) {
+	in.Status.Components = pendingComponents(subs)
+	in.Status.BPOperStatus = BridgePortOperStatusDown
+	if deleting {
+		in.Status.BPOperStatus = BridgePortOperStatusToBeDeleted
+	}
+}
+
+func (in *BridgePort) keepMetadata(local mirrorObject) {
+	in.Metadata = local.(*BridgePort).Metadata
+}
+
+func (in *Resource) mirrorStatus() (string, bool) {
+	return in.ResourceVersion, in.Status.OperStatus == OperStatusToBeDeleted
+}
+
+func (in *Resource) resetMirrorStatus(subs []*eventbus.Subscriber, deleting bool) {
+	in.Status.Components = pendingComponents(subs)
+	in.Status.OperStatus = OperStatusDown
+	if deleting {
+		in.Status.OperStatus = OperStatusToBeDeleted
+	}
+}
+
+// keepMetadata has nothing to keep, the resources have no metadata
+func (in *Resource) keepMetadata(mirrorObject) {}
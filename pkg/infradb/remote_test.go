@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// Test_MirrorObject mirrors a vrf of the controller on a node, then forgets it once the controller does not hold it
+func Test_MirrorObject(t *testing.T) {
+	eventbus.EBus.StartSubscriber("dummy", "vrf", 1, nil)
+	if err := NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	vrf, err := NewVrf("//network.opiproject.org/vrfs/mirrored", &VrfSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vrf.ResourceVersion = "controller-1"
+	vrf.Status.Components = []common.Component{{Name: "remote", CompStatus: common.ComponentStatusSuccess}}
+	data, err := json.Marshal(vrf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if done, err := MirrorObject("vrf", data); err != nil || done {
+		t.Fatalf("expected the vrf to be realized by the node, got %v %v", done, err)
+	}
+	stored, err := GetVrf(vrf.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ResourceVersion != "controller-1" || stored.Status.VrfOperStatus != VrfOperStatusDown ||
+		len(stored.Status.Components) != 1 || stored.Status.Components[0].Name != "dummy" {
+		t.Errorf("expected the vrf to keep the version of the controller and wait for the node components, got %+v", stored)
+	}
+	names, err := GetStoredNames("vrf")
+	if err != nil || len(names) != 1 || names[0] != vrf.Name {
+		t.Errorf("expected the vrf in the index, got %v %v", names, err)
+	}
+
+	// the same version is not realized again
+	if done, err := MirrorObject("vrf", data); err != nil || done {
+		t.Errorf("expected the vrf to be still pending, got %v %v", done, err)
+	}
+
+	// an object to be deleted which the node does not hold needs nothing
+	deleted, _ := NewVrf("//network.opiproject.org/vrfs/gone", &VrfSpec{})
+	deleted.Status.VrfOperStatus = VrfOperStatusToBeDeleted
+	data, _ = json.Marshal(deleted)
+	if done, err := MirrorObject("vrf", data); err != nil || !done {
+		t.Errorf("expected nothing to do, got %v %v", done, err)
+	}
+
+	if err := ForgetMirroredObjects("vrf", map[string]bool{}); err != nil {
+		t.Fatal(err)
+	}
+	stored, err = GetVrf(vrf.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status.VrfOperStatus != VrfOperStatusToBeDeleted {
+		t.Errorf("expected the forgotten vrf to be torn down, got %+v", stored.Status)
+	}
+
+	if _, err := MirrorObject("bogus", data); err == nil {
+		t.Error("expected an unknown kind to be refused")
+	}
+}
@@ -86,6 +86,9 @@ func getObjectTypesToReplay(componentName string) []string {
 	typesAndSubs["svi"] = eventbus.EBus.GetSubscribers("svi")
 	typesAndSubs["logical-bridge"] = eventbus.EBus.GetSubscribers("logical-bridge")
 	typesAndSubs["vrf"] = eventbus.EBus.GetSubscribers("vrf")
+	for _, kind := range resourceKinds {
+		typesAndSubs[kind.eventType] = eventbus.EBus.GetSubscribers(kind.eventType)
+	}
 
 	for objType, subs := range typesAndSubs {
 		for _, sub := range subs {
@@ -247,6 +250,18 @@ func gatherObjectsAndSubsToReplay(componentName string, objectTypesToReplay []st
 				subsForReplay = append(subsForReplay, tempSubs)
 				objectsToReplay = append(objectsToReplay, bp)
 			}
+		default:
+			for _, kind := range resourceKinds {
+				if kind.eventType != objType {
+					continue
+				}
+				objs, subs, err := kind.gatherForReplay(componentName)
+				if err != nil {
+					return nil, nil, err
+				}
+				objectsToReplay = append(objectsToReplay, objs...)
+				subsForReplay = append(subsForReplay, subs...)
+			}
 		}
 	}
 
@@ -265,6 +280,9 @@ func createReplayTasks(objectsToReplay []interface{}, subsForReplay [][]*eventbu
 			taskmanager.TaskMan.CreateTask(tempObj.Name, "svi", tempObj.ResourceVersion, subsForReplay[i])
 		case *BridgePort:
 			taskmanager.TaskMan.CreateTask(tempObj.Name, "bridge-port", tempObj.ResourceVersion, subsForReplay[i])
+		case *replayObject:
+			res := tempObj.obj.base()
+			taskmanager.TaskMan.CreateTask(res.Name, tempObj.eventType, res.ResourceVersion, subsForReplay[i])
 		default:
 			log.Printf("createReplayTasks: Unknown object type %+v\n", tempObj)
 		}
@@ -0,0 +1,435 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+)
+
+// OperStatus operational Status for the resources which are not part of the opi-api
+type OperStatus int32
+
+const (
+	// OperStatusUnspecified for resource unknown state
+	OperStatusUnspecified OperStatus = iota
+	// OperStatusUp for resource up state
+	OperStatusUp = iota
+	// OperStatusDown for resource down state
+	OperStatusDown = iota
+	// OperStatusToBeDeleted for resource to be deleted state
+	OperStatusToBeDeleted = iota
+)
+
+// String returns the name of the operational status
+func (s OperStatus) String() string {
+	switch s {
+	case OperStatusUp:
+		return "UP"
+	case OperStatusDown:
+		return "DOWN"
+	case OperStatusToBeDeleted:
+		return "TO_BE_DELETED"
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+// Status holds the Status of a resource
+type Status struct {
+	OperStatus OperStatus
+	Components []common.Component
+}
+
+// Resource holds the fields which are common to all the resources which are
+// not part of the opi-api. The resources go through the same life cycle as the
+// opi-api objects: they get realized by the subscribed components and the
+// object is removed from the DB only when all the components have torn it down.
+type Resource struct {
+	Name            string
+	Status          *Status
+	ResourceVersion string
+}
+
+// resourceObject is implemented by all the resources embedding Resource
+type resourceObject interface {
+	base() *Resource
+}
+
+// base returns the common part of the resource
+func (in *Resource) base() *Resource {
+	return in
+}
+
+// GetName returns object unique name
+func (in *Resource) GetName() string {
+	return in.Name
+}
+
+// setComponentState set the stat of the component
+func (in *Resource) setComponentState(component common.Component) {
+	for i, comp := range in.Status.Components {
+		if comp.Name == component.Name {
+			in.Status.Components[i] = component
+			break
+		}
+	}
+}
+
+// checkForAllSuccess check if all the components are in Success state
+func (in *Resource) checkForAllSuccess() bool {
+	for _, comp := range in.Status.Components {
+		if comp.CompStatus != common.ComponentStatusSuccess {
+			return false
+		}
+	}
+	return true
+}
+
+// prepareObjectsForReplay prepares an object for replay by setting the unsuccessful components
+// in pending state and returning a list of the components that need to be contacted for the
+// replay of the particular object that called the function.
+func (in *Resource) prepareObjectsForReplay(componentName string, subs []*eventbus.Subscriber) []*eventbus.Subscriber {
+	tempSubs := []*eventbus.Subscriber{}
+	for i, comp := range in.Status.Components {
+		if comp.Name == componentName || comp.CompStatus != common.ComponentStatusSuccess {
+			in.Status.Components[i] = common.Component{Name: comp.Name, CompStatus: common.ComponentStatusPending, Details: ""}
+			tempSubs = append(tempSubs, subs[i])
+		}
+	}
+	if in.Status.OperStatus == OperStatusUp {
+		in.Status.OperStatus = OperStatusDown
+	}
+
+	in.ResourceVersion = generateVersion()
+	return tempSubs
+}
+
+// newResource initializes the common part of a resource for the given event type
+func newResource(name string, eventType string) (Resource, error) {
+	if name == "" {
+		return Resource{}, fmt.Errorf("%s name cannot be empty", eventType)
+	}
+
+	subscribers := eventbus.EBus.GetSubscribers(eventType)
+	if len(subscribers) == 0 {
+		log.Printf("newResource(): No subscribers for %s objects\n", eventType)
+		return Resource{}, fmt.Errorf("no subscribers found for %s", eventType)
+	}
+
+	components := make([]common.Component, 0)
+	for _, sub := range subscribers {
+		component := common.Component{Name: sub.Name, CompStatus: common.ComponentStatusPending, Details: ""}
+		components = append(components, component)
+	}
+
+	return Resource{
+		Name: name,
+		Status: &Status{
+			OperStatus: OperStatusDown,
+			Components: components,
+		},
+		ResourceVersion: generateVersion(),
+	}, nil
+}
+
+// resourceKind describes how a kind of resource is stored and notified
+type resourceKind struct {
+	// eventType is the event type that the components subscribe to
+	eventType string
+	// indexKey is the key of the map in the DB holding the names of the resources
+	indexKey string
+	// newObject returns an empty object of the kind
+	newObject func() resourceObject
+	// references returns the names of the objects that the resource depends on
+	references func(obj resourceObject) []string
+}
+
+// resourceKinds holds all the registered kinds of resources
+var resourceKinds []resourceKind
+
+// registerKind registers a kind of resource so that it takes part in the replay
+func registerKind(kind resourceKind) resourceKind {
+	resourceKinds = append(resourceKinds, kind)
+	return kind
+}
+
+// checkVrfExists checks that the referenced vrf exists, the caller must hold the global lock
+func checkVrfExists(name string) error {
+	vrf := Vrf{}
+	found, err := infradb.client.Get(name, &vrf)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if !found {
+		log.Printf("The VRF with name %+v has not been found\n", name)
+		return ErrVrfNotFound
+	}
+	return nil
+}
+
+// findReferrer returns the name of a resource which references the object with the
+// given name, the caller must hold the global lock
+func findReferrer(name string) (string, error) {
+	for _, kind := range resourceKinds {
+		if kind.references == nil {
+			continue
+		}
+		names, err := kind.names()
+		if err != nil {
+			return "", err
+		}
+		for _, resName := range names {
+			obj := kind.newObject()
+			if err := kind.get(resName, obj); err != nil {
+				return "", err
+			}
+			for _, ref := range kind.references(obj) {
+				if ref == name {
+					return resName, nil
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// replayObject wraps a resource which has been gathered for replay
+type replayObject struct {
+	eventType string
+	obj       resourceObject
+}
+
+// gatherForReplay prepares all the resources of the kind for replay, the caller must hold the global lock
+func (k resourceKind) gatherForReplay(componentName string) ([]interface{}, [][]*eventbus.Subscriber, error) {
+	objectsToReplay := []interface{}{}
+	subsForReplay := [][]*eventbus.Subscriber{}
+	subs := eventbus.EBus.GetSubscribers(k.eventType)
+
+	names, err := k.names()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, name := range names {
+		obj := k.newObject()
+		if err := k.get(name, obj); err != nil {
+			return nil, nil, err
+		}
+
+		// tempSubs holds the subscribers list to be contacted for every object each time
+		// for replay
+		tempSubs := obj.base().prepareObjectsForReplay(componentName, subs)
+
+		err = infradb.client.Set(name, obj)
+		if err != nil {
+			return nil, nil, err
+		}
+		subsForReplay = append(subsForReplay, tempSubs)
+		objectsToReplay = append(objectsToReplay, &replayObject{eventType: k.eventType, obj: obj})
+	}
+	return objectsToReplay, subsForReplay, nil
+}
+
+// getSubscribers returns the subscribers of the resource kind
+func (k resourceKind) getSubscribers() ([]*eventbus.Subscriber, error) {
+	subscribers := eventbus.EBus.GetSubscribers(k.eventType)
+	if len(subscribers) == 0 {
+		log.Printf("No subscribers for %s objects\n", k.eventType)
+		return nil, fmt.Errorf("no subscribers found for %s", k.eventType)
+	}
+	return subscribers, nil
+}
+
+// create stores a new resource and notifies the subscribers, the caller must hold the global lock
+func (k resourceKind) create(obj resourceObject) error {
+	subscribers, err := k.getSubscribers()
+	if err != nil {
+		return err
+	}
+	res := obj.base()
+
+	log.Printf("Create %s: %+v\n", k.eventType, obj)
+
+	err = infradb.client.Set(res.Name, obj)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	names := make(map[string]bool)
+	_, err = infradb.client.Get(k.indexKey, &names)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	names[res.Name] = false
+	err = infradb.client.Set(k.indexKey, &names)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	notifyLifecycle(StatusEventCreated, k.eventType, res.Name, res.ResourceVersion)
+	taskmanager.TaskMan.CreateTask(res.Name, k.eventType, res.ResourceVersion, subscribers)
+
+	return nil
+}
+
+// update stores the modified resource and notifies the subscribers, the caller must hold the global lock
+func (k resourceKind) update(obj resourceObject) error {
+	subscribers, err := k.getSubscribers()
+	if err != nil {
+		return err
+	}
+	res := obj.base()
+
+	for i := range res.Status.Components {
+		res.Status.Components[i].CompStatus = common.ComponentStatusPending
+	}
+	res.ResourceVersion = generateVersion()
+
+	err = infradb.client.Set(res.Name, obj)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	notifyLifecycle(StatusEventUpdated, k.eventType, res.Name, res.ResourceVersion)
+	taskmanager.TaskMan.CreateTask(res.Name, k.eventType, res.ResourceVersion, subscribers)
+
+	return nil
+}
+
+// delete marks the resource to be deleted and notifies the subscribers, the caller must hold the global lock
+func (k resourceKind) delete(obj resourceObject) error {
+	subscribers, err := k.getSubscribers()
+	if err != nil {
+		return err
+	}
+	res := obj.base()
+
+	for i := range subscribers {
+		res.Status.Components[i].CompStatus = common.ComponentStatusPending
+	}
+	res.ResourceVersion = generateVersion()
+	res.Status.OperStatus = OperStatusToBeDeleted
+
+	err = infradb.client.Set(res.Name, obj)
+	if err != nil {
+		return err
+	}
+
+	forgetLease(res.Name)
+	notifyLifecycle(StatusEventDeleting, k.eventType, res.Name, res.ResourceVersion)
+	taskmanager.TaskMan.CreateTask(res.Name, k.eventType, res.ResourceVersion, subscribers)
+
+	return nil
+}
+
+// get fetches a resource from the DB, the caller must hold the global lock
+func (k resourceKind) get(name string, obj resourceObject) error {
+	found, err := infradb.client.Get(name, obj)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// names returns the names of all the resources of the kind, the caller must hold the global lock
+func (k resourceKind) names() ([]string, error) {
+	names := make(map[string]bool)
+	_, err := infradb.client.Get(k.indexKey, &names)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	return list, nil
+}
+
+// updateStatus updates the status of the resource based on the component report
+func (k resourceKind) updateStatus(obj resourceObject, name string, resourceVersion string, notificationID string, component common.Component) error {
+	err := k.get(name, obj)
+	res := obj.base()
+	if errors.Is(err, ErrKeyNotFound) {
+		// No object has been found in the database so we will instruct TaskManager to drop the Task that is related with this status update.
+		taskmanager.TaskMan.StatusUpdated(name, k.eventType, resourceVersion, notificationID, true, &component)
+		log.Printf("updateStatus(): No %s object has been found in DB with Name %s\n", k.eventType, name)
+		return nil
+	}
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	if res.ResourceVersion != resourceVersion {
+		// Object in the database with different resourceVersion so we will instruct TaskManager to drop the Task that is related with this status update.
+		taskmanager.TaskMan.StatusUpdated(res.Name, k.eventType, res.ResourceVersion, notificationID, true, &component)
+		log.Printf("updateStatus(): Invalid resourceVersion %s for %s %+v\n", resourceVersion, k.eventType, obj)
+		return nil
+	}
+
+	if component.Replay {
+		// One of the components has requested a replay of the DB.
+		// The task related to the status update will be dropped.
+		log.Printf("updateStatus(): Component %s has requested a replay\n", component.Name)
+		taskmanager.TaskMan.StatusUpdated(res.Name, k.eventType, res.ResourceVersion, notificationID, true, &component)
+		go startReplayProcedure(component.Name)
+		return nil
+	}
+
+	before := res.Status.OperStatus
+	res.setComponentState(component)
+
+	if res.checkForAllSuccess() && res.Status.OperStatus == OperStatusToBeDeleted {
+		err = infradb.client.Delete(res.Name)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+
+		names := make(map[string]bool)
+		_, err = infradb.client.Get(k.indexKey, &names)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		delete(names, res.Name)
+		err = infradb.client.Set(k.indexKey, &names)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		log.Printf("updateStatus(): %s %s has been deleted\n", k.eventType, name)
+	} else {
+		if res.checkForAllSuccess() {
+			res.Status.OperStatus = OperStatusUp
+		}
+		err = infradb.client.Set(res.Name, obj)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		log.Printf("updateStatus(): %s %s has been updated: %+v\n", k.eventType, name, obj)
+	}
+
+	notifyStatus(k.eventType, res.Name, res.ResourceVersion, int32(before), int32(res.Status.OperStatus),
+		res.checkForAllSuccess() && res.Status.OperStatus == OperStatusToBeDeleted, component)
+	taskmanager.TaskMan.StatusUpdated(res.Name, k.eventType, res.ResourceVersion, notificationID, false, &component)
+
+	return nil
+}
@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+var (
+	// ErrRouteLeakLoop route leak creates a loop
+	ErrRouteLeakLoop = errors.New("the route leak creates a loop between VRFs")
+	// ErrRouteLeakSameVrf route leak source and destination are the same
+	ErrRouteLeakSameVrf = errors.New("the route leak source and destination VRF are the same")
+)
+
+// RouteLeakSpec holds Route Leak Spec
+type RouteLeakSpec struct {
+	// SrcVrf is the VRF that the prefixes are imported from
+	SrcVrf string
+	// DstVrf is the VRF that the prefixes are imported to
+	DstVrf string
+	// Prefixes are the IPv4 and IPv6 prefixes imported, rendered in the unicast family of their own
+	Prefixes []*net.IPNet
+}
+
+// RouteLeak holds Route Leak info
+type RouteLeak struct {
+	Resource
+	Spec *RouteLeakSpec
+}
+
+// routeLeakKind describes the storage of the Route Leak objects
+var routeLeakKind = registerKind(resourceKind{
+	eventType: "route-leak",
+	indexKey:  "routeleaks",
+	newObject: func() resourceObject { return &RouteLeak{} },
+	references: func(obj resourceObject) []string {
+		rl := obj.(*RouteLeak)
+		return []string{rl.Spec.SrcVrf, rl.Spec.DstVrf}
+	},
+})
+
+// NewRouteLeak creates new Route Leak object
+func NewRouteLeak(name string, spec *RouteLeakSpec) (*RouteLeak, error) {
+	if spec == nil || spec.SrcVrf == "" || spec.DstVrf == "" || len(spec.Prefixes) == 0 {
+		return nil, fmt.Errorf("NewRouteLeak(): Route Leak needs source, destination VRF and prefixes")
+	}
+	if spec.SrcVrf == spec.DstVrf {
+		return nil, ErrRouteLeakSameVrf
+	}
+
+	res, err := newResource(name, routeLeakKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RouteLeak{Resource: res, Spec: spec}, nil
+}
+
+// prefixesOverlap checks if any prefix of the first list overlaps with any prefix of the second list
+func prefixesOverlap(a, b []*net.IPNet) bool {
+	for _, pa := range a {
+		for _, pb := range b {
+			if pa.Contains(pb.IP) || pb.Contains(pa.IP) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkRouteLeakLoop checks that the route leak together with the existing ones and the VPC peerings
+// does not import overlapping prefixes back into the VRF that they originate from. The ways of the
+// peerings without prefixes import all the routes of their source.
+func checkRouteLeakLoop(leaks []*RouteLeak, vps []*VpcPeering, rl *RouteLeak) error {
+	visited := map[string]bool{}
+	queue := []string{rl.Spec.DstVrf}
+	for len(queue) > 0 {
+		vrf := queue[0]
+		queue = queue[1:]
+		if vrf == rl.Spec.SrcVrf {
+			return ErrRouteLeakLoop
+		}
+		if visited[vrf] {
+			continue
+		}
+		visited[vrf] = true
+		for _, leak := range leaks {
+			if leak.Name == rl.Name || leak.Status.OperStatus == OperStatusToBeDeleted {
+				continue
+			}
+			if leak.Spec.SrcVrf == vrf && prefixesOverlap(leak.Spec.Prefixes, rl.Spec.Prefixes) {
+				queue = append(queue, leak.Spec.DstVrf)
+			}
+		}
+		for _, vp := range vps {
+			if vp.Status.OperStatus == OperStatusToBeDeleted {
+				continue
+			}
+			for _, dir := range vp.Spec.Directions() {
+				if dir.Src == vrf && (len(dir.Prefixes) == 0 || prefixesOverlap(dir.Prefixes, rl.Spec.Prefixes)) {
+					queue = append(queue, dir.Dst)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// getAllRouteLeaks returns all the route leaks, the caller must hold the global lock
+func getAllRouteLeaks() ([]*RouteLeak, error) {
+	rls := []*RouteLeak{}
+	names, err := routeLeakKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		rl := &RouteLeak{}
+		if err := routeLeakKind.get(name, rl); err != nil {
+			log.Printf("getAllRouteLeaks(): Failed to get the Route Leak %s from store: %v", name, err)
+			return nil, err
+		}
+		rls = append(rls, rl)
+	}
+	return rls, nil
+}
+
+// CreateRouteLeak creates an infradb route leak object
+func CreateRouteLeak(rl *RouteLeak) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	for _, vrfName := range []string{rl.Spec.SrcVrf, rl.Spec.DstVrf} {
+		if err := checkVrfExists(vrfName); err != nil {
+			return err
+		}
+	}
+
+	rls, err := getAllRouteLeaks()
+	if err != nil {
+		return err
+	}
+	vps, err := getAllVpcPeerings()
+	if err != nil {
+		return err
+	}
+	if err := checkRouteLeakLoop(rls, vps, rl); err != nil {
+		log.Printf("CreateRouteLeak(): Route Leak %s rejected: %v\n", rl.Name, err)
+		return err
+	}
+
+	return routeLeakKind.create(rl)
+}
+
+// DeleteRouteLeak deletes a route leak infradb object
+func DeleteRouteLeak(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	rl := &RouteLeak{}
+	if err := routeLeakKind.get(name, rl); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, rl.ResourceVersion, opts); err != nil {
+		return err
+	}
+	return routeLeakKind.delete(rl)
+}
+
+// GetRouteLeak returns an infradb route leak object
+func GetRouteLeak(name string) (*RouteLeak, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	rl := &RouteLeak{}
+	err := routeLeakKind.get(name, rl)
+	return rl, err
+}
+
+// GetAllRouteLeaks returns a list of route leaks from the DB
+func GetAllRouteLeaks() ([]*RouteLeak, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllRouteLeaks()
+}
+
+// UpdateRouteLeakStatus updates the status of route leak object based on the component report
+func UpdateRouteLeakStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return routeLeakKind.updateStatus(&RouteLeak{}, name, resourceVersion, notificationID, component)
+}
@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+var (
+	// ErrRouterAdvertisementInUse the SVI already has router advertisements
+	ErrRouterAdvertisementInUse = errors.New("the SVI already has router advertisements")
+	// ErrRouterAdvertisementNoIPv6 the SVI has IPv4 gateway addresses only
+	ErrRouterAdvertisementNoIPv6 = errors.New("the SVI has IPv4 gateway addresses only")
+)
+
+const (
+	// defaultRaInterval is the longest time between the unsolicited advertisements of radvd, in seconds
+	defaultRaInterval = 600
+	// minRaInterval and maxRaInterval bound the MaxRtrAdvInterval of RFC 4861
+	minRaInterval = 4
+	maxRaInterval = 1800
+	// minIPv6Mtu is the smallest link MTU of IPv6
+	minIPv6Mtu = 1280
+)
+
+// RouterAdvertisementSpec holds Router Advertisement Spec
+type RouterAdvertisementSpec struct {
+	Svi string
+	// Managed tells the hosts to get their addresses from DHCPv6, the prefixes are then not autonomous
+	Managed bool
+	// Other tells the hosts to get the other configuration, e.g. the DNS servers, from DHCPv6
+	Other bool
+	// Prefixes defaults to the IPv6 subnets of the gateway addresses of the SVI, none for a link-local only SVI
+	Prefixes []*net.IPNet
+	// Rdnss are the recursive DNS servers of RFC 8106
+	Rdnss []net.IP
+	// Interval is the longest time between the unsolicited advertisements in seconds
+	Interval uint32
+	// Mtu defaults to the MTU of the SVI
+	Mtu uint32
+}
+
+// RouterAdvertisement holds Router Advertisement info
+type RouterAdvertisement struct {
+	Resource
+	Spec *RouterAdvertisementSpec
+}
+
+// routerAdvertisementKind describes the storage of the Router Advertisement objects
+var routerAdvertisementKind = registerKind(resourceKind{
+	eventType: "router-advertisement",
+	indexKey:  "routeradvertisements",
+	newObject: func() resourceObject { return &RouterAdvertisement{} },
+	references: func(obj resourceObject) []string {
+		return []string{obj.(*RouterAdvertisement).Spec.Svi}
+	},
+})
+
+// validate checks the Router Advertisement Spec and sets the default interval
+func (in *RouterAdvertisementSpec) validate() error {
+	if in.Svi == "" {
+		return fmt.Errorf("router advertisement needs an SVI")
+	}
+	for _, prefix := range in.Prefixes {
+		if prefix.IP.To4() != nil {
+			return fmt.Errorf("router advertisement prefix %s is not an IPv6 prefix", prefix)
+		}
+	}
+	for _, ip := range in.Rdnss {
+		if ip.To4() != nil || ip.IsUnspecified() || ip.IsMulticast() {
+			return fmt.Errorf("router advertisement DNS server %s is not an IPv6 unicast address", ip)
+		}
+	}
+	if in.Interval == 0 {
+		in.Interval = defaultRaInterval
+	}
+	if in.Interval < minRaInterval || in.Interval > maxRaInterval {
+		return fmt.Errorf("router advertisement interval %d is not between %d and %d", in.Interval, minRaInterval, maxRaInterval)
+	}
+	if in.Mtu != 0 && in.Mtu < minIPv6Mtu {
+		return fmt.Errorf("router advertisement MTU %d is below the IPv6 minimum %d", in.Mtu, minIPv6Mtu)
+	}
+	return nil
+}
+
+// NewRouterAdvertisement creates new Router Advertisement object
+func NewRouterAdvertisement(name string, spec *RouterAdvertisementSpec) (*RouterAdvertisement, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewRouterAdvertisement(): Router Advertisement spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("NewRouterAdvertisement(): %v", err)
+	}
+
+	res, err := newResource(name, routerAdvertisementKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RouterAdvertisement{Resource: res, Spec: spec}, nil
+}
+
+// getAllRouterAdvertisements returns all the router advertisements, the caller must hold the global lock
+func getAllRouterAdvertisements() ([]*RouterAdvertisement, error) {
+	ras := []*RouterAdvertisement{}
+	names, err := routerAdvertisementKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		ra := &RouterAdvertisement{}
+		if err := routerAdvertisementKind.get(name, ra); err != nil {
+			log.Printf("getAllRouterAdvertisements(): Failed to get the Router Advertisement %s from store: %v", name, err)
+			return nil, err
+		}
+		ras = append(ras, ra)
+	}
+	return ras, nil
+}
+
+// CreateRouterAdvertisement creates an infradb router advertisement object
+func CreateRouterAdvertisement(ra *RouterAdvertisement) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	svi := Svi{}
+	found, err := infradb.client.Get(ra.Spec.Svi, &svi)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if !found {
+		log.Printf("CreateRouterAdvertisement(): The SVI with name %+v has not been found\n", ra.Spec.Svi)
+		return ErrSviNotFound
+	}
+	// A link-local only SVI advertises the default router without prefix, the hosts route over it
+	hasIPv6 := svi.Spec.IsUnnumbered()
+	for _, gwIP := range svi.Spec.GatewayIPs {
+		hasIPv6 = hasIPv6 || gwIP.IP.To4() == nil
+	}
+	if !hasIPv6 {
+		return ErrRouterAdvertisementNoIPv6
+	}
+
+	ras, err := getAllRouterAdvertisements()
+	if err != nil {
+		return err
+	}
+	for _, existing := range ras {
+		if existing.Spec.Svi == ra.Spec.Svi {
+			log.Printf("CreateRouterAdvertisement(): %s already has the router advertisement %s\n", ra.Spec.Svi, existing.Name)
+			return ErrRouterAdvertisementInUse
+		}
+	}
+
+	return routerAdvertisementKind.create(ra)
+}
+
+// DeleteRouterAdvertisement deletes a router advertisement infradb object
+func DeleteRouterAdvertisement(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	ra := &RouterAdvertisement{}
+	if err := routerAdvertisementKind.get(name, ra); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, ra.ResourceVersion, opts); err != nil {
+		return err
+	}
+	return routerAdvertisementKind.delete(ra)
+}
+
+// GetRouterAdvertisement returns an infradb router advertisement object
+func GetRouterAdvertisement(name string) (*RouterAdvertisement, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	ra := &RouterAdvertisement{}
+	err := routerAdvertisementKind.get(name, ra)
+	return ra, err
+}
+
+// GetAllRouterAdvertisements returns a list of router advertisements from the DB
+func GetAllRouterAdvertisements() ([]*RouterAdvertisement, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllRouterAdvertisements()
+}
+
+// UpdateRouterAdvertisementStatus updates the status of router advertisement object based on the component report
+func UpdateRouterAdvertisementStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return routerAdvertisementKind.updateStatus(&RouterAdvertisement{}, name, resourceVersion, notificationID, component)
+}
@@ -0,0 +1,288 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"path"
+	"regexp"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+var (
+	// ErrRoutingPolicyAttached the attachment point already has a routing policy in that direction
+	ErrRoutingPolicyAttached = errors.New("the attachment point already has a routing policy in that direction")
+	// ErrRoutingPolicyNoBgp the SVI which the routing policy is attached to does not run BGP
+	ErrRoutingPolicyNoBgp = errors.New("the SVI does not run BGP")
+	// ErrSviNotFound the referenced SVI has not been found
+	ErrSviNotFound = errors.New("the referenced SVI has not been found")
+	// ErrSviInUse the SVI is referenced by another resource
+	ErrSviInUse = errors.New("the SVI is used by another resource")
+)
+
+const (
+	// PolicyImport applies the routing policy to the routes learned by the attachment point
+	PolicyImport = "import"
+	// PolicyExport applies the routing policy to the routes advertised by the attachment point
+	PolicyExport = "export"
+)
+
+// communityRegexp matches the standard communities in the AA:NN format and the well-known ones
+var communityRegexp = regexp.MustCompile(`^([0-9]+:[0-9]+|no-export|no-advertise|local-AS|graceful-shutdown|blackhole)$`)
+
+// RoutingPolicyRule is a term of a routing policy, the rules are evaluated in the order of their sequence
+// and the first one matching a route decides its fate
+type RoutingPolicyRule struct {
+	Seq    uint32
+	Permit bool
+	// MatchPrefixes are of one address family, the rule matches any route when it has no match
+	MatchPrefixes []*net.IPNet
+	// MaxPrefixLen also matches the more specific routes of the prefixes up to that length, zero matches the exact prefixes
+	MaxPrefixLen     uint32
+	MatchCommunities []string
+	SetLocalPref     *uint32
+	SetMed           *uint32
+	// SetCommunities are added to the communities of the route
+	SetCommunities []string
+}
+
+// RoutingPolicyAttachment attaches a routing policy to a VRF, applying to the EVPN routes it imports
+// and exports, or to an SVI running BGP, applying to the routes of its peers
+type RoutingPolicyAttachment struct {
+	Target    string
+	Direction string
+}
+
+// RoutingPolicySpec holds Routing Policy Spec
+type RoutingPolicySpec struct {
+	Rules       []*RoutingPolicyRule
+	Attachments []*RoutingPolicyAttachment
+}
+
+// RoutingPolicy holds Routing Policy info
+type RoutingPolicy struct {
+	Resource
+	Spec *RoutingPolicySpec
+}
+
+// routingPolicyKind describes the storage of the Routing Policy objects
+var routingPolicyKind = registerKind(resourceKind{
+	eventType: "routing-policy",
+	indexKey:  "routingpolicies",
+	newObject: func() resourceObject { return &RoutingPolicy{} },
+	references: func(obj resourceObject) []string {
+		refs := []string{}
+		for _, att := range obj.(*RoutingPolicy).Spec.Attachments {
+			refs = append(refs, att.Target)
+		}
+		return refs
+	},
+})
+
+// validate checks the Routing Policy Spec
+func (in *RoutingPolicySpec) validate() error {
+	if len(in.Rules) == 0 {
+		return fmt.Errorf("routing policy needs a rule")
+	}
+	seqs := map[uint32]bool{}
+	for _, rule := range in.Rules {
+		if rule.Seq == 0 || rule.Seq > 65535 {
+			return fmt.Errorf("routing policy rule sequence %d has to be between 1 and 65535", rule.Seq)
+		}
+		if seqs[rule.Seq] {
+			return fmt.Errorf("routing policy rule sequence %d is duplicated", rule.Seq)
+		}
+		seqs[rule.Seq] = true
+		for _, prefix := range rule.MatchPrefixes {
+			ones, bits := prefix.Mask.Size()
+			if _, family := rule.MatchPrefixes[0].Mask.Size(); bits != family {
+				return fmt.Errorf("routing policy rule %d mixes address families", rule.Seq)
+			}
+			if rule.MaxPrefixLen != 0 && (rule.MaxPrefixLen < uint32(ones) || rule.MaxPrefixLen > uint32(bits)) {
+				return fmt.Errorf("routing policy rule %d max prefix length %d does not fit prefix %s", rule.Seq, rule.MaxPrefixLen, prefix)
+			}
+		}
+		for _, community := range append(append([]string{}, rule.MatchCommunities...), rule.SetCommunities...) {
+			if !communityRegexp.MatchString(community) {
+				return fmt.Errorf("routing policy rule %d community %q is not valid", rule.Seq, community)
+			}
+		}
+	}
+	attachments := map[RoutingPolicyAttachment]bool{}
+	for _, att := range in.Attachments {
+		if att.Direction != PolicyImport && att.Direction != PolicyExport {
+			return fmt.Errorf("routing policy direction %q has to be %s or %s", att.Direction, PolicyImport, PolicyExport)
+		}
+		if kind := path.Base(path.Dir(att.Target)); kind != "vrfs" && kind != "svis" {
+			return fmt.Errorf("routing policy can only be attached to a VRF or an SVI, not %s", att.Target)
+		}
+		if attachments[*att] {
+			return fmt.Errorf("routing policy attachment %s %s is duplicated", att.Target, att.Direction)
+		}
+		attachments[*att] = true
+	}
+	return nil
+}
+
+// NewRoutingPolicy creates new Routing Policy object
+func NewRoutingPolicy(name string, spec *RoutingPolicySpec) (*RoutingPolicy, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewRoutingPolicy(): Routing Policy spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+
+	res, err := newResource(name, routingPolicyKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RoutingPolicy{Resource: res, Spec: spec}, nil
+}
+
+// getAllRoutingPolicies returns all the routing policies, the caller must hold the global lock
+func getAllRoutingPolicies() ([]*RoutingPolicy, error) {
+	rps := []*RoutingPolicy{}
+	names, err := routingPolicyKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		rp := &RoutingPolicy{}
+		if err := routingPolicyKind.get(name, rp); err != nil {
+			log.Printf("getAllRoutingPolicies(): Failed to get the Routing Policy %s from store: %v", name, err)
+			return nil, err
+		}
+		rps = append(rps, rp)
+	}
+	return rps, nil
+}
+
+// checkRoutingPolicyAttachments checks that the attachment points exist and have no other
+// policy in the same direction, the caller must hold the global lock
+func checkRoutingPolicyAttachments(rp *RoutingPolicy) error {
+	for _, att := range rp.Spec.Attachments {
+		if path.Base(path.Dir(att.Target)) == "vrfs" {
+			if err := checkVrfExists(att.Target); err != nil {
+				return err
+			}
+			continue
+		}
+		svi := Svi{}
+		found, err := infradb.client.Get(att.Target, &svi)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrSviNotFound
+		}
+		if !svi.Spec.EnableBgp {
+			return ErrRoutingPolicyNoBgp
+		}
+	}
+
+	rps, err := getAllRoutingPolicies()
+	if err != nil {
+		return err
+	}
+	for _, other := range rps {
+		if other.Name == rp.Name || other.Status.OperStatus == OperStatusToBeDeleted {
+			continue
+		}
+		for _, att := range other.Spec.Attachments {
+			for _, mine := range rp.Spec.Attachments {
+				if *att == *mine {
+					log.Printf("Routing Policy %s rejected: %s %s is attached to %s\n", rp.Name, att.Target, att.Direction, other.Name)
+					return ErrRoutingPolicyAttached
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// CreateRoutingPolicy creates an infradb routing policy object
+func CreateRoutingPolicy(rp *RoutingPolicy) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	if err := checkRoutingPolicyAttachments(rp); err != nil {
+		return err
+	}
+	return routingPolicyKind.create(rp)
+}
+
+// UpdateRoutingPolicy replaces the spec of a routing policy, the subscribers render it again
+// together with all its attachments
+func UpdateRoutingPolicy(rp *RoutingPolicy, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	existing := &RoutingPolicy{}
+	if err := routingPolicyKind.get(rp.Name, existing); err != nil {
+		return err
+	}
+	if err := checkIfMatch(rp.Name, existing.ResourceVersion, opts); err != nil {
+		return err
+	}
+	if existing.Status.OperStatus == OperStatusToBeDeleted {
+		return ErrKeyNotFound
+	}
+	if err := checkRoutingPolicyAttachments(rp); err != nil {
+		return err
+	}
+	existing.Spec = rp.Spec
+	if err := routingPolicyKind.update(existing); err != nil {
+		return err
+	}
+	*rp = *existing
+	return nil
+}
+
+// DeleteRoutingPolicy deletes a routing policy infradb object
+func DeleteRoutingPolicy(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	rp := &RoutingPolicy{}
+	if err := routingPolicyKind.get(name, rp); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, rp.ResourceVersion, opts); err != nil {
+		return err
+	}
+	return routingPolicyKind.delete(rp)
+}
+
+// GetRoutingPolicy returns an infradb routing policy object
+func GetRoutingPolicy(name string) (*RoutingPolicy, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	rp := &RoutingPolicy{}
+	err := routingPolicyKind.get(name, rp)
+	return rp, err
+}
+
+// GetAllRoutingPolicies returns a list of routing policies from the DB
+func GetAllRoutingPolicies() ([]*RoutingPolicy, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllRoutingPolicies()
+}
+
+// UpdateRoutingPolicyStatus updates the status of routing policy object based on the component report
+func UpdateRoutingPolicyStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return routingPolicyKind.updateStatus(&RoutingPolicy{}, name, resourceVersion, notificationID, component)
+}
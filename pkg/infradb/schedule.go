@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+)
+
+// schedulesKey is the key of the DB map holding the scheduled changes by name
+var schedulesKey = registerStoreKey("schedules")
+
+// ScheduleState is the progress of a scheduled change
+type ScheduleState string
+
+const (
+	// SchedulePending waits for the time of the change
+	SchedulePending ScheduleState = "PENDING"
+	// ScheduleRunning is being applied
+	ScheduleRunning ScheduleState = "RUNNING"
+	// ScheduleAwaitingConfirmation has been applied and is reverted unless it is confirmed in time
+	ScheduleAwaitingConfirmation ScheduleState = "AWAITING_CONFIRMATION"
+	// ScheduleDone has been applied for good
+	ScheduleDone ScheduleState = "DONE"
+	// ScheduleReverted has been applied then reverted as it was not confirmed
+	ScheduleReverted ScheduleState = "REVERTED"
+	// ScheduleFailed could not be applied, the transaction has been rolled back
+	ScheduleFailed ScheduleState = "FAILED"
+)
+
+// IsFinal tells whether the scheduled change will not change anymore
+func (s ScheduleState) IsFinal() bool {
+	return s == ScheduleDone || s == ScheduleReverted || s == ScheduleFailed
+}
+
+// ScheduledChange is a bundle applied at a given time, which is reverted after a window unless it is confirmed
+type ScheduledChange struct {
+	Name string
+	// Bundle is the YAML or JSON bundle of the apply endpoint
+	Bundle []byte
+	Prune  bool
+	// ExecuteAt is when the bundle is applied
+	ExecuteAt time.Time
+	// RevertAfter is the window after which the applied bundle is reverted unless it is confirmed, none when zero
+	RevertAfter time.Duration
+	State       ScheduleState
+	AppliedAt   time.Time
+	// RevertAt is the end of the window, set once the bundle is applied
+	RevertAt time.Time
+	// Snapshot is what it takes to revert the applied bundle, in its json form
+	Snapshot []byte
+	Error    string
+}
+
+// loadScheduledChanges returns the scheduled changes by name, the caller must hold the global lock
+func loadScheduledChanges() (map[string]*ScheduledChange, error) {
+	changes := make(map[string]*ScheduledChange)
+	if _, err := infradb.client.Get(schedulesKey, &changes); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return changes, nil
+}
+
+// CreateScheduledChange stores the scheduled change, it fails when a change of the same name already exists
+func CreateScheduledChange(c *ScheduledChange) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	changes, err := loadScheduledChanges()
+	if err != nil {
+		return err
+	}
+	if _, ok := changes[c.Name]; ok {
+		return apierrors.AlreadyExists("schedules", c.Name, "the scheduled change %s already exists", c.Name)
+	}
+	changes[c.Name] = c
+	return infradb.client.Set(schedulesKey, changes)
+}
+
+// UpdateScheduledChange changes the scheduled change of the name with the update function, nothing
+// is stored when the function fails. The function runs under the lock of the store, it must be quick.
+func UpdateScheduledChange(name string, update func(c *ScheduledChange) error) (*ScheduledChange, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	changes, err := loadScheduledChanges()
+	if err != nil {
+		return nil, err
+	}
+	c, ok := changes[name]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	if err := update(c); err != nil {
+		return nil, err
+	}
+	if err := infradb.client.Set(schedulesKey, changes); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetScheduledChange returns the scheduled change of the name
+func GetScheduledChange(name string) (*ScheduledChange, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	changes, err := loadScheduledChanges()
+	if err != nil {
+		return nil, err
+	}
+	c, ok := changes[name]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return c, nil
+}
+
+// GetAllScheduledChanges returns the scheduled changes in the order of their time
+func GetAllScheduledChanges() ([]*ScheduledChange, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	changes, err := loadScheduledChanges()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*ScheduledChange, 0, len(changes))
+	for _, c := range changes {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].ExecuteAt.Equal(out[j].ExecuteAt) {
+			return out[i].ExecuteAt.Before(out[j].ExecuteAt)
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// DeleteScheduledChange removes the scheduled change of the name when the check allows it
+func DeleteScheduledChange(name string, check func(c *ScheduledChange) error) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	changes, err := loadScheduledChanges()
+	if err != nil {
+		return err
+	}
+	c, ok := changes[name]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	if err := check(c); err != nil {
+		return err
+	}
+	delete(changes, name)
+	return infradb.client.Set(schedulesKey, changes)
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+)
+
+// ErrSecondaryIPOverlap the secondary address of the SVI overlaps with the subnet of another SVI of the VRF
+var ErrSecondaryIPOverlap = errors.New("the secondary address overlaps with the subnet of another SVI")
+
+// Addresses returns the addresses programmed on the SVI: its gateway addresses followed by its secondary addresses
+func (in *SviSpec) Addresses() []*net.IPNet {
+	return append(append([]*net.IPNet{}, in.GatewayIPs...), in.SecondaryIPs...)
+}
+
+// isHostPrefix tells whether the prefix is a single address, a /32 or a /128 virtual IP
+func isHostPrefix(prefix *net.IPNet) bool {
+	ones, bits := prefix.Mask.Size()
+	return ones == bits
+}
+
+// sameSubnet tells whether the two prefixes are the same subnet
+func sameSubnet(a, b *net.IPNet) bool {
+	return a.IP.Mask(a.Mask).Equal(b.IP.Mask(b.Mask)) && a.Mask.String() == b.Mask.String()
+}
+
+// validateSecondaryIPs checks the secondary addresses against the gateway addresses of the SVI and against each
+// other: a virtual IP stands on its own, any other address lies in a subnet of the SVI or in a subnet of its own
+func validateSecondaryIPs(spec *SviSpec, ips []*net.IPNet) error {
+	for i, ip := range ips {
+		if ip.IP.IsUnspecified() || ip.IP.IsMulticast() || ip.IP.IsLoopback() || ip.IP.IsLinkLocalUnicast() {
+			return fmt.Errorf("secondary address %s is not a unicast address", ip)
+		}
+		if !isHostPrefix(ip) && isNetworkAddress(ip) {
+			return fmt.Errorf("secondary address %s is a network address", ip)
+		}
+		for _, other := range append(append([]*net.IPNet{}, spec.GatewayIPs...), ips[:i]...) {
+			if other.IP.Equal(ip.IP) {
+				return fmt.Errorf("secondary address %s is already an address of the SVI", ip)
+			}
+			if !isHostPrefix(ip) && !isHostPrefix(other) && !sameSubnet(ip, other) && prefixesOverlap([]*net.IPNet{ip}, []*net.IPNet{other}) {
+				return fmt.Errorf("secondary address %s overlaps with %s of the SVI", ip, other)
+			}
+		}
+	}
+	return nil
+}
+
+// checkSecondaryIPs checks the secondary addresses against the subnets and the secondary addresses of the other
+// SVIs of the VRF, against its host routes and against the addresses allocated in the subnets of the SVI, the caller
+// must hold the global lock
+func checkSecondaryIPs(svi *Svi, ips []*net.IPNet) error {
+	vrf := &Vrf{}
+	found, err := infradb.client.Get(svi.Spec.Vrf, vrf)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrVrfNotFound
+	}
+	for name := range vrf.Svis {
+		if name == svi.Name {
+			continue
+		}
+		other := &Svi{}
+		found, err := infradb.client.Get(name, other)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		if prefixesOverlap(ips, other.Spec.Addresses()) {
+			log.Printf("checkSecondaryIPs(): The secondary addresses of %s overlap with %s\n", svi.Name, name)
+			return ErrSecondaryIPOverlap
+		}
+	}
+	hostRoutes, err := vrfHostRoutes(svi.Spec.Vrf, "")
+	if err != nil {
+		return err
+	}
+	if prefixesOverlap(ips, hostRoutes) {
+		log.Printf("checkSecondaryIPs(): The secondary addresses of %s overlap with the host routes of %s\n", svi.Name, svi.Spec.Vrf)
+		return ErrSecondaryIPOverlap
+	}
+	allocations, err := getAllocations(svi.Name)
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		if _, ok := allocations[ip.IP.String()]; ok {
+			return fmt.Errorf("secondary address %s: %w", ip.IP, ErrIPAddressInUse)
+		}
+	}
+	return nil
+}
+
+// SetSviSecondaryIPs sets the secondary addresses of an SVI, e.g. the legacy gateway addresses kept during a
+// migration or virtual IPs, nil removes them. The SVI is programmed again with the addresses.
+func SetSviSecondaryIPs(name string, ips []*net.IPNet, opts ...WriteOption) (*Svi, error) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	subscribers := eventbus.EBus.GetSubscribers("svi")
+	if len(subscribers) == 0 {
+		log.Println("SetSviSecondaryIPs(): No subscribers for SVI objects")
+		return nil, errors.New("no subscribers found for svi")
+	}
+
+	svi := &Svi{}
+	found, err := infradb.client.Get(name, svi)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	if err := checkIfMatch(name, svi.ResourceVersion, opts); err != nil {
+		return nil, err
+	}
+	if svi.Status.SviOperStatus == SviOperStatusToBeDeleted {
+		return nil, ErrSviToBeDeleted
+	}
+	if err := validateSecondaryIPs(svi.Spec, ips); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "SetSviSecondaryIPs(): %v", err)
+	}
+	if err := checkSecondaryIPs(svi, ips); err != nil {
+		return nil, err
+	}
+
+	svi.Spec.SecondaryIPs = ips
+	for i := range svi.Status.Components {
+		svi.Status.Components[i].CompStatus = common.ComponentStatusPending
+	}
+	svi.ResourceVersion = generateVersion()
+
+	err = infradb.client.Set(svi.Name, svi)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	notifyLifecycle(StatusEventUpdated, "svi", svi.Name, svi.ResourceVersion)
+	taskmanager.TaskMan.CreateTask(svi.Name, "svi", svi.ResourceVersion, subscribers)
+
+	return svi, nil
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+)
+
+// ErrBridgePortToBeDeleted the bridge port is being deleted
+var ErrBridgePortToBeDeleted = errors.New("the bridge port is being deleted")
+
+const (
+	// minSflowHeaderLength keeps the ethernet, ip and transport headers of the samples
+	minSflowHeaderLength = 64
+	// maxSflowHeaderLength is the largest header of the sFlow sampled_header records
+	maxSflowHeaderLength = 256
+	// defaultSflowHeaderLength is the header length of the sFlow agents
+	defaultSflowHeaderLength = 128
+)
+
+// SflowSpec holds the sFlow sampling of a Bridge Port
+type SflowSpec struct {
+	// SamplingRate samples one in SamplingRate packets
+	SamplingRate uint32
+	// HeaderLength is how many bytes of the sampled packets are sent
+	HeaderLength uint32
+	// Collector is the host:port of the sFlow collector, reached over udp
+	Collector string
+}
+
+// validate checks the sFlow sampling and sets the default header length
+func (in *SflowSpec) validate() error {
+	if in.SamplingRate == 0 {
+		return fmt.Errorf("sFlow sampling rate cannot be zero")
+	}
+	if in.HeaderLength == 0 {
+		in.HeaderLength = defaultSflowHeaderLength
+	}
+	if in.HeaderLength < minSflowHeaderLength || in.HeaderLength > maxSflowHeaderLength {
+		return fmt.Errorf("sFlow header length %d is not between %d and %d", in.HeaderLength, minSflowHeaderLength, maxSflowHeaderLength)
+	}
+	host, port, err := net.SplitHostPort(in.Collector)
+	if err != nil || host == "" || port == "" {
+		return fmt.Errorf("sFlow collector %q is not a host:port", in.Collector)
+	}
+	return nil
+}
+
+// NewSflowSpec returns the validated sFlow sampling, a zero header length is the default one
+func NewSflowSpec(samplingRate, headerLength uint32, collector string) (*SflowSpec, error) {
+	in := &SflowSpec{SamplingRate: samplingRate, HeaderLength: headerLength, Collector: collector}
+	if err := in.validate(); err != nil {
+		return nil, fmt.Errorf("NewSflowSpec(): %w", err)
+	}
+	return in, nil
+}
+
+// SetBridgePortSflow sets the sFlow sampling of the bridge port, nil stops it, the bridge port is
+// programmed again with the sampling
+func SetBridgePortSflow(name string, sflow *SflowSpec, opts ...WriteOption) (*BridgePort, error) {
+	if sflow != nil {
+		if err := sflow.validate(); err != nil {
+			return nil, fmt.Errorf("SetBridgePortSflow(): %w", err)
+		}
+	}
+
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	subscribers := eventbus.EBus.GetSubscribers("bridge-port")
+	if len(subscribers) == 0 {
+		log.Println("SetBridgePortSflow(): No subscribers for Bridge Port objects")
+		return nil, errors.New("no subscribers found for bridge port")
+	}
+
+	bp := &BridgePort{}
+	found, err := infradb.client.Get(name, bp)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	if err := checkIfMatch(name, bp.ResourceVersion, opts); err != nil {
+		return nil, err
+	}
+	if bp.Status.BPOperStatus == BridgePortOperStatusToBeDeleted {
+		return nil, ErrBridgePortToBeDeleted
+	}
+	if err := checkNotProfiled(bp, func(p *BridgePortProfile) bool { return p.Sflow != nil }); err != nil {
+		return nil, err
+	}
+
+	bp.Spec.Sflow = sflow
+	for i := range bp.Status.Components {
+		bp.Status.Components[i].CompStatus = common.ComponentStatusPending
+	}
+	bp.ResourceVersion = generateVersion()
+
+	err = infradb.client.Set(bp.Name, bp)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	notifyLifecycle(StatusEventUpdated, "bridge-port", bp.Name, bp.ResourceVersion)
+	taskmanager.TaskMan.CreateTask(bp.Name, "bridge-port", bp.ResourceVersion, subscribers)
+
+	return bp, nil
+}
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+var (
+	// ErrSrv6Unsupported the SRv6 dataplane is disabled or the routing backend cannot carry it
+	ErrSrv6Unsupported = errors.New("the SRv6 dataplane is not supported")
+	// ErrSrv6FunctionsExhausted every function of the locator is in use
+	ErrSrv6FunctionsExhausted = apierrors.Exhausted(apierrors.ReasonExhausted, 0, "no function is left in the SRv6 locator")
+)
+
+const (
+	// srv6FunctionBits is the length of the functions which follow the locator in the SIDs
+	srv6FunctionBits = 16
+	// maxSrv6Function is the highest function of the locator, 0 is the locator itself
+	maxSrv6Function = 1<<srv6FunctionBits - 1
+)
+
+// Srv6Spec selects the SRv6 dataplane for a VPC, which is then carried over the SRv6 fabric as an L3VPN
+// whose routes are decapsulated by its End.DT4 and End.DT6 SIDs
+type Srv6Spec struct {
+	// Dt4Function and Dt6Function are the functions of the SIDs in the locator, allocated when 0
+	Dt4Function uint32
+	Dt6Function uint32
+}
+
+// validate checks the functions
+func (in *Srv6Spec) validate() error {
+	if in.Dt4Function > maxSrv6Function || in.Dt6Function > maxSrv6Function {
+		return fmt.Errorf("srv6 functions must be between 1 and %d", maxSrv6Function)
+	}
+	if in.Dt4Function != 0 && in.Dt4Function == in.Dt6Function {
+		return fmt.Errorf("srv6 End.DT4 and End.DT6 need different functions, not %d", in.Dt4Function)
+	}
+	return nil
+}
+
+// NewSrv6Spec returns the validated SRv6 dataplane
+func NewSrv6Spec(dt4Function, dt6Function uint32) (*Srv6Spec, error) {
+	in := &Srv6Spec{Dt4Function: dt4Function, Dt6Function: dt6Function}
+	if err := in.validate(); err != nil {
+		return nil, fmt.Errorf("NewSrv6Spec(): %w", err)
+	}
+	return in, nil
+}
+
+// Srv6Locator returns the locator of the config
+func Srv6Locator() (netip.Prefix, error) {
+	locator, err := netip.ParsePrefix(config.GlobalConfig.Srv6.Locator)
+	if err != nil || !locator.Addr().Is6() || locator.Bits() > 128-srv6FunctionBits {
+		return netip.Prefix{}, fmt.Errorf("%w: invalid srv6 locator %q", ErrSrv6Unsupported, config.GlobalConfig.Srv6.Locator)
+	}
+	return locator.Masked(), nil
+}
+
+// Srv6Sid returns the SID of the function in the locator, the function taking the bits following the locator
+func Srv6Sid(locator netip.Prefix, function uint32) netip.Addr {
+	sid := locator.Masked().Addr().As16()
+	hi, lo := binary.BigEndian.Uint64(sid[:8]), binary.BigEndian.Uint64(sid[8:])
+	shift := 128 - locator.Bits() - srv6FunctionBits
+	if shift >= 64 {
+		hi |= uint64(function) << (shift - 64)
+	} else {
+		lo |= uint64(function) << shift
+		hi |= uint64(function) >> (64 - shift)
+	}
+	binary.BigEndian.PutUint64(sid[:8], hi)
+	binary.BigEndian.PutUint64(sid[8:], lo)
+	return netip.AddrFrom16(sid)
+}
+
+// Sids returns the End.DT4 and End.DT6 SIDs of the VPC
+func (in *Srv6Spec) Sids(locator netip.Prefix) (dt4 netip.Addr, dt6 netip.Addr) {
+	return Srv6Sid(locator, in.Dt4Function), Srv6Sid(locator, in.Dt6Function)
+}
+
+// IsSrv6 tells whether the VPC is carried over the SRv6 fabric
+func (in *VrfSpec) IsSrv6() bool {
+	return in.Vni != nil && in.Srv6 != nil
+}
+
+// checkSrv6Support checks that the locator is configured and that the routing backend carries the VPCs over SRv6
+func checkSrv6Support() error {
+	if !config.GlobalConfig.Srv6.Enabled {
+		return fmt.Errorf("%w: srv6 is not enabled in the config", ErrSrv6Unsupported)
+	}
+	if _, err := Srv6Locator(); err != nil {
+		return err
+	}
+	backend, err := routing.Get()
+	if err != nil {
+		return err
+	}
+	if _, ok := backend.(routing.Srv6Configurer); !ok {
+		return fmt.Errorf("%w: the %s routing backend does not carry the VPCs over SRv6", ErrSrv6Unsupported, backend.Name())
+	}
+	return nil
+}
+
+// usedSrv6Functions returns the functions of the locator used by the vrfs but the named one, the caller
+// holds the global lock
+func usedSrv6Functions(name string) (map[uint32]string, error) {
+	names, err := storedNames("vrfs")
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[uint32]string)
+	for _, other := range names {
+		if other == name {
+			continue
+		}
+		vrf := Vrf{}
+		found, err := infradb.client.Get(other, &vrf)
+		if err != nil {
+			return nil, err
+		}
+		if !found || vrf.Spec == nil || vrf.Spec.Srv6 == nil {
+			continue
+		}
+		used[vrf.Spec.Srv6.Dt4Function] = other
+		used[vrf.Spec.Srv6.Dt6Function] = other
+	}
+	return used, nil
+}
+
+// claimSrv6Function gives the function to the vrf, a zero function is replaced by the lowest free one
+func claimSrv6Function(used map[uint32]string, name string, function *uint32) error {
+	if *function == 0 {
+		for f := uint32(1); f <= maxSrv6Function; f++ {
+			if _, ok := used[f]; !ok {
+				*function = f
+				break
+			}
+		}
+		if *function == 0 {
+			return ErrSrv6FunctionsExhausted
+		}
+	} else if owner, ok := used[*function]; ok {
+		return status.Errorf(codes.AlreadyExists, "the SRv6 function %d is already used by %s", *function, owner)
+	}
+	used[*function] = name
+	return nil
+}
+
+// SetVrfSrv6 carries the VPC of the vrf over the SRv6 fabric with the SIDs of the functions, the missing
+// ones being allocated from the locator, nil is back to VXLAN. The vrf is programmed again over its new dataplane.
+func SetVrfSrv6(name string, srv6 *Srv6Spec, opts ...WriteOption) (*Vrf, error) {
+	if srv6 != nil {
+		if err := srv6.validate(); err != nil {
+			return nil, fmt.Errorf("SetVrfSrv6(): %w", err)
+		}
+		if err := checkSrv6Support(); err != nil {
+			return nil, err
+		}
+	}
+	return setVrfDataplane("SetVrfSrv6", name, func(vrf *Vrf) error {
+		if srv6 != nil && vrf.Spec.Netns != "" {
+			return ErrNetnsVpn
+		}
+		if srv6 != nil {
+			used, err := usedSrv6Functions(vrf.Name)
+			if err != nil {
+				return err
+			}
+			spec := *srv6
+			if err := claimSrv6Function(used, vrf.Name, &spec.Dt4Function); err != nil {
+				return err
+			}
+			if err := claimSrv6Function(used, vrf.Name, &spec.Dt6Function); err != nil {
+				return err
+			}
+			srv6 = &spec
+		}
+		vrf.Spec.Mpls, vrf.Spec.Srv6 = nil, srv6
+		return nil
+	}, opts...)
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"net/netip"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_Srv6Sid(t *testing.T) {
+	tests := map[string]struct {
+		locator  string
+		function uint32
+		expected string
+	}{
+		"function after a /48":     {locator: "fc00:0:1::/48", function: 1, expected: "fc00:0:1:1::"},
+		"function after a /64":     {locator: "fc00:0:1:2::/64", function: 0xabcd, expected: "fc00:0:1:2:abcd::"},
+		"function across words":    {locator: "fc00:0:1:200::/56", function: 0x1234, expected: "fc00:0:1:212:3400::"},
+		"host bits of the locator": {locator: "fc00:0:1::1/48", function: 2, expected: "fc00:0:1:2::"},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			sid := Srv6Sid(netip.MustParsePrefix(tt.locator), tt.function)
+			if sid != netip.MustParseAddr(tt.expected) {
+				t.Errorf("expected %s, received %s", tt.expected, sid)
+			}
+		})
+	}
+}
+
+func Test_ClaimSrv6Function(t *testing.T) {
+	tests := map[string]struct {
+		used     map[uint32]string
+		function uint32
+		expected uint32
+		code     codes.Code
+	}{
+		"allocated from the locator": {
+			used:     map[uint32]string{1: "blue", 2: "blue"},
+			expected: 3,
+		},
+		"explicit function": {
+			used:     map[uint32]string{1: "blue"},
+			function: 100,
+			expected: 100,
+		},
+		"explicit function already used": {
+			used:     map[uint32]string{100: "blue"},
+			function: 100,
+			code:     codes.AlreadyExists,
+		},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			function := tt.function
+			err := claimSrv6Function(tt.used, "green", &function)
+			if status.Code(err) != tt.code {
+				t.Fatalf("expected code %v, received %v", tt.code, err)
+			}
+			if err == nil && (function != tt.expected || tt.used[function] != "green") {
+				t.Errorf("expected function %d of green, received %d in %v", tt.expected, function, tt.used)
+			}
+		})
+	}
+}
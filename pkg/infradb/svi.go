@@ -6,15 +6,12 @@
 package infradb
 
 import (
-	"encoding/binary"
-	//	"fmt"
 	"errors"
+	"fmt"
 
 	"log"
 	"net"
 
-	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
-	opinetcommon "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
 )
@@ -45,9 +42,25 @@ type SviSpec struct {
 	LogicalBridge string
 	MacAddress    *net.HardwareAddr
 	// TODO: This should be plural in Protobuf as well
+	// GatewayIPs are the addresses programmed on the SVI, the prefixes given by their network address
+	// being resolved by the gateway policy
 	GatewayIPs []*net.IPNet
-	EnableBgp  bool
-	RemoteAs   *uint32
+	// GatewayPrefixes are the gateway prefixes of the opi-api spec, as they were given
+	GatewayPrefixes []*net.IPNet
+	// GatewayPolicy is the policy which resolved the gateway addresses, empty when they were all given
+	GatewayPolicy string
+	EnableBgp     bool
+	RemoteAs      *uint32
+	// ProxyArp is not part of the opi-api spec, it is set with SetSviProxyArp
+	ProxyArp *ProxyArpSpec
+	// SecondaryIPs are the addresses programmed and advertised besides the gateway addresses, not part of the
+	// opi-api spec either, they are set with SetSviSecondaryIPs
+	SecondaryIPs []*net.IPNet
+}
+
+// IsUnnumbered tells whether the SVI is link-local only, without a subnet of its own
+func (in *SviSpec) IsUnnumbered() bool {
+	return len(in.GatewayIPs) == 0
 }
 
 // SviMetadata holds SVI Metadata
@@ -64,23 +77,11 @@ type Svi struct {
 }
 
 // build time check that struct implements interface
-var _ EvpnObject[*pb.Svi] = (*Svi)(nil)
+var _ EvpnObject = (*Svi)(nil)
 
-// NewSvi creates new SVI object from protobuf message
-func NewSvi(in *pb.Svi) (*Svi, error) {
+// NewSvi creates new SVI object from its spec
+func NewSvi(name string, spec *SviSpec) (*Svi, error) {
 	components := make([]common.Component, 0)
-	gwIPs := make([]*net.IPNet, 0)
-
-	// Tansform Mac From Byte to net.HardwareAddr type
-	macAddr := net.HardwareAddr(in.Spec.MacAddress)
-
-	// Parse Gateway IPs
-	for _, gwIPPrefix := range in.Spec.GwIpPrefix {
-		gatewayIP := make(net.IP, 4)
-		binary.BigEndian.PutUint32(gatewayIP, gwIPPrefix.Addr.GetV4Addr())
-		gwIP := net.IPNet{IP: gatewayIP, Mask: net.CIDRMask(int(gwIPPrefix.Len), 32)}
-		gwIPs = append(gwIPs, &gwIP)
-	}
 
 	subscribers := eventbus.EBus.GetSubscribers("svi")
 	if len(subscribers) == 0 {
@@ -93,16 +94,22 @@ func NewSvi(in *pb.Svi) (*Svi, error) {
 		components = append(components, component)
 	}
 
+	policy := GatewayPolicy()
+	gatewayIPs, err := resolveGateways(spec.GatewayIPs, policy)
+	if err != nil {
+		return &Svi{}, fmt.Errorf("NewSvi(): %w", err)
+	}
+	spec.GatewayPrefixes = spec.GatewayIPs
+	for i, gw := range gatewayIPs {
+		if gw != spec.GatewayPrefixes[i] {
+			spec.GatewayPolicy = policy
+		}
+	}
+	spec.GatewayIPs = gatewayIPs
+
 	return &Svi{
-		Name: in.Name,
-		Spec: &SviSpec{
-			Vrf:           in.Spec.Vrf,
-			LogicalBridge: in.Spec.LogicalBridge,
-			MacAddress:    &macAddr,
-			GatewayIPs:    gwIPs,
-			EnableBgp:     in.Spec.EnableBgp,
-			RemoteAs:      &in.Spec.RemoteAs,
-		},
+		Name: name,
+		Spec: spec,
 		Status: &SviStatus{
 			SviOperStatus: SviOperStatus(SviOperStatusDown),
 			Components:    components,
@@ -112,58 +119,6 @@ func NewSvi(in *pb.Svi) (*Svi, error) {
 	}, nil
 }
 
-// ToPb transforms Svi object to protobuf message
-func (in *Svi) ToPb() *pb.Svi {
-	gatewayIPs := make([]*opinetcommon.IPPrefix, 0)
-
-	for _, gwIP := range in.Spec.GatewayIPs {
-		gatewayIP := common.ConvertToIPPrefix(gwIP)
-		gatewayIPs = append(gatewayIPs, gatewayIP)
-	}
-
-	svi := &pb.Svi{
-		Name: in.Name,
-		Spec: &pb.SviSpec{
-			Vrf:           in.Spec.Vrf,
-			LogicalBridge: in.Spec.LogicalBridge,
-			MacAddress:    *in.Spec.MacAddress,
-			GwIpPrefix:    gatewayIPs,
-			EnableBgp:     in.Spec.EnableBgp,
-			RemoteAs:      *in.Spec.RemoteAs,
-		},
-		Status: &pb.SviStatus{},
-	}
-
-	switch in.Status.SviOperStatus {
-	case SviOperStatusDown:
-		svi.Status.OperStatus = pb.SVIOperStatus_SVI_OPER_STATUS_DOWN
-	case SviOperStatusUp:
-		svi.Status.OperStatus = pb.SVIOperStatus_SVI_OPER_STATUS_UP
-	case SviOperStatusToBeDeleted:
-		svi.Status.OperStatus = pb.SVIOperStatus_SVI_OPER_STATUS_TO_BE_DELETED
-	default:
-		svi.Status.OperStatus = pb.SVIOperStatus_SVI_OPER_STATUS_UNSPECIFIED
-	}
-
-	for _, comp := range in.Status.Components {
-		component := &pb.Component{Name: comp.Name, Details: comp.Details}
-
-		switch comp.CompStatus {
-		case common.ComponentStatusPending:
-			component.Status = pb.CompStatus_COMP_STATUS_PENDING
-		case common.ComponentStatusSuccess:
-			component.Status = pb.CompStatus_COMP_STATUS_SUCCESS
-		case common.ComponentStatusError:
-			component.Status = pb.CompStatus_COMP_STATUS_ERROR
-		default:
-			component.Status = pb.CompStatus_COMP_STATUS_UNSPECIFIED
-		}
-		svi.Status.Components = append(svi.Status.Components, component)
-	}
-
-	return svi
-}
-
 // GetName returns object unique name
 func (in *Svi) GetName() string {
 	return in.Name
@@ -217,6 +217,10 @@ func (t *TaskManager) processTasks() {
 					t.taskQueue.Enqueue(task)
 				})
 				break loopTwo
+			case common.ComponentStatusPending:
+				// The subscriber waits for a dependency of the object and creates the task again once it appears
+				log.Printf("processTasks(): Subscriber %+v has deferred the task %+v until its dependencies are met\n", sub, task)
+				break loopTwo
 			default:
 				log.Printf("processTasks(): Subscriber %+v has not provided designated status for the task %+v\n", sub, task)
 				log.Printf("processTasks(): The task %+v will be dropped\n", task)
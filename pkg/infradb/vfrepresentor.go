@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+var (
+	// ErrVfRepresentorInUse VF representor is still used by a bridge port
+	ErrVfRepresentorInUse = errors.New("the VF representor is still used by a bridge port")
+	// ErrVfInUse VF already has a VF representor
+	ErrVfInUse = errors.New("the VF already has a VF representor")
+)
+
+// maxVfIndex is the highest VF index of a PF allowed by the PCI SR-IOV capability
+const maxVfIndex = 65534
+
+// VfRepresentorSpec holds VF Representor Spec
+type VfRepresentorSpec struct {
+	// PF is the linux device of the physical function of the smart NIC, e.g. p0 or ens1f0
+	PF string
+	// VF is the index of the virtual function on the PF
+	VF int
+}
+
+// VfRepresentor holds VF Representor info. The representor netdev of the VF gets the
+// resource id as alternative name so that a Bridge Port with the same resource id
+// attaches the VF.
+type VfRepresentor struct {
+	Resource
+	Spec *VfRepresentorSpec
+}
+
+// LinkName returns the alternative name of the representor netdev
+func (in *VfRepresentor) LinkName() string {
+	return path.Base(in.Name)
+}
+
+// vfRepresentorKind describes the storage of the VF Representor objects
+var vfRepresentorKind = registerKind(resourceKind{
+	eventType: "vf-representor",
+	indexKey:  "vfrepresentors",
+	newObject: func() resourceObject { return &VfRepresentor{} },
+})
+
+// validate checks the VF Representor Spec
+func (in *VfRepresentorSpec) validate() error {
+	if in.PF == "" {
+		return fmt.Errorf("VF representor needs a PF")
+	}
+	if in.VF < 0 || in.VF > maxVfIndex {
+		return fmt.Errorf("VF representor VF index %d is out of range", in.VF)
+	}
+	return nil
+}
+
+// NewVfRepresentor creates new VF Representor object
+func NewVfRepresentor(name string, spec *VfRepresentorSpec) (*VfRepresentor, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewVfRepresentor(): VF Representor spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("NewVfRepresentor(): %v", err)
+	}
+
+	res, err := newResource(name, vfRepresentorKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VfRepresentor{Resource: res, Spec: spec}, nil
+}
+
+// getAllVfRepresentors returns all the VF representors, the caller must hold the global lock
+func getAllVfRepresentors() ([]*VfRepresentor, error) {
+	reps := []*VfRepresentor{}
+	names, err := vfRepresentorKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		rep := &VfRepresentor{}
+		if err := vfRepresentorKind.get(name, rep); err != nil {
+			log.Printf("getAllVfRepresentors(): Failed to get the VF Representor %s from store: %v", name, err)
+			return nil, err
+		}
+		reps = append(reps, rep)
+	}
+	return reps, nil
+}
+
+// CreateVfRepresentor creates an infradb VF representor object
+func CreateVfRepresentor(rep *VfRepresentor) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	reps, err := getAllVfRepresentors()
+	if err != nil {
+		return err
+	}
+	for _, existing := range reps {
+		if existing.Spec.PF == rep.Spec.PF && existing.Spec.VF == rep.Spec.VF {
+			log.Printf("CreateVfRepresentor(): VF %d of %s already has the VF Representor %s\n", rep.Spec.VF, rep.Spec.PF, existing.Name)
+			return ErrVfInUse
+		}
+	}
+
+	return vfRepresentorKind.create(rep)
+}
+
+// DeleteVfRepresentor deletes a VF representor infradb object
+func DeleteVfRepresentor(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	rep := &VfRepresentor{}
+	if err := vfRepresentorKind.get(name, rep); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, rep.ResourceVersion, opts); err != nil {
+		return err
+	}
+
+	bpsMap := make(map[string]bool)
+	if _, err := infradb.client.Get("bps", &bpsMap); err != nil {
+		log.Println(err)
+		return err
+	}
+	for bpName := range bpsMap {
+		if path.Base(bpName) == rep.LinkName() {
+			log.Printf("DeleteVfRepresentor(): VF Representor %s is still used by Bridge Port %s\n", name, bpName)
+			return ErrVfRepresentorInUse
+		}
+	}
+
+	return vfRepresentorKind.delete(rep)
+}
+
+// GetVfRepresentor returns an infradb VF representor object
+func GetVfRepresentor(name string) (*VfRepresentor, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	rep := &VfRepresentor{}
+	err := vfRepresentorKind.get(name, rep)
+	return rep, err
+}
+
+// GetVfRepresentorByLink returns the VF representor with the given alternative name,
+// ErrKeyNotFound when the Bridge Port of that name is not a VF
+func GetVfRepresentorByLink(linkName string) (*VfRepresentor, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	reps, err := getAllVfRepresentors()
+	if err != nil {
+		return nil, err
+	}
+	for _, rep := range reps {
+		if rep.LinkName() == linkName {
+			return rep, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// GetAllVfRepresentors returns a list of VF representors from the DB
+func GetAllVfRepresentors() ([]*VfRepresentor, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllVfRepresentors()
+}
+
+// UpdateVfRepresentorStatus updates the status of VF representor object based on the component report
+func UpdateVfRepresentorStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return vfRepresentorKind.updateStatus(&VfRepresentor{}, name, resourceVersion, notificationID, component)
+}
@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"path/filepath"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+var (
+	// ErrVirtualPortInUse virtual port is still used by a bridge port
+	ErrVirtualPortInUse = errors.New("the virtual port is still used by a bridge port")
+	// ErrVirtualPortSocketInUse socket is already used by another virtual port
+	ErrVirtualPortSocketInUse = errors.New("the socket is already used by another virtual port")
+)
+
+// Virtual port types, they select the backend of the userspace dataplane
+const (
+	// VhostUser serves a VM through a vhost-user socket
+	VhostUser = "vhost-user"
+	// VirtioUser attaches a virtio-user device, e.g. an exception path towards the kernel
+	VirtioUser = "virtio-user"
+)
+
+// maxVirtualPortQueues bounds the number of queue pairs of a virtio device
+const maxVirtualPortQueues = 256
+
+// VirtualPortSpec holds Virtual Port Spec
+type VirtualPortSpec struct {
+	// Type is either vhost-user or virtio-user
+	Type string
+	// SocketPath is the unix socket shared with the VM or the virtio-user device
+	SocketPath string
+	// Server makes the dataplane create the socket, the VM connects to it
+	Server bool
+	// Queues is the number of queue pairs, one when zero
+	Queues int
+}
+
+// VirtualPort holds Virtual Port info. The port carries the resource id of the virtual
+// port so that a Bridge Port with the same resource id attaches it through the
+// userspace dataplane instead of the kernel.
+type VirtualPort struct {
+	Resource
+	Spec *VirtualPortSpec
+}
+
+// LinkName returns the port name of the virtual port in the dataplane
+func (in *VirtualPort) LinkName() string {
+	return path.Base(in.Name)
+}
+
+// virtualPortKind describes the storage of the Virtual Port objects
+var virtualPortKind = registerKind(resourceKind{
+	eventType: "virtual-port",
+	indexKey:  "virtualports",
+	newObject: func() resourceObject { return &VirtualPort{} },
+})
+
+// validate checks the Virtual Port Spec
+func (in *VirtualPortSpec) validate() error {
+	switch in.Type {
+	case VhostUser, VirtioUser:
+	default:
+		return fmt.Errorf("Virtual port type %q is not supported", in.Type)
+	}
+	if !filepath.IsAbs(in.SocketPath) {
+		return fmt.Errorf("Virtual port socket path %q is not absolute", in.SocketPath)
+	}
+	if in.Queues == 0 {
+		in.Queues = 1
+	}
+	if in.Queues < 0 || in.Queues > maxVirtualPortQueues {
+		return fmt.Errorf("Virtual port queues %d is out of range", in.Queues)
+	}
+	return nil
+}
+
+// NewVirtualPort creates new Virtual Port object
+func NewVirtualPort(name string, spec *VirtualPortSpec) (*VirtualPort, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("NewVirtualPort(): Virtual Port spec cannot be empty")
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("NewVirtualPort(): %v", err)
+	}
+
+	res, err := newResource(name, virtualPortKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VirtualPort{Resource: res, Spec: spec}, nil
+}
+
+// getAllVirtualPorts returns all the virtual ports, the caller must hold the global lock
+func getAllVirtualPorts() ([]*VirtualPort, error) {
+	vports := []*VirtualPort{}
+	names, err := virtualPortKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		vport := &VirtualPort{}
+		if err := virtualPortKind.get(name, vport); err != nil {
+			log.Printf("getAllVirtualPorts(): Failed to get the Virtual Port %s from store: %v", name, err)
+			return nil, err
+		}
+		vports = append(vports, vport)
+	}
+	return vports, nil
+}
+
+// CreateVirtualPort creates an infradb virtual port object
+func CreateVirtualPort(vport *VirtualPort) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	vports, err := getAllVirtualPorts()
+	if err != nil {
+		return err
+	}
+	for _, existing := range vports {
+		if existing.Spec.SocketPath == vport.Spec.SocketPath {
+			log.Printf("CreateVirtualPort(): Socket %s is already used by %s\n", vport.Spec.SocketPath, existing.Name)
+			return ErrVirtualPortSocketInUse
+		}
+	}
+
+	return virtualPortKind.create(vport)
+}
+
+// DeleteVirtualPort deletes a virtual port infradb object
+func DeleteVirtualPort(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	vport := &VirtualPort{}
+	if err := virtualPortKind.get(name, vport); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, vport.ResourceVersion, opts); err != nil {
+		return err
+	}
+
+	bpsMap := make(map[string]bool)
+	if _, err := infradb.client.Get("bps", &bpsMap); err != nil {
+		log.Println(err)
+		return err
+	}
+	for bpName := range bpsMap {
+		if path.Base(bpName) == vport.LinkName() {
+			log.Printf("DeleteVirtualPort(): Virtual Port %s is still used by Bridge Port %s\n", name, bpName)
+			return ErrVirtualPortInUse
+		}
+	}
+
+	return virtualPortKind.delete(vport)
+}
+
+// GetVirtualPort returns an infradb virtual port object
+func GetVirtualPort(name string) (*VirtualPort, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	vport := &VirtualPort{}
+	err := virtualPortKind.get(name, vport)
+	return vport, err
+}
+
+// GetVirtualPortByLink returns the virtual port with the given port name, ErrKeyNotFound
+// when the Bridge Port of that name is a kernel device
+func GetVirtualPortByLink(linkName string) (*VirtualPort, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	vports, err := getAllVirtualPorts()
+	if err != nil {
+		return nil, err
+	}
+	for _, vport := range vports {
+		if vport.LinkName() == linkName {
+			return vport, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// GetAllVirtualPorts returns a list of virtual ports from the DB
+func GetAllVirtualPorts() ([]*VirtualPort, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllVirtualPorts()
+}
+
+// UpdateVirtualPortStatus updates the status of virtual port object based on the component report
+func UpdateVirtualPortStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return virtualPortKind.updateStatus(&VirtualPort{}, name, resourceVersion, notificationID, component)
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"log"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// vlansKey is the key of the table mapping the local VLAN IDs of the VLAN-aware bridge to the Logical Bridges which use them
+var vlansKey = registerStoreKey("vlans")
+
+// maxVlanID is the highest usable VLAN ID, 4095 is reserved by 802.1Q
+const maxVlanID = 4094
+
+// ErrVlanPoolExhausted every VLAN ID of the pool is in use
+var ErrVlanPoolExhausted = apierrors.Exhausted(apierrors.ReasonExhausted, 0, "no VLAN ID is left in the VLAN pool")
+
+// vlanPoolRange returns the configured range of the VLAN pool clamped to the usable VLAN IDs
+func vlanPoolRange() (uint32, uint32) {
+	pool := config.GlobalConfig.VlanPool
+	first, last := pool.Min, pool.Max
+	if first < 1 {
+		first = 1
+	}
+	if last == 0 || last > maxVlanID {
+		last = maxVlanID
+	}
+	return first, last
+}
+
+// loadVlans reads the table of the used VLAN IDs, the caller holds the global lock
+func loadVlans() (map[uint32]string, error) {
+	vlans := make(map[uint32]string)
+	if _, err := infradb.client.Get(vlansKey, &vlans); err != nil {
+		return nil, err
+	}
+	return vlans, nil
+}
+
+// claimVlan gives the VLAN ID to the Logical Bridge in the table, a zero VLAN ID is replaced by the
+// lowest free one of the pool. An explicit VLAN ID may be outside of the pool but not used by another bridge.
+func claimVlan(vlans map[uint32]string, name string, vlanID *uint32) error {
+	if *vlanID == 0 {
+		first, last := vlanPoolRange()
+		for vlan := first; vlan <= last; vlan++ {
+			if _, used := vlans[vlan]; !used {
+				log.Printf("claimVlan(): allocated VLAN ID %d to %s\n", vlan, name)
+				*vlanID = vlan
+				break
+			}
+		}
+		if *vlanID == 0 {
+			return ErrVlanPoolExhausted
+		}
+	} else if owner, used := vlans[*vlanID]; used && owner != name {
+		return status.Errorf(codes.AlreadyExists, "the VLAN ID %d is already used by %s", *vlanID, owner)
+	}
+	vlans[*vlanID] = name
+	return nil
+}
+
+// releaseVlan frees the VLAN ID of the Logical Bridge in the table
+func releaseVlan(vlans map[uint32]string, name string, vlanID uint32) {
+	if vlans[vlanID] == name {
+		delete(vlans, vlanID)
+	}
+}
+
+// migrateVlans builds the table of the VLAN IDs used by the stored Logical Bridges. The bridges sharing
+// a VLAN ID are kept, the first one in the order of the names holds it in the table.
+func migrateVlans() error {
+	names, err := storedNames("lbs")
+	if err != nil {
+		return err
+	}
+	vlans := make(map[uint32]string)
+	for _, name := range names {
+		lb := LogicalBridge{}
+		found, err := infradb.client.Get(name, &lb)
+		if err != nil {
+			return err
+		}
+		if !found || lb.Spec == nil {
+			continue
+		}
+		if owner, used := vlans[lb.Spec.VlanID]; used {
+			log.Printf("migrateVlans(): %s shares the VLAN ID %d with %s\n", name, lb.Spec.VlanID, owner)
+			continue
+		}
+		vlans[lb.Spec.VlanID] = name
+	}
+	return infradb.client.Set(vlansKey, vlans)
+}
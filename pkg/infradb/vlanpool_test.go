@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_ClaimVlan(t *testing.T) {
+	config.GlobalConfig.VlanPool = config.VlanPoolConfig{Min: 10, Max: 11}
+	t.Cleanup(func() { config.GlobalConfig.VlanPool = config.VlanPoolConfig{} })
+
+	tests := map[string]struct {
+		vlans    map[uint32]string
+		vlanID   uint32
+		expected uint32
+		code     codes.Code
+	}{
+		"allocated from the pool": {
+			vlans:    map[uint32]string{10: "blue"},
+			expected: 11,
+		},
+		"explicit outside of the pool": {
+			vlans:    map[uint32]string{10: "blue"},
+			vlanID:   100,
+			expected: 100,
+		},
+		"explicit already used": {
+			vlans:  map[uint32]string{100: "blue"},
+			vlanID: 100,
+			code:   codes.AlreadyExists,
+		},
+		"explicit used by the same bridge": {
+			vlans:    map[uint32]string{100: "green"},
+			vlanID:   100,
+			expected: 100,
+		},
+		"pool exhausted": {
+			vlans: map[uint32]string{10: "blue", 11: "red"},
+			code:  codes.ResourceExhausted,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			vlanID := tt.vlanID
+			err := claimVlan(tt.vlans, "green", &vlanID)
+			if status.Code(err) != tt.code {
+				t.Fatalf("expected %v, got %v", tt.code, err)
+			}
+			if err != nil {
+				return
+			}
+			if vlanID != tt.expected || tt.vlans[vlanID] != "green" {
+				t.Errorf("expected the VLAN ID %d to be claimed, got %d in %v", tt.expected, vlanID, tt.vlans)
+			}
+			releaseVlan(tt.vlans, "green", vlanID)
+			if _, used := tt.vlans[vlanID]; used {
+				t.Errorf("expected the VLAN ID %d to be released", vlanID)
+			}
+		})
+	}
+}
+
+func Test_MigrateVlans(t *testing.T) {
+	if err := NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	blue := newTestLB("blue", 1)
+	blue.Spec.VlanID = 20
+	if err := infradb.client.Set("lbs", map[string]bool{blue.Name: false}); err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.client.Set(blue.Name, blue); err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.client.Set(schemaVersionKey, &schemaVersion{Version: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Migrate(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	vlans, err := loadVlans()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vlans[20] != blue.Name {
+		t.Errorf("expected the VLAN ID 20 to be used by %s, got %v", blue.Name, vlans)
+	}
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"log"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+// maxVni is the highest VNI of the 24 bits of the vxlan header
+const maxVni = 16777215
+
+var (
+	// ErrVniPoolNotConfigured a VNI has to be allocated but no VNI pool is configured
+	ErrVniPoolNotConfigured = apierrors.InvalidField("spec.vni", apierrors.ReasonNotConfigured, "the VNI is zero and no VNI pool is configured")
+	// ErrVniPoolExhausted every VNI of the pool is in use
+	ErrVniPoolExhausted = apierrors.Exhausted(apierrors.ReasonExhausted, 0, "no VNI is left in the VNI pool")
+)
+
+// vniPoolRange returns the configured range of the VNI pool clamped to the valid VNIs
+func vniPoolRange() (uint32, uint32, bool) {
+	pool := config.GlobalConfig.VniPool
+	first, last := pool.Min, pool.Max
+	if first < 1 {
+		first = 1
+	}
+	if last > maxVni {
+		last = maxVni
+	}
+	return first, last, pool.Max != 0 && first <= last
+}
+
+// allocateVni returns the lowest VNI of the pool which is not used by a Logical Bridge or a VRF,
+// the caller holds the global lock and reserves the VNI in the "vpns" map along with the object
+func allocateVni() (uint32, error) {
+	first, last, ok := vniPoolRange()
+	if !ok {
+		return 0, ErrVniPoolNotConfigured
+	}
+
+	vpns := make(map[uint32]bool)
+	if _, err := infradb.client.Get("vpns", &vpns); err != nil {
+		return 0, err
+	}
+	for vni := first; vni <= last; vni++ {
+		if _, used := vpns[vni]; !used {
+			log.Printf("allocateVni(): allocated VNI %d\n", vni)
+			return vni, nil
+		}
+	}
+	return 0, ErrVniPoolExhausted
+}
+
+// resolveVni replaces the zero VNI of a spec with one allocated from the pool, a new pointer is
+// set because the spec may share it with the request
+func resolveVni(vni **uint32) error {
+	if *vni == nil || **vni != 0 {
+		return nil
+	}
+	allocated, err := allocateVni()
+	if err != nil {
+		return err
+	}
+	*vni = &allocated
+	return nil
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestLB(name string, vni uint32) *LogicalBridge {
+	return &LogicalBridge{
+		Name:            "//network.opiproject.org/bridges/" + name,
+		Spec:            &LogicalBridgeSpec{Vni: &vni},
+		Status:          &LogicalBridgeStatus{},
+		Metadata:        &LogicalBridgeMetadata{},
+		BridgePorts:     make(map[string]bool),
+		MacTable:        make(map[string]string),
+		ResourceVersion: generateVersion(),
+	}
+}
+
+func Test_AllocateVni(t *testing.T) {
+	eventbus.EBus.StartSubscriber("dummy", "logical-bridge", 1, nil)
+	if err := NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { config.GlobalConfig.VniPool = config.VniPoolConfig{} })
+
+	if err := CreateLB(newTestLB("nopool", 0)); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument without a pool, got %v", err)
+	}
+
+	config.GlobalConfig.VniPool = config.VniPoolConfig{Min: 100, Max: 102}
+	if err := CreateLB(newTestLB("static", 100)); err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []uint32{101, 102} {
+		lb := newTestLB(fmt.Sprintf("auto%d", i), 0)
+		if err := CreateLB(lb); err != nil {
+			t.Fatal(err)
+		}
+		// the allocated VNI is persisted with the object
+		stored, err := GetLB(lb.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *stored.Spec.Vni != expected {
+			t.Errorf("expected the VNI %d, got %d", expected, *stored.Spec.Vni)
+		}
+	}
+
+	if err := CreateLB(newTestLB("exhausted", 0)); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected ResourceExhausted once the pool is used, got %v", err)
+	}
+}
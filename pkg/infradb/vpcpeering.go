@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"path"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+var (
+	// ErrVpcPeeringSameVrf vpc peering connects a VRF to itself
+	ErrVpcPeeringSameVrf = errors.New("the VPC peering connects a VRF to itself")
+	// ErrVpcPeeringExists the VRFs are already peered
+	ErrVpcPeeringExists = errors.New("the VRFs are already peered")
+)
+
+// VpcPeeringSpec holds VPC Peering Spec
+type VpcPeeringSpec struct {
+	VrfA string
+	VrfB string
+	// PrefixesA are the prefixes of VrfA which VrfB reaches, all of them when empty
+	PrefixesA []*net.IPNet
+	// PrefixesB are the prefixes of VrfB which VrfA reaches, all of them when empty
+	PrefixesB []*net.IPNet
+}
+
+// PeeringDirection is one way of a VPC peering: Dst reaches the prefixes of Src
+type PeeringDirection struct {
+	Src      string
+	Dst      string
+	Prefixes []*net.IPNet
+}
+
+// Directions returns both ways of the VPC peering
+func (in *VpcPeeringSpec) Directions() []PeeringDirection {
+	return []PeeringDirection{
+		{Src: in.VrfA, Dst: in.VrfB, Prefixes: in.PrefixesA},
+		{Src: in.VrfB, Dst: in.VrfA, Prefixes: in.PrefixesB},
+	}
+}
+
+// VpcPeering holds VPC Peering info
+type VpcPeering struct {
+	Resource
+	Spec *VpcPeeringSpec
+}
+
+// vpcPeeringKind describes the storage of the VPC Peering objects
+var vpcPeeringKind = registerKind(resourceKind{
+	eventType: "vpc-peering",
+	indexKey:  "vpcpeerings",
+	newObject: func() resourceObject { return &VpcPeering{} },
+	references: func(obj resourceObject) []string {
+		vp := obj.(*VpcPeering)
+		return []string{vp.Spec.VrfA, vp.Spec.VrfB}
+	},
+})
+
+// NewVpcPeering creates new VPC Peering object
+func NewVpcPeering(name string, spec *VpcPeeringSpec) (*VpcPeering, error) {
+	if spec == nil || spec.VrfA == "" || spec.VrfB == "" {
+		return nil, fmt.Errorf("NewVpcPeering(): VPC Peering needs two VRFs")
+	}
+	if spec.VrfA == spec.VrfB {
+		return nil, ErrVpcPeeringSameVrf
+	}
+	// the GRD has no vrf device for the kernel routes to point to
+	if path.Base(spec.VrfA) == "GRD" || path.Base(spec.VrfB) == "GRD" {
+		return nil, fmt.Errorf("NewVpcPeering(): VPC Peering connects tenant VRFs, not the GRD")
+	}
+
+	res, err := newResource(name, vpcPeeringKind.eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VpcPeering{Resource: res, Spec: spec}, nil
+}
+
+// getAllVpcPeerings returns all the vpc peerings, the caller must hold the global lock
+func getAllVpcPeerings() ([]*VpcPeering, error) {
+	vps := []*VpcPeering{}
+	names, err := vpcPeeringKind.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		vp := &VpcPeering{}
+		if err := vpcPeeringKind.get(name, vp); err != nil {
+			log.Printf("getAllVpcPeerings(): Failed to get the VPC Peering %s from store: %v", name, err)
+			return nil, err
+		}
+		vps = append(vps, vp)
+	}
+	return vps, nil
+}
+
+// CreateVpcPeering creates an infradb vpc peering object
+func CreateVpcPeering(vp *VpcPeering) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	for _, vrfName := range []string{vp.Spec.VrfA, vp.Spec.VrfB} {
+		if err := checkVrfExists(vrfName); err != nil {
+			return err
+		}
+	}
+
+	vps, err := getAllVpcPeerings()
+	if err != nil {
+		return err
+	}
+	for _, other := range vps {
+		if other.Status.OperStatus == OperStatusToBeDeleted {
+			continue
+		}
+		if (other.Spec.VrfA == vp.Spec.VrfA && other.Spec.VrfB == vp.Spec.VrfB) ||
+			(other.Spec.VrfA == vp.Spec.VrfB && other.Spec.VrfB == vp.Spec.VrfA) {
+			log.Printf("CreateVpcPeering(): VPC Peering %s rejected: the VRFs are peered by %s\n", vp.Name, other.Name)
+			return ErrVpcPeeringExists
+		}
+	}
+
+	return vpcPeeringKind.create(vp)
+}
+
+// DeleteVpcPeering deletes a vpc peering infradb object
+func DeleteVpcPeering(name string, opts ...WriteOption) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	vp := &VpcPeering{}
+	if err := vpcPeeringKind.get(name, vp); err != nil {
+		return err
+	}
+	if err := checkIfMatch(name, vp.ResourceVersion, opts); err != nil {
+		return err
+	}
+	return vpcPeeringKind.delete(vp)
+}
+
+// GetVpcPeering returns an infradb vpc peering object
+func GetVpcPeering(name string) (*VpcPeering, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	vp := &VpcPeering{}
+	err := vpcPeeringKind.get(name, vp)
+	return vp, err
+}
+
+// GetAllVpcPeerings returns a list of vpc peerings from the DB
+func GetAllVpcPeerings() ([]*VpcPeering, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	return getAllVpcPeerings()
+}
+
+// UpdateVpcPeeringStatus updates the status of vpc peering object based on the component report
+func UpdateVpcPeeringStatus(name string, resourceVersion string, notificationID string, component common.Component) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	return vpcPeeringKind.updateStatus(&VpcPeering{}, name, resourceVersion, notificationID, component)
+}
@@ -6,14 +6,11 @@
 package infradb
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"log"
 	"net"
 
-	// "time"
-	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
 	"github.com/opiproject/opi-evpn-bridge/pkg/config"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
@@ -45,6 +42,13 @@ type VrfSpec struct {
 	Vni        *uint32
 	LoopbackIP *net.IPNet
 	VtepIP     *net.IPNet
+	// Mpls carries the VPC over the MPLS core instead of VXLAN when it is set
+	Mpls *MplsSpec
+	// Srv6 carries the VPC over the SRv6 fabric instead of VXLAN when it is set
+	Srv6 *Srv6Spec
+	// Netns is the named network namespace of the devices of the vrf and its svis, the one of the
+	// bridge when it is empty
+	Netns string
 }
 
 // VrfMetadata holds VRF Metadata
@@ -66,7 +70,7 @@ type Vrf struct {
 }
 
 // build time check that struct implements interface
-var _ EvpnObject[*pb.Vrf] = (*Vrf)(nil)
+var _ EvpnObject = (*Vrf)(nil)
 
 // NewVrfWithArgs creates a vrf object by passing arguments
 func NewVrfWithArgs(name string, vni *uint32, loopbackIP, vtepIP *net.IPNet) (*Vrf, error) {
@@ -121,23 +125,13 @@ func NewVrfWithArgs(name string, vni *uint32, loopbackIP, vtepIP *net.IPNet) (*V
 	return vrf, nil
 }
 
-// NewVrf creates new VRF object from protobuf message
-func NewVrf(in *pb.Vrf) (*Vrf, error) {
-	var vip *net.IPNet
+// NewVrf creates new VRF object from its spec, the VTEP IP defaults to the one of the config
+func NewVrf(name string, spec *VrfSpec) (*Vrf, error) {
 	components := make([]common.Component, 0)
 
-	loopip := make(net.IP, 4)
-	binary.BigEndian.PutUint32(loopip, in.Spec.LoopbackIpPrefix.Addr.GetV4Addr())
-	lip := net.IPNet{IP: loopip, Mask: net.CIDRMask(int(in.Spec.LoopbackIpPrefix.Len), 32)}
-
-	// Parse vtep IP
-	if in.Spec.VtepIpPrefix != nil {
-		vtepip := make(net.IP, 4)
-		binary.BigEndian.PutUint32(vtepip, in.Spec.VtepIpPrefix.Addr.GetV4Addr())
-		vip = &net.IPNet{IP: vtepip, Mask: net.CIDRMask(int(in.Spec.VtepIpPrefix.Len), 32)}
-	} else {
+	if spec.VtepIP == nil {
 		tmpVtepIP := utils.GetIPAddress(config.GlobalConfig.LinuxFrr.DefaultVtep)
-		vip = &tmpVtepIP
+		spec.VtepIP = &tmpVtepIP
 	}
 
 	subscribers := eventbus.EBus.GetSubscribers("vrf")
@@ -152,12 +146,8 @@ func NewVrf(in *pb.Vrf) (*Vrf, error) {
 	}
 
 	return &Vrf{
-		Name: in.Name,
-		Spec: &VrfSpec{
-			Vni:        in.Spec.Vni,
-			LoopbackIP: &lip,
-			VtepIP:     vip,
-		},
+		Name: name,
+		Spec: spec,
 		Status: &VrfStatus{
 			VrfOperStatus: VrfOperStatus(VrfOperStatusDown),
 
@@ -169,50 +159,6 @@ func NewVrf(in *pb.Vrf) (*Vrf, error) {
 	}, nil
 }
 
-// ToPb transforms VRF object to protobuf message
-func (in *Vrf) ToPb() *pb.Vrf {
-	loopbackIP := common.ConvertToIPPrefix(in.Spec.LoopbackIP)
-	vtepip := common.ConvertToIPPrefix(in.Spec.VtepIP)
-	vrf := &pb.Vrf{
-		Name: in.Name,
-		Spec: &pb.VrfSpec{
-			Vni:              in.Spec.Vni,
-			LoopbackIpPrefix: loopbackIP,
-
-			VtepIpPrefix: vtepip,
-		},
-		Status: &pb.VrfStatus{},
-	}
-
-	switch in.Status.VrfOperStatus {
-	case VrfOperStatusDown:
-		vrf.Status.OperStatus = pb.VRFOperStatus_VRF_OPER_STATUS_DOWN
-	case VrfOperStatusUp:
-		vrf.Status.OperStatus = pb.VRFOperStatus_VRF_OPER_STATUS_UP
-	case VrfOperStatusToBeDeleted:
-		vrf.Status.OperStatus = pb.VRFOperStatus_VRF_OPER_STATUS_TO_BE_DELETED
-	default:
-		vrf.Status.OperStatus = pb.VRFOperStatus_VRF_OPER_STATUS_UNSPECIFIED
-	}
-
-	for _, comp := range in.Status.Components {
-		component := &pb.Component{Name: comp.Name, Details: comp.Details}
-
-		switch comp.CompStatus {
-		case common.ComponentStatusPending:
-			component.Status = pb.CompStatus_COMP_STATUS_PENDING
-		case common.ComponentStatusSuccess:
-			component.Status = pb.CompStatus_COMP_STATUS_SUCCESS
-		case common.ComponentStatusError:
-			component.Status = pb.CompStatus_COMP_STATUS_ERROR
-		default:
-			component.Status = pb.CompStatus_COMP_STATUS_UNSPECIFIED
-		}
-		vrf.Status.Components = append(vrf.Status.Components, component)
-	}
-	return vrf
-}
-
 // AddSvi adds a reference of SVI to the VRF object
 func (in *Vrf) AddSvi(sviName string) error {
 	_, ok := in.Svis[sviName]
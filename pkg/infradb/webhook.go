@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package infradb
+
+import (
+	"log"
+	"net/url"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// webhooksKey is the key of the DB map holding the webhooks by name
+var webhooksKey = registerStoreKey("webhooks")
+
+// Webhook is an http(s) endpoint receiving the status events as json posts
+type Webhook struct {
+	Name string
+	URL  string
+	// Secret signs the body of the posts with HMAC-SHA256, they are not signed when empty
+	Secret string
+	// Types filters the events sent, the status transitions when empty, Kinds the kinds of objects, all of them when empty
+	Types []StatusEventType
+	Kinds []string
+}
+
+// Matches tells whether the event is sent to the webhook
+func (w *Webhook) Matches(event StatusEvent) bool {
+	if len(w.Types) == 0 && !event.Type.IsStatus() || len(w.Types) != 0 && !contains(w.Types, event.Type) {
+		return false
+	}
+	return len(w.Kinds) == 0 || contains(w.Kinds, event.Kind)
+}
+
+// contains tells whether the value is in the list
+func contains[T comparable](list []T, value T) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// loadWebhooks returns the webhooks by name, the caller must hold the global lock
+func loadWebhooks() (map[string]*Webhook, error) {
+	webhooks := make(map[string]*Webhook)
+	if _, err := infradb.client.Get(webhooksKey, &webhooks); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// SetWebhook registers the webhook, it replaces the webhook of the same name
+func SetWebhook(w *Webhook) error {
+	if w.Name == "" {
+		return status.Error(codes.InvalidArgument, "the webhook has no name")
+	}
+	if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return status.Errorf(codes.InvalidArgument, "the webhook url %q is not an http(s) url", w.URL)
+	}
+	for _, t := range w.Types {
+		if !t.IsValid() {
+			return status.Errorf(codes.InvalidArgument, "unknown event type %q", t)
+		}
+	}
+
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	webhooks, err := loadWebhooks()
+	if err != nil {
+		return err
+	}
+	webhooks[w.Name] = w
+	return infradb.client.Set(webhooksKey, webhooks)
+}
+
+// GetWebhook returns the webhook of the name
+func GetWebhook(name string) (*Webhook, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	webhooks, err := loadWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	w, ok := webhooks[name]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return w, nil
+}
+
+// GetAllWebhooks returns the webhooks in the order of their names
+func GetAllWebhooks() ([]*Webhook, error) {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+
+	webhooks, err := loadWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		out = append(out, w)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// DeleteWebhook unregisters the webhook
+func DeleteWebhook(name string) error {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+
+	webhooks, err := loadWebhooks()
+	if err != nil {
+		return err
+	}
+	if _, ok := webhooks[name]; !ok {
+		return ErrKeyNotFound
+	}
+	delete(webhooks, name)
+	return infradb.client.Set(webhooksKey, webhooks)
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package interceptor assembles the chain of gRPC interceptors of the bridge
+package interceptor
+
+import (
+	"context"
+	"log"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+const (
+	// AnalyzeOnlyHeader is the metadata of a Delete or Update call which only reports what the call would affect
+	AnalyzeOnlyHeader = "opi-analyze-only"
+	// ImpactHeader is the response header listing the affected resources and kernel devices, "<kind> <name>" each
+	ImpactHeader = "opi-impact"
+)
+
+// DependentsFunc returns the object with everything which depends on it
+type DependentsFunc func(name string) (*infradb.DependencyGraph, error)
+
+// Analyze does not run the Delete and Update calls carrying the opi-analyze-only header, it returns in the
+// opi-impact header the object and everything depending on it, resources and kernel devices, which the call
+// would tear down or reprogram. The Delete calls return an empty response, the Update calls the object of
+// the request as it was sent.
+func Analyze() grpc.UnaryServerInterceptor {
+	return analyze(infradb.GetDependents)
+}
+
+func analyze(dependents DependentsFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := path.Base(info.FullMethod)
+		values := metadata.ValueFromIncomingContext(ctx, AnalyzeOnlyHeader)
+		if len(values) == 0 || (!strings.HasPrefix(method, "Delete") && !strings.HasPrefix(method, "Update")) {
+			return handler(ctx, req)
+		}
+		if values[0] != "true" {
+			if values[0] == "false" {
+				return handler(ctx, req)
+			}
+			return nil, status.Errorf(codes.InvalidArgument, "invalid %s %q", AnalyzeOnlyHeader, values[0])
+		}
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+		name := utils.ObjectName(msg)
+		if name == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "%s has no object name to analyze", method)
+		}
+		graph, err := dependents(name)
+		if err != nil {
+			log.Printf("%s(): Failed to analyze the impact on %s: %v", method, name, err)
+			return nil, err
+		}
+		impact := metadata.MD{}
+		for _, n := range graph.Nodes {
+			impact.Append(ImpactHeader, n.Kind+" "+n.ID)
+		}
+		if err := grpc.SetHeader(ctx, impact); err != nil {
+			log.Printf("%s(): Failed to report the impact on %s: %v", method, name, err)
+		}
+		if strings.HasPrefix(method, "Delete") {
+			return &emptypb.Empty{}, nil
+		}
+		return requestObject(msg), nil
+	}
+}
+
+// requestObject returns the object carried by an Update request
+func requestObject(msg proto.Message) proto.Message {
+	m := msg.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.Message() == nil || field.IsList() || field.IsMap() || !m.Has(field) {
+			continue
+		}
+		obj := m.Get(field).Message()
+		if obj.Descriptor().Fields().ByName("name") != nil {
+			return obj.Interface()
+		}
+	}
+	return msg
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package interceptor assembles the chain of gRPC interceptors of the bridge
+package interceptor
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Auth rejects with Unauthenticated the calls whose "authorization: bearer <token>" header does not carry one
+// of the tokens, which are read at every call so that a reloaded config applies to the next calls. The health
+// checks are left open for the probes of the orchestrators.
+func Auth(tokens func() []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+			return handler(ctx, req)
+		}
+		token, err := auth.AuthFromMD(ctx, "bearer")
+		if err != nil {
+			return nil, err
+		}
+		for _, valid := range tokens() {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(valid)) == 1 {
+				return handler(ctx, req)
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid auth token")
+	}
+}
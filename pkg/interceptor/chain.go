@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package interceptor assembles the chain of gRPC interceptors of the bridge
+package interceptor
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"google.golang.org/grpc"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/placement"
+	"github.com/opiproject/opi-evpn-bridge/pkg/tenant"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// DefaultChain is the chain used when the config names no interceptor. Recovery comes first so that
+// it also catches the panics of the other interceptors, readonly comes after analyze which runs no call, errors
+// comes last so that the others log and count the status codes of the store errors, auth and validation are opt-in.
+var DefaultChain = []string{"recovery", "logging", "metrics", "deadline", "tenant", "placement", "etag", "analyze", "readonly", "confirm", "history", "lease", "errors"}
+
+// interceptors builds the interceptors by name
+var interceptors = map[string]func() grpc.UnaryServerInterceptor{
+	"recovery": Recovery,
+	"errors":   Errors,
+	"logging": func() grpc.UnaryServerInterceptor {
+		return logging.UnaryServerInterceptor(utils.InterceptorLogger(log.Default(),
+			func() string { return config.GlobalConfig.LogLevel.Grpc }),
+			logging.WithLogOnEvents(
+				logging.StartCall,
+				logging.FinishCall,
+				logging.PayloadReceived,
+				logging.PayloadSent,
+			),
+		)
+	},
+	"metrics": Metrics,
+	"auth": func() grpc.UnaryServerInterceptor {
+		return Auth(func() []string { return config.GlobalConfig.Interceptors.AuthTokens })
+	},
+	"validation": Validation,
+	"deadline": func() grpc.UnaryServerInterceptor {
+		return utils.DeadlineInterceptor(utils.MethodDeadlines(
+			func() map[string]int { return config.GlobalConfig.Deadlines.Methods },
+			func() int { return config.GlobalConfig.Deadlines.Default },
+		))
+	},
+	"tenant":    tenant.UnaryServerInterceptor,
+	"placement": placement.UnaryServerInterceptor,
+	"lease":     Lease,
+	"analyze":   Analyze,
+	"readonly":  ReadOnly,
+	"confirm":   Confirm,
+	"history":   History,
+	"etag": func() grpc.UnaryServerInterceptor {
+		return utils.ETagInterceptor(infradb.GetResourceVersion, config.GlobalConfig.RequireETag)
+	},
+}
+
+// Chain returns the named interceptors in the order in which they wrap the calls,
+// the default chain when no name is given
+func Chain(names []string) ([]grpc.UnaryServerInterceptor, error) {
+	if len(names) == 0 {
+		names = DefaultChain
+	}
+	chain := make([]grpc.UnaryServerInterceptor, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		newInterceptor, ok := interceptors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown interceptor %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("interceptor %q is listed twice", name)
+		}
+		seen[name] = true
+		chain = append(chain, newInterceptor())
+	}
+	return chain, nil
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package interceptor assembles the chain of gRPC interceptors of the bridge
+package interceptor
+
+import (
+	"context"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"go.einride.tech/aip/resourceid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apply"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/schedule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+const (
+	// ConfirmTimeoutHeader is the metadata of an Update call which is reverted unless it is confirmed in time, e.g. "5m"
+	ConfirmTimeoutHeader = "opi-confirm-timeout"
+	// ChangeIDHeader is the response header naming the change to confirm
+	ChangeIDHeader = "opi-change-id"
+)
+
+// Confirm reverts the Update calls carrying the opi-confirm-timeout header unless the change named by the
+// opi-change-id response header is confirmed through the admin API within the timeout, e.g. when the update
+// has cut the connectivity of the client itself. The revert is stored before the update runs, so that an
+// update is never left without its revert, and it is dropped when the update fails. An update creating the
+// missing object with allow_missing is reverted by deleting the object.
+func Confirm() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := path.Base(info.FullMethod)
+		values := metadata.ValueFromIncomingContext(ctx, ConfirmTimeoutHeader)
+		if len(values) == 0 || !strings.HasPrefix(method, "Update") {
+			return handler(ctx, req)
+		}
+		timeout, err := time.ParseDuration(values[0])
+		if err != nil || timeout <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid %s %q", ConfirmTimeoutHeader, values[0])
+		}
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+		name := utils.ObjectName(msg)
+		kind := strings.TrimPrefix(method, "Update")
+		// the version is read before the object so that the update aborts when the object changes in between
+		version, err := infradb.GetResourceVersion(name)
+		if err != nil {
+			return nil, err
+		}
+		snapshot, err := apply.CaptureUpdate(ctx, info.Server, kind, name)
+		if status.Code(err) == codes.NotFound {
+			if !allowMissing(msg) {
+				// the update fails on its own
+				return handler(ctx, req)
+			}
+			snapshot, err = apply.CaptureCreate(kind, name)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%s cannot be confirmed: %v", method, err)
+		}
+		change, err := schedule.Track(resourceid.NewSystemGenerated(), snapshot, timeout)
+		if err != nil {
+			log.Printf("%s(): Failed to track the update of %s: %v", method, name, err)
+			return nil, err
+		}
+		if version != "" && utils.IfMatch(ctx) == "" {
+			// the revert restores the object as captured, the update must not apply on top of another change
+			md, _ := metadata.FromIncomingContext(ctx)
+			ctx = metadata.NewIncomingContext(ctx, metadata.Join(md, metadata.Pairs(utils.IfMatchHeader, version)))
+		}
+		resp, err := handler(ctx, req)
+		if err != nil {
+			if uerr := schedule.Untrack(change.Name); uerr != nil {
+				log.Printf("%s(): Failed to drop the revert %s of the failed update of %s: %v", method, change.Name, name, uerr)
+			}
+			return resp, err
+		}
+		if err := grpc.SetHeader(ctx, metadata.Pairs(ChangeIDHeader, change.Name)); err != nil {
+			log.Printf("%s(): Failed to report the change %s: %v", method, change.Name, err)
+		}
+		return resp, nil
+	}
+}
+
+// allowMissing tells whether the update request creates the object when it is missing
+func allowMissing(msg proto.Message) bool {
+	m := msg.ProtoReflect()
+	field := m.Descriptor().Fields().ByName("allow_missing")
+	return field != nil && field.Kind() == protoreflect.BoolKind && m.Get(field).Bool()
+}
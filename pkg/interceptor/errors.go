@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package interceptor assembles the chain of gRPC interceptors of the bridge
+package interceptor
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+)
+
+// Errors returns the errors of the store, e.g. a VRF which is not empty, with their status code and
+// ErrorInfo instead of Unknown. The other errors are left as they are.
+func Errors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			if st, ok := apierrors.Lookup(err); ok {
+				return resp, st.Err()
+			}
+		}
+		return resp, err
+	}
+}
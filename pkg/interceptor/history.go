@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package interceptor assembles the chain of gRPC interceptors of the bridge
+package interceptor
+
+import (
+	"context"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apply"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// AddRevisionFunc adds a revision to the history of the resources
+type AddRevisionFunc func(rev *infradb.Revision) (*infradb.Revision, error)
+
+// History keeps a revision of the resources changed by the Create, Update and Delete calls which succeed,
+// the resource as it is after the call, so that the resource or the whole config can be rolled back to it
+func History() grpc.UnaryServerInterceptor {
+	return history(infradb.AddRevision)
+}
+
+func history(addRevision AddRevisionFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := path.Base(info.FullMethod)
+		var action apply.Action
+		for _, a := range []apply.Action{apply.ActionCreate, apply.ActionUpdate, apply.ActionDelete} {
+			// e.g. CreateVrf for the create action
+			if strings.HasPrefix(method, strings.ToUpper(string(a[:1]))+string(a[1:])) {
+				action = a
+			}
+		}
+		kind := method[min(len(method), len(action)):]
+		if action == "" || !apply.IsKind(kind) {
+			return handler(ctx, req)
+		}
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		rev := &infradb.Revision{Kind: kind, Action: string(action), Time: time.Now()}
+		if action == apply.ActionDelete {
+			if msg, ok := req.(proto.Message); ok {
+				rev.Name = utils.ObjectName(msg)
+			}
+		} else if obj, ok := resp.(proto.Message); ok {
+			rev.Name = utils.ObjectName(obj)
+			if rev.Object, err = protojson.Marshal(obj); err != nil {
+				log.Printf("%s(): Failed to keep the revision of %s: %v", method, rev.Name, err)
+				return resp, nil
+			}
+		}
+		if rev.Name == "" {
+			return resp, nil
+		}
+		if _, err := addRevision(rev); err != nil {
+			log.Printf("%s(): Failed to keep the revision of %s: %v", method, rev.Name, err)
+		}
+		return resp, nil
+	}
+}
@@ -0,0 +1,479 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package interceptor assembles the chain of gRPC interceptors of the bridge
+package interceptor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apply"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+const testMethod = "/opi_api.network.evpn_gw.v1alpha1.VrfService/GetVrf"
+
+func okHandler(context.Context, interface{}) (interface{}, error) {
+	return &pb.Vrf{}, nil
+}
+
+func Test_Chain(t *testing.T) {
+	tests := map[string]struct {
+		names []string
+		size  int
+		ok    bool
+	}{
+		"default chain": {
+			names: nil,
+			size:  len(DefaultChain),
+			ok:    true,
+		},
+		"configured chain": {
+			names: []string{"recovery", "auth", "validation"},
+			size:  3,
+			ok:    true,
+		},
+		"unknown interceptor": {
+			names: []string{"recovery", "tracing"},
+			ok:    false,
+		},
+		"duplicated interceptor": {
+			names: []string{"recovery", "recovery"},
+			ok:    false,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			chain, err := Chain(tt.names)
+			if (err == nil) != tt.ok {
+				t.Fatalf("expected success %v, got %v", tt.ok, err)
+			}
+			if len(chain) != tt.size {
+				t.Errorf("expected %d interceptors, got %d", tt.size, len(chain))
+			}
+		})
+	}
+}
+
+func Test_Recovery(t *testing.T) {
+	before := testutil.ToFloat64(panics)
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		var vrf *pb.Vrf
+		return vrf.Spec.Vni, nil
+	}
+	_, err := Recovery()(context.Background(), &pb.GetVrfRequest{}, &grpc.UnaryServerInfo{FullMethod: testMethod}, handler)
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected Internal, got %v", err)
+	}
+	if testutil.ToFloat64(panics) != before+1 {
+		t.Error("expected the panic to be counted")
+	}
+}
+
+func Test_Auth(t *testing.T) {
+	tokens := func() []string { return []string{"secret"} }
+
+	tests := map[string]struct {
+		method string
+		header string
+		code   codes.Code
+	}{
+		"valid token": {
+			method: testMethod,
+			header: "Bearer secret",
+			code:   codes.OK,
+		},
+		"invalid token": {
+			method: testMethod,
+			header: "Bearer guess",
+			code:   codes.Unauthenticated,
+		},
+		"missing token": {
+			method: testMethod,
+			code:   codes.Unauthenticated,
+		},
+		"health check is open": {
+			method: "/grpc.health.v1.Health/Check",
+			code:   codes.OK,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.header != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.header))
+			}
+			_, err := Auth(tokens)(ctx, &pb.GetVrfRequest{}, &grpc.UnaryServerInfo{FullMethod: tt.method}, okHandler)
+			if status.Code(err) != tt.code {
+				t.Errorf("expected %v, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func Test_Validation(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+	if _, err := Validation()(context.Background(), &pb.GetVrfRequest{}, info, okHandler); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+	if _, err := Validation()(context.Background(), &pb.GetVrfRequest{Name: "vrfs/blue"}, info, okHandler); err != nil {
+		t.Errorf("expected success, got %v", err)
+	}
+}
+
+func Test_Metrics(t *testing.T) {
+	before := testutil.ToFloat64(requests.WithLabelValues("GetVrf", codes.NotFound.String()))
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "unable to find key")
+	}
+	_, _ = Metrics()(context.Background(), &pb.GetVrfRequest{}, &grpc.UnaryServerInfo{FullMethod: testMethod}, handler)
+	if testutil.ToFloat64(requests.WithLabelValues("GetVrf", codes.NotFound.String())) != before+1 {
+		t.Error("expected the call to be counted by method and code")
+	}
+}
+
+func Test_Errors(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+	storeHandler := func(context.Context, interface{}) (interface{}, error) {
+		return nil, infradb.ErrVrfNotEmpty
+	}
+	_, err := Errors()(context.Background(), &pb.GetVrfRequest{}, info, storeHandler)
+	if code := status.Code(err); code != codes.FailedPrecondition {
+		t.Fatalf("expected code %v, received %v", codes.FailedPrecondition, code)
+	}
+	if reason := apierrors.Reason(err); reason != apierrors.ReasonNotEmpty {
+		t.Errorf("expected reason %s, received %q", apierrors.ReasonNotEmpty, reason)
+	}
+
+	plainHandler := func(context.Context, interface{}) (interface{}, error) {
+		return nil, errors.New("plain")
+	}
+	if _, err := Errors()(context.Background(), &pb.GetVrfRequest{}, info, plainHandler); status.Code(err) != codes.Unknown {
+		t.Errorf("expected the plain error to be left as it is, received %v", err)
+	}
+}
+
+func Test_Lease(t *testing.T) {
+	create := &grpc.UnaryServerInfo{FullMethod: "/opi_api.network.evpn_gw.v1alpha1.VrfService/CreateVrf"}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(LeaseHeader, "soon"))
+	called := false
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		called = true
+		return &pb.Vrf{}, nil
+	}
+	if _, err := Lease()(ctx, &pb.CreateVrfRequest{}, create, handler); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected code %v, received %v", codes.InvalidArgument, err)
+	}
+	if called {
+		t.Error("expected the vrf not to be created with an invalid lease")
+	}
+
+	// the header is ignored by the calls which do not create a resource
+	get := &grpc.UnaryServerInfo{FullMethod: testMethod}
+	if _, err := Lease()(ctx, &pb.GetVrfRequest{}, get, handler); err != nil || !called {
+		t.Errorf("expected the get to go through, received %v", err)
+	}
+}
+
+type headerStream struct {
+	header metadata.MD
+}
+
+func (s *headerStream) Method() string { return "" }
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *headerStream) SendHeader(md metadata.MD) error { return s.SetHeader(md) }
+
+func (s *headerStream) SetTrailer(metadata.MD) error { return nil }
+
+func Test_Analyze(t *testing.T) {
+	const name = "//network.opiproject.org/vrfs/blue"
+	dependents := func(n string) (*infradb.DependencyGraph, error) {
+		if n != name {
+			return nil, infradb.ErrKeyNotFound
+		}
+		return &infradb.DependencyGraph{Nodes: []*infradb.GraphNode{
+			{ID: name, Kind: "vrf"},
+			{ID: "netdev:blue", Kind: infradb.GraphKindNetdev},
+			{ID: "//network.opiproject.org/svis/blue", Kind: "svi"},
+		}}, nil
+	}
+	called := false
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		called = true
+		return &pb.Vrf{}, nil
+	}
+	analyzeOnly := func(value string) (context.Context, *headerStream) {
+		stream := &headerStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(AnalyzeOnlyHeader, value)), stream
+	}
+
+	del := &grpc.UnaryServerInfo{FullMethod: "/opi_api.network.evpn_gw.v1alpha1.VrfService/DeleteVrf"}
+	ctx, stream := analyzeOnly("true")
+	if _, err := analyze(dependents)(ctx, &pb.DeleteVrfRequest{Name: name}, del, handler); err != nil {
+		t.Fatal(err)
+	}
+	if called {
+		t.Error("expected the vrf not to be deleted")
+	}
+	expected := []string{"vrf " + name, "netdev netdev:blue", "svi //network.opiproject.org/svis/blue"}
+	if impact := stream.header.Get(ImpactHeader); !reflect.DeepEqual(impact, expected) {
+		t.Errorf("expected the impact %v, received %v", expected, impact)
+	}
+
+	// the update returns the object of the request without storing it
+	update := &grpc.UnaryServerInfo{FullMethod: "/opi_api.network.evpn_gw.v1alpha1.VrfService/UpdateVrf"}
+	vrf := &pb.Vrf{Name: name, Spec: &pb.VrfSpec{}}
+	ctx, _ = analyzeOnly("true")
+	if resp, err := analyze(dependents)(ctx, &pb.UpdateVrfRequest{Vrf: vrf}, update, handler); err != nil || resp != vrf || called {
+		t.Errorf("expected the vrf of the request, received %v %v", resp, err)
+	}
+
+	ctx, _ = analyzeOnly("true")
+	if _, err := analyze(dependents)(ctx, &pb.DeleteVrfRequest{Name: "//network.opiproject.org/vrfs/red"}, del, handler); err != infradb.ErrKeyNotFound {
+		t.Errorf("expected %v for an unknown vrf, received %v", infradb.ErrKeyNotFound, err)
+	}
+	ctx, _ = analyzeOnly("maybe")
+	if _, err := analyze(dependents)(ctx, &pb.DeleteVrfRequest{Name: name}, del, handler); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected code %v, received %v", codes.InvalidArgument, err)
+	}
+
+	// the header is ignored by the calls which do not modify a resource
+	ctx, _ = analyzeOnly("true")
+	if _, err := analyze(dependents)(ctx, &pb.GetVrfRequest{Name: name}, &grpc.UnaryServerInfo{FullMethod: testMethod}, handler); err != nil || !called {
+		t.Errorf("expected the get to go through, received %v", err)
+	}
+}
+
+func Test_ReadOnly(t *testing.T) {
+	mode := infradb.ReadOnlyMode{Enabled: true, Reason: "change freeze"}
+	getMode := func() (infradb.ReadOnlyMode, error) { return mode, nil }
+	called := false
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		called = true
+		return &pb.Vrf{}, nil
+	}
+	info := func(method string) *grpc.UnaryServerInfo {
+		return &grpc.UnaryServerInfo{FullMethod: "/opi_api.network.evpn_gw.v1alpha1.VrfService/" + method}
+	}
+
+	for _, method := range []string{"CreateVrf", "UpdateVrf", "DeleteVrf"} {
+		_, err := readOnly(getMode)(context.Background(), &pb.DeleteVrfRequest{}, info(method), handler)
+		if status.Code(err) != codes.FailedPrecondition || apierrors.Reason(err) != apierrors.ReasonReadOnly {
+			t.Errorf("expected %s to be rejected with %v, received %v", method, apierrors.ReasonReadOnly, err)
+		}
+	}
+	if called {
+		t.Error("expected no change while the bridge is read-only")
+	}
+	if _, err := readOnly(getMode)(context.Background(), &pb.GetVrfRequest{}, info("GetVrf"), handler); err != nil || !called {
+		t.Errorf("expected the get to go through, received %v", err)
+	}
+
+	// the deletes go through when the mode allows them
+	mode.AllowDeletes = true
+	called = false
+	if _, err := readOnly(getMode)(context.Background(), &pb.DeleteVrfRequest{}, info("DeleteVrf"), handler); err != nil || !called {
+		t.Errorf("expected the delete to go through, received %v", err)
+	}
+	if _, err := readOnly(getMode)(context.Background(), &pb.CreateVrfRequest{}, info("CreateVrf"), handler); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected the create to be rejected, received %v", err)
+	}
+}
+
+// vrfServer serves the vrf of the confirm test
+type vrfServer struct {
+	pb.UnimplementedVrfServiceServer
+	vrf *pb.Vrf
+}
+
+func (s *vrfServer) GetVrf(_ context.Context, in *pb.GetVrfRequest) (*pb.Vrf, error) {
+	if in.Name != s.vrf.Name {
+		return nil, status.Error(codes.NotFound, "unknown vrf")
+	}
+	return s.vrf, nil
+}
+
+func Test_Confirm(t *testing.T) {
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	const name = "//network.opiproject.org/vrfs/blue"
+	vni := uint32(1000)
+	server := &vrfServer{vrf: &pb.Vrf{Name: name, Spec: &pb.VrfSpec{Vni: &vni}}}
+	update := &grpc.UnaryServerInfo{FullMethod: "/opi_api.network.evpn_gw.v1alpha1.VrfService/UpdateVrf", Server: server}
+	withTimeout := func(value string) (context.Context, *headerStream) {
+		stream := &headerStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(ConfirmTimeoutHeader, value)), stream
+	}
+
+	ctx, stream := withTimeout("5m")
+	if _, err := Confirm()(ctx, &pb.UpdateVrfRequest{Vrf: &pb.Vrf{Name: name}}, update, okHandler); err != nil {
+		t.Fatal(err)
+	}
+	ids := stream.header.Get(ChangeIDHeader)
+	if len(ids) != 1 {
+		t.Fatalf("expected the id of the change, received %v", stream.header)
+	}
+	c, err := infradb.GetScheduledChange(ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.State != infradb.ScheduleAwaitingConfirmation || c.RevertAfter != 5*time.Minute || !strings.Contains(string(c.Snapshot), "1000") {
+		t.Errorf("unexpected change %+v", c)
+	}
+
+	ctx, _ = withTimeout("never")
+	if _, err := Confirm()(ctx, &pb.UpdateVrfRequest{Vrf: &pb.Vrf{Name: name}}, update, okHandler); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected code %v, received %v", codes.InvalidArgument, err)
+	}
+	// the update is rejected when the previous object cannot be read back to revert it
+	other := &grpc.UnaryServerInfo{FullMethod: "/opi_api.network.evpn_gw.v1alpha1.SviService/UpdateSvi", Server: server}
+	ctx, _ = withTimeout("5m")
+	if _, err := Confirm()(ctx, &pb.UpdateSviRequest{Svi: &pb.Svi{Name: "//network.opiproject.org/svis/blue"}}, other, okHandler); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected code %v, received %v", codes.InvalidArgument, err)
+	}
+
+	// the revert is tracked before the update runs, and dropped when the update fails
+	tracked := func() int {
+		t.Helper()
+		changes, err := infradb.GetAllScheduledChanges()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(changes)
+	}
+	before := tracked()
+	ctx, stream = withTimeout("5m")
+	failHandler := func(context.Context, interface{}) (interface{}, error) {
+		if tracked() != before+1 {
+			t.Errorf("expected the revert to be tracked before the update")
+		}
+		return nil, status.Error(codes.Internal, "failed")
+	}
+	if _, err := Confirm()(ctx, &pb.UpdateVrfRequest{Vrf: &pb.Vrf{Name: name}}, update, failHandler); status.Code(err) != codes.Internal {
+		t.Errorf("expected code %v, received %v", codes.Internal, err)
+	}
+	if n := tracked(); n != before || len(stream.header.Get(ChangeIDHeader)) != 0 {
+		t.Errorf("expected the revert of the failed update to be dropped, received %d changes and %v", n-before, stream.header)
+	}
+
+	// the update creating a missing vrf is reverted by deleting it
+	const missing = "//network.opiproject.org/vrfs/green"
+	ctx, stream = withTimeout("5m")
+	if _, err := Confirm()(ctx, &pb.UpdateVrfRequest{Vrf: &pb.Vrf{Name: missing}, AllowMissing: true}, update, okHandler); err != nil {
+		t.Fatal(err)
+	}
+	ids = stream.header.Get(ChangeIDHeader)
+	if len(ids) != 1 {
+		t.Fatalf("expected the id of the change, received %v", stream.header)
+	}
+	c, err = infradb.GetScheduledChange(ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := &apply.Snapshot{}
+	if err := json.Unmarshal(c.Snapshot, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Undos) != 1 || snapshot.Undos[0].Action != apply.ActionCreate || snapshot.Undos[0].Name != missing {
+		t.Errorf("expected the undo of the creation of %s, received %s", missing, c.Snapshot)
+	}
+	// without allow_missing the update of a missing vrf fails on its own, there is nothing to revert
+	ctx, stream = withTimeout("5m")
+	if _, err := Confirm()(ctx, &pb.UpdateVrfRequest{Vrf: &pb.Vrf{Name: missing}}, update, okHandler); err != nil {
+		t.Fatal(err)
+	}
+	if len(stream.header.Get(ChangeIDHeader)) != 0 {
+		t.Errorf("expected no change to confirm, received %v", stream.header)
+	}
+}
+
+func Test_History(t *testing.T) {
+	const name = "//network.opiproject.org/vrfs/blue"
+	var revs []*infradb.Revision
+	add := func(rev *infradb.Revision) (*infradb.Revision, error) {
+		revs = append(revs, rev)
+		return rev, nil
+	}
+	vrfHandler := func(context.Context, interface{}) (interface{}, error) {
+		return &pb.Vrf{Name: name}, nil
+	}
+	failHandler := func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	tests := map[string]struct {
+		method  string
+		req     interface{}
+		handler grpc.UnaryHandler
+		action  string
+		object  bool
+	}{
+		"create": {
+			method:  "/opi_api.network.evpn_gw.v1alpha1.VrfService/CreateVrf",
+			req:     &pb.CreateVrfRequest{VrfId: "blue"},
+			handler: vrfHandler,
+			action:  "create",
+			object:  true,
+		},
+		"delete": {
+			method:  "/opi_api.network.evpn_gw.v1alpha1.VrfService/DeleteVrf",
+			req:     &pb.DeleteVrfRequest{Name: name},
+			handler: okHandler,
+			action:  "delete",
+		},
+		"failed update": {
+			method:  "/opi_api.network.evpn_gw.v1alpha1.VrfService/UpdateVrf",
+			req:     &pb.UpdateVrfRequest{Vrf: &pb.Vrf{Name: name}},
+			handler: failHandler,
+		},
+		"read": {
+			method:  "/opi_api.network.evpn_gw.v1alpha1.VrfService/GetVrf",
+			req:     &pb.GetVrfRequest{Name: name},
+			handler: vrfHandler,
+		},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			revs = nil
+			info := &grpc.UnaryServerInfo{FullMethod: tt.method}
+			_, _ = history(add)(context.Background(), tt.req, info, tt.handler)
+			if tt.action == "" {
+				if len(revs) != 0 {
+					t.Errorf("expected no revision, received %+v", revs[0])
+				}
+				return
+			}
+			if len(revs) != 1 {
+				t.Fatalf("expected a revision, received %d", len(revs))
+			}
+			rev := revs[0]
+			if rev.Name != name || rev.Kind != "Vrf" || rev.Action != tt.action || (len(rev.Object) > 0) != tt.object {
+				t.Errorf("unexpected revision %+v", rev)
+			}
+		})
+	}
+}
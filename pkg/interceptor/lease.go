@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package interceptor assembles the chain of gRPC interceptors of the bridge
+package interceptor
+
+import (
+	"context"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// LeaseHeader is the metadata of a Create call which makes the created resource ephemeral, e.g. "30m"
+const LeaseHeader = "opi-lease"
+
+// named is implemented by the resources returned by the Create calls
+type named interface {
+	GetName() string
+}
+
+// Lease gives the resource created by a Create call carrying the opi-lease header a lease of that duration,
+// the resource is deleted once the lease expires unless it is renewed through the admin API
+func Lease() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		values := metadata.ValueFromIncomingContext(ctx, LeaseHeader)
+		if len(values) == 0 || !strings.HasPrefix(path.Base(info.FullMethod), "Create") {
+			return handler(ctx, req)
+		}
+		duration, err := time.ParseDuration(values[0])
+		if err != nil || duration <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid %s %q", LeaseHeader, values[0])
+		}
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		if obj, ok := resp.(named); ok {
+			if _, err := infradb.SetLease(obj.GetName(), duration); err != nil {
+				log.Printf("Lease(): Failed to give %s a lease of %v: %v", obj.GetName(), duration, err)
+				return nil, err
+			}
+		}
+		return resp, nil
+	}
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package interceptor assembles the chain of gRPC interceptors of the bridge
+package interceptor
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// registry holds the metrics of the bridge, apart from the default registry of the libraries
+	registry = prometheus.NewRegistry()
+
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "opi_evpn_grpc_requests_total",
+		Help: "Number of gRPC calls handled, by method and status code.",
+	}, []string{"method", "code"})
+
+	latency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "opi_evpn_grpc_request_duration_seconds",
+		Help:    "Latency of the gRPC calls, by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+
+	panics = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "opi_evpn_grpc_panics_total",
+		Help: "Number of panics recovered in the gRPC handlers.",
+	})
+)
+
+func init() {
+	registry.MustRegister(requests, latency, panics,
+		collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+}
+
+// RegisterMetrics adds the metrics of a subsystem to the ones served by the bridge
+func RegisterMetrics(cs ...prometheus.Collector) {
+	registry.MustRegister(cs...)
+}
+
+// Metrics counts the calls by method and status code and observes their latency
+func Metrics() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		method := path.Base(info.FullMethod)
+		requests.WithLabelValues(method, status.Code(err).String()).Inc()
+		latency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+		return resp, err
+	}
+}
+
+// MetricsHandler serves the metrics in the prometheus text format
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package interceptor assembles the chain of gRPC interceptors of the bridge
+package interceptor
+
+import (
+	"context"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// ReadOnlyFunc returns the read-only mode of the bridge
+type ReadOnlyFunc func() (infradb.ReadOnlyMode, error)
+
+var (
+	readOnlyRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "opi_evpn_read_only_rejected_total",
+		Help: "Number of calls rejected because the bridge is read-only, by method.",
+	}, []string{"method"})
+
+	readOnlyEnabled = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "opi_evpn_read_only",
+		Help: "Whether the bridge is read-only, 1 when it is, 0 otherwise.",
+	}, func() float64 {
+		if mode, err := infradb.GetReadOnlyMode(); err == nil && mode.Enabled {
+			return 1
+		}
+		return 0
+	})
+)
+
+func init() {
+	registry.MustRegister(readOnlyRejected, readOnlyEnabled)
+}
+
+// CheckWritable returns a FailedPrecondition error when the read-only mode rejects the call of the method,
+// which deletes a resource or else creates or changes one
+func CheckWritable(mode infradb.ReadOnlyMode, method string, isDelete bool) error {
+	if !mode.Enabled || (isDelete && mode.AllowDeletes) {
+		return nil
+	}
+	readOnlyRejected.WithLabelValues(method).Inc()
+	return apierrors.FailedPrecondition(apierrors.ReasonReadOnly, method, "the bridge is read-only since %s: %s",
+		mode.Since.Format("2006-01-02T15:04:05Z07:00"), mode.Reason)
+}
+
+// ReadOnly rejects the Create, Update and Delete calls while the bridge is read-only, the deletes go through
+// when the mode allows them. It comes after analyze so that the analyze-only calls are still answered.
+func ReadOnly() grpc.UnaryServerInterceptor {
+	return readOnly(infradb.GetReadOnlyMode)
+}
+
+func readOnly(getMode ReadOnlyFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := path.Base(info.FullMethod)
+		isDelete := strings.HasPrefix(method, "Delete")
+		if !isDelete && !strings.HasPrefix(method, "Create") && !strings.HasPrefix(method, "Update") {
+			return handler(ctx, req)
+		}
+		mode, err := getMode()
+		if err != nil {
+			log.Printf("%s(): Failed to read the read-only mode: %v", method, err)
+			return nil, err
+		}
+		if err := CheckWritable(mode, method, isDelete); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
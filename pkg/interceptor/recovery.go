@@ -0,0 +1,27 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package interceptor assembles the chain of gRPC interceptors of the bridge
+package interceptor
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Recovery turns the panic of a handler into an Internal error of its call, so that the bridge keeps serving
+// the other calls. The stack of the panic is logged, the client only gets the panic value.
+func Recovery() grpc.UnaryServerInterceptor {
+	return recovery.UnaryServerInterceptor(recovery.WithRecoveryHandlerContext(func(_ context.Context, p any) error {
+		panics.Inc()
+		log.Printf("recovered from a panic in a gRPC handler: %v\n%s", p, debug.Stack())
+		return status.Errorf(codes.Internal, "internal error: %v", p)
+	}))
+}
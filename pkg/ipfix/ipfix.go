@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ipfix exports flow records to an IPFIX (RFC7011) collector over udp
+package ipfix
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// the flowEndReason values of the IANA registry
+const (
+	EndReasonIdleTimeout   uint8 = 1
+	EndReasonActiveTimeout uint8 = 2
+	EndReasonEndOfFlow     uint8 = 3
+	EndReasonForcedEnd     uint8 = 4
+)
+
+// Flow is a unidirectional flow record, the counters are the deltas since the previous record of the flow
+type Flow struct {
+	SrcIP     net.IP
+	DstIP     net.IP
+	SrcPort   uint16
+	DstPort   uint16
+	Protocol  uint8
+	Packets   uint64
+	Bytes     uint64
+	Start     time.Time
+	End       time.Time
+	EndReason uint8
+	// VrfID is the ingressVRFID of the record, the routing table of the vrf
+	VrfID uint32
+}
+
+const (
+	version       = 10
+	headerLen     = 16
+	setHeaderLen  = 4
+	templateSetID = 2
+	templateIPv4  = 256
+	templateIPv6  = 257
+	maxMessageLen = 1400
+	recordTailLen = 2 + 2 + 1 + 8 + 8 + 8 + 8 + 1 + 4
+	recordIPv4Len = 4 + 4 + recordTailLen
+	recordIPv6Len = 16 + 16 + recordTailLen
+)
+
+// field is an information element of a template
+type field struct {
+	id, length uint16
+}
+
+// tailFields follow the addresses in both templates
+var tailFields = []field{
+	{7, 2},   // sourceTransportPort
+	{11, 2},  // destinationTransportPort
+	{4, 1},   // protocolIdentifier
+	{2, 8},   // packetDeltaCount
+	{1, 8},   // octetDeltaCount
+	{152, 8}, // flowStartMilliseconds
+	{153, 8}, // flowEndMilliseconds
+	{136, 1}, // flowEndReason
+	{234, 4}, // ingressVRFID
+}
+
+// templates are the fields of the IPv4 and IPv6 records
+var templates = map[uint16][]field{
+	templateIPv4: append([]field{{8, 4}, {12, 4}}, tailFields...),    // sourceIPv4Address, destinationIPv4Address
+	templateIPv6: append([]field{{27, 16}, {28, 16}}, tailFields...), // sourceIPv6Address, destinationIPv6Address
+}
+
+// templateRefresh is how often the templates are sent again, udp collectors may have missed them (RFC7011 10.3.6)
+var templateRefresh = 10 * time.Minute
+
+// Exporter sends the flow records of an observation domain to a collector
+type Exporter struct {
+	mu           sync.Mutex
+	conn         net.Conn
+	domain       uint32
+	sequence     uint32
+	lastTemplate time.Time
+	now          func() time.Time
+}
+
+// NewExporter returns the exporter writing to the connection, one message per datagram
+func NewExporter(conn net.Conn, domain uint32) *Exporter {
+	return &Exporter{conn: conn, domain: domain, now: time.Now}
+}
+
+// Dial returns the exporter to the udp collector at host:port
+func Dial(collector string, domain uint32, dialer *net.Dialer) (*Exporter, error) {
+	conn, err := dialer.Dial("udp", collector)
+	if err != nil {
+		return nil, fmt.Errorf("ipfix collector %s: %w", collector, err)
+	}
+	return NewExporter(conn, domain), nil
+}
+
+// appendTemplates appends the template set
+func appendTemplates(b []byte) []byte {
+	start := len(b)
+	b = binary.BigEndian.AppendUint16(b, templateSetID)
+	b = binary.BigEndian.AppendUint16(b, 0)
+	for _, id := range []uint16{templateIPv4, templateIPv6} {
+		b = binary.BigEndian.AppendUint16(b, id)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(templates[id])))
+		for _, f := range templates[id] {
+			b = binary.BigEndian.AppendUint16(b, f.id)
+			b = binary.BigEndian.AppendUint16(b, f.length)
+		}
+	}
+	binary.BigEndian.PutUint16(b[start+2:], uint16(len(b)-start))
+	return b
+}
+
+// templateOf returns the template of the flow and the length of its record
+func templateOf(f *Flow) (uint16, int) {
+	if f.SrcIP.To4() != nil && f.DstIP.To4() != nil {
+		return templateIPv4, recordIPv4Len
+	}
+	return templateIPv6, recordIPv6Len
+}
+
+// appendRecord appends the data record of the flow
+func appendRecord(b []byte, f *Flow, template uint16) []byte {
+	if template == templateIPv4 {
+		b = append(b, f.SrcIP.To4()...)
+		b = append(b, f.DstIP.To4()...)
+	} else {
+		b = append(b, f.SrcIP.To16()...)
+		b = append(b, f.DstIP.To16()...)
+	}
+	b = binary.BigEndian.AppendUint16(b, f.SrcPort)
+	b = binary.BigEndian.AppendUint16(b, f.DstPort)
+	b = append(b, f.Protocol)
+	b = binary.BigEndian.AppendUint64(b, f.Packets)
+	b = binary.BigEndian.AppendUint64(b, f.Bytes)
+	b = binary.BigEndian.AppendUint64(b, uint64(f.Start.UnixMilli()))
+	b = binary.BigEndian.AppendUint64(b, uint64(f.End.UnixMilli()))
+	b = append(b, f.EndReason)
+	b = binary.BigEndian.AppendUint32(b, f.VrfID)
+	return b
+}
+
+// message is an IPFIX message being built
+type message struct {
+	buf     []byte
+	records uint32
+	// set is the offset of the open data set, its template is setID
+	set   int
+	setID uint16
+}
+
+// closeSet writes the length of the open data set
+func (m *message) closeSet() {
+	if m.setID != 0 {
+		binary.BigEndian.PutUint16(m.buf[m.set+2:], uint16(len(m.buf)-m.set))
+		m.setID = 0
+	}
+}
+
+// messages encodes the flows in messages fitting a datagram, the templates lead the first message when
+// they are due, the sequence numbers count the data records sent before each message
+func (e *Exporter) messages(flows []Flow, now time.Time) [][]byte {
+	withTemplates := e.lastTemplate.IsZero() || now.Sub(e.lastTemplate) >= templateRefresh
+	if withTemplates {
+		e.lastTemplate = now
+	}
+	var out [][]byte
+	var m *message
+	flush := func() {
+		m.closeSet()
+		binary.BigEndian.PutUint16(m.buf[2:], uint16(len(m.buf)))
+		out = append(out, m.buf)
+		e.sequence += m.records
+		m = nil
+	}
+	open := func() {
+		m = &message{buf: make([]byte, headerLen, maxMessageLen)}
+		binary.BigEndian.PutUint16(m.buf[0:], version)
+		binary.BigEndian.PutUint32(m.buf[4:], uint32(now.Unix()))
+		binary.BigEndian.PutUint32(m.buf[8:], e.sequence)
+		binary.BigEndian.PutUint32(m.buf[12:], e.domain)
+		if withTemplates {
+			m.buf = appendTemplates(m.buf)
+			withTemplates = false
+		}
+	}
+	for i := range flows {
+		template, size := templateOf(&flows[i])
+		if m != nil {
+			needed := size
+			if m.setID != template {
+				needed += setHeaderLen
+			}
+			if len(m.buf)+needed > maxMessageLen {
+				flush()
+			}
+		}
+		if m == nil {
+			open()
+		}
+		if m.setID != template {
+			m.closeSet()
+			m.set, m.setID = len(m.buf), template
+			m.buf = binary.BigEndian.AppendUint16(m.buf, template)
+			m.buf = binary.BigEndian.AppendUint16(m.buf, 0)
+		}
+		m.buf = appendRecord(m.buf, &flows[i], template)
+		m.records++
+	}
+	if m == nil && withTemplates {
+		open()
+	}
+	if m != nil {
+		flush()
+	}
+	return out
+}
+
+// Export sends the flow records, and the templates when they are due, to the collector
+func (e *Exporter) Export(flows []Flow) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, msg := range e.messages(flows, e.now()) {
+		if _, err := e.conn.Write(msg); err != nil {
+			// the templates are sent again with the next export
+			e.lastTemplate = time.Time{}
+			return fmt.Errorf("ipfix export to %s: %w", e.conn.RemoteAddr(), err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection to the collector
+func (e *Exporter) Close() error {
+	return e.conn.Close()
+}
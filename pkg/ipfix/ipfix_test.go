@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ipfix exports flow records to an IPFIX (RFC7011) collector over udp
+package ipfix
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// decoded is what a collector reads from a message
+type decoded struct {
+	sequence  uint32
+	domain    uint32
+	templates []uint16
+	records   map[uint16]int
+	first     []byte
+}
+
+// decode walks the sets of the message, checking the lengths
+func decode(t *testing.T, msg []byte) decoded {
+	t.Helper()
+	if binary.BigEndian.Uint16(msg[0:]) != version || int(binary.BigEndian.Uint16(msg[2:])) != len(msg) {
+		t.Fatalf("invalid message header % x", msg[:headerLen])
+	}
+	d := decoded{
+		sequence: binary.BigEndian.Uint32(msg[8:]),
+		domain:   binary.BigEndian.Uint32(msg[12:]),
+		records:  make(map[uint16]int),
+	}
+	for off := headerLen; off < len(msg); {
+		id, length := binary.BigEndian.Uint16(msg[off:]), int(binary.BigEndian.Uint16(msg[off+2:]))
+		if length < setHeaderLen || off+length > len(msg) {
+			t.Fatalf("invalid set length %d at %d", length, off)
+		}
+		body := msg[off+setHeaderLen : off+length]
+		switch id {
+		case templateSetID:
+			for len(body) > 0 {
+				template, count := binary.BigEndian.Uint16(body), int(binary.BigEndian.Uint16(body[2:]))
+				d.templates = append(d.templates, template)
+				body = body[4+4*count:]
+			}
+		case templateIPv4, templateIPv6:
+			size := recordIPv4Len
+			if id == templateIPv6 {
+				size = recordIPv6Len
+			}
+			if len(body)%size != 0 {
+				t.Fatalf("data set of %d bytes is not made of %d bytes records", len(body), size)
+			}
+			if d.first == nil {
+				d.first = body[:size]
+			}
+			d.records[id] += len(body) / size
+		}
+		off += length
+	}
+	return d
+}
+
+func Test_Messages(t *testing.T) {
+	e := NewExporter(nil, 7)
+	now := time.Date(2023, 10, 17, 9, 30, 0, 0, time.UTC)
+	flows := []Flow{
+		{SrcIP: net.ParseIP("10.1.1.10"), DstIP: net.ParseIP("10.2.2.20"), SrcPort: 40000, DstPort: 443, Protocol: 6,
+			Packets: 12, Bytes: 4096, Start: now.Add(-time.Minute), End: now, EndReason: EndReasonActiveTimeout, VrfID: 1000},
+		{SrcIP: net.ParseIP("2001:db8::10"), DstIP: net.ParseIP("2001:db8::20"), SrcPort: 53, DstPort: 53, Protocol: 17,
+			Packets: 1, Bytes: 80, Start: now, End: now, EndReason: EndReasonIdleTimeout, VrfID: 1000},
+	}
+	msgs := e.messages(flows, now)
+	if len(msgs) != 1 {
+		t.Fatalf("expected one message, received %d", len(msgs))
+	}
+	d := decode(t, msgs[0])
+	if d.domain != 7 || d.sequence != 0 || len(d.templates) != 2 || d.records[templateIPv4] != 1 || d.records[templateIPv6] != 1 {
+		t.Errorf("unexpected message %+v", d)
+	}
+	// 10.1.1.10 > 10.2.2.20 40000 > 443 tcp
+	expected := []byte{10, 1, 1, 10, 10, 2, 2, 20, 0x9c, 0x40, 0x01, 0xbb, 6}
+	if string(d.first[:len(expected)]) != string(expected) || binary.BigEndian.Uint64(d.first[13:]) != 12 ||
+		binary.BigEndian.Uint64(d.first[21:]) != 4096 || d.first[45] != EndReasonActiveTimeout ||
+		binary.BigEndian.Uint32(d.first[46:]) != 1000 {
+		t.Errorf("unexpected record % x", d.first)
+	}
+
+	// the templates are only sent again once they are due, the records are split to fit the datagrams
+	many := make([]Flow, 100)
+	for i := range many {
+		many[i] = flows[0]
+	}
+	msgs = e.messages(many, now.Add(time.Minute))
+	total := 0
+	for i, msg := range msgs {
+		if len(msg) > maxMessageLen {
+			t.Errorf("message of %d bytes does not fit a datagram", len(msg))
+		}
+		d := decode(t, msg)
+		if len(d.templates) != 0 {
+			t.Errorf("expected the templates not to be sent again")
+		}
+		if d.sequence != uint32(2+total) {
+			t.Errorf("expected message %d to follow %d records, received %d", i, 2+total, d.sequence)
+		}
+		total += d.records[templateIPv4]
+	}
+	if total != len(many) || len(msgs) < 2 {
+		t.Errorf("expected the %d records in several messages, received %d in %d", len(many), total, len(msgs))
+	}
+	msgs = e.messages(nil, now.Add(templateRefresh))
+	if len(msgs) != 1 || len(decode(t, msgs[0]).templates) != 2 {
+		t.Errorf("expected the templates to be refreshed")
+	}
+}
+
+func Test_Export(t *testing.T) {
+	collector, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer collector.Close()
+	e, err := Dial(collector.LocalAddr().String(), 1, &net.Dialer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	if err := e.Export([]Flow{{SrcIP: net.ParseIP("10.1.1.10"), DstIP: net.ParseIP("10.2.2.20"), Protocol: 1, Packets: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 2048)
+	_ = collector.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := collector.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := decode(t, buf[:n]); d.records[templateIPv4] != 1 || len(d.templates) != 2 {
+		t.Errorf("unexpected message %+v", d)
+	}
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ipsec encrypts the VXLAN tunnels to the remote VTEPs with ESP
+package ipsec
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/netip"
+	"os"
+	"path/filepath"
+)
+
+// outEpochs are the rekey intervals of the last outbound SAs installed for the peers, kept in a file across the
+// restarts of the bridge: an outbound SA installed again restarts its sequence numbers, which are the nonces of the
+// AEAD, so the SA of an interval is never installed twice
+type outEpochs struct {
+	path   string
+	epochs map[netip.Addr]int64
+}
+
+// loadOutEpochs reads the rekey intervals of the file, none when there is no file yet
+func loadOutEpochs(path string) (*outEpochs, error) {
+	o := &outEpochs{path: path, epochs: make(map[netip.Addr]int64)}
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &o.epochs); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// last returns the rekey interval of the last outbound SA installed for the peer
+func (o *outEpochs) last(peer netip.Addr) (int64, bool) {
+	epoch, ok := o.epochs[peer]
+	return epoch, ok
+}
+
+// use records the outbound SA of the peer in the rekey interval epoch before it is installed, the intervals which
+// are over are forgotten
+func (o *outEpochs) use(peer netip.Addr, epoch, current int64) error {
+	epochs := map[netip.Addr]int64{peer: epoch}
+	for p, e := range o.epochs {
+		if p != peer && e >= current {
+			epochs[p] = e
+		}
+	}
+	raw, err := json.Marshal(epochs)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(o.path), 0o700); err != nil {
+		return err
+	}
+	tmp := o.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, o.path); err != nil {
+		return err
+	}
+	o.epochs = epochs
+	return nil
+}
@@ -0,0 +1,538 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ipsec encrypts the VXLAN tunnels to the remote VTEPs with ESP
+package ipsec
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+const (
+	// reqid ties the policies of the bridge to its SAs and tells them from the ones of other IKE daemons
+	reqid = 0x0e7b
+	// vxlanPort is the destination port of the VXLAN packets which are encrypted
+	vxlanPort         = 4789
+	defaultRekey      = time.Hour
+	reconcileInterval = 10 * time.Second
+	replayWindow      = 32
+	// icvLen is the length in bits of the integrity check value of the AEAD
+	icvLen = 128
+	// imetRoute is the EVPN route type whose nexthops are the remote VTEPs of the logical bridges
+	imetRoute = 3
+	// defaultStateFile keeps the rekey intervals of the outbound SAs across the restarts
+	defaultStateFile = "/var/lib/opi-evpn-bridge/ipsec.state"
+)
+
+// Source of the peers
+const (
+	SourceConfig = "config"
+	SourceEvpn   = "evpn"
+)
+
+// ciphers are the AEADs of the SAs by name, with the length of their key in bytes
+var ciphers = map[string]int{"aes-gcm-128": 16, "aes-gcm-256": 32}
+
+// Tunnel is the encryption of the VXLAN traffic exchanged with a peer
+type Tunnel struct {
+	Peer   string
+	Source string
+	// OutSpi is the spi of the packets sent to the peer in the current rekey interval, InSpis the spis of the
+	// packets accepted from it, of the previous, the current and the next interval
+	OutSpi    uint32
+	InSpis    []uint32
+	LastRekey time.Time
+	// The counters are the ones of the SAs in place, they restart with every rekey
+	TxBytes, TxPackets uint64
+	RxBytes, RxPackets uint64
+	// ReplayErrors counts the packets dropped as replayed, IntegrityErrors the ones failing the integrity check
+	ReplayErrors    uint64
+	IntegrityErrors uint64
+	// Error is the failure of the last reconciliation of the tunnel
+	Error string
+}
+
+// kernel programs the XFRM policies and states
+type kernel interface {
+	StateList() ([]netlink.XfrmState, error)
+	StateAdd(state *netlink.XfrmState) error
+	StateDel(state *netlink.XfrmState) error
+	PolicyUpdate(policy *netlink.XfrmPolicy) error
+	PolicyDel(policy *netlink.XfrmPolicy) error
+}
+
+// netlinkKernel programs the XFRM of the kernel over netlink
+type netlinkKernel struct{}
+
+func (netlinkKernel) StateList() ([]netlink.XfrmState, error) {
+	return netlink.XfrmStateList(netlink.FAMILY_ALL)
+}
+
+func (netlinkKernel) StateAdd(state *netlink.XfrmState) error {
+	return netlink.XfrmStateAdd(state)
+}
+
+func (netlinkKernel) StateDel(state *netlink.XfrmState) error {
+	return netlink.XfrmStateDel(state)
+}
+
+func (netlinkKernel) PolicyUpdate(policy *netlink.XfrmPolicy) error {
+	return netlink.XfrmPolicyUpdate(policy)
+}
+
+func (netlinkKernel) PolicyDel(policy *netlink.XfrmPolicy) error {
+	return netlink.XfrmPolicyDel(policy)
+}
+
+var (
+	bytesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "opi_evpn_ipsec_tunnel_bytes",
+		Help: "Bytes encrypted to or decrypted from a remote VTEP by the SAs in place.",
+	}, []string{"peer", "direction"})
+	errorsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "opi_evpn_ipsec_tunnel_errors",
+		Help: "Packets of a remote VTEP dropped as replayed or failing the integrity check by the SAs in place.",
+	}, []string{"peer", "reason"})
+	rekeysCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "opi_evpn_ipsec_rekeys_total",
+		Help: "Rekeys of the tunnels to the remote VTEPs.",
+	})
+)
+
+// Collectors returns the metrics of the tunnels
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{bytesGauge, errorsGauge, rekeysCounter}
+}
+
+// tunnelState is what the manager programmed for a peer
+type tunnelState struct {
+	source    string
+	epoch     int64
+	outSpi    uint32
+	inSpis    []uint32
+	lastRekey time.Time
+	err       string
+}
+
+// Manager keeps the policies and the SAs of the tunnels to the peers in place and rekeys them at every interval
+type Manager struct {
+	mu       sync.Mutex
+	kernel   kernel
+	keys     keySource
+	local    netip.Addr
+	keyLen   int
+	rekey    time.Duration
+	static   []netip.Addr
+	discover bool
+	tunnels  map[netip.Addr]*tunnelState
+	used     *outEpochs
+	now      func() time.Time
+	// evpnRoutes returns the routes of the remote VTEPs
+	evpnRoutes func(ctx context.Context) ([]routing.EvpnRoute, error)
+}
+
+// newManager returns the manager of the tunnels of the config
+func newManager(cfg *config.IpsecConfig, local netip.Addr, keys keySource, k kernel) (*Manager, error) {
+	m := &Manager{
+		kernel:   k,
+		keys:     keys,
+		local:    local,
+		rekey:    time.Duration(cfg.Rekey) * time.Second,
+		discover: cfg.Discover,
+		tunnels:  make(map[netip.Addr]*tunnelState),
+		now:      time.Now,
+		evpnRoutes: func(ctx context.Context) ([]routing.EvpnRoute, error) {
+			backend, err := routing.Get()
+			if err != nil {
+				return nil, err
+			}
+			return backend.EvpnRoutes(ctx)
+		},
+	}
+	cipher := cfg.Cipher
+	if cipher == "" {
+		cipher = "aes-gcm-256"
+	}
+	var ok bool
+	if m.keyLen, ok = ciphers[cipher]; !ok {
+		return nil, fmt.Errorf("unknown ipsec cipher %s", cipher)
+	}
+	if m.rekey == 0 {
+		m.rekey = defaultRekey
+	}
+	stateFile := cfg.StateFile
+	if stateFile == "" {
+		stateFile = defaultStateFile
+	}
+	var err error
+	if m.used, err = loadOutEpochs(stateFile); err != nil {
+		return nil, fmt.Errorf("failed to read the ipsec state of %s: %w", stateFile, err)
+	}
+	for _, peer := range cfg.Peers {
+		addr, err := netip.ParseAddr(peer)
+		if err != nil {
+			return nil, err
+		}
+		m.static = append(m.static, addr)
+	}
+	return m, nil
+}
+
+// targets returns the peers to encrypt with their source, the configured peers first
+func (m *Manager) targets(ctx context.Context) map[netip.Addr]string {
+	out := make(map[netip.Addr]string)
+	if m.discover {
+		routes, err := m.evpnRoutes(ctx)
+		if err != nil {
+			log.Printf("ipsec: failed to read the remote VTEPs of the EVPN routes: %v\n", err)
+		}
+		for _, route := range routes {
+			if route.RouteType != imetRoute {
+				continue
+			}
+			for _, nh := range route.Nexthops {
+				if addr, err := netip.ParseAddr(nh); err == nil && addr.Unmap() != m.local && !addr.IsUnspecified() {
+					out[addr.Unmap()] = SourceEvpn
+				}
+			}
+		}
+	}
+	for _, addr := range m.static {
+		out[addr] = SourceConfig
+	}
+	return out
+}
+
+// hostNet is the selector of a single address
+func hostNet(addr netip.Addr) *net.IPNet {
+	return &net.IPNet{IP: addr.AsSlice(), Mask: net.CIDRMask(addr.BitLen(), addr.BitLen())}
+}
+
+// policies returns the policies wrapping the VXLAN packets exchanged with the peer in ESP transport mode, the
+// packets of the peer which are not encrypted are dropped
+func (m *Manager) policies(peer netip.Addr) []*netlink.XfrmPolicy {
+	policy := func(dir netlink.Dir, src, dst netip.Addr) *netlink.XfrmPolicy {
+		return &netlink.XfrmPolicy{
+			Src:     hostNet(src),
+			Dst:     hostNet(dst),
+			Proto:   syscall.IPPROTO_UDP,
+			DstPort: vxlanPort,
+			Dir:     dir,
+			Tmpls: []netlink.XfrmPolicyTmpl{{
+				Src: src.AsSlice(), Dst: dst.AsSlice(), Proto: netlink.XFRM_PROTO_ESP, Mode: netlink.XFRM_MODE_TRANSPORT, Reqid: reqid,
+			}},
+		}
+	}
+	return []*netlink.XfrmPolicy{
+		policy(netlink.XFRM_DIR_OUT, m.local, peer),
+		policy(netlink.XFRM_DIR_IN, peer, m.local),
+	}
+}
+
+// state returns the SA from src to dst in the rekey interval epoch
+func (m *Manager) state(secret []byte, src, dst netip.Addr, epoch int64) (*netlink.XfrmState, error) {
+	spi, key, err := deriveSA(secret, src, dst, epoch, m.keyLen)
+	if err != nil {
+		return nil, err
+	}
+	return &netlink.XfrmState{
+		Src:          src.AsSlice(),
+		Dst:          dst.AsSlice(),
+		Proto:        netlink.XFRM_PROTO_ESP,
+		Mode:         netlink.XFRM_MODE_TRANSPORT,
+		Spi:          int(spi),
+		Reqid:        reqid,
+		ReplayWindow: replayWindow,
+		Aead:         &netlink.XfrmStateAlgo{Name: "rfc4106(gcm(aes))", Key: key, ICVLen: icvLen},
+	}, nil
+}
+
+// outState returns the outbound SA to the peer, the one of the rekey interval epoch unless it was installed before
+// and is no longer in place, after a restart of the bridge or a flush of the SAs, the tunnel then moves to the next
+// interval, which the peer accepts as well
+func (m *Manager) outState(secret []byte, peer netip.Addr, epoch int64, installed map[saKey]netlink.XfrmState) (*netlink.XfrmState, int64, error) {
+	out := epoch
+	if last, ok := m.used.last(peer); ok && last >= epoch {
+		state, err := m.state(secret, m.local, peer, last)
+		if err != nil {
+			return nil, 0, err
+		}
+		if _, ok := installed[keyOf(state)]; ok {
+			return state, last, nil
+		}
+		out = last + 1
+	}
+	if out > epoch+1 {
+		return nil, 0, fmt.Errorf("the outbound SAs of the current and of the next rekey interval were already used, waiting for the next interval")
+	}
+	state, err := m.state(secret, m.local, peer, out)
+	return state, out, err
+}
+
+// saKey identifies an SA
+type saKey struct {
+	src, dst netip.Addr
+	spi      uint32
+}
+
+func keyOf(s *netlink.XfrmState) saKey {
+	src, _ := netip.AddrFromSlice(s.Src)
+	dst, _ := netip.AddrFromSlice(s.Dst)
+	return saKey{src: src.Unmap(), dst: dst.Unmap(), spi: uint32(s.Spi)}
+}
+
+// reconcile puts in place the SAs of the current rekey interval of every peer, the SAs of the previous and the next
+// interval are accepted as well from the peers whose clock is a little off, and removes the other SAs of the bridge
+func (m *Manager) reconcile(ctx context.Context) {
+	targets := m.targets(ctx)
+	now := m.now()
+	epoch := now.Unix() / int64(m.rekey/time.Second)
+
+	existing, err := m.kernel.StateList()
+	if err != nil {
+		log.Printf("ipsec: failed to list the SAs: %v\n", err)
+		return
+	}
+	installed := make(map[saKey]netlink.XfrmState)
+	for _, s := range existing {
+		if s.Reqid == reqid && s.Proto == netlink.XFRM_PROTO_ESP {
+			installed[keyOf(&s)] = s
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	wanted := make(map[saKey]bool)
+	for peer, source := range targets {
+		t, ok := m.tunnels[peer]
+		if !ok {
+			t = &tunnelState{}
+			m.tunnels[peer] = t
+		}
+		t.source = source
+		if err := m.setUp(t, peer, epoch, installed, wanted); err != nil {
+			t.err = err.Error()
+			log.Printf("ipsec: failed to encrypt the tunnel to %s: %v\n", peer, err)
+			continue
+		}
+		t.err = ""
+		if t.epoch != epoch {
+			if !t.lastRekey.IsZero() {
+				rekeysCounter.Inc()
+			}
+			t.epoch = epoch
+			t.lastRekey = now
+		}
+	}
+
+	// the SAs are replaced once the new ones are in place, the kernel then encrypts with the new outbound SA
+	for k, s := range installed {
+		if !wanted[k] {
+			s := s
+			if err := m.kernel.StateDel(&s); err != nil {
+				log.Printf("ipsec: failed to remove the SA %08x from %s to %s: %v\n", k.spi, k.src, k.dst, err)
+			}
+		}
+	}
+	for peer := range m.tunnels {
+		if _, ok := targets[peer]; ok {
+			continue
+		}
+		for _, policy := range m.policies(peer) {
+			if err := m.kernel.PolicyDel(policy); err != nil {
+				log.Printf("ipsec: failed to remove the policy of %s: %v\n", peer, err)
+			}
+		}
+		delete(m.tunnels, peer)
+		for _, direction := range []string{"tx", "rx"} {
+			bytesGauge.DeleteLabelValues(peer.String(), direction)
+		}
+		for _, reason := range []string{"replay", "integrity"} {
+			errorsGauge.DeleteLabelValues(peer.String(), reason)
+		}
+		log.Printf("ipsec: the tunnel to %s is no longer encrypted\n", peer)
+	}
+}
+
+// setUp puts in place the policies and the SAs of the tunnel to the peer and marks its SAs as wanted
+func (m *Manager) setUp(t *tunnelState, peer netip.Addr, epoch int64, installed map[saKey]netlink.XfrmState, wanted map[saKey]bool) error {
+	if peer.Is4() != m.local.Is4() {
+		return fmt.Errorf("the address family of %s is not the one of the local vtep %s", peer, m.local)
+	}
+	secret, err := m.keys.secret(peer)
+	if err != nil {
+		return err
+	}
+	out, outEpoch, err := m.outState(secret, peer, epoch, installed)
+	if err != nil {
+		return err
+	}
+	states := []*netlink.XfrmState{out}
+	for e := epoch - 1; e <= epoch+1; e++ {
+		in, err := m.state(secret, peer, m.local, e)
+		if err != nil {
+			return err
+		}
+		states = append(states, in)
+	}
+	t.outSpi = uint32(out.Spi)
+	t.inSpis = t.inSpis[:0]
+	for _, s := range states {
+		k := keyOf(s)
+		wanted[k] = true
+		if k.dst == m.local {
+			t.inSpis = append(t.inSpis, k.spi)
+		}
+		if _, ok := installed[k]; ok {
+			continue
+		}
+		if s == out {
+			if err := m.used.use(peer, outEpoch, epoch); err != nil {
+				return fmt.Errorf("failed to record the outbound SA %08x: %w", k.spi, err)
+			}
+		}
+		if err := m.kernel.StateAdd(s); err != nil {
+			return fmt.Errorf("failed to add the SA %08x from %s to %s: %w", k.spi, k.src, k.dst, err)
+		}
+	}
+	for _, policy := range m.policies(peer) {
+		if err := m.kernel.PolicyUpdate(policy); err != nil {
+			return fmt.Errorf("failed to update the %s policy: %w", policy.Dir, err)
+		}
+	}
+	return nil
+}
+
+// Tunnels returns the tunnels with the counters of their SAs, by address of the peer
+func (m *Manager) Tunnels() []Tunnel {
+	states, err := m.kernel.StateList()
+	if err != nil {
+		log.Printf("ipsec: failed to list the SAs: %v\n", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	byPeer := make(map[netip.Addr]*Tunnel, len(m.tunnels))
+	out := make([]Tunnel, 0, len(m.tunnels))
+	for peer, t := range m.tunnels {
+		byPeer[peer] = &Tunnel{
+			Peer:      peer.String(),
+			Source:    t.source,
+			OutSpi:    t.outSpi,
+			InSpis:    append([]uint32{}, t.inSpis...),
+			LastRekey: t.lastRekey,
+			Error:     t.err,
+		}
+	}
+	for i := range states {
+		s := &states[i]
+		if s.Reqid != reqid {
+			continue
+		}
+		k := keyOf(s)
+		if tunnel, ok := byPeer[k.dst]; ok {
+			tunnel.TxBytes += s.Statistics.Bytes
+			tunnel.TxPackets += s.Statistics.Packets
+		} else if tunnel, ok := byPeer[k.src]; ok {
+			tunnel.RxBytes += s.Statistics.Bytes
+			tunnel.RxPackets += s.Statistics.Packets
+			tunnel.ReplayErrors += uint64(s.Statistics.Replay)
+			tunnel.IntegrityErrors += uint64(s.Statistics.Failed)
+		}
+	}
+	for _, tunnel := range byPeer {
+		bytesGauge.WithLabelValues(tunnel.Peer, "tx").Set(float64(tunnel.TxBytes))
+		bytesGauge.WithLabelValues(tunnel.Peer, "rx").Set(float64(tunnel.RxBytes))
+		errorsGauge.WithLabelValues(tunnel.Peer, "replay").Set(float64(tunnel.ReplayErrors))
+		errorsGauge.WithLabelValues(tunnel.Peer, "integrity").Set(float64(tunnel.IntegrityErrors))
+		out = append(out, *tunnel)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return netip.MustParseAddr(out[i].Peer).Less(netip.MustParseAddr(out[j].Peer))
+	})
+	return out
+}
+
+// Run reconciles the tunnels at every interval until the context is done
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+	for {
+		m.reconcile(ctx)
+		// refreshes the metrics
+		m.Tunnels()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// running is the manager started with the bridge
+var running atomic.Pointer[Manager]
+
+// Start encrypts the VXLAN tunnels to the peers, both ends derive the keys of every rekey interval on their own,
+// their clocks are expected in sync within an interval
+func Start(ctx context.Context, cfg *config.Config) error {
+	ic := &cfg.Ipsec
+	if !ic.Enabled {
+		return nil
+	}
+	vtep := strings.Split(cfg.Underlay.VtepIP, "/")[0]
+	if vtep == "" {
+		vtep = utils.GetIPAddress(cfg.LinuxFrr.DefaultVtep).IP.String()
+	}
+	local, err := netip.ParseAddr(vtep)
+	if err != nil {
+		return fmt.Errorf("ipsec requires the address of the local vtep: %w", err)
+	}
+	var keys keySource
+	switch ic.KeySource {
+	case "", "psk":
+		keys, err = loadPsk(ic.PskFile)
+	case "pki":
+		keys, err = loadPki(ic.Cert, ic.Key, ic.Ca, ic.CertDir)
+	default:
+		err = fmt.Errorf("unknown ipsec keysource %s", ic.KeySource)
+	}
+	if err != nil {
+		return err
+	}
+	m, err := newManager(ic, local.Unmap(), keys, netlinkKernel{})
+	if err != nil {
+		return err
+	}
+	running.Store(m)
+	go m.Run(ctx)
+	log.Printf("ipsec: encrypting the VXLAN tunnels of %s, rekeyed every %v\n", local, m.rekey)
+	return nil
+}
+
+// Tunnels returns the encrypted tunnels, none when the encryption is disabled
+func Tunnels() []Tunnel {
+	if m := running.Load(); m != nil {
+		return m.Tunnels()
+	}
+	return []Tunnel{}
+}
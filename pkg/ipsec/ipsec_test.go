@@ -0,0 +1,332 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ipsec encrypts the VXLAN tunnels to the remote VTEPs with ESP
+package ipsec
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// fakeKernel holds the SAs and the policies in memory
+type fakeKernel struct {
+	states   map[saKey]netlink.XfrmState
+	policies map[string]netlink.XfrmPolicy
+}
+
+func newFakeKernel() *fakeKernel {
+	return &fakeKernel{states: make(map[saKey]netlink.XfrmState), policies: make(map[string]netlink.XfrmPolicy)}
+}
+
+func (k *fakeKernel) StateList() ([]netlink.XfrmState, error) {
+	out := make([]netlink.XfrmState, 0, len(k.states))
+	for _, s := range k.states {
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (k *fakeKernel) StateAdd(s *netlink.XfrmState) error {
+	k.states[keyOf(s)] = *s
+	return nil
+}
+
+func (k *fakeKernel) StateDel(s *netlink.XfrmState) error {
+	delete(k.states, keyOf(s))
+	return nil
+}
+
+func (k *fakeKernel) PolicyUpdate(p *netlink.XfrmPolicy) error {
+	k.policies[p.Dir.String()+p.Src.String()+p.Dst.String()] = *p
+	return nil
+}
+
+func (k *fakeKernel) PolicyDel(p *netlink.XfrmPolicy) error {
+	delete(k.policies, p.Dir.String()+p.Src.String()+p.Dst.String())
+	return nil
+}
+
+// newTestManager returns the manager of the tunnels of local to the peers sharing psk
+func newTestManager(t *testing.T, local string, peers []string, k kernel, now *time.Time) *Manager {
+	cfg := &config.IpsecConfig{Rekey: 60, Peers: peers, StateFile: filepath.Join(t.TempDir(), "ipsec.state")}
+	m, err := newManager(cfg, netip.MustParseAddr(local),
+		&pskSource{psk: []byte("0123456789abcdef")}, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.now = func() time.Time { return *now }
+	return m
+}
+
+func Test_DeriveSA(t *testing.T) {
+	a, b := netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2")
+	secret := []byte("0123456789abcdef")
+	spi, key, err := deriveSA(secret, a, b, 7, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != 36 {
+		t.Errorf("expected a key and a salt of 36 bytes, received %d", len(key))
+	}
+	if spi < 1<<31 {
+		t.Errorf("expected the spi out of the reserved range, received %08x", spi)
+	}
+	if spi2, key2, _ := deriveSA(secret, a, b, 7, 32); spi2 != spi || !bytes.Equal(key2, key) {
+		t.Errorf("expected the derivation to be deterministic")
+	}
+	if spi2, key2, _ := deriveSA(secret, b, a, 7, 32); spi2 == spi || bytes.Equal(key2, key) {
+		t.Errorf("expected each direction to have its own SA")
+	}
+	if spi2, key2, _ := deriveSA(secret, a, b, 8, 32); spi2 == spi || bytes.Equal(key2, key) {
+		t.Errorf("expected each rekey interval to have its own SA")
+	}
+}
+
+func Test_Reconcile(t *testing.T) {
+	now := time.Unix(6000, 0)
+	k := newFakeKernel()
+	m := newTestManager(t, "10.0.0.1", []string{"10.0.0.2"}, k, &now)
+	m.reconcile(context.Background())
+
+	// one outbound SA, three inbound SAs and the two policies
+	if len(k.states) != 4 || len(k.policies) != 2 {
+		t.Fatalf("expected 4 SAs and 2 policies, received %d and %d", len(k.states), len(k.policies))
+	}
+	tunnels := m.Tunnels()
+	if len(tunnels) != 1 || tunnels[0].Peer != "10.0.0.2" || tunnels[0].Source != SourceConfig || len(tunnels[0].InSpis) != 3 {
+		t.Fatalf("unexpected tunnels %+v", tunnels)
+	}
+	outSpi := tunnels[0].OutSpi
+
+	// the peer derives the same SAs the other way round
+	peer := newFakeKernel()
+	newTestManager(t, "10.0.0.2", []string{"10.0.0.1"}, peer, &now).reconcile(context.Background())
+	for key, s := range k.states {
+		if key.src == netip.MustParseAddr("10.0.0.1") {
+			if ps, ok := peer.states[key]; !ok || !bytes.Equal(ps.Aead.Key, s.Aead.Key) {
+				t.Errorf("expected the peer to accept the SA %08x", key.spi)
+			}
+		}
+	}
+
+	// the rekey replaces the outbound SA
+	now = now.Add(time.Minute)
+	m.reconcile(context.Background())
+	tunnels = m.Tunnels()
+	if len(k.states) != 4 || tunnels[0].OutSpi == outSpi || tunnels[0].LastRekey != now {
+		t.Errorf("expected the tunnel to be rekeyed, received %+v", tunnels[0])
+	}
+	if _, ok := k.states[saKey{src: netip.MustParseAddr("10.0.0.1"), dst: netip.MustParseAddr("10.0.0.2"), spi: outSpi}]; ok {
+		t.Errorf("expected the outbound SA of the previous interval to be removed")
+	}
+
+	// the SAs and the policies go with the peer
+	m.static = nil
+	m.reconcile(context.Background())
+	if len(k.states) != 0 || len(k.policies) != 0 || len(m.Tunnels()) != 0 {
+		t.Errorf("expected the tunnel to be removed, received %d SAs and %d policies", len(k.states), len(k.policies))
+	}
+}
+
+func Test_ReconcileRestart(t *testing.T) {
+	now := time.Unix(6000, 0)
+	k := newFakeKernel()
+	m := newTestManager(t, "10.0.0.1", []string{"10.0.0.2"}, k, &now)
+	m.reconcile(context.Background())
+	outSpi := m.Tunnels()[0].OutSpi
+
+	// a restart keeps the SAs in place
+	restarted, err := newManager(&config.IpsecConfig{Rekey: 60, Peers: []string{"10.0.0.2"}, StateFile: m.used.path}, m.local, m.keys, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted.now = m.now
+	restarted.reconcile(context.Background())
+	if tunnels := restarted.Tunnels(); tunnels[0].OutSpi != outSpi {
+		t.Errorf("expected the outbound SA in place to be kept, received %+v", tunnels[0])
+	}
+
+	// the outbound SA of the interval is not installed again once flushed, the tunnel moves to the next interval
+	flush := func() {
+		for key := range k.states {
+			delete(k.states, key)
+		}
+	}
+	flush()
+	restarted.reconcile(context.Background())
+	next, _, _ := deriveSA([]byte("0123456789abcdef"), m.local, m.static[0], now.Unix()/60+1, restarted.keyLen)
+	if tunnels := restarted.Tunnels(); tunnels[0].OutSpi != next || tunnels[0].Error != "" {
+		t.Errorf("expected the outbound SA of the next interval, received %+v", tunnels[0])
+	}
+
+	// and waits for the next interval when both were used
+	flush()
+	restarted.reconcile(context.Background())
+	if tunnels := restarted.Tunnels(); tunnels[0].Error == "" {
+		t.Errorf("expected the tunnel to wait for the next interval, received %+v", tunnels[0])
+	}
+	now = now.Add(2 * time.Minute)
+	restarted.reconcile(context.Background())
+	if tunnels := restarted.Tunnels(); tunnels[0].Error != "" || len(k.states) != 4 {
+		t.Errorf("expected the tunnel to be encrypted again, received %+v", tunnels[0])
+	}
+}
+
+func Test_ReconcileDiscover(t *testing.T) {
+	now := time.Unix(6000, 0)
+	k := newFakeKernel()
+	m := newTestManager(t, "10.0.0.1", nil, k, &now)
+	m.discover = true
+	m.evpnRoutes = func(context.Context) ([]routing.EvpnRoute, error) {
+		return []routing.EvpnRoute{
+			{RouteType: imetRoute, Nexthops: []string{"10.0.0.3", "10.0.0.1"}},
+			{RouteType: 2, Nexthops: []string{"10.0.0.4"}},
+			{RouteType: imetRoute, Nexthops: []string{"fd00::3"}},
+		}, nil
+	}
+	m.reconcile(context.Background())
+	tunnels := m.Tunnels()
+	if len(tunnels) != 2 || tunnels[0].Peer != "10.0.0.3" || tunnels[0].Source != SourceEvpn {
+		t.Fatalf("unexpected tunnels %+v", tunnels)
+	}
+	if tunnels[1].Error == "" {
+		t.Errorf("expected the IPv6 peer of an IPv4 vtep to fail, received %+v", tunnels[1])
+	}
+	if len(k.states) != 4 {
+		t.Errorf("expected the SAs of a single tunnel, received %d", len(k.states))
+	}
+}
+
+// writeCert writes the certificate of the key signed by the ca, self-signed without a ca
+func writeCert(t *testing.T, path string, key *ecdsa.PrivateKey, ca *x509.Certificate, caKey *ecdsa.PrivateKey) *x509.Certificate {
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: filepath.Base(path)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  ca == nil,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyAgreement | x509.KeyUsageCertSign,
+	}
+	if ca == nil {
+		ca, caKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// writeKey writes the key and returns it
+func writeKey(t *testing.T, path string) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func Test_PkiSecret(t *testing.T) {
+	dir := t.TempDir()
+	caKey := writeKey(t, filepath.Join(dir, "ca.key"))
+	ca := writeCert(t, filepath.Join(dir, "ca.pem"), caKey, nil, nil)
+	certDir := filepath.Join(dir, "peers")
+	if err := os.Mkdir(certDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range []string{"10.0.0.1", "10.0.0.2"} {
+		key := writeKey(t, filepath.Join(dir, node+".key"))
+		writeCert(t, filepath.Join(certDir, node+".pem"), key, ca, caKey)
+	}
+	// a certificate which is not signed by the ca
+	rogueKey := writeKey(t, filepath.Join(dir, "rogue.key"))
+	writeCert(t, filepath.Join(certDir, "10.0.0.3.pem"), rogueKey, nil, nil)
+
+	a, err := loadPki(filepath.Join(certDir, "10.0.0.1.pem"), filepath.Join(dir, "10.0.0.1.key"), filepath.Join(dir, "ca.pem"), certDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := loadPki(filepath.Join(certDir, "10.0.0.2.pem"), filepath.Join(dir, "10.0.0.2.key"), filepath.Join(dir, "ca.pem"), certDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab, err := a.secret(netip.MustParseAddr("10.0.0.2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ba, err := b.secret(netip.MustParseAddr("10.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ab, ba) {
+		t.Errorf("expected both ends to share the secret")
+	}
+	if _, err := a.secret(netip.MustParseAddr("10.0.0.3")); err == nil {
+		t.Errorf("expected the certificate which is not signed by the ca to be refused")
+	}
+	if _, err := a.secret(netip.MustParseAddr("10.0.0.4")); err == nil {
+		t.Errorf("expected the peer without a certificate to fail")
+	}
+}
+
+func Test_LoadPsk(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]struct {
+		content string
+		psk     []byte
+		err     bool
+	}{
+		"hex":   {content: "000102030405060708090a0b0c0d0e0f\n", psk: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}},
+		"raw":   {content: "a pre-shared key of the fabric", psk: []byte("a pre-shared key of the fabric")},
+		"short": {content: "secret", err: true},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			path := filepath.Join(dir, testName)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			s, err := loadPsk(path)
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error %v, received %v", tt.err, err)
+			}
+			if err == nil && !bytes.Equal(s.psk, tt.psk) {
+				t.Errorf("expected %x, received %x", tt.psk, s.psk)
+			}
+		})
+	}
+}
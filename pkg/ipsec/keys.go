@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ipsec encrypts the VXLAN tunnels to the remote VTEPs with ESP
+package ipsec
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// minPskLength is the shortest pre-shared key accepted, in bytes
+const minPskLength = 16
+
+// kdfLabel separates the keys of the tunnels from any other use of the secret
+var kdfLabel = []byte("opi-evpn-bridge ipsec")
+
+// keySource returns the secret shared with a peer
+type keySource interface {
+	secret(peer netip.Addr) ([]byte, error)
+}
+
+// pskSource shares the same pre-shared key with every peer
+type pskSource struct {
+	psk []byte
+}
+
+func (s *pskSource) secret(netip.Addr) ([]byte, error) {
+	return s.psk, nil
+}
+
+// loadPsk reads the pre-shared key from the file, hex encoded or raw
+func loadPsk(path string) (*pskSource, error) {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	raw = bytes.TrimSpace(raw)
+	psk, err := hex.DecodeString(string(raw))
+	if err != nil {
+		psk = raw
+	}
+	if len(psk) < minPskLength {
+		return nil, fmt.Errorf("the pre-shared key of %s is shorter than %d bytes", path, minPskLength)
+	}
+	return &pskSource{psk: psk}, nil
+}
+
+// pkiSource shares with a peer the ECDH of the key of the node with the public key of the certificate of the peer,
+// which the peer computes the other way round
+type pkiSource struct {
+	key   *ecdh.PrivateKey
+	roots *x509.CertPool
+	dir   string
+}
+
+// loadPki reads the certificate and the ECDSA key of the node and the certificate authority of the peers
+func loadPki(certFile, keyFile, caFile, dir string) (*pkiSource, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	priv, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the key of %s is not an ECDSA key", certFile)
+	}
+	key, err := priv.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	ca, err := os.ReadFile(filepath.Clean(caFile))
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificate in %s", caFile)
+	}
+	return &pkiSource{key: key, roots: roots, dir: dir}, nil
+}
+
+// secret verifies the certificate of the peer and computes the shared secret
+func (s *pkiSource) secret(peer netip.Addr) ([]byte, error) {
+	path := filepath.Join(s.dir, peer.String()+".pem")
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("no certificate in %s", path)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{Roots: s.roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+		return nil, fmt.Errorf("the certificate of %s is not trusted: %w", peer, err)
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("the certificate of " + peer.String() + " has no ECDSA key")
+	}
+	peerKey, err := pub.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	return s.key.ECDH(peerKey)
+}
+
+// deriveSA derives the spi and the key of the SA from src to dst in the rekey interval epoch, both ends of the
+// tunnel derive the same SA from the secret they share, the key is followed by the 4 bytes salt of the AEAD
+func deriveSA(secret []byte, src, dst netip.Addr, epoch int64, keyLen int) (spi uint32, key []byte, err error) {
+	info := append(append(src.AsSlice(), dst.AsSlice()...), binary.BigEndian.AppendUint64(nil, uint64(epoch))...)
+	out := make([]byte, 4+keyLen+4)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, kdfLabel, info), out); err != nil {
+		return 0, nil, err
+	}
+	// the spis below 256 are reserved
+	spi = binary.BigEndian.Uint32(out) | 1<<31
+	return spi, out[4:], nil
+}
@@ -0,0 +1,340 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package lldp discovers the neighbors of the uplinks with an in-process LLDP (IEEE 802.1AB) agent
+// and checks that the uplinks are cabled to the expected top of rack switches
+package lldp
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+const (
+	// defaultTxInterval is the interval of the announcements when the config leaves it out
+	defaultTxInterval = 30 * time.Second
+	// txHold is how many intervals the neighbors hold the announcements
+	txHold = 4
+	// systemDescription is the system description of the announcements
+	systemDescription = "opi-evpn-bridge"
+	// headerLen is the length of the ethernet header of the LLDP frames
+	headerLen = 14
+)
+
+// Cabling is the check of the cabling of an uplink
+type Cabling struct {
+	config.LldpExpectationConfig
+	Cabled bool
+	// Reason tells why the uplink is not cabled as expected
+	Reason string
+}
+
+// Agent keeps the neighbors discovered on the interfaces
+type Agent struct {
+	mu sync.Mutex
+	// neighbors are indexed by interface, then by chassis and port id
+	neighbors map[string]map[string]*Neighbor
+	expected  []config.LldpExpectationConfig
+	enforce   bool
+	now       func() time.Time
+}
+
+// newAgent returns the agent checking the cabling against the expectations
+func newAgent(expected []config.LldpExpectationConfig, enforce bool) *Agent {
+	return &Agent{
+		neighbors: make(map[string]map[string]*Neighbor),
+		expected:  expected,
+		enforce:   enforce,
+		now:       time.Now,
+	}
+}
+
+// learn records the neighbor announced on the interface, a zero TTL is the neighbor leaving
+func (a *Agent) learn(n *Neighbor) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	byKey, ok := a.neighbors[n.Interface]
+	if !ok {
+		byKey = make(map[string]*Neighbor)
+		a.neighbors[n.Interface] = byKey
+	}
+	if n.TTL == 0 {
+		delete(byKey, n.key())
+		return
+	}
+	byKey[n.key()] = n
+}
+
+// current returns the neighbors of the interface which did not expire, the caller must hold the lock
+func (a *Agent) current(iface string, now time.Time) []*Neighbor {
+	var out []*Neighbor
+	for key, n := range a.neighbors[iface] {
+		if n.Expired(now) {
+			delete(a.neighbors[iface], key)
+			continue
+		}
+		out = append(out, n)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].key() < out[j].key() })
+	return out
+}
+
+// Neighbors returns the neighbors which did not expire, by interface
+func (a *Agent) Neighbors() []Neighbor {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ifaces := make([]string, 0, len(a.neighbors))
+	for iface := range a.neighbors {
+		ifaces = append(ifaces, iface)
+	}
+	sort.Strings(ifaces)
+	now := a.now()
+	out := []Neighbor{}
+	for _, iface := range ifaces {
+		for _, n := range a.current(iface, now) {
+			out = append(out, *n)
+		}
+	}
+	return out
+}
+
+// matches tells whether the neighbor is the expected switch port
+func matches(e *config.LldpExpectationConfig, n *Neighbor) bool {
+	return (e.SystemName == "" || strings.EqualFold(e.SystemName, n.SystemName)) &&
+		(e.PortID == "" || e.PortID == n.PortID || e.PortID == n.PortDescription)
+}
+
+// Cabling checks the cabling of the uplinks against the neighbors they discovered
+func (a *Agent) Cabling() []Cabling {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	out := make([]Cabling, 0, len(a.expected))
+	for i := range a.expected {
+		e := &a.expected[i]
+		c := Cabling{LldpExpectationConfig: *e}
+		neighbors := a.current(e.Interface, now)
+		var seen []string
+		for _, n := range neighbors {
+			if matches(e, n) {
+				c.Cabled = true
+				break
+			}
+			seen = append(seen, fmt.Sprintf("%s port %s", n.SystemName, n.PortID))
+		}
+		switch {
+		case c.Cabled:
+		case len(neighbors) == 0:
+			c.Reason = fmt.Sprintf("no LLDP neighbor on %s", e.Interface)
+		default:
+			c.Reason = fmt.Sprintf("%s is cabled to %s, not to %s port %s", e.Interface, strings.Join(seen, ", "), e.SystemName, e.PortID)
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// check returns the miscabled uplinks
+func (a *Agent) check() error {
+	var reasons []string
+	for _, c := range a.Cabling() {
+		if !c.Cabled {
+			reasons = append(reasons, c.Reason)
+		}
+	}
+	if len(reasons) != 0 {
+		return fmt.Errorf("lldp: %s", strings.Join(reasons, "; "))
+	}
+	return nil
+}
+
+// htons converts the ethertype to the byte order of the packet sockets
+func htons(v uint16) uint16 {
+	return binary.NativeEndian.Uint16(binary.BigEndian.AppendUint16(nil, v))
+}
+
+// serve announces the node and learns the neighbors on the interface until the context is done
+// or the socket fails
+func (a *Agent) serve(ctx context.Context, dev string, announce Announcement, interval time.Duration) error {
+	iface, err := net.InterfaceByName(dev)
+	if err != nil {
+		return err
+	}
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, int(htons(EtherType)))
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+	if err := unix.Bind(fd, &unix.SockaddrLinklayer{Protocol: htons(EtherType), Ifindex: iface.Index}); err != nil {
+		return err
+	}
+	mreq := unix.PacketMreq{Ifindex: int32(iface.Index), Type: unix.PACKET_MR_MULTICAST, Alen: uint16(len(NearestBridge))}
+	copy(mreq.Address[:], NearestBridge)
+	if err := unix.SetsockoptPacketMreq(fd, unix.SOL_PACKET, unix.PACKET_ADD_MEMBERSHIP, &mreq); err != nil {
+		return err
+	}
+	// the reads return regularly to send the announcements and notice the end of the context
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &unix.Timeval{Sec: 1}); err != nil {
+		return err
+	}
+
+	to := &unix.SockaddrLinklayer{Protocol: htons(EtherType), Ifindex: iface.Index, Halen: uint8(len(NearestBridge))}
+	copy(to.Addr[:], NearestBridge)
+	announce.PortID = dev
+	send := func(ttl time.Duration) {
+		announce.TTL = ttl
+		if err := unix.Sendto(fd, Frame(iface.HardwareAddr, &announce), 0, to); err != nil {
+			log.Printf("lldp: failed to announce the node on %s: %v\n", dev, err)
+		}
+	}
+	// the neighbors forget the node as it leaves
+	defer send(0)
+
+	buf := make([]byte, 1600)
+	next := time.Now()
+	for ctx.Err() == nil {
+		if now := time.Now(); !now.Before(next) {
+			send(txHold * interval)
+			next = now.Add(interval)
+		}
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if n < headerLen || binary.BigEndian.Uint16(buf[12:]) != EtherType {
+			continue
+		}
+		neighbor, err := Parse(buf[headerLen:n])
+		if err != nil {
+			log.Printf("lldp: invalid LLDPDU on %s: %v\n", dev, err)
+			continue
+		}
+		neighbor.Interface = dev
+		neighbor.LastSeen = time.Now()
+		a.learn(neighbor)
+	}
+	return nil
+}
+
+// run serves the interface, again after a failure, until the context is done
+func (a *Agent) run(ctx context.Context, dev string, announce Announcement, interval time.Duration) {
+	for {
+		err := a.serve(ctx, dev, announce, interval)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("lldp: %s: %v\n", dev, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// running is the agent started with the bridge
+var running atomic.Pointer[Agent]
+
+// Start runs the LLDP agent on the uplinks, the underlay uplinks when the interfaces are left out
+func Start(ctx context.Context, cfg *config.Config) error {
+	if !cfg.Lldp.Enabled {
+		return nil
+	}
+	ifaces := cfg.Lldp.Interfaces
+	if len(ifaces) == 0 {
+		for _, uplink := range cfg.Underlay.Uplinks {
+			ifaces = append(ifaces, uplink.Name)
+		}
+	}
+	if len(ifaces) == 0 {
+		return errors.New("lldp: no interface to run on, set lldp.interfaces or the underlay uplinks")
+	}
+	known := map[string]bool{}
+	for _, iface := range ifaces {
+		known[iface] = true
+	}
+	for _, e := range cfg.Lldp.Expected {
+		if !known[e.Interface] {
+			return fmt.Errorf("lldp: the expected cabling of %s is not checked as lldp does not run on it", e.Interface)
+		}
+	}
+	if cfg.Lldp.Enforce && len(cfg.Lldp.Expected) == 0 {
+		return errors.New("lldp: enforce requires the expected cabling of the uplinks")
+	}
+	interval := defaultTxInterval
+	if cfg.Lldp.TxInterval > 0 {
+		interval = time.Duration(cfg.Lldp.TxInterval) * time.Second
+	}
+
+	announce := Announcement{SystemDescription: systemDescription}
+	announce.SystemName, _ = os.Hostname()
+	// the chassis is identified by the mac address of its first uplink
+	if iface, err := net.InterfaceByName(ifaces[0]); err == nil {
+		announce.ChassisID = iface.HardwareAddr
+	}
+	if vtep := net.ParseIP(strings.Split(cfg.Underlay.VtepIP, "/")[0]); vtep != nil {
+		announce.ManagementAddress = vtep
+	}
+
+	a := newAgent(cfg.Lldp.Expected, cfg.Lldp.Enforce)
+	running.Store(a)
+	for _, iface := range ifaces {
+		go a.run(ctx, iface, announce, interval)
+	}
+	log.Printf("lldp: agent running on %s\n", strings.Join(ifaces, ", "))
+	return nil
+}
+
+// Neighbors returns the neighbors discovered by the agent, none when it does not run
+func Neighbors() []Neighbor {
+	if a := running.Load(); a != nil {
+		return a.Neighbors()
+	}
+	return []Neighbor{}
+}
+
+// CablingStatus returns the check of the cabling of the uplinks, none when the agent does not run
+func CablingStatus() []Cabling {
+	if a := running.Load(); a != nil {
+		return a.Cabling()
+	}
+	return []Cabling{}
+}
+
+// Probe fails while an uplink is not cabled as expected
+func Probe(context.Context) error {
+	if a := running.Load(); a != nil {
+		return a.check()
+	}
+	return nil
+}
+
+// Admitted tells whether the tenant traffic is admitted, it is held while the cabling is enforced
+// and an uplink is not cabled as expected
+func Admitted() error {
+	if a := running.Load(); a != nil && a.enforce {
+		return a.check()
+	}
+	return nil
+}
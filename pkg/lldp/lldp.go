@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package lldp discovers the neighbors of the uplinks with an in-process LLDP (IEEE 802.1AB) agent
+// and checks that the uplinks are cabled to the expected top of rack switches
+package lldp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// EtherType is the ethertype of the LLDP frames
+const EtherType = 0x88cc
+
+// NearestBridge is the destination of the LLDP frames, which are not forwarded by the bridges
+var NearestBridge = net.HardwareAddr{0x01, 0x80, 0xc2, 0x00, 0x00, 0x0e}
+
+// the TLV types of the LLDPDU
+const (
+	tlvEnd               = 0
+	tlvChassisID         = 1
+	tlvPortID            = 2
+	tlvTTL               = 3
+	tlvPortDescription   = 4
+	tlvSystemName        = 5
+	tlvSystemDescription = 6
+	tlvManagementAddress = 8
+	tlvOrganization      = 127
+)
+
+// the subtypes of the chassis and port ids
+const (
+	chassisIDMacAddress     = 4
+	chassisIDNetworkAddress = 5
+	portIDMacAddress        = 3
+	portIDNetworkAddress    = 4
+	portIDInterfaceName     = 5
+)
+
+// the IEEE 802.1 organizationally specific TLVs
+var ieee8021 = [3]byte{0x00, 0x80, 0xc2}
+
+const (
+	ieee8021PortVlanID = 1
+	ieee8021VlanName   = 3
+)
+
+// Vlan is a VLAN announced by a neighbor on its port
+type Vlan struct {
+	ID   uint16
+	Name string
+}
+
+// Neighbor is what a neighbor announces of itself on an interface
+type Neighbor struct {
+	Interface         string
+	ChassisID         string
+	PortID            string
+	PortDescription   string
+	SystemName        string
+	SystemDescription string
+	ManagementAddress net.IP
+	// PortVlanID is the untagged VLAN of the port of the neighbor, 0 when not announced
+	PortVlanID uint16
+	Vlans      []Vlan
+	TTL        time.Duration
+	LastSeen   time.Time
+}
+
+// Expired tells whether the neighbor did not announce itself again within its TTL
+func (n *Neighbor) Expired(now time.Time) bool {
+	return now.After(n.LastSeen.Add(n.TTL))
+}
+
+// key identifies the neighbor on the interface
+func (n *Neighbor) key() string {
+	return n.ChassisID + "/" + n.PortID
+}
+
+// errTruncated is returned for the LLDPDUs whose TLVs overrun the frame
+var errTruncated = errors.New("lldp: truncated LLDPDU")
+
+// formatID formats a chassis or port id after its subtype, the other subtypes are text
+func formatID(value []byte, macSubtype, addressSubtype byte) string {
+	if len(value) < 1 {
+		return ""
+	}
+	subtype, id := value[0], value[1:]
+	switch {
+	case subtype == macSubtype && len(id) == 6:
+		return net.HardwareAddr(id).String()
+	case subtype == addressSubtype && len(id) > 1:
+		// the address starts with its IANA family
+		if ip := net.IP(id[1:]); len(ip) == net.IPv4len || len(ip) == net.IPv6len {
+			return ip.String()
+		}
+	}
+	return string(id)
+}
+
+// parseOrganization reads the port VLAN and the VLAN names of the IEEE 802.1 TLVs
+func parseOrganization(n *Neighbor, value []byte) {
+	if len(value) < 4 || [3]byte(value[:3]) != ieee8021 {
+		return
+	}
+	body := value[4:]
+	switch value[3] {
+	case ieee8021PortVlanID:
+		if len(body) >= 2 {
+			n.PortVlanID = binary.BigEndian.Uint16(body)
+		}
+	case ieee8021VlanName:
+		if len(body) >= 3 && len(body) >= 3+int(body[2]) {
+			n.Vlans = append(n.Vlans, Vlan{ID: binary.BigEndian.Uint16(body), Name: string(body[3 : 3+int(body[2])])})
+		}
+	}
+}
+
+// Parse reads the neighbor from the LLDPDU, the payload of an LLDP frame
+func Parse(pdu []byte) (*Neighbor, error) {
+	n := &Neighbor{}
+	seen := 0
+	for len(pdu) > 0 {
+		if len(pdu) < 2 {
+			return nil, errTruncated
+		}
+		header := binary.BigEndian.Uint16(pdu)
+		typ, length := header>>9, int(header&0x1ff)
+		if len(pdu) < 2+length {
+			return nil, errTruncated
+		}
+		value := pdu[2 : 2+length]
+		pdu = pdu[2+length:]
+		switch typ {
+		case tlvEnd:
+			pdu = nil
+		case tlvChassisID:
+			n.ChassisID = formatID(value, chassisIDMacAddress, chassisIDNetworkAddress)
+			seen++
+		case tlvPortID:
+			n.PortID = formatID(value, portIDMacAddress, portIDNetworkAddress)
+			seen++
+		case tlvTTL:
+			if length < 2 {
+				return nil, errTruncated
+			}
+			n.TTL = time.Duration(binary.BigEndian.Uint16(value)) * time.Second
+			seen++
+		case tlvPortDescription:
+			n.PortDescription = string(value)
+		case tlvSystemName:
+			n.SystemName = string(value)
+		case tlvSystemDescription:
+			n.SystemDescription = string(value)
+		case tlvManagementAddress:
+			if length >= 2 && int(value[0]) <= length-1 {
+				if ip := net.IP(value[2 : 1+int(value[0])]); len(ip) == net.IPv4len || len(ip) == net.IPv6len {
+					n.ManagementAddress = ip
+				}
+			}
+		case tlvOrganization:
+			parseOrganization(n, value)
+		}
+	}
+	if seen != 3 {
+		return nil, fmt.Errorf("lldp: LLDPDU without chassis id, port id or ttl")
+	}
+	return n, nil
+}
+
+// Announcement is what the agent announces of the node on an interface
+type Announcement struct {
+	ChassisID         net.HardwareAddr
+	PortID            string
+	SystemName        string
+	SystemDescription string
+	ManagementAddress net.IP
+	TTL               time.Duration
+}
+
+// appendTLV appends a TLV to the LLDPDU
+func appendTLV(b []byte, typ int, value ...[]byte) []byte {
+	length := 0
+	for _, v := range value {
+		length += len(v)
+	}
+	b = binary.BigEndian.AppendUint16(b, uint16(typ<<9|length))
+	for _, v := range value {
+		b = append(b, v...)
+	}
+	return b
+}
+
+// Frame encodes the LLDP frame of the announcement sent from the mac address
+func Frame(src net.HardwareAddr, a *Announcement) []byte {
+	b := make([]byte, 0, 128)
+	b = append(b, NearestBridge...)
+	b = append(b, src...)
+	b = binary.BigEndian.AppendUint16(b, EtherType)
+	b = appendTLV(b, tlvChassisID, []byte{chassisIDMacAddress}, a.ChassisID)
+	b = appendTLV(b, tlvPortID, []byte{portIDInterfaceName}, []byte(a.PortID))
+	b = appendTLV(b, tlvTTL, binary.BigEndian.AppendUint16(nil, uint16(a.TTL/time.Second)))
+	if a.SystemName != "" {
+		b = appendTLV(b, tlvSystemName, []byte(a.SystemName))
+	}
+	if a.SystemDescription != "" {
+		b = appendTLV(b, tlvSystemDescription, []byte(a.SystemDescription))
+	}
+	if a.ManagementAddress != nil {
+		addr, family := a.ManagementAddress.To4(), byte(1)
+		if addr == nil {
+			addr, family = a.ManagementAddress.To16(), 2
+		}
+		// no interface numbering and no object identifier
+		b = appendTLV(b, tlvManagementAddress, []byte{byte(1 + len(addr)), family}, addr, []byte{1, 0, 0, 0, 0, 0})
+	}
+	return appendTLV(b, tlvEnd)
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package lldp discovers the neighbors of the uplinks with an in-process LLDP (IEEE 802.1AB) agent
+// and checks that the uplinks are cabled to the expected top of rack switches
+package lldp
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+func Test_FrameParse(t *testing.T) {
+	src := net.HardwareAddr{0xaa, 0xbb, 0xcc, 0, 0, 1}
+	frame := Frame(src, &Announcement{
+		ChassisID:         src,
+		PortID:            "eth0",
+		SystemName:        "dpu-7",
+		SystemDescription: systemDescription,
+		ManagementAddress: net.ParseIP("10.0.0.7"),
+		TTL:               120 * time.Second,
+	})
+	if !bytes.Equal(frame[:6], NearestBridge) || !bytes.Equal(frame[6:12], src) || frame[12] != 0x88 || frame[13] != 0xcc {
+		t.Fatalf("invalid ethernet header % x", frame[:headerLen])
+	}
+	n, err := Parse(frame[headerLen:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.ChassisID != src.String() || n.PortID != "eth0" || n.SystemName != "dpu-7" || n.TTL != 120*time.Second {
+		t.Errorf("unexpected neighbor %+v", n)
+	}
+	if !n.ManagementAddress.Equal(net.ParseIP("10.0.0.7")) || n.SystemDescription != systemDescription {
+		t.Errorf("unexpected management address %v or description %q", n.ManagementAddress, n.SystemDescription)
+	}
+}
+
+func Test_ParseVlans(t *testing.T) {
+	pdu := appendTLV(nil, tlvChassisID, []byte{7}, []byte("tor-1"))
+	pdu = appendTLV(pdu, tlvPortID, []byte{portIDInterfaceName}, []byte("Ethernet12"))
+	pdu = appendTLV(pdu, tlvTTL, []byte{0, 120})
+	pdu = appendTLV(pdu, tlvOrganization, ieee8021[:], []byte{ieee8021PortVlanID, 0, 10})
+	pdu = appendTLV(pdu, tlvOrganization, ieee8021[:], []byte{ieee8021VlanName, 0, 20, 4}, []byte("blue"))
+	// the TLVs of the other organizations are skipped
+	pdu = appendTLV(pdu, tlvOrganization, []byte{0x00, 0x12, 0x0f, 1, 3})
+	pdu = appendTLV(pdu, tlvEnd)
+
+	n, err := Parse(pdu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.ChassisID != "tor-1" || n.PortID != "Ethernet12" || n.PortVlanID != 10 {
+		t.Errorf("unexpected neighbor %+v", n)
+	}
+	if len(n.Vlans) != 1 || n.Vlans[0] != (Vlan{ID: 20, Name: "blue"}) {
+		t.Errorf("unexpected vlans %+v", n.Vlans)
+	}
+
+	if _, err := Parse(pdu[:len(pdu)-5]); err == nil {
+		t.Errorf("expected a truncated LLDPDU to be rejected")
+	}
+	if _, err := Parse(appendTLV(nil, tlvTTL, []byte{0, 120})); err == nil {
+		t.Errorf("expected an LLDPDU without chassis and port ids to be rejected")
+	}
+}
+
+func Test_Cabling(t *testing.T) {
+	now := time.Now()
+	a := newAgent([]config.LldpExpectationConfig{
+		{Interface: "eth0", SystemName: "tor-1", PortID: "Ethernet12"},
+		{Interface: "eth1", SystemName: "tor-2", PortID: "Ethernet12"},
+		{Interface: "eth2", SystemName: "tor-3"},
+	}, true)
+	a.now = func() time.Time { return now }
+
+	a.learn(&Neighbor{Interface: "eth0", ChassisID: "c1", PortID: "Ethernet12", SystemName: "TOR-1", TTL: time.Minute, LastSeen: now})
+	// eth1 and eth2 are swapped
+	a.learn(&Neighbor{Interface: "eth1", ChassisID: "c3", PortID: "Ethernet4", SystemName: "tor-3", TTL: time.Minute, LastSeen: now})
+	a.learn(&Neighbor{Interface: "eth2", ChassisID: "c2", PortID: "Ethernet12", SystemName: "tor-2", TTL: time.Minute, LastSeen: now})
+
+	cabling := a.Cabling()
+	if !cabling[0].Cabled || cabling[1].Cabled || cabling[2].Cabled {
+		t.Fatalf("unexpected cabling %+v", cabling)
+	}
+	if !strings.Contains(cabling[1].Reason, "tor-3 port Ethernet4") {
+		t.Errorf("expected the reason to name the neighbor, received %q", cabling[1].Reason)
+	}
+	if err := a.check(); err == nil {
+		t.Errorf("expected the miscabled uplinks to fail the check")
+	}
+
+	// the neighbors expire after their TTL, the neighbor of eth0 leaves with a zero TTL
+	a.learn(&Neighbor{Interface: "eth0", ChassisID: "c1", PortID: "Ethernet12"})
+	now = now.Add(2 * time.Minute)
+	if neighbors := a.Neighbors(); len(neighbors) != 0 {
+		t.Errorf("expected the neighbors to expire, received %+v", neighbors)
+	}
+	if c := a.Cabling(); c[0].Cabled || !strings.Contains(c[0].Reason, "no LLDP neighbor") {
+		t.Errorf("unexpected cabling of eth0 %+v", c[0])
+	}
+}
+
+func Test_Start(t *testing.T) {
+	tests := map[string]struct {
+		lldp config.LldpConfig
+		err  bool
+	}{
+		"disabled": {
+			lldp: config.LldpConfig{Enforce: true},
+		},
+		"no interface": {
+			lldp: config.LldpConfig{Enabled: true},
+			err:  true,
+		},
+		"expectation of another interface": {
+			lldp: config.LldpConfig{Enabled: true, Interfaces: []string{"eth0"}, Expected: []config.LldpExpectationConfig{{Interface: "eth1"}}},
+			err:  true,
+		},
+		"enforce without expectations": {
+			lldp: config.LldpConfig{Enabled: true, Interfaces: []string{"eth0"}, Enforce: true},
+			err:  true,
+		},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			cfg := &config.Config{Lldp: tt.lldp}
+			if err := Start(context.Background(), cfg); (err != nil) != tt.err {
+				t.Errorf("expected error %v, received %v", tt.err, err)
+			}
+		})
+	}
+}
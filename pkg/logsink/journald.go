@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package logsink ships the log of the bridge to syslog or journald, for the DPU images which do not collect stdout
+package logsink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultJournalSocket is the socket of the native protocol of journald
+const defaultJournalSocket = "/run/systemd/journal/socket"
+
+// journaldSink sends the entries with the native protocol of journald, as structured fields
+type journaldSink struct {
+	mu     sync.Mutex
+	socket string
+	tag    string
+	conn   *net.UnixConn
+}
+
+// newJournaldSink returns the sink of journald, listening on its default socket when the address is empty
+func newJournaldSink(socket, tag string) (Sink, error) {
+	if socket == "" {
+		socket = defaultJournalSocket
+	}
+	return &journaldSink{socket: socket, tag: tag}, nil
+}
+
+// appendField appends a field of the native protocol, a value holding a new line is sent with its length
+func appendField(buf *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(name + "=" + value + "\n")
+		return
+	}
+	buf.WriteString(name + "\n")
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value + "\n")
+}
+
+// encode renders the entry as a datagram of the native protocol
+func (s *journaldSink) encode(e Entry) []byte {
+	buf := &bytes.Buffer{}
+	appendField(buf, "MESSAGE", strings.TrimRight(e.Message, "\n"))
+	appendField(buf, "PRIORITY", strconv.Itoa(int(e.Priority)))
+	appendField(buf, "SYSLOG_IDENTIFIER", s.tag)
+	return buf.Bytes()
+}
+
+// Send sends the entry, connecting to the socket on the first entry
+func (s *journaldSink) Send(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: s.socket, Net: "unixgram"})
+		if err != nil {
+			return fmt.Errorf("journald %s: %w", s.socket, err)
+		}
+		s.conn = conn
+	}
+	if _, err := s.conn.Write(s.encode(e)); err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		return fmt.Errorf("journald %s: %w", s.socket, err)
+	}
+	return nil
+}
+
+// Close closes the socket
+func (s *journaldSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,211 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package logsink ships the log of the bridge to syslog or journald, for the DPU images which do not collect stdout
+package logsink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+// Priority is the syslog severity of an entry
+type Priority int
+
+// the severities of RFC5424, also the PRIORITY of journald
+const (
+	PriorityEmerg Priority = iota
+	PriorityAlert
+	PriorityCrit
+	PriorityErr
+	PriorityWarning
+	PriorityNotice
+	PriorityInfo
+	PriorityDebug
+)
+
+// Entry is a log line with its severity
+type Entry struct {
+	Time     time.Time
+	Priority Priority
+	Message  string
+}
+
+// Sink sends the entries to a log collector
+type Sink interface {
+	Send(e Entry) error
+	Close() error
+}
+
+// levelPrefixes are the prefixes of the messages of the gRPC logging interceptor
+var levelPrefixes = []struct {
+	prefix   string
+	priority Priority
+}{
+	{"DEBUG :", PriorityDebug},
+	{"INFO :", PriorityInfo},
+	{"WARN :", PriorityWarning},
+	{"ERROR :", PriorityErr},
+}
+
+// errorWords mark the plain log lines reporting an error
+var errorWords = []string{"error", "failed", "panic", "cannot"}
+
+// classify returns the severity of a message: the level of the interceptor messages, err for the
+// lines reporting an error and info for the others
+func classify(message string) Priority {
+	trimmed := strings.TrimPrefix(message, "msg ")
+	trimmed = strings.TrimPrefix(trimmed, "[msg ")
+	for _, level := range levelPrefixes {
+		if strings.HasPrefix(trimmed, level.prefix) {
+			return level.priority
+		}
+	}
+	lower := strings.ToLower(message)
+	if strings.HasPrefix(lower, "warn") {
+		return PriorityWarning
+	}
+	for _, word := range errorWords {
+		if strings.Contains(lower, word) {
+			return PriorityErr
+		}
+	}
+	return PriorityInfo
+}
+
+// stdPrefixLen is the length of the date and time the standard logger puts before the message
+const stdPrefixLen = len("2006/01/02 15:04:05 ")
+
+// parseLine splits a line of the standard logger into the time and the message
+func parseLine(line string) (time.Time, string) {
+	line = strings.TrimRight(line, "\n")
+	if len(line) >= stdPrefixLen {
+		if t, err := time.ParseInLocation("2006/01/02 15:04:05", line[:stdPrefixLen-1], time.Local); err == nil {
+			return t, line[stdPrefixLen:]
+		}
+	}
+	return time.Now(), line
+}
+
+// limiter is a token bucket refilled with rate tokens per second up to burst
+type limiter struct {
+	rate, burst float64
+	tokens      float64
+	last        time.Time
+}
+
+// allow takes a token at the time, it never refuses when the rate is zero
+func (l *limiter) allow(now time.Time) bool {
+	if l.rate <= 0 {
+		return true
+	}
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Writer is the output of the standard logger sending each line to the sink, the lines above the rate
+// are dropped and counted, the count is sent once the rate allows it again
+type Writer struct {
+	mu         sync.Mutex
+	sink       Sink
+	limiter    limiter
+	minimum    Priority
+	suppressed int
+	now        func() time.Time
+}
+
+// NewWriter returns the writer of the sink, rate lines per second with bursts of burst lines, the entries
+// less severe than minimum are dropped
+func NewWriter(sink Sink, rate, burst int, minimum Priority) *Writer {
+	burst = max(burst, rate)
+	return &Writer{
+		sink:    sink,
+		limiter: limiter{rate: float64(rate), burst: float64(burst), tokens: float64(burst)},
+		minimum: minimum,
+		now:     time.Now,
+	}
+}
+
+// Write sends the line to the sink, it never fails so that the other outputs of the logger keep the line
+func (w *Writer) Write(p []byte) (int, error) {
+	t, message := parseLine(string(p))
+	entry := Entry{Time: t, Priority: classify(message), Message: message}
+	if entry.Priority > w.minimum {
+		return len(p), nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.limiter.allow(w.now()) {
+		w.suppressed++
+		return len(p), nil
+	}
+	if w.suppressed > 0 {
+		notice := Entry{Time: t, Priority: PriorityNotice, Message: fmt.Sprintf("%d log messages suppressed by the rate limit", w.suppressed)}
+		if err := w.sink.Send(notice); err == nil {
+			w.suppressed = 0
+		}
+	}
+	if err := w.sink.Send(entry); err != nil {
+		// the sink cannot log its own failure without looping
+		fmt.Fprintf(os.Stderr, "logsink: %v\n", err)
+	}
+	return len(p), nil
+}
+
+// Close closes the sink
+func (w *Writer) Close() error {
+	return w.sink.Close()
+}
+
+// priorities are the names of the minimum severities of the config
+var priorities = map[string]Priority{
+	"":        PriorityDebug,
+	"debug":   PriorityDebug,
+	"info":    PriorityInfo,
+	"notice":  PriorityNotice,
+	"warning": PriorityWarning,
+	"err":     PriorityErr,
+}
+
+// New returns the writer of the configured sink, nil when no sink is configured
+func New(cfg *config.LogSinkConfig) (*Writer, error) {
+	minimum, ok := priorities[cfg.Priority]
+	if !ok {
+		return nil, fmt.Errorf("unknown log priority %s", cfg.Priority)
+	}
+	tag := cfg.Tag
+	if tag == "" {
+		tag = filepath.Base(os.Args[0])
+	}
+	var sink Sink
+	var err error
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case "syslog":
+		sink, err = newSyslogSink(cfg.Network, cfg.Address, cfg.Facility, tag)
+	case "journald":
+		sink, err = newJournaldSink(cfg.Address, tag)
+	default:
+		err = fmt.Errorf("unknown log sink %s, expected syslog or journald", cfg.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewWriter(sink, cfg.RateLimit, cfg.Burst, minimum), nil
+}
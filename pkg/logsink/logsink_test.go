@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package logsink ships the log of the bridge to syslog or journald, for the DPU images which do not collect stdout
+package logsink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+)
+
+// recordingSink keeps the entries it is sent
+type recordingSink struct{ entries []Entry }
+
+func (s *recordingSink) Send(e Entry) error { s.entries = append(s.entries, e); return nil }
+func (s *recordingSink) Close() error       { return nil }
+
+func Test_Classify(t *testing.T) {
+	tests := map[string]Priority{
+		"[msg INFO :started call grpc.service opi_api.network.evpn_gw.v1alpha1.VrfService]": PriorityInfo,
+		"[msg WARN :finished call grpc.code NotFound]":                                      PriorityWarning,
+		"[msg ERROR :finished call grpc.code Internal]":                                     PriorityErr,
+		"LGM: Failed to write the kernel settings of br-tenant":                             PriorityErr,
+		"Warning: the uplink eth1 has no carrier":                                           PriorityWarning,
+		"LGM Executed : ip link set br-tenant up":                                           PriorityInfo,
+	}
+	for message, expected := range tests {
+		if p := classify(message); p != expected {
+			t.Errorf("expected %q to be of priority %d, received %d", message, expected, p)
+		}
+	}
+}
+
+func Test_WriterRateLimit(t *testing.T) {
+	sink := &recordingSink{}
+	w := NewWriter(sink, 1, 2, PriorityInfo)
+	now := time.Date(2023, 10, 17, 9, 30, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		_, _ = w.Write([]byte("2023/10/17 09:30:00 LGM Executed : ip link set br-tenant up\n"))
+	}
+	_, _ = w.Write([]byte("[msg DEBUG :dropped below the minimum priority]\n"))
+	if len(sink.entries) != 2 || w.suppressed != 3 {
+		t.Fatalf("expected a burst of 2 entries and 3 suppressed, received %d and %d", len(sink.entries), w.suppressed)
+	}
+	if sink.entries[0].Message != "LGM Executed : ip link set br-tenant up" || sink.entries[0].Time.Hour() != 9 {
+		t.Errorf("expected the time to be split from the message, received %+v", sink.entries[0])
+	}
+
+	now = now.Add(time.Second)
+	_, _ = w.Write([]byte("2023/10/17 09:30:01 CreateVrf(): Error in creating the VRF\n"))
+	if len(sink.entries) != 4 || sink.entries[2].Priority != PriorityNotice || !strings.HasPrefix(sink.entries[2].Message, "3 log messages suppressed") {
+		t.Fatalf("expected the count of the suppressed entries before the next entry, received %+v", sink.entries)
+	}
+	if sink.entries[3].Priority != PriorityErr {
+		t.Errorf("expected the error to be of priority err, received %d", sink.entries[3].Priority)
+	}
+}
+
+func Test_SyslogSink(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- string(data)
+	}()
+
+	w, err := New(&config.LogSinkConfig{Type: "syslog", Network: "tcp", Address: lis.Addr().String(), Facility: "local0", Tag: "opi-evpn-bridge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("2023/10/17 09:30:00 CreateVrf(): Error in creating the VRF\n"))
+	// local0 is 16, err is 3: 16*8+3, the message is framed with its length over tcp
+	format := regexp.MustCompile(`^(\d+) <131>1 2023-10-17T09:30:00[^ ]* [^ ]+ opi-evpn-bridge \d+ - - \x{feff}CreateVrf\(\): Error in creating the VRF$`)
+	_ = w.Close()
+	select {
+	case line := <-received:
+		if match := format.FindStringSubmatch(line); match == nil {
+			t.Errorf("unexpected syslog message %q", line)
+		} else if size, _ := strconv.Atoi(match[1]); size != len(line)-len(match[1])-1 {
+			t.Errorf("expected the frame to be the length of the message, received %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the message to reach the syslog daemon")
+	}
+
+	if _, err := New(&config.LogSinkConfig{Type: "syslog", Facility: "kern"}); err == nil {
+		t.Error("expected an unknown facility to be refused")
+	}
+}
+
+func Test_JournaldSink(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "journal.socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	defer os.Remove(socket)
+
+	w, err := New(&config.LogSinkConfig{Type: "journald", Address: socket, Tag: "opi-evpn-bridge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _ = w.Write([]byte("2023/10/17 09:30:00 panic: runtime error\ngoroutine 1 [running]:\n"))
+
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := "panic: runtime error\ngoroutine 1 [running]:"
+	size := make([]byte, 8)
+	binary.LittleEndian.PutUint64(size, uint64(len(message)))
+	expected := "MESSAGE\n" + string(size) + message + "\nPRIORITY=3\nSYSLOG_IDENTIFIER=opi-evpn-bridge\n"
+	if !bytes.Equal(buf[:n], []byte(expected)) {
+		t.Errorf("expected the datagram %q, received %q", expected, buf[:n])
+	}
+}
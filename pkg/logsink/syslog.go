@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package logsink ships the log of the bridge to syslog or journald, for the DPU images which do not collect stdout
+package logsink
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// facilities are the syslog facilities by name
+var facilities = map[string]int{
+	"": 3, "daemon": 3, "user": 1, "local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// defaultSyslogSocket is the local syslog daemon
+const defaultSyslogSocket = "/dev/log"
+
+// syslogSink sends RFC5424 messages to a syslog daemon, over tcp they are framed with their length (RFC6587)
+type syslogSink struct {
+	mu       sync.Mutex
+	network  string
+	address  string
+	facility int
+	hostname string
+	tag      string
+	conn     net.Conn
+}
+
+// newSyslogSink returns the sink of the syslog daemon, the local one when the address is empty
+func newSyslogSink(network, address, facility, tag string) (Sink, error) {
+	code, ok := facilities[facility]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %s", facility)
+	}
+	if address == "" {
+		network, address = "unixgram", defaultSyslogSocket
+	}
+	switch network {
+	case "unixgram", "unix", "udp", "tcp":
+	case "":
+		network = "udp"
+	default:
+		return nil, fmt.Errorf("unknown syslog network %s, expected unixgram, udp or tcp", network)
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	return &syslogSink{network: network, address: address, facility: code, hostname: hostname, tag: tag}, nil
+}
+
+// format renders the entry as an RFC5424 message, the message is prefixed by the BOM of UTF-8 as the RFC requires
+func (s *syslogSink) format(e Entry) string {
+	return fmt.Sprintf("<%d>1 %s %s %s %d - - \ufeff%s",
+		s.facility*8+int(e.Priority), e.Time.Format(time.RFC3339Nano), s.hostname, s.tag, os.Getpid(),
+		strings.TrimSpace(e.Message))
+}
+
+// Send sends the entry, connecting again once when the connection has failed
+func (s *syslogSink) Send(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg := s.format(e)
+	if s.network == "tcp" {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.conn == nil {
+			if s.conn, err = net.DialTimeout(s.network, s.address, time.Second); err != nil {
+				s.conn = nil
+				continue
+			}
+		}
+		_ = s.conn.SetWriteDeadline(time.Now().Add(time.Second))
+		if _, err = s.conn.Write([]byte(msg)); err == nil {
+			return nil
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	return fmt.Errorf("syslog %s %s: %w", s.network, s.address, err)
+}
+
+// Close closes the connection to the daemon
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,245 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package macmobility counts the moves of the MAC addresses of the logical bridges, a VM flapping between
+// hosts or a miswired host moving its address over and over
+package macmobility
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+const (
+	defaultInterval = 10 * time.Second
+	defaultWindow   = time.Hour
+)
+
+// key identifies a MAC address across the readings
+type key struct {
+	vni uint32
+	mac string
+}
+
+// Mover is a MAC address with its moves, Recent counting the moves within the window
+type Mover struct {
+	Vni    uint32
+	Mac    string
+	Moves  int
+	Recent int
+	// LastMove is zero when the address did not move since it is tracked
+	LastMove time.Time
+	// Location is the VTEP the address is learnt from, empty when it is learnt locally
+	Location string
+}
+
+// Stats are the moves of the MAC addresses of a VNI, Total counting the addresses no longer learnt too
+type Stats struct {
+	Vni   uint32
+	Total int
+	Top   []Mover
+}
+
+// macState is the last sequence number of an address with the times of its moves within the window
+type macState struct {
+	seq      uint32
+	location string
+	moves    int
+	recent   []time.Time
+	alarmed  bool
+}
+
+// Tracker reads the MAC addresses at every interval and counts their sequence number increases as moves
+type Tracker struct {
+	mu        sync.Mutex
+	interval  time.Duration
+	window    time.Duration
+	threshold int
+	reporter  routing.MacReporter
+	macs      map[key]*macState
+	totals    map[uint32]int
+	now       func() time.Time
+	// notify sends the alarm of an address moving too often
+	notify func(vni uint32, details string)
+}
+
+// newTracker returns the tracker of the MAC addresses of the reporter
+func newTracker(cfg *config.MacMobilityConfig, reporter routing.MacReporter) *Tracker {
+	t := &Tracker{
+		interval:  time.Duration(cfg.Interval) * time.Second,
+		window:    time.Duration(cfg.Window) * time.Second,
+		threshold: cfg.Threshold,
+		reporter:  reporter,
+		macs:      map[key]*macState{},
+		totals:    map[uint32]int{},
+		now:       time.Now,
+		notify:    notifyBridge,
+	}
+	if t.interval == 0 {
+		t.interval = defaultInterval
+	}
+	if t.window == 0 {
+		t.window = defaultWindow
+	}
+	return t
+}
+
+// notifyBridge sends the alarm on the logical bridge of the VNI, the alarms of the other VNIs are only logged
+func notifyBridge(vni uint32, details string) {
+	log.Printf("macmobility: %s\n", details)
+	lb, err := infradb.GetLBByVni(vni)
+	if err != nil {
+		return
+	}
+	infradb.NotifyAlert(infradb.StatusEventMacMoves, "logical-bridge", lb.Name, lb.ResourceVersion, details)
+}
+
+// location names where an address is learnt in the alarms
+func location(vtep string) string {
+	if vtep == "" {
+		return "locally"
+	}
+	return "from " + vtep
+}
+
+// check counts the moves since the previous reading and alarms on the addresses reaching the threshold within the
+// window, an address is alarmed on again once its recent moves dropped below the threshold
+func (t *Tracker) check(ctx context.Context) {
+	macs, err := t.reporter.EvpnMacs(ctx)
+	if err != nil {
+		log.Printf("macmobility: failed to read the MAC addresses: %v\n", err)
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	current := make(map[key]*macState, len(macs))
+	for _, mac := range macs {
+		k := key{vni: mac.Vni, mac: mac.Mac}
+		seq := max(mac.LocalSeq, mac.RemoteSeq)
+		s, ok := t.macs[k]
+		if !ok {
+			// the moves before the address is tracked are unknown
+			s = &macState{seq: seq}
+		} else if seq > s.seq {
+			moves := int(seq - s.seq)
+			s.moves += moves
+			t.totals[mac.Vni] += moves
+			for i := 0; i < moves; i++ {
+				s.recent = append(s.recent, now)
+			}
+			s.seq = seq
+		}
+		s.location = mac.RemoteVtep
+		s.recent = expire(s.recent, now.Add(-t.window))
+		if t.threshold > 0 {
+			switch {
+			case len(s.recent) >= t.threshold && !s.alarmed:
+				s.alarmed = true
+				t.notify(mac.Vni, fmt.Sprintf("mac %s of vni %d moved %d times in %v, learnt last %s",
+					mac.Mac, mac.Vni, len(s.recent), t.window, location(mac.RemoteVtep)))
+			case len(s.recent) < t.threshold:
+				s.alarmed = false
+			}
+		}
+		current[k] = s
+	}
+	t.macs = current
+}
+
+// expire drops the moves before the start of the window
+func expire(moves []time.Time, start time.Time) []time.Time {
+	i := sort.Search(len(moves), func(i int) bool { return moves[i].After(start) })
+	return moves[i:]
+}
+
+// Stats returns the moves of a VNI with its top movers, all of them when top is zero
+func (t *Tracker) Stats(vni uint32, top int) Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	start := t.now().Add(-t.window)
+	stats := Stats{Vni: vni, Total: t.totals[vni], Top: []Mover{}}
+	for k, s := range t.macs {
+		if k.vni != vni || s.moves == 0 {
+			continue
+		}
+		m := Mover{Vni: vni, Mac: k.mac, Moves: s.moves, Recent: len(expire(s.recent, start)), Location: s.location}
+		if len(s.recent) > 0 {
+			m.LastMove = s.recent[len(s.recent)-1]
+		}
+		stats.Top = append(stats.Top, m)
+	}
+	sort.Slice(stats.Top, func(i, j int) bool {
+		a, b := stats.Top[i], stats.Top[j]
+		if a.Recent != b.Recent {
+			return a.Recent > b.Recent
+		}
+		if a.Moves != b.Moves {
+			return a.Moves > b.Moves
+		}
+		return a.Mac < b.Mac
+	})
+	if top > 0 && len(stats.Top) > top {
+		stats.Top = stats.Top[:top]
+	}
+	return stats
+}
+
+// Run reads the MAC addresses at every interval until the context is done
+func (t *Tracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		t.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// running is the tracker started with the bridge
+var running atomic.Pointer[Tracker]
+
+// Start tracks the moves of the MAC addresses of the routing backend, it does nothing unless enabled
+func Start(ctx context.Context, cfg *config.Config, backend routing.Backend) error {
+	mc := &cfg.MacMobility
+	if !mc.Enabled {
+		return nil
+	}
+	reporter, ok := backend.(routing.MacReporter)
+	if !ok {
+		return fmt.Errorf("the %s routing backend does not report the MAC addresses", backend.Name())
+	}
+	t := newTracker(mc, reporter)
+	running.Store(t)
+	go t.Run(ctx)
+	log.Printf("macmobility: reading the MAC addresses every %v\n", t.interval)
+	return nil
+}
+
+// Enabled tells whether the moves are tracked
+func Enabled() bool {
+	return running.Load() != nil
+}
+
+// GetStats returns the moves of a VNI, none when they are not tracked
+func GetStats(vni uint32, top int) Stats {
+	if t := running.Load(); t != nil {
+		return t.Stats(vni, top)
+	}
+	return Stats{Vni: vni, Top: []Mover{}}
+}
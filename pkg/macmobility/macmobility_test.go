@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package macmobility counts the moves of the MAC addresses of the logical bridges, a VM flapping between
+// hosts or a miswired host moving its address over and over
+package macmobility
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// fakeReporter reports the MAC addresses it is given
+type fakeReporter struct {
+	macs []routing.EvpnMac
+}
+
+func (r *fakeReporter) EvpnMacs(context.Context) ([]routing.EvpnMac, error) {
+	return r.macs, nil
+}
+
+func Test_Check(t *testing.T) {
+	reporter := &fakeReporter{}
+	tr := newTracker(&config.MacMobilityConfig{Threshold: 3, Window: 60}, reporter)
+	now := time.Unix(1700000000, 0)
+	tr.now = func() time.Time { return now }
+	alarms := []string{}
+	tr.notify = func(_ uint32, details string) { alarms = append(alarms, details) }
+
+	read := func(step time.Duration, macs ...routing.EvpnMac) {
+		now = now.Add(step)
+		reporter.macs = macs
+		tr.check(context.Background())
+	}
+	flapping := func(seq uint32, vtep string) routing.EvpnMac {
+		return routing.EvpnMac{Vni: 1000, Mac: "aa:bb:cc:00:00:01", RemoteVtep: vtep, LocalSeq: seq, RemoteSeq: seq}
+	}
+	stable := routing.EvpnMac{Vni: 1000, Mac: "aa:bb:cc:00:00:02", RemoteSeq: 7}
+	moved := routing.EvpnMac{Vni: 1000, Mac: "aa:bb:cc:00:00:03", RemoteVtep: "10.0.0.3", RemoteSeq: 1}
+
+	// the moves before the first reading are not counted
+	read(0, flapping(4, "10.0.0.2"), stable, moved)
+	read(10*time.Second, flapping(5, ""), stable, moved)
+	read(10*time.Second, flapping(7, "10.0.0.2"), stable, routing.EvpnMac{Vni: 1000, Mac: "aa:bb:cc:00:00:03", RemoteSeq: 2})
+	// alarmed once while it keeps moving
+	read(10*time.Second, flapping(8, ""), stable)
+	// the moves leave the window, then it flaps again
+	read(2*time.Minute, flapping(8, ""), stable)
+	read(10*time.Second, flapping(11, "10.0.0.2"), stable)
+
+	expected := []string{
+		"mac aa:bb:cc:00:00:01 of vni 1000 moved 3 times in 1m0s, learnt last from 10.0.0.2",
+		"mac aa:bb:cc:00:00:01 of vni 1000 moved 3 times in 1m0s, learnt last from 10.0.0.2",
+	}
+	if !reflect.DeepEqual(alarms, expected) {
+		t.Errorf("expected the alarms %q, received %q", expected, alarms)
+	}
+
+	stats := tr.Stats(1000, 1)
+	expectedStats := Stats{Vni: 1000, Total: 8, Top: []Mover{
+		{Vni: 1000, Mac: "aa:bb:cc:00:00:01", Moves: 7, Recent: 3, LastMove: now, Location: "10.0.0.2"},
+	}}
+	if !reflect.DeepEqual(stats, expectedStats) {
+		t.Errorf("expected the stats %+v, received %+v", expectedStats, stats)
+	}
+	if stats := tr.Stats(2000, 0); stats.Total != 0 || len(stats.Top) != 0 {
+		t.Errorf("expected no moves in another vni, received %+v", stats)
+	}
+	if tr.interval != defaultInterval {
+		t.Errorf("expected the default interval, received %v", tr.interval)
+	}
+}
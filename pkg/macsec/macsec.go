@@ -0,0 +1,373 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package macsec protects the uplinks with MACsec, the underlay runs over a MACsec device stacked on each uplink
+package macsec
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// Modes of the key agreement
+const (
+	ModeMka    = "mka"
+	ModeStatic = "static"
+)
+
+const (
+	defaultCipher = "gcm-aes-128"
+	defaultReload = time.Minute
+	// defaultStateFile keeps the keys installed in static mode across the restarts
+	defaultStateFile = "/var/lib/opi-evpn-bridge/macsec.state"
+)
+
+// keyLengths are the lengths in bytes of the keys of the ciphers
+var keyLengths = map[string]int{"gcm-aes-128": 16, "gcm-aes-256": 32}
+
+// run runs a command and returns its output
+var run = func(args ...string) (string, error) {
+	out, err := exec.Command(args[0], args[1:]...).CombinedOutput() //nolint:gosec
+	if err != nil {
+		return string(out), fmt.Errorf("%s: %w: %s", strings.Join(args, " "), err, bytes.TrimSpace(out))
+	}
+	return string(out), nil
+}
+
+// runSecret runs the ip command whose arguments hold a key through the standard input of ip -batch, so that the
+// key is not on the command line any user can read, its errors name the command without the key
+var runSecret = func(args ...string) error {
+	cmd := exec.Command(args[0], "-batch", "-") //nolint:gosec
+	cmd.Stdin = strings.NewReader(strings.Join(args[1:], " ") + "\n")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", strings.Join(redact(args), " "), err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// redact returns the arguments with the key id and the key following the key keyword hidden
+func redact(args []string) []string {
+	out := append([]string{}, args...)
+	for i := range out {
+		if out[i] == "key" {
+			for j := i + 1; j < len(out) && j <= i+2; j++ {
+				out[j] = "<redacted>"
+			}
+		}
+	}
+	return out
+}
+
+// DeviceName returns the name of the MACsec device of the uplink, handed out by the interface name table of the
+// infradb which hashes the names that do not fit in the kernel limit
+func DeviceName(uplink string) (string, error) {
+	return infradb.AllocateLinkName(uplink, infradb.LinkRoleMacsec, "ms"+uplink)
+}
+
+// Interfaces returns the uplinks protected with MACsec, none when MACsec is disabled
+func Interfaces(cfg *config.Config) []string {
+	if !cfg.Macsec.Enabled {
+		return nil
+	}
+	if len(cfg.Macsec.Interfaces) != 0 {
+		return cfg.Macsec.Interfaces
+	}
+	out := make([]string, 0, len(cfg.Underlay.Uplinks))
+	for _, uplink := range cfg.Underlay.Uplinks {
+		out = append(out, uplink.Name)
+	}
+	return out
+}
+
+// Protects tells whether the uplink is protected with MACsec, its addresses and its sessions are then on the MACsec
+// device
+func Protects(cfg *config.Config, uplink string) bool {
+	for _, name := range Interfaces(cfg) {
+		if name == uplink {
+			return true
+		}
+	}
+	return false
+}
+
+// Status is the protection of an uplink
+type Status struct {
+	Interface string
+	Mode      string
+	Device    string
+	// Protected tells whether the MACsec device is up and encrypts with a secure association
+	Protected  bool
+	Cipher     string
+	EncodingSa int
+	// LastRotation is when the key of the key file was last put in use
+	LastRotation time.Time
+	// Error is the failure of the last reconciliation of the uplink
+	Error string
+}
+
+// port is the protection of an uplink
+type port struct {
+	name    string
+	dev     string
+	address string
+	peerMac net.HardwareAddr
+	key     []byte
+	// an is the association number of the transmit SA in use, prevAn the one of the previous key which is still
+	// accepted, -1 without previous key
+	an, prevAn   int
+	lastRotation time.Time
+	err          string
+}
+
+// Manager keeps the MACsec devices of the uplinks in place and rotates their keys
+type Manager struct {
+	mu       sync.Mutex
+	mode     string
+	cipher   string
+	keyFile  string
+	ckn      string
+	interval time.Duration
+	ports    map[string]*port
+	used     *usedKeys
+}
+
+// newManager returns the manager of the uplinks of the config
+func newManager(cfg *config.Config) (*Manager, error) {
+	mc := &cfg.Macsec
+	m := &Manager{
+		mode:     mc.Mode,
+		cipher:   mc.Cipher,
+		keyFile:  mc.KeyFile,
+		ckn:      mc.Ckn,
+		interval: time.Duration(mc.Reload) * time.Second,
+		ports:    make(map[string]*port),
+	}
+	if m.mode == "" {
+		m.mode = ModeMka
+	}
+	if m.cipher == "" {
+		m.cipher = defaultCipher
+	}
+	if _, ok := keyLengths[m.cipher]; !ok {
+		return nil, fmt.Errorf("unknown macsec cipher %s", m.cipher)
+	}
+	if m.interval == 0 {
+		m.interval = defaultReload
+	}
+	addresses := make(map[string]string)
+	for _, uplink := range cfg.Underlay.Uplinks {
+		addresses[uplink.Name] = uplink.Address
+	}
+	for _, name := range Interfaces(cfg) {
+		dev, err := DeviceName(name)
+		if err != nil {
+			return nil, err
+		}
+		m.ports[name] = &port{name: name, dev: dev, address: addresses[name], an: -1, prevAn: -1}
+	}
+	for _, peer := range mc.Peers {
+		p, ok := m.ports[peer.Interface]
+		if !ok {
+			return nil, fmt.Errorf("macsec peer interface %s is not protected", peer.Interface)
+		}
+		mac, err := net.ParseMAC(peer.Mac)
+		if err != nil {
+			return nil, fmt.Errorf("macsec peer of %s: %w", peer.Interface, err)
+		}
+		p.peerMac = mac
+	}
+	if m.mode == ModeStatic {
+		for _, p := range m.ports {
+			if p.peerMac == nil {
+				return nil, fmt.Errorf("macsec static mode requires the peer mac of %s", p.name)
+			}
+		}
+		stateFile := mc.StateFile
+		if stateFile == "" {
+			stateFile = defaultStateFile
+		}
+		var err error
+		if m.used, err = loadUsedKeys(stateFile); err != nil {
+			return nil, fmt.Errorf("failed to read the macsec state of %s: %w", stateFile, err)
+		}
+	}
+	return m, nil
+}
+
+// readKey reads the hex encoded key of the key file
+func (m *Manager) readKey() ([]byte, error) {
+	raw, err := os.ReadFile(filepath.Clean(m.keyFile))
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("the key of %s is not hex encoded: %w", m.keyFile, err)
+	}
+	switch {
+	case m.mode == ModeStatic && len(key) != keyLengths[m.cipher]:
+		return nil, fmt.Errorf("the key of %s must be %d bytes for %s", m.keyFile, keyLengths[m.cipher], m.cipher)
+	case m.mode == ModeMka && len(key) != 16 && len(key) != 32:
+		return nil, fmt.Errorf("the connectivity association key of %s must be 16 or 32 bytes", m.keyFile)
+	}
+	return key, nil
+}
+
+// reconcile puts the MACsec devices of the uplinks in place and rotates the new key of the key file in
+func (m *Manager) reconcile() {
+	key, err := m.readKey()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.ports {
+		if err == nil {
+			if m.mode == ModeStatic {
+				err = m.setUpStatic(p, key)
+			} else {
+				err = m.setUpMka(p, key)
+			}
+		}
+		if err != nil {
+			p.err = err.Error()
+			log.Printf("macsec: failed to protect %s: %v\n", p.name, err)
+			continue
+		}
+		p.err = ""
+	}
+}
+
+// assignAddress puts the address of the uplink on the MACsec device
+func assignAddress(p *port) error {
+	if p.address == "" {
+		return nil
+	}
+	// Example: ip address replace <prefix> dev <dev>
+	if _, err := run("ip", "address", "replace", p.address, "dev", p.dev); err != nil {
+		return err
+	}
+	return nil
+}
+
+// linkInfo is the part of the json output of ip -d link show describing a MACsec device
+type linkInfo struct {
+	IfName    string `json:"ifname"`
+	Link      string `json:"link"`
+	OperState string `json:"operstate"`
+	LinkInfo  struct {
+		InfoKind string `json:"info_kind"`
+		InfoData struct {
+			CipherSuite string `json:"cipher_suite"`
+			Encrypt     bool   `json:"encrypt"`
+			EncodingSa  int    `json:"encodingsa"`
+		} `json:"info_data"`
+	} `json:"linkinfo"`
+}
+
+// macsecLinks lists the MACsec devices of the kernel
+func macsecLinks() ([]linkInfo, error) {
+	// Example: ip -j -d link show type macsec
+	out, err := run("ip", "-j", "-d", "link", "show", "type", "macsec")
+	if err != nil {
+		return nil, err
+	}
+	links := []linkInfo{}
+	if strings.TrimSpace(out) == "" {
+		return links, nil
+	}
+	if err := json.Unmarshal([]byte(out), &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// Status returns the protection of the uplinks, by name
+func (m *Manager) Status() []Status {
+	links, err := macsecLinks()
+	if err != nil {
+		log.Printf("macsec: failed to list the MACsec devices: %v\n", err)
+	}
+	byName := make(map[string]*linkInfo, len(links))
+	for i := range links {
+		byName[links[i].IfName] = &links[i]
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Status, 0, len(m.ports))
+	for _, p := range m.ports {
+		s := Status{Interface: p.name, Mode: m.mode, Device: p.dev, Cipher: m.cipher, EncodingSa: -1,
+			LastRotation: p.lastRotation, Error: p.err}
+		if link, ok := byName[p.dev]; ok {
+			data := link.LinkInfo.InfoData
+			s.Protected = link.OperState != "DOWN" && data.Encrypt && p.err == ""
+			s.Cipher = strings.ToLower(data.CipherSuite)
+			s.EncodingSa = data.EncodingSa
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Interface < out[j].Interface })
+	return out
+}
+
+// Run reconciles the uplinks at every interval until the context is done
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.reconcile()
+		}
+	}
+}
+
+// running is the manager started with the bridge
+var running atomic.Pointer[Manager]
+
+// Start protects the uplinks, before the underlay is brought up over their MACsec devices
+func Start(ctx context.Context, cfg *config.Config) error {
+	if !cfg.Macsec.Enabled {
+		return nil
+	}
+	m, err := newManager(cfg)
+	if err != nil {
+		return err
+	}
+	if m.mode == ModeMka {
+		if err := os.MkdirAll(ctrlDir, 0o755); err != nil {
+			return err
+		}
+	}
+	m.reconcile()
+	running.Store(m)
+	go m.Run(ctx)
+	log.Printf("macsec: protecting %d uplinks with %s keys\n", len(m.ports), m.mode)
+	return nil
+}
+
+// Statuses returns the protection of the uplinks, none when MACsec is disabled
+func Statuses() []Status {
+	if m := running.Load(); m != nil {
+		return m.Status()
+	}
+	return []Status{}
+}
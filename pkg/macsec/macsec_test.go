@@ -0,0 +1,272 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package macsec protects the uplinks with MACsec, the underlay runs over a MACsec device stacked on each uplink
+package macsec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// fakeRun records the commands and answers the listing of the MACsec devices with links
+func fakeRun(t *testing.T, links string) *[]string {
+	cmds := &[]string{}
+	saved := run
+	run = func(args ...string) (string, error) {
+		cmd := strings.Join(args, " ")
+		if cmd == "ip -j -d link show type macsec" {
+			return links, nil
+		}
+		*cmds = append(*cmds, cmd)
+		return "", nil
+	}
+	savedSecret := runSecret
+	runSecret = func(args ...string) error {
+		*cmds = append(*cmds, strings.Join(args, " "))
+		return nil
+	}
+	t.Cleanup(func() { run, runSecret = saved, savedSecret })
+	return cmds
+}
+
+// testConfig returns the config protecting eth1 with the key written in a temporary key file
+func testConfig(t *testing.T, mode, key string) *config.Config {
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte(key+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Underlay.Uplinks = []config.UplinkConfig{{Name: "eth1", Address: "10.168.1.5/31"}}
+	cfg.Macsec = config.MacsecConfig{Enabled: true, Mode: mode, KeyFile: keyFile, Ckn: "00112233",
+		Peers:     []config.MacsecPeerConfig{{Interface: "eth1", Mac: "00:11:22:33:44:55"}},
+		StateFile: filepath.Join(t.TempDir(), "macsec.state")}
+	return cfg
+}
+
+func Test_Interfaces(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Underlay.Uplinks = []config.UplinkConfig{{Name: "eth1"}, {Name: "eth2"}}
+	if Protects(cfg, "eth1") {
+		t.Errorf("expected no uplink protected with MACsec disabled")
+	}
+	cfg.Macsec.Enabled = true
+	if !reflect.DeepEqual(Interfaces(cfg), []string{"eth1", "eth2"}) {
+		t.Errorf("expected the uplinks to be protected by default, received %v", Interfaces(cfg))
+	}
+	cfg.Macsec.Interfaces = []string{"eth2"}
+	if Protects(cfg, "eth1") || !Protects(cfg, "eth2") {
+		t.Errorf("expected only eth2 to be protected")
+	}
+}
+
+func Test_DeviceName(t *testing.T) {
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	if name, err := DeviceName("eth1"); err != nil || name != "mseth1" {
+		t.Errorf("expected mseth1, received %s %v", name, err)
+	}
+	long, err := DeviceName("enp0s1f0np0uplink1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := DeviceName("enp0s1f0np0uplink2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(long) > 15 || len(other) > 15 || long == other {
+		t.Errorf("expected distinct names within the kernel limit, received %s and %s", long, other)
+	}
+	if again, _ := DeviceName("enp0s1f0np0uplink1"); again != long {
+		t.Errorf("expected the uplink to keep %s, received %s", long, again)
+	}
+}
+
+func Test_Redact(t *testing.T) {
+	args := []string{"ip", "macsec", "add", "mseth1", "tx", "sa", "0", "pn", "1", "on", "key", "be45cb26", "00010203"}
+	redacted := strings.Join(redact(args), " ")
+	if strings.Contains(redacted, "be45cb26") || strings.Contains(redacted, "00010203") {
+		t.Errorf("expected the key to be redacted, received %s", redacted)
+	}
+	if args[12] != "00010203" {
+		t.Errorf("expected the arguments to be left alone")
+	}
+}
+
+func Test_StaticRotation(t *testing.T) {
+	cmds := fakeRun(t, "")
+	cfg := testConfig(t, ModeStatic, "000102030405060708090a0b0c0d0e0f")
+	m, err := newManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.reconcile()
+	expected := []string{
+		"ip link add link eth1 name mseth1 type macsec port 1 encrypt on cipher gcm-aes-128",
+		"ip macsec add mseth1 rx port 1 address 00:11:22:33:44:55",
+		"ip macsec add mseth1 rx port 1 address 00:11:22:33:44:55 sa 0 pn 1 on key be45cb2605bf36bebde684841a28f0fd 000102030405060708090a0b0c0d0e0f",
+		"ip macsec add mseth1 tx sa 0 pn 1 on key be45cb2605bf36bebde684841a28f0fd 000102030405060708090a0b0c0d0e0f",
+		"ip link set mseth1 type macsec encodingsa 0",
+		"ip link set mseth1 up",
+		"ip address replace 10.168.1.5/31 dev mseth1",
+	}
+	if !reflect.DeepEqual(*cmds, expected) {
+		t.Errorf("expected\n%s\nreceived\n%s", strings.Join(expected, "\n"), strings.Join(*cmds, "\n"))
+	}
+
+	// the same key is left in use
+	*cmds = nil
+	m.reconcile()
+	if len(*cmds) != 2 {
+		t.Errorf("expected no rotation, received %v", *cmds)
+	}
+
+	// the new keys go on the next association numbers, the one before the previous key is removed
+	for i, key := range []string{"101112131415161718191a1b1c1d1e1f", "202122232425262728292a2b2c2d2e2f"} {
+		if err := os.WriteFile(cfg.Macsec.KeyFile, []byte(key), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		*cmds = nil
+		m.reconcile()
+		deleted := strings.Contains(strings.Join(*cmds, "\n"), "ip macsec del mseth1 tx sa 0")
+		if deleted != (i == 1) {
+			t.Errorf("unexpected removal of the association 0 at the rotation %d: %v", i, *cmds)
+		}
+	}
+	if p := m.ports["eth1"]; p.an != 2 || p.prevAn != 1 {
+		t.Errorf("expected the associations 2 and 1 in use, received %d and %d", p.an, p.prevAn)
+	}
+
+	// a key of the wrong length is refused
+	if err := os.WriteFile(cfg.Macsec.KeyFile, []byte("0011"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m.reconcile()
+	if m.ports["eth1"].err == "" {
+		t.Errorf("expected the short key to be refused")
+	}
+}
+
+func Test_StaticRestart(t *testing.T) {
+	cmds := fakeRun(t, "")
+	cfg := testConfig(t, ModeStatic, "000102030405060708090a0b0c0d0e0f")
+	m, err := newManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.reconcile()
+
+	// the key installed before the restart is refused, the device is left alone
+	restarted, err := newManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	*cmds = nil
+	restarted.reconcile()
+	if len(*cmds) != 0 || restarted.ports["eth1"].err == "" {
+		t.Errorf("expected the installed key to be refused, received %v", *cmds)
+	}
+
+	// a new key creates the device again
+	if err := os.WriteFile(cfg.Macsec.KeyFile, []byte("101112131415161718191a1b1c1d1e1f"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	restarted.reconcile()
+	if len(*cmds) != 7 || restarted.ports["eth1"].err != "" {
+		t.Errorf("expected the device to be created with the new key, received %v", *cmds)
+	}
+
+	// and the previous key is not rotated back in
+	if err := os.WriteFile(cfg.Macsec.KeyFile, []byte("000102030405060708090a0b0c0d0e0f"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	*cmds = nil
+	restarted.reconcile()
+	if len(*cmds) != 0 || restarted.ports["eth1"].err == "" {
+		t.Errorf("expected the previous key to be refused, received %v", *cmds)
+	}
+}
+
+func Test_StaticRequiresPeer(t *testing.T) {
+	cfg := testConfig(t, ModeStatic, "000102030405060708090a0b0c0d0e0f")
+	cfg.Macsec.Peers = nil
+	if _, err := newManager(cfg); err == nil {
+		t.Errorf("expected the static mode to require the mac of the switch port")
+	}
+}
+
+func Test_Mka(t *testing.T) {
+	savedRunDir, savedCtrlDir := runDir, ctrlDir
+	runDir, ctrlDir = t.TempDir(), "/run/test/wpa"
+	t.Cleanup(func() { runDir, ctrlDir = savedRunDir, savedCtrlDir })
+	cmds := fakeRun(t, `[{"ifname":"macsec0","link":"eth1","operstate":"UP","linkinfo":{"info_kind":"macsec",
+		"info_data":{"cipher_suite":"GCM-AES-128","encrypt":true,"encodingsa":1}}}]`)
+	cfg := testConfig(t, ModeMka, "000102030405060708090a0b0c0d0e0f")
+	m, err := newManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.reconcile()
+	p := m.ports["eth1"]
+	expected := []string{
+		"wpa_supplicant -B -D macsec_linux -i eth1 -c " + confPath(p) + " -P " + pidPath(p),
+		"ip link set macsec0 down",
+		"ip link set macsec0 name mseth1",
+		"ip link set mseth1 up",
+		"ip address replace 10.168.1.5/31 dev mseth1",
+	}
+	if !reflect.DeepEqual(*cmds, expected) {
+		t.Errorf("expected\n%s\nreceived\n%s", strings.Join(expected, "\n"), strings.Join(*cmds, "\n"))
+	}
+	conf, err := os.ReadFile(confPath(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(conf), "mka_cak=000102030405060708090a0b0c0d0e0f\n") ||
+		!strings.Contains(string(conf), "mka_ckn=00112233\n") {
+		t.Errorf("unexpected wpa_supplicant configuration\n%s", conf)
+	}
+
+	// a new connectivity association key reconfigures wpa_supplicant
+	if err := os.WriteFile(cfg.Macsec.KeyFile, []byte("101112131415161718191a1b1c1d1e1f"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	*cmds = nil
+	m.reconcile()
+	if (*cmds)[0] != "wpa_cli -p /run/test/wpa -i eth1 reconfigure" {
+		t.Errorf("expected wpa_supplicant to be reconfigured, received %v", *cmds)
+	}
+}
+
+func Test_Status(t *testing.T) {
+	fakeRun(t, `[{"ifname":"mseth1","link":"eth1","operstate":"UP","linkinfo":{"info_kind":"macsec",
+		"info_data":{"cipher_suite":"GCM-AES-256","encrypt":true,"encodingsa":2}}}]`)
+	cfg := testConfig(t, ModeStatic, "000102030405060708090a0b0c0d0e0f")
+	cfg.Underlay.Uplinks = append(cfg.Underlay.Uplinks, config.UplinkConfig{Name: "eth2"})
+	cfg.Macsec.Peers = append(cfg.Macsec.Peers, config.MacsecPeerConfig{Interface: "eth2", Mac: "00:11:22:33:44:66"})
+	m, err := newManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := m.Status()
+	if len(status) != 2 {
+		t.Fatalf("expected the status of 2 uplinks, received %+v", status)
+	}
+	if s := status[0]; !s.Protected || s.Device != "mseth1" || s.Cipher != "gcm-aes-256" || s.EncodingSa != 2 {
+		t.Errorf("expected eth1 to be protected, received %+v", s)
+	}
+	if s := status[1]; s.Protected || s.EncodingSa != -1 {
+		t.Errorf("expected eth2 without MACsec device to be unprotected, received %+v", s)
+	}
+}
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package macsec protects the uplinks with MACsec, the underlay runs over a MACsec device stacked on each uplink
+package macsec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// runDir is the location of the wpa_supplicant configuration and pid files
+	runDir = "/run/opi-evpn"
+	// ctrlDir is the location of the control sockets of the wpa_supplicant instances
+	ctrlDir = "/run/opi-evpn/wpa"
+)
+
+// errMkaPending is returned until the key agreement with the switch created the MACsec device
+var errMkaPending = errors.New("the key agreement with the switch is pending")
+
+// confPath returns the wpa_supplicant configuration file of the uplink
+func confPath(p *port) string {
+	return path.Join(runDir, "macsec-"+p.name+".conf")
+}
+
+// pidPath returns the wpa_supplicant pid file of the uplink
+func pidPath(p *port) string {
+	return path.Join(runDir, "macsec-"+p.name+".pid")
+}
+
+// mkaConfig renders the wpa_supplicant configuration of an uplink, the switch is the key server
+func (m *Manager) mkaConfig(cak []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ctrl_interface=%s\neapol_version=3\nap_scan=0\n", ctrlDir)
+	fmt.Fprintf(&b, "network={\n")
+	fmt.Fprintf(&b, "\tkey_mgmt=NONE\n\teapol_flags=0\n\tmacsec_policy=1\n\tmacsec_integ_only=0\n")
+	if m.cipher == "gcm-aes-256" {
+		fmt.Fprintf(&b, "\tmacsec_csindex=1\n")
+	}
+	fmt.Fprintf(&b, "\tmka_cak=%s\n\tmka_ckn=%s\n\tmka_priority=255\n", hex.EncodeToString(cak), m.ckn)
+	fmt.Fprintf(&b, "}\n")
+	return b.String()
+}
+
+// stopMka stops the wpa_supplicant instance of the uplink if it is running
+func stopMka(p *port) error {
+	data, err := os.ReadFile(pidPath(p))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return err
+	}
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil && err != syscall.ESRCH {
+		return err
+	}
+	return os.Remove(pidPath(p))
+}
+
+// adoptMka names the MACsec device created by wpa_supplicant on the uplink after it, so that the underlay finds it
+func adoptMka(p *port) error {
+	links, err := macsecLinks()
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		if link.IfName == p.dev {
+			return nil
+		}
+	}
+	for _, link := range links {
+		if link.Link != p.name {
+			continue
+		}
+		for _, cmd := range [][]string{
+			{"ip", "link", "set", link.IfName, "down"},
+			// Example: ip link set macsec0 name <dev>
+			{"ip", "link", "set", link.IfName, "name", p.dev},
+		} {
+			if _, err := run(cmd...); err != nil {
+				return err
+			}
+		}
+		log.Printf("macsec: renamed %s of %s to %s\n", link.IfName, p.name, p.dev)
+		return nil
+	}
+	return errMkaPending
+}
+
+// setUpMka runs the key agreement of the uplink, a new connectivity association key is given to wpa_supplicant which
+// rotates the secure association keys with the switch
+func (m *Manager) setUpMka(p *port, cak []byte) error {
+	if !bytes.Equal(cak, p.key) {
+		if err := os.WriteFile(confPath(p), []byte(m.mkaConfig(cak)), 0o600); err != nil {
+			return err
+		}
+		if p.key == nil {
+			if err := stopMka(p); err != nil {
+				return err
+			}
+			// Example: wpa_supplicant -B -D macsec_linux -i <uplink> -c /run/opi-evpn/macsec-<uplink>.conf
+			if _, err := run("wpa_supplicant", "-B", "-D", "macsec_linux", "-i", p.name, "-c", confPath(p), "-P", pidPath(p)); err != nil {
+				return err
+			}
+		} else if _, err := run("wpa_cli", "-p", ctrlDir, "-i", p.name, "reconfigure"); err != nil {
+			return err
+		}
+		p.key = cak
+		p.lastRotation = time.Now()
+		log.Printf("macsec: the key agreement of %s runs with the key %s\n", p.name, m.ckn)
+	}
+	if err := adoptMka(p); err != nil {
+		return err
+	}
+	if _, err := run("ip", "link", "set", p.dev, "up"); err != nil {
+		return err
+	}
+	return assignAddress(p)
+}
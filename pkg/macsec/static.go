@@ -0,0 +1,196 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package macsec protects the uplinks with MACsec, the underlay runs over a MACsec device stacked on each uplink
+package macsec
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// associationNumbers is the number of secure associations of a secure channel
+const associationNumbers = 4
+
+// usedKeys are the ids of the keys installed on the uplinks, kept in a file across the restarts of the bridge: the
+// packet numbers of an association installed again restart at 1, they are the nonces of the cipher so a key is
+// never installed twice
+type usedKeys struct {
+	path string
+	keys map[string][]string
+}
+
+// loadUsedKeys reads the ids of the keys of the file, none when there is no file yet
+func loadUsedKeys(path string) (*usedKeys, error) {
+	u := &usedKeys{path: path, keys: make(map[string][]string)}
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return u, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &u.keys); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// installed tells whether the key has been installed on the uplink
+func (u *usedKeys) installed(uplink, id string) bool {
+	for _, used := range u.keys[uplink] {
+		if used == id {
+			return true
+		}
+	}
+	return false
+}
+
+// record writes the key of the uplink to the file before it is installed
+func (u *usedKeys) record(uplink, id string) error {
+	keys := make(map[string][]string, len(u.keys)+1)
+	for name, ids := range u.keys {
+		keys[name] = ids
+	}
+	keys[uplink] = append(append([]string{}, u.keys[uplink]...), id)
+	raw, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(u.path), 0o700); err != nil {
+		return err
+	}
+	tmp := u.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, u.path); err != nil {
+		return err
+	}
+	u.keys = keys
+	return nil
+}
+
+// keyID is the id of the key in the secure associations
+func keyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:16])
+}
+
+// findLink returns the MACsec device of the name, nil when it is missing
+func findLink(name string) (*linkInfo, error) {
+	links, err := macsecLinks()
+	if err != nil {
+		return nil, err
+	}
+	for i := range links {
+		if links[i].IfName == name {
+			return &links[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// createStatic creates the MACsec device of the uplink with the receive channel of the switch port, the device left
+// by a previous run is created again as its secure associations are unknown
+func (m *Manager) createStatic(p *port) error {
+	link, err := findLink(p.dev)
+	if err != nil {
+		return err
+	}
+	if link != nil {
+		if _, err := run("ip", "link", "del", p.dev); err != nil {
+			return err
+		}
+	}
+	cmds := [][]string{
+		// Example: ip link add link <uplink> name <dev> type macsec port 1 encrypt on cipher gcm-aes-128
+		{"ip", "link", "add", "link", p.name, "name", p.dev, "type", "macsec", "port", "1", "encrypt", "on", "cipher", m.cipher},
+		// Example: ip macsec add <dev> rx port 1 address <peer mac>
+		{"ip", "macsec", "add", p.dev, "rx", "port", "1", "address", p.peerMac.String()},
+	}
+	for _, cmd := range cmds {
+		if _, err := run(cmd...); err != nil {
+			return err
+		}
+	}
+	log.Printf("macsec: created %s on %s\n", p.dev, p.name)
+	return nil
+}
+
+// rotateStatic puts the key in use on the next association number of both channels, the association of the previous
+// key is still accepted from the switch until the next rotation
+func (m *Manager) rotateStatic(p *port, key []byte) error {
+	an := (p.an + 1) % associationNumbers
+	rx := []string{"rx", "port", "1", "address", p.peerMac.String(), "sa"}
+	if p.prevAn >= 0 {
+		for _, cmd := range [][]string{
+			append([]string{"ip", "macsec", "del", p.dev}, append(rx, strconv.Itoa(p.prevAn))...),
+			{"ip", "macsec", "del", p.dev, "tx", "sa", strconv.Itoa(p.prevAn)},
+		} {
+			if _, err := run(cmd...); err != nil {
+				return err
+			}
+		}
+	}
+	id := keyID(key)
+	if err := m.used.record(p.name, id); err != nil {
+		return fmt.Errorf("failed to record the key of %s: %w", p.dev, err)
+	}
+	sa := []string{strconv.Itoa(an), "pn", "1", "on", "key", id, hex.EncodeToString(key)}
+	for _, cmd := range [][]string{
+		// Example: ip macsec add <dev> rx port 1 address <peer mac> sa <an> pn 1 on key <id> <key>
+		append(append([]string{"ip", "macsec", "add", p.dev}, rx...), sa...),
+		// Example: ip macsec add <dev> tx sa <an> pn 1 on key <id> <key>
+		append([]string{"ip", "macsec", "add", p.dev, "tx", "sa"}, sa...),
+	} {
+		if err := runSecret(cmd...); err != nil {
+			return fmt.Errorf("failed to rotate the key of %s: %w", p.dev, err)
+		}
+	}
+	// Example: ip link set <dev> type macsec encodingsa <an>
+	if _, err := run("ip", "link", "set", p.dev, "type", "macsec", "encodingsa", strconv.Itoa(an)); err != nil {
+		return fmt.Errorf("failed to rotate the key of %s: %w", p.dev, err)
+	}
+	if p.an >= 0 {
+		p.prevAn = p.an
+	}
+	p.an = an
+	p.key = key
+	p.lastRotation = time.Now()
+	log.Printf("macsec: %s encrypts with the association %d\n", p.dev, an)
+	return nil
+}
+
+// setUpStatic keeps the MACsec device of the uplink in place with the key of the key file, a key which has been
+// installed before is refused and the device left as it is until a new key is rotated in
+func (m *Manager) setUpStatic(p *port, key []byte) error {
+	if p.an < 0 || !bytes.Equal(key, p.key) {
+		if m.used.installed(p.name, keyID(key)) {
+			return fmt.Errorf("the key of %s has been installed on %s before, its packet numbers would restart at 1, a new key is required", m.keyFile, p.dev)
+		}
+		if p.an < 0 {
+			if err := m.createStatic(p); err != nil {
+				return err
+			}
+		}
+		if err := m.rotateStatic(p, key); err != nil {
+			return err
+		}
+	}
+	if _, err := run("ip", "link", "set", p.dev, "up"); err != nil {
+		return err
+	}
+	return assignAddress(p)
+}
@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package maintenance drains the traffic away from the node before a maintenance, e.g. a firmware update
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	gen_linux "github.com/opiproject/opi-evpn-bridge/pkg/LinuxGeneralModule"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// State is the maintenance state of the node
+type State string
+
+const (
+	// StateActive is the node serving traffic
+	StateActive State = "ACTIVE"
+	// StateDraining is the node moving the traffic away, step by step
+	StateDraining State = "DRAINING"
+	// StateDrained is the node without traffic, ready for the maintenance
+	StateDrained State = "DRAINED"
+)
+
+// RoutesStep is the name of the step draining the EVPN routes
+const RoutesStep = "routes"
+
+// Request holds the parameters of a drain
+type Request struct {
+	Mode routing.DrainMode
+	// DrainTimer is the time given to the traffic to move away after the routes are drained
+	DrainTimer time.Duration
+	// SviInterval is the time between bringing down two svis
+	SviInterval time.Duration
+	// SviOrder names the svis brought down first, the others follow in the order of their names
+	SviOrder []string
+}
+
+// Step is a step of the drain: the EVPN routes, then every svi
+type Step struct {
+	Name  string
+	Done  bool
+	At    time.Time
+	Error string
+}
+
+// Status is the progress of the drain
+type Status struct {
+	State State
+	Mode  routing.DrainMode
+	// Started is when the node entered the maintenance, zero when it is active
+	Started time.Time
+	// SvisDownAt is when the drain timer ends and the svis start going down
+	SvisDownAt time.Time
+	Steps      []Step
+}
+
+// Progress returns the percentage of the steps done
+func (s *Status) Progress() int {
+	if len(s.Steps) == 0 {
+		return 100
+	}
+	done := 0
+	for _, step := range s.Steps {
+		if step.Done {
+			done++
+		}
+	}
+	return done * 100 / len(s.Steps)
+}
+
+// setSviAdminState brings an svi up or down, it is replaced by the tests
+var setSviAdminState = gen_linux.SetSviAdminState
+
+// node holds the maintenance state and stops the drain in progress
+var node = struct {
+	sync.Mutex
+	status Status
+	cancel context.CancelFunc
+	done   chan struct{}
+}{status: Status{State: StateActive}}
+
+// errNotInMaintenance is returned when leaving the maintenance of an active node
+var errNotInMaintenance = apierrors.FailedPrecondition(apierrors.ReasonFailedPrecondition, "maintenance", "the node is not in maintenance")
+
+// Get returns the maintenance state of the node and the progress of the drain
+func Get() Status {
+	node.Lock()
+	defer node.Unlock()
+	return copyStatus(&node.status)
+}
+
+// copyStatus returns a copy of the status which does not share its steps
+func copyStatus(s *Status) Status {
+	out := *s
+	out.Steps = append([]Step{}, s.Steps...)
+	return out
+}
+
+// sviOrder returns the svis in the order they are brought down
+func sviOrder(order []string) ([]string, error) {
+	svis, err := infradb.GetAllSvis()
+	if err != nil && !errors.Is(err, infradb.ErrKeyNotFound) {
+		return nil, err
+	}
+	rest := map[string]bool{}
+	for _, svi := range svis {
+		rest[svi.Name] = true
+	}
+	out := []string{}
+	for _, name := range order {
+		if !rest[name] {
+			return nil, apierrors.InvalidField("svi_order", apierrors.ReasonReferenceNotFound, "svi %s of the order is unknown", name)
+		}
+		out = append(out, name)
+		delete(rest, name)
+	}
+	others := make([]string, 0, len(rest))
+	for name := range rest {
+		others = append(others, name)
+	}
+	sort.Strings(others)
+	return append(out, others...), nil
+}
+
+// Enter starts draining the node in the background and returns the steps ahead
+func Enter(req Request) (Status, error) {
+	node.Lock()
+	defer node.Unlock()
+	if node.status.State != StateActive {
+		return Status{}, apierrors.FailedPrecondition(apierrors.ReasonInMaintenance, "maintenance", "the node is already in maintenance")
+	}
+	svis, err := sviOrder(req.SviOrder)
+	if err != nil {
+		return Status{}, err
+	}
+	node.status = Status{
+		State:      StateDraining,
+		Mode:       req.Mode,
+		Started:    time.Now(),
+		SvisDownAt: time.Now().Add(req.DrainTimer),
+		Steps:      []Step{{Name: RoutesStep}},
+	}
+	for _, svi := range svis {
+		node.status.Steps = append(node.status.Steps, Step{Name: svi})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	node.cancel = cancel
+	node.done = make(chan struct{})
+	go drain(ctx, req, node.done)
+	log.Printf("maintenance: draining the node with %s, %d svis to bring down\n", req.Mode, len(svis))
+	return copyStatus(&node.status), nil
+}
+
+// finishStep records the outcome of the i-th step
+func finishStep(i int, err error) {
+	node.Lock()
+	defer node.Unlock()
+	step := &node.status.Steps[i]
+	step.Done = err == nil
+	step.At = time.Now()
+	if err != nil {
+		step.Error = err.Error()
+		log.Printf("maintenance: step %s failed: %v\n", step.Name, err)
+	}
+}
+
+// wait sleeps for the duration unless the drain is stopped meanwhile
+func wait(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// drain runs the steps of the drain until they are all done or the node leaves the maintenance
+func drain(ctx context.Context, req Request, done chan struct{}) {
+	defer close(done)
+	backend, err := routing.Get()
+	if err == nil {
+		err = backend.SetDrain(ctx, req.Mode, true)
+	}
+	finishStep(0, err)
+	if !wait(ctx, req.DrainTimer) {
+		return
+	}
+	steps := Get().Steps
+	for i := 1; i < len(steps); i++ {
+		if i > 1 && !wait(ctx, req.SviInterval) {
+			return
+		}
+		finishStep(i, setSviAdminState(steps[i].Name, false))
+	}
+	node.Lock()
+	node.status.State = StateDrained
+	node.Unlock()
+	log.Println("maintenance: the node is drained")
+}
+
+// Exit stops the drain in progress and restores the traffic: the svis come back up in the reverse order,
+// then the routes are advertised again. Every step is attempted, the first failure is returned.
+func Exit() (Status, error) {
+	node.Lock()
+	if node.status.State == StateActive {
+		node.Unlock()
+		return Status{}, errNotInMaintenance
+	}
+	node.cancel()
+	done := node.done
+	node.Unlock()
+	<-done
+
+	node.Lock()
+	defer node.Unlock()
+	var firstErr error
+	steps := node.status.Steps
+	for i := len(steps) - 1; i >= 1; i-- {
+		if !steps[i].Done {
+			continue
+		}
+		if err := setSviAdminState(steps[i].Name, true); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to bring %s back up: %w", steps[i].Name, err)
+		}
+	}
+	if steps[0].Done {
+		backend, err := routing.Get()
+		if err == nil {
+			err = backend.SetDrain(context.Background(), node.status.Mode, false)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to advertise the routes again: %w", err)
+		}
+	}
+	node.status = Status{State: StateActive}
+	node.cancel = nil
+	node.done = nil
+	log.Println("maintenance: the node is active again")
+	return copyStatus(&node.status), firstErr
+}
@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package maintenance drains the traffic away from the node before a maintenance, e.g. a firmware update
+package maintenance
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/pbconv"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// drainBackend records the drains of the routes
+type drainBackend struct {
+	mu     sync.Mutex
+	drains []string
+}
+
+func (*drainBackend) Name() string                                                { return "drain" }
+func (*drainBackend) Initialize()                                                 {}
+func (*drainBackend) DeInitialize()                                               {}
+func (*drainBackend) Probe(context.Context) error                                 { return nil }
+func (*drainBackend) DeepProbe(context.Context) error                             { return nil }
+func (*drainBackend) EvpnVnis(context.Context) ([]routing.EvpnVni, error)         { return nil, nil }
+func (*drainBackend) EvpnRoutes(context.Context) ([]routing.EvpnRoute, error)     { return nil, nil }
+func (*drainBackend) BgpPeers(context.Context, string) ([]routing.BgpPeer, error) { return nil, nil }
+func (*drainBackend) BgpRoutes(context.Context, string) ([]routing.BgpRoute, error) {
+	return nil, nil
+}
+func (*drainBackend) NexthopGroups(context.Context) ([]routing.NexthopGroup, routing.NexthopGroupStats, error) {
+	return nil, routing.NexthopGroupStats{}, nil
+}
+
+func (b *drainBackend) SetDrain(_ context.Context, mode routing.DrainMode, drained bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if drained {
+		b.drains = append(b.drains, "drain "+string(mode))
+	} else {
+		b.drains = append(b.drains, "undrain "+string(mode))
+	}
+	return nil
+}
+
+const testSviPrefix = "//network.opiproject.org/svis/"
+
+// setUpSvis creates the svis of a vrf and records the changes of their admin state
+func setUpSvis(t *testing.T, ids ...string) *[]string {
+	eb := eventbus.EBus
+	eb.StartSubscriber("dummy", "vrf", 1, nil)
+	eb.StartSubscriber("dummy", "logical-bridge", 1, nil)
+	eb.StartSubscriber("dummy", "svi", 1, nil)
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	vrfName := "//network.opiproject.org/vrfs/blue"
+	vrf, err := infradb.NewVrfWithArgs(vrfName, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateVrf(vrf); err != nil {
+		t.Fatal(err)
+	}
+	for i, id := range ids {
+		lbName := "//network.opiproject.org/bridges/" + id
+		lb, err := pbconv.LogicalBridgeFromPb(&pb.LogicalBridge{Name: lbName, Spec: &pb.LogicalBridgeSpec{VlanId: uint32(10 + i)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := infradb.CreateLB(lb); err != nil {
+			t.Fatal(err)
+		}
+		svi, err := pbconv.SviFromPb(&pb.Svi{Name: testSviPrefix + id, Spec: &pb.SviSpec{
+			Vrf:           vrfName,
+			LogicalBridge: lbName,
+			MacAddress:    []byte{0xaa, 0xbb, 0xcc, 0, 0, byte(i)},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := infradb.CreateSvi(svi); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes := &[]string{}
+	var mu sync.Mutex
+	setSviAdminState = func(name string, up bool) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if up {
+			*changes = append(*changes, "up "+name)
+		} else {
+			*changes = append(*changes, "down "+name)
+		}
+		return nil
+	}
+	return changes
+}
+
+// waitState waits for the drain to reach the state
+func waitState(t *testing.T, state State) Status {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if s := Get(); s.State == state {
+			return s
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("the node did not reach %s, it is %+v", state, Get())
+	return Status{}
+}
+
+func Test_EnterExit(t *testing.T) {
+	changes := setUpSvis(t, "web", "db", "cache")
+	backend := &drainBackend{}
+	routing.Register(backend)
+	if _, err := routing.Select(backend.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Enter(Request{Mode: routing.DrainMed, SviOrder: []string{testSviPrefix + "unknown"}}); err == nil {
+		t.Fatal("expected an unknown svi of the order to be refused")
+	}
+	s, err := Enter(Request{Mode: routing.DrainMed, SviOrder: []string{testSviPrefix + "web"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.State != StateDraining || len(s.Steps) != 4 {
+		t.Errorf("expected the node draining in 4 steps, received %+v", s)
+	}
+	if _, err := Enter(Request{Mode: routing.DrainMed}); err == nil {
+		t.Error("expected a second drain to be refused")
+	}
+
+	s = waitState(t, StateDrained)
+	if s.Progress() != 100 {
+		t.Errorf("expected the drain to be complete, received %d%%", s.Progress())
+	}
+	expected := []string{"down " + testSviPrefix + "web", "down " + testSviPrefix + "cache", "down " + testSviPrefix + "db"}
+	if !reflect.DeepEqual(*changes, expected) {
+		t.Errorf("expected %q, received %q", expected, *changes)
+	}
+
+	if s, err = Exit(); err != nil || s.State != StateActive {
+		t.Fatalf("expected the node to be active again, received %+v, %v", s, err)
+	}
+	expected = append(expected, "up "+testSviPrefix+"db", "up "+testSviPrefix+"cache", "up "+testSviPrefix+"web")
+	if !reflect.DeepEqual(*changes, expected) {
+		t.Errorf("expected %q, received %q", expected, *changes)
+	}
+	if drains := []string{"drain med", "undrain med"}; !reflect.DeepEqual(backend.drains, drains) {
+		t.Errorf("expected %q, received %q", drains, backend.drains)
+	}
+	if _, err := Exit(); err != errNotInMaintenance {
+		t.Errorf("expected %v, received %v", errNotInMaintenance, err)
+	}
+}
+
+func Test_ExitWhileDraining(t *testing.T) {
+	changes := setUpSvis(t, "web")
+	if _, err := Enter(Request{Mode: routing.DrainWithdraw, DrainTimer: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Exit()
+	if err != nil || s.State != StateActive {
+		t.Fatalf("expected the node to be active again, received %+v, %v", s, err)
+	}
+	if len(*changes) != 0 {
+		t.Errorf("expected the svis to be left up, received %q", *changes)
+	}
+}
@@ -20,11 +20,14 @@ var ctx context.Context
 
 var nlink utils.Netlink
 
+// topology maps the logical bridges onto linux bridges
+var topology utils.BridgeTopology
+
 // EventBus variable
 var EventBus = eb.NewEventBus()
 
-// pollInterval variable
-var pollInterval int
+// pollInterval is the resync period in seconds, it is updated when the config is reloaded
+var pollInterval atomic.Int64
 
 // grd default route bool variable
 var grdDefaultRoute bool
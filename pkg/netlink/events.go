@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	vn "github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// LinkStateChanged event const, published with the LinkState when a device goes up or down
+const LinkStateChanged = "link_state_changed"
+
+// LinkState is the state of a kernel device as reported by the netlink notifications
+type LinkState struct {
+	Name string
+	// Owner is the resource which owns the device, empty for the devices which have not been created for a resource
+	Owner string
+	// OperState is the RFC 2863 operational state, e.g. up, down or lowerlayerdown
+	OperState string
+	Carrier   bool
+	// Flaps counts the transitions between up and down, also the short ones missed by the periodic resync
+	Flaps uint64
+	// FdbChanges counts the learned and aged MAC addresses of the bridge port
+	FdbChanges uint64
+	LastChange time.Time
+}
+
+// linkStates holds the state of the devices indexed by their ifindex
+var linkStates = struct {
+	sync.Mutex
+	byIndex map[int]*LinkState
+}{byIndex: make(map[int]*LinkState)}
+
+// resyncRequests coalesces the notifications into resyncs with the kernel
+var resyncRequests = make(chan struct{}, 1)
+
+// requestResync triggers a resync with the kernel unless one is pending already
+func requestResync() {
+	select {
+	case resyncRequests <- struct{}{}:
+	default:
+	}
+}
+
+// GetLinkStates returns the state of the devices in the order of their names
+func GetLinkStates() []LinkState {
+	linkStates.Lock()
+	defer linkStates.Unlock()
+	states := make([]LinkState, 0, len(linkStates.byIndex))
+	for _, state := range linkStates.byIndex {
+		states = append(states, *state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// updateLinkState records the state of the device and tells whether it went up or down
+func updateLinkState(update vn.LinkUpdate) (LinkState, bool) {
+	attrs := update.Attrs()
+	linkStates.Lock()
+	defer linkStates.Unlock()
+	if update.Header.Type == unix.RTM_DELLINK {
+		delete(linkStates.byIndex, attrs.Index)
+		return LinkState{}, false
+	}
+	owner, _ := utils.LinkAliasOwner(attrs.Alias)
+	state, ok := linkStates.byIndex[attrs.Index]
+	if !ok {
+		state = &LinkState{LastChange: time.Now()}
+		linkStates.byIndex[attrs.Index] = state
+	}
+	up := attrs.OperState == vn.OperUp
+	changed := ok && (state.OperState == vn.LinkOperState(vn.OperUp).String()) != up
+	if changed {
+		state.Flaps++
+		state.LastChange = time.Now()
+	}
+	state.Name = attrs.Name
+	state.Owner = owner
+	state.OperState = attrs.OperState.String()
+	state.Carrier = update.IfInfomsg.Flags&unix.IFF_LOWER_UP != 0
+	return *state, changed
+}
+
+// countFdbChange accounts a learned or aged MAC address to its bridge port
+func countFdbChange(update vn.NeighUpdate) {
+	if update.Family != unix.AF_BRIDGE {
+		return
+	}
+	linkStates.Lock()
+	defer linkStates.Unlock()
+	if state, ok := linkStates.byIndex[update.LinkIndex]; ok {
+		state.FdbChanges++
+	}
+}
+
+// subscribeKernelEvents listens to the link, neighbor and route notifications of the kernel until done is closed.
+// The link state is updated right away, the other changes trigger a resync so that the short flaps are not missed.
+func subscribeKernelEvents(done chan struct{}) error {
+	links := make(chan vn.LinkUpdate, 64)
+	neighs := make(chan vn.NeighUpdate, 64)
+	routes := make(chan vn.RouteUpdate, 64)
+	onError := func(err error) {
+		log.Printf("netlink: notification error, falling back to the periodic resync: %v", err)
+	}
+	if err := vn.LinkSubscribeWithOptions(links, done, vn.LinkSubscribeOptions{ListExisting: true, ErrorCallback: onError}); err != nil {
+		return err
+	}
+	if err := vn.NeighSubscribeWithOptions(neighs, done, vn.NeighSubscribeOptions{ErrorCallback: onError}); err != nil {
+		return err
+	}
+	if err := vn.RouteSubscribeWithOptions(routes, done, vn.RouteSubscribeOptions{ErrorCallback: onError}); err != nil {
+		return err
+	}
+	go func() {
+		for {
+			select {
+			case update, ok := <-links:
+				if !ok {
+					return
+				}
+				if state, changed := updateLinkState(update); changed {
+					log.Printf("netlink: link %s is %s", state.Name, state.OperState)
+					notifyAddDel(state, LinkStateChanged)
+				}
+				requestResync()
+			case update, ok := <-neighs:
+				if !ok {
+					return
+				}
+				countFdbChange(update)
+				requestResync()
+			case _, ok := <-routes:
+				if !ok {
+					return
+				}
+				requestResync()
+			}
+		}
+	}()
+	return nil
+}
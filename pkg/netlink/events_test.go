@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package netlink handles the netlink related functionality
+package netlink
+
+import (
+	"testing"
+
+	vn "github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// linkUpdate builds the notification of a change of the device
+func linkUpdate(msgType uint16, oper vn.LinkOperState, flags uint32) vn.LinkUpdate {
+	attrs := vn.LinkAttrs{Index: 7, Name: "blue-10", OperState: oper, Alias: utils.LinkAlias("//network.opiproject.org/svis/blue")}
+	return vn.LinkUpdate{
+		IfInfomsg: nl.IfInfomsg{IfInfomsg: unix.IfInfomsg{Flags: flags}},
+		Header:    unix.NlMsghdr{Type: msgType},
+		Link:      &vn.Device{LinkAttrs: attrs},
+	}
+}
+
+func Test_UpdateLinkState(t *testing.T) {
+	t.Cleanup(func() { linkStates.byIndex = make(map[int]*LinkState) })
+
+	updates := []struct {
+		update  vn.LinkUpdate
+		changed bool
+		flaps   uint64
+		carrier bool
+	}{
+		{linkUpdate(unix.RTM_NEWLINK, vn.OperUp, unix.IFF_UP|unix.IFF_LOWER_UP), false, 0, true},
+		{linkUpdate(unix.RTM_NEWLINK, vn.OperUp, unix.IFF_UP|unix.IFF_LOWER_UP), false, 0, true},
+		{linkUpdate(unix.RTM_NEWLINK, vn.OperLowerLayerDown, unix.IFF_UP), true, 1, false},
+		{linkUpdate(unix.RTM_NEWLINK, vn.OperUp, unix.IFF_UP|unix.IFF_LOWER_UP), true, 2, true},
+	}
+	for i, u := range updates {
+		state, changed := updateLinkState(u.update)
+		if changed != u.changed {
+			t.Errorf("update %d: expected changed %v, received %v", i, u.changed, changed)
+		}
+		if state.Flaps != u.flaps || state.Carrier != u.carrier {
+			t.Errorf("update %d: expected %d flaps and carrier %v, received %+v", i, u.flaps, u.carrier, state)
+		}
+		if state.Owner != "//network.opiproject.org/svis/blue" {
+			t.Errorf("update %d: unexpected owner %s", i, state.Owner)
+		}
+	}
+
+	countFdbChange(vn.NeighUpdate{Neigh: vn.Neigh{LinkIndex: 7, Family: unix.AF_BRIDGE}})
+	if states := GetLinkStates(); len(states) != 1 || states[0].FdbChanges != 1 {
+		t.Errorf("expected one fdb change, received %+v", states)
+	}
+
+	updateLinkState(linkUpdate(unix.RTM_DELLINK, vn.OperDown, 0))
+	if states := GetLinkStates(); len(states) != 0 {
+		t.Errorf("expected the deleted link to be forgotten, received %+v", states)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 )
@@ -95,42 +96,63 @@ func (fdb *FdbEntryStruct) preFilterMac() bool {
 	return false
 }
 
+// fdbBridges returns the linux bridges carrying the logical bridges together with their vlans
+func fdbBridges() map[string][]int {
+	bridges := make(map[string][]int)
+	lbs, _ := infradb.GetAllLBs()
+	for _, lb := range lbs {
+		if lb.Spec.VlanID > math.MaxUint16 {
+			continue
+		}
+		name := topology.BridgeName(uint16(lb.Spec.VlanID))
+		bridges[name] = append(bridges[name], int(lb.Spec.VlanID))
+	}
+	return bridges
+}
+
 // readFDB read the fdb from db
 func readFDB() []*FdbEntryStruct {
-	var fdbs []FdbIPStruct
 	var macs []*FdbEntryStruct
-	var fs *FdbEntryStruct
+	for bridge, vids := range fdbBridges() {
+		for _, fi := range readBridgeFDB(bridge) {
+			// A bridge which is not vlan aware reports its entries without vlan,
+			// those belong to the only vlan the bridge carries
+			if fi.Vlan == 0 && fi.Master != "" && len(vids) == 1 {
+				fi.Vlan = vids[0]
+			}
+			fs := ParseFdb(fi)
+			if fs.preFilterMac() {
+				macs = append(macs, fs)
+			}
+		}
+	}
+	return macs
+}
+
+// readBridgeFDB reads the fdb entries of one linux bridge
+func readBridgeFDB(bridge string) []FdbIPStruct {
+	var fdbs []FdbIPStruct
 
-	cp, err := nlink.ReadFDB(ctx)
+	cp, err := nlink.ReadFDB(ctx, bridge)
 	if err != nil || len(cp) <= 3 {
-		return macs
+		return fdbs
 	}
 
 	var rawMessages []json.RawMessage
 	err = json.Unmarshal([]byte(cp), &rawMessages)
 	if err != nil {
 		log.Printf("netlink fdb: JSON unmarshal error: %v %v : %v\n", err, cp, rawMessages)
-		return macs
+		return fdbs
 	}
-	cps := make([]string, 0, len(rawMessages))
 	for _, rawMsg := range rawMessages {
-		cps = append(cps, string(rawMsg))
-	}
-	for i := 0; i < len(cps); i++ {
 		var fi FdbIPStruct
-		err := json.Unmarshal([]byte(cps[i]), &fi)
+		err := json.Unmarshal(rawMsg, &fi)
 		if err != nil {
 			log.Printf("netlink: error-%v", err)
 		}
 		fdbs = append(fdbs, fi)
 	}
-	for _, m := range fdbs {
-		fs = ParseFdb(m)
-		if fs.preFilterMac() {
-			macs = append(macs, fs)
-		}
-	}
-	return macs
+	return fdbs
 }
 
 // addFdbEntry add fdb entries
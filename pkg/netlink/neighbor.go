@@ -6,9 +6,6 @@ import (
 	"log"
 	"net"
 	"path"
-	"regexp"
-	"strconv"
-	"strings"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	vn "github.com/vishvananda/netlink"
@@ -140,7 +137,7 @@ func readNeighbors(v *infradb.Vrf) {
 		   so using ip command as WA */
 		nb, err = nlink.ReadNeigh(ctx, "")
 	} else {
-		nb, err = nlink.ReadNeigh(ctx, path.Base(v.Name))
+		nb, err = nlink.ReadNeigh(ctx, infradb.LinkName(v.Name, infradb.LinkRoleVrf))
 	}
 	if len(nb) <= 3 || err != nil {
 		return
@@ -203,6 +200,29 @@ func (neigh *NeighStruct) printNeigh() string {
 	return str
 }
 
+// sviVlan returns the vlan of the device when it is the routed interface of an SVI of the VRF
+func sviVlan(dev, vrfName string) (uint32, bool) {
+	owner, ok := infradb.GetLinkOwner(dev)
+	if !ok || owner.Role != infradb.LinkRoleSvi {
+		return 0, false
+	}
+	svi, err := infradb.GetSvi(owner.Object)
+	if err != nil || svi.Spec.Vrf != vrfName {
+		return 0, false
+	}
+	lb, err := infradb.GetLB(svi.Spec.LogicalBridge)
+	if err != nil {
+		return 0, false
+	}
+	return lb.Spec.VlanID, true
+}
+
+// isSviOf tells whether the device is the routed interface of an SVI of the VRF
+func isSviOf(dev, vrfName string) bool {
+	_, ok := sviVlan(dev, vrfName)
+	return ok
+}
+
 // nolint
 func (neigh NeighStruct) neighborAnnotate() NeighStruct {
 	neigh.Metadata = make(map[interface{}]interface{})
@@ -213,20 +233,13 @@ func (neigh NeighStruct) neighborAnnotate() NeighStruct {
 			phyFlag = true
 		}
 	}
-	if strings.HasPrefix(nameIndex[neigh.Neigh0.LinkIndex], path.Base(neigh.VrfName)) && neigh.Protocol != zebraStr {
-		pattern := fmt.Sprintf(`%s-\d+$`, path.Base(neigh.VrfName))
-		mustcompile := regexp.MustCompile(pattern)
-		s := mustcompile.FindStringSubmatch(nameIndex[neigh.Neigh0.LinkIndex])
+	vlanID, isSvi := sviVlan(nameIndex[neigh.Neigh0.LinkIndex], neigh.VrfName)
+	if isSvi && neigh.Protocol != zebraStr {
 		var lb *infradb.LogicalBridge
 		var bp *infradb.BridgePort
-		vID := strings.Split(s[0], "-")[1]
 		lbs, _ := infradb.GetAllLBs()
-		vlanID, err := strconv.ParseUint(vID, 10, 32)
-		if err != nil {
-			panic(err)
-		}
 		for _, lB := range lbs {
-			if lB.Spec.VlanID == uint32(vlanID) {
+			if lB.Spec.VlanID == vlanID {
 				lb = lB
 				break
 			}
@@ -240,33 +253,22 @@ func (neigh NeighStruct) neighborAnnotate() NeighStruct {
 		if bp != nil {
 			neigh.Type = SVI
 			neigh.Metadata["vport_id"] = bp.Metadata.VPort
-			neigh.Metadata["vlanID"] = uint32(vlanID)
+			neigh.Metadata["vlanID"] = vlanID
 			neigh.Metadata["portType"] = bp.Spec.Ptype
 		} else {
 			neigh.Type = IGNORE
 		}
-	} else if strings.HasPrefix(nameIndex[neigh.Neigh0.LinkIndex], path.Base(neigh.VrfName)) && neigh.Protocol == zebraStr {
-		pattern := fmt.Sprintf(`%s-\d+$`, path.Base(neigh.VrfName))
-		mustcompile := regexp.MustCompile(pattern)
-		s := mustcompile.FindStringSubmatch(neigh.Dev)
+	} else if isSvi && neigh.Protocol == zebraStr {
 		var lb *infradb.LogicalBridge
-		vID := strings.Split(s[0], "-")[1]
 		lbs, _ := infradb.GetAllLBs()
-		vlanID, err := strconv.ParseUint(vID, 10, 32)
-		if err != nil {
-			panic(err)
-		}
 		for _, lB := range lbs {
-			if lB.Spec.VlanID == uint32(vlanID) {
+			if lB.Spec.VlanID == vlanID {
 				lb = lB
 				break
 			}
 		}
 		if lb.Spec.Vni != nil {
-			vid, err := strconv.Atoi(vID)
-			if err != nil {
-				panic(err)
-			}
+			vid := int(vlanID)
 			fdbEntry := latestFDB[FdbKey{vid, neigh.Neigh0.HardwareAddr.String()}]
 			neigh.Metadata["l2_nh"] = fdbEntry.Nexthop
 			neigh.Type = VXLAN // confirm this later
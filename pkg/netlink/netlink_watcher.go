@@ -7,6 +7,7 @@ package netlink
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"time"
@@ -145,12 +146,25 @@ func notifyUpdates[K comparable, V any](items map[K]V, deletionType string) {
 
 // Usage
 
-// monitorNetlink moniters the netlink
+// resyncHoldoff is the minimum delay between two resyncs triggered by kernel notifications
+const resyncHoldoff = 200 * time.Millisecond
+
+// monitorNetlink resyncs with the kernel on every netlink notification and at least every poll interval
 func monitorNetlink() {
+	done := make(chan struct{})
+	if err := subscribeKernelEvents(done); err != nil {
+		log.Printf("netlink: Failed to subscribe to the kernel notifications, polling only: %v", err)
+	}
 	for !stopMonitoring.Load() {
 		resyncWithKernel()
-		time.Sleep(time.Duration(pollInterval) * time.Second)
+		select {
+		case <-resyncRequests:
+			// Let the burst of notifications settle before the next resync
+			time.Sleep(resyncHoldoff)
+		case <-time.After(time.Duration(pollInterval.Load()) * time.Second):
+		}
 	}
+	close(done)
 	log.Printf("netlink: Stopped periodic polling. Waiting for Infra DB cleanup to finish")
 	time.Sleep(2 * time.Second)
 	log.Printf("netlink: One final netlink poll to identify what's still left.")
@@ -190,8 +204,11 @@ func getlink() {
 
 // Initialize function intializes config
 func Initialize() {
-	pollInterval = config.GlobalConfig.Netlink.PollInterval
-	log.Printf("netlink: poll interval: %v", pollInterval)
+	pollInterval.Store(int64(config.GlobalConfig.Netlink.PollInterval))
+	log.Printf("netlink: poll interval: %v", pollInterval.Load())
+	config.OnReload(func(cfg *config.Config) {
+		pollInterval.Store(int64(cfg.Netlink.PollInterval))
+	})
 	nlEnabled := config.GlobalConfig.Netlink.Enabled
 
 	grdDefaultRoute = config.GlobalConfig.Netlink.GrdDefaultRoute
@@ -207,10 +224,27 @@ func Initialize() {
 	getlink()
 	ctx = context.Background()
 	nlink = utils.NewNetlinkWrapperWithArgs(config.GlobalConfig.Tracer)
+	var err error
+	topology, err = utils.NewBridgeTopology(config.GlobalConfig.LinuxFrr.BridgeTopology, nlink, config.GlobalConfig.LinuxFrr.IPMtu+20)
+	if err != nil {
+		log.Fatalf("netlink: %v", err)
+	}
 	stopMonitoring.Store(false)
 	go monitorNetlink() // monitor Thread started
 }
 
+// Probe checks that the netlink monitor is running and the kernel answers netlink requests
+func Probe(ctx context.Context) error {
+	if !config.GlobalConfig.Netlink.Enabled {
+		return nil
+	}
+	if nlink == nil || stopMonitoring.Load() {
+		return errors.New("netlink monitor is not running")
+	}
+	_, err := nlink.LinkByName(ctx, "lo")
+	return err
+}
+
 // DeInitialize function handles stops functionality
 func DeInitialize() {
 	// stopMonitoring = true
@@ -7,7 +7,6 @@ import (
 	"net"
 	"path"
 	"reflect"
-	"strings"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	vn "github.com/vishvananda/netlink"
@@ -241,9 +240,9 @@ func (nexthop *NexthopStruct) ParseNexthop(v *infradb.Vrf, rc RouteCmdInfo) {
 	}
 	if (nexthop.nexthop.Gw != nil && !nexthop.nexthop.Gw.IsUnspecified()) && phyFlag && !nexthop.Local {
 		nexthop.NhType = PHY
-	} else if (nexthop.nexthop.Gw != nil && !nexthop.nexthop.Gw.IsUnspecified()) && nexthop.nexthop.LinkIndex != 0 && strings.HasPrefix(nameIndex[nexthop.nexthop.LinkIndex], path.Base(nexthop.Vrf.Name)+"-") && !nexthop.Local {
+	} else if (nexthop.nexthop.Gw != nil && !nexthop.nexthop.Gw.IsUnspecified()) && nexthop.nexthop.LinkIndex != 0 && isSviOf(nameIndex[nexthop.nexthop.LinkIndex], nexthop.Vrf.Name) && !nexthop.Local {
 		nexthop.NhType = VRFNEIGHBOR
-	} else if (nexthop.nexthop.Gw != nil && !nexthop.nexthop.Gw.IsUnspecified()) && nameIndex[nexthop.nexthop.LinkIndex] == fmt.Sprintf("br-%s", path.Base(nexthop.Vrf.Name)) && !nexthop.Local {
+	} else if (nexthop.nexthop.Gw != nil && !nexthop.nexthop.Gw.IsUnspecified()) && nameIndex[nexthop.nexthop.LinkIndex] == infradb.LinkName(nexthop.Vrf.Name, infradb.LinkRoleBridge) && !nexthop.Local {
 		nexthop.NhType = VXLAN
 	} else {
 		nexthop.NhType = ACC
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"log"
 	"net"
-	"path"
 	"reflect"
 	"strconv"
 	"strings"
@@ -194,7 +193,7 @@ func (route *RouteStruct) setRouteType(v *infradb.Vrf) string {
 			for _, d := range route.Nexthops {
 				devs = append(devs, nameIndex[d.nexthop.LinkIndex])
 			}
-			if len(devs) == 1 && devs[0] == "br-"+v.Name {
+			if len(devs) == 1 && devs[0] == infradb.LinkName(v.Name, infradb.LinkRoleBridge) {
 				return routeTypeEvpnVxlan
 			}
 			return routeTypeBgp
@@ -484,7 +483,7 @@ func lookupRoute(dst net.IP, v *infradb.Vrf) (*RouteStruct, bool) {
 	var err error
 	var routeData []RouteCmdInfo
 	if v.Spec.Vni != nil {
-		cp, err = nlink.RouteLookup(ctx, dst.String(), path.Base(v.Name))
+		cp, err = nlink.RouteLookup(ctx, dst.String(), infradb.LinkName(v.Name, infradb.LinkRoleVrf))
 	} else {
 		cp, err = nlink.RouteLookup(ctx, dst.String(), "")
 	}
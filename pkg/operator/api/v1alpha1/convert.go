@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package v1alpha1
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+	"go.einride.tech/aip/resourcename"
+)
+
+// fullName returns the bridge name of the object with the given id
+func fullName(collection, id string) string {
+	return resourcename.Join("//network.opiproject.org/", collection, id)
+}
+
+// prefixToPb translates a CIDR into its protobuf representation
+func prefixToPb(cidr string) (*pc.IPPrefix, error) {
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	ones, _ := ipnet.Mask.Size()
+	if v4 := ip.To4(); v4 != nil {
+		return &pc.IPPrefix{
+			Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: binary.BigEndian.Uint32(v4)}},
+			Len:  int32(ones),
+		}, nil
+	}
+	return &pc.IPPrefix{
+		Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET6, V4OrV6: &pc.IPAddress_V6Addr{V6Addr: ip.To16()}},
+		Len:  int32(ones),
+	}, nil
+}
+
+// optionalPrefixToPb translates a CIDR which may be left empty
+func optionalPrefixToPb(cidr string) (*pc.IPPrefix, error) {
+	if cidr == "" {
+		return nil, nil
+	}
+	return prefixToPb(cidr)
+}
+
+// ToPb translates the Vrf custom resource into the bridge object
+func (in *Vrf) ToPb() (*pb.Vrf, error) {
+	loopback, err := optionalPrefixToPb(in.Spec.LoopbackIPPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid loopbackIpPrefix: %v", err)
+	}
+	vtep, err := optionalPrefixToPb(in.Spec.VtepIPPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vtepIpPrefix: %v", err)
+	}
+	return &pb.Vrf{
+		Name: fullName("vrfs", in.Name),
+		Spec: &pb.VrfSpec{Vni: in.Spec.Vni, LoopbackIpPrefix: loopback, VtepIpPrefix: vtep},
+	}, nil
+}
+
+// ToPb translates the LogicalBridge custom resource into the bridge object
+func (in *LogicalBridge) ToPb() (*pb.LogicalBridge, error) {
+	return &pb.LogicalBridge{
+		Name: fullName("bridges", in.Name),
+		Spec: &pb.LogicalBridgeSpec{VlanId: in.Spec.VlanID, Vni: in.Spec.Vni},
+	}, nil
+}
+
+// ToPb translates the BridgePort custom resource into the bridge object
+func (in *BridgePort) ToPb() (*pb.BridgePort, error) {
+	mac, err := net.ParseMAC(in.Spec.MacAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid macAddress: %v", err)
+	}
+	var ptype pb.BridgePortType
+	switch strings.ToLower(in.Spec.Type) {
+	case "access":
+		ptype = pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS
+	case "trunk":
+		ptype = pb.BridgePortType_BRIDGE_PORT_TYPE_TRUNK
+	default:
+		return nil, fmt.Errorf("type must be either access or trunk and not %q", in.Spec.Type)
+	}
+	lbs := make([]string, 0, len(in.Spec.LogicalBridges))
+	for _, lb := range in.Spec.LogicalBridges {
+		lbs = append(lbs, fullName("bridges", lb))
+	}
+	return &pb.BridgePort{
+		Name: fullName("ports", in.Name),
+		Spec: &pb.BridgePortSpec{MacAddress: mac, Ptype: ptype, LogicalBridges: lbs},
+	}, nil
+}
+
+// ToPb translates the Svi custom resource into the bridge object
+func (in *Svi) ToPb() (*pb.Svi, error) {
+	mac, err := net.ParseMAC(in.Spec.MacAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid macAddress: %v", err)
+	}
+	gws := make([]*pc.IPPrefix, 0, len(in.Spec.GwIPPrefixes))
+	for _, gw := range in.Spec.GwIPPrefixes {
+		prefix, err := prefixToPb(gw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gwIpPrefixes: %v", err)
+		}
+		gws = append(gws, prefix)
+	}
+	return &pb.Svi{
+		Name: fullName("svis", in.Name),
+		Spec: &pb.SviSpec{
+			Vrf:           fullName("vrfs", in.Spec.Vrf),
+			LogicalBridge: fullName("bridges", in.Spec.LogicalBridge),
+			MacAddress:    mac,
+			GwIpPrefix:    gws,
+			EnableBgp:     in.Spec.EnableBgp,
+			RemoteAs:      in.Spec.RemoteAs,
+		},
+	}, nil
+}
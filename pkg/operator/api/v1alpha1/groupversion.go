@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package v1alpha1 contains the custom resources reconciled by the operator
+// +kubebuilder:object:generate=true
+// +groupName=evpn.opiproject.org
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is the group and version of the custom resources
+	GroupVersion = schema.GroupVersion{Group: "evpn.opiproject.org", Version: "v1alpha1"}
+
+	// SchemeBuilder registers the custom resources with a scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the custom resources to a scheme
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+func init() {
+	SchemeBuilder.Register(&Vrf{}, &VrfList{})
+	SchemeBuilder.Register(&LogicalBridge{}, &LogicalBridgeList{})
+	SchemeBuilder.Register(&BridgePort{}, &BridgePortList{})
+	SchemeBuilder.Register(&Svi{}, &SviList{})
+}
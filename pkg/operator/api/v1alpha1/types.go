@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ComponentStatus is the programming status of one bridge component
+type ComponentStatus struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Details string `json:"details,omitempty"`
+}
+
+// BridgeStatus is the status written back from the bridge, shared by all the custom resources
+type BridgeStatus struct {
+	// Name is the full name of the object in the bridge
+	Name string `json:"name,omitempty"`
+	// OperStatus is the operational status reported by the bridge
+	OperStatus string            `json:"operStatus,omitempty"`
+	Components []ComponentStatus `json:"components,omitempty"`
+	// ObservedGeneration is the generation of the spec last pushed to the bridge
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Message holds the last error returned by the bridge
+	Message string `json:"message,omitempty"`
+}
+
+// VrfSpec mirrors the spec of the bridge Vrf, the routing domain of a VPC
+type VrfSpec struct {
+	Vni *uint32 `json:"vni,omitempty"`
+	// LoopbackIPPrefix is the loopback address of the VRF in CIDR notation
+	LoopbackIPPrefix string `json:"loopbackIpPrefix,omitempty"`
+	// VtepIPPrefix is the VTEP address of the VRF in CIDR notation
+	VtepIPPrefix string `json:"vtepIpPrefix,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+
+// Vrf is the custom resource of a bridge Vrf
+type Vrf struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VrfSpec      `json:"spec,omitempty"`
+	Status BridgeStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VrfList is a list of Vrf
+type VrfList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Vrf `json:"items"`
+}
+
+// LogicalBridgeSpec mirrors the spec of the bridge LogicalBridge
+type LogicalBridgeSpec struct {
+	VlanID uint32  `json:"vlanId"`
+	Vni    *uint32 `json:"vni,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+
+// LogicalBridge is the custom resource of a bridge LogicalBridge
+type LogicalBridge struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LogicalBridgeSpec `json:"spec,omitempty"`
+	Status BridgeStatus      `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LogicalBridgeList is a list of LogicalBridge
+type LogicalBridgeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LogicalBridge `json:"items"`
+}
+
+// BridgePortSpec mirrors the spec of the bridge BridgePort
+type BridgePortSpec struct {
+	MacAddress string `json:"macAddress"`
+	// Type is either access or trunk
+	Type string `json:"type"`
+	// LogicalBridges are the names of the LogicalBridge custom resources
+	LogicalBridges []string `json:"logicalBridges,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+
+// BridgePort is the custom resource of a bridge BridgePort
+type BridgePort struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BridgePortSpec `json:"spec,omitempty"`
+	Status BridgeStatus   `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BridgePortList is a list of BridgePort
+type BridgePortList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BridgePort `json:"items"`
+}
+
+// SviSpec mirrors the spec of the bridge Svi, the gateway of a subnet
+type SviSpec struct {
+	// Vrf is the name of the Vrf custom resource
+	Vrf string `json:"vrf"`
+	// LogicalBridge is the name of the LogicalBridge custom resource
+	LogicalBridge string `json:"logicalBridge"`
+	MacAddress    string `json:"macAddress"`
+	// GwIPPrefixes are the gateway addresses of the subnet in CIDR notation
+	GwIPPrefixes []string `json:"gwIpPrefixes,omitempty"`
+	EnableBgp    bool     `json:"enableBgp,omitempty"`
+	RemoteAs     uint32   `json:"remoteAs,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+
+// Svi is the custom resource of a bridge Svi
+type Svi struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SviSpec      `json:"spec,omitempty"`
+	Status BridgeStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SviList is a list of Svi
+type SviList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Svi `json:"items"`
+}
+
+// BridgeStatusOf returns the bridge status of the custom resource
+func (in *Vrf) BridgeStatusOf() *BridgeStatus { return &in.Status }
+
+// BridgeStatusOf returns the bridge status of the custom resource
+func (in *LogicalBridge) BridgeStatusOf() *BridgeStatus { return &in.Status }
+
+// BridgeStatusOf returns the bridge status of the custom resource
+func (in *BridgePort) BridgeStatusOf() *BridgeStatus { return &in.Status }
+
+// BridgeStatusOf returns the bridge status of the custom resource
+func (in *Svi) BridgeStatusOf() *BridgeStatus { return &in.Status }
@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package operator reconciles the custom resources against the bridge gRPC API
+package operator
+
+import (
+	"strings"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"go.einride.tech/aip/resourcename"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/operator/api/v1alpha1"
+)
+
+// fullName returns the bridge name of the object with the given id
+func fullName(collection, id string) string {
+	return resourcename.Join("//network.opiproject.org/", collection, id)
+}
+
+// componentsFromPb translates the bridge components into their status representation
+func componentsFromPb(comps []*pb.Component) []v1alpha1.ComponentStatus {
+	out := make([]v1alpha1.ComponentStatus, 0, len(comps))
+	for _, c := range comps {
+		out = append(out, v1alpha1.ComponentStatus{
+			Name:    c.Name,
+			Status:  strings.TrimPrefix(c.Status.String(), "COMP_STATUS_"),
+			Details: c.Details,
+		})
+	}
+	return out
+}
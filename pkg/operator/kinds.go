@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package operator
+
+import (
+	"context"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/operator/api/v1alpha1"
+)
+
+// vrfKind binds the Vrf custom resource to the VrfService
+func vrfKind(c pb.VrfServiceClient) *bridgeKind[*v1alpha1.Vrf] {
+	state := func(v *pb.Vrf, err error) (*bridgeState, error) {
+		if err != nil {
+			return nil, err
+		}
+		return &bridgeState{operStatus: operStatus(v.GetStatus().GetOperStatus(), "VRF_OPER_STATUS_"), components: v.GetStatus().GetComponents()}, nil
+	}
+	return &bridgeKind[*v1alpha1.Vrf]{
+		collection: "vrfs",
+		newObject:  func() *v1alpha1.Vrf { return &v1alpha1.Vrf{} },
+		get: func(ctx context.Context, name string) (*bridgeState, error) {
+			return state(c.GetVrf(ctx, &pb.GetVrfRequest{Name: name}))
+		},
+		create: func(ctx context.Context, obj *v1alpha1.Vrf) (*bridgeState, error) {
+			vrf, err := obj.ToPb()
+			if err != nil {
+				return nil, err
+			}
+			return state(c.CreateVrf(ctx, &pb.CreateVrfRequest{VrfId: obj.Name, Vrf: vrf}))
+		},
+		update: func(ctx context.Context, obj *v1alpha1.Vrf) (*bridgeState, error) {
+			vrf, err := obj.ToPb()
+			if err != nil {
+				return nil, err
+			}
+			return state(c.UpdateVrf(ctx, &pb.UpdateVrfRequest{Vrf: vrf, AllowMissing: true}))
+		},
+		delete: func(ctx context.Context, name string) error {
+			_, err := c.DeleteVrf(ctx, &pb.DeleteVrfRequest{Name: name, AllowMissing: true})
+			return err
+		},
+	}
+}
+
+// logicalBridgeKind binds the LogicalBridge custom resource to the LogicalBridgeService
+func logicalBridgeKind(c pb.LogicalBridgeServiceClient) *bridgeKind[*v1alpha1.LogicalBridge] {
+	state := func(lb *pb.LogicalBridge, err error) (*bridgeState, error) {
+		if err != nil {
+			return nil, err
+		}
+		return &bridgeState{operStatus: operStatus(lb.GetStatus().GetOperStatus(), "LB_OPER_STATUS_"), components: lb.GetStatus().GetComponents()}, nil
+	}
+	return &bridgeKind[*v1alpha1.LogicalBridge]{
+		collection: "bridges",
+		newObject:  func() *v1alpha1.LogicalBridge { return &v1alpha1.LogicalBridge{} },
+		get: func(ctx context.Context, name string) (*bridgeState, error) {
+			return state(c.GetLogicalBridge(ctx, &pb.GetLogicalBridgeRequest{Name: name}))
+		},
+		create: func(ctx context.Context, obj *v1alpha1.LogicalBridge) (*bridgeState, error) {
+			lb, err := obj.ToPb()
+			if err != nil {
+				return nil, err
+			}
+			return state(c.CreateLogicalBridge(ctx, &pb.CreateLogicalBridgeRequest{LogicalBridgeId: obj.Name, LogicalBridge: lb}))
+		},
+		update: func(ctx context.Context, obj *v1alpha1.LogicalBridge) (*bridgeState, error) {
+			lb, err := obj.ToPb()
+			if err != nil {
+				return nil, err
+			}
+			return state(c.UpdateLogicalBridge(ctx, &pb.UpdateLogicalBridgeRequest{LogicalBridge: lb, AllowMissing: true}))
+		},
+		delete: func(ctx context.Context, name string) error {
+			_, err := c.DeleteLogicalBridge(ctx, &pb.DeleteLogicalBridgeRequest{Name: name, AllowMissing: true})
+			return err
+		},
+	}
+}
+
+// bridgePortKind binds the BridgePort custom resource to the BridgePortService
+func bridgePortKind(c pb.BridgePortServiceClient) *bridgeKind[*v1alpha1.BridgePort] {
+	state := func(bp *pb.BridgePort, err error) (*bridgeState, error) {
+		if err != nil {
+			return nil, err
+		}
+		return &bridgeState{operStatus: operStatus(bp.GetStatus().GetOperStatus(), "BP_OPER_STATUS_"), components: bp.GetStatus().GetComponents()}, nil
+	}
+	return &bridgeKind[*v1alpha1.BridgePort]{
+		collection: "ports",
+		newObject:  func() *v1alpha1.BridgePort { return &v1alpha1.BridgePort{} },
+		get: func(ctx context.Context, name string) (*bridgeState, error) {
+			return state(c.GetBridgePort(ctx, &pb.GetBridgePortRequest{Name: name}))
+		},
+		create: func(ctx context.Context, obj *v1alpha1.BridgePort) (*bridgeState, error) {
+			bp, err := obj.ToPb()
+			if err != nil {
+				return nil, err
+			}
+			return state(c.CreateBridgePort(ctx, &pb.CreateBridgePortRequest{BridgePortId: obj.Name, BridgePort: bp}))
+		},
+		update: func(ctx context.Context, obj *v1alpha1.BridgePort) (*bridgeState, error) {
+			bp, err := obj.ToPb()
+			if err != nil {
+				return nil, err
+			}
+			return state(c.UpdateBridgePort(ctx, &pb.UpdateBridgePortRequest{BridgePort: bp, AllowMissing: true}))
+		},
+		delete: func(ctx context.Context, name string) error {
+			_, err := c.DeleteBridgePort(ctx, &pb.DeleteBridgePortRequest{Name: name, AllowMissing: true})
+			return err
+		},
+	}
+}
+
+// sviKind binds the Svi custom resource to the SviService
+func sviKind(c pb.SviServiceClient) *bridgeKind[*v1alpha1.Svi] {
+	state := func(svi *pb.Svi, err error) (*bridgeState, error) {
+		if err != nil {
+			return nil, err
+		}
+		return &bridgeState{operStatus: operStatus(svi.GetStatus().GetOperStatus(), "SVI_OPER_STATUS_"), components: svi.GetStatus().GetComponents()}, nil
+	}
+	return &bridgeKind[*v1alpha1.Svi]{
+		collection: "svis",
+		newObject:  func() *v1alpha1.Svi { return &v1alpha1.Svi{} },
+		get: func(ctx context.Context, name string) (*bridgeState, error) {
+			return state(c.GetSvi(ctx, &pb.GetSviRequest{Name: name}))
+		},
+		create: func(ctx context.Context, obj *v1alpha1.Svi) (*bridgeState, error) {
+			svi, err := obj.ToPb()
+			if err != nil {
+				return nil, err
+			}
+			return state(c.CreateSvi(ctx, &pb.CreateSviRequest{SviId: obj.Name, Svi: svi}))
+		},
+		update: func(ctx context.Context, obj *v1alpha1.Svi) (*bridgeState, error) {
+			svi, err := obj.ToPb()
+			if err != nil {
+				return nil, err
+			}
+			return state(c.UpdateSvi(ctx, &pb.UpdateSviRequest{Svi: svi, AllowMissing: true}))
+		},
+		delete: func(ctx context.Context, name string) error {
+			_, err := c.DeleteSvi(ctx, &pb.DeleteSviRequest{Name: name, AllowMissing: true})
+			return err
+		},
+	}
+}
+
+// SetupWithManager registers the reconcilers of all the custom resources, talking to the bridge over conn
+func SetupWithManager(mgr ctrl.Manager, conn grpc.ClientConnInterface) error {
+	if err := vrfKind(pb.NewVrfServiceClient(conn)).setup(mgr); err != nil {
+		return err
+	}
+	if err := logicalBridgeKind(pb.NewLogicalBridgeServiceClient(conn)).setup(mgr); err != nil {
+		return err
+	}
+	if err := bridgePortKind(pb.NewBridgePortServiceClient(conn)).setup(mgr); err != nil {
+		return err
+	}
+	return sviKind(pb.NewSviServiceClient(conn)).setup(mgr)
+}
@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package operator
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/operator/api/v1alpha1"
+)
+
+// finalizer keeps the custom resource until the object is deleted from the bridge
+const finalizer = "evpn.opiproject.org/finalizer"
+
+var (
+	// pendingInterval is the requeue interval while the bridge has not brought the object up
+	pendingInterval = 5 * time.Second
+	// resyncInterval is the requeue interval used to detect objects lost by the bridge
+	resyncInterval = time.Minute
+)
+
+// bridgeObject is a custom resource mirrored into the bridge
+type bridgeObject interface {
+	client.Object
+	BridgeStatusOf() *v1alpha1.BridgeStatus
+}
+
+// bridgeState is the state of an object as returned by the bridge
+type bridgeState struct {
+	operStatus string
+	components []*pb.Component
+}
+
+// bridgeKind binds a custom resource to the gRPC service of the bridge
+type bridgeKind[T bridgeObject] struct {
+	collection string
+	newObject  func() T
+	get        func(ctx context.Context, name string) (*bridgeState, error)
+	create     func(ctx context.Context, obj T) (*bridgeState, error)
+	update     func(ctx context.Context, obj T) (*bridgeState, error)
+	delete     func(ctx context.Context, name string) error
+}
+
+// reconciler reconciles one kind of custom resource
+type reconciler[T bridgeObject] struct {
+	client.Client
+	kind *bridgeKind[T]
+}
+
+// Reconcile pushes the custom resource to the bridge and writes the bridge status back
+func (r *reconciler[T]) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	obj := r.kind.newObject()
+	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	name := fullName(r.kind.collection, obj.GetName())
+
+	if !obj.GetDeletionTimestamp().IsZero() {
+		if !controllerutil.ContainsFinalizer(obj, finalizer) {
+			return ctrl.Result{}, nil
+		}
+		if err := r.kind.delete(ctx, name); err != nil {
+			logger.Error(err, "failed to delete from the bridge", "name", name)
+			return r.writeStatus(ctx, obj, name, nil, err)
+		}
+		controllerutil.RemoveFinalizer(obj, finalizer)
+		return ctrl.Result{}, r.Update(ctx, obj)
+	}
+
+	if controllerutil.AddFinalizer(obj, finalizer) {
+		if err := r.Update(ctx, obj); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	state, err := r.kind.get(ctx, name)
+	switch {
+	case status.Code(err) == codes.NotFound:
+		logger.Info("creating in the bridge", "name", name)
+		state, err = r.kind.create(ctx, obj)
+	case err == nil && obj.BridgeStatusOf().ObservedGeneration != obj.GetGeneration():
+		logger.Info("updating in the bridge", "name", name)
+		state, err = r.kind.update(ctx, obj)
+	}
+	return r.writeStatus(ctx, obj, name, state, err)
+}
+
+// writeStatus writes the bridge state, or the error returned by the bridge, to the status of the custom resource
+func (r *reconciler[T]) writeStatus(ctx context.Context, obj T, name string, state *bridgeState, bridgeErr error) (ctrl.Result, error) {
+	st := obj.BridgeStatusOf()
+	st.Name = name
+	if bridgeErr != nil {
+		st.Message = bridgeErr.Error()
+	} else {
+		st.Message = ""
+		st.OperStatus = state.operStatus
+		st.Components = componentsFromPb(state.components)
+		st.ObservedGeneration = obj.GetGeneration()
+	}
+	if err := r.Status().Update(ctx, obj); err != nil {
+		return ctrl.Result{}, err
+	}
+	if bridgeErr != nil {
+		return ctrl.Result{}, bridgeErr
+	}
+	if st.OperStatus != "UP" {
+		return ctrl.Result{RequeueAfter: pendingInterval}, nil
+	}
+	return ctrl.Result{RequeueAfter: resyncInterval}, nil
+}
+
+// setup registers the reconciler of the kind with the manager
+func (k *bridgeKind[T]) setup(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(k.newObject(), builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(&reconciler[T]{Client: mgr.GetClient(), kind: k})
+}
+
+// operStatus strips the enum prefix of the oper status returned by the bridge
+func operStatus(s interface{ String() string }, prefix string) string {
+	return strings.TrimPrefix(s.String(), prefix)
+}
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package operator
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/operator/api/v1alpha1"
+)
+
+// fakeVrfClient is an in-memory VrfServiceClient
+type fakeVrfClient struct {
+	pb.VrfServiceClient
+	vrfs      map[string]*pb.Vrf
+	createErr error
+}
+
+func (f *fakeVrfClient) GetVrf(_ context.Context, in *pb.GetVrfRequest, _ ...grpc.CallOption) (*pb.Vrf, error) {
+	vrf, ok := f.vrfs[in.Name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+	}
+	return vrf, nil
+}
+
+func (f *fakeVrfClient) CreateVrf(_ context.Context, in *pb.CreateVrfRequest, _ ...grpc.CallOption) (*pb.Vrf, error) {
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	in.Vrf.Status = &pb.VrfStatus{
+		OperStatus: pb.VRFOperStatus_VRF_OPER_STATUS_UP,
+		Components: []*pb.Component{{Name: "frr", Status: pb.CompStatus_COMP_STATUS_SUCCESS}},
+	}
+	f.vrfs[in.Vrf.Name] = in.Vrf
+	return in.Vrf, nil
+}
+
+func (f *fakeVrfClient) DeleteVrf(_ context.Context, in *pb.DeleteVrfRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	delete(f.vrfs, in.Name)
+	return &emptypb.Empty{}, nil
+}
+
+func Test_ReconcileVrf(t *testing.T) {
+	vni := uint32(1000)
+	tests := map[string]struct {
+		spec       v1alpha1.VrfSpec
+		createErr  error
+		wantErr    bool
+		operStatus string
+		message    string
+	}{
+		"created and up": {
+			spec:       v1alpha1.VrfSpec{Vni: &vni, LoopbackIPPrefix: "10.0.0.1/32", VtepIPPrefix: "10.1.0.1/32"},
+			operStatus: "UP",
+		},
+		"invalid prefix": {
+			spec:    v1alpha1.VrfSpec{LoopbackIPPrefix: "10.0.0.1"},
+			wantErr: true,
+			message: "invalid loopbackIpPrefix: invalid CIDR address: 10.0.0.1",
+		},
+		"rejected by the bridge": {
+			spec:      v1alpha1.VrfSpec{Vni: &vni},
+			createErr: status.Error(codes.InvalidArgument, "vni in use"),
+			wantErr:   true,
+			message:   "rpc error: code = InvalidArgument desc = vni in use",
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := v1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			cr := &v1alpha1.Vrf{ObjectMeta: metav1.ObjectMeta{Name: "blue", Generation: 1}, Spec: tt.spec}
+			k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr).WithStatusSubresource(cr).Build()
+			bridge := &fakeVrfClient{vrfs: map[string]*pb.Vrf{}, createErr: tt.createErr}
+			r := &reconciler[*v1alpha1.Vrf]{Client: k8s, kind: vrfKind(bridge)}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "blue"}}
+
+			_, err := r.Reconcile(context.Background(), req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reconcile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got := &v1alpha1.Vrf{}
+			if err := k8s.Get(context.Background(), req.NamespacedName, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status.Name != "//network.opiproject.org/vrfs/blue" {
+				t.Errorf("status name = %q", got.Status.Name)
+			}
+			if got.Status.OperStatus != tt.operStatus {
+				t.Errorf("oper status = %q, want %q", got.Status.OperStatus, tt.operStatus)
+			}
+			if got.Status.Message != tt.message {
+				t.Errorf("message = %q, want %q", got.Status.Message, tt.message)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got.Status.Components) != 1 || got.Status.Components[0].Status != "SUCCESS" {
+				t.Errorf("components = %+v", got.Status.Components)
+			}
+			if len(got.Finalizers) != 1 || got.Finalizers[0] != finalizer {
+				t.Errorf("finalizers = %v", got.Finalizers)
+			}
+
+			// deleting the custom resource deletes the bridge object, then releases the finalizer
+			if err := k8s.Delete(context.Background(), got); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Reconcile(context.Background(), req); err != nil {
+				t.Fatalf("Reconcile() on delete error = %v", err)
+			}
+			if len(bridge.vrfs) != 0 {
+				t.Errorf("bridge still holds %v", bridge.vrfs)
+			}
+			if err := k8s.Get(context.Background(), req.NamespacedName, got); err == nil {
+				t.Errorf("custom resource still exists after its finalizer was released")
+			}
+		})
+	}
+}
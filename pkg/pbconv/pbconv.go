@@ -0,0 +1,267 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package pbconv converts the opi-api EVPN gateway messages to the domain objects
+// of infradb and back. It is the only place below the gRPC servers where the
+// protobuf types are known, the storage and the dataplane modules only see the
+// domain objects, so a new version of the API does not ripple through them.
+package pbconv
+
+import (
+	"fmt"
+	"net"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	opinetcommon "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+)
+
+// VrfFromPb creates a VRF object from its protobuf message
+func VrfFromPb(in *pb.Vrf) (*infradb.Vrf, error) {
+	var lip, vip *net.IPNet
+	var err error
+
+	// Parse the optional loopback IP
+	if in.Spec.LoopbackIpPrefix != nil {
+		if lip, err = common.ConvertToIPNet(in.Spec.LoopbackIpPrefix); err != nil {
+			return nil, fmt.Errorf("VrfFromPb(): invalid loopback ip prefix: %w", err)
+		}
+	}
+
+	// Parse vtep IP
+	if in.Spec.VtepIpPrefix != nil {
+		if vip, err = common.ConvertToIPNet(in.Spec.VtepIpPrefix); err != nil {
+			return nil, fmt.Errorf("VrfFromPb(): invalid vtep ip prefix: %w", err)
+		}
+	}
+
+	return infradb.NewVrf(in.Name, &infradb.VrfSpec{
+		Vni:        in.Spec.Vni,
+		LoopbackIP: lip,
+		VtepIP:     vip,
+	})
+}
+
+// VrfToPb transforms a VRF object to protobuf message
+func VrfToPb(in *infradb.Vrf) *pb.Vrf {
+	vrf := &pb.Vrf{
+		Name: in.Name,
+		Spec: &pb.VrfSpec{
+			Vni:              in.Spec.Vni,
+			LoopbackIpPrefix: common.ConvertToIPPrefix(in.Spec.LoopbackIP),
+			VtepIpPrefix:     common.ConvertToIPPrefix(in.Spec.VtepIP),
+		},
+		Status: &pb.VrfStatus{
+			Components: componentsToPb(in.Status.Components),
+		},
+	}
+
+	switch in.Status.VrfOperStatus {
+	case infradb.VrfOperStatusDown:
+		vrf.Status.OperStatus = pb.VRFOperStatus_VRF_OPER_STATUS_DOWN
+	case infradb.VrfOperStatusUp:
+		vrf.Status.OperStatus = pb.VRFOperStatus_VRF_OPER_STATUS_UP
+	case infradb.VrfOperStatusToBeDeleted:
+		vrf.Status.OperStatus = pb.VRFOperStatus_VRF_OPER_STATUS_TO_BE_DELETED
+	default:
+		vrf.Status.OperStatus = pb.VRFOperStatus_VRF_OPER_STATUS_UNSPECIFIED
+	}
+	return vrf
+}
+
+// LogicalBridgeFromPb creates a Logical Bridge object from its protobuf message
+func LogicalBridgeFromPb(in *pb.LogicalBridge) (*infradb.LogicalBridge, error) {
+	var vip *net.IPNet
+
+	// Parse vtep IP
+	if in.Spec.VtepIpPrefix != nil {
+		var err error
+		if vip, err = common.ConvertToIPNet(in.Spec.VtepIpPrefix); err != nil {
+			return nil, fmt.Errorf("LogicalBridgeFromPb(): invalid vtep ip prefix: %w", err)
+		}
+	}
+
+	return infradb.NewLogicalBridge(in.Name, &infradb.LogicalBridgeSpec{
+		VlanID: in.Spec.VlanId,
+		Vni:    in.Spec.Vni,
+		VtepIP: vip,
+	})
+}
+
+// LogicalBridgeToPb transforms a Logical Bridge object to protobuf message
+func LogicalBridgeToPb(in *infradb.LogicalBridge) *pb.LogicalBridge {
+	lb := &pb.LogicalBridge{
+		Name: in.Name,
+		Spec: &pb.LogicalBridgeSpec{
+			VlanId:       in.Spec.VlanID,
+			Vni:          in.Spec.Vni,
+			VtepIpPrefix: common.ConvertToIPPrefix(in.Spec.VtepIP),
+		},
+		Status: &pb.LogicalBridgeStatus{
+			Components: componentsToPb(in.Status.Components),
+		},
+	}
+
+	switch in.Status.LBOperStatus {
+	case infradb.LogicalBridgeOperStatusDown:
+		lb.Status.OperStatus = pb.LBOperStatus_LB_OPER_STATUS_DOWN
+	case infradb.LogicalBridgeOperStatusUp:
+		lb.Status.OperStatus = pb.LBOperStatus_LB_OPER_STATUS_UP
+	case infradb.LogicalBridgeOperStatusToBeDeleted:
+		lb.Status.OperStatus = pb.LBOperStatus_LB_OPER_STATUS_TO_BE_DELETED
+	default:
+		lb.Status.OperStatus = pb.LBOperStatus_LB_OPER_STATUS_UNSPECIFIED
+	}
+	return lb
+}
+
+// BridgePortFromPb creates a Bridge Port object from its protobuf message
+func BridgePortFromPb(in *pb.BridgePort) (*infradb.BridgePort, error) {
+	var bpType infradb.BridgePortType
+
+	// Tansform Mac From Byte to net.HardwareAddr type
+	macAddr := net.HardwareAddr(in.Spec.MacAddress)
+
+	switch in.Spec.Ptype {
+	case pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS:
+		bpType = infradb.Access
+	case pb.BridgePortType_BRIDGE_PORT_TYPE_TRUNK:
+		bpType = infradb.Trunk
+	default:
+		bpType = infradb.Unspecified
+	}
+
+	return infradb.NewBridgePort(in.Name, &infradb.BridgePortSpec{
+		Ptype:          bpType,
+		MacAddress:     &macAddr,
+		LogicalBridges: in.Spec.LogicalBridges,
+	})
+}
+
+// BridgePortToPb transforms a Bridge Port object to protobuf message
+func BridgePortToPb(in *infradb.BridgePort) *pb.BridgePort {
+	bp := &pb.BridgePort{
+		Name: in.Name,
+		Spec: &pb.BridgePortSpec{
+			MacAddress: *in.Spec.MacAddress,
+		},
+		Status: &pb.BridgePortStatus{
+			Components: componentsToPb(in.Status.Components),
+		},
+	}
+
+	switch in.Spec.Ptype {
+	case infradb.Access:
+		bp.Spec.Ptype = pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS
+	case infradb.Trunk:
+		bp.Spec.Ptype = pb.BridgePortType_BRIDGE_PORT_TYPE_TRUNK
+	default:
+		bp.Spec.Ptype = pb.BridgePortType_BRIDGE_PORT_TYPE_UNSPECIFIED
+	}
+
+	if !in.TransparentTrunk {
+		bp.Spec.LogicalBridges = in.Spec.LogicalBridges
+	}
+
+	switch in.Status.BPOperStatus {
+	case infradb.BridgePortOperStatusDown:
+		bp.Status.OperStatus = pb.BPOperStatus_BP_OPER_STATUS_DOWN
+	case infradb.BridgePortOperStatusUp:
+		bp.Status.OperStatus = pb.BPOperStatus_BP_OPER_STATUS_UP
+	case infradb.BridgePortOperStatusToBeDeleted:
+		bp.Status.OperStatus = pb.BPOperStatus_BP_OPER_STATUS_TO_BE_DELETED
+	default:
+		bp.Status.OperStatus = pb.BPOperStatus_BP_OPER_STATUS_UNSPECIFIED
+	}
+	return bp
+}
+
+// SviFromPb creates an SVI object from its protobuf message
+func SviFromPb(in *pb.Svi) (*infradb.Svi, error) {
+	gwIPs := make([]*net.IPNet, 0)
+
+	// Tansform Mac From Byte to net.HardwareAddr type
+	macAddr := net.HardwareAddr(in.Spec.MacAddress)
+
+	// Parse Gateway IPs
+	for _, gwIPPrefix := range in.Spec.GwIpPrefix {
+		gwIP, err := common.ConvertToDualStackIPNet(gwIPPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("SviFromPb(): invalid gateway ip prefix: %w", err)
+		}
+		gwIPs = append(gwIPs, gwIP)
+	}
+
+	remoteAs := in.Spec.RemoteAs
+	return infradb.NewSvi(in.Name, &infradb.SviSpec{
+		Vrf:           in.Spec.Vrf,
+		LogicalBridge: in.Spec.LogicalBridge,
+		MacAddress:    &macAddr,
+		GatewayIPs:    gwIPs,
+		EnableBgp:     in.Spec.EnableBgp,
+		RemoteAs:      &remoteAs,
+	})
+}
+
+// SviToPb transforms an SVI object to protobuf message
+func SviToPb(in *infradb.Svi) *pb.Svi {
+	gatewayIPs := make([]*opinetcommon.IPPrefix, 0)
+
+	// the gateway prefixes as they were given, the SVIs stored before they were kept have their addresses only
+	gwPrefixes := in.Spec.GatewayPrefixes
+	if gwPrefixes == nil {
+		gwPrefixes = in.Spec.GatewayIPs
+	}
+	for _, gwIP := range gwPrefixes {
+		gatewayIPs = append(gatewayIPs, common.ConvertToIPPrefix(gwIP))
+	}
+
+	svi := &pb.Svi{
+		Name: in.Name,
+		Spec: &pb.SviSpec{
+			Vrf:           in.Spec.Vrf,
+			LogicalBridge: in.Spec.LogicalBridge,
+			MacAddress:    *in.Spec.MacAddress,
+			GwIpPrefix:    gatewayIPs,
+			EnableBgp:     in.Spec.EnableBgp,
+			RemoteAs:      *in.Spec.RemoteAs,
+		},
+		Status: &pb.SviStatus{
+			Components: componentsToPb(in.Status.Components),
+		},
+	}
+
+	switch in.Status.SviOperStatus {
+	case infradb.SviOperStatusDown:
+		svi.Status.OperStatus = pb.SVIOperStatus_SVI_OPER_STATUS_DOWN
+	case infradb.SviOperStatusUp:
+		svi.Status.OperStatus = pb.SVIOperStatus_SVI_OPER_STATUS_UP
+	case infradb.SviOperStatusToBeDeleted:
+		svi.Status.OperStatus = pb.SVIOperStatus_SVI_OPER_STATUS_TO_BE_DELETED
+	default:
+		svi.Status.OperStatus = pb.SVIOperStatus_SVI_OPER_STATUS_UNSPECIFIED
+	}
+	return svi
+}
+
+func componentsToPb(comps []common.Component) []*pb.Component {
+	var components []*pb.Component
+	for _, comp := range comps {
+		component := &pb.Component{Name: comp.Name, Details: comp.Details}
+		switch comp.CompStatus {
+		case common.ComponentStatusPending:
+			component.Status = pb.CompStatus_COMP_STATUS_PENDING
+		case common.ComponentStatusSuccess:
+			component.Status = pb.CompStatus_COMP_STATUS_SUCCESS
+		case common.ComponentStatusError:
+			component.Status = pb.CompStatus_COMP_STATUS_ERROR
+		default:
+			component.Status = pb.CompStatus_COMP_STATUS_UNSPECIFIED
+		}
+		components = append(components, component)
+	}
+	return components
+}
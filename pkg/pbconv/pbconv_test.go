@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package pbconv converts the opi-api EVPN gateway messages to the domain objects
+// of infradb and back
+package pbconv
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+func init() {
+	for _, eventType := range []string{"vrf", "logical-bridge", "bridge-port", "svi"} {
+		eventbus.EBus.StartSubscriber("dummy", eventType, 1, nil)
+	}
+}
+
+var (
+	testVni    = uint32(1000)
+	testPrefix = &pc.IPPrefix{Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 167772162}}, Len: 24}
+	testMac    = []byte{0xaa, 0xbb, 0xcc, 0x00, 0x00, 0x01}
+	testStatus = []*pb.Component{{Name: "dummy", Status: pb.CompStatus_COMP_STATUS_PENDING}}
+)
+
+func TestRoundTrip(t *testing.T) {
+	vrf := &pb.Vrf{
+		Name:   "//network.opiproject.org/vrfs/blue",
+		Spec:   &pb.VrfSpec{Vni: &testVni, LoopbackIpPrefix: testPrefix, VtepIpPrefix: testPrefix},
+		Status: &pb.VrfStatus{OperStatus: pb.VRFOperStatus_VRF_OPER_STATUS_DOWN, Components: testStatus},
+	}
+	lb := &pb.LogicalBridge{
+		Name:   "//network.opiproject.org/bridges/web",
+		Spec:   &pb.LogicalBridgeSpec{VlanId: 10, Vni: &testVni, VtepIpPrefix: testPrefix},
+		Status: &pb.LogicalBridgeStatus{OperStatus: pb.LBOperStatus_LB_OPER_STATUS_DOWN, Components: testStatus},
+	}
+	bp := &pb.BridgePort{
+		Name: "//network.opiproject.org/ports/eth2",
+		Spec: &pb.BridgePortSpec{MacAddress: testMac, Ptype: pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS,
+			LogicalBridges: []string{lb.Name}},
+		Status: &pb.BridgePortStatus{OperStatus: pb.BPOperStatus_BP_OPER_STATUS_DOWN, Components: testStatus},
+	}
+	svi := &pb.Svi{
+		Name: "//network.opiproject.org/svis/web",
+		Spec: &pb.SviSpec{Vrf: vrf.Name, LogicalBridge: lb.Name, MacAddress: testMac,
+			GwIpPrefix: []*pc.IPPrefix{testPrefix}, EnableBgp: true, RemoteAs: 65000},
+		Status: &pb.SviStatus{OperStatus: pb.SVIOperStatus_SVI_OPER_STATUS_DOWN, Components: testStatus},
+	}
+
+	domainVrf, err := VrfFromPb(vrf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	domainLB, err := LogicalBridgeFromPb(lb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	domainBP, err := BridgePortFromPb(bp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	domainSvi, err := SviFromPb(svi)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct{ want, got proto.Message }{
+		{vrf, VrfToPb(domainVrf)},
+		{lb, LogicalBridgeToPb(domainLB)},
+		{bp, BridgePortToPb(domainBP)},
+		{svi, SviToPb(domainSvi)},
+	} {
+		if !proto.Equal(tt.want, tt.got) {
+			t.Errorf("expected %v, received %v", tt.want, tt.got)
+		}
+	}
+
+	// the domain object does not point into the message it was converted from
+	svi.Spec.RemoteAs = 65001
+	if *domainSvi.Spec.RemoteAs != 65000 {
+		t.Errorf("expected the remote AS of the SVI to stay 65000, received %v", *domainSvi.Spec.RemoteAs)
+	}
+}
+
+func TestInvalidPrefix(t *testing.T) {
+	vrf := &pb.Vrf{
+		Name: "//network.opiproject.org/vrfs/blue",
+		Spec: &pb.VrfSpec{VtepIpPrefix: &pc.IPPrefix{Addr: testPrefix.Addr, Len: 33}},
+	}
+	if _, err := VrfFromPb(vrf); err == nil {
+		t.Errorf("expected the vtep prefix length 33 to be refused")
+	}
+}
@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package placement places the objects of a controller on a single node agent, so that a fleet of DPUs
+// is orchestrated through the API of the controller
+package placement
+
+import (
+	"context"
+	"log"
+	"path"
+	"strings"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"go.einride.tech/aip/resourcename"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/remote"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// NodeHeader is the request header naming the node agent the created objects are placed on, and the node
+// whose objects are listed
+const NodeHeader = "node"
+
+// nodeOf returns the node of the call, or an empty node when the call is not placed
+func nodeOf(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(NodeHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// describe names the nodes an object with the placement lives on
+func describe(node string) string {
+	if node == "" {
+		return "all the nodes"
+	}
+	return "node " + node
+}
+
+// createdName returns the name of the object a Create call targets, or an empty name when the id is generated
+func createdName(req interface{}) string {
+	join := func(collection, id string) string {
+		if id == "" {
+			return ""
+		}
+		return resourcename.Join("//network.opiproject.org/", collection, id)
+	}
+	switch in := req.(type) {
+	case *pb.CreateVrfRequest:
+		return join("vrfs", in.VrfId)
+	case *pb.CreateLogicalBridgeRequest:
+		return join("bridges", in.LogicalBridgeId)
+	case *pb.CreateBridgePortRequest:
+		return join("ports", in.BridgePortId)
+	case *pb.CreateSviRequest:
+		return join("svis", in.SviId)
+	}
+	return ""
+}
+
+// checkReferences rejects the references to the objects which do not live on the node of the object
+func checkReferences(req interface{}, node string) error {
+	refs := []string{}
+	switch in := req.(type) {
+	case *pb.CreateSviRequest:
+		refs = append(refs, in.GetSvi().GetSpec().GetVrf(), in.GetSvi().GetSpec().GetLogicalBridge())
+	case *pb.UpdateSviRequest:
+		refs = append(refs, in.GetSvi().GetSpec().GetVrf(), in.GetSvi().GetSpec().GetLogicalBridge())
+	case *pb.CreateBridgePortRequest:
+		refs = append(refs, in.GetBridgePort().GetSpec().GetLogicalBridges()...)
+	case *pb.UpdateBridgePortRequest:
+		refs = append(refs, in.GetBridgePort().GetSpec().GetLogicalBridges()...)
+	}
+	for _, ref := range refs {
+		if ref == "" {
+			continue
+		}
+		parent, err := infradb.GetPlacement(ref)
+		if err != nil {
+			return err
+		}
+		if parent != node {
+			return status.Errorf(codes.InvalidArgument, "%s lives on %s, not on %s", ref, describe(parent), describe(node))
+		}
+	}
+	return nil
+}
+
+// filter returns the elements of the list which live on the node
+func filter[T interface {
+	proto.Message
+	GetName() string
+}](objs []T, placements map[string]string, node string) []T {
+	out := []T{}
+	for _, obj := range objs {
+		if p := placements[obj.GetName()]; p == "" || p == node {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+// filterList removes the objects placed on the other nodes from a List response. The pages may hold
+// fewer elements than requested, the next page token remains valid.
+func filterList(resp interface{}, node string) error {
+	placements, err := infradb.GetPlacements()
+	if err != nil {
+		return err
+	}
+	switch out := resp.(type) {
+	case *pb.ListVrfsResponse:
+		out.Vrfs = filter(out.Vrfs, placements, node)
+	case *pb.ListLogicalBridgesResponse:
+		out.LogicalBridges = filter(out.LogicalBridges, placements, node)
+	case *pb.ListBridgePortsResponse:
+		out.BridgePorts = filter(out.BridgePorts, placements, node)
+	case *pb.ListSvisResponse:
+		out.Svis = filter(out.Svis, placements, node)
+	}
+	return nil
+}
+
+// place checks the placement of the object a Create or Update call writes, and records the node of a new
+// object before it is stored so that the controller never sends it to the other nodes
+func place(req interface{}, name, node string) (bool, error) {
+	version, err := infradb.GetResourceVersion(name)
+	if err != nil {
+		return false, err
+	}
+	current, err := infradb.GetPlacement(name)
+	if err != nil {
+		return false, err
+	}
+	if version != "" && node != "" && node != current {
+		return false, status.Errorf(codes.FailedPrecondition, "%s lives on %s, its placement cannot change", name, describe(current))
+	}
+	if version != "" || node == "" {
+		// an unplaced call keeps the placement of the object
+		return false, checkReferences(req, current)
+	}
+	if err := checkReferences(req, node); err != nil {
+		return false, err
+	}
+	if err := infradb.SetPlacement(name, node); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UnaryServerInterceptor places the objects created with a node header on that node agent, and lists
+// the objects living on the node of the header: the ones placed on it and the ones placed on all the
+// nodes. The parents an object references must live on its node.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := path.Base(info.FullMethod)
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+		node := nodeOf(ctx)
+		if config.GlobalConfig.Remote.Mode != remote.ModeController {
+			if node != "" {
+				return nil, status.Error(codes.FailedPrecondition, "the objects are placed on the node agents of a controller")
+			}
+			return handler(ctx, req)
+		}
+
+		switch {
+		case strings.HasPrefix(method, "List"):
+			resp, err := handler(ctx, req)
+			if err == nil && node != "" {
+				err = filterList(resp, node)
+			}
+			return resp, err
+		case strings.HasPrefix(method, "Create"), strings.HasPrefix(method, "Update"):
+			name := utils.ObjectName(msg)
+			if strings.HasPrefix(method, "Create") {
+				name = createdName(req)
+			}
+			if name == "" {
+				if node != "" {
+					return nil, status.Errorf(codes.InvalidArgument, "the id of an object placed on node %s must be given", node)
+				}
+				if err := checkReferences(req, ""); err != nil {
+					return nil, err
+				}
+				return handler(ctx, req)
+			}
+			placed, err := place(req, name, node)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := handler(ctx, req)
+			if err != nil && placed {
+				if err := infradb.DeletePlacement(name); err != nil {
+					log.Printf("%s(): failed to forget the placement: %v", method, err)
+				}
+			}
+			return resp, err
+		case strings.HasPrefix(method, "Delete"):
+			resp, err := handler(ctx, req)
+			if name := utils.ObjectName(msg); err == nil && name != "" {
+				if err := infradb.DeletePlacement(name); err != nil {
+					log.Printf("%s(): failed to forget the placement: %v", method, err)
+				}
+			}
+			return resp, err
+		default:
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package placement places the objects of a controller on a single node agent, so that a fleet of DPUs
+// is orchestrated through the API of the controller
+package placement
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/remote"
+	"github.com/opiproject/opi-evpn-bridge/pkg/svi"
+	"github.com/opiproject/opi-evpn-bridge/pkg/vrf"
+)
+
+const (
+	vrfA    = "//network.opiproject.org/vrfs/vrf-a"
+	bridgeA = "//network.opiproject.org/bridges/bridge-a"
+	bridgeB = "//network.opiproject.org/bridges/bridge-b"
+)
+
+// testClients are the clients of the bridge API of a controller
+type testClients struct {
+	vrf    pb.VrfServiceClient
+	bridge pb.LogicalBridgeServiceClient
+	svi    pb.SviServiceClient
+}
+
+// newTestClients serves the bridge API of a controller with the interceptor on top of an empty gomap db
+func newTestClients(t *testing.T) *testClients {
+	config.GlobalConfig.Remote.Mode = remote.ModeController
+	t.Cleanup(func() { config.GlobalConfig.Remote.Mode = "" })
+	eb := eventbus.EBus
+	for _, eventType := range []string{"vrf", "logical-bridge", "svi"} {
+		eb.StartSubscriber("dummy", eventType, 1, nil)
+	}
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor()))
+	pb.RegisterVrfServiceServer(s, vrf.NewServer())
+	pb.RegisterLogicalBridgeServiceServer(s, bridge.NewServer())
+	pb.RegisterSviServiceServer(s, svi.NewServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return &testClients{
+		vrf:    pb.NewVrfServiceClient(conn),
+		bridge: pb.NewLogicalBridgeServiceClient(conn),
+		svi:    pb.NewSviServiceClient(conn),
+	}
+}
+
+// onNode returns a context carrying the node header
+func onNode(node string) context.Context {
+	if node == "" {
+		return context.Background()
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), NodeHeader, node)
+}
+
+// prefix returns the IPv4 prefix addr/length
+func prefix(addr uint32, length int32) *pc.IPPrefix {
+	return &pc.IPPrefix{Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: addr}}, Len: length}
+}
+
+// sviOf returns an svi of the vrf a on the logical bridge
+func sviOf(lb string) *pb.Svi {
+	return &pb.Svi{Spec: &pb.SviSpec{
+		Vrf: vrfA, LogicalBridge: lb, MacAddress: []byte{0xaa, 0xbb, 0xcc, 0, 0, 1},
+		GwIpPrefix: []*pc.IPPrefix{prefix(0x0a0a0001, 24)},
+	}}
+}
+
+// createObjects creates the vrf a and the logical bridge a on the node dpu-1, and the logical bridge b on all the nodes
+func createObjects(t *testing.T, c *testClients) {
+	spec := &pb.VrfSpec{LoopbackIpPrefix: prefix(0x0a000001, 32), VtepIpPrefix: prefix(0x0a010001, 32)}
+	if _, err := c.vrf.CreateVrf(onNode("dpu-1"), &pb.CreateVrfRequest{VrfId: "vrf-a", Vrf: &pb.Vrf{Spec: spec}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.bridge.CreateLogicalBridge(onNode("dpu-1"), &pb.CreateLogicalBridgeRequest{LogicalBridgeId: "bridge-a",
+		LogicalBridge: &pb.LogicalBridge{Spec: &pb.LogicalBridgeSpec{VlanId: 10}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.bridge.CreateLogicalBridge(onNode(""), &pb.CreateLogicalBridgeRequest{LogicalBridgeId: "bridge-b",
+		LogicalBridge: &pb.LogicalBridge{Spec: &pb.LogicalBridgeSpec{VlanId: 20}}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Placement(t *testing.T) {
+	tests := map[string]struct {
+		call func(c *testClients) (int, error)
+		want int
+		code codes.Code
+	}{
+		"svi on the node of its parents": {
+			call: func(c *testClients) (int, error) {
+				_, err := c.svi.CreateSvi(onNode("dpu-1"), &pb.CreateSviRequest{SviId: "svi-a", Svi: sviOf(bridgeA)})
+				return 1, err
+			},
+			want: 1,
+		},
+		"svi on another node than its vrf": {
+			call: func(c *testClients) (int, error) {
+				_, err := c.svi.CreateSvi(onNode("dpu-2"), &pb.CreateSviRequest{SviId: "svi-a", Svi: sviOf(bridgeA)})
+				return 0, err
+			},
+			code: codes.InvalidArgument,
+		},
+		"unplaced svi referencing a placed vrf": {
+			call: func(c *testClients) (int, error) {
+				_, err := c.svi.CreateSvi(onNode(""), &pb.CreateSviRequest{SviId: "svi-b", Svi: sviOf(bridgeB)})
+				return 0, err
+			},
+			code: codes.InvalidArgument,
+		},
+		"placed object with a generated id": {
+			call: func(c *testClients) (int, error) {
+				_, err := c.bridge.CreateLogicalBridge(onNode("dpu-1"), &pb.CreateLogicalBridgeRequest{
+					LogicalBridge: &pb.LogicalBridge{Spec: &pb.LogicalBridgeSpec{VlanId: 30}}})
+				return 0, err
+			},
+			code: codes.InvalidArgument,
+		},
+		"placement does not change": {
+			call: func(c *testClients) (int, error) {
+				_, err := c.bridge.UpdateLogicalBridge(onNode("dpu-2"), &pb.UpdateLogicalBridgeRequest{
+					LogicalBridge: &pb.LogicalBridge{Name: bridgeA, Spec: &pb.LogicalBridgeSpec{VlanId: 10}}})
+				return 0, err
+			},
+			code: codes.FailedPrecondition,
+		},
+		"node lists its objects and the shared ones": {
+			call: func(c *testClients) (int, error) {
+				resp, err := c.bridge.ListLogicalBridges(onNode("dpu-1"), &pb.ListLogicalBridgesRequest{})
+				return len(resp.GetLogicalBridges()), err
+			},
+			want: 2,
+		},
+		"other node lists the shared objects": {
+			call: func(c *testClients) (int, error) {
+				resp, err := c.bridge.ListLogicalBridges(onNode("dpu-2"), &pb.ListLogicalBridgesRequest{})
+				return len(resp.GetLogicalBridges()), err
+			},
+			want: 1,
+		},
+		"unplaced list sees all the objects": {
+			call: func(c *testClients) (int, error) {
+				resp, err := c.bridge.ListLogicalBridges(onNode(""), &pb.ListLogicalBridgesRequest{})
+				return len(resp.GetLogicalBridges()), err
+			},
+			want: 2,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClients(t)
+			createObjects(t, c)
+			got, err := tt.call(c)
+			if status.Code(err) != tt.code {
+				t.Fatalf("expected code %v, got %v", tt.code, err)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("expected %d objects, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_DeleteForgetsPlacement(t *testing.T) {
+	c := newTestClients(t)
+	createObjects(t, c)
+	if placement, err := infradb.GetPlacement(bridgeA); err != nil || placement != "dpu-1" {
+		t.Fatalf("expected the bridge to be placed on dpu-1, got %q %v", placement, err)
+	}
+	if _, err := c.bridge.DeleteLogicalBridge(context.Background(), &pb.DeleteLogicalBridgeRequest{Name: bridgeA}); err != nil {
+		t.Fatal(err)
+	}
+	if placement, err := infradb.GetPlacement(bridgeA); err != nil || placement != "" {
+		t.Errorf("expected the placement to be forgotten, got %q %v", placement, err)
+	}
+}
+
+func Test_PlacementNeedsController(t *testing.T) {
+	c := newTestClients(t)
+	config.GlobalConfig.Remote.Mode = ""
+	_, err := c.bridge.CreateLogicalBridge(onNode("dpu-1"), &pb.CreateLogicalBridgeRequest{LogicalBridgeId: "bridge-c",
+		LogicalBridge: &pb.LogicalBridge{Spec: &pb.LogicalBridgeSpec{VlanId: 40}}})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected the placement to be refused, got %v", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"path"
 	"sort"
 	"testing"
 
@@ -24,8 +25,11 @@ import (
 	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
 
 	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/pbconv"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
 )
 
@@ -41,8 +45,15 @@ func (s *Server) createBridgePort(bp *pb.BridgePort) (*pb.BridgePort, error) {
 		return nil, err
 	}
 
+	// the Bridge Ports of the VF representors take entries of the hardware tables
+	if _, err := infradb.GetVfRepresentorByLink(path.Base(bp.Name)); err == nil {
+		if err := utils.CheckOffloadCapacity(context.Background(), s.devlink, config.GlobalConfig.Devlink.OccupancyThreshold); err != nil {
+			return nil, err
+		}
+	}
+
 	// translation of pb to domain object
-	domainBP, err := infradb.NewBridgePort(bp)
+	domainBP, err := pbconv.BridgePortFromPb(bp)
 	if err != nil {
 		return nil, err
 	}
@@ -50,12 +61,12 @@ func (s *Server) createBridgePort(bp *pb.BridgePort) (*pb.BridgePort, error) {
 	if err := infradb.CreateBP(domainBP); err != nil {
 		return nil, err
 	}
-	return domainBP.ToPb(), nil
+	return pbconv.BridgePortToPb(domainBP), nil
 }
 
-func (s *Server) deleteBridgePort(name string) error {
+func (s *Server) deleteBridgePort(name string, opts ...infradb.WriteOption) error {
 	// Note: The status of the object will be generated in infraDB operation not here
-	if err := infradb.DeleteBP(name); err != nil {
+	if err := infradb.DeleteBP(name, opts...); err != nil {
 		return err
 	}
 	return nil
@@ -66,7 +77,7 @@ func (s *Server) getBridgePort(name string) (*pb.BridgePort, error) {
 	if err != nil {
 		return nil, err
 	}
-	return domainBP.ToPb(), nil
+	return pbconv.BridgePortToPb(domainBP), nil
 }
 
 func (s *Server) getAllBridgePorts() ([]*pb.BridgePort, error) {
@@ -77,27 +88,44 @@ func (s *Server) getAllBridgePorts() ([]*pb.BridgePort, error) {
 	}
 
 	for _, domainBP := range domainBPs {
-		bps = append(bps, domainBP.ToPb())
+		bps = append(bps, pbconv.BridgePortToPb(domainBP))
 	}
 	return bps, nil
 }
 
-func (s *Server) updateBridgePort(bp *pb.BridgePort) (*pb.BridgePort, error) {
+func (s *Server) updateBridgePort(bp *pb.BridgePort, opts ...infradb.WriteOption) (*pb.BridgePort, error) {
 	// check parameters
 	if err := s.validateBridgePortSpec(bp); err != nil {
 		return nil, err
 	}
 
+	// the Bridge Ports of the VF representors take entries of the hardware tables
+	if _, err := infradb.GetVfRepresentorByLink(path.Base(bp.Name)); err == nil {
+		if err := utils.CheckOffloadCapacity(context.Background(), s.devlink, config.GlobalConfig.Devlink.OccupancyThreshold); err != nil {
+			return nil, err
+		}
+	}
+
 	// translation of pb to domain object
-	domainBP, err := infradb.NewBridgePort(bp)
+	domainBP, err := pbconv.BridgePortFromPb(bp)
 	if err != nil {
 		return nil, err
 	}
 	// Note: The status of the object will be generated in infraDB operation not here
-	if err := infradb.UpdateBP(domainBP); err != nil {
+	if err := infradb.UpdateBP(domainBP, opts...); err != nil {
 		return nil, err
 	}
-	return domainBP.ToPb(), nil
+	return pbconv.BridgePortToPb(domainBP), nil
+}
+
+// sameBridgePortSpec tells whether the create request replays the creation of the existing Bridge Port,
+// the spec of the request is compared in its stored form
+func (s *Server) sameBridgePortSpec(bp *pb.BridgePort, existing *pb.BridgePort) bool {
+	domainBP, err := pbconv.BridgePortFromPb(bp)
+	if err != nil {
+		return false
+	}
+	return proto.Equal(pbconv.BridgePortToPb(domainBP).Spec, existing.Spec)
 }
 
 func resourceIDToFullName(resourceID string) string {
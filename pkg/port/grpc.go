@@ -16,6 +16,7 @@ import (
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
 
 	"go.einride.tech/aip/resourceid"
 	"google.golang.org/grpc/codes"
@@ -24,12 +25,16 @@ import (
 )
 
 // CreateBridgePort executes the creation of the port
-func (s *Server) CreateBridgePort(_ context.Context, in *pb.CreateBridgePortRequest) (*pb.BridgePort, error) {
+func (s *Server) CreateBridgePort(ctx context.Context, in *pb.CreateBridgePortRequest) (*pb.BridgePort, error) {
 	// check input correctness
 	if err := s.validateCreateBridgePortRequest(in); err != nil {
 		log.Printf("CreateBridgePort(): validation failure: %v", err)
 		return nil, err
 	}
+	if err := utils.ContextError(ctx); err != nil {
+		log.Printf("CreateBridgePort(): %v", err)
+		return nil, err
+	}
 	// see https://google.aip.dev/133#user-specified-ids
 	resourceID := resourceid.NewSystemGenerated()
 	if in.BridgePortId != "" {
@@ -37,7 +42,7 @@ func (s *Server) CreateBridgePort(_ context.Context, in *pb.CreateBridgePortRequ
 		resourceID = in.BridgePortId
 	}
 	in.BridgePort.Name = resourceIDToFullName(resourceID)
-	// idempotent API when called with same key, should return same object
+	// idempotent API when called with same key and spec, should return same object
 	bpObj, err := s.getBridgePort(in.BridgePort.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
@@ -45,6 +50,15 @@ func (s *Server) CreateBridgePort(_ context.Context, in *pb.CreateBridgePortRequ
 			return nil, err
 		}
 	} else {
+		if err := s.validateBridgePortSpec(in.BridgePort); err != nil {
+			log.Printf("CreateBridgePort(): validation failure: %v", err)
+			return nil, err
+		}
+		if !s.sameBridgePortSpec(in.BridgePort, bpObj) {
+			err := apierrors.AlreadyExists("bridgePorts", in.BridgePort.Name, "%s already exists with another spec", in.BridgePort.Name)
+			log.Printf("CreateBridgePort(): %v", err)
+			return nil, err
+		}
 		log.Printf("CreateBridgePort(): Already existing BridgePort with id %v", in.BridgePort.Name)
 		return bpObj, nil
 	}
@@ -58,12 +72,16 @@ func (s *Server) CreateBridgePort(_ context.Context, in *pb.CreateBridgePortRequ
 }
 
 // DeleteBridgePort deletes a port
-func (s *Server) DeleteBridgePort(_ context.Context, in *pb.DeleteBridgePortRequest) (*emptypb.Empty, error) {
+func (s *Server) DeleteBridgePort(ctx context.Context, in *pb.DeleteBridgePortRequest) (*emptypb.Empty, error) {
 	// check input correctness
 	if err := s.validateDeleteBridgePortRequest(in); err != nil {
 		log.Printf("DeleteBridgePort(): validation failure: %v", err)
 		return nil, err
 	}
+	if err := utils.ContextError(ctx); err != nil {
+		log.Printf("DeleteBridgePort(): %v", err)
+		return nil, err
+	}
 	// fetch object from the database
 	_, err := s.getBridgePort(in.Name)
 	if err != nil {
@@ -72,14 +90,14 @@ func (s *Server) DeleteBridgePort(_ context.Context, in *pb.DeleteBridgePortRequ
 			return nil, err
 		}
 		if !in.AllowMissing {
-			err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+			err = apierrors.NotFound("bridgePorts", in.Name)
 			log.Printf("DeleteBridgePort(): BridgePort with id %v: Not Found %v", in.Name, err)
 			return nil, err
 		}
 		return &emptypb.Empty{}, nil
 	}
 
-	if err := s.deleteBridgePort(in.Name); err != nil {
+	if err := s.deleteBridgePort(in.Name, infradb.IfMatch(utils.IfMatch(ctx))); err != nil {
 		log.Printf("DeleteBridgePort(): BridgePort with id %v, Delete Bridge Port from DB failure: %v", in.Name, err)
 		return nil, err
 	}
@@ -87,12 +105,16 @@ func (s *Server) DeleteBridgePort(_ context.Context, in *pb.DeleteBridgePortRequ
 }
 
 // UpdateBridgePort updates an Nvme Subsystem
-func (s *Server) UpdateBridgePort(_ context.Context, in *pb.UpdateBridgePortRequest) (*pb.BridgePort, error) {
+func (s *Server) UpdateBridgePort(ctx context.Context, in *pb.UpdateBridgePortRequest) (*pb.BridgePort, error) {
 	// check input correctness
 	if err := s.validateUpdateBridgePortRequest(in); err != nil {
 		log.Printf("UpdateBridgePort(): validation failure: %v", err)
 		return nil, err
 	}
+	if err := utils.ContextError(ctx); err != nil {
+		log.Printf("UpdateBridgePort(): %v", err)
+		return nil, err
+	}
 	// fetch object from the
 	bpObj, err := s.getBridgePort(in.BridgePort.Name)
 	if err != nil {
@@ -101,7 +123,7 @@ func (s *Server) UpdateBridgePort(_ context.Context, in *pb.UpdateBridgePortRequ
 			return nil, err
 		}
 		if !in.AllowMissing {
-			err = status.Errorf(codes.NotFound, "unable to find key %s", in.BridgePort.Name)
+			err = apierrors.NotFound("bridgePorts", in.BridgePort.Name)
 			log.Printf("UpdateBridgePort(): BridgePort with id %v: Not Found %v", in.BridgePort.Name, err)
 			return nil, err
 		}
@@ -136,7 +158,7 @@ func (s *Server) UpdateBridgePort(_ context.Context, in *pb.UpdateBridgePortRequ
 		return bpObj, nil
 	}
 
-	response, err := s.updateBridgePort(updatedbpObj)
+	response, err := s.updateBridgePort(updatedbpObj, infradb.IfMatch(utils.IfMatch(ctx)))
 	if err != nil {
 		log.Printf("UpdateBridgePort(): BridgePort with id %v, Update Bridge Port to DB failure: %v", in.BridgePort.Name, err)
 		return nil, err
@@ -159,7 +181,7 @@ func (s *Server) GetBridgePort(_ context.Context, in *pb.GetBridgePortRequest) (
 			log.Printf("Failed to interact with store: %v", err)
 			return nil, err
 		}
-		err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+		err = apierrors.NotFound("bridgePorts", in.Name)
 		log.Printf("GetBridgePort(): BridgePort with id %v: Not Found %v", in.Name, err)
 		return nil, err
 	}
@@ -175,7 +197,9 @@ func (s *Server) ListBridgePorts(_ context.Context, in *pb.ListBridgePortsReques
 		return nil, err
 	}
 	// fetch pagination from the database, calculate size and offset
+	s.paginationLock.Lock()
 	size, offset, err := utils.ExtractPagination(in.PageSize, in.PageToken, s.Pagination)
+	s.paginationLock.Unlock()
 	if err != nil {
 		return nil, err
 	}
@@ -197,7 +221,9 @@ func (s *Server) ListBridgePorts(_ context.Context, in *pb.ListBridgePortsReques
 	token := ""
 	if hasMoreElements {
 		token = uuid.New().String()
+		s.paginationLock.Lock()
 		s.Pagination[token] = offset + size
+		s.paginationLock.Unlock()
 	}
 	return &pb.ListBridgePortsResponse{BridgePorts: Blobarray, NextPageToken: token}, nil
 }
@@ -75,6 +75,21 @@ func Test_CreateBridgePort(t *testing.T) {
 			exist:   true,
 			on:      nil,
 		},
+		"already exists with another spec": {
+			id: testBridgePortID,
+			in: &pb.BridgePort{
+				Spec: &pb.BridgePortSpec{
+					MacAddress:     testBridgePort.Spec.MacAddress,
+					Ptype:          pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS,
+					LogicalBridges: []string{testLogicalBridgeName},
+				},
+			},
+			out:     nil,
+			errCode: codes.AlreadyExists,
+			errMsg:  fmt.Sprintf("%s already exists with another spec", testBridgePortName),
+			exist:   true,
+			on:      nil,
+		},
 		"no required port field": {
 			id:      testBridgePortID,
 			in:      nil,
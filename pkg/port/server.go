@@ -7,17 +7,24 @@
 package port
 
 import (
+	"sync"
+
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 )
 
 // Server represents the Server object
 type Server struct {
 	pb.UnimplementedBridgePortServiceServer
 	Pagination map[string]int
-	tracer     trace.Tracer
+	// paginationLock guards Pagination, the List calls run concurrently
+	paginationLock sync.Mutex
+	tracer         trace.Tracer
+	devlink        utils.Devlink
 }
 
 // NewServer creates initialized instance of EVPN server
@@ -25,5 +32,6 @@ func NewServer() *Server {
 	return &Server{
 		Pagination: make(map[string]int),
 		tracer:     otel.Tracer(""),
+		devlink:    utils.NewDevlinkWrapperWithArgs(true),
 	}
 }
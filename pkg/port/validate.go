@@ -7,17 +7,13 @@
 package port
 
 import (
-	"fmt"
-
 	"go.einride.tech/aip/fieldbehavior"
 	"go.einride.tech/aip/fieldmask"
 	"go.einride.tech/aip/resourceid"
 	"go.einride.tech/aip/resourcename"
 
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 )
 
@@ -41,8 +37,8 @@ func (s *Server) validateBridgePortSpec(bp *pb.BridgePort) error {
 	if bp.Spec.LogicalBridges != nil {
 		for _, lb := range bp.Spec.LogicalBridges {
 			if err := resourcename.Validate(lb); err != nil {
-				msg := fmt.Sprintf("Logical Bridge %v has invalid name, error: %v", lb, err)
-				return status.Errorf(codes.InvalidArgument, msg)
+				return apierrors.InvalidField("bridge_port.spec.logical_bridges", apierrors.ReasonInvalidName,
+					"Logical Bridge %v has invalid name, error: %v", lb, err)
 			}
 		}
 	}
@@ -50,21 +46,21 @@ func (s *Server) validateBridgePortSpec(bp *pb.BridgePort) error {
 	// for Access type, the LogicalBridge list must have only one item
 	if bp.Spec.Ptype == pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS {
 		if bp.Spec.LogicalBridges == nil {
-			msg := "LogicalBridges field cannot be empty when the Bridge Port is of type ACCESS"
-			return status.Errorf(codes.InvalidArgument, msg)
+			return apierrors.InvalidField("bridge_port.spec.logical_bridges", apierrors.ReasonInvalidField,
+				"LogicalBridges field cannot be empty when the Bridge Port is of type ACCESS")
 		}
 
 		lenLbs := len(bp.Spec.LogicalBridges)
 		if lenLbs > 1 {
-			msg := fmt.Sprintf("ACCESS type must have single LogicalBridge and not (%d)", lenLbs)
-			return status.Errorf(codes.InvalidArgument, msg)
+			return apierrors.InvalidField("bridge_port.spec.logical_bridges", apierrors.ReasonInvalidField,
+				"ACCESS type must have single LogicalBridge and not (%d)", lenLbs)
 		}
 	}
 
 	// validate MacAddress format
 	if err := utils.ValidateMacAddress(bp.Spec.MacAddress); err != nil {
-		msg := fmt.Sprintf("Invalid format of MAC Address: %v", err)
-		return status.Errorf(codes.InvalidArgument, msg)
+		return apierrors.InvalidField("bridge_port.spec.mac_address", apierrors.ReasonInvalidAddress,
+			"Invalid format of MAC Address: %v", err)
 	}
 
 	return nil
@@ -0,0 +1,356 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package preflight checks at startup that the host can run the bridge, so that a missing kernel
+// module, setting, daemon or privilege stops the bridge with its remedy instead of failing the first Create
+package preflight
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+	"github.com/vishvananda/netlink"
+)
+
+// checkTimeout bounds the duration of a single check
+var checkTimeout = 5 * time.Second
+
+// procPath, sysPath and modulesPath are the locations of the kernel state read by the checks
+var (
+	procPath    = "/proc"
+	sysPath     = "/sys"
+	modulesPath = "/lib/modules"
+)
+
+// lookPath finds the executables run by the bridge
+var lookPath = exec.LookPath
+
+// linkByName finds the devices of the management plane
+var linkByName = netlink.LinkByName
+
+// kernelModules are the modules of the devices created by the bridge
+var kernelModules = []string{"vxlan", "vrf", "bridge"}
+
+// defaultSysctls are the kernel settings the bridge requires, the alternatives are separated by |.
+// The replies to ARP requests are filtered by the routes of the main table unless arp_filter is off,
+// and the strict reverse path filter drops the traffic routed asymmetrically through the vxlan devices.
+var defaultSysctls = map[string]string{
+	"net.ipv4.ip_forward":          "1",
+	"net.ipv4.conf.all.arp_filter": "0",
+	"net.ipv4.conf.all.rp_filter":  "0|2",
+}
+
+// capabilities are the bits of the capabilities in the capability sets of the kernel
+var capabilities = map[string]uint{
+	"net_admin": 12,
+	"net_raw":   13,
+}
+
+// Check is a self-check of the host, Hint tells how to fix it when it fails
+type Check struct {
+	Name string
+	Hint string
+	Run  func(ctx context.Context) error
+}
+
+// Result is the outcome of a check, Err is nil when it passed
+type Result struct {
+	Check
+	Err error
+}
+
+// Checks returns the checks of the host required by the config and the routing backend
+func Checks(cfg *config.Config, backend routing.Backend) []Check {
+	checks := []Check{}
+	for _, module := range kernelModules {
+		checks = append(checks, moduleCheck(module))
+	}
+	sysctls := map[string]string{}
+	for key, value := range defaultSysctls {
+		sysctls[key] = value
+	}
+	for _, sysctl := range cfg.Preflight.Sysctls {
+		key, value, _ := strings.Cut(sysctl, "=")
+		sysctls[key] = value
+	}
+	keys := make([]string, 0, len(sysctls))
+	for key := range sysctls {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		checks = append(checks, sysctlCheck(key, sysctls[key]))
+	}
+	checks = append(checks, capabilityCheck("net_admin"))
+	if cfg.Garp.Count > 0 {
+		// the gratuitous arps and unsolicited NAs are sent on packet sockets
+		checks = append(checks, capabilityCheck("net_raw"))
+	}
+	for _, command := range []string{"ip", "bridge", "sysctl"} {
+		checks = append(checks, commandCheck(command))
+	}
+	if cfg.Management.Vrf != "" {
+		checks = append(checks, managementCheck(cfg.Management.Vrf, cfg.Management.Interface))
+	}
+	if backend == nil {
+		return checks
+	}
+	if backend.Name() == routing.DefaultBackend {
+		if !cfg.LinuxFrr.Enabled {
+			return checks
+		}
+		address := cfg.LinuxFrr.FrrAddress
+		if address == "" {
+			address = "localhost"
+		}
+		for _, daemon := range []string{"zebra", "bgpd"} {
+			checks = append(checks, frrCheck(address, daemon))
+		}
+		return checks
+	}
+	return append(checks, Check{
+		Name: "routing:" + backend.Name(),
+		Hint: fmt.Sprintf("start the %s routing stack or fix its address in the routing section of the config", backend.Name()),
+		Run:  backend.Probe,
+	})
+}
+
+// Skip removes the checks named, or whose kind is named, it fails when a name matches no check
+func Skip(checks []Check, names []string) ([]Check, error) {
+	skip := map[string]bool{}
+	for _, name := range names {
+		skip[name] = false
+	}
+	kept := []Check{}
+	for _, check := range checks {
+		kind, _, _ := strings.Cut(check.Name, ":")
+		if _, ok := skip[check.Name]; ok {
+			skip[check.Name] = true
+			continue
+		}
+		if _, ok := skip[kind]; ok {
+			skip[kind] = true
+			continue
+		}
+		kept = append(kept, check)
+	}
+	for _, name := range names {
+		if !skip[name] {
+			return nil, fmt.Errorf("preflight: no check named %s to skip", name)
+		}
+	}
+	return kept, nil
+}
+
+// Run runs the checks in order
+func Run(ctx context.Context, checks []Check) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		results = append(results, Result{Check: check, Err: check.Run(checkCtx)})
+		cancel()
+	}
+	return results
+}
+
+// Verify runs the checks of the config and the backend, it logs every failure with its remedy and
+// returns them all unless the config only asks for warnings
+func Verify(ctx context.Context, cfg *config.Config, backend routing.Backend) error {
+	checks, err := Skip(Checks(cfg, backend), cfg.Preflight.Skip)
+	if err != nil {
+		return err
+	}
+	var failures []error
+	for _, result := range Run(ctx, checks) {
+		if result.Err == nil {
+			continue
+		}
+		log.Printf("preflight: %s failed: %v, %s\n", result.Name, result.Err, result.Hint)
+		failures = append(failures, fmt.Errorf("%s: %v (%s)", result.Name, result.Err, result.Hint))
+	}
+	log.Printf("preflight: %d checks, %d failed\n", len(checks), len(failures))
+	if len(failures) == 0 || cfg.Preflight.WarnOnly {
+		return nil
+	}
+	return fmt.Errorf("preflight checks failed, fix them or skip them in the preflight section of the config: %w", errors.Join(failures...))
+}
+
+// moduleCheck checks that the kernel module is loaded, built in or can be loaded on demand
+func moduleCheck(module string) Check {
+	return Check{
+		Name: "module:" + module,
+		Hint: "run modprobe " + module + " on the host, or mount /lib/modules in the container",
+		Run: func(context.Context) error {
+			if _, err := os.Stat(filepath.Join(sysPath, "module", module)); err == nil {
+				return nil
+			}
+			release, err := os.ReadFile(filepath.Join(procPath, "sys", "kernel", "osrelease"))
+			if err != nil {
+				return err
+			}
+			dir := filepath.Join(modulesPath, strings.TrimSpace(string(release)))
+			for _, list := range []string{"modules.builtin", "modules.dep"} {
+				found, err := listsModule(filepath.Join(dir, list), module)
+				if err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+				if found {
+					return nil
+				}
+			}
+			return fmt.Errorf("module %s is neither loaded nor available in %s", module, dir)
+		},
+	}
+}
+
+// listsModule tells whether the module list of the kernel holds the module
+func listsModule(list string, module string) (bool, error) {
+	f, err := os.Open(filepath.Clean(list))
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = f.Close() }()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// the lines are the paths of the modules, modules.dep follows them with a colon and their dependencies
+		file, _, _ := strings.Cut(scanner.Text(), ":")
+		name := strings.ReplaceAll(filepath.Base(file), "-", "_")
+		if name == module+".ko" || strings.HasPrefix(name, module+".ko.") {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// sysctlCheck checks that the kernel setting has one of the values
+func sysctlCheck(key string, values string) Check {
+	alternatives := strings.Split(values, "|")
+	return Check{
+		Name: "sysctl:" + key,
+		Hint: fmt.Sprintf("run sysctl -w %s=%s and persist it in /etc/sysctl.d", key, alternatives[0]),
+		Run: func(context.Context) error {
+			data, err := os.ReadFile(filepath.Join(procPath, "sys", strings.ReplaceAll(key, ".", "/")))
+			if err != nil {
+				return err
+			}
+			value := strings.Join(strings.Fields(string(data)), " ")
+			for _, alternative := range alternatives {
+				if value == alternative {
+					return nil
+				}
+			}
+			return fmt.Errorf("%s is %s, expected %s", key, value, strings.Join(alternatives, " or "))
+		},
+	}
+}
+
+// capabilityCheck checks that the bridge runs with the capability
+func capabilityCheck(name string) Check {
+	return Check{
+		Name: "capability:" + name,
+		Hint: fmt.Sprintf("run the bridge as root or grant it CAP_%s, e.g. with securityContext.capabilities.add in a pod",
+			strings.ToUpper(name)),
+		Run: func(context.Context) error {
+			f, err := os.Open(filepath.Join(procPath, "self", "status"))
+			if err != nil {
+				return err
+			}
+			defer func() { _ = f.Close() }()
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				value, ok := strings.CutPrefix(scanner.Text(), "CapEff:")
+				if !ok {
+					continue
+				}
+				caps, err := strconv.ParseUint(strings.TrimSpace(value), 16, 64)
+				if err != nil {
+					return err
+				}
+				if caps&(1<<capabilities[name]) == 0 {
+					return fmt.Errorf("CAP_%s is not in the effective capabilities %s", strings.ToUpper(name), strings.TrimSpace(value))
+				}
+				return nil
+			}
+			if err := scanner.Err(); err != nil {
+				return err
+			}
+			return errors.New("no effective capabilities in the process status")
+		},
+	}
+}
+
+// commandCheck checks that the executable run by the bridge is installed
+func commandCheck(command string) Check {
+	hint := "install " + command
+	switch command {
+	case "ip", "bridge":
+		hint = "install iproute2"
+	case "sysctl":
+		hint = "install procps"
+	}
+	return Check{
+		Name: "command:" + command,
+		Hint: hint,
+		Run: func(context.Context) error {
+			_, err := lookPath(command)
+			return err
+		},
+	}
+}
+
+// managementCheck checks that the management vrf is a vrf device the bridge does not own, with the
+// management interface enslaved to it
+func managementCheck(vrf string, intf string) Check {
+	return Check{
+		Name: "management:" + vrf,
+		Hint: fmt.Sprintf("create the management vrf with ip link add %s type vrf table <table> and enslave the management interface to it", vrf),
+		Run: func(context.Context) error {
+			link, err := linkByName(vrf)
+			if err != nil {
+				return fmt.Errorf("management vrf %s: %w", vrf, err)
+			}
+			if link.Type() != "vrf" {
+				return fmt.Errorf("management vrf %s is a %s device", vrf, link.Type())
+			}
+			if owner, ok := utils.LinkAliasOwner(link.Attrs().Alias); ok {
+				return fmt.Errorf("management vrf %s is a tenant vrf of the bridge owned by %s", vrf, owner)
+			}
+			if intf == "" {
+				return nil
+			}
+			mgmt, err := linkByName(intf)
+			if err != nil {
+				return fmt.Errorf("management interface %s: %w", intf, err)
+			}
+			if mgmt.Attrs().MasterIndex != link.Attrs().Index {
+				return fmt.Errorf("management interface %s is not enslaved to %s", intf, vrf)
+			}
+			return nil
+		},
+	}
+}
+
+// frrCheck checks that the FRR daemon accepts vty connections
+func frrCheck(address string, daemon string) Check {
+	return Check{
+		Name: "frr:" + daemon,
+		Hint: fmt.Sprintf("set %s=yes in /etc/frr/daemons and start frr, or fix linuxfrr.frraddress", daemon),
+		Run: func(ctx context.Context) error {
+			return utils.FrrDaemonPing(ctx, address, daemon)
+		},
+	}
+}
@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package preflight checks at startup that the host can run the bridge, so that a missing kernel
+// module, setting, daemon or privilege stops the bridge with its remedy instead of failing the first Create
+package preflight
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+	"github.com/vishvananda/netlink"
+)
+
+// probeBackend is a routing backend whose probe fails with err
+type probeBackend struct{ err error }
+
+func (probeBackend) Name() string                                                { return "gobgp" }
+func (probeBackend) Initialize()                                                 {}
+func (probeBackend) DeInitialize()                                               {}
+func (b probeBackend) Probe(context.Context) error                               { return b.err }
+func (probeBackend) DeepProbe(context.Context) error                             { return nil }
+func (probeBackend) EvpnVnis(context.Context) ([]routing.EvpnVni, error)         { return nil, nil }
+func (probeBackend) EvpnRoutes(context.Context) ([]routing.EvpnRoute, error)     { return nil, nil }
+func (probeBackend) BgpPeers(context.Context, string) ([]routing.BgpPeer, error) { return nil, nil }
+func (probeBackend) BgpRoutes(context.Context, string) ([]routing.BgpRoute, error) {
+	return nil, nil
+}
+func (probeBackend) SetDrain(context.Context, routing.DrainMode, bool) error { return nil }
+func (probeBackend) NexthopGroups(context.Context) ([]routing.NexthopGroup, routing.NexthopGroupStats, error) {
+	return nil, routing.NexthopGroupStats{}, nil
+}
+
+// writeFile writes the file below the root, creating its directories
+func writeFile(t *testing.T, root string, name string, content string) {
+	t.Helper()
+	file := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeHost points the checks at a host with every module, setting, capability and command the bridge needs
+func fakeHost(t *testing.T) string {
+	root := t.TempDir()
+	procPath, sysPath, modulesPath = filepath.Join(root, "proc"), filepath.Join(root, "sys"), filepath.Join(root, "modules")
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	t.Cleanup(func() {
+		procPath, sysPath, modulesPath = "/proc", "/sys", "/lib/modules"
+		lookPath = exec.LookPath
+	})
+	writeFile(t, root, "proc/sys/kernel/osrelease", "6.1.0\n")
+	writeFile(t, root, "proc/sys/net/ipv4/ip_forward", "1\n")
+	writeFile(t, root, "proc/sys/net/ipv4/conf/all/arp_filter", "0\n")
+	writeFile(t, root, "proc/sys/net/ipv4/conf/all/rp_filter", "2\n")
+	writeFile(t, root, "proc/self/status", "Name:\topi-evpn-bridge\nCapEff:\t000001ffffffffff\n")
+	writeFile(t, root, "sys/module/bridge/refcnt", "0\n")
+	writeFile(t, root, "modules/6.1.0/modules.builtin", "kernel/net/ipv4/vrf.ko\n")
+	writeFile(t, root, "modules/6.1.0/modules.dep", "kernel/drivers/net/vxlan/vxlan.ko.xz: kernel/net/ipv4/udp_tunnel.ko.xz\n")
+	return root
+}
+
+// failed returns the names of the failed checks
+func failed(results []Result) []string {
+	names := []string{}
+	for _, result := range results {
+		if result.Err != nil {
+			names = append(names, result.Name)
+		}
+	}
+	return names
+}
+
+func Test_Checks(t *testing.T) {
+	root := fakeHost(t)
+	cfg := &config.Config{}
+	results := Run(context.Background(), Checks(cfg, probeBackend{}))
+	if names := failed(results); len(names) != 0 {
+		t.Fatalf("expected the checks to pass on a ready host, %v failed", names)
+	}
+
+	writeFile(t, root, "proc/sys/net/ipv4/conf/all/rp_filter", "1\n")
+	writeFile(t, root, "proc/self/status", "CapEff:\t0000000000000000\n")
+	if err := os.RemoveAll(filepath.Join(root, "modules")); err != nil {
+		t.Fatal(err)
+	}
+	lookPath = func(file string) (string, error) { return "", exec.ErrNotFound }
+	cfg.Garp.Count = 1
+	backendErr := errors.New("connection refused")
+	results = Run(context.Background(), Checks(cfg, probeBackend{err: backendErr}))
+	expected := []string{
+		"module:vxlan", "module:vrf", "sysctl:net.ipv4.conf.all.rp_filter", "capability:net_admin", "capability:net_raw",
+		"command:ip", "command:bridge", "command:sysctl", "routing:gobgp",
+	}
+	if names := failed(results); strings.Join(names, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected the checks %v to fail, %v did", expected, names)
+	}
+	for _, result := range results {
+		if result.Err != nil && result.Hint == "" {
+			t.Errorf("expected the failed check %s to tell how to fix it", result.Name)
+		}
+	}
+}
+
+func Test_ConfiguredSysctls(t *testing.T) {
+	root := fakeHost(t)
+	writeFile(t, root, "proc/sys/net/ipv4/tcp_l3mdev_accept", "0\n")
+	cfg := &config.Config{}
+	cfg.Preflight.Sysctls = []string{"net.ipv4.tcp_l3mdev_accept=1", "net.ipv4.conf.all.rp_filter=2"}
+	names := failed(Run(context.Background(), Checks(cfg, nil)))
+	if strings.Join(names, " ") != "sysctl:net.ipv4.tcp_l3mdev_accept" {
+		t.Errorf("expected the configured setting to fail, %v did", names)
+	}
+}
+
+func Test_Skip(t *testing.T) {
+	fakeHost(t)
+	checks := Checks(&config.Config{}, nil)
+	kept, err := Skip(checks, []string{"module", "sysctl:net.ipv4.ip_forward"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, check := range kept {
+		if strings.HasPrefix(check.Name, "module:") || check.Name == "sysctl:net.ipv4.ip_forward" {
+			t.Errorf("expected %s to be skipped", check.Name)
+		}
+	}
+	if len(kept) != len(checks)-len(kernelModules)-1 {
+		t.Errorf("expected %d checks to be kept, received %d", len(checks)-len(kernelModules)-1, len(kept))
+	}
+	if _, err := Skip(checks, []string{"module:ipvlan"}); err == nil {
+		t.Error("expected skipping an unknown check to fail")
+	}
+}
+
+func Test_Verify(t *testing.T) {
+	root := fakeHost(t)
+	writeFile(t, root, "proc/sys/net/ipv4/ip_forward", "0\n")
+	cfg := &config.Config{}
+	err := Verify(context.Background(), cfg, probeBackend{})
+	if err == nil || !strings.Contains(err.Error(), "sysctl -w net.ipv4.ip_forward=1") {
+		t.Errorf("expected the failure to tell how to fix it, received %v", err)
+	}
+	cfg.Preflight.WarnOnly = true
+	if err := Verify(context.Background(), cfg, probeBackend{}); err != nil {
+		t.Errorf("expected only a warning, received %v", err)
+	}
+	cfg.Preflight.WarnOnly = false
+	cfg.Preflight.Skip = []string{"sysctl:net.ipv4.ip_forward"}
+	if err := Verify(context.Background(), cfg, probeBackend{}); err != nil {
+		t.Errorf("expected the skipped check not to fail, received %v", err)
+	}
+}
+
+func Test_ManagementCheck(t *testing.T) {
+	fakeHost(t)
+	links := map[string]netlink.Link{}
+	linkByName = func(name string) (netlink.Link, error) {
+		if link, ok := links[name]; ok {
+			return link, nil
+		}
+		return nil, netlink.LinkNotFoundError{}
+	}
+	defer func() { linkByName = netlink.LinkByName }()
+	cfg := &config.Config{}
+	cfg.Management.Vrf, cfg.Management.Interface = "mgmt", "eth0"
+	check := func() error {
+		checks, err := Skip(Checks(cfg, nil), []string{"module", "sysctl", "capability", "command"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Run(context.Background(), checks)[0].Err
+	}
+
+	if err := check(); err == nil {
+		t.Error("expected a missing management vrf to fail")
+	}
+	links["mgmt"] = &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: "mgmt", Index: 5}}
+	if err := check(); err == nil {
+		t.Error("expected a management vrf which is not a vrf device to fail")
+	}
+	links["mgmt"] = &netlink.Vrf{LinkAttrs: netlink.LinkAttrs{Name: "mgmt", Index: 5, Alias: utils.LinkAlias("//network.opiproject.org/vrfs/mgmt")}}
+	if err := check(); err == nil {
+		t.Error("expected a tenant vrf of the bridge to fail")
+	}
+	links["mgmt"] = &netlink.Vrf{LinkAttrs: netlink.LinkAttrs{Name: "mgmt", Index: 5}}
+	links["eth0"] = &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "eth0", Index: 2}}
+	if err := check(); err == nil {
+		t.Error("expected a management interface outside the vrf to fail")
+	}
+	links["eth0"] = &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "eth0", Index: 2, MasterIndex: 5}}
+	if err := check(); err != nil {
+		t.Errorf("expected the management vrf to pass, received %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package publisher mirrors the lifecycle and status events of the objects onto a message bus,
+// one topic per kind of object, for the fleet-wide analytics pipelines
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// kafkaContentType is the embedded json format of the v2 API of the Kafka REST proxy
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// kafkaRecords is the body of a produce request of the REST proxy
+type kafkaRecords struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// kafkaPublisher produces the messages through a Kafka REST proxy, keyed by object so that the
+// events of an object land in the same partition and stay in order
+type kafkaPublisher struct {
+	baseURL string
+	client  *http.Client
+}
+
+// newKafkaPublisher returns the publisher to the REST proxy at the http(s) url
+func newKafkaPublisher(address string, dialer *net.Dialer) (Publisher, error) {
+	u, err := url.Parse(address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("kafka address %q is not the http(s) url of a REST proxy", address)
+	}
+	return &kafkaPublisher{
+		baseURL: strings.TrimSuffix(address, "/"),
+		client:  &http.Client{Transport: &http.Transport{DialContext: dialer.DialContext}},
+	}, nil
+}
+
+// Publish produces the message to the topic
+func (p *kafkaPublisher) Publish(ctx context.Context, topic string, key string, message []byte) error {
+	body, err := json.Marshal(kafkaRecords{Records: []kafkaRecord{{Key: key, Value: message}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kafka: POST %s: %s", req.URL, resp.Status)
+	}
+	// the proxy answers 200 with the error of each record in the offsets
+	out := struct {
+		Offsets []struct {
+			ErrorCode *int   `json:"error_code"`
+			Error     string `json:"error"`
+		} `json:"offsets"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("kafka: %w", err)
+	}
+	for _, offset := range out.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("kafka: error %d producing to %s: %s", *offset.ErrorCode, topic, offset.Error)
+		}
+	}
+	return nil
+}
+
+// Close releases the idle connections to the proxy
+func (p *kafkaPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package publisher mirrors the lifecycle and status events of the objects onto a message bus,
+// one topic per kind of object, for the fleet-wide analytics pipelines
+package publisher
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// natsConnect is the CONNECT of the client, the server does not acknowledge the commands
+const natsConnect = `CONNECT {"verbose":false,"pedantic":false,"name":"opi-evpn-bridge","lang":"go","version":"1.0.0"}` + "\r\n"
+
+// natsPublisher publishes with the text protocol of NATS, each publication is flushed with a PING
+// so that it is only acknowledged once the server has processed it
+type natsPublisher struct {
+	mu      sync.Mutex
+	address string
+	dialer  *net.Dialer
+	conn    net.Conn
+	reader  *bufio.Reader
+}
+
+// newNatsPublisher returns the publisher to the NATS server at host:port, it connects on the first publication
+func newNatsPublisher(address string, dialer *net.Dialer) (Publisher, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, fmt.Errorf("nats address %q: %w", address, err)
+	}
+	return &natsPublisher{address: address, dialer: dialer}, nil
+}
+
+// connect reads the INFO of the server and sends the CONNECT
+func (p *natsPublisher) connect(ctx context.Context) error {
+	conn, err := p.dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		_ = conn.Close()
+		return fmt.Errorf("nats: unexpected greeting %q", strings.TrimSpace(line))
+	}
+	if _, err := conn.Write([]byte(natsConnect)); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	p.conn, p.reader = conn, reader
+	return nil
+}
+
+// flush waits for the PONG of the PING following the publication, answering the PINGs of the server
+func (p *natsPublisher) flush() error {
+	for {
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		switch line = strings.TrimSpace(line); {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("nats: " + line)
+		}
+		// +OK and the INFO updates are ignored
+	}
+}
+
+// Publish sends the message to the subject, the key is not used as NATS does not partition the subjects
+func (p *natsPublisher) Publish(ctx context.Context, topic string, _ string, message []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	// no deadline when the context has none
+	deadline, _ := ctx.Deadline()
+	_ = p.conn.SetDeadline(deadline)
+	cmd := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", topic, len(message), message)
+	_, err := p.conn.Write([]byte(cmd))
+	if err == nil {
+		err = p.flush()
+	}
+	if err != nil {
+		// the next publication connects again
+		_ = p.conn.Close()
+		p.conn, p.reader = nil, nil
+	}
+	return err
+}
+
+// Close closes the connection to the server
+func (p *natsPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn, p.reader = nil, nil
+	return err
+}
@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package publisher mirrors the lifecycle and status events of the objects onto a message bus,
+// one topic per kind of object, for the fleet-wide analytics pipelines
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// Publisher sends messages to a message bus
+type Publisher interface {
+	// Publish sends the message to the topic, the key keeps the messages of an object in order when the bus partitions the topic
+	Publish(ctx context.Context, topic string, key string, message []byte) error
+	// Close releases the connection to the bus
+	Close() error
+}
+
+// Factory returns a publisher to the address of the bus, its connections are made with the dialer
+type Factory func(address string, dialer *net.Dialer) (Publisher, error)
+
+var factories = struct {
+	sync.RWMutex
+	byName map[string]Factory
+}{byName: map[string]Factory{}}
+
+// Register makes a message bus available by name
+func Register(name string, factory Factory) {
+	factories.Lock()
+	defer factories.Unlock()
+	factories.byName[name] = factory
+}
+
+func init() {
+	Register("nats", newNatsPublisher)
+	Register("kafka", newKafkaPublisher)
+}
+
+// New returns the publisher of the named message bus
+func New(name, address string, dialer *net.Dialer) (Publisher, error) {
+	factories.RLock()
+	defer factories.RUnlock()
+	factory, ok := factories.byName[name]
+	if !ok {
+		names := make([]string, 0, len(factories.byName))
+		for n := range factories.byName {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown message bus %s, expected one of %v", name, names)
+	}
+	return factory(address, dialer)
+}
+
+const (
+	// defaultTopicPrefix starts the topic names when no prefix is configured
+	defaultTopicPrefix = "opi-evpn"
+	// queueSize bounds the events waiting to be published, the newer events are dropped when it is full
+	queueSize = 4096
+	// publishTimeout bounds the publishing of an event
+	publishTimeout = 10 * time.Second
+)
+
+// retryInterval is the delay before publishing an event again once the bus failed, doubled up to maxRetryInterval
+var retryInterval = time.Second
+
+const maxRetryInterval = time.Minute
+
+// Topic returns the topic of the events of a kind of object, e.g. opi-evpn.logical-bridge
+func Topic(prefix, kind string) string {
+	if prefix == "" {
+		prefix = defaultTopicPrefix
+	}
+	return prefix + "." + kind
+}
+
+// message is an event as published, with the node it comes from
+type message struct {
+	Node string `json:"node"`
+	infradb.StatusEvent
+}
+
+// Mirror publishes the events of the DB in the order in which they happen
+type Mirror struct {
+	publisher Publisher
+	prefix    string
+	node      string
+	events    chan infradb.StatusEvent
+}
+
+// NewMirror returns a mirror of the events onto the publisher
+func NewMirror(publisher Publisher, prefix string) *Mirror {
+	node, _ := os.Hostname()
+	return &Mirror{publisher: publisher, prefix: prefix, node: node, events: make(chan infradb.StatusEvent, queueSize)}
+}
+
+// Enqueue queues the event without blocking, it is the status listener of the DB
+func (m *Mirror) Enqueue(event infradb.StatusEvent) {
+	select {
+	case m.events <- event:
+	default:
+		log.Printf("publisher: queue full, dropping the %s event of %s\n", event.Type, event.Name)
+	}
+}
+
+// Run publishes the queued events until the context is done, an event is retried until the bus takes it
+// so that the events are not reordered
+func (m *Mirror) Run(ctx context.Context) {
+	defer m.publisher.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-m.events:
+			data, err := json.Marshal(message{Node: m.node, StatusEvent: event})
+			if err != nil {
+				log.Printf("publisher: failed to encode the event: %v\n", err)
+				continue
+			}
+			topic := Topic(m.prefix, event.Kind)
+			interval := retryInterval
+			for {
+				publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+				err = m.publisher.Publish(publishCtx, topic, event.Name, data)
+				cancel()
+				if err == nil {
+					break
+				}
+				log.Printf("publisher: failed to publish to %s, retrying in %v: %v\n", topic, interval, err)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(interval):
+				}
+				interval = min(2*interval, maxRetryInterval)
+			}
+		}
+	}
+}
+
+// Start mirrors the events of the DB onto the configured message bus, it does nothing unless a bus is configured
+func Start(ctx context.Context, cfg *config.Config) error {
+	if cfg.Publisher.Backend == "" {
+		return nil
+	}
+	dialer := &net.Dialer{Timeout: publishTimeout}
+	if vrf := cfg.Management.Vrf; vrf != "" {
+		dialer.Control = utils.BindToDevice(vrf)
+	}
+	publisher, err := New(strings.ToLower(cfg.Publisher.Backend), cfg.Publisher.Address, dialer)
+	if err != nil {
+		return err
+	}
+	m := NewMirror(publisher, cfg.Publisher.TopicPrefix)
+	infradb.OnStatusChange(m.Enqueue)
+	go m.Run(ctx)
+	log.Printf("publisher: mirroring the events to %s %s\n", cfg.Publisher.Backend, cfg.Publisher.Address)
+	return nil
+}
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package publisher mirrors the lifecycle and status events of the objects onto a message bus,
+// one topic per kind of object, for the fleet-wide analytics pipelines
+package publisher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// serveNats accepts NATS clients and sends the payloads they publish, by subject, to the channel
+func serveNats(t *testing.T, published chan<- string) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = conn.Write([]byte(`INFO {"server_id":"test","max_payload":1048576}` + "\r\n"))
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					fields := strings.Fields(line)
+					switch {
+					case len(fields) == 3 && fields[0] == "PUB":
+						size, _ := strconv.Atoi(fields[2])
+						// the payload is followed by CRLF
+						payload := make([]byte, size+2)
+						if _, err := io.ReadFull(reader, payload); err != nil {
+							return
+						}
+						published <- fields[1] + " " + string(payload[:size])
+					case len(fields) == 1 && fields[0] == "PING":
+						_, _ = conn.Write([]byte("PONG\r\n"))
+					}
+				}
+			}()
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func Test_NatsPublisher(t *testing.T) {
+	published := make(chan string, 2)
+	p, err := New("nats", serveNats(t, published), &net.Dialer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, message := range []string{`{"type":"created"}`, `{"type":"up"}`} {
+		if err := p.Publish(ctx, "opi-evpn.vrf", "blue", []byte(message)); err != nil {
+			t.Fatal(err)
+		}
+		if received := <-published; received != "opi-evpn.vrf "+message {
+			t.Errorf("expected %s to be published, received %s", message, received)
+		}
+	}
+	if _, err := New("nats", "nats.example.com", &net.Dialer{}); err == nil {
+		t.Error("expected an address without port to be refused")
+	}
+}
+
+func Test_KafkaPublisher(t *testing.T) {
+	var mu sync.Mutex
+	paths, keys := []string{}, []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		records := kafkaRecords{}
+		if r.Header.Get("Content-Type") != kafkaContentType || json.NewDecoder(r.Body).Decode(&records) != nil {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+		keys = append(keys, records.Records[0].Key)
+		if strings.HasSuffix(r.URL.Path, "missing") {
+			_, _ = w.Write([]byte(`{"offsets":[{"partition":null,"offset":null,"error_code":40403,"error":"topic not found"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":42}]}`))
+	}))
+	defer server.Close()
+
+	p, err := New("kafka", server.URL+"/", &net.Dialer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err := p.Publish(context.Background(), "opi-evpn.svi", "//network.opiproject.org/svis/blue-web", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Publish(context.Background(), "missing", "key", []byte(`{}`)); err == nil {
+		t.Error("expected the error of the record to fail the publication")
+	}
+	if paths[0] != "/topics/opi-evpn.svi" || keys[0] != "//network.opiproject.org/svis/blue-web" {
+		t.Errorf("unexpected produce request to %v with keys %v", paths, keys)
+	}
+	if _, err := New("kafka", "kafka:9092", &net.Dialer{}); err == nil {
+		t.Error("expected an address which is not a REST proxy url to be refused")
+	}
+	if _, err := New("amqp", "amqp://127.0.0.1", &net.Dialer{}); err == nil {
+		t.Error("expected an unknown message bus to be refused")
+	}
+}
+
+// flakyPublisher fails its first publications
+type flakyPublisher struct {
+	failures  int
+	published chan string
+}
+
+func (p *flakyPublisher) Publish(_ context.Context, topic string, key string, _ []byte) error {
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("bus unavailable")
+	}
+	p.published <- topic + " " + key
+	return nil
+}
+
+func (p *flakyPublisher) Close() error { return nil }
+
+func Test_Mirror(t *testing.T) {
+	retryInterval = time.Millisecond
+	defer func() { retryInterval = time.Second }()
+	p := &flakyPublisher{failures: 2, published: make(chan string, 3)}
+	m := NewMirror(p, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+
+	m.Enqueue(infradb.StatusEvent{Type: infradb.StatusEventCreated, Kind: "vrf", Name: "blue"})
+	m.Enqueue(infradb.StatusEvent{Type: infradb.StatusEventCreated, Kind: "logical-bridge", Name: "blue-web"})
+	m.Enqueue(infradb.StatusEvent{Type: infradb.StatusEventUp, Kind: "vrf", Name: "blue"})
+	for _, expected := range []string{"opi-evpn.vrf blue", "opi-evpn.logical-bridge blue-web", "opi-evpn.vrf blue"} {
+		select {
+		case received := <-p.published:
+			if received != expected {
+				t.Errorf("expected %s to be published, received %s", expected, received)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected %s to be published", expected)
+		}
+	}
+}
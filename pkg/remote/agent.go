@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package remote
+
+import (
+	"context"
+	"log"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// resultsSize bounds the results waiting to be sent to the controller, the controller sends the task
+// again when a result is lost
+const resultsSize = 1024
+
+// Backoff of the connection to the controller
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// Agent mirrors on its node the objects of the controller, the components of the node realize them and
+// the agent reports their outcome
+type Agent struct {
+	controller string
+	node       string
+	heartbeat  time.Duration
+	opts       []grpc.DialOption
+	serving    func(context.Context) bool
+	results    chan *Result
+}
+
+// NewAgent creates the agent of the node, serving reports the overall health of the node in the heartbeats
+func NewAgent(cfg *config.Config, opts []grpc.DialOption, serving func(context.Context) bool) *Agent {
+	node := cfg.Remote.Node
+	if node == "" {
+		node, _ = os.Hostname()
+	}
+	a := &Agent{
+		controller: cfg.Remote.Controller,
+		node:       node,
+		heartbeat:  seconds(cfg.Remote.Heartbeat, defaultHeartbeat),
+		opts:       opts,
+		serving:    serving,
+		results:    make(chan *Result, resultsSize),
+	}
+	kinds := map[string]bool{}
+	for _, kind := range infradb.RemoteKinds() {
+		kinds[kind] = true
+	}
+	infradb.OnStatusChange(func(event infradb.StatusEvent) {
+		if !kinds[event.Kind] {
+			return
+		}
+		r := &Result{Kind: event.Kind, Name: event.Name, ResourceVersion: event.ResourceVersion}
+		switch event.Type {
+		case infradb.StatusEventUp, infradb.StatusEventDeleted:
+			r.Success = true
+		case infradb.StatusEventFailed:
+			r.Details = event.Component + ": " + event.Details
+		default:
+			return
+		}
+		a.report(r)
+	})
+	return a
+}
+
+// report queues the result for the controller without blocking, the listeners of the DB must not block
+func (a *Agent) report(r *Result) {
+	select {
+	case a.results <- r:
+	default:
+		log.Printf("remote: dropped the result of %s %s, the controller will ask again\n", r.Kind, r.Name)
+	}
+}
+
+// Run connects the agent to the controller until the context is canceled, it connects again when the
+// session ends
+func (a *Agent) Run(ctx context.Context) error {
+	conn, err := grpc.Dial(a.controller, a.opts...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	log.Printf("remote: node %s is an agent of the controller %s\n", a.node, a.controller)
+
+	backoff := minBackoff
+	for {
+		started := time.Now()
+		err := a.session(ctx, conn)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("remote: session with the controller ended: %v\n", err)
+		if time.Since(started) > maxBackoff {
+			backoff = minBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// session runs a stream with the controller: the agent names its node, then sends the heartbeats and the
+// results while it realizes the tasks
+func (a *Agent) session(ctx context.Context, conn *grpc.ClientConn) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := openStream(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(&AgentMessage{Hello: &Hello{Node: a.node, Version: buildVersion()}}); err != nil {
+		return err
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			msg := &ControllerMessage{}
+			if err := stream.RecvMsg(msg); err != nil {
+				errs <- err
+				return
+			}
+			a.handle(ctx, msg)
+		}
+	}()
+
+	ticker := time.NewTicker(a.heartbeat)
+	defer ticker.Stop()
+	for {
+		var msg *AgentMessage
+		select {
+		case err := <-errs:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			msg = &AgentMessage{Heartbeat: &Heartbeat{Serving: a.serving(ctx)}}
+		case r := <-a.results:
+			msg = &AgentMessage{Result: r}
+		}
+		if err := stream.SendMsg(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle realizes a message of the controller
+func (a *Agent) handle(ctx context.Context, msg *ControllerMessage) {
+	if msg.Sync != nil {
+		// the children are torn down before their parents
+		kinds := infradb.RemoteKinds()
+		for i := len(kinds) - 1; i >= 0; i-- {
+			keep := map[string]bool{}
+			for _, name := range msg.Sync.Objects[kinds[i]] {
+				keep[name] = true
+			}
+			if err := infradb.ForgetMirroredObjects(kinds[i], keep); err != nil {
+				log.Printf("remote: failed to synchronize the %s objects: %v\n", kinds[i], err)
+			}
+		}
+	}
+	if t := msg.Task; t != nil {
+		done, err := infradb.MirrorObject(t.Kind, t.Object)
+		switch {
+		case err != nil:
+			log.Printf("remote: failed to mirror %s %s: %v\n", t.Kind, t.Name, err)
+			a.reply(ctx, &Result{Kind: t.Kind, Name: t.Name, ResourceVersion: t.ResourceVersion, Details: err.Error()})
+		case done:
+			a.reply(ctx, &Result{Kind: t.Kind, Name: t.Name, ResourceVersion: t.ResourceVersion, Success: true})
+		}
+	}
+}
+
+// reply queues the result of a task, it waits for room unlike the status listener
+func (a *Agent) reply(ctx context.Context, r *Result) {
+	select {
+	case a.results <- r:
+	case <-ctx.Done():
+	}
+}
+
+// buildVersion returns the version of the module the agent is built from
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Version
+	}
+	return ""
+}
@@ -0,0 +1,537 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// States of the nodes
+const (
+	NodeUp   = "up"
+	NodeDown = "down"
+)
+
+// queueSize bounds the messages waiting to be sent to a node
+const queueSize = 1024
+
+// maxRetryTimer bounds the backoff of the objects which are not realized on every node
+const maxRetryTimer = time.Minute
+
+// errNoNode fails the realization of the objects while no node is up
+var errNoNode = errors.New("no node agent is connected")
+
+// NodeStatus is the state of a node seen by the controller
+type NodeStatus struct {
+	Name    string
+	Address string
+	Version string
+	State   string
+	// Serving is the overall health reported by the node in its last heartbeat
+	Serving     bool
+	ConnectedAt time.Time
+	LastSeen    time.Time
+	// Pending counts the objects waited for, Realized the ones whose last version succeeded on the node
+	Pending  int
+	Realized int
+	// Failed lists the objects whose last version failed on the node, with the reason
+	Failed []string
+}
+
+// waiter waits for the result of a version of an object on a node
+type waiter struct {
+	version string
+	ch      chan *Result
+}
+
+// node is a node agent known to the controller
+type node struct {
+	name        string
+	address     string
+	version     string
+	serving     bool
+	connectedAt time.Time
+	lastSeen    time.Time
+	// out holds the messages to send, done is closed when the session ends and cancel ends it
+	out    chan *ControllerMessage
+	done   chan struct{}
+	cancel context.CancelFunc
+	// sendMu keeps the order of the messages sent to the node
+	sendMu  sync.Mutex
+	results map[string]*Result
+	waiters map[string][]*waiter
+}
+
+// up tells whether the session of the node is running
+func (n *node) up() bool {
+	select {
+	case <-n.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// send queues the message for the node, it is dropped when the session ends
+func (n *node) send(msg *ControllerMessage) bool {
+	select {
+	case n.out <- msg:
+		return true
+	case <-n.done:
+		return false
+	}
+}
+
+// Controller serves the API and realizes the objects on the node agents connected to it
+type Controller struct {
+	heartbeat time.Duration
+	timeout   time.Duration
+
+	mu     sync.Mutex
+	nodes  map[string]*node
+	timers map[string]time.Duration
+}
+
+var (
+	currentLock sync.RWMutex
+	current     *Controller
+)
+
+// NewController creates the controller of the node agents
+func NewController(cfg *config.Config) *Controller {
+	return &Controller{
+		heartbeat: seconds(cfg.Remote.Heartbeat, defaultHeartbeat),
+		timeout:   seconds(cfg.Remote.Timeout, defaultTimeout),
+		nodes:     map[string]*node{},
+		timers:    map[string]time.Duration{},
+	}
+}
+
+// StartController creates the controller, which stands for the components of the nodes: it subscribes
+// to all the events the components of config subscribe to. The nodes missing their heartbeats are
+// disconnected until the context is canceled.
+func StartController(ctx context.Context, cfg *config.Config) *Controller {
+	c := NewController(cfg)
+	events := map[string]bool{}
+	for _, sub := range cfg.Subscribers {
+		for _, event := range sub.Events {
+			if !events[event] {
+				events[event] = true
+				eventbus.EBus.StartSubscriber(Component, event, 1, c)
+			}
+		}
+	}
+	currentLock.Lock()
+	current = c
+	currentLock.Unlock()
+	go c.sweep(ctx)
+	log.Printf("remote: controller started, the objects are realized on the node agents\n")
+	return c
+}
+
+// GetNodes returns the nodes known to the controller sorted by name, none when the bridge is not a controller
+func GetNodes() []NodeStatus {
+	currentLock.RLock()
+	c := current
+	currentLock.RUnlock()
+	if c == nil {
+		return nil
+	}
+	return c.Nodes()
+}
+
+// Nodes returns the nodes known to the controller sorted by name
+func (c *Controller) Nodes() []NodeStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]NodeStatus, 0, len(c.nodes))
+	for _, n := range c.nodes {
+		s := NodeStatus{
+			Name:        n.name,
+			Address:     n.address,
+			Version:     n.version,
+			State:       NodeDown,
+			Serving:     n.serving,
+			ConnectedAt: n.connectedAt,
+			LastSeen:    n.lastSeen,
+			Failed:      []string{},
+		}
+		if n.up() {
+			s.State = NodeUp
+		}
+		for _, w := range n.waiters {
+			s.Pending += len(w)
+		}
+		for key, r := range n.results {
+			if r.Success {
+				s.Realized++
+			} else {
+				s.Failed = append(s.Failed, fmt.Sprintf("%s: %s", key, r.Details))
+			}
+		}
+		sort.Strings(s.Failed)
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Probe fails while no node is up
+func (c *Controller) Probe(context.Context) error {
+	if len(c.upNodes("")) == 0 {
+		return errNoNode
+	}
+	return nil
+}
+
+// upNodes returns the nodes whose session is running, only the node of the placement unless it is empty
+func (c *Controller) upNodes(placement string) []*node {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	nodes := []*node{}
+	for _, n := range c.nodes {
+		if n.up() && (placement == "" || n.name == placement) {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// HandleEvent realizes the object on all the nodes which are up and reports the outcome as the status of the component
+func (c *Controller) HandleEvent(eventType string, objectData *eventbus.ObjectData) {
+	key := objectKey(eventType, objectData.Name)
+	comp := common.Component{Name: Component, CompStatus: common.ComponentStatusSuccess}
+	if err := c.realize(eventType, objectData.Name, objectData.ResourceVersion); err != nil {
+		log.Printf("remote: %v\n", err)
+		comp.CompStatus = common.ComponentStatusError
+		comp.Details = err.Error()
+		comp.Timer = c.retryTimer(key)
+	} else {
+		c.resetTimer(key)
+	}
+	if err := infradb.UpdateObjectStatus(eventType, objectData.Name, objectData.ResourceVersion, objectData.NotificationID, comp); err != nil {
+		log.Printf("remote: error in updating %s status: %v\n", eventType, err)
+	}
+}
+
+// realize sends the version of the object to the nodes it lives on which are up and waits for their
+// results, a node leaving meanwhile is synchronized again once it is back
+func (c *Controller) realize(kind, name, version string) error {
+	data, err := infradb.GetStoredObject(name)
+	if errors.Is(err, infradb.ErrKeyNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	placement, err := infradb.GetPlacement(name)
+	if err != nil {
+		return err
+	}
+	nodes := c.upNodes(placement)
+	if len(nodes) == 0 && placement != "" {
+		return fmt.Errorf("%w: %s %s lives on node %s", errNoNode, kind, name, placement)
+	}
+	if len(nodes) == 0 {
+		return errNoNode
+	}
+	task := &Task{Kind: kind, Name: name, ResourceVersion: version, Object: data}
+	key := objectKey(kind, name)
+	waits := map[*node]*waiter{}
+	for _, n := range nodes {
+		if w := c.dispatch(n, task); w != nil {
+			waits[n] = w
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	failures := []string{}
+	for n, w := range waits {
+		select {
+		case r := <-w.ch:
+			if !r.Success {
+				failures = append(failures, fmt.Sprintf("%s: %s", n.name, strings.TrimSpace(r.Details)))
+			}
+		case <-n.done:
+		case <-ctx.Done():
+			failures = append(failures, fmt.Sprintf("%s: no result within %v", n.name, c.timeout))
+		}
+		c.unwait(n, key, w)
+	}
+	if len(failures) != 0 {
+		sort.Strings(failures)
+		return fmt.Errorf("%s %s is not realized on %s", kind, name, strings.Join(failures, ", "))
+	}
+	return nil
+}
+
+// dispatch sends the task to the node and returns the waiter of its result, none when the node has realized this version already
+func (c *Controller) dispatch(n *node, task *Task) *waiter {
+	key := objectKey(task.Kind, task.Name)
+	n.sendMu.Lock()
+	defer n.sendMu.Unlock()
+
+	c.mu.Lock()
+	if r, ok := n.results[key]; ok && r.Success && r.ResourceVersion == task.ResourceVersion {
+		c.mu.Unlock()
+		return nil
+	}
+	w := &waiter{version: task.ResourceVersion, ch: make(chan *Result, 1)}
+	n.waiters[key] = append(n.waiters[key], w)
+	c.mu.Unlock()
+
+	n.send(&ControllerMessage{Task: task})
+	return w
+}
+
+// unwait forgets the waiter
+func (c *Controller) unwait(n *node, key string, w *waiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	waiters := n.waiters[key]
+	for i := range waiters {
+		if waiters[i] == w {
+			n.waiters[key] = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(n.waiters[key]) == 0 {
+		delete(n.waiters, key)
+	}
+}
+
+// retryTimer returns the backoff of the object, doubled at each failure
+func (c *Controller) retryTimer(key string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := 2 * time.Second
+	if previous, ok := c.timers[key]; ok {
+		timer = previous * 2
+	}
+	if timer > maxRetryTimer {
+		timer = maxRetryTimer
+	}
+	c.timers[key] = timer
+	return timer
+}
+
+// resetTimer forgets the backoff of the object realized on every node
+func (c *Controller) resetTimer(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.timers, key)
+}
+
+// connect runs the session of an agent: its node is synchronized first, then it receives the tasks until
+// the stream breaks or the node misses its heartbeats
+func (c *Controller) connect(stream grpc.ServerStream) error {
+	hello := &AgentMessage{}
+	if err := stream.RecvMsg(hello); err != nil {
+		return err
+	}
+	if hello.Hello == nil || hello.Hello.Node == "" {
+		return status.Error(codes.InvalidArgument, "the first message of an agent must name its node")
+	}
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	address := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		address = p.Addr.String()
+	}
+	n := c.register(hello.Hello, address, cancel)
+	defer c.unregister(n)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case msg := <-n.out:
+				if err := stream.SendMsg(msg); err != nil {
+					log.Printf("remote: failed to send to node %s: %v\n", n.name, err)
+					cancel()
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		for {
+			msg := &AgentMessage{}
+			if err := stream.RecvMsg(msg); err != nil {
+				log.Printf("remote: node %s left: %v\n", n.name, err)
+				cancel()
+				return
+			}
+			c.received(n, msg)
+		}
+	}()
+	go c.synchronize(n)
+
+	<-ctx.Done()
+	wg.Wait()
+	return nil
+}
+
+// register starts the session of the node, replacing its previous one
+func (c *Controller) register(hello *Hello, address string, cancel context.CancelFunc) *node {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if previous, ok := c.nodes[hello.Node]; ok && previous.up() {
+		log.Printf("remote: node %s connected again, its previous session ends\n", hello.Node)
+		previous.cancel()
+	}
+	now := time.Now()
+	n := &node{
+		name:        hello.Node,
+		address:     address,
+		version:     hello.Version,
+		connectedAt: now,
+		lastSeen:    now,
+		out:         make(chan *ControllerMessage, queueSize),
+		done:        make(chan struct{}),
+		cancel:      cancel,
+		results:     map[string]*Result{},
+		waiters:     map[string][]*waiter{},
+	}
+	c.nodes[hello.Node] = n
+	log.Printf("remote: node %s connected from %s\n", n.name, address)
+	return n
+}
+
+// unregister ends the session of the node, it is kept in the list as down
+func (c *Controller) unregister(n *node) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	close(n.done)
+	n.serving = false
+}
+
+// received handles a message of the node
+func (c *Controller) received(n *node, msg *AgentMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n.lastSeen = time.Now()
+	if msg.Heartbeat != nil {
+		n.serving = msg.Heartbeat.Serving
+	}
+	if r := msg.Result; r != nil {
+		key := objectKey(r.Kind, r.Name)
+		n.results[key] = r
+		for _, w := range n.waiters[key] {
+			if w.version != r.ResourceVersion {
+				continue
+			}
+			select {
+			case w.ch <- r:
+			default:
+			}
+		}
+	}
+}
+
+// synchronize sends to the node the names of all the objects then a task for each of them, the session
+// ends when the store cannot be read
+func (c *Controller) synchronize(n *node) {
+	n.sendMu.Lock()
+	defer n.sendMu.Unlock()
+	msgs, err := snapshot(n.name)
+	if err != nil {
+		log.Printf("remote: failed to synchronize node %s: %v\n", n.name, err)
+		n.cancel()
+		return
+	}
+	for _, msg := range msgs {
+		if !n.send(msg) {
+			return
+		}
+	}
+	log.Printf("remote: sent %d objects to node %s\n", len(msgs)-1, n.name)
+}
+
+// snapshot returns the sync message and the tasks of all the objects of the store living on the node,
+// the parents first
+func snapshot(node string) ([]*ControllerMessage, error) {
+	placements, err := infradb.GetPlacements()
+	if err != nil {
+		return nil, err
+	}
+	objects := &Sync{Objects: map[string][]string{}}
+	msgs := []*ControllerMessage{{Sync: objects}}
+	for _, kind := range infradb.RemoteKinds() {
+		names, err := infradb.GetStoredNames(kind)
+		if err != nil {
+			return nil, err
+		}
+		objects.Objects[kind] = []string{}
+		for _, name := range names {
+			if p := placements[name]; p != "" && p != node {
+				continue
+			}
+			data, err := infradb.GetStoredObject(name)
+			if errors.Is(err, infradb.ErrKeyNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			stored := struct{ ResourceVersion string }{}
+			if err := json.Unmarshal(data, &stored); err != nil {
+				return nil, err
+			}
+			objects.Objects[kind] = append(objects.Objects[kind], name)
+			msgs = append(msgs, &ControllerMessage{Task: &Task{Kind: kind, Name: name, ResourceVersion: stored.ResourceVersion, Object: data}})
+		}
+	}
+	return msgs, nil
+}
+
+// sweep ends the sessions of the nodes missing their heartbeats, they connect again and are synchronized
+func (c *Controller) sweep(ctx context.Context) {
+	ticker := time.NewTicker(c.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		c.mu.Lock()
+		for _, n := range c.nodes {
+			if n.up() && time.Since(n.lastSeen) > missedHeartbeats*c.heartbeat {
+				log.Printf("remote: node %s missed its heartbeats, its session ends\n", n.name)
+				n.cancel()
+			}
+		}
+		c.mu.Unlock()
+	}
+}
+
+// objectKey identifies an object in the results of the nodes
+func objectKey(kind, name string) string {
+	return kind + " " + name
+}
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package remote splits the bridge into a controller serving the API and holding the store, and the
+// node agents programming the DPUs of a cluster
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// Modes of the bridge, it programs its own node when the mode is empty
+const (
+	ModeController = "controller"
+	ModeAgent      = "agent"
+)
+
+// Component is the name of the component of the controller realizing the objects on the nodes
+const Component = "remote"
+
+// defaultHeartbeat and defaultTimeout apply when the config leaves them out
+const (
+	defaultHeartbeat = 5 * time.Second
+	defaultTimeout   = 20 * time.Second
+)
+
+// missedHeartbeats is the number of heartbeats a node misses before it is down
+const missedHeartbeats = 3
+
+// Hello is the first message of an agent, it names its node
+type Hello struct {
+	Node    string `json:"node"`
+	Version string `json:"version,omitempty"`
+}
+
+// Heartbeat tells that the agent is alive, Serving is the overall health of its node
+type Heartbeat struct {
+	Serving bool `json:"serving"`
+}
+
+// Result is the outcome of the realization of a version of an object on the node
+type Result struct {
+	Kind            string `json:"kind"`
+	Name            string `json:"name"`
+	ResourceVersion string `json:"resourceVersion"`
+	Success         bool   `json:"success"`
+	Details         string `json:"details,omitempty"`
+}
+
+// Task realizes a version of an object on the node, Object is the object as stored by the controller
+type Task struct {
+	Kind            string          `json:"kind"`
+	Name            string          `json:"name"`
+	ResourceVersion string          `json:"resourceVersion"`
+	Object          json.RawMessage `json:"object"`
+}
+
+// Sync lists the names of the objects of each kind held by the controller when the agent connects,
+// the node tears down the other ones. The tasks of all the objects follow.
+type Sync struct {
+	Objects map[string][]string `json:"objects"`
+}
+
+// AgentMessage is a message of an agent to the controller
+type AgentMessage struct {
+	Hello     *Hello     `json:"hello,omitempty"`
+	Heartbeat *Heartbeat `json:"heartbeat,omitempty"`
+	Result    *Result    `json:"result,omitempty"`
+}
+
+// ControllerMessage is a message of the controller to an agent
+type ControllerMessage struct {
+	Sync *Sync `json:"sync,omitempty"`
+	Task *Task `json:"task,omitempty"`
+}
+
+// The messages are json encoded, the service has no protobuf definition
+const codecName = "json"
+
+// jsonCodec encodes the messages of the service
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// serviceName and connectMethod name the stream between the agents and the controller
+const (
+	serviceName   = "opi_evpn_bridge.remote.v1.NodeAgentService"
+	connectMethod = "/" + serviceName + "/Connect"
+)
+
+// agentService is implemented by the controller
+type agentService interface {
+	connect(stream grpc.ServerStream) error
+}
+
+// serviceDesc describes the service served by the controller, the agents open a single stream
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*agentService)(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName: "Connect",
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			return srv.(agentService).connect(stream)
+		},
+		ServerStreams: true,
+		ClientStreams: true,
+	}},
+}
+
+// RegisterService serves the stream of the agents on the gRPC server of the controller
+func RegisterService(s *grpc.Server, c *Controller) {
+	s.RegisterService(&serviceDesc, c)
+}
+
+// openStream opens the stream of an agent to the controller
+func openStream(ctx context.Context, conn *grpc.ClientConn) (grpc.ClientStream, error) {
+	return conn.NewStream(ctx, &serviceDesc.Streams[0], connectMethod, grpc.CallContentSubtype(codecName))
+}
+
+// seconds converts a setting in seconds, the default applies when it is zero
+func seconds(setting int, defaultValue time.Duration) time.Duration {
+	if setting == 0 {
+		return defaultValue
+	}
+	return time.Duration(setting) * time.Second
+}
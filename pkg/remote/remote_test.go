@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+)
+
+// newTestController serves the stream of the agents on top of a gomap db holding a vrf
+func newTestController(t *testing.T) (*Controller, *grpc.ClientConn, *infradb.Vrf) {
+	eventbus.EBus.StartSubscriber("dummy", "vrf", 1, nil)
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	vrf, err := infradb.NewVrf("//network.opiproject.org/vrfs/blue", &infradb.VrfSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infradb.CreateVrf(vrf); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewController(&config.Config{Remote: config.RemoteConfig{Heartbeat: 1, Timeout: 1}})
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	RegisterService(s, c)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return c, conn, vrf
+}
+
+// receive returns the next message of the controller
+func receive(t *testing.T, stream grpc.ClientStream) *ControllerMessage {
+	msg := &ControllerMessage{}
+	if err := stream.RecvMsg(msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func Test_Controller(t *testing.T) {
+	c, conn, vrf := newTestController(t)
+	if err := c.realize("vrf", vrf.Name, vrf.ResourceVersion); !errors.Is(err, errNoNode) {
+		t.Fatalf("expected no node to be up, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := openStream(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(&AgentMessage{Hello: &Hello{Node: "dpu-1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the node is synchronized first
+	msg := receive(t, stream)
+	if msg.Sync == nil || len(msg.Sync.Objects["vrf"]) != 1 || msg.Sync.Objects["vrf"][0] != vrf.Name {
+		t.Fatalf("expected the sync of the vrf, got %+v", msg)
+	}
+	msg = receive(t, stream)
+	if msg.Task == nil || msg.Task.Name != vrf.Name || msg.Task.ResourceVersion != vrf.ResourceVersion {
+		t.Fatalf("expected the task of the vrf, got %+v", msg)
+	}
+
+	tests := map[string]struct {
+		success bool
+		errMsg  string
+	}{
+		"realized": {true, ""},
+		"failed":   {false, "dpu-1: frr: vrf not found"},
+	}
+	for _, name := range []string{"realized", "failed"} {
+		tt := tests[name]
+		t.Run(name, func(t *testing.T) {
+			errs := make(chan error, 1)
+			go func() { errs <- c.realize("vrf", vrf.Name, "v2-"+name) }()
+			msg := receive(t, stream)
+			if msg.Task == nil || msg.Task.ResourceVersion != "v2-"+name {
+				t.Fatalf("expected the task of the new version, got %+v", msg)
+			}
+			result := &Result{Kind: "vrf", Name: vrf.Name, ResourceVersion: msg.Task.ResourceVersion, Success: tt.success}
+			if !tt.success {
+				result.Details = "frr: vrf not found"
+			}
+			if err := stream.SendMsg(&AgentMessage{Result: result}); err != nil {
+				t.Fatal(err)
+			}
+			err := <-errs
+			if tt.errMsg == "" && err != nil {
+				t.Errorf("expected the vrf to be realized, got %v", err)
+			}
+			if tt.errMsg != "" && (err == nil || !strings.Contains(err.Error(), tt.errMsg)) {
+				t.Errorf("expected error %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	nodes := c.Nodes()
+	if len(nodes) != 1 || nodes[0].Name != "dpu-1" || nodes[0].State != NodeUp || len(nodes[0].Failed) != 1 {
+		t.Fatalf("expected dpu-1 to be up with a failed vrf, got %+v", nodes)
+	}
+	if err := c.Probe(ctx); err != nil {
+		t.Errorf("expected the probe to succeed, got %v", err)
+	}
+
+	// the node leaves, the objects are not realized anymore
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Nodes()[0].State != NodeDown {
+		if time.Now().After(deadline) {
+			t.Fatal("expected dpu-1 to be down")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := c.Probe(context.Background()); !errors.Is(err, errNoNode) {
+		t.Errorf("expected the probe to fail, got %v", err)
+	}
+}
+
+func Test_ControllerTimeout(t *testing.T) {
+	c, conn, vrf := newTestController(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := openStream(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(&AgentMessage{Hello: &Hello{Node: "dpu-2"}}); err != nil {
+		t.Fatal(err)
+	}
+	receive(t, stream)
+	receive(t, stream)
+
+	// the node never answers
+	err = c.realize("vrf", vrf.Name, vrf.ResourceVersion)
+	if err == nil || !strings.Contains(err.Error(), "dpu-2: no result within") {
+		t.Errorf("expected the timeout of dpu-2, got %v", err)
+	}
+	if timer := c.retryTimer("vrf blue"); timer != 2*time.Second {
+		t.Errorf("expected the first backoff to be 2s, got %v", timer)
+	}
+	if timer := c.retryTimer("vrf blue"); timer != 4*time.Second {
+		t.Errorf("expected the backoff to double, got %v", timer)
+	}
+}
+
+func Test_ConnectNeedsHello(t *testing.T) {
+	_, conn, _ := newTestController(t)
+	stream, err := openStream(context.Background(), conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(&AgentMessage{Heartbeat: &Heartbeat{Serving: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.RecvMsg(&ControllerMessage{}); err == nil || !strings.Contains(err.Error(), "must name its node") {
+		t.Errorf("expected the stream to be refused, got %v", err)
+	}
+}
+
+func Test_ControllerPlacement(t *testing.T) {
+	c, conn, vrf := newTestController(t)
+	if err := infradb.SetPlacement(vrf.Name, "dpu-1"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := openStream(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(&AgentMessage{Hello: &Hello{Node: "dpu-2"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the vrf of dpu-1 is not synchronized on dpu-2, which tears it down if it holds it
+	msg := receive(t, stream)
+	if msg.Sync == nil || len(msg.Sync.Objects["vrf"]) != 0 {
+		t.Fatalf("expected an empty sync of the vrfs, got %+v", msg)
+	}
+	err = c.realize("vrf", vrf.Name, vrf.ResourceVersion)
+	if !errors.Is(err, errNoNode) || !strings.Contains(err.Error(), "lives on node dpu-1") {
+		t.Errorf("expected dpu-1 to be missing, got %v", err)
+	}
+}
@@ -0,0 +1,347 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package routing abstracts the routing stack which runs the EVPN control plane
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"sync"
+)
+
+// DefaultBackend is the backend used when the config does not select one
+const DefaultBackend = "frr"
+
+// ErrNoBackend is returned when no routing backend has been selected
+var ErrNoBackend = errors.New("no routing backend selected")
+
+// Backend is a routing stack like FRR or an embedded BGP speaker. The backend subscribes
+// to the infradb events of the objects it programs when it is initialized and reports its
+// state in a form which does not depend on the stack.
+type Backend interface {
+	// Name is the name of the backend in the config, also used as the name of its health service
+	Name() string
+	Initialize()
+	DeInitialize()
+	// Probe checks that the routing stack is reachable, DeepProbe that it answers
+	Probe(ctx context.Context) error
+	DeepProbe(ctx context.Context) error
+	EvpnVnis(ctx context.Context) ([]EvpnVni, error)
+	EvpnRoutes(ctx context.Context) ([]EvpnRoute, error)
+	// BgpPeers and BgpRoutes take the name of the vrf resource
+	BgpPeers(ctx context.Context, vrf string) ([]BgpPeer, error)
+	BgpRoutes(ctx context.Context, vrf string) ([]BgpRoute, error)
+	// NexthopGroups returns the kernel nexthop groups of the received routes and how much they are shared
+	NexthopGroups(ctx context.Context) ([]NexthopGroup, NexthopGroupStats, error)
+	// SetDrain steers the traffic away from the node in the given mode, or back to it when drained is false
+	SetDrain(ctx context.Context, mode DrainMode, drained bool) error
+}
+
+// DrainMode is how the routing stack steers the traffic away from the node before a maintenance
+type DrainMode string
+
+const (
+	// DrainWithdraw withdraws the EVPN routes originated by the node
+	DrainWithdraw DrainMode = "withdraw"
+	// DrainMed advertises the EVPN routes with the highest MED
+	DrainMed DrainMode = "med"
+	// DrainPrepend advertises the EVPN routes with the local AS prepended
+	DrainPrepend DrainMode = "prepend"
+)
+
+// ParseDrainMode checks the name of a drain mode, the empty name stands for DrainWithdraw
+func ParseDrainMode(name string) (DrainMode, error) {
+	switch mode := DrainMode(name); mode {
+	case "":
+		return DrainWithdraw, nil
+	case DrainWithdraw, DrainMed, DrainPrepend:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown drain mode %s, expected one of %v", name, []DrainMode{DrainWithdraw, DrainMed, DrainPrepend})
+	}
+}
+
+// EvpnVni is the state of a VNI in the routing stack
+type EvpnVni struct {
+	Vni       uint32
+	Type      string
+	VxlanIf   string
+	TenantVrf string
+	NumMacs   int
+	NumArpNd  int
+	// The fields below are empty when the VNI is not known by the BGP speaker
+	InKernel  bool
+	Rd        string
+	ImportRts []string
+	ExportRts []string
+}
+
+// EvpnRoute is a path of a route of the l2vpn evpn table
+type EvpnRoute struct {
+	Rd        string
+	Prefix    string
+	RouteType int
+	Mac       string
+	IP        string
+	Nexthops  []string
+	Best      bool
+	PathFrom  string
+}
+
+// BgpPeer is the state of a bgp session of a vrf
+type BgpPeer struct {
+	Afi      string
+	Address  string
+	RemoteAs string
+	State    string
+	Uptime   string
+	PfxRcd   int
+	PfxSnt   int
+	// GrLocalMode and GrRemoteMode are the graceful restart modes of the bridge and of the peer on the
+	// session, e.g. Restart, Helper or Disable, empty when the backend does not report them
+	GrLocalMode  string
+	GrRemoteMode string
+	// GrRestartTime is the restart time announced to the peer, GrPeerRestartTime the one received from it, in seconds
+	GrRestartTime     int
+	GrPeerRestartTime int
+}
+
+// BgpRoute is a path of a route of the unicast table of a vrf
+type BgpRoute struct {
+	Prefix   string
+	Nexthops []string
+	Best     bool
+	PathFrom string
+	AsPath   string
+	Origin   string
+	// Multipath tells that the path is one of the paths installed for the prefix, Weight is its share
+	// of the traffic when the paths are weighted by their link bandwidth, zero otherwise
+	Multipath bool
+	Weight    uint32
+}
+
+// NexthopMember is a remote vtep of a nexthop group, Weight is zero when the group is not weighted
+type NexthopMember struct {
+	Nexthop string
+	Weight  uint32
+}
+
+// NexthopGroup is a kernel nexthop object shared by the routes of a vrf which go to the same remote vteps
+type NexthopGroup struct {
+	ID      uint32
+	Vrf     string
+	Members []NexthopMember
+	// Routes is the number of routes using the group
+	Routes int
+}
+
+// NexthopGroupStats tells how much the nexthop groups are reused by the routes. Created, Deleted and Reused
+// count the groups programmed, the groups removed and the routes installed onto an existing group since the
+// start, they are zero when the backend does not program the groups itself.
+type NexthopGroupStats struct {
+	Groups  int
+	Routes  int
+	Created uint64
+	Deleted uint64
+	Reused  uint64
+}
+
+// UnderlayPeer is a bgp session of the underlay, with the address of the neighbor or, when it is unnumbered,
+// on the interface. RemoteAs is the AS number of the neighbor, internal or external.
+type UnderlayPeer struct {
+	Address   string
+	Interface string
+	RemoteAs  string
+}
+
+// Underlay is the bgp configuration which brings the node into the fabric, VtepIP is advertised to the peers
+type Underlay struct {
+	RouterID string
+	VtepIP   netip.Prefix
+	Peers    []UnderlayPeer
+}
+
+// UnderlayConfigurer is implemented by the backends which bring up the bgp sessions of the underlay
+type UnderlayConfigurer interface {
+	// ConfigureUnderlay applies the underlay, it leaves alone what has been configured already
+	ConfigureUnderlay(ctx context.Context, underlay Underlay) error
+}
+
+// Transports of the labels of the vtep addresses in an MPLS core
+const (
+	MplsTransportLdp = "ldp"
+	MplsTransportSr  = "sr"
+)
+
+// Mpls is the MPLS core the VPCs selecting the MPLS dataplane are carried over
+type Mpls struct {
+	// Transport distributes the label of VtepIP, the next hop of the VPN routes of the VPCs
+	Transport  string
+	VtepIP     netip.Prefix
+	Interfaces []string
+	// SidIndex is the prefix segment of VtepIP with the sr transport
+	SidIndex uint32
+	// Neighbors exchange the VPN routes, and the labelled routes of the sr transport
+	Neighbors []string
+}
+
+// MplsConfigurer is implemented by the backends which carry the VPCs over an MPLS core
+type MplsConfigurer interface {
+	// ConfigureMpls brings up the transport of the labels, it leaves alone what has been configured already
+	ConfigureMpls(ctx context.Context, mpls Mpls) error
+}
+
+// Srv6 is the SRv6 fabric the VPCs selecting the SRv6 dataplane are carried over
+type Srv6 struct {
+	// Locator holds the SIDs of the VPCs, it is advertised to the Neighbors which exchange the VPN routes
+	LocatorName string
+	Locator     netip.Prefix
+	// Source is the source address of the encapsulated packets
+	Source    netip.Addr
+	Neighbors []string
+}
+
+// Srv6Configurer is implemented by the backends which carry the VPCs over an SRv6 fabric
+type Srv6Configurer interface {
+	// ConfigureSrv6 sets up the locator and the VPN sessions, it leaves alone what has been configured already
+	ConfigureSrv6(ctx context.Context, srv6 Srv6) error
+}
+
+// DupAddrDetection are the thresholds of the mac mobility of RFC 7432: an address moving between the VTEPs
+// MaxMoves times within Time seconds is a duplicate
+type DupAddrDetection struct {
+	MaxMoves int
+	Time     int
+	// Freeze holds a duplicate address where it was learnt last for Freeze seconds, until it is cleared when
+	// negative, the address keeps moving when zero
+	Freeze int
+}
+
+// Duplicate is a MAC address, or the IP address of a MAC, flagged as duplicate in the VNI
+type Duplicate struct {
+	Vni uint32
+	Mac string
+	// IP is set for a duplicate ARP/ND entry, empty for a duplicate MAC address
+	IP string
+	// RemoteVtep is the VTEP the address was learnt from last, empty when it was learnt locally
+	RemoteVtep string
+	// Moves is the number of moves which flagged the address
+	Moves int
+}
+
+// DuplicateDetector is implemented by the backends which detect the duplicate addresses of the EVPN
+type DuplicateDetector interface {
+	// ConfigureDupAddrDetection sets the thresholds of the detection, zero values keep the defaults of the stack
+	ConfigureDupAddrDetection(ctx context.Context, detection DupAddrDetection) error
+	// Duplicates returns the duplicate addresses of all the VNIs
+	Duplicates(ctx context.Context) ([]Duplicate, error)
+	// ClearDuplicate clears the duplicate addresses of the VNI, the MAC or IP address only when one is given,
+	// which unfreezes them
+	ClearDuplicate(ctx context.Context, vni uint32, mac, ip string) error
+}
+
+// EvpnMac is a MAC address of a VNI with the sequence numbers of its MAC Mobility extended community, which
+// the VTEP learning the address bumps at every move
+type EvpnMac struct {
+	Vni uint32
+	Mac string
+	// RemoteVtep is the VTEP the address is learnt from, empty when it is learnt locally
+	RemoteVtep string
+	LocalSeq   uint32
+	RemoteSeq  uint32
+}
+
+// MacReporter is implemented by the backends which report the MAC addresses of the VNIs
+type MacReporter interface {
+	EvpnMacs(ctx context.Context) ([]EvpnMac, error)
+}
+
+// RunningVrf is a vrf of the running config of the routing stack
+type RunningVrf struct {
+	// Name is the name of the vrf in the routing stack, which is the name of its kernel device
+	Name string
+	// Vni is the L3 VNI of the vrf, zero when it has none
+	Vni uint32
+	// Bgp tells whether the vrf has a bgp instance
+	Bgp bool
+}
+
+// ConfigReporter is implemented by the backends which report the vrfs of their running config
+type ConfigReporter interface {
+	RunningVrfs(ctx context.Context) ([]RunningVrf, error)
+}
+
+// Encapsulations of the tunnels of the logical bridges
+const (
+	EncapVxlan  = "vxlan"
+	EncapGeneve = "geneve"
+)
+
+// EncapSupporter is implemented by the backends which tell the encapsulations they carry the logical
+// bridges over, the other backends carry them over VXLAN only
+type EncapSupporter interface {
+	Encaps() []string
+}
+
+// SupportsEncap fails when the backend cannot carry the logical bridges over the encapsulation
+func SupportsEncap(b Backend, encap string) error {
+	encaps := []string{EncapVxlan}
+	if s, ok := b.(EncapSupporter); ok {
+		encaps = s.Encaps()
+	}
+	for _, e := range encaps {
+		if e == encap {
+			return nil
+		}
+	}
+	return fmt.Errorf("the %s routing backend carries the logical bridges over %v only, not %s", b.Name(), encaps, encap)
+}
+
+// backends holds the registered backends by name and the selected one
+var backends = struct {
+	sync.RWMutex
+	byName map[string]Backend
+	active Backend
+}{byName: make(map[string]Backend)}
+
+// Register makes the backend available for selection
+func Register(b Backend) {
+	backends.Lock()
+	defer backends.Unlock()
+	backends.byName[b.Name()] = b
+}
+
+// Select selects the backend by name, the default backend when the name is empty
+func Select(name string) (Backend, error) {
+	if name == "" {
+		name = DefaultBackend
+	}
+	backends.Lock()
+	defer backends.Unlock()
+	b, ok := backends.byName[name]
+	if !ok {
+		names := make([]string, 0, len(backends.byName))
+		for n := range backends.byName {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown routing backend %s, expected one of %v", name, names)
+	}
+	backends.active = b
+	return b, nil
+}
+
+// Get returns the selected backend
+func Get() (Backend, error) {
+	backends.RLock()
+	defer backends.RUnlock()
+	if backends.active == nil {
+		return nil, ErrNoBackend
+	}
+	return backends.active, nil
+}
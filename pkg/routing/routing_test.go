@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package routing abstracts the routing stack which runs the EVPN control plane
+package routing
+
+import (
+	"context"
+	"testing"
+)
+
+// testBackend is a backend without routing stack
+type testBackend struct{ name string }
+
+func (b testBackend) Name() string                                        { return b.name }
+func (testBackend) Initialize()                                           {}
+func (testBackend) DeInitialize()                                         {}
+func (testBackend) Probe(context.Context) error                           { return nil }
+func (testBackend) DeepProbe(context.Context) error                       { return nil }
+func (testBackend) EvpnVnis(context.Context) ([]EvpnVni, error)           { return nil, nil }
+func (testBackend) EvpnRoutes(context.Context) ([]EvpnRoute, error)       { return nil, nil }
+func (testBackend) BgpPeers(context.Context, string) ([]BgpPeer, error)   { return nil, nil }
+func (testBackend) BgpRoutes(context.Context, string) ([]BgpRoute, error) { return nil, nil }
+func (testBackend) SetDrain(context.Context, DrainMode, bool) error       { return nil }
+func (testBackend) NexthopGroups(context.Context) ([]NexthopGroup, NexthopGroupStats, error) {
+	return nil, NexthopGroupStats{}, nil
+}
+
+func Test_Select(t *testing.T) {
+	if _, err := Get(); err != ErrNoBackend {
+		t.Errorf("expected %v before the selection, received %v", ErrNoBackend, err)
+	}
+	Register(testBackend{name: DefaultBackend})
+	Register(testBackend{name: "gobgp"})
+
+	if _, err := Select("bird"); err == nil {
+		t.Error("expected the selection of an unknown backend to fail")
+	}
+	tests := map[string]string{
+		"":      DefaultBackend,
+		"gobgp": "gobgp",
+	}
+	for name, expected := range tests {
+		if _, err := Select(name); err != nil {
+			t.Fatal(err)
+		}
+		b, err := Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Name() != expected {
+			t.Errorf("selecting %q: expected %s, received %s", name, expected, b.Name())
+		}
+	}
+}
+
+func Test_ParseDrainMode(t *testing.T) {
+	tests := map[string]DrainMode{
+		"":         DrainWithdraw,
+		"withdraw": DrainWithdraw,
+		"med":      DrainMed,
+		"prepend":  DrainPrepend,
+	}
+	for name, expected := range tests {
+		mode, err := ParseDrainMode(name)
+		if err != nil || mode != expected {
+			t.Errorf("parsing %q: expected %s, received %s, %v", name, expected, mode, err)
+		}
+	}
+	if _, err := ParseDrainMode("shutdown"); err == nil {
+		t.Error("expected an unknown drain mode to fail")
+	}
+}
+
+// geneveBackend is a backend carrying the logical bridges over geneve too
+type geneveBackend struct{ testBackend }
+
+func (geneveBackend) Encaps() []string { return []string{EncapVxlan, EncapGeneve} }
+
+func Test_SupportsEncap(t *testing.T) {
+	if err := SupportsEncap(testBackend{name: "frr"}, EncapVxlan); err != nil {
+		t.Errorf("expected every backend to carry vxlan, received %v", err)
+	}
+	if err := SupportsEncap(testBackend{name: "frr"}, EncapGeneve); err == nil {
+		t.Error("expected a backend without encapsulations to refuse geneve")
+	}
+	if err := SupportsEncap(geneveBackend{testBackend{name: "gobgp"}}, EncapGeneve); err != nil {
+		t.Errorf("expected the backend to carry geneve, received %v", err)
+	}
+}
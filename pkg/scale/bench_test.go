@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package scale measures the bridge at scale: the latency of the gRPC calls, the memory footprint
+// and the time until the objects are realized
+package scale
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+)
+
+// newBenchEnv starts the services with the logs muted
+func newBenchEnv(b *testing.B) *Env {
+	log.SetOutput(io.Discard)
+	env, err := NewEnv(context.Background())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() {
+		env.Close()
+		log.SetOutput(os.Stderr)
+	})
+	return env
+}
+
+func BenchmarkCreateBridgePort(b *testing.B) {
+	ctx := context.Background()
+	env := newBenchEnv(b)
+	if err := env.Populate(ctx, 1, 0, map[string]Latencies{}); err != nil {
+		b.Fatal(err)
+	}
+	client := pb.NewBridgePortServiceClient(env.Conn)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for j := 0; j < b.N; j++ {
+		if _, err := client.CreateBridgePort(ctx, &pb.CreateBridgePortRequest{BridgePortId: BridgePortID(0, j), BridgePort: BridgePort(0, j)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListSvis(b *testing.B) {
+	ctx := context.Background()
+	env := newBenchEnv(b)
+	if err := env.Populate(ctx, 1000, 0, map[string]Latencies{}); err != nil {
+		b.Fatal(err)
+	}
+	client := pb.NewSviServiceClient(env.Conn)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		token := ""
+		for {
+			resp, err := client.ListSvis(ctx, &pb.ListSvisRequest{PageSize: 250, PageToken: token})
+			if err != nil {
+				b.Fatal(err)
+			}
+			if token = resp.NextPageToken; token == "" {
+				break
+			}
+		}
+	}
+}
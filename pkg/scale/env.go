@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package scale measures the bridge at scale: the latency of the gRPC calls, the memory footprint
+// and the time until the objects are realized
+package scale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/taskmanager"
+	"github.com/opiproject/opi-evpn-bridge/pkg/port"
+	"github.com/opiproject/opi-evpn-bridge/pkg/svi"
+	"github.com/opiproject/opi-evpn-bridge/pkg/vrf"
+)
+
+// scaleComp is the name of the subscriber realizing the objects
+const scaleComp = "scale"
+
+// startTaskManager starts the task manager once for all the environments
+var startTaskManager sync.Once
+
+// ackHandler realizes every object at once, so that the measures are the ones of the bridge itself
+type ackHandler struct{}
+
+// HandleEvent reports the object as realized
+func (ackHandler) HandleEvent(eventType string, objectData *eventbus.ObjectData) {
+	comp := common.Component{Name: scaleComp, CompStatus: common.ComponentStatusSuccess}
+	var err error
+	switch eventType {
+	case "vrf":
+		err = infradb.UpdateVrfStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
+	case "logical-bridge":
+		err = infradb.UpdateLBStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
+	case "svi":
+		err = infradb.UpdateSviStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
+	case "bridge-port":
+		err = infradb.UpdateBPStatus(objectData.Name, objectData.ResourceVersion, objectData.NotificationID, nil, comp)
+	}
+	if err != nil {
+		log.Printf("scale: failed to update the status of %s: %v\n", objectData.Name, err)
+	}
+}
+
+// Env runs the gRPC services of the bridge in memory over an empty store
+type Env struct {
+	Conn   *grpc.ClientConn
+	server *grpc.Server
+}
+
+// NewEnv starts the services, the objects are realized by a subscriber answering at once
+func NewEnv(ctx context.Context) (*Env, error) {
+	startTaskManager.Do(taskmanager.TaskMan.StartTaskManager)
+	for _, eventType := range []string{"vrf", "logical-bridge", "svi", "bridge-port"} {
+		eventbus.EBus.StartSubscriber(scaleComp, eventType, 1, ackHandler{})
+	}
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		return nil, err
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	pb.RegisterVrfServiceServer(server, vrf.NewServer())
+	pb.RegisterLogicalBridgeServiceServer(server, bridge.NewServer())
+	pb.RegisterSviServiceServer(server, svi.NewServer())
+	pb.RegisterBridgePortServiceServer(server, port.NewServer())
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Printf("scale: server stopped: %v\n", err)
+		}
+	}()
+
+	conn, err := grpc.DialContext(ctx, "",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }))
+	if err != nil {
+		server.Stop()
+		return nil, err
+	}
+	return &Env{Conn: conn, server: server}, nil
+}
+
+// Close stops the services
+func (e *Env) Close() {
+	_ = e.Conn.Close()
+	e.server.Stop()
+}
+
+// Pending counts the objects which are not up yet, an empty collection is not found in the store
+func Pending() (int, error) {
+	pending := 0
+	vrfs, err := infradb.GetAllVrfs()
+	if err != nil && !errors.Is(err, infradb.ErrKeyNotFound) {
+		return 0, err
+	}
+	for _, v := range vrfs {
+		if v.Status.VrfOperStatus != infradb.VrfOperStatusUp {
+			pending++
+		}
+	}
+	lbs, err := infradb.GetAllLBs()
+	if err != nil && !errors.Is(err, infradb.ErrKeyNotFound) {
+		return 0, err
+	}
+	for _, lb := range lbs {
+		if lb.Status.LBOperStatus != infradb.LogicalBridgeOperStatusUp {
+			pending++
+		}
+	}
+	svis, err := infradb.GetAllSvis()
+	if err != nil && !errors.Is(err, infradb.ErrKeyNotFound) {
+		return 0, err
+	}
+	for _, s := range svis {
+		if s.Status.SviOperStatus != infradb.SviOperStatusUp {
+			pending++
+		}
+	}
+	bps, err := infradb.GetAllBPs()
+	if err != nil && !errors.Is(err, infradb.ErrKeyNotFound) {
+		return 0, err
+	}
+	for _, bp := range bps {
+		if bp.Status.BPOperStatus != infradb.BridgePortOperStatusUp {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// WaitConverged waits until all the objects are up and returns how long it took
+func WaitConverged(ctx context.Context, poll time.Duration) (time.Duration, error) {
+	start := time.Now()
+	for {
+		pending, err := Pending()
+		if err != nil {
+			return 0, err
+		}
+		if pending == 0 {
+			return time.Since(start), nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, fmt.Errorf("%d objects are still not up: %w", pending, ctx.Err())
+		case <-time.After(poll):
+		}
+	}
+}
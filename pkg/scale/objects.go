@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package scale measures the bridge at scale: the latency of the gRPC calls, the memory footprint
+// and the time until the objects are realized
+package scale
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+)
+
+// MaxTenants is the number of subnets which fit the VLANs of the logical bridges
+const MaxTenants = 4000
+
+// VrfID is the id of the vrf of all the subnets
+const VrfID = "scale-vrf"
+
+// ipv4Prefix returns the prefix of the address with the length
+func ipv4Prefix(addr uint32, length int32) *pc.IPPrefix {
+	return &pc.IPPrefix{
+		Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: addr}},
+		Len:  length,
+	}
+}
+
+// vtep is the address of the local vtep, 10.255.255.1
+const vtep = 10<<24 | 255<<16 | 255<<8 | 1
+
+// Vrf returns the vrf of the subnets
+func Vrf() *pb.Vrf {
+	return &pb.Vrf{Spec: &pb.VrfSpec{
+		Vni:              proto.Uint32(1000),
+		LoopbackIpPrefix: ipv4Prefix(vtep, 32),
+		VtepIpPrefix:     ipv4Prefix(vtep, 32),
+	}}
+}
+
+// LogicalBridgeID returns the id of the i-th logical bridge
+func LogicalBridgeID(i int) string {
+	return fmt.Sprintf("scale-lb-%d", i)
+}
+
+// LogicalBridge returns the i-th logical bridge, with the VLAN i+2 and the VNI 10000+i
+func LogicalBridge(i int) *pb.LogicalBridge {
+	return &pb.LogicalBridge{Spec: &pb.LogicalBridgeSpec{
+		VlanId:       uint32(i + 2),
+		Vni:          proto.Uint32(uint32(10000 + i)),
+		VtepIpPrefix: ipv4Prefix(vtep, 32),
+	}}
+}
+
+// SviID returns the id of the svi of the i-th subnet
+func SviID(i int) string {
+	return fmt.Sprintf("scale-svi-%d", i)
+}
+
+// Svi returns the gateway 10.<i/256>.<i%256>.1/24 of the i-th subnet on its logical bridge
+func Svi(i int) *pb.Svi {
+	return &pb.Svi{Spec: &pb.SviSpec{
+		Vrf:           "//network.opiproject.org/vrfs/" + VrfID,
+		LogicalBridge: "//network.opiproject.org/bridges/" + LogicalBridgeID(i),
+		MacAddress:    []byte{0x02, 0x01, 0, 0, byte(i >> 8), byte(i)},
+		GwIpPrefix:    []*pc.IPPrefix{ipv4Prefix(uint32(10<<24|(i/256)<<16|(i%256)<<8|1), 24)},
+	}}
+}
+
+// BridgePortID returns the id of the j-th port of the i-th subnet
+func BridgePortID(i, j int) string {
+	return fmt.Sprintf("scale-port-%d-%d", i, j)
+}
+
+// BridgePort returns the j-th access port of the i-th subnet
+func BridgePort(i, j int) *pb.BridgePort {
+	return &pb.BridgePort{Spec: &pb.BridgePortSpec{
+		MacAddress:     []byte{0x02, byte(j >> 16), byte(j >> 8), byte(j), byte(i >> 8), byte(i)},
+		Ptype:          pb.BridgePortType_BRIDGE_PORT_TYPE_ACCESS,
+		LogicalBridges: []string{"//network.opiproject.org/bridges/" + LogicalBridgeID(i)},
+	}}
+}
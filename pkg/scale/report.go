@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package scale measures the bridge at scale: the latency of the gRPC calls, the memory footprint
+// and the time until the objects are realized
+package scale
+
+import (
+	"encoding/json"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// Latencies are the durations of the calls of a method
+type Latencies []time.Duration
+
+// Percentile returns the duration which p percent of the calls did not exceed
+func (l Latencies) Percentile(p float64) time.Duration {
+	if len(l) == 0 {
+		return 0
+	}
+	sorted := append(Latencies{}, l...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	i := int(float64(len(sorted))*p/100+0.5) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
+
+// CallStats summarizes the latencies of a method in microseconds
+type CallStats struct {
+	Calls int   `json:"calls"`
+	P50   int64 `json:"p50_us"`
+	P99   int64 `json:"p99_us"`
+	Max   int64 `json:"max_us"`
+}
+
+// Stats summarizes the latencies
+func (l Latencies) Stats() CallStats {
+	return CallStats{
+		Calls: len(l),
+		P50:   l.Percentile(50).Microseconds(),
+		P99:   l.Percentile(99).Microseconds(),
+		Max:   l.Percentile(100).Microseconds(),
+	}
+}
+
+// Report is the outcome of a scale run, written as json so that the runs before and after a change can be compared
+type Report struct {
+	Subnets int                  `json:"subnets"`
+	Ports   int                  `json:"ports"`
+	Calls   map[string]CallStats `json:"calls"`
+	// HeapBytes is the heap in use once the objects are created, after a garbage collection
+	HeapBytes uint64 `json:"heap_bytes"`
+	// ConvergenceMs is the time from the first call until all the objects are up
+	ConvergenceMs int64 `json:"convergence_ms"`
+}
+
+// HeapInUse returns the heap in use after a garbage collection
+func HeapInUse() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// Write writes the report as json to the file
+func (r *Report) Write(file string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o600)
+}
@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package scale measures the bridge at scale: the latency of the gRPC calls, the memory footprint
+// and the time until the objects are realized
+package scale
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Percentile(t *testing.T) {
+	l := Latencies{}
+	for i := 100; i >= 1; i-- {
+		l = append(l, time.Duration(i)*time.Millisecond)
+	}
+	tests := map[float64]time.Duration{
+		50:  50 * time.Millisecond,
+		99:  99 * time.Millisecond,
+		100: 100 * time.Millisecond,
+		0:   time.Millisecond,
+	}
+	for p, expected := range tests {
+		if d := l.Percentile(p); d != expected {
+			t.Errorf("p%v: expected %v, received %v", p, expected, d)
+		}
+	}
+	if s := (Latencies{}).Stats(); s.Calls != 0 || s.Max != 0 {
+		t.Errorf("expected empty stats, received %+v", s)
+	}
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package scale measures the bridge at scale: the latency of the gRPC calls, the memory footprint
+// and the time until the objects are realized
+package scale
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+)
+
+// Populate creates the vrf, then the logical bridge and the svi of every subnet with its ports,
+// and records the latency of every call by method
+func (e *Env) Populate(ctx context.Context, subnets int, ports int, calls map[string]Latencies) error {
+	timed := func(method string, call func() error) error {
+		start := time.Now()
+		err := call()
+		calls[method] = append(calls[method], time.Since(start))
+		return err
+	}
+	vrfs := pb.NewVrfServiceClient(e.Conn)
+	lbs := pb.NewLogicalBridgeServiceClient(e.Conn)
+	svis := pb.NewSviServiceClient(e.Conn)
+	bps := pb.NewBridgePortServiceClient(e.Conn)
+
+	if err := timed("CreateVrf", func() error {
+		_, err := vrfs.CreateVrf(ctx, &pb.CreateVrfRequest{VrfId: VrfID, Vrf: Vrf()})
+		return err
+	}); err != nil {
+		return err
+	}
+	for i := 0; i < subnets; i++ {
+		if err := timed("CreateLogicalBridge", func() error {
+			_, err := lbs.CreateLogicalBridge(ctx, &pb.CreateLogicalBridgeRequest{LogicalBridgeId: LogicalBridgeID(i), LogicalBridge: LogicalBridge(i)})
+			return err
+		}); err != nil {
+			return err
+		}
+		if err := timed("CreateSvi", func() error {
+			_, err := svis.CreateSvi(ctx, &pb.CreateSviRequest{SviId: SviID(i), Svi: Svi(i)})
+			return err
+		}); err != nil {
+			return err
+		}
+		for j := 0; j < ports; j++ {
+			if err := timed("CreateBridgePort", func() error {
+				_, err := bps.CreateBridgePort(ctx, &pb.CreateBridgePortRequest{BridgePortId: BridgePortID(i, j), BridgePort: BridgePort(i, j)})
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+//go:build scale
+
+// Package scale measures the bridge at scale: the latency of the gRPC calls, the memory footprint
+// and the time until the objects are realized
+package scale
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// envInt reads a positive number from the environment
+func envInt(t *testing.T, name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		t.Fatalf("%s must be a positive number, not %q", name, value)
+	}
+	return n
+}
+
+// Test_Scale creates SCALE_SUBNETS subnets with SCALE_PORTS ports each and reports the latencies of the calls,
+// the heap and the convergence time, written as json to SCALE_REPORT when set
+func Test_Scale(t *testing.T) {
+	subnets := envInt(t, "SCALE_SUBNETS", 1000)
+	ports := envInt(t, "SCALE_PORTS", 4)
+	if subnets > MaxTenants {
+		t.Fatalf("SCALE_SUBNETS cannot exceed %d", MaxTenants)
+	}
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	ctx := context.Background()
+	env, err := NewEnv(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+
+	heapBefore := HeapInUse()
+	calls := map[string]Latencies{}
+	start := time.Now()
+	if err := env.Populate(ctx, subnets, ports, calls); err != nil {
+		t.Fatal(err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	if _, err := WaitConverged(waitCtx, 100*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	report := &Report{
+		Subnets:       subnets,
+		Ports:         subnets * ports,
+		Calls:         map[string]CallStats{},
+		ConvergenceMs: time.Since(start).Milliseconds(),
+	}
+	if heap := HeapInUse(); heap > heapBefore {
+		report.HeapBytes = heap - heapBefore
+	}
+	methods := []string{}
+	for method, l := range calls {
+		report.Calls[method] = l.Stats()
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		t.Logf("%-20s %+v", method, report.Calls[method])
+	}
+	t.Logf("heap %d bytes, converged in %d ms", report.HeapBytes, report.ConvergenceMs)
+	if file := os.Getenv("SCALE_REPORT"); file != "" {
+		if err := report.Write(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
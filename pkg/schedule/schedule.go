@@ -0,0 +1,286 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package schedule applies the bundles submitted for later, and reverts them unless they are confirmed in time
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apply"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// pollInterval is the period at which the scheduler looks for the changes to apply or to revert
+var pollInterval = time.Second
+
+// transactionTimeout bounds the application of a scheduled change and its revert
+const transactionTimeout = 60 * time.Second
+
+// errNotDue tells that another run of the scheduler has taken the change
+var errNotDue = errors.New("the scheduled change is not due")
+
+// Submit checks the bundle of the change and stores it, the scheduler applies it at its time, at once
+// when no time is given
+func Submit(c *infradb.ScheduledChange) (*infradb.ScheduledChange, error) {
+	if _, err := apply.Parse(c.Bundle); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid bundle: %v", err)
+	}
+	if c.RevertAfter < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "the revert window %v is negative", c.RevertAfter)
+	}
+	if c.ExecuteAt.IsZero() {
+		c.ExecuteAt = time.Now()
+	}
+	c.State = infradb.SchedulePending
+	if err := infradb.CreateScheduledChange(c); err != nil {
+		return nil, err
+	}
+	log.Printf("Submit(): %s is scheduled at %v, reverted after %v unless confirmed\n", c.Name, c.ExecuteAt, c.RevertAfter)
+	return c, nil
+}
+
+// Track stores a change applied right now, which the scheduler reverts with the snapshot unless it is
+// confirmed within the timeout
+func Track(name string, snapshot *apply.Snapshot, timeout time.Duration) (*infradb.ScheduledChange, error) {
+	if timeout <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "the confirm timeout %v is not positive", timeout)
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	c := &infradb.ScheduledChange{
+		Name:        name,
+		ExecuteAt:   now,
+		RevertAfter: timeout,
+		State:       infradb.ScheduleAwaitingConfirmation,
+		AppliedAt:   now,
+		RevertAt:    now.Add(timeout),
+		Snapshot:    data,
+	}
+	if err := infradb.CreateScheduledChange(c); err != nil {
+		return nil, err
+	}
+	log.Printf("Track(): %s is reverted at %v unless confirmed\n", c.Name, c.RevertAt)
+	return c, nil
+}
+
+// Untrack forgets a tracked change whose update has failed, there is nothing to revert
+func Untrack(name string) error {
+	return infradb.DeleteScheduledChange(name, func(c *infradb.ScheduledChange) error {
+		if c.State != infradb.ScheduleAwaitingConfirmation {
+			return apierrors.FailedPrecondition(apierrors.ReasonFailedPrecondition, c.Name,
+				"%s is %s, it is not awaiting a confirmation", c.Name, c.State)
+		}
+		return nil
+	})
+}
+
+// Confirm keeps the applied change for good
+func Confirm(name string) (*infradb.ScheduledChange, error) {
+	return infradb.UpdateScheduledChange(name, func(c *infradb.ScheduledChange) error {
+		if c.State != infradb.ScheduleAwaitingConfirmation {
+			return apierrors.FailedPrecondition(apierrors.ReasonFailedPrecondition, c.Name,
+				"%s is %s, it is not awaiting a confirmation", c.Name, c.State)
+		}
+		c.State = infradb.ScheduleDone
+		c.Snapshot = nil
+		c.Error = ""
+		return nil
+	})
+}
+
+// Cancel removes a change which is pending or over, an applied change has to be confirmed or reverted first
+func Cancel(name string) error {
+	return infradb.DeleteScheduledChange(name, func(c *infradb.ScheduledChange) error {
+		if c.State == infradb.ScheduleRunning || c.State == infradb.ScheduleAwaitingConfirmation {
+			return apierrors.FailedPrecondition(apierrors.ReasonFailedPrecondition, c.Name,
+				"%s is %s, it cannot be canceled", c.Name, c.State)
+		}
+		return nil
+	})
+}
+
+// Run applies and reverts the scheduled changes through the gRPC API of the bridge until the context is done
+func Run(ctx context.Context, conn grpc.ClientConnInterface) {
+	recoverInterrupted()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+		if err := RunDue(ctx, conn, time.Now()); err != nil {
+			log.Printf("schedule: %v\n", err)
+		}
+	}
+}
+
+// recoverInterrupted fails the changes which were being applied or reverted when the bridge stopped,
+// a change being reverted is left awaiting its confirmation so that the revert is retried
+func recoverInterrupted() {
+	changes, err := infradb.GetAllScheduledChanges()
+	if err != nil {
+		log.Printf("schedule: %v\n", err)
+		return
+	}
+	for _, c := range changes {
+		if c.State != infradb.ScheduleRunning {
+			continue
+		}
+		_, err := infradb.UpdateScheduledChange(c.Name, func(c *infradb.ScheduledChange) error {
+			if c.Snapshot != nil {
+				c.State = infradb.ScheduleAwaitingConfirmation
+			} else {
+				c.State = infradb.ScheduleFailed
+				c.Error = "interrupted by a restart of the bridge"
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("schedule: failed to recover %s: %v\n", c.Name, err)
+		}
+	}
+}
+
+// RunDue applies the pending changes whose time has come and reverts the applied changes
+// whose revert window is over
+func RunDue(ctx context.Context, conn grpc.ClientConnInterface, now time.Time) error {
+	changes, err := infradb.GetAllScheduledChanges()
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		switch {
+		case c.State == infradb.SchedulePending && !c.ExecuteAt.After(now):
+			execute(ctx, conn, c.Name, now)
+		case c.State == infradb.ScheduleAwaitingConfirmation && !c.RevertAt.After(now):
+			revert(ctx, conn, c.Name)
+		}
+	}
+	return nil
+}
+
+// claim marks the change as running when it is in the expected state, so that it is not confirmed,
+// canceled or run again meanwhile
+func claim(name string, expected infradb.ScheduleState) (*infradb.ScheduledChange, error) {
+	return infradb.UpdateScheduledChange(name, func(c *infradb.ScheduledChange) error {
+		if c.State != expected {
+			return errNotDue
+		}
+		c.State = infradb.ScheduleRunning
+		return nil
+	})
+}
+
+// finish stores the outcome of the run of the change
+func finish(name string, update func(c *infradb.ScheduledChange)) {
+	_, err := infradb.UpdateScheduledChange(name, func(c *infradb.ScheduledChange) error {
+		update(c)
+		return nil
+	})
+	if err != nil {
+		log.Printf("schedule: failed to store the outcome of %s: %v\n", name, err)
+	}
+}
+
+// execute applies the bundle of the change as a transaction, and keeps what it takes to revert it
+// when it has a revert window
+func execute(ctx context.Context, conn grpc.ClientConnInterface, name string, now time.Time) {
+	c, err := claim(name, infradb.SchedulePending)
+	if err != nil {
+		return
+	}
+	log.Printf("schedule: applying %s scheduled at %v\n", c.Name, c.ExecuteAt)
+	snapshot, err := applyBundle(ctx, conn, c)
+	if err != nil {
+		log.Printf("schedule: failed to apply %s: %v\n", c.Name, err)
+		finish(name, func(c *infradb.ScheduledChange) {
+			c.State = infradb.ScheduleFailed
+			c.Error = err.Error()
+		})
+		return
+	}
+	finish(name, func(c *infradb.ScheduledChange) {
+		c.AppliedAt = now
+		if c.RevertAfter == 0 {
+			c.State = infradb.ScheduleDone
+			return
+		}
+		c.State = infradb.ScheduleAwaitingConfirmation
+		c.RevertAt = now.Add(c.RevertAfter)
+		c.Snapshot = snapshot
+	})
+}
+
+// applyBundle runs the plan of the bundle and returns the json snapshot reverting it
+func applyBundle(ctx context.Context, conn grpc.ClientConnInterface, c *infradb.ScheduledChange) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, transactionTimeout)
+	defer cancel()
+	bundle, err := apply.Parse(c.Bundle)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := apply.NewPlan(ctx, conn, bundle, c.Prune)
+	if err != nil {
+		return nil, err
+	}
+	if err := plan.ApplyAtomic(ctx, false); err != nil {
+		return nil, err
+	}
+	snapshot, err := plan.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(snapshot)
+}
+
+// revert undoes the applied change which has not been confirmed, the undos left by a failure
+// are kept and retried at the next run
+func revert(ctx context.Context, conn grpc.ClientConnInterface, name string) {
+	c, err := claim(name, infradb.ScheduleAwaitingConfirmation)
+	if err != nil {
+		return
+	}
+	log.Printf("schedule: reverting %s which has not been confirmed by %v\n", c.Name, c.RevertAt)
+	snapshot := &apply.Snapshot{}
+	if err := json.Unmarshal(c.Snapshot, snapshot); err != nil {
+		log.Printf("schedule: invalid snapshot of %s: %v\n", c.Name, err)
+		finish(name, func(c *infradb.ScheduledChange) {
+			c.State = infradb.ScheduleFailed
+			c.Error = "invalid snapshot: " + err.Error()
+		})
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, transactionTimeout)
+	defer cancel()
+	err = snapshot.Revert(ctx, conn)
+	left, merr := json.Marshal(snapshot)
+	if merr != nil {
+		left = c.Snapshot
+	}
+	finish(name, func(c *infradb.ScheduledChange) {
+		if err != nil {
+			log.Printf("schedule: failed to revert %s: %v\n", c.Name, err)
+			c.State = infradb.ScheduleAwaitingConfirmation
+			c.Snapshot = left
+			c.Error = err.Error()
+			return
+		}
+		c.State = infradb.ScheduleReverted
+		c.Snapshot = nil
+		c.Error = ""
+	})
+}
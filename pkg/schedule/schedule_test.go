@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package schedule applies the bundles submitted for later, and reverts them unless they are confirmed in time
+package schedule
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apply"
+	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/port"
+	"github.com/opiproject/opi-evpn-bridge/pkg/svi"
+	"github.com/opiproject/opi-evpn-bridge/pkg/vrf"
+)
+
+const bridgeName = "//network.opiproject.org/bridges/blue-web"
+
+// blueWebBridge returns the manifest of the logical bridge with the vni
+func blueWebBridge(vni string) []byte {
+	return []byte(`
+apiVersion: evpn.opiproject.org/v1alpha1
+kind: LogicalBridge
+metadata:
+  name: blue-web
+spec:
+  vlanId: 10
+  vni: ` + vni + "\n")
+}
+
+// newTestConn serves the bridge gRPC API on top of a gomap db holding the logical bridge with the vni 10
+func newTestConn(t *testing.T) *grpc.ClientConn {
+	for _, eventType := range []string{"vrf", "logical-bridge", "svi", "bridge-port"} {
+		eventbus.EBus.StartSubscriber("dummy", eventType, 1, nil)
+	}
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	pb.RegisterVrfServiceServer(s, vrf.NewServer())
+	pb.RegisterLogicalBridgeServiceServer(s, bridge.NewServer())
+	pb.RegisterSviServiceServer(s, svi.NewServer())
+	pb.RegisterBridgePortServiceServer(s, port.NewServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	b, err := apply.Parse(blueWebBridge("10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := apply.NewPlan(context.Background(), conn, b, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// bridgeVni returns the vni of the logical bridge
+func bridgeVni(t *testing.T, conn grpc.ClientConnInterface) uint32 {
+	lb, err := pb.NewLogicalBridgeServiceClient(conn).GetLogicalBridge(context.Background(), &pb.GetLogicalBridgeRequest{Name: bridgeName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lb.GetSpec().GetVni()
+}
+
+func Test_Schedule(t *testing.T) {
+	now := time.Now()
+	tests := map[string]struct {
+		confirm bool
+		state   infradb.ScheduleState
+		vni     uint32
+	}{
+		"reverted unless confirmed": {
+			state: infradb.ScheduleReverted,
+			vni:   10,
+		},
+		"confirmed": {
+			confirm: true,
+			state:   infradb.ScheduleDone,
+			vni:     11,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			conn := newTestConn(t)
+			ctx := context.Background()
+			_, err := Submit(&infradb.ScheduledChange{
+				Name:        "vni-change",
+				Bundle:      blueWebBridge("11"),
+				ExecuteAt:   now.Add(time.Hour),
+				RevertAfter: 30 * time.Minute,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// nothing runs before the time of the change
+			if err := RunDue(ctx, conn, now); err != nil {
+				t.Fatal(err)
+			}
+			if vni := bridgeVni(t, conn); vni != 10 {
+				t.Fatalf("expected the change to wait for its time, received the vni %d", vni)
+			}
+
+			if err := RunDue(ctx, conn, now.Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			c, err := infradb.GetScheduledChange("vni-change")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.State != infradb.ScheduleAwaitingConfirmation || !c.RevertAt.Equal(now.Add(90*time.Minute)) {
+				t.Fatalf("expected the change to await its confirmation, received %+v", c)
+			}
+			if vni := bridgeVni(t, conn); vni != 11 {
+				t.Fatalf("expected the change to be applied, received the vni %d", vni)
+			}
+			if err := Cancel("vni-change"); status.Code(err) != codes.FailedPrecondition {
+				t.Errorf("expected the applied change not to be canceled, received %v", err)
+			}
+
+			if tt.confirm {
+				if _, err := Confirm("vni-change"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := RunDue(ctx, conn, now.Add(2*time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			if c, err = infradb.GetScheduledChange("vni-change"); err != nil || c.State != tt.state {
+				t.Errorf("expected the state %v, received %+v %v", tt.state, c, err)
+			}
+			if vni := bridgeVni(t, conn); vni != tt.vni {
+				t.Errorf("expected the vni %d, received %d", tt.vni, vni)
+			}
+			if _, err := Confirm("vni-change"); status.Code(err) != codes.FailedPrecondition {
+				t.Errorf("expected the change over not to be confirmed, received %v", err)
+			}
+			if err := Cancel("vni-change"); err != nil {
+				t.Errorf("expected the change over to be removed, received %v", err)
+			}
+		})
+	}
+}
+
+func Test_ScheduleFailed(t *testing.T) {
+	conn := newTestConn(t)
+	// the svi refers to a vrf which does not exist
+	_, err := Submit(&infradb.ScheduledChange{
+		Name: "new-svi",
+		Bundle: []byte(`
+apiVersion: evpn.opiproject.org/v1alpha1
+kind: Svi
+metadata:
+  name: blue-web
+spec:
+  vrf: blue
+  logicalBridge: blue-web
+  macAddress: "aa:bb:cc:00:00:01"
+  gwIpPrefixes:
+  - 10.10.10.1/24
+`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RunDue(context.Background(), conn, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := infradb.GetScheduledChange("new-svi"); err != nil || c.State != infradb.ScheduleFailed || c.Error == "" {
+		t.Errorf("expected the change to fail, received %+v %v", c, err)
+	}
+
+	if _, err := Submit(&infradb.ScheduledChange{Name: "invalid", Bundle: []byte("kind: Subnet")}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected the invalid bundle to be rejected, received %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package sflow is an sFlow version 5 agent sending the packets sampled by the kernel to the collectors of the ports
+package sflow
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// the attributes of include/uapi/linux/psample.h
+const (
+	psampleAttrIifindex    = 0
+	psampleAttrOifindex    = 1
+	psampleAttrOrigsize    = 2
+	psampleAttrSampleGroup = 3
+	psampleAttrGroupSeq    = 4
+	psampleAttrSampleRate  = 5
+	psampleAttrData        = 6
+)
+
+const (
+	psampleFamily     = "psample"
+	psampleMcastGroup = "packets"
+	// psampleBuffer absorbs the bursts of samples between two reads
+	psampleBuffer = 4 << 20
+)
+
+// psampleSource receives the packets that the tc sample actions of the group send to the psample multicast group
+type psampleSource struct {
+	sock   *nl.NetlinkSocket
+	family uint16
+	group  uint32
+}
+
+// ListenPsample returns the source of the packets sampled by the tc sample actions of the group
+func ListenPsample(group uint32) (Source, error) {
+	family, err := netlink.GenlFamilyGet(psampleFamily)
+	if err != nil {
+		return nil, fmt.Errorf("psample family: %w", err)
+	}
+	var mcast uint32
+	for _, g := range family.Groups {
+		if g.Name == psampleMcastGroup {
+			mcast = g.ID
+		}
+	}
+	if mcast == 0 {
+		return nil, fmt.Errorf("psample has no %s multicast group", psampleMcastGroup)
+	}
+	sock, err := nl.Subscribe(unix.NETLINK_GENERIC)
+	if err != nil {
+		return nil, err
+	}
+	// the id of a generic netlink group may not fit the bitmask of the bind
+	if err := unix.SetsockoptInt(sock.GetFd(), unix.SOL_NETLINK, unix.NETLINK_ADD_MEMBERSHIP, int(mcast)); err != nil {
+		sock.Close()
+		return nil, fmt.Errorf("psample multicast group: %w", err)
+	}
+	_ = sock.SetReceiveBufferSize(psampleBuffer, false)
+	// the reads return regularly so that a closed source is noticed
+	if err := sock.SetReceiveTimeout(&unix.Timeval{Sec: 1}); err != nil {
+		sock.Close()
+		return nil, err
+	}
+	return &psampleSource{sock: sock, family: family.ID, group: group}, nil
+}
+
+// parseSample parses the attributes of a psample message, the samples of the other groups are skipped
+func parseSample(data []byte, group uint32) (*Sample, bool, error) {
+	attrs, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return nil, false, err
+	}
+	s := &Sample{}
+	var sampleGroup uint32
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case psampleAttrIifindex:
+			s.InIfindex = uint32(nl.NativeEndian().Uint16(attr.Value))
+		case psampleAttrOifindex:
+			s.OutIfindex = uint32(nl.NativeEndian().Uint16(attr.Value))
+		case psampleAttrOrigsize:
+			s.OrigSize = nl.NativeEndian().Uint32(attr.Value)
+		case psampleAttrSampleGroup:
+			sampleGroup = nl.NativeEndian().Uint32(attr.Value)
+		case psampleAttrGroupSeq:
+			s.Seq = nl.NativeEndian().Uint32(attr.Value)
+		case psampleAttrSampleRate:
+			s.Rate = nl.NativeEndian().Uint32(attr.Value)
+		case psampleAttrData:
+			s.Data = attr.Value
+		}
+	}
+	return s, sampleGroup == group, nil
+}
+
+// Receive returns the samples of the group, none when the read times out
+func (p *psampleSource) Receive() ([]*Sample, error) {
+	msgs, _, err := p.sock.Receive()
+	if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EWOULDBLOCK) || errors.Is(err, unix.EINTR) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var samples []*Sample
+	for _, msg := range msgs {
+		if msg.Header.Type != p.family || len(msg.Data) < nl.SizeofGenlmsg {
+			continue
+		}
+		s, ok, err := parseSample(msg.Data[nl.SizeofGenlmsg:], p.group)
+		if err != nil {
+			return samples, err
+		}
+		if ok {
+			samples = append(samples, s)
+		}
+	}
+	return samples, nil
+}
+
+// Close closes the netlink socket
+func (p *psampleSource) Close() error {
+	p.sock.Close()
+	return nil
+}
@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package sflow is an sFlow version 5 agent sending the packets sampled by the kernel to the collectors of the ports
+package sflow
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	version = 5
+	// flowSampleFormat is the enterprise 0 flow_sample
+	flowSampleFormat = 1
+	// rawHeaderFormat is the enterprise 0 sampled_header record
+	rawHeaderFormat = 1
+	// headerProtocolEthernet is the ISO88023 header protocol
+	headerProtocolEthernet = 1
+	// DefaultHeaderLength is how much of the sampled packets is sent by default
+	DefaultHeaderLength = 128
+)
+
+// Sample is a packet sampled by the kernel
+type Sample struct {
+	InIfindex  uint32
+	OutIfindex uint32
+	// OrigSize is the size of the packet, Data holds its first bytes
+	OrigSize uint32
+	Seq      uint32
+	Rate     uint32
+	Data     []byte
+}
+
+// Port is the sampling of a port
+type Port struct {
+	Ifindex      uint32
+	Rate         uint32
+	HeaderLength uint32
+	// Collector is the host:port of the collector, reached over udp
+	Collector string
+}
+
+// portState holds the counters of the flow samples of a port
+type portState struct {
+	Port
+	seq  uint32
+	pool uint32
+}
+
+// collector is the connection to a collector with the sequence of its datagrams
+type collector struct {
+	conn  net.Conn
+	seq   uint32
+	ports int
+}
+
+// Agent sends a datagram per sampled packet to the collector of the port which received it
+type Agent struct {
+	mu         sync.Mutex
+	address    net.IP
+	dialer     *net.Dialer
+	start      time.Time
+	ports      map[uint32]*portState
+	collectors map[string]*collector
+	// lastSeq is the sequence of the last sample of the group, the gaps are the samples lost by the kernel
+	lastSeq uint32
+	drops   uint32
+}
+
+// NewAgent returns the agent identified by the address in the datagrams, the collectors are reached with the dialer
+func NewAgent(address net.IP, dialer *net.Dialer) *Agent {
+	if address == nil {
+		address = net.IPv4zero
+	}
+	return &Agent{
+		address:    address,
+		dialer:     dialer,
+		start:      time.Now(),
+		ports:      make(map[uint32]*portState),
+		collectors: make(map[string]*collector),
+	}
+}
+
+// release drops the use of the collector by a port, the caller must hold the lock
+func (a *Agent) release(address string) {
+	c, ok := a.collectors[address]
+	if !ok {
+		return
+	}
+	if c.ports--; c.ports == 0 {
+		_ = c.conn.Close()
+		delete(a.collectors, address)
+	}
+}
+
+// SetPort starts or changes the sampling of the port
+func (a *Agent) SetPort(p Port) error {
+	if p.HeaderLength == 0 {
+		p.HeaderLength = DefaultHeaderLength
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, ok := a.ports[p.Ifindex]
+	if ok && state.Collector == p.Collector {
+		state.Port = p
+		return nil
+	}
+	c, found := a.collectors[p.Collector]
+	if !found {
+		conn, err := a.dialer.Dial("udp", p.Collector)
+		if err != nil {
+			return fmt.Errorf("sflow collector %s: %w", p.Collector, err)
+		}
+		c = &collector{conn: conn}
+		a.collectors[p.Collector] = c
+	}
+	c.ports++
+	if ok {
+		a.release(state.Collector)
+		state.Port = p
+		return nil
+	}
+	a.ports[p.Ifindex] = &portState{Port: p}
+	return nil
+}
+
+// RemovePort stops the sampling of the port
+func (a *Agent) RemovePort(ifindex uint32) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if state, ok := a.ports[ifindex]; ok {
+		a.release(state.Collector)
+		delete(a.ports, ifindex)
+	}
+}
+
+// appendFlowSample appends the flow_sample of the packet with its header
+func appendFlowSample(b []byte, state *portState, s *Sample, drops uint32) []byte {
+	header := s.Data
+	if uint32(len(header)) > state.HeaderLength {
+		header = header[:state.HeaderLength]
+	}
+	padded := (len(header) + 3) &^ 3
+	record := 4 + 4 + 4 + 4 + padded
+	sample := 7*4 + 4 + 4 + 4 + record
+
+	b = binary.BigEndian.AppendUint32(b, flowSampleFormat)
+	b = binary.BigEndian.AppendUint32(b, uint32(sample))
+	b = binary.BigEndian.AppendUint32(b, state.seq)
+	// the source is the ifIndex of the port
+	b = binary.BigEndian.AppendUint32(b, s.InIfindex)
+	b = binary.BigEndian.AppendUint32(b, s.Rate)
+	b = binary.BigEndian.AppendUint32(b, state.pool)
+	b = binary.BigEndian.AppendUint32(b, drops)
+	b = binary.BigEndian.AppendUint32(b, s.InIfindex)
+	b = binary.BigEndian.AppendUint32(b, s.OutIfindex)
+	b = binary.BigEndian.AppendUint32(b, 1)
+
+	b = binary.BigEndian.AppendUint32(b, rawHeaderFormat)
+	b = binary.BigEndian.AppendUint32(b, uint32(record))
+	b = binary.BigEndian.AppendUint32(b, headerProtocolEthernet)
+	b = binary.BigEndian.AppendUint32(b, s.OrigSize)
+	// nothing is stripped from the frames
+	b = binary.BigEndian.AppendUint32(b, 0)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(header)))
+	b = append(b, header...)
+	return append(b, make([]byte, padded-len(header))...)
+}
+
+// datagram encodes the datagram carrying the flow sample
+func (a *Agent) datagram(c *collector, state *portState, s *Sample, now time.Time) []byte {
+	b := make([]byte, 0, 256)
+	b = binary.BigEndian.AppendUint32(b, version)
+	if ip4 := a.address.To4(); ip4 != nil {
+		b = binary.BigEndian.AppendUint32(b, 1)
+		b = append(b, ip4...)
+	} else {
+		b = binary.BigEndian.AppendUint32(b, 2)
+		b = append(b, a.address.To16()...)
+	}
+	// sub agent id
+	b = binary.BigEndian.AppendUint32(b, 0)
+	b = binary.BigEndian.AppendUint32(b, c.seq)
+	b = binary.BigEndian.AppendUint32(b, uint32(now.Sub(a.start).Milliseconds()))
+	b = binary.BigEndian.AppendUint32(b, 1)
+	return appendFlowSample(b, state, s, a.drops)
+}
+
+// Handle sends the sampled packet to the collector of the port which received it
+func (a *Agent) Handle(s *Sample) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.lastSeq != 0 && s.Seq > a.lastSeq+1 {
+		a.drops += s.Seq - a.lastSeq - 1
+	}
+	a.lastSeq = s.Seq
+	state, ok := a.ports[s.InIfindex]
+	if !ok {
+		return
+	}
+	c := a.collectors[state.Collector]
+	state.seq++
+	state.pool += s.Rate
+	c.seq++
+	if _, err := c.conn.Write(a.datagram(c, state, s, time.Now())); err != nil {
+		log.Printf("sflow: failed to send the sample of port %d to %s: %v\n", s.InIfindex, state.Collector, err)
+	}
+}
+
+// Source returns the packets sampled by the kernel
+type Source interface {
+	Receive() ([]*Sample, error)
+	Close() error
+}
+
+// Run hands the samples of the source to the agent until the context is done
+func (a *Agent) Run(ctx context.Context, source Source) {
+	go func() {
+		<-ctx.Done()
+		_ = source.Close()
+	}()
+	for {
+		samples, err := source.Receive()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("sflow: failed to receive the samples: %v\n", err)
+			time.Sleep(time.Second)
+			continue
+		}
+		for _, s := range samples {
+			a.Handle(s)
+		}
+	}
+}
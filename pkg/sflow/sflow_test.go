@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package sflow is an sFlow version 5 agent sending the packets sampled by the kernel to the collectors of the ports
+package sflow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/vishvananda/netlink/nl"
+)
+
+// flowSample is what a collector reads from a datagram
+type flowSample struct {
+	agent    net.IP
+	sequence uint32
+	source   uint32
+	rate     uint32
+	pool     uint32
+	drops    uint32
+	input    uint32
+	frame    uint32
+	header   []byte
+}
+
+// decode reads the datagram carrying a single flow sample, checking the lengths
+func decode(t *testing.T, b []byte) flowSample {
+	t.Helper()
+	u32 := func(off int) uint32 { return binary.BigEndian.Uint32(b[off:]) }
+	if u32(0) != version || u32(4) != 1 {
+		t.Fatalf("invalid datagram header % x", b[:8])
+	}
+	d := flowSample{agent: net.IP(b[8:12]), sequence: u32(16)}
+	if u32(24) != 1 || u32(28) != flowSampleFormat {
+		t.Fatalf("expected a single flow sample, received % x", b[24:32])
+	}
+	if int(u32(32)) != len(b)-36 {
+		t.Fatalf("flow sample length %d does not match the %d bytes of the datagram", u32(32), len(b)-36)
+	}
+	s := 36
+	d.source, d.rate, d.pool, d.drops, d.input = u32(s+4), u32(s+8), u32(s+12), u32(s+16), u32(s+20)
+	if u32(s+28) != 1 || u32(s+32) != rawHeaderFormat {
+		t.Fatalf("expected a single raw header record, received % x", b[s+28:s+36])
+	}
+	r := s + 40
+	if int(u32(r-4)) != len(b)-r || u32(r) != headerProtocolEthernet {
+		t.Fatalf("invalid raw header record % x", b[r-4:r+4])
+	}
+	d.frame = u32(r + 4)
+	length := int(u32(r + 12))
+	if (len(b)-r-16)%4 != 0 || length > len(b)-r-16 {
+		t.Fatalf("header of %d bytes is not padded in the %d bytes of the record", length, len(b)-r-16)
+	}
+	d.header = b[r+16 : r+16+length]
+	return d
+}
+
+func Test_Handle(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	agent := NewAgent(net.IPv4(192, 0, 2, 1), &net.Dialer{})
+	if err := agent.SetPort(Port{Ifindex: 7, Rate: 100, HeaderLength: 64, Collector: pc.LocalAddr().String()}); err != nil {
+		t.Fatal(err)
+	}
+	frame := bytes.Repeat([]byte{0xab}, 101)
+	agent.Handle(&Sample{InIfindex: 7, OrigSize: 1514, Seq: 1, Rate: 100, Data: frame})
+	// the samples of the other ports are not sent
+	agent.Handle(&Sample{InIfindex: 8, OrigSize: 60, Seq: 2, Rate: 100, Data: frame[:60]})
+	// the kernel lost the samples 3 and 4
+	agent.Handle(&Sample{InIfindex: 7, OrigSize: 60, Seq: 5, Rate: 100, Data: frame[:61]})
+
+	var got []flowSample
+	buf := make([]byte, 2048)
+	for i := 0; i < 2; i++ {
+		_ = pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, decode(t, append([]byte(nil), buf[:n]...)))
+	}
+
+	if !got[0].agent.Equal(net.IPv4(192, 0, 2, 1)) || got[0].source != 7 || got[0].input != 7 || got[0].rate != 100 {
+		t.Errorf("unexpected flow sample %+v", got[0])
+	}
+	if got[0].frame != 1514 || !bytes.Equal(got[0].header, frame[:64]) {
+		t.Errorf("expected the first 64 bytes of a 1514 bytes frame, received %d bytes of %d", len(got[0].header), got[0].frame)
+	}
+	if got[1].sequence != 2 || got[1].pool != 200 || got[1].drops != 2 || len(got[1].header) != 61 {
+		t.Errorf("unexpected second flow sample %+v", got[1])
+	}
+
+	agent.RemovePort(7)
+	if len(agent.collectors) != 0 {
+		t.Errorf("expected the collector to be closed with its last port")
+	}
+}
+
+func Test_ParseSample(t *testing.T) {
+	attr := func(typ int, value []byte) []byte {
+		return nl.NewRtAttr(typ, value).Serialize()
+	}
+	u16 := func(v uint16) []byte { return nl.Uint16Attr(v) }
+	u32 := func(v uint32) []byte { return nl.Uint32Attr(v) }
+
+	var data []byte
+	data = append(data, attr(psampleAttrIifindex, u16(7))...)
+	data = append(data, attr(psampleAttrOrigsize, u32(1514))...)
+	data = append(data, attr(psampleAttrSampleGroup, u32(42))...)
+	data = append(data, attr(psampleAttrGroupSeq, u32(9))...)
+	data = append(data, attr(psampleAttrSampleRate, u32(1000))...)
+	data = append(data, attr(psampleAttrData, []byte{1, 2, 3})...)
+
+	s, ok, err := parseSample(data, 42)
+	if err != nil || !ok {
+		t.Fatalf("expected a sample of the group, received %v %v", ok, err)
+	}
+	if s.InIfindex != 7 || s.OrigSize != 1514 || s.Seq != 9 || s.Rate != 1000 || !bytes.Equal(s.Data, []byte{1, 2, 3}) {
+		t.Errorf("unexpected sample %+v", s)
+	}
+	if _, ok, _ := parseSample(data, 43); ok {
+		t.Errorf("expected the sample of another group to be skipped")
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/pbconv"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
 	"github.com/opiproject/opi-evpn-bridge/pkg/vrf"
 )
@@ -43,7 +44,7 @@ func (s *Server) createSvi(svi *pb.Svi) (*pb.Svi, error) {
 	}
 
 	// translation of pb to domain object
-	domainSvi, err := infradb.NewSvi(svi)
+	domainSvi, err := pbconv.SviFromPb(svi)
 	if err != nil {
 		return nil, err
 	}
@@ -51,12 +52,12 @@ func (s *Server) createSvi(svi *pb.Svi) (*pb.Svi, error) {
 	if err := infradb.CreateSvi(domainSvi); err != nil {
 		return nil, err
 	}
-	return domainSvi.ToPb(), nil
+	return pbconv.SviToPb(domainSvi), nil
 }
 
-func (s *Server) deleteSvi(name string) error {
+func (s *Server) deleteSvi(name string, opts ...infradb.WriteOption) error {
 	// Note: The status of the object will be generated in infraDB operation not here
-	if err := infradb.DeleteSvi(name); err != nil {
+	if err := infradb.DeleteSvi(name, opts...); err != nil {
 		return err
 	}
 	return nil
@@ -67,7 +68,7 @@ func (s *Server) getSvi(name string) (*pb.Svi, error) {
 	if err != nil {
 		return nil, err
 	}
-	return domainSvi.ToPb(), nil
+	return pbconv.SviToPb(domainSvi), nil
 }
 
 func (s *Server) getAllSvis() ([]*pb.Svi, error) {
@@ -78,27 +79,37 @@ func (s *Server) getAllSvis() ([]*pb.Svi, error) {
 	}
 
 	for _, domainSvi := range domainSvis {
-		svis = append(svis, domainSvi.ToPb())
+		svis = append(svis, pbconv.SviToPb(domainSvi))
 	}
 	return svis, nil
 }
 
-func (s *Server) updateSvi(svi *pb.Svi) (*pb.Svi, error) {
+func (s *Server) updateSvi(svi *pb.Svi, opts ...infradb.WriteOption) (*pb.Svi, error) {
 	// check parameters
 	if err := s.validateSviSpec(svi); err != nil {
 		return nil, err
 	}
 
 	// translation of pb to domain object
-	domainSvi, err := infradb.NewSvi(svi)
+	domainSvi, err := pbconv.SviFromPb(svi)
 	if err != nil {
 		return nil, err
 	}
 	// Note: The status of the object will be generated in infraDB operation not here
-	if err := infradb.UpdateSvi(domainSvi); err != nil {
+	if err := infradb.UpdateSvi(domainSvi, opts...); err != nil {
 		return nil, err
 	}
-	return domainSvi.ToPb(), nil
+	return pbconv.SviToPb(domainSvi), nil
+}
+
+// sameSviSpec tells whether the create request replays the creation of the existing SVI,
+// the spec of the request is compared in its stored form
+func (s *Server) sameSviSpec(svi *pb.Svi, existing *pb.Svi) bool {
+	domainSvi, err := pbconv.SviFromPb(svi)
+	if err != nil {
+		return false
+	}
+	return proto.Equal(pbconv.SviToPb(domainSvi).Spec, existing.Spec)
 }
 
 func resourceIDToFullName(resourceID string) string {
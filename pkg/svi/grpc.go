@@ -16,6 +16,7 @@ import (
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
 	"go.einride.tech/aip/resourceid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -23,12 +24,16 @@ import (
 )
 
 // CreateSvi executes the creation of the Svi
-func (s *Server) CreateSvi(_ context.Context, in *pb.CreateSviRequest) (*pb.Svi, error) {
+func (s *Server) CreateSvi(ctx context.Context, in *pb.CreateSviRequest) (*pb.Svi, error) {
 	// check input correctness
 	if err := s.validateCreateSviRequest(in); err != nil {
 		log.Printf("CreateSvi(): validation failure: %v", err)
 		return nil, err
 	}
+	if err := utils.ContextError(ctx); err != nil {
+		log.Printf("CreateSvi(): %v", err)
+		return nil, err
+	}
 	// see https://google.aip.dev/133#user-specified-ids
 	resourceID := resourceid.NewSystemGenerated()
 	if in.SviId != "" {
@@ -36,7 +41,7 @@ func (s *Server) CreateSvi(_ context.Context, in *pb.CreateSviRequest) (*pb.Svi,
 		resourceID = in.SviId
 	}
 	in.Svi.Name = resourceIDToFullName(resourceID)
-	// idempotent API when called with same key, should return same object
+	// idempotent API when called with same key and spec, should return same object
 	sviObj, err := s.getSvi(in.Svi.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
@@ -44,6 +49,15 @@ func (s *Server) CreateSvi(_ context.Context, in *pb.CreateSviRequest) (*pb.Svi,
 			return nil, err
 		}
 	} else {
+		if err := s.validateSviSpec(in.Svi); err != nil {
+			log.Printf("CreateSvi(): validation failure: %v", err)
+			return nil, err
+		}
+		if !s.sameSviSpec(in.Svi, sviObj) {
+			err := apierrors.AlreadyExists("svis", in.Svi.Name, "%s already exists with another spec", in.Svi.Name)
+			log.Printf("CreateSvi(): %v", err)
+			return nil, err
+		}
 		log.Printf("CreateSvi(): Already existing Svi with id %v", in.Svi.Name)
 		return sviObj, nil
 	}
@@ -58,12 +72,16 @@ func (s *Server) CreateSvi(_ context.Context, in *pb.CreateSviRequest) (*pb.Svi,
 }
 
 // DeleteSvi deletes a Svi
-func (s *Server) DeleteSvi(_ context.Context, in *pb.DeleteSviRequest) (*emptypb.Empty, error) {
+func (s *Server) DeleteSvi(ctx context.Context, in *pb.DeleteSviRequest) (*emptypb.Empty, error) {
 	// check input correctness
 	if err := s.validateDeleteSviRequest(in); err != nil {
 		log.Printf("DeleteSvi(): validation failure: %v", err)
 		return nil, err
 	}
+	if err := utils.ContextError(ctx); err != nil {
+		log.Printf("DeleteSvi(): %v", err)
+		return nil, err
+	}
 	// fetch object from the database
 	_, err := s.getSvi(in.Name)
 	if err != nil {
@@ -72,14 +90,14 @@ func (s *Server) DeleteSvi(_ context.Context, in *pb.DeleteSviRequest) (*emptypb
 			return nil, err
 		}
 		if !in.AllowMissing {
-			err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+			err = apierrors.NotFound("svis", in.Name)
 			log.Printf("DeleteSvi(): Svi with id %v: Not Found %v", in.Name, err)
 			return nil, err
 		}
 		return &emptypb.Empty{}, nil
 	}
 
-	if err := s.deleteSvi(in.Name); err != nil {
+	if err := s.deleteSvi(in.Name, infradb.IfMatch(utils.IfMatch(ctx))); err != nil {
 		log.Printf("DeleteSvi(): Svi with id %v, Delete Svi from DB failure: %v", in.Name, err)
 		return nil, err
 	}
@@ -88,12 +106,16 @@ func (s *Server) DeleteSvi(_ context.Context, in *pb.DeleteSviRequest) (*emptypb
 }
 
 // UpdateSvi updates a Svi
-func (s *Server) UpdateSvi(_ context.Context, in *pb.UpdateSviRequest) (*pb.Svi, error) {
+func (s *Server) UpdateSvi(ctx context.Context, in *pb.UpdateSviRequest) (*pb.Svi, error) {
 	// check input correctness
 	if err := s.validateUpdateSviRequest(in); err != nil {
 		log.Printf("UpdateSvi(): validation failure: %v", err)
 		return nil, err
 	}
+	if err := utils.ContextError(ctx); err != nil {
+		log.Printf("UpdateSvi(): %v", err)
+		return nil, err
+	}
 	// fetch object from the database
 	sviObj, err := s.getSvi(in.Svi.Name)
 	if err != nil {
@@ -102,7 +124,7 @@ func (s *Server) UpdateSvi(_ context.Context, in *pb.UpdateSviRequest) (*pb.Svi,
 			return nil, err
 		}
 		if !in.AllowMissing {
-			err = status.Errorf(codes.NotFound, "unable to find key %s", in.Svi.Name)
+			err = apierrors.NotFound("svis", in.Svi.Name)
 			log.Printf("UpdateSvi(): Svi with id %v: Not Found %v", in.Svi.Name, err)
 			return nil, err
 		}
@@ -137,7 +159,7 @@ func (s *Server) UpdateSvi(_ context.Context, in *pb.UpdateSviRequest) (*pb.Svi,
 		return sviObj, nil
 	}
 
-	response, err := s.updateSvi(updatedsviObj)
+	response, err := s.updateSvi(updatedsviObj, infradb.IfMatch(utils.IfMatch(ctx)))
 	if err != nil {
 		log.Printf("UpdateSvi(): Svi with id %v, Update Svi to DB failure: %v", in.Svi.Name, err)
 		return nil, err
@@ -160,7 +182,7 @@ func (s *Server) GetSvi(_ context.Context, in *pb.GetSviRequest) (*pb.Svi, error
 			log.Printf("Failed to interact with store: %v", err)
 			return nil, err
 		}
-		err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+		err = apierrors.NotFound("svis", in.Name)
 		log.Printf("GetSvi(): Svi with id %v: Not Found %v", in.Name, err)
 		return nil, err
 	}
@@ -176,7 +198,9 @@ func (s *Server) ListSvis(_ context.Context, in *pb.ListSvisRequest) (*pb.ListSv
 		return nil, err
 	}
 	// fetch pagination from the database, calculate size and offset
+	s.paginationLock.Lock()
 	size, offset, err := utils.ExtractPagination(in.PageSize, in.PageToken, s.Pagination)
+	s.paginationLock.Unlock()
 	if err != nil {
 		return nil, err
 	}
@@ -198,7 +222,9 @@ func (s *Server) ListSvis(_ context.Context, in *pb.ListSvisRequest) (*pb.ListSv
 	token := ""
 	if hasMoreElements {
 		token = uuid.New().String()
+		s.paginationLock.Lock()
 		s.Pagination[token] = offset + size
+		s.paginationLock.Unlock()
 	}
 	return &pb.ListSvisResponse{Svis: Blobarray, NextPageToken: token}, nil
 }
@@ -9,6 +9,7 @@ package svi
 import (
 	"context"
 	"fmt"
+	"net"
 	"reflect"
 	"testing"
 
@@ -81,6 +82,22 @@ func Test_CreateSvi(t *testing.T) {
 			exist:   true,
 			on:      nil,
 		},
+		"already exists with another spec": {
+			id: testSviID,
+			in: &pb.Svi{
+				Spec: &pb.SviSpec{
+					Vrf:           testVrfName,
+					LogicalBridge: testLogicalBridgeName,
+					MacAddress:    []byte{0xCB, 0xB8, 0x33, 0x4C, 0x88, 0x50},
+					GwIpPrefix:    testSvi.Spec.GwIpPrefix,
+				},
+			},
+			out:     nil,
+			errCode: codes.AlreadyExists,
+			errMsg:  fmt.Sprintf("%s already exists with another spec", testSviName),
+			exist:   true,
+			on:      nil,
+		},
 		"no required svi field": {
 			id:      testSviID,
 			in:      nil,
@@ -128,7 +145,7 @@ func Test_CreateSvi(t *testing.T) {
 			exist:   false,
 			on:      nil,
 		},
-		"no required gw ip field": {
+		"link-local only svi without gw ip": {
 			id: testSviID,
 			in: &pb.Svi{
 				Spec: &pb.SviSpec{
@@ -137,9 +154,38 @@ func Test_CreateSvi(t *testing.T) {
 					MacAddress:    []byte{0xCB, 0xB8, 0x33, 0x4C, 0x88, 0x4F},
 				},
 			},
+			out: &pb.Svi{
+				Name: testSviName,
+				Spec: &pb.SviSpec{
+					Vrf:           testVrfName,
+					LogicalBridge: testLogicalBridgeName,
+					MacAddress:    []byte{0xCB, 0xB8, 0x33, 0x4C, 0x88, 0x4F},
+				},
+				Status: testSviWithStatus.Status,
+			},
+			errCode: codes.OK,
+			errMsg:  "",
+			exist:   false,
+			on:      nil,
+		},
+		"link-local gw ip": {
+			id: testSviID,
+			in: &pb.Svi{
+				Spec: &pb.SviSpec{
+					Vrf:           testVrfName,
+					LogicalBridge: testLogicalBridgeName,
+					MacAddress:    []byte{0xCB, 0xB8, 0x33, 0x4C, 0x88, 0x4F},
+					GwIpPrefix: []*pc.IPPrefix{{
+						Addr: &pc.IPAddress{
+							Af:     pc.IpAf_IP_AF_INET6,
+							V4OrV6: &pc.IPAddress_V6Addr{V6Addr: net.ParseIP("fe80::1")},
+						},
+						Len: 64}},
+				},
+			},
 			out:     nil,
-			errCode: codes.Unknown,
-			errMsg:  "missing required field: svi.spec.gw_ip_prefix",
+			errCode: codes.InvalidArgument,
+			errMsg:  "Invalid gw_ip_prefix: fe80::1/64 is link-local, leave gw_ip_prefix empty for a link-local only SVI",
 			exist:   false,
 			on:      nil,
 		},
@@ -175,6 +221,38 @@ func Test_CreateSvi(t *testing.T) {
 			exist:   false,
 			on:      nil,
 		},
+		"truncated MacAddress": {
+			id: testSviID,
+			in: &pb.Svi{
+				Spec: &pb.SviSpec{
+					Vrf:           testVrfName,
+					LogicalBridge: testLogicalBridgeName,
+					MacAddress:    []byte{0xCB, 0xB8, 0x33},
+					GwIpPrefix:    []*pc.IPPrefix{{Len: 24}},
+				},
+			},
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  "Invalid format of MAC Address: a MAC address has 6 bytes, not 3",
+			exist:   false,
+			on:      nil,
+		},
+		"out of range gateway prefix": {
+			id: testSviID,
+			in: &pb.Svi{
+				Spec: &pb.SviSpec{
+					Vrf:           testVrfName,
+					LogicalBridge: testLogicalBridgeName,
+					MacAddress:    []byte{0xCB, 0xB8, 0x33, 0x4C, 0x88, 0x4F},
+					GwIpPrefix:    []*pc.IPPrefix{{Len: 33}, nil},
+				},
+			},
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  "Invalid gw_ip_prefix: the prefix length 33 has to be between 0 and 32",
+			exist:   false,
+			on:      nil,
+		},
 		"missing LogicalBridge name": {
 			id: testSviID,
 			in: &pb.Svi{
@@ -8,22 +8,21 @@ package svi
 
 import (
 	"errors"
-	"fmt"
 
 	"go.einride.tech/aip/fieldbehavior"
 	"go.einride.tech/aip/fieldmask"
 	"go.einride.tech/aip/resourceid"
 	"go.einride.tech/aip/resourcename"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 )
 
 func (s *Server) validateCreateSviRequest(in *pb.CreateSviRequest) error {
 	// check required fields
-	if err := fieldbehavior.ValidateRequiredFields(in); err != nil {
+	if err := utils.ValidateRequiredFields(in); err != nil {
 		return err
 	}
 
@@ -39,35 +38,46 @@ func (s *Server) validateCreateSviRequest(in *pb.CreateSviRequest) error {
 func (s *Server) validateSviSpec(svi *pb.Svi) error {
 	// Validate that a LogicalBridge resource name conforms to the restrictions outlined in AIP-122.
 	if err := resourcename.Validate(svi.Spec.LogicalBridge); err != nil {
-		msg := fmt.Sprintf("Logical Bridge %v has invalid name, error: %v", svi.Spec.LogicalBridge, err)
-		return status.Errorf(codes.InvalidArgument, msg)
+		return apierrors.InvalidField("svi.spec.logical_bridge", apierrors.ReasonInvalidName,
+			"Logical Bridge %v has invalid name, error: %v", svi.Spec.LogicalBridge, err)
 	}
 
 	// Validate that a Vrf resource name conforms to the restrictions outlined in AIP-122.
 	if err := resourcename.Validate(svi.Spec.Vrf); err != nil {
-		msg := fmt.Sprintf("VRF %v has invalid name, error: %v", svi.Spec.Vrf, err)
-		return status.Errorf(codes.InvalidArgument, msg)
+		return apierrors.InvalidField("svi.spec.vrf", apierrors.ReasonInvalidName,
+			"VRF %v has invalid name, error: %v", svi.Spec.Vrf, err)
 	}
 
 	// Validate that the MacAddress has the right format
 	if err := utils.ValidateMacAddress(svi.Spec.MacAddress); err != nil {
-		msg := fmt.Sprintf("Invalid format of MAC Address: %v", err)
-		return status.Errorf(codes.InvalidArgument, msg)
+		return apierrors.InvalidField("svi.spec.mac_address", apierrors.ReasonInvalidAddress,
+			"Invalid format of MAC Address: %v", err)
 	}
 
-	// Dimitris: Should I validate also the gw_ip_prefix ?
+	// An SVI without gateway prefix is link-local only, the address derived from its MAC is the gateway of
+	// the hosts, which are reached over BGP unnumbered sessions or type-5 routes rather than a shared subnet
+	for _, gw := range svi.Spec.GwIpPrefix {
+		gwIP, err := common.ConvertToDualStackIPNet(gw)
+		if err != nil {
+			return apierrors.InvalidField("svi.spec.gw_ip_prefix", apierrors.ReasonInvalidAddress,
+				"Invalid gw_ip_prefix: %v", err)
+		}
+		if gwIP.IP.IsLinkLocalUnicast() {
+			return apierrors.InvalidField("svi.spec.gw_ip_prefix", apierrors.ReasonInvalidAddress,
+				"Invalid gw_ip_prefix: %v is link-local, leave gw_ip_prefix empty for a link-local only SVI", gwIP)
+		}
+	}
 
 	// Dimitris: Do we need to change the type of RemoteAs to something else than uint32 ?
 	// because now the default value is "0" which is not good. I think "optional uint32" in protobuf is better
 	if svi.Spec.EnableBgp {
 		if err := validateASN(svi.Spec.RemoteAs); err != nil {
-			msg := fmt.Sprintf("Invalid RemoteAs: %v", err)
-			return status.Errorf(codes.InvalidArgument, msg)
+			return apierrors.InvalidField("svi.spec.remote_as", apierrors.ReasonOutOfRange, "Invalid RemoteAs: %v", err)
 		}
 	} else {
 		if svi.Spec.RemoteAs != 0 {
-			msg := "Invalid RemoteAs: RemoteAs must not be defined when EnableBgp is False"
-			return status.Errorf(codes.InvalidArgument, msg)
+			return apierrors.InvalidField("svi.spec.remote_as", apierrors.ReasonInvalidField,
+				"Invalid RemoteAs: RemoteAs must not be defined when EnableBgp is False")
 		}
 	}
 
@@ -92,7 +102,7 @@ func (s *Server) validateDeleteSviRequest(in *pb.DeleteSviRequest) error {
 
 func (s *Server) validateUpdateSviRequest(in *pb.UpdateSviRequest) error {
 	// check required fields
-	if err := fieldbehavior.ValidateRequiredFields(in); err != nil {
+	if err := utils.ValidateRequiredFields(in); err != nil {
 		return err
 	}
 	// update_mask = 2
@@ -0,0 +1,304 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package tenant scopes the objects of the bridge by parent, so that several orchestrators can share it
+package tenant
+
+import (
+	"context"
+	"errors"
+	"log"
+	"path"
+	"strings"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"go.einride.tech/aip/resourcename"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// ParentHeader is the request header holding the parent of the call: a tenant
+// (//network.opiproject.org/tenants/<id>) or a VPC of a tenant (//network.opiproject.org/vrfs/<id>)
+const ParentHeader = "parent"
+
+const (
+	tenantsCollection = "//network.opiproject.org/tenants/"
+	vrfsCollection    = "//network.opiproject.org/vrfs/"
+)
+
+// inCollection tells whether the name is the one of an object of the collection
+func inCollection(name, collection string) bool {
+	id := strings.TrimPrefix(name, collection)
+	return id != name && id != "" && !strings.Contains(id, "/")
+}
+
+// parentOf returns the parent of the call, or an empty parent for the unscoped callers which see all the objects
+func parentOf(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(ParentHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// tenantOf resolves the tenant of the parent: a tenant is its own tenant, a VPC belongs to the tenant which created it
+func tenantOf(parent string) (string, error) {
+	switch {
+	case inCollection(parent, tenantsCollection):
+		return parent, nil
+	case inCollection(parent, vrfsCollection):
+		owner, err := ownerOf(parent)
+		if err != nil {
+			return "", err
+		}
+		if owner == "" || owner == "*" {
+			return "", status.Errorf(codes.NotFound, "unable to find parent %s", parent)
+		}
+		return owner, nil
+	default:
+		return "", status.Errorf(codes.InvalidArgument, "parent %s is neither a tenant nor a VPC", parent)
+	}
+}
+
+// ownerOf returns the tenant which owns an existing object, or an empty tenant when the object does not exist
+func ownerOf(name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	version, err := infradb.GetResourceVersion(name)
+	if err != nil || version == "" {
+		return "", err
+	}
+	owner, err := infradb.GetParent(name)
+	if err != nil {
+		return "", err
+	}
+	if owner == "" {
+		// shared object, e.g. the GRD
+		return "*", nil
+	}
+	return owner, nil
+}
+
+// createdName returns the name of the object a Create call targets, or an empty name when the id is generated
+func createdName(req interface{}) string {
+	join := func(collection, id string) string {
+		if id == "" {
+			return ""
+		}
+		return resourcename.Join("//network.opiproject.org/", collection, id)
+	}
+	switch in := req.(type) {
+	case *pb.CreateVrfRequest:
+		return join("vrfs", in.VrfId)
+	case *pb.CreateLogicalBridgeRequest:
+		return join("bridges", in.LogicalBridgeId)
+	case *pb.CreateBridgePortRequest:
+		return join("ports", in.BridgePortId)
+	case *pb.CreateSviRequest:
+		return join("svis", in.SviId)
+	}
+	return ""
+}
+
+// checkReferences rejects the references to the objects of other tenants, the shared objects can be referenced by all
+func checkReferences(req interface{}, parent, tenant string) error {
+	var svi *pb.Svi
+	var bp *pb.BridgePort
+	switch in := req.(type) {
+	case *pb.CreateSviRequest:
+		svi = in.Svi
+	case *pb.UpdateSviRequest:
+		svi = in.Svi
+	case *pb.CreateBridgePortRequest:
+		bp = in.BridgePort
+	case *pb.UpdateBridgePortRequest:
+		bp = in.BridgePort
+	}
+	refs := []string{}
+	if svi != nil && svi.Spec != nil {
+		if inCollection(parent, vrfsCollection) && svi.Spec.Vrf != parent {
+			return status.Errorf(codes.InvalidArgument, "the SVI must belong to its parent VPC %s", parent)
+		}
+		refs = append(refs, svi.Spec.Vrf, svi.Spec.LogicalBridge)
+	}
+	if bp != nil && bp.Spec != nil {
+		if len(bp.Spec.LogicalBridges) == 0 {
+			return status.Errorf(codes.InvalidArgument, "a transparent trunk spans the logical bridges of all the tenants")
+		}
+		refs = append(refs, bp.Spec.LogicalBridges...)
+	}
+	for _, ref := range refs {
+		owner, err := ownerOf(ref)
+		if err != nil {
+			return err
+		}
+		if owner != "" && owner != "*" && owner != tenant {
+			return status.Errorf(codes.PermissionDenied, "%s belongs to another tenant", ref)
+		}
+	}
+	return nil
+}
+
+// visible tells whether the object is visible from the parent of the call
+func visible(name, parent, tenant string, obj proto.Message) bool {
+	owner, err := infradb.GetParent(name)
+	if err != nil || owner != tenant {
+		return false
+	}
+	if svi, ok := obj.(*pb.Svi); ok && inCollection(parent, vrfsCollection) {
+		return svi.GetSpec().GetVrf() == parent
+	}
+	return true
+}
+
+// filter returns the elements of the list which are visible from the parent
+func filter[T interface {
+	proto.Message
+	GetName() string
+}](objs []T, parent, tenant string) []T {
+	out := []T{}
+	for _, obj := range objs {
+		if visible(obj.GetName(), parent, tenant, obj) {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+// filterList removes the objects of the other tenants from a List response. The pages may hold
+// fewer elements than requested, the next page token remains valid.
+func filterList(resp interface{}, parent, tenant string) {
+	switch out := resp.(type) {
+	case *pb.ListVrfsResponse:
+		out.Vrfs = filter(out.Vrfs, parent, tenant)
+	case *pb.ListLogicalBridgesResponse:
+		out.LogicalBridges = filter(out.LogicalBridges, parent, tenant)
+	case *pb.ListBridgePortsResponse:
+		out.BridgePorts = filter(out.BridgePorts, parent, tenant)
+	case *pb.ListSvisResponse:
+		out.Svis = filter(out.Svis, parent, tenant)
+	}
+}
+
+// claimParent makes the tenant the owner of the object about to be created, the conflict error
+// is returned when another tenant owns it or when it is shared
+func claimParent(name, tenant string, conflict error) (bool, error) {
+	claimed, err := infradb.ClaimParent(name, tenant)
+	if errors.Is(err, infradb.ErrParentConflict) {
+		return false, conflict
+	}
+	return claimed, err
+}
+
+// releaseParent gives back the ownership claimed for an object which has not been created
+func releaseParent(name string, claimed bool) {
+	if !claimed {
+		return
+	}
+	if err := infradb.DeleteParent(name); err != nil {
+		log.Printf("releaseParent(): failed to forget the parent of %s: %v", name, err)
+	}
+}
+
+// UnaryServerInterceptor scopes the calls carrying a parent header to the objects of its tenant:
+// the created objects are owned by the tenant, the objects of other tenants are not found
+// and cannot be referenced. The calls without parent header are not scoped.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := path.Base(info.FullMethod)
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+		parent := parentOf(ctx)
+		if parent == "" {
+			resp, err := handler(ctx, req)
+			if name := utils.ObjectName(msg); err == nil && name != "" && strings.HasPrefix(method, "Delete") {
+				if err := infradb.DeleteParent(name); err != nil {
+					log.Printf("%s(): failed to forget the parent: %v", method, err)
+				}
+			}
+			return resp, err
+		}
+		tenant, err := tenantOf(parent)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case strings.HasPrefix(method, "List"):
+			resp, err := handler(ctx, req)
+			if err == nil {
+				filterList(resp, parent, tenant)
+			}
+			return resp, err
+		case strings.HasPrefix(method, "Create"):
+			// the object is claimed before its creation, so that another tenant creating
+			// the same object concurrently is refused rather than taking it over
+			name := createdName(req)
+			claimed := false
+			if name != "" {
+				claimed, err = claimParent(name, tenant, status.Errorf(codes.AlreadyExists, "%s already exists", name))
+				if err != nil {
+					return nil, err
+				}
+			}
+			if err := checkReferences(req, parent, tenant); err != nil {
+				releaseParent(name, claimed)
+				return nil, err
+			}
+			resp, err := handler(ctx, req)
+			if err != nil {
+				releaseParent(name, claimed)
+				return resp, err
+			}
+			// the system generated names are unique, they are recorded once known
+			if created, ok := resp.(proto.Message); ok && name == "" {
+				if err := infradb.SetParent(utils.ObjectName(created), tenant); err != nil {
+					return nil, err
+				}
+			}
+			return resp, nil
+		default:
+			name := utils.ObjectName(msg)
+			owner, err := ownerOf(name)
+			if err != nil {
+				return nil, err
+			}
+			notFound := status.Errorf(codes.NotFound, "unable to find key %s", name)
+			if owner != "" && owner != tenant {
+				return nil, notFound
+			}
+			claimed := false
+			if strings.HasPrefix(method, "Update") && owner == "" {
+				// created by allow_missing
+				if claimed, err = claimParent(name, tenant, notFound); err != nil {
+					return nil, err
+				}
+			}
+			if err := checkReferences(req, parent, tenant); err != nil {
+				releaseParent(name, claimed)
+				return nil, err
+			}
+			resp, err := handler(ctx, req)
+			if err != nil {
+				releaseParent(name, claimed)
+				return resp, err
+			}
+			if strings.HasPrefix(method, "Delete") {
+				if err := infradb.DeleteParent(name); err != nil {
+					return nil, err
+				}
+			}
+			return resp, nil
+		}
+	}
+}
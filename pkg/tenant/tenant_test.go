@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package tenant scopes the objects of the bridge by parent, so that several orchestrators can share it
+package tenant
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/svi"
+	"github.com/opiproject/opi-evpn-bridge/pkg/vrf"
+)
+
+const (
+	tenantA = "//network.opiproject.org/tenants/a"
+	tenantB = "//network.opiproject.org/tenants/b"
+	vrfA    = "//network.opiproject.org/vrfs/vrf-a"
+	bridgeA = "//network.opiproject.org/bridges/bridge-a"
+)
+
+// testClients are the clients of the scoped bridge API
+type testClients struct {
+	vrf    pb.VrfServiceClient
+	bridge pb.LogicalBridgeServiceClient
+	svi    pb.SviServiceClient
+}
+
+// newTestClients serves the bridge API with the interceptor on top of an empty gomap db
+func newTestClients(t *testing.T) *testClients {
+	eb := eventbus.EBus
+	for _, eventType := range []string{"vrf", "logical-bridge", "svi"} {
+		eb.StartSubscriber("dummy", eventType, 1, nil)
+	}
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor()))
+	pb.RegisterVrfServiceServer(s, vrf.NewServer())
+	pb.RegisterLogicalBridgeServiceServer(s, bridge.NewServer())
+	pb.RegisterSviServiceServer(s, svi.NewServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return &testClients{
+		vrf:    pb.NewVrfServiceClient(conn),
+		bridge: pb.NewLogicalBridgeServiceClient(conn),
+		svi:    pb.NewSviServiceClient(conn),
+	}
+}
+
+// withParent returns a context carrying the parent header
+func withParent(parent string) context.Context {
+	if parent == "" {
+		return context.Background()
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), ParentHeader, parent)
+}
+
+// prefix returns the IPv4 prefix addr/length
+func prefix(addr uint32, length int32) *pc.IPPrefix {
+	return &pc.IPPrefix{Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: addr}}, Len: length}
+}
+
+// vrfSpec returns a valid vrf spec
+func vrfSpec() *pb.VrfSpec {
+	return &pb.VrfSpec{LoopbackIpPrefix: prefix(0x0a000001, 32), VtepIpPrefix: prefix(0x0a010001, 32)}
+}
+
+// createTenantA creates a vrf, a logical bridge and an svi owned by the tenant a
+func createTenantA(t *testing.T, c *testClients) {
+	ctx := withParent(tenantA)
+	if _, err := c.vrf.CreateVrf(ctx, &pb.CreateVrfRequest{VrfId: "vrf-a", Vrf: &pb.Vrf{Spec: vrfSpec()}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.bridge.CreateLogicalBridge(ctx, &pb.CreateLogicalBridgeRequest{LogicalBridgeId: "bridge-a",
+		LogicalBridge: &pb.LogicalBridge{Spec: &pb.LogicalBridgeSpec{VlanId: 10}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.svi.CreateSvi(ctx, &pb.CreateSviRequest{SviId: "svi-a", Svi: &pb.Svi{Spec: &pb.SviSpec{
+		Vrf: vrfA, LogicalBridge: bridgeA, MacAddress: []byte{0xaa, 0xbb, 0xcc, 0, 0, 1},
+		GwIpPrefix: []*pc.IPPrefix{prefix(0x0a0a0001, 24)},
+	}}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Scoping(t *testing.T) {
+	tests := map[string]struct {
+		call func(c *testClients) (int, error)
+		want int
+		code codes.Code
+	}{
+		"owner gets its vrf": {
+			call: func(c *testClients) (int, error) {
+				_, err := c.vrf.GetVrf(withParent(tenantA), &pb.GetVrfRequest{Name: vrfA})
+				return 1, err
+			},
+			want: 1,
+		},
+		"other tenant does not find the vrf": {
+			call: func(c *testClients) (int, error) {
+				_, err := c.vrf.GetVrf(withParent(tenantB), &pb.GetVrfRequest{Name: vrfA})
+				return 0, err
+			},
+			code: codes.NotFound,
+		},
+		"other tenant cannot delete the vrf": {
+			call: func(c *testClients) (int, error) {
+				_, err := c.vrf.DeleteVrf(withParent(tenantB), &pb.DeleteVrfRequest{Name: vrfA})
+				return 0, err
+			},
+			code: codes.NotFound,
+		},
+		"other tenant lists nothing": {
+			call: func(c *testClients) (int, error) {
+				resp, err := c.vrf.ListVrfs(withParent(tenantB), &pb.ListVrfsRequest{})
+				return len(resp.GetVrfs()), err
+			},
+			want: 0,
+		},
+		"owner lists its vrf": {
+			call: func(c *testClients) (int, error) {
+				resp, err := c.vrf.ListVrfs(withParent(tenantA), &pb.ListVrfsRequest{})
+				return len(resp.GetVrfs()), err
+			},
+			want: 1,
+		},
+		"unscoped caller lists everything": {
+			call: func(c *testClients) (int, error) {
+				resp, err := c.vrf.ListVrfs(withParent(""), &pb.ListVrfsRequest{})
+				return len(resp.GetVrfs()), err
+			},
+			want: 1,
+		},
+		"svis listed by vpc": {
+			call: func(c *testClients) (int, error) {
+				resp, err := c.svi.ListSvis(withParent(vrfA), &pb.ListSvisRequest{})
+				return len(resp.GetSvis()), err
+			},
+			want: 1,
+		},
+		"same id as another tenant": {
+			call: func(c *testClients) (int, error) {
+				_, err := c.vrf.CreateVrf(withParent(tenantB), &pb.CreateVrfRequest{VrfId: "vrf-a", Vrf: &pb.Vrf{Spec: vrfSpec()}})
+				return 0, err
+			},
+			code: codes.AlreadyExists,
+		},
+		"cross tenant reference": {
+			call: func(c *testClients) (int, error) {
+				ctx := withParent(tenantB)
+				if _, err := c.bridge.CreateLogicalBridge(ctx, &pb.CreateLogicalBridgeRequest{LogicalBridgeId: "bridge-b",
+					LogicalBridge: &pb.LogicalBridge{Spec: &pb.LogicalBridgeSpec{VlanId: 20}}}); err != nil {
+					return 0, err
+				}
+				_, err := c.svi.CreateSvi(ctx, &pb.CreateSviRequest{SviId: "svi-b", Svi: &pb.Svi{Spec: &pb.SviSpec{
+					Vrf: vrfA, LogicalBridge: "//network.opiproject.org/bridges/bridge-b", MacAddress: []byte{0xaa, 0xbb, 0xcc, 0, 0, 2},
+					GwIpPrefix: []*pc.IPPrefix{prefix(0x0a0b0001, 24)},
+				}}})
+				return 0, err
+			},
+			code: codes.PermissionDenied,
+		},
+		"invalid parent": {
+			call: func(c *testClients) (int, error) {
+				_, err := c.vrf.ListVrfs(withParent("acme"), &pb.ListVrfsRequest{})
+				return 0, err
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			c := newTestClients(t)
+			createTenantA(t, c)
+			got, err := tt.call(c)
+			if status.Code(err) != tt.code {
+				t.Fatalf("expected code %v, received %v", tt.code, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %d objects, received %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_ConcurrentCreate(t *testing.T) {
+	newTestClients(t)
+	server := bridge.NewServer()
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/opi_api.network.evpn_gw.v1alpha1.LogicalBridgeService/CreateLogicalBridge"}
+	req := &pb.CreateLogicalBridgeRequest{LogicalBridgeId: "bridge-a", LogicalBridge: &pb.LogicalBridge{Spec: &pb.LogicalBridgeSpec{VlanId: 10}}}
+	create := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return server.CreateLogicalBridge(ctx, req.(*pb.CreateLogicalBridgeRequest))
+	}
+	incoming := func(parent string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(ParentHeader, parent))
+	}
+
+	// the tenant b creates the same bridge while the creation of the tenant a is in progress
+	var errB error
+	_, errA := interceptor(incoming(tenantA), req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		_, errB = interceptor(incoming(tenantB), req, info, create)
+		return create(ctx, req)
+	})
+	if errA != nil {
+		t.Fatalf("expected the tenant a to create the bridge, received %v", errA)
+	}
+	if status.Code(errB) != codes.AlreadyExists {
+		t.Errorf("expected the tenant b to be refused, received %v", errB)
+	}
+	owner, err := infradb.GetParent(bridgeA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner != tenantA {
+		t.Errorf("expected the bridge to be owned by the tenant a, owned by %q", owner)
+	}
+}
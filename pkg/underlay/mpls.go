@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package underlay brings a factory-fresh node into the fabric from the underlay section of the config:
+// it assigns the vtep address, configures the uplinks and brings up the bgp sessions of the underlay
+package underlay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// sysctlPath is the location of the kernel settings
+var sysctlPath = "/proc/sys"
+
+// defaultPlatformLabels is the size of the label table of the kernel when the config leaves it out
+const defaultPlatformLabels = 100000
+
+// PlanMpls returns the MPLS core of the config for the routing backend, it fails when the config is invalid
+func PlanMpls(cfg *config.Config) (routing.Mpls, error) {
+	underlay, err := Plan(cfg)
+	if err != nil {
+		return routing.Mpls{}, err
+	}
+	if !underlay.VtepIP.IsValid() {
+		return routing.Mpls{}, errors.New("mpls requires the underlay vtepip, the next hop of the VPN routes")
+	}
+	mpls := routing.Mpls{Transport: cfg.Mpls.Transport, VtepIP: underlay.VtepIP, Interfaces: cfg.Mpls.Interfaces, SidIndex: uint32(cfg.Mpls.SidIndex)}
+	if mpls.Transport == "" {
+		mpls.Transport = routing.MplsTransportLdp
+	}
+	if len(mpls.Interfaces) == 0 {
+		for _, uplink := range cfg.Underlay.Uplinks {
+			mpls.Interfaces = append(mpls.Interfaces, uplink.Name)
+		}
+	}
+	if len(mpls.Interfaces) == 0 {
+		return routing.Mpls{}, errors.New("mpls requires the interfaces facing the core, or the underlay uplinks")
+	}
+	for _, peer := range underlay.Peers {
+		neighbor := peer.Address
+		if peer.Interface != "" {
+			neighbor = peer.Interface
+		}
+		mpls.Neighbors = append(mpls.Neighbors, neighbor)
+	}
+	if mpls.Transport == routing.MplsTransportSr && len(mpls.Neighbors) == 0 {
+		return routing.Mpls{}, errors.New("mpls sr transport requires the underlay peers, which exchange the labelled routes")
+	}
+	return mpls, nil
+}
+
+// BootstrapMpls lets the interfaces facing the core accept labelled packets and brings up the transport
+// of the labels in the routing backend, it can run at every start
+func BootstrapMpls(ctx context.Context, cfg *config.Config, backend routing.Backend) error {
+	if !cfg.Mpls.Enabled {
+		return nil
+	}
+	mpls, err := PlanMpls(cfg)
+	if err != nil {
+		return err
+	}
+	configurer, ok := backend.(routing.MplsConfigurer)
+	if !ok {
+		return fmt.Errorf("the %s routing backend does not carry the VPCs over MPLS", backend.Name())
+	}
+	labels := cfg.Mpls.PlatformLabels
+	if labels == 0 {
+		labels = defaultPlatformLabels
+	}
+	// Example: sysctl -w net.mpls.platform_labels=100000, which needs the mpls_router module
+	if err := writeSysctl(filepath.Join("net", "mpls", "platform_labels"), strconv.Itoa(labels)); err != nil {
+		return err
+	}
+	for _, dev := range mpls.Interfaces {
+		// Example: sysctl -w net.mpls.conf.<dev>.input=1
+		if err := writeSysctl(filepath.Join("net", "mpls", "conf", dev, "input"), "1"); err != nil {
+			return err
+		}
+	}
+	if err := configurer.ConfigureMpls(ctx, mpls); err != nil {
+		return fmt.Errorf("mpls %s: %w", mpls.Transport, err)
+	}
+	log.Printf("underlay: mpls %s transport of %v configured on %v\n", mpls.Transport, mpls.VtepIP, mpls.Interfaces)
+	return nil
+}
+
+// writeSysctl writes the kernel setting, the file of the device as its name may hold dots
+func writeSysctl(setting string, value string) error {
+	if err := os.WriteFile(filepath.Join(sysctlPath, setting), []byte(value), 0600); err != nil {
+		return fmt.Errorf("underlay: failed to write %s=%s: %w", setting, value, err)
+	}
+	log.Printf("underlay Executed : sysctl -w %s=%s\n", strings.ReplaceAll(setting, "/", "."), value)
+	return nil
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package underlay brings a factory-fresh node into the fabric from the underlay section of the config:
+// it assigns the vtep address, configures the uplinks and brings up the bgp sessions of the underlay
+package underlay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// mplsBackend records the MPLS core it is asked to configure
+type mplsBackend struct {
+	routing.Backend
+	mpls *routing.Mpls
+}
+
+func (b mplsBackend) ConfigureMpls(_ context.Context, mpls routing.Mpls) error {
+	*b.mpls = mpls
+	return nil
+}
+
+// vxlanBackend carries the VPCs over VXLAN only
+type vxlanBackend struct {
+	routing.Backend
+}
+
+func (vxlanBackend) Name() string {
+	return "test-vxlan"
+}
+
+func Test_PlanMpls(t *testing.T) {
+	tests := map[string]struct {
+		transport string
+		peers     bool
+		err       bool
+	}{
+		"ldp over the uplinks": {transport: "", peers: true},
+		"ldp without peers":    {transport: routing.MplsTransportLdp},
+		"sr with peers":        {transport: routing.MplsTransportSr, peers: true},
+		"sr without peers":     {transport: routing.MplsTransportSr, err: true},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Mpls.Transport = tt.transport
+			cfg.Mpls.SidIndex = 2
+			if !tt.peers {
+				cfg.Underlay.Peers = nil
+			}
+			mpls, err := PlanMpls(cfg)
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error %v, received %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if mpls.VtepIP.String() != "10.0.0.2/32" || !reflect.DeepEqual(mpls.Interfaces, []string{"eth1", "eth2"}) || mpls.SidIndex != 2 {
+				t.Errorf("unexpected mpls %+v", mpls)
+			}
+			if tt.transport == "" && mpls.Transport != routing.MplsTransportLdp {
+				t.Errorf("expected ldp by default, received %s", mpls.Transport)
+			}
+			if tt.peers && !reflect.DeepEqual(mpls.Neighbors, []string{"10.168.1.6", "eth2"}) {
+				t.Errorf("unexpected neighbors %v", mpls.Neighbors)
+			}
+		})
+	}
+}
+
+func Test_BootstrapMpls(t *testing.T) {
+	sysctlPath = t.TempDir()
+	t.Cleanup(func() { sysctlPath = "/proc/sys" })
+	for _, dir := range []string{"net/mpls/conf/eth1", "net/mpls/conf/eth2"} {
+		if err := os.MkdirAll(filepath.Join(sysctlPath, dir), 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := testConfig()
+	cfg.Mpls.Enabled = true
+	cfg.Mpls.Interfaces = []string{"eth1"}
+
+	mpls := routing.Mpls{}
+	if err := BootstrapMpls(context.Background(), cfg, mplsBackend{mpls: &mpls}); err != nil {
+		t.Fatal(err)
+	}
+	if mpls.Transport != routing.MplsTransportLdp || !reflect.DeepEqual(mpls.Interfaces, []string{"eth1"}) {
+		t.Errorf("unexpected mpls %+v", mpls)
+	}
+	for setting, expected := range map[string]string{"net/mpls/platform_labels": "100000", "net/mpls/conf/eth1/input": "1"} {
+		value, err := os.ReadFile(filepath.Join(sysctlPath, setting))
+		if err != nil || string(value) != expected {
+			t.Errorf("expected %s=%s, received %q %v", setting, expected, value, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(sysctlPath, "net/mpls/conf/eth2/input")); err == nil {
+		t.Errorf("expected eth2 to be left alone")
+	}
+
+	if err := BootstrapMpls(context.Background(), cfg, vxlanBackend{}); err == nil {
+		t.Errorf("expected a backend without mpls to be refused")
+	}
+}
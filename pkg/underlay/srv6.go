@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package underlay brings a factory-fresh node into the fabric from the underlay section of the config:
+// it assigns the vtep address, configures the uplinks and brings up the bgp sessions of the underlay
+package underlay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/netip"
+	"path/filepath"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// defaultLocatorName names the locator in the routing backend when the config leaves it out
+const defaultLocatorName = "opi"
+
+// PlanSrv6 returns the SRv6 fabric of the config for the routing backend, it fails when the config is invalid
+func PlanSrv6(cfg *config.Config) (routing.Srv6, error) {
+	underlay, err := Plan(cfg)
+	if err != nil {
+		return routing.Srv6{}, err
+	}
+	locator, err := netip.ParsePrefix(cfg.Srv6.Locator)
+	if err != nil || !locator.Addr().Is6() || locator.Bits() > 64 {
+		return routing.Srv6{}, fmt.Errorf("srv6 locator %q is not an IPv6 prefix up to a /64", cfg.Srv6.Locator)
+	}
+	srv6 := routing.Srv6{LocatorName: cfg.Srv6.LocatorName, Locator: locator.Masked()}
+	if srv6.LocatorName == "" {
+		srv6.LocatorName = defaultLocatorName
+	}
+	srv6.Source = srv6.Locator.Addr().Next()
+	if cfg.Srv6.Source != "" {
+		if srv6.Source, err = netip.ParseAddr(cfg.Srv6.Source); err != nil || !srv6.Source.Is6() {
+			return routing.Srv6{}, fmt.Errorf("srv6 source %q is not an IPv6 address", cfg.Srv6.Source)
+		}
+	}
+	for _, peer := range underlay.Peers {
+		neighbor := peer.Address
+		if peer.Interface != "" {
+			neighbor = peer.Interface
+		}
+		srv6.Neighbors = append(srv6.Neighbors, neighbor)
+	}
+	if len(srv6.Neighbors) == 0 {
+		return routing.Srv6{}, errors.New("srv6 requires the underlay peers, which learn the locator and exchange the VPN routes")
+	}
+	return srv6, nil
+}
+
+// BootstrapSrv6 lets the uplinks process the SRv6 packets and End.DT4 look up the vrf tables, and brings
+// up the locator in the routing backend, it can run at every start
+func BootstrapSrv6(ctx context.Context, cfg *config.Config, backend routing.Backend) error {
+	if !cfg.Srv6.Enabled {
+		return nil
+	}
+	srv6, err := PlanSrv6(cfg)
+	if err != nil {
+		return err
+	}
+	configurer, ok := backend.(routing.Srv6Configurer)
+	if !ok {
+		return fmt.Errorf("the %s routing backend does not carry the VPCs over SRv6", backend.Name())
+	}
+	// Example: sysctl -w net.vrf.strict_mode=1, End.DT4 looks up the table of a single vrf
+	if err := writeSysctl(filepath.Join("net", "vrf", "strict_mode"), "1"); err != nil {
+		return err
+	}
+	devs := []string{"all"}
+	for _, uplink := range cfg.Underlay.Uplinks {
+		devs = append(devs, uplink.Name)
+	}
+	for _, dev := range devs {
+		// Example: sysctl -w net.ipv6.conf.<dev>.seg6_enabled=1
+		if err := writeSysctl(filepath.Join("net", "ipv6", "conf", dev, "seg6_enabled"), "1"); err != nil {
+			return err
+		}
+	}
+	if err := configurer.ConfigureSrv6(ctx, srv6); err != nil {
+		return fmt.Errorf("srv6: %w", err)
+	}
+	log.Printf("underlay: srv6 locator %s %v configured, source %v\n", srv6.LocatorName, srv6.Locator, srv6.Source)
+	return nil
+}
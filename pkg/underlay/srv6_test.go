@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package underlay brings a factory-fresh node into the fabric from the underlay section of the config:
+// it assigns the vtep address, configures the uplinks and brings up the bgp sessions of the underlay
+package underlay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+)
+
+// srv6Backend records the SRv6 fabric it is asked to configure
+type srv6Backend struct {
+	routing.Backend
+	srv6 *routing.Srv6
+}
+
+func (b srv6Backend) ConfigureSrv6(_ context.Context, srv6 routing.Srv6) error {
+	*b.srv6 = srv6
+	return nil
+}
+
+func Test_PlanSrv6(t *testing.T) {
+	tests := map[string]struct {
+		locator string
+		source  string
+		peers   bool
+		err     bool
+	}{
+		"source in the locator":   {locator: "fc00:0:1::/48", peers: true},
+		"explicit source":         {locator: "fc00:0:1::/48", source: "fc00::2", peers: true},
+		"locator longer than /64": {locator: "fc00:0:1::/80", peers: true, err: true},
+		"IPv4 locator":            {locator: "10.0.0.0/8", peers: true, err: true},
+		"without peers":           {locator: "fc00:0:1::/48", err: true},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Srv6.Locator, cfg.Srv6.Source = tt.locator, tt.source
+			if !tt.peers {
+				cfg.Underlay.Peers = nil
+			}
+			srv6, err := PlanSrv6(cfg)
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error %v, received %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			source := "fc00:0:1::1"
+			if tt.source != "" {
+				source = tt.source
+			}
+			if srv6.LocatorName != "opi" || srv6.Locator.String() != tt.locator || srv6.Source.String() != source ||
+				!reflect.DeepEqual(srv6.Neighbors, []string{"10.168.1.6", "eth2"}) {
+				t.Errorf("unexpected srv6 %+v", srv6)
+			}
+		})
+	}
+}
+
+func Test_BootstrapSrv6(t *testing.T) {
+	sysctlPath = t.TempDir()
+	t.Cleanup(func() { sysctlPath = "/proc/sys" })
+	for _, dir := range []string{"net/vrf", "net/ipv6/conf/all", "net/ipv6/conf/eth1", "net/ipv6/conf/eth2"} {
+		if err := os.MkdirAll(filepath.Join(sysctlPath, dir), 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := testConfig()
+	cfg.Srv6.Enabled = true
+	cfg.Srv6.Locator = "fc00:0:1::/48"
+
+	srv6 := routing.Srv6{}
+	if err := BootstrapSrv6(context.Background(), cfg, srv6Backend{srv6: &srv6}); err != nil {
+		t.Fatal(err)
+	}
+	if srv6.Locator.String() != "fc00:0:1::/48" {
+		t.Errorf("unexpected srv6 %+v", srv6)
+	}
+	for _, setting := range []string{"net/vrf/strict_mode", "net/ipv6/conf/all/seg6_enabled", "net/ipv6/conf/eth1/seg6_enabled", "net/ipv6/conf/eth2/seg6_enabled"} {
+		value, err := os.ReadFile(filepath.Join(sysctlPath, setting))
+		if err != nil || string(value) != "1" {
+			t.Errorf("expected %s=1, received %q %v", setting, value, err)
+		}
+	}
+
+	if err := BootstrapSrv6(context.Background(), cfg, vxlanBackend{}); err == nil {
+		t.Errorf("expected a backend without srv6 to be refused")
+	}
+}
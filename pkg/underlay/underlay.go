@@ -0,0 +1,267 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package underlay brings a factory-fresh node into the fabric from the underlay section of the config:
+// it assigns the vtep address, configures the uplinks and brings up the bgp sessions of the underlay
+package underlay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/macsec"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// linkLocalWait is how long the bootstrap waits for the link local address of an unnumbered uplink, which is
+// polled every linkLocalPoll
+var (
+	linkLocalWait = 3 * time.Second
+	linkLocalPoll = 100 * time.Millisecond
+)
+
+// Plan returns the underlay of the config for the routing backend, it fails when the config is invalid
+func Plan(cfg *config.Config) (routing.Underlay, error) {
+	u := cfg.Underlay
+	underlay := routing.Underlay{RouterID: u.RouterID}
+	if u.VtepIP != "" {
+		vtep, err := netip.ParsePrefix(u.VtepIP)
+		if err != nil || !vtep.Addr().Is4() {
+			return routing.Underlay{}, fmt.Errorf("underlay vtepip %q is not an IPv4 prefix", u.VtepIP)
+		}
+		if cfg.LinuxFrr.DefaultVtep == "" {
+			return routing.Underlay{}, errors.New("underlay vtepip requires the linuxfrr defaultvtep device which holds it")
+		}
+		underlay.VtepIP = vtep
+		if underlay.RouterID == "" {
+			underlay.RouterID = vtep.Addr().String()
+		}
+	}
+	if underlay.RouterID != "" {
+		if id, err := netip.ParseAddr(underlay.RouterID); err != nil || !id.Is4() {
+			return routing.Underlay{}, fmt.Errorf("underlay routerid %q is not an IPv4 address", underlay.RouterID)
+		}
+	}
+	uplinks := map[string]bool{}
+	for _, uplink := range u.Uplinks {
+		if uplink.Name == "" {
+			return routing.Underlay{}, errors.New("underlay uplink without name")
+		}
+		if uplink.Address != "" {
+			if _, err := netip.ParsePrefix(uplink.Address); err != nil {
+				return routing.Underlay{}, fmt.Errorf("underlay uplink %s address: %w", uplink.Name, err)
+			}
+		}
+		if uplink.MTU < 0 {
+			return routing.Underlay{}, fmt.Errorf("underlay uplink %s mtu must not be negative", uplink.Name)
+		}
+		uplinks[uplink.Name] = true
+	}
+	for _, peer := range u.Peers {
+		if (peer.Address == "") == (peer.Interface == "") {
+			return routing.Underlay{}, errors.New("underlay peer needs either an address or an interface")
+		}
+		if peer.Address != "" {
+			if _, err := netip.ParseAddr(peer.Address); err != nil {
+				return routing.Underlay{}, fmt.Errorf("underlay peer address: %w", err)
+			}
+		}
+		if peer.Interface != "" && !uplinks[peer.Interface] {
+			return routing.Underlay{}, fmt.Errorf("underlay peer interface %s is not an uplink", peer.Interface)
+		}
+		if as, err := strconv.ParseUint(peer.RemoteAs, 10, 32); (err != nil || as == 0) && peer.RemoteAs != "internal" && peer.RemoteAs != "external" {
+			return routing.Underlay{}, fmt.Errorf("underlay peer remoteas %q is neither an AS number, internal nor external", peer.RemoteAs)
+		}
+		iface := peer.Interface
+		if iface != "" && macsec.Protects(cfg, iface) {
+			// the session runs over the MACsec device of the uplink
+			dev, err := macsec.DeviceName(iface)
+			if err != nil {
+				return routing.Underlay{}, err
+			}
+			iface = dev
+		}
+		underlay.Peers = append(underlay.Peers, routing.UnderlayPeer{Address: peer.Address, Interface: iface, RemoteAs: peer.RemoteAs})
+	}
+	return underlay, nil
+}
+
+// Bootstrap applies the underlay of the config, what is in place already is left alone so that it
+// can run at every start
+func Bootstrap(ctx context.Context, cfg *config.Config, nlink utils.Netlink, backend routing.Backend) error {
+	if !cfg.Underlay.Enabled {
+		return nil
+	}
+	underlay, err := Plan(cfg)
+	if err != nil {
+		return err
+	}
+	if underlay.VtepIP.IsValid() {
+		if err := setUpVtep(ctx, nlink, cfg.LinuxFrr.DefaultVtep, underlay.VtepIP); err != nil {
+			return err
+		}
+	}
+	unnumbered := map[string]bool{}
+	for _, peer := range cfg.Underlay.Peers {
+		unnumbered[peer.Interface] = peer.Interface != ""
+	}
+	for _, uplink := range cfg.Underlay.Uplinks {
+		protected := macsec.Protects(cfg, uplink.Name)
+		if protected {
+			// the address goes on the MACsec device of the uplink
+			uplink.Address = ""
+		}
+		if err := setUpUplink(ctx, nlink, uplink); err != nil {
+			return err
+		}
+		// the unnumbered sessions of a protected uplink run over its MACsec device, which does not exist yet
+		if unnumbered[uplink.Name] && !protected {
+			if err := checkLinkLocal(ctx, nlink, uplink.Name); err != nil {
+				return err
+			}
+		}
+	}
+	if len(underlay.Peers) == 0 && !underlay.VtepIP.IsValid() {
+		return nil
+	}
+	configurer, ok := backend.(routing.UnderlayConfigurer)
+	if !ok {
+		return fmt.Errorf("the %s routing backend does not configure the underlay", backend.Name())
+	}
+	if err := configurer.ConfigureUnderlay(ctx, underlay); err != nil {
+		return fmt.Errorf("underlay bgp: %w", err)
+	}
+	log.Printf("underlay: vtep %v, router id %s, %d uplinks and %d peers configured\n",
+		underlay.VtepIP, underlay.RouterID, len(cfg.Underlay.Uplinks), len(underlay.Peers))
+	return nil
+}
+
+// setUpVtep assigns the vtep address to its device, a dummy device created when missing
+func setUpVtep(ctx context.Context, nlink utils.Netlink, dev string, vtep netip.Prefix) error {
+	link, err := nlink.LinkByName(ctx, dev)
+	if errors.As(err, &netlink.LinkNotFoundError{}) {
+		// Example: ip link add <dev> type dummy
+		if err := nlink.LinkAdd(ctx, &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: dev}}); err != nil {
+			return fmt.Errorf("underlay: failed to create the vtep device %s: %w", dev, err)
+		}
+		log.Printf("underlay Executed : ip link add %s type dummy\n", dev)
+		link, err = nlink.LinkByName(ctx, dev)
+	}
+	if err != nil {
+		return fmt.Errorf("underlay: vtep device %s: %w", dev, err)
+	}
+	if err := addAddress(ctx, nlink, link, vtep); err != nil {
+		return err
+	}
+	if err := nlink.LinkSetUp(ctx, link); err != nil {
+		return fmt.Errorf("underlay: failed to set up the vtep device %s: %w", dev, err)
+	}
+	return nil
+}
+
+// setUpUplink configures the address and the MTU of the uplink and brings it up, an unnumbered uplink
+// only has its IPv6 link local address
+func setUpUplink(ctx context.Context, nlink utils.Netlink, uplink config.UplinkConfig) error {
+	link, err := nlink.LinkByName(ctx, uplink.Name)
+	if err != nil {
+		return fmt.Errorf("underlay: uplink %s: %w", uplink.Name, err)
+	}
+	if uplink.MTU != 0 && link.Attrs().MTU != uplink.MTU {
+		if err := nlink.LinkSetMTU(ctx, link, uplink.MTU); err != nil {
+			return fmt.Errorf("underlay: failed to set the mtu of the uplink %s: %w", uplink.Name, err)
+		}
+		log.Printf("underlay Executed : ip link set %s mtu %d\n", uplink.Name, uplink.MTU)
+	}
+	if uplink.Address != "" {
+		if err := addAddress(ctx, nlink, link, netip.MustParsePrefix(uplink.Address)); err != nil {
+			return err
+		}
+	}
+	if err := nlink.LinkSetUp(ctx, link); err != nil {
+		return fmt.Errorf("underlay: failed to set up the uplink %s: %w", uplink.Name, err)
+	}
+	return nil
+}
+
+// checkLinkLocal makes sure that the unnumbered sessions of the uplink can discover their neighbor, whose
+// link local address is learnt from its router advertisements: IPv6 is enabled on the uplink and its own
+// link local address has passed the duplicate address detection. The address only shows up once the
+// uplink has a carrier, a missing one is logged rather than failing the bootstrap.
+func checkLinkLocal(ctx context.Context, nlink utils.Netlink, name string) error {
+	disabled := filepath.Join("net", "ipv6", "conf", name, "disable_ipv6")
+	if value, err := os.ReadFile(filepath.Join(sysctlPath, disabled)); err == nil && strings.TrimSpace(string(value)) == "1" {
+		// Example: sysctl -w net.ipv6.conf.<uplink>.disable_ipv6=0
+		if err := writeSysctl(disabled, "0"); err != nil {
+			return err
+		}
+	}
+	if value, err := os.ReadFile(filepath.Join(sysctlPath, "net", "ipv6", "conf", name, "addr_gen_mode")); err == nil && strings.TrimSpace(string(value)) == "1" {
+		return fmt.Errorf("underlay: uplink %s generates no IPv6 link local address (addr_gen_mode 1), its unnumbered sessions cannot discover the neighbor", name)
+	}
+	link, err := nlink.LinkByName(ctx, name)
+	if err != nil {
+		return fmt.Errorf("underlay: uplink %s: %w", name, err)
+	}
+	deadline := time.Now().Add(linkLocalWait)
+	for {
+		addrs, err := nlink.AddrList(ctx, link, netlink.FAMILY_V6)
+		if err != nil {
+			return fmt.Errorf("underlay: failed to list the addresses of %s: %w", name, err)
+		}
+		for _, addr := range addrs {
+			if addr.IPNet == nil || !addr.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			if addr.Flags&unix.IFA_F_DADFAILED != 0 {
+				return fmt.Errorf("underlay: the link local address %s of uplink %s failed the duplicate address detection", addr.IP, name)
+			}
+			if addr.Flags&unix.IFA_F_TENTATIVE == 0 {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			log.Printf("underlay: uplink %s has no usable IPv6 link local address yet, its unnumbered sessions wait for it\n", name)
+			return nil
+		}
+		time.Sleep(linkLocalPoll)
+	}
+}
+
+// addAddress adds the address to the device unless it has it already
+func addAddress(ctx context.Context, nlink utils.Netlink, link netlink.Link, prefix netip.Prefix) error {
+	family := netlink.FAMILY_V4
+	if !prefix.Addr().Is4() {
+		family = netlink.FAMILY_V6
+	}
+	addrs, err := nlink.AddrList(ctx, link, family)
+	if err != nil {
+		return fmt.Errorf("underlay: failed to list the addresses of %s: %w", link.Attrs().Name, err)
+	}
+	ipNet := &net.IPNet{IP: prefix.Addr().AsSlice(), Mask: net.CIDRMask(prefix.Bits(), prefix.Addr().BitLen())}
+	for _, addr := range addrs {
+		if addr.IPNet != nil && addr.IPNet.String() == ipNet.String() {
+			return nil
+		}
+	}
+	// Example: ip address add <prefix> dev <dev>
+	if err := nlink.AddrAdd(ctx, link, &netlink.Addr{IPNet: ipNet}); err != nil {
+		return fmt.Errorf("underlay: failed to add %s to %s: %w", prefix, link.Attrs().Name, err)
+	}
+	log.Printf("underlay Executed : ip address add %s dev %s\n", prefix, link.Attrs().Name)
+	return nil
+}
@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package underlay brings a factory-fresh node into the fabric from the underlay section of the config:
+// it assigns the vtep address, configures the uplinks and brings up the bgp sessions of the underlay
+package underlay
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/routing"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
+)
+
+// underlayBackend records the underlay it is asked to configure
+type underlayBackend struct {
+	routing.Backend
+	underlay *routing.Underlay
+}
+
+func (b underlayBackend) ConfigureUnderlay(_ context.Context, underlay routing.Underlay) error {
+	*b.underlay = underlay
+	return nil
+}
+
+// testConfig returns the config of a leaf with a numbered and an unnumbered uplink
+func testConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.LinuxFrr.DefaultVtep = "lo0"
+	cfg.Underlay = config.UnderlayConfig{
+		Enabled: true,
+		VtepIP:  "10.0.0.2/32",
+		Uplinks: []config.UplinkConfig{
+			{Name: "eth1", Address: "10.168.1.5/24", MTU: 9100},
+			{Name: "eth2"},
+		},
+		Peers: []config.UnderlayPeerConfig{
+			{Address: "10.168.1.6", RemoteAs: "65001"},
+			{Interface: "eth2", RemoteAs: "external"},
+		},
+	}
+	return cfg
+}
+
+func Test_Plan(t *testing.T) {
+	underlay, err := Plan(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if underlay.RouterID != "10.0.0.2" || underlay.VtepIP.String() != "10.0.0.2/32" || len(underlay.Peers) != 2 {
+		t.Errorf("unexpected underlay %+v", underlay)
+	}
+	tests := map[string]func(cfg *config.Config){
+		"vtep not a prefix":       func(cfg *config.Config) { cfg.Underlay.VtepIP = "10.0.0.2" },
+		"vtep without device":     func(cfg *config.Config) { cfg.LinuxFrr.DefaultVtep = "" },
+		"router id not ipv4":      func(cfg *config.Config) { cfg.Underlay.RouterID = "fe80::1" },
+		"uplink without name":     func(cfg *config.Config) { cfg.Underlay.Uplinks[1].Name = "" },
+		"uplink address":          func(cfg *config.Config) { cfg.Underlay.Uplinks[0].Address = "10.168.1.5" },
+		"peer address and intf":   func(cfg *config.Config) { cfg.Underlay.Peers[0].Interface = "eth1" },
+		"peer on unknown uplink":  func(cfg *config.Config) { cfg.Underlay.Peers[1].Interface = "eth3" },
+		"peer remote as":          func(cfg *config.Config) { cfg.Underlay.Peers[0].RemoteAs = "ibgp" },
+		"peer without remote as":  func(cfg *config.Config) { cfg.Underlay.Peers[0].RemoteAs = "" },
+		"peer address not an ip":  func(cfg *config.Config) { cfg.Underlay.Peers[0].Address = "spine1" },
+		"negative mtu of uplink":  func(cfg *config.Config) { cfg.Underlay.Uplinks[0].MTU = -1 },
+		"peer without neighbor":   func(cfg *config.Config) { cfg.Underlay.Peers[1].Interface = "" },
+		"router id not an ip":     func(cfg *config.Config) { cfg.Underlay.RouterID = "leaf1" },
+		"vtep is an ipv6 address": func(cfg *config.Config) { cfg.Underlay.VtepIP = "fd00::2/128" },
+	}
+	for name, change := range tests {
+		cfg := testConfig()
+		change(cfg)
+		if _, err := Plan(cfg); err == nil {
+			t.Errorf("%s: expected the config to be refused", name)
+		}
+	}
+}
+
+func Test_PlanMacsec(t *testing.T) {
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig()
+	cfg.Macsec.Enabled = true
+	underlay, err := Plan(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if underlay.Peers[1].Interface != "mseth2" {
+		t.Errorf("expected the unnumbered session over the MACsec device, received %+v", underlay.Peers[1])
+	}
+}
+
+func Test_Bootstrap(t *testing.T) {
+	sysctlPath = t.TempDir()
+	t.Cleanup(func() { sysctlPath = "/proc/sys" })
+	ctx := context.Background()
+	lo0 := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: "lo0"}}
+	eth1 := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "eth1", MTU: 1500}}
+	eth2 := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "eth2", MTU: 1500}}
+	_, eth1Net, _ := net.ParseCIDR("10.168.1.0/24")
+	eth1Net.IP = net.ParseIP("10.168.1.5").To4()
+
+	mockNetlink := mocks.NewNetlink(t)
+	// the vtep device is missing, the uplink has its address already
+	mockNetlink.On("LinkByName", ctx, "lo0").Return(nil, netlink.LinkNotFoundError{}).Once()
+	mockNetlink.On("LinkAdd", ctx, mock.MatchedBy(func(link netlink.Link) bool { return link.Attrs().Name == "lo0" && link.Type() == "dummy" })).Return(nil)
+	mockNetlink.On("LinkByName", ctx, "lo0").Return(lo0, nil).Once()
+	mockNetlink.On("AddrList", ctx, lo0, netlink.FAMILY_V4).Return(nil, nil)
+	mockNetlink.On("AddrAdd", ctx, lo0, mock.MatchedBy(func(addr *netlink.Addr) bool { return addr.IPNet.String() == "10.0.0.2/32" })).Return(nil)
+	mockNetlink.On("LinkSetUp", ctx, lo0).Return(nil)
+	mockNetlink.On("LinkByName", ctx, "eth1").Return(eth1, nil)
+	mockNetlink.On("LinkSetMTU", ctx, eth1, 9100).Return(nil)
+	mockNetlink.On("AddrList", ctx, eth1, netlink.FAMILY_V4).Return([]netlink.Addr{{IPNet: eth1Net}}, nil)
+	mockNetlink.On("LinkSetUp", ctx, eth1).Return(nil)
+	mockNetlink.On("LinkByName", ctx, "eth2").Return(eth2, nil)
+	mockNetlink.On("LinkSetUp", ctx, eth2).Return(nil)
+	// the unnumbered uplink has its link local address
+	mockNetlink.On("AddrList", ctx, eth2, netlink.FAMILY_V6).Return([]netlink.Addr{{IPNet: linkLocal("fe80::2")}}, nil)
+
+	underlay := routing.Underlay{}
+	if err := Bootstrap(ctx, testConfig(), mockNetlink, underlayBackend{underlay: &underlay}); err != nil {
+		t.Fatal(err)
+	}
+	mockNetlink.AssertNotCalled(t, "AddrAdd", ctx, eth1, mock.Anything)
+	expected := []routing.UnderlayPeer{{Address: "10.168.1.6", RemoteAs: "65001"}, {Interface: "eth2", RemoteAs: "external"}}
+	if underlay.RouterID != "10.0.0.2" || !reflect.DeepEqual(underlay.Peers, expected) {
+		t.Errorf("unexpected underlay %+v", underlay)
+	}
+}
+
+// linkLocal returns the link local address as an IPNet of length 64
+func linkLocal(ip string) *net.IPNet {
+	return &net.IPNet{IP: net.ParseIP(ip), Mask: net.CIDRMask(64, 128)}
+}
+
+func Test_CheckLinkLocal(t *testing.T) {
+	sysctlPath = t.TempDir()
+	linkLocalWait, linkLocalPoll = 20*time.Millisecond, time.Millisecond
+	t.Cleanup(func() { sysctlPath, linkLocalWait, linkLocalPoll = "/proc/sys", 3*time.Second, 100*time.Millisecond })
+	ctx := context.Background()
+	conf := filepath.Join(sysctlPath, "net", "ipv6", "conf", "eth2")
+	if err := os.MkdirAll(conf, 0750); err != nil {
+		t.Fatal(err)
+	}
+	eth2 := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "eth2"}}
+	tests := map[string]struct {
+		addrs       []netlink.Addr
+		addrGenMode string
+		err         bool
+	}{
+		"link local address":       {addrs: []netlink.Addr{{IPNet: linkLocal("fe80::2")}}},
+		"tentative until timeout":  {addrs: []netlink.Addr{{IPNet: linkLocal("fe80::2"), Flags: unix.IFA_F_TENTATIVE}}},
+		"no carrier yet":           {},
+		"duplicate address":        {addrs: []netlink.Addr{{IPNet: linkLocal("fe80::2"), Flags: unix.IFA_F_DADFAILED}}, err: true},
+		"no link local generation": {addrGenMode: "1", err: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := os.WriteFile(filepath.Join(conf, "disable_ipv6"), []byte("1\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			mode := tt.addrGenMode
+			if mode == "" {
+				mode = "0"
+			}
+			if err := os.WriteFile(filepath.Join(conf, "addr_gen_mode"), []byte(mode+"\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			mockNetlink := mocks.NewNetlink(t)
+			if tt.addrGenMode == "" {
+				mockNetlink.On("LinkByName", ctx, "eth2").Return(eth2, nil)
+				mockNetlink.On("AddrList", ctx, eth2, netlink.FAMILY_V6).Return(tt.addrs, nil)
+			}
+			err := checkLinkLocal(ctx, mockNetlink, "eth2")
+			if (err != nil) != tt.err {
+				t.Errorf("expected error %v, received %v", tt.err, err)
+			}
+			if value, _ := os.ReadFile(filepath.Join(conf, "disable_ipv6")); string(value) != "0" {
+				t.Errorf("expected IPv6 to be enabled on the uplink, received disable_ipv6=%q", value)
+			}
+		})
+	}
+}
+
+func Test_BootstrapDisabled(t *testing.T) {
+	cfg := testConfig()
+	cfg.Underlay.Enabled = false
+	if err := Bootstrap(context.Background(), cfg, mocks.NewNetlink(t), nil); err != nil {
+		t.Errorf("expected a disabled underlay to do nothing, received %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// BindToDevice returns the control function of the sockets binding them to the device, e.g. the vrf
+// device of the management plane. The empty device unbinds them from the vrf the process may have
+// been started in with ip vrf exec, so that they use the default vrf.
+func BindToDevice(dev string) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, c syscall.RawConn) error {
+		var err error
+		if ctrlErr := c.Control(func(fd uintptr) {
+			if dev == "" {
+				// changing the device of a socket takes CAP_NET_RAW, the sockets not bound are left alone
+				if bound, getErr := unix.GetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_BINDTODEVICE); getErr == nil && bound == "" {
+					return
+				}
+			}
+			err = unix.SetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_BINDTODEVICE, dev)
+		}); ctrlErr != nil {
+			return ctrlErr
+		}
+		return err
+	}
+}
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func Test_BindToDevice(t *testing.T) {
+	// the default vrf needs no privilege when the socket is not bound yet
+	lc := net.ListenConfig{Control: BindToDevice("")}
+	lis, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected the listener in the default vrf to open, received %v", err)
+	}
+	dialer := net.Dialer{Control: BindToDevice("")}
+	conn, err := dialer.DialContext(context.Background(), "tcp", lis.Addr().String())
+	if err != nil {
+		t.Fatalf("expected the dial in the default vrf to succeed, received %v", err)
+	}
+	_ = conn.Close()
+	_ = lis.Close()
+
+	lc = net.ListenConfig{Control: BindToDevice("no-such-vrf")}
+	if lis, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:0"); err == nil {
+		_ = lis.Close()
+		t.Error("expected the listener in a missing vrf to fail")
+	}
+}
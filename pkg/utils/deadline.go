@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils contains utility functions
+package utils
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// DeadlineFunc returns the maximum execution time of the named method, zero when unlimited
+type DeadlineFunc func(method string) time.Duration
+
+// DeadlineInterceptor bounds the execution of the calls by the maximum execution time of their method,
+// the deadline of the client still applies when it is earlier. The calls which expire before they
+// are handled fail with DeadlineExceeded without running.
+func DeadlineInterceptor(deadline DeadlineFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if max := deadline(path.Base(info.FullMethod)); max > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, max)
+			defer cancel()
+		}
+		if err := ContextError(ctx); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// MethodDeadlines returns the maximum execution times of the methods in seconds, keyed by the method
+// name regardless of its case as the config keys are lower cased, with the default of the other methods.
+// The times are read at every call so that a reloaded config applies to the next calls.
+func MethodDeadlines(methods func() map[string]int, defaultSeconds func() int) DeadlineFunc {
+	return func(method string) time.Duration {
+		for name, seconds := range methods() {
+			if strings.EqualFold(name, method) {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+		return time.Duration(defaultSeconds()) * time.Second
+	}
+}
+
+// ContextError returns the gRPC status of a cancelled or expired context, nil while the call can go on.
+// The handlers check it before they write to the store so that an abandoned call changes nothing.
+func ContextError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return nil
+}
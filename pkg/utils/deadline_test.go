@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils contains utility functions
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_DeadlineInterceptor(t *testing.T) {
+	deadlines := MethodDeadlines(
+		func() map[string]int { return map[string]int{"listvrfs": 60, "GetVrf": 0} },
+		func() int { return 30 },
+	)
+
+	tests := map[string]struct {
+		method    string
+		cancelled bool
+		deadline  time.Duration
+		code      codes.Code
+	}{
+		"default deadline": {
+			method:   "CreateVrf",
+			deadline: 30 * time.Second,
+			code:     codes.OK,
+		},
+		"method deadline regardless of the case": {
+			method:   "ListVrfs",
+			deadline: 60 * time.Second,
+			code:     codes.OK,
+		},
+		"unlimited method": {
+			method: "GetVrf",
+			code:   codes.OK,
+		},
+		"cancelled call is not handled": {
+			method:    "CreateVrf",
+			cancelled: true,
+			code:      codes.Canceled,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+			handled := false
+			handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+				handled = true
+				deadline, ok := ctx.Deadline()
+				if ok != (tt.deadline != 0) {
+					t.Errorf("expected a deadline %v, got %v", tt.deadline != 0, ok)
+				}
+				if ok && (time.Until(deadline) > tt.deadline || time.Until(deadline) < tt.deadline-time.Second) {
+					t.Errorf("expected a deadline in %v, got %v", tt.deadline, time.Until(deadline))
+				}
+				return nil, nil
+			}
+
+			interceptor := DeadlineInterceptor(deadlines)
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/opi_api.network.evpn_gw.v1alpha1.VrfService/" + tt.method}, handler)
+			if status.Code(err) != tt.code {
+				t.Errorf("expected code %v, got %v", tt.code, err)
+			}
+			if handled == tt.cancelled {
+				t.Errorf("expected the call to be handled %v", !tt.cancelled)
+			}
+		})
+	}
+}
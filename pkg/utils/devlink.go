@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"context"
+
+	"github.com/vishvananda/netlink"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DevlinkDevice is a devlink device of the NIC with its eswitch mode
+type DevlinkDevice struct {
+	Bus    string
+	Device string
+	// EswitchMode is legacy or switchdev, empty when the device has no eswitch
+	EswitchMode string
+}
+
+// DevlinkResourceUsage is the size and the occupancy of a hardware table of a devlink device
+type DevlinkResourceUsage struct {
+	// Path is the path of the resource in the tree of the device, e.g. /kvd/hash_single
+	Path      string
+	Size      uint64
+	Occupancy uint64
+	// OccupancyValid is false when the driver does not report the occupancy
+	OccupancyValid bool
+	Unit           string
+}
+
+// Devlink represents limited subset of functions from the devlink part of the netlink package
+type Devlink interface {
+	DeviceList(context.Context) ([]DevlinkDevice, error)
+	SetEswitchMode(context.Context, string, string, string) error
+	Resources(context.Context, string, string) ([]DevlinkResourceUsage, error)
+}
+
+// DevlinkWrapper wrapper for the devlink functions of the netlink package
+type DevlinkWrapper struct {
+	tracer trace.Tracer
+}
+
+// NewDevlinkWrapperWithArgs creates initialized instance of DevlinkWrapper
+// based on passing arguments
+func NewDevlinkWrapperWithArgs(enableTracer bool) *DevlinkWrapper {
+	devlinkWrapper := &DevlinkWrapper{}
+	devlinkWrapper.tracer = noop.NewTracerProvider().Tracer("")
+	if enableTracer {
+		devlinkWrapper.tracer = otel.Tracer("")
+	}
+	return devlinkWrapper
+}
+
+// build time check that struct implements interface
+var _ Devlink = (*DevlinkWrapper)(nil)
+
+// DeviceList is a wrapper for netlink.DevLinkGetDeviceList
+func (d *DevlinkWrapper) DeviceList(ctx context.Context) ([]DevlinkDevice, error) {
+	_, childSpan := d.tracer.Start(ctx, "devlink.DeviceList")
+	defer childSpan.End()
+
+	devs, err := netlink.DevLinkGetDeviceList()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]DevlinkDevice, 0, len(devs))
+	for _, dev := range devs {
+		out = append(out, DevlinkDevice{Bus: dev.BusName, Device: dev.DeviceName, EswitchMode: dev.Attrs.Eswitch.Mode})
+	}
+	return out, nil
+}
+
+// SetEswitchMode is a wrapper for netlink.DevLinkSetEswitchMode
+func (d *DevlinkWrapper) SetEswitchMode(ctx context.Context, bus string, device string, mode string) error {
+	_, childSpan := d.tracer.Start(ctx, "devlink.SetEswitchMode")
+	childSpan.SetAttributes(attribute.String("devlink.device", bus+"/"+device), attribute.String("devlink.mode", mode))
+	defer childSpan.End()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	dev, err := netlink.DevLinkGetDeviceByName(bus, device)
+	if err != nil {
+		return err
+	}
+	return netlink.DevLinkSetEswitchMode(dev, mode)
+}
+
+// Resources is a wrapper for netlink.DevlinkGetDeviceResources, the tree of the resources is flattened
+func (d *DevlinkWrapper) Resources(ctx context.Context, bus string, device string) ([]DevlinkResourceUsage, error) {
+	_, childSpan := d.tracer.Start(ctx, "devlink.Resources")
+	childSpan.SetAttributes(attribute.String("devlink.device", bus+"/"+device))
+	defer childSpan.End()
+
+	res, err := netlink.DevlinkGetDeviceResources(bus, device)
+	if err != nil {
+		return nil, err
+	}
+	return flattenResources("", res.Resources), nil
+}
+
+// devlinkUnits are the names of the units of the devlink resources
+var devlinkUnits = map[uint8]string{0: "entry"}
+
+// flattenResources lists the resources of the tree with their path
+func flattenResources(parent string, resources []netlink.DevlinkResource) []DevlinkResourceUsage {
+	out := []DevlinkResourceUsage{}
+	for _, res := range resources {
+		usage := DevlinkResourceUsage{
+			Path:           parent + "/" + res.Name,
+			Size:           res.Size,
+			Occupancy:      res.OCCSize,
+			OccupancyValid: res.OCCValid,
+			Unit:           devlinkUnits[res.Unit],
+		}
+		out = append(out, usage)
+		out = append(out, flattenResources(usage.Path, res.Children)...)
+	}
+	return out
+}
+
+// CheckOffloadCapacity refuses with ResourceExhausted the creation of an offloaded object when a hardware
+// table of a devlink device is filled to the threshold percentage, a zero threshold disables the check
+func CheckOffloadCapacity(ctx context.Context, dl Devlink, threshold int) error {
+	if threshold <= 0 {
+		return nil
+	}
+	devs, err := dl.DeviceList(ctx)
+	if err != nil {
+		// no devlink support, nothing is offloaded
+		return nil
+	}
+	for _, dev := range devs {
+		resources, err := dl.Resources(ctx, dev.Bus, dev.Device)
+		if err != nil {
+			continue
+		}
+		for _, res := range resources {
+			if !res.OccupancyValid || res.Size == 0 {
+				continue
+			}
+			if res.Occupancy*100 >= uint64(threshold)*res.Size {
+				return status.Errorf(codes.ResourceExhausted, "the hardware table %s of %s/%s is %d%% full (%d of %d)",
+					res.Path, dev.Bus, dev.Device, res.Occupancy*100/res.Size, res.Occupancy, res.Size)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeDevlink is a Devlink with a fixed set of devices and resources
+type fakeDevlink struct {
+	devices   []DevlinkDevice
+	resources map[string][]DevlinkResourceUsage
+	err       error
+}
+
+func (f *fakeDevlink) DeviceList(context.Context) ([]DevlinkDevice, error) {
+	return f.devices, f.err
+}
+
+func (f *fakeDevlink) SetEswitchMode(context.Context, string, string, string) error {
+	return f.err
+}
+
+func (f *fakeDevlink) Resources(_ context.Context, bus string, device string) ([]DevlinkResourceUsage, error) {
+	res, ok := f.resources[bus+"/"+device]
+	if !ok {
+		return nil, errors.New("no resources")
+	}
+	return res, nil
+}
+
+func Test_CheckOffloadCapacity(t *testing.T) {
+	devices := []DevlinkDevice{{Bus: "pci", Device: "0000:03:00.0", EswitchMode: "switchdev"}, {Bus: "pci", Device: "0000:03:00.1"}}
+	tests := map[string]struct {
+		dl        *fakeDevlink
+		threshold int
+		code      codes.Code
+	}{
+		"room left": {
+			dl: &fakeDevlink{devices: devices, resources: map[string][]DevlinkResourceUsage{
+				"pci/0000:03:00.0": {{Path: "/fdb", Size: 1000, Occupancy: 500, OccupancyValid: true}},
+			}},
+			threshold: 90,
+			code:      codes.OK,
+		},
+		"table nearly full": {
+			dl: &fakeDevlink{devices: devices, resources: map[string][]DevlinkResourceUsage{
+				"pci/0000:03:00.0": {{Path: "/fdb", Size: 1000, Occupancy: 500, OccupancyValid: true}},
+				"pci/0000:03:00.1": {{Path: "/encap", Size: 100, Occupancy: 95, OccupancyValid: true}},
+			}},
+			threshold: 90,
+			code:      codes.ResourceExhausted,
+		},
+		"occupancy not reported": {
+			dl: &fakeDevlink{devices: devices, resources: map[string][]DevlinkResourceUsage{
+				"pci/0000:03:00.0": {{Path: "/fdb", Size: 1000, Occupancy: 1000}},
+			}},
+			threshold: 90,
+			code:      codes.OK,
+		},
+		"check disabled": {
+			dl: &fakeDevlink{devices: devices, resources: map[string][]DevlinkResourceUsage{
+				"pci/0000:03:00.0": {{Path: "/fdb", Size: 1000, Occupancy: 1000, OccupancyValid: true}},
+			}},
+			threshold: 0,
+			code:      codes.OK,
+		},
+		"no devlink": {
+			dl:        &fakeDevlink{err: errors.New("no such family")},
+			threshold: 90,
+			code:      codes.OK,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			err := CheckOffloadCapacity(context.Background(), tt.dl, tt.threshold)
+			if code := status.Code(err); code != tt.code {
+				t.Errorf("expected code %v, got %v (%v)", tt.code, code, err)
+			}
+		})
+	}
+}
+
+func Test_flattenResources(t *testing.T) {
+	resources := []netlink.DevlinkResource{
+		{Name: "kvd", Size: 100, Children: []netlink.DevlinkResource{
+			{Name: "hash_single", Size: 60, OCCValid: true, OCCSize: 12},
+			{Name: "linear", Size: 40},
+		}},
+		{Name: "encap", Size: 8, OCCValid: true, OCCSize: 2, Unit: 0},
+	}
+	want := []DevlinkResourceUsage{
+		{Path: "/kvd", Size: 100, Unit: "entry"},
+		{Path: "/kvd/hash_single", Size: 60, Occupancy: 12, OccupancyValid: true, Unit: "entry"},
+		{Path: "/kvd/linear", Size: 40, Unit: "entry"},
+		{Path: "/encap", Size: 8, Occupancy: 2, OccupancyValid: true, Unit: "entry"},
+	}
+	if got := flattenResources("", resources); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
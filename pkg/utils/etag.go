@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils contains utility functions
+package utils
+
+import (
+	"context"
+	"log"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// ETagHeader is the response header holding the resource version of the object returned by Get, Create and Update
+	ETagHeader = "etag"
+	// IfMatchHeader is the request header holding the resource version expected by Update and Delete
+	IfMatchHeader = "if-match"
+)
+
+// ResourceVersionFunc returns the version of the named object, or an empty version when the object does not exist
+type ResourceVersionFunc func(name string) (string, error)
+
+// ObjectName returns the name of the object targeted by the request: the name field of the
+// request itself (Get, Delete) or the name of the object it carries (Create, Update)
+func ObjectName(msg proto.Message) string {
+	m := msg.ProtoReflect()
+	if field := m.Descriptor().Fields().ByName("name"); field != nil && field.Kind() == protoreflect.StringKind {
+		return m.Get(field).String()
+	}
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.Kind() != protoreflect.MessageKind || field.IsList() || field.IsMap() || !m.Has(field) {
+			continue
+		}
+		obj := m.Get(field).Message()
+		if name := obj.Descriptor().Fields().ByName("name"); name != nil && name.Kind() == protoreflect.StringKind {
+			return obj.Get(name).String()
+		}
+	}
+	return ""
+}
+
+// IfMatch returns the resource version of the if-match header of the request, empty without header
+func IfMatch(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(IfMatchHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ETagInterceptor returns the resource version of the objects in the etag header of the Get, Create and Update
+// responses, and rejects with Aborted the Update and Delete calls whose if-match header is not the stored version.
+// The check only fails the stale calls early, the handlers pass the if-match header to the store which compares
+// it again under the lock of the write, so that the concurrent writers do not silently overwrite each other.
+// When required is set the Update and Delete calls of an existing object without if-match header are rejected
+// with FailedPrecondition.
+func ETagInterceptor(version ResourceVersionFunc, required bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := path.Base(info.FullMethod)
+		write := strings.HasPrefix(method, "Update") || strings.HasPrefix(method, "Delete")
+		if write {
+			if msg, ok := req.(proto.Message); ok {
+				if err := checkIfMatch(ctx, version, ObjectName(msg), required); err != nil {
+					log.Printf("%s(): %v", method, err)
+					return nil, err
+				}
+			}
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil || strings.HasPrefix(method, "Delete") {
+			return resp, err
+		}
+		if !strings.HasPrefix(method, "Get") && !strings.HasPrefix(method, "Create") && !strings.HasPrefix(method, "Update") {
+			return resp, err
+		}
+		if msg, ok := resp.(proto.Message); ok {
+			name := ObjectName(msg)
+			if name == "" {
+				return resp, err
+			}
+			if v, verr := version(name); verr == nil && v != "" {
+				if herr := grpc.SetHeader(ctx, metadata.Pairs(ETagHeader, v)); herr != nil {
+					log.Printf("%s(): failed to set the etag of %s: %v", method, name, herr)
+				}
+			}
+		}
+		return resp, err
+	}
+}
+
+// checkIfMatch compares the if-match header of the request with the stored version of the object
+func checkIfMatch(ctx context.Context, version ResourceVersionFunc, name string, required bool) error {
+	if name == "" {
+		return nil
+	}
+	current, err := version(name)
+	if err != nil {
+		return err
+	}
+	if current == "" {
+		// Missing objects are handled by allow_missing
+		return nil
+	}
+	ifMatch := IfMatch(ctx)
+	if ifMatch == "" {
+		if required {
+			return status.Errorf(codes.FailedPrecondition, "the %s header with the etag of %s is required", IfMatchHeader, name)
+		}
+		return nil
+	}
+	if ifMatch != current {
+		return status.Errorf(codes.Aborted, "%s was modified concurrently: etag %s does not match %s", name, ifMatch, current)
+	}
+	return nil
+}
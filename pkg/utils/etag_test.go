@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils contains utility functions
+package utils
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// headerStream records the headers set by the interceptor
+type headerStream struct {
+	header metadata.MD
+}
+
+func (s *headerStream) Method() string { return "" }
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *headerStream) SendHeader(md metadata.MD) error { return s.SetHeader(md) }
+
+func (s *headerStream) SetTrailer(metadata.MD) error { return nil }
+
+func Test_ETagInterceptor(t *testing.T) {
+	const name = "//network.opiproject.org/vrfs/blue"
+	versions := map[string]string{name: "2"}
+	version := func(name string) (string, error) { return versions[name], nil }
+
+	tests := map[string]struct {
+		method   string
+		req      interface{}
+		ifMatch  string
+		required bool
+		code     codes.Code
+		etag     string
+	}{
+		"get returns the etag": {
+			method: "GetVrf",
+			req:    &pb.GetVrfRequest{Name: name},
+			code:   codes.OK,
+			etag:   "2",
+		},
+		"update with matching etag": {
+			method:  "UpdateVrf",
+			req:     &pb.UpdateVrfRequest{Vrf: &pb.Vrf{Name: name}},
+			ifMatch: "2",
+			code:    codes.OK,
+			etag:    "2",
+		},
+		"update with stale etag": {
+			method:  "UpdateVrf",
+			req:     &pb.UpdateVrfRequest{Vrf: &pb.Vrf{Name: name}},
+			ifMatch: "1",
+			code:    codes.Aborted,
+		},
+		"delete with stale etag": {
+			method:  "DeleteVrf",
+			req:     &pb.DeleteVrfRequest{Name: name},
+			ifMatch: "1",
+			code:    codes.Aborted,
+		},
+		"delete without etag": {
+			method: "DeleteVrf",
+			req:    &pb.DeleteVrfRequest{Name: name},
+			code:   codes.OK,
+		},
+		"delete without required etag": {
+			method:   "DeleteVrf",
+			req:      &pb.DeleteVrfRequest{Name: name},
+			required: true,
+			code:     codes.FailedPrecondition,
+		},
+		"delete of missing object without required etag": {
+			method:   "DeleteVrf",
+			req:      &pb.DeleteVrfRequest{Name: "//network.opiproject.org/vrfs/red", AllowMissing: true},
+			required: true,
+			code:     codes.OK,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			stream := &headerStream{}
+			ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+			if tt.ifMatch != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(IfMatchHeader, tt.ifMatch))
+			}
+			called := false
+			handler := func(context.Context, interface{}) (interface{}, error) {
+				called = true
+				return &pb.Vrf{Name: name}, nil
+			}
+			info := &grpc.UnaryServerInfo{FullMethod: "/opi_api.network.evpn_gw.v1alpha1.VrfService/" + tt.method}
+			_, err := ETagInterceptor(version, tt.required)(ctx, tt.req, info, handler)
+			if status.Code(err) != tt.code {
+				t.Fatalf("expected code %v, received %v", tt.code, err)
+			}
+			if called != (tt.code == codes.OK) {
+				t.Errorf("expected handler called %v", tt.code == codes.OK)
+			}
+			etag := ""
+			if values := stream.header.Get(ETagHeader); len(values) > 0 {
+				etag = values[0]
+			}
+			if etag != tt.etag {
+				t.Errorf("expected etag %q, received %q", tt.etag, etag)
+			}
+		})
+	}
+}
@@ -0,0 +1,444 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vishvananda/netlink"
+)
+
+// FaultsEnv names the environment variable configuring the fault injection of a binary built with
+// the faultinjection tag, e.g. OPI_FAULTS=netlink=0.1,frr=0.05,hang=0.2,hangfor=10s,ops=LinkAdd+FrrBgpCmd
+const FaultsEnv = "OPI_FAULTS"
+
+// defaultHangFor is how long a hanging operation blocks unless its context is done before
+const defaultHangFor = time.Minute
+
+// ErrInjectedFault is the error of the operations failed by the fault injection
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultConfig tells which fraction of the netlink and FRR operations fail
+type FaultConfig struct {
+	Netlink float64
+	Frr     float64
+	// Hang is the fraction of the faults which block for HangFor before failing
+	Hang    float64
+	HangFor time.Duration
+	// Ops restricts the faults to the operations named, all of them when empty
+	Ops  map[string]bool
+	Seed int64
+}
+
+// ParseFaults parses the fault injection config, a comma separated list of key=value
+func ParseFaults(spec string) (FaultConfig, error) {
+	cfg := FaultConfig{HangFor: defaultHangFor, Seed: time.Now().UnixNano()}
+	for _, item := range strings.Split(spec, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+		var err error
+		switch key {
+		case "netlink", "frr", "hang":
+			var fraction float64
+			fraction, err = strconv.ParseFloat(value, 64)
+			if err == nil && (fraction < 0 || fraction > 1) {
+				err = errors.New("the fraction has to be between 0 and 1")
+			}
+			switch key {
+			case "netlink":
+				cfg.Netlink = fraction
+			case "frr":
+				cfg.Frr = fraction
+			default:
+				cfg.Hang = fraction
+			}
+		case "hangfor":
+			cfg.HangFor, err = time.ParseDuration(value)
+		case "seed":
+			cfg.Seed, err = strconv.ParseInt(value, 10, 64)
+		case "ops":
+			cfg.Ops = map[string]bool{}
+			for _, op := range strings.Split(value, "+") {
+				cfg.Ops[op] = true
+			}
+		default:
+			err = errors.New("unknown key")
+		}
+		if err != nil {
+			return FaultConfig{}, fmt.Errorf("invalid fault injection %q: %v", item, err)
+		}
+	}
+	return cfg, nil
+}
+
+// faultsFromEnv returns the fault injection config of the environment, false when the binary
+// is not built for fault injection or the environment does not ask for it
+func faultsFromEnv() (FaultConfig, bool) {
+	spec := os.Getenv(FaultsEnv)
+	if !faultInjection || spec == "" {
+		return FaultConfig{}, false
+	}
+	cfg, err := ParseFaults(spec)
+	if err != nil {
+		log.Printf("faults: %v, no fault is injected\n", err)
+		return FaultConfig{}, false
+	}
+	log.Printf("faults: injecting faults %+v\n", cfg)
+	return cfg, true
+}
+
+// faultInjector draws the operations which fail
+type faultInjector struct {
+	mu       sync.Mutex
+	rand     *rand.Rand
+	fraction float64
+	cfg      FaultConfig
+}
+
+// newFaultInjector returns the injector failing the fraction of the operations
+func newFaultInjector(fraction float64, cfg FaultConfig) *faultInjector {
+	return &faultInjector{rand: rand.New(rand.NewSource(cfg.Seed)), fraction: fraction, cfg: cfg} //nolint:gosec
+}
+
+// inject returns the fault of the operation, nil when it runs normally. A hanging operation blocks
+// for the hang time or until its context is done.
+func (f *faultInjector) inject(ctx context.Context, op string) error {
+	if len(f.cfg.Ops) != 0 && !f.cfg.Ops[op] {
+		return nil
+	}
+	f.mu.Lock()
+	fail := f.rand.Float64() < f.fraction
+	hang := fail && f.rand.Float64() < f.cfg.Hang
+	f.mu.Unlock()
+	if !fail {
+		return nil
+	}
+	if hang {
+		log.Printf("faults: hanging %s for %v\n", op, f.cfg.HangFor)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(f.cfg.HangFor):
+		}
+	} else {
+		log.Printf("faults: failing %s\n", op)
+	}
+	return fmt.Errorf("%s: %w", op, ErrInjectedFault)
+}
+
+// WithNetlinkFaults returns the netlink wrapper failing operations as the environment asks,
+// the wrapper itself when it asks for none
+func WithNetlinkFaults(n Netlink) Netlink {
+	if cfg, ok := faultsFromEnv(); ok && cfg.Netlink > 0 {
+		return NewFaultyNetlink(n, cfg)
+	}
+	return n
+}
+
+// WithFrrFaults returns the FRR wrapper failing operations as the environment asks,
+// the wrapper itself when it asks for none
+func WithFrrFaults(f Frr) Frr {
+	if cfg, ok := faultsFromEnv(); ok && cfg.Frr > 0 {
+		return NewFaultyFrr(f, cfg)
+	}
+	return f
+}
+
+// FaultyNetlink fails a fraction of the netlink operations changing the kernel state
+type FaultyNetlink struct {
+	Netlink
+	faults *faultInjector
+}
+
+// NewFaultyNetlink returns the netlink wrapper failing the netlink fraction of the config
+func NewFaultyNetlink(n Netlink, cfg FaultConfig) *FaultyNetlink {
+	return &FaultyNetlink{Netlink: n, faults: newFaultInjector(cfg.Netlink, cfg)}
+}
+
+// build time check that struct implements interface
+var _ Netlink = (*FaultyNetlink)(nil)
+
+// LinkModify fails or runs netlink.LinkModify
+func (n *FaultyNetlink) LinkModify(ctx context.Context, link netlink.Link) error {
+	if err := n.faults.inject(ctx, "LinkModify"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkModify(ctx, link)
+}
+
+// LinkSetHardwareAddr fails or runs netlink.LinkSetHardwareAddr
+func (n *FaultyNetlink) LinkSetHardwareAddr(ctx context.Context, link netlink.Link, hwaddr net.HardwareAddr) error {
+	if err := n.faults.inject(ctx, "LinkSetHardwareAddr"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetHardwareAddr(ctx, link, hwaddr)
+}
+
+// LinkSetVfHardwareAddr fails or runs netlink.LinkSetVfHardwareAddr
+func (n *FaultyNetlink) LinkSetVfHardwareAddr(ctx context.Context, link netlink.Link, vf int, hwaddr net.HardwareAddr) error {
+	if err := n.faults.inject(ctx, "LinkSetVfHardwareAddr"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetVfHardwareAddr(ctx, link, vf, hwaddr)
+}
+
+// AddrAdd fails or runs netlink.AddrAdd
+func (n *FaultyNetlink) AddrAdd(ctx context.Context, link netlink.Link, addr *netlink.Addr) error {
+	if err := n.faults.inject(ctx, "AddrAdd"); err != nil {
+		return err
+	}
+	return n.Netlink.AddrAdd(ctx, link, addr)
+}
+
+// AddrDel fails or runs netlink.AddrDel
+func (n *FaultyNetlink) AddrDel(ctx context.Context, link netlink.Link, addr *netlink.Addr) error {
+	if err := n.faults.inject(ctx, "AddrDel"); err != nil {
+		return err
+	}
+	return n.Netlink.AddrDel(ctx, link, addr)
+}
+
+// LinkAdd fails or runs netlink.LinkAdd
+func (n *FaultyNetlink) LinkAdd(ctx context.Context, link netlink.Link) error {
+	if err := n.faults.inject(ctx, "LinkAdd"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkAdd(ctx, link)
+}
+
+// LinkDel fails or runs netlink.LinkDel
+func (n *FaultyNetlink) LinkDel(ctx context.Context, link netlink.Link) error {
+	if err := n.faults.inject(ctx, "LinkDel"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkDel(ctx, link)
+}
+
+// LinkSetUp fails or runs netlink.LinkSetUp
+func (n *FaultyNetlink) LinkSetUp(ctx context.Context, link netlink.Link) error {
+	if err := n.faults.inject(ctx, "LinkSetUp"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetUp(ctx, link)
+}
+
+// LinkSetDown fails or runs netlink.LinkSetDown
+func (n *FaultyNetlink) LinkSetDown(ctx context.Context, link netlink.Link) error {
+	if err := n.faults.inject(ctx, "LinkSetDown"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetDown(ctx, link)
+}
+
+// LinkSetMaster fails or runs netlink.LinkSetMaster
+func (n *FaultyNetlink) LinkSetMaster(ctx context.Context, link netlink.Link, master netlink.Link) error {
+	if err := n.faults.inject(ctx, "LinkSetMaster"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetMaster(ctx, link, master)
+}
+
+// LinkSetNoMaster fails or runs netlink.LinkSetNoMaster
+func (n *FaultyNetlink) LinkSetNoMaster(ctx context.Context, link netlink.Link) error {
+	if err := n.faults.inject(ctx, "LinkSetNoMaster"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetNoMaster(ctx, link)
+}
+
+// LinkSetNsFd fails or runs netlink.LinkSetNsFd
+func (n *FaultyNetlink) LinkSetNsFd(ctx context.Context, link netlink.Link, fd int) error {
+	if err := n.faults.inject(ctx, "LinkSetNsFd"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetNsFd(ctx, link, fd)
+}
+
+// LinkSetName fails or runs netlink.LinkSetName
+func (n *FaultyNetlink) LinkSetName(ctx context.Context, link netlink.Link, name string) error {
+	if err := n.faults.inject(ctx, "LinkSetName"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetName(ctx, link, name)
+}
+
+// LinkSetAlias fails or runs netlink.LinkSetAlias
+func (n *FaultyNetlink) LinkSetAlias(ctx context.Context, link netlink.Link, alias string) error {
+	if err := n.faults.inject(ctx, "LinkSetAlias"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetAlias(ctx, link, alias)
+}
+
+// LinkSetVfRate fails or runs netlink.LinkSetVfRate
+func (n *FaultyNetlink) LinkSetVfRate(ctx context.Context, link netlink.Link, vf int, minRate int, maxRate int) error {
+	if err := n.faults.inject(ctx, "LinkSetVfRate"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetVfRate(ctx, link, vf, minRate, maxRate)
+}
+
+// LinkSetVfSpoofchk fails or runs netlink.LinkSetVfSpoofchk
+func (n *FaultyNetlink) LinkSetVfSpoofchk(ctx context.Context, link netlink.Link, vf int, check bool) error {
+	if err := n.faults.inject(ctx, "LinkSetVfSpoofchk"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetVfSpoofchk(ctx, link, vf, check)
+}
+
+// LinkSetVfTrust fails or runs netlink.LinkSetVfTrust
+func (n *FaultyNetlink) LinkSetVfTrust(ctx context.Context, link netlink.Link, vf int, state bool) error {
+	if err := n.faults.inject(ctx, "LinkSetVfTrust"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetVfTrust(ctx, link, vf, state)
+}
+
+// LinkSetVfState fails or runs netlink.LinkSetVfState
+func (n *FaultyNetlink) LinkSetVfState(ctx context.Context, link netlink.Link, vf int, state uint32) error {
+	if err := n.faults.inject(ctx, "LinkSetVfState"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetVfState(ctx, link, vf, state)
+}
+
+// BridgeVlanAdd fails or runs netlink.BridgeVlanAdd
+func (n *FaultyNetlink) BridgeVlanAdd(ctx context.Context, link netlink.Link, vid uint16, pvid bool, untagged bool, self bool, master bool) error {
+	if err := n.faults.inject(ctx, "BridgeVlanAdd"); err != nil {
+		return err
+	}
+	return n.Netlink.BridgeVlanAdd(ctx, link, vid, pvid, untagged, self, master)
+}
+
+// BridgeVlanDel fails or runs netlink.BridgeVlanDel
+func (n *FaultyNetlink) BridgeVlanDel(ctx context.Context, link netlink.Link, vid uint16, pvid bool, untagged bool, self bool, master bool) error {
+	if err := n.faults.inject(ctx, "BridgeVlanDel"); err != nil {
+		return err
+	}
+	return n.Netlink.BridgeVlanDel(ctx, link, vid, pvid, untagged, self, master)
+}
+
+// LinkSetMTU fails or runs netlink.LinkSetMTU
+func (n *FaultyNetlink) LinkSetMTU(ctx context.Context, link netlink.Link, mtu int) error {
+	if err := n.faults.inject(ctx, "LinkSetMTU"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetMTU(ctx, link, mtu)
+}
+
+// BridgeFdbAdd fails or runs netlink.BridgeFdbAdd
+func (n *FaultyNetlink) BridgeFdbAdd(ctx context.Context, link string, macAddress string) error {
+	if err := n.faults.inject(ctx, "BridgeFdbAdd"); err != nil {
+		return err
+	}
+	return n.Netlink.BridgeFdbAdd(ctx, link, macAddress)
+}
+
+// RouteAdd fails or runs netlink.RouteAdd
+func (n *FaultyNetlink) RouteAdd(ctx context.Context, route *netlink.Route) error {
+	if err := n.faults.inject(ctx, "RouteAdd"); err != nil {
+		return err
+	}
+	return n.Netlink.RouteAdd(ctx, route)
+}
+
+// RouteDel fails or runs netlink.RouteDel
+func (n *FaultyNetlink) RouteDel(ctx context.Context, route *netlink.Route) error {
+	if err := n.faults.inject(ctx, "RouteDel"); err != nil {
+		return err
+	}
+	return n.Netlink.RouteDel(ctx, route)
+}
+
+// RouteFlushTable fails or runs netlink.RouteFlushTable
+func (n *FaultyNetlink) RouteFlushTable(ctx context.Context, table string) error {
+	if err := n.faults.inject(ctx, "RouteFlushTable"); err != nil {
+		return err
+	}
+	return n.Netlink.RouteFlushTable(ctx, table)
+}
+
+// LinkSetBrNeighSuppress fails or runs netlink.LinkSetBrNeighSuppress
+func (n *FaultyNetlink) LinkSetBrNeighSuppress(ctx context.Context, link netlink.Link, suppress bool) error {
+	if err := n.faults.inject(ctx, "LinkSetBrNeighSuppress"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetBrNeighSuppress(ctx, link, suppress)
+}
+
+// LinkSetIsolated fails or runs netlink.LinkSetIsolated
+func (n *FaultyNetlink) LinkSetIsolated(ctx context.Context, link netlink.Link, isolated bool) error {
+	if err := n.faults.inject(ctx, "LinkSetIsolated"); err != nil {
+		return err
+	}
+	return n.Netlink.LinkSetIsolated(ctx, link, isolated)
+}
+
+// FaultyFrr fails a fraction of the commands sent to FRR
+type FaultyFrr struct {
+	Frr
+	faults *faultInjector
+}
+
+// NewFaultyFrr returns the FRR wrapper failing the frr fraction of the config
+func NewFaultyFrr(f Frr, cfg FaultConfig) *FaultyFrr {
+	return &FaultyFrr{Frr: f, faults: newFaultInjector(cfg.Frr, cfg)}
+}
+
+// build time check that struct implements interface
+var _ Frr = (*FaultyFrr)(nil)
+
+// FrrZebraCmd fails or runs the zebra command
+func (f *FaultyFrr) FrrZebraCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error) {
+	if err := f.faults.inject(ctx, "FrrZebraCmd"); err != nil {
+		return "", err
+	}
+	return f.Frr.FrrZebraCmd(ctx, command, cmdTypeShow)
+}
+
+// FrrBgpCmd fails or runs the bgpd command
+func (f *FaultyFrr) FrrBgpCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error) {
+	if err := f.faults.inject(ctx, "FrrBgpCmd"); err != nil {
+		return "", err
+	}
+	return f.Frr.FrrBgpCmd(ctx, command, cmdTypeShow)
+}
+
+// FrrLdpCmd fails or runs the ldpd command
+func (f *FaultyFrr) FrrLdpCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error) {
+	if err := f.faults.inject(ctx, "FrrLdpCmd"); err != nil {
+		return "", err
+	}
+	return f.Frr.FrrLdpCmd(ctx, command, cmdTypeShow)
+}
+
+// Save fails or saves the FRR configuration
+func (f *FaultyFrr) Save(ctx context.Context) error {
+	if err := f.faults.inject(ctx, "Save"); err != nil {
+		return err
+	}
+	return f.Frr.Save(ctx)
+}
+
+// TelnetDialAndCommunicate fails or runs the command on the vty port
+func (f *FaultyFrr) TelnetDialAndCommunicate(ctx context.Context, command string, port int) (string, error) {
+	if err := f.faults.inject(ctx, "TelnetDialAndCommunicate"); err != nil {
+		return "", err
+	}
+	return f.Frr.TelnetDialAndCommunicate(ctx, command, port)
+}
@@ -0,0 +1,11 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+//go:build faultinjection
+
+// Package utils has some utility functions and interfaces
+package utils
+
+// faultInjection lets the environment inject faults into the netlink and FRR operations
+const faultInjection = true
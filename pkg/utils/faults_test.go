@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vishvananda/netlink"
+)
+
+// countingNetlink counts the links added to it
+type countingNetlink struct {
+	Netlink
+	added int
+}
+
+func (c *countingNetlink) LinkAdd(_ context.Context, _ netlink.Link) error {
+	c.added++
+	return nil
+}
+
+// countingFrr counts the bgpd commands sent to it
+type countingFrr struct {
+	Frr
+	sent int
+}
+
+func (c *countingFrr) FrrBgpCmd(_ context.Context, _ string, _ bool) (string, error) {
+	c.sent++
+	return "", nil
+}
+
+func Test_ParseFaults(t *testing.T) {
+	cfg, err := ParseFaults("netlink=0.25, frr=1,hang=0.5,hangfor=2s,seed=7,ops=LinkAdd+FrrBgpCmd")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if cfg.Netlink != 0.25 || cfg.Frr != 1 || cfg.Hang != 0.5 || cfg.HangFor != 2*time.Second || cfg.Seed != 7 ||
+		len(cfg.Ops) != 2 || !cfg.Ops["LinkAdd"] || !cfg.Ops["FrrBgpCmd"] {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if cfg, _ := ParseFaults(""); cfg.HangFor != defaultHangFor || cfg.Netlink != 0 || cfg.Frr != 0 {
+		t.Errorf("expected an empty spec to inject nothing, received %+v", cfg)
+	}
+	for _, spec := range []string{"netlink=2", "frr=-0.1", "hang=x", "hangfor=5", "seed=a", "disk=0.1"} {
+		if _, err := ParseFaults(spec); err == nil {
+			t.Errorf("expected %q to be refused", spec)
+		}
+	}
+}
+
+func Test_FaultyNetlink(t *testing.T) {
+	inner := &countingNetlink{}
+	n := NewFaultyNetlink(inner, FaultConfig{Netlink: 0.5, Seed: 1})
+	failed := 0
+	for i := 0; i < 1000; i++ {
+		if err := n.LinkAdd(context.Background(), nil); err != nil {
+			if !errors.Is(err, ErrInjectedFault) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			failed++
+		}
+	}
+	if failed < 400 || failed > 600 {
+		t.Errorf("expected about half of the operations to fail, %d did", failed)
+	}
+	if inner.added != 1000-failed {
+		t.Errorf("expected the %d operations not failed to run, %d did", 1000-failed, inner.added)
+	}
+}
+
+func Test_FaultyNetlinkOps(t *testing.T) {
+	inner := &countingNetlink{}
+	n := NewFaultyNetlink(inner, FaultConfig{Netlink: 1, Ops: map[string]bool{"LinkDel": true}, Seed: 1})
+	if err := n.LinkAdd(context.Background(), nil); err != nil || inner.added != 1 {
+		t.Errorf("expected an operation not selected to run, received %v", err)
+	}
+}
+
+func Test_FaultyFrrHang(t *testing.T) {
+	inner := &countingFrr{}
+	f := NewFaultyFrr(inner, FaultConfig{Frr: 1, Hang: 1, HangFor: time.Hour, Seed: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := f.FrrBgpCmd(ctx, "show bgp summary", true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the hanging command to end with its context, received %v", err)
+	}
+	f = NewFaultyFrr(inner, FaultConfig{Frr: 1, Hang: 1, HangFor: time.Millisecond, Seed: 1})
+	if _, err := f.FrrBgpCmd(context.Background(), "show bgp summary", true); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("expected the hanging command to fail after the hang time, received %v", err)
+	}
+	if inner.sent != 0 {
+		t.Errorf("expected no failed command to reach FRR, %d did", inner.sent)
+	}
+}
+
+func Test_WithFaultsDisabled(t *testing.T) {
+	t.Setenv(FaultsEnv, "netlink=1,frr=1")
+	inner := &countingNetlink{}
+	n := WithNetlinkFaults(inner)
+	if _, wrapped := n.(*FaultyNetlink); wrapped != faultInjection {
+		t.Errorf("expected the netlink wrapper to be wrapped only in the faultinjection builds")
+	}
+	t.Setenv(FaultsEnv, "")
+	if n := WithNetlinkFaults(inner); n != Netlink(inner) {
+		t.Errorf("expected no wrapper without the environment")
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"net"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
@@ -53,6 +55,7 @@ type Frr interface {
 	TelnetDialAndCommunicate(ctx context.Context, command string, port int) (string, error)
 	FrrZebraCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error)
 	FrrBgpCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error)
+	FrrLdpCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error)
 	Save(context.Context) error
 	Password(conn *telnet.Conn, delim string) error
 	EnterPrivileged(conn *telnet.Conn) error
@@ -84,6 +87,33 @@ func NewFrrWrapperWithArgs(address string, enableTracer bool) *FrrWrapper {
 // build time check that struct implements interface
 var _ Frr = (*FrrWrapper)(nil)
 
+// FrrDaemons are the vty ports of the FRR daemons the bridge configures
+var FrrDaemons = map[string]int{"zebra": zebra, "bgpd": bgpd, "ldpd": ldpd}
+
+// FrrPing checks that the vty ports of zebra and bgpd accept connections
+func FrrPing(ctx context.Context, address string) error {
+	for _, daemon := range []string{"zebra", "bgpd"} {
+		if err := FrrDaemonPing(ctx, address, daemon); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FrrDaemonPing checks that the vty port of the FRR daemon accepts connections
+func FrrDaemonPing(ctx context.Context, address string, daemon string) error {
+	port, ok := FrrDaemons[daemon]
+	if !ok {
+		return fmt.Errorf("unknown FRR daemon %s", daemon)
+	}
+	dialer := net.Dialer{Timeout: timeout, Control: BindToDevice("")}
+	conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(address, strconv.Itoa(port)))
+	if err != nil {
+		return fmt.Errorf("%s: %w", daemon, err)
+	}
+	return conn.Close()
+}
+
 // Password handles password sending
 func (n *FrrWrapper) Password(conn *telnet.Conn, delim string) error {
 	err := conn.SkipUntil("Password: ")
@@ -158,6 +188,18 @@ func (n *FrrWrapper) FrrBgpCmd(ctx context.Context, command string, cmdTypeShow
 	return cmdOutput, cmdError
 }
 
+// FrrLdpCmd connects to Ldp telnet with password and runs command
+func (n *FrrWrapper) FrrLdpCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error) {
+	// ports defined here https://docs.frrouting.org/en/latest/setup.html#services
+	cmdOutput, cmdError := n.TelnetDialAndCommunicate(ctx, command, ldpd)
+	if cmdError != nil {
+		return "", cmdError
+	} else if checkFrrResult(cmdOutput, cmdTypeShow) {
+		return "", fmt.Errorf("%s", cmdOutput)
+	}
+	return cmdOutput, cmdError
+}
+
 // Save command save the current config to /etc/frr/frr.conf
 func (n *FrrWrapper) Save(ctx context.Context) error {
 	cmd := exec.CommandContext(ctx, "vtysh", "-c", "write")
@@ -201,12 +243,30 @@ func (n *FrrWrapper) TelnetDialAndCommunicate(ctx context.Context, command strin
 		)
 	}
 
-	// new connection every time
-	conn, err := telnet.DialTimeout(network, fmt.Sprintf("%s:%d", n.address, port), timeout)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	dialTimeout := timeout
+	deadline, hasDeadline := ctx.Deadline()
+	if hasDeadline && time.Until(deadline) < dialTimeout {
+		dialTimeout = time.Until(deadline)
+	}
+
+	// new connection every time, in the default vrf where the FRR daemons listen
+	dialer := net.Dialer{Timeout: dialTimeout, Control: BindToDevice("")}
+	tcpConn, err := dialer.DialContext(ctx, network, net.JoinHostPort(n.address, strconv.Itoa(port)))
+	if err != nil {
+		return "", err
+	}
+	conn, err := telnet.NewConn(tcpConn)
 	if err != nil {
+		_ = tcpConn.Close()
 		return "", err
 	}
 	defer func(t *telnet.Conn) { _ = t.Close() }(conn)
+	// closing the connection aborts the command when the caller gives up
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
 
 	conn.SetUnixWriteMode(true)
 
@@ -214,6 +274,11 @@ func (n *FrrWrapper) TelnetDialAndCommunicate(ctx context.Context, command strin
 	if err != nil {
 		return "", err
 	}
+	if hasDeadline {
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			return "", err
+		}
+	}
 
 	err = n.Password(conn, ">")
 	if err != nil {
@@ -225,5 +290,9 @@ func (n *FrrWrapper) TelnetDialAndCommunicate(ctx context.Context, command strin
 		return "", err
 	}
 
-	return n.MultiLineCmd(conn, command)
+	output, err := n.MultiLineCmd(conn, command)
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return output, ctxErr
+	}
+	return output, err
 }
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+
+	"go.einride.tech/aip/fieldmask"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
+)
+
+func FuzzValidateMacAddress(f *testing.F) {
+	for _, mac := range [][]byte{{0xcb, 0xb8, 0x33, 0x4c, 0x88, 0x4f}, {}, {0xaa}, []byte("aa:bb:cc:dd:ee:ff"), make([]byte, 20)} {
+		f.Add(mac)
+	}
+	f.Fuzz(func(t *testing.T, mac []byte) {
+		if err := ValidateMacAddress(mac); err != nil {
+			if len(mac) == 6 {
+				t.Fatalf("expected %x to be accepted, received %v", mac, err)
+			}
+			return
+		}
+		parsed, err := net.ParseMAC(net.HardwareAddr(mac).String())
+		if err != nil || !bytes.Equal(parsed, mac) {
+			t.Errorf("expected %x to be an EUI-48 address, received %v, %v", mac, parsed, err)
+		}
+	})
+}
+
+// fuzzSvi is the stored svi which the fuzzed masks update
+func fuzzSvi(vrf string, mac []byte, gwLen int32) *pb.Svi {
+	return &pb.Svi{
+		Name: "//network.opiproject.org/svis/s1",
+		Spec: &pb.SviSpec{
+			Vrf:           vrf,
+			LogicalBridge: "//network.opiproject.org/bridges/b1",
+			MacAddress:    mac,
+			GwIpPrefix:    []*pc.IPPrefix{{Addr: &pc.IPAddress{Af: pc.IpAf_IP_AF_INET, V4OrV6: &pc.IPAddress_V4Addr{V4Addr: 167772162}}, Len: gwLen}},
+			EnableBgp:     true,
+			RemoteAs:      65000,
+		},
+	}
+}
+
+func FuzzApplyMaskToStoredPbObject(f *testing.F) {
+	for _, paths := range []string{"spec.vrf", "spec", "*", "spec.gw_ip_prefix,spec.mac_address", "spec.gw_ip_prefix.len", "", "name,status", "spec..vrf", "spec.*"} {
+		f.Add(paths, "//network.opiproject.org/vrfs/red", []byte{0xaa, 0xbb, 0xcc, 0, 0, 1}, int32(16))
+	}
+	f.Fuzz(func(t *testing.T, paths string, vrf string, mac []byte, gwLen int32) {
+		mask := &fieldmaskpb.FieldMask{}
+		if paths != "" {
+			mask.Paths = strings.Split(paths, ",")
+		}
+		src := fuzzSvi(vrf, mac, gwLen)
+		if fieldmask.Validate(mask, src) != nil {
+			return
+		}
+		dst := fuzzSvi("//network.opiproject.org/vrfs/blue", []byte{0xaa, 0xbb, 0xcc, 0, 0, 2}, 24)
+		ApplyMaskToStoredPbObject(mask, dst, src)
+		for _, p := range mask.Paths {
+			if p == "spec.vrf" && dst.Spec.Vrf != vrf {
+				t.Errorf("expected the mask %q to update the vrf to %q, received %q", paths, vrf, dst.Spec.Vrf)
+			}
+		}
+	})
+}
@@ -65,8 +65,9 @@ func (ip *IDPool) GetPoolStatus() string {
 func (ip *IDPool) assignid(key interface{}) uint32 {
 	// Check if there was an id assigned for that key earlier
 	var id uint32
-	if _, ok := ip.idsForReuse[key]; ok {
+	if old, ok := ip.idsForReuse[key]; ok {
 		// Re-use the old id
+		id = old
 		delete(ip.idsForReuse, key)
 	} else {
 		if len(ip.unusedIDs) != 0 {
@@ -76,7 +77,8 @@ func (ip *IDPool) assignid(key interface{}) uint32 {
 		} else {
 			if len(ip.idsForReuse) != 0 {
 				// Pick one of the ids earlier used for another key
-				for oldKey := range ip.idsForReuse {
+				for oldKey, old := range ip.idsForReuse {
+					id = old
 					delete(ip.idsForReuse, oldKey)
 					break
 				}
@@ -170,3 +172,32 @@ func (ip *IDPool) ReleaseIDWithRef(key interface{}, ref interface{}) (uint32, ui
 	}
 	return id, uint32(0)
 }
+
+// Reserve assigns the given id to the key, e.g. the id of an object adopted from the kernel, so that it
+// is not handed out to another key. An id in use by another key is refused.
+func (ip *IDPool) Reserve(key interface{}, id uint32) bool {
+	if inUse, ok := ip.idsInUse[key]; ok {
+		return inUse == id
+	}
+	for other, inUse := range ip.idsInUse {
+		if inUse == id {
+			log.Printf("IDPool: Failed to reserve id %v for key %v, it is in use by %v", id, key, other)
+			return false
+		}
+	}
+	for i, unused := range ip.unusedIDs {
+		if unused == id {
+			ip.unusedIDs = append(ip.unusedIDs[:i], ip.unusedIDs[i+1:]...)
+			break
+		}
+	}
+	for other, old := range ip.idsForReuse {
+		if old == id {
+			delete(ip.idsForReuse, other)
+		}
+	}
+	delete(ip.idsForReuse, key)
+	ip.idsInUse[key] = id
+	log.Printf("IDPool: Reserved id %v for key %v", id, key)
+	return true
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import "testing"
+
+func Test_IDPoolReuse(t *testing.T) {
+	pool, _ := IDPoolInit("test", 1, 2)
+	a, b := pool.GetID("a"), pool.GetID("b")
+	if a != 1 || b != 2 {
+		t.Fatalf("expected the ids 1 and 2, received %d and %d", a, b)
+	}
+	pool.ReleaseID("a")
+	if id := pool.GetID("a"); id != a {
+		t.Errorf("expected a released key to get its id back, received %d", id)
+	}
+	pool.ReleaseID("b")
+	if id := pool.GetID("c"); id != b {
+		t.Errorf("expected a new key to recycle the released id %d, received %d", b, id)
+	}
+	if id := pool.GetID("d"); id != 0 {
+		t.Errorf("expected the exhausted pool to refuse a new key, received %d", id)
+	}
+}
+
+func Test_IDPoolReserve(t *testing.T) {
+	pool, _ := IDPoolInit("test", 1, 3)
+	if !pool.Reserve("adopted", 1) {
+		t.Fatal("expected a free id to be reserved")
+	}
+	if id := pool.GetID("adopted"); id != 1 {
+		t.Errorf("expected the key to keep its reserved id, received %d", id)
+	}
+	if id := pool.GetID("a"); id != 2 {
+		t.Errorf("expected the reserved id to be skipped, received %d", id)
+	}
+	if pool.Reserve("b", 2) {
+		t.Error("expected an id in use by another key to be refused")
+	}
+	if !pool.Reserve("outside", 100) {
+		t.Error("expected an id outside of the pool to be reserved")
+	}
+}
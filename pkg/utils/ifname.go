@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils contains utility functions
+package utils
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// IfNameSize is the longest kernel interface name, IFNAMSIZ without the terminating NUL
+const IfNameSize = 15
+
+// HashedIfName returns a name of at most IfNameSize characters made of the beginning of the preferred name
+// and of a hash of the owner key. The attempt salts the hash when the previous names collided.
+func HashedIfName(preferred, key string, attempt int) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	if attempt > 0 {
+		_, _ = fmt.Fprintf(h, "#%d", attempt)
+	}
+	suffix := fmt.Sprintf("-%06x", h.Sum32()&0xffffff)
+	keep := IfNameSize - len(suffix)
+	if keep > len(preferred) {
+		keep = len(preferred)
+	}
+	return strings.TrimRight(preferred[:keep], "-") + suffix
+}
@@ -13,9 +13,21 @@ import (
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 )
 
-// InterceptorLogger creates logger for interceptors based on default Go logger
-func InterceptorLogger(l *log.Logger) logging.Logger {
+// logLevels maps the log levels of the config onto the interceptor levels
+var logLevels = map[string]logging.Level{
+	"debug": logging.LevelDebug,
+	"info":  logging.LevelInfo,
+	"warn":  logging.LevelWarn,
+	"error": logging.LevelError,
+}
+
+// InterceptorLogger creates logger for interceptors based on default Go logger,
+// minLevel is called for every message so that the level can be changed at runtime, an empty level logs everything
+func InterceptorLogger(l *log.Logger, minLevel func() string) logging.Logger {
 	return logging.LoggerFunc(func(_ context.Context, lvl logging.Level, msg string, fields ...any) {
+		if lvl < logLevels[minLevel()] {
+			return
+		}
 		switch lvl {
 		case logging.LevelDebug:
 			msg = fmt.Sprintf("DEBUG :%v", msg)
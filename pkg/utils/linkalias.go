@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"strings"
+)
+
+// linkAliasPrefix marks the alias of the kernel devices created by the bridge
+const linkAliasPrefix = "opi-evpn-bridge:"
+
+// LinkAlias returns the alias of a device created for the resource, the static devices
+// which are not owned by a resource have an empty owner
+func LinkAlias(owner string) string {
+	return linkAliasPrefix + owner
+}
+
+// LinkAliasOwner returns the resource encoded in the alias of a device, ok is false
+// for the devices which have not been created by the bridge
+func LinkAliasOwner(alias string) (owner string, ok bool) {
+	return strings.CutPrefix(alias, linkAliasPrefix)
+}
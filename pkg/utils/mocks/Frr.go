@@ -175,6 +175,64 @@ func (_c *Frr_FrrBgpCmd_Call) RunAndReturn(run func(context.Context, string, boo
 	return _c
 }
 
+// FrrLdpCmd provides a mock function with given fields: ctx, command, cmdTypeShow
+func (_m *Frr) FrrLdpCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error) {
+	ret := _m.Called(ctx, command, cmdTypeShow)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FrrLdpCmd")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (string, error)); ok {
+		return rf(ctx, command, cmdTypeShow)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) string); ok {
+		r0 = rf(ctx, command, cmdTypeShow)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, command, cmdTypeShow)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Frr_FrrLdpCmd_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FrrLdpCmd'
+type Frr_FrrLdpCmd_Call struct {
+	*mock.Call
+}
+
+// FrrLdpCmd is a helper method to define mock.On call
+//   - ctx context.Context
+//   - command string
+//   - cmdTypeShow bool
+func (_e *Frr_Expecter) FrrLdpCmd(ctx interface{}, command interface{}, cmdTypeShow interface{}) *Frr_FrrLdpCmd_Call {
+	return &Frr_FrrLdpCmd_Call{Call: _e.mock.On("FrrLdpCmd", ctx, command, cmdTypeShow)}
+}
+
+func (_c *Frr_FrrLdpCmd_Call) Run(run func(ctx context.Context, command string, cmdTypeShow bool)) *Frr_FrrLdpCmd_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *Frr_FrrLdpCmd_Call) Return(_a0 string, _a1 error) *Frr_FrrLdpCmd_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Frr_FrrLdpCmd_Call) RunAndReturn(run func(context.Context, string, bool) (string, error)) *Frr_FrrLdpCmd_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // FrrZebraCmd provides a mock function with given fields: ctx, command, cmdTypeShow
 func (_m *Frr) FrrZebraCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error) {
 	ret := _m.Called(ctx, command, cmdTypeShow)
@@ -488,6 +488,64 @@ func (_c *Netlink_LinkDel_Call) RunAndReturn(run func(context.Context, netlink.L
 	return _c
 }
 
+// LinkList provides a mock function with given fields: _a0
+func (_m *Netlink) LinkList(_a0 context.Context) ([]netlink.Link, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LinkList")
+	}
+
+	var r0 []netlink.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]netlink.Link, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []netlink.Link); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]netlink.Link)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Netlink_LinkList_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LinkList'
+type Netlink_LinkList_Call struct {
+	*mock.Call
+}
+
+// LinkList is a helper method to define mock.On call
+//   - _a0 context.Context
+func (_e *Netlink_Expecter) LinkList(_a0 interface{}) *Netlink_LinkList_Call {
+	return &Netlink_LinkList_Call{Call: _e.mock.On("LinkList", _a0)}
+}
+
+func (_c *Netlink_LinkList_Call) Run(run func(_a0 context.Context)) *Netlink_LinkList_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Netlink_LinkList_Call) Return(_a0 []netlink.Link, _a1 error) *Netlink_LinkList_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Netlink_LinkList_Call) RunAndReturn(run func(context.Context) ([]netlink.Link, error)) *Netlink_LinkList_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // LinkModify provides a mock function with given fields: _a0, _a1
 func (_m *Netlink) LinkModify(_a0 context.Context, _a1 netlink.Link) error {
 	ret := _m.Called(_a0, _a1)
@@ -535,6 +593,54 @@ func (_c *Netlink_LinkModify_Call) RunAndReturn(run func(context.Context, netlin
 	return _c
 }
 
+// LinkSetAlias provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Netlink) LinkSetAlias(_a0 context.Context, _a1 netlink.Link, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LinkSetAlias")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, netlink.Link, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Netlink_LinkSetAlias_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LinkSetAlias'
+type Netlink_LinkSetAlias_Call struct {
+	*mock.Call
+}
+
+// LinkSetAlias is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 netlink.Link
+//   - _a2 string
+func (_e *Netlink_Expecter) LinkSetAlias(_a0 interface{}, _a1 interface{}, _a2 interface{}) *Netlink_LinkSetAlias_Call {
+	return &Netlink_LinkSetAlias_Call{Call: _e.mock.On("LinkSetAlias", _a0, _a1, _a2)}
+}
+
+func (_c *Netlink_LinkSetAlias_Call) Run(run func(_a0 context.Context, _a1 netlink.Link, _a2 string)) *Netlink_LinkSetAlias_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(netlink.Link), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Netlink_LinkSetAlias_Call) Return(_a0 error) *Netlink_LinkSetAlias_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Netlink_LinkSetAlias_Call) RunAndReturn(run func(context.Context, netlink.Link, string) error) *Netlink_LinkSetAlias_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // LinkSetBrNeighSuppress provides a mock function with given fields: _a0, _a1, _a2
 func (_m *Netlink) LinkSetBrNeighSuppress(_a0 context.Context, _a1 netlink.Link, _a2 bool) error {
 	ret := _m.Called(_a0, _a1, _a2)
@@ -678,6 +784,54 @@ func (_c *Netlink_LinkSetHardwareAddr_Call) RunAndReturn(run func(context.Contex
 	return _c
 }
 
+// LinkSetIsolated provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Netlink) LinkSetIsolated(_a0 context.Context, _a1 netlink.Link, _a2 bool) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LinkSetIsolated")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, netlink.Link, bool) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Netlink_LinkSetIsolated_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LinkSetIsolated'
+type Netlink_LinkSetIsolated_Call struct {
+	*mock.Call
+}
+
+// LinkSetIsolated is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 netlink.Link
+//   - _a2 bool
+func (_e *Netlink_Expecter) LinkSetIsolated(_a0 interface{}, _a1 interface{}, _a2 interface{}) *Netlink_LinkSetIsolated_Call {
+	return &Netlink_LinkSetIsolated_Call{Call: _e.mock.On("LinkSetIsolated", _a0, _a1, _a2)}
+}
+
+func (_c *Netlink_LinkSetIsolated_Call) Run(run func(_a0 context.Context, _a1 netlink.Link, _a2 bool)) *Netlink_LinkSetIsolated_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(netlink.Link), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *Netlink_LinkSetIsolated_Call) Return(_a0 error) *Netlink_LinkSetIsolated_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Netlink_LinkSetIsolated_Call) RunAndReturn(run func(context.Context, netlink.Link, bool) error) *Netlink_LinkSetIsolated_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // LinkSetMTU provides a mock function with given fields: _a0, _a1, _a2
 func (_m *Netlink) LinkSetMTU(_a0 context.Context, _a1 netlink.Link, _a2 int) error {
 	ret := _m.Called(_a0, _a1, _a2)
@@ -1210,9 +1364,9 @@ func (_c *Netlink_LinkSetVfTrust_Call) RunAndReturn(run func(context.Context, ne
 	return _c
 }
 
-// ReadFDB provides a mock function with given fields: _a0
-func (_m *Netlink) ReadFDB(_a0 context.Context) (string, error) {
-	ret := _m.Called(_a0)
+// ReadFDB provides a mock function with given fields: _a0, _a1
+func (_m *Netlink) ReadFDB(_a0 context.Context, _a1 string) (string, error) {
+	ret := _m.Called(_a0, _a1)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ReadFDB")
@@ -1220,17 +1374,17 @@ func (_m *Netlink) ReadFDB(_a0 context.Context) (string, error) {
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) (string, error)); ok {
-		return rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return rf(_a0, _a1)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) string); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1245,13 +1399,14 @@ type Netlink_ReadFDB_Call struct {
 
 // ReadFDB is a helper method to define mock.On call
 //   - _a0 context.Context
-func (_e *Netlink_Expecter) ReadFDB(_a0 interface{}) *Netlink_ReadFDB_Call {
-	return &Netlink_ReadFDB_Call{Call: _e.mock.On("ReadFDB", _a0)}
+//   - _a1 string
+func (_e *Netlink_Expecter) ReadFDB(_a0 interface{}, _a1 interface{}) *Netlink_ReadFDB_Call {
+	return &Netlink_ReadFDB_Call{Call: _e.mock.On("ReadFDB", _a0, _a1)}
 }
 
-func (_c *Netlink_ReadFDB_Call) Run(run func(_a0 context.Context)) *Netlink_ReadFDB_Call {
+func (_c *Netlink_ReadFDB_Call) Run(run func(_a0 context.Context, _a1 string)) *Netlink_ReadFDB_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -1261,7 +1416,7 @@ func (_c *Netlink_ReadFDB_Call) Return(_a0 string, _a1 error) *Netlink_ReadFDB_C
 	return _c
 }
 
-func (_c *Netlink_ReadFDB_Call) RunAndReturn(run func(context.Context) (string, error)) *Netlink_ReadFDB_Call {
+func (_c *Netlink_ReadFDB_Call) RunAndReturn(run func(context.Context, string) (string, error)) *Netlink_ReadFDB_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1427,6 +1582,53 @@ func (_c *Netlink_RouteAdd_Call) RunAndReturn(run func(context.Context, *netlink
 	return _c
 }
 
+// RouteDel provides a mock function with given fields: _a0, _a1
+func (_m *Netlink) RouteDel(_a0 context.Context, _a1 *netlink.Route) error {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RouteDel")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *netlink.Route) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Netlink_RouteDel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RouteDel'
+type Netlink_RouteDel_Call struct {
+	*mock.Call
+}
+
+// RouteDel is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 *netlink.Route
+func (_e *Netlink_Expecter) RouteDel(_a0 interface{}, _a1 interface{}) *Netlink_RouteDel_Call {
+	return &Netlink_RouteDel_Call{Call: _e.mock.On("RouteDel", _a0, _a1)}
+}
+
+func (_c *Netlink_RouteDel_Call) Run(run func(_a0 context.Context, _a1 *netlink.Route)) *Netlink_RouteDel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*netlink.Route))
+	})
+	return _c
+}
+
+func (_c *Netlink_RouteDel_Call) Return(_a0 error) *Netlink_RouteDel_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Netlink_RouteDel_Call) RunAndReturn(run func(context.Context, *netlink.Route) error) *Netlink_RouteDel_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RouteFlushTable provides a mock function with given fields: _a0, _a1
 func (_m *Netlink) RouteFlushTable(_a0 context.Context, _a1 string) error {
 	ret := _m.Called(_a0, _a1)
@@ -12,6 +12,7 @@ import (
 	"net"
 
 	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
 
 	"errors"
 
@@ -38,6 +39,8 @@ type Netlink interface {
 	LinkSetNoMaster(context.Context, netlink.Link) error
 	LinkSetNsFd(context.Context, netlink.Link, int) error
 	LinkSetName(context.Context, netlink.Link, string) error
+	LinkSetAlias(context.Context, netlink.Link, string) error
+	LinkList(context.Context) ([]netlink.Link, error)
 	LinkSetVfRate(context.Context, netlink.Link, int, int, int) error
 	LinkSetVfSpoofchk(context.Context, netlink.Link, int, bool) error
 	LinkSetVfTrust(context.Context, netlink.Link, int, bool) error
@@ -47,19 +50,26 @@ type Netlink interface {
 	LinkSetMTU(context.Context, netlink.Link, int) error
 	BridgeFdbAdd(context.Context, string, string) error
 	RouteAdd(context.Context, *netlink.Route) error
+	RouteDel(context.Context, *netlink.Route) error
 	RouteListFiltered(context.Context, int, *netlink.Route, uint64) ([]netlink.Route, error)
 	RouteFlushTable(context.Context, string) error
 	RouteListIPTable(context.Context, string) bool
 	LinkSetBrNeighSuppress(context.Context, netlink.Link, bool) error
+	LinkSetIsolated(context.Context, netlink.Link, bool) error
 	ReadNeigh(context.Context, string) (string, error)
 	ReadRoute(context.Context, string) (string, error)
-	ReadFDB(context.Context) (string, error)
+	ReadFDB(context.Context, string) (string, error)
 	RouteLookup(context.Context, string, string) (string, error)
 }
 
-// NetlinkWrapper wrapper for netlink package
+// NetlinkWrapper wrapper for netlink package. The calls changing the kernel state are not
+// issued once their context is done, so that an abandoned operation stops at the next step.
 type NetlinkWrapper struct {
 	tracer trace.Tracer
+	// handle issues the netlink requests in the namespace of the wrapper
+	handle *netlink.Handle
+	// namespace is the named network namespace of the wrapper, empty for the one of the bridge
+	namespace string
 }
 
 // NewNetlinkWrapper creates initialized instance of NetlinkWrapper
@@ -70,7 +80,7 @@ func NewNetlinkWrapper() *NetlinkWrapper {
 // NewNetlinkWrapperWithArgs creates initialized instance of NetlinkWrapper
 // based on passing arguments
 func NewNetlinkWrapperWithArgs(enableTracer bool) *NetlinkWrapper {
-	netlinkWrapper := &NetlinkWrapper{}
+	netlinkWrapper := &NetlinkWrapper{handle: &netlink.Handle{}}
 	netlinkWrapper.tracer = noop.NewTracerProvider().Tracer("")
 	if enableTracer {
 		netlinkWrapper.tracer = otel.Tracer("")
@@ -78,16 +88,43 @@ func NewNetlinkWrapperWithArgs(enableTracer bool) *NetlinkWrapper {
 	return netlinkWrapper
 }
 
+// NewNetlinkWrapperAt creates initialized instance of NetlinkWrapper issuing its requests
+// in the named network namespace
+func NewNetlinkWrapperAt(namespace string, enableTracer bool) (*NetlinkWrapper, error) {
+	ns, err := netns.GetFromName(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("network namespace %s: %w", namespace, err)
+	}
+	// the sockets of the handle stay in the namespace once its descriptor is closed
+	defer func() { _ = ns.Close() }()
+	handle, err := netlink.NewHandleAt(ns)
+	if err != nil {
+		return nil, fmt.Errorf("network namespace %s: %w", namespace, err)
+	}
+	netlinkWrapper := NewNetlinkWrapperWithArgs(enableTracer)
+	netlinkWrapper.handle = handle
+	netlinkWrapper.namespace = namespace
+	return netlinkWrapper, nil
+}
+
 // build time check that struct implements interface
 var _ Netlink = (*NetlinkWrapper)(nil)
 
+// command returns the ip or bridge command run in the namespace of the wrapper
+func (n *NetlinkWrapper) command(tool string, args ...string) []string {
+	if n.namespace == "" {
+		return append([]string{tool}, args...)
+	}
+	return append([]string{tool, "-n", n.namespace}, args...)
+}
+
 // LinkByName is a wrapper for netlink.LinkByName
 func (n *NetlinkWrapper) LinkByName(ctx context.Context, name string) (netlink.Link, error) {
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkByName")
 	childSpan.SetAttributes(attribute.String("link.name", name))
 	defer childSpan.End()
 
-	return netlink.LinkByName(name)
+	return n.handle.LinkByName(name)
 }
 
 // LinkModify is a wrapper for netlink.LinkModify
@@ -95,7 +132,10 @@ func (n *NetlinkWrapper) LinkModify(ctx context.Context, link netlink.Link) erro
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkModify")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkModify(link)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkModify(link)
 }
 
 // LinkSetHardwareAddr is a wrapper for netlink.LinkSetHardwareAddr
@@ -103,7 +143,10 @@ func (n *NetlinkWrapper) LinkSetHardwareAddr(ctx context.Context, link netlink.L
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetHardwareAddr")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetHardwareAddr(link, hwaddr)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetHardwareAddr(link, hwaddr)
 }
 
 // LinkSetVfHardwareAddr is a wrapper for netlink.LinkSetVfHardwareAddr
@@ -111,7 +154,10 @@ func (n *NetlinkWrapper) LinkSetVfHardwareAddr(ctx context.Context, link netlink
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetVfHardwareAddrr")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetVfHardwareAddr(link, vf, hwaddr)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetVfHardwareAddr(link, vf, hwaddr)
 }
 
 // AddrAdd is a wrapper for netlink.AddrAdd
@@ -119,7 +165,10 @@ func (n *NetlinkWrapper) AddrAdd(ctx context.Context, link netlink.Link, addr *n
 	_, childSpan := n.tracer.Start(ctx, "netlink.AddrAdd")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.AddrAdd(link, addr)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.AddrAdd(link, addr)
 }
 
 // AddrDel is a wrapper for netlink.AddrDel
@@ -127,7 +176,10 @@ func (n *NetlinkWrapper) AddrDel(ctx context.Context, link netlink.Link, addr *n
 	_, childSpan := n.tracer.Start(ctx, "netlink.AddrDel")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.AddrDel(link, addr)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.AddrDel(link, addr)
 }
 
 // AddrList is a wrapper for netlink.AddrList
@@ -135,7 +187,7 @@ func (n *NetlinkWrapper) AddrList(ctx context.Context, link netlink.Link, family
 	_, childSpan := n.tracer.Start(ctx, "netlink.AddrList")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.AddrList(link, family)
+	return n.handle.AddrList(link, family)
 }
 
 // LinkAdd is a wrapper for netlink.LinkAdd
@@ -143,7 +195,10 @@ func (n *NetlinkWrapper) LinkAdd(ctx context.Context, link netlink.Link) error {
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkAdd")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkAdd(link)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkAdd(link)
 }
 
 // LinkDel is a wrapper for netlink.LinkDel
@@ -151,7 +206,10 @@ func (n *NetlinkWrapper) LinkDel(ctx context.Context, link netlink.Link) error {
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkDel")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkDel(link)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkDel(link)
 }
 
 // LinkSetUp is a wrapper for netlink.LinkSetUp
@@ -159,7 +217,10 @@ func (n *NetlinkWrapper) LinkSetUp(ctx context.Context, link netlink.Link) error
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetUp")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetUp(link)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetUp(link)
 }
 
 // LinkSetMTU is a wrapper for netlink.LinkSetUp
@@ -167,7 +228,10 @@ func (n *NetlinkWrapper) LinkSetMTU(ctx context.Context, link netlink.Link, mtu
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetMTU")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetMTU(link, mtu)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetMTU(link, mtu)
 }
 
 // LinkSetDown is a wrapper for netlink.LinkSetDown
@@ -175,7 +239,10 @@ func (n *NetlinkWrapper) LinkSetDown(ctx context.Context, link netlink.Link) err
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetDown")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetDown(link)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetDown(link)
 }
 
 // LinkSetMaster is a wrapper for netlink.LinkSetMaster
@@ -183,7 +250,10 @@ func (n *NetlinkWrapper) LinkSetMaster(ctx context.Context, link, master netlink
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetMaster")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetMaster(link, master)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetMaster(link, master)
 }
 
 // LinkSetNoMaster is a wrapper for netlink.LinkSetNoMaster
@@ -191,7 +261,10 @@ func (n *NetlinkWrapper) LinkSetNoMaster(ctx context.Context, link netlink.Link)
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetNoMaster")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetNoMaster(link)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetNoMaster(link)
 }
 
 // LinkSetNsFd is a wrapper for netlink.LinkSetNsFd
@@ -199,7 +272,10 @@ func (n *NetlinkWrapper) LinkSetNsFd(ctx context.Context, link netlink.Link, fd
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetNsFd")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetNsFd(link, fd)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetNsFd(link, fd)
 }
 
 // LinkSetName is a wrapper for netlink.LinkSetName
@@ -207,7 +283,28 @@ func (n *NetlinkWrapper) LinkSetName(ctx context.Context, link netlink.Link, nam
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetName")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetName(link, name)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetName(link, name)
+}
+
+// LinkSetAlias is a wrapper for netlink.LinkSetAlias
+func (n *NetlinkWrapper) LinkSetAlias(ctx context.Context, link netlink.Link, alias string) error {
+	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetAlias")
+	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
+	defer childSpan.End()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetAlias(link, alias)
+}
+
+// LinkList is a wrapper for netlink.LinkList
+func (n *NetlinkWrapper) LinkList(ctx context.Context) ([]netlink.Link, error) {
+	_, childSpan := n.tracer.Start(ctx, "netlink.LinkList")
+	defer childSpan.End()
+	return n.handle.LinkList()
 }
 
 // LinkSetVfRate is a wrapper for netlink.LinkSetVfRate
@@ -215,7 +312,10 @@ func (n *NetlinkWrapper) LinkSetVfRate(ctx context.Context, link netlink.Link, v
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetVfRate")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetVfRate(link, vf, minRate, maxRate)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetVfRate(link, vf, minRate, maxRate)
 }
 
 // LinkSetVfSpoofchk is a wrapper for netlink.LinkSetVfSpoofchk
@@ -223,7 +323,10 @@ func (n *NetlinkWrapper) LinkSetVfSpoofchk(ctx context.Context, link netlink.Lin
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetVfSpoofchk")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetVfSpoofchk(link, vf, check)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetVfSpoofchk(link, vf, check)
 }
 
 // LinkSetVfTrust is a wrapper for netlink.LinkSetVfTrust
@@ -231,7 +334,10 @@ func (n *NetlinkWrapper) LinkSetVfTrust(ctx context.Context, link netlink.Link,
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetVfTrust")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetVfTrust(link, vf, state)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetVfTrust(link, vf, state)
 }
 
 // LinkSetVfState is a wrapper for netlink.LinkSetVfState
@@ -239,7 +345,10 @@ func (n *NetlinkWrapper) LinkSetVfState(ctx context.Context, link netlink.Link,
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetVfState")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetVfState(link, vf, state)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetVfState(link, vf, state)
 }
 
 // BridgeVlanAdd is a wrapper for netlink.BridgeVlanAdd
@@ -247,7 +356,10 @@ func (n *NetlinkWrapper) BridgeVlanAdd(ctx context.Context, link netlink.Link, v
 	_, childSpan := n.tracer.Start(ctx, "netlink.BridgeVlanAdd")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.BridgeVlanAdd(link, vid, pvid, untagged, self, master)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.BridgeVlanAdd(link, vid, pvid, untagged, self, master)
 }
 
 // BridgeVlanDel is a wrapper for netlink.BridgeVlanDel
@@ -255,7 +367,10 @@ func (n *NetlinkWrapper) BridgeVlanDel(ctx context.Context, link netlink.Link, v
 	_, childSpan := n.tracer.Start(ctx, "netlink.BridgeVlanDel")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.BridgeVlanDel(link, vid, pvid, untagged, self, master)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.BridgeVlanDel(link, vid, pvid, untagged, self, master)
 }
 
 // RouteListFiltered is a wrapper for netlink.RouteListFiltered
@@ -264,21 +379,38 @@ func (n *NetlinkWrapper) RouteListFiltered(ctx context.Context, family int, rout
 	//	link,_:=netlink.LinkByIndex(route.LinkIndex)
 	childSpan.SetAttributes(attribute.String("route.LinkIndex", string(rune(route.LinkIndex))))
 	defer childSpan.End()
-	return netlink.RouteListFiltered(family, route, filter)
+	return n.handle.RouteListFiltered(family, route, filter)
 }
 
 // RouteAdd is a wrapper for netlink.RouteAdd
 func (n *NetlinkWrapper) RouteAdd(ctx context.Context, route *netlink.Route) error {
 	_, childSpan := n.tracer.Start(ctx, "netlink.RouteAdd")
-	_, _ = netlink.LinkByIndex(route.LinkIndex)
+	_, _ = n.handle.LinkByIndex(route.LinkIndex)
 	childSpan.SetAttributes(attribute.String("route.LinkIndex", string(rune(route.LinkIndex))))
 	defer childSpan.End()
-	return netlink.RouteAdd(route)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.RouteAdd(route)
+}
+
+// RouteDel is a wrapper for netlink.RouteDel
+func (n *NetlinkWrapper) RouteDel(ctx context.Context, route *netlink.Route) error {
+	_, childSpan := n.tracer.Start(ctx, "netlink.RouteDel")
+	childSpan.SetAttributes(attribute.Int("route.Table", route.Table))
+	defer childSpan.End()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.RouteDel(route)
 }
 
 // RouteFlushTable is a wrapper for netlink.RouteFlushTable
-func (n *NetlinkWrapper) RouteFlushTable(_ context.Context, routingTable string) error {
-	_, err := Run([]string{"ip", "route", "flush", "table", routingTable}, false)
+func (n *NetlinkWrapper) RouteFlushTable(ctx context.Context, routingTable string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := Run(n.command("ip", "route", "flush", "table", routingTable), false)
 	if err != 0 {
 		return fmt.Errorf("lgm: Error in executing command ip route flush table %s", routingTable)
 	}
@@ -287,13 +419,16 @@ func (n *NetlinkWrapper) RouteFlushTable(_ context.Context, routingTable string)
 
 // RouteListIPTable is a wrapper for netlink.RouteListIPTable
 func (n *NetlinkWrapper) RouteListIPTable(_ context.Context, vtip string) bool {
-	_, err := Run([]string{"ip", "route", "list", "exact", vtip, "table", "local"}, false)
+	_, err := Run(n.command("ip", "route", "list", "exact", vtip, "table", "local"), false)
 	return err == 0
 }
 
 // BridgeFdbAdd is a wrapper for netlink.BridgeFdbAdd
-func (n *NetlinkWrapper) BridgeFdbAdd(_ context.Context, link string, macAddress string) error {
-	_, err := Run([]string{"bridge", "fdb", "add", macAddress, "dev", link, "master", "static", "extern_learn"}, false)
+func (n *NetlinkWrapper) BridgeFdbAdd(ctx context.Context, link string, macAddress string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := Run(n.command("bridge", "fdb", "add", macAddress, "dev", link, "master", "static", "extern_learn"), false)
 	if err != 0 {
 		return errors.New("failed to add fdb entry")
 	}
@@ -305,9 +440,9 @@ func (n *NetlinkWrapper) ReadNeigh(_ context.Context, link string) (string, erro
 	var out string
 	var err int
 	if link == "" {
-		out, err = Run([]string{"ip", "-j", "-d", "neighbor", "show"}, false)
+		out, err = Run(n.command("ip", "-j", "-d", "neighbor", "show"), false)
 	} else {
-		out, err = Run([]string{"ip", "-j", "-d", "neighbor", "show", "vrf", link}, false)
+		out, err = Run(n.command("ip", "-j", "-d", "neighbor", "show", "vrf", link), false)
 	}
 	if err != 0 {
 		return "", errors.New("failed routelookup")
@@ -317,7 +452,7 @@ func (n *NetlinkWrapper) ReadNeigh(_ context.Context, link string) (string, erro
 
 // ReadRoute is a wrapper for netlink.ReadRoute
 func (n *NetlinkWrapper) ReadRoute(_ context.Context, table string) (string, error) {
-	out, err := Run([]string{"ip", "-j", "-d", "route", "show", "table", table}, false)
+	out, err := Run(n.command("ip", "-j", "-d", "route", "show", "table", table), false)
 	if err != 0 {
 		return "", errors.New("failed to read route")
 	}
@@ -325,8 +460,8 @@ func (n *NetlinkWrapper) ReadRoute(_ context.Context, table string) (string, err
 }
 
 // ReadFDB is a wrapper for netlink.ReadFDB
-func (n *NetlinkWrapper) ReadFDB(_ context.Context) (string, error) {
-	out, err := Run([]string{"bridge", "-d", "-j", "fdb", "show", "br", "br-tenant", "dynamic"}, false)
+func (n *NetlinkWrapper) ReadFDB(_ context.Context, bridge string) (string, error) {
+	out, err := Run(n.command("bridge", "-d", "-j", "fdb", "show", "br", bridge, "dynamic"), false)
 	if err != 0 {
 		return "", errors.New("failed to read fdb")
 	}
@@ -338,9 +473,9 @@ func (n *NetlinkWrapper) RouteLookup(_ context.Context, dst string, link string)
 	var out string
 	var err int
 	if link == "" {
-		out, err = Run([]string{"ip", "-j", "route", "get", dst, "fibmatch"}, false)
+		out, err = Run(n.command("ip", "-j", "route", "get", dst, "fibmatch"), false)
 	} else {
-		out, err = Run([]string{"ip", "-j", "route", "get", dst, "vrf", link, "fibmatch"}, false)
+		out, err = Run(n.command("ip", "-j", "route", "get", dst, "vrf", link, "fibmatch"), false)
 	}
 	if err != 0 {
 		return "", errors.New("failed routelookup")
@@ -353,5 +488,19 @@ func (n *NetlinkWrapper) LinkSetBrNeighSuppress(ctx context.Context, link netlin
 	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetBrNeighSuppress")
 	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
 	defer childSpan.End()
-	return netlink.LinkSetBrNeighSuppress(link, neighSuppress)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetBrNeighSuppress(link, neighSuppress)
+}
+
+// LinkSetIsolated is a wrapper for netlink.LinkSetIsolated
+func (n *NetlinkWrapper) LinkSetIsolated(ctx context.Context, link netlink.Link, isolated bool) error {
+	_, childSpan := n.tracer.Start(ctx, "netlink.LinkSetIsolated")
+	childSpan.SetAttributes(attribute.String("link.name", link.Attrs().Name))
+	defer childSpan.End()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return n.handle.LinkSetIsolated(link, isolated)
 }
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+// NetnsDir holds the named network namespaces, as `ip netns` does
+var NetnsDir = "/var/run/netns"
+
+// netnsName is a valid name of `ip netns add`, a file of NetnsDir
+var netnsName = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,63}$`)
+
+// ValidateNetnsName checks that the name can be the one of a named network namespace
+func ValidateNetnsName(name string) error {
+	if !netnsName.MatchString(name) {
+		return fmt.Errorf("invalid network namespace name %q, expected up to 64 letters, digits, '_', '.' or '-'", name)
+	}
+	return nil
+}
+
+// ListNetns returns the names of the named network namespaces
+func ListNetns() ([]string, error) {
+	entries, err := os.ReadDir(NetnsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names, nil
+}
+
+// MoveLinkToNetns moves the device to the named network namespace, the device keeps its
+// name and a tunnel keeps its socket in the namespace it has been created in
+func MoveLinkToNetns(ctx context.Context, n Netlink, link netlink.Link, namespace string) error {
+	ns, err := netns.GetFromName(namespace)
+	if err != nil {
+		return fmt.Errorf("network namespace %s: %w", namespace, err)
+	}
+	defer func() { _ = ns.Close() }()
+	return n.LinkSetNsFd(ctx, link, int(ns))
+}
+
+// NetnsID identifies the named network namespace, which changes when the namespace is created again
+func NetnsID(namespace string) (string, error) {
+	ns, err := netns.GetFromName(namespace)
+	if err != nil {
+		return "", fmt.Errorf("network namespace %s: %w", namespace, err)
+	}
+	defer func() { _ = ns.Close() }()
+	return ns.UniqueId(), nil
+}
+
+// InNetns runs the function on a thread entered in the named network namespace, in the namespace of the
+// bridge when the name is empty. The sockets opened by the function stay in the namespace.
+func InNetns(namespace string, fn func() error) error {
+	if namespace == "" {
+		return fn()
+	}
+	runtime.LockOSThread()
+	origin, err := netns.Get()
+	if err != nil {
+		runtime.UnlockOSThread()
+		return err
+	}
+	defer func() { _ = origin.Close() }()
+	ns, err := netns.GetFromName(namespace)
+	if err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("network namespace %s: %w", namespace, err)
+	}
+	defer func() { _ = ns.Close() }()
+	if err := netns.Set(ns); err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("network namespace %s: %w", namespace, err)
+	}
+	// the thread ends with the goroutine, instead of serving another one, when it cannot come back
+	defer func() {
+		if err := netns.Set(origin); err == nil {
+			runtime.UnlockOSThread()
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_ValidateNetnsName(t *testing.T) {
+	tests := map[string]struct {
+		name  string
+		valid bool
+	}{
+		"letters and digits": {name: "tenant1", valid: true},
+		"dots and dashes":    {name: "tenant-a.blue", valid: true},
+		"empty":              {name: ""},
+		"path":               {name: "../tenant"},
+		"leading dash":       {name: "-tenant"},
+		"64 characters":      {name: strings.Repeat("t", 64), valid: true},
+		"longer than 64":     {name: strings.Repeat("t", 65)},
+	}
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			err := ValidateNetnsName(tt.name)
+			if (err == nil) != tt.valid {
+				t.Errorf("expected valid %v, received %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func Test_ListNetns(t *testing.T) {
+	dir := t.TempDir()
+	saved := NetnsDir
+	t.Cleanup(func() { NetnsDir = saved })
+
+	NetnsDir = filepath.Join(dir, "missing")
+	names, err := ListNetns()
+	if err != nil || len(names) != 0 {
+		t.Fatalf("expected no namespace without the directory, received %v %v", names, err)
+	}
+
+	NetnsDir = dir
+	for _, name := range []string{"blue", "red"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, err = ListNetns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"blue", "red"}) {
+		t.Errorf("expected [blue red], received %v", names)
+	}
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils contains utility functions
+package utils
+
+import (
+	"go.einride.tech/aip/fieldbehavior"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// allocatedFields are the required uint32 fields whose zero value asks the server to allocate the value
+var allocatedFields = map[protoreflect.FullName]bool{
+	"opi_api.network.evpn_gw.v1alpha1.LogicalBridgeSpec.vlan_id": true,
+}
+
+// optionalFields are the fields required by the API which the bridge does without: a VRF without loopback
+// takes its router id from its VTEP, an SVI without gateway prefix is a link-local only (unnumbered) subnet
+var optionalFields = map[protoreflect.FullName]bool{
+	"opi_api.network.evpn_gw.v1alpha1.VrfSpec.loopback_ip_prefix": true,
+	"opi_api.network.evpn_gw.v1alpha1.SviSpec.gw_ip_prefix":       true,
+}
+
+// ValidateRequiredFields checks the required fields of the message as fieldbehavior.ValidateRequiredFields
+// does, except that a zero allocated field is a request of an allocation rather than a missing field and
+// that the optional fields may be missing
+func ValidateRequiredFields(msg proto.Message) error {
+	clone := proto.Clone(msg)
+	fillAllocatedFields(clone.ProtoReflect())
+	return fieldbehavior.ValidateRequiredFields(clone)
+}
+
+// fillAllocatedFields sets a placeholder into the zero allocated fields and the missing optional fields
+// of the message and its sub messages
+func fillAllocatedFields(m protoreflect.Message) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		switch {
+		case allocatedFields[field.FullName()] && !m.Has(field):
+			m.Set(field, protoreflect.ValueOfUint32(1))
+		case optionalFields[field.FullName()] && field.IsList() && !m.Has(field):
+			list := m.Mutable(field).List()
+			list.Append(list.NewElement())
+		case optionalFields[field.FullName()] && !m.Has(field):
+			m.Set(field, m.NewField(field))
+		case field.Kind() == protoreflect.MessageKind && !field.IsList() && !field.IsMap() && m.Has(field):
+			fillAllocatedFields(m.Mutable(field).Message())
+		}
+	}
+}
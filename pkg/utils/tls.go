@@ -99,3 +99,36 @@ func setupTLSCredentials(config TLSConfig,
 
 	return grpc.Creds(credentials.NewTLS(c)), nil
 }
+
+// SetupTLSClientCredentials returns a dial option authenticating a gRPC client with its certificate and verifying
+// the server with the CA certificate of the config
+func SetupTLSClientCredentials(config TLSConfig) (grpc.DialOption, error) {
+	return setupTLSClientCredentials(config, tls.LoadX509KeyPair, os.ReadFile)
+}
+
+func setupTLSClientCredentials(config TLSConfig,
+	loadX509KeyPair func(string, string) (tls.Certificate, error),
+	readFile func(string) ([]byte, error),
+) (grpc.DialOption, error) {
+	clientCert, err := loadX509KeyPair(config.ServerCertPath, config.ServerKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		MinVersion:   tls.VersionTLS12,
+		RootCAs:      x509.NewCertPool(),
+	}
+
+	caCert, err := readFile(config.CaCertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %v. error: %v", config.CaCertPath, err)
+	}
+
+	if !c.RootCAs.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to add server CA's certificate: %v", config.CaCertPath)
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(c)), nil
+}
@@ -175,3 +175,57 @@ func TestServer_SetupTLSCredentials(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_SetupTLSClientCredentials(t *testing.T) {
+	tests := map[string]struct {
+		expectErr   bool
+		loadKeyErr  error
+		readFileErr error
+		validCaCert bool
+	}{
+		"failed to load key pair": {
+			expectErr:   true,
+			loadKeyErr:  errors.New("Key load failed"),
+			validCaCert: true,
+		},
+		"failed to read file": {
+			expectErr:   true,
+			readFileErr: errors.New("Failed to read file"),
+			validCaCert: true,
+		},
+		"invalid CA certificate": {
+			expectErr:   true,
+			validCaCert: false,
+		},
+		"valid CA certificate": {
+			expectErr:   false,
+			validCaCert: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			caCert := make([]byte, len(validCa))
+			copy(caCert, validCa)
+			if !tt.validCaCert {
+				caCert[0] = caCert[0] - 1
+			}
+
+			out, err := setupTLSClientCredentials(TLSConfig{
+				ServerCertPath: "a",
+				ServerKeyPath:  "b",
+				CaCertPath:     "c",
+			}, func(_, _ string) (tls.Certificate, error) {
+				return tls.Certificate{}, tt.loadKeyErr
+			}, func(_ string) ([]byte, error) {
+				return caCert, tt.readFileErr
+			})
+
+			if (err != nil) != tt.expectErr {
+				t.Error("Expect error", tt.expectErr, "received", err)
+			}
+			if !tt.expectErr && out == nil {
+				t.Error("Expect not nil dial option, received nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// VlanAwareTopology carries all the logical bridges in one vlan aware linux bridge
+	VlanAwareTopology = "vlan-aware"
+	// PerVlanTopology carries every logical bridge in a linux bridge of its own
+	PerVlanTopology = "per-vlan"
+)
+
+// tenantBridge is the vlan aware bridge of the VlanAwareTopology
+const tenantBridge = "br-tenant"
+
+// BridgeTopology abstracts how the logical bridges are mapped onto linux bridges,
+// so that the modules can stay agnostic of the selected topology
+type BridgeTopology interface {
+	// SetUp creates the static bridges of the topology
+	SetUp(ctx context.Context) error
+	// TearDown deletes the static bridges of the topology
+	TearDown(ctx context.Context) error
+	// BridgeName returns the linux bridge which carries the vlan
+	BridgeName(vid uint16) string
+	// AttachVxlan adds the vxlan device of the logical bridge to its linux bridge
+	AttachVxlan(ctx context.Context, vxlan netlink.Link, vid uint16) error
+	// DelSegment removes the state of the vlan once its logical bridge is gone
+	DelSegment(ctx context.Context, vid uint16) error
+	// AddSvi creates the routed interface of the vlan with the given name
+	AddSvi(ctx context.Context, name string, vid uint16) (netlink.Link, error)
+	// ReleaseSvi removes the state of the vlan that is kept for the routed interface
+	ReleaseSvi(ctx context.Context, vid uint16) error
+	// AddPort prepares the bridge port before its vlans are attached
+	AddPort(ctx context.Context, iface netlink.Link) error
+	// AttachPort adds the bridge port to the vlan, untagged for access ports
+	AttachPort(ctx context.Context, iface netlink.Link, vid uint16, access bool) error
+	// DetachPort removes the bridge port from the vlan
+	DetachPort(ctx context.Context, iface netlink.Link, vid uint16, access bool) error
+	// IsolatePort stops the bridge port in the vlan from forwarding to the other isolated ports
+	IsolatePort(ctx context.Context, iface netlink.Link, vid uint16, access bool, isolated bool) error
+}
+
+// NewBridgeTopology creates the topology of the given kind, the vlan aware one being the default
+func NewBridgeTopology(kind string, nlink Netlink, mtu int) (BridgeTopology, error) {
+	switch kind {
+	case "", VlanAwareTopology:
+		return &vlanAwareTopology{nlink: nlink, mtu: mtu}, nil
+	case PerVlanTopology:
+		return &perVlanTopology{nlink: nlink, mtu: mtu}, nil
+	default:
+		return nil, fmt.Errorf("unknown bridge topology %s", kind)
+	}
+}
+
+// vlanAwareTopology implements the BridgeTopology with one vlan aware bridge
+type vlanAwareTopology struct {
+	nlink Netlink
+	mtu   int
+}
+
+// SetUp creates the vlan aware br-tenant unless it exists already
+func (t *vlanAwareTopology) SetUp(ctx context.Context) error {
+	if _, err := t.nlink.LinkByName(ctx, tenantBridge); err == nil {
+		return nil
+	}
+	vlanfiltering := true
+	bridge := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: tenantBridge},
+		VlanDefaultPVID: new(uint16),
+		VlanFiltering:   &vlanfiltering,
+	}
+	if err := t.nlink.LinkAdd(ctx, bridge); err != nil {
+		return fmt.Errorf("failed to create %s: %v", tenantBridge, err)
+	}
+	if err := t.nlink.LinkSetAlias(ctx, bridge, LinkAlias("")); err != nil {
+		return fmt.Errorf("failed to tag %s: %v", tenantBridge, err)
+	}
+	if err := t.nlink.LinkSetMTU(ctx, bridge, t.mtu); err != nil {
+		return fmt.Errorf("unable to set MTU %v to %s: %v", t.mtu, tenantBridge, err)
+	}
+	if err := t.nlink.LinkSetUp(ctx, bridge); err != nil {
+		return fmt.Errorf("failed to set up %s: %v", tenantBridge, err)
+	}
+	return nil
+}
+
+// TearDown deletes br-tenant
+func (t *vlanAwareTopology) TearDown(ctx context.Context) error {
+	bridge, err := t.nlink.LinkByName(ctx, tenantBridge)
+	if err != nil {
+		return err
+	}
+	return t.nlink.LinkDel(ctx, bridge)
+}
+
+// BridgeName returns br-tenant for all the vlans
+func (t *vlanAwareTopology) BridgeName(_ uint16) string {
+	return tenantBridge
+}
+
+// AttachVxlan adds the vxlan device to br-tenant with the vlan as pvid
+func (t *vlanAwareTopology) AttachVxlan(ctx context.Context, vxlan netlink.Link, vid uint16) error {
+	bridge, err := t.nlink.LinkByName(ctx, tenantBridge)
+	if err != nil {
+		return err
+	}
+	// Example: ip link set vxlan-<lb-vlan-id> master br-tenant addrgenmode none
+	if err := t.nlink.LinkSetMaster(ctx, vxlan, bridge); err != nil {
+		return err
+	}
+	// Example: bridge vlan add dev vxlan-<lb-vlan-id> vid <lb-vlan-id> pvid untagged
+	return t.nlink.BridgeVlanAdd(ctx, vxlan, vid, true, true, false, false)
+}
+
+// DelSegment has nothing to do as the vlan is removed together with its members
+func (t *vlanAwareTopology) DelSegment(_ context.Context, _ uint16) error {
+	return nil
+}
+
+// AddSvi creates a vlan sub-interface on top of br-tenant
+func (t *vlanAwareTopology) AddSvi(ctx context.Context, name string, vid uint16) (netlink.Link, error) {
+	bridge, err := t.nlink.LinkByName(ctx, tenantBridge)
+	if err != nil {
+		return nil, err
+	}
+	// Example: bridge vlan add dev br-tenant vid <vlan-id> self
+	if err := t.nlink.BridgeVlanAdd(ctx, bridge, vid, false, false, true, false); err != nil {
+		return nil, err
+	}
+	// Example: ip link add link br-tenant name <svi> type vlan id <vlan-id>
+	vlanLink := &netlink.Vlan{LinkAttrs: netlink.LinkAttrs{Name: name, ParentIndex: bridge.Attrs().Index}, VlanId: int(vid)}
+	if err := t.nlink.LinkAdd(ctx, vlanLink); err != nil {
+		return nil, err
+	}
+	return vlanLink, nil
+}
+
+// ReleaseSvi removes the vlan from br-tenant itself
+func (t *vlanAwareTopology) ReleaseSvi(ctx context.Context, vid uint16) error {
+	bridge, err := t.nlink.LinkByName(ctx, tenantBridge)
+	if err != nil {
+		return err
+	}
+	// Example: bridge vlan del dev br-tenant vid <vlan-id> self
+	return t.nlink.BridgeVlanDel(ctx, bridge, vid, false, false, true, false)
+}
+
+// AddPort adds the bridge port to br-tenant
+func (t *vlanAwareTopology) AddPort(ctx context.Context, iface netlink.Link) error {
+	bridge, err := t.nlink.LinkByName(ctx, tenantBridge)
+	if err != nil {
+		return err
+	}
+	return t.nlink.LinkSetMaster(ctx, iface, bridge)
+}
+
+// AttachPort adds the vlan to the bridge port
+func (t *vlanAwareTopology) AttachPort(ctx context.Context, iface netlink.Link, vid uint16, access bool) error {
+	// Example: bridge vlan add dev eth2 vid 20 [pvid untagged]
+	return t.nlink.BridgeVlanAdd(ctx, iface, vid, access, access, false, false)
+}
+
+// DetachPort removes the vlan from the bridge port
+func (t *vlanAwareTopology) DetachPort(ctx context.Context, iface netlink.Link, vid uint16, access bool) error {
+	return t.nlink.BridgeVlanDel(ctx, iface, vid, access, access, false, false)
+}
+
+// IsolatePort sets the isolation of the bridge port, which applies to all its vlans
+func (t *vlanAwareTopology) IsolatePort(ctx context.Context, iface netlink.Link, _ uint16, _ bool, isolated bool) error {
+	// Example: bridge link set dev eth2 isolated on
+	return t.nlink.LinkSetIsolated(ctx, iface, isolated)
+}
+
+// perVlanTopology implements the BridgeTopology with one bridge per vlan. The routed
+// interface is a macvlan on top of the bridge, as the bridge is not vlan aware.
+type perVlanTopology struct {
+	nlink Netlink
+	mtu   int
+}
+
+// SetUp has nothing to do as the bridges are created per vlan
+func (t *perVlanTopology) SetUp(_ context.Context) error {
+	return nil
+}
+
+// TearDown has nothing to do as the bridges are deleted per vlan
+func (t *perVlanTopology) TearDown(_ context.Context) error {
+	return nil
+}
+
+// BridgeName returns the bridge of the vlan
+func (t *perVlanTopology) BridgeName(vid uint16) string {
+	return fmt.Sprintf("brt-%d", vid)
+}
+
+// segmentBridge returns the bridge of the vlan and creates it when it does not exist yet
+func (t *perVlanTopology) segmentBridge(ctx context.Context, vid uint16) (netlink.Link, error) {
+	name := t.BridgeName(vid)
+	if bridge, err := t.nlink.LinkByName(ctx, name); err == nil {
+		return bridge, nil
+	}
+	// Example: ip link add brt-<vlan-id> type bridge
+	bridge := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: name}}
+	if err := t.nlink.LinkAdd(ctx, bridge); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %v", name, err)
+	}
+	if err := t.nlink.LinkSetAlias(ctx, bridge, LinkAlias("")); err != nil {
+		return nil, fmt.Errorf("failed to tag %s: %v", name, err)
+	}
+	if err := t.nlink.LinkSetMTU(ctx, bridge, t.mtu); err != nil {
+		return nil, fmt.Errorf("unable to set MTU %v to %s: %v", t.mtu, name, err)
+	}
+	if err := t.nlink.LinkSetUp(ctx, bridge); err != nil {
+		return nil, fmt.Errorf("failed to set up %s: %v", name, err)
+	}
+	return bridge, nil
+}
+
+// AttachVxlan adds the vxlan device to the bridge of the vlan
+func (t *perVlanTopology) AttachVxlan(ctx context.Context, vxlan netlink.Link, vid uint16) error {
+	bridge, err := t.segmentBridge(ctx, vid)
+	if err != nil {
+		return err
+	}
+	// Example: ip link set vxlan-<lb-vlan-id> master brt-<lb-vlan-id>
+	return t.nlink.LinkSetMaster(ctx, vxlan, bridge)
+}
+
+// DelSegment deletes the bridge of the vlan
+func (t *perVlanTopology) DelSegment(ctx context.Context, vid uint16) error {
+	bridge, err := t.nlink.LinkByName(ctx, t.BridgeName(vid))
+	if err != nil {
+		return nil
+	}
+	// Example: ip link delete brt-<vlan-id>
+	return t.nlink.LinkDel(ctx, bridge)
+}
+
+// AddSvi creates a macvlan on top of the bridge of the vlan
+func (t *perVlanTopology) AddSvi(ctx context.Context, name string, vid uint16) (netlink.Link, error) {
+	bridge, err := t.segmentBridge(ctx, vid)
+	if err != nil {
+		return nil, err
+	}
+	// Example: ip link add link brt-<vlan-id> name <svi> type macvlan mode private
+	macvlan := &netlink.Macvlan{LinkAttrs: netlink.LinkAttrs{Name: name, ParentIndex: bridge.Attrs().Index}, Mode: netlink.MACVLAN_MODE_PRIVATE}
+	if err := t.nlink.LinkAdd(ctx, macvlan); err != nil {
+		return nil, err
+	}
+	return macvlan, nil
+}
+
+// ReleaseSvi has nothing to do as the macvlan is deleted with the routed interface
+func (t *perVlanTopology) ReleaseSvi(_ context.Context, _ uint16) error {
+	return nil
+}
+
+// AddPort has nothing to do as the bridge port joins the bridges per vlan
+func (t *perVlanTopology) AddPort(_ context.Context, _ netlink.Link) error {
+	return nil
+}
+
+// portLinkName returns the vlan sub-interface used by trunk ports. When the name does not fit in
+// IFNAMSIZ, it is hashed like the names of the infradb table.
+func portLinkName(iface netlink.Link, vid uint16) string {
+	name := fmt.Sprintf("%s.%d", iface.Attrs().Name, vid)
+	if len(name) <= IfNameSize {
+		return name
+	}
+	return HashedIfName(name, name, 0)
+}
+
+// AttachPort adds the access port, or a vlan sub-interface of the trunk port, to the bridge of the vlan
+func (t *perVlanTopology) AttachPort(ctx context.Context, iface netlink.Link, vid uint16, access bool) error {
+	bridge, err := t.segmentBridge(ctx, vid)
+	if err != nil {
+		return err
+	}
+	if access {
+		// Example: ip link set eth2 master brt-<vlan-id>
+		return t.nlink.LinkSetMaster(ctx, iface, bridge)
+	}
+	// Example: ip link add link eth2 name eth2.<vlan-id> type vlan id <vlan-id>
+	sub := &netlink.Vlan{LinkAttrs: netlink.LinkAttrs{Name: portLinkName(iface, vid), ParentIndex: iface.Attrs().Index}, VlanId: int(vid)}
+	if err := t.nlink.LinkAdd(ctx, sub); err != nil {
+		return err
+	}
+	if err := t.nlink.LinkSetAlias(ctx, sub, LinkAlias("")); err != nil {
+		return err
+	}
+	if err := t.nlink.LinkSetMaster(ctx, sub, bridge); err != nil {
+		return err
+	}
+	return t.nlink.LinkSetUp(ctx, sub)
+}
+
+// DetachPort removes the access port, or the vlan sub-interface of the trunk port, from the bridge of the vlan
+func (t *perVlanTopology) DetachPort(ctx context.Context, iface netlink.Link, vid uint16, access bool) error {
+	if access {
+		return t.nlink.LinkSetNoMaster(ctx, iface)
+	}
+	sub, err := t.nlink.LinkByName(ctx, portLinkName(iface, vid))
+	if err != nil {
+		return nil
+	}
+	return t.nlink.LinkDel(ctx, sub)
+}
+
+// IsolatePort sets the isolation of the access port, or of the vlan sub-interface of the trunk port
+func (t *perVlanTopology) IsolatePort(ctx context.Context, iface netlink.Link, vid uint16, access bool, isolated bool) error {
+	if access {
+		return t.nlink.LinkSetIsolated(ctx, iface, isolated)
+	}
+	sub, err := t.nlink.LinkByName(ctx, portLinkName(iface, vid))
+	if err != nil {
+		return err
+	}
+	return t.nlink.LinkSetIsolated(ctx, sub, isolated)
+}
@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/vishvananda/netlink"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
+)
+
+// linkNamed matches the links created by the topology, which are not known to the tests
+func linkNamed(name string) interface{} {
+	return mock.MatchedBy(func(link netlink.Link) bool { return link.Attrs().Name == name })
+}
+
+func newTestTopology(t *testing.T, kind string) (BridgeTopology, *mocks.Netlink) {
+	t.Helper()
+	nl := mocks.NewNetlink(t)
+	topology, err := NewBridgeTopology(kind, nl, 9000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return topology, nl
+}
+
+func Test_VlanAwareTopology(t *testing.T) {
+	ctx := context.Background()
+	bridge := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: tenantBridge, Index: 10}}
+	vxlan := &netlink.Vxlan{LinkAttrs: netlink.LinkAttrs{Name: "vxlan-20", Index: 20}}
+	port := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "eth2", Index: 2}}
+
+	t.Run("set up", func(t *testing.T) {
+		topology, nl := newTestTopology(t, VlanAwareTopology)
+		nl.On("LinkByName", ctx, tenantBridge).Return(nil, netlink.LinkNotFoundError{}).Once()
+		nl.On("LinkAdd", ctx, mock.MatchedBy(func(link netlink.Link) bool {
+			br, ok := link.(*netlink.Bridge)
+			return ok && br.Name == tenantBridge && br.VlanFiltering != nil && *br.VlanFiltering
+		})).Return(nil)
+		nl.On("LinkSetAlias", ctx, linkNamed(tenantBridge), LinkAlias("")).Return(nil)
+		nl.On("LinkSetMTU", ctx, linkNamed(tenantBridge), 9000).Return(nil)
+		nl.On("LinkSetUp", ctx, linkNamed(tenantBridge)).Return(nil)
+		if err := topology.SetUp(ctx); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("set up with an existing bridge", func(t *testing.T) {
+		topology, nl := newTestTopology(t, VlanAwareTopology)
+		nl.On("LinkByName", ctx, tenantBridge).Return(bridge, nil)
+		if err := topology.SetUp(ctx); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("attach vxlan", func(t *testing.T) {
+		topology, nl := newTestTopology(t, VlanAwareTopology)
+		nl.On("LinkByName", ctx, tenantBridge).Return(bridge, nil)
+		nl.On("LinkSetMaster", ctx, vxlan, bridge).Return(nil)
+		nl.On("BridgeVlanAdd", ctx, vxlan, uint16(20), true, true, false, false).Return(nil)
+		if err := topology.AttachVxlan(ctx, vxlan, 20); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("svi", func(t *testing.T) {
+		topology, nl := newTestTopology(t, VlanAwareTopology)
+		nl.On("LinkByName", ctx, tenantBridge).Return(bridge, nil)
+		nl.On("BridgeVlanAdd", ctx, bridge, uint16(20), false, false, true, false).Return(nil)
+		nl.On("LinkAdd", ctx, mock.MatchedBy(func(link netlink.Link) bool {
+			vlan, ok := link.(*netlink.Vlan)
+			return ok && vlan.Name == "svi-blue" && vlan.ParentIndex == bridge.Index && vlan.VlanId == 20
+		})).Return(nil)
+		nl.On("BridgeVlanDel", ctx, bridge, uint16(20), false, false, true, false).Return(nil)
+		svi, err := topology.AddSvi(ctx, "svi-blue", 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if svi.Attrs().Name != "svi-blue" {
+			t.Errorf("unexpected svi %+v", svi.Attrs())
+		}
+		if err := topology.ReleaseSvi(ctx, 20); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("ports", func(t *testing.T) {
+		topology, nl := newTestTopology(t, VlanAwareTopology)
+		nl.On("BridgeVlanAdd", ctx, port, uint16(20), true, true, false, false).Return(nil)
+		nl.On("BridgeVlanAdd", ctx, port, uint16(30), false, false, false, false).Return(nil)
+		nl.On("BridgeVlanDel", ctx, port, uint16(20), true, true, false, false).Return(nil)
+		nl.On("BridgeVlanDel", ctx, port, uint16(30), false, false, false, false).Return(nil)
+		if err := topology.AttachPort(ctx, port, 20, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := topology.AttachPort(ctx, port, 30, false); err != nil {
+			t.Fatal(err)
+		}
+		if err := topology.DetachPort(ctx, port, 20, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := topology.DetachPort(ctx, port, 30, false); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func Test_PerVlanTopology(t *testing.T) {
+	ctx := context.Background()
+	bridge20 := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: "brt-20", Index: 11}}
+	bridge30 := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: "brt-30", Index: 12}}
+	vxlan := &netlink.Vxlan{LinkAttrs: netlink.LinkAttrs{Name: "vxlan-20", Index: 20}}
+	port := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: "eth2", Index: 2}}
+	sub := &netlink.Vlan{LinkAttrs: netlink.LinkAttrs{Name: "eth2.30", Index: 3, ParentIndex: 2}, VlanId: 30}
+
+	t.Run("set up", func(t *testing.T) {
+		// the bridges are created per vlan, nothing is done through netlink
+		topology, _ := newTestTopology(t, PerVlanTopology)
+		if err := topology.SetUp(ctx); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("attach vxlan", func(t *testing.T) {
+		topology, nl := newTestTopology(t, PerVlanTopology)
+		nl.On("LinkByName", ctx, "brt-20").Return(nil, netlink.LinkNotFoundError{}).Once()
+		nl.On("LinkAdd", ctx, mock.MatchedBy(func(link netlink.Link) bool {
+			br, ok := link.(*netlink.Bridge)
+			return ok && br.Name == "brt-20" && br.VlanFiltering == nil
+		})).Return(nil)
+		nl.On("LinkSetAlias", ctx, linkNamed("brt-20"), LinkAlias("")).Return(nil)
+		nl.On("LinkSetMTU", ctx, linkNamed("brt-20"), 9000).Return(nil)
+		nl.On("LinkSetUp", ctx, linkNamed("brt-20")).Return(nil)
+		nl.On("LinkSetMaster", ctx, vxlan, linkNamed("brt-20")).Return(nil)
+		if err := topology.AttachVxlan(ctx, vxlan, 20); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("svi", func(t *testing.T) {
+		topology, nl := newTestTopology(t, PerVlanTopology)
+		nl.On("LinkByName", ctx, "brt-20").Return(bridge20, nil)
+		nl.On("LinkAdd", ctx, mock.MatchedBy(func(link netlink.Link) bool {
+			macvlan, ok := link.(*netlink.Macvlan)
+			return ok && macvlan.Name == "svi-blue" && macvlan.ParentIndex == bridge20.Index && macvlan.Mode == netlink.MACVLAN_MODE_PRIVATE
+		})).Return(nil)
+		svi, err := topology.AddSvi(ctx, "svi-blue", 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if svi.Attrs().Name != "svi-blue" {
+			t.Errorf("unexpected svi %+v", svi.Attrs())
+		}
+		// the macvlan goes with the routed interface, the bridge is left alone
+		if err := topology.ReleaseSvi(ctx, 20); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("access port", func(t *testing.T) {
+		topology, nl := newTestTopology(t, PerVlanTopology)
+		nl.On("LinkByName", ctx, "brt-20").Return(bridge20, nil)
+		nl.On("LinkSetMaster", ctx, port, bridge20).Return(nil)
+		nl.On("LinkSetNoMaster", ctx, port).Return(nil)
+		if err := topology.AttachPort(ctx, port, 20, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := topology.DetachPort(ctx, port, 20, true); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("trunk port", func(t *testing.T) {
+		topology, nl := newTestTopology(t, PerVlanTopology)
+		nl.On("LinkByName", ctx, "brt-30").Return(bridge30, nil)
+		nl.On("LinkAdd", ctx, mock.MatchedBy(func(link netlink.Link) bool {
+			vlan, ok := link.(*netlink.Vlan)
+			return ok && vlan.Name == "eth2.30" && vlan.ParentIndex == port.Index && vlan.VlanId == 30
+		})).Return(nil)
+		nl.On("LinkSetAlias", ctx, linkNamed("eth2.30"), LinkAlias("")).Return(nil)
+		nl.On("LinkSetMaster", ctx, linkNamed("eth2.30"), bridge30).Return(nil)
+		nl.On("LinkSetUp", ctx, linkNamed("eth2.30")).Return(nil)
+		nl.On("LinkByName", ctx, "eth2.30").Return(sub, nil)
+		nl.On("LinkDel", ctx, sub).Return(nil)
+		if err := topology.AttachPort(ctx, port, 30, false); err != nil {
+			t.Fatal(err)
+		}
+		if err := topology.DetachPort(ctx, port, 30, false); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func Test_PortLinkName(t *testing.T) {
+	link := func(name string) netlink.Link {
+		return &netlink.Device{LinkAttrs: netlink.LinkAttrs{Name: name}}
+	}
+	if name := portLinkName(link("eth2"), 30); name != "eth2.30" {
+		t.Errorf("expected eth2.30, received %s", name)
+	}
+	long := portLinkName(link("enp175s0f0np0v1"), 4094)
+	other := portLinkName(link("enp175s0f0np0v2"), 4094)
+	if len(long) > IfNameSize || len(other) > IfNameSize {
+		t.Errorf("expected names of at most %d characters, received %s and %s", IfNameSize, long, other)
+	}
+	if long == other {
+		t.Errorf("expected distinct names for distinct ports, received %s", long)
+	}
+	if long != portLinkName(link("enp175s0f0np0v1"), 4094) {
+		t.Errorf("expected a stable name for %s", long)
+	}
+}
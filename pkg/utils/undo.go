@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// undoStep reverts one step of a request
+type undoStep struct {
+	desc string
+	undo func() error
+}
+
+// UndoStack records how to revert the steps of a request, so that a failure midway
+// returns the system to the state it had before the request instead of leaving it
+// half configured
+type UndoStack struct {
+	steps []undoStep
+}
+
+// Push records how to revert the step which has just succeeded
+func (u *UndoStack) Push(desc string, undo func() error) {
+	u.steps = append(u.steps, undoStep{desc: desc, undo: undo})
+}
+
+// Rollback reverts the recorded steps in the reverse order. All the steps are attempted,
+// the ones which fail are reported together.
+func (u *UndoStack) Rollback() error {
+	var errs []error
+	for i := len(u.steps) - 1; i >= 0; i-- {
+		if err := u.steps[i].undo(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to undo %s: %w", u.steps[i].desc, err))
+		}
+	}
+	u.steps = nil
+	return errors.Join(errs...)
+}
+
+// Len returns the number of recorded steps
+func (u *UndoStack) Len() int {
+	return len(u.steps)
+}
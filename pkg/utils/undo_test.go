@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUndoStack(t *testing.T) {
+	undone := []string{}
+	undo := &UndoStack{}
+	for _, step := range []string{"link add", "addr add", "route add"} {
+		step := step
+		undo.Push(step, func() error {
+			undone = append(undone, step)
+			if step == "addr add" {
+				return errors.New("no such device")
+			}
+			return nil
+		})
+	}
+	if undo.Len() != 3 {
+		t.Fatalf("expected 3 steps, received %d", undo.Len())
+	}
+
+	err := undo.Rollback()
+	if err == nil || !strings.Contains(err.Error(), "failed to undo addr add: no such device") {
+		t.Errorf("unexpected error %v", err)
+	}
+	expected := []string{"route add", "addr add", "link add"}
+	if !reflect.DeepEqual(undone, expected) {
+		t.Errorf("expected %v, received %v", expected, undone)
+	}
+	if undo.Len() != 0 {
+		t.Errorf("the steps have not been cleared")
+	}
+	if err := undo.Rollback(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
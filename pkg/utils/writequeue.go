@@ -0,0 +1,505 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/vishvananda/netlink"
+)
+
+// DefaultNamespace names the write queue of the network namespace the bridge runs in
+const DefaultNamespace = "default"
+
+const (
+	// writeAttempts is how many times an operation failing with a transient error is issued
+	writeAttempts = 3
+	// writeBackoff is the wait before the first retry, doubled for every further one
+	writeBackoff = 20 * time.Millisecond
+)
+
+// writeOp is an operation waiting in a write queue, shared by the callers merged into it
+type writeOp struct {
+	ctx    context.Context
+	object string
+	key    string
+	run    func() (string, error)
+	done   chan struct{}
+	out    string
+	err    error
+}
+
+// WriteQueue issues the netlink and FRR operations changing the dataplane of a namespace one
+// at a time, in the order they are enqueued. An operation identical to the last one pending
+// for the same object is merged into it instead of being issued twice.
+type WriteQueue struct {
+	namespace string
+	mu        sync.Mutex
+	pending   []*writeOp
+	// tails holds the last pending operation of every object
+	tails   map[string]*writeOp
+	running *writeOp
+	wake    chan struct{}
+	stats   WriteQueueStats
+}
+
+// WriteQueueStats is a snapshot of the state and the counters of a write queue
+type WriteQueueStats struct {
+	Namespace   string
+	Running     string
+	Pending     []string
+	Enqueued    uint64
+	Merged      uint64
+	Executed    uint64
+	Retried     uint64
+	Failed      uint64
+	LastError   string
+	LastErrorAt time.Time
+}
+
+var (
+	writeQueuesLock sync.Mutex
+	writeQueues     = map[string]*WriteQueue{}
+)
+
+// WriteQueueFor returns the write queue of the namespace, starting it on first use
+func WriteQueueFor(namespace string) *WriteQueue {
+	writeQueuesLock.Lock()
+	defer writeQueuesLock.Unlock()
+	if q, ok := writeQueues[namespace]; ok {
+		return q
+	}
+	q := newWriteQueue(namespace)
+	writeQueues[namespace] = q
+	go q.process()
+	return q
+}
+
+// GetWriteQueueStats returns the state of the write queues sorted by namespace
+func GetWriteQueueStats() []WriteQueueStats {
+	writeQueuesLock.Lock()
+	queues := make([]*WriteQueue, 0, len(writeQueues))
+	for _, q := range writeQueues {
+		queues = append(queues, q)
+	}
+	writeQueuesLock.Unlock()
+
+	out := make([]WriteQueueStats, 0, len(queues))
+	for _, q := range queues {
+		out = append(out, q.Stats())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Namespace < out[j].Namespace })
+	return out
+}
+
+// newWriteQueue returns the write queue of the namespace, its caller starts the processing
+func newWriteQueue(namespace string) *WriteQueue {
+	return &WriteQueue{
+		namespace: namespace,
+		tails:     map[string]*writeOp{},
+		wake:      make(chan struct{}, 1),
+		stats:     WriteQueueStats{Namespace: namespace},
+	}
+}
+
+// Do enqueues the operation on the object and waits for its result. An empty key marks an
+// operation which is never merged, e.g. because its arguments cannot be compared. A merged
+// operation runs with the context of the caller which enqueued it first.
+func (q *WriteQueue) Do(ctx context.Context, object, key string, run func() (string, error)) (string, error) {
+	q.mu.Lock()
+	q.stats.Enqueued++
+	op := q.tails[object]
+	if key == "" || op == nil || op.key != key || op.ctx.Err() != nil {
+		op = &writeOp{ctx: ctx, object: object, key: key, run: run, done: make(chan struct{})}
+		q.pending = append(q.pending, op)
+		q.tails[object] = op
+	} else {
+		q.stats.Merged++
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	select {
+	case <-op.done:
+		return op.out, op.err
+	case <-ctx.Done():
+		// the operation stays queued, the wrappers do not issue it once its context is done
+		return "", ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of the queue
+func (q *WriteQueue) Stats() WriteQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := q.stats
+	if q.running != nil {
+		stats.Running = q.running.description()
+	}
+	stats.Pending = make([]string, 0, len(q.pending))
+	for _, op := range q.pending {
+		stats.Pending = append(stats.Pending, op.description())
+	}
+	return stats
+}
+
+// process issues the queued operations until the process exits
+func (q *WriteQueue) process() {
+	for {
+		op := q.next()
+		if op == nil {
+			<-q.wake
+			continue
+		}
+		op.out, op.err = q.execute(op)
+
+		q.mu.Lock()
+		q.running = nil
+		q.stats.Executed++
+		if op.err != nil {
+			q.stats.Failed++
+			q.stats.LastError = fmt.Sprintf("%s: %v", op.description(), op.err)
+			q.stats.LastErrorAt = time.Now()
+		}
+		q.mu.Unlock()
+		close(op.done)
+	}
+}
+
+// next dequeues the oldest operation, nil when none is pending
+func (q *WriteQueue) next() *writeOp {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return nil
+	}
+	op := q.pending[0]
+	q.pending[0] = nil
+	q.pending = q.pending[1:]
+	if q.tails[op.object] == op {
+		delete(q.tails, op.object)
+	}
+	q.running = op
+	return op
+}
+
+// execute issues the operation, again after a backoff while it fails with a transient error
+func (q *WriteQueue) execute(op *writeOp) (string, error) {
+	backoff := writeBackoff
+	for attempt := 1; ; attempt++ {
+		out, err := op.run()
+		if err == nil || attempt == writeAttempts || !isTransient(err) {
+			return out, err
+		}
+		log.Printf("write queue %s: retrying %s after %v: %v\n", q.namespace, op.description(), backoff, err)
+		q.mu.Lock()
+		q.stats.Retried++
+		q.mu.Unlock()
+		select {
+		case <-op.ctx.Done():
+			return "", op.ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// description names the operation in the stats and the logs
+func (op *writeOp) description() string {
+	if op.key == "" {
+		return op.object
+	}
+	return op.key
+}
+
+// isTransient tells whether an operation failing with the error may succeed when issued again
+func isTransient(err error) bool {
+	for _, errno := range []syscall.Errno{syscall.EBUSY, syscall.EAGAIN, syscall.EINTR, syscall.ENOBUFS, syscall.ECONNREFUSED} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
+// QueuedNetlink issues the netlink operations changing the kernel state through a write queue,
+// the ones reading it are issued directly
+type QueuedNetlink struct {
+	Netlink
+	queue *WriteQueue
+}
+
+// NewQueuedNetlink returns the netlink wrapper writing through the queue
+func NewQueuedNetlink(n Netlink, q *WriteQueue) *QueuedNetlink {
+	return &QueuedNetlink{Netlink: n, queue: q}
+}
+
+// WithWriteQueue returns the netlink wrapper writing through the queue of the namespace
+func WithWriteQueue(n Netlink, namespace string) Netlink {
+	return NewQueuedNetlink(n, WriteQueueFor(namespace))
+}
+
+// build time check that struct implements interface
+var _ Netlink = (*QueuedNetlink)(nil)
+
+// do enqueues the operation on the link, the key is made of the operation and its arguments
+func (n *QueuedNetlink) do(ctx context.Context, object, op string, args []interface{}, run func() error) error {
+	key := ""
+	if args != nil {
+		key = fmt.Sprintf("%s %s %v", op, object, args)
+	}
+	_, err := n.queue.Do(ctx, object, key, func() (string, error) { return "", run() })
+	return err
+}
+
+// LinkModify queues netlink.LinkModify
+func (n *QueuedNetlink) LinkModify(ctx context.Context, link netlink.Link) error {
+	return n.do(ctx, link.Attrs().Name, "LinkModify", nil, func() error { return n.Netlink.LinkModify(ctx, link) })
+}
+
+// LinkSetHardwareAddr queues netlink.LinkSetHardwareAddr
+func (n *QueuedNetlink) LinkSetHardwareAddr(ctx context.Context, link netlink.Link, hwaddr net.HardwareAddr) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetHardwareAddr", []interface{}{hwaddr}, func() error {
+		return n.Netlink.LinkSetHardwareAddr(ctx, link, hwaddr)
+	})
+}
+
+// LinkSetVfHardwareAddr queues netlink.LinkSetVfHardwareAddr
+func (n *QueuedNetlink) LinkSetVfHardwareAddr(ctx context.Context, link netlink.Link, vf int, hwaddr net.HardwareAddr) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetVfHardwareAddr", []interface{}{vf, hwaddr}, func() error {
+		return n.Netlink.LinkSetVfHardwareAddr(ctx, link, vf, hwaddr)
+	})
+}
+
+// AddrAdd queues netlink.AddrAdd
+func (n *QueuedNetlink) AddrAdd(ctx context.Context, link netlink.Link, addr *netlink.Addr) error {
+	return n.do(ctx, link.Attrs().Name, "AddrAdd", []interface{}{addr.String()}, func() error {
+		return n.Netlink.AddrAdd(ctx, link, addr)
+	})
+}
+
+// AddrDel queues netlink.AddrDel
+func (n *QueuedNetlink) AddrDel(ctx context.Context, link netlink.Link, addr *netlink.Addr) error {
+	return n.do(ctx, link.Attrs().Name, "AddrDel", []interface{}{addr.String()}, func() error {
+		return n.Netlink.AddrDel(ctx, link, addr)
+	})
+}
+
+// LinkAdd queues netlink.LinkAdd
+func (n *QueuedNetlink) LinkAdd(ctx context.Context, link netlink.Link) error {
+	return n.do(ctx, link.Attrs().Name, "LinkAdd", nil, func() error { return n.Netlink.LinkAdd(ctx, link) })
+}
+
+// LinkDel queues netlink.LinkDel
+func (n *QueuedNetlink) LinkDel(ctx context.Context, link netlink.Link) error {
+	return n.do(ctx, link.Attrs().Name, "LinkDel", []interface{}{}, func() error { return n.Netlink.LinkDel(ctx, link) })
+}
+
+// LinkSetUp queues netlink.LinkSetUp
+func (n *QueuedNetlink) LinkSetUp(ctx context.Context, link netlink.Link) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetUp", []interface{}{}, func() error { return n.Netlink.LinkSetUp(ctx, link) })
+}
+
+// LinkSetDown queues netlink.LinkSetDown
+func (n *QueuedNetlink) LinkSetDown(ctx context.Context, link netlink.Link) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetDown", []interface{}{}, func() error { return n.Netlink.LinkSetDown(ctx, link) })
+}
+
+// LinkSetMaster queues netlink.LinkSetMaster
+func (n *QueuedNetlink) LinkSetMaster(ctx context.Context, link netlink.Link, master netlink.Link) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetMaster", []interface{}{master.Attrs().Name}, func() error {
+		return n.Netlink.LinkSetMaster(ctx, link, master)
+	})
+}
+
+// LinkSetNoMaster queues netlink.LinkSetNoMaster
+func (n *QueuedNetlink) LinkSetNoMaster(ctx context.Context, link netlink.Link) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetNoMaster", []interface{}{}, func() error {
+		return n.Netlink.LinkSetNoMaster(ctx, link)
+	})
+}
+
+// LinkSetNsFd queues netlink.LinkSetNsFd
+func (n *QueuedNetlink) LinkSetNsFd(ctx context.Context, link netlink.Link, fd int) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetNsFd", nil, func() error { return n.Netlink.LinkSetNsFd(ctx, link, fd) })
+}
+
+// LinkSetName queues netlink.LinkSetName
+func (n *QueuedNetlink) LinkSetName(ctx context.Context, link netlink.Link, name string) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetName", []interface{}{name}, func() error {
+		return n.Netlink.LinkSetName(ctx, link, name)
+	})
+}
+
+// LinkSetAlias queues netlink.LinkSetAlias
+func (n *QueuedNetlink) LinkSetAlias(ctx context.Context, link netlink.Link, alias string) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetAlias", []interface{}{alias}, func() error {
+		return n.Netlink.LinkSetAlias(ctx, link, alias)
+	})
+}
+
+// LinkSetVfRate queues netlink.LinkSetVfRate
+func (n *QueuedNetlink) LinkSetVfRate(ctx context.Context, link netlink.Link, vf int, minRate int, maxRate int) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetVfRate", []interface{}{vf, minRate, maxRate}, func() error {
+		return n.Netlink.LinkSetVfRate(ctx, link, vf, minRate, maxRate)
+	})
+}
+
+// LinkSetVfSpoofchk queues netlink.LinkSetVfSpoofchk
+func (n *QueuedNetlink) LinkSetVfSpoofchk(ctx context.Context, link netlink.Link, vf int, check bool) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetVfSpoofchk", []interface{}{vf, check}, func() error {
+		return n.Netlink.LinkSetVfSpoofchk(ctx, link, vf, check)
+	})
+}
+
+// LinkSetVfTrust queues netlink.LinkSetVfTrust
+func (n *QueuedNetlink) LinkSetVfTrust(ctx context.Context, link netlink.Link, vf int, state bool) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetVfTrust", []interface{}{vf, state}, func() error {
+		return n.Netlink.LinkSetVfTrust(ctx, link, vf, state)
+	})
+}
+
+// LinkSetVfState queues netlink.LinkSetVfState
+func (n *QueuedNetlink) LinkSetVfState(ctx context.Context, link netlink.Link, vf int, state uint32) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetVfState", []interface{}{vf, state}, func() error {
+		return n.Netlink.LinkSetVfState(ctx, link, vf, state)
+	})
+}
+
+// BridgeVlanAdd queues netlink.BridgeVlanAdd
+func (n *QueuedNetlink) BridgeVlanAdd(ctx context.Context, link netlink.Link, vid uint16, pvid bool, untagged bool, self bool, master bool) error {
+	return n.do(ctx, link.Attrs().Name, "BridgeVlanAdd", []interface{}{vid, pvid, untagged, self, master}, func() error {
+		return n.Netlink.BridgeVlanAdd(ctx, link, vid, pvid, untagged, self, master)
+	})
+}
+
+// BridgeVlanDel queues netlink.BridgeVlanDel
+func (n *QueuedNetlink) BridgeVlanDel(ctx context.Context, link netlink.Link, vid uint16, pvid bool, untagged bool, self bool, master bool) error {
+	return n.do(ctx, link.Attrs().Name, "BridgeVlanDel", []interface{}{vid, pvid, untagged, self, master}, func() error {
+		return n.Netlink.BridgeVlanDel(ctx, link, vid, pvid, untagged, self, master)
+	})
+}
+
+// LinkSetMTU queues netlink.LinkSetMTU
+func (n *QueuedNetlink) LinkSetMTU(ctx context.Context, link netlink.Link, mtu int) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetMTU", []interface{}{mtu}, func() error {
+		return n.Netlink.LinkSetMTU(ctx, link, mtu)
+	})
+}
+
+// BridgeFdbAdd queues the addition of the fdb entry
+func (n *QueuedNetlink) BridgeFdbAdd(ctx context.Context, link string, macAddress string) error {
+	return n.do(ctx, link, "BridgeFdbAdd", []interface{}{macAddress}, func() error {
+		return n.Netlink.BridgeFdbAdd(ctx, link, macAddress)
+	})
+}
+
+// RouteAdd queues netlink.RouteAdd
+func (n *QueuedNetlink) RouteAdd(ctx context.Context, route *netlink.Route) error {
+	return n.do(ctx, routeObject(route), "RouteAdd", []interface{}{route.String()}, func() error {
+		return n.Netlink.RouteAdd(ctx, route)
+	})
+}
+
+// RouteDel queues netlink.RouteDel
+func (n *QueuedNetlink) RouteDel(ctx context.Context, route *netlink.Route) error {
+	return n.do(ctx, routeObject(route), "RouteDel", []interface{}{route.String()}, func() error {
+		return n.Netlink.RouteDel(ctx, route)
+	})
+}
+
+// RouteFlushTable queues the flush of the routing table
+func (n *QueuedNetlink) RouteFlushTable(ctx context.Context, table string) error {
+	return n.do(ctx, "table "+table, "RouteFlushTable", []interface{}{}, func() error {
+		return n.Netlink.RouteFlushTable(ctx, table)
+	})
+}
+
+// LinkSetBrNeighSuppress queues the change of the neighbor suppression of the bridge port
+func (n *QueuedNetlink) LinkSetBrNeighSuppress(ctx context.Context, link netlink.Link, suppress bool) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetBrNeighSuppress", []interface{}{suppress}, func() error {
+		return n.Netlink.LinkSetBrNeighSuppress(ctx, link, suppress)
+	})
+}
+
+// LinkSetIsolated queues the change of the isolation of the bridge port
+func (n *QueuedNetlink) LinkSetIsolated(ctx context.Context, link netlink.Link, isolated bool) error {
+	return n.do(ctx, link.Attrs().Name, "LinkSetIsolated", []interface{}{isolated}, func() error {
+		return n.Netlink.LinkSetIsolated(ctx, link, isolated)
+	})
+}
+
+// routeObject names the destination of the route in its table
+func routeObject(route *netlink.Route) string {
+	return fmt.Sprintf("route %d %v", route.Table, route.Dst)
+}
+
+// QueuedFrr sends the commands changing the FRR configuration through a write queue,
+// the show commands are sent directly
+type QueuedFrr struct {
+	Frr
+	queue *WriteQueue
+}
+
+// NewQueuedFrr returns the FRR wrapper writing through the queue
+func NewQueuedFrr(f Frr, q *WriteQueue) *QueuedFrr {
+	return &QueuedFrr{Frr: f, queue: q}
+}
+
+// WithFrrWriteQueue returns the FRR wrapper writing through the queue of the namespace
+func WithFrrWriteQueue(f Frr, namespace string) Frr {
+	return NewQueuedFrr(f, WriteQueueFor(namespace))
+}
+
+// build time check that struct implements interface
+var _ Frr = (*QueuedFrr)(nil)
+
+// FrrZebraCmd queues the zebra command
+func (f *QueuedFrr) FrrZebraCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error) {
+	if cmdTypeShow {
+		return f.Frr.FrrZebraCmd(ctx, command, cmdTypeShow)
+	}
+	return f.queue.Do(ctx, "zebra", "zebra "+command, func() (string, error) {
+		return f.Frr.FrrZebraCmd(ctx, command, cmdTypeShow)
+	})
+}
+
+// FrrBgpCmd queues the bgpd command
+func (f *QueuedFrr) FrrBgpCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error) {
+	if cmdTypeShow {
+		return f.Frr.FrrBgpCmd(ctx, command, cmdTypeShow)
+	}
+	return f.queue.Do(ctx, "bgpd", "bgpd "+command, func() (string, error) {
+		return f.Frr.FrrBgpCmd(ctx, command, cmdTypeShow)
+	})
+}
+
+// FrrLdpCmd queues the ldpd command
+func (f *QueuedFrr) FrrLdpCmd(ctx context.Context, command string, cmdTypeShow bool) (string, error) {
+	if cmdTypeShow {
+		return f.Frr.FrrLdpCmd(ctx, command, cmdTypeShow)
+	}
+	return f.queue.Do(ctx, "ldpd", "ldpd "+command, func() (string, error) {
+		return f.Frr.FrrLdpCmd(ctx, command, cmdTypeShow)
+	})
+}
+
+// Save queues the save of the FRR configuration
+func (f *QueuedFrr) Save(ctx context.Context) error {
+	_, err := f.queue.Do(ctx, "frr.conf", "save", func() (string, error) { return "", f.Frr.Save(ctx) })
+	return err
+}
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package utils has some utility functions and interfaces
+package utils
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// waitEnqueued waits until the queue has seen the number of operations
+func waitEnqueued(t *testing.T, q *WriteQueue, n uint64) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); q.Stats().Enqueued < n; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d operations to be enqueued, %+v", n, q.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func Test_WriteQueueOrderAndMerge(t *testing.T) {
+	q := newWriteQueue("test")
+	go q.process()
+
+	var mu sync.Mutex
+	var issued []string
+	record := func(key string) func() (string, error) {
+		return func() (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			issued = append(issued, key)
+			return key, nil
+		}
+	}
+
+	// hold the queue until all the operations are enqueued
+	release := make(chan struct{})
+	go func() {
+		_, _ = q.Do(context.Background(), "gate", "", func() (string, error) { <-release; return "", nil })
+	}()
+	waitEnqueued(t, q, 1)
+
+	var wg sync.WaitGroup
+	results := make([]string, 4)
+	for i, key := range []string{"up vxlan-blue", "up vxlan-blue", "down vxlan-blue", "up vxlan-blue"} {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			results[i], _ = q.Do(context.Background(), "vxlan-blue", key, record(key))
+		}(i, key)
+		waitEnqueued(t, q, uint64(i+2))
+	}
+	if pending := q.Stats().Pending; len(pending) != 3 {
+		t.Errorf("expected the second operation to be merged into the first, pending %v", pending)
+	}
+	close(release)
+	wg.Wait()
+
+	if want := []string{"up vxlan-blue", "down vxlan-blue", "up vxlan-blue"}; !reflect.DeepEqual(issued, want) {
+		t.Errorf("expected the operations %v to be issued in order, issued %v", want, issued)
+	}
+	if results[1] != "up vxlan-blue" {
+		t.Errorf("expected the merged operation to share the result, received %q", results[1])
+	}
+	if stats := q.Stats(); stats.Merged != 1 || stats.Executed != 4 || stats.Failed != 0 {
+		t.Errorf("unexpected counters %+v", stats)
+	}
+}
+
+func Test_WriteQueueRetry(t *testing.T) {
+	q := newWriteQueue("test")
+	go q.process()
+
+	attempts := 0
+	_, err := q.Do(context.Background(), "vrf-blue", "", func() (string, error) {
+		attempts++
+		if attempts < 3 {
+			return "", syscall.EBUSY
+		}
+		return "", nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("expected the busy operation to succeed on the third attempt, attempts %d: %v", attempts, err)
+	}
+
+	attempts = 0
+	_, err = q.Do(context.Background(), "vrf-blue", "", func() (string, error) {
+		attempts++
+		return "", syscall.EINVAL
+	})
+	if !errors.Is(err, syscall.EINVAL) || attempts != 1 {
+		t.Errorf("expected the invalid operation to fail without retry, attempts %d: %v", attempts, err)
+	}
+
+	attempts = 0
+	_, err = q.Do(context.Background(), "vrf-blue", "", func() (string, error) {
+		attempts++
+		return "", syscall.EAGAIN
+	})
+	if !errors.Is(err, syscall.EAGAIN) || attempts != writeAttempts {
+		t.Errorf("expected the operation to give up after %d attempts, attempts %d: %v", writeAttempts, attempts, err)
+	}
+
+	if stats := q.Stats(); stats.Retried != 4 || stats.Failed != 2 || stats.LastError == "" {
+		t.Errorf("unexpected counters %+v", stats)
+	}
+}
+
+func Test_QueuedFrrShow(t *testing.T) {
+	q := newWriteQueue("test")
+	go q.process()
+	release := make(chan struct{})
+	defer close(release)
+	go func() {
+		_, _ = q.Do(context.Background(), "gate", "", func() (string, error) { <-release; return "", nil })
+	}()
+	waitEnqueued(t, q, 1)
+
+	inner := &countingFrr{}
+	f := NewQueuedFrr(inner, q)
+	if _, err := f.FrrBgpCmd(context.Background(), "show bgp summary", true); err != nil || inner.sent != 1 {
+		t.Errorf("expected the show command to bypass the busy queue, sent %d: %v", inner.sent, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := f.FrrBgpCmd(ctx, "router bgp 65000", false); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the configuration command to wait for the queue, received %v", err)
+	}
+}
@@ -17,10 +17,12 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/pbconv"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
 )
 
@@ -37,7 +39,7 @@ func (s *Server) createVrf(vrf *pb.Vrf) (*pb.Vrf, error) {
 	}
 
 	// translation of pb to domain object
-	domainVrf, err := infradb.NewVrf(vrf)
+	domainVrf, err := pbconv.VrfFromPb(vrf)
 	if err != nil {
 		return nil, err
 	}
@@ -45,12 +47,12 @@ func (s *Server) createVrf(vrf *pb.Vrf) (*pb.Vrf, error) {
 	if err := infradb.CreateVrf(domainVrf); err != nil {
 		return nil, err
 	}
-	return domainVrf.ToPb(), nil
+	return pbconv.VrfToPb(domainVrf), nil
 }
 
-func (s *Server) deleteVrf(name string) error {
+func (s *Server) deleteVrf(name string, opts ...infradb.WriteOption) error {
 	// Note: The status of the object will be generated in infraDB operation not here
-	if err := infradb.DeleteVrf(name); err != nil {
+	if err := infradb.DeleteVrf(name, opts...); err != nil {
 		return err
 	}
 	return nil
@@ -61,7 +63,7 @@ func (s *Server) getVrf(name string) (*pb.Vrf, error) {
 	if err != nil {
 		return nil, err
 	}
-	return domainVrf.ToPb(), nil
+	return pbconv.VrfToPb(domainVrf), nil
 }
 
 func (s *Server) getAllVrfs() ([]*pb.Vrf, error) {
@@ -72,27 +74,41 @@ func (s *Server) getAllVrfs() ([]*pb.Vrf, error) {
 	}
 
 	for _, domainVrf := range domainVrfs {
-		vrfs = append(vrfs, domainVrf.ToPb())
+		vrfs = append(vrfs, pbconv.VrfToPb(domainVrf))
 	}
 	return vrfs, nil
 }
 
-func (s *Server) updateVrf(vrf *pb.Vrf) (*pb.Vrf, error) {
+func (s *Server) updateVrf(vrf *pb.Vrf, opts ...infradb.WriteOption) (*pb.Vrf, error) {
 	// check parameters
 	if err := s.validateVrfSpec(vrf); err != nil {
 		return nil, err
 	}
 
 	// translation of pb to domain object
-	domainVrf, err := infradb.NewVrf(vrf)
+	domainVrf, err := pbconv.VrfFromPb(vrf)
 	if err != nil {
 		return nil, err
 	}
 	// Note: The status of the object will be generated in infraDB operation not here
-	if err := infradb.UpdateVrf(domainVrf); err != nil {
+	if err := infradb.UpdateVrf(domainVrf, opts...); err != nil {
 		return nil, err
 	}
-	return domainVrf.ToPb(), nil
+	return pbconv.VrfToPb(domainVrf), nil
+}
+
+// sameVrfSpec tells whether the create request replays the creation of the existing VRF. The spec of the
+// request is compared in its stored form, a VNI left to the VNI pool matches the allocated one.
+func (s *Server) sameVrfSpec(vrf *pb.Vrf, existing *pb.Vrf) bool {
+	domainVrf, err := pbconv.VrfFromPb(vrf)
+	if err != nil {
+		return false
+	}
+	spec := pbconv.VrfToPb(domainVrf).Spec
+	if spec.Vni == nil || *spec.Vni == 0 {
+		spec.Vni = existing.Spec.Vni
+	}
+	return proto.Equal(spec, existing.Spec)
 }
 
 func resourceIDToFullName(resourceID string) string {
@@ -134,7 +150,7 @@ func (s *Server) TestCreateVrf(vrf *pb.Vrf) (*pb.Vrf, error) {
 	}
 
 	// translation of pb to domain object
-	domainVrf, err := infradb.NewVrf(vrf)
+	domainVrf, err := pbconv.VrfFromPb(vrf)
 	if err != nil {
 		return nil, err
 	}
@@ -142,7 +158,7 @@ func (s *Server) TestCreateVrf(vrf *pb.Vrf) (*pb.Vrf, error) {
 	if err := infradb.CreateVrf(domainVrf); err != nil {
 		return nil, err
 	}
-	return domainVrf.ToPb(), nil
+	return pbconv.VrfToPb(domainVrf), nil
 }
 
 func newTestEnv(ctx context.Context, t *testing.T) *testEnv {
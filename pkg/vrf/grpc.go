@@ -15,6 +15,7 @@ import (
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
 	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"go.einride.tech/aip/resourceid"
 	"google.golang.org/grpc/codes"
@@ -23,12 +24,16 @@ import (
 )
 
 // CreateVrf executes the creation of the VRF
-func (s *Server) CreateVrf(_ context.Context, in *pb.CreateVrfRequest) (*pb.Vrf, error) {
+func (s *Server) CreateVrf(ctx context.Context, in *pb.CreateVrfRequest) (*pb.Vrf, error) {
 	// check input correctness
 	if err := s.validateCreateVrfRequest(in); err != nil {
 		log.Printf("CreateVrf(): validation failure: %v", err)
 		return nil, err
 	}
+	if err := utils.ContextError(ctx); err != nil {
+		log.Printf("CreateVrf(): %v", err)
+		return nil, err
+	}
 	// see https://google.aip.dev/133#user-specified-ids
 	resourceID := resourceid.NewSystemGenerated()
 	if in.VrfId != "" {
@@ -36,7 +41,7 @@ func (s *Server) CreateVrf(_ context.Context, in *pb.CreateVrfRequest) (*pb.Vrf,
 		resourceID = in.VrfId
 	}
 	in.Vrf.Name = resourceIDToFullName(resourceID)
-	// idempotent API when called with same key, should return same object
+	// idempotent API when called with same key and spec, should return same object
 	vrfObj, err := s.getVrf(in.Vrf.Name)
 	if err != nil {
 		if err != infradb.ErrKeyNotFound {
@@ -44,6 +49,15 @@ func (s *Server) CreateVrf(_ context.Context, in *pb.CreateVrfRequest) (*pb.Vrf,
 			return nil, err
 		}
 	} else {
+		if err := s.validateVrfSpec(in.Vrf); err != nil {
+			log.Printf("CreateVrf(): validation failure: %v", err)
+			return nil, err
+		}
+		if !s.sameVrfSpec(in.Vrf, vrfObj) {
+			err := apierrors.AlreadyExists("vrfs", in.Vrf.Name, "%s already exists with another spec", in.Vrf.Name)
+			log.Printf("CreateVrf(): %v", err)
+			return nil, err
+		}
 		log.Printf("CreateVrf(): Already existing Vrf with id %v", in.Vrf.Name)
 		return vrfObj, nil
 	}
@@ -58,12 +72,16 @@ func (s *Server) CreateVrf(_ context.Context, in *pb.CreateVrfRequest) (*pb.Vrf,
 }
 
 // DeleteVrf deletes a VRF
-func (s *Server) DeleteVrf(_ context.Context, in *pb.DeleteVrfRequest) (*emptypb.Empty, error) {
+func (s *Server) DeleteVrf(ctx context.Context, in *pb.DeleteVrfRequest) (*emptypb.Empty, error) {
 	// check input correctness
 	if err := s.validateDeleteVrfRequest(in); err != nil {
 		log.Printf("DeleteVrf(): validation failure: %v", err)
 		return nil, err
 	}
+	if err := utils.ContextError(ctx); err != nil {
+		log.Printf("DeleteVrf(): %v", err)
+		return nil, err
+	}
 	// fetch object from the database
 	_, err := s.getVrf(in.Name)
 	if err != nil {
@@ -72,14 +90,14 @@ func (s *Server) DeleteVrf(_ context.Context, in *pb.DeleteVrfRequest) (*emptypb
 			return nil, err
 		}
 		if !in.AllowMissing {
-			err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+			err = apierrors.NotFound("vrfs", in.Name)
 			log.Printf("DeleteVrf(): Vrf with id %v: Not Found %v", in.Name, err)
 			return nil, err
 		}
 		return &emptypb.Empty{}, nil
 	}
 
-	if err := s.deleteVrf(in.Name); err != nil {
+	if err := s.deleteVrf(in.Name, infradb.IfMatch(utils.IfMatch(ctx))); err != nil {
 		log.Printf("DeleteVrf(): Vrf with id %v, Delete Vrf from DB failure: %v", in.Name, err)
 		return nil, err
 	}
@@ -88,12 +106,16 @@ func (s *Server) DeleteVrf(_ context.Context, in *pb.DeleteVrfRequest) (*emptypb
 }
 
 // UpdateVrf updates an VRF
-func (s *Server) UpdateVrf(_ context.Context, in *pb.UpdateVrfRequest) (*pb.Vrf, error) {
+func (s *Server) UpdateVrf(ctx context.Context, in *pb.UpdateVrfRequest) (*pb.Vrf, error) {
 	// check input correctness
 	if err := s.validateUpdateVrfRequest(in); err != nil {
 		log.Printf("UpdateVrf(): validation failure: %v", err)
 		return nil, err
 	}
+	if err := utils.ContextError(ctx); err != nil {
+		log.Printf("UpdateVrf(): %v", err)
+		return nil, err
+	}
 	// fetch object from the database
 	vrfObj, err := s.getVrf(in.Vrf.Name)
 	if err != nil {
@@ -102,7 +124,7 @@ func (s *Server) UpdateVrf(_ context.Context, in *pb.UpdateVrfRequest) (*pb.Vrf,
 			return nil, err
 		}
 		if !in.AllowMissing {
-			err = status.Errorf(codes.NotFound, "unable to find key %s", in.Vrf.Name)
+			err = apierrors.NotFound("vrfs", in.Vrf.Name)
 			log.Printf("UpdateVrf(): Vrf with id %v: Not Found %v", in.Vrf.Name, err)
 			return nil, err
 		}
@@ -131,13 +153,18 @@ func (s *Server) UpdateVrf(_ context.Context, in *pb.UpdateVrfRequest) (*pb.Vrf,
 	// Apply updateMask to the current Pb object
 	utils.ApplyMaskToStoredPbObject(in.UpdateMask, updatedvrfObj, in.Vrf)
 
+	// The zero VNI allocates a VNI on create only, an update keeps the VNI in use
+	if updatedvrfObj.Spec.Vni != nil && *updatedvrfObj.Spec.Vni == 0 {
+		updatedvrfObj.Spec.Vni = vrfObj.Spec.Vni
+	}
+
 	// Check if the object before the application of the field mask
 	// is different with the one after the application of the field mask
 	if reflect.DeepEqual(vrfObj, updatedvrfObj) {
 		return vrfObj, nil
 	}
 
-	response, err := s.updateVrf(updatedvrfObj)
+	response, err := s.updateVrf(updatedvrfObj, infradb.IfMatch(utils.IfMatch(ctx)))
 	if err != nil {
 		log.Printf("UpdateVrf(): Vrf with id %v, Update Vrf to DB failure: %v", in.Vrf.Name, err)
 		return nil, err
@@ -160,7 +187,7 @@ func (s *Server) GetVrf(_ context.Context, in *pb.GetVrfRequest) (*pb.Vrf, error
 			log.Printf("Failed to interact with store: %v", err)
 			return nil, err
 		}
-		err = status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+		err = apierrors.NotFound("vrfs", in.Name)
 		log.Printf("GetVrf(): Vrf with id %v: Not Found %v", in.Name, err)
 		return nil, err
 	}
@@ -176,7 +203,9 @@ func (s *Server) ListVrfs(_ context.Context, in *pb.ListVrfsRequest) (*pb.ListVr
 		return nil, err
 	}
 	// fetch pagination from the database, calculate size and offset
+	s.paginationLock.Lock()
 	size, offset, err := utils.ExtractPagination(in.PageSize, in.PageToken, s.Pagination)
+	s.paginationLock.Unlock()
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +228,9 @@ func (s *Server) ListVrfs(_ context.Context, in *pb.ListVrfsRequest) (*pb.ListVr
 	token := ""
 	if hasMoreElements {
 		token = uuid.New().String()
+		s.paginationLock.Lock()
 		s.Pagination[token] = offset + size
+		s.paginationLock.Unlock()
 	}
 	return &pb.ListVrfsResponse{Vrfs: Blobarray, NextPageToken: token}, nil
 }
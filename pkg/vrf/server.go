@@ -6,6 +6,8 @@
 package vrf
 
 import (
+	"sync"
+
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 
@@ -16,7 +18,9 @@ import (
 type Server struct {
 	pb.UnimplementedVrfServiceServer
 	Pagination map[string]int
-	tracer     trace.Tracer
+	// paginationLock guards Pagination, the List calls run concurrently
+	paginationLock sync.Mutex
+	tracer         trace.Tracer
 }
 
 // NewServer creates initialized instance of EVPN server
@@ -6,21 +6,20 @@
 package vrf
 
 import (
-	"fmt"
-
 	"go.einride.tech/aip/fieldbehavior"
 	"go.einride.tech/aip/fieldmask"
 	"go.einride.tech/aip/resourceid"
 	"go.einride.tech/aip/resourcename"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"github.com/opiproject/opi-evpn-bridge/pkg/apierrors"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/common"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 )
 
 func (s *Server) validateCreateVrfRequest(in *pb.CreateVrfRequest) error {
 	// check required fields
-	if err := fieldbehavior.ValidateRequiredFields(in); err != nil {
+	if err := utils.ValidateRequiredFields(in); err != nil {
 		return err
 	}
 	// see https://google.aip.dev/133#user-specified-ids
@@ -33,12 +32,24 @@ func (s *Server) validateCreateVrfRequest(in *pb.CreateVrfRequest) error {
 }
 
 func (s *Server) validateVrfSpec(vrf *pb.Vrf) error {
-	// check vni is in range
-	if (vrf.Spec.Vni != nil) && (*vrf.Spec.Vni < 1 || *vrf.Spec.Vni > 16777215) {
-		msg := fmt.Sprintf("Vni value (%d) have to be between 1 and 16777215", *vrf.Spec.Vni)
-		return status.Errorf(codes.InvalidArgument, msg)
+	// check vni is in range, a zero vni is allocated from the vni pool
+	if (vrf.Spec.Vni != nil) && (*vrf.Spec.Vni > 16777215) {
+		return apierrors.InvalidField("vrf.spec.vni", apierrors.ReasonOutOfRange,
+			"Vni value (%d) have to be between 0 and 16777215", *vrf.Spec.Vni)
+	}
+	// a VRF without loopback takes the router id of its BGP instance from its VTEP
+	if vrf.Spec.LoopbackIpPrefix != nil {
+		if _, err := common.ConvertToIPNet(vrf.Spec.LoopbackIpPrefix); err != nil {
+			return apierrors.InvalidField("vrf.spec.loopback_ip_prefix", apierrors.ReasonInvalidAddress,
+				"Invalid loopback_ip_prefix: %v", err)
+		}
+	}
+	if vrf.Spec.VtepIpPrefix != nil {
+		if _, err := common.ConvertToIPNet(vrf.Spec.VtepIpPrefix); err != nil {
+			return apierrors.InvalidField("vrf.spec.vtep_ip_prefix", apierrors.ReasonInvalidAddress,
+				"Invalid vtep_ip_prefix: %v", err)
+		}
 	}
-	// Dimitris: Do we need to validate the loopback_ip_prefix, vtep_ip_prefix ?
 	return nil
 }
 
@@ -53,7 +64,7 @@ func (s *Server) validateDeleteVrfRequest(in *pb.DeleteVrfRequest) error {
 
 func (s *Server) validateUpdateVrfRequest(in *pb.UpdateVrfRequest) error {
 	// check required fields
-	if err := fieldbehavior.ValidateRequiredFields(in); err != nil {
+	if err := utils.ValidateRequiredFields(in); err != nil {
 		return err
 	}
 	// update_mask = 2
@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 
 	"google.golang.org/grpc/codes"
@@ -20,6 +21,7 @@ import (
 	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
 	pc "github.com/opiproject/opi-api/network/opinetcommon/v1alpha1/gen/go"
 
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
 	"github.com/opiproject/opi-evpn-bridge/pkg/utils/mocks"
 )
@@ -92,14 +94,24 @@ func Test_CreateVrf(t *testing.T) {
 			exist:   false,
 			on:      nil,
 		},
-		"no required loopback_ip_prefix field": {
+		"optional loopback_ip_prefix field": {
 			id: testVrfID,
 			in: &pb.Vrf{
-				Spec: &pb.VrfSpec{},
+				Spec: &pb.VrfSpec{
+					Vni:          testVrf.Spec.Vni,
+					VtepIpPrefix: testVrf.Spec.VtepIpPrefix,
+				},
 			},
-			out:     nil,
-			errCode: codes.Unknown,
-			errMsg:  "missing required field: vrf.spec.loopback_ip_prefix",
+			out: &pb.Vrf{
+				Name: testVrfName,
+				Spec: &pb.VrfSpec{
+					Vni:          testVrf.Spec.Vni,
+					VtepIpPrefix: testVrf.Spec.VtepIpPrefix,
+				},
+				Status: testVrfWithStatus.Status,
+			},
+			errCode: codes.OK,
+			errMsg:  "",
 			exist:   false,
 			on:      nil,
 		},
@@ -112,6 +124,21 @@ func Test_CreateVrf(t *testing.T) {
 			exist:   true,
 			on:      nil,
 		},
+		"already exists with another spec": {
+			id: testVrfID,
+			in: &pb.Vrf{
+				Spec: &pb.VrfSpec{
+					Vni:              proto.Uint32(1001),
+					LoopbackIpPrefix: testVrf.Spec.LoopbackIpPrefix,
+					VtepIpPrefix:     testVrf.Spec.VtepIpPrefix,
+				},
+			},
+			out:     nil,
+			errCode: codes.AlreadyExists,
+			errMsg:  fmt.Sprintf("%s already exists with another spec", testVrfName),
+			exist:   true,
+			on:      nil,
+		},
 		"valid request empty VNI": {
 			id: testVrfID,
 			in: &pb.Vrf{
@@ -211,6 +238,21 @@ func Test_CreateVrf(t *testing.T) {
 	}
 }
 
+func Test_CreateVrfCancelled(t *testing.T) {
+	env := newTestEnv(context.Background(), t)
+	defer env.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	request := &pb.CreateVrfRequest{Vrf: utils.ProtoClone(&testVrf), VrfId: testVrfID}
+	if _, err := env.opi.CreateVrf(ctx, request); status.Code(err) != codes.Canceled {
+		t.Error("error code: expected", codes.Canceled, "received", err)
+	}
+	if _, err := env.opi.getVrf(testVrfName); err != infradb.ErrKeyNotFound {
+		t.Error("expected the cancelled call to leave the store untouched, received", err)
+	}
+}
+
 func Test_DeleteVrf(t *testing.T) {
 	tests := map[string]struct {
 		in      string
@@ -543,3 +585,35 @@ func Test_ListVrfs(t *testing.T) {
 		})
 	}
 }
+
+func Test_ListVrfsConcurrently(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(ctx, t)
+	defer env.Close()
+	client := pb.NewVrfServiceClient(env.conn)
+
+	for i := 0; i < 3; i++ {
+		vrf := &pb.Vrf{Name: resourceIDToFullName(fmt.Sprintf("opi-list-%d", i)), Spec: &pb.VrfSpec{}}
+		if _, err := env.opi.createVrf(vrf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the page tokens of the parallel lists are recorded by the same server
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			first, err := client.ListVrfs(ctx, &pb.ListVrfsRequest{PageSize: 1})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err := client.ListVrfs(ctx, &pb.ListVrfsRequest{PageSize: 1, PageToken: first.NextPageToken}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package webhook sends the status events of the objects to the registered http(s) endpoints,
+// for the orchestrators which cannot hold a gRPC stream
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+const (
+	// EventHeader holds the type of the event
+	EventHeader = "X-Opi-Event"
+	// DeliveryHeader identifies a delivery, it is the same across the retries of the delivery
+	DeliveryHeader = "X-Opi-Delivery"
+	// SignatureHeader holds sha256=<hex HMAC-SHA256 of the body with the secret of the webhook>
+	SignatureHeader = "X-Opi-Signature-256"
+)
+
+const (
+	// queueSize bounds the events waiting for delivery, the newer events are dropped when it is full
+	queueSize = 1024
+	// defaultRetries is the number of retries of a failed delivery when none is configured
+	defaultRetries = 5
+	// defaultTimeout bounds a post when no timeout is configured
+	defaultTimeout = 10 * time.Second
+)
+
+// retryInterval is the delay before the first retry of a delivery, doubled at each retry
+var retryInterval = time.Second
+
+// Sign returns the signature of the body with the secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher delivers the status events to the webhooks matching them
+type Dispatcher struct {
+	events  chan infradb.StatusEvent
+	client  *http.Client
+	retries int
+}
+
+// NewDispatcher returns a dispatcher whose posts go through the management vrf when there is one
+func NewDispatcher(cfg *config.Config) *Dispatcher {
+	timeout := defaultTimeout
+	if cfg.Webhooks.Timeout > 0 {
+		timeout = time.Duration(cfg.Webhooks.Timeout) * time.Second
+	}
+	retries := defaultRetries
+	if cfg.Webhooks.Retries != 0 {
+		retries = max(cfg.Webhooks.Retries, 0)
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	if vrf := cfg.Management.Vrf; vrf != "" {
+		dialer.Control = utils.BindToDevice(vrf)
+	}
+	return &Dispatcher{
+		events:  make(chan infradb.StatusEvent, queueSize),
+		client:  &http.Client{Timeout: timeout, Transport: &http.Transport{DialContext: dialer.DialContext}},
+		retries: retries,
+	}
+}
+
+// Enqueue queues the event for delivery without blocking, it is the status listener of the DB
+func (d *Dispatcher) Enqueue(event infradb.StatusEvent) {
+	select {
+	case d.events <- event:
+	default:
+		log.Printf("webhook: queue full, dropping the %s event of %s\n", event.Type, event.Name)
+	}
+}
+
+// Run delivers the queued events until the context is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-d.events:
+			webhooks, err := infradb.GetAllWebhooks()
+			if err != nil {
+				log.Printf("webhook: failed to read the webhooks: %v\n", err)
+				continue
+			}
+			body, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("webhook: failed to encode the event: %v\n", err)
+				continue
+			}
+			for _, w := range webhooks {
+				if w.Matches(event) {
+					// a slow endpoint only delays its own deliveries
+					go d.deliver(ctx, w, string(event.Type), body)
+				}
+			}
+		}
+	}
+}
+
+// deliver posts the event to the webhook, retrying with an exponential backoff
+func (d *Dispatcher) deliver(ctx context.Context, w *infradb.Webhook, eventType string, body []byte) {
+	delivery := uuid.NewString()
+	interval := retryInterval
+	for attempt := 0; ; attempt++ {
+		err := d.post(ctx, w, eventType, delivery, body)
+		if err == nil {
+			return
+		}
+		if attempt >= d.retries {
+			log.Printf("webhook: giving up the delivery %s to %s after %d attempts: %v\n", delivery, w.Name, attempt+1, err)
+			return
+		}
+		log.Printf("webhook: delivery %s to %s failed, retrying in %v: %v\n", delivery, w.Name, interval, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+}
+
+// post sends one attempt of a delivery, any status but 2xx is a failure
+func (d *Dispatcher) post(ctx context.Context, w *infradb.Webhook, eventType, delivery string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventType)
+	req.Header.Set(DeliveryHeader, delivery)
+	if w.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("POST %s: %s", w.URL, resp.Status)
+	}
+	return nil
+}
+
+// Start registers the dispatcher as a listener of the status events of the DB and delivers them
+func Start(ctx context.Context, cfg *config.Config) {
+	d := NewDispatcher(cfg)
+	infradb.OnStatusChange(d.Enqueue)
+	go d.Run(ctx)
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package webhook sends the status events of the objects to the registered http(s) endpoints,
+// for the orchestrators which cannot hold a gRPC stream
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+)
+
+// endpoint is a webhook endpoint failing the first posts
+type endpoint struct {
+	mu         sync.Mutex
+	failures   int
+	attempts   int
+	deliveries map[string]bool
+	events     []infradb.StatusEvent
+	signatures []string
+	received   chan struct{}
+}
+
+func (e *endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.attempts++
+	e.deliveries[r.Header.Get(DeliveryHeader)] = true
+	if e.attempts <= e.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	event := infradb.StatusEvent{}
+	_ = json.Unmarshal(body, &event)
+	e.events = append(e.events, event)
+	e.signatures = append(e.signatures, r.Header.Get(SignatureHeader))
+	if r.Header.Get(SignatureHeader) != Sign("s3cr3t", body) {
+		e.signatures[len(e.signatures)-1] = "invalid"
+	}
+	e.received <- struct{}{}
+}
+
+func Test_Dispatcher(t *testing.T) {
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	retryInterval = 10 * time.Millisecond
+	defer func() { retryInterval = time.Second }()
+	e := &endpoint{failures: 2, deliveries: map[string]bool{}, received: make(chan struct{}, 1)}
+	server := httptest.NewServer(e)
+	defer server.Close()
+	if err := infradb.SetWebhook(&infradb.Webhook{Name: "failures", URL: server.URL, Secret: "s3cr3t", Types: []infradb.StatusEventType{infradb.StatusEventFailed}}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := NewDispatcher(&config.Config{})
+	go d.Run(ctx)
+	d.Enqueue(infradb.StatusEvent{Type: infradb.StatusEventUp, Kind: "vrf", Name: "//network.opiproject.org/vrfs/blue"})
+	d.Enqueue(infradb.StatusEvent{Type: infradb.StatusEventFailed, Kind: "svi", Name: "//network.opiproject.org/svis/blue-web", Component: "frr"})
+
+	select {
+	case <-e.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the failure to be delivered")
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.events) != 1 || e.events[0].Name != "//network.opiproject.org/svis/blue-web" || e.events[0].Component != "frr" {
+		t.Errorf("expected only the failure to be delivered, received %+v", e.events)
+	}
+	if e.attempts != 3 || len(e.deliveries) != 1 {
+		t.Errorf("expected one delivery retried twice, received %d attempts of %d deliveries", e.attempts, len(e.deliveries))
+	}
+	if e.signatures[0] == "invalid" {
+		t.Error("expected the body to be signed with the secret of the webhook")
+	}
+}
+
+func Test_Matches(t *testing.T) {
+	w := &infradb.Webhook{Kinds: []string{"vrf", "svi"}}
+	if !w.Matches(infradb.StatusEvent{Type: infradb.StatusEventDeleted, Kind: "svi"}) {
+		t.Error("expected a webhook without types to receive all the types")
+	}
+	if w.Matches(infradb.StatusEvent{Type: infradb.StatusEventUp, Kind: "bridge-port"}) {
+		t.Error("expected the events of the other kinds to be filtered out")
+	}
+}
@@ -0,0 +1,279 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ztp provisions a factory-fresh node without operator: the bridge fetches its initial bundle
+// from a provisioning server, applies it and reports the outcome back
+package ztp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/apply"
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/utils"
+)
+
+// State is the progress of the provisioning reported to the server
+type State string
+
+const (
+	// StateFetching is reported before each attempt to fetch the bundle
+	StateFetching State = "fetching"
+	// StateApplying is reported once the bundle is fetched and planned
+	StateApplying State = "applying"
+	// StateApplied is reported once the objects of the bundle are programmed
+	StateApplied State = "applied"
+	// StateFailed is reported when an attempt fails, the provisioning is retried unless it was the last attempt
+	StateFailed State = "failed"
+)
+
+// Status is the json body posted to the status url
+type Status struct {
+	Node    string      `json:"node"`
+	URL     string      `json:"url,omitempty"`
+	State   State       `json:"state"`
+	Attempt int         `json:"attempt"`
+	Plan    *apply.Plan `json:"plan,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+const (
+	// maxBundleSize bounds the bundle fetched from the server
+	maxBundleSize = 4 << 20
+	// defaultLeaseOption is the dhcp option 67 holding the url of the bundle
+	defaultLeaseOption = "bootfile-name"
+	// defaultRetryInterval is the time between two attempts when no interval is configured
+	defaultRetryInterval = 30 * time.Second
+	// defaultTimeout bounds the transaction applying the bundle when no timeout is configured
+	defaultTimeout = 5 * time.Minute
+	// requestTimeout bounds a request to the provisioning server
+	requestTimeout = 30 * time.Second
+)
+
+// defaultLeaseFiles are the dhclient lease files searched when none is configured
+var defaultLeaseFiles = []string{"/var/lib/dhcp/dhclient.leases", "/var/lib/dhclient/dhclient.leases"}
+
+// ErrNoURL is returned when neither the config nor the dhcp leases give the url of the bundle
+var ErrNoURL = errors.New("ztp: no bundle url configured nor found in the dhcp leases")
+
+// leaseOption returns the value of the last occurrence of the option in a dhclient lease file,
+// e.g. `option bootfile-name "https://ztp.example.com/dpu.yaml";`
+func leaseOption(file string, option string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	value := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(scanner.Text()), ";"))
+		if len(fields) == 3 && fields[0] == "option" && fields[1] == option {
+			value = strings.Trim(fields[2], `"`)
+		}
+	}
+	return value, scanner.Err()
+}
+
+// bundleURL returns the configured url of the bundle, or the one given by the dhcp server
+func bundleURL(cfg *config.ZtpConfig) (string, error) {
+	if cfg.URL != "" {
+		return cfg.URL, nil
+	}
+	files, option := cfg.LeaseFiles, cfg.LeaseOption
+	if len(files) == 0 {
+		files = defaultLeaseFiles
+	}
+	if option == "" {
+		option = defaultLeaseOption
+	}
+	for _, file := range files {
+		value, err := leaseOption(file, option)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("ztp: failed to read the lease file %s: %v", file, err)
+			continue
+		}
+		if value == "" {
+			continue
+		}
+		// the url comes from the network, only https is trusted
+		if u, err := url.Parse(value); err != nil || u.Scheme != "https" || u.Host == "" {
+			return "", fmt.Errorf("ztp: the %s option of %s is not an https url: %q", option, file, value)
+		}
+		return value, nil
+	}
+	return "", ErrNoURL
+}
+
+// newClient returns the https client of the provisioning server, reached through the management vrf when there is one
+func newClient(cfg *config.Config) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.Ztp.CAFile != "" {
+		pem, err := os.ReadFile(cfg.Ztp.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("ztp: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ztp: no certificate found in %s", cfg.Ztp.CAFile)
+		}
+	}
+	dialer := &net.Dialer{Timeout: requestTimeout}
+	if vrf := cfg.Management.Vrf; vrf != "" {
+		dialer.Control = utils.BindToDevice(vrf)
+	}
+	return &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			DialContext:     dialer.DialContext,
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+// fetch downloads the bundle
+func fetch(ctx context.Context, client *http.Client, bundleURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, bundleURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", bundleURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBundleSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxBundleSize {
+		return nil, fmt.Errorf("the bundle is larger than %d bytes", maxBundleSize)
+	}
+	return data, nil
+}
+
+// report posts the status to the server, a failure is only logged as the provisioning goes on without it
+func report(ctx context.Context, client *http.Client, statusURL string, s *Status) {
+	log.Printf("ztp: %s (attempt %d) %s", s.State, s.Attempt, s.Error)
+	if statusURL == "" {
+		return
+	}
+	body, err := json.Marshal(s)
+	if err != nil {
+		log.Printf("ztp: failed to encode the status: %v", err)
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, statusURL, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("ztp: failed to report the status: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("ztp: failed to report the status: %v", err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		log.Printf("ztp: failed to report the status: POST %s: %s", statusURL, resp.Status)
+	}
+}
+
+// provision fetches the bundle and applies it as a transaction covering the programming of its objects
+func provision(ctx context.Context, cfg *config.Config, client *http.Client, conn grpc.ClientConnInterface, s *Status) error {
+	var err error
+	if s.URL, err = bundleURL(&cfg.Ztp); err != nil {
+		return err
+	}
+	report(ctx, client, cfg.Ztp.StatusURL, s)
+	data, err := fetch(ctx, client, s.URL)
+	if err != nil {
+		return err
+	}
+	bundle, err := apply.Parse(data)
+	if err != nil {
+		return fmt.Errorf("invalid bundle: %w", err)
+	}
+	if s.Plan, err = apply.NewPlan(ctx, conn, bundle, false); err != nil {
+		return err
+	}
+	s.State = StateApplying
+	report(ctx, client, cfg.Ztp.StatusURL, s)
+	timeout := defaultTimeout
+	if cfg.Ztp.Timeout > 0 {
+		timeout = time.Duration(cfg.Ztp.Timeout) * time.Second
+	}
+	applyCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return s.Plan.ApplyAtomic(applyCtx, true)
+}
+
+// Run provisions the bridge through its gRPC API, retrying until the bundle is applied, the attempts
+// are exhausted or the context is done. It does nothing unless enabled or once the done file exists.
+func Run(ctx context.Context, cfg *config.Config, conn grpc.ClientConnInterface) error {
+	if !cfg.Ztp.Enabled {
+		return nil
+	}
+	if cfg.Ztp.DoneFile != "" {
+		if _, err := os.Stat(cfg.Ztp.DoneFile); err == nil {
+			log.Printf("ztp: already provisioned, %s exists", cfg.Ztp.DoneFile)
+			return nil
+		}
+	}
+	client, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+	node, _ := os.Hostname()
+	interval := defaultRetryInterval
+	if cfg.Ztp.RetryInterval > 0 {
+		interval = time.Duration(cfg.Ztp.RetryInterval) * time.Second
+	}
+	for attempt := 1; ; attempt++ {
+		s := &Status{Node: node, State: StateFetching, Attempt: attempt}
+		err = provision(ctx, cfg, client, conn, s)
+		if err == nil {
+			s.State = StateApplied
+			report(ctx, client, cfg.Ztp.StatusURL, s)
+			break
+		}
+		s.State, s.Error = StateFailed, err.Error()
+		report(ctx, client, cfg.Ztp.StatusURL, s)
+		if cfg.Ztp.Attempts > 0 && attempt >= cfg.Ztp.Attempts {
+			return fmt.Errorf("ztp: provisioning failed after %d attempts: %w", attempt, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+	if cfg.Ztp.DoneFile != "" {
+		if err := os.WriteFile(cfg.Ztp.DoneFile, []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0600); err != nil {
+			return fmt.Errorf("ztp: the bundle is applied but %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Intel Corporation, or its subsidiaries.
+// Copyright (C) 2023 Nordix Foundation.
+
+// Package ztp provisions a factory-fresh node without operator: the bridge fetches its initial bundle
+// from a provisioning server, applies it and reports the outcome back
+package ztp
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/network/evpn-gw/v1alpha1/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/opiproject/opi-evpn-bridge/pkg/bridge"
+	"github.com/opiproject/opi-evpn-bridge/pkg/config"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb"
+	"github.com/opiproject/opi-evpn-bridge/pkg/infradb/subscriberframework/eventbus"
+	"github.com/opiproject/opi-evpn-bridge/pkg/port"
+	"github.com/opiproject/opi-evpn-bridge/pkg/svi"
+	"github.com/opiproject/opi-evpn-bridge/pkg/vrf"
+)
+
+// newTestConn serves the bridge gRPC API on top of an empty gomap db
+func newTestConn(t *testing.T) *grpc.ClientConn {
+	eb := eventbus.EBus
+	for _, eventType := range []string{"vrf", "logical-bridge", "svi", "bridge-port"} {
+		eb.StartSubscriber("dummy", eventType, 1, nil)
+	}
+	if err := infradb.NewInfraDB("", "gomap"); err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	pb.RegisterVrfServiceServer(s, vrf.NewServer())
+	pb.RegisterLogicalBridgeServiceServer(s, bridge.NewServer())
+	pb.RegisterSviServiceServer(s, svi.NewServer())
+	pb.RegisterBridgePortServiceServer(s, port.NewServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// provisioningServer serves the bundle and records the states reported by the bridge
+type provisioningServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	bundle  string
+	fetches int
+	states  []State
+}
+
+// newProvisioningServer starts the https provisioning server and returns the config of the bridge trusting it
+func newProvisioningServer(t *testing.T, bundle string) (*provisioningServer, *config.Config) {
+	p := &provisioningServer{bundle: bundle}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dpu.yaml", func(w http.ResponseWriter, _ *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.fetches++
+		if p.bundle == "" {
+			http.NotFound(w, nil)
+			return
+		}
+		_, _ = w.Write([]byte(p.bundle))
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		s := Status{}
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.states = append(p.states, s.State)
+	})
+	p.Server = httptest.NewTLSServer(mux)
+	t.Cleanup(p.Close)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: p.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Ztp = config.ZtpConfig{
+		Enabled:   true,
+		URL:       p.URL + "/dpu.yaml",
+		StatusURL: p.URL + "/status",
+		CAFile:    caFile,
+		Attempts:  1,
+		DoneFile:  filepath.Join(dir, "ztp.done"),
+	}
+	return p, cfg
+}
+
+func Test_BundleURL(t *testing.T) {
+	dir := t.TempDir()
+	leases := filepath.Join(dir, "dhclient.leases")
+	content := `lease {
+  interface "eth0";
+  option bootfile-name "https://old.example.com/dpu.yaml";
+}
+lease {
+  interface "eth0";
+  option routers 192.168.0.1;
+  option bootfile-name "https://ztp.example.com/dpu.yaml";
+}
+`
+	if err := os.WriteFile(leases, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.ZtpConfig{LeaseFiles: []string{filepath.Join(dir, "missing.leases"), leases}}
+	if u, err := bundleURL(cfg); err != nil || u != "https://ztp.example.com/dpu.yaml" {
+		t.Errorf("expected the url of the last lease, received %q (%v)", u, err)
+	}
+	cfg.URL = "https://config.example.com/dpu.yaml"
+	if u, _ := bundleURL(cfg); u != cfg.URL {
+		t.Errorf("expected the configured url to win, received %q", u)
+	}
+	cfg.URL, cfg.LeaseOption = "", "routers"
+	if _, err := bundleURL(cfg); err == nil {
+		t.Error("expected an option which is not an https url to be refused")
+	}
+	cfg.LeaseOption = "opi-ztp-url"
+	if _, err := bundleURL(cfg); !errors.Is(err, ErrNoURL) {
+		t.Errorf("expected ErrNoURL, received %v", err)
+	}
+}
+
+func Test_Run(t *testing.T) {
+	conn := newTestConn(t)
+	server, cfg := newProvisioningServer(t, "---\n")
+	if err := Run(context.Background(), cfg, conn); err != nil {
+		t.Fatal(err)
+	}
+	expected := []State{StateFetching, StateApplying, StateApplied}
+	if !reflect.DeepEqual(server.states, expected) {
+		t.Errorf("expected the states %v, received %v", expected, server.states)
+	}
+	if _, err := os.Stat(cfg.Ztp.DoneFile); err != nil {
+		t.Errorf("expected the done file to be written: %v", err)
+	}
+	if err := Run(context.Background(), cfg, conn); err != nil || server.fetches != 1 {
+		t.Errorf("expected a provisioned bridge not to fetch the bundle again, %d fetches (%v)", server.fetches, err)
+	}
+}
+
+func Test_RunFailure(t *testing.T) {
+	conn := newTestConn(t)
+	server, cfg := newProvisioningServer(t, "")
+	if err := Run(context.Background(), cfg, conn); err == nil {
+		t.Error("expected a missing bundle to fail the provisioning")
+	}
+	expected := []State{StateFetching, StateFailed}
+	if !reflect.DeepEqual(server.states, expected) {
+		t.Errorf("expected the states %v, received %v", expected, server.states)
+	}
+	if _, err := os.Stat(cfg.Ztp.DoneFile); err == nil {
+		t.Error("expected no done file after a failure")
+	}
+
+	server.bundle = "apiVersion: v1\nkind: Vrf\n"
+	if err := Run(context.Background(), cfg, conn); err == nil {
+		t.Error("expected an invalid bundle to fail the provisioning")
+	}
+
+	cfg.Ztp.CAFile = ""
+	if err := Run(context.Background(), cfg, conn); err == nil {
+		t.Error("expected a server which is not trusted to fail the provisioning")
+	}
+}
+
+func Test_RunDisabled(t *testing.T) {
+	if err := Run(context.Background(), &config.Config{}, nil); err != nil {
+		t.Errorf("expected a disabled provisioning to do nothing, received %v", err)
+	}
+}